@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dshills/gocreator/internal/bundle"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyOutput string
+	applyCheck  bool
+	applyForce  bool
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <bundle.tar>",
+	Short: "Apply a patch bundle produced by generate --emit-patches",
+	Long: `Apply a portable patch bundle to a tree without LLM access.
+
+Bundles are created with 'gocreator generate --emit-patches bundle.tar'. They
+hold one patch per changed file, a manifest recording the checksum of the
+content each patch was made against, and SHA256SUMS for the archive members.
+
+Before writing anything, every file in the target tree is checked:
+  new       File does not exist and will be created
+  pending   File matches the patch base and will be modified
+  applied   File already has the patched content and is skipped
+  conflict  File was changed since the bundle was created
+
+Any conflict aborts the apply unless --force is given.
+
+Example:
+  # Preview what would change
+  gocreator apply bundle.tar --output ./my-project --check
+
+  # Apply the bundle
+  gocreator apply bundle.tar --output ./my-project`,
+	Args: cobra.ExactArgs(1),
+	RunE: runApply,
+}
+
+func setupApplyFlags() {
+	applyCmd.Flags().StringVarP(&applyOutput, "output", "o", ".", "tree to apply the bundle to")
+	applyCmd.Flags().BoolVar(&applyCheck, "check", false, "only report conflicts, do not write files")
+	applyCmd.Flags().BoolVar(&applyForce, "force", false, "apply patches to conflicting files anyway")
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	bundlePath := args[0]
+
+	log.Info().
+		Str("bundle", bundlePath).
+		Str("output", applyOutput).
+		Bool("check", applyCheck).
+		Msg("Applying patch bundle")
+
+	//nolint:gosec // G304: Reading user-provided bundle - required for CLI functionality
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to open bundle: %w", err)}
+	}
+	defer func() { _ = file.Close() }()
+
+	b, err := bundle.Read(file)
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+
+	diffEngine, err := fsops.NewDiffEngineByName(b.Manifest.PatchFormat)
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+
+	if err := os.MkdirAll(applyOutput, 0o750); err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to create output directory: %w", err)}
+	}
+
+	logger, err := fsops.NewFileLogger(filepath.Join(applyOutput, ".gocreator", "logs"))
+	if err != nil {
+		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create file logger: %w", err)}
+	}
+	defer func() { _ = logger.Close() }()
+
+	fileOps, err := fsops.New(fsops.Config{
+		RootDir:        applyOutput,
+		Logger:         logger,
+		DiffEngine:     diffEngine,
+		ProtectedPaths: cfg.Project.ProtectedPaths,
+		MaxOpenFiles:   cfg.Limits.MaxOpenFiles,
+	})
+	if err != nil {
+		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create file operations: %w", err)}
+	}
+
+	fmt.Printf("Bundle: %s (%d files, created %s)\n\n", bundlePath, len(b.Manifest.Files), b.Manifest.CreatedAt.Format("2006-01-02 15:04:05"))
+
+	ctx := cmd.Context()
+	if applyCheck {
+		results, err := b.Check(ctx, fileOps)
+		if err != nil {
+			return ExitError{Code: ExitCodeFileSystemError, Err: err}
+		}
+		printBundleResults(results)
+		if conflicts := countStatus(results, bundle.StatusConflict); conflicts > 0 {
+			return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("%d file(s) conflict with the bundle", conflicts)}
+		}
+		fmt.Printf("\nNo conflicts; run without --check to apply\n")
+		return nil
+	}
+
+	results, err := b.Apply(ctx, fileOps, bundle.ApplyOptions{Force: applyForce})
+	printBundleResults(results)
+	if err != nil {
+		var conflictErr *bundle.ConflictError
+		if errors.As(err, &conflictErr) {
+			fmt.Printf("\nNothing was written. Resolve the conflicts or re-run with --force.\n")
+		}
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+
+	fmt.Printf("\n✓ Bundle applied to %s\n", applyOutput)
+	return nil
+}
+
+// printBundleResults prints one line per bundle entry
+func printBundleResults(results []bundle.Result) {
+	for _, r := range results {
+		marker := "✓"
+		if r.Status == bundle.StatusConflict {
+			marker = "✗"
+		}
+		fmt.Printf("  %s %-9s %s", marker, r.Status, r.Path)
+		if r.Reason != "" {
+			fmt.Printf(" (%s)", r.Reason)
+		}
+		fmt.Println()
+	}
+}
+
+// countStatus counts results with the given status
+func countStatus(results []bundle.Result, status bundle.Status) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// writePatchBundle exports the output's patches as a bundle at path
+func writePatchBundle(output *models.GenerationOutput, path string) error {
+	b, err := bundle.New(output, fsops.NewUnifiedDiffEngine().Name())
+	if err != nil {
+		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to build patch bundle: %w", err)}
+	}
+
+	//nolint:gosec // G304: Writing user-specified bundle path - required for CLI functionality
+	file, err := os.Create(path)
+	if err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to create bundle file: %w", err)}
+	}
+
+	if err := b.Write(file); err != nil {
+		_ = file.Close()
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to write patch bundle: %w", err)}
+	}
+	if err := file.Close(); err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to close bundle file: %w", err)}
+	}
+
+	fmt.Printf("Patch bundle written to: %s (%d files)\n", path, len(b.Manifest.Files))
+	return nil
+}
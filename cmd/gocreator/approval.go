@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/cli"
+	"github.com/dshills/gocreator/internal/config"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// fullCheckpoints pauses the full pipeline for approval at the enabled
+// checkpoints
+type fullCheckpoints struct {
+	enabled  []string
+	action   cli.ApprovalAction
+	approver *cli.Approver
+}
+
+// newFullCheckpoints resolves the approval checkpoints of the full command
+// from its flags and workflow.approval. Without a terminal on stdin nobody
+// can answer, so every checkpoint takes the default action at once.
+func newFullCheckpoints(cmd *cobra.Command) (*fullCheckpoints, error) {
+	approval := cfg.Workflow.Approval
+	if cmd.Flags().Changed("approve") {
+		approval.Checkpoints = fullApprove
+	}
+	if cmd.Flags().Changed("approval-timeout") {
+		approval.Timeout = fullApprovalTimeout
+	}
+	if cmd.Flags().Changed("approval-default") {
+		approval.Default = fullApprovalDefault
+	}
+
+	for _, checkpoint := range approval.Checkpoints {
+		if !slices.Contains(config.ApprovalCheckpoints, checkpoint) {
+			return nil, ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("unknown checkpoint %q (want %s)", checkpoint, strings.Join(config.ApprovalCheckpoints, ", "))}
+		}
+	}
+	if approval.Timeout < 0 {
+		return nil, ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("--approval-timeout must not be negative")}
+	}
+	action := cli.ApprovalContinue
+	if approval.Default != "" {
+		var err error
+		if action, err = cli.ParseApprovalAction(approval.Default); err != nil {
+			return nil, ExitError{Code: ExitCodeGeneralError, Err: err}
+		}
+	}
+
+	checkpoints := &fullCheckpoints{enabled: approval.Checkpoints, action: action}
+	if len(approval.Checkpoints) > 0 && stdinIsTerminal() {
+		checkpoints.approver = cli.NewApprover(cli.ApprovalConfig{
+			In:      os.Stdin,
+			Out:     os.Stdout,
+			Timeout: approval.Timeout,
+			Default: action,
+		})
+	}
+	return checkpoints, nil
+}
+
+// approve pauses at checkpoint when it is enabled. Aborting returns an error
+// that tells the user how to continue from the checkpoint.
+func (c *fullCheckpoints) approve(ctx context.Context, checkpoint, summary string) error {
+	if !slices.Contains(c.enabled, checkpoint) {
+		return nil
+	}
+
+	action := c.action
+	if c.approver != nil {
+		var err error
+		if action, err = c.approver.Ask(ctx, checkpoint, summary); err != nil {
+			return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("%s checkpoint interrupted: %w", checkpoint, err)}
+		}
+	} else {
+		fmt.Printf("--- Checkpoint: %s ---\n\nstdin is not a terminal; taking the default action (%s)\n\n", checkpoint, action)
+	}
+
+	log.Info().
+		Str("checkpoint", checkpoint).
+		Str("action", string(action)).
+		Bool("interactive", c.approver != nil).
+		Msg("Approval checkpoint")
+
+	if action == cli.ApprovalAbort {
+		return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("aborted at the %s checkpoint; rerun with --resume to continue from it", checkpoint)}
+	}
+	return nil
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// summarizeFCS describes a clarified specification for the clarify checkpoint
+func summarizeFCS(fcs *models.FinalClarifiedSpecification) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Requirements: %d functional, %d non-functional\n",
+		len(fcs.Requirements.Functional), len(fcs.Requirements.NonFunctional)))
+
+	packages := make([]string, 0, len(fcs.Architecture.Packages))
+	for _, pkg := range fcs.Architecture.Packages {
+		packages = append(packages, pkg.Path)
+	}
+	sb.WriteString(fmt.Sprintf("Packages (%d): %s\n", len(packages), strings.Join(packages, ", ")))
+
+	entities := make([]string, 0, len(fcs.DataModel.Entities))
+	for _, entity := range fcs.DataModel.Entities {
+		entities = append(entities, entity.Name)
+	}
+	sb.WriteString(fmt.Sprintf("Entities (%d): %s\n", len(entities), strings.Join(entities, ", ")))
+
+	if clarifications := fcs.Metadata.Clarifications; len(clarifications) > 0 {
+		sb.WriteString(fmt.Sprintf("Clarifications (%d):\n", len(clarifications)))
+		for _, c := range clarifications {
+			sb.WriteString(fmt.Sprintf("  %s: %s\n", c.QuestionID, c.Answer))
+		}
+	}
+	return sb.String()
+}
+
+// summarizeGenerationPlan describes a plan for the plan checkpoint
+func summarizeGenerationPlan(plan *generationPlan) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Packages (%d): %s\n", len(plan.Packages), strings.Join(plan.Packages, ", ")))
+	sb.WriteString(fmt.Sprintf("Files (%d): %s\n", len(plan.Files), strings.Join(plan.Files, ", ")))
+	return sb.String()
+}
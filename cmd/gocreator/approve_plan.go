@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dshills/gocreator/internal/cli"
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/schema"
+	"github.com/dshills/gocreator/internal/yamlutil"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// approvedPlanName is the file under <output>/.gocreator the plan is written
+// to for review
+const approvedPlanName = "plan.yaml"
+
+// checkPlanApproval fails fast on plan approval flags that cannot work,
+// before clarification and planning spend anything
+func checkPlanApproval() error {
+	if generateApprovePlan && !stdinIsTerminal() {
+		return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("--approve-plan needs a terminal to confirm on; use --approve-from <file> in scripts")}
+	}
+	if generateApproveFrom != "" {
+		if _, err := readPlanFile(generateApproveFrom); err != nil {
+			return ExitError{Code: ExitCodeSpecError, Err: err}
+		}
+	}
+	return nil
+}
+
+// newPlanApprover returns the approver of the generation plan for
+// --approve-plan and --approve-from, or nil when neither is set. settle is
+// called before prompting so the progress output does not run into the
+// prompt.
+func newPlanApprover(outputDir string, settle func()) generate.PlanApprover {
+	if generateApproveFrom != "" {
+		path := generateApproveFrom
+		return func(_ context.Context, plan *models.GenerationPlan) (*models.GenerationPlan, error) {
+			approved, err := readPlanFile(path)
+			if err != nil {
+				return nil, err
+			}
+			if approved.FCSID != plan.FCSID {
+				log.Warn().
+					Str("file", path).
+					Str("plan_fcs_id", approved.FCSID).
+					Str("fcs_id", plan.FCSID).
+					Msg("Approved plan was made for a different FCS")
+			}
+			log.Info().Str("file", path).Str("plan_id", approved.ID).Msg("Generating from approved plan")
+			return approved, nil
+		}
+	}
+	if !generateApprovePlan {
+		return nil
+	}
+
+	// Editing takes as long as it takes, and a timeout could pick up a
+	// half-saved file, so the prompt waits without one
+	approver := cli.NewApprover(cli.ApprovalConfig{In: os.Stdin, Out: os.Stdout})
+	path := filepath.Join(outputDir, ".gocreator", approvedPlanName)
+
+	return func(ctx context.Context, plan *models.GenerationPlan) (*models.GenerationPlan, error) {
+		settle()
+		if err := writePlanYAML(path, plan); err != nil {
+			return nil, err
+		}
+
+		summary := fmt.Sprintf("%s\n\nThe plan is in %s; edit it now if you want changes.\nThe saved file is what gets generated.",
+			summarizePlan(plan), path)
+		for {
+			action, err := approver.Ask(ctx, "plan", summary)
+			if err != nil {
+				return nil, err
+			}
+			if action == cli.ApprovalAbort {
+				return nil, fmt.Errorf("aborted; generate from the plan later with --approve-from %s", path)
+			}
+
+			approved, err := readPlanFile(path)
+			if err == nil {
+				err = approved.Validate()
+			}
+			if err == nil {
+				log.Info().
+					Str("file", path).
+					Bool("edited", !samePlan(plan, approved)).
+					Msg("Plan approved")
+				return approved, nil
+			}
+			// Let the user fix the edit rather than lose the run
+			summary = fmt.Sprintf("✗ %v\n\nFix %s and continue, or abort.", err, path)
+		}
+	}
+}
+
+// summarizePlan describes a generation plan for its approval
+func summarizePlan(plan *models.GenerationPlan) string {
+	tasks := 0
+	for _, phase := range plan.Phases {
+		tasks += len(phase.Tasks)
+	}
+	return fmt.Sprintf("Plan %s: %s, %s, %s",
+		plan.ID,
+		countNoun(len(plan.Phases), "phase"),
+		countNoun(tasks, "task"),
+		countNoun(len(plan.FileTree.Files), "file"))
+}
+
+// writePlanYAML writes a plan as YAML, keeping the field names and order of
+// its JSON form
+func writePlanYAML(path string, plan *models.GenerationPlan) error {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+
+	// JSON is YAML, so the node tree keeps the key order; clearing the
+	// styles turns the flow JSON into block YAML
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to convert plan to YAML: %w", err)
+	}
+	clearStyles(&doc)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to convert plan to YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to convert plan to YAML: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create plan directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+	return nil
+}
+
+// clearStyles resets a node tree to the default block style
+func clearStyles(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyles(child)
+	}
+}
+
+// readPlanFile reads a generation plan from a YAML or JSON file and checks
+// it against the plan schema
+func readPlanFile(path string) (*models.GenerationPlan, error) {
+	//nolint:gosec // G304: Reading user-provided plan file - required for CLI functionality
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	var doc interface{}
+	if err := yamlutil.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %w", path, err)
+	}
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %w", path, err)
+	}
+
+	if err := schema.Plan().Validate(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var plan models.GenerationPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %w", path, err)
+	}
+	return &plan, nil
+}
+
+// samePlan reports whether two plans encode identically
+func samePlan(a, b *models.GenerationPlan) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
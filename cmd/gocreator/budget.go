@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/cli"
+	"github.com/dshills/gocreator/internal/config"
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	budgetMaxCost   float64
+	budgetMaxTokens int64
+	budgetOnExceed  string
+)
+
+// addBudgetFlags adds the flags capping what a generation run spends
+func addBudgetFlags(cmd *cobra.Command) {
+	cmd.Flags().Float64Var(&budgetMaxCost, "max-cost", 0, "stop generation once its LLM calls cost this much in USD (overrides limits.max_cost)")
+	cmd.Flags().Int64Var(&budgetMaxTokens, "max-tokens", 0, "stop generation once its LLM calls use this many tokens (overrides limits.max_tokens)")
+	cmd.Flags().StringVar(&budgetOnExceed, "on-budget", "", "when a cap is crossed: abort, or confirm to ask whether to continue (overrides limits.on_budget)")
+}
+
+// newCostGovernor creates the governor enforcing the run's cost and token
+// caps, or nil when neither is set. Crossing a cap cancels the run through
+// stop. With on_budget set to confirm, an interactive run asks whether to
+// continue; an unattended one aborts.
+func newCostGovernor(stop context.CancelCauseFunc, eventChan chan<- models.ProgressEvent, interactive bool) (*generate.CostGovernor, error) {
+	limits := generate.BudgetLimits{MaxCost: cfg.Limits.MaxCost, MaxTokens: cfg.Limits.MaxTokens}
+	if budgetMaxCost > 0 {
+		limits.MaxCost = budgetMaxCost
+	}
+	if budgetMaxTokens > 0 {
+		limits.MaxTokens = budgetMaxTokens
+	}
+
+	action := cfg.Limits.OnBudget
+	if budgetOnExceed != "" {
+		action = budgetOnExceed
+	}
+	if action != "" && !slices.Contains(config.BudgetActions, action) {
+		return nil, fmt.Errorf("--on-budget must be one of: %s", strings.Join(config.BudgetActions, ", "))
+	}
+
+	var confirm generate.BudgetConfirmFunc
+	if action == "confirm" && interactive {
+		approver := cli.NewApprover(cli.ApprovalConfig{
+			In:      os.Stdin,
+			Out:     os.Stdout,
+			Default: cli.ApprovalAbort,
+		})
+		confirm = func(ctx context.Context, usage generate.BudgetUsage, limits generate.BudgetLimits) bool {
+			summary := (&generate.BudgetExceededError{Usage: usage, Limits: limits}).Error() +
+				"\nContinuing raises each crossed cap by its configured amount."
+			answer, err := approver.Ask(ctx, "budget", summary)
+			return err == nil && answer == cli.ApprovalContinue
+		}
+	}
+
+	return generate.NewCostGovernor(generate.CostGovernorConfig{
+		Limits:    limits,
+		Confirm:   confirm,
+		Stop:      stop,
+		EventChan: eventChan,
+	}), nil
+}
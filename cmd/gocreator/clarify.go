@@ -7,13 +7,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"time"
+	"strings"
 
 	"github.com/dshills/gocreator/internal/clarify"
+	"github.com/dshills/gocreator/internal/clarify/importers"
 	"github.com/dshills/gocreator/internal/config"
 	"github.com/dshills/gocreator/internal/models"
 	"github.com/dshills/gocreator/internal/spec"
 	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/dshills/gocreator/pkg/llm/cache"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -22,10 +24,12 @@ var (
 	clarifyOutput      string
 	clarifyInteractive bool
 	clarifyBatch       string
+	clarifyExport      string
+	clarifyAnswers     string
 )
 
 var clarifyCmd = &cobra.Command{
-	Use:   "clarify <spec-file>",
+	Use:   "clarify <spec-file>...",
 	Short: "Analyze specification and run clarification phase",
 	Long: `Analyze a specification file to identify ambiguities and run the clarification phase.
 
@@ -35,36 +39,69 @@ The clarification phase:
   3. Generates targeted questions for resolution
   4. Produces a Final Clarified Specification (FCS)
 
-Interactive mode (default):
-  Prompts for answers to clarification questions interactively.
+By default the ambiguities are resolved without asking.
+
+Interactive mode (--interactive):
+  Asks the generated questions one at a time at the terminal. Answer with an
+  option number or label, or with your own answer; "back" revisits the
+  previous question to revise its answer. Each answer is applied to the FCS
+  as it is given. The FCS is written once every question is answered.
 
 Batch mode (--batch):
   Uses pre-answered questions from a JSON file.
 
+Several specifications:
+  Given several files, such as a product spec, a security requirements
+  document and an OpenAPI description, clarify merges them into one FCS.
+  Requirements, entities, enums and API contracts are matched by their ID,
+  name or method and endpoint. A value the files disagree on is recorded in
+  the FCS metadata as a conflict and asked as a question; the FCS cannot be
+  generated from until every conflict is resolved.
+
+Offline review (--export-questions, --answers):
+  --export-questions writes the open questions to a YAML file and exits
+  without producing an FCS, so they can be answered offline. A later run
+  with --answers ingests the answered file and produces the FCS without
+  asking the LLM again. The spec must not change in between.
+
 Example:
-  # Interactive mode
+  # Resolve ambiguities without asking
   gocreator clarify ./my-project-spec.yaml
 
+  # Answer the questions interactively
+  gocreator clarify ./my-project-spec.yaml --interactive
+
   # Batch mode
   gocreator clarify ./my-project-spec.yaml --batch ./answers.json
 
+  # Export questions for offline review, then ingest the answers
+  gocreator clarify ./my-project-spec.yaml --export-questions ./questions.yaml
+  gocreator clarify ./my-project-spec.yaml --answers ./questions.yaml
+
+  # Merge several specifications into one FCS
+  gocreator clarify ./product.md ./security.md ./api.yaml --interactive
+
   # Specify output directory
   gocreator clarify ./my-project-spec.yaml --output ./output`,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MinimumNArgs(1),
 	RunE: runClarify,
 }
 
 func setupClarifyFlags() {
 	clarifyCmd.Flags().StringVarP(&clarifyOutput, "output", "o", ".", "output directory for FCS")
-	clarifyCmd.Flags().BoolVarP(&clarifyInteractive, "interactive", "i", true, "interactive mode for answering questions")
+	clarifyCmd.Flags().BoolVarP(&clarifyInteractive, "interactive", "i", false, "ask the clarification questions at the terminal and apply each answer to the FCS")
 	clarifyCmd.Flags().StringVar(&clarifyBatch, "batch", "", "path to JSON file with pre-answered questions")
+	clarifyCmd.Flags().StringVar(&clarifyExport, "export-questions", "", "write the open questions to a YAML file for offline answering and exit")
+	clarifyCmd.Flags().StringVar(&clarifyAnswers, "answers", "", "path to a question file exported with --export-questions and answered")
+	clarifyCmd.MarkFlagsMutuallyExclusive("export-questions", "answers", "batch", "interactive")
 }
 
-func runClarify(_ *cobra.Command, args []string) error {
-	specFile := args[0]
+func runClarify(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	specFile := strings.Join(args, " ")
 
 	log.Info().
-		Str("spec_file", specFile).
+		Strs("spec_files", args).
 		Str("output", clarifyOutput).
 		Bool("interactive", clarifyInteractive).
 		Msg("Starting clarification phase")
@@ -72,26 +109,9 @@ func runClarify(_ *cobra.Command, args []string) error {
 	fmt.Printf("GoCreator v%s - Clarification Phase\n\n", version)
 	fmt.Printf("Analyzing specification: %s\n\n", specFile)
 
-	// Detect format from file extension
-	format, err := detectSpecFormat(specFile)
+	inputSpec, content, err := loadClarifySpec(args)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to detect spec format")
-		return ExitError{Code: ExitCodeSpecError, Err: err}
-	}
-
-	// Read spec file
-	//nolint:gosec // G304: Reading user-provided spec file - required for CLI functionality
-	content, err := os.ReadFile(specFile)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to read spec file")
-		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to read spec file: %w", err)}
-	}
-
-	// Parse and validate specification
-	inputSpec, err := spec.ParseAndValidate(format, string(content))
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to parse specification")
-		return ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("specification validation failed: %w", err)}
+		return err
 	}
 
 	log.Info().
@@ -99,6 +119,15 @@ func runClarify(_ *cobra.Command, args []string) error {
 		Str("format", string(inputSpec.Format)).
 		Msg("Specification parsed and validated")
 
+	// Ingesting answered questions needs no LLM calls
+	if clarifyAnswers != "" {
+		fcs, err := applyQuestionSet(inputSpec, content)
+		if err != nil {
+			return err
+		}
+		return writeClarifiedSpec(fcs)
+	}
+
 	// Create LLM client
 	llmClient, err := createLLMClient(cfg)
 	if err != nil {
@@ -115,8 +144,20 @@ func runClarify(_ *cobra.Command, args []string) error {
 		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create clarification engine: %w", err)}
 	}
 
+	if clarifyExport != "" {
+		return exportQuestions(ctx, engine, inputSpec, specFile, content)
+	}
+
+	if clarifyInteractive {
+		fcs, err := clarifyInteractively(ctx, engine, inputSpec)
+		if err != nil {
+			return err
+		}
+		return writeClarifiedSpec(fcs)
+	}
+
 	// Determine interactive mode
-	interactive := clarifyInteractive && clarifyBatch == ""
+	interactive := clarifyBatch == ""
 
 	// Load batch answers if provided
 	var batchAnswers map[string]string
@@ -149,13 +190,17 @@ func runClarify(_ *cobra.Command, args []string) error {
 	}
 
 	// Run clarification
-	ctx := context.Background()
-	fcs, err := engine.Clarify(ctx, inputSpec, interactive)
+	fcs, err := clarifySpec(ctx, engine, inputSpec, interactive)
 	if err != nil {
 		log.Error().Err(err).Msg("Clarification failed")
 		return ExitError{Code: ExitCodeClarificationError, Err: fmt.Errorf("clarification failed: %w", err)}
 	}
 
+	return writeClarifiedSpec(fcs)
+}
+
+// writeClarifiedSpec writes the FCS to <output>/.gocreator/fcs.json
+func writeClarifiedSpec(fcs *models.FinalClarifiedSpecification) error {
 	// Ensure output directory exists
 	fcsDir := filepath.Join(clarifyOutput, ".gocreator")
 	if err := os.MkdirAll(fcsDir, 0o750); err != nil {
@@ -171,6 +216,13 @@ func runClarify(_ *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("\nFinal Clarified Specification written to: %s\n", fcsPath)
+	if unresolved := fcs.UnresolvedConflicts(); len(unresolved) > 0 {
+		fmt.Printf("\n%s between the merged specifications must be resolved before generating:\n", countNoun(len(unresolved), "conflict"))
+		for _, conflict := range unresolved {
+			fmt.Printf("  %s: %s\n", conflict.ID, conflict.Path)
+		}
+		fmt.Printf("Answer them with --interactive or --answers, or set their resolution in %s\n", fcsPath)
+	}
 
 	log.Info().
 		Str("fcs_id", fcs.ID).
@@ -181,6 +233,187 @@ func runClarify(_ *cobra.Command, args []string) error {
 	return nil
 }
 
+// exportQuestions writes the open questions for a spec to the
+// --export-questions file instead of producing an FCS
+func exportQuestions(ctx context.Context, engine clarify.Engine, inputSpec *models.InputSpecification, specFile, content string) error {
+	phaseCtx, cancel := withPhaseTimeout(ctx, cfg.Timeouts.Clarify)
+	defer cancel()
+
+	request, err := engine.GenerateRequest(phaseCtx, inputSpec)
+	if err != nil {
+		err = phaseError(phaseCtx, "clarification", "clarify", cfg.Timeouts.Clarify, err)
+		log.Error().Err(err).Msg("Failed to generate clarification questions")
+		return ExitError{Code: ExitCodeClarificationError, Err: fmt.Errorf("failed to generate clarification questions: %w", err)}
+	}
+
+	set := clarify.NewQuestionSet(request, specFile, content)
+	if err := clarify.WriteQuestionSet(clarifyExport, set); err != nil {
+		log.Error().Err(err).Msg("Failed to write question set")
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+
+	log.Info().
+		Str("request_id", request.ID).
+		Str("path", clarifyExport).
+		Int("questions", len(set.Questions)).
+		Msg("Clarification questions exported")
+
+	if len(set.Questions) == 0 {
+		fmt.Printf("No open questions; %s can be ingested as is.\n", clarifyExport)
+	} else {
+		fmt.Printf("Exported %s to: %s\n", countNoun(len(set.Questions), "question"), clarifyExport)
+	}
+	fmt.Printf("Fill in the answers, then run: gocreator clarify %s --answers %s\n", specFile, clarifyExport)
+	return nil
+}
+
+// clarifyInteractively asks the open questions for a spec at the terminal
+// and builds the FCS from the answers. timeouts.clarify limits generating the
+// questions, not the time taken to answer them.
+func clarifyInteractively(ctx context.Context, engine clarify.Engine, inputSpec *models.InputSpecification) (*models.FinalClarifiedSpecification, error) {
+	phaseCtx, cancel := withPhaseTimeout(ctx, cfg.Timeouts.Clarify)
+	defer cancel()
+
+	request, err := engine.GenerateRequest(phaseCtx, inputSpec)
+	if err != nil {
+		err = phaseError(phaseCtx, "clarification", "clarify", cfg.Timeouts.Clarify, err)
+		log.Error().Err(err).Msg("Failed to generate clarification questions")
+		return nil, ExitError{Code: ExitCodeClarificationError, Err: fmt.Errorf("failed to generate clarification questions: %w", err)}
+	}
+
+	session := clarify.NewSession(clarify.SessionConfig{In: os.Stdin, Out: os.Stdout})
+	fcs, err := session.Run(ctx, inputSpec, request)
+	if err != nil {
+		log.Error().Err(err).Msg("Interactive clarification failed")
+		return nil, ExitError{Code: ExitCodeClarificationError, Err: fmt.Errorf("interactive clarification failed: %w", err)}
+	}
+
+	log.Info().
+		Str("request_id", request.ID).
+		Int("questions", len(request.Questions)).
+		Msg("Clarification questions answered interactively")
+
+	return fcs, nil
+}
+
+// applyQuestionSet builds the FCS from the answered --answers file
+func applyQuestionSet(inputSpec *models.InputSpecification, content string) (*models.FinalClarifiedSpecification, error) {
+	fmt.Printf("Loading answers from: %s\n", clarifyAnswers)
+
+	set, err := clarify.LoadQuestionSet(clarifyAnswers)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load answers")
+		return nil, ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+	if err := set.CheckSpec(content); err != nil {
+		log.Error().Err(err).Msg("Answers do not match the specification")
+		return nil, ExitError{Code: ExitCodeClarificationError, Err: err}
+	}
+	fcs, err := set.Apply(inputSpec)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to apply answers")
+		return nil, ExitError{Code: ExitCodeClarificationError, Err: fmt.Errorf("failed to apply answers from %s: %w", clarifyAnswers, err)}
+	}
+	return fcs, nil
+}
+
+// loadClarifySpec reads, parses and validates the spec files given to
+// clarify, merging several into one spec. It returns the spec with the
+// content question sets are checked against.
+func loadClarifySpec(specFiles []string) (*models.InputSpecification, string, error) {
+	sources := make([]spec.MergeSource, 0, len(specFiles))
+	var content string
+	for _, specFile := range specFiles {
+		// Detect format from file extension
+		format, err := detectSpecFormat(specFile)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to detect spec format")
+			return nil, "", ExitError{Code: ExitCodeSpecError, Err: err}
+		}
+
+		// Read spec file
+		//nolint:gosec // G304: Reading user-provided spec file - required for CLI functionality
+		data, err := os.ReadFile(specFile)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to read spec file")
+			return nil, "", ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to read spec file: %w", err)}
+		}
+		content = string(data)
+
+		// Parse and validate specification
+		inputSpec, err := parseSpec(specFile, format, content)
+		if err != nil {
+			log.Error().Err(err).Str("spec_file", specFile).Msg("Failed to parse specification")
+			return nil, "", ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("specification validation failed: %w", err)}
+		}
+		sources = append(sources, spec.MergeSource{Name: specFile, Spec: inputSpec})
+	}
+	if len(sources) == 1 {
+		return sources[0].Spec, content, nil
+	}
+
+	merged, err := spec.Merge(sources)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to merge specifications")
+		return nil, "", ExitError{Code: ExitCodeSpecError, Err: err}
+	}
+
+	log.Info().
+		Strs("spec_files", specFiles).
+		Int("conflicts", len(merged.Conflicts)).
+		Msg("Specifications merged")
+	fmt.Printf("Merged %d specifications with %s\n", len(sources), countNoun(len(merged.Conflicts), "conflict"))
+	for _, conflict := range merged.Conflicts {
+		fmt.Printf("  %s: %s\n", conflict.ID, conflict.Path)
+		for _, source := range conflict.Sources {
+			fmt.Printf("    %s: %s\n", source.Spec, source.Value)
+		}
+	}
+	fmt.Println()
+	return merged, merged.Content, nil
+}
+
+// parseSpec parses and validates the content of specFile. A document in a
+// format with an importer, such as OpenAPI, is converted to a spec first.
+func parseSpec(specFile string, format models.SpecFormat, content string) (*models.InputSpecification, error) {
+	format, content, err := importSpecDocument(specFile, format, content)
+	if err != nil {
+		return nil, err
+	}
+	return spec.ParseAndValidateWithImports(format, content, filepath.Dir(specFile))
+}
+
+// importSpecDocument converts the content of specFile to a YAML spec when it
+// is a document in a format with an importer, such as OpenAPI
+func importSpecDocument(specFile string, format models.SpecFormat, content string) (models.SpecFormat, string, error) {
+	if format != models.FormatMarkdown {
+		result, err := importers.Import(content)
+		if err != nil {
+			return "", "", err
+		}
+		if result != nil {
+			if content, err = result.Spec(); err != nil {
+				return "", "", err
+			}
+			format = models.FormatYAML
+
+			for _, warning := range result.Warnings {
+				log.Warn().Str("spec_file", specFile).Msg(warning)
+			}
+			log.Info().
+				Str("spec_file", specFile).
+				Str("source", result.Source).
+				Int("api_contracts", len(result.APIContracts)).
+				Int("entities", len(result.Entities)).
+				Int("enums", len(result.Enums)).
+				Msg("Imported specification")
+			fmt.Printf("Imported %s document: %s, %d entities, %d enums\n\n", result.Source,
+				countNoun(len(result.APIContracts), "operation"), len(result.Entities), len(result.Enums))
+		}
+	}
+	return format, content, nil
+}
+
 func detectSpecFormat(filename string) (models.SpecFormat, error) {
 	ext := filepath.Ext(filename)
 	switch ext {
@@ -208,42 +441,235 @@ func writeFCS(fcs *models.FinalClarifiedSpecification, path string) error {
 	return nil
 }
 
+// apiKeyEnvVars maps providers to the environment variable holding their API key
+var apiKeyEnvVars = map[string]string{
+	"anthropic": "ANTHROPIC_API_KEY",
+	"openai":    "OPENAI_API_KEY",
+	"google":    "GOOGLE_API_KEY",
+
+	// Without a key, Azure OpenAI and Bedrock use the managed identity or
+	// AWS credentials of the host
+	"azure-openai": "AZURE_OPENAI_API_KEY",
+	"bedrock":      "AWS_BEARER_TOKEN_BEDROCK",
+}
+
+// resolveAPIKey returns the API key from config, falling back to the
+// provider's environment variable
+func resolveAPIKey(cfg *config.Config) string {
+	if cfg.LLM.APIKey != "" {
+		return cfg.LLM.APIKey
+	}
+	if envVar, ok := apiKeyEnvVars[cfg.LLM.Provider]; ok {
+		return os.Getenv(envVar)
+	}
+	return ""
+}
+
+// networkConfig converts the proxy and TLS settings for the LLM client
+func networkConfig(cfg *config.Config) llm.NetworkConfig {
+	return llm.NetworkConfig{
+		ProxyURL:           cfg.LLM.Network.ProxyURL,
+		CABundle:           cfg.LLM.Network.CABundle,
+		TLSMinVersion:      cfg.LLM.Network.TLSMinVersion,
+		InsecureSkipVerify: cfg.LLM.Network.InsecureSkipVerify,
+	}
+}
+
+// llmQuotas is shared by every client created for llmQuotasConfig, so clients
+// for the same provider draw on one llm.concurrency cap
+var (
+	llmQuotas       *llm.QuotaLimiter
+	llmQuotasConfig *config.Config
+)
+
+// quotaLimiter returns the limiter for the configured llm.concurrency classes
+// and limits.soft_memory_mb
+func quotaLimiter(cfg *config.Config) *llm.QuotaLimiter {
+	if cfg != llmQuotasConfig {
+		classes := make([]llm.QuotaClass, 0, len(cfg.LLM.Concurrency))
+		for _, class := range cfg.LLM.Concurrency {
+			classes = append(classes, llm.QuotaClass{
+				Provider:    llm.Provider(class.Provider),
+				Model:       class.Model,
+				MaxParallel: class.MaxParallel,
+			})
+		}
+		llmQuotas = llm.NewQuotaLimiter(classes)
+		if throttle := memoryThrottle(cfg); throttle != nil {
+			llmQuotas = llmQuotas.WithThrottle(throttle)
+		}
+		llmQuotasConfig = cfg
+	}
+	return llmQuotas
+}
+
+// llmCache is the response cache shared by every client created for
+// llmCacheConfig, nil when caching is off or the cache cannot be opened
+var (
+	llmCache       llm.Cache
+	llmCacheConfig *config.Config
+)
+
+// responseCache returns the disk cache of LLM responses configured by
+// llm.cache, or nil when it is disabled or --no-cache is set
+func responseCache(cfg *config.Config) llm.Cache {
+	if cfg == llmCacheConfig {
+		return llmCache
+	}
+	llmCache, llmCacheConfig = nil, cfg
+	if !cfg.LLM.Cache.Enabled || noCache {
+		return nil
+	}
+
+	disk, err := cache.New(cache.Config{
+		Dir:     cfg.LLM.Cache.Path,
+		TTL:     cfg.LLM.Cache.TTL,
+		MaxSize: cfg.LLM.Cache.MaxSizeMB << 20,
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("LLM response cache disabled")
+		return nil
+	}
+	stats := disk.Stats()
+	log.Debug().
+		Str("dir", disk.Dir()).
+		Int("entries", stats.Entries).
+		Int64("size_kb", stats.TotalSizeKB).
+		Msg("Using LLM response cache")
+	llmCache = disk
+	return llmCache
+}
+
+// clientParallelism returns the llm.concurrency cap for client, falling back
+// to workflow.max_parallel
+func clientParallelism(cfg *config.Config, client llm.Client) int {
+	if n := quotaLimiter(cfg).MaxParallel(client.Provider(), client.Model()); n > 0 {
+		return n
+	}
+	return cfg.Workflow.MaxParallel
+}
+
 func createLLMClient(cfg *config.Config) (llm.Client, error) {
 	// Validate config
 	if cfg == nil {
 		return nil, fmt.Errorf("config cannot be nil")
 	}
 
-	// Get API key from config or environment variable
-	apiKey := cfg.LLM.APIKey
-	if apiKey == "" {
-		// Fall back to environment variable based on provider
-		switch cfg.LLM.Provider {
-		case "anthropic":
-			apiKey = os.Getenv("ANTHROPIC_API_KEY")
-		case "openai":
-			apiKey = os.Getenv("OPENAI_API_KEY")
-		case "google":
-			apiKey = os.Getenv("GOOGLE_API_KEY")
+	return newLLMClient(cfg, cfg.LLM.Provider, cfg.LLM.Model, resolveAPIKey(cfg))
+}
+
+// createEnsembleClient creates the client for the second model configured
+// under llm.ensemble, which competes with the primary model on --ensemble files
+func createEnsembleClient(cfg *config.Config) (llm.Client, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	ensemble := cfg.LLM.Ensemble
+	if !ensemble.Enabled() {
+		return nil, fmt.Errorf("llm.ensemble.model is not set")
+	}
+
+	return newSecondaryClient(cfg, ensemble.Provider, ensemble.Model, ensemble.APIKey)
+}
+
+// createDowngradeClient creates the client for the cheaper model configured
+// under llm.downgrade, which generates files over llm.file_cost_ceiling
+func createDowngradeClient(cfg *config.Config) (llm.Client, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	downgrade := cfg.LLM.Downgrade
+	if !downgrade.Enabled() {
+		return nil, fmt.Errorf("llm.downgrade.model is not set")
+	}
+
+	return newSecondaryClient(cfg, downgrade.Provider, downgrade.Model, downgrade.APIKey)
+}
+
+// phaseClients are the clients of the generation phases
+type phaseClients struct {
+	planner, coder, tester llm.Client
+}
+
+// createPhaseClients creates a client for each generation phase with a
+// model of its own under models; the other phases use primary
+func createPhaseClients(cfg *config.Config, primary llm.Client) (phaseClients, error) {
+	clients := phaseClients{planner: primary, coder: primary, tester: primary}
+	phases := []struct {
+		name   string
+		model  config.PhaseModelConfig
+		client *llm.Client
+	}{
+		{"planner", cfg.Models.Planner, &clients.planner},
+		{"coder", cfg.Models.Coder, &clients.coder},
+		{"tester", cfg.Models.Tester, &clients.tester},
+	}
+	for _, phase := range phases {
+		if !phase.model.Enabled() {
+			continue
+		}
+		client, err := newSecondaryClient(cfg, phase.model.Provider, phase.model.Model, phase.model.APIKey)
+		if err != nil {
+			return phaseClients{}, fmt.Errorf("failed to create %s LLM client: %w", phase.name, err)
 		}
+		*phase.client = client
 	}
+	return clients, nil
+}
 
-	if apiKey == "" {
-		return nil, fmt.Errorf("API key not found in config or environment variable for provider: %s", cfg.LLM.Provider)
+// newSecondaryClient creates a client for a model configured next to the
+// primary one. The provider defaults to llm.provider, and the API key to the
+// primary key for the same provider or the provider's environment variable.
+func newSecondaryClient(cfg *config.Config, provider, model, apiKey string) (llm.Client, error) {
+	if provider == "" {
+		provider = cfg.LLM.Provider
+	}
+	if apiKey == "" && provider == cfg.LLM.Provider {
+		apiKey = resolveAPIKey(cfg)
+	}
+	if envVar, ok := apiKeyEnvVars[provider]; ok && apiKey == "" {
+		apiKey = os.Getenv(envVar)
+	}
+
+	return newLLMClient(cfg, provider, model, apiKey)
+}
+
+// llmTemperature is the sampling temperature of every LLM client; 0.0 keeps
+// output deterministic, as the spec requires
+const llmTemperature = 0.0
+
+// newLLMClient creates a client for provider and model with the shared
+// timeout, token, retry, network and response cache settings
+func newLLMClient(cfg *config.Config, provider, model, apiKey string) (llm.Client, error) {
+	// Self-hosted and registered providers may run without a key
+	if apiKey == "" && llm.Provider(provider).RequiresAPIKey() {
+		return nil, fmt.Errorf("API key not found in config or environment variable for provider: %s", provider)
 	}
 
 	// Create LLM client configuration
 	llmConfig := llm.Config{
-		Provider:      llm.Provider(cfg.LLM.Provider),
-		Model:         cfg.LLM.Model,
-		Temperature:   0.0, // Force 0.0 for deterministic output (required by spec)
-		APIKey:        apiKey,
-		Timeout:       cfg.LLM.Timeout,
-		MaxTokens:     cfg.LLM.MaxTokens,
-		MaxRetries:    3,
-		RetryDelay:    time.Second * 2,
-		EnableCaching: true, // Enable prompt caching for cost savings
-		CacheTTL:      "5m",
+		Provider:          llm.Provider(provider),
+		Model:             model,
+		Temperature:       llmTemperature,
+		APIKey:            apiKey,
+		BaseURL:           cfg.LLM.BaseURL,
+		APIVersion:        cfg.LLM.APIVersion,
+		Region:            cfg.LLM.Region,
+		Timeout:           cfg.LLM.Timeout,
+		MaxTokens:         cfg.LLM.MaxTokens,
+		MaxRetries:        cfg.LLM.Retry.MaxRetries,
+		RetryDelay:        cfg.LLM.Retry.InitialDelay,
+		MaxRetryDelay:     cfg.LLM.Retry.MaxDelay,
+		EnableCaching:     true, // Enable prompt caching for cost savings
+		CacheTTL:          "5m",
+		Network:           networkConfig(cfg),
+		BatchPollInterval: cfg.LLM.BatchPollInterval,
+	}
+
+	// The deployment serves the primary model; other models on azure-openai
+	// are served by deployments named after them
+	if provider == cfg.LLM.Provider && model == cfg.LLM.Model {
+		llmConfig.Deployment = cfg.LLM.Deployment
 	}
 
 	// Create and return LLM client
@@ -253,9 +679,20 @@ func createLLMClient(cfg *config.Config) (llm.Client, error) {
 	}
 
 	log.Info().
-		Str("provider", cfg.LLM.Provider).
-		Str("model", cfg.LLM.Model).
+		Str("provider", provider).
+		Str("model", model).
 		Msg("LLM client created successfully")
 
-	return client, nil
+	if class, ok := quotaLimiter(cfg).Class(provider, model); ok {
+		log.Info().
+			Str("class", class.String()).
+			Int("max_parallel", class.MaxParallel).
+			Msg("LLM client limited to its concurrency class")
+	}
+
+	// Cache hits take no concurrency slot
+	if responses := responseCache(cfg); responses != nil {
+		client = llm.NewCachedClient(client, responses)
+	}
+	return quotaLimiter(cfg).Wrap(client), nil
 }
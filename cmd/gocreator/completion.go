@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/spf13/cobra"
+)
+
+// setupCompletions registers dynamic shell completion for flag values and
+// arguments; the scripts themselves come from cobra's completion command
+func setupCompletions() {
+	_ = rootCmd.MarkPersistentFlagFilename("config", "yaml", "yml")
+
+	runIDArg := func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeRunIDs(cmd)
+	}
+	debugStateCmd.ValidArgsFunction = runIDArg
+	resumeCmd.ValidArgsFunction = runIDArg
+	_ = retryFailedCmd.RegisterFlagCompletionFunc("run", func(cmd *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return completeRunIDs(cmd)
+	})
+	_ = retryFailedCmd.RegisterFlagCompletionFunc("model", completeModels)
+}
+
+// completeRunIDs offers the recorded runs under the command's --output
+// directory, most recent first
+func completeRunIDs(cmd *cobra.Command) ([]string, cobra.ShellCompDirective) {
+	output, _ := cmd.Flags().GetString("output")
+	runs, err := generate.ListStateRuns(output)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	if len(runs) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return append([]string{"latest"}, runs...), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeModels offers the known model families; they are prefixes, so
+// no space is added after a completed name
+func completeModels(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var models []string
+	for _, family := range llm.ModelFamilies() {
+		if strings.HasPrefix(family, toComplete) {
+			models = append(models, family)
+		}
+	}
+	return models, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/spf13/cobra"
+)
+
+var (
+	debugStateOutput string
+	debugStateAfter  string
+	debugStateStep   int
+	debugStateDelta  bool
+	debugStateField  string
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Inspect recorded generation runs",
+}
+
+var debugStateCmd = &cobra.Command{
+	Use:   "state <run-id>",
+	Short: "Show the workflow state recorded after each graph node",
+	Long: `Show the workflow state recorded during a generation run.
+
+Every 'gocreator generate' run appends the state delta returned by each graph
+node to <output>/.gocreator/runs/<run-id>/state.jsonl. The run ID is printed
+when generation fails and logged when it succeeds; 'latest' selects the most
+recent run.
+
+Without --after or --step the transitions are listed, one per node, with the
+state fields each node changed. With either flag the state is rebuilt by
+replaying the recorded deltas through the workflow reducer up to that point.
+
+Example:
+  # List the transitions of the last run
+  gocreator debug state latest --output ./my-project
+
+  # Show the state generate_packages received
+  gocreator debug state latest --output ./my-project --after create_plan
+
+  # Show only the plan field as it was after step 3
+  gocreator debug state gen-1234 --step 3 --field plan`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDebugState,
+}
+
+func setupDebugFlags() {
+	debugStateCmd.Flags().StringVarP(&debugStateOutput, "output", "o", ".", "output directory of the run")
+	debugStateCmd.Flags().StringVar(&debugStateAfter, "after", "", "show the state after the named node")
+	debugStateCmd.Flags().IntVar(&debugStateStep, "step", -1, "show the state after the transition with this sequence number")
+	debugStateCmd.Flags().BoolVar(&debugStateDelta, "delta", false, "show the node's delta instead of the accumulated state")
+	debugStateCmd.Flags().StringVar(&debugStateField, "field", "", "show a single state field (e.g. plan, all_patches, error)")
+
+	debugCmd.AddCommand(debugStateCmd)
+}
+
+func runDebugState(cmd *cobra.Command, args []string) error {
+	if debugStateAfter != "" && cmd.Flags().Changed("step") {
+		return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("--after and --step cannot be used together")}
+	}
+
+	runID := args[0]
+	if runID == "latest" {
+		runs, err := generate.ListStateRuns(debugStateOutput)
+		if err != nil {
+			return ExitError{Code: ExitCodeFileSystemError, Err: err}
+		}
+		if len(runs) == 0 {
+			return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("no recorded runs in %s", debugStateOutput)}
+		}
+		runID = runs[0]
+	}
+
+	transitions, err := generate.LoadStateTransitions(debugStateOutput, runID)
+	if err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+	if len(transitions) == 0 {
+		return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("run %s has no recorded transitions", runID)}
+	}
+
+	index := -1
+	switch {
+	case debugStateAfter != "":
+		index = findTransition(transitions, func(t generate.StateTransition) bool { return t.Node == debugStateAfter })
+		if index < 0 {
+			return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("node %q did not run in %s (ran: %s)", debugStateAfter, runID, strings.Join(transitionNodes(transitions), ", "))}
+		}
+	case cmd.Flags().Changed("step"):
+		index = findTransition(transitions, func(t generate.StateTransition) bool { return t.Seq == debugStateStep })
+		if index < 0 {
+			return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("run %s has no step %d (last step is %d)", runID, debugStateStep, transitions[len(transitions)-1].Seq)}
+		}
+	case debugStateDelta || debugStateField != "":
+		index = len(transitions) - 1
+	default:
+		return printTransitions(runID, transitions)
+	}
+
+	snapshot := transitions[index].Delta
+	if !debugStateDelta {
+		snapshot = generate.NewStateSnapshot(generate.ReplayState(transitions, index))
+	}
+	return printSnapshot(snapshot, debugStateField)
+}
+
+// findTransition returns the index of the last transition matching fn, or -1
+func findTransition(transitions []generate.StateTransition, fn func(generate.StateTransition) bool) int {
+	for i := len(transitions) - 1; i >= 0; i-- {
+		if fn(transitions[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func transitionNodes(transitions []generate.StateTransition) []string {
+	nodes := make([]string, len(transitions))
+	for i, t := range transitions {
+		nodes[i] = t.Node
+	}
+	return nodes
+}
+
+func printTransitions(runID string, transitions []generate.StateTransition) error {
+	fmt.Printf("Run %s (%d transitions)\n\n", runID, len(transitions))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STEP\tNODE\tDURATION\tNEXT\tCHANGED\tERROR")
+	for _, t := range transitions {
+		errText := t.Err
+		if errText == "" {
+			errText = t.Delta.Error
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			t.Seq, t.Node, t.Duration.Round(time.Millisecond), t.Next, strings.Join(t.Delta.ChangedFields(), ","), errText)
+	}
+	return w.Flush()
+}
+
+// printSnapshot writes the snapshot, or a single top-level field of it, as indented JSON
+func printSnapshot(snapshot generate.StateSnapshot, field string) error {
+	var value interface{} = snapshot
+	if field != "" {
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to marshal state: %w", err)}
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to decode state: %w", err)}
+		}
+
+		raw, ok := fields[field]
+		if !ok {
+			if !isStateField(field) {
+				return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("unknown state field %q (valid: %s)", field, strings.Join(generate.StateFields, ", "))}
+			}
+			raw = json.RawMessage("null") // Omitted because it is unset
+		}
+		value = raw
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to marshal state: %w", err)}
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func isStateField(name string) bool {
+	for _, f := range generate.StateFields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
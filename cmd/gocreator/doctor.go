@@ -0,0 +1,413 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorTimeout time.Duration
+	doctorOutput  string
+	doctorOffline bool
+)
+
+// doctorMinGoVersion is the oldest Go toolchain that builds generated projects
+const doctorMinGoVersion = "1.21"
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose environment and configuration problems",
+	Long: `Check that the environment and configuration can run GoCreator.
+
+Checks:
+  - Configuration file is readable and valid
+  - Go toolchain is installed and recent enough
+  - git and golangci-lint are available
+  - Output directory is writable
+  - LLM network settings (proxy URL, CA bundle, TLS options)
+  - Connectivity to the configured provider API through the proxy
+  - API key is present and accepted by the provider (a model listing, no tokens used)
+
+Each failed check prints a suggested fix. Use --offline to skip network checks.
+
+Exit codes:
+  0 - All checks passed (warnings allowed)
+  1 - One or more checks failed
+
+Example:
+  gocreator doctor
+  gocreator doctor --config ./corp.gocreator.yaml
+  gocreator doctor --output ./generated --offline`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func setupDoctorFlags() {
+	doctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", 30*time.Second, "timeout for network checks")
+	doctorCmd.Flags().StringVarP(&doctorOutput, "output", "o", "", "output directory to check for write access (default: project.output_dir or ./generated)")
+	doctorCmd.Flags().BoolVar(&doctorOffline, "offline", false, "skip checks that contact the LLM provider")
+}
+
+// doctorStatus is the outcome of a single diagnostic check
+type doctorStatus string
+
+const (
+	doctorOK   doctorStatus = "ok"
+	doctorWarn doctorStatus = "warn"
+	doctorFail doctorStatus = "fail"
+)
+
+// doctorResult describes a check outcome and how to fix it
+type doctorResult struct {
+	Status doctorStatus
+	Detail string
+	Fix    string
+}
+
+// doctorCheck is a named diagnostic
+type doctorCheck struct {
+	Name string
+	Run  func(ctx context.Context) doctorResult
+}
+
+// doctorChecks returns the diagnostics in the order they are printed
+func doctorChecks() []doctorCheck {
+	checks := []doctorCheck{
+		{Name: "Configuration", Run: checkConfiguration},
+		{Name: "Go toolchain", Run: checkGoToolchain},
+		{Name: "git", Run: checkGit},
+		{Name: "golangci-lint", Run: checkGolangciLint},
+		{Name: "Output directory", Run: checkOutputDir},
+		{Name: "LLM network settings", Run: checkNetworkSettings},
+	}
+	if !doctorOffline {
+		checks = append(checks, doctorCheck{Name: "Provider connectivity", Run: checkProviderConnectivity})
+	}
+	return append(checks, doctorCheck{Name: "API key", Run: checkAPIKey})
+}
+
+func runDoctor(cmd *cobra.Command, _ []string) error {
+	fmt.Printf("GoCreator v%s - Doctor\n\n", version)
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), doctorTimeout)
+	defer cancel()
+
+	failed := 0
+	for _, check := range doctorChecks() {
+		result := check.Run(ctx)
+		printDoctorResult(check.Name, result)
+		if result.Status == doctorFail {
+			failed++
+		}
+
+		log.Debug().
+			Str("check", check.Name).
+			Str("status", string(result.Status)).
+			Str("detail", result.Detail).
+			Msg("Doctor check completed")
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		fmt.Printf("%d check(s) failed\n", failed)
+		return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("doctor found %d failing check(s)", failed)}
+	}
+
+	fmt.Printf("All checks passed\n")
+	return nil
+}
+
+func printDoctorResult(name string, result doctorResult) {
+	symbol := "✓"
+	switch result.Status {
+	case doctorWarn:
+		symbol = "!"
+	case doctorFail:
+		symbol = "✗"
+	}
+
+	fmt.Printf("  %s %s: %s\n", symbol, name, result.Detail)
+	if result.Status != doctorOK && result.Fix != "" {
+		fmt.Printf("      fix: %s\n", result.Fix)
+	}
+}
+
+func checkNetworkSettings(_ context.Context) doctorResult {
+	network := networkConfig(cfg)
+	if network.IsZero() {
+		return doctorResult{Status: doctorOK, Detail: "defaults (proxy from HTTPS_PROXY/HTTP_PROXY)"}
+	}
+
+	if err := network.Validate(); err != nil {
+		return doctorResult{
+			Status: doctorFail,
+			Detail: err.Error(),
+			Fix:    "correct llm.network in your config file",
+		}
+	}
+
+	if network.InsecureSkipVerify {
+		return doctorResult{
+			Status: doctorWarn,
+			Detail: "TLS certificate verification is disabled",
+			Fix:    "set llm.network.ca_bundle to your corporate CA instead of insecure_skip_verify",
+		}
+	}
+
+	detail := "custom settings valid"
+	if network.ProxyURL != "" {
+		detail = fmt.Sprintf("proxy %s", network.ProxyURL)
+	}
+	return doctorResult{Status: doctorOK, Detail: detail}
+}
+
+func checkProviderConnectivity(ctx context.Context) doctorResult {
+	provider := llm.Provider(cfg.LLM.Provider)
+	check := func() error { return llm.CheckConnectivity(ctx, provider, networkConfig(cfg)) }
+	switch provider {
+	case llm.ProviderOpenAICompatible:
+		check = func() error {
+			return llm.CheckEndpoint(ctx, strings.TrimSuffix(cfg.LLM.BaseURL, "/")+"/models", networkConfig(cfg))
+		}
+	case llm.ProviderAzureOpenAI:
+		check = func() error { return llm.CheckEndpoint(ctx, cfg.LLM.BaseURL, networkConfig(cfg)) }
+	case llm.ProviderBedrock:
+		check = func() error {
+			endpoint, err := llm.BedrockEndpoint(llm.Config{BaseURL: cfg.LLM.BaseURL, Region: cfg.LLM.Region})
+			if err != nil {
+				return err
+			}
+			return llm.CheckEndpoint(ctx, endpoint, networkConfig(cfg))
+		}
+	}
+	if err := check(); err != nil {
+		return doctorResult{
+			Status: doctorFail,
+			Detail: err.Error(),
+			Fix:    "check llm.network.proxy_url and llm.network.ca_bundle, or the HTTPS_PROXY environment variable",
+		}
+	}
+
+	return doctorResult{Status: doctorOK, Detail: fmt.Sprintf("%s API reachable", provider)}
+}
+
+func checkConfiguration(_ context.Context) doctorResult {
+	source := cfg.Source
+	if source == "" {
+		source = "defaults (no .gocreator.yaml found)"
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fix := "fix the setting in your config file"
+		if cfg.Source != "" {
+			fix = fmt.Sprintf("fix the setting in %s", cfg.Source)
+		}
+		return doctorResult{Status: doctorFail, Detail: err.Error(), Fix: fix}
+	}
+
+	return doctorResult{Status: doctorOK, Detail: source}
+}
+
+func checkGoToolchain(ctx context.Context) doctorResult {
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		return doctorResult{
+			Status: doctorFail,
+			Detail: "go not found in PATH",
+			Fix:    "install Go from https://go.dev/dl/ and add it to PATH",
+		}
+	}
+
+	out, err := exec.CommandContext(ctx, goPath, "env", "GOVERSION").Output()
+	if err != nil {
+		return doctorResult{
+			Status: doctorFail,
+			Detail: fmt.Sprintf("failed to run go env: %v", err),
+			Fix:    "reinstall Go from https://go.dev/dl/",
+		}
+	}
+
+	goVersion := strings.TrimSpace(string(out))
+	if !goVersionAtLeast(goVersion, doctorMinGoVersion) {
+		return doctorResult{
+			Status: doctorFail,
+			Detail: fmt.Sprintf("%s is older than go%s", goVersion, doctorMinGoVersion),
+			Fix:    fmt.Sprintf("upgrade to Go %s or newer", doctorMinGoVersion),
+		}
+	}
+
+	return doctorResult{Status: doctorOK, Detail: goVersion}
+}
+
+func checkGit(_ context.Context) doctorResult {
+	if _, err := exec.LookPath("git"); err != nil {
+		return doctorResult{
+			Status: doctorWarn,
+			Detail: "git not found in PATH",
+			Fix:    "install git to version and diff generated projects",
+		}
+	}
+	return doctorResult{Status: doctorOK, Detail: "found"}
+}
+
+func checkGolangciLint(_ context.Context) doctorResult {
+	if _, err := exec.LookPath("golangci-lint"); err != nil {
+		return doctorResult{
+			Status: doctorWarn,
+			Detail: "not found in PATH, lint validation will be skipped",
+			Fix:    "install from https://golangci-lint.run/welcome/install/",
+		}
+	}
+	return doctorResult{Status: doctorOK, Detail: "found"}
+}
+
+func checkOutputDir(_ context.Context) doctorResult {
+	dir := doctorOutputDir()
+
+	existing, err := nearestExistingDir(dir)
+	if err != nil {
+		return doctorResult{
+			Status: doctorFail,
+			Detail: err.Error(),
+			Fix:    "choose a different --output directory",
+		}
+	}
+
+	probe, err := os.CreateTemp(existing, ".gocreator-doctor-*")
+	if err != nil {
+		return doctorResult{
+			Status: doctorFail,
+			Detail: fmt.Sprintf("%s is not writable: %v", existing, err),
+			Fix:    fmt.Sprintf("fix permissions on %s or choose a different --output directory", existing),
+		}
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+
+	return doctorResult{Status: doctorOK, Detail: fmt.Sprintf("%s is writable", dir)}
+}
+
+func checkAPIKey(ctx context.Context) doctorResult {
+	envVar := apiKeyEnvVars[cfg.LLM.Provider]
+	apiKey := resolveAPIKey(cfg)
+	provider := llm.Provider(cfg.LLM.Provider)
+	if !provider.RequiresAPIKey() {
+		switch {
+		case apiKey == "" && provider == llm.ProviderAzureOpenAI:
+			return doctorResult{Status: doctorOK, Detail: "not set, using the managed identity of the host"}
+		case apiKey == "" && provider == llm.ProviderBedrock:
+			return doctorResult{Status: doctorOK, Detail: "not set, signing with the AWS credentials of the environment or instance"}
+		case apiKey == "":
+			return doctorResult{Status: doctorOK, Detail: fmt.Sprintf("not required for %s", provider)}
+		}
+		return doctorResult{Status: doctorOK, Detail: fmt.Sprintf("present (not verified for %s)", provider)}
+	}
+	if apiKey == "" {
+		return doctorResult{
+			Status: doctorFail,
+			Detail: fmt.Sprintf("no API key configured for %s", cfg.LLM.Provider),
+			Fix:    fmt.Sprintf("set llm.api_key in your config or export %s", envVar),
+		}
+	}
+
+	if err := llm.ValidateAPIKey(provider, apiKey); err != nil {
+		return doctorResult{
+			Status: doctorFail,
+			Detail: err.Error(),
+			Fix:    "check that the whole key was copied",
+		}
+	}
+
+	if doctorOffline {
+		return doctorResult{Status: doctorOK, Detail: "present (not verified, --offline)"}
+	}
+
+	if err := llm.PingAPIKey(ctx, provider, apiKey, networkConfig(cfg)); err != nil {
+		if errors.Is(err, llm.ErrInvalidAPIKey) {
+			return doctorResult{
+				Status: doctorFail,
+				Detail: err.Error(),
+				Fix:    fmt.Sprintf("create a new key in the %s console and update llm.api_key or %s", provider, envVar),
+			}
+		}
+		return doctorResult{
+			Status: doctorWarn,
+			Detail: fmt.Sprintf("could not verify key: %v", err),
+			Fix:    "re-run doctor once the provider is reachable",
+		}
+	}
+
+	return doctorResult{Status: doctorOK, Detail: fmt.Sprintf("accepted by %s", provider)}
+}
+
+// doctorOutputDir returns the directory generation would write to. For
+// templated output directories the static prefix is checked.
+func doctorOutputDir() string {
+	dir := doctorOutput
+	if dir == "" {
+		dir = cfg.Project.OutputDir
+	}
+	if dir == "" {
+		dir = "./generated"
+	}
+
+	if idx := strings.Index(dir, "{{"); idx >= 0 {
+		dir = filepath.Dir(dir[:idx] + "x")
+	}
+	return filepath.Clean(dir)
+}
+
+// nearestExistingDir walks up from dir to the first path that exists
+func nearestExistingDir(dir string) (string, error) {
+	for current := dir; ; current = filepath.Dir(current) {
+		info, err := os.Stat(current)
+		if err == nil {
+			if !info.IsDir() {
+				return "", fmt.Errorf("%s exists and is not a directory", current)
+			}
+			return current, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("cannot access %s: %w", current, err)
+		}
+		if parent := filepath.Dir(current); parent == current {
+			return "", fmt.Errorf("no existing parent directory for %s", dir)
+		}
+	}
+}
+
+// goVersionAtLeast compares a "go1.24.1" style version against "major.minor"
+func goVersionAtLeast(goVersion, minimum string) bool {
+	have := parseMajorMinor(strings.TrimPrefix(goVersion, "go"))
+	want := parseMajorMinor(minimum)
+	if have[0] != want[0] {
+		return have[0] > want[0]
+	}
+	return have[1] >= want[1]
+}
+
+// parseMajorMinor extracts the major and minor numbers from a version string
+func parseMajorMinor(version string) [2]int {
+	var result [2]int
+	parts := strings.SplitN(version, ".", 3)
+	for i := 0; i < len(parts) && i < 2; i++ {
+		// Cut pre-release suffixes such as "25rc1" at the first non-digit
+		digits := parts[i]
+		if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+			digits = digits[:end]
+		}
+		result[i], _ = strconv.Atoi(digits)
+	}
+	return result
+}
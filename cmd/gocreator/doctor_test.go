@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMajorMinor(t *testing.T) {
+	tests := []struct {
+		version string
+		want    [2]int
+	}{
+		{"1.24.3", [2]int{1, 24}},
+		{"1.22", [2]int{1, 22}},
+		{"1", [2]int{1, 0}},
+		{"1.25rc1", [2]int{1, 25}},
+		{"1.23beta2", [2]int{1, 23}},
+		{"1.21.0-rc.2", [2]int{1, 21}},
+		{"", [2]int{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseMajorMinor(tt.version))
+		})
+	}
+}
+
+func TestGoVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		goVersion, minimum string
+		want               bool
+	}{
+		{"go1.24.3", "1.21", true},
+		{"go1.21", "1.21", true},
+		{"go1.20.14", "1.21", false},
+		{"go1.25rc1", "1.21", true},
+		{"go1.21beta1", "1.21", true},
+		{"go1.20rc3", "1.21", false},
+		{"go2.0", "1.21", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goVersion, func(t *testing.T) {
+			assert.Equal(t, tt.want, goVersionAtLeast(tt.goVersion, tt.minimum))
+		})
+	}
+}
@@ -1,27 +1,32 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/dshills/gocreator/internal/clarify"
-	"github.com/dshills/gocreator/internal/spec"
+	"github.com/dshills/gocreator/internal/fcsdump"
+	"github.com/dshills/gocreator/internal/models"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
 var (
-	dumpFCSOutput string
-	dumpFCSBatch  string
-	dumpFCSPretty bool
+	dumpFCSOutput   string
+	dumpFCSBatch    string
+	dumpFCSPretty   bool
+	dumpFCSSections []string
+	dumpFCSFormat   string
+	dumpFCSRedact   bool
+	dumpFCSFromFCS  bool
 )
 
 var dumpFCSCmd = &cobra.Command{
 	Use:   "dump-fcs <spec-file>",
-	Short: "Output Final Clarified Specification as JSON",
-	Long: `Generate and output the Final Clarified Specification (FCS) as JSON.
+	Short: "Output the Final Clarified Specification",
+	Long: `Generate and output the Final Clarified Specification (FCS).
 
 This command runs the clarification phase and outputs the resulting FCS
 without generating any code. Useful for:
@@ -40,6 +45,14 @@ Output:
   By default, outputs to stdout (can be redirected)
   Use --output to write to a file instead
 
+  --section limits output to one or more sections: metadata, requirements,
+  architecture, data_model, api_contracts, cross_cutting, contracts,
+  testing_strategy, build_config, file_tree.
+  --format selects json (default), yaml, markdown or table.
+  --redact replaces descriptions, purposes, clarification answers and the
+  original spec text with [REDACTED], keeping IDs, names and types.
+  --fcs renders an existing FCS JSON file instead of running clarification.
+
 Example:
   # Output to stdout
   gocreator dump-fcs ./my-project-spec.yaml
@@ -51,7 +64,13 @@ Example:
   gocreator dump-fcs ./my-project-spec.yaml --pretty=false
 
   # Batch mode
-  gocreator dump-fcs ./my-project-spec.yaml --batch ./answers.json`,
+  gocreator dump-fcs ./my-project-spec.yaml --batch ./answers.json
+
+  # Review the data model of an existing FCS as a Markdown table
+  gocreator dump-fcs .gocreator/fcs.json --fcs --section data_model --format markdown
+
+  # Share requirements without their descriptions
+  gocreator dump-fcs ./my-project-spec.yaml --section requirements --format yaml --redact`,
 	Args: cobra.ExactArgs(1),
 	RunE: runDumpFCS,
 }
@@ -60,22 +79,82 @@ func setupDumpFCSFlags() {
 	dumpFCSCmd.Flags().StringVarP(&dumpFCSOutput, "output", "o", "", "output file path (default: stdout)")
 	dumpFCSCmd.Flags().StringVar(&dumpFCSBatch, "batch", "", "path to JSON file with pre-answered questions")
 	dumpFCSCmd.Flags().BoolVar(&dumpFCSPretty, "pretty", true, "pretty-print JSON")
+	dumpFCSCmd.Flags().StringSliceVar(&dumpFCSSections, "section", nil, "only output these sections (repeatable or comma-separated)")
+	dumpFCSCmd.Flags().StringVar(&dumpFCSFormat, "format", "json", "output format: json, yaml, markdown, or table")
+	dumpFCSCmd.Flags().BoolVar(&dumpFCSRedact, "redact", false, "replace free-text descriptions with [REDACTED]")
+	dumpFCSCmd.Flags().BoolVar(&dumpFCSFromFCS, "fcs", false, "read an existing FCS JSON file instead of a spec")
 }
 
-func runDumpFCS(_ *cobra.Command, args []string) error {
+func runDumpFCS(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
 	specFile := args[0]
 
 	log.Info().
 		Str("spec_file", specFile).
 		Str("output", dumpFCSOutput).
-		Bool("pretty", dumpFCSPretty).
+		Str("format", dumpFCSFormat).
+		Strs("sections", dumpFCSSections).
+		Bool("redact", dumpFCSRedact).
 		Msg("Dumping FCS")
 
+	// Check the rendering flags before spending time on clarification
+	outputFormat, err := fcsdump.ParseFormat(dumpFCSFormat)
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+	if _, err := fcsdump.ParseSections(dumpFCSSections); err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+
+	var fcs *models.FinalClarifiedSpecification
+	if dumpFCSFromFCS {
+		fcs, err = readFCS(specFile)
+		if err != nil {
+			return ExitError{Code: ExitCodeSpecError, Err: err}
+		}
+	} else {
+		fcs, err = clarifySpecFile(ctx, specFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	var rendered bytes.Buffer
+	if err := fcsdump.Render(&rendered, fcs, fcsdump.Options{
+		Sections: dumpFCSSections,
+		Format:   outputFormat,
+		Pretty:   dumpFCSPretty,
+		Redact:   dumpFCSRedact,
+	}); err != nil {
+		log.Error().Err(err).Msg("Failed to render FCS")
+		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to render FCS: %w", err)}
+	}
+
+	// Output FCS
+	if dumpFCSOutput != "" {
+		// Write to file
+		if err := os.WriteFile(dumpFCSOutput, rendered.Bytes(), 0o600); err != nil {
+			log.Error().Err(err).Msg("Failed to write FCS file")
+			return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to write FCS file: %w", err)}
+		}
+		fmt.Printf("FCS written to: %s\n", dumpFCSOutput)
+		log.Info().Str("output", dumpFCSOutput).Msg("FCS dumped to file")
+	} else {
+		// Write to stdout
+		fmt.Print(rendered.String())
+		log.Info().Msg("FCS dumped to stdout")
+	}
+
+	return nil
+}
+
+// clarifySpecFile parses a spec file and runs clarification on it
+func clarifySpecFile(ctx context.Context, specFile string) (*models.FinalClarifiedSpecification, error) {
 	// Detect format
 	format, err := detectSpecFormat(specFile)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to detect spec format")
-		return ExitError{Code: ExitCodeSpecError, Err: err}
+		return nil, ExitError{Code: ExitCodeSpecError, Err: err}
 	}
 
 	// Read spec file
@@ -83,14 +162,14 @@ func runDumpFCS(_ *cobra.Command, args []string) error {
 	content, err := os.ReadFile(specFile)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to read spec file")
-		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to read spec file: %w", err)}
+		return nil, ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to read spec file: %w", err)}
 	}
 
 	// Parse and validate
-	inputSpec, err := spec.ParseAndValidate(format, string(content))
+	inputSpec, err := parseSpec(specFile, format, string(content))
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to parse specification")
-		return ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("specification validation failed: %w", err)}
+		return nil, ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("specification validation failed: %w", err)}
 	}
 
 	log.Info().
@@ -102,7 +181,7 @@ func runDumpFCS(_ *cobra.Command, args []string) error {
 	llmClient, err := createLLMClient(cfg)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create LLM client")
-		return ExitError{Code: ExitCodeNetworkError, Err: fmt.Errorf("failed to create LLM client: %w", err)}
+		return nil, ExitError{Code: ExitCodeNetworkError, Err: fmt.Errorf("failed to create LLM client: %w", err)}
 	}
 
 	// Create clarification engine
@@ -111,46 +190,17 @@ func runDumpFCS(_ *cobra.Command, args []string) error {
 	})
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create clarification engine")
-		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create clarification engine: %w", err)}
+		return nil, ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create clarification engine: %w", err)}
 	}
 
 	// Determine interactive mode
 	interactive := dumpFCSBatch == ""
 
 	// Run clarification
-	ctx := context.Background()
-	fcs, err := engine.Clarify(ctx, inputSpec, interactive)
+	fcs, err := clarifySpec(ctx, engine, inputSpec, interactive)
 	if err != nil {
 		log.Error().Err(err).Msg("Clarification failed")
-		return ExitError{Code: ExitCodeClarificationError, Err: fmt.Errorf("clarification failed: %w", err)}
+		return nil, ExitError{Code: ExitCodeClarificationError, Err: fmt.Errorf("clarification failed: %w", err)}
 	}
-
-	// Marshal FCS to JSON
-	var jsonData []byte
-	if dumpFCSPretty {
-		jsonData, err = json.MarshalIndent(fcs, "", "  ")
-	} else {
-		jsonData, err = json.Marshal(fcs)
-	}
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to marshal FCS")
-		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to marshal FCS: %w", err)}
-	}
-
-	// Output FCS
-	if dumpFCSOutput != "" {
-		// Write to file
-		if err := os.WriteFile(dumpFCSOutput, jsonData, 0o600); err != nil {
-			log.Error().Err(err).Msg("Failed to write FCS file")
-			return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to write FCS file: %w", err)}
-		}
-		fmt.Printf("FCS written to: %s\n", dumpFCSOutput)
-		log.Info().Str("output", dumpFCSOutput).Msg("FCS dumped to file")
-	} else {
-		// Write to stdout
-		fmt.Println(string(jsonData))
-		log.Info().Msg("FCS dumped to stdout")
-	}
-
-	return nil
+	return fcs, nil
 }
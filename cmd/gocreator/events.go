@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dshills/gocreator/internal/eventsink"
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// eventSinks creates the sinks configured under events for a run that
+// generates into outputDir. The file sink's path is relative to outputDir.
+func eventSinks(outputDir string) ([]models.EventSink, error) {
+	events := cfg.Events
+	var sinks []models.EventSink
+
+	if events.File.Path != "" {
+		path := events.File.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(outputDir, path)
+		}
+		sink, err := eventsink.NewFileSink(path, eventTypes(events.File.Types))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if events.Webhook.URL != "" {
+		sink, err := eventsink.NewWebhookSink(eventsink.WebhookConfig{
+			URL:     events.Webhook.URL,
+			Types:   eventTypes(events.Webhook.Types),
+			Headers: expandHeaders(events.Webhook.Headers),
+			Timeout: events.Webhook.Timeout,
+			Source:  outputDir,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("events.webhook: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if events.OTLP.Endpoint != "" {
+		sink, err := eventsink.NewOTLPSink(eventsink.OTLPConfig{
+			Endpoint:       events.OTLP.Endpoint,
+			ServiceName:    events.OTLP.ServiceName,
+			ServiceVersion: version,
+			Headers:        expandHeaders(events.OTLP.Headers),
+			Timeout:        events.OTLP.Timeout,
+			Attributes: map[string]string{
+				"gocreator.output_dir": outputDir,
+				"gocreator.provider":   cfg.LLM.Provider,
+				"gocreator.model":      cfg.LLM.Model,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("events.otlp: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	return sinks, nil
+}
+
+// eventTypes converts configured event type names
+func eventTypes(names []string) []models.EventType {
+	types := make([]models.EventType, 0, len(names))
+	for _, name := range names {
+		types = append(types, models.EventType(name))
+	}
+	return types
+}
+
+// expandHeaders expands ${VAR} in header values, so secrets stay out of the
+// config file
+func expandHeaders(headers map[string]string) map[string]string {
+	expanded := make(map[string]string, len(headers))
+	for name, value := range headers {
+		expanded[name] = os.ExpandEnv(value)
+	}
+	return expanded
+}
+
+// eventForwarder is a sink that passes events on to a channel
+type eventForwarder chan<- models.ProgressEvent
+
+// HandleEvent implements models.EventSink
+func (f eventForwarder) HandleEvent(event models.ProgressEvent) {
+	f <- event
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/fcsedit"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/schema"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fcsAddEntityPackage string
+	fcsAddEntityAttrs   []string
+
+	fcsAddRequirementID        string
+	fcsAddRequirementPriority  string
+	fcsAddRequirementCategory  string
+	fcsAddRequirementType      string
+	fcsAddRequirementThreshold string
+)
+
+var fcsCmd = &cobra.Command{
+	Use:   "fcs",
+	Short: "Edit an FCS file without breaking its structure",
+	Long: `Edit a Final Clarified Specification (FCS) JSON file safely.
+
+Every edit is checked against the FCS JSON Schema and against the structure
+change detection relies on: requirement IDs and entity, enum and package
+names must be unique, entities must live in declared packages, packages may
+only depend on declared packages and not in a cycle, relationships must join
+known entities, and attribute types must be Go types or entities and enums
+of the data model. An edit introducing such a problem is refused and the
+file is left unchanged; problems the file already had do not block edits.
+An accepted edit updates metadata.updated_at and recomputes metadata.hash.`,
+}
+
+var fcsEditCmd = &cobra.Command{
+	Use:   "edit <file>",
+	Short: "Edit an FCS file in $EDITOR and check the result",
+	Long: `Open a copy of an FCS file in $VISUAL or $EDITOR (vi when neither is
+set) and replace the file with it once the editor exits, if the edit passes
+the FCS checks. A refused edit is kept in a temporary file, whose path is
+printed, so it can be fixed and retried.
+
+Example:
+  EDITOR="code --wait" gocreator fcs edit ./fcs.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFCSEdit,
+}
+
+var fcsSetCmd = &cobra.Command{
+	Use:   "set <file> <path> <value>",
+	Short: "Set one value of an FCS file",
+	Long: `Set the value at a path of an FCS file. The path uses the JSON keys of
+the FCS; a segment in brackets selects a list item by its id, name or path,
+by its method and endpoint, or by its index. Strings are set as given; other
+values are read as JSON or YAML.
+
+Example:
+  gocreator fcs set ./fcs.json build_config.persistence sqlc
+  gocreator fcs set ./fcs.json requirements.functional[FR-003].priority high
+  gocreator fcs set ./fcs.json architecture.packages[api].dependencies '["models", "service"]'
+  gocreator fcs set ./fcs.json data_model.entities[User].attributes.role Role
+  gocreator fcs set ./fcs.json metadata.conflicts[conflict-1].resolution gin`,
+	Args: cobra.ExactArgs(3),
+	RunE: runFCSSet,
+}
+
+var fcsAddEntityCmd = &cobra.Command{
+	Use:   "add-entity <file> <name>",
+	Short: "Add an entity to the data model of an FCS file",
+	Long: `Add an entity to the data model of an FCS file. The name must not be
+taken by another entity or an enum, the package must be declared in the
+architecture, and attribute types must be Go types or entities and enums of
+the data model.
+
+Example:
+  gocreator fcs add-entity ./fcs.json Order --package models \
+    --attr id=uuid.UUID --attr buyer=*User --attr status=OrderStatus`,
+	Args: cobra.ExactArgs(2),
+	RunE: runFCSAddEntity,
+}
+
+var fcsAddRequirementCmd = &cobra.Command{
+	Use:   "add-requirement <file> <description>",
+	Short: "Add a requirement to an FCS file",
+	Long: `Add a functional requirement to an FCS file, or a non-functional one
+with --type. Without --id the requirement takes the next free FR- or NFR-
+ID; an ID used by any other requirement is refused.
+
+Example:
+  gocreator fcs add-requirement ./fcs.json "Customers cancel unpaid orders" --priority high
+  gocreator fcs add-requirement ./fcs.json "Checkout responds quickly" --type performance --threshold "p99 < 200ms"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runFCSAddRequirement,
+}
+
+func setupFCSFlags() {
+	fcsAddEntityCmd.Flags().StringVar(&fcsAddEntityPackage, "package", "", "package the entity lives in")
+	fcsAddEntityCmd.Flags().StringArrayVar(&fcsAddEntityAttrs, "attr", nil, "attribute as name=type (repeatable)")
+
+	fcsAddRequirementCmd.Flags().StringVar(&fcsAddRequirementID, "id", "", "requirement ID (default: next free FR- or NFR- ID)")
+	fcsAddRequirementCmd.Flags().StringVar(&fcsAddRequirementPriority, "priority", "", "priority of a functional requirement")
+	fcsAddRequirementCmd.Flags().StringVar(&fcsAddRequirementCategory, "category", "", "category of a functional requirement")
+	fcsAddRequirementCmd.Flags().StringVar(&fcsAddRequirementType, "type", "", "add a non-functional requirement of this type (e.g. performance, security)")
+	fcsAddRequirementCmd.Flags().StringVar(&fcsAddRequirementThreshold, "threshold", "", "threshold of a non-functional requirement")
+
+	fcsCmd.AddCommand(fcsEditCmd)
+	fcsCmd.AddCommand(fcsSetCmd)
+	fcsCmd.AddCommand(fcsAddEntityCmd)
+	fcsCmd.AddCommand(fcsAddRequirementCmd)
+}
+
+func runFCSEdit(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	fcs, err := readFCS(path)
+	if err != nil {
+		return ExitError{Code: ExitCodeSpecError, Err: err}
+	}
+	original, err := json.MarshalIndent(fcs, "", "  ")
+	if err != nil {
+		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to marshal FCS: %w", err)}
+	}
+
+	tmp, err := os.CreateTemp("", "gocreator-fcs-*.json")
+	if err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to create temporary file: %w", err)}
+	}
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to write temporary file: %w", err)}
+	}
+
+	editor := editorCommand()
+	//nolint:gosec // G204: Subprocess launched with the user's editor - required to edit the FCS
+	editCmd := exec.CommandContext(cmd.Context(), editor[0], append(editor[1:], tmp.Name())...)
+	editCmd.Stdin, editCmd.Stdout, editCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editCmd.Run(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("editor %s failed: %w", strings.Join(editor, " "), err)}
+	}
+
+	//nolint:gosec // G304: Reading the temporary copy the user edited
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to read edited FCS: %w", err)}
+	}
+	if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(original)) {
+		_ = os.Remove(tmp.Name())
+		fmt.Printf("No changes made to %s\n", path)
+		return nil
+	}
+
+	updated, err := fcsedit.Replace(fcs, edited)
+	if err != nil {
+		fmt.Printf("Your edit is kept in %s\n", tmp.Name())
+		return editRefused(path, err)
+	}
+	_ = os.Remove(tmp.Name())
+	return writeEditedFCS(path, updated, "edited")
+}
+
+func runFCSSet(_ *cobra.Command, args []string) error {
+	path, field, value := args[0], args[1], args[2]
+	fcs, err := readFCS(path)
+	if err != nil {
+		return ExitError{Code: ExitCodeSpecError, Err: err}
+	}
+
+	updated, err := fcsedit.Set(fcs, field, value)
+	if err != nil {
+		return editRefused(path, err)
+	}
+	return writeEditedFCS(path, updated, fmt.Sprintf("set %s", field))
+}
+
+func runFCSAddEntity(_ *cobra.Command, args []string) error {
+	path := args[0]
+	entity := models.Entity{Name: args[1], Package: fcsAddEntityPackage, Attributes: make(map[string]string, len(fcsAddEntityAttrs))}
+	for _, attr := range fcsAddEntityAttrs {
+		name, typ, ok := strings.Cut(attr, "=")
+		name, typ = strings.TrimSpace(name), strings.TrimSpace(typ)
+		if !ok || name == "" || typ == "" {
+			return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("invalid --attr %q: expected name=type", attr)}
+		}
+		entity.Attributes[name] = typ
+	}
+
+	fcs, err := readFCS(path)
+	if err != nil {
+		return ExitError{Code: ExitCodeSpecError, Err: err}
+	}
+	updated, err := fcsedit.AddEntity(fcs, entity)
+	if err != nil {
+		return editRefused(path, err)
+	}
+	return writeEditedFCS(path, updated, fmt.Sprintf("added entity %s", entity.Name))
+}
+
+func runFCSAddRequirement(_ *cobra.Command, args []string) error {
+	path, description := args[0], args[1]
+	nonFunctional := fcsAddRequirementType != "" || fcsAddRequirementThreshold != ""
+	if nonFunctional && (fcsAddRequirementPriority != "" || fcsAddRequirementCategory != "") {
+		return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("--priority and --category apply to functional requirements, --type and --threshold to non-functional ones")}
+	}
+
+	fcs, err := readFCS(path)
+	if err != nil {
+		return ExitError{Code: ExitCodeSpecError, Err: err}
+	}
+
+	var updated *models.FinalClarifiedSpecification
+	var id string
+	if nonFunctional {
+		req := models.NonFunctionalRequirement{ID: fcsAddRequirementID, Description: description, Type: fcsAddRequirementType, Threshold: fcsAddRequirementThreshold}
+		updated, err = fcsedit.AddNonFunctionalRequirement(fcs, req)
+		if err == nil {
+			id = updated.Requirements.NonFunctional[len(updated.Requirements.NonFunctional)-1].ID
+		}
+	} else {
+		req := models.FunctionalRequirement{ID: fcsAddRequirementID, Description: description, Priority: fcsAddRequirementPriority, Category: fcsAddRequirementCategory}
+		updated, err = fcsedit.AddFunctionalRequirement(fcs, req)
+		if err == nil {
+			id = updated.Requirements.Functional[len(updated.Requirements.Functional)-1].ID
+		}
+	}
+	if err != nil {
+		return editRefused(path, err)
+	}
+	return writeEditedFCS(path, updated, fmt.Sprintf("added requirement %s", id))
+}
+
+// editorCommand returns the command editing a file: $VISUAL, $EDITOR or vi,
+// split into the program and its arguments
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// editRefused reports why an edit of the FCS at path was refused, listing
+// the problems it introduces or the schema violations
+func editRefused(path string, err error) error {
+	var problems *fcsedit.Error
+	if errors.As(err, &problems) {
+		fmt.Printf("✗ Edit of %s refused; it introduces:\n", path)
+		for _, problem := range problems.Problems {
+			fmt.Printf("  %s\n", problem)
+		}
+		return ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("%s: edit introduces %s", path, countNoun(len(problems.Problems), "problem"))}
+	}
+	var invalid *schema.ValidationError
+	if errors.As(err, &invalid) {
+		fmt.Printf("✗ Edit of %s refused; it does not match the FCS schema:\n", path)
+		for _, v := range invalid.Violations {
+			fmt.Printf("  %s\n", v)
+		}
+		return ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("%s: %s of the FCS schema", path, countNoun(len(invalid.Violations), "violation"))}
+	}
+	return ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("%s: %w", path, err)}
+}
+
+// writeEditedFCS writes an accepted edit and reports what changed
+func writeEditedFCS(path string, fcs *models.FinalClarifiedSpecification, change string) error {
+	if err := fcsedit.Write(path, fcs); err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+
+	fmt.Printf("✓ %s: %s\n", path, change)
+	fmt.Printf("  Hash: %s\n", fcs.Metadata.Hash)
+	if problems := fcsedit.Problems(fcs); len(problems) > 0 {
+		fmt.Printf("  %s left from before:\n", countNoun(len(problems), "problem"))
+		for _, problem := range problems {
+			fmt.Printf("    %s\n", problem)
+		}
+	}
+	log.Info().Str("file", path).Str("change", change).Str("hash", fcs.Metadata.Hash).Msg("FCS edited")
+	return nil
+}
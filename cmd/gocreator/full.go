@@ -5,21 +5,38 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/dshills/gocreator/internal/clarify"
+	"github.com/dshills/gocreator/internal/generate"
 	"github.com/dshills/gocreator/internal/models"
-	"github.com/dshills/gocreator/internal/spec"
 	"github.com/dshills/gocreator/internal/validate"
+	"github.com/dshills/gocreator/pkg/fsops"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
 var (
-	fullOutput string
-	fullBatch  string
-	fullResume bool
-	fullReport string
+	fullOutput     string
+	fullBatch      string
+	fullResume     bool
+	fullReport     string
+	fullSmoke      bool
+	fullFuzz       bool
+	fullBuildFiles bool
+	fullContracts  string
+
+	fullRepairAttempts     int
+	fullRepairTests        bool
+	fullTestRepairAttempts int
+	fullRepairLint         bool
+	fullLintRepairAttempts int
+
+	fullApprove         []string
+	fullApprovalTimeout time.Duration
+	fullApprovalDefault string
 )
 
 var fullCmd = &cobra.Command{
@@ -32,14 +49,45 @@ The full pipeline includes:
   2. Planning: Creates architecture plan and file structure
   3. Code Generation: Generates complete project structure
   4. Finalization: Creates build files, documentation, and metadata
-  5. Validation: Validates generated code (build, lint, test)
+  5. Validation: Validates generated code (build, lint, test, requirement
+     coverage, enum usage, and optionally a smoke run of the built executable
+     a fuzz run of the generated fuzz targets and checks of the generated
+     Dockerfile and Makefile)
 
 This is the recommended command for end-to-end code generation.
 
 Options:
   --batch       Use pre-answered questions from JSON file
-  --resume      Resume from last checkpoint if available
+  --resume      Resume from the last finished phase (clarify, generate or
+                validate) recorded in <output>/.gocreator/pipeline.json
   --report PATH Output validation report to JSON file
+  --smoke       Build and run the generated executable after validation
+  --fuzz        Run each fuzz target briefly after validation (always on
+                when the spec sets testing_strategy.fuzz_tests)
+  --build-files Check the generated Dockerfile and Makefile after validation
+  --contracts FILE
+                Also check the output contracts in this YAML or JSON file,
+                as validate --contracts does
+  --repair-attempts N
+                Regenerate files that fail to compile with their errors and
+                rebuild, up to N times (default: validation.max_repair_attempts)
+  --repair-lint
+                Regenerate files golangci-lint reports issues in, with the
+                issues, and lint again, up to
+                validation.max_lint_repair_attempts times
+  --repair-tests
+                Regenerate failing tests, or the code they test, with the
+                failure output and rerun them, up to
+                validation.max_test_repair_attempts times
+  --approve CHECKPOINTS
+                Pause for approval after clarify and/or plan, showing a
+                summary of the FCS or the plan
+  --approval-timeout DURATION
+                Wait this long for an answer before taking the default
+                action (0 waits indefinitely)
+  --approval-default ACTION
+                continue or abort when nobody answers; runs without a
+                terminal on stdin take it at once
 
 Example:
   # Full pipeline
@@ -48,6 +96,12 @@ Example:
   # Specify output directory
   gocreator full ./my-project-spec.yaml --output ./my-project
 
+  # Resume after a crash without clarifying again
+  gocreator full ./my-project-spec.yaml --output ./my-project --resume
+
+  # Review the plan before code is generated, aborting if unattended
+  gocreator full ./my-project-spec.yaml --approve plan --approval-default abort
+
   # Batch mode with validation report
   gocreator full ./my-project-spec.yaml --batch ./answers.json --report ./validation.json`,
 	Args: cobra.ExactArgs(1),
@@ -57,11 +111,21 @@ Example:
 func setupFullFlags() {
 	fullCmd.Flags().StringVarP(&fullOutput, "output", "o", "./generated", "output directory")
 	fullCmd.Flags().StringVar(&fullBatch, "batch", "", "path to JSON file with pre-answered questions")
-	fullCmd.Flags().BoolVar(&fullResume, "resume", false, "resume from last checkpoint")
+	fullCmd.Flags().BoolVar(&fullResume, "resume", false, "resume from the last finished pipeline phase")
 	fullCmd.Flags().StringVarP(&fullReport, "report", "r", "", "output validation report to file")
+	fullCmd.Flags().BoolVar(&fullSmoke, "smoke", false, "build and run the executable (default: validation.smoke.enabled)")
+	fullCmd.Flags().BoolVar(&fullFuzz, "fuzz", false, "run each fuzz target with go test -fuzz (default: validation.fuzz.enabled)")
+	fullCmd.Flags().StringVar(&fullContracts, "contracts", "", "YAML or JSON file with output contracts to check in addition to the FCS contracts")
+	fullCmd.Flags().BoolVar(&fullBuildFiles, "build-files", false, "check the Dockerfile and Makefile with docker (or hadolint) and make -n (default: validation.build_files.enabled)")
+	fullCmd.Flags().IntVar(&fullRepairAttempts, "repair-attempts", 0, "regenerate files that fail to compile with their errors, rebuilding up to this many times; 0 disables (default: validation.max_repair_attempts)")
+	fullCmd.Flags().BoolVar(&fullRepairLint, "repair-lint", false, "regenerate files golangci-lint reports issues in, with the issues (default: validation.repair_lint)")
+	fullCmd.Flags().BoolVar(&fullRepairTests, "repair-tests", false, "regenerate failing tests, or the code they test, with the failure output (default: validation.repair_tests)")
+	fullCmd.Flags().StringSliceVar(&fullApprove, "approve", nil, "pause for approval at these checkpoints: clarify, plan (default: workflow.approval.checkpoints)")
+	fullCmd.Flags().DurationVar(&fullApprovalTimeout, "approval-timeout", 0, "wait for an answer before taking the default action, 0 waits indefinitely (default: workflow.approval.timeout)")
+	fullCmd.Flags().StringVar(&fullApprovalDefault, "approval-default", "", "action without an answer: continue or abort (default: workflow.approval.default)")
 }
 
-func runFull(_ *cobra.Command, args []string) error {
+func runFull(cmd *cobra.Command, args []string) error {
 	specFile := args[0]
 
 	log.Info().
@@ -74,45 +138,92 @@ func runFull(_ *cobra.Command, args []string) error {
 
 	startTime := time.Now()
 
-	// Phase 1: Clarification
-	fmt.Printf("=== Phase 1: Clarification ===\n\n")
-	fcs, err := runFullClarification(specFile, fullBatch)
+	//nolint:gosec // G304: Reading user-provided spec file - required for CLI functionality
+	specContent, err := os.ReadFile(specFile)
 	if err != nil {
-		return err
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to read spec file: %w", err)}
 	}
-	fmt.Printf("  ✓ Specification analyzed\n")
-	fmt.Printf("  ✓ FCS constructed\n\n")
 
-	// Phase 2: Planning
-	fmt.Printf("=== Phase 2: Planning ===\n\n")
-	plan, err := runPlanningPhase(fcs)
+	checkpoints, err := newFullCheckpoints(cmd)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("  ✓ Architecture planned (%d packages)\n", len(plan.Packages))
-	fmt.Printf("  ✓ File tree generated (%d files)\n\n", len(plan.Files))
 
-	// Phase 3: Code Generation
-	fmt.Printf("=== Phase 3: Code Generation ===\n\n")
-	if err := runCodeGeneration(plan, fullOutput, false); err != nil {
+	state, fcs, err := loadFullPipelineState(cmd, specContent)
+	if err != nil {
 		return err
 	}
-	fmt.Printf("  ✓ Code generation complete\n\n")
+	if state == nil {
+		state = generate.NewPipelineState(specFile, specContent)
+	} else {
+		fmt.Printf("Resuming from %s (previous run finished %s)\n\n", state.ResumePhase(), state.LastCompleted)
+	}
 
-	// Phase 4: Finalization
-	fmt.Printf("=== Phase 4: Finalization ===\n\n")
-	if err := runFinalization(fullOutput, false); err != nil {
+	// Phase 1: Clarification
+	fmt.Printf("=== Phase 1: Clarification ===\n\n")
+	clarified := fcs == nil
+	if fcs != nil {
+		fmt.Printf("  ✓ FCS loaded from previous run\n\n")
+	} else {
+		fcs, err = runFullClarification(cmd.Context(), specFile, fullBatch)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  ✓ Specification analyzed\n")
+		fmt.Printf("  ✓ FCS constructed\n\n")
+	}
+
+	// Resolve the output directory template now that the project is known
+	fullOutput, err = resolveOutputDir(fullOutput, cmd.Flags().Changed("output"), fcs)
+	if err != nil {
 		return err
 	}
-	fmt.Printf("  ✓ Build files created\n")
-	fmt.Printf("  ✓ Documentation generated\n\n")
+
+	if !state.Done(generate.PipelinePhaseClarify) {
+		if err := saveFullClarification(state, fullOutput, fcs); err != nil {
+			return err
+		}
+	}
+
+	// A clarification approved in an earlier run is not asked about again
+	if clarified {
+		if err := checkpoints.approve(cmd.Context(), "clarify", summarizeFCS(fcs)); err != nil {
+			return err
+		}
+	}
+
+	// Phases 2-4 make up the generate macro-phase
+	if state.Done(generate.PipelinePhaseGenerate) {
+		fmt.Printf("=== Phases 2-4: Generation ===\n\n")
+		fmt.Printf("  ✓ Completed in previous run\n\n")
+	} else {
+		if err := runFullGeneration(cmd.Context(), fcs, checkpoints); err != nil {
+			return err
+		}
+		state.Complete(generate.PipelinePhaseGenerate)
+		if err := saveFullPipelineState(state, fullOutput); err != nil {
+			return err
+		}
+	}
 
 	// Phase 5: Validation
+	if !cmd.Flags().Changed("repair-attempts") {
+		fullRepairAttempts = cfg.Validation.MaxRepairAttempts
+	}
+	fullRepairTests = fullRepairTests || cfg.Validation.RepairTests
+	fullTestRepairAttempts = cfg.Validation.MaxTestRepairAttempts
+	fullRepairLint = fullRepairLint || cfg.Validation.RepairLint
+	fullLintRepairAttempts = cfg.Validation.MaxLintRepairAttempts
 	fmt.Printf("=== Phase 5: Validation ===\n\n")
-	validationPassed, err := runFullValidation(fullOutput, fullReport)
+	validationPassed, err := runFullValidation(cmd.Context(), fullOutput, fullReport, fcs)
 	if err != nil {
 		// Don't return error - validation failure shouldn't fail the entire pipeline
 		log.Warn().Err(err).Msg("Validation phase had failures")
+	} else {
+		state.Complete(generate.PipelinePhaseValidate)
+		if err := saveFullPipelineState(state, fullOutput); err != nil {
+			return err
+		}
 	}
 
 	duration := time.Since(startTime)
@@ -143,7 +254,119 @@ func runFull(_ *cobra.Command, args []string) error {
 	return nil
 }
 
-func runFullClarification(specFile, batchFile string) (*models.FinalClarifiedSpecification, error) {
+// runFullGeneration runs the planning, code generation and finalization
+// phases, pausing at the plan checkpoint
+func runFullGeneration(ctx context.Context, fcs *models.FinalClarifiedSpecification, checkpoints *fullCheckpoints) error {
+	// Phase 2: Planning
+	fmt.Printf("=== Phase 2: Planning ===\n\n")
+	plan, err := runPlanningPhase(fcs)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("  ✓ Architecture planned (%d packages)\n", len(plan.Packages))
+	fmt.Printf("  ✓ File tree generated (%d files)\n\n", len(plan.Files))
+
+	if err := checkpoints.approve(ctx, "plan", summarizeGenerationPlan(plan)); err != nil {
+		return err
+	}
+
+	// Phase 3: Code Generation
+	fmt.Printf("=== Phase 3: Code Generation ===\n\n")
+	if err := runCodeGeneration(plan, fullOutput, false); err != nil {
+		return err
+	}
+	fmt.Printf("  ✓ Code generation complete\n\n")
+
+	// Phase 4: Finalization
+	fmt.Printf("=== Phase 4: Finalization ===\n\n")
+	if err := runFinalization(fullOutput, false); err != nil {
+		return err
+	}
+	fmt.Printf("  ✓ Build files created\n")
+	fmt.Printf("  ✓ Documentation generated\n\n")
+
+	return nil
+}
+
+// loadFullPipelineState returns the saved pipeline state and FCS when --resume
+// is set and a previous run of the same specification finished clarification.
+// Anything else starts the pipeline fresh.
+func loadFullPipelineState(cmd *cobra.Command, specContent []byte) (*generate.PipelineState, *models.FinalClarifiedSpecification, error) {
+	if !fullResume {
+		return nil, nil, nil
+	}
+
+	outputDir := fullOutput
+	if !cmd.Flags().Changed("output") && cfg.Project.OutputDir != "" {
+		outputDir = cfg.Project.OutputDir
+	}
+	if strings.Contains(outputDir, "{{") {
+		log.Warn().
+			Str("output", outputDir).
+			Msg("Resume needs an output directory without template actions, starting fresh")
+		return nil, nil, nil
+	}
+
+	state, err := generate.LoadPipelineState(outputDir)
+	if err != nil {
+		return nil, nil, ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+	if state == nil || !state.Done(generate.PipelinePhaseClarify) {
+		log.Warn().
+			Str("output_dir", outputDir).
+			Msg("Resume requested but no previous pipeline state found, starting fresh")
+		return nil, nil, nil
+	}
+	if !state.Matches(specContent) {
+		log.Warn().
+			Str("spec_file", state.SpecFile).
+			Msg("Specification changed since the previous run, starting fresh")
+		return nil, nil, nil
+	}
+
+	fcsPath := filepath.Join(outputDir, state.Artifacts[generate.PipelineArtifactFCS])
+	fcs, err := readFCS(fcsPath)
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Str("fcs_path", fcsPath).
+			Msg("Previous FCS could not be loaded, starting fresh")
+		return nil, nil, nil
+	}
+
+	log.Info().
+		Str("state_file", generate.PipelineStatePath(outputDir)).
+		Str("last_completed", string(state.LastCompleted)).
+		Msg("Resuming full pipeline")
+
+	return state, fcs, nil
+}
+
+// saveFullClarification writes the FCS into the output directory and records
+// the clarify phase as finished
+func saveFullClarification(state *generate.PipelineState, outputDir string, fcs *models.FinalClarifiedSpecification) error {
+	fcsRel := filepath.Join(".gocreator", "fcs.json")
+	if err := os.MkdirAll(filepath.Join(outputDir, ".gocreator"), 0o750); err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to create metadata directory: %w", err)}
+	}
+	if err := writeFCS(fcs, filepath.Join(outputDir, fcsRel)); err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+
+	state.Artifacts[generate.PipelineArtifactFCS] = fcsRel
+	state.Complete(generate.PipelinePhaseClarify)
+	return saveFullPipelineState(state, outputDir)
+}
+
+// saveFullPipelineState persists the pipeline state for --resume
+func saveFullPipelineState(state *generate.PipelineState, outputDir string) error {
+	if err := state.Save(outputDir); err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+	return nil
+}
+
+func runFullClarification(ctx context.Context, specFile, batchFile string) (*models.FinalClarifiedSpecification, error) {
 	// Detect format
 	format, err := detectSpecFormat(specFile)
 	if err != nil {
@@ -158,7 +381,7 @@ func runFullClarification(specFile, batchFile string) (*models.FinalClarifiedSpe
 	}
 
 	// Parse and validate
-	inputSpec, err := spec.ParseAndValidate(format, string(content))
+	inputSpec, err := parseSpec(specFile, format, string(content))
 	if err != nil {
 		return nil, ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("specification validation failed: %w", err)}
 	}
@@ -181,8 +404,7 @@ func runFullClarification(specFile, batchFile string) (*models.FinalClarifiedSpe
 	interactive := batchFile == ""
 
 	// Run clarification
-	ctx := context.Background()
-	fcs, err := engine.Clarify(ctx, inputSpec, interactive)
+	fcs, err := clarifySpec(ctx, engine, inputSpec, interactive)
 	if err != nil {
 		return nil, ExitError{Code: ExitCodeClarificationError, Err: fmt.Errorf("clarification failed: %w", err)}
 	}
@@ -190,17 +412,229 @@ func runFullClarification(specFile, batchFile string) (*models.FinalClarifiedSpe
 	return fcs, nil
 }
 
-func runFullValidation(projectRoot, reportPath string) (bool, error) {
-	ctx := context.Background()
+// fullRepairTools holds what the build and test repairs of full share
+type fullRepairTools struct {
+	clients  phaseClients
+	fileOps  fsops.FileOps
+	preamble string
+	close    func()
+}
+
+// newFullRepairTools creates the clients and the file operations handler
+// the repairs write through, bounded to projectRoot and its protected paths
+func newFullRepairTools(projectRoot string) (*fullRepairTools, error) {
+	llmClient, err := createLLMClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+	clients, err := createPhaseClients(cfg, llmClient)
+	if err != nil {
+		return nil, err
+	}
+
+	preamble, err := cfg.Prompts.LoadPreamble()
+	if err != nil {
+		return nil, err
+	}
+
+	logger, err := fsops.NewFileLogger(filepath.Join(projectRoot, ".gocreator", "logs"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file logger: %w", err)
+	}
+	fileOps, err := fsops.New(fsops.Config{
+		RootDir:        projectRoot,
+		Logger:         logger,
+		ProtectedPaths: cfg.Project.ProtectedPaths,
+		MaxOpenFiles:   cfg.Limits.MaxOpenFiles,
+	})
+	if err != nil {
+		_ = logger.Close()
+		return nil, fmt.Errorf("failed to create file operations handler: %w", err)
+	}
+
+	return &fullRepairTools{
+		clients:  clients,
+		fileOps:  fileOps,
+		preamble: preamble,
+		close:    func() { _ = logger.Close() },
+	}, nil
+}
+
+// repairFullBuild feeds the compile errors of a failed build back to the
+// coder model, regenerating the failing files and rebuilding up to
+// fullRepairAttempts times. It returns the last build result; when the repair
+// cannot start, the failed build is returned as it was.
+func repairFullBuild(ctx context.Context, projectRoot string, build *models.BuildResult) *models.BuildResult {
+	tools, err := newFullRepairTools(projectRoot)
+	if err != nil {
+		log.Warn().Err(err).Msg("Build repair skipped")
+		return build
+	}
+	defer tools.close()
+
+	repairer, err := generate.NewBuildRepairer(generate.BuildRepairConfig{
+		LLMClient:   tools.clients.coder,
+		Validator:   validate.NewBuildValidator(cfg.Validation.TestTimeout, validate.WithBuildParallelism(validationParallelism())),
+		FileOps:     tools.fileOps,
+		MaxAttempts: fullRepairAttempts,
+		Preamble:    tools.preamble,
+		Knowledge:   loadFixKnowledge(),
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Build repair skipped")
+		return build
+	}
+
+	fmt.Printf("  ↻ Build failed with %s; repairing (up to %s)\n", countNoun(len(build.Errors), "error"), countNoun(fullRepairAttempts, "attempt"))
+	result, err := repairer.Repair(ctx, projectRoot, build)
+	if err != nil {
+		log.Warn().Err(err).Msg("Build repair stopped")
+	}
+	if len(result.Repaired) > 0 {
+		fmt.Printf("  ↻ Regenerated %s in %s: %s\n", countNoun(len(result.Repaired), "file"), countNoun(result.Attempts, "attempt"), strings.Join(result.Repaired, ", "))
+	}
+
+	log.Info().
+		Int("attempts", result.Attempts).
+		Strs("repaired", result.Repaired).
+		Bool("build_success", result.Build.Success).
+		Msg("Build repair finished")
+
+	return result.Build
+}
+
+// repairFullLint feeds the issues of a lint run back to the coder model,
+// regenerating the files they were reported in and linting again up to
+// fullLintRepairAttempts times. A round whose files break the build is
+// rolled back. It returns the last lint result; when the repair cannot
+// start, the lint result is returned as it was.
+func repairFullLint(ctx context.Context, projectRoot string, validator validate.LintValidator, lint *models.LintResult) *models.LintResult {
+	tools, err := newFullRepairTools(projectRoot)
+	if err != nil {
+		log.Warn().Err(err).Msg("Lint repair skipped")
+		return lint
+	}
+	defer tools.close()
+
+	repairer, err := generate.NewLintRepairer(generate.LintRepairConfig{
+		LLMClient:   tools.clients.coder,
+		Validator:   validator,
+		FileOps:     tools.fileOps,
+		MaxAttempts: fullLintRepairAttempts,
+		Preamble:    tools.preamble,
+		Knowledge:   loadFixKnowledge(),
+		Build:       validate.NewBuildValidator(cfg.Validation.TestTimeout, validate.WithBuildParallelism(validationParallelism())),
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Lint repair skipped")
+		return lint
+	}
+
+	fmt.Printf("  ↻ Found %s; repairing (up to %s)\n", countNoun(len(lint.Issues), "lint issue"), countNoun(fullLintRepairAttempts, "attempt"))
+	result, err := repairer.Repair(ctx, projectRoot, lint)
+	if err != nil {
+		log.Warn().Err(err).Msg("Lint repair stopped")
+	}
+	if len(result.Lint.Repaired) > 0 {
+		fmt.Printf("  ↻ Regenerated %s in %s: %s\n", countNoun(len(result.Lint.Repaired), "file"), countNoun(result.Attempts, "attempt"), strings.Join(result.Lint.Repaired, ", "))
+	}
+
+	log.Info().
+		Int("attempts", result.Attempts).
+		Strs("repaired", result.Lint.Repaired).
+		Bool("lint_success", result.Lint.Success).
+		Msg("Lint repair finished")
+
+	return result.Lint
+}
+
+// policyLintValidator applies the severity policy to every lint result, so a
+// rerun after repairs reports the same issues a first run would
+type policyLintValidator struct {
+	validate.LintValidator
+	policy *validate.SeverityPolicy
+}
+
+// Validate runs the linter and applies the policy to its issues
+func (v policyLintValidator) Validate(ctx context.Context, projectRoot string) (*models.LintResult, error) {
+	result, err := v.LintValidator.Validate(ctx, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	v.policy.ApplyToLint(result)
+	return result, nil
+}
+
+// repairFullTests feeds the failures of a test run back to the tester model,
+// regenerating the failing test files, or the implementation files the model
+// finds at fault, and rerunning the tests up to fullTestRepairAttempts times.
+// It returns the last test result; when the repair cannot start, the failed
+// run is returned as it was.
+func repairFullTests(ctx context.Context, projectRoot string, tests *models.TestResult) *models.TestResult {
+	tools, err := newFullRepairTools(projectRoot)
+	if err != nil {
+		log.Warn().Err(err).Msg("Test repair skipped")
+		return tests
+	}
+	defer tools.close()
+
+	repairer, err := generate.NewTestRepairer(generate.TestRepairConfig{
+		LLMClient: tools.clients.tester,
+		Validator: validate.NewTestValidator(
+			validate.WithTestTimeout(cfg.Validation.TestTimeout),
+			validate.WithTestParallelism(validationParallelism())),
+		FileOps:     tools.fileOps,
+		MaxAttempts: fullTestRepairAttempts,
+		Preamble:    tools.preamble,
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Test repair skipped")
+		return tests
+	}
+
+	fmt.Printf("  ↻ %s failed; repairing (up to %s)\n", countNoun(len(tests.Failures), "test"), countNoun(fullTestRepairAttempts, "attempt"))
+	result, err := repairer.Repair(ctx, projectRoot, tests)
+	if err != nil {
+		log.Warn().Err(err).Msg("Test repair stopped")
+	}
+	if len(result.Repaired) > 0 {
+		fmt.Printf("  ↻ Regenerated %s in %s: %s\n", countNoun(len(result.Repaired), "file"), countNoun(result.Attempts, "attempt"), strings.Join(result.Repaired, ", "))
+	}
+
+	log.Info().
+		Int("attempts", result.Attempts).
+		Strs("repaired", result.Repaired).
+		Bool("tests_success", result.Tests.Success).
+		Msg("Test repair finished")
+
+	return result.Tests
+}
+
+func runFullValidation(ctx context.Context, projectRoot, reportPath string, fcs *models.FinalClarifiedSpecification) (bool, error) {
+	ctx, cancel := withPhaseTimeout(ctx, cfg.Timeouts.Validate)
+	defer cancel()
+
+	policy, err := newSeverityPolicy()
+	if err != nil {
+		return false, err
+	}
 
 	// Run build validation
 	fmt.Printf("[1/3] Build Validation\n")
-	buildValidator := validate.NewBuildValidator(cfg.Validation.TestTimeout)
+	generateProtoCode(ctx, projectRoot)
+	generatePersistenceCode(ctx, projectRoot)
+	progress := startPackageProgress()
+	buildValidator := validate.NewBuildValidator(cfg.Validation.TestTimeout, validate.WithBuildEvents(progress.events),
+		validate.WithBuildParallelism(validationParallelism()))
 	buildResult, err := buildValidator.Validate(ctx, projectRoot)
+	progress.stop()
 	if err != nil {
 		log.Error().Err(err).Msg("Build validation error")
 		return false, err
 	}
+	if !buildResult.Success && fullRepairAttempts > 0 {
+		buildResult = repairFullBuild(ctx, projectRoot, buildResult)
+	}
 
 	if buildResult.Success {
 		fmt.Printf("  ✓ Build successful [elapsed: %.1fs]\n", buildResult.Duration.Seconds())
@@ -218,12 +652,17 @@ func runFullValidation(projectRoot, reportPath string) (bool, error) {
 
 	// Run lint validation
 	fmt.Printf("\n[2/3] Lint Validation\n")
-	lintValidator := validate.NewLintValidator(validate.WithSkipIfNotFound(true))
+	lintValidator := policyLintValidator{validate.NewLintValidator(validate.WithSkipIfNotFound(true)), policy}
 	lintResult, err := lintValidator.Validate(ctx, projectRoot)
 	if err != nil {
 		log.Error().Err(err).Msg("Lint validation error")
 		return false, err
 	}
+	// The lint repair rolls back rounds that break the build, which it can
+	// only tell when the build passed to begin with
+	if !lintResult.Success && fullRepairLint && fullLintRepairAttempts > 0 && buildResult.Success {
+		lintResult = repairFullLint(ctx, projectRoot, lintValidator, lintResult)
+	}
 
 	if lintResult.Success {
 		fmt.Printf("  ✓ No lint issues [elapsed: %.1fs]\n", lintResult.Duration.Seconds())
@@ -241,12 +680,20 @@ func runFullValidation(projectRoot, reportPath string) (bool, error) {
 
 	// Run test validation
 	fmt.Printf("\n[3/3] Test Validation\n")
-	testValidator := validate.NewTestValidator(validate.WithTestTimeout(cfg.Validation.TestTimeout))
+	progress = startPackageProgress()
+	testValidator := validate.NewTestValidator(
+		validate.WithTestTimeout(cfg.Validation.TestTimeout),
+		validate.WithTestEvents(progress.events),
+		validate.WithTestParallelism(validationParallelism()))
 	testResult, err := testValidator.Validate(ctx, projectRoot)
+	progress.stop()
 	if err != nil {
 		log.Error().Err(err).Msg("Test validation error")
 		return false, err
 	}
+	if !testResult.Success && fullRepairTests && fullTestRepairAttempts > 0 {
+		testResult = repairFullTests(ctx, projectRoot, testResult)
+	}
 
 	if testResult.Success {
 		fmt.Printf("  ✓ All tests passed (%d/%d) [coverage: %.1f%%] [elapsed: %.1fs]\n",
@@ -262,20 +709,82 @@ func runFullValidation(projectRoot, reportPath string) (bool, error) {
 			fmt.Printf("    ... and %d more failures\n", len(testResult.Failures)-5)
 		}
 	}
+	printCoverageShortfall(testResult)
+	fmt.Printf("\n")
+
+	// The checks after the build, lint and tests are those of validate
+	buildPassed := buildResult.Success
+	results := checkResults{build: buildResult, lint: lintResult, test: testResult}
+	if results.requirements, err = runRequirementValidation(ctx, projectRoot, fcs); err != nil {
+		return false, err
+	}
+	if results.enums, err = runEnumValidation(ctx, projectRoot, fcs); err != nil {
+		return false, err
+	}
+	if results.middleware, err = runMiddlewareValidation(ctx, projectRoot, fcs); err != nil {
+		return false, err
+	}
+	if results.contracts, err = runContractValidation(ctx, projectRoot, fcs, fullContracts); err != nil {
+		return false, err
+	}
+	if results.style, err = runStyleValidation(ctx, projectRoot, fcs); err != nil {
+		return false, err
+	}
+	if results.smoke, err = runSmokeValidation(ctx, projectRoot, fullSmoke || cfg.Validation.Smoke.Enabled, buildPassed); err != nil {
+		return false, err
+	}
+	if results.fuzz, err = runFuzzValidation(ctx, projectRoot, fullFuzz || fuzzEnabled(fcs), buildPassed); err != nil {
+		return false, err
+	}
+	if results.buildFiles, err = runBuildFilesValidation(ctx, projectRoot, fullBuildFiles || cfg.Validation.BuildFiles.Enabled); err != nil {
+		return false, err
+	}
 
-	allPassed := buildResult.Success && lintResult.Success && testResult.Success
+	// The most severe failure decides whether validation passed
+	outcomes := results.outcomes(policy)
+	allPassed := !policy.Fails(outcomes)
+	if validate.HighestSeverity(outcomes) != "" {
+		fmt.Printf("\nFailed checks:\n")
+		printCheckOutcomes(outcomes)
+	}
 
 	// Save report if requested
 	if reportPath != "" {
 		report := map[string]interface{}{
-			"build_passed":  buildResult.Success,
-			"lint_passed":   lintResult.Success,
-			"test_passed":   testResult.Success,
-			"all_passed":    allPassed,
-			"build_errors":  len(buildResult.Errors),
-			"lint_issues":   len(lintResult.Issues),
-			"test_failures": len(testResult.Failures),
-			"coverage":      testResult.Coverage,
+			"build_passed":     buildResult.Success,
+			"lint_passed":      lintResult.Success,
+			"test_passed":      testResult.Success,
+			"all_passed":       allPassed,
+			"build_errors":     len(buildResult.Errors),
+			"lint_issues":      len(lintResult.Issues),
+			"test_failures":    len(testResult.Failures),
+			"coverage":         testResult.Coverage,
+			"checks":           outcomes,
+			"highest_severity": validate.HighestSeverity(outcomes),
+		}
+		if results.requirements != nil {
+			report["untested_requirements"] = results.requirements.Untested
+		}
+		if results.enums != nil {
+			report["enum_usage"] = results.enums
+		}
+		if results.middleware != nil {
+			report["middleware_usage"] = results.middleware
+		}
+		if results.contracts != nil {
+			report["contracts"] = results.contracts
+		}
+		if results.style != nil {
+			report["style"] = results.style
+		}
+		if results.smoke != nil {
+			report["smoke"] = results.smoke
+		}
+		if results.fuzz != nil {
+			report["fuzz"] = results.fuzz
+		}
+		if results.buildFiles != nil {
+			report["build_files"] = results.buildFiles
 		}
 
 		data, err := json.MarshalIndent(report, "", "  ")
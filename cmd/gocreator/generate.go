@@ -6,14 +6,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/dshills/gocreator/internal/clarify"
 	"github.com/dshills/gocreator/internal/cli"
+	"github.com/dshills/gocreator/internal/config"
 	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/internal/generate/templates"
 	"github.com/dshills/gocreator/internal/models"
-	"github.com/dshills/gocreator/internal/spec"
 	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/dshills/gocreator/pkg/postprocess"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +28,19 @@ var (
 	generateBatch       string
 	generateDryRun      bool
 	generateIncremental bool
+	generateMerge       string
+	generateCritic      []string
+	generateEnsemble    []string
+	generateEmit        string
+	generateLLMBatch    bool
+	generateLLMStream   bool
+	generateSpeculative bool
+	generateCostCeiling float64
+	generateCheck       bool
+	generateProbe       bool
+	generateBrownfield  bool
+	generateApprovePlan bool
+	generateApproveFrom string
 )
 
 var generateCmd = &cobra.Command{
@@ -44,6 +61,26 @@ Options:
   --batch        Use pre-answered questions from JSON file
   --dry-run      Show what would be generated without writing files
   --incremental  Enable incremental regeneration (only regenerate changed files)
+  --merge        How to merge hand-edited files on regeneration: markers or llm
+  --critic       Review selected file classes in a second pass (handlers, auth, concurrency, or path globs)
+  --ensemble     Generate selected file classes with two models and keep the better candidate
+                 (the second model is configured under llm.ensemble)
+  --experimental-speculative
+                 Generate source files workflow.max_parallel at a time and start files of
+                 the next dependency level against the APIs of the previous output early
+  --brownfield   Generate into the existing Go module in the output directory, modifying
+                 its files with patches and keeping to its package structure
+  --approve-plan Write the generation plan to <output>/.gocreator/plan.yaml and wait for
+                 confirmation before generating code; edits saved to the file are used
+  --approve-from Generate from a reviewed plan file (YAML or JSON) instead of the new plan,
+                 without prompting
+  --emit-patches Also write a portable patch bundle (apply elsewhere with 'gocreator apply')
+  --check        Plan the run and build every source file prompt, but write nothing;
+                 exits non-zero if the plan or a prompt would fail (a fast CI gate)
+  --probe        With --check, also send a minimal request to confirm the model responds
+  --max-cost     Stop once the run's LLM calls cost this much (USD); continue with 'gocreator resume'
+  --max-tokens   Stop once the run's LLM calls use this many tokens
+  --on-budget    At a crossed cap: abort (default), or confirm to ask whether to continue
 
 Example:
   # Basic generation
@@ -52,11 +89,29 @@ Example:
   # Specify output directory
   gocreator generate ./my-project-spec.yaml --output ./my-project
 
+  # Templated output directory (also configurable as project.output_dir)
+  gocreator generate ./my-project-spec.yaml --output './generated/{{.ProjectName}}-{{.Date}}'
+
   # Resume from checkpoint
   gocreator generate ./my-project-spec.yaml --resume
 
   # Batch mode
-  gocreator generate ./my-project-spec.yaml --batch ./answers.json`,
+  gocreator generate ./my-project-spec.yaml --batch ./answers.json
+
+  # Add a feature to an existing project
+  gocreator generate ./feature-spec.yaml --output ./my-project --brownfield
+
+  # Review and edit the plan before any code is generated
+  gocreator generate ./my-project-spec.yaml --approve-plan
+
+  # Generate from a plan reviewed earlier, in a script
+  gocreator generate ./my-project-spec.yaml --approve-from ./generated/.gocreator/plan.yaml
+
+  # Verify a spec or config change in CI without generating code
+  gocreator generate ./my-project-spec.yaml --check
+
+  # Export patches for an air-gapped machine
+  gocreator generate ./my-project-spec.yaml --emit-patches bundle.tar`,
 	Args: cobra.ExactArgs(1),
 	RunE: runGenerate,
 }
@@ -67,9 +122,29 @@ func setupGenerateFlags() {
 	generateCmd.Flags().StringVar(&generateBatch, "batch", "", "path to JSON file with pre-answered questions")
 	generateCmd.Flags().BoolVar(&generateDryRun, "dry-run", false, "show what would be generated without writing files")
 	generateCmd.Flags().BoolVar(&generateIncremental, "incremental", false, "enable incremental regeneration (only regenerate changed files)")
+	generateCmd.Flags().StringVar(&generateMerge, "merge", string(generate.MergeStrategyMarkers), "merge strategy for hand-edited files during incremental regeneration (markers, llm)")
+	generateCmd.Flags().StringVar(&generateEmit, "emit-patches", "", "write a portable patch bundle (tar) for 'gocreator apply'")
+	generateCmd.Flags().StringSliceVar(&generateCritic, "critic", nil, "file classes to review with a critic pass (handlers, auth, concurrency, or path globs)")
+	generateCmd.Flags().StringSliceVar(&generateEnsemble, "ensemble", nil, "critical file classes to generate with two models (handlers, auth, concurrency, or path globs)")
+	generateCmd.Flags().BoolVar(&generateLLMBatch, "llm-batch", false, "generate source files as provider batch jobs: cheaper, but may take hours (Anthropic, OpenAI)")
+	generateCmd.Flags().Float64Var(&generateCostCeiling, "file-cost-ceiling", 0, "cap the projected cost in USD of each source file, downgrading files over it to llm.downgrade or a stub (overrides llm.file_cost_ceiling)")
+	generateCmd.Flags().BoolVar(&generateLLMStream, "llm-stream", false, "stream source file responses to disk and resume interrupted ones")
+	generateCmd.Flags().BoolVar(&generateSpeculative, "experimental-speculative", false, "generate source files in parallel and start files of the next dependency level early against their predicted APIs (experimental)")
+	generateCmd.Flags().BoolVar(&generateCheck, "check", false, "plan the run and build every prompt without writing anything; exit non-zero if the plan or a prompt would fail")
+	generateCmd.Flags().BoolVar(&generateProbe, "probe", false, "with --check, also send a minimal request to confirm the model responds")
+	generateCmd.Flags().BoolVar(&generateBrownfield, "brownfield", false, "generate into the existing Go module in the output directory, patching its files instead of creating them")
+	generateCmd.Flags().BoolVar(&generateApprovePlan, "approve-plan", false, "write the plan to <output>/.gocreator/plan.yaml and wait for confirmation before generating code from it")
+	generateCmd.Flags().StringVar(&generateApproveFrom, "approve-from", "", "generate from a reviewed plan file (YAML or JSON) without prompting")
+	addBudgetFlags(generateCmd)
+	generateCmd.MarkFlagsMutuallyExclusive("approve-plan", "approve-from")
+	generateCmd.MarkFlagsMutuallyExclusive("check", "approve-plan")
+	generateCmd.MarkFlagsMutuallyExclusive("check", "approve-from")
+	generateCmd.MarkFlagsMutuallyExclusive("check", "dry-run")
+	generateCmd.MarkFlagsMutuallyExclusive("check", "resume")
+	generateCmd.MarkFlagsMutuallyExclusive("check", "emit-patches")
 }
 
-func runGenerate(_ *cobra.Command, args []string) error {
+func runGenerate(cmd *cobra.Command, args []string) error {
 	specFile := args[0]
 
 	log.Info().
@@ -79,9 +154,31 @@ func runGenerate(_ *cobra.Command, args []string) error {
 		Bool("dry_run", generateDryRun).
 		Msg("Starting generation phase")
 
+	if err := checkPlanApproval(); err != nil {
+		return err
+	}
+
+	// Phase 1: Clarification (silent, no progress bar for now)
+	fcs, err := runClarificationPhase(cmd.Context(), specFile, generateBatch)
+	if err != nil {
+		return err
+	}
+
+	// The output directory may be templated on the project name
+	outputDir, err := resolveOutputDir(generateOutput, cmd.Flags().Changed("output"), fcs)
+	if err != nil {
+		return err
+	}
+
+	if generateCheck {
+		return runGenerateCheck(cmd.Context(), fcs, outputDir)
+	} else if generateProbe {
+		return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("--probe requires --check")}
+	}
+
 	// Handle resume: check for existing state and enable incremental mode
 	if generateResume {
-		stateFilePath := filepath.Join(generateOutput, ".gocreator", "state.json")
+		stateFilePath := filepath.Join(outputDir, ".gocreator", "state.json")
 		if _, err := os.Stat(stateFilePath); err == nil {
 			log.Info().
 				Str("state_file", stateFilePath).
@@ -89,40 +186,157 @@ func runGenerate(_ *cobra.Command, args []string) error {
 			generateIncremental = true
 		} else if os.IsNotExist(err) {
 			log.Warn().
-				Str("output_dir", generateOutput).
+				Str("output_dir", outputDir).
 				Msg("Resume requested but no previous generation state found, starting fresh")
 		} else {
 			return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to check state file: %w", err)}
 		}
 	}
 
-	// Phase 1: Clarification (silent, no progress bar for now)
-	fcs, err := runClarificationPhase(specFile, generateBatch)
-	if err != nil {
-		return err
-	}
-
 	// Phase 2: Code Generation with Progress Tracking
 	if generateDryRun {
-		fmt.Printf("\n[DRY RUN] No files will be written\n\n")
+		fmt.Printf("\n[DRY RUN] No files will be written to %s\n\n", outputDir)
 		return nil
 	}
 
-	if err := runGenerationWithProgress(fcs, generateOutput, generateIncremental); err != nil {
+	if err := runGenerationWithProgress(cmd.Context(), fcs, outputDir, generateIncremental, ""); err != nil {
 		return err
 	}
 
 	// Show next steps
-	fmt.Printf("\nOutput written to: %s\n\n", generateOutput)
+	fmt.Printf("\nOutput written to: %s\n\n", outputDir)
 	fmt.Printf("Next steps:\n")
-	fmt.Printf("  cd %s\n", generateOutput)
+	fmt.Printf("  cd %s\n", outputDir)
 	fmt.Printf("  go mod tidy\n")
 	fmt.Printf("  make test\n\n")
 
 	return nil
 }
 
-func runClarificationPhase(specFile, batchFile string) (*models.FinalClarifiedSpecification, error) {
+// projectSettings returns the configured module path and binary name
+func projectSettings() templates.ProjectSettings {
+	return templates.ProjectSettings{
+		ModulePath: cfg.Project.ModulePath,
+		BinaryName: cfg.Project.BinaryName,
+	}
+}
+
+// planLimits returns the configured plan size limits
+func planLimits() models.PlanLimits {
+	return models.PlanLimits{
+		MaxFiles:           cfg.Plan.MaxFiles,
+		MaxDirectories:     cfg.Plan.MaxDirectories,
+		MaxDepth:           cfg.Plan.MaxDepth,
+		MaxFilesPerPackage: cfg.Plan.MaxFilesPerPackage,
+	}
+}
+
+// fileLayout returns the configured file split strategy; "auto" leaves it to the planner
+func fileLayout() models.FileLayout {
+	layout := models.FileLayout{MaxFileLines: cfg.Plan.MaxFileLines}
+	if cfg.Plan.FileStrategy != "" && cfg.Plan.FileStrategy != "auto" {
+		layout.Strategy = models.FileSplitStrategy(cfg.Plan.FileStrategy)
+	}
+	return layout
+}
+
+// migrationsDir returns the directory the plan gets data model migrations
+// in, "" when database.migrations is off
+func migrationsDir() string {
+	if !cfg.Database.Migrations {
+		return ""
+	}
+	return cfg.Database.MigrationsDir
+}
+
+// maxReplans maps plan.max_replans to the planner setting, where zero disables re-planning
+func maxReplans() int {
+	if cfg.Plan.MaxReplans == 0 {
+		return -1
+	}
+	return cfg.Plan.MaxReplans
+}
+
+// loadPromptTemplates loads the coder prompt overrides from
+// prompts.templates_dir, or returns nil when none are configured
+func loadPromptTemplates() (*generate.PromptTemplates, error) {
+	if cfg.Prompts.TemplatesDir == "" {
+		return nil, nil
+	}
+	templates, err := generate.LoadPromptTemplates(cfg.Prompts.TemplatesDir)
+	if err != nil {
+		return nil, fmt.Errorf("prompts.templates_dir: %w", err)
+	}
+	log.Info().Str("dir", templates.Dir()).Msg("Using prompt templates")
+	return templates, nil
+}
+
+// loadPostProcessor builds the post-processing pipeline of generated Go files
+// with the transforms of postprocess.transforms
+func loadPostProcessor() (*postprocess.Pipeline, error) {
+	pipeline, err := postprocess.New(cfg.PostProcess.Transforms...)
+	if err != nil {
+		return nil, fmt.Errorf("postprocess.transforms: %w", err)
+	}
+	if len(cfg.PostProcess.Transforms) > 0 {
+		log.Info().Strs("transforms", pipeline.Transforms()).Msg("Using post-processing transforms")
+	}
+	return pipeline, nil
+}
+
+// loadFixKnowledge opens the fix knowledge base from knowledge. It returns nil
+// when the knowledge base is disabled or unreadable; repairs then run without
+// remembered fixes.
+func loadFixKnowledge() *generate.FixKnowledge {
+	if !cfg.Knowledge.Enabled {
+		return nil
+	}
+
+	path := cfg.Knowledge.Path
+	if path == "" {
+		var err error
+		if path, err = generate.DefaultFixKnowledgePath(); err != nil {
+			log.Warn().Err(err).Msg("Fix knowledge base disabled")
+			return nil
+		}
+	}
+
+	knowledge, err := generate.LoadFixKnowledge(path)
+	if err != nil {
+		log.Warn().Err(err).Msg("Fix knowledge base disabled")
+		return nil
+	}
+	log.Debug().Str("path", path).Int("fixes", knowledge.Len()).Msg("Loaded fix knowledge base")
+	return knowledge
+}
+
+// resolveOutputDir expands the output directory template for this project.
+// An explicit --output flag takes precedence over project.output_dir.
+func resolveOutputDir(flagValue string, flagChanged bool, fcs *models.FinalClarifiedSpecification) (string, error) {
+	pattern := flagValue
+	if !flagChanged && cfg.Project.OutputDir != "" {
+		pattern = cfg.Project.OutputDir
+	}
+
+	data := templates.ExtractTemplateData(fcs)
+	data.ApplySettings(projectSettings())
+
+	outputDir, err := config.ResolveOutputDir(pattern, config.NewOutputPathData(data.ProjectName, data.ModuleName, time.Now()))
+	if err != nil {
+		return "", ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+
+	if outputDir != pattern {
+		log.Info().
+			Str("pattern", pattern).
+			Str("output_dir", outputDir).
+			Msg("Resolved output directory template")
+	}
+
+	return outputDir, nil
+}
+
+func runClarificationPhase(ctx context.Context, specFile, batchFile string) (*models.FinalClarifiedSpecification, error) {
 	// Detect format
 	format, err := detectSpecFormat(specFile)
 	if err != nil {
@@ -137,7 +351,7 @@ func runClarificationPhase(specFile, batchFile string) (*models.FinalClarifiedSp
 	}
 
 	// Parse and validate
-	inputSpec, err := spec.ParseAndValidate(format, string(content))
+	inputSpec, err := parseSpec(specFile, format, string(content))
 	if err != nil {
 		return nil, ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("specification validation failed: %w", err)}
 	}
@@ -184,8 +398,7 @@ func runClarificationPhase(specFile, batchFile string) (*models.FinalClarifiedSp
 	}
 
 	// Run clarification
-	ctx := context.Background()
-	fcs, err := engine.Clarify(ctx, inputSpec, interactive)
+	fcs, err := clarifySpec(ctx, engine, inputSpec, interactive)
 	if err != nil {
 		return nil, ExitError{Code: ExitCodeClarificationError, Err: fmt.Errorf("clarification failed: %w", err)}
 	}
@@ -238,8 +451,10 @@ func runFinalization(outputDir string, dryRun bool) error {
 	return nil
 }
 
-// runGenerationWithProgress runs the generation engine with real-time progress tracking
-func runGenerationWithProgress(fcs *models.FinalClarifiedSpecification, outputDir string, incremental bool) error {
+// runGenerationWithProgress runs the generation engine with real-time progress
+// tracking. With resumeRunID set, the checkpointed run is resumed instead and
+// fcs is unused.
+func runGenerationWithProgress(ctx context.Context, fcs *models.FinalClarifiedSpecification, outputDir string, incremental bool, resumeRunID string) error {
 	// Create event channel for progress updates
 	eventChan := make(chan models.ProgressEvent, 100)
 
@@ -254,78 +469,396 @@ func runGenerationWithProgress(fcs *models.FinalClarifiedSpecification, outputDi
 	}
 	tracker := cli.NewProgressTracker(progressConfig)
 
+	// Crossing a cost or token cap cancels the run at its last checkpoint
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+	budget, err := newCostGovernor(stop, eventChan, stdinIsTerminal())
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+
+	// The plan approval prompt waits for the progress output to catch up
+	approvePlan := newPlanApprover(outputDir, func() {
+		for len(eventChan) > 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		tracker.Flush()
+	})
+
+	engine, closeEngine, err := newGenerationEngine(engineOptions{
+		outputDir:   outputDir,
+		incremental: incremental,
+		brownfield:  generateBrownfield && resumeRunID == "",
+		eventChan:   eventChan,
+		approvePlan: approvePlan,
+		budget:      budget,
+	})
+	if err != nil {
+		return err
+	}
+	defer closeEngine()
+
+	// The progress display and the sinks configured under events receive
+	// every event
+	sinks, err := eventSinks(outputDir)
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+	bus := models.NewEventBus(append([]models.EventSink{tracker}, sinks...)...)
+
 	// Start progress tracking in background
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
-		for event := range eventChan {
-			tracker.HandleEvent(event)
-		}
+		bus.Run(eventChan)
 	}()
 
+	// Start progress tracker with total phases
+	// Phases: initialization, analyze_fcs, create_plan, generate_packages, generate_tests, generate_config, file_writing
+	// (generate_docs and approve_plan report no phase of their own)
+	tracker.Start(7)
+
+	// Run generation; planning and generation nodes carry their own deadlines
+	var output *models.GenerationOutput
+	if resumeRunID != "" {
+		output, err = engine.Resume(ctx, resumeRunID, outputDir)
+	} else {
+		output, err = engine.Generate(ctx, fcs, outputDir)
+	}
+
+	// Close event channel and wait for progress tracker to render everything
+	close(eventChan)
+	<-done
+	tracker.Flush()
+	if closeErr := bus.Close(); closeErr != nil {
+		log.Warn().Err(closeErr).Msg("Failed to deliver progress events")
+	}
+
+	if err != nil {
+		budgetErr := budget.Err()
+		if output != nil && output.RunID != "" {
+			fmt.Fprintf(os.Stderr, "\nInspect the workflow state with: gocreator debug state %s --output %s\n", output.RunID, outputDir)
+			if budgetErr != nil {
+				fmt.Fprintf(os.Stderr, "Raise --max-cost or --max-tokens and continue with: gocreator resume %s --output %s\n", output.RunID, outputDir)
+			} else {
+				fmt.Fprintf(os.Stderr, "Continue from the last finished step with: gocreator resume %s --output %s\n", output.RunID, outputDir)
+			}
+		}
+		if budgetErr != nil {
+			return ExitError{Code: ExitCodeGenerationError, Err: fmt.Errorf("code generation stopped: %w", budgetErr)}
+		}
+		return ExitError{Code: ExitCodeGenerationError, Err: fmt.Errorf("code generation failed: %w", err)}
+	}
+
+	// Complete progress tracking
+	tracker.Complete()
+
+	// Log summary
+	log.Info().
+		Str("output_id", output.ID).
+		Str("run_id", output.RunID).
+		Int("files", len(output.Files)).
+		Int("imports_fixed", output.Metadata.ImportsFixed).
+		Msg("Generation completed successfully")
+
+	if output.Metadata.ImportsFixed > 0 {
+		fmt.Printf("\nFixed %s to match the go.mod module path\n", countNoun(output.Metadata.ImportsFixed, "import"))
+	}
+
+	printDowngrades(output.Metadata.Downgrades)
+	printConflicts(output.Metadata.Conflicts)
+
+	if generateEmit != "" {
+		return writePatchBundle(output, generateEmit)
+	}
+
+	return nil
+}
+
+// engineOptions are the settings of one generation run
+type engineOptions struct {
+	outputDir   string
+	incremental bool
+	brownfield  bool // plan against the module already in outputDir
+	eventChan   chan<- models.ProgressEvent
+	approvePlan generate.PlanApprover
+	budget      *generate.CostGovernor
+}
+
+// newGenerationEngine creates the generation engine of a run with the
+// configured LLM clients and project settings. The returned function closes
+// the run's audit log once the run is done.
+func newGenerationEngine(opts engineOptions) (generate.Engine, func(), error) {
 	// Create LLM client
 	llmClient, err := createLLMClient(cfg)
 	if err != nil {
-		return ExitError{Code: ExitCodeNetworkError, Err: fmt.Errorf("failed to create LLM client: %w", err)}
+		return nil, nil, ExitError{Code: ExitCodeNetworkError, Err: fmt.Errorf("failed to create LLM client: %w", err)}
+	}
+	clients, err := createPhaseClients(cfg, llmClient)
+	if err != nil {
+		return nil, nil, ExitError{Code: ExitCodeNetworkError, Err: err}
+	}
+
+	var ensembleClient llm.Client
+	if len(generateEnsemble) > 0 {
+		if !cfg.LLM.Ensemble.Enabled() {
+			return nil, nil, ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("--ensemble needs a second model in llm.ensemble.model")}
+		}
+		ensembleClient, err = createEnsembleClient(cfg)
+		if err != nil {
+			return nil, nil, ExitError{Code: ExitCodeNetworkError, Err: fmt.Errorf("failed to create ensemble LLM client: %w", err)}
+		}
+	}
+
+	// Files over the per-file cost ceiling go to the cheaper model, if any
+	costCeiling := cfg.LLM.FileCostCeiling
+	if generateCostCeiling > 0 {
+		costCeiling = generateCostCeiling
+	}
+	var downgradeClient llm.Client
+	if costCeiling > 0 && cfg.LLM.Downgrade.Enabled() {
+		downgradeClient, err = createDowngradeClient(cfg)
+		if err != nil {
+			return nil, nil, ExitError{Code: ExitCodeNetworkError, Err: fmt.Errorf("failed to create downgrade LLM client: %w", err)}
+		}
 	}
 
 	// Create file operations handler with logger
-	logDir := filepath.Join(outputDir, ".gocreator", "logs")
+	logDir := filepath.Join(opts.outputDir, ".gocreator", "logs")
 	logger, err := fsops.NewFileLogger(logDir)
 	if err != nil {
-		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create file logger: %w", err)}
+		return nil, nil, ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create file logger: %w", err)}
 	}
-	defer func() {
+	closeLogger := func() {
 		if closeErr := logger.Close(); closeErr != nil {
 			log.Warn().Err(closeErr).Msg("Failed to close file logger")
 		}
-	}()
+	}
 
 	fileOps, err := fsops.New(fsops.Config{
-		RootDir: outputDir,
-		Logger:  logger,
+		RootDir:        opts.outputDir,
+		Logger:         logger,
+		ProtectedPaths: cfg.Project.ProtectedPaths,
+		MaxOpenFiles:   cfg.Limits.MaxOpenFiles,
 	})
 	if err != nil {
-		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create file operations handler: %w", err)}
+		closeLogger()
+		return nil, nil, ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create file operations handler: %w", err)}
+	}
+
+	preamble, err := cfg.Prompts.LoadPreamble()
+	if err != nil {
+		closeLogger()
+		return nil, nil, ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+	promptTemplates, err := loadPromptTemplates()
+	if err != nil {
+		closeLogger()
+		return nil, nil, ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+	postProcessor, err := loadPostProcessor()
+	if err != nil {
+		closeLogger()
+		return nil, nil, ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+
+	// Brownfield: the planner adds to the module already in the output directory
+	var codebase *models.Codebase
+	if opts.brownfield {
+		codebase, err = generate.AnalyzeCodebase(opts.outputDir)
+		if err != nil {
+			closeLogger()
+			return nil, nil, ExitError{Code: ExitCodeFileSystemError, Err: err}
+		}
+		log.Info().
+			Str("module", codebase.ModulePath).
+			Int("packages", len(codebase.Packages)).
+			Int("files", len(codebase.Files)).
+			Msg("Analyzed existing codebase")
+	}
+
+	// Batch jobs may take up to a day, so the code phase is not time-limited
+	batch := cfg.LLM.Batch || generateLLMBatch
+	timeouts := phaseTimeouts()
+	if batch {
+		timeouts.Packages = -1
+		log.Info().Msg("Batch mode: source files are generated as provider batch jobs")
 	}
 
 	// Create generation engine
 	engine, err := generate.NewEngine(generate.EngineConfig{
-		LLMClient:    llmClient,
-		FileOps:      fileOps,
-		LogDecisions: true,
-		EventChan:    eventChan,
-		Incremental:  incremental,
-		OutputDir:    outputDir,
+		LLMClient:        llmClient,
+		PlannerClient:    clients.planner,
+		CoderClient:      clients.coder,
+		TesterClient:     clients.tester,
+		FileOps:          fileOps,
+		LogDecisions:     true,
+		EventChan:        opts.eventChan,
+		Incremental:      opts.incremental,
+		OutputDir:        opts.outputDir,
+		TemplatesDir:     cfg.Project.TemplatesDir,
+		MergeStrategy:    generate.MergeStrategy(generateMerge),
+		Project:          projectSettings(),
+		PlanLimits:       planLimits(),
+		MaxReplans:       maxReplans(),
+		FileLayout:       fileLayout(),
+		ProtectedPaths:   cfg.Project.ProtectedPaths,
+		Codebase:         codebase,
+		Preamble:         preamble,
+		PromptTemplates:  promptTemplates,
+		Style:            cfg.Style.Guide(),
+		PostProcess:      postProcessor,
+		Timeouts:         timeouts,
+		ApprovePlan:      opts.approvePlan,
+		CriticClasses:    generateCritic,
+		EnsembleClient:   ensembleClient,
+		EnsembleClasses:  generateEnsemble,
+		AuditLogger:      logger,
+		RecordState:      true,
+		Checkpoint:       true,
+		TestParallelism:  clientParallelism(cfg, clients.tester),
+		Batch:            batch,
+		Stream:           cfg.LLM.Stream || generateLLMStream,
+		Speculative:      cfg.Workflow.Speculative || generateSpeculative,
+		CodeParallelism:  clientParallelism(cfg, clients.coder),
+		FileCostCeiling:  costCeiling,
+		DowngradeClient:  downgradeClient,
+		MaxTokens:        cfg.LLM.MaxTokens,
+		FixKnowledge:     loadFixKnowledge(),
+		PackageDocs:      cfg.Project.PackageDocs,
+		Scaffolds:        cfg.Project.Scaffolds,
+		MigrationsDir:    migrationsDir(),
+		Dialect:          models.SQLDialect(cfg.Database.Dialect),
+		GeneratorVersion: version,
+		Temperature:      llmTemperature,
+		Budget:           opts.budget,
 	})
 	if err != nil {
-		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create generation engine: %w", err)}
+		closeLogger()
+		return nil, nil, ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create generation engine: %w", err)}
 	}
 
-	// Start progress tracker with total phases
-	// Phases: initialization, analyze_fcs, create_plan, generate_packages, generate_tests, generate_config, file_writing
-	tracker.Start(7)
+	return engine, closeLogger, nil
+}
 
-	// Run generation
-	ctx := context.Background()
-	output, err := engine.Generate(ctx, fcs, outputDir)
+// printDowngrades lists the files the per-file cost ceiling kept from the
+// primary model
+func printDowngrades(downgrades []models.FileDowngrade) {
+	if len(downgrades) == 0 {
+		return
+	}
 
-	// Close event channel and wait for progress tracker to finish
-	close(eventChan)
-	<-done
+	fmt.Printf("\nDowngraded %s over the per-file cost ceiling:\n", countNoun(len(downgrades), "file"))
+	for _, d := range downgrades {
+		fmt.Printf("  %s: %s instead of %s (projected $%.2f, ceiling $%.2f)\n", d.Path, d.To, d.From, d.ProjectedUSD, d.CeilingUSD)
+	}
+}
 
+// printConflicts lists the generated hunks that were not applied because
+// their files changed in the same place after the change was generated
+func printConflicts(conflicts []models.PatchConflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+
+	fmt.Printf("\nRejected %s conflicting with edits made since generation:\n", countNoun(len(conflicts), "hunk"))
+	for _, c := range conflicts {
+		fmt.Printf("  %s:%d\n", c.Path, c.Line)
+		printConflictLines("<", c.Kept)
+		printConflictLines(">", c.Rejected)
+	}
+}
+
+// printConflictLines prints the lines of one side of a conflict behind marker
+func printConflictLines(marker, lines string) {
+	if lines == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(lines, "\n"), "\n") {
+		fmt.Printf("    %s %s\n", marker, line)
+	}
+}
+
+// runGenerateCheck plans the run and builds its prompts without writing
+// anything, failing when the plan or a prompt would make the run fail
+func runGenerateCheck(ctx context.Context, fcs *models.FinalClarifiedSpecification, outputDir string) error {
+	llmClient, err := createLLMClient(cfg)
 	if err != nil {
-		return ExitError{Code: ExitCodeGenerationError, Err: fmt.Errorf("code generation failed: %w", err)}
+		return ExitError{Code: ExitCodeNetworkError, Err: fmt.Errorf("failed to create LLM client: %w", err)}
+	}
+	clients, err := createPhaseClients(cfg, llmClient)
+	if err != nil {
+		return ExitError{Code: ExitCodeNetworkError, Err: err}
 	}
 
-	// Complete progress tracking
-	tracker.Complete()
+	preamble, err := cfg.Prompts.LoadPreamble()
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+	promptTemplates, err := loadPromptTemplates()
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
 
-	// Log summary
-	log.Info().
-		Str("output_id", output.ID).
-		Int("files", len(output.Files)).
-		Msg("Generation completed successfully")
+	var codebase *models.Codebase
+	if generateBrownfield {
+		codebase, err = generate.AnalyzeCodebase(outputDir)
+		if err != nil {
+			return ExitError{Code: ExitCodeFileSystemError, Err: err}
+		}
+	}
+
+	planCtx, cancel := withPhaseTimeout(ctx, cfg.Timeouts.Plan)
+	defer cancel()
+
+	result, err := generate.Check(planCtx, generate.CheckConfig{
+		LLMClient:       clients.coder,
+		PlannerClient:   clients.planner,
+		PlanLimits:      planLimits(),
+		MaxReplans:      maxReplans(),
+		FileLayout:      fileLayout(),
+		ProtectedPaths:  cfg.Project.ProtectedPaths,
+		Codebase:        codebase,
+		Preamble:        preamble,
+		MigrationsDir:   migrationsDir(),
+		PromptTemplates: promptTemplates,
+		Style:           cfg.Style.Guide(),
+		OutputDir:       outputDir,
+		MaxTokens:       cfg.LLM.MaxTokens,
+		Probe:           generateProbe,
+	}, fcs)
+	if err != nil {
+		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("check failed: %w", err)}
+	}
+
+	fmt.Printf("\n[CHECK] Nothing was written\n")
+	if result.Probed {
+		fmt.Printf("  Model %s/%s responded\n", clients.coder.Provider(), clients.coder.Model())
+	}
+	if result.Plan != nil {
+		fmt.Printf("  Plan: %s, %s\n", countNoun(len(result.Plan.Phases), "phase"), countNoun(len(result.Plan.FileTree.Files), "file"))
+		fmt.Printf("  Prompts: %s built", countNoun(result.Prompts, "source file prompt"))
+		if result.LargestPrompt != "" {
+			fmt.Printf(", largest ~%d tokens (%s)", result.LargestPromptTokens, result.LargestPrompt)
+		}
+		if result.ContextWindow > 0 {
+			fmt.Printf(", context window %d tokens", result.ContextWindow)
+		}
+		fmt.Println()
+	}
+
+	if !result.Passed() {
+		fmt.Printf("\nCheck failed with %s:\n", countNoun(len(result.Problems), "problem"))
+		for _, problem := range result.Problems {
+			fmt.Printf("  - %s\n", problem)
+		}
+		err := fmt.Errorf("check found %s", countNoun(len(result.Problems), "problem"))
+		if planCtx.Err() != nil {
+			err = phaseError(planCtx, "check", "plan", cfg.Timeouts.Plan, err)
+		}
+		return ExitError{Code: ExitCodeGenerationError, Err: err}
+	}
 
+	fmt.Printf("\nCheck passed\n")
 	return nil
 }
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	impactFCS    string
+	impactOutput string
+)
+
+var impactCmd = &cobra.Command{
+	Use:   "impact",
+	Short: "Report what regenerating against an edited FCS would redo",
+	Long: `Report the impact of an edited FCS before regenerating.
+
+The new FCS is compared with the FCS stored by the last incremental
+generation in <output>/.gocreator/state.json, using the same change detection
+and file dependency graph as 'gocreator generate --incremental'. The report
+lists the changes, the files and packages that would be regenerated, and the
+projected LLM calls, tokens and cost for the configured model. No LLM calls are
+made and no files are written.
+
+Files the planner would add for new entities or requirements are not known
+before planning and are not included in the estimate.
+
+Options:
+  --fcs     Edited FCS JSON file (required)
+  --output  Generated project with incremental state (default: ./generated)
+
+Example:
+  # Decide whether a spec tweak is worth a run
+  gocreator impact --fcs new.fcs.json --output ./my-project`,
+	Args: cobra.NoArgs,
+	RunE: runImpact,
+}
+
+func setupImpactFlags() {
+	impactCmd.Flags().StringVar(&impactFCS, "fcs", "", "edited FCS JSON file")
+	impactCmd.Flags().StringVarP(&impactOutput, "output", "o", "./generated", "generated project with incremental state")
+	_ = impactCmd.MarkFlagRequired("fcs")
+}
+
+func runImpact(cmd *cobra.Command, _ []string) error {
+	fcs, err := readFCS(impactFCS)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load FCS")
+		return ExitError{Code: ExitCodeSpecError, Err: err}
+	}
+
+	outputDir, err := resolveOutputDir(impactOutput, cmd.Flags().Changed("output"), fcs)
+	if err != nil {
+		return err
+	}
+
+	statePath := filepath.Join(outputDir, ".gocreator", "state.json")
+	if _, err := os.Stat(statePath); err != nil {
+		err = fmt.Errorf("no incremental state in %s; generate the project with --incremental first", outputDir)
+		log.Error().Err(err).Msg("Failed to load incremental state")
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+	state, err := generate.NewIncrementalStateManager(outputDir).Load()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load incremental state")
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+
+	report, err := generate.AnalyzeImpact(state, fcs, codeEstimate(cfg))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to analyze impact")
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+
+	printImpactReport(report, cfg.LLM.Model)
+	return nil
+}
+
+// printImpactReport prints the changes, affected files and estimate of a report
+func printImpactReport(r *generate.ImpactReport, model string) {
+	if !r.Changes.HasChanges {
+		fmt.Println("No changes; regeneration would not touch any files")
+		return
+	}
+
+	fmt.Println("Changes")
+	for _, line := range impactChangeLines(r.Changes) {
+		fmt.Printf("  %s\n", line)
+	}
+	if r.FullRebuild {
+		fmt.Printf("  All files would be regenerated: %s\n", r.FullRebuildReason)
+	}
+
+	fmt.Printf("\nAffected files (%d of %d)\n", len(r.Files), r.TotalFiles)
+	for i, file := range r.Files {
+		branch, _ := treeBranch(i, len(r.Files))
+		fmt.Printf("%s %s", branch, filepath.ToSlash(file.Path))
+		switch {
+		case file.Template:
+			fmt.Printf(" [template]")
+		case len(file.Entities) > 0:
+			fmt.Printf(" (%s)", strings.Join(file.Entities, ", "))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("\nPackages rebuilt (%d)\n", len(r.Packages))
+	for i, pkg := range r.Packages {
+		branch, _ := treeBranch(i, len(r.Packages))
+		fmt.Printf("%s %s\n", branch, pkg)
+	}
+
+	fmt.Printf("\nEstimate")
+	if model != "" {
+		fmt.Printf(" (%s)", model)
+	}
+	fmt.Printf(": %d LLM calls, ~%d input + ~%d output tokens", r.Estimate.Calls, r.Estimate.InputTokens, r.Estimate.OutputTokens)
+	if r.Estimate.CostUSD > 0 {
+		fmt.Printf(", ~$%.2f", r.Estimate.CostUSD)
+	}
+	fmt.Println()
+	if len(r.Changes.AddedEntities) > 0 || len(r.Changes.AddedRequirements) > 0 {
+		fmt.Println("  Files the planner adds for new entities or requirements are not included")
+	}
+}
+
+// impactChangeLines summarizes the detected changes, one kind per line
+func impactChangeLines(c *generate.FCSChanges) []string {
+	var lines []string
+	add := func(kind string, added, modified, deleted int) {
+		var parts []string
+		for _, part := range []struct {
+			n    int
+			verb string
+		}{{added, "added"}, {modified, "modified"}, {deleted, "deleted"}} {
+			if part.n > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", part.n, part.verb))
+			}
+		}
+		if len(parts) > 0 {
+			lines = append(lines, fmt.Sprintf("%-24s %s", kind, strings.Join(parts, ", ")))
+		}
+	}
+	add("Requirements", len(c.AddedRequirements), len(c.ModifiedRequirements), len(c.DeletedRequirements))
+	add("Non-functional", len(c.AddedNonFunctionalRequirements), len(c.ModifiedNonFunctionalRequirements), len(c.DeletedNonFunctionalRequirements))
+	add("Packages", len(c.AddedPackages), len(c.ModifiedPackages), len(c.DeletedPackages))
+	add("Entities", len(c.AddedEntities), len(c.ModifiedEntities), len(c.DeletedEntities))
+	add("API contracts", len(c.AddedAPIContracts), len(c.ModifiedAPIContracts), len(c.DeletedAPIContracts))
+	if c.ArchitectureChanged {
+		lines = append(lines, "Architecture changed")
+	}
+	if c.BuildConfigChanged {
+		lines = append(lines, "Build config changed")
+	}
+	if c.CrossCuttingChanged {
+		lines = append(lines, "Cross-cutting concerns changed")
+	}
+	return lines
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"runtime/metrics"
+
+	"github.com/dshills/gocreator/internal/config"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/spf13/cobra"
+)
+
+// errMaxDuration is the cause of a run context that reached limits.max_duration
+var errMaxDuration = errors.New("run exceeded limits.max_duration")
+
+// heapMetric is the live heap size sampled against limits.soft_memory_mb
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// runLimitCancel releases the deadline set by applyRunLimits
+var runLimitCancel context.CancelFunc = func() {}
+
+// applyRunLimits bounds the command's context by limits.max_duration. When it
+// passes, in-flight requests are cancelled and each phase stops at its last
+// checkpoint, so the run can be continued with --resume.
+func applyRunLimits(cmd *cobra.Command) {
+	if cfg.Limits.MaxDuration <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeoutCause(cmd.Context(), cfg.Limits.MaxDuration, errMaxDuration)
+	cmd.SetContext(ctx)
+	runLimitCancel = cancel
+}
+
+// runLimitReached reports whether ctx ended because of limits.max_duration
+func runLimitReached(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errMaxDuration)
+}
+
+// memoryThrottle returns the throttle that sends LLM requests one at a time
+// while the heap is over limits.soft_memory_mb, or nil when it is not set
+func memoryThrottle(cfg *config.Config) *llm.Throttle {
+	if cfg.Limits.SoftMemoryMB <= 0 {
+		return nil
+	}
+	limit := uint64(cfg.Limits.SoftMemoryMB) << 20
+	return llm.NewThrottle("heap over limits.soft_memory_mb", func() bool {
+		sample := []metrics.Sample{{Name: heapMetric}}
+		metrics.Read(sample)
+		return sample[0].Value.Kind() == metrics.KindUint64 && sample[0].Value.Uint64() > limit
+	})
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/dshills/gocreator/internal/config"
@@ -21,6 +25,11 @@ var (
 	cfgFile   string
 	logLevel  string
 	logFormat string
+	logFile   string
+	noCache   bool
+
+	// logFileWriter receives JSON log lines when --log-file is set
+	logFileWriter *os.File
 
 	// Global config
 	cfg *config.Config
@@ -28,7 +37,27 @@ var (
 
 func main() {
 	setupCommands()
-	if err := rootCmd.Execute(); err != nil {
+
+	// Every phase derives its context from this one, so an interrupt cancels
+	// in-flight LLM calls and commands. A second interrupt exits immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	executed, err := rootCmd.ExecuteContextC(ctx)
+	if err != nil && executed != nil && runLimitReached(executed.Context()) {
+		log.Error().
+			Str("max_duration", cfg.Limits.MaxDuration.String()).
+			Msg("Run stopped at limits.max_duration; rerun with --resume to continue from the last checkpoint")
+	}
+	runLimitCancel()
+	stop()
+	if logFileWriter != nil {
+		_ = logFileWriter.Close()
+	}
+	if err != nil {
 		os.Exit(1)
 	}
 }
@@ -47,8 +76,8 @@ It follows a three-phase workflow:
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
-		// Skip for version command
-		if cmd.Name() == "version" {
+		// Skip for the version command and completion scripts
+		if cmd.Name() == "version" || (cmd.HasParent() && cmd.Parent().Name() == "completion") {
 			return nil
 		}
 
@@ -57,14 +86,24 @@ It follows a three-phase workflow:
 			return fmt.Errorf("failed to initialize logging: %w", err)
 		}
 
-		// Load configuration
+		// Load configuration; doctor validates it as one of its checks
 		var err error
-		cfg, err = config.Load(cfgFile)
+		if cmd.Name() == "doctor" {
+			cfg, err = config.LoadUnvalidated(cfgFile)
+		} else {
+			cfg, err = config.Load(cfgFile)
+		}
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to load configuration")
 			return fmt.Errorf("failed to load configuration: %w", err)
 		}
 
+		// Bound the whole command by limits.max_duration; the server and
+		// watch bound each of their runs instead
+		if cmd.Name() != "serve" && cmd.Name() != "watch" {
+			applyRunLimits(cmd)
+		}
+
 		// Override log level from config if not set via flag
 		if cmd.Flags().Changed("log-level") {
 			// Use flag value
@@ -92,6 +131,8 @@ func setupCommands() {
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default: .gocreator.yaml)")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "log level (debug, info, warn, error)")
 	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "console", "log format (console, json)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "also append logs to this file as JSON lines")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "send every LLM request, bypassing the response cache")
 
 	// Setup command-specific flags
 	setupVersionFlags()
@@ -100,6 +141,20 @@ func setupCommands() {
 	setupValidateFlags()
 	setupFullFlags()
 	setupDumpFCSFlags()
+	setupDoctorFlags()
+	setupApplyFlags()
+	setupDebugFlags()
+	setupUpgradeFlags()
+	setupPlanFlags()
+	setupImpactFlags()
+	setupRetryFailedFlags()
+	setupResumeFlags()
+	setupManFlags()
+	setupManifestFlags()
+	setupValidateFCSFlags()
+	setupServeFlags()
+	setupWatchFlags()
+	setupFCSFlags()
 
 	// Add subcommands
 	rootCmd.AddCommand(versionCmd)
@@ -108,6 +163,23 @@ func setupCommands() {
 	rootCmd.AddCommand(validateCmd)
 	rootCmd.AddCommand(fullCmd)
 	rootCmd.AddCommand(dumpFCSCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(debugCmd)
+	rootCmd.AddCommand(upgradeCmd)
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(impactCmd)
+	rootCmd.AddCommand(retryFailedCmd)
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(manCmd)
+	rootCmd.AddCommand(manifestCmd)
+	rootCmd.AddCommand(validateFCSCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(fcsCmd)
+
+	// Dynamic completion for flag values and arguments
+	setupCompletions()
 
 	// Set version template
 	rootCmd.SetVersionTemplate(fmt.Sprintf("GoCreator v%s\n", version))
@@ -115,16 +187,30 @@ func setupCommands() {
 
 func initLogging() error {
 	// Configure output
+	var output io.Writer = os.Stderr
 	if logFormat == "console" {
-		output := zerolog.ConsoleWriter{
+		output = zerolog.ConsoleWriter{
 			Out:        os.Stderr,
 			TimeFormat: time.RFC3339,
 		}
-		log.Logger = zerolog.New(output).With().Timestamp().Logger()
-	} else {
-		log.Logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
 	}
 
+	// The log file always gets JSON lines, whatever the console format, so
+	// runs can be filtered by run_id and task_id afterwards
+	if logFile != "" && logFileWriter == nil {
+		//nolint:gosec // G304: Log file path is provided by the user
+		file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		logFileWriter = file
+	}
+	if logFileWriter != nil {
+		output = zerolog.MultiLevelWriter(output, logFileWriter)
+	}
+
+	log.Logger = zerolog.New(output).With().Timestamp().Logger()
+
 	// Set log level
 	level, err := parseLogLevel(logLevel)
 	if err != nil {
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dshills/gocreator/internal/cli"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var manOutput string
+
+var manCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Generate man pages for every command",
+	Long: `Generate roff man pages from the command tree, one per command
+(gocreator.1, gocreator-generate.1, gocreator-debug-state.1, ...).
+
+Example:
+  gocreator man --output ./man
+  man ./man/gocreator-generate.1`,
+	Args: cobra.NoArgs,
+	RunE: runMan,
+}
+
+func setupManFlags() {
+	manCmd.Flags().StringVarP(&manOutput, "output", "o", "./man", "directory to write the man pages to")
+}
+
+func runMan(_ *cobra.Command, _ []string) error {
+	if err := os.MkdirAll(manOutput, 0o755); err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to create %s: %w", manOutput, err)}
+	}
+
+	date, err := time.Parse(time.RFC3339, buildDate)
+	if err != nil {
+		date = time.Now()
+	}
+	header := cli.ManHeader{
+		Source: "GoCreator " + version,
+		Manual: "GoCreator Manual",
+		Date:   date,
+	}
+	if err := cli.WriteManPages(rootCmd, manOutput, header); err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+
+	log.Info().Str("output", manOutput).Msg("Man pages written")
+	return nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	manifestVerifyAll bool
+	manifestDiffAll   bool
+)
+
+var manifestCmd = &cobra.Command{
+	Use:   "manifest",
+	Short: "Inspect the generation manifest of a project",
+}
+
+var manifestVerifyCmd = &cobra.Command{
+	Use:   "verify <project-root>",
+	Short: "Re-check generated files against the checksums in the manifest",
+	Long: `Re-check every file recorded in <project-root>/.gocreator/manifest.json.
+
+Each generation records the checksum of every file it wrote, together with
+its provenance: the gocreator version, the provider and model, the
+temperature, and the SHA-256 of the prompt each LLM-written file was
+generated from. Verify recomputes the checksums and reports each file as
+  intact    Content matches what was generated
+  modified  Changed since generation
+  missing   Deleted since generation
+
+Comparing the provenance of two runs shows whether differing output comes
+from a new gocreator version, another model or a changed prompt.
+
+Options:
+  --all  Also list intact files
+
+Example:
+  gocreator manifest verify ./my-project`,
+	Args: cobra.ExactArgs(1),
+	RunE: runManifestVerify,
+}
+
+var manifestDiffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "Compare the generation manifests of two runs",
+	Long: `Compare the files recorded in two generation manifests, for example of two
+runs of the same specification. Each argument is a project root or a
+manifest file, such as a copy of .gocreator/manifest.json kept from an
+earlier run.
+
+Each manifest entry records the plan task that generated the file, the
+SHA-256 of its prompt, the model, estimated input and output tokens, the
+checksum of the content as generated and when generation started and
+finished. Diff reports each file as
+  added      Recorded only in <new>
+  removed    Recorded only in <old>
+  changed    Generated differently, with what differs: content, model,
+             prompt, generator (gocreator version) or owner
+  unchanged  Generated alike; listed with --all
+
+Timestamps, task IDs and token counts differ between any two runs and are
+not compared.
+
+Options:
+  --all  Also list unchanged files
+
+Example:
+  cp ./my-project/.gocreator/manifest.json manifest-v1.json
+  gocreator generate spec.yaml --output ./my-project
+  gocreator manifest diff manifest-v1.json ./my-project`,
+	Args: cobra.ExactArgs(2),
+	RunE: runManifestDiff,
+}
+
+func setupManifestFlags() {
+	manifestVerifyCmd.Flags().BoolVar(&manifestVerifyAll, "all", false, "also list intact files")
+	manifestDiffCmd.Flags().BoolVar(&manifestDiffAll, "all", false, "also list unchanged files")
+
+	manifestCmd.AddCommand(manifestVerifyCmd)
+	manifestCmd.AddCommand(manifestDiffCmd)
+}
+
+func runManifestVerify(cmd *cobra.Command, args []string) error {
+	projectRoot := args[0]
+
+	manifest, checks, err := generate.VerifyManifest(cmd.Context(), projectRoot)
+	if err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+
+	fmt.Printf("Manifest: %s (%s)\n", generate.ManifestPath(projectRoot), countNoun(len(checks), "file"))
+	if p := manifest.Provenance; p != nil {
+		fmt.Printf("Last generated by %s with %s/%s (temperature %g)\n", describeGenerator(p.GeneratorVersion), p.Provider, p.Model, p.Temperature)
+		for _, phase := range []string{"planner", "tester"} {
+			if model, ok := p.PhaseModels[phase]; ok {
+				fmt.Printf("  %s model: %s\n", phase, model)
+			}
+		}
+	}
+	fmt.Println()
+
+	counts := make(map[generate.ManifestFileStatus]int)
+	for _, check := range checks {
+		counts[check.Status]++
+		if check.Status == generate.ManifestFileIntact && !manifestVerifyAll {
+			continue
+		}
+
+		marker := "✗"
+		if check.Status == generate.ManifestFileIntact {
+			marker = "✓"
+		}
+		fmt.Printf("  %s %-8s %s", marker, check.Status, check.Path)
+		if check.Entry.Model != "" {
+			fmt.Printf(" (%s)", check.Entry.Model)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("\n%d intact, %d modified, %d missing\n",
+		counts[generate.ManifestFileIntact], counts[generate.ManifestFileModified], counts[generate.ManifestFileMissing])
+
+	log.Info().
+		Str("project_root", projectRoot).
+		Int("intact", counts[generate.ManifestFileIntact]).
+		Int("modified", counts[generate.ManifestFileModified]).
+		Int("missing", counts[generate.ManifestFileMissing]).
+		Msg("Manifest verified")
+
+	if changed := counts[generate.ManifestFileModified] + counts[generate.ManifestFileMissing]; changed > 0 {
+		return ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("%s changed since generation", countNoun(changed, "file"))}
+	}
+	return nil
+}
+
+// describeGenerator names a recorded generator version
+func describeGenerator(version string) string {
+	if version == "" {
+		return "an unknown gocreator version"
+	}
+	return "gocreator " + version
+}
+
+func runManifestDiff(_ *cobra.Command, args []string) error {
+	oldPath, newPath := manifestFilePath(args[0]), manifestFilePath(args[1])
+	older, err := generate.LoadManifestFile(oldPath)
+	if err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("%s: %w", oldPath, err)}
+	}
+	newer, err := generate.LoadManifestFile(newPath)
+	if err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("%s: %w", newPath, err)}
+	}
+
+	fmt.Printf("Comparing %s with %s\n", oldPath, newPath)
+	printProvenanceDiff(older.Provenance, newer.Provenance)
+	fmt.Println()
+
+	counts := make(map[generate.ManifestChange]int)
+	for _, diff := range generate.DiffManifests(older, newer) {
+		counts[diff.Change]++
+		switch diff.Change {
+		case generate.ManifestFileAdded:
+			fmt.Printf("  + %-9s %s", diff.Change, diff.Path)
+			if diff.New.Model != "" {
+				fmt.Printf(" (%s)", diff.New.Model)
+			}
+			fmt.Println()
+		case generate.ManifestFileRemoved:
+			fmt.Printf("  - %-9s %s\n", diff.Change, diff.Path)
+		case generate.ManifestFileChanged:
+			fmt.Printf("  ~ %-9s %s: %s\n", diff.Change, diff.Path, strings.Join(diff.Fields, ", "))
+		default:
+			if manifestDiffAll {
+				fmt.Printf("    %-9s %s\n", diff.Change, diff.Path)
+			}
+		}
+	}
+
+	fmt.Printf("\n%d added, %d removed, %d changed, %d unchanged\n",
+		counts[generate.ManifestFileAdded], counts[generate.ManifestFileRemoved],
+		counts[generate.ManifestFileChanged], counts[generate.ManifestFileUnchanged])
+	return nil
+}
+
+// manifestFilePath returns the manifest of a project root, or path itself
+// when it names a file
+func manifestFilePath(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return generate.ManifestPath(path)
+	}
+	return path
+}
+
+// printProvenanceDiff prints the run settings that differ between two
+// manifests
+func printProvenanceDiff(older, newer *models.Provenance) {
+	if older == nil || newer == nil {
+		return
+	}
+	if older.GeneratorVersion != newer.GeneratorVersion {
+		fmt.Printf("Generator: %s → %s\n", describeGenerator(older.GeneratorVersion), describeGenerator(newer.GeneratorVersion))
+	}
+	if oldModel, newModel := older.Provider+"/"+older.Model, newer.Provider+"/"+newer.Model; oldModel != newModel {
+		fmt.Printf("Model: %s → %s\n", oldModel, newModel)
+	}
+	if older.Temperature != newer.Temperature {
+		fmt.Printf("Temperature: %g → %g\n", older.Temperature, newer.Temperature)
+	}
+	for _, phase := range []string{"planner", "tester"} {
+		if oldModel, newModel := older.PhaseModels[phase], newer.PhaseModels[phase]; oldModel != newModel {
+			fmt.Printf("%s model: %s → %s\n", strings.ToUpper(phase[:1])+phase[1:], describePhaseModel(oldModel), describePhaseModel(newModel))
+		}
+	}
+}
+
+// describePhaseModel names a recorded phase model
+func describePhaseModel(model string) string {
+	if model == "" {
+		return "the run's model"
+	}
+	return model
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// generatePersistenceCode generates the database code of a project whose
+// repositories use sqlc or ent, so the build finds it: sqlc generate for a
+// project with a sqlc.yaml, go generate ./ent for one with an
+// ent/generate.go. Without sqlc on PATH it prints how to generate the code
+// and leaves the build to report the missing package.
+func generatePersistenceCode(ctx context.Context, projectRoot string) {
+	if _, err := os.Stat(filepath.Join(projectRoot, "sqlc.yaml")); err == nil {
+		sqlc, err := exec.LookPath("sqlc")
+		if err != nil {
+			fmt.Printf("  ! sqlc not found in PATH; install it and run `make sqlc` to generate the database code\n")
+		} else {
+			//nolint:gosec // G204: Subprocess launched with sqlc - required to compile the project's queries
+			runCodeGenerator(exec.CommandContext(ctx, sqlc, "generate"), projectRoot, "sqlc generate", "database code with sqlc")
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(projectRoot, "ent", "generate.go")); err == nil {
+		runCodeGenerator(exec.CommandContext(ctx, "go", "generate", "./ent"), projectRoot, "go generate ./ent", "ent client")
+	}
+}
+
+// runCodeGenerator runs a code generator in the project root and reports
+// the outcome
+func runCodeGenerator(cmd *exec.Cmd, projectRoot, name, generated string) {
+	cmd.Dir = projectRoot
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Warn().Err(err).Str("output", strings.TrimSpace(string(output))).Msgf("%s failed", name)
+		fmt.Printf("  ✗ %s failed: %s\n", name, strings.TrimSpace(string(output)))
+		return
+	}
+	fmt.Printf("  ✓ Generated %s\n", generated)
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/config"
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/plangraph"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	planShowFCS   string
+	planExportDOT string
+	planExportMMD string
+	planOutput    string
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan [plan.json]",
+	Short: "Inspect generation plans",
+	Long: `Inspect generation plans and export their dependency graphs.
+
+With --export-dot or --export-mermaid, the phase and task dependency graph of
+a plan is written for Graphviz or Mermaid, as 'plan show' does. The plan is
+read from plan.json, or else taken from the most recent run recorded in the
+output directory.
+
+Options:
+  --export-dot      Write the dependency graph as Graphviz DOT to a file
+  --export-mermaid  Write the dependency graph as a Mermaid flowchart to a file
+  --output, -o      Output directory of the run whose plan is exported (default: ./generated)
+
+Example:
+  # Render the dependency graph of the last run's plan
+  gocreator plan --export-dot plan.dot --output ./my-project && dot -Tsvg plan.dot > plan.svg
+
+  # Or of a saved plan
+  gocreator plan plan.json --export-mermaid plan.mmd`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPlanExport,
+}
+
+var planShowCmd = &cobra.Command{
+	Use:   "show <plan.json>",
+	Short: "Show a generation plan as a tree",
+	Long: `Show a generation plan as a tree for review before a run.
+
+The plan is printed as its phases in execution order, with their dependencies
+and task counts by type, and the number of dependency levels the files are
+generated in, followed by the planned files grouped by package directory. Each file is marked as rendered from a template or written by the
+LLM, and the plan ends with the projected token usage and cost for the
+configured model.
+
+A plan is recorded with every generation run and can be extracted with
+'gocreator debug state <run-id> --field plan'.
+
+The phase and task dependency graph can also be exported for Graphviz or
+Mermaid. Each phase is a cluster of its tasks, phase dependencies are edges,
+and each file task is labeled with its dependency level: the files of a level
+are generated in parallel, after the files of the levels before it. Other
+tasks, such as commands, are dashed.
+
+Options:
+  --fcs             FCS JSON file; includes the specification in the input token estimate
+  --export-dot      Write the dependency graph as Graphviz DOT to a file
+  --export-mermaid  Write the dependency graph as a Mermaid flowchart to a file
+
+Example:
+  # Save the plan of the last run and review it
+  gocreator debug state latest --output ./my-project --field plan > plan.json
+  gocreator plan show plan.json --fcs ./my-project/.gocreator/fcs.json
+
+  # Render the dependency graph
+  gocreator plan show plan.json --export-dot plan.dot && dot -Tsvg plan.dot > plan.svg`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPlanShow,
+}
+
+func setupPlanFlags() {
+	planShowCmd.Flags().StringVar(&planShowFCS, "fcs", "", "FCS JSON file included in the input token estimate")
+	planCmd.PersistentFlags().StringVar(&planExportDOT, "export-dot", "", "write the dependency graph as Graphviz DOT to this file")
+	planCmd.PersistentFlags().StringVar(&planExportMMD, "export-mermaid", "", "write the dependency graph as a Mermaid flowchart to this file")
+	planCmd.Flags().StringVarP(&planOutput, "output", "o", "./generated", "output directory of the run whose plan is exported")
+
+	planCmd.AddCommand(planShowCmd)
+}
+
+func runPlanShow(_ *cobra.Command, args []string) error {
+	plan, err := readPlan(args[0])
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load plan")
+		return ExitError{Code: ExitCodeSpecError, Err: err}
+	}
+
+	var fcs *models.FinalClarifiedSpecification
+	if planShowFCS != "" {
+		if fcs, err = readFCS(planShowFCS); err != nil {
+			log.Error().Err(err).Msg("Failed to load FCS")
+			return ExitError{Code: ExitCodeSpecError, Err: err}
+		}
+	}
+
+	estimate := generate.EstimateConfig{}
+	if cfg != nil {
+		estimate = codeEstimate(cfg)
+	}
+
+	printPlanSummary(generate.SummarizePlan(plan, fcs, estimate))
+	if planExportDOT != "" || planExportMMD != "" {
+		fmt.Println()
+	}
+	return exportPlanGraphs(plan)
+}
+
+func runPlanExport(cmd *cobra.Command, args []string) error {
+	if planExportDOT == "" && planExportMMD == "" {
+		return cmd.Help()
+	}
+
+	if len(args) == 0 {
+		plan, err := latestRunPlan(planOutput)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to load plan of the last run")
+			return ExitError{Code: ExitCodeFileSystemError, Err: err}
+		}
+		return exportPlanGraphs(plan)
+	}
+
+	plan, err := readPlan(args[0])
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load plan")
+		return ExitError{Code: ExitCodeSpecError, Err: err}
+	}
+	return exportPlanGraphs(plan)
+}
+
+// latestRunPlan returns the plan of the most recent run recorded in outputDir
+func latestRunPlan(outputDir string) (*models.GenerationPlan, error) {
+	runs, err := generate.ListStateRuns(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("no recorded runs in %s; pass a plan file", outputDir)
+	}
+	transitions, err := generate.LoadStateTransitions(outputDir, runs[0])
+	if err != nil {
+		return nil, err
+	}
+	plan := generate.ReplayState(transitions, len(transitions)-1).Plan
+	if plan == nil {
+		return nil, fmt.Errorf("run %s did not get to plan", runs[0])
+	}
+	return plan, nil
+}
+
+// exportPlanGraphs writes the dependency graph of plan to the files the
+// export flags name
+func exportPlanGraphs(plan *models.GenerationPlan) error {
+	exports := []struct {
+		path  string
+		write func(io.Writer, *models.GenerationPlan) error
+	}{
+		{planExportDOT, plangraph.WriteDOT},
+		{planExportMMD, plangraph.WriteMermaid},
+	}
+	for _, export := range exports {
+		if export.path == "" {
+			continue
+		}
+		if err := exportPlanGraph(export.path, plan, export.write); err != nil {
+			log.Error().Err(err).Str("path", export.path).Msg("Failed to export plan graph")
+			return ExitError{Code: ExitCodeFileSystemError, Err: err}
+		}
+		fmt.Printf("Dependency graph written to %s\n", export.path)
+	}
+	return nil
+}
+
+// exportPlanGraph writes the dependency graph of plan to a file
+func exportPlanGraph(graphPath string, plan *models.GenerationPlan, write func(io.Writer, *models.GenerationPlan) error) error {
+	//nolint:gosec // G304: Writing user-provided output file - required for CLI functionality
+	file, err := os.Create(graphPath)
+	if err != nil {
+		return fmt.Errorf("failed to create graph file: %w", err)
+	}
+	if err := write(file, plan); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write graph file: %w", err)
+	}
+	return file.Close()
+}
+
+// codeEstimate returns the estimate settings of the model that generates
+// source files: models.coder when set, llm.model otherwise
+func codeEstimate(cfg *config.Config) generate.EstimateConfig {
+	provider, model := cfg.LLM.Provider, cfg.LLM.Model
+	if coder := cfg.Models.Coder; coder.Enabled() {
+		model = coder.Model
+		if coder.Provider != "" {
+			provider = coder.Provider
+		}
+	}
+	return generate.EstimateConfig{
+		Provider: provider,
+		Model:    model,
+		Pricing:  llm.PricingFor(llm.Provider(provider), model),
+	}
+}
+
+// readPlan reads a generation plan from a JSON file
+func readPlan(planPath string) (*models.GenerationPlan, error) {
+	//nolint:gosec // G304: Reading user-provided plan file - required for CLI functionality
+	data, err := os.ReadFile(planPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	var plan models.GenerationPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file %s: %w", planPath, err)
+	}
+	if len(plan.Phases) == 0 && len(plan.FileTree.Files) == 0 {
+		return nil, fmt.Errorf("%s contains no phases or files; is it a generation plan?", planPath)
+	}
+
+	return &plan, nil
+}
+
+// printPlanSummary prints the phases and packages of a plan as trees
+func printPlanSummary(s *generate.PlanSummary) {
+	fmt.Printf("Plan %s", s.ID)
+	if s.FCSID != "" {
+		fmt.Printf(" (FCS %s)", s.FCSID)
+	}
+	fmt.Printf("\n\n")
+
+	fmt.Printf("Phases (%d, %s)\n", len(s.Phases), countNoun(s.Tasks, "task"))
+	for i, phase := range s.Phases {
+		branch, indent := treeBranch(i, len(s.Phases))
+		fmt.Printf("%s %d. %s (%s)\n", branch, phase.Order, phase.Name, taskCounts(phase))
+		if len(phase.Dependencies) > 0 {
+			fmt.Printf("%s└── after %s\n", indent, strings.Join(phase.Dependencies, ", "))
+		}
+	}
+	if s.Levels > 0 {
+		fmt.Printf("Files generate in %s, up to %d in parallel\n", countNoun(s.Levels, "dependency level"), s.Parallel)
+	}
+
+	fmt.Printf("\nPackages (%d, %s: %d template, %d LLM)\n", len(s.Packages), countNoun(s.Files, "file"), s.Template, s.LLM)
+	for i, pkg := range s.Packages {
+		branch, indent := treeBranch(i, len(s.Packages))
+		fmt.Printf("%s %s (%s", branch, pkg.Dir, countNoun(len(pkg.Files), "file"))
+		if pkg.Template > 0 {
+			fmt.Printf(", %d template", pkg.Template)
+		}
+		fmt.Printf(")\n")
+		for j, file := range pkg.Files {
+			fileBranch, _ := treeBranch(j, len(pkg.Files))
+			source := "llm"
+			if file.Template {
+				source = "template"
+			}
+			fmt.Printf("%s%s %s [%s]\n", indent, fileBranch, path.Base(file.Path), source)
+		}
+	}
+
+	total := s.Estimate.Total()
+	fmt.Printf("\nEstimate")
+	if s.Estimate.Model != "" {
+		fmt.Printf(" (%s)", s.Estimate.Model)
+	}
+	fmt.Printf(": %d LLM calls, ~%d input + ~%d output tokens", total.Calls, total.InputTokens, total.OutputTokens)
+	if total.CostUSD > 0 {
+		fmt.Printf(", ~$%.2f", total.CostUSD)
+	}
+	fmt.Println()
+	for _, group := range s.Estimate.ByPhase() {
+		fmt.Printf("  %-18s %d calls, ~%d tokens\n", group.Name, group.Estimate.Calls, group.Estimate.InputTokens+group.Estimate.OutputTokens)
+	}
+}
+
+// treeBranch returns the branch for item i of n and the indent for its children
+func treeBranch(i, n int) (string, string) {
+	if i == n-1 {
+		return "└──", "    "
+	}
+	return "├──", "│   "
+}
+
+// taskCounts describes the tasks of a phase, e.g. "3 tasks: 2 generate_file, 1 run_command"
+func taskCounts(phase generate.PhaseSummary) string {
+	if phase.Tasks == 0 {
+		return "no tasks"
+	}
+	types := make([]string, 0, len(phase.TaskTypes))
+	for taskType := range phase.TaskTypes {
+		types = append(types, taskType)
+	}
+	sort.Strings(types)
+
+	counts := make([]string, 0, len(types))
+	for _, taskType := range types {
+		counts = append(counts, fmt.Sprintf("%d %s", phase.TaskTypes[taskType], taskType))
+	}
+	return fmt.Sprintf("%s: %s", countNoun(phase.Tasks, "task"), strings.Join(counts, ", "))
+}
+
+// countNoun formats n with noun, pluralized with an s
+func countNoun(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
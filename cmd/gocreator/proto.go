@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// generateProtoCode runs buf generate in a project with a buf.gen.yaml, so
+// the build finds the Go code generated from its .proto files. Without buf on
+// PATH it prints how to generate the code and leaves the build to report
+// the missing packages.
+func generateProtoCode(ctx context.Context, projectRoot string) {
+	if _, err := os.Stat(filepath.Join(projectRoot, "buf.gen.yaml")); err != nil {
+		return
+	}
+
+	buf, err := exec.LookPath("buf")
+	if err != nil {
+		fmt.Printf("  ! buf not found in PATH; install it and run `make proto` to generate the gRPC code\n")
+		return
+	}
+
+	//nolint:gosec // G204: Subprocess launched with buf - required to compile the project's .proto files
+	cmd := exec.CommandContext(ctx, buf, "generate")
+	cmd.Dir = projectRoot
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		log.Warn().Err(err).Str("output", strings.TrimSpace(string(output))).Msg("buf generate failed")
+		fmt.Printf("  ✗ buf generate failed: %s\n", strings.TrimSpace(string(output)))
+		return
+	}
+	fmt.Printf("  ✓ Generated gRPC code with buf\n")
+}
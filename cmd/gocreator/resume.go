@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var resumeOutput string
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume <run-id>",
+	Short: "Continue an interrupted generation run from its last checkpoint",
+	Long: `Continue a generation run that failed or was interrupted.
+
+Every 'gocreator generate' run saves the workflow state after each graph node
+to <output>/.gocreator/runs/<run-id>/checkpoint.json. Resuming loads that
+state, including the clarified specification and the code generated so far,
+and continues with the node that failed, or the node after the last one that
+finished. Nodes that finished are not run again, so their LLM calls are not
+repeated. The run ID is printed when generation fails; 'latest' selects the
+most recent run.
+
+The resumed run keeps its run ID: its state log continues, and the files are
+written and recorded in the manifest as a completed 'generate' run would.
+Runs that finished cannot be resumed; re-attempt the tasks they skipped with
+'gocreator retry-failed'.
+
+A run stopped by --max-cost or --max-tokens resumes with a fresh budget: the
+caps count only the LLM calls made after resuming.
+
+Example:
+  # Continue the last run after a network failure
+  gocreator resume latest --output ./my-project
+
+  # Continue a specific run
+  gocreator resume gen-1234 --output ./my-project`,
+	Args: cobra.ExactArgs(1),
+	RunE: runResume,
+}
+
+func setupResumeFlags() {
+	resumeCmd.Flags().StringVarP(&resumeOutput, "output", "o", "./generated", "output directory of the run")
+	addBudgetFlags(resumeCmd)
+}
+
+func runResume(cmd *cobra.Command, args []string) error {
+	outputDir := resumeOutput
+
+	runID := args[0]
+	if runID == "latest" {
+		runs, err := generate.ListStateRuns(outputDir)
+		if err != nil {
+			return ExitError{Code: ExitCodeFileSystemError, Err: err}
+		}
+		if len(runs) == 0 {
+			return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("no recorded runs in %s", outputDir)}
+		}
+		runID = runs[0]
+	}
+
+	checkpoint, err := generate.LoadRunCheckpoint(outputDir, runID)
+	if err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+	node := checkpoint.ResumeNode()
+	if node == "" {
+		return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("run %s already finished; re-attempt the tasks it skipped with 'gocreator retry-failed --run %s'", runID, runID)}
+	}
+
+	// A run over an incrementally generated project stays incremental
+	incremental := false
+	if _, err := os.Stat(filepath.Join(outputDir, ".gocreator", "state.json")); err == nil {
+		incremental = true
+	}
+
+	log.Info().
+		Str("run_id", runID).
+		Str("output", outputDir).
+		Str("checkpoint_node", checkpoint.Node).
+		Str("resume_node", node).
+		Bool("incremental", incremental).
+		Msg("Resuming generation run")
+
+	fmt.Printf("Resuming run %s at %s (last checkpoint: %s, %s)\n", runID, node, checkpoint.Node, checkpoint.SavedAt.Format("2006-01-02 15:04:05"))
+
+	if err := runGenerationWithProgress(cmd.Context(), nil, outputDir, incremental, runID); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nOutput written to: %s\n\n", outputDir)
+	fmt.Printf("Next steps:\n")
+	fmt.Printf("  cd %s\n", outputDir)
+	fmt.Printf("  go mod tidy\n")
+	fmt.Printf("  make test\n\n")
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	retryFailedOutput   string
+	retryFailedRun      string
+	retryFailedModel    string
+	retryFailedAttempts int
+	retryFailedDryRun   bool
+)
+
+var retryFailedCmd = &cobra.Command{
+	Use:   "retry-failed [task-id...]",
+	Short: "Re-attempt the tasks a finished generation run skipped",
+	Long: `Re-attempt only the failed tasks of a finished generation run.
+
+The run's recorded plan (<output>/.gocreator/runs/<run-id>/state.jsonl) is
+compared with the output directory and its manifest. Planned files that were
+never written, such as test files whose generation failed and was skipped, are
+failed tasks. Each is attempted again with fresh LLM calls, and the files that
+succeed are merged into the output directory, the manifest and, for
+incremental projects, the incremental state. Nothing else is regenerated.
+
+Pass task IDs or target paths to retry only those tasks; test tasks are named
+"test:<source file>". Runs that did not finish are continued with
+'gocreator resume <run-id>' instead.
+
+Options:
+  --output    Generated project (default: ./generated)
+  --run       Run ID to retry (default: the most recent run)
+  --model     Model to retry with (default: llm.model from the config)
+  --attempts  Attempts per task (default: 2)
+  --dry-run   List the failed tasks without retrying them
+
+Examples:
+  # Retry everything the last run skipped
+  gocreator retry-failed --output ./my-project
+
+  # Retry one test file with a stronger model
+  gocreator retry-failed test:internal/api/api.go --model claude-opus-4-1`,
+	RunE: runRetryFailed,
+}
+
+func setupRetryFailedFlags() {
+	retryFailedCmd.Flags().StringVarP(&retryFailedOutput, "output", "o", "./generated", "generated project")
+	retryFailedCmd.Flags().StringVar(&retryFailedRun, "run", "", "run ID to retry (default: the most recent run)")
+	retryFailedCmd.Flags().StringVar(&retryFailedModel, "model", "", "model to retry with (default: llm.model)")
+	retryFailedCmd.Flags().IntVar(&retryFailedAttempts, "attempts", generate.DefaultRetryAttempts, "attempts per task")
+	retryFailedCmd.Flags().BoolVar(&retryFailedDryRun, "dry-run", false, "list the failed tasks without retrying them")
+}
+
+func runRetryFailed(cmd *cobra.Command, args []string) error {
+	if retryFailedAttempts < 1 {
+		return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("--attempts must be at least 1")}
+	}
+	outputDir := retryFailedOutput
+
+	runID := retryFailedRun
+	if runID == "" {
+		runs, err := generate.ListStateRuns(outputDir)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to list recorded runs")
+			return ExitError{Code: ExitCodeFileSystemError, Err: err}
+		}
+		if len(runs) == 0 {
+			err := fmt.Errorf("no recorded runs in %s", outputDir)
+			log.Error().Err(err).Msg("Failed to find a run to retry")
+			return ExitError{Code: ExitCodeFileSystemError, Err: err}
+		}
+		runID = runs[0]
+	}
+
+	state, err := generate.LoadFinishedRun(outputDir, runID)
+	if err != nil {
+		log.Error().Err(err).Str("run_id", runID).Msg("Failed to load run")
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+	failed, err := generate.FindFailedTasks(state, outputDir)
+	if err != nil {
+		log.Error().Err(err).Str("run_id", runID).Msg("Failed to find failed tasks")
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+	failed, err = selectFailedTasks(failed, args)
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+
+	if len(failed) == 0 {
+		fmt.Printf("Run %s has no failed tasks\n", runID)
+		return nil
+	}
+	if retryFailedDryRun {
+		fmt.Printf("Run %s: %s to retry\n", runID, countNoun(len(failed), "failed task"))
+		for i, task := range failed {
+			branch, _ := treeBranch(i, len(failed))
+			fmt.Printf("%s %s -> %s\n", branch, task.ID, task.Target)
+		}
+		return nil
+	}
+
+	model := cfg.LLM.Model
+	if retryFailedModel != "" {
+		model = retryFailedModel
+	}
+	llmClient, err := newLLMClient(cfg, cfg.LLM.Provider, model, resolveAPIKey(cfg))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create LLM client")
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+
+	logger, err := fsops.NewFileLogger(filepath.Join(outputDir, ".gocreator", "logs"))
+	if err != nil {
+		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create file logger: %w", err)}
+	}
+	defer func() { _ = logger.Close() }()
+
+	fileOps, err := fsops.New(fsops.Config{
+		RootDir:        outputDir,
+		Logger:         logger,
+		ProtectedPaths: cfg.Project.ProtectedPaths,
+		MaxOpenFiles:   cfg.Limits.MaxOpenFiles,
+	})
+	if err != nil {
+		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create file operations handler: %w", err)}
+	}
+
+	preamble, err := cfg.Prompts.LoadPreamble()
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+	promptTemplates, err := loadPromptTemplates()
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+	postProcessor, err := loadPostProcessor()
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+
+	log.Info().
+		Str("run_id", runID).
+		Str("model", model).
+		Int("tasks", len(failed)).
+		Int("attempts", retryFailedAttempts).
+		Msg("Retrying failed tasks")
+
+	results, err := generate.RetryFailedTasks(cmd.Context(), generate.RetryConfig{
+		LLMClient:        llmClient,
+		FileOps:          fileOps,
+		OutputDir:        outputDir,
+		Attempts:         retryFailedAttempts,
+		Preamble:         preamble,
+		PromptTemplates:  promptTemplates,
+		Style:            cfg.Style.Guide(),
+		PostProcess:      postProcessor,
+		FixKnowledge:     loadFixKnowledge(),
+		GeneratorVersion: version,
+		Temperature:      llmTemperature,
+	}, state, failed)
+	printRetryResults(runID, results)
+	if err != nil {
+		log.Error().Err(err).Msg("Retry failed")
+		return ExitError{Code: ExitCodeGenerationError, Err: err}
+	}
+
+	remaining := 0
+	for _, result := range results {
+		if result.Err != nil {
+			remaining++
+		}
+	}
+	if remaining > 0 {
+		return ExitError{Code: ExitCodeGenerationError, Err: fmt.Errorf("%s still failing", countNoun(remaining, "task"))}
+	}
+	return nil
+}
+
+// selectFailedTasks keeps the failed tasks named by ID or target path, or all
+// of them when no names are given
+func selectFailedTasks(failed []generate.FailedTask, names []string) ([]generate.FailedTask, error) {
+	if len(names) == 0 {
+		return failed, nil
+	}
+	var selected []generate.FailedTask
+	for _, name := range names {
+		found := false
+		for _, task := range failed {
+			if task.ID == name || task.Target == filepath.ToSlash(filepath.Clean(name)) {
+				selected = append(selected, task)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%q is not a failed task of this run", name)
+		}
+	}
+	return selected, nil
+}
+
+// printRetryResults prints the outcome of each retried task
+func printRetryResults(runID string, results []generate.RetryResult) {
+	fmt.Printf("Run %s: retried %s\n", runID, countNoun(len(results), "task"))
+	for i, result := range results {
+		branch, _ := treeBranch(i, len(results))
+		status := "ok"
+		if result.Err != nil {
+			status = "failed: " + result.Err.Error()
+		}
+		fmt.Printf("%s %s -> %s (%s, %s)\n", branch, result.Task.ID, result.Task.Target, countNoun(result.Attempts, "attempt"), status)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dshills/gocreator/internal/clarify"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/server"
+	"github.com/dshills/gocreator/internal/spec"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// serveShutdownTimeout is how long the server waits for open requests once
+// it is interrupted
+const serveShutdownTimeout = 10 * time.Second
+
+var (
+	serveAddr        string
+	serveOutputRoot  string
+	serveToken       string
+	serveMaxParallel int
+	serveImportRoot  string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve clarification and generation jobs over an HTTP API",
+	Long: `Run GoCreator as a long-running HTTP server, so tools such as a developer
+portal can submit specs and follow their generation instead of running the CLI.
+
+Each submitted spec becomes a job. The server generates its clarification
+questions, waits for the answers, and generates the code into
+<output-root>/<job-id> once asked to. Progress events stream as server-sent
+events. Jobs are kept in memory only and do not survive a restart; their
+generated code does.
+
+Endpoints:
+  POST   /v1/jobs                 submit a spec: {"spec", "name" or "format",
+                                  "answers", "autonomous", "generate"}
+  GET    /v1/jobs                 list jobs
+  GET    /v1/jobs/{id}            job status
+  GET    /v1/jobs/{id}/questions  clarification questions
+  POST   /v1/jobs/{id}/answers    answer every question: {"answers": {...}}
+  POST   /v1/jobs/{id}/generate   start generation once clarified
+  GET    /v1/jobs/{id}/events     progress events (text/event-stream)
+  DELETE /v1/jobs/{id}            cancel a job, or forget a finished one
+  GET    /healthz                 health check, without a token
+
+Generation runs as 'gocreator generate' with the configuration the server
+was started with. The plan is not reviewed, and a crossed --max-cost or
+--max-tokens cap stops the job. limits.max_duration bounds each job rather
+than the server.
+
+Set --token, or GOCREATOR_SERVE_TOKEN, before listening beyond localhost:
+each job spends LLM budget.
+
+Submitted specs may only import fragments from the --import-root directory;
+absolute paths and paths leading out of it are refused with 400 Bad
+Request. Without --import-root, specs with imports are refused.
+
+Example:
+  GOCREATOR_SERVE_TOKEN=secret gocreator serve --addr :8080 --output-root /srv/gocreator
+  curl -H "Authorization: Bearer secret" -d '{"spec": "...", "format": "yaml", "autonomous": true, "generate": true}' \
+    http://localhost:8080/v1/jobs`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func setupServeFlags() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "address to listen on")
+	serveCmd.Flags().StringVar(&serveOutputRoot, "output-root", "./generated", "directory holding the generated code of each job")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "bearer token API requests must carry (default: $GOCREATOR_SERVE_TOKEN)")
+	serveCmd.Flags().IntVar(&serveMaxParallel, "max-parallel", 1, "jobs generating at once; the others queue")
+	serveCmd.Flags().StringVar(&serveImportRoot, "import-root", "", "directory submitted specs may import fragments from (default: imports refused)")
+	addBudgetFlags(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	llmClient, err := createLLMClient(cfg)
+	if err != nil {
+		return ExitError{Code: ExitCodeNetworkError, Err: fmt.Errorf("failed to create LLM client: %w", err)}
+	}
+	engine, err := clarify.NewEngine(clarify.EngineConfig{
+		LLMClient: llmClient,
+	})
+	if err != nil {
+		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create clarification engine: %w", err)}
+	}
+
+	token := serveToken
+	if token == "" {
+		token = os.Getenv("GOCREATOR_SERVE_TOKEN")
+	}
+
+	srv, err := server.NewServer(ctx, server.Config{
+		Engine: engine,
+		// The name and the imports are the client's; fragments are only read
+		// from the import root
+		Parse: func(name string, format models.SpecFormat, content string) (*models.InputSpecification, error) {
+			format, content, err := importSpecDocument(filepath.Base(name), format, content)
+			if err != nil {
+				return nil, err
+			}
+			return spec.ParseAndValidateWithinRoot(format, content, serveImportRoot)
+		},
+		Generate:       generateJob,
+		OutputRoot:     serveOutputRoot,
+		Token:          token,
+		MaxParallel:    serveMaxParallel,
+		ClarifyTimeout: cfg.Timeouts.Clarify,
+		MaxDuration:    cfg.Limits.MaxDuration,
+	})
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: err}
+	}
+
+	listener, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return ExitError{Code: ExitCodeNetworkError, Err: fmt.Errorf("failed to listen on %s: %w", serveAddr, err)}
+	}
+	if token == "" {
+		log.Warn().Str("addr", listener.Addr().String()).Msg("Serving without a token; anyone who can reach the server can start jobs")
+	}
+
+	httpServer := &http.Server{
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	log.Info().
+		Str("addr", listener.Addr().String()).
+		Str("output_root", serveOutputRoot).
+		Int("max_parallel", serveMaxParallel).
+		Msg("Serving GoCreator API")
+	fmt.Printf("Serving the GoCreator API on http://%s\n", listener.Addr())
+
+	select {
+	case err := <-serveErr:
+		return ExitError{Code: ExitCodeNetworkError, Err: fmt.Errorf("server failed: %w", err)}
+	case <-ctx.Done():
+	}
+
+	// Running jobs share the cancelled context, so they stop at their last
+	// checkpoint and their event streams end
+	fmt.Println("\nShutting down; running jobs stop at their last checkpoint")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		log.Warn().Err(err).Msg("Failed to shut down the server")
+	}
+	srv.Wait()
+	return nil
+}
+
+// generateJob runs the generation of a server job as 'gocreator generate'
+// does, without a progress display or prompts
+func generateJob(ctx context.Context, fcs *models.FinalClarifiedSpecification, outputDir string, events chan<- models.ProgressEvent) (*models.GenerationOutput, error) {
+	// The sinks configured under events receive the job's events too
+	sinks, err := eventSinks(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	bus := models.NewEventBus(append(sinks, eventForwarder(events))...)
+	jobEvents := make(chan models.ProgressEvent, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bus.Run(jobEvents)
+	}()
+	defer func() {
+		close(jobEvents)
+		<-done
+		if err := bus.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to deliver progress events")
+		}
+	}()
+
+	// Crossing a cost or token cap cancels the job at its last checkpoint
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+	budget, err := newCostGovernor(stop, jobEvents, false)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, closeEngine, err := newGenerationEngine(engineOptions{
+		outputDir: outputDir,
+		eventChan: jobEvents,
+		budget:    budget,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer closeEngine()
+
+	output, err := engine.Generate(ctx, fcs, outputDir)
+	if budgetErr := budget.Err(); err != nil && budgetErr != nil {
+		return output, budgetErr
+	}
+	return output, err
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/validate"
+)
+
+// checkResults holds the results of the validation checks of a run; a nil
+// result is a check that did not run
+type checkResults struct {
+	build        *models.BuildResult
+	vet          bool // go vet ran with the build
+	lint         *models.LintResult
+	test         *models.TestResult
+	requirements *models.RequirementCoverage
+	enums        *models.EnumUsage
+	middleware   *models.MiddlewareUsage
+	contracts    *models.ContractResult
+	style        *models.StyleResult
+	smoke        *models.SmokeResult
+	fuzz         *models.FuzzResult
+	buildFiles   *models.BuildFilesResult
+}
+
+// newSeverityPolicy creates the severity policy from validation.severity
+func newSeverityPolicy() (*validate.SeverityPolicy, error) {
+	severity := cfg.Validation.Severity
+	policy, err := validate.NewSeverityPolicy(severity.Checks, severity.LintRules, severity.FailOn)
+	if err != nil {
+		return nil, ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("validation.severity: %w", err)}
+	}
+	return policy, nil
+}
+
+// outcomes returns the outcome of each check that ran under the policy.
+// Coverage below validation.required_coverage is its own check, as are go
+// vet findings when vet ran.
+func (r checkResults) outcomes(policy *validate.SeverityPolicy) []validate.CheckOutcome {
+	var outcomes []validate.CheckOutcome
+	if r.build != nil {
+		outcomes = append(outcomes, policy.Outcome(validate.CheckBuild, r.build.Success))
+		if r.vet {
+			outcomes = append(outcomes, policy.Outcome(validate.CheckVet, len(validate.VetFindings(r.build)) == 0))
+		}
+	}
+	if r.lint != nil {
+		outcomes = append(outcomes, policy.LintOutcome(r.lint))
+	}
+	if r.test != nil {
+		outcomes = append(outcomes,
+			policy.Outcome(validate.CheckTest, r.test.Success),
+			policy.Outcome(validate.CheckCoverage, r.test.Coverage >= cfg.Validation.RequiredCoverage))
+	}
+	if r.requirements != nil {
+		outcomes = append(outcomes, policy.Outcome(validate.CheckRequirements, r.requirements.Success))
+	}
+	if r.enums != nil {
+		outcomes = append(outcomes, policy.Outcome(validate.CheckEnums, r.enums.Success))
+	}
+	if r.middleware != nil {
+		outcomes = append(outcomes, policy.Outcome(validate.CheckMiddleware, r.middleware.Success))
+	}
+	if r.contracts != nil {
+		outcomes = append(outcomes, policy.Outcome(validate.CheckOutputContracts, r.contracts.Success))
+	}
+	if r.style != nil {
+		outcomes = append(outcomes, policy.Outcome(validate.CheckStyle, r.style.Success))
+	}
+	if r.smoke != nil && !r.smoke.Skipped {
+		outcomes = append(outcomes, policy.Outcome(validate.CheckSmoke, r.smoke.Success))
+	}
+	if r.fuzz != nil && r.fuzz.Targets > 0 {
+		outcomes = append(outcomes, policy.Outcome(validate.CheckFuzz, r.fuzz.Success))
+	}
+	if r.buildFiles != nil && r.buildFiles.Checked() {
+		outcomes = append(outcomes, policy.Outcome(validate.CheckBuildFiles, r.buildFiles.Success))
+	}
+	return outcomes
+}
+
+// countChecks counts the checks that ran and passed; checks whose severity
+// is ignore are not counted
+func countChecks(outcomes []validate.CheckOutcome) (checksRun, checksPassed int) {
+	for _, outcome := range outcomes {
+		if outcome.Severity == validate.SeverityIgnore {
+			continue
+		}
+		checksRun++
+		if outcome.Passed {
+			checksPassed++
+		}
+	}
+	return checksRun, checksPassed
+}
+
+// printCheckOutcomes lists the failed checks that are reported, with their severity
+func printCheckOutcomes(outcomes []validate.CheckOutcome) {
+	for _, outcome := range outcomes {
+		if !outcome.Passed && outcome.Severity != validate.SeverityIgnore {
+			fmt.Printf("  %-7s %s\n", outcome.Severity, outcome.Check)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dshills/gocreator/internal/clarify"
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// withPhaseTimeout derives a phase context from the run context. A zero
+// timeout leaves the phase bounded only by the run context.
+func withPhaseTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// phaseError names the timeout setting when a phase ran out of time, or
+// reports an interrupted run, so the cause is not buried in a wrapped error
+func phaseError(ctx context.Context, phase, setting string, timeout time.Duration, err error) error {
+	switch {
+	case runLimitReached(ctx):
+		return fmt.Errorf("%s stopped at the run's %s limit (set limits.max_duration): %w", phase, cfg.Limits.MaxDuration, err)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%s exceeded its %s timeout (set timeouts.%s): %w", phase, timeout, setting, err)
+	case errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("%s interrupted: %w", phase, err)
+	default:
+		return err
+	}
+}
+
+// clarifySpec runs clarification under the configured clarify timeout
+func clarifySpec(ctx context.Context, engine clarify.Engine, inputSpec *models.InputSpecification, interactive bool) (*models.FinalClarifiedSpecification, error) {
+	ctx, cancel := withPhaseTimeout(ctx, cfg.Timeouts.Clarify)
+	defer cancel()
+
+	fcs, err := engine.Clarify(ctx, inputSpec, interactive)
+	if err != nil {
+		return nil, phaseError(ctx, "clarification", "clarify", cfg.Timeouts.Clarify, err)
+	}
+	return fcs, nil
+}
+
+// phaseTimeouts maps the configured generation timeouts onto the workflow
+// nodes. The config uses 0 to disable a limit; the graph uses a negative value.
+func phaseTimeouts() generate.PhaseTimeouts {
+	node := func(d time.Duration) time.Duration {
+		if d == 0 {
+			return -1
+		}
+		return d
+	}
+	return generate.PhaseTimeouts{
+		Plan:     node(cfg.Timeouts.Plan),
+		Packages: node(cfg.Timeouts.Code),
+		Tests:    node(cfg.Timeouts.Tests),
+		Docs:     node(cfg.Timeouts.Docs),
+		Config:   node(cfg.Timeouts.Config),
+	}
+}
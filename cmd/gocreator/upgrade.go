@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeDryRun bool
+	upgradeForce  bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade <project-root>",
+	Short: "Refresh template-owned files of a generated project with this version",
+	Long: `Upgrade a generated project to the templates of this gocreator version without LLM access.
+
+Every generation records the files it wrote in <project-root>/.gocreator/manifest.json,
+together with their checksums and the gocreator version. Upgrade only touches files
+in that manifest:
+  updated     Template-owned file (go.mod, Makefile, Dockerfile, .gitignore,
+              README.md) re-rendered from the current template
+  current     Template-owned file that already matches the current template
+  skipped     File edited or deleted since generation, or inside a protected path
+  regenerate  Prompt-owned file written by another gocreator version; it is
+              reported, not changed. Regenerate the project to pick up the
+              improved prompts.
+
+Files that are not in the manifest are never read or written.
+
+Options:
+  --dry-run  Report what would change without writing files
+  --force    Also re-render template-owned files edited since generation
+
+Example:
+  # Preview the upgrade
+  gocreator upgrade ./my-project --dry-run
+
+  # Upgrade template-owned files
+  gocreator upgrade ./my-project`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUpgrade,
+}
+
+func setupUpgradeFlags() {
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "report what would change without writing files")
+	upgradeCmd.Flags().BoolVar(&upgradeForce, "force", false, "re-render template-owned files edited since generation")
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	projectRoot := args[0]
+
+	log.Info().
+		Str("project_root", projectRoot).
+		Bool("dry_run", upgradeDryRun).
+		Bool("force", upgradeForce).
+		Msg("Upgrading generated project")
+
+	if info, err := os.Stat(projectRoot); err != nil || !info.IsDir() {
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("project root %s is not a directory", projectRoot)}
+	}
+
+	logger, err := fsops.NewFileLogger(filepath.Join(projectRoot, ".gocreator", "logs"))
+	if err != nil {
+		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create file logger: %w", err)}
+	}
+	defer func() { _ = logger.Close() }()
+
+	fileOps, err := fsops.New(fsops.Config{
+		RootDir:        projectRoot,
+		Logger:         logger,
+		ProtectedPaths: cfg.Project.ProtectedPaths,
+		MaxOpenFiles:   cfg.Limits.MaxOpenFiles,
+	})
+	if err != nil {
+		return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to create file operations: %w", err)}
+	}
+
+	results, err := generate.Upgrade(cmd.Context(), projectRoot, fileOps, generate.UpgradeOptions{
+		GeneratorVersion: version,
+		Project:          projectSettings(),
+		TemplatesDir:     cfg.Project.TemplatesDir,
+		DryRun:           upgradeDryRun,
+		Force:            upgradeForce,
+	})
+	if err != nil {
+		return ExitError{Code: ExitCodeGeneralError, Err: fmt.Errorf("upgrade failed: %w", err)}
+	}
+
+	printUpgradeResults(results)
+	return nil
+}
+
+// printUpgradeResults prints template-owned files first, then the prompt-owned
+// files worth regenerating
+func printUpgradeResults(results []generate.UpgradeResult) {
+	if upgradeDryRun {
+		fmt.Printf("[DRY RUN] No files will be written\n\n")
+	}
+
+	fmt.Printf("Template-owned files\n")
+	var regenerate []generate.UpgradeResult
+	for _, r := range results {
+		if r.Owner != generate.FileOwnerTemplate {
+			if r.Status == generate.UpgradeStatusRegenerate {
+				regenerate = append(regenerate, r)
+			}
+			continue
+		}
+
+		marker := "✓"
+		if r.Status == generate.UpgradeStatusSkipped {
+			marker = "-"
+		}
+		fmt.Printf("  %s %-8s %s", marker, r.Status, r.Path)
+		if r.Reason != "" {
+			fmt.Printf(" (%s)", r.Reason)
+		}
+		fmt.Println()
+	}
+
+	if len(regenerate) == 0 {
+		fmt.Printf("\nAll prompt-owned files were generated by gocreator %s\n", version)
+		return
+	}
+
+	fmt.Printf("\nPrompt-owned files recommended for regeneration (%d)\n", len(regenerate))
+	for _, r := range regenerate {
+		fmt.Printf("  ! %s (%s)\n", r.Path, r.Reason)
+	}
+	fmt.Printf("\nCommit any hand edits, then regenerate with 'gocreator generate <spec> --output <project-root>'\n")
+}
@@ -5,17 +5,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/schema"
+	"github.com/dshills/gocreator/internal/spec"
 	"github.com/dshills/gocreator/internal/validate"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
 var (
-	validateSkipBuild bool
-	validateSkipLint  bool
-	validateSkipTests bool
-	validateReport    string
+	validateSkipBuild  bool
+	validateSkipLint   bool
+	validateSkipTests  bool
+	validateReport     string
+	validateVet        bool
+	validateFCS        string
+	validateContracts  string
+	validateSmoke      bool
+	validateFuzz       bool
+	validateBuildFiles bool
 )
 
 var validateCmd = &cobra.Command{
@@ -27,17 +39,53 @@ The validation phase:
   1. Build Validation: Ensures code compiles without errors
   2. Lint Validation: Runs golangci-lint to check code quality
   3. Test Validation: Executes all tests and measures coverage
+  4. Requirement Coverage: Every functional requirement in the FCS has a test
+     tagged with "// Requirement: <ID>" (only when an FCS is available)
+  5. Enum Usage: Every enum in the FCS data model is declared with its
+     constants, String method and Parse function, and entity fields use the
+     enum type (only when the FCS defines enums)
+  6. Middleware Usage: The middleware package declares a function per
+     cross-cutting concern, and every file that registers routes wraps them
+     with it (only when the FCS lists cross-cutting concerns)
+  7. Output Contracts: Files that must exist, identifiers packages must
+     export and routes that must be registered, from the FCS contracts
+     section and --contracts (only when contracts are declared)
+  8. Smoke Run: Builds the main package and runs it (--help, or a health check
+     for servers) to catch runtime panics (only with --smoke or
+     validation.smoke.enabled)
+  9. Fuzz Run: Runs go test -fuzz briefly on every fuzz target (only with
+     --fuzz, validation.fuzz.enabled, or an FCS with fuzz_tests)
+ 10. Build Files: Builds the Dockerfile with docker (or lints it with
+     hadolint when no docker daemon is available) and runs make -n for every
+     Makefile target (only with --build-files or validation.build_files.enabled)
+ 11. House Style: Error wrapping, logging library, context parameters,
+     initialisms and test framework imports follow the style config (only
+     when the style section sets one of them)
 
 All checks run by default. Use skip flags to disable specific checks.
+The FCS is read from --fcs, or from <project-root>/.gocreator/fcs.json when present.
+
+Each failed check has a severity (error, warning, info or ignore) from
+validation.severity.checks; lint issues take the severity of their linter
+from validation.severity.lint_rules. Failures are errors by default, except
+go vet findings and coverage below validation.required_coverage, which are
+warnings.
 
 Exit codes:
-  0 - All validations passed
-  5 - One or more validations failed
+  0 - All validations passed, or every failure is below validation.severity.fail_on
+  5 - A failed check reached validation.severity.fail_on (default: error)
 
 Options:
   --skip-build    Skip build validation
   --skip-lint     Skip lint validation
   --skip-tests    Skip test validation
+  --vet           Also run go vet on each package (findings reported as warnings)
+  --fcs PATH      FCS JSON file whose functional requirements must be tested
+  --contracts PATH
+                  YAML or JSON file with more output contracts to check
+  --smoke         Build and run the executable after the other checks
+  --fuzz          Run each fuzz target for validation.fuzz.time
+  --build-files   Check the Dockerfile and Makefile with docker/hadolint and make
   --report PATH   Output validation report to JSON file
 
 Example:
@@ -58,9 +106,75 @@ func setupValidateFlags() {
 	validateCmd.Flags().BoolVar(&validateSkipLint, "skip-lint", false, "skip lint validation")
 	validateCmd.Flags().BoolVar(&validateSkipTests, "skip-tests", false, "skip test validation")
 	validateCmd.Flags().StringVarP(&validateReport, "report", "r", "", "output validation report to file (JSON format)")
+	validateCmd.Flags().BoolVar(&validateVet, "vet", false, "run go vet on each package after it builds")
+	validateCmd.Flags().StringVar(&validateFCS, "fcs", "", "FCS JSON file for requirement coverage, enum and middleware usage (default: <project-root>/.gocreator/fcs.json if present)")
+	validateCmd.Flags().StringVar(&validateContracts, "contracts", "", "YAML or JSON file with output contracts to check in addition to the FCS contracts")
+	validateCmd.Flags().BoolVar(&validateSmoke, "smoke", false, "build and run the executable (default: validation.smoke.enabled)")
+	validateCmd.Flags().BoolVar(&validateFuzz, "fuzz", false, "run each fuzz target with go test -fuzz (default: validation.fuzz.enabled)")
+	validateCmd.Flags().BoolVar(&validateBuildFiles, "build-files", false, "check the Dockerfile and Makefile with docker (or hadolint) and make -n (default: validation.build_files.enabled)")
+}
+
+// packageProgress prints per-package validation events while a check runs
+type packageProgress struct {
+	events chan models.ProgressEvent
+	done   chan struct{}
+}
+
+// startPackageProgress starts printing package events in the background
+func startPackageProgress() *packageProgress {
+	p := &packageProgress{
+		events: make(chan models.ProgressEvent, 100),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(p.done)
+		for event := range p.events {
+			printPackageEvent(event)
+		}
+	}()
+
+	return p
+}
+
+// stop waits until every queued event has been printed
+func (p *packageProgress) stop() {
+	close(p.events)
+	<-p.done
+}
+
+// validationParallelism builds the per-package worker pool config from settings
+func validationParallelism() validate.ParallelValidationConfig {
+	return validate.ParallelValidationConfig{
+		MaxParallel:    cfg.Validation.MaxParallel,
+		EnableParallel: cfg.Validation.MaxParallel > 1,
+	}
 }
 
-func runValidate(_ *cobra.Command, args []string) error {
+func printPackageEvent(event models.ProgressEvent) {
+	if event.Type != models.EventPackageValidated {
+		return
+	}
+
+	pkg, _ := event.Data["package"].(string)
+	check, _ := event.Data["check"].(string)
+	success, _ := event.Data["success"].(bool)
+	duration, _ := event.Data["duration"].(time.Duration)
+
+	if success {
+		fmt.Printf("    ✓ %s %s [%.1fs]\n", check, pkg, duration.Seconds())
+		return
+	}
+
+	fmt.Printf("    ✗ %s %s\n", check, pkg)
+	if excerpt, _ := event.Data["excerpt"].(string); excerpt != "" {
+		for _, line := range strings.Split(excerpt, "\n") {
+			fmt.Printf("        %s\n", line)
+		}
+	}
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
 	projectRoot := args[0]
 
 	log.Info().
@@ -83,45 +197,96 @@ func runValidate(_ *cobra.Command, args []string) error {
 	logSkippedValidations()
 
 	// Run validation
-	ctx := context.Background()
+	ctx, cancel := withPhaseTimeout(cmd.Context(), cfg.Timeouts.Validate)
+	defer cancel()
 
-	// Run validations
-	buildPassed, err := runBuildValidation(ctx, projectRoot)
+	policy, err := newSeverityPolicy()
 	if err != nil {
 		return err
 	}
 
-	lintPassed, err := runLintValidation(ctx, projectRoot)
-	if err != nil {
+	// Run validations
+	results := checkResults{vet: validateVet}
+	if results.build, err = runBuildValidation(ctx, projectRoot); err != nil {
 		return err
 	}
+	buildPassed := results.build == nil || results.build.Success
 
-	testPassed, err := runTestValidation(ctx, projectRoot)
+	if results.lint, err = runLintValidation(ctx, projectRoot, policy); err != nil {
+		return err
+	}
+
+	if results.test, err = runTestValidation(ctx, projectRoot); err != nil {
+		return err
+	}
+
+	fcs, err := loadValidationFCS(projectRoot)
 	if err != nil {
 		return err
 	}
 
-	// Determine overall result
-	checksRun, checksPassed := calculateResults(buildPassed, lintPassed, testPassed)
-	allPassed := checksPassed == checksRun
+	if results.requirements, err = runRequirementValidation(ctx, projectRoot, fcs); err != nil {
+		return err
+	}
+
+	if results.enums, err = runEnumValidation(ctx, projectRoot, fcs); err != nil {
+		return err
+	}
+
+	if results.middleware, err = runMiddlewareValidation(ctx, projectRoot, fcs); err != nil {
+		return err
+	}
+
+	if results.contracts, err = runContractValidation(ctx, projectRoot, fcs, validateContracts); err != nil {
+		return err
+	}
+
+	if results.style, err = runStyleValidation(ctx, projectRoot, fcs); err != nil {
+		return err
+	}
+
+	if results.smoke, err = runSmokeValidation(ctx, projectRoot, validateSmoke || cfg.Validation.Smoke.Enabled, buildPassed); err != nil {
+		return err
+	}
+
+	if results.fuzz, err = runFuzzValidation(ctx, projectRoot, validateFuzz || fuzzEnabled(fcs), buildPassed); err != nil {
+		return err
+	}
+
+	if results.buildFiles, err = runBuildFilesValidation(ctx, projectRoot, validateBuildFiles || cfg.Validation.BuildFiles.Enabled); err != nil {
+		return err
+	}
+
+	// Checks cut short by the deadline or an interrupt are not real failures
+	if err := ctx.Err(); err != nil {
+		return ExitError{Code: ExitCodeValidationError, Err: phaseError(ctx, "validation", "validate", cfg.Timeouts.Validate, err)}
+	}
+
+	// Determine overall result: the most severe failure decides it
+	outcomes := results.outcomes(policy)
+	checksRun, checksPassed := countChecks(outcomes)
+	highest := validate.HighestSeverity(outcomes)
+	failed := policy.Fails(outcomes)
 
 	// Print result
-	printValidationResult(allPassed, checksPassed, checksRun)
+	printValidationResult(!failed, checksPassed, checksRun)
+	printCheckOutcomes(outcomes)
 
 	// Save report if requested
-	if err := saveReport(buildPassed, lintPassed, testPassed, checksRun, checksPassed); err != nil {
+	if err := saveReport(results, outcomes, highest, checksRun, checksPassed); err != nil {
 		return err
 	}
 
 	log.Info().
-		Bool("all_passed", allPassed).
+		Bool("failed", failed).
+		Str("highest_severity", string(highest)).
 		Int("checks_passed", checksPassed).
 		Int("checks_run", checksRun).
 		Msg("Validation phase completed")
 
-	// Return error if any checks failed
-	if !allPassed {
-		return ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("validation failed: %d/%d checks passed", checksPassed, checksRun)}
+	// Return error if the failed checks are severe enough
+	if failed {
+		return ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("validation failed: %d/%d checks passed, highest severity %s", checksPassed, checksRun, highest)}
 	}
 
 	return nil
@@ -139,37 +304,55 @@ func logSkippedValidations() {
 	}
 }
 
-func runBuildValidation(ctx context.Context, projectRoot string) (bool, error) {
+// runBuildValidation builds (and with --vet, vets) every package. It returns
+// nil when the build is skipped.
+func runBuildValidation(ctx context.Context, projectRoot string) (*models.BuildResult, error) {
 	if validateSkipBuild {
-		return false, nil
+		return nil, nil
 	}
 
 	fmt.Printf("[1/3] Build Validation\n")
 	fmt.Printf("  Running: go build ./...\n")
 
-	buildValidator := validate.NewBuildValidator(cfg.Validation.TestTimeout)
+	progress := startPackageProgress()
+	buildValidator := validate.NewBuildValidator(cfg.Validation.TestTimeout,
+		validate.WithVet(validateVet),
+		validate.WithBuildEvents(progress.events),
+		validate.WithBuildParallelism(validationParallelism()))
 	buildResult, err := buildValidator.Validate(ctx, projectRoot)
+	progress.stop()
 	if err != nil {
 		log.Error().Err(err).Msg("Build validation error")
-		return false, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("build validation error: %w", err)}
+		return nil, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("build validation error: %w", err)}
 	}
 
 	if buildResult.Success {
-		fmt.Printf("  ✓ Build successful [elapsed: %.1fs]\n\n", buildResult.Duration.Seconds())
-		return true, nil
+		fmt.Printf("  ✓ Build successful [elapsed: %.1fs]\n", buildResult.Duration.Seconds())
+	} else {
+		fmt.Printf("  ✗ Build failed:\n")
+		for _, buildErr := range buildResult.Errors {
+			fmt.Printf("    - %s:%d: %s\n", buildErr.File, buildErr.Line, buildErr.Message)
+		}
 	}
-
-	fmt.Printf("  ✗ Build failed:\n")
-	for _, buildErr := range buildResult.Errors {
-		fmt.Printf("    - %s:%d: %s\n", buildErr.File, buildErr.Line, buildErr.Message)
+	if findings := validate.VetFindings(buildResult); len(findings) > 0 {
+		fmt.Printf("  ✗ go vet reported %d findings:\n", len(findings))
+		for i, finding := range findings {
+			if i == 5 {
+				fmt.Printf("    ... and %d more findings\n", len(findings)-5)
+				break
+			}
+			fmt.Printf("    - %s:%d: %s\n", finding.File, finding.Line, finding.Message)
+		}
 	}
 	fmt.Printf("\n")
-	return false, nil
+	return buildResult, nil
 }
 
-func runLintValidation(ctx context.Context, projectRoot string) (bool, error) {
+// runLintValidation runs golangci-lint and maps its issues through the
+// severity policy. It returns nil when linting is skipped.
+func runLintValidation(ctx context.Context, projectRoot string, policy *validate.SeverityPolicy) (*models.LintResult, error) {
 	if validateSkipLint {
-		return false, nil
+		return nil, nil
 	}
 
 	fmt.Printf("[2/3] Lint Validation\n")
@@ -179,82 +362,489 @@ func runLintValidation(ctx context.Context, projectRoot string) (bool, error) {
 	lintResult, err := lintValidator.Validate(ctx, projectRoot)
 	if err != nil {
 		log.Error().Err(err).Msg("Lint validation error")
-		return false, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("lint validation error: %w", err)}
+		return nil, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("lint validation error: %w", err)}
 	}
+	policy.ApplyToLint(lintResult)
 
 	if lintResult.Success {
 		fmt.Printf("  ✓ No lint issues found [elapsed: %.1fs]\n\n", lintResult.Duration.Seconds())
-		return true, nil
+		return lintResult, nil
 	}
 
 	fmt.Printf("  ✗ Found %d issues:\n", len(lintResult.Issues))
 	for i, issue := range lintResult.Issues {
 		if i < 10 { // Show first 10 issues
-			fmt.Printf("    - %s:%d: %s\n", issue.File, issue.Line, issue.Message)
+			fmt.Printf("    - %s:%d: %s (%s, %s)\n", issue.File, issue.Line, issue.Message, issue.Rule, issue.Severity)
 		}
 	}
 	if len(lintResult.Issues) > 10 {
 		fmt.Printf("    ... and %d more issues\n", len(lintResult.Issues)-10)
 	}
 	fmt.Printf("\n")
-	return false, nil
+	return lintResult, nil
 }
 
-func runTestValidation(ctx context.Context, projectRoot string) (bool, error) {
+// runTestValidation runs the tests with coverage. It returns nil when the
+// tests are skipped.
+func runTestValidation(ctx context.Context, projectRoot string) (*models.TestResult, error) {
 	if validateSkipTests {
-		return false, nil
+		return nil, nil
 	}
 
 	fmt.Printf("[3/3] Test Validation\n")
 	fmt.Printf("  Running: go test ./...\n")
 
-	testValidator := validate.NewTestValidator(validate.WithTestTimeout(cfg.Validation.TestTimeout))
+	progress := startPackageProgress()
+	testValidator := validate.NewTestValidator(
+		validate.WithTestTimeout(cfg.Validation.TestTimeout),
+		validate.WithTestEvents(progress.events),
+		validate.WithTestParallelism(validationParallelism()))
 	testResult, err := testValidator.Validate(ctx, projectRoot)
+	progress.stop()
 	if err != nil {
 		log.Error().Err(err).Msg("Test validation error")
-		return false, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("test validation error: %w", err)}
+		return nil, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("test validation error: %w", err)}
 	}
 
 	if testResult.Success {
-		fmt.Printf("  ✓ All tests passed (%d/%d) [coverage: %.1f%%] [elapsed: %.1fs]\n\n",
+		fmt.Printf("  ✓ All tests passed (%d/%d) [coverage: %.1f%%] [elapsed: %.1fs]\n",
 			testResult.PassedTests, testResult.TotalTests, testResult.Coverage, testResult.Duration.Seconds())
-		return true, nil
+	} else {
+		fmt.Printf("  ✗ Tests failed (%d/%d passed) [coverage: %.1f%%]\n",
+			testResult.PassedTests, testResult.TotalTests, testResult.Coverage)
+		for i, failure := range testResult.Failures {
+			if i < 5 { // Show first 5 failures
+				fmt.Printf("    - %s: %s\n", failure.Test, failure.Message)
+			}
+		}
+		if len(testResult.Failures) > 5 {
+			fmt.Printf("    ... and %d more failures\n", len(testResult.Failures)-5)
+		}
 	}
+	printCoverageShortfall(testResult)
+	fmt.Printf("\n")
+	return testResult, nil
+}
+
+// printCoverageShortfall reports coverage below validation.required_coverage
+func printCoverageShortfall(testResult *models.TestResult) {
+	if testResult.Coverage < cfg.Validation.RequiredCoverage {
+		fmt.Printf("  ✗ Coverage %.1f%% is below the required %.1f%%\n", testResult.Coverage, cfg.Validation.RequiredCoverage)
+	}
+}
 
-	fmt.Printf("  ✗ Tests failed (%d/%d passed) [coverage: %.1f%%]\n",
-		testResult.PassedTests, testResult.TotalTests, testResult.Coverage)
-	for i, failure := range testResult.Failures {
-		if i < 5 { // Show first 5 failures
-			fmt.Printf("    - %s: %s\n", failure.Test, failure.Message)
+// loadValidationFCS reads the FCS from --fcs or the project's .gocreator
+// directory. It returns nil when no FCS is available.
+func loadValidationFCS(projectRoot string) (*models.FinalClarifiedSpecification, error) {
+	fcsPath := validateFCS
+	if fcsPath == "" {
+		fcsPath = filepath.Join(projectRoot, ".gocreator", "fcs.json")
+		if _, err := os.Stat(fcsPath); err != nil {
+			log.Debug().Str("fcs_path", fcsPath).Msg("No FCS found, skipping requirement coverage, enum and middleware usage")
+			return nil, nil
 		}
 	}
-	if len(testResult.Failures) > 5 {
-		fmt.Printf("    ... and %d more failures\n", len(testResult.Failures)-5)
+
+	fcs, err := readFCS(fcsPath)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load FCS")
+		return nil, ExitError{Code: ExitCodeSpecError, Err: err}
+	}
+	return fcs, nil
+}
+
+// runRequirementValidation checks that every functional requirement has a tagged
+// test. It returns nil when no FCS is available.
+func runRequirementValidation(ctx context.Context, projectRoot string, fcs *models.FinalClarifiedSpecification) (*models.RequirementCoverage, error) {
+	if fcs == nil {
+		return nil, nil
+	}
+
+	fmt.Printf("Requirement Coverage\n")
+	fmt.Printf("  Checking: // %s tags in *_test.go\n", validate.RequirementTagPrefix)
+
+	coverage, err := validate.NewRequirementValidator(fcs.Requirements.Functional).Validate(ctx, projectRoot)
+	if err != nil {
+		log.Error().Err(err).Msg("Requirement coverage error")
+		return nil, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("requirement coverage error: %w", err)}
 	}
+
+	if coverage.Success {
+		fmt.Printf("  ✓ All %d functional requirements have tests\n\n", coverage.TotalRequirements)
+		return coverage, nil
+	}
+
+	fmt.Printf("  ✗ %d/%d functional requirements have no test:\n", len(coverage.Untested), coverage.TotalRequirements)
+	descriptions := make(map[string]string, len(fcs.Requirements.Functional))
+	for _, req := range fcs.Requirements.Functional {
+		descriptions[req.ID] = req.Description
+	}
+	for _, id := range coverage.Untested {
+		fmt.Printf("    - %s: %s\n", id, descriptions[id])
+	}
+	fmt.Printf("\n")
+	return coverage, nil
+}
+
+// runEnumValidation checks that the FCS enums are declared and used for entity
+// fields. It returns nil when the FCS defines no enums.
+func runEnumValidation(ctx context.Context, projectRoot string, fcs *models.FinalClarifiedSpecification) (*models.EnumUsage, error) {
+	if fcs == nil || len(fcs.DataModel.Enums) == 0 {
+		return nil, nil
+	}
+
+	fmt.Printf("Enum Usage\n")
+	usage, err := validate.NewEnumValidator(fcs.DataModel).Validate(ctx, projectRoot)
+	if err != nil {
+		log.Error().Err(err).Msg("Enum usage error")
+		return nil, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("enum usage error: %w", err)}
+	}
+
+	printEnumUsage(usage)
 	fmt.Printf("\n")
-	return false, nil
+	return usage, nil
 }
 
-func calculateResults(buildPassed, lintPassed, testPassed bool) (checksRun, checksPassed int) {
-	if !validateSkipBuild {
-		checksRun++
-		if buildPassed {
-			checksPassed++
+// printEnumUsage prints the outcome of the enum usage check
+func printEnumUsage(usage *models.EnumUsage) {
+	if usage.Success {
+		fmt.Printf("  ✓ All %d enums declared and used\n", usage.TotalEnums)
+		return
+	}
+
+	fmt.Printf("  ✗ Found %d enum issues\n", len(usage.Issues))
+	for i, issue := range usage.Issues {
+		if i == 5 {
+			fmt.Printf("    ... and %d more issues\n", len(usage.Issues)-5)
+			break
+		}
+		if issue.File != "" {
+			fmt.Printf("    - %s:%d: %s\n", issue.File, issue.Line, issue.Message)
+		} else {
+			fmt.Printf("    - %s\n", issue.Message)
 		}
 	}
-	if !validateSkipLint {
-		checksRun++
-		if lintPassed {
-			checksPassed++
+}
+
+// runMiddlewareValidation checks that the cross-cutting middleware is declared
+// and wraps every registered route. It returns nil when the FCS lists no
+// cross-cutting concerns.
+func runMiddlewareValidation(ctx context.Context, projectRoot string, fcs *models.FinalClarifiedSpecification) (*models.MiddlewareUsage, error) {
+	if fcs == nil || !fcs.CrossCutting.Enabled() {
+		return nil, nil
+	}
+
+	fmt.Printf("Middleware Usage\n")
+	usage, err := validate.NewMiddlewareValidator(fcs.CrossCutting).Validate(ctx, projectRoot)
+	if err != nil {
+		log.Error().Err(err).Msg("Middleware usage error")
+		return nil, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("middleware usage error: %w", err)}
+	}
+
+	printMiddlewareUsage(usage)
+	fmt.Printf("\n")
+	return usage, nil
+}
+
+// printMiddlewareUsage prints the outcome of the middleware usage check
+func printMiddlewareUsage(usage *models.MiddlewareUsage) {
+	if usage.Success {
+		fmt.Printf("  ✓ Middleware in %s wraps routes in %d files\n", usage.Package, len(usage.Wrapped))
+		return
+	}
+
+	fmt.Printf("  ✗ Found %d middleware issues\n", len(usage.Issues))
+	for i, issue := range usage.Issues {
+		if i == 5 {
+			fmt.Printf("    ... and %d more issues\n", len(usage.Issues)-5)
+			break
 		}
+		if issue.File != "" {
+			fmt.Printf("    - %s:%d: %s\n", issue.File, issue.Line, issue.Message)
+		} else {
+			fmt.Printf("    - %s\n", issue.Message)
+		}
+	}
+}
+
+// runContractValidation checks the output contracts declared in the FCS and
+// the companion contracts file, if any. It returns nil when no contracts are
+// declared.
+func runContractValidation(ctx context.Context, projectRoot string, fcs *models.FinalClarifiedSpecification, contractsFile string) (*models.ContractResult, error) {
+	var contracts []models.OutputContract
+	if fcs != nil {
+		contracts = append(contracts, fcs.Contracts...)
 	}
-	if !validateSkipTests {
-		checksRun++
-		if testPassed {
-			checksPassed++
+	if contractsFile != "" {
+		extra, err := spec.LoadContracts(contractsFile)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to load contracts")
+			return nil, ExitError{Code: ExitCodeSpecError, Err: err}
 		}
+		contracts = append(contracts, extra...)
+	}
+	if len(contracts) == 0 {
+		return nil, nil
 	}
-	return checksRun, checksPassed
+
+	fmt.Printf("Output Contracts\n")
+	result, err := validate.NewContractValidator(contracts).Validate(ctx, projectRoot)
+	if err != nil {
+		log.Error().Err(err).Msg("Contract validation error")
+		return nil, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("contract validation error: %w", err)}
+	}
+
+	printContractResult(result)
+	fmt.Printf("\n")
+	return result, nil
+}
+
+// printContractResult prints the outcome of the output contract check
+func printContractResult(result *models.ContractResult) {
+	if result.Success {
+		fmt.Printf("  ✓ All %d contracts hold\n", result.Checked)
+		return
+	}
+
+	fmt.Printf("  ✗ %d/%d contracts violated\n", len(result.Violations), result.Checked)
+	for i, violation := range result.Violations {
+		if i == 5 {
+			fmt.Printf("    ... and %d more violations\n", len(result.Violations)-5)
+			break
+		}
+		fmt.Printf("    - %s: %s\n", violation.Contract, violation.Message)
+	}
+}
+
+// runStyleValidation checks the project against the style section. It
+// returns nil when the style sets no rule that can be checked.
+func runStyleValidation(ctx context.Context, projectRoot string, fcs *models.FinalClarifiedSpecification) (*models.StyleResult, error) {
+	style := validationStyle(fcs)
+	if !style.Checkable() {
+		return nil, nil
+	}
+
+	fmt.Printf("House Style\n")
+	result, err := validate.NewStyleValidator(style).Validate(ctx, projectRoot)
+	if err != nil {
+		log.Error().Err(err).Msg("Style validation error")
+		return nil, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("style validation error: %w", err)}
+	}
+
+	printStyleResult(result)
+	fmt.Printf("\n")
+	return result, nil
+}
+
+// validationStyle returns the style generated code is checked against. Tests
+// are written with the FCS's test framework when it selects one, as in
+// generation, so only the style's is otherwise enforced.
+func validationStyle(fcs *models.FinalClarifiedSpecification) models.StyleGuide {
+	style := cfg.Style.Guide()
+	if style.TestFramework != "" && fcs != nil && models.IsTestFramework(fcs.TestingStrategy.TestFramework) {
+		style.TestFramework = fcs.TestingStrategy.TestFramework
+	}
+	return style
+}
+
+// printStyleResult prints the outcome of the house style check
+func printStyleResult(result *models.StyleResult) {
+	if result.Success {
+		fmt.Printf("  ✓ No style issues in %s\n", countNoun(result.Files, "Go file"))
+		return
+	}
+
+	fmt.Printf("  ✗ Found %d style issues\n", len(result.Issues))
+	for i, issue := range result.Issues {
+		if i == 5 {
+			fmt.Printf("    ... and %d more issues\n", len(result.Issues)-5)
+			break
+		}
+		fmt.Printf("    - %s:%d: %s (%s)\n", issue.File, issue.Line, issue.Message, issue.Rule)
+	}
+}
+
+// newSmokeValidator creates a smoke validator from validation.smoke
+func newSmokeValidator() validate.SmokeValidator {
+	smoke := cfg.Validation.Smoke
+	opts := []validate.SmokeOption{
+		validate.WithSmokePackage(smoke.Package),
+		validate.WithSmokeEnv(smoke.Env...),
+		validate.WithHealthCheck(smoke.HealthURL),
+		validate.WithStartupTimeout(smoke.StartupTimeout),
+	}
+	if len(smoke.Args) > 0 {
+		opts = append(opts, validate.WithSmokeArgs(smoke.Args...))
+	}
+	return validate.NewSmokeValidator(opts...)
+}
+
+// runSmokeValidation builds and runs the project's executable. It returns nil
+// when the smoke run is disabled or the build already failed.
+func runSmokeValidation(ctx context.Context, projectRoot string, enabled, buildPassed bool) (*models.SmokeResult, error) {
+	if !enabled {
+		return nil, nil
+	}
+
+	fmt.Printf("Smoke Run\n")
+	if !buildPassed {
+		fmt.Printf("  - Skipped: build failed\n\n")
+		return nil, nil
+	}
+
+	result, err := newSmokeValidator().Validate(ctx, projectRoot)
+	if err != nil {
+		log.Error().Err(err).Msg("Smoke run error")
+		return nil, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("smoke run error: %w", err)}
+	}
+
+	printSmokeResult(result)
+	fmt.Printf("\n")
+	return result, nil
+}
+
+// printSmokeResult prints the outcome of a smoke run with the end of its output on failure
+func printSmokeResult(result *models.SmokeResult) {
+	switch {
+	case result.Skipped:
+		fmt.Printf("  - Skipped: %s\n", result.Message)
+	case result.Success && result.HealthURL != "":
+		fmt.Printf("  ✓ %s healthy at %s [elapsed: %.1fs]\n", result.Package, result.HealthURL, result.Duration.Seconds())
+	case result.Success:
+		fmt.Printf("  ✓ %s ran cleanly: %s [elapsed: %.1fs]\n", result.Package, result.Command, result.Duration.Seconds())
+	default:
+		fmt.Printf("  ✗ %s: %s\n", result.Package, result.Message)
+		lines := strings.Split(strings.TrimRight(result.Output, "\n"), "\n")
+		if len(lines) > 10 {
+			lines = lines[len(lines)-10:]
+		}
+		for _, line := range lines {
+			if line != "" {
+				fmt.Printf("    | %s\n", line)
+			}
+		}
+	}
+}
+
+// fuzzEnabled reports whether fuzz targets are run: when configured, or when
+// the FCS asked for fuzz tests
+func fuzzEnabled(fcs *models.FinalClarifiedSpecification) bool {
+	return cfg.Validation.Fuzz.Enabled || (fcs != nil && fcs.TestingStrategy.FuzzTests)
+}
+
+// runFuzzValidation runs each fuzz target briefly. It returns nil when the
+// fuzz run is disabled or the build already failed.
+func runFuzzValidation(ctx context.Context, projectRoot string, enabled, buildPassed bool) (*models.FuzzResult, error) {
+	if !enabled {
+		return nil, nil
+	}
+
+	fmt.Printf("Fuzz Run\n")
+	if !buildPassed {
+		fmt.Printf("  - Skipped: build failed\n\n")
+		return nil, nil
+	}
+
+	result, err := validate.NewFuzzValidator(validate.WithFuzzTime(cfg.Validation.Fuzz.Time)).Validate(ctx, projectRoot)
+	if err != nil {
+		log.Error().Err(err).Msg("Fuzz run error")
+		return nil, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("fuzz run error: %w", err)}
+	}
+
+	printFuzzResult(result)
+	fmt.Printf("\n")
+	return result, nil
+}
+
+// printFuzzResult prints the outcome of a fuzz run with the failing targets
+func printFuzzResult(result *models.FuzzResult) {
+	switch {
+	case result.Targets == 0:
+		fmt.Printf("  - Skipped: no fuzz targets\n")
+	case result.Success:
+		fmt.Printf("  ✓ %s ran for %v each without failures [elapsed: %.1fs]\n", countNoun(result.Targets, "fuzz target"), result.FuzzTime, result.Duration.Seconds())
+	default:
+		fmt.Printf("  ✗ %d/%d fuzz targets failed\n", len(result.Failures), result.Targets)
+		for _, failure := range result.Failures {
+			fmt.Printf("    - %s %s: %s\n", failure.Package, failure.Target, failure.Message)
+		}
+	}
+}
+
+// runBuildFilesValidation checks the project's Dockerfile and Makefile. It
+// returns nil when the check is disabled.
+func runBuildFilesValidation(ctx context.Context, projectRoot string, enabled bool) (*models.BuildFilesResult, error) {
+	if !enabled {
+		return nil, nil
+	}
+
+	fmt.Printf("Build Files\n")
+	result, err := newBuildFilesValidator().Validate(ctx, projectRoot)
+	if err != nil {
+		log.Error().Err(err).Msg("Build file validation error")
+		return nil, ExitError{Code: ExitCodeValidationError, Err: fmt.Errorf("build file validation error: %w", err)}
+	}
+
+	printBuildFilesResult(result)
+	fmt.Printf("\n")
+	return result, nil
+}
+
+// newBuildFilesValidator builds the Dockerfile and Makefile checks from validation.build_files
+func newBuildFilesValidator() validate.BuildFilesValidator {
+	return validate.NewBuildFilesValidator(validate.WithDockerBuildTimeout(cfg.Validation.BuildFiles.DockerTimeout))
+}
+
+// printBuildFilesResult prints each build file check with the end of the
+// tool's output on failure
+func printBuildFilesResult(result *models.BuildFilesResult) {
+	if len(result.Checks) == 0 {
+		fmt.Printf("  - Skipped: no Dockerfile or Makefile\n")
+		return
+	}
+	for _, check := range result.Checks {
+		switch {
+		case check.Skipped:
+			fmt.Printf("  - Skipped %s: %s\n", check.File, check.Message)
+		case check.Success:
+			fmt.Printf("  ✓ %s: %s [%.1fs]\n", check.File, check.Command, check.Duration.Seconds())
+		default:
+			fmt.Printf("  ✗ %s: %s: %s\n", check.File, check.Command, check.Message)
+			lines := strings.Split(strings.TrimRight(check.Output, "\n"), "\n")
+			if len(lines) > 10 {
+				lines = lines[len(lines)-10:]
+			}
+			for _, line := range lines {
+				if line != "" {
+					fmt.Printf("    | %s\n", line)
+				}
+			}
+		}
+	}
+}
+
+// readFCS loads a Final Clarified Specification from a JSON file, migrating
+// files written with an older schema version
+func readFCS(path string) (*models.FinalClarifiedSpecification, error) {
+	//nolint:gosec // G304: Reading user-provided FCS file - required for CLI functionality
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FCS file: %w", err)
+	}
+
+	fcs, from, err := schema.LoadFCS(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FCS file %s: %w", path, err)
+	}
+	if len(from) > 0 {
+		log.Info().
+			Str("file", path).
+			Strs("from", from).
+			Str("to", models.FCSSchemaVersion).
+			Msg("Migrated FCS to the current schema version")
+	}
+
+	return fcs, nil
 }
 
 func printValidationResult(allPassed bool, checksPassed, checksRun int) {
@@ -265,17 +855,46 @@ func printValidationResult(allPassed bool, checksPassed, checksRun int) {
 	}
 }
 
-func saveReport(buildPassed, lintPassed, testPassed bool, checksRun, checksPassed int) error {
+func saveReport(results checkResults, outcomes []validate.CheckOutcome, highest validate.Severity, checksRun, checksPassed int) error {
 	if validateReport == "" {
 		return nil
 	}
 
 	report := map[string]interface{}{
-		"build_passed":  buildPassed,
-		"lint_passed":   lintPassed,
-		"test_passed":   testPassed,
-		"checks_run":    checksRun,
-		"checks_passed": checksPassed,
+		"build_passed":     results.build != nil && results.build.Success,
+		"lint_passed":      results.lint != nil && results.lint.Success,
+		"test_passed":      results.test != nil && results.test.Success,
+		"checks_run":       checksRun,
+		"checks_passed":    checksPassed,
+		"checks":           outcomes,
+		"highest_severity": highest,
+	}
+	if results.lint != nil && len(results.lint.Issues) > 0 {
+		report["lint_issues"] = results.lint.Issues
+	}
+	if results.requirements != nil {
+		report["requirement_coverage"] = results.requirements
+	}
+	if results.enums != nil {
+		report["enum_usage"] = results.enums
+	}
+	if results.middleware != nil {
+		report["middleware_usage"] = results.middleware
+	}
+	if results.contracts != nil {
+		report["contracts"] = results.contracts
+	}
+	if results.style != nil {
+		report["style"] = results.style
+	}
+	if results.smoke != nil {
+		report["smoke"] = results.smoke
+	}
+	if results.fuzz != nil {
+		report["fuzz"] = results.fuzz
+	}
+	if results.buildFiles != nil {
+		report["build_files"] = results.buildFiles
 	}
 
 	data, err := json.MarshalIndent(report, "", "  ")
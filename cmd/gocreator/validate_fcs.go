@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/schema"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateFCSPlan        bool
+	validateFCSPrintSchema bool
+)
+
+var validateFCSCmd = &cobra.Command{
+	Use:   "validate-fcs <file>",
+	Short: "Check an FCS file against the FCS JSON Schema",
+	Long: `Check a Final Clarified Specification (FCS) JSON file against the FCS
+JSON Schema, then against the rules generation enforces.
+
+FCS files written by an older gocreator are first migrated to the current
+schema version, as every command reading an FCS does; the file itself is
+left unchanged. The schema check reports every property of the wrong type,
+every required property that is missing and every property the schema does
+not know, such as a misspelled key. The rules cover cyclic package
+dependencies, the file tree, the build config and the metadata hash.
+
+Options:
+  --plan          Check a generation plan against the plan schema instead
+  --print-schema  Print the JSON Schema (of a plan with --plan) and exit
+
+Example:
+  # Check a hand-edited FCS before generating from it
+  gocreator validate-fcs ./fcs.json
+
+  # Check a recorded plan
+  gocreator debug state latest --output ./my-project --field plan > plan.json
+  gocreator validate-fcs plan.json --plan
+
+  # Save the schema for an editor
+  gocreator validate-fcs --print-schema > fcs.schema.json`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if validateFCSPrintSchema {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: runValidateFCS,
+}
+
+func setupValidateFCSFlags() {
+	validateFCSCmd.Flags().BoolVar(&validateFCSPlan, "plan", false, "check a generation plan instead of an FCS")
+	validateFCSCmd.Flags().BoolVar(&validateFCSPrintSchema, "print-schema", false, "print the JSON Schema and exit")
+}
+
+func runValidateFCS(_ *cobra.Command, args []string) error {
+	if validateFCSPrintSchema {
+		s := schema.FCS()
+		if validateFCSPlan {
+			s = schema.Plan()
+		}
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return ExitError{Code: ExitCodeInternalError, Err: fmt.Errorf("failed to encode schema: %w", err)}
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	path := args[0]
+	//nolint:gosec // G304: Reading user-provided FCS file - required for CLI functionality
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to read %s: %w", path, err)}
+	}
+
+	if validateFCSPlan {
+		return validatePlanDocument(path, data)
+	}
+	return validateFCSDocument(path, data)
+}
+
+// validateFCSDocument migrates an FCS document and checks it against the
+// schema and the FCS rules
+func validateFCSDocument(path string, data []byte) error {
+	migrated, from, err := schema.MigrateFCS(data)
+	if err != nil {
+		return ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("%s: %w", path, err)}
+	}
+	if err := checkSchema(path, "FCS", schema.FCS(), migrated); err != nil {
+		return err
+	}
+
+	fcs, _, err := schema.LoadFCS(data)
+	if err != nil {
+		return ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("failed to parse FCS file %s: %w", path, err)}
+	}
+	if err := fcs.Validate(); err != nil {
+		return ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("%s: %w", path, err)}
+	}
+
+	fmt.Printf("✓ %s is a valid FCS (schema version %s)\n", path, models.FCSSchemaVersion)
+	for _, version := range from {
+		fmt.Printf("  Migrated from schema version %s\n", version)
+	}
+	log.Info().Str("file", path).Strs("migrated_from", from).Msg("FCS is valid")
+	return nil
+}
+
+// validatePlanDocument checks a generation plan against the schema and the
+// plan rules
+func validatePlanDocument(path string, data []byte) error {
+	if err := checkSchema(path, "plan", schema.Plan(), data); err != nil {
+		return err
+	}
+
+	var plan models.GenerationPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("failed to parse plan file %s: %w", path, err)}
+	}
+	if err := plan.Validate(); err != nil {
+		return ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("%s: %w", path, err)}
+	}
+
+	fmt.Printf("✓ %s is a valid generation plan (schema version %s)\n", path, models.PlanSchemaVersion)
+	log.Info().Str("file", path).Msg("Plan is valid")
+	return nil
+}
+
+// checkSchema validates a document against a schema, listing the violations
+func checkSchema(path, kind string, s *schema.Schema, data []byte) error {
+	err := s.Validate(data)
+	var invalid *schema.ValidationError
+	if errors.As(err, &invalid) {
+		fmt.Printf("✗ %s does not match the %s schema:\n", path, kind)
+		for _, v := range invalid.Violations {
+			fmt.Printf("  %s\n", v)
+		}
+		return ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("%s: %s of the %s schema", path, countNoun(len(invalid.Violations), "violation"), kind)}
+	}
+	if err != nil {
+		return ExitError{Code: ExitCodeSpecError, Err: fmt.Errorf("%s: %w", path, err)}
+	}
+	return nil
+}
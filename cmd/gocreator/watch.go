@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dshills/gocreator/internal/clarify"
+	"github.com/dshills/gocreator/internal/cli"
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/schema"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchOutput   string
+	watchFCS      bool
+	watchDebounce time.Duration
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <spec-file>",
+	Short: "Regenerate the affected files whenever the specification changes",
+	Long: `Watch a specification and regenerate the project each time it is saved.
+
+The project is generated incrementally first, as by 'gocreator generate
+--incremental', unless the output directory already has incremental state.
+After each save the specification is clarified again, the new FCS is
+compared with the FCS of the last generation, and only the files affected by
+the changes are regenerated; the changes are listed first, as by 'gocreator
+impact'. Saves that leave the specification or its FCS unchanged do not
+regenerate anything, and an unchanged specification is not clarified again.
+
+Changes are picked up once they have settled for --debounce, so an editor
+writing a file in several steps triggers one run. A save during a run is
+handled when the run finishes. A run that fails is reported and the watch
+goes on; saving again retries it. limits.max_duration bounds each run rather
+than the watch.
+
+Options:
+  -o, --output    Output directory (default: ./generated)
+  --fcs           The watched file is an FCS; it is not clarified
+  --debounce      Quiet period before a change is handled (default: 500ms)
+  --max-cost      Stop a run once its LLM calls cost this much (USD)
+  --max-tokens    Stop a run once its LLM calls used this many tokens
+  --on-budget     At a crossed cap: abort the run, or confirm to ask
+
+Example:
+  gocreator watch ./my-project-spec.yaml --output ./my-project
+  gocreator watch ./my-project.fcs.json --fcs --output ./my-project`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWatch,
+}
+
+func setupWatchFlags() {
+	watchCmd.Flags().StringVarP(&watchOutput, "output", "o", "./generated", "output directory for generated code")
+	watchCmd.Flags().BoolVar(&watchFCS, "fcs", false, "the watched file is an FCS; skip clarification")
+	watchCmd.Flags().DurationVar(&watchDebounce, "debounce", cli.DefaultDebounce, "quiet period before a change is handled")
+	addBudgetFlags(watchCmd)
+}
+
+// specWatch regenerates a project from the specification it watches
+type specWatch struct {
+	specFile      string
+	outputFlagSet bool
+
+	// specSum is the checksum of the specification last generated from
+	specSum   string
+	outputDir string
+	engine    clarify.Engine
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	specFile := args[0]
+	if _, err := os.Stat(specFile); err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: fmt.Errorf("failed to read spec file: %w", err)}
+	}
+	if !watchFCS {
+		if _, err := detectSpecFormat(specFile); err != nil {
+			return ExitError{Code: ExitCodeSpecError, Err: err}
+		}
+	}
+
+	changes, err := cli.WatchFile(ctx, specFile, watchDebounce)
+	if err != nil {
+		return ExitError{Code: ExitCodeFileSystemError, Err: err}
+	}
+
+	log.Info().
+		Str("spec_file", specFile).
+		Dur("debounce", watchDebounce).
+		Msg("Watching specification")
+
+	w := &specWatch{specFile: specFile, outputFlagSet: cmd.Flags().Changed("output")}
+	w.run(ctx)
+	for range changes {
+		w.run(ctx)
+	}
+
+	fmt.Println("\nStopped watching")
+	return nil
+}
+
+// run regenerates the project from the current specification and prints
+// the outcome as a status line
+func (w *specWatch) run(ctx context.Context) {
+	started := time.Now()
+	outcome, err := w.regenerate(ctx)
+	switch {
+	case ctx.Err() != nil:
+		// Interrupted; the watch is ending
+		return
+	case err != nil:
+		log.Error().Err(err).Str("spec_file", w.specFile).Msg("Watch run failed")
+		w.status("✗", err.Error())
+	default:
+		w.status("✓", fmt.Sprintf("%s (%s)", outcome, time.Since(started).Round(time.Second)))
+	}
+}
+
+// status prints the outcome of a run and what is watched
+func (w *specWatch) status(marker, message string) {
+	fmt.Printf("\n[%s] %s %s\n", time.Now().Format("15:04:05"), marker, message)
+	fmt.Printf("Watching %s for changes (Ctrl+C to stop)\n", w.specFile)
+}
+
+// regenerate clarifies a changed specification and regenerates the files
+// its changes affect, returning a summary of what was done
+func (w *specWatch) regenerate(ctx context.Context) (string, error) {
+	//nolint:gosec // G304: Reading user-provided spec file - required for CLI functionality
+	content, err := os.ReadFile(w.specFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read spec file: %w", err)
+	}
+	sum := clarify.SpecChecksum(string(content))
+	if sum == w.specSum {
+		return "Specification unchanged", nil
+	}
+
+	fcs, err := w.clarify(ctx, content)
+	if err != nil {
+		return "", err
+	}
+
+	if w.outputDir == "" {
+		w.outputDir, err = resolveOutputDir(watchOutput, w.outputFlagSet, fcs)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Each run, not the watch, is bounded by limits.max_duration
+	if cfg.Limits.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, cfg.Limits.MaxDuration, errMaxDuration)
+		defer cancel()
+	}
+
+	outcome := "Generated " + w.outputDir
+	report, err := w.impact(fcs)
+	if err != nil {
+		return "", err
+	}
+	if report != nil {
+		if !report.Changes.HasChanges {
+			w.specSum = sum
+			return "FCS unchanged; no files to regenerate", nil
+		}
+		fmt.Printf("\nChanges in %s\n", w.specFile)
+		for _, line := range impactChangeLines(report.Changes) {
+			fmt.Printf("  %s\n", line)
+		}
+		if report.FullRebuild {
+			fmt.Printf("  All files are regenerated: %s\n", report.FullRebuildReason)
+		}
+		fmt.Println()
+		outcome = fmt.Sprintf("Regenerated %d of %s", len(report.Files), countNoun(report.TotalFiles, "file"))
+	}
+
+	if err := runGenerationWithProgress(ctx, fcs, w.outputDir, true, ""); err != nil {
+		var exitErr ExitError
+		if errors.As(err, &exitErr) {
+			err = exitErr.Err
+		}
+		if runLimitReached(ctx) {
+			err = fmt.Errorf("%w: %w", errMaxDuration, err)
+		}
+		return "", err
+	}
+	w.specSum = sum
+	return outcome, nil
+}
+
+// clarify turns the watched file's content into an FCS
+func (w *specWatch) clarify(ctx context.Context, content []byte) (*models.FinalClarifiedSpecification, error) {
+	if watchFCS {
+		fcs, _, err := schema.LoadFCS(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse FCS file %s: %w", w.specFile, err)
+		}
+		return fcs, nil
+	}
+
+	format, err := detectSpecFormat(w.specFile)
+	if err != nil {
+		return nil, err
+	}
+	inputSpec, err := parseSpec(w.specFile, format, string(content))
+	if err != nil {
+		return nil, fmt.Errorf("specification validation failed: %w", err)
+	}
+
+	if w.engine == nil {
+		llmClient, err := createLLMClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create LLM client: %w", err)
+		}
+		engine, err := clarify.NewEngine(clarify.EngineConfig{LLMClient: llmClient})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create clarification engine: %w", err)
+		}
+		w.engine = engine
+	}
+
+	fmt.Printf("\nClarifying %s\n", w.specFile)
+	fcs, err := clarifySpec(ctx, w.engine, inputSpec, false)
+	if err != nil {
+		return nil, fmt.Errorf("clarification failed: %w", err)
+	}
+	return fcs, nil
+}
+
+// impact compares fcs with the FCS of the last generation, or returns nil
+// when the output directory has no generated files recorded yet
+func (w *specWatch) impact(fcs *models.FinalClarifiedSpecification) (*generate.ImpactReport, error) {
+	if _, err := os.Stat(filepath.Join(w.outputDir, ".gocreator", "state.json")); err != nil {
+		return nil, nil
+	}
+	state, err := generate.NewIncrementalStateManager(w.outputDir).Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load incremental state: %w", err)
+	}
+	if len(state.GeneratedFiles) == 0 {
+		return nil, nil
+	}
+	return generate.AnalyzeImpact(state, fcs, codeEstimate(cfg))
+}
@@ -0,0 +1,426 @@
+// Package bundle exports generated patches as a portable tar archive and
+// applies them to another tree without LLM access.
+//
+// A bundle contains manifest.json, one patches/<path>.patch file per changed
+// file, and a SHA256SUMS file covering both. Each manifest entry records the
+// checksum of the content its patch was computed against, so the receiving
+// machine can detect drift before anything is written.
+package bundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+)
+
+// SchemaVersion is the bundle manifest format version
+const SchemaVersion = "1.0"
+
+const (
+	manifestName  = "manifest.json"
+	checksumsName = "SHA256SUMS"
+	patchDir      = "patches/"
+
+	// maxEntrySize bounds a single archive member to guard against corrupt or hostile bundles
+	maxEntrySize = 64 << 20
+)
+
+// Manifest describes the patches in a bundle
+type Manifest struct {
+	SchemaVersion string    `json:"schema_version"`
+	OutputID      string    `json:"output_id,omitempty"`
+	PlanID        string    `json:"plan_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	PatchFormat   string    `json:"patch_format"` // DiffEngine name, e.g. "unified"
+	Files         []Entry   `json:"files"`
+}
+
+// Entry describes one patched file
+type Entry struct {
+	Path         string `json:"path"`
+	Patch        string `json:"patch"`                   // Archive member holding the diff
+	BaseChecksum string `json:"base_checksum,omitempty"` // Content the diff applies to; empty for new files
+	Checksum     string `json:"checksum"`                // Content after applying the diff
+}
+
+// Bundle is a manifest together with its patch contents
+type Bundle struct {
+	Manifest Manifest
+	patches  map[string]string // Entry.Path -> diff
+}
+
+// New builds a bundle from a generation output. Files whose patch is empty,
+// because the generated content matched the existing file, are omitted.
+func New(output *models.GenerationOutput, patchFormat string) (*Bundle, error) {
+	if output == nil {
+		return nil, fmt.Errorf("generation output is required")
+	}
+
+	checksums := make(map[string]string, len(output.Files))
+	for _, file := range output.Files {
+		checksums[file.Path] = file.Checksum
+	}
+
+	b := &Bundle{
+		Manifest: Manifest{
+			SchemaVersion: SchemaVersion,
+			OutputID:      output.ID,
+			PlanID:        output.PlanID,
+			CreatedAt:     time.Now().UTC(),
+			PatchFormat:   patchFormat,
+		},
+		patches: make(map[string]string, len(output.Patches)),
+	}
+
+	for _, patch := range output.Patches {
+		if patch.Diff == "" {
+			continue
+		}
+
+		target, err := cleanTarget(patch.TargetFile)
+		if err != nil {
+			return nil, err
+		}
+		checksum, ok := checksums[patch.TargetFile]
+		if !ok {
+			return nil, fmt.Errorf("no generated file recorded for patch target %s", patch.TargetFile)
+		}
+		if _, dup := b.patches[target]; dup {
+			return nil, fmt.Errorf("duplicate patch target: %s", target)
+		}
+
+		b.patches[target] = patch.Diff
+		b.Manifest.Files = append(b.Manifest.Files, Entry{
+			Path:         target,
+			Patch:        patchDir + target + ".patch",
+			BaseChecksum: patch.BaseChecksum,
+			Checksum:     checksum,
+		})
+	}
+
+	sort.Slice(b.Manifest.Files, func(i, j int) bool {
+		return b.Manifest.Files[i].Path < b.Manifest.Files[j].Path
+	})
+
+	return b, nil
+}
+
+// Patch returns the diff for a file in the bundle
+func (b *Bundle) Patch(target string) (string, bool) {
+	diff, ok := b.patches[target]
+	return diff, ok
+}
+
+// member is one file in the bundle archive
+type member struct {
+	name    string
+	content []byte
+}
+
+// Write serializes the bundle as a tar archive
+func (b *Bundle) Write(w io.Writer) error {
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	members := []member{{manifestName, manifest}}
+	for _, entry := range b.Manifest.Files {
+		members = append(members, member{entry.Patch, []byte(b.patches[entry.Path])})
+	}
+
+	var sums strings.Builder
+	for _, m := range members {
+		sums.WriteString(fmt.Sprintf("%s  %s\n", sha256Hex(m.content), m.name))
+	}
+
+	members = append(members, member{checksumsName, []byte(sums.String())})
+
+	tw := tar.NewWriter(w)
+	for _, m := range members {
+		header := &tar.Header{
+			Name:    m.name,
+			Mode:    0o644,
+			Size:    int64(len(m.content)),
+			ModTime: b.Manifest.CreatedAt,
+			Format:  tar.FormatPAX,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s header: %w", m.name, err)
+		}
+		if _, err := tw.Write(m.content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", m.name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+	return nil
+}
+
+// Read parses a bundle archive and verifies every member against SHA256SUMS
+func Read(r io.Reader) (*Bundle, error) {
+	members := make(map[string][]byte)
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Size > maxEntrySize {
+			return nil, fmt.Errorf("bundle member %s exceeds %d bytes", header.Name, maxEntrySize)
+		}
+
+		content, err := io.ReadAll(io.LimitReader(tr, maxEntrySize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		members[header.Name] = content
+	}
+
+	sums, ok := members[checksumsName]
+	if !ok {
+		return nil, fmt.Errorf("bundle is missing %s", checksumsName)
+	}
+	expected, err := parseChecksums(sums)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, content := range members {
+		if name == checksumsName {
+			continue
+		}
+		want, listed := expected[name]
+		if !listed {
+			return nil, fmt.Errorf("bundle member %s is not listed in %s", name, checksumsName)
+		}
+		if got := sha256Hex(content); got != want {
+			return nil, fmt.Errorf("checksum mismatch for %s: bundle is corrupt or was modified", name)
+		}
+	}
+
+	raw, ok := members[manifestName]
+	if !ok {
+		return nil, fmt.Errorf("bundle is missing %s", manifestName)
+	}
+
+	b := &Bundle{patches: make(map[string]string)}
+	if err := json.Unmarshal(raw, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if b.Manifest.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("unsupported bundle schema version %q (expected %s)", b.Manifest.SchemaVersion, SchemaVersion)
+	}
+
+	for _, entry := range b.Manifest.Files {
+		if _, err := cleanTarget(entry.Path); err != nil {
+			return nil, err
+		}
+		diff, ok := members[entry.Patch]
+		if !ok {
+			return nil, fmt.Errorf("bundle is missing patch %s for %s", entry.Patch, entry.Path)
+		}
+		b.patches[entry.Path] = string(diff)
+	}
+
+	return b, nil
+}
+
+// Status classifies a bundle entry against the receiving tree
+type Status string
+
+const (
+	// StatusNew means the file does not exist and the patch creates it
+	StatusNew Status = "new"
+	// StatusPending means the file matches the patch base and will be modified
+	StatusPending Status = "pending"
+	// StatusApplied means the file already has the patched content
+	StatusApplied Status = "applied"
+	// StatusConflict means the file differs from the content the patch was made against
+	StatusConflict Status = "conflict"
+)
+
+// Result reports the state of one file before or after applying a bundle
+type Result struct {
+	Path   string
+	Status Status
+	Reason string // Set for conflicts
+}
+
+// ConflictError reports files that drifted from the bundle's base content
+type ConflictError struct {
+	Conflicts []Result
+}
+
+// Error implements the error interface
+func (e *ConflictError) Error() string {
+	paths := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		paths[i] = c.Path
+	}
+	return fmt.Sprintf("%d file(s) conflict with the bundle: %s", len(e.Conflicts), strings.Join(paths, ", "))
+}
+
+// Check compares every entry with the tree managed by fileOps without writing anything
+func (b *Bundle) Check(ctx context.Context, fileOps fsops.FileOps) ([]Result, error) {
+	results := make([]Result, 0, len(b.Manifest.Files))
+
+	for _, entry := range b.Manifest.Files {
+		exists, err := fileOps.Exists(ctx, entry.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", entry.Path, err)
+		}
+
+		result := Result{Path: entry.Path}
+		switch {
+		case !exists && entry.BaseChecksum == "":
+			result.Status = StatusNew
+		case !exists:
+			result.Status = StatusConflict
+			result.Reason = "file is missing but the patch modifies an existing file"
+		default:
+			current, err := fileOps.Checksum(ctx, entry.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to checksum %s: %w", entry.Path, err)
+			}
+			switch current {
+			case entry.Checksum:
+				result.Status = StatusApplied
+			case entry.BaseChecksum:
+				result.Status = StatusPending
+			default:
+				result.Status = StatusConflict
+				if entry.BaseChecksum == "" {
+					result.Reason = "file already exists but the patch creates it"
+				} else {
+					result.Reason = "file was modified since the bundle was created"
+				}
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// ApplyOptions controls how conflicts are handled
+type ApplyOptions struct {
+	// Force applies patches to conflicting files anyway, relying on the diff
+	// engine to relocate hunks. Files whose hunks no longer match still fail.
+	Force bool
+}
+
+// Apply checks the tree and applies every new and pending patch. Unless
+// opts.Force is set, nothing is written when any file conflicts.
+func (b *Bundle) Apply(ctx context.Context, fileOps fsops.FileOps, opts ApplyOptions) ([]Result, error) {
+	results, err := b.Check(ctx, fileOps)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []Result
+	for _, r := range results {
+		if r.Status == StatusConflict {
+			conflicts = append(conflicts, r)
+		}
+	}
+	if len(conflicts) > 0 && !opts.Force {
+		return results, &ConflictError{Conflicts: conflicts}
+	}
+
+	entries := make(map[string]Entry, len(b.Manifest.Files))
+	for _, entry := range b.Manifest.Files {
+		entries[entry.Path] = entry
+	}
+
+	for i, r := range results {
+		if r.Status == StatusApplied {
+			continue
+		}
+
+		entry := entries[r.Path]
+		patch := models.Patch{
+			TargetFile:   entry.Path,
+			Diff:         b.patches[entry.Path],
+			AppliedAt:    time.Now(),
+			Reversible:   true,
+			BaseChecksum: entry.BaseChecksum,
+		}
+		if err := fileOps.ApplyPatchWithBackup(ctx, patch); err != nil {
+			return results, fmt.Errorf("failed to apply patch to %s: %w", entry.Path, err)
+		}
+
+		// Forced patches land on drifted content, so only clean applies must reproduce the checksum
+		if r.Status != StatusConflict {
+			checksum, err := fileOps.Checksum(ctx, entry.Path)
+			if err != nil {
+				return results, fmt.Errorf("failed to checksum %s: %w", entry.Path, err)
+			}
+			if checksum != entry.Checksum {
+				return results, fmt.Errorf("checksum mismatch after applying %s", entry.Path)
+			}
+		}
+		results[i].Status = StatusApplied
+	}
+
+	return results, nil
+}
+
+// cleanTarget normalizes a target path and rejects paths that escape the tree
+func cleanTarget(target string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(target, "\\", "/"))
+	if cleaned == "." || strings.HasPrefix(cleaned, "/") || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("invalid patch target path: %q", target)
+	}
+	return cleaned, nil
+}
+
+// parseChecksums parses sha256sum-style "<hex>  <name>" lines
+func parseChecksums(data []byte) (map[string]string, error) {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		sum, name, ok := strings.Cut(line, "  ")
+		if !ok || len(sum) != sha256.Size*2 {
+			return nil, fmt.Errorf("malformed %s line: %q", checksumsName, line)
+		}
+		sums[name] = sum
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", checksumsName, err)
+	}
+	return sums, nil
+}
+
+// sha256Hex returns the hex SHA-256 digest of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
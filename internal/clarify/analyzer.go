@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/promptguard"
+	"github.com/dshills/gocreator/internal/schema"
 	"github.com/dshills/gocreator/pkg/llm"
 	"github.com/rs/zerolog/log"
 )
@@ -41,7 +43,7 @@ func (a *LLMAnalyzer) Analyze(ctx context.Context, spec *models.InputSpecificati
 	prompt := a.buildAnalysisPrompt(spec)
 
 	// Call LLM with deterministic temperature (0.0)
-	response, err := a.client.Generate(ctx, prompt)
+	response, err := generateArray(ctx, a.client, prompt, schema.Ambiguities(), "ambiguities")
 	if err != nil {
 		return nil, fmt.Errorf("LLM analysis failed: %w", err)
 	}
@@ -60,6 +62,26 @@ func (a *LLMAnalyzer) Analyze(ctx context.Context, spec *models.InputSpecificati
 	return ambiguities, nil
 }
 
+// generateArray sends prompt and returns the JSON array the LLM answers
+// with. Clients with structured output return it validated against s, under
+// key of an object since structured output is an object; other clients
+// return text that may wrap the array in markdown.
+func generateArray(ctx context.Context, client llm.Client, prompt string, s *schema.Schema, key string) (string, error) {
+	if !llm.SupportsStructuredOutput(client) {
+		return client.Generate(ctx, prompt)
+	}
+	output, err := client.GenerateStructured(ctx, prompt, s)
+	if err != nil {
+		return "", err
+	}
+	object, _ := output.(map[string]interface{})
+	data, err := json.Marshal(object[key])
+	if err != nil {
+		return "", fmt.Errorf("failed to encode structured output: %w", err)
+	}
+	return string(data), nil
+}
+
 // buildAnalysisPrompt constructs the prompt for ambiguity detection
 func (a *LLMAnalyzer) buildAnalysisPrompt(spec *models.InputSpecification) string {
 	var sb strings.Builder
@@ -68,9 +90,10 @@ func (a *LLMAnalyzer) buildAnalysisPrompt(spec *models.InputSpecification) strin
 	sb.WriteString("Analyze the following specification and identify ALL ambiguities, missing constraints, ")
 	sb.WriteString("conflicting requirements, unclear specifications, and underspecified features.\n\n")
 
+	sb.WriteString(promptguard.Instructions)
 	sb.WriteString("# Specification Content\n\n")
-	sb.WriteString(spec.Content)
-	sb.WriteString("\n\n")
+	sb.WriteString(promptguard.Fence(spec.Content))
+	sb.WriteString("\n")
 
 	sb.WriteString("# Analysis Guidelines\n\n")
 	sb.WriteString("Identify the following types of ambiguities:\n\n")
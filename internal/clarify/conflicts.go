@@ -0,0 +1,110 @@
+package clarify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+	specparser "github.com/dshills/gocreator/internal/spec"
+	"github.com/rs/zerolog/log"
+)
+
+// maxConflictOptions is the most values a conflict question offers, the
+// limit on the options of any question
+const maxConflictOptions = 4
+
+// conflictQuestion asks which value a conflict between merged specs
+// resolves to. Each option is a value, labelled with the specs giving it.
+func conflictQuestion(conflict models.SpecConflict) models.Question {
+	values := conflict.Values()
+	if len(values) > maxConflictOptions {
+		values = values[:maxConflictOptions]
+	}
+
+	options := make([]models.Option, 0, len(values))
+	for _, value := range values {
+		specs := conflict.Specs(value)
+		options = append(options, models.Option{
+			Label:        strings.Join(specs, ", "),
+			Description:  value,
+			Implications: fmt.Sprintf("Keeps the value given by %s", strings.Join(specs, " and ")),
+		})
+	}
+	return models.Question{
+		ID:       conflict.ID,
+		Topic:    "Conflicting specifications",
+		Context:  fmt.Sprintf("The merged specifications disagree on %s", conflict.Path),
+		Question: fmt.Sprintf("Which value should %s have?", conflict.Path),
+		Options:  options,
+	}
+}
+
+// withConflictQuestions appends a question for each conflict between the
+// specs merged into spec that questions do not ask yet
+func withConflictQuestions(spec *models.InputSpecification, questions []models.Question) []models.Question {
+	asked := make(map[string]bool, len(questions))
+	for _, q := range questions {
+		asked[q.ID] = true
+	}
+	for _, conflict := range spec.Conflicts {
+		if !asked[conflict.ID] {
+			questions = append(questions, conflictQuestion(conflict))
+		}
+	}
+	return questions
+}
+
+// resolveConflicts returns the conflicts of a merged spec resolved by the
+// answers to their questions: the value of the selected option, or the
+// custom answer. Conflicts without an answer stay unresolved.
+func resolveConflicts(conflicts []models.SpecConflict, answers map[string]models.Answer) []models.SpecConflict {
+	resolved := make([]models.SpecConflict, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		if answer, ok := answers[conflict.ID]; ok {
+			conflict.Resolution = conflictResolution(conflict, answer)
+		}
+		resolved = append(resolved, conflict)
+	}
+	return resolved
+}
+
+// conflictResolution returns the value an answer to a conflict question
+// selects
+func conflictResolution(conflict models.SpecConflict, answer models.Answer) string {
+	if answer.SelectedOption != nil {
+		for _, value := range conflict.Values() {
+			if strings.EqualFold(strings.Join(conflict.Specs(value), ", "), strings.TrimSpace(*answer.SelectedOption)) {
+				return value
+			}
+		}
+	}
+	return answerText(answer)
+}
+
+// applyMergedStructure records the specs merged into spec and their
+// conflicts, resolved by answers, in the FCS, and copies the structure of
+// the merged spec, with the resolutions applied, into it
+func applyMergedStructure(fcs *models.FinalClarifiedSpecification, spec *models.InputSpecification, answers map[string]models.Answer) {
+	fcs.Metadata.Sources = spec.Sources
+	fcs.Metadata.Conflicts = resolveConflicts(spec.Conflicts, answers)
+
+	resolved, err := specparser.ApplyResolutions(spec, fcs.Metadata.Conflicts)
+	if err != nil {
+		log.Warn().Err(err).Str("spec_id", spec.ID).Msg("Failed to apply conflict resolutions")
+		resolved = spec
+	}
+	structured, err := specparser.BuildFCS(resolved)
+	if err != nil {
+		log.Warn().Err(err).Str("spec_id", spec.ID).Msg("Failed to structure merged specification")
+		return
+	}
+	fcs.Requirements = structured.Requirements
+	fcs.Architecture = structured.Architecture
+	fcs.DataModel = structured.DataModel
+	fcs.APIContracts = structured.APIContracts
+	fcs.CrossCutting = structured.CrossCutting
+	fcs.Contracts = structured.Contracts
+	fcs.TestingStrategy = structured.TestingStrategy
+	fcs.BuildConfig = structured.BuildConfig
+	fcs.FileTree = structured.FileTree
+}
@@ -134,6 +134,8 @@ func (e *ClarificationEngine) GenerateRequest(
 	if err != nil {
 		return nil, fmt.Errorf("question generation failed: %w", err)
 	}
+	questions = withHTTPFrameworkQuestion(spec, questions)
+	questions = withConflictQuestions(spec, questions)
 
 	// Create clarification request
 	request := &models.ClarificationRequest{
@@ -166,6 +168,16 @@ func (e *ClarificationEngine) ApplyAnswers(
 	spec *models.InputSpecification,
 	request *models.ClarificationRequest,
 	response *models.ClarificationResponse,
+) (*models.FinalClarifiedSpecification, error) {
+	return applyAnswers(spec, request, response)
+}
+
+// applyAnswers builds the FCS from a spec and the answers to its
+// clarification request. It makes no LLM calls.
+func applyAnswers(
+	spec *models.InputSpecification,
+	request *models.ClarificationRequest,
+	response *models.ClarificationResponse,
 ) (*models.FinalClarifiedSpecification, error) {
 	log.Info().
 		Str("spec_id", spec.ID).
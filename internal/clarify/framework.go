@@ -0,0 +1,62 @@
+package clarify
+
+import (
+	"regexp"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// httpFrameworkQuestionID identifies the question selecting the HTTP
+// framework, asked of every spec describing an HTTP API
+const httpFrameworkQuestionID = "http-framework"
+
+// httpAPIPattern matches the words of a spec describing an HTTP API
+var httpAPIPattern = regexp.MustCompile(`(?i)\b(https?|rest(ful)?|api|apis|endpoints?|routes?)\b`)
+
+// httpFrameworkQuestion asks which framework the router is built with
+func httpFrameworkQuestion() models.Question {
+	return models.Question{
+		ID:       httpFrameworkQuestionID,
+		Topic:    "HTTP framework",
+		Context:  "The specification describes an HTTP API; its routing, middleware and graceful shutdown are generated for one framework",
+		Question: "Which HTTP framework should the router be built with?",
+		Options: []models.Option{
+			{Label: models.HTTPFrameworkStdlib, Description: "The standard library's http.ServeMux", Implications: "No dependencies; middleware is written by hand"},
+			{Label: models.HTTPFrameworkChi, Description: "github.com/go-chi/chi/v5", Implications: "net/http handlers with chi's middleware"},
+			{Label: models.HTTPFrameworkGin, Description: "github.com/gin-gonic/gin", Implications: "Handlers take a *gin.Context"},
+			{Label: models.HTTPFrameworkEcho, Description: "github.com/labstack/echo/v4", Implications: "Handlers take an echo.Context and return an error"},
+		},
+	}
+}
+
+// withHTTPFrameworkQuestion appends the HTTP framework question to questions
+// when the spec describes an HTTP API and its architecture selects no
+// framework
+func withHTTPFrameworkQuestion(spec *models.InputSpecification, questions []models.Question) []models.Question {
+	if !httpAPIPattern.MatchString(spec.Content) {
+		return questions
+	}
+	if architecture, ok := spec.ParsedData["architecture"].(map[string]interface{}); ok {
+		if framework, _ := architecture["http_framework"].(string); framework != "" {
+			return questions
+		}
+	}
+	for _, q := range questions {
+		if q.ID == httpFrameworkQuestionID {
+			return questions
+		}
+	}
+	return append(questions, httpFrameworkQuestion())
+}
+
+// applyHTTPFramework sets the architecture's HTTP framework from the answer
+// to the HTTP framework question, when it names a supported framework
+func applyHTTPFramework(fcs *models.FinalClarifiedSpecification, answers map[string]models.Answer) {
+	answer, ok := answers[httpFrameworkQuestionID]
+	if !ok {
+		return
+	}
+	if framework := answerText(answer); models.IsHTTPFramework(framework) {
+		fcs.Architecture.HTTPFramework = framework
+	}
+}
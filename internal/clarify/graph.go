@@ -6,7 +6,9 @@ import (
 	"os"
 	"time"
 
+	"github.com/dshills/gocreator/internal/clarify/importers"
 	"github.com/dshills/gocreator/internal/models"
+	specparser "github.com/dshills/gocreator/internal/spec"
 	"github.com/dshills/langgraph-go/graph"
 	"github.com/dshills/langgraph-go/graph/emit"
 	"github.com/dshills/langgraph-go/graph/store"
@@ -260,6 +262,8 @@ func (cg *ClarificationGraph) generateQuestionsNode(ctx context.Context, s Clari
 			Route: graph.Stop(),
 		}
 	}
+	questions = withHTTPFrameworkQuestion(s.Spec, questions)
+	questions = withConflictQuestions(s.Spec, questions)
 
 	log.Info().
 		Int("questions_generated", len(questions)).
@@ -311,7 +315,7 @@ func buildFCSFromSpec(spec *models.InputSpecification, answers map[string]models
 	// 4. Validate completeness
 
 	fcs := &models.FinalClarifiedSpecification{
-		SchemaVersion:  "1.0",
+		SchemaVersion:  models.FCSSchemaVersion,
 		ID:             fmt.Sprintf("fcs-%s", spec.ID),
 		Version:        "1.0",
 		OriginalSpecID: spec.ID,
@@ -330,21 +334,23 @@ func buildFCSFromSpec(spec *models.InputSpecification, answers map[string]models
 		},
 	}
 
+	// Imported specs are structured by their source document, and merged
+	// specs by the merge, so carry the structure into the FCS
+	if len(spec.Sources) > 0 {
+		applyMergedStructure(fcs, spec, answers)
+	} else if importers.IsImported(spec) {
+		applyImportedStructure(fcs, spec)
+	}
+
 	// Apply answers if provided
 	for qID, answer := range answers {
-		var answerText string
-		if answer.SelectedOption != nil {
-			answerText = *answer.SelectedOption
-		} else if answer.CustomAnswer != nil {
-			answerText = *answer.CustomAnswer
-		}
-
 		fcs.Metadata.Clarifications = append(fcs.Metadata.Clarifications, models.AppliedClarification{
 			QuestionID: qID,
-			Answer:     answerText,
+			Answer:     answerText(answer),
 			AppliedTo:  "specification",
 		})
 	}
+	applyHTTPFramework(fcs, answers)
 
 	// Compute hash
 	hash, err := fcs.ComputeHash()
@@ -356,3 +362,16 @@ func buildFCSFromSpec(spec *models.InputSpecification, answers map[string]models
 
 	return fcs
 }
+
+// applyImportedStructure copies the requirements, data model and API
+// contracts of an imported spec into the FCS
+func applyImportedStructure(fcs *models.FinalClarifiedSpecification, spec *models.InputSpecification) {
+	structured, err := specparser.BuildFCS(spec)
+	if err != nil {
+		log.Warn().Err(err).Str("spec_id", spec.ID).Msg("Failed to structure imported specification")
+		return
+	}
+	fcs.Requirements = structured.Requirements
+	fcs.DataModel = structured.DataModel
+	fcs.APIContracts = structured.APIContracts
+}
@@ -0,0 +1,190 @@
+// Package importers converts specifications written in other formats, such
+// as OpenAPI documents, into GoCreator specifications, so an existing API
+// description can be clarified and generated like a hand-written spec.
+package importers
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/dshills/gocreator/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// ImportedFromKey is the spec field naming the format an imported spec was
+// converted from. Clarification carries the structure of imported specs
+// into the FCS.
+const ImportedFromKey = "imported_from"
+
+// entityPackage is the package imported entities and enums are placed in
+const entityPackage = "models"
+
+// Importer converts documents of one foreign format into GoCreator specs
+type Importer interface {
+	// Name identifies the format, e.g. OpenAPI
+	Name() string
+
+	// Detect reports whether content is a document in the importer's format
+	Detect(content string) bool
+
+	// Import converts the document
+	Import(content string) (*Result, error)
+}
+
+// importers are tried in order by Import
+var importers = []Importer{
+	&OpenAPIImporter{},
+}
+
+// Result is a foreign document converted to the parts of a GoCreator spec
+type Result struct {
+	Source       string // Format and version imported from, e.g. OpenAPI 3.0.3
+	Name         string
+	Description  string
+	Requirements []models.FunctionalRequirement
+	Entities     []models.Entity
+	Enums        []models.Enum
+	APIContracts []models.APIContract
+
+	// Warnings lists the constructs the importer could not convert
+	Warnings []string
+}
+
+// Import converts content with the first importer that detects its format.
+// It returns nil when no importer does, so the content is read as a
+// GoCreator spec.
+func Import(content string) (*Result, error) {
+	for _, importer := range importers {
+		if !importer.Detect(content) {
+			continue
+		}
+		result, err := importer.Import(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import %s document: %w", importer.Name(), err)
+		}
+		return result, nil
+	}
+	return nil, nil
+}
+
+// IsImported reports whether a spec was converted by an importer
+func IsImported(spec *models.InputSpecification) bool {
+	source, ok := spec.ParsedData[ImportedFromKey].(string)
+	return ok && source != ""
+}
+
+// importedSpec is a Result in the GoCreator spec layout
+type importedSpec struct {
+	Name         string                `yaml:"name"`
+	Description  string                `yaml:"description"`
+	ImportedFrom string                `yaml:"imported_from"`
+	Requirements []importedRequirement `yaml:"requirements"`
+	DataModel    *importedDataModel    `yaml:"data_model,omitempty"`
+	APIContracts []importedContract    `yaml:"api_contracts,omitempty"`
+}
+
+type importedRequirement struct {
+	ID          string `yaml:"id"`
+	Description string `yaml:"description"`
+	Category    string `yaml:"category,omitempty"`
+}
+
+type importedDataModel struct {
+	Entities []importedEntity `yaml:"entities,omitempty"`
+	Enums    []importedEnum   `yaml:"enums,omitempty"`
+}
+
+type importedEntity struct {
+	Name       string            `yaml:"name"`
+	Package    string            `yaml:"package"`
+	Attributes map[string]string `yaml:"attributes"`
+}
+
+type importedEnum struct {
+	Name        string   `yaml:"name"`
+	Package     string   `yaml:"package,omitempty"`
+	Values      []string `yaml:"values"`
+	Description string   `yaml:"description,omitempty"`
+}
+
+type importedContract struct {
+	Endpoint    string          `yaml:"endpoint"`
+	Method      string          `yaml:"method"`
+	Description string          `yaml:"description"`
+	Request     *importedFields `yaml:"request,omitempty"`
+	Response    importedFields  `yaml:"response"`
+}
+
+type importedFields struct {
+	Fields map[string]string `yaml:"fields"`
+}
+
+// Spec renders the result as a YAML GoCreator spec
+func (r *Result) Spec() (string, error) {
+	spec := importedSpec{
+		Name:         r.Name,
+		Description:  r.Description,
+		ImportedFrom: r.Source,
+		Requirements: make([]importedRequirement, 0, len(r.Requirements)),
+	}
+	for _, req := range r.Requirements {
+		spec.Requirements = append(spec.Requirements, importedRequirement{ID: req.ID, Description: req.Description, Category: req.Category})
+	}
+
+	if len(r.Entities) > 0 || len(r.Enums) > 0 {
+		spec.DataModel = &importedDataModel{}
+		for _, entity := range r.Entities {
+			spec.DataModel.Entities = append(spec.DataModel.Entities, importedEntity(entity))
+		}
+		for _, enum := range r.Enums {
+			spec.DataModel.Enums = append(spec.DataModel.Enums, importedEnum{
+				Name:        enum.Name,
+				Package:     enum.Package,
+				Values:      enum.Values,
+				Description: enum.Description,
+			})
+		}
+	}
+
+	for _, contract := range r.APIContracts {
+		imported := importedContract{
+			Endpoint:    contract.Endpoint,
+			Method:      contract.Method,
+			Description: contract.Description,
+			Response:    importedFields{Fields: contract.Response.Fields},
+		}
+		if len(contract.Request.Fields) > 0 {
+			imported.Request = &importedFields{Fields: contract.Request.Fields}
+		}
+		spec.APIContracts = append(spec.APIContracts, imported)
+	}
+
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to render imported spec: %w", err)
+	}
+	return fmt.Sprintf("# Imported from %s; edit the source document and import it again\n\n%s", r.Source, data), nil
+}
+
+// goName converts a schema or property name to an exported Go identifier
+func goName(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	identifier := sb.String()
+	if identifier == "" || unicode.IsDigit(rune(identifier[0])) {
+		identifier = "T" + identifier
+	}
+	return identifier
+}
@@ -0,0 +1,494 @@
+package importers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaRefPrefix starts references to the reusable schemas of a document
+const schemaRefPrefix = "#/components/schemas/"
+
+// httpMethods are the operations of a path item, in the order they are
+// imported
+var httpMethods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+// OpenAPIImporter converts OpenAPI 3.x documents, in YAML or JSON. Paths
+// become API contracts with one functional requirement per operation, and
+// object schemas under components become entities; string schemas with an
+// enum become enums.
+type OpenAPIImporter struct{}
+
+// openAPIDocument is the part of an OpenAPI document the importer reads
+type openAPIDocument struct {
+	OpenAPI    string                     `yaml:"openapi"`
+	Swagger    string                     `yaml:"swagger"`
+	Info       openAPIInfo                `yaml:"info"`
+	Paths      map[string]openAPIPathItem `yaml:"paths"`
+	Components openAPIComponents          `yaml:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Version     string `yaml:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas       map[string]*openAPISchema     `yaml:"schemas"`
+	Parameters    map[string]openAPIParameter   `yaml:"parameters"`
+	RequestBodies map[string]openAPIRequestBody `yaml:"requestBodies"`
+	Responses     map[string]openAPIResponse    `yaml:"responses"`
+}
+
+type openAPIPathItem struct {
+	Parameters []openAPIParameter `yaml:"parameters"`
+	Get        *openAPIOperation  `yaml:"get"`
+	Post       *openAPIOperation  `yaml:"post"`
+	Put        *openAPIOperation  `yaml:"put"`
+	Patch      *openAPIOperation  `yaml:"patch"`
+	Delete     *openAPIOperation  `yaml:"delete"`
+	Head       *openAPIOperation  `yaml:"head"`
+	Options    *openAPIOperation  `yaml:"options"`
+	Trace      *openAPIOperation  `yaml:"trace"`
+}
+
+// operation returns the operation for an HTTP method
+func (p openAPIPathItem) operation(method string) *openAPIOperation {
+	return map[string]*openAPIOperation{
+		"get": p.Get, "post": p.Post, "put": p.Put, "patch": p.Patch,
+		"delete": p.Delete, "head": p.Head, "options": p.Options, "trace": p.Trace,
+	}[method]
+}
+
+type openAPIOperation struct {
+	OperationID string                     `yaml:"operationId"`
+	Summary     string                     `yaml:"summary"`
+	Description string                     `yaml:"description"`
+	Tags        []string                   `yaml:"tags"`
+	Parameters  []openAPIParameter         `yaml:"parameters"`
+	RequestBody *openAPIRequestBody        `yaml:"requestBody"`
+	Responses   map[string]openAPIResponse `yaml:"responses"`
+}
+
+type openAPIParameter struct {
+	Ref    string         `yaml:"$ref"`
+	Name   string         `yaml:"name"`
+	In     string         `yaml:"in"`
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Ref     string                      `yaml:"$ref"`
+	Content map[string]openAPIMediaType `yaml:"content"`
+}
+
+type openAPIResponse struct {
+	Ref     string                      `yaml:"$ref"`
+	Content map[string]openAPIMediaType `yaml:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `yaml:"$ref"`
+	Type                 schemaType                `yaml:"type"`
+	Format               string                    `yaml:"format"`
+	Description          string                    `yaml:"description"`
+	Properties           map[string]*openAPISchema `yaml:"properties"`
+	Items                *openAPISchema            `yaml:"items"`
+	AdditionalProperties *additionalProperties     `yaml:"additionalProperties"`
+	Enum                 []interface{}             `yaml:"enum"`
+	AllOf                []*openAPISchema          `yaml:"allOf"`
+	OneOf                []*openAPISchema          `yaml:"oneOf"`
+	AnyOf                []*openAPISchema          `yaml:"anyOf"`
+}
+
+// schemaType is a schema's type. OpenAPI 3.1 allows a list such as
+// [string, "null"]; the first type other than null is used.
+type schemaType string
+
+// UnmarshalYAML reads a type name or a list of type names
+func (t *schemaType) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*t = schemaType(node.Value)
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Value != "null" {
+				*t = schemaType(item.Value)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// additionalProperties is the schema of a map's values; true allows any value
+type additionalProperties struct {
+	Schema *openAPISchema
+}
+
+// UnmarshalYAML reads a schema or a boolean
+func (a *additionalProperties) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		a.Schema = &openAPISchema{}
+		return node.Decode(a.Schema)
+	case yaml.ScalarNode:
+		if node.Value == "true" {
+			a.Schema = &openAPISchema{}
+		}
+	}
+	return nil
+}
+
+// Name identifies the format
+func (i *OpenAPIImporter) Name() string {
+	return "OpenAPI"
+}
+
+// Detect reports whether content is an OpenAPI or Swagger document. Swagger
+// 2.0 is detected so that importing it reports that it is unsupported.
+func (i *OpenAPIImporter) Detect(content string) bool {
+	var header struct {
+		OpenAPI string `yaml:"openapi"`
+		Swagger string `yaml:"swagger"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &header); err != nil {
+		return false
+	}
+	return header.OpenAPI != "" || header.Swagger != ""
+}
+
+// Import converts an OpenAPI 3.x document
+func (i *OpenAPIImporter) Import(content string) (*Result, error) {
+	var doc openAPIDocument
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+	if doc.OpenAPI == "" {
+		return nil, fmt.Errorf("swagger %s documents are not supported; convert the document to OpenAPI 3", doc.Swagger)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("openapi %s is not supported (want 3.x)", doc.OpenAPI)
+	}
+	if strings.TrimSpace(doc.Info.Title) == "" {
+		return nil, fmt.Errorf("info.title is required")
+	}
+
+	c := &openAPIConverter{doc: &doc}
+	result := &Result{
+		Source:      "OpenAPI " + doc.OpenAPI,
+		Name:        strings.TrimSpace(doc.Info.Title),
+		Description: strings.TrimSpace(doc.Info.Description),
+	}
+	if result.Description == "" {
+		result.Description = fmt.Sprintf("%s API, imported from its OpenAPI document (version %s)", result.Name, doc.Info.Version)
+	}
+
+	c.convertSchemas(result)
+	c.convertPaths(result)
+	result.Warnings = c.warnings
+	return result, nil
+}
+
+// openAPIConverter converts one document
+type openAPIConverter struct {
+	doc      *openAPIDocument
+	types    map[string]string // Component schema names to the Go type they became
+	warnings []string
+}
+
+// convertSchemas converts the component schemas to entities and enums
+func (c *openAPIConverter) convertSchemas(result *Result) {
+	c.types = make(map[string]string, len(c.doc.Components.Schemas))
+	names := sortedKeys(c.doc.Components.Schemas)
+
+	// Name the entities and enums first so schemas can reference each other
+	for _, name := range names {
+		schema := c.doc.Components.Schemas[name]
+		if schema == nil {
+			continue
+		}
+		switch {
+		case len(schema.Enum) > 0 && (schema.Type == "string" || schema.Type == ""):
+			enum := models.Enum{Name: goName(name), Package: entityPackage, Description: strings.TrimSpace(schema.Description)}
+			for _, value := range schema.Enum {
+				enum.Values = append(enum.Values, fmt.Sprint(value))
+			}
+			if err := enum.Validate(); err != nil {
+				c.warn("schema %s: %v; imported as a string", name, err)
+				continue
+			}
+			result.Enums = append(result.Enums, enum)
+			c.types[name] = enum.Name
+		case c.isObject(schema):
+			c.types[name] = goName(name)
+		}
+	}
+
+	for _, name := range names {
+		schema := c.doc.Components.Schemas[name]
+		if schema == nil || !c.isObject(schema) || c.types[name] == "" {
+			continue
+		}
+		attributes := make(map[string]string)
+		c.collectProperties(schema, attributes, map[string]bool{name: true})
+		result.Entities = append(result.Entities, models.Entity{
+			Name:       c.types[name],
+			Package:    entityPackage,
+			Attributes: attributes,
+		})
+	}
+}
+
+// isObject reports whether a schema has properties to become an entity
+func (c *openAPIConverter) isObject(schema *openAPISchema) bool {
+	return len(schema.Enum) == 0 && (len(schema.Properties) > 0 || len(schema.AllOf) > 0 ||
+		(schema.Type == "object" && schema.AdditionalProperties == nil))
+}
+
+// collectProperties adds the properties of a schema, including those of the
+// schemas it composes with allOf, as Go types
+func (c *openAPIConverter) collectProperties(schema *openAPISchema, attributes map[string]string, visiting map[string]bool) {
+	if schema.Ref != "" {
+		name, target := c.schemaRef(schema.Ref)
+		if target == nil || visiting[name] {
+			return
+		}
+		visiting[name] = true
+		defer delete(visiting, name)
+		schema = target
+	}
+	for _, part := range schema.AllOf {
+		if part != nil {
+			c.collectProperties(part, attributes, visiting)
+		}
+	}
+	for property, propertySchema := range schema.Properties {
+		attributes[property] = c.goType(propertySchema)
+	}
+}
+
+// goType returns the Go type for a schema
+func (c *openAPIConverter) goType(schema *openAPISchema) string {
+	if schema == nil {
+		return "any"
+	}
+	if schema.Ref != "" {
+		name, target := c.schemaRef(schema.Ref)
+		if target == nil {
+			return "any"
+		}
+		if typ, ok := c.types[name]; ok {
+			return typ
+		}
+		return c.goType(target)
+	}
+	if len(schema.AllOf) == 1 {
+		return c.goType(schema.AllOf[0])
+	}
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		return "any"
+	}
+
+	switch schema.Type {
+	case "string":
+		switch schema.Format {
+		case "date-time", "date":
+			return "time.Time"
+		case "byte", "binary":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		switch schema.Format {
+		case "int32", "int64":
+			return schema.Format
+		}
+		return "int"
+	case "number":
+		if schema.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + c.goType(schema.Items)
+	case "object":
+		if schema.AdditionalProperties != nil {
+			return "map[string]" + c.goType(schema.AdditionalProperties.Schema)
+		}
+		return "map[string]any"
+	}
+	return "any"
+}
+
+// schemaRef resolves a reference to a component schema. References to other
+// documents cannot be followed.
+func (c *openAPIConverter) schemaRef(ref string) (string, *openAPISchema) {
+	name, ok := strings.CutPrefix(ref, schemaRefPrefix)
+	if !ok {
+		c.warn("reference %s is not a component schema; typed as any", ref)
+		return "", nil
+	}
+	schema := c.doc.Components.Schemas[name]
+	if schema == nil {
+		c.warn("reference %s has no schema; typed as any", ref)
+	}
+	return name, schema
+}
+
+// convertPaths converts every operation to an API contract and a functional
+// requirement
+func (c *openAPIConverter) convertPaths(result *Result) {
+	for _, path := range sortedKeys(c.doc.Paths) {
+		item := c.doc.Paths[path]
+		for _, method := range httpMethods {
+			op := item.operation(method)
+			if op == nil {
+				continue
+			}
+
+			contract := models.APIContract{
+				Endpoint:    path,
+				Method:      strings.ToUpper(method),
+				Description: operationSummary(op),
+				Request:     c.requestSchema(item.Parameters, op),
+				Response:    c.responseSchema(op),
+			}
+			result.APIContracts = append(result.APIContracts, contract)
+
+			requirement := models.FunctionalRequirement{
+				ID:          fmt.Sprintf("FR-%03d", len(result.Requirements)+1),
+				Description: fmt.Sprintf("System MUST handle %s %s", contract.Method, path),
+			}
+			if contract.Description != "" {
+				requirement.Description += ": " + contract.Description
+			}
+			if len(op.Tags) > 0 {
+				requirement.Category = op.Tags[0]
+			}
+			result.Requirements = append(result.Requirements, requirement)
+		}
+	}
+}
+
+// requestSchema returns the fields of an operation's parameters, including
+// those shared by its path, and of its request body
+func (c *openAPIConverter) requestSchema(shared []openAPIParameter, op *openAPIOperation) models.ContractSchema {
+	fields := make(map[string]string)
+	for _, param := range append(append([]openAPIParameter{}, shared...), op.Parameters...) {
+		if param.Ref != "" {
+			param = c.doc.Components.Parameters[strings.TrimPrefix(param.Ref, "#/components/parameters/")]
+		}
+		if param.Name != "" {
+			fields[param.Name] = c.goType(param.Schema)
+		}
+	}
+
+	if body := op.RequestBody; body != nil {
+		if body.Ref != "" {
+			resolved := c.doc.Components.RequestBodies[strings.TrimPrefix(body.Ref, "#/components/requestBodies/")]
+			body = &resolved
+		}
+		c.bodyFields(body.Content, fields)
+	}
+	return models.ContractSchema{Fields: fields}
+}
+
+// responseSchema returns the fields of an operation's first success response,
+// or of its default response
+func (c *openAPIConverter) responseSchema(op *openAPIOperation) models.ContractSchema {
+	fields := make(map[string]string)
+	for _, code := range sortedKeys(op.Responses) {
+		if !strings.HasPrefix(code, "2") && code != "default" {
+			continue
+		}
+		response := op.Responses[code]
+		if response.Ref != "" {
+			response = c.doc.Components.Responses[strings.TrimPrefix(response.Ref, "#/components/responses/")]
+		}
+		c.bodyFields(response.Content, fields)
+		break
+	}
+	return models.ContractSchema{Fields: fields}
+}
+
+// bodyFields adds the properties of a JSON body, or the whole body as "body"
+// when it is not an object
+func (c *openAPIConverter) bodyFields(content map[string]openAPIMediaType, fields map[string]string) {
+	schema := mediaSchema(content)
+	if schema == nil {
+		return
+	}
+
+	properties := make(map[string]string)
+	c.collectProperties(schema, properties, map[string]bool{})
+	if len(properties) == 0 {
+		fields["body"] = c.goType(schema)
+		return
+	}
+	for name, typ := range properties {
+		fields[name] = typ
+	}
+}
+
+// mediaSchema picks the schema of the JSON media type, falling back to the
+// first media type
+func mediaSchema(content map[string]openAPIMediaType) *openAPISchema {
+	if media, ok := content["application/json"]; ok {
+		return media.Schema
+	}
+	types := sortedKeys(content)
+	for _, mediaType := range types {
+		if strings.HasSuffix(mediaType, "+json") {
+			return content[mediaType].Schema
+		}
+	}
+	if len(types) > 0 {
+		return content[types[0]].Schema
+	}
+	return nil
+}
+
+// operationSummary describes an operation from its summary, the first line
+// of its description or its ID
+func operationSummary(op *openAPIOperation) string {
+	if summary := strings.TrimSpace(op.Summary); summary != "" {
+		return summary
+	}
+	if description := strings.TrimSpace(op.Description); description != "" {
+		line, _, _ := strings.Cut(description, "\n")
+		return strings.TrimSpace(line)
+	}
+	return op.OperationID
+}
+
+// warn records a construct that could not be converted
+func (c *openAPIConverter) warn(format string, args ...any) {
+	warning := fmt.Sprintf(format, args...)
+	for _, existing := range c.warnings {
+		if existing == warning {
+			return
+		}
+	}
+	c.warnings = append(c.warnings, warning)
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
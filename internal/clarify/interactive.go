@@ -0,0 +1,222 @@
+package clarify
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// backCommand revisits the previous question so its answer can be revised
+const backCommand = "back"
+
+// SessionConfig configures an interactive clarification session
+type SessionConfig struct {
+	// In is where answers are read from
+	In io.Reader
+
+	// Out is where questions and FCS revisions are written
+	Out io.Writer
+}
+
+// Session asks the questions of a clarification request one at a time and
+// revises the FCS after every answer, so the user sees each answer applied
+// before the next question. Entering "back" returns to the previous
+// question; answering it again replaces the earlier answer.
+type Session struct {
+	config  SessionConfig
+	reader  *bufio.Reader
+	lines   chan sessionLine
+	pending bool
+}
+
+// sessionLine is one line read from the input
+type sessionLine struct {
+	text string
+	err  error
+}
+
+// NewSession creates an interactive clarification session
+func NewSession(config SessionConfig) *Session {
+	return &Session{
+		config: config,
+		reader: bufio.NewReader(config.In),
+		lines:  make(chan sessionLine, 1),
+	}
+}
+
+// Run asks every question of request and returns the FCS built from the
+// answers. It fails when the input ends before every question is answered.
+func (s *Session) Run(
+	ctx context.Context,
+	spec *models.InputSpecification,
+	request *models.ClarificationRequest,
+) (*models.FinalClarifiedSpecification, error) {
+	response := &models.ClarificationResponse{
+		SchemaVersion: request.SchemaVersion,
+		ID:            uuid.New().String(),
+		RequestID:     request.ID,
+		Answers:       make(map[string]models.Answer, len(request.Questions)),
+	}
+
+	if len(request.Questions) == 0 {
+		s.printf("No open questions; the specification needs no clarification.\n\n")
+	} else {
+		s.printf("%d questions to answer. Enter an option number or label, or your own answer; %q revisits the previous question.\n\n",
+			len(request.Questions), backCommand)
+	}
+
+	revision := 0
+	for i := 0; i < len(request.Questions); {
+		q := request.Questions[i]
+		s.showQuestion(i, len(request.Questions), q)
+
+		answer, back, err := s.ask(ctx, q)
+		if err != nil {
+			return nil, fmt.Errorf("question %s: %w", q.ID, err)
+		}
+		if back {
+			if i == 0 {
+				s.printf("This is the first question.\n\n")
+				continue
+			}
+			i--
+			continue
+		}
+
+		previous, revised := response.Answers[q.ID]
+		response.Answers[q.ID] = answer
+		revision++
+
+		// Apply the answers so far, as the final FCS will
+		fcs := buildFCSFromSpec(spec, response.Answers)
+		if revised {
+			s.printf("Revised %s: %s -> %s\n", q.ID, answerText(previous), answerText(answer))
+		}
+		s.printf("FCS revision %d: %d of %d questions answered (hash %s)\n\n",
+			revision, len(fcs.Metadata.Clarifications), len(request.Questions), shortHash(fcs.Metadata.Hash))
+
+		log.Debug().
+			Str("question_id", q.ID).
+			Int("revision", revision).
+			Bool("revised", revised).
+			Msg("Clarification answer applied")
+
+		i++
+	}
+
+	response.AnsweredAt = time.Now()
+	return applyAnswers(spec, request, response)
+}
+
+// showQuestion writes a question with its numbered options
+func (s *Session) showQuestion(i, total int, q models.Question) {
+	if q.Topic != "" {
+		s.printf("--- Question %d of %d: %s ---\n\n", i+1, total, q.Topic)
+	} else {
+		s.printf("--- Question %d of %d ---\n\n", i+1, total)
+	}
+	if q.Context != "" {
+		s.printf("%s\n\n", strings.TrimSpace(q.Context))
+	}
+	s.printf("%s\n\n", strings.TrimSpace(q.Question))
+	for n, opt := range q.Options {
+		if opt.Description != "" {
+			s.printf("  %d. %s - %s\n", n+1, opt.Label, opt.Description)
+		} else {
+			s.printf("  %d. %s\n", n+1, opt.Label)
+		}
+		if opt.Implications != "" {
+			s.printf("     Implications: %s\n", opt.Implications)
+		}
+	}
+	if len(q.Options) > 0 {
+		s.printf("\n")
+	}
+}
+
+// ask reads answers until one is given for q or the user goes back. An
+// empty answer asks again.
+func (s *Session) ask(ctx context.Context, q models.Question) (models.Answer, bool, error) {
+	labels := make([]string, 0, len(q.Options))
+	for _, opt := range q.Options {
+		labels = append(labels, opt.Label)
+	}
+
+	for {
+		s.printf("Answer: ")
+
+		if !s.pending {
+			s.pending = true
+			go s.readLine()
+		}
+
+		var line sessionLine
+		select {
+		case <-ctx.Done():
+			s.printf("\n")
+			return models.Answer{}, false, ctx.Err()
+		case line = <-s.lines:
+			s.pending = false
+		}
+		if line.err != nil {
+			s.printf("\n")
+			return models.Answer{}, false, fmt.Errorf("input ended before the question was answered")
+		}
+
+		text := strings.TrimSpace(line.text)
+		switch {
+		case text == "":
+			s.printf("Please enter an answer.\n")
+			continue
+		case strings.EqualFold(text, backCommand):
+			s.printf("\n")
+			return models.Answer{}, true, nil
+		}
+		if n, err := strconv.Atoi(text); err == nil && n >= 1 && n <= len(labels) {
+			text = labels[n-1]
+		}
+		return matchAnswer(q.ID, text, labels), false, nil
+	}
+}
+
+// readLine reads one answer for the question waiting on lines
+func (s *Session) readLine() {
+	text, err := s.reader.ReadString('\n')
+	if err != nil && text != "" {
+		// A final line without a newline is still an answer
+		err = nil
+	}
+	s.lines <- sessionLine{text: text, err: err}
+}
+
+// printf writes to the session output
+func (s *Session) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(s.config.Out, format, args...)
+}
+
+// answerText returns the selected option or the custom answer
+func answerText(answer models.Answer) string {
+	if answer.SelectedOption != nil {
+		return *answer.SelectedOption
+	}
+	if answer.CustomAnswer != nil {
+		return *answer.CustomAnswer
+	}
+	return ""
+}
+
+// shortHash abbreviates an FCS hash for display
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/schema"
 	"github.com/dshills/gocreator/pkg/llm"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -44,7 +45,7 @@ func (g *LLMQuestionGenerator) Generate(ctx context.Context, ambiguities []model
 	prompt := g.buildQuestionPrompt(ambiguities)
 
 	// Call LLM with deterministic temperature (0.0)
-	response, err := g.client.Generate(ctx, prompt)
+	response, err := generateArray(ctx, g.client, prompt, schema.Questions(), "questions")
 	if err != nil {
 		return nil, fmt.Errorf("LLM question generation failed: %w", err)
 	}
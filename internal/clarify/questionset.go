@@ -0,0 +1,213 @@
+package clarify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// questionSetHeader explains an exported question set to whoever answers it
+const questionSetHeader = `# GoCreator clarification questions
+#
+# Answer each question by filling in its "answer" field, either with the
+# label of one of its options or with your own answer, then ingest the file:
+#
+#   gocreator clarify <spec-file> --answers <this-file>
+#
+# Do not change the question IDs or the spec checksum. If the specification
+# changes, export the questions again.
+
+`
+
+// QuestionSet is a clarification request exported to a file, so the open
+// questions can be answered offline and ingested by a later run
+type QuestionSet struct {
+	SchemaVersion string             `yaml:"schema_version"`
+	RequestID     string             `yaml:"request_id"`
+	SpecFile      string             `yaml:"spec_file"`
+	SpecChecksum  string             `yaml:"spec_checksum"`
+	CreatedAt     time.Time          `yaml:"created_at"`
+	Questions     []ExportedQuestion `yaml:"questions"`
+}
+
+// ExportedQuestion is a question in a question set, with the answer to fill in
+type ExportedQuestion struct {
+	ID       string           `yaml:"id"`
+	Topic    string           `yaml:"topic,omitempty"`
+	Context  string           `yaml:"context,omitempty"`
+	Question string           `yaml:"question"`
+	Options  []ExportedOption `yaml:"options"`
+	Answer   string           `yaml:"answer"`
+}
+
+// ExportedOption is one of the suggested answers to an exported question
+type ExportedOption struct {
+	Label        string `yaml:"label"`
+	Description  string `yaml:"description,omitempty"`
+	Implications string `yaml:"implications,omitempty"`
+}
+
+// SpecChecksum returns the checksum recorded for the spec a question set was
+// exported from
+func SpecChecksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewQuestionSet exports the questions of a clarification request for the
+// spec at specFile with the given content
+func NewQuestionSet(request *models.ClarificationRequest, specFile, content string) *QuestionSet {
+	set := &QuestionSet{
+		SchemaVersion: request.SchemaVersion,
+		RequestID:     request.ID,
+		SpecFile:      specFile,
+		SpecChecksum:  SpecChecksum(content),
+		CreatedAt:     request.CreatedAt,
+		Questions:     make([]ExportedQuestion, 0, len(request.Questions)),
+	}
+	for _, q := range request.Questions {
+		exported := ExportedQuestion{
+			ID:       q.ID,
+			Topic:    q.Topic,
+			Context:  q.Context,
+			Question: q.Question,
+			Options:  make([]ExportedOption, 0, len(q.Options)),
+		}
+		for _, opt := range q.Options {
+			exported.Options = append(exported.Options, ExportedOption(opt))
+		}
+		set.Questions = append(set.Questions, exported)
+	}
+	return set
+}
+
+// WriteQuestionSet writes a question set as YAML with instructions for
+// answering it
+func WriteQuestionSet(path string, set *QuestionSet) error {
+	data, err := yaml.Marshal(set)
+	if err != nil {
+		return fmt.Errorf("failed to marshal question set: %w", err)
+	}
+	if err := os.WriteFile(path, append([]byte(questionSetHeader), data...), 0o600); err != nil {
+		return fmt.Errorf("failed to write question set: %w", err)
+	}
+	return nil
+}
+
+// LoadQuestionSet reads a question set written by WriteQuestionSet
+func LoadQuestionSet(path string) (*QuestionSet, error) {
+	//nolint:gosec // G304: Reading user-provided answers file - required for CLI functionality
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read question set: %w", err)
+	}
+
+	var set QuestionSet
+	if err := yaml.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse question set: %w", err)
+	}
+	if set.RequestID == "" {
+		return nil, fmt.Errorf("question set has no request_id")
+	}
+	return &set, nil
+}
+
+// CheckSpec verifies that the question set was exported from a spec with
+// the given content
+func (s *QuestionSet) CheckSpec(content string) error {
+	if s.SpecChecksum != SpecChecksum(content) {
+		return fmt.Errorf("the specification changed since the questions were exported from %s; export them again with --export-questions", s.SpecFile)
+	}
+	return nil
+}
+
+// Request rebuilds the clarification request the question set was exported from
+func (s *QuestionSet) Request(specID string) *models.ClarificationRequest {
+	request := &models.ClarificationRequest{
+		SchemaVersion: s.SchemaVersion,
+		ID:            s.RequestID,
+		SpecID:        specID,
+		Questions:     make([]models.Question, 0, len(s.Questions)),
+		CreatedAt:     s.CreatedAt,
+	}
+	for _, q := range s.Questions {
+		question := models.Question{
+			ID:       q.ID,
+			Topic:    q.Topic,
+			Context:  q.Context,
+			Question: q.Question,
+			Options:  make([]models.Option, 0, len(q.Options)),
+		}
+		for _, opt := range q.Options {
+			question.Options = append(question.Options, models.Option(opt))
+		}
+		request.Questions = append(request.Questions, question)
+	}
+	return request
+}
+
+// Response converts the filled-in answers to a clarification response. An
+// answer matching an option's label, ignoring case, selects that option;
+// any other answer is a custom answer. Every question must be answered.
+func (s *QuestionSet) Response() (*models.ClarificationResponse, error) {
+	response := &models.ClarificationResponse{
+		SchemaVersion: s.SchemaVersion,
+		ID:            uuid.New().String(),
+		RequestID:     s.RequestID,
+		Answers:       make(map[string]models.Answer, len(s.Questions)),
+		AnsweredAt:    time.Now(),
+	}
+
+	var unanswered []string
+	for _, q := range s.Questions {
+		answer := strings.TrimSpace(q.Answer)
+		if answer == "" {
+			unanswered = append(unanswered, q.ID)
+			continue
+		}
+
+		labels := make([]string, 0, len(q.Options))
+		for _, opt := range q.Options {
+			labels = append(labels, opt.Label)
+		}
+		response.Answers[q.ID] = matchAnswer(q.ID, answer, labels)
+	}
+
+	if len(unanswered) > 0 {
+		return nil, fmt.Errorf("%d of %d questions are not answered: %s", len(unanswered), len(s.Questions), strings.Join(unanswered, ", "))
+	}
+	return response, nil
+}
+
+// Apply builds the FCS for spec from the answered questions. Unlike an
+// interactive run it makes no LLM calls.
+func (s *QuestionSet) Apply(spec *models.InputSpecification) (*models.FinalClarifiedSpecification, error) {
+	response, err := s.Response()
+	if err != nil {
+		return nil, err
+	}
+	return applyAnswers(spec, s.Request(spec.ID), response)
+}
+
+// matchAnswer converts an answer to a question with the given option labels.
+// An answer matching a label, ignoring case, selects that option; any other
+// answer is a custom answer.
+func matchAnswer(questionID, answer string, labels []string) models.Answer {
+	result := models.Answer{QuestionID: questionID}
+	for _, label := range labels {
+		if strings.EqualFold(answer, strings.TrimSpace(label)) {
+			selected := label
+			result.SelectedOption = &selected
+			return result
+		}
+	}
+	result.CustomAnswer = &answer
+	return result
+}
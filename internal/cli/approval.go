@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ApprovalAction is the outcome of an approval checkpoint
+type ApprovalAction string
+
+const (
+	// ApprovalContinue lets the pipeline proceed
+	ApprovalContinue ApprovalAction = "continue"
+
+	// ApprovalAbort stops the pipeline
+	ApprovalAbort ApprovalAction = "abort"
+)
+
+// ParseApprovalAction parses an approval action name
+func ParseApprovalAction(s string) (ApprovalAction, error) {
+	switch action := ApprovalAction(strings.ToLower(strings.TrimSpace(s))); action {
+	case ApprovalContinue, ApprovalAbort:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown approval action %q (want continue or abort)", s)
+	}
+}
+
+// ApprovalConfig configures approval checkpoints
+type ApprovalConfig struct {
+	// In is where answers are read from
+	In io.Reader
+
+	// Out is where summaries and questions are written
+	Out io.Writer
+
+	// Timeout is how long a checkpoint waits for an answer before taking
+	// the default action; 0 waits indefinitely
+	Timeout time.Duration
+
+	// Default is the action taken on timeout, on an empty answer and when
+	// In reaches EOF
+	Default ApprovalAction
+}
+
+// Approver pauses a pipeline at checkpoints until the user approves, or the
+// timeout takes the default action so unattended runs proceed.
+//
+// A single goroutine reads answers, one line per checkpoint. A checkpoint
+// that times out leaves its read pending, and the line that eventually
+// arrives answers the next checkpoint.
+type Approver struct {
+	config  ApprovalConfig
+	reader  *bufio.Reader
+	lines   chan approvalLine
+	pending bool
+}
+
+// approvalLine is one line read from the input
+type approvalLine struct {
+	text string
+	err  error
+}
+
+// NewApprover creates an approver
+func NewApprover(config ApprovalConfig) *Approver {
+	if config.Default == "" {
+		config.Default = ApprovalContinue
+	}
+	return &Approver{
+		config: config,
+		reader: bufio.NewReader(config.In),
+		lines:  make(chan approvalLine, 1),
+	}
+}
+
+// Ask shows the summary of the artifact produced before the checkpoint and
+// waits for the user to continue or abort. An unrecognized answer asks again.
+func (a *Approver) Ask(ctx context.Context, checkpoint, summary string) (ApprovalAction, error) {
+	_, _ = fmt.Fprintf(a.config.Out, "--- Checkpoint: %s ---\n\n", checkpoint)
+	if summary != "" {
+		_, _ = fmt.Fprintf(a.config.Out, "%s\n\n", strings.TrimRight(summary, "\n"))
+	}
+
+	var deadline <-chan time.Time
+	if a.config.Timeout > 0 {
+		timer := time.NewTimer(a.config.Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		_, _ = fmt.Fprintf(a.config.Out, "Continue? %s%s: ", a.choices(), a.timeoutNote())
+
+		if !a.pending {
+			a.pending = true
+			go a.readLine()
+		}
+
+		select {
+		case <-ctx.Done():
+			_, _ = fmt.Fprintln(a.config.Out)
+			return "", ctx.Err()
+
+		case <-deadline:
+			_, _ = fmt.Fprintf(a.config.Out, "\nNo answer within %v; %s\n\n", a.config.Timeout, describeApproval(a.config.Default))
+			return a.config.Default, nil
+
+		case line := <-a.lines:
+			a.pending = false
+			if line.err != nil {
+				// No more input (EOF): nobody is there to answer
+				_, _ = fmt.Fprintf(a.config.Out, "\n%s\n\n", describeApproval(a.config.Default))
+				return a.config.Default, nil
+			}
+			if action, ok := a.parseAnswer(line.text); ok {
+				_, _ = fmt.Fprintln(a.config.Out)
+				return action, nil
+			}
+			_, _ = fmt.Fprintf(a.config.Out, "Please answer y or n.\n")
+		}
+	}
+}
+
+// readLine reads one answer for the checkpoint waiting on lines
+func (a *Approver) readLine() {
+	text, err := a.reader.ReadString('\n')
+	if err != nil && text != "" {
+		// A final line without a newline is still an answer
+		err = nil
+	}
+	a.lines <- approvalLine{text: text, err: err}
+}
+
+// parseAnswer maps an answer to an action; an empty answer is the default
+func (a *Approver) parseAnswer(answer string) (ApprovalAction, bool) {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "":
+		return a.config.Default, true
+	case "y", "yes", "continue":
+		return ApprovalContinue, true
+	case "n", "no", "abort":
+		return ApprovalAbort, true
+	default:
+		return "", false
+	}
+}
+
+// choices renders the answers with the default capitalized
+func (a *Approver) choices() string {
+	if a.config.Default == ApprovalAbort {
+		return "[y/N]"
+	}
+	return "[Y/n]"
+}
+
+// timeoutNote tells the user what happens when they do not answer
+func (a *Approver) timeoutNote() string {
+	if a.config.Timeout <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s in %v)", a.config.Default, a.config.Timeout)
+}
+
+// describeApproval reports the action taken without an answer
+func describeApproval(action ApprovalAction) string {
+	if action == ApprovalAbort {
+		return "aborting"
+	}
+	return "continuing"
+}
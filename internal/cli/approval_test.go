@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApprover_Answers(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		def     ApprovalAction
+		want    ApprovalAction
+		reasked bool
+	}{
+		{name: "yes", input: "y\n", want: ApprovalContinue},
+		{name: "no", input: "no\n", want: ApprovalAbort},
+		{name: "empty takes default", input: "\n", def: ApprovalAbort, want: ApprovalAbort},
+		{name: "EOF takes default", input: "", want: ApprovalContinue},
+		{name: "last line without newline", input: "abort", want: ApprovalAbort},
+		{name: "unrecognized asks again", input: "maybe\nn\n", want: ApprovalAbort, reasked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			approver := NewApprover(ApprovalConfig{In: strings.NewReader(tt.input), Out: &out, Default: tt.def})
+
+			action, err := approver.Ask(context.Background(), "plan", "3 packages")
+			if err != nil {
+				t.Fatalf("Ask failed: %v", err)
+			}
+			if action != tt.want {
+				t.Errorf("Ask() = %s, want %s", action, tt.want)
+			}
+			if !strings.Contains(out.String(), "--- Checkpoint: plan ---\n\n3 packages\n") {
+				t.Errorf("Summary not shown:\n%s", out.String())
+			}
+			if got := strings.Contains(out.String(), "Please answer y or n."); got != tt.reasked {
+				t.Errorf("Asked again = %v, want %v", got, tt.reasked)
+			}
+		})
+	}
+}
+
+func TestApprover_Timeout(t *testing.T) {
+	in, answer := io.Pipe()
+	defer func() { _ = answer.Close() }()
+	var out bytes.Buffer
+	approver := NewApprover(ApprovalConfig{In: in, Out: &out, Timeout: 10 * time.Millisecond, Default: ApprovalAbort})
+
+	action, err := approver.Ask(context.Background(), "clarify", "")
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if action != ApprovalAbort {
+		t.Errorf("Ask() = %s, want the default", action)
+	}
+	if !strings.Contains(out.String(), "[y/N] (abort in 10ms)") || !strings.Contains(out.String(), "No answer within 10ms; aborting") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+
+	// The answer typed after the timeout goes to the next checkpoint
+	go func() { _, _ = answer.Write([]byte("y\n")) }()
+	approver.config.Timeout = 0
+	action, err = approver.Ask(context.Background(), "plan", "")
+	if err != nil {
+		t.Fatalf("Ask failed: %v", err)
+	}
+	if action != ApprovalContinue {
+		t.Errorf("Ask() = %s, want continue", action)
+	}
+}
+
+func TestApprover_Canceled(t *testing.T) {
+	in, answer := io.Pipe()
+	defer func() { _ = answer.Close() }()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	approver := NewApprover(ApprovalConfig{In: in, Out: io.Discard})
+	if _, err := approver.Ask(ctx, "plan", ""); err != context.Canceled {
+		t.Errorf("Ask() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestParseApprovalAction(t *testing.T) {
+	if action, err := ParseApprovalAction(" Abort "); err != nil || action != ApprovalAbort {
+		t.Errorf("ParseApprovalAction(Abort) = %s, %v", action, err)
+	}
+	if _, err := ParseApprovalAction("skip"); err == nil {
+		t.Error("Expected an error for an unknown action")
+	}
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ManHeader is the title block shared by every generated man page
+type ManHeader struct {
+	// Section is the manual section (default: 1)
+	Section string
+
+	// Source names the software, e.g. "GoCreator 0.1.0"
+	Source string
+
+	// Manual is the title of the manual, e.g. "GoCreator Manual"
+	Manual string
+
+	// Date is printed in the page footer
+	Date time.Time
+}
+
+// WriteManPages writes a roff man page for cmd and each of its available
+// subcommands to dir, named after the command path ("gocreator-debug-state.1").
+// Hidden commands and flags, and cobra's help command, are left out.
+func WriteManPages(cmd *cobra.Command, dir string, header ManHeader) error {
+	if header.Section == "" {
+		header.Section = "1"
+	}
+
+	for _, sub := range cmd.Commands() {
+		if !sub.IsAvailableCommand() || sub.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		if err := WriteManPages(sub, dir, header); err != nil {
+			return err
+		}
+	}
+
+	name := strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+	path := filepath.Join(dir, name+"."+header.Section)
+	if err := os.WriteFile(path, RenderManPage(cmd, header), 0o644); err != nil {
+		return fmt.Errorf("failed to write man page %s: %w", path, err)
+	}
+	return nil
+}
+
+// RenderManPage returns the roff man page of a single command
+func RenderManPage(cmd *cobra.Command, header ManHeader) []byte {
+	if header.Section == "" {
+		header.Section = "1"
+	}
+	name := strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, ".TH %q %q %q %q %q\n", strings.ToUpper(name), header.Section,
+		header.Date.Format("Jan 2006"), header.Source, header.Manual)
+
+	buf.WriteString(".SH NAME\n")
+	fmt.Fprintf(&buf, "%s \\- %s\n", roffEscape(name), roffEscape(cmd.Short))
+
+	buf.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&buf, ".B %s\n", roffEscape(cmd.UseLine()))
+
+	description := cmd.Long
+	if description == "" {
+		description = cmd.Short
+	}
+	buf.WriteString(".SH DESCRIPTION\n.nf\n")
+	for _, line := range strings.Split(strings.TrimSpace(description), "\n") {
+		buf.WriteString(roffEscape(line) + "\n")
+	}
+	buf.WriteString(".fi\n")
+
+	writeManFlags(&buf, "OPTIONS", cmd.NonInheritedFlags())
+	writeManFlags(&buf, "OPTIONS INHERITED FROM PARENT COMMANDS", cmd.InheritedFlags())
+
+	var related []string
+	if cmd.HasParent() {
+		related = append(related, manReference(cmd.Parent(), header.Section))
+	}
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() && !sub.IsAdditionalHelpTopicCommand() {
+			related = append(related, manReference(sub, header.Section))
+		}
+	}
+	if len(related) > 0 {
+		buf.WriteString(".SH SEE ALSO\n")
+		buf.WriteString(strings.Join(related, ", ") + "\n")
+	}
+
+	return buf.Bytes()
+}
+
+// writeManFlags writes a section listing the visible flags of a set
+func writeManFlags(buf *bytes.Buffer, title string, flags *pflag.FlagSet) {
+	var entries []string
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+		term := "\\fB\\-\\-" + roffEscape(flag.Name) + "\\fP"
+		if flag.Shorthand != "" && flag.ShorthandDeprecated == "" {
+			term = "\\fB\\-" + roffEscape(flag.Shorthand) + "\\fP, " + term
+		}
+		if flag.Value.Type() != "bool" {
+			term += "=" + roffEscape(flag.Value.Type())
+		}
+		usage := flag.Usage
+		if flag.DefValue != "" && flag.DefValue != "false" && flag.DefValue != "[]" && flag.DefValue != "0" {
+			usage += fmt.Sprintf(" (default: %s)", flag.DefValue)
+		}
+		entries = append(entries, ".TP\n"+term+"\n"+roffEscape(usage)+"\n")
+	})
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, ".SH %s\n", title)
+	for _, entry := range entries {
+		buf.WriteString(entry)
+	}
+}
+
+// manReference formats a SEE ALSO entry for a command
+func manReference(cmd *cobra.Command, section string) string {
+	return fmt.Sprintf("\\fB%s\\fP(%s)", roffEscape(strings.ReplaceAll(cmd.CommandPath(), " ", "-")), section)
+}
+
+// roffEscape escapes text so roff prints it literally: backslashes and
+// dashes are escaped, and a leading dot or quote is kept from being read as
+// a request
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\e")
+	s = strings.ReplaceAll(s, "-", "\\-")
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = "\\&" + s
+	}
+	return s
+}
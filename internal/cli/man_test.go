@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func manTestTree() *cobra.Command {
+	root := &cobra.Command{Use: "tool", Short: "A tool"}
+	root.PersistentFlags().StringP("config", "c", "", "config file")
+
+	debug := &cobra.Command{Use: "debug", Short: "Inspect runs"}
+	state := &cobra.Command{
+		Use:   "state <run-id>",
+		Short: "Show state",
+		Long:  "Show the state of a run.\n.dotted line stays text\n  tool debug state --after create-plan",
+		Run:   func(*cobra.Command, []string) {},
+	}
+	state.Flags().StringP("output", "o", ".", "output directory")
+	state.Flags().Bool("delta", false, "show the delta")
+	state.Flags().String("secret", "", "hidden flag")
+	_ = state.Flags().MarkHidden("secret")
+	hidden := &cobra.Command{Use: "internal", Hidden: true, Run: func(*cobra.Command, []string) {}}
+
+	debug.AddCommand(state)
+	root.AddCommand(debug, hidden)
+	return root
+}
+
+func TestWriteManPages(t *testing.T) {
+	dir := t.TempDir()
+	header := ManHeader{Source: "Tool 1.0", Manual: "Tool Manual", Date: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+
+	if err := WriteManPages(manTestTree(), dir, header); err != nil {
+		t.Fatalf("WriteManPages failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, " ") != "tool-debug-state.1 tool-debug.1 tool.1" {
+		t.Errorf("Unexpected pages: %v", names)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "tool-debug-state.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(data)
+
+	for _, want := range []string{
+		`.TH "TOOL-DEBUG-STATE" "1" "Mar 2025" "Tool 1.0" "Tool Manual"`,
+		`tool\-debug\-state \- Show state`,
+		`.B tool debug state <run\-id> [flags]`,
+		"\n\\&.dotted line stays text\n",
+		`  tool debug state \-\-after create\-plan`,
+		`\fB\-o\fP, \fB\-\-output\fP=string`,
+		"output directory (default: .)",
+		"\\fB\\-\\-delta\\fP\nshow the delta\n",
+		".SH OPTIONS INHERITED FROM PARENT COMMANDS",
+		`\fB\-c\fP, \fB\-\-config\fP=string`,
+		`.SH SEE ALSO` + "\n" + `\fBtool\-debug\fP(1)`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Page missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "secret") {
+		t.Error("Hidden flags should not be documented")
+	}
+}
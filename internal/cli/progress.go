@@ -32,13 +32,42 @@ type ProgressConfig struct {
 
 	// Quiet disables all progress output
 	Quiet bool
+
+	// BufferSize is how many events are queued for rendering before
+	// high-frequency events are coalesced (default: 256)
+	BufferSize int
+}
+
+// DefaultEventBufferSize is the default number of queued progress events
+const DefaultEventBufferSize = 256
+
+// trackerMessage is a queued event, or a flush marker when flushed is set
+type trackerMessage struct {
+	event   models.ProgressEvent
+	flushed chan struct{}
 }
 
-// ProgressTracker tracks and displays progress during generation
+// ProgressTracker tracks and displays progress during generation.
+//
+// Events are queued and rendered by a dedicated goroutine, so a slow
+// terminal never blocks the workers that report progress. Token and cost
+// events carry running totals; when the queue is full only the latest of
+// each is kept, and file-generating events are dropped. Streamed responses
+// are shown live on one line, which is likewise coalesced to the latest.
 type ProgressTracker struct {
 	config ProgressConfig
 	mu     sync.RWMutex
 
+	// Event queue
+	events     chan trackerMessage
+	renderDone chan struct{}
+	sendMu     sync.RWMutex
+	closed     bool
+	coalesceMu sync.Mutex
+	coalesced  map[models.EventType]models.ProgressEvent
+	dropped    int
+	metricsDue bool
+
 	// State
 	startTime       time.Time
 	currentPhase    string
@@ -61,6 +90,14 @@ type ProgressTracker struct {
 	phaseStartTime map[string]time.Time
 	phaseDurations map[string]time.Duration
 
+	// Projected budget from the plan estimate
+	estimate *models.PlanEstimate
+
+	// Live line of the response being streamed, and the files whose
+	// responses are complete
+	streamShown bool
+	streamDone  map[string]bool
+
 	// Colors
 	green  *color.Color
 	yellow *color.Color
@@ -82,12 +119,19 @@ func NewProgressTracker(config ProgressConfig) *ProgressTracker {
 	if config.UpdateInterval == 0 {
 		config.UpdateInterval = 500 * time.Millisecond
 	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultEventBufferSize
+	}
 
-	return &ProgressTracker{
+	pt := &ProgressTracker{
 		config:         config,
+		events:         make(chan trackerMessage, config.BufferSize),
+		renderDone:     make(chan struct{}),
+		coalesced:      make(map[models.EventType]models.ProgressEvent),
 		startTime:      time.Now(),
 		phaseStartTime: make(map[string]time.Time),
 		phaseDurations: make(map[string]time.Duration),
+		streamDone:     make(map[string]bool),
 		green:          color.New(color.FgGreen),
 		yellow:         color.New(color.FgYellow),
 		red:            color.New(color.FgRed),
@@ -99,6 +143,13 @@ func NewProgressTracker(config ProgressConfig) *ProgressTracker {
 		stopSpinner:    make(chan struct{}),
 		spinnerDone:    make(chan struct{}),
 	}
+
+	if config.Quiet {
+		close(pt.renderDone)
+	} else {
+		go pt.render()
+	}
+	return pt
 }
 
 // Start begins progress tracking
@@ -108,22 +159,173 @@ func (pt *ProgressTracker) Start(totalPhases int) {
 	}
 
 	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
 	pt.totalPhases = totalPhases
 	pt.startTime = time.Now()
-	pt.mu.Unlock()
-
 	pt.printHeader()
 }
 
-// HandleEvent processes a progress event
+// HandleEvent queues a progress event for rendering. It only blocks when the
+// queue is full and the event must not be lost; token, cost and
+// file-generating events never block.
 func (pt *ProgressTracker) HandleEvent(event models.ProgressEvent) {
 	if pt.config.Quiet {
 		return
 	}
 
+	pt.sendMu.RLock()
+	defer pt.sendMu.RUnlock()
+	if pt.closed {
+		return
+	}
+
+	streamUpdate := event.Type == models.EventFileStreaming
+	if streamUpdate {
+		done, _ := event.Data["done"].(bool)
+		streamUpdate = !done
+	}
+
+	switch {
+	case event.Type == models.EventTokensUsed, event.Type == models.EventCostUpdate, streamUpdate:
+		// Running totals and the live stream line: a newer event supersedes any coalesced one
+		pt.coalesceMu.Lock()
+		select {
+		case pt.events <- trackerMessage{event: event}:
+			delete(pt.coalesced, event.Type)
+		default:
+			pt.coalesced[event.Type] = event
+			pt.dropped++
+		}
+		pt.coalesceMu.Unlock()
+	case event.Type == models.EventFileGenerating:
+		// Only drives the spinner, so it is safe to lose
+		select {
+		case pt.events <- trackerMessage{event: event}:
+		default:
+			pt.coalesceMu.Lock()
+			pt.dropped++
+			pt.coalesceMu.Unlock()
+		}
+	default:
+		pt.events <- trackerMessage{event: event}
+	}
+}
+
+// Flush blocks until every event queued so far has been rendered
+func (pt *ProgressTracker) Flush() {
+	if pt.config.Quiet {
+		return
+	}
+
+	pt.sendMu.RLock()
+	if pt.closed {
+		pt.sendMu.RUnlock()
+		return
+	}
+	flushed := make(chan struct{})
+	pt.events <- trackerMessage{flushed: flushed}
+	pt.sendMu.RUnlock()
+
+	<-flushed
+}
+
+// DroppedEvents returns how many high-frequency events were coalesced or
+// dropped because the render queue was full
+func (pt *ProgressTracker) DroppedEvents() int {
+	pt.coalesceMu.Lock()
+	defer pt.coalesceMu.Unlock()
+	return pt.dropped
+}
+
+// Complete renders any queued events, stops the render goroutine and
+// displays the summary. Later events and calls are ignored.
+func (pt *ProgressTracker) Complete() {
+	if pt.config.Quiet {
+		return
+	}
+
+	pt.sendMu.Lock()
+	if pt.closed {
+		pt.sendMu.Unlock()
+		return
+	}
+	pt.closed = true
+	close(pt.events)
+	pt.sendMu.Unlock()
+	<-pt.renderDone
+
 	pt.mu.Lock()
 	defer pt.mu.Unlock()
 
+	pt.clearStreamLine()
+	pt.printSummary()
+}
+
+// render applies queued events until the queue is closed
+func (pt *ProgressTracker) render() {
+	defer close(pt.renderDone)
+
+	for msg := range pt.events {
+		if msg.flushed != nil {
+			pt.catchUp()
+			close(msg.flushed)
+			continue
+		}
+
+		pt.apply(msg.event)
+		if len(pt.events) == 0 {
+			pt.catchUp()
+		}
+	}
+	pt.catchUp()
+}
+
+// catchUp applies coalesced events and prints any deferred metrics once the
+// queue has drained
+func (pt *ProgressTracker) catchUp() {
+	pt.coalesceMu.Lock()
+	pending := make([]models.ProgressEvent, 0, len(pt.coalesced))
+	for _, eventType := range []models.EventType{models.EventTokensUsed, models.EventCostUpdate, models.EventFileStreaming} {
+		if event, ok := pt.coalesced[eventType]; ok {
+			pending = append(pending, event)
+			delete(pt.coalesced, eventType)
+		}
+	}
+	pt.coalesceMu.Unlock()
+
+	for _, event := range pending {
+		pt.apply(event)
+	}
+
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.printDueMetrics()
+}
+
+// apply updates the tracker state for an event and prints its output
+func (pt *ProgressTracker) apply(event models.ProgressEvent) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	switch event.Type {
+	case models.EventTokensUsed:
+		pt.handleTokensUsed(event)
+		return
+	case models.EventCostUpdate:
+		pt.handleCostUpdate(event)
+		return
+	case models.EventFileStreaming:
+		pt.handleFileStreaming(event)
+		return
+	}
+
+	// Other output replaces the live stream line
+	pt.clearStreamLine()
+
+	// Keep metrics ahead of any output that followed them
+	pt.printDueMetrics()
+
 	switch event.Type {
 	case models.EventPhaseStarted:
 		pt.handlePhaseStarted(event)
@@ -133,25 +335,21 @@ func (pt *ProgressTracker) HandleEvent(event models.ProgressEvent) {
 		pt.handleFileGenerating(event)
 	case models.EventFileCompleted:
 		pt.handleFileCompleted(event)
-	case models.EventTokensUsed:
-		pt.handleTokensUsed(event)
-	case models.EventCostUpdate:
-		pt.handleCostUpdate(event)
+	case models.EventEstimate:
+		pt.handleEstimate(event)
 	case models.EventError:
 		pt.handleError(event)
 	}
 }
 
-// Complete finalizes progress tracking and displays summary
-func (pt *ProgressTracker) Complete() {
-	if pt.config.Quiet {
+// printDueMetrics prints the metrics deferred by a burst of token events
+func (pt *ProgressTracker) printDueMetrics() {
+	if !pt.metricsDue {
 		return
 	}
-
-	pt.mu.Lock()
-	defer pt.mu.Unlock()
-
-	pt.printSummary()
+	pt.metricsDue = false
+	pt.clearStreamLine()
+	pt.printMetricsUpdate()
 }
 
 // printHeader prints the initial header
@@ -194,6 +392,9 @@ func (pt *ProgressTracker) handlePhaseCompleted(event models.ProgressEvent) {
 
 	// Print phase completion
 	pt.printPhaseComplete(phase, duration, files)
+	if pt.estimate != nil && pt.estimatesPhase(phase) {
+		pt.printBudgetUpdate(phase)
+	}
 	_, _ = fmt.Fprintln(pt.config.Writer)
 }
 
@@ -226,6 +427,29 @@ func (pt *ProgressTracker) handleFileCompleted(event models.ProgressEvent) {
 	pt.printFileComplete(path, lines, duration)
 }
 
+// handleFileStreaming shows the progress of a streamed response on the live
+// line, and prints the file once its response is complete
+func (pt *ProgressTracker) handleFileStreaming(event models.ProgressEvent) {
+	path := event.Data["path"].(string)
+	tokens, _ := event.Data["tokens"].(int64)
+	line, _ := event.Data["line"].(string)
+	done, _ := event.Data["done"].(bool)
+
+	if done {
+		pt.streamDone[path] = true
+		duration, _ := event.Data["duration"].(time.Duration)
+		pt.clearStreamLine()
+		pt.printFileStreamed(path, tokens, duration)
+		return
+	}
+
+	// A coalesced update may arrive after the response completed
+	if pt.streamDone[path] {
+		return
+	}
+	pt.printStreamLine(path, tokens, line)
+}
+
 // handleTokensUsed handles token usage events
 func (pt *ProgressTracker) handleTokensUsed(event models.ProgressEvent) {
 	if !pt.config.ShowTokens {
@@ -246,7 +470,8 @@ func (pt *ProgressTracker) handleTokensUsed(event models.ProgressEvent) {
 		pt.cacheMisses++
 	}
 
-	pt.printMetricsUpdate()
+	// Printed once the queue drains, so bursts produce a single update
+	pt.metricsDue = true
 }
 
 // handleCostUpdate handles cost update events
@@ -263,6 +488,19 @@ func (pt *ProgressTracker) handleCostUpdate(event models.ProgressEvent) {
 	}
 }
 
+// handleEstimate handles plan estimate events
+func (pt *ProgressTracker) handleEstimate(event models.ProgressEvent) {
+	estimate, ok := event.Data["estimate"].(*models.PlanEstimate)
+	if !ok || estimate == nil {
+		return
+	}
+
+	pt.estimate = estimate
+	if pt.config.ShowCost || pt.config.ShowETA {
+		pt.printEstimate()
+	}
+}
+
 // handleError handles error events
 func (pt *ProgressTracker) handleError(event models.ProgressEvent) {
 	phase := event.Data["phase"].(string)
@@ -357,6 +595,53 @@ func (pt *ProgressTracker) printFileComplete(path string, lines int, duration ti
 	_, _ = fmt.Fprintln(pt.config.Writer)
 }
 
+// streamLineWidth is the width of the live stream line
+const streamLineWidth = 100
+
+// printStreamLine overwrites the live line with a streamed file, the tokens
+// received so far and the line being written
+func (pt *ProgressTracker) printStreamLine(path string, tokens int64, line string) {
+	// Write errors are intentionally ignored for best-effort console output
+	text := fmt.Sprintf(" Streaming %s (~%s tokens)", path, formatNumber(tokens))
+	if line = strings.TrimSpace(strings.ReplaceAll(line, "\t", " ")); line != "" {
+		text += " │ " + line
+	}
+
+	// Pad to the full width so a shorter update hides the previous one
+	runes := []rune(text)
+	if len(runes) > streamLineWidth-1 {
+		runes = append(runes[:streamLineWidth-2], '…')
+	}
+	text = string(runes) + strings.Repeat(" ", streamLineWidth-1-len(runes))
+
+	_, _ = fmt.Fprintf(pt.config.Writer, "\r%s%s", pt.cyan.Sprint(pt.spinnerChars[pt.spinnerIndex]), text)
+	pt.spinnerIndex = (pt.spinnerIndex + 1) % len(pt.spinnerChars)
+	pt.streamShown = true
+}
+
+// clearStreamLine erases the live stream line, if shown
+func (pt *ProgressTracker) clearStreamLine() {
+	if !pt.streamShown {
+		return
+	}
+	_, _ = fmt.Fprintf(pt.config.Writer, "\r%s\r", strings.Repeat(" ", streamLineWidth))
+	pt.streamShown = false
+}
+
+// printFileStreamed prints a file whose response finished streaming
+func (pt *ProgressTracker) printFileStreamed(path string, tokens int64, duration time.Duration) {
+	// Write errors are intentionally ignored for best-effort console output
+	_, _ = fmt.Fprintf(pt.config.Writer, "  ")
+	_, _ = pt.green.Fprintf(pt.config.Writer, "✓")
+	_, _ = fmt.Fprintf(pt.config.Writer, " %s", path)
+	_, _ = pt.gray.Fprintf(pt.config.Writer, " (~%s tokens streamed", formatNumber(tokens))
+	if duration > 0 {
+		_, _ = pt.gray.Fprintf(pt.config.Writer, ", %s", formatDuration(duration))
+	}
+	_, _ = pt.gray.Fprintf(pt.config.Writer, ")")
+	_, _ = fmt.Fprintln(pt.config.Writer)
+}
+
 // printMetricsUpdate prints current metrics
 func (pt *ProgressTracker) printMetricsUpdate() {
 	// Write errors are intentionally ignored for best-effort console output
@@ -401,6 +686,101 @@ func (pt *ProgressTracker) printMetricsUpdate() {
 	_, _ = fmt.Fprintln(pt.config.Writer)
 }
 
+// printEstimate prints the projected budget per phase and priority class
+func (pt *ProgressTracker) printEstimate() {
+	// Write errors are intentionally ignored for best-effort console output
+	total := pt.estimate.Total()
+
+	_, _ = pt.bold.Fprint(pt.config.Writer, "Projected Budget")
+	if pt.estimate.Model != "" {
+		_, _ = pt.gray.Fprintf(pt.config.Writer, " (%s)", pt.estimate.Model)
+	}
+	_, _ = fmt.Fprintf(pt.config.Writer, ": %s\n", pt.budgetString(total))
+
+	_, _ = fmt.Fprintln(pt.config.Writer, "  By phase:")
+	for _, group := range pt.estimate.ByPhase() {
+		_, _ = fmt.Fprintf(pt.config.Writer, "    %-18s %s (%d calls)\n", group.Name, pt.budgetString(group.Estimate), group.Estimate.Calls)
+	}
+
+	pt.printPriorityBreakdown(pt.estimate.ByPriority(), total)
+	_, _ = pt.gray.Fprintln(pt.config.Writer, "  Press Ctrl+C to abort before the remaining phases run")
+	_, _ = fmt.Fprintln(pt.config.Writer)
+}
+
+// printBudgetUpdate compares a completed phase with its projection and
+// re-projects the remaining phases at the observed pace
+func (pt *ProgressTracker) printBudgetUpdate(phase string) {
+	// Write errors are intentionally ignored for best-effort console output
+	completed := make(map[string]bool, len(pt.phaseDurations))
+	var projectedDone, actualDone, projected time.Duration
+	for _, group := range pt.estimate.ByPhase() {
+		if actual, ok := pt.phaseDurations[group.Name]; ok {
+			completed[group.Name] = true
+			projectedDone += group.Estimate.Duration
+			actualDone += actual
+		}
+		if group.Name == phase {
+			projected = group.Estimate.Duration
+		}
+	}
+
+	if pt.config.ShowETA {
+		_, _ = pt.gray.Fprintf(pt.config.Writer, "    took %s, projected %s\n",
+			formatDuration(pt.phaseDurations[phase]), formatDuration(projected))
+	}
+
+	phases, remaining := pt.estimate.Remaining(completed)
+	if len(phases) == 0 {
+		return
+	}
+
+	// Scale the remaining time by how far completed phases deviated from their projection
+	if projectedDone > 0 && actualDone > 0 {
+		remaining.Duration = time.Duration(float64(remaining.Duration) * float64(actualDone) / float64(projectedDone))
+	}
+
+	_, _ = fmt.Fprintf(pt.config.Writer, "  Remaining (%s): %s\n", strings.Join(phases, ", "), pt.budgetString(remaining))
+	pt.printPriorityBreakdown(pt.estimate.ByPriority(phases...), remaining)
+}
+
+// printPriorityBreakdown prints each priority class's share of a budget
+func (pt *ProgressTracker) printPriorityBreakdown(groups []models.EstimateGroup, total models.CostEstimate) {
+	if len(groups) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintln(pt.config.Writer, "  By priority:")
+	for _, group := range groups {
+		share := 0.0
+		if total.CostUSD > 0 {
+			share = group.Estimate.CostUSD / total.CostUSD * 100
+		}
+		_, _ = fmt.Fprintf(pt.config.Writer, "    %-18s %s (%.0f%%)\n", group.Name, pt.budgetString(group.Estimate), share)
+	}
+}
+
+// budgetString formats the cost and/or time of an estimate depending on what is shown
+func (pt *ProgressTracker) budgetString(e models.CostEstimate) string {
+	var parts []string
+	if pt.config.ShowCost {
+		parts = append(parts, fmt.Sprintf("~$%.4f", e.CostUSD))
+	}
+	if pt.config.ShowETA {
+		parts = append(parts, "~"+formatDuration(e.Duration))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// estimatesPhase reports whether the plan estimate covers the phase
+func (pt *ProgressTracker) estimatesPhase(phase string) bool {
+	for _, p := range pt.estimate.Phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
 // printError prints an error
 func (pt *ProgressTracker) printError(phase, message, file string) {
 	// Write errors are intentionally ignored for best-effort console output
@@ -3,6 +3,7 @@ package cli
 import (
 	"bytes"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -88,6 +89,41 @@ func TestProgressTracker_FileTracking(t *testing.T) {
 	}
 }
 
+func TestProgressTracker_FileStreaming(t *testing.T) {
+	var buf bytes.Buffer
+
+	config := ProgressConfig{
+		Writer:         &buf,
+		UpdateInterval: 100 * time.Millisecond,
+	}
+
+	tracker := NewProgressTracker(config)
+	tracker.Start(1)
+
+	tracker.HandleEvent(models.NewPhaseStartedEvent("generate", "Generating files"))
+	tracker.HandleEvent(models.NewFileStreamingEvent("internal/app/app.go", 42, "func Run() error {", false, 0))
+	time.Sleep(50 * time.Millisecond)
+	tracker.HandleEvent(models.NewFileStreamingEvent("internal/app/app.go", 120, "}", true, 2*time.Second))
+
+	// A late update for a finished file is not shown again
+	tracker.HandleEvent(models.NewFileStreamingEvent("internal/app/app.go", 120, "late", false, 0))
+	tracker.Complete()
+
+	output := buf.String()
+
+	if !strings.Contains(output, "Streaming internal/app/app.go (~42 tokens) │ func Run() error {") {
+		t.Error("Output should show the file being streamed and its current line")
+	}
+
+	if !strings.Contains(output, "~120 tokens streamed") {
+		t.Error("Output should show the tokens streamed for the finished file")
+	}
+
+	if strings.Contains(output, "late") {
+		t.Error("Output should not show updates after the file finished streaming")
+	}
+}
+
 func TestProgressTracker_TokenTracking(t *testing.T) {
 	var buf bytes.Buffer
 
@@ -255,3 +291,157 @@ func TestFormatNumber(t *testing.T) {
 		}
 	}
 }
+
+func TestProgressTracker_Estimate(t *testing.T) {
+	var buf bytes.Buffer
+
+	tracker := NewProgressTracker(ProgressConfig{
+		Writer:   &buf,
+		ShowCost: true,
+		ShowETA:  true,
+	})
+	tracker.Start(3)
+
+	estimate := &models.PlanEstimate{
+		Model:  "claude-sonnet-4-5",
+		Phases: []string{"generate_packages", "generate_tests"},
+		Items: []models.EstimateItem{
+			{Phase: "generate_packages", Priority: "high", Estimate: models.CostEstimate{Calls: 2, CostUSD: 0.10, Duration: 20 * time.Second}},
+			{Phase: "generate_packages", Priority: "low", Estimate: models.CostEstimate{Calls: 6, CostUSD: 0.30, Duration: 60 * time.Second}},
+			{Phase: "generate_tests", Priority: "high", Estimate: models.CostEstimate{Calls: 2, CostUSD: 0.05, Duration: 10 * time.Second}},
+			{Phase: "generate_tests", Priority: "low", Estimate: models.CostEstimate{Calls: 6, CostUSD: 0.15, Duration: 30 * time.Second}},
+		},
+	}
+	tracker.HandleEvent(models.NewEstimateEvent(estimate))
+	tracker.Flush()
+
+	output := buf.String()
+	for _, want := range []string{"Projected Budget", "~$0.6000", "generate_packages", "low                ~$0.4500", "(75%)", "(25%)", "Ctrl+C"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("estimate output missing %q:\n%s", want, output)
+		}
+	}
+
+	// Packages took twice as long as projected, so the remaining time doubles
+	buf.Reset()
+	tracker.HandleEvent(models.NewPhaseStartedEvent("generate_packages", ""))
+	tracker.HandleEvent(models.NewPhaseCompletedEvent("generate_packages", 160*time.Second, 8))
+	tracker.Flush()
+
+	output = buf.String()
+	for _, want := range []string{"took 2m40s, projected 1m20s", "Remaining (generate_tests): ~$0.2000, ~1m20s", "high               ~$0.0500", "(25%)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("budget update missing %q:\n%s", want, output)
+		}
+	}
+
+	// Phases outside the estimate do not print budget updates
+	buf.Reset()
+	tracker.HandleEvent(models.NewPhaseStartedEvent("generate_config", ""))
+	tracker.HandleEvent(models.NewPhaseCompletedEvent("generate_config", time.Second, 1))
+	tracker.Flush()
+	if strings.Contains(buf.String(), "Remaining") {
+		t.Errorf("unexpected budget update for unestimated phase:\n%s", buf.String())
+	}
+}
+
+// blockingWriter holds every write until it is released, like a stalled terminal
+type blockingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestProgressTracker_SlowWriterDoesNotBlockEvents(t *testing.T) {
+	writer := &blockingWriter{release: make(chan struct{})}
+	tracker := NewProgressTracker(ProgressConfig{
+		Writer:     writer,
+		ShowTokens: true,
+		ShowCost:   true,
+		BufferSize: 4,
+	})
+
+	// The writer is stalled, so the render goroutine is stuck on the first event
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		tracker.HandleEvent(models.NewPhaseStartedEvent("generate", "Generating code"))
+		for i := int64(1); i <= 1000; i++ {
+			tracker.HandleEvent(models.NewTokensUsedEvent("anthropic", 10, 5, 0, i*10, i*5, 0, 0))
+			tracker.HandleEvent(models.NewCostUpdateEvent("anthropic", 0.001, float64(i)*0.001, 0))
+			tracker.HandleEvent(models.NewFileGeneratingEvent("file.go", "generate"))
+		}
+	}()
+
+	select {
+	case <-handled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("HandleEvent blocked on a stalled writer")
+	}
+
+	if tracker.DroppedEvents() == 0 {
+		t.Error("expected high-frequency events to be coalesced while the writer was stalled")
+	}
+
+	close(writer.release)
+	tracker.Complete()
+
+	// Coalescing keeps the latest running totals
+	output := writer.String()
+	for _, want := range []string{"Input: 10,000 tokens", "Output: 5,000 tokens", "Total: $1.0000"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("summary missing %q:\n%s", want, output)
+		}
+	}
+	if got := strings.Count(output, "Progress Metrics:"); got >= 1000 {
+		t.Errorf("token bursts should be coalesced, got %d metric updates", got)
+	}
+}
+
+func TestProgressTracker_ConcurrentEvents(t *testing.T) {
+	var buf bytes.Buffer
+	tracker := NewProgressTracker(ProgressConfig{Writer: &buf})
+	tracker.Start(1)
+	tracker.HandleEvent(models.NewPhaseStartedEvent("generate", "Generating code"))
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				tracker.HandleEvent(models.NewFileCompletedEvent("file.go", "generate", 10, 0))
+			}
+		}()
+	}
+	wg.Wait()
+	tracker.HandleEvent(models.NewPhaseCompletedEvent("generate", time.Second, 200))
+	tracker.Complete()
+
+	// File completions are never dropped
+	if !strings.Contains(buf.String(), "Files Generated: 200") {
+		t.Errorf("expected every file completion to be counted:\n%s", buf.String())
+	}
+
+	// Events after Complete are ignored rather than panicking
+	before := buf.Len()
+	tracker.HandleEvent(models.NewPhaseStartedEvent("late", ""))
+	tracker.Flush()
+	tracker.Complete()
+	if buf.Len() != before {
+		t.Errorf("expected no output after Complete, got %q", buf.String()[before:])
+	}
+}
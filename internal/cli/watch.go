@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultDebounce is how long changes to a watched file must settle before
+// they are reported
+const DefaultDebounce = 500 * time.Millisecond
+
+// WatchFile reports changes to the file at path on the returned channel,
+// once changes have settled for debounce. Editors save in several writes or
+// replace the file with a renamed copy, so the file's directory is watched
+// and each burst of writes is reported once. A change made before the last
+// one is received is folded into it. The channel is closed when ctx ends.
+func WatchFile(ctx context.Context, path string, debounce time.Duration) (<-chan struct{}, error) {
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(target)); err != nil {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", filepath.Dir(target), err)
+	}
+
+	changes := make(chan struct{}, 1)
+	go func() {
+		defer close(changes)
+		defer func() { _ = watcher.Close() }()
+
+		timer := time.NewTimer(debounce)
+		timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != target || !event.Has(fsnotify.Write|fsnotify.Create) {
+					continue
+				}
+				timer.Reset(debounce)
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warn().Err(err).Str("path", path).Msg("File watcher error")
+
+			case <-timer.C:
+				select {
+				case changes <- struct{}{}:
+				default:
+					// A change is already waiting to be received
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
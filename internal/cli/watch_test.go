@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "spec.yaml")
+	if err := os.WriteFile(path, []byte("name: app\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := WatchFile(ctx, path, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WatchFile: %v", err)
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Other files in the directory are not reported
+	write(filepath.Join(dir, "notes.txt"), "x")
+	select {
+	case <-changes:
+		t.Fatal("a change to another file was reported")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// A burst of writes is reported once
+	for i := 0; i < 3; i++ {
+		write(path, "name: app\nversion: 2\n")
+	}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change was not reported")
+	}
+	select {
+	case <-changes:
+		t.Fatal("the burst was reported more than once")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Replacing the file, as editors do on save, is a change
+	tmp := filepath.Join(dir, ".spec.yaml.swp")
+	write(tmp, "name: app\nversion: 3\n")
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the replaced file was not reported")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-changes:
+		if ok {
+			t.Error("the channel is not closed when the context ends")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the channel was not closed")
+	}
+}
@@ -4,47 +4,461 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
 	"time"
 
+	"github.com/dshills/gocreator/internal/models"
 	"github.com/rs/zerolog"
 	"github.com/spf13/viper"
 )
 
 // Config represents the application configuration
 type Config struct {
-	LLM        LLMConfig        `mapstructure:"llm"`
-	Workflow   WorkflowConfig   `mapstructure:"workflow"`
-	Validation ValidationConfig `mapstructure:"validation"`
-	Logging    LoggingConfig    `mapstructure:"logging"`
+	LLM         LLMConfig         `mapstructure:"llm"`
+	Models      ModelsConfig      `mapstructure:"models"`
+	Workflow    WorkflowConfig    `mapstructure:"workflow"`
+	Validation  ValidationConfig  `mapstructure:"validation"`
+	Logging     LoggingConfig     `mapstructure:"logging"`
+	Project     ProjectConfig     `mapstructure:"project"`
+	Plan        PlanConfig        `mapstructure:"plan"`
+	Timeouts    TimeoutsConfig    `mapstructure:"timeouts"`
+	Prompts     PromptsConfig     `mapstructure:"prompts"`
+	Limits      LimitsConfig      `mapstructure:"limits"`
+	Knowledge   KnowledgeConfig   `mapstructure:"knowledge"`
+	Events      EventsConfig      `mapstructure:"events"`
+	Style       StyleConfig       `mapstructure:"style"`
+	PostProcess PostProcessConfig `mapstructure:"postprocess"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+
+	// Source is the config file that was read, empty when only defaults apply
+	Source string `mapstructure:"-"`
 }
 
 // LLMConfig configures the LLM provider
 type LLMConfig struct {
-	Provider    string        `mapstructure:"provider"`
-	Model       string        `mapstructure:"model"`
-	Temperature float64       `mapstructure:"temperature"`
-	APIKey      string        `mapstructure:"api_key"`
-	Timeout     time.Duration `mapstructure:"timeout"`
-	MaxTokens   int           `mapstructure:"max_tokens"`
+	Provider    string         `mapstructure:"provider"`
+	Model       string         `mapstructure:"model"`
+	Temperature float64        `mapstructure:"temperature"`
+	APIKey      string         `mapstructure:"api_key"`
+	BaseURL     string         `mapstructure:"base_url"`    // Endpoint of the openai-compatible or azure-openai provider
+	Deployment  string         `mapstructure:"deployment"`  // Azure OpenAI deployment of the model (default: model)
+	APIVersion  string         `mapstructure:"api_version"` // Azure OpenAI API version
+	Region      string         `mapstructure:"region"`      // AWS region of the bedrock provider (default: AWS_REGION)
+	Timeout     time.Duration  `mapstructure:"timeout"`
+	MaxTokens   int            `mapstructure:"max_tokens"`
+	Network     NetworkConfig  `mapstructure:"network"`
+	Retry       RetryConfig    `mapstructure:"retry"`
+	Cache       CacheConfig    `mapstructure:"cache"`
+	Ensemble    EnsembleConfig `mapstructure:"ensemble"`
+
+	// Concurrency caps requests in flight per provider or model, shared by
+	// every client for it (default: workflow.max_parallel)
+	Concurrency []ConcurrencyClass `mapstructure:"concurrency"`
+
+	// Batch generates source files through the provider's batch API
+	// (Anthropic, OpenAI), polled every BatchPollInterval
+	Batch             bool          `mapstructure:"batch"`
+	BatchPollInterval time.Duration `mapstructure:"batch_poll_interval"`
+
+	// Stream writes source file responses to disk as they arrive, so large
+	// files are not buffered and interrupted responses resume
+	Stream bool `mapstructure:"stream"`
+
+	// FileCostCeiling caps the projected worst-case cost in USD of generating
+	// one source file (0: no cap). Files over it are generated with the
+	// Downgrade model when that fits, and written as stubs otherwise.
+	FileCostCeiling float64         `mapstructure:"file_cost_ceiling"`
+	Downgrade       DowngradeConfig `mapstructure:"downgrade"`
+}
+
+// RetryConfig controls how rate-limited, overloaded and failed LLM requests
+// are retried: with exponential backoff and jitter from InitialDelay, and at
+// least as long as the provider's rate-limit headers ask, up to MaxDelay
+type RetryConfig struct {
+	MaxRetries   int           `mapstructure:"max_retries"`
+	InitialDelay time.Duration `mapstructure:"initial_delay"`
+	MaxDelay     time.Duration `mapstructure:"max_delay"` // Longer waits fail the request
+}
+
+// CacheConfig configures the disk cache of LLM responses: a rerun sending
+// the same prompt to the same model gets the stored response without a
+// request
+type CacheConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Path      string        `mapstructure:"path"`        // Default: gocreator/responses in the user cache directory
+	TTL       time.Duration `mapstructure:"ttl"`         // 0: entries never expire
+	MaxSizeMB int64         `mapstructure:"max_size_mb"` // 0: no limit
+}
+
+// ConcurrencyClass caps the requests in flight to a provider, or to one of
+// its models when Model is set
+type ConcurrencyClass struct {
+	Provider    string `mapstructure:"provider"`
+	Model       string `mapstructure:"model"` // Takes precedence over the provider-wide class
+	MaxParallel int    `mapstructure:"max_parallel"`
+}
+
+// EnsembleConfig selects the second model that competes with the primary one
+// on files generated with --ensemble
+type EnsembleConfig struct {
+	Provider string `mapstructure:"provider"` // Defaults to llm.provider
+	Model    string `mapstructure:"model"`
+	APIKey   string `mapstructure:"api_key"` // Defaults to the provider's environment variable
+}
+
+// Enabled reports whether a second model is configured
+func (e EnsembleConfig) Enabled() bool {
+	return e.Model != ""
+}
+
+// DowngradeConfig selects the cheaper model for files whose projected cost
+// exceeds llm.file_cost_ceiling
+type DowngradeConfig struct {
+	Provider string `mapstructure:"provider"` // Defaults to llm.provider
+	Model    string `mapstructure:"model"`
+	APIKey   string `mapstructure:"api_key"` // Defaults to the provider's environment variable
+}
+
+// Enabled reports whether a cheaper model is configured
+func (d DowngradeConfig) Enabled() bool {
+	return d.Model != ""
+}
+
+// ModelsConfig selects the model of each generation phase; a phase without
+// a model uses llm.provider and llm.model
+type ModelsConfig struct {
+	Planner PhaseModelConfig `mapstructure:"planner"` // Plans the file tree
+	Coder   PhaseModelConfig `mapstructure:"coder"`   // Generates source files and package docs
+	Tester  PhaseModelConfig `mapstructure:"tester"`  // Generates tests
+}
+
+// PhaseModelConfig selects the model of one generation phase
+type PhaseModelConfig struct {
+	Provider string `mapstructure:"provider"` // Defaults to llm.provider
+	Model    string `mapstructure:"model"`
+	APIKey   string `mapstructure:"api_key"` // Defaults to the provider's environment variable
+}
+
+// Enabled reports whether the phase has a model of its own
+func (p PhaseModelConfig) Enabled() bool {
+	return p.Model != ""
+}
+
+// NetworkConfig configures outbound connections to LLM providers
+type NetworkConfig struct {
+	ProxyURL           string `mapstructure:"proxy_url"`            // HTTP(S) proxy (default: HTTPS_PROXY/HTTP_PROXY)
+	CABundle           string `mapstructure:"ca_bundle"`            // PEM file with extra trusted root CAs
+	TLSMinVersion      string `mapstructure:"tls_min_version"`      // 1.2 (default) or 1.3
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"` // Disable certificate verification (debugging only)
 }
 
 // WorkflowConfig configures workflow execution
 type WorkflowConfig struct {
-	RootDir            string   `mapstructure:"root_dir"`
-	AllowCommands      []string `mapstructure:"allow_commands"`
-	MaxParallel        int      `mapstructure:"max_parallel"`
-	CheckpointInterval int      `mapstructure:"checkpoint_interval"`
+	RootDir            string         `mapstructure:"root_dir"`
+	AllowCommands      []string       `mapstructure:"allow_commands"`
+	MaxParallel        int            `mapstructure:"max_parallel"`
+	Speculative        bool           `mapstructure:"speculative"` // Experimental: start files of the next dependency level early
+	CheckpointInterval int            `mapstructure:"checkpoint_interval"`
+	Approval           ApprovalConfig `mapstructure:"approval"`
 }
 
+// ApprovalConfig configures the checkpoints at which the full pipeline waits
+// for the user to approve the artifact produced so far
+type ApprovalConfig struct {
+	Checkpoints []string      `mapstructure:"checkpoints"` // clarify, plan; empty disables approval
+	Timeout     time.Duration `mapstructure:"timeout"`     // Wait before the default action; 0 waits indefinitely
+	Default     string        `mapstructure:"default"`     // continue (default) or abort
+}
+
+// ApprovalCheckpoints lists the checkpoints of the full pipeline in order
+var ApprovalCheckpoints = []string{"clarify", "plan"}
+
 // ValidationConfig configures validation behavior
 type ValidationConfig struct {
-	EnableLinting    bool          `mapstructure:"enable_linting"`
-	LinterConfig     string        `mapstructure:"linter_config"`
-	EnableTests      bool          `mapstructure:"enable_tests"`
-	TestTimeout      time.Duration `mapstructure:"test_timeout"`
-	RequiredCoverage float64       `mapstructure:"required_coverage"`
+	EnableLinting    bool             `mapstructure:"enable_linting"`
+	LinterConfig     string           `mapstructure:"linter_config"`
+	EnableTests      bool             `mapstructure:"enable_tests"`
+	TestTimeout      time.Duration    `mapstructure:"test_timeout"`
+	RequiredCoverage float64          `mapstructure:"required_coverage"`
+	MaxParallel      int              `mapstructure:"max_parallel"` // Packages built/tested concurrently
+	Smoke            SmokeConfig      `mapstructure:"smoke"`
+	Fuzz             FuzzConfig       `mapstructure:"fuzz"`
+	BuildFiles       BuildFilesConfig `mapstructure:"build_files"`
+	Severity         SeverityConfig   `mapstructure:"severity"`
+
+	// MaxRepairAttempts bounds the rebuilds of `full` after regenerating
+	// files that fail to compile with their errors; 0 disables repairs
+	MaxRepairAttempts int `mapstructure:"max_repair_attempts"`
+
+	// RepairTests has `full` regenerate failing tests, or the code under
+	// test, with the failure output, rerunning the tests up to
+	// MaxTestRepairAttempts times
+	RepairTests           bool `mapstructure:"repair_tests"`
+	MaxTestRepairAttempts int  `mapstructure:"max_test_repair_attempts"`
+
+	// RepairLint has `full` regenerate the files golangci-lint reports
+	// issues in, linting again up to MaxLintRepairAttempts times
+	RepairLint            bool `mapstructure:"repair_lint"`
+	MaxLintRepairAttempts int  `mapstructure:"max_lint_repair_attempts"`
+}
+
+// SeverityConfig maps failed validation checks to a severity (error,
+// warning, info or ignore); the highest severity of a run decides its exit
+// code
+type SeverityConfig struct {
+	Checks    map[string]string `mapstructure:"checks"`     // Check -> severity (default: error; warning for vet and coverage)
+	LintRules map[string]string `mapstructure:"lint_rules"` // golangci-lint linter -> severity (default: checks.lint)
+	FailOn    string            `mapstructure:"fail_on"`    // Lowest severity that fails the run: error (default), warning or info
+}
+
+// SeverityChecks lists the validation checks a severity can be set for
+var SeverityChecks = []string{"build", "vet", "lint", "test", "coverage", "requirements", "enums", "middleware", "contracts", "smoke", "fuzz", "build_files"}
+
+// Severities lists the severities of failed validation checks, highest first
+var Severities = []string{"error", "warning", "info", "ignore"}
+
+// BuildFilesConfig configures the optional checks of the generated
+// Dockerfile (docker build, or hadolint without docker) and Makefile
+// (make -n per target) after the other validation checks
+type BuildFilesConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	DockerTimeout time.Duration `mapstructure:"docker_timeout"` // Bounds docker build
+}
+
+// FuzzConfig configures the optional go test -fuzz run of the generated fuzz
+// targets after the other validation checks
+type FuzzConfig struct {
+	Enabled bool          `mapstructure:"enabled"` // Always on for specs with testing_strategy.fuzz_tests
+	Time    time.Duration `mapstructure:"time"`    // Per fuzz target
+}
+
+// SmokeConfig configures the optional smoke run of the generated executable
+// after the other validation checks
+type SmokeConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Package        string        `mapstructure:"package"`         // Main package to build (default: detected, preferring ./cmd/...)
+	Args           []string      `mapstructure:"args"`            // Arguments (default: --help, or none with a health check)
+	Env            []string      `mapstructure:"env"`             // Extra KEY=VALUE environment entries
+	HealthURL      string        `mapstructure:"health_url"`      // For servers: poll until 2xx, e.g. http://localhost:8080/healthz
+	StartupTimeout time.Duration `mapstructure:"startup_timeout"` // Time to exit, or to pass the health check
+}
+
+// ProjectConfig configures the identity and location of generated projects
+type ProjectConfig struct {
+	ModulePath string `mapstructure:"module_path"` // Go module path (default: inferred from the specification)
+	BinaryName string `mapstructure:"binary_name"` // Main binary name (default: last module path element)
+	OutputDir  string `mapstructure:"output_dir"`  // Output directory template, e.g. ./generated/{{.ProjectName}}-{{.Date}}
+
+	// ProtectedPaths are globs in the output directory that generation never
+	// writes, e.g. docs/adr/** or scripts/**
+	ProtectedPaths []string `mapstructure:"protected_paths"`
+
+	// TemplatesDir holds templates that replace the built-in boilerplate
+	// templates, named like them (go.mod.tmpl, Makefile.tmpl, ...)
+	TemplatesDir string `mapstructure:"templates_dir"`
+
+	// PackageDocs generates a doc.go for each generated package that has
+	// no package comment
+	PackageDocs bool `mapstructure:"package_docs"`
+
+	// Scaffolds renders entity structs, repository interfaces, their
+	// PostgreSQL implementations and table schemas from the data model
+	// instead of requesting them from the LLM
+	Scaffolds bool `mapstructure:"scaffolds"`
+}
+
+// PlanConfig bounds the size of generation plans. A zero limit is unlimited.
+type PlanConfig struct {
+	MaxFiles           int    `mapstructure:"max_files"`             // Files in the plan's file tree
+	MaxDirectories     int    `mapstructure:"max_directories"`       // Distinct directories
+	MaxDepth           int    `mapstructure:"max_depth"`             // Directory nesting below the project root
+	MaxFilesPerPackage int    `mapstructure:"max_files_per_package"` // Non-test .go files per package
+	MaxReplans         int    `mapstructure:"max_replans"`           // Simplification requests before failing
+	FileStrategy       string `mapstructure:"file_strategy"`         // auto, per_entity, per_package or bounded
+	MaxFileLines       int    `mapstructure:"max_file_lines"`        // Line budget per source file for bounded
+}
+
+// TimeoutsConfig bounds the wall-clock time of each pipeline phase. A zero
+// timeout disables the limit for that phase.
+type TimeoutsConfig struct {
+	Clarify  time.Duration `mapstructure:"clarify"`  // Specification analysis and FCS construction
+	Plan     time.Duration `mapstructure:"plan"`     // Architecture planning, including re-planning
+	Code     time.Duration `mapstructure:"code"`     // Source file generation
+	Tests    time.Duration `mapstructure:"tests"`    // Test file generation
+	Docs     time.Duration `mapstructure:"docs"`     // Package documentation
+	Config   time.Duration `mapstructure:"config"`   // Build and configuration files
+	Validate time.Duration `mapstructure:"validate"` // Build, lint and test validation together
+}
+
+// LimitsConfig bounds the resources of a whole run, so unattended runs cannot
+// take unbounded time or memory. A zero limit is disabled.
+type LimitsConfig struct {
+	MaxDuration  time.Duration `mapstructure:"max_duration"`   // Wall-clock time of the whole command, across phases
+	MaxOpenFiles int           `mapstructure:"max_open_files"` // Files open at once for generated output
+	SoftMemoryMB int           `mapstructure:"soft_memory_mb"` // Heap size above which LLM requests run one at a time
+
+	// MaxCost (USD) and MaxTokens cap what generation spends across all LLM
+	// calls; OnBudget is what happens when one is crossed: abort (default),
+	// or confirm to ask whether to continue with the cap raised by its amount
+	MaxCost   float64 `mapstructure:"max_cost"`
+	MaxTokens int64   `mapstructure:"max_tokens"`
+	OnBudget  string  `mapstructure:"on_budget"`
+}
+
+// BudgetActions are the valid values of limits.on_budget
+var BudgetActions = []string{"abort", "confirm"}
+
+// KnowledgeConfig configures the local knowledge base of repairs: how a
+// repaired file fixed the problem its check reported, consulted when the same
+// problem recurs in any project
+type KnowledgeConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"` // Default: gocreator/fixes.json in the user cache directory
+}
+
+// StyleConfig is the house style of generated code: it is rendered into
+// every coder and tester prompt and checked after generation. Empty fields
+// set no rule.
+type StyleConfig struct {
+	ErrorWrapping string   `mapstructure:"error_wrapping"` // fmt_errorf or pkg_errors
+	Logging       string   `mapstructure:"logging"`        // slog, zerolog, zap, logrus or log
+	Context       string   `mapstructure:"context"`        // first_param
+	Initialisms   bool     `mapstructure:"initialisms"`    // Initialisms keep one case, e.g. userID
+	TestFramework string   `mapstructure:"test_framework"` // Used when the spec selects none
+	Rules         []string `mapstructure:"rules"`          // Free-form rules, prompted but not checked
+}
+
+// Guide converts the style for the generators and validators
+func (s StyleConfig) Guide() models.StyleGuide {
+	return models.StyleGuide{
+		ErrorWrapping: s.ErrorWrapping,
+		Logging:       s.Logging,
+		Context:       s.Context,
+		Initialisms:   s.Initialisms,
+		TestFramework: s.TestFramework,
+		Rules:         s.Rules,
+	}
+}
+
+// PostProcessConfig selects the custom AST transforms run over every
+// generated Go file, in order, after the built-in post-processing. Programs
+// embedding the generator register them with postprocess.Register.
+type PostProcessConfig struct {
+	Transforms []string `mapstructure:"transforms"`
+}
+
+// DatabaseConfig selects the database generated SQL is written for and the
+// migrations planned from the data model
+type DatabaseConfig struct {
+	Dialect string `mapstructure:"dialect"` // postgres (default), mysql or sqlite
+
+	// Migrations plans golang-migrate migrations of the data model's tables,
+	// with foreign keys from its relationships, in MigrationsDir
+	Migrations    bool   `mapstructure:"migrations"`
+	MigrationsDir string `mapstructure:"migrations_dir"` // Relative to the output directory
+}
+
+// Dialects are the valid values of database.dialect
+var Dialects = []string{"postgres", "mysql", "sqlite"}
+
+// EventsConfig configures the sinks that receive the progress events of each
+// generation run besides the console. Types select the events a sink
+// receives; empty selects every event but the file_streaming chunks.
+type EventsConfig struct {
+	File    FileSinkConfig    `mapstructure:"file"`
+	Webhook WebhookSinkConfig `mapstructure:"webhook"`
+	OTLP    OTLPSinkConfig    `mapstructure:"otlp"`
+}
+
+// FileSinkConfig configures the JSON Lines event log
+type FileSinkConfig struct {
+	Path  string   `mapstructure:"path"` // Relative to the output directory; empty disables the log
+	Types []string `mapstructure:"types"`
+}
+
+// WebhookSinkConfig configures the webhook each event is posted to
+type WebhookSinkConfig struct {
+	URL     string            `mapstructure:"url"` // Empty disables the webhook
+	Types   []string          `mapstructure:"types"`
+	Headers map[string]string `mapstructure:"headers"` // Values expand ${VAR} from the environment
+	Timeout time.Duration     `mapstructure:"timeout"`
+}
+
+// OTLPSinkConfig configures the export of each run as an OpenTelemetry trace
+// over OTLP/HTTP
+type OTLPSinkConfig struct {
+	Endpoint    string            `mapstructure:"endpoint"` // Collector base URL, e.g. http://localhost:4318; empty disables export
+	ServiceName string            `mapstructure:"service_name"`
+	Headers     map[string]string `mapstructure:"headers"` // Values expand ${VAR} from the environment
+	Timeout     time.Duration     `mapstructure:"timeout"`
+}
+
+// PromptsConfig holds organization-wide prompt policy
+type PromptsConfig struct {
+	Preamble     string `mapstructure:"preamble"`      // Standards prepended to every planner, coder and tester prompt
+	PreambleFile string `mapstructure:"preamble_file"` // File to read the preamble from instead
+	TemplatesDir string `mapstructure:"templates_dir"` // Directory of coder prompt section overrides (<type>.tmpl)
+}
+
+// LoadPreamble returns the configured preamble text, reading preamble_file if set
+func (p PromptsConfig) LoadPreamble() (string, error) {
+	if p.PreambleFile == "" {
+		return strings.TrimSpace(p.Preamble), nil
+	}
+
+	data, err := os.ReadFile(p.PreambleFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompts.preamble_file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// OutputPathData is the data available to output directory templates
+type OutputPathData struct {
+	ProjectName string
+	ModulePath  string
+	Date        string // 2006-01-02
+	Timestamp   string // 20060102-150405
+}
+
+// NewOutputPathData builds output path template data for a project at time t
+func NewOutputPathData(projectName, modulePath string, t time.Time) OutputPathData {
+	return OutputPathData{
+		ProjectName: projectName,
+		ModulePath:  modulePath,
+		Date:        t.Format("2006-01-02"),
+		Timestamp:   t.Format("20060102-150405"),
+	}
+}
+
+// ResolveOutputDir expands an output directory template. Paths without
+// template actions are returned unchanged.
+func ResolveOutputDir(pattern string, data OutputPathData) (string, error) {
+	if !strings.Contains(pattern, "{{") {
+		return pattern, nil
+	}
+
+	tmpl, err := template.New("output_dir").Option("missingkey=error").Parse(pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse output directory template: %w", err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to resolve output directory template: %w", err)
+	}
+
+	resolved := strings.TrimSpace(sb.String())
+	if resolved == "" {
+		return "", fmt.Errorf("output directory template %q resolved to an empty path", pattern)
+	}
+
+	return filepath.Clean(resolved), nil
 }
 
 // LoggingConfig configures logging behavior
@@ -57,6 +471,22 @@ type LoggingConfig struct {
 
 // Load loads configuration from file and environment variables
 func Load(configPath string) (*Config, error) {
+	cfg, err := LoadUnvalidated(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate configuration
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// LoadUnvalidated loads configuration without validating it, so diagnostics
+// can report every problem instead of failing on the first one
+func LoadUnvalidated(configPath string) (*Config, error) {
 	v := viper.New()
 
 	// Set defaults
@@ -98,11 +528,7 @@ func Load(configPath string) (*Config, error) {
 	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
-
-	// Validate configuration
-	if err := cfg.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
-	}
+	cfg.Source = v.ConfigFileUsed()
 
 	return &cfg, nil
 }
@@ -113,14 +539,33 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("llm.provider", "anthropic")
 	v.SetDefault("llm.model", "claude-sonnet-4-5")
 	v.SetDefault("llm.temperature", 0.0)
+	v.SetDefault("llm.base_url", "")
+	v.SetDefault("llm.deployment", "")
+	v.SetDefault("llm.api_version", "")
+	v.SetDefault("llm.region", "")
 	v.SetDefault("llm.timeout", 60*time.Second)
 	v.SetDefault("llm.max_tokens", 4096)
+	v.SetDefault("llm.retry.max_retries", 3)
+	v.SetDefault("llm.retry.initial_delay", 2*time.Second)
+	v.SetDefault("llm.retry.max_delay", 2*time.Minute)
+	v.SetDefault("llm.cache.enabled", true)
+	v.SetDefault("llm.cache.path", "")
+	v.SetDefault("llm.cache.ttl", 7*24*time.Hour)
+	v.SetDefault("llm.cache.max_size_mb", 512)
+	v.SetDefault("llm.batch", false)
+	v.SetDefault("llm.batch_poll_interval", 30*time.Second)
+	v.SetDefault("llm.stream", false)
+	v.SetDefault("llm.file_cost_ceiling", 0.0)
 
 	// Workflow defaults
 	v.SetDefault("workflow.root_dir", "./generated")
 	v.SetDefault("workflow.allow_commands", []string{"go", "git", "golangci-lint"})
 	v.SetDefault("workflow.max_parallel", 4)
+	v.SetDefault("workflow.speculative", false)
 	v.SetDefault("workflow.checkpoint_interval", 10)
+	v.SetDefault("workflow.approval.checkpoints", []string{})
+	v.SetDefault("workflow.approval.timeout", 5*time.Minute)
+	v.SetDefault("workflow.approval.default", "continue")
 
 	// Validation defaults
 	v.SetDefault("validation.enable_linting", true)
@@ -128,6 +573,75 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("validation.enable_tests", true)
 	v.SetDefault("validation.test_timeout", 5*time.Minute)
 	v.SetDefault("validation.required_coverage", 80.0)
+	v.SetDefault("validation.max_parallel", 4)
+	v.SetDefault("validation.max_repair_attempts", 2)
+	v.SetDefault("validation.repair_tests", false)
+	v.SetDefault("validation.max_test_repair_attempts", 2)
+	v.SetDefault("validation.repair_lint", false)
+	v.SetDefault("validation.max_lint_repair_attempts", 2)
+	v.SetDefault("validation.smoke.enabled", false)
+	v.SetDefault("validation.smoke.startup_timeout", 30*time.Second)
+	v.SetDefault("validation.fuzz.enabled", false)
+	v.SetDefault("validation.fuzz.time", 5*time.Second)
+	v.SetDefault("validation.build_files.enabled", false)
+	v.SetDefault("validation.build_files.docker_timeout", 10*time.Minute)
+	v.SetDefault("validation.severity.checks", map[string]string{})
+	v.SetDefault("validation.severity.lint_rules", map[string]string{})
+	v.SetDefault("validation.severity.fail_on", "error")
+
+	// Project defaults
+	v.SetDefault("project.package_docs", true)
+	v.SetDefault("project.scaffolds", true)
+
+	// Database defaults
+	v.SetDefault("database.dialect", "postgres")
+	v.SetDefault("database.migrations", true)
+	v.SetDefault("database.migrations_dir", "migrations")
+
+	// Plan defaults
+	v.SetDefault("plan.max_files", 200)
+	v.SetDefault("plan.max_directories", 50)
+	v.SetDefault("plan.max_depth", 6)
+	v.SetDefault("plan.max_files_per_package", 40)
+	v.SetDefault("plan.max_replans", 2)
+	v.SetDefault("plan.file_strategy", "auto")
+	v.SetDefault("plan.max_file_lines", 300)
+
+	// Timeout defaults
+	v.SetDefault("timeouts.clarify", 10*time.Minute)
+	v.SetDefault("timeouts.plan", 10*time.Minute)
+	v.SetDefault("timeouts.code", 30*time.Minute)
+	v.SetDefault("timeouts.tests", 30*time.Minute)
+	v.SetDefault("timeouts.docs", 10*time.Minute)
+	v.SetDefault("timeouts.config", 10*time.Minute)
+	v.SetDefault("timeouts.validate", 30*time.Minute)
+
+	// Run limit defaults
+	v.SetDefault("limits.max_duration", 0)
+	v.SetDefault("limits.max_open_files", 0)
+	v.SetDefault("limits.soft_memory_mb", 0)
+	v.SetDefault("limits.max_cost", 0)
+	v.SetDefault("limits.max_tokens", 0)
+	v.SetDefault("limits.on_budget", "abort")
+
+	// Knowledge defaults
+	v.SetDefault("knowledge.enabled", true)
+	v.SetDefault("knowledge.path", "")
+
+	// Style defaults: no house style
+	v.SetDefault("style.error_wrapping", "")
+	v.SetDefault("style.logging", "")
+	v.SetDefault("style.context", "")
+	v.SetDefault("style.initialisms", false)
+	v.SetDefault("style.test_framework", "")
+
+	// Event sink defaults
+	v.SetDefault("events.file.path", "")
+	v.SetDefault("events.webhook.url", "")
+	v.SetDefault("events.webhook.timeout", 10*time.Second)
+	v.SetDefault("events.otlp.endpoint", "")
+	v.SetDefault("events.otlp.service_name", "gocreator")
+	v.SetDefault("events.otlp.timeout", 10*time.Second)
 
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
@@ -151,6 +665,78 @@ func (c *Config) Validate() error {
 	if c.LLM.MaxTokens <= 0 {
 		return fmt.Errorf("llm.max_tokens must be positive")
 	}
+	if c.LLM.Retry.MaxRetries < 0 {
+		return fmt.Errorf("llm.retry.max_retries must not be negative")
+	}
+	if c.LLM.Retry.InitialDelay < 0 || (c.LLM.Retry.MaxRetries > 0 && c.LLM.Retry.InitialDelay == 0) {
+		return fmt.Errorf("llm.retry.initial_delay must be positive")
+	}
+	if c.LLM.Retry.MaxDelay < 0 {
+		return fmt.Errorf("llm.retry.max_delay must not be negative")
+	}
+	if c.LLM.Cache.TTL < 0 {
+		return fmt.Errorf("llm.cache.ttl must not be negative")
+	}
+	if c.LLM.Cache.MaxSizeMB < 0 {
+		return fmt.Errorf("llm.cache.max_size_mb must not be negative")
+	}
+	if c.LLM.BatchPollInterval < 0 {
+		return fmt.Errorf("llm.batch_poll_interval must not be negative")
+	}
+
+	switch c.LLM.Network.TLSMinVersion {
+	case "", "1.2", "1.3":
+	default:
+		return fmt.Errorf("llm.network.tls_min_version must be 1.2 or 1.3, got %q", c.LLM.Network.TLSMinVersion)
+	}
+	if c.LLM.Network.CABundle != "" {
+		if _, err := os.Stat(c.LLM.Network.CABundle); err != nil {
+			return fmt.Errorf("llm.network.ca_bundle is not readable: %w", err)
+		}
+	}
+
+	phases := []struct {
+		name  string
+		model PhaseModelConfig
+	}{{"planner", c.Models.Planner}, {"coder", c.Models.Coder}, {"tester", c.Models.Tester}}
+	for _, phase := range phases {
+		if !phase.model.Enabled() && (phase.model.Provider != "" || phase.model.APIKey != "") {
+			return fmt.Errorf("models.%s.model is required when models.%s is configured", phase.name, phase.name)
+		}
+	}
+
+	providers := []string{c.LLM.Provider, c.LLM.Ensemble.Provider, c.LLM.Downgrade.Provider}
+	for _, phase := range phases {
+		providers = append(providers, phase.model.Provider)
+	}
+	for _, provider := range providers {
+		if (provider == "openai-compatible" || provider == "azure-openai") && c.LLM.BaseURL == "" {
+			return fmt.Errorf("llm.base_url is required for provider %s", provider)
+		}
+	}
+	if !c.LLM.Ensemble.Enabled() && (c.LLM.Ensemble.Provider != "" || c.LLM.Ensemble.APIKey != "") {
+		return fmt.Errorf("llm.ensemble.model is required when llm.ensemble is configured")
+	}
+	if c.LLM.FileCostCeiling < 0 {
+		return fmt.Errorf("llm.file_cost_ceiling must not be negative")
+	}
+	if !c.LLM.Downgrade.Enabled() && (c.LLM.Downgrade.Provider != "" || c.LLM.Downgrade.APIKey != "") {
+		return fmt.Errorf("llm.downgrade.model is required when llm.downgrade is configured")
+	}
+	classes := make(map[string]bool)
+	for i, class := range c.LLM.Concurrency {
+		if class.Provider == "" {
+			return fmt.Errorf("llm.concurrency[%d].provider is required", i)
+		}
+		if class.MaxParallel <= 0 {
+			return fmt.Errorf("llm.concurrency[%d].max_parallel must be positive", i)
+		}
+		key := strings.ToLower(class.Provider + "/" + class.Model)
+		if classes[key] {
+			return fmt.Errorf("llm.concurrency[%d] repeats provider %q model %q", i, class.Provider, class.Model)
+		}
+		classes[key] = true
+	}
 
 	// Validate workflow config
 	if c.Workflow.MaxParallel <= 0 {
@@ -159,11 +745,158 @@ func (c *Config) Validate() error {
 	if c.Workflow.CheckpointInterval <= 0 {
 		return fmt.Errorf("workflow.checkpoint_interval must be positive")
 	}
+	for _, checkpoint := range c.Workflow.Approval.Checkpoints {
+		if !slices.Contains(ApprovalCheckpoints, checkpoint) {
+			return fmt.Errorf("workflow.approval.checkpoints must contain only: %s", strings.Join(ApprovalCheckpoints, ", "))
+		}
+	}
+	if c.Workflow.Approval.Timeout < 0 {
+		return fmt.Errorf("workflow.approval.timeout must not be negative")
+	}
+	validApprovalDefaults := map[string]bool{"": true, "continue": true, "abort": true}
+	if !validApprovalDefaults[c.Workflow.Approval.Default] {
+		return fmt.Errorf("workflow.approval.default must be one of: continue, abort")
+	}
 
 	// Validate validation config
 	if c.Validation.RequiredCoverage < 0 || c.Validation.RequiredCoverage > 100 {
 		return fmt.Errorf("validation.required_coverage must be between 0 and 100")
 	}
+	if c.Validation.MaxRepairAttempts < 0 {
+		return fmt.Errorf("validation.max_repair_attempts must not be negative")
+	}
+	if c.Validation.MaxTestRepairAttempts < 0 {
+		return fmt.Errorf("validation.max_test_repair_attempts must not be negative")
+	}
+	if c.Validation.MaxLintRepairAttempts < 0 {
+		return fmt.Errorf("validation.max_lint_repair_attempts must not be negative")
+	}
+	if c.Validation.MaxParallel <= 0 {
+		return fmt.Errorf("validation.max_parallel must be positive")
+	}
+	if c.Validation.Smoke.StartupTimeout < 0 {
+		return fmt.Errorf("validation.smoke.startup_timeout must not be negative")
+	}
+	if c.Validation.Smoke.HealthURL != "" {
+		u, err := url.Parse(c.Validation.Smoke.HealthURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("validation.smoke.health_url must be an http(s) URL, got %q", c.Validation.Smoke.HealthURL)
+		}
+	}
+	for _, entry := range c.Validation.Smoke.Env {
+		if !strings.Contains(entry, "=") {
+			return fmt.Errorf("validation.smoke.env entries must be KEY=VALUE, got %q", entry)
+		}
+	}
+	if c.Validation.Fuzz.Time < 0 {
+		return fmt.Errorf("validation.fuzz.time must not be negative")
+	}
+	if c.Validation.BuildFiles.DockerTimeout < 0 {
+		return fmt.Errorf("validation.build_files.docker_timeout must not be negative")
+	}
+	for check, severity := range c.Validation.Severity.Checks {
+		if !slices.Contains(SeverityChecks, check) {
+			return fmt.Errorf("validation.severity.checks has unknown check %q (want %s)", check, strings.Join(SeverityChecks, ", "))
+		}
+		if !slices.Contains(Severities, severity) {
+			return fmt.Errorf("validation.severity.checks.%s must be one of: %s", check, strings.Join(Severities, ", "))
+		}
+	}
+	for rule, severity := range c.Validation.Severity.LintRules {
+		if !slices.Contains(Severities, severity) {
+			return fmt.Errorf("validation.severity.lint_rules.%s must be one of: %s", rule, strings.Join(Severities, ", "))
+		}
+	}
+	if c.Validation.Severity.FailOn != "" && (c.Validation.Severity.FailOn == "ignore" || !slices.Contains(Severities, c.Validation.Severity.FailOn)) {
+		return fmt.Errorf("validation.severity.fail_on must be one of: error, warning, info")
+	}
+
+	// Validate project config
+	if strings.ContainsAny(c.Project.ModulePath, " \t\\") || strings.HasPrefix(c.Project.ModulePath, "/") || strings.HasSuffix(c.Project.ModulePath, "/") {
+		return fmt.Errorf("project.module_path is not a valid module path: %q", c.Project.ModulePath)
+	}
+	if strings.ContainsAny(c.Project.BinaryName, "/\\ \t") {
+		return fmt.Errorf("project.binary_name must not contain path separators or spaces")
+	}
+	if c.Project.OutputDir != "" {
+		if _, err := ResolveOutputDir(c.Project.OutputDir, NewOutputPathData("project", "example.com/project", time.Now())); err != nil {
+			return fmt.Errorf("project.output_dir is invalid: %w", err)
+		}
+	}
+	if err := models.ProtectedPaths(c.Project.ProtectedPaths).Validate(); err != nil {
+		return fmt.Errorf("project.protected_paths is invalid: %w", err)
+	}
+	if c.Project.TemplatesDir != "" {
+		if info, err := os.Stat(c.Project.TemplatesDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("project.templates_dir %q is not a directory", c.Project.TemplatesDir)
+		}
+	}
+
+	// Validate plan config
+	if c.Plan.MaxFiles < 0 || c.Plan.MaxDirectories < 0 || c.Plan.MaxDepth < 0 || c.Plan.MaxFilesPerPackage < 0 {
+		return fmt.Errorf("plan limits must not be negative")
+	}
+	if c.Plan.MaxReplans < 0 {
+		return fmt.Errorf("plan.max_replans must not be negative")
+	}
+	validStrategies := map[string]bool{"": true, "auto": true, "per_entity": true, "per_package": true, "bounded": true}
+	if !validStrategies[c.Plan.FileStrategy] {
+		return fmt.Errorf("plan.file_strategy must be one of: auto, per_entity, per_package, bounded")
+	}
+	if c.Plan.FileStrategy == "bounded" && c.Plan.MaxFileLines <= 0 {
+		return fmt.Errorf("plan.max_file_lines must be positive when plan.file_strategy is bounded")
+	}
+
+	// Validate database config
+	if c.Database.Dialect != "" && !slices.Contains(Dialects, c.Database.Dialect) {
+		return fmt.Errorf("database.dialect must be one of: %s", strings.Join(Dialects, ", "))
+	}
+	if c.Database.Migrations {
+		dir := filepath.ToSlash(filepath.Clean(c.Database.MigrationsDir))
+		if c.Database.MigrationsDir == "" || filepath.IsAbs(c.Database.MigrationsDir) || dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+			return fmt.Errorf("database.migrations_dir must be a directory inside the output directory")
+		}
+	}
+
+	// Validate timeouts config
+	t := c.Timeouts
+	if t.Clarify < 0 || t.Plan < 0 || t.Code < 0 || t.Tests < 0 || t.Docs < 0 || t.Config < 0 || t.Validate < 0 {
+		return fmt.Errorf("timeouts must not be negative")
+	}
+
+	// Validate limits config
+	if c.Limits.MaxDuration < 0 || c.Limits.MaxOpenFiles < 0 || c.Limits.SoftMemoryMB < 0 ||
+		c.Limits.MaxCost < 0 || c.Limits.MaxTokens < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	if c.Limits.OnBudget != "" && !slices.Contains(BudgetActions, c.Limits.OnBudget) {
+		return fmt.Errorf("limits.on_budget must be one of: %s", strings.Join(BudgetActions, ", "))
+	}
+
+	// Validate events config
+	if err := c.Events.validate(); err != nil {
+		return err
+	}
+
+	// Validate style config
+	if err := c.Style.Guide().Validate(); err != nil {
+		return fmt.Errorf("style.%w", err)
+	}
+
+	// Validate prompts config
+	if c.Prompts.Preamble != "" && c.Prompts.PreambleFile != "" {
+		return fmt.Errorf("set only one of prompts.preamble and prompts.preamble_file")
+	}
+	if c.Prompts.PreambleFile != "" {
+		if _, err := os.Stat(c.Prompts.PreambleFile); err != nil {
+			return fmt.Errorf("prompts.preamble_file is not readable: %w", err)
+		}
+	}
+	if c.Prompts.TemplatesDir != "" {
+		if info, err := os.Stat(c.Prompts.TemplatesDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("prompts.templates_dir must be an existing directory: %s", c.Prompts.TemplatesDir)
+		}
+	}
 
 	// Validate logging config
 	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
@@ -178,6 +911,31 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validate checks the sink URLs and event types
+func (e EventsConfig) validate() error {
+	for _, sink := range []struct {
+		name, url string
+	}{{"events.webhook.url", e.Webhook.URL}, {"events.otlp.endpoint", e.OTLP.Endpoint}} {
+		if sink.url == "" {
+			continue
+		}
+		if u, err := url.Parse(sink.url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s must be an http(s) URL", sink.name)
+		}
+	}
+	if e.Webhook.Timeout < 0 || e.OTLP.Timeout < 0 {
+		return fmt.Errorf("events timeouts must not be negative")
+	}
+	for name, types := range map[string][]string{"events.file.types": e.File.Types, "events.webhook.types": e.Webhook.Types} {
+		for _, t := range types {
+			if !slices.Contains(models.EventTypes, models.EventType(t)) {
+				return fmt.Errorf("%s has unknown event type %q", name, t)
+			}
+		}
+	}
+	return nil
+}
+
 // GetLogLevel returns the zerolog level based on config
 func (c *Config) GetLogLevel() zerolog.Level {
 	switch c.Logging.Level {
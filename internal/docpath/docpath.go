@@ -0,0 +1,196 @@
+// Package docpath addresses values in decoded JSON and YAML documents, such
+// as specs and FCS files, with paths like
+// data_model.entities[User].attributes.email. A segment in brackets selects
+// a list item by its id, name or path, the method and endpoint of an HTTP
+// contract or the service and method of a gRPC one, or by its index.
+package docpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// segment is a map key, or the key of a list item when item is set
+type segment struct {
+	key  string
+	item bool
+}
+
+// parse splits a path into its segments
+func parse(path string) []segment {
+	var segments []segment
+	for path != "" {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+		case '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				end = len(path)
+			}
+			segments = append(segments, segment{key: path[1:end], item: true})
+			path = path[min(end+1, len(path)):]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			segments = append(segments, segment{key: path[:end]})
+			path = path[end:]
+		}
+	}
+	return segments
+}
+
+// ItemKey returns the key a list item is addressed by: its id, name or
+// path, the method and endpoint of an HTTP contract, or the service and
+// method of a gRPC one. It is empty for other items.
+func ItemKey(item map[string]interface{}) string {
+	for _, key := range []string{"id", "name", "path"} {
+		if value, _ := item[key].(string); value != "" {
+			return value
+		}
+	}
+	method, _ := item["method"].(string)
+	if endpoint, _ := item["endpoint"].(string); endpoint != "" {
+		return strings.ToUpper(method) + " " + endpoint
+	}
+	if service, _ := item["service"].(string); service != "" {
+		return service + "/" + method
+	}
+	return ""
+}
+
+// IndexOf returns the index of the list item with the given key, or -1
+func IndexOf(list []interface{}, key string) int {
+	for i, item := range list {
+		if itemMap, ok := item.(map[string]interface{}); ok && ItemKey(itemMap) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// find returns the index of the list item a path segment selects: the item
+// with the key, or else the item at the index the key gives, or -1
+func find(list []interface{}, key string) int {
+	if i := IndexOf(list, key); i >= 0 {
+		return i
+	}
+	if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(list) {
+		return i
+	}
+	return -1
+}
+
+// Lookup returns the value at path
+func Lookup(doc map[string]interface{}, path string) (interface{}, bool) {
+	return lookup(doc, parse(path))
+}
+
+func lookup(doc map[string]interface{}, segments []segment) (interface{}, bool) {
+	var value interface{} = doc
+	for _, seg := range segments {
+		next, ok := child(value, seg)
+		if !ok {
+			return nil, false
+		}
+		value = next
+	}
+	return value, true
+}
+
+// Set sets the value at path. The value it replaces must exist, except for
+// a missing key of an existing object, which is added.
+func Set(doc map[string]interface{}, path string, value interface{}) error {
+	segments := parse(path)
+	if len(segments) == 0 {
+		return fmt.Errorf("empty path")
+	}
+	for i := range segments[:len(segments)-1] {
+		if _, ok := lookup(doc, segments[:i+1]); !ok {
+			return fmt.Errorf("%s: %s not found", path, format(segments[:i+1]))
+		}
+	}
+	parent, _ := lookup(doc, segments[:len(segments)-1])
+
+	last := segments[len(segments)-1]
+	switch parent := parent.(type) {
+	case map[string]interface{}:
+		if last.item {
+			return fmt.Errorf("%s: %s is an object, not a list", path, format(segments[:len(segments)-1]))
+		}
+		parent[last.key] = value
+	case []interface{}:
+		if !last.item {
+			return fmt.Errorf("%s: %s is a list; select an item in brackets", path, format(segments[:len(segments)-1]))
+		}
+		i := find(parent, last.key)
+		if i < 0 {
+			return fmt.Errorf("%s: no item %q in %s", path, last.key, format(segments[:len(segments)-1]))
+		}
+		parent[i] = value
+	default:
+		return fmt.Errorf("%s: %s holds a value, not an object or list", path, format(segments[:len(segments)-1]))
+	}
+	return nil
+}
+
+// ParseValue converts text to a value to set where current is: text itself
+// where current is a string, and otherwise the JSON or YAML value it holds
+func ParseValue(text string, current interface{}) (interface{}, error) {
+	text = strings.TrimSpace(text)
+	if _, ok := current.(string); ok {
+		return text, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err == nil {
+		return value, nil
+	}
+	if err := yaml.Unmarshal([]byte(text), &value); err != nil {
+		return nil, fmt.Errorf("value %q is neither JSON nor YAML: %w", text, err)
+	}
+	return value, nil
+}
+
+// child returns the value of one path segment within value
+func child(value interface{}, seg segment) (interface{}, bool) {
+	if seg.item {
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		i := find(list, seg.key)
+		if i < 0 {
+			return nil, false
+		}
+		return list[i], true
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	v, ok := m[seg.key]
+	return v, ok
+}
+
+// format joins segments back into a path
+func format(segments []segment) string {
+	var sb strings.Builder
+	for _, seg := range segments {
+		switch {
+		case seg.item:
+			sb.WriteString("[" + seg.key + "]")
+		case sb.Len() > 0:
+			sb.WriteString("." + seg.key)
+		default:
+			sb.WriteString(seg.key)
+		}
+	}
+	return sb.String()
+}
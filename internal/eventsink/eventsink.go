@@ -0,0 +1,171 @@
+// Package eventsink provides the sinks that receive the progress events of a
+// run from a models.EventBus besides the console: a JSON Lines file, a
+// webhook, and an OpenTelemetry trace exporter.
+package eventsink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultTimeout bounds each request of a network sink when no timeout is set
+	DefaultTimeout = 10 * time.Second
+
+	// queueSize is how many payloads a poster holds before it drops new ones
+	queueSize = 1024
+
+	// drainTimeout is how long closing a poster waits for its queue to be
+	// delivered before it gives up on the rest
+	drainTimeout = 10 * time.Second
+)
+
+// filter selects the events a sink handles: those of the listed types, or
+// without any every event but the file_streaming chunks
+type filter map[models.EventType]bool
+
+func newFilter(types []models.EventType) filter {
+	f := make(filter, len(types))
+	for _, t := range types {
+		f[t] = true
+	}
+	return f
+}
+
+func (f filter) allows(t models.EventType) bool {
+	if len(f) == 0 {
+		return t != models.EventFileStreaming
+	}
+	return f[t]
+}
+
+// checkURL validates the URL of a network sink
+func checkURL(name, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s URL must be an http(s) URL: %q", name, rawURL)
+	}
+	return nil
+}
+
+// poster posts JSON payloads to an endpoint from a goroutine of its own, so a
+// slow or unreachable receiver never holds up the run. Payloads that do not
+// fit its queue are dropped.
+type poster struct {
+	name    string
+	url     string
+	headers map[string]string
+	client  *http.Client
+	queue   chan []byte
+	done    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu      sync.Mutex
+	sent    int
+	failed  int
+	dropped int
+}
+
+// newPoster starts a poster; name identifies it in logs and errors
+func newPoster(name, endpoint string, headers map[string]string, timeout time.Duration) *poster {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &poster{
+		name:    name,
+		url:     endpoint,
+		headers: headers,
+		client:  &http.Client{Timeout: timeout},
+		queue:   make(chan []byte, queueSize),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	go p.run()
+	return p
+}
+
+// send queues payload for delivery
+func (p *poster) send(payload []byte) {
+	select {
+	case p.queue <- payload:
+	default:
+		p.mu.Lock()
+		p.dropped++
+		p.mu.Unlock()
+	}
+}
+
+func (p *poster) run() {
+	defer close(p.done)
+	for payload := range p.queue {
+		err := p.post(payload)
+		p.mu.Lock()
+		if err == nil {
+			p.sent++
+			p.mu.Unlock()
+			continue
+		}
+		p.failed++
+		first := p.failed == 1
+		p.mu.Unlock()
+
+		// One warning is enough when the receiver is down
+		if first {
+			log.Warn().Err(err).Str("sink", p.name).Msg("Failed to deliver progress event")
+		} else {
+			log.Debug().Err(err).Str("sink", p.name).Msg("Failed to deliver progress event")
+		}
+	}
+}
+
+func (p *poster) post(payload []byte) error {
+	req, err := http.NewRequestWithContext(p.ctx, http.MethodPost, p.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range p.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %s: %s", p.url, resp.Status)
+	}
+	return nil
+}
+
+// close delivers the queued payloads, waiting at most drainTimeout, and
+// reports those that were not delivered. Nothing may be sent after it.
+func (p *poster) close() error {
+	close(p.queue)
+	select {
+	case <-p.done:
+	case <-time.After(drainTimeout):
+		p.cancel()
+		<-p.done
+	}
+	p.cancel()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if lost := p.failed + p.dropped; lost > 0 {
+		return fmt.Errorf("%s: %d of %d payloads not delivered", p.name, lost, lost+p.sent)
+	}
+	return nil
+}
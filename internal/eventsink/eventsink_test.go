@@ -0,0 +1,172 @@
+package eventsink
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func event(t models.EventType, data map[string]interface{}) models.ProgressEvent {
+	return models.ProgressEvent{Type: t, Timestamp: time.Now(), Data: data}
+}
+
+// receiver records the bodies posted to it
+type receiver struct {
+	mu     sync.Mutex
+	bodies [][]byte
+	header http.Header
+	path   string
+}
+
+func newReceiver(t *testing.T, status int) (*receiver, *httptest.Server) {
+	r := &receiver{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.bodies = append(r.bodies, body)
+		r.header = req.Header.Clone()
+		r.path = req.URL.Path
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(ts.Close)
+	return r, ts
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "events.jsonl")
+
+	sink, err := NewFileSink(path, nil)
+	require.NoError(t, err)
+	bus := models.NewEventBus(sink)
+	bus.Publish(event(models.EventPhaseStarted, map[string]interface{}{"phase": "create_plan"}))
+	bus.Publish(event(models.EventFileStreaming, map[string]interface{}{"path": "main.go"}))
+	bus.Publish(event(models.EventPhaseCompleted, map[string]interface{}{"phase": "create_plan"}))
+	require.NoError(t, bus.Close())
+
+	// A second run appends, recording only the selected types
+	sink, err = NewFileSink(path, []models.EventType{models.EventError})
+	require.NoError(t, err)
+	sink.HandleEvent(event(models.EventPhaseStarted, nil))
+	sink.HandleEvent(event(models.EventError, map[string]interface{}{"message": "boom"}))
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var types []models.EventType
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var e models.ProgressEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []models.EventType{models.EventPhaseStarted, models.EventPhaseCompleted, models.EventError}, types)
+}
+
+func TestWebhookSink(t *testing.T) {
+	r, ts := newReceiver(t, http.StatusNoContent)
+
+	sink, err := NewWebhookSink(WebhookConfig{
+		URL:     ts.URL,
+		Types:   []models.EventType{models.EventPhaseCompleted, models.EventError},
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+		Source:  "./out",
+	})
+	require.NoError(t, err)
+	sink.HandleEvent(event(models.EventPhaseStarted, map[string]interface{}{"phase": "create_plan"}))
+	sink.HandleEvent(event(models.EventPhaseCompleted, map[string]interface{}{"phase": "create_plan"}))
+	sink.HandleEvent(event(models.EventError, map[string]interface{}{"message": "boom"}))
+	require.NoError(t, sink.Close())
+
+	require.Len(t, r.bodies, 2)
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(r.bodies[1], &payload))
+	assert.Equal(t, "./out", payload.Source)
+	assert.Equal(t, models.EventError, payload.Event.Type)
+	assert.Equal(t, "boom", payload.Event.Data["message"])
+	assert.Equal(t, "Bearer secret", r.header.Get("Authorization"))
+	assert.Equal(t, "application/json", r.header.Get("Content-Type"))
+}
+
+func TestWebhookSink_Undelivered(t *testing.T) {
+	_, ts := newReceiver(t, http.StatusInternalServerError)
+
+	sink, err := NewWebhookSink(WebhookConfig{URL: ts.URL})
+	require.NoError(t, err)
+	sink.HandleEvent(event(models.EventPhaseStarted, nil))
+	sink.HandleEvent(event(models.EventPhaseCompleted, nil))
+
+	err = sink.Close()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhook: 2 of 2 payloads not delivered")
+
+	_, err = NewWebhookSink(WebhookConfig{URL: "ftp://example.com"})
+	assert.Error(t, err)
+}
+
+func TestOTLPSink(t *testing.T) {
+	r, ts := newReceiver(t, http.StatusOK)
+
+	sink, err := NewOTLPSink(OTLPConfig{
+		Endpoint:       ts.URL + "/",
+		ServiceVersion: "1.2.3",
+		Attributes:     map[string]string{"gocreator.model": "m"},
+	})
+	require.NoError(t, err)
+	sink.HandleEvent(event(models.EventPhaseStarted, map[string]interface{}{"phase": "generate_packages"}))
+	sink.HandleEvent(event(models.EventFileGenerating, map[string]interface{}{"phase": "generate_packages", "path": "main.go"}))
+	sink.HandleEvent(event(models.EventFileCompleted, map[string]interface{}{"path": "main.go", "lines": 42}))
+	sink.HandleEvent(event(models.EventPhaseCompleted, map[string]interface{}{"phase": "generate_packages", "files": 1}))
+	sink.HandleEvent(event(models.EventPhaseStarted, map[string]interface{}{"phase": "file_writing"}))
+	sink.HandleEvent(event(models.EventError, map[string]interface{}{"phase": "file_writing", "message": "disk full"}))
+	sink.HandleEvent(event(models.EventTokensUsed, map[string]interface{}{"total_input": 100, "total_output": 50, "total_cached": 0}))
+	sink.HandleEvent(event(models.EventCostUpdate, map[string]interface{}{"total_cost": 0.25}))
+	require.NoError(t, sink.Close())
+
+	// Spans are exported as their phase completes, and the rest on close
+	require.Len(t, r.bodies, 2)
+	spans := map[string]*otlpSpan{}
+	for _, body := range r.bodies {
+		var request otlpTraceRequest
+		require.NoError(t, json.Unmarshal(body, &request))
+		require.Len(t, request.ResourceSpans, 1)
+		assert.Equal(t, "gocreator", *request.ResourceSpans[0].Resource.Attributes[0].Value.StringValue)
+		for _, span := range request.ResourceSpans[0].ScopeSpans[0].Spans {
+			spans[span.Name] = span
+		}
+	}
+	assert.Equal(t, "/v1/traces", r.path)
+
+	root := spans["gocreator.run"]
+	require.NotNil(t, root)
+	assert.Empty(t, root.ParentSpanID)
+	assert.Equal(t, root.SpanID, spans["generate_packages"].ParentSpanID)
+	assert.Equal(t, spans["generate_packages"].SpanID, spans["generate_file"].ParentSpanID)
+	assert.Equal(t, root.TraceID, spans["generate_file"].TraceID)
+
+	failed := spans["file_writing"]
+	require.NotNil(t, failed.Status)
+	assert.Equal(t, statusCodeError, failed.Status.Code)
+	assert.Equal(t, "disk full", failed.Status.Message)
+	require.Len(t, failed.Events, 1)
+
+	attributes := map[string]otlpValue{}
+	for _, attribute := range root.Attributes {
+		attributes[attribute.Key] = attribute.Value
+	}
+	assert.Equal(t, "m", *attributes["gocreator.model"].StringValue)
+	assert.Equal(t, "100", *attributes["gocreator.tokens.input"].IntValue)
+	assert.InDelta(t, 0.25, *attributes["gocreator.cost_usd"].DoubleValue, 1e-9)
+}
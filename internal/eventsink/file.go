@@ -0,0 +1,61 @@
+package eventsink
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// FileSink appends each event to a JSON Lines file, one event per line
+type FileSink struct {
+	filter filter
+
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	err     error // First write error, reported by Close
+}
+
+// NewFileSink opens path for appending, creating it and its directory. It
+// records the events of types, or without any every event but the
+// file_streaming chunks.
+func NewFileSink(path string, types []models.EventType) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create event log directory: %w", err)
+	}
+	//nolint:gosec // G304: The event log path is configured by the user
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	return &FileSink{filter: newFilter(types), file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// HandleEvent implements models.EventSink
+func (s *FileSink) HandleEvent(event models.ProgressEvent) {
+	if !s.filter.allows(event.Type) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return
+	}
+	if err := s.encoder.Encode(event); err != nil {
+		s.err = fmt.Errorf("failed to write event log %s: %w", s.file.Name(), err)
+	}
+}
+
+// Close closes the file, reporting the first write that failed
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Close(); err != nil && s.err == nil {
+		s.err = fmt.Errorf("failed to close event log: %w", err)
+	}
+	return s.err
+}
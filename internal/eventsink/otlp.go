@@ -0,0 +1,357 @@
+package eventsink
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// otlpScope names the instrumentation scope of the exported spans
+const otlpScope = "github.com/dshills/gocreator"
+
+// OTLP span kind and status codes
+const (
+	spanKindInternal = 1
+	statusCodeError  = 2
+)
+
+// OTLPConfig configures an OTLPSink
+type OTLPConfig struct {
+	Endpoint       string            // Collector base URL; spans are posted to <endpoint>/v1/traces
+	ServiceName    string            // service.name of the resource (default: gocreator)
+	ServiceVersion string            // service.version of the resource
+	Headers        map[string]string // Sent with each request, e.g. an API key
+	Timeout        time.Duration     // Per request (default: DefaultTimeout)
+	Attributes     map[string]string // Attributes of the run's root span
+}
+
+// OTLPSink exports a run as an OpenTelemetry trace over OTLP/HTTP with JSON
+// encoding. The run is the root span, each phase a child span, and each
+// generated file a span under its phase. Errors and package validations are
+// span events; token and cost totals are attributes of the root span.
+// Finished spans are exported when their phase completes, and the rest when
+// the sink is closed.
+type OTLPSink struct {
+	config OTLPConfig
+	poster *poster
+
+	mu       sync.Mutex
+	traceID  string
+	root     *otlpSpan
+	phases   map[string]*otlpSpan
+	files    map[string]*otlpSpan
+	finished []*otlpSpan
+	totals   map[string]float64 // Root span attribute -> latest total
+}
+
+// NewOTLPSink creates a sink exporting to the collector at config.Endpoint
+func NewOTLPSink(config OTLPConfig) (*OTLPSink, error) {
+	if err := checkURL("OTLP endpoint", config.Endpoint); err != nil {
+		return nil, err
+	}
+	if config.ServiceName == "" {
+		config.ServiceName = "gocreator"
+	}
+	endpoint := strings.TrimSuffix(config.Endpoint, "/") + "/v1/traces"
+	return &OTLPSink{
+		config:  config,
+		poster:  newPoster("otlp", endpoint, config.Headers, config.Timeout),
+		traceID: randomHex(16),
+		phases:  make(map[string]*otlpSpan),
+		files:   make(map[string]*otlpSpan),
+		totals:  make(map[string]float64),
+	}, nil
+}
+
+// HandleEvent implements models.EventSink
+func (s *OTLPSink) HandleEvent(event models.ProgressEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at := event.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if s.root == nil {
+		s.root = s.newSpan("gocreator.run", "", at)
+	}
+
+	switch event.Type {
+	case models.EventPhaseStarted:
+		phase := eventString(event, "phase")
+		if open := s.phases[phase]; open != nil {
+			s.endLocked(open, at)
+		}
+		span := s.newSpan(phase, s.root.SpanID, at)
+		span.addString("gocreator.phase", phase)
+		if description := eventString(event, "description"); description != "" {
+			span.addString("gocreator.phase.description", description)
+		}
+		s.phases[phase] = span
+
+	case models.EventPhaseCompleted:
+		phase := eventString(event, "phase")
+		if span := s.phases[phase]; span != nil {
+			span.addInt("gocreator.files", eventInt(event, "files"))
+			s.endLocked(span, at)
+			delete(s.phases, phase)
+		}
+		s.exportLocked()
+
+	case models.EventFileGenerating:
+		path := eventString(event, "path")
+		parent := s.root
+		if phase := s.phases[eventString(event, "phase")]; phase != nil {
+			parent = phase
+		}
+		span := s.newSpan("generate_file", parent.SpanID, at)
+		span.addString("gocreator.file.path", path)
+		s.files[path] = span
+
+	case models.EventFileCompleted:
+		path := eventString(event, "path")
+		if span := s.files[path]; span != nil {
+			span.addInt("gocreator.file.lines", eventInt(event, "lines"))
+			s.endLocked(span, at)
+			delete(s.files, path)
+		}
+
+	case models.EventError:
+		// The error belongs to the innermost open span it names
+		span := s.root
+		if phase := s.phases[eventString(event, "phase")]; phase != nil {
+			span = phase
+		}
+		if file := s.files[eventString(event, "file")]; file != nil {
+			span = file
+		}
+		message := eventString(event, "message")
+		span.addEvent("error", at, stringAttribute("message", message), stringAttribute("file", eventString(event, "file")))
+		span.Status = &otlpStatus{Code: statusCodeError, Message: message}
+
+	case models.EventPackageValidated:
+		s.root.addEvent("package_validated", at,
+			stringAttribute("package", eventString(event, "package")),
+			stringAttribute("check", eventString(event, "check")),
+			boolAttribute("success", event.Data["success"] == true))
+
+	case models.EventTokensUsed:
+		s.totals["gocreator.tokens.input"] = float64(eventInt(event, "total_input"))
+		s.totals["gocreator.tokens.output"] = float64(eventInt(event, "total_output"))
+		s.totals["gocreator.tokens.cached"] = float64(eventInt(event, "total_cached"))
+
+	case models.EventCostUpdate:
+		s.totals["gocreator.cost_usd"] = eventFloat(event, "total_cost")
+	}
+}
+
+// Close ends the spans still open, including the root span, exports them,
+// and reports spans that could not be delivered
+func (s *OTLPSink) Close() error {
+	s.mu.Lock()
+	if s.root != nil {
+		now := time.Now()
+		for _, span := range s.files {
+			s.endLocked(span, now)
+		}
+		for _, span := range s.phases {
+			s.endLocked(span, now)
+		}
+		for key, value := range s.config.Attributes {
+			s.root.addString(key, value)
+		}
+		for _, key := range []string{"gocreator.tokens.input", "gocreator.tokens.output", "gocreator.tokens.cached"} {
+			if value, ok := s.totals[key]; ok {
+				s.root.addInt(key, int64(value))
+			}
+		}
+		if cost, ok := s.totals["gocreator.cost_usd"]; ok {
+			s.root.Attributes = append(s.root.Attributes, otlpAttribute{Key: "gocreator.cost_usd", Value: otlpValue{DoubleValue: &cost}})
+		}
+		s.endLocked(s.root, now)
+		s.exportLocked()
+	}
+	s.mu.Unlock()
+	return s.poster.close()
+}
+
+// newSpan starts a span of the run's trace
+func (s *OTLPSink) newSpan(name, parentID string, start time.Time) *otlpSpan {
+	return &otlpSpan{
+		TraceID:           s.traceID,
+		SpanID:            randomHex(8),
+		ParentSpanID:      parentID,
+		Name:              name,
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: unixNano(start),
+	}
+}
+
+// endLocked ends span at end and queues it for export
+func (s *OTLPSink) endLocked(span *otlpSpan, end time.Time) {
+	span.EndTimeUnixNano = unixNano(end)
+	s.finished = append(s.finished, span)
+}
+
+// exportLocked posts the finished spans
+func (s *OTLPSink) exportLocked() {
+	if len(s.finished) == 0 {
+		return
+	}
+	resource := []otlpAttribute{stringAttribute("service.name", s.config.ServiceName)}
+	if s.config.ServiceVersion != "" {
+		resource = append(resource, stringAttribute("service.version", s.config.ServiceVersion))
+	}
+	request := otlpTraceRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: resource},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpInstrumentationScope{Name: otlpScope, Version: s.config.ServiceVersion},
+			Spans: s.finished,
+		}},
+	}}}
+	s.finished = nil
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return
+	}
+	s.poster.send(payload)
+}
+
+// otlpTraceRequest is the JSON encoding of an OTLP ExportTraceServiceRequest
+type otlpTraceRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpInstrumentationScope `json:"scope"`
+	Spans []*otlpSpan              `json:"spans"`
+}
+
+type otlpInstrumentationScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// otlpSpan is an OTLP span; IDs are hex encoded and times are decimal strings
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Events            []otlpEvent     `json:"events,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+func (s *otlpSpan) addString(key, value string) {
+	s.Attributes = append(s.Attributes, stringAttribute(key, value))
+}
+
+func (s *otlpSpan) addInt(key string, value int64) {
+	v := strconv.FormatInt(value, 10)
+	s.Attributes = append(s.Attributes, otlpAttribute{Key: key, Value: otlpValue{IntValue: &v}})
+}
+
+func (s *otlpSpan) addEvent(name string, at time.Time, attributes ...otlpAttribute) {
+	s.Events = append(s.Events, otlpEvent{TimeUnixNano: unixNano(at), Name: name, Attributes: attributes})
+}
+
+type otlpEvent struct {
+	TimeUnixNano string          `json:"timeUnixNano"`
+	Name         string          `json:"name"`
+	Attributes   []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+// otlpValue is an AnyValue; int64 values are encoded as strings
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: &value}}
+}
+
+func boolAttribute(key string, value bool) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{BoolValue: &value}}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// eventString returns a string field of the event's data
+func eventString(event models.ProgressEvent, key string) string {
+	if value, ok := event.Data[key]; ok && value != nil {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+// eventInt returns an integer field of the event's data, which holds Go
+// values when published in process and float64 when decoded from JSON
+func eventInt(event models.ProgressEvent, key string) int64 {
+	switch value := event.Data[key].(type) {
+	case int:
+		return int64(value)
+	case int64:
+		return value
+	case float64:
+		return int64(value)
+	default:
+		return 0
+	}
+}
+
+// eventFloat returns a number field of the event's data
+func eventFloat(event models.ProgressEvent, key string) float64 {
+	switch value := event.Data[key].(type) {
+	case float64:
+		return value
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	default:
+		return 0
+	}
+}
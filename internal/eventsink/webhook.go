@@ -0,0 +1,61 @@
+package eventsink
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// WebhookConfig configures a WebhookSink
+type WebhookConfig struct {
+	URL     string
+	Types   []models.EventType // Events to post; empty posts all but file_streaming
+	Headers map[string]string  // Sent with each request, e.g. Authorization
+	Timeout time.Duration      // Per request (default: DefaultTimeout)
+	Source  string             // Identifies the run in each payload, such as its output directory
+}
+
+// WebhookPayload is the JSON body posted for each event
+type WebhookPayload struct {
+	Source string               `json:"source,omitempty"`
+	Event  models.ProgressEvent `json:"event"`
+}
+
+// WebhookSink posts events to a URL, for example to notify CI of a run's
+// phases and errors. Events are posted one per request in the order they
+// occur, from a queue, so an unreachable receiver does not slow the run.
+type WebhookSink struct {
+	filter filter
+	source string
+	poster *poster
+}
+
+// NewWebhookSink creates a sink posting to config.URL
+func NewWebhookSink(config WebhookConfig) (*WebhookSink, error) {
+	if err := checkURL("webhook", config.URL); err != nil {
+		return nil, err
+	}
+	return &WebhookSink{
+		filter: newFilter(config.Types),
+		source: config.Source,
+		poster: newPoster("webhook", config.URL, config.Headers, config.Timeout),
+	}, nil
+}
+
+// HandleEvent implements models.EventSink
+func (s *WebhookSink) HandleEvent(event models.ProgressEvent) {
+	if !s.filter.allows(event.Type) {
+		return
+	}
+	payload, err := json.Marshal(WebhookPayload{Source: s.source, Event: event})
+	if err != nil {
+		return
+	}
+	s.poster.send(payload)
+}
+
+// Close posts the queued events, and reports those that were not delivered
+func (s *WebhookSink) Close() error {
+	return s.poster.close()
+}
@@ -0,0 +1,302 @@
+// Package fcsdump renders a Final Clarified Specification for review.
+//
+// Output can be narrowed to individual sections, written as JSON, YAML,
+// Markdown or plain-text tables, and redacted so that free-text descriptions
+// stay private while the structure of the specification is still reviewable.
+package fcsdump
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Format selects the output representation
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatMarkdown Format = "markdown"
+	FormatTable    Format = "table"
+)
+
+// Sections lists the FCS sections in document order, named by their JSON keys
+var Sections = []string{
+	"metadata",
+	"requirements",
+	"architecture",
+	"data_model",
+	"api_contracts",
+	"cross_cutting",
+	"contracts",
+	"testing_strategy",
+	"build_config",
+	"file_tree",
+}
+
+// RedactedText replaces free-text fields when redaction is enabled
+const RedactedText = "[REDACTED]"
+
+// Options controls what is rendered and how
+type Options struct {
+	Sections []string // Sections to include; empty renders the whole FCS
+	Format   Format   // Output format (default: json)
+	Pretty   bool     // Indent JSON output
+	Redact   bool     // Replace descriptions, purposes and answers with RedactedText
+}
+
+// ParseFormat validates a format name
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatJSON, FormatYAML, FormatMarkdown, FormatTable:
+		return f, nil
+	case "md":
+		return FormatMarkdown, nil
+	case "yml":
+		return FormatYAML, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q (must be json, yaml, markdown, or table)", name)
+	}
+}
+
+// ParseSections validates section names and returns them in document order
+// without duplicates. Dashes are accepted in place of underscores.
+func ParseSections(names []string) ([]string, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+		if !isSection(key) {
+			return nil, fmt.Errorf("unknown FCS section %q (valid: %s)", name, strings.Join(Sections, ", "))
+		}
+		wanted[key] = true
+	}
+
+	var sections []string
+	for _, key := range Sections {
+		if wanted[key] {
+			sections = append(sections, key)
+		}
+	}
+	return sections, nil
+}
+
+func isSection(key string) bool {
+	for _, s := range Sections {
+		if s == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Render writes the selected sections of fcs to w
+func Render(w io.Writer, fcs *models.FinalClarifiedSpecification, opts Options) error {
+	sections, err := ParseSections(opts.Sections)
+	if err != nil {
+		return err
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = FormatJSON
+	}
+
+	if opts.Redact {
+		fcs, err = Redact(fcs)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch format {
+	case FormatJSON:
+		return renderJSON(w, fcs, sections, opts.Pretty)
+	case FormatYAML:
+		return renderYAML(w, fcs, sections)
+	case FormatMarkdown:
+		return renderMarkdown(w, fcs, sections)
+	case FormatTable:
+		return renderTable(w, fcs, sections)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// Redact returns a copy of fcs with free-text fields replaced by RedactedText.
+// IDs, names, types and the metadata hash are kept, so the redacted copy can be
+// matched with the original but no longer validates against its hash.
+func Redact(fcs *models.FinalClarifiedSpecification) (*models.FinalClarifiedSpecification, error) {
+	data, err := json.Marshal(fcs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy FCS: %w", err)
+	}
+	var redacted models.FinalClarifiedSpecification
+	if err := json.Unmarshal(data, &redacted); err != nil {
+		return nil, fmt.Errorf("failed to copy FCS: %w", err)
+	}
+
+	redact := func(s *string) {
+		if *s != "" {
+			*s = RedactedText
+		}
+	}
+
+	redact(&redacted.Metadata.OriginalSpec)
+	for i := range redacted.Metadata.Clarifications {
+		redact(&redacted.Metadata.Clarifications[i].Answer)
+	}
+	for i := range redacted.Metadata.Conflicts {
+		conflict := &redacted.Metadata.Conflicts[i]
+		redact(&conflict.Resolution)
+		for j := range conflict.Sources {
+			redact(&conflict.Sources[j].Value)
+		}
+	}
+	for i := range redacted.Requirements.Functional {
+		redact(&redacted.Requirements.Functional[i].Description)
+	}
+	for i := range redacted.Requirements.NonFunctional {
+		redact(&redacted.Requirements.NonFunctional[i].Description)
+	}
+	for i := range redacted.Architecture.Packages {
+		redact(&redacted.Architecture.Packages[i].Purpose)
+	}
+	for i := range redacted.Architecture.Dependencies {
+		redact(&redacted.Architecture.Dependencies[i].Purpose)
+	}
+	for i := range redacted.Architecture.Patterns {
+		redact(&redacted.Architecture.Patterns[i].Description)
+	}
+	for i := range redacted.DataModel.Relationships {
+		redact(&redacted.DataModel.Relationships[i].Description)
+	}
+	for i := range redacted.DataModel.Enums {
+		redact(&redacted.DataModel.Enums[i].Description)
+	}
+	for i := range redacted.APIContracts {
+		redact(&redacted.APIContracts[i].Description)
+	}
+	for i := range redacted.CrossCutting.Concerns {
+		redact(&redacted.CrossCutting.Concerns[i].Description)
+	}
+	for i := range redacted.Contracts {
+		redact(&redacted.Contracts[i].Description)
+	}
+	if redacted.FileTree != nil {
+		for i := range redacted.FileTree.Directories {
+			redact(&redacted.FileTree.Directories[i].Purpose)
+		}
+		for i := range redacted.FileTree.Files {
+			redact(&redacted.FileTree.Files[i].Purpose)
+		}
+	}
+
+	return &redacted, nil
+}
+
+// sectionValue returns the FCS field stored under a section key
+func sectionValue(fcs *models.FinalClarifiedSpecification, key string) interface{} {
+	switch key {
+	case "metadata":
+		return fcs.Metadata
+	case "requirements":
+		return fcs.Requirements
+	case "architecture":
+		return fcs.Architecture
+	case "data_model":
+		return fcs.DataModel
+	case "api_contracts":
+		return fcs.APIContracts
+	case "cross_cutting":
+		return fcs.CrossCutting
+	case "contracts":
+		return fcs.Contracts
+	case "testing_strategy":
+		return fcs.TestingStrategy
+	case "build_config":
+		return fcs.BuildConfig
+	case "file_tree":
+		return fcs.FileTree
+	default:
+		return nil
+	}
+}
+
+// marshalSections encodes the selected sections as one JSON object in
+// document order, or the whole FCS when no sections are selected
+func marshalSections(fcs *models.FinalClarifiedSpecification, sections []string) ([]byte, error) {
+	if len(sections) == 0 {
+		return json.Marshal(fcs)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range sections {
+		value, err := json.Marshal(sectionValue(fcs, key))
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, "%q:", key)
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func renderJSON(w io.Writer, fcs *models.FinalClarifiedSpecification, sections []string, pretty bool) error {
+	data, err := marshalSections(fcs, sections)
+	if err != nil {
+		return fmt.Errorf("failed to marshal FCS: %w", err)
+	}
+
+	if pretty {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			return fmt.Errorf("failed to format FCS: %w", err)
+		}
+		data = indented.Bytes()
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// renderYAML converts the JSON encoding so YAML keys match the JSON schema
+// and keep the FCS field order
+func renderYAML(w io.Writer, fcs *models.FinalClarifiedSpecification, sections []string) error {
+	data, err := marshalSections(fcs, sections)
+	if err != nil {
+		return fmt.Errorf("failed to marshal FCS: %w", err)
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to convert FCS to YAML: %w", err)
+	}
+	clearStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return fmt.Errorf("failed to write YAML: %w", err)
+	}
+	return enc.Close()
+}
+
+// clearStyle drops the flow style and quoting inherited from the JSON input;
+// the encoder still quotes strings that would otherwise read back as other types
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
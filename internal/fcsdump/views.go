@@ -0,0 +1,383 @@
+package fcsdump
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// view is a format-neutral description of one section, shared by the
+// Markdown and table renderers
+type view struct {
+	title  string
+	fields []field
+	tables []table
+}
+
+type field struct {
+	name  string
+	value string
+}
+
+type table struct {
+	title   string
+	headers []string
+	rows    [][]string
+}
+
+// buildViews describes the selected sections, or every section when none are selected
+func buildViews(fcs *models.FinalClarifiedSpecification, sections []string) []view {
+	if len(sections) == 0 {
+		sections = Sections
+	}
+
+	views := make([]view, 0, len(sections))
+	for _, key := range sections {
+		switch key {
+		case "metadata":
+			views = append(views, metadataView(fcs))
+		case "requirements":
+			views = append(views, requirementsView(fcs.Requirements))
+		case "architecture":
+			views = append(views, architectureView(fcs.Architecture))
+		case "data_model":
+			views = append(views, dataModelView(fcs.DataModel))
+		case "api_contracts":
+			views = append(views, apiContractsView(fcs.APIContracts))
+		case "cross_cutting":
+			views = append(views, crossCuttingView(fcs.CrossCutting))
+		case "contracts":
+			views = append(views, contractsView(fcs.Contracts))
+		case "testing_strategy":
+			views = append(views, testingView(fcs.TestingStrategy))
+		case "build_config":
+			views = append(views, buildView(fcs.BuildConfig))
+		case "file_tree":
+			views = append(views, fileTreeView(fcs.FileTree))
+		}
+	}
+	return views
+}
+
+func metadataView(fcs *models.FinalClarifiedSpecification) view {
+	m := fcs.Metadata
+	v := view{
+		title: "Metadata",
+		fields: []field{
+			{"ID", fcs.ID},
+			{"Version", fcs.Version},
+			{"Original spec ID", fcs.OriginalSpecID},
+			{"Created", m.CreatedAt.Format("2006-01-02 15:04:05")},
+			{"Hash", m.Hash},
+		},
+	}
+	if !m.UpdatedAt.IsZero() {
+		v.fields = append(v.fields, field{"Updated", m.UpdatedAt.Format("2006-01-02 15:04:05")})
+	}
+	if m.OriginalSpec == RedactedText {
+		v.fields = append(v.fields, field{"Original spec", RedactedText})
+	} else if m.OriginalSpec != "" {
+		v.fields = append(v.fields, field{"Original spec", fmt.Sprintf("%d bytes (use --format json to view)", len(m.OriginalSpec))})
+	}
+
+	if len(m.Sources) > 0 {
+		v.fields = append(v.fields, field{"Merged from", strings.Join(m.Sources, ", ")})
+	}
+
+	if len(m.Clarifications) > 0 {
+		t := table{title: "Clarifications", headers: []string{"Question", "Applied To", "Answer"}}
+		for _, c := range m.Clarifications {
+			t.rows = append(t.rows, []string{c.QuestionID, c.AppliedTo, c.Answer})
+		}
+		v.tables = append(v.tables, t)
+	}
+	if len(m.Conflicts) > 0 {
+		t := table{title: "Conflicts", headers: []string{"ID", "Path", "Values", "Resolution"}}
+		for _, c := range m.Conflicts {
+			values := make([]string, 0, len(c.Sources))
+			for _, source := range c.Sources {
+				values = append(values, fmt.Sprintf("%s: %s", source.Spec, source.Value))
+			}
+			resolution := c.Resolution
+			if !c.Resolved() {
+				resolution = "(unresolved)"
+			}
+			t.rows = append(t.rows, []string{c.ID, c.Path, strings.Join(values, "; "), resolution})
+		}
+		v.tables = append(v.tables, t)
+	}
+	return v
+}
+
+func requirementsView(r models.Requirements) view {
+	v := view{title: "Requirements"}
+
+	functional := table{title: "Functional", headers: []string{"ID", "Priority", "Category", "Description"}}
+	for _, req := range r.Functional {
+		functional.rows = append(functional.rows, []string{req.ID, req.Priority, req.Category, req.Description})
+	}
+	v.tables = append(v.tables, functional)
+
+	if len(r.NonFunctional) > 0 {
+		nonFunctional := table{title: "Non-Functional", headers: []string{"ID", "Type", "Threshold", "Description"}}
+		for _, req := range r.NonFunctional {
+			nonFunctional.rows = append(nonFunctional.rows, []string{req.ID, req.Type, req.Threshold, req.Description})
+		}
+		v.tables = append(v.tables, nonFunctional)
+	}
+	return v
+}
+
+func architectureView(a models.Architecture) view {
+	v := view{title: "Architecture", fields: []field{{"HTTP framework", a.Framework()}}}
+
+	packages := table{title: "Packages", headers: []string{"Name", "Path", "Dependencies", "Purpose"}}
+	for _, pkg := range a.Packages {
+		packages.rows = append(packages.rows, []string{pkg.Name, pkg.Path, strings.Join(pkg.Dependencies, ", "), pkg.Purpose})
+	}
+	v.tables = append(v.tables, packages)
+
+	if len(a.Dependencies) > 0 {
+		deps := table{title: "External Dependencies", headers: []string{"Name", "Version", "Purpose"}}
+		for _, dep := range a.Dependencies {
+			deps.rows = append(deps.rows, []string{dep.Name, dep.Version, dep.Purpose})
+		}
+		v.tables = append(v.tables, deps)
+	}
+
+	if len(a.Patterns) > 0 {
+		patterns := table{title: "Patterns", headers: []string{"Name", "Applies To", "Description"}}
+		for _, p := range a.Patterns {
+			patterns.rows = append(patterns.rows, []string{p.Name, strings.Join(p.AppliesTo, ", "), p.Description})
+		}
+		v.tables = append(v.tables, patterns)
+	}
+	return v
+}
+
+func dataModelView(d models.DataModel) view {
+	v := view{title: "Data Model"}
+
+	for _, entity := range d.Entities {
+		t := table{title: fmt.Sprintf("%s (package %s)", entity.Name, entity.Package), headers: []string{"Attribute", "Type"}}
+		for _, name := range sortedKeys(entity.Attributes) {
+			t.rows = append(t.rows, []string{name, entity.Attributes[name]})
+		}
+		v.tables = append(v.tables, t)
+	}
+
+	if len(d.Relationships) > 0 {
+		rels := table{title: "Relationships", headers: []string{"From", "To", "Type", "Description"}}
+		for _, r := range d.Relationships {
+			rels.rows = append(rels.rows, []string{r.From, r.To, r.Type, r.Description})
+		}
+		v.tables = append(v.tables, rels)
+	}
+
+	if len(d.Enums) > 0 {
+		enums := table{title: "Enums", headers: []string{"Name", "Package", "Type", "Values", "Description"}}
+		for _, e := range d.Enums {
+			enums.rows = append(enums.rows, []string{e.Name, e.Package, e.Backing(), strings.Join(e.Values, ", "), e.Description})
+		}
+		v.tables = append(v.tables, enums)
+	}
+	return v
+}
+
+func apiContractsView(contracts []models.APIContract) view {
+	t := table{headers: []string{"Method", "Endpoint", "Request", "Response", "Description"}}
+	for _, c := range contracts {
+		t.rows = append(t.rows, []string{c.Method, c.Endpoint, schemaFields(c.Request), schemaFields(c.Response), c.Description})
+	}
+	return view{title: "API Contracts", tables: []table{t}}
+}
+
+func crossCuttingView(c models.CrossCutting) view {
+	v := view{
+		title:  "Cross-Cutting Concerns",
+		fields: []field{{"Middleware package", c.MiddlewarePackage()}},
+	}
+
+	t := table{headers: []string{"Concern", "Middleware", "Settings", "Excluded", "Description"}}
+	for _, concern := range c.Ordered() {
+		settings := make([]string, 0, len(concern.Settings))
+		for _, key := range sortedKeys(concern.Settings) {
+			settings = append(settings, key+"="+concern.Settings[key])
+		}
+		t.rows = append(t.rows, []string{concern.Name, concern.Func(), strings.Join(settings, ", "),
+			strings.Join(concern.Exclude, ", "), concern.Description})
+	}
+	v.tables = append(v.tables, t)
+	return v
+}
+
+func contractsView(contracts []models.OutputContract) view {
+	t := table{headers: []string{"Contract", "Description"}}
+	for _, c := range contracts {
+		t.rows = append(t.rows, []string{c.String(), c.Description})
+	}
+	return view{title: "Output Contracts", tables: []table{t}}
+}
+
+func testingView(t models.TestingStrategy) view {
+	return view{
+		title: "Testing Strategy",
+		fields: []field{
+			{"Coverage target", fmt.Sprintf("%.1f%%", t.CoverageTarget)},
+			{"Unit tests", fmt.Sprintf("%t", t.UnitTests)},
+			{"Integration tests", fmt.Sprintf("%t", t.IntegrationTests)},
+			{"Frameworks", strings.Join(t.Frameworks, ", ")},
+			{"Test framework", t.Framework()},
+		},
+	}
+}
+
+func buildView(b models.BuildConfig) view {
+	return view{
+		title: "Build Configuration",
+		fields: []field{
+			{"Go version", b.GoVersion},
+			{"Output path", b.OutputPath},
+			{"Build flags", strings.Join(b.BuildFlags, " ")},
+			{"Persistence", b.PersistenceStrategy()},
+		},
+	}
+}
+
+func fileTreeView(tree *models.FileTree) view {
+	if tree == nil {
+		return view{title: "File Tree", fields: []field{{"Layout", "chosen by the planner"}}}
+	}
+
+	t := table{headers: []string{"Path", "Entities", "Purpose"}}
+	for _, dir := range tree.Directories {
+		t.rows = append(t.rows, []string{strings.TrimSuffix(dir.Path, "/") + "/", "", dir.Purpose})
+	}
+	for _, file := range tree.Files {
+		t.rows = append(t.rows, []string{file.Path, strings.Join(file.Entities, ", "), file.Purpose})
+	}
+	return view{title: "File Tree", fields: []field{{"Layout", "required"}}, tables: []table{t}}
+}
+
+// schemaFields lists contract fields as "name:type" in name order
+func schemaFields(s models.ContractSchema) string {
+	parts := make([]string, 0, len(s.Fields))
+	for _, name := range sortedKeys(s.Fields) {
+		parts = append(parts, name+":"+s.Fields[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// renderMarkdown writes each section as a heading with a field list and GitHub tables
+func renderMarkdown(w io.Writer, fcs *models.FinalClarifiedSpecification, sections []string) error {
+	var sb strings.Builder
+	if len(sections) == 0 {
+		sb.WriteString(fmt.Sprintf("# Final Clarified Specification %s\n\n", fcs.ID))
+	}
+
+	for _, v := range buildViews(fcs, sections) {
+		sb.WriteString(fmt.Sprintf("## %s\n\n", v.title))
+
+		for _, f := range v.fields {
+			if f.value != "" {
+				sb.WriteString(fmt.Sprintf("- **%s**: %s\n", f.name, markdownCell(f.value)))
+			}
+		}
+		if len(v.fields) > 0 {
+			sb.WriteString("\n")
+		}
+
+		for _, t := range v.tables {
+			if t.title != "" {
+				sb.WriteString(fmt.Sprintf("### %s\n\n", t.title))
+			}
+			if len(t.rows) == 0 {
+				sb.WriteString("_None_\n\n")
+				continue
+			}
+			sb.WriteString("| " + strings.Join(t.headers, " | ") + " |\n")
+			sb.WriteString("|" + strings.Repeat(" --- |", len(t.headers)) + "\n")
+			for _, row := range t.rows {
+				cells := make([]string, len(row))
+				for i, cell := range row {
+					cells[i] = markdownCell(cell)
+				}
+				sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, strings.TrimRight(sb.String(), "\n")+"\n")
+	return err
+}
+
+// renderTable writes each section as aligned plain-text columns
+func renderTable(w io.Writer, fcs *models.FinalClarifiedSpecification, sections []string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for i, v := range buildViews(fcs, sections) {
+		if i > 0 {
+			_, _ = fmt.Fprintln(tw)
+		}
+		_, _ = fmt.Fprintln(tw, strings.ToUpper(v.title))
+
+		for _, f := range v.fields {
+			if f.value != "" {
+				_, _ = fmt.Fprintf(tw, "%s:\t%s\n", f.name, plainCell(f.value))
+			}
+		}
+
+		for _, t := range v.tables {
+			_, _ = fmt.Fprintln(tw)
+			if t.title != "" {
+				_, _ = fmt.Fprintln(tw, t.title)
+			}
+			if len(t.rows) == 0 {
+				_, _ = fmt.Fprintln(tw, "(none)")
+				continue
+			}
+			_, _ = fmt.Fprintln(tw, strings.Join(upper(t.headers), "\t"))
+			for _, row := range t.rows {
+				cells := make([]string, len(row))
+				for i, cell := range row {
+					cells[i] = plainCell(cell)
+				}
+				_, _ = fmt.Fprintln(tw, strings.Join(cells, "\t"))
+			}
+		}
+	}
+
+	return tw.Flush()
+}
+
+func upper(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToUpper(v)
+	}
+	return out
+}
+
+// markdownCell keeps a value on one table row
+func markdownCell(s string) string {
+	return strings.ReplaceAll(plainCell(s), "|", `\|`)
+}
+
+// plainCell collapses whitespace so a value cannot break column alignment
+func plainCell(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
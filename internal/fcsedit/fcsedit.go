@@ -0,0 +1,265 @@
+// Package fcsedit makes changes to a Final Clarified Specification that keep
+// it consistent: each edit works on a copy, is refused when it leaves a
+// reference dangling or an identifier duplicated that was fine before, and
+// recomputes the metadata hash, so change detection keeps matching
+// requirements, entities and packages across regenerations.
+package fcsedit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/internal/docpath"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/schema"
+)
+
+// derivedPaths are the FCS paths an edit does not set, because every edit
+// recomputes them
+var derivedPaths = map[string]bool{
+	"metadata.hash":       true,
+	"metadata.updated_at": true,
+}
+
+// Error is an edit refused because it introduces problems into the FCS
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("edit introduces %d problems: %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// Set returns a copy of fcs with the value at path, such as
+// build_config.persistence or requirements.functional[FR-001].priority, set
+// to value. The value is taken as is where a string is expected, and
+// otherwise read as JSON or YAML.
+func Set(fcs *models.FinalClarifiedSpecification, path, value string) (*models.FinalClarifiedSpecification, error) {
+	if derivedPaths[path] {
+		return nil, fmt.Errorf("%s is recomputed by every edit and cannot be set", path)
+	}
+
+	data, err := json.Marshal(fcs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode FCS: %w", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode FCS: %w", err)
+	}
+
+	current, _ := docpath.Lookup(doc, path)
+	parsed, err := docpath.ParseValue(value, current)
+	if err != nil {
+		return nil, err
+	}
+	if err := docpath.Set(doc, path, parsed); err != nil {
+		return nil, err
+	}
+
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode FCS: %w", err)
+	}
+	return Replace(fcs, data)
+}
+
+// Replace returns the FCS document data holds, such as a copy of fcs
+// changed in an editor, checked against the FCS schema and against fcs for
+// new problems
+func Replace(fcs *models.FinalClarifiedSpecification, data []byte) (*models.FinalClarifiedSpecification, error) {
+	if err := schema.FCS().Validate(data); err != nil {
+		return nil, err
+	}
+	var edited models.FinalClarifiedSpecification
+	if err := json.Unmarshal(data, &edited); err != nil {
+		return nil, fmt.Errorf("failed to decode FCS: %w", err)
+	}
+	return finish(fcs, &edited)
+}
+
+// AddEntity returns a copy of fcs with entity added to the data model. Its
+// name must not be taken by an entity or enum, its package must be declared
+// when the architecture lists packages, and the types of its attributes must
+// be Go types or entities and enums of the data model.
+func AddEntity(fcs *models.FinalClarifiedSpecification, entity models.Entity) (*models.FinalClarifiedSpecification, error) {
+	if strings.TrimSpace(entity.Name) == "" {
+		return nil, fmt.Errorf("entity name is required")
+	}
+	for _, existing := range fcs.DataModel.Entities {
+		if existing.Name == entity.Name {
+			return nil, fmt.Errorf("entity %s already exists", entity.Name)
+		}
+	}
+	if _, ok := fcs.DataModel.FindEnum(entity.Name); ok {
+		return nil, fmt.Errorf("enum %s already exists", entity.Name)
+	}
+	if entity.Attributes == nil {
+		entity.Attributes = map[string]string{}
+	}
+
+	edited, err := clone(fcs)
+	if err != nil {
+		return nil, err
+	}
+	edited.DataModel.Entities = append(edited.DataModel.Entities, entity)
+	return finish(fcs, edited)
+}
+
+// AddFunctionalRequirement returns a copy of fcs with req added. Its ID must
+// not be used by another requirement; empty takes the next free FR- ID.
+func AddFunctionalRequirement(fcs *models.FinalClarifiedSpecification, req models.FunctionalRequirement) (*models.FinalClarifiedSpecification, error) {
+	if req.ID == "" {
+		req.ID = NextRequirementID(fcs, "FR")
+	}
+	if err := checkRequirement(fcs, req.ID, req.Description); err != nil {
+		return nil, err
+	}
+
+	edited, err := clone(fcs)
+	if err != nil {
+		return nil, err
+	}
+	edited.Requirements.Functional = append(edited.Requirements.Functional, req)
+	return finish(fcs, edited)
+}
+
+// AddNonFunctionalRequirement returns a copy of fcs with req added. Its ID
+// must not be used by another requirement; empty takes the next free NFR-
+// ID.
+func AddNonFunctionalRequirement(fcs *models.FinalClarifiedSpecification, req models.NonFunctionalRequirement) (*models.FinalClarifiedSpecification, error) {
+	if req.ID == "" {
+		req.ID = NextRequirementID(fcs, "NFR")
+	}
+	if err := checkRequirement(fcs, req.ID, req.Description); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Type) == "" {
+		return nil, fmt.Errorf("non-functional requirement %s needs a type, such as performance or security", req.ID)
+	}
+
+	edited, err := clone(fcs)
+	if err != nil {
+		return nil, err
+	}
+	edited.Requirements.NonFunctional = append(edited.Requirements.NonFunctional, req)
+	return finish(fcs, edited)
+}
+
+// NextRequirementID returns the first ID of the form <prefix>-001 that no
+// requirement uses
+func NextRequirementID(fcs *models.FinalClarifiedSpecification, prefix string) string {
+	used := requirementIDs(fcs)
+	for n := 1; ; n++ {
+		if id := fmt.Sprintf("%s-%03d", prefix, n); !used[id] {
+			return id
+		}
+	}
+}
+
+// Write writes fcs to path, replacing it only once the whole document is
+// written
+func Write(path string, fcs *models.FinalClarifiedSpecification) error {
+	data, err := json.MarshalIndent(fcs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal FCS: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write FCS file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write FCS file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write FCS file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write FCS file: %w", err)
+	}
+	return nil
+}
+
+// Problems lists what is wrong with the structure of fcs: its structural
+// problems, and an invalid file tree or build config
+func Problems(fcs *models.FinalClarifiedSpecification) []string {
+	problems := fcs.StructuralProblems()
+	if fcs.FileTree != nil {
+		if err := fcs.FileTree.Validate(fcs.Architecture.Packages); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid file tree: %v", err))
+		}
+	}
+	if err := fcs.BuildConfig.Validate(fcs.Architecture.Dependencies); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid build config: %v", err))
+	}
+	return problems
+}
+
+// finish refuses edited when it has problems original does not, and
+// otherwise stamps it with the time and its hash
+func finish(original, edited *models.FinalClarifiedSpecification) (*models.FinalClarifiedSpecification, error) {
+	existing := make(map[string]int)
+	for _, problem := range Problems(original) {
+		existing[problem]++
+	}
+	var introduced []string
+	for _, problem := range Problems(edited) {
+		if existing[problem] > 0 {
+			existing[problem]--
+			continue
+		}
+		introduced = append(introduced, problem)
+	}
+	if len(introduced) > 0 {
+		return nil, &Error{Problems: introduced}
+	}
+
+	edited.Metadata.UpdatedAt = time.Now().UTC()
+	hash, err := edited.ComputeHash()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute hash: %w", err)
+	}
+	edited.Metadata.Hash = hash
+	return edited, nil
+}
+
+func checkRequirement(fcs *models.FinalClarifiedSpecification, id, description string) error {
+	if requirementIDs(fcs)[id] {
+		return fmt.Errorf("requirement %s already exists", id)
+	}
+	if strings.TrimSpace(description) == "" {
+		return fmt.Errorf("requirement %s needs a description", id)
+	}
+	return nil
+}
+
+func requirementIDs(fcs *models.FinalClarifiedSpecification) map[string]bool {
+	ids := make(map[string]bool)
+	for _, req := range fcs.Requirements.Functional {
+		ids[req.ID] = true
+	}
+	for _, req := range fcs.Requirements.NonFunctional {
+		ids[req.ID] = true
+	}
+	return ids
+}
+
+// clone returns a deep copy of fcs
+func clone(fcs *models.FinalClarifiedSpecification) (*models.FinalClarifiedSpecification, error) {
+	data, err := json.Marshal(fcs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode FCS: %w", err)
+	}
+	var copied models.FinalClarifiedSpecification
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("failed to decode FCS: %w", err)
+	}
+	return &copied, nil
+}
@@ -0,0 +1,325 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/promptguard"
+	"gopkg.in/yaml.v3"
+)
+
+// ancillaryProblemInput is the task input through which a repair request
+// tells the model why its previous file was rejected
+const ancillaryProblemInput = "ancillary_problem"
+
+// ancillaryKnownFixInput is the task input through which a repair request
+// passes on how the fix knowledge base fixed the same problem before
+const ancillaryKnownFixInput = "ancillary_known_fix"
+
+// shellcheckTimeout bounds one shellcheck run
+const shellcheckTimeout = 30 * time.Second
+
+// ancillaryFormat describes a non-Go file the plan asks for: what the prompt
+// requires of it, and how the generated content is checked
+type ancillaryFormat struct {
+	// name is the format's name in prompts
+	name string
+
+	// requirements replace the Go-specific requirements of the prompt
+	requirements []string
+
+	// check reports why content is not valid in the format; nil when the
+	// format has no check
+	check func(ctx context.Context, content string) error
+}
+
+// ancillaryFormats maps the non-Go file types of determineFileType to their
+// formats
+var ancillaryFormats = map[string]ancillaryFormat{
+	"documentation": {
+		name: "Markdown",
+		requirements: []string{
+			"A single top-level heading naming the project or topic",
+			"Sections for what the project does, how to build and run it, and how to configure it",
+			"Commands in fenced code blocks with a language tag",
+			"Only features, commands and settings that the project context defines",
+		},
+	},
+	"yaml": {
+		name: "YAML",
+		requirements: []string{
+			"Two-space indentation, never tabs",
+			"Unique keys within each mapping",
+			"Quoted strings wherever a value could be read as a number, boolean or null",
+			"Comments for settings whose purpose is not obvious",
+		},
+		check: checkYAML,
+	},
+	"json": {
+		name: "JSON",
+		requirements: []string{
+			"A single valid JSON document",
+			"No comments or trailing commas",
+			"Two-space indentation",
+		},
+		check: checkJSON,
+	},
+	"sql": {
+		name: "SQL",
+		requirements: []string{
+			"Statements terminated with semicolons",
+			"Table and column names matching the data model",
+			"Primary keys, foreign keys, NOT NULL and unique constraints the data model implies",
+			"Idempotent DDL (IF NOT EXISTS) where the dialect supports it",
+		},
+	},
+	"proto": {
+		name: "Protocol Buffers",
+		requirements: []string{
+			"syntax = \"proto3\"; as the first statement",
+			"The package named by the file's directory under proto/, e.g. package orders.v1; for proto/orders/v1",
+			"One service with an rpc for every method its API contracts list, with their streaming",
+			"A message for every request and response, with sequential field numbers starting at 1",
+			"No go_package option: buf.gen.yaml manages it",
+		},
+		check: checkProto,
+	},
+	"shell": {
+		name: "shell script",
+		requirements: []string{
+			"A shebang line on the first line (#!/usr/bin/env bash or #!/bin/sh)",
+			"set -euo pipefail (or set -eu for sh) before the first command",
+			"Quoted variable expansions",
+			"A usage message for missing or invalid arguments",
+		},
+		check: checkShell,
+	},
+	"Makefile": {
+		name: "Makefile",
+		requirements: []string{
+			"Recipe lines indented with a single tab, never spaces",
+			"A .PHONY declaration for every target that is not a file",
+			"build, test, lint and clean targets using the go tool",
+			"Variables for the binary name and build flags",
+		},
+	},
+	"Dockerfile": {
+		name: "Dockerfile",
+		requirements: []string{
+			"A multi-stage build: a golang builder stage and a minimal runtime stage",
+			"go.mod and go.sum copied and dependencies downloaded before the source",
+			"A statically linked binary (CGO_ENABLED=0)",
+			"A non-root user in the runtime stage",
+		},
+	},
+	"text": {
+		name: "plain text",
+		requirements: []string{
+			"The conventions of the file's format, as its name implies",
+			"Content consistent with the project context",
+		},
+	},
+}
+
+// ancillaryFileType returns the type of a non-Go file from its name
+func ancillaryFileType(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".md", ".markdown":
+		return "documentation"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".json":
+		return "json"
+	case ".sql":
+		return "sql"
+	case ".sh", ".bash":
+		return "shell"
+	case ".proto":
+		return "proto"
+	}
+
+	switch {
+	case fileName == "Makefile" || fileName == "makefile" || fileName == "GNUmakefile":
+		return "Makefile"
+	case fileName == "Dockerfile" || strings.HasPrefix(fileName, "Dockerfile."):
+		return "Dockerfile"
+	default:
+		return "text"
+	}
+}
+
+// writeAncillaryRequirements writes the requirements of a non-Go file,
+// including the problem a repair request must fix
+func writeAncillaryRequirements(sb *strings.Builder, format ancillaryFormat, task models.GenerationTask) {
+	sb.WriteString(fmt.Sprintf("Generate a %s file with:\n", format.name))
+	for _, requirement := range format.requirements {
+		sb.WriteString(fmt.Sprintf("- %s\n", requirement))
+	}
+	sb.WriteString("\nThis is not a Go source file: the Go coding standards do not apply, and it must not contain Go code unless the format embeds it.\n\n")
+
+	if problem, ok := task.Inputs[ancillaryProblemInput].(string); ok && problem != "" {
+		sb.WriteString("# Previous Attempt\n\n")
+		sb.WriteString(fmt.Sprintf("A previous attempt was rejected: %s\n", promptguard.Inline(problem)))
+		if fix, ok := task.Inputs[ancillaryKnownFixInput].(string); ok && fix != "" {
+			sb.WriteString("The same problem was fixed in an earlier file by removing (-) and adding (+) these lines:\n\n")
+			sb.WriteString(promptguard.Fence(fix))
+			sb.WriteString("Apply the equivalent change to this file.\n")
+		}
+		sb.WriteString("Return a corrected file.\n\n")
+	}
+}
+
+// writeAncillaryOutputFormat writes the output instructions of a non-Go file
+func writeAncillaryOutputFormat(sb *strings.Builder, format ancillaryFormat) {
+	sb.WriteString("# Output Format\n\n")
+	sb.WriteString(fmt.Sprintf("Return ONLY the content of the %s file, no additional explanation or markdown fences.\n", format.name))
+	sb.WriteString("The file should be complete and ready to use.\n")
+}
+
+// repairAncillary checks a generated non-Go file and, when the check fails,
+// asks the model once for a corrected file. A file that still fails is kept
+// with a warning: the Go build and lint never read it, so it must not fail
+// the run. With a fix knowledge base, the request includes how the same
+// problem was fixed before, and a repair that passes is remembered.
+func (c *llmCoder) repairAncillary(ctx context.Context, task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS, code string) string {
+	format, ok := ancillaryFormats[c.determineFileType(filepath.Base(task.TargetPath))]
+	if !ok || format.check == nil {
+		return code
+	}
+	problem := format.check(ctx, code)
+	if problem == nil {
+		return code
+	}
+
+	logctx.Logger(ctx).Debug().
+		Err(problem).
+		Str("target_path", task.TargetPath).
+		Msgf("Regenerating %s file that failed its check", format.name)
+
+	retry := task
+	retry.Inputs = make(map[string]interface{}, len(task.Inputs)+1)
+	for key, value := range task.Inputs {
+		retry.Inputs[key] = value
+	}
+	retry.Inputs[ancillaryProblemInput] = problem.Error()
+	if c.knowledge != nil {
+		if known, ok := c.knowledge.Lookup(format.name, problem.Error()); ok {
+			retry.Inputs[ancillaryKnownFixInput] = known.Fix
+			logctx.Logger(ctx).Debug().
+				Str("target_path", task.TargetPath).
+				Str("signature", known.Signature).
+				Msg("Including a remembered fix in the repair request")
+		}
+	}
+
+	repaired, err := c.requestCode(ctx, c.client, retry, plan, filteredFCS)
+	if err != nil {
+		logctx.Logger(ctx).Warn().
+			Err(err).
+			Str("target_path", task.TargetPath).
+			Msgf("Failed to regenerate %s file; keeping the first attempt", format.name)
+		return code
+	}
+	if remaining := format.check(ctx, repaired); remaining != nil {
+		logctx.Logger(ctx).Warn().
+			Err(remaining).
+			Str("target_path", task.TargetPath).
+			Msgf("Generated %s file failed its check", format.name)
+		return repaired
+	}
+	if c.knowledge != nil {
+		if err := c.knowledge.Record(format.name, problem.Error(), code, repaired); err != nil {
+			logctx.Logger(ctx).Warn().Err(err).Msg("Failed to record fix")
+		}
+	}
+	return repaired
+}
+
+// checkYAML parses every document of a YAML file; the parser rejects tab
+// indentation and duplicate keys
+func checkYAML(_ context.Context, content string) error {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var document interface{}
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid YAML: %w", err)
+		}
+	}
+}
+
+// checkJSON parses a JSON file
+func checkJSON(_ context.Context, content string) error {
+	var value interface{}
+	if err := json.Unmarshal([]byte(content), &value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return nil
+}
+
+// protoSyntax matches the proto3 syntax statement
+var protoSyntax = regexp.MustCompile(`(?m)^\s*syntax\s*=\s*"proto3"\s*;`)
+
+// protoPackage matches a package statement
+var protoPackage = regexp.MustCompile(`(?m)^\s*package\s+[A-Za-z_][\w.]*\s*;`)
+
+// checkProto checks the statements buf needs to compile a .proto file; the
+// file's own grammar is left to buf lint
+func checkProto(_ context.Context, content string) error {
+	if !protoSyntax.MatchString(content) {
+		return fmt.Errorf("missing syntax = \"proto3\"; statement")
+	}
+	if !protoPackage.MatchString(content) {
+		return fmt.Errorf("missing package statement")
+	}
+	if strings.Count(content, "{") != strings.Count(content, "}") {
+		return fmt.Errorf("unbalanced braces")
+	}
+	return nil
+}
+
+// checkShell runs shellcheck over a shell script when it is installed; error
+// level findings reject the script
+func checkShell(ctx context.Context, content string) error {
+	shellcheck, err := exec.LookPath("shellcheck")
+	if err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, shellcheckTimeout)
+	defer cancel()
+
+	//nolint:gosec // G204: Subprocess launched with shellcheck - required for shell script validation
+	cmd := exec.CommandContext(ctx, shellcheck, "--format=gcc", "--severity=error", "-")
+	cmd.Stdin = strings.NewReader(content)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			// A check that cannot finish does not reject the script
+			return nil
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil
+		}
+		return fmt.Errorf("shellcheck: %s", strings.TrimSpace(out.String()))
+	}
+	return nil
+}
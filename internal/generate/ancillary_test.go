@@ -0,0 +1,108 @@
+package generate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetermineFileType_Ancillary(t *testing.T) {
+	c := &llmCoder{}
+	for name, want := range map[string]string{
+		"README.md":           "documentation",
+		"config.yaml":         "yaml",
+		"compose.yml":         "yaml",
+		"package.json":        "json",
+		"001_models.sql":      "sql",
+		"migrate.sh":          "shell",
+		"order_service.proto": "proto",
+		"Makefile":            "Makefile",
+		"Dockerfile":          "Dockerfile",
+		"Dockerfile.dev":      "Dockerfile",
+		".env.example":        "text",
+		"LICENSE":             "text",
+		"user_model.go":       "model",
+		"user_model_test.go":  "test",
+		"go.mod":              "go.mod",
+	} {
+		assert.Equal(t, want, c.determineFileType(name), name)
+	}
+}
+
+func TestBuildCodeGenerationPrompt_Ancillary(t *testing.T) {
+	c := &llmCoder{}
+	plan := &models.GenerationPlan{FileTree: models.FileTree{Files: []models.File{
+		{Path: "config/app.yaml", Purpose: "Application configuration"},
+	}}}
+	task := models.GenerationTask{TargetPath: "config/app.yaml"}
+
+	prompt := c.buildCodeGenerationPrompt(task, plan, nil)
+	assert.Contains(t, prompt, "Generate the YAML file: config/app.yaml")
+	assert.Contains(t, prompt, "Two-space indentation, never tabs")
+	assert.Contains(t, prompt, "Return ONLY the content of the YAML file")
+	assert.NotContains(t, prompt, "Go Best Practices")
+	assert.NotContains(t, prompt, "Return ONLY the Go source code")
+
+	messages := c.buildCodeGenerationPromptWithCache(task, plan, nil)
+	dynamic := messages[len(messages)-1].Content
+	assert.Contains(t, dynamic, "Generate the YAML file: config/app.yaml")
+	assert.Contains(t, dynamic, "the Go coding standards do not apply")
+	assert.NotContains(t, dynamic, "Generate a Go source file")
+
+	goPrompt := c.buildCodeGenerationPrompt(models.GenerationTask{TargetPath: "internal/app/app.go"}, plan, nil)
+	assert.Contains(t, goPrompt, "Generate a Go source file for: internal/app/app.go")
+	assert.Contains(t, goPrompt, "Go Best Practices")
+}
+
+func TestGenerateFile_RepairsInvalidYAML(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{
+		"```yaml\nserver:\n  port: 8080\n  port: 9090\n```",
+		"```yaml\nserver:\n  port: 8080\n```",
+	}}
+	coder, err := NewCoder(CoderConfig{LLMClient: client})
+	require.NoError(t, err)
+	plan := &models.GenerationPlan{}
+
+	patch, err := coder.GenerateFile(context.Background(), models.GenerationTask{ID: "config", TargetPath: "config.yaml"}, plan, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "server:\n  port: 8080\n", extractContentFromDiff(patch.Diff))
+
+	require.Len(t, client.prompts, 2)
+	assert.NotContains(t, client.prompts[0], "Previous Attempt")
+	assert.Contains(t, client.prompts[1], "# Previous Attempt")
+	assert.Contains(t, client.prompts[1], "already defined")
+}
+
+func TestGenerateFile_KeepsAncillaryFileThatStillFails(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{`{"name": "app",}`}}
+	coder, err := NewCoder(CoderConfig{LLMClient: client})
+	require.NoError(t, err)
+
+	patch, err := coder.GenerateFile(context.Background(), models.GenerationTask{ID: "pkg", TargetPath: "web/package.json"}, &models.GenerationPlan{}, nil)
+	require.NoError(t, err, "an invalid ancillary file does not fail generation")
+	assert.Equal(t, "{\"name\": \"app\",}\n", extractContentFromDiff(patch.Diff))
+	assert.Len(t, client.prompts, 2)
+}
+
+func TestAncillaryChecks(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, checkYAML(ctx, "a: 1\n---\nb: [1, 2]\n"))
+	assert.Error(t, checkYAML(ctx, "a:\n\tb: 1\n"), "tab indentation")
+	assert.Error(t, checkYAML(ctx, "a: 1\na: 2\n"), "duplicate key")
+
+	assert.NoError(t, checkJSON(ctx, `{"a": [1, 2]}`))
+	assert.Error(t, checkJSON(ctx, `{"a": 1,}`))
+
+	assert.NoError(t, checkProto(ctx, "syntax = \"proto3\";\n\npackage orders.v1;\n\nservice OrderService {\n  rpc Get(GetRequest) returns (Order);\n}\n"))
+	assert.ErrorContains(t, checkProto(ctx, "package orders.v1;\n"), "syntax")
+	assert.ErrorContains(t, checkProto(ctx, "syntax = \"proto3\";\nmessage Order {}\n"), "package")
+	assert.ErrorContains(t, checkProto(ctx, "syntax = \"proto3\";\npackage a;\nmessage Order {\n"), "unbalanced")
+
+	// Without shellcheck on PATH, shell scripts are not checked
+	t.Setenv("PATH", t.TempDir())
+	assert.NoError(t, checkShell(ctx, "echo $("))
+}
@@ -0,0 +1,123 @@
+package generate
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// entityIdentifiers maps the Go identifier of each FCS entity to the entity's
+// name, so "order item" and "order_item" both match OrderItem
+func entityIdentifiers(fcs *models.FinalClarifiedSpecification) map[string]string {
+	idents := make(map[string]string, len(fcs.DataModel.Entities))
+	for _, entity := range fcs.DataModel.Entities {
+		if ident := entityTypeName(entity.Name); ident != "" {
+			idents[ident] = entity.Name
+		}
+	}
+	return idents
+}
+
+// entityTypeName returns the Go type name of an entity, so "order item" and
+// "order_item" give OrderItem. A name without letters or digits gives "".
+func entityTypeName(name string) string {
+	var sb strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	return sb.String()
+}
+
+// projectDirs returns the directories of the project's planned files
+func projectDirs(files []string) map[string]bool {
+	dirs := make(map[string]bool, len(files))
+	for _, file := range files {
+		if dir := path.Dir(filepath.ToSlash(normalizePath(file))); dir != "." {
+			dirs[dir] = true
+		}
+	}
+	return dirs
+}
+
+// isProjectImport reports whether an import path names one of the project's
+// packages, whatever the module path
+func isProjectImport(imp string, dirs map[string]bool) bool {
+	for dir := range dirs {
+		if imp == dir || strings.HasSuffix(imp, "/"+dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// astDependencies returns the FCS entities a Go file references: types and
+// identifiers named after an entity, whether declared in the file, used
+// unqualified, or used through an import of one of the project's packages.
+// Identifiers qualified by other imports (time.Time, sql.Result) never count.
+// It reports false when the file is not Go source or does not parse.
+func astDependencies(target, content string, entities map[string]string, dirs map[string]bool) ([]string, bool) {
+	if !strings.HasSuffix(target, ".go") {
+		return nil, false
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Base(target), content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, false
+	}
+
+	// Local import name -> whether it is a project package
+	imports := make(map[string]bool, len(file.Imports))
+	for _, spec := range file.Imports {
+		imp, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(imp)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = isProjectImport(imp, dirs)
+	}
+
+	deps := make(map[string]bool)
+	for _, decl := range file.Decls {
+		ast.Inspect(decl, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ImportSpec:
+				return false
+			case *ast.SelectorExpr:
+				if x, ok := n.X.(*ast.Ident); ok {
+					if project, isImport := imports[x.Name]; isImport {
+						if name, ok := entities[n.Sel.Name]; ok && project {
+							deps[name] = true
+						}
+						return false
+					}
+				}
+			case *ast.Ident:
+				if name, ok := entities[n.Name]; ok {
+					deps[name] = true
+				}
+			}
+			return true
+		})
+	}
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, true
+}
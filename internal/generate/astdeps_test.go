@@ -0,0 +1,77 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func astDepsFCS() *models.FinalClarifiedSpecification {
+	return &models.FinalClarifiedSpecification{
+		ID:      "fcs",
+		Version: "1.0",
+		DataModel: models.DataModel{Entities: []models.Entity{
+			{Name: "User", Package: "models"},
+			{Name: "order item", Package: "models"},
+			{Name: "Invoice", Package: "billing"},
+			{Name: "Time", Package: "models"},
+		}},
+	}
+}
+
+func TestAstDependencies(t *testing.T) {
+	entities := entityIdentifiers(astDepsFCS())
+	dirs := projectDirs([]string{"internal/models/user.go", "internal/service/orders.go"})
+
+	content := `package service
+
+import (
+	"time"
+
+	"example.com/shop/internal/models"
+)
+
+// UserService mentions Invoice only in a comment
+type UserService struct {
+	created time.Time
+}
+
+func (s *UserService) Items(u *models.User) []models.OrderItem {
+	return nil
+}
+`
+	deps, ok := astDependencies("internal/service/orders.go", content, entities, dirs)
+	require.True(t, ok)
+	assert.Equal(t, []string{"User", "order item"}, deps, "time.Time is not the Time entity, comments do not count")
+
+	deps, ok = astDependencies("internal/models/user.go", "package models\n\ntype User struct{ Items []OrderItem }\n", entities, dirs)
+	require.True(t, ok)
+	assert.Equal(t, []string{"User", "order item"}, deps)
+
+	_, ok = astDependencies("internal/models/broken.go", "package models\n\nfunc {", entities, dirs)
+	assert.False(t, ok, "unparsable files fall back to the heuristic")
+	_, ok = astDependencies("README.md", "# User", entities, dirs)
+	assert.False(t, ok)
+}
+
+func TestUpdateIncrementalState_ASTDependencies(t *testing.T) {
+	fcs := astDepsFCS()
+	coder := &llmCoder{
+		contextFilter: NewContextFilter(fcs),
+		stateManager:  NewIncrementalStateManager(t.TempDir()),
+	}
+	patches := []models.Patch{
+		{TargetFile: "internal/billing/invoice.go", Diff: newFileDiff("internal/billing/invoice.go", "package billing\n\ntype Invoice struct{}\n")},
+		{TargetFile: "internal/models/user.go", Diff: newFileDiff("internal/models/user.go", "package models\n\nfunc {\n")},
+	}
+
+	require.NoError(t, coder.updateIncrementalState(fcs, patches, []string{"internal/billing/invoice.go", "internal/models/user.go"}))
+
+	state, err := coder.stateManager.GetState()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Invoice"}, state.DependencyGraph["internal/billing/invoice.go"])
+	assert.Equal(t, []string{"Invoice"}, state.GeneratedFiles["internal/billing/invoice.go"].Dependencies)
+	assert.Contains(t, state.DependencyGraph["internal/models/user.go"], "User", "unparsable file uses its filtered context")
+}
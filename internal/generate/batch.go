@@ -0,0 +1,159 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/plangraph"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// batchLevels groups the generate_file tasks by dependency level, keeping
+// plan order within a level
+func batchLevels(tasks []models.GenerationTask, plan *models.GenerationPlan) [][]models.GenerationTask {
+	graph := plangraph.Build(plan)
+
+	levels := make([][]models.GenerationTask, len(graph.Levels))
+	for _, task := range tasks {
+		if !task.WritesFile() {
+			continue
+		}
+		level := 0
+		if node, ok := graph.Nodes[task.ID]; ok {
+			level = node.Level
+		}
+		if level >= len(levels) {
+			levels = append(levels, make([][]models.GenerationTask, level+1-len(levels))...)
+		}
+		levels[level] = append(levels[level], task)
+	}
+	return levels
+}
+
+// generateBatched generates tasks as provider batch jobs, one per dependency
+// level, and returns the patches by task ID. Batch jobs are cheaper but can
+// take hours, so this suits large unattended runs. Requests that fail within a
+// job, and levels whose job fails, are left out for the caller to generate
+// interactively; only cancellation and results that do not match the
+// requests are errors.
+func (c *llmCoder) generateBatched(ctx context.Context, client llm.BatchClient, tasks []models.GenerationTask, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) (map[string]models.Patch, error) {
+	patches := make(map[string]models.Patch)
+
+	for level, levelTasks := range batchLevels(tasks, plan) {
+		if len(levelTasks) == 0 {
+			continue
+		}
+
+		// Files over the cost ceiling are left to the interactive path, which
+		// downgrades them, as are files rendered from the data model
+		var (
+			batchTasks []models.GenerationTask
+			filtered   []*FilteredFCS
+			requests   []llm.BatchRequest
+		)
+		for _, task := range levelTasks {
+			if _, _, ok := c.scaffoldFile(task, plan); ok {
+				continue
+			}
+			filteredFCS := c.filterContext(task, plan, fcs)
+			prompt := c.buildCodeGenerationPrompt(task, plan, filteredFCS)
+			if c.overCeiling(task, plan, prompt) {
+				continue
+			}
+			c.record(task.ID, task.TargetPath, prompt)
+			batchTasks = append(batchTasks, task)
+			filtered = append(filtered, filteredFCS)
+			requests = append(requests, llm.BatchRequest{ID: task.ID, Prompt: prompt})
+		}
+		if len(requests) == 0 {
+			continue
+		}
+
+		logctx.Logger(ctx).Info().
+			Int("level", level).
+			Int("files", len(requests)).
+			Msg("Submitting batch job")
+
+		started := time.Now()
+		results, err := client.GenerateBatch(ctx, requests)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("batch generation canceled: %w", ctx.Err())
+			}
+			logctx.Logger(ctx).Warn().Err(err).
+				Int("level", level).
+				Msg("Batch job failed, generating its files interactively")
+			continue
+		}
+
+		results, err = matchBatchResults(requests, results)
+		if err != nil {
+			return nil, fmt.Errorf("batch job for level %d: %w", level, err)
+		}
+
+		failed := 0
+		for i, result := range results {
+			task := batchTasks[i]
+			taskCtx := logctx.WithTaskID(ctx, task.ID)
+			if result.Err != nil {
+				failed++
+				logctx.Logger(taskCtx).Warn().Err(result.Err).
+					Str("target_path", task.TargetPath).
+					Msg("Batch request failed, generating file interactively")
+				continue
+			}
+			patch, err := c.finishFile(taskCtx, task, plan, filtered[i], c.cleanCodeResponse(result.Text))
+			if err != nil {
+				failed++
+				logctx.Logger(taskCtx).Warn().Err(err).
+					Str("target_path", task.TargetPath).
+					Msg("Batch response rejected, generating file interactively")
+				continue
+			}
+			patches[task.ID] = patch
+		}
+
+		logctx.Logger(ctx).Info().
+			Int("level", level).
+			Int("files", len(requests)-failed).
+			Int("failed", failed).
+			Dur("duration", time.Since(started)).
+			Msg("Batch job merged")
+	}
+
+	return patches, nil
+}
+
+// matchBatchResults orders results like the requests they answer, by ID, so
+// a client that returns them in another order cannot put a response in the
+// wrong file. Results for unknown requests, and requests without exactly
+// one result, are errors.
+func matchBatchResults(requests []llm.BatchRequest, results []llm.BatchResult) ([]llm.BatchResult, error) {
+	index := make(map[string]int, len(requests))
+	for i, req := range requests {
+		index[req.ID] = i
+	}
+
+	matched := make([]llm.BatchResult, len(requests))
+	seen := make([]bool, len(requests))
+	for _, result := range results {
+		i, ok := index[result.ID]
+		if !ok {
+			return nil, fmt.Errorf("result for unknown request %q", result.ID)
+		}
+		if seen[i] {
+			return nil, fmt.Errorf("more than one result for request %q", result.ID)
+		}
+		matched[i] = result
+		seen[i] = true
+	}
+	for i, req := range requests {
+		if !seen[i] {
+			return nil, fmt.Errorf("no result for request %q", req.ID)
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,129 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchLLMClient answers batch requests with a file per request ID, failing
+// the IDs in fail and leaving out those in omit, in reverse order when
+// reverse is set; interactive calls go to the scripted client
+type batchLLMClient struct {
+	scriptedLLMClient
+	fail    map[string]bool
+	omit    map[string]bool
+	reverse bool
+	batches [][]string
+}
+
+func (b *batchLLMClient) GenerateBatch(_ context.Context, requests []llm.BatchRequest) ([]llm.BatchResult, error) {
+	var ids []string
+	results := make([]llm.BatchResult, len(requests))
+	for i, req := range requests {
+		ids = append(ids, req.ID)
+		results[i] = llm.BatchResult{ID: req.ID, Text: "```go\npackage " + req.ID + "\n\nconst task = \"" + req.ID + "\"\n```"}
+		if b.fail[req.ID] {
+			results[i] = llm.BatchResult{ID: req.ID, Err: errors.New("expired")}
+		}
+	}
+	b.batches = append(b.batches, ids)
+
+	kept := results[:0]
+	for _, result := range results {
+		if !b.omit[result.ID] {
+			kept = append(kept, result)
+		}
+	}
+	if b.reverse {
+		slices.Reverse(kept)
+	}
+	return kept, nil
+}
+
+func batchPlan() *models.GenerationPlan {
+	return &models.GenerationPlan{
+		ID: "batch_plan",
+		Phases: []models.GenerationPhase{
+			{Name: "models", Tasks: []models.GenerationTask{
+				{ID: "user", Type: "generate_file", TargetPath: "internal/models/user.go"},
+				{ID: "order", Type: "generate_file", TargetPath: "internal/models/order.go"},
+			}},
+			{Name: "service", Dependencies: []string{"models"}, Tasks: []models.GenerationTask{
+				{ID: "service", Type: "generate_file", TargetPath: "internal/service/service.go"},
+			}},
+		},
+	}
+}
+
+func TestCoder_BatchMode(t *testing.T) {
+	client := &batchLLMClient{
+		scriptedLLMClient: scriptedLLMClient{responses: []string{"package models\n\nconst task = \"fallback\"\n"}},
+		fail:              map[string]bool{"order": true},
+	}
+	coder, err := NewCoder(CoderConfig{LLMClient: client, Batch: true})
+	require.NoError(t, err)
+
+	patches, err := coder.Generate(context.Background(), batchPlan(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{{"user", "order"}, {"service"}}, client.batches, "one batch per dependency level")
+	require.Len(t, patches, 3)
+	assert.Equal(t, "internal/models/user.go", patches[0].TargetFile)
+	assert.Contains(t, extractContentFromDiff(patches[0].Diff), "package models\n\nconst task = \"user\"")
+	assert.NotContains(t, extractContentFromDiff(patches[0].Diff), "```")
+	assert.Contains(t, extractContentFromDiff(patches[1].Diff), `const task = "fallback"`, "failed requests are generated interactively")
+	assert.Contains(t, extractContentFromDiff(patches[2].Diff), "package service")
+	assert.Len(t, client.prompts, 1)
+}
+
+func TestCoder_BatchModeWithoutBatchAPI(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{"package models\n"}}
+	coder, err := NewCoder(CoderConfig{LLMClient: client, Batch: true})
+	require.NoError(t, err)
+
+	patches, err := coder.Generate(context.Background(), batchPlan(), nil)
+	require.NoError(t, err)
+	assert.Len(t, patches, 3)
+	assert.Len(t, client.prompts, 3)
+}
+
+func TestCoder_BatchModeMatchesResultsByID(t *testing.T) {
+	client := &batchLLMClient{reverse: true}
+	coder, err := NewCoder(CoderConfig{LLMClient: client, Batch: true})
+	require.NoError(t, err)
+
+	patches, err := coder.Generate(context.Background(), batchPlan(), nil)
+	require.NoError(t, err)
+	require.Len(t, patches, 3)
+	assert.Equal(t, "internal/models/user.go", patches[0].TargetFile)
+	assert.Contains(t, extractContentFromDiff(patches[0].Diff), `const task = "user"`)
+	assert.Equal(t, "internal/models/order.go", patches[1].TargetFile)
+	assert.Contains(t, extractContentFromDiff(patches[1].Diff), `const task = "order"`)
+
+	client = &batchLLMClient{omit: map[string]bool{"order": true}}
+	coder, err = NewCoder(CoderConfig{LLMClient: client, Batch: true})
+	require.NoError(t, err)
+
+	_, err = coder.Generate(context.Background(), batchPlan(), nil)
+	assert.ErrorContains(t, err, `no result for request "order"`)
+}
+
+func TestMatchBatchResults(t *testing.T) {
+	requests := []llm.BatchRequest{{ID: "a"}, {ID: "b"}}
+
+	matched, err := matchBatchResults(requests, []llm.BatchResult{{ID: "b", Text: "B"}, {ID: "a", Text: "A"}})
+	require.NoError(t, err)
+	assert.Equal(t, []llm.BatchResult{{ID: "a", Text: "A"}, {ID: "b", Text: "B"}}, matched)
+
+	_, err = matchBatchResults(requests, []llm.BatchResult{{ID: "a"}, {ID: "b"}, {ID: "c"}})
+	assert.ErrorContains(t, err, `unknown request "c"`)
+	_, err = matchBatchResults(requests, []llm.BatchResult{{ID: "a"}, {ID: "a"}})
+	assert.ErrorContains(t, err, `more than one result for request "a"`)
+}
@@ -0,0 +1,159 @@
+package generate
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// AnalyzeCodebase parses the Go module in dir for brownfield generation: its
+// files, and for each package its name, the module's packages it imports and
+// its exported struct types. Hidden, testdata and vendor directories and
+// nested modules are skipped.
+func AnalyzeCodebase(dir string) (*models.Codebase, error) {
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, fmt.Errorf("brownfield generation needs an existing Go module: %w", err)
+	}
+	codebase := &models.Codebase{
+		ModulePath: parseModulePath(string(goMod)),
+		GoVersion:  parseGoVersion(string(goMod)),
+	}
+	if codebase.ModulePath == "" {
+		return nil, fmt.Errorf("no module path in %s", filepath.Join(dir, "go.mod"))
+	}
+
+	packages := make(map[string]*models.CodebasePackage)
+	fset := token.NewFileSet()
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != dir {
+				if name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+					return filepath.SkipDir
+				}
+				if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		codebase.Files = append(codebase.Files, rel)
+		if !strings.HasSuffix(rel, ".go") || strings.HasSuffix(rel, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, p, nil, parser.SkipObjectResolution)
+		if err != nil {
+			// A file that does not parse still counts as existing
+			return nil
+		}
+		pkgDir := path.Dir(rel)
+		pkg := packages[pkgDir]
+		if pkg == nil {
+			pkg = &models.CodebasePackage{Dir: pkgDir, Name: file.Name.Name}
+			packages[pkgDir] = pkg
+		}
+		pkg.Files = append(pkg.Files, rel)
+		pkg.Imports = appendModuleImports(pkg.Imports, file, codebase.ModulePath)
+		pkg.Types = append(pkg.Types, structTypes(file)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze %s: %w", dir, err)
+	}
+
+	sort.Strings(codebase.Files)
+	for _, pkg := range packages {
+		sort.Strings(pkg.Imports)
+		sort.Slice(pkg.Types, func(i, j int) bool { return pkg.Types[i].Name < pkg.Types[j].Name })
+		codebase.Packages = append(codebase.Packages, *pkg)
+	}
+	sort.Slice(codebase.Packages, func(i, j int) bool { return codebase.Packages[i].Dir < codebase.Packages[j].Dir })
+	return codebase, nil
+}
+
+// parseGoVersion returns the version of the go directive in a go.mod file
+func parseGoVersion(goMod string) string {
+	for _, line := range strings.Split(goMod, "\n") {
+		fields := strings.Fields(stripModComment(line))
+		if len(fields) == 2 && fields[0] == "go" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// appendModuleImports adds the directories of the module's packages a file
+// imports to dirs, once each
+func appendModuleImports(dirs []string, file *ast.File, modulePath string) []string {
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		dir, ok := packageDir(importPath, modulePath)
+		if !ok || slices.Contains(dirs, dir) {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// structTypes returns the exported struct types a file declares with their
+// exported fields
+func structTypes(file *ast.File) []models.CodebaseType {
+	var structs []models.CodebaseType
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			st, ok := typeSpec.Type.(*ast.StructType)
+			if !ok || !typeSpec.Name.IsExported() {
+				continue
+			}
+			typ := models.CodebaseType{Name: typeSpec.Name.Name}
+			for _, field := range st.Fields.List {
+				for _, name := range field.Names {
+					if !name.IsExported() {
+						continue
+					}
+					if typ.Fields == nil {
+						typ.Fields = make(map[string]string)
+					}
+					typ.Fields[name.Name] = types.ExprString(field.Type)
+				}
+			}
+			structs = append(structs, typ)
+		}
+	}
+	return structs
+}
@@ -0,0 +1,105 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProjectFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0o600))
+	}
+}
+
+func TestAnalyzeCodebase(t *testing.T) {
+	root := t.TempDir()
+	writeProjectFiles(t, root, map[string]string{
+		"go.mod":                       "module example.com/shop // the shop\n\ngo 1.22\n",
+		"Makefile":                     "build:\n\tgo build ./...\n",
+		"cmd/shop/main.go":             "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/shop/internal/store\"\n)\n\nfunc main() { fmt.Println(store.Item{}) }\n",
+		"internal/store/store.go":      "package store\n\ntype Item struct {\n\tID    string\n\tPrice float64\n\tTags  []string\n\tnote  string\n}\n\ntype item struct{}\n\ntype Kind int\n",
+		"internal/store/store_test.go": "package store\n\ntype Fixture struct{}\n",
+		"internal/store/broken.go":     "package store\n\nfunc {\n",
+		"tools/go.mod":                 "module example.com/shop/tools\n",
+		"tools/tools.go":               "package tools\n",
+		".gocreator/state.json":        "{}\n",
+		"vendor/x/x.go":                "package x\n",
+	})
+
+	codebase, err := AnalyzeCodebase(root)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/shop", codebase.ModulePath)
+	assert.Equal(t, "1.22", codebase.GoVersion)
+	assert.Equal(t, []string{
+		"Makefile",
+		"cmd/shop/main.go",
+		"go.mod",
+		"internal/store/broken.go",
+		"internal/store/store.go",
+		"internal/store/store_test.go",
+	}, codebase.Files)
+
+	require.Len(t, codebase.Packages, 2)
+	assert.Equal(t, models.CodebasePackage{
+		Dir:     "cmd/shop",
+		Name:    "main",
+		Files:   []string{"cmd/shop/main.go"},
+		Imports: []string{"internal/store"},
+	}, codebase.Packages[0])
+	assert.Equal(t, models.CodebasePackage{
+		Dir:   "internal/store",
+		Name:  "store",
+		Files: []string{"internal/store/store.go"},
+		Types: []models.CodebaseType{{
+			Name:   "Item",
+			Fields: map[string]string{"ID": "string", "Price": "float64", "Tags": "[]string"},
+		}},
+	}, codebase.Packages[1], "files that do not parse and test files are not analyzed")
+
+	pseudo := codebase.PseudoFCS()
+	assert.Equal(t, []models.Package{
+		{Name: "main", Path: "cmd/shop", Dependencies: []string{"internal/store"}},
+		{Name: "store", Path: "internal/store"},
+	}, pseudo.Architecture.Packages)
+	require.Len(t, pseudo.DataModel.Entities, 1)
+	assert.Equal(t, "store", pseudo.DataModel.Entities[0].Package)
+
+	assert.True(t, codebase.Has("./internal/store/store.go"))
+	assert.False(t, codebase.Has("internal/store/orders.go"))
+	assert.True(t, codebase.FitsLayout("internal/api"))
+	assert.False(t, codebase.FitsLayout("api"))
+}
+
+func TestAnalyzeCodebase_NoModule(t *testing.T) {
+	_, err := AnalyzeCodebase(t.TempDir())
+	assert.ErrorContains(t, err, "needs an existing Go module")
+}
+
+func TestCodeGenerationPrompt_ApplyPatch(t *testing.T) {
+	root := t.TempDir()
+	writeProjectFiles(t, root, map[string]string{
+		"internal/store/store.go": "package store\n\ntype Item struct{}\n",
+	})
+	c := &llmCoder{outputDir: root}
+	plan := &models.GenerationPlan{}
+
+	patch := models.GenerationTask{ID: "store", Type: "apply_patch", TargetPath: "internal/store/store.go"}
+	assert.Contains(t, c.buildCodeGenerationPrompt(patch, plan, nil), "# Current File")
+	assert.Contains(t, c.buildCodeGenerationPrompt(patch, plan, nil), "type Item struct{}")
+
+	var cached string
+	for _, msg := range c.buildCodeGenerationPromptWithCache(patch, plan, nil) {
+		cached += msg.Content
+	}
+	assert.Contains(t, cached, "type Item struct{}")
+
+	create := models.GenerationTask{ID: "orders", Type: "generate_file", TargetPath: "internal/store/orders.go"}
+	assert.NotContains(t, c.buildCodeGenerationPrompt(create, plan, nil), "# Current File")
+}
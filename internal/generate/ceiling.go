@@ -0,0 +1,184 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+const (
+	// costCeilingAttempts is how many requests a file may take in the worst
+	// case: the first one plus the CLI client's three retries
+	costCeilingAttempts = 4
+
+	// costCeilingTokensPerLine converts a file's planned lines to output tokens
+	costCeilingTokensPerLine = 12
+)
+
+// DowngradeReporter is implemented by coders that cap the cost of each file
+type DowngradeReporter interface {
+	// Downgrades returns the files generated below the primary model so far
+	Downgrades() []models.FileDowngrade
+}
+
+// projectFileCost returns the worst-case cost of generating a file with
+// client: every attempt of the generation request, plus the critic and
+// ensemble passes when the file's path selects them, each priced at the full
+// prompt and the planned output
+func (c *llmCoder) projectFileCost(client llm.Client, task models.GenerationTask, plan *models.GenerationPlan, prompt string) float64 {
+	inputTokens := countTokens(client, prompt)
+	outputTokens := int64(estimateSourceOutputTokens)
+	if lines := plannedLines(plan, task.TargetPath); lines > 0 {
+		outputTokens = int64(lines * costCeilingTokensPerLine)
+	}
+
+	pricing := llm.PricingFor(llm.Provider(client.Provider()), client.Model())
+	cost := pricing.Cost(inputTokens, outputTokens)
+	if client == c.client {
+		if c.critic != nil && len(c.critic.matchClasses(task.TargetPath, "")) > 0 {
+			// The review prompt carries the generated code
+			cost += pricing.Cost(inputTokens+outputTokens, outputTokens)
+		}
+		if c.ensemble != nil && len(c.ensemble.matchClasses(task.TargetPath, "")) > 0 {
+			// A competing candidate, and the adjudication reading both
+			second := c.ensemble.client
+			cost += llm.PricingFor(llm.Provider(second.Provider()), second.Model()).Cost(inputTokens, outputTokens)
+			cost += pricing.Cost(2*outputTokens, outputTokens/4)
+		}
+	}
+	return cost * costCeilingAttempts
+}
+
+// plannedLines returns the planner's size estimate for a file, or 0
+func plannedLines(plan *models.GenerationPlan, target string) int {
+	if plan == nil {
+		return 0
+	}
+	target = filepath.Clean(target)
+	for _, file := range plan.FileTree.Files {
+		if filepath.Clean(file.Path) == target {
+			return file.EstimatedLines
+		}
+	}
+	return 0
+}
+
+// overCeiling reports whether the primary model's projected cost for a file
+// exceeds the per-file ceiling
+func (c *llmCoder) overCeiling(task models.GenerationTask, plan *models.GenerationPlan, prompt string) bool {
+	return c.costCeiling > 0 && c.projectFileCost(c.client, task, plan, prompt) > c.costCeiling
+}
+
+// chooseClient picks the model for a file under the per-file cost ceiling:
+// the primary model if it fits, otherwise the downgrade model if that fits.
+// Files that do not use the primary model are recorded and returned as a
+// downgrade; a nil client means neither fits and the file is written as a stub.
+func (c *llmCoder) chooseClient(ctx context.Context, task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS) (llm.Client, *models.FileDowngrade) {
+	if c.costCeiling <= 0 {
+		return c.client, nil
+	}
+
+	prompt := c.buildCodeGenerationPrompt(task, plan, filteredFCS)
+	projected := c.projectFileCost(c.client, task, plan, prompt)
+	if projected <= c.costCeiling {
+		return c.client, nil
+	}
+
+	var client llm.Client
+	to := models.DowngradeStub
+	if c.downgradeClient != nil && c.projectFileCost(c.downgradeClient, task, plan, prompt) <= c.costCeiling {
+		client = c.downgradeClient
+		to = modelName(client)
+	}
+
+	downgrade := models.FileDowngrade{
+		Path:         filepath.ToSlash(task.TargetPath),
+		From:         modelName(c.client),
+		To:           to,
+		ProjectedUSD: projected,
+		CeilingUSD:   c.costCeiling,
+	}
+	c.downgradesMu.Lock()
+	c.downgrades = append(c.downgrades, downgrade)
+	c.downgradesMu.Unlock()
+
+	logctx.Logger(ctx).Warn().
+		Str("file", downgrade.Path).
+		Str("to", to).
+		Float64("projected_usd", projected).
+		Float64("ceiling_usd", c.costCeiling).
+		Msg("Projected file cost exceeds the per-file ceiling, downgrading")
+	if c.audit != nil {
+		rationale := fmt.Sprintf("Projected cost $%.2f exceeds the per-file ceiling of $%.2f, generating with %s", projected, c.costCeiling, to)
+		if err := c.audit.LogDecision(ctx, models.DecisionLog{
+			LogEntry: models.LogEntry{
+				Level:     "warn",
+				Component: "generate",
+				Operation: "cost_ceiling",
+				Message:   rationale,
+				Context: map[string]interface{}{
+					"path":          downgrade.Path,
+					"from":          downgrade.From,
+					"to":            to,
+					"projected_usd": projected,
+					"ceiling_usd":   c.costCeiling,
+				},
+			},
+			Decision:  "file_downgraded",
+			Rationale: rationale,
+		}); err != nil {
+			logctx.Logger(ctx).Warn().Err(err).Str("file", downgrade.Path).Msg("Failed to record downgrade decision")
+		}
+	}
+
+	return client, &downgrade
+}
+
+// Downgrades returns the files generated below the primary model so far
+func (c *llmCoder) Downgrades() []models.FileDowngrade {
+	c.downgradesMu.Lock()
+	defer c.downgradesMu.Unlock()
+	return append([]models.FileDowngrade(nil), c.downgrades...)
+}
+
+// modelName names a client's model as provider/model
+func modelName(client llm.Client) string {
+	return client.Provider() + "/" + client.Model()
+}
+
+// stubFile returns a compilable placeholder for a file whose projected cost
+// exceeds the ceiling with every configured model
+func stubFile(downgrade models.FileDowngrade) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("// TODO: implement %s. It was not generated because its projected\n", path.Base(downgrade.Path)))
+	sb.WriteString(fmt.Sprintf("// cost of $%.2f exceeded the per-file ceiling of $%.2f (llm.file_cost_ceiling).\n\n", downgrade.ProjectedUSD, downgrade.CeilingUSD))
+	sb.WriteString(fmt.Sprintf("package %s\n", stubPackageName(downgrade.Path)))
+	return sb.String()
+}
+
+// stubPackageName guesses a stub's package: main under cmd/ and at the
+// project root, otherwise the directory name made a valid identifier
+func stubPackageName(target string) string {
+	dir := path.Dir(path.Clean(filepath.ToSlash(target)))
+	if dir == "." || dir == "cmd" || strings.HasPrefix(dir, "cmd/") {
+		return "main"
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, path.Base(dir))
+	if unicode.IsDigit(rune(name[0])) {
+		name = "pkg" + name
+	}
+	return name
+}
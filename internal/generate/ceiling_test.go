@@ -0,0 +1,90 @@
+package generate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pricedLLMClient is a scriptedLLMClient reporting a model with a known price
+type pricedLLMClient struct {
+	scriptedLLMClient
+	provider, model string
+}
+
+func (p *pricedLLMClient) Provider() string { return p.provider }
+func (p *pricedLLMClient) Model() string    { return p.model }
+
+func TestGenerateFile_CostCeiling(t *testing.T) {
+	task := models.GenerationTask{ID: "t1", Type: "generate_file", TargetPath: "internal/big-store/store.go"}
+	plan := &models.GenerationPlan{FileTree: models.FileTree{Files: []models.File{
+		{Path: "internal/big-store/store.go", EstimatedLines: 200},
+	}}}
+
+	tests := []struct {
+		name      string
+		ceiling   float64
+		wantTo    string // Empty when the primary model generates the file
+		wantCode  string
+		wantCalls [2]int // Requests to the primary and downgrade models
+	}{
+		{"under the ceiling", 5, "", "package store // opus\n", [2]int{1, 0}},
+		{"downgraded to the cheaper model", 0.10, "openai/gpt-4o-mini", "package store // mini\n", [2]int{0, 1}},
+		{"stub when no model fits", 0.001, models.DowngradeStub, "", [2]int{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &pricedLLMClient{scriptedLLMClient: scriptedLLMClient{responses: []string{"package store // opus"}}, provider: "anthropic", model: "claude-opus-4-1"}
+			cheap := &pricedLLMClient{scriptedLLMClient: scriptedLLMClient{responses: []string{"package store // mini"}}, provider: "openai", model: "gpt-4o-mini"}
+			audit := fsops.NewMemoryLogger()
+
+			coder, err := NewCoder(CoderConfig{LLMClient: primary, DowngradeClient: cheap, FileCostCeiling: tt.ceiling, AuditLogger: audit})
+			require.NoError(t, err)
+
+			patch, err := coder.GenerateFile(context.Background(), task, plan, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCalls, [2]int{len(primary.prompts), len(cheap.prompts)})
+
+			code := extractContentFromDiff(patch.Diff)
+			downgrades := coder.(DowngradeReporter).Downgrades()
+			if tt.wantTo == "" {
+				assert.Empty(t, downgrades)
+				assert.Equal(t, tt.wantCode, code)
+				return
+			}
+
+			require.Len(t, downgrades, 1)
+			downgrade := downgrades[0]
+			assert.Equal(t, "internal/big-store/store.go", downgrade.Path)
+			assert.Equal(t, "anthropic/claude-opus-4-1", downgrade.From)
+			assert.Equal(t, tt.wantTo, downgrade.To)
+			assert.Greater(t, downgrade.ProjectedUSD, tt.ceiling)
+			assert.Equal(t, tt.ceiling, downgrade.CeilingUSD)
+			assert.Len(t, audit.GetEntries(), 1)
+
+			if tt.wantTo == models.DowngradeStub {
+				assert.Contains(t, code, "// TODO: implement store.go.")
+				assert.Contains(t, code, "\n\npackage big_store\n", "the stub compiles and has no package doc")
+			} else {
+				assert.Equal(t, tt.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestStubPackageName(t *testing.T) {
+	assert.Equal(t, "main", stubPackageName("cmd/app/main.go"))
+	assert.Equal(t, "main", stubPackageName("main.go"))
+	assert.Equal(t, "store", stubPackageName("internal/store/store.go"))
+	assert.Equal(t, "pkg2fa", stubPackageName("internal/2fa/totp.go"))
+}
+
+func TestNewCoder_NegativeCostCeiling(t *testing.T) {
+	_, err := NewCoder(CoderConfig{LLMClient: &scriptedLLMClient{}, FileCostCeiling: -1})
+	assert.Error(t, err)
+}
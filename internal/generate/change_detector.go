@@ -2,7 +2,6 @@ package generate
 
 import (
 	"encoding/json"
-	"fmt"
 	"strings"
 
 	"github.com/dshills/gocreator/internal/models"
@@ -29,6 +28,7 @@ type FCSChanges struct {
 	DeletedAPIContracts               []string
 	ArchitectureChanged               bool
 	BuildConfigChanged                bool
+	CrossCuttingChanged               bool
 }
 
 // ChangeDetector detects changes between FCS versions
@@ -80,6 +80,9 @@ func (cd *ChangeDetector) DetectChanges(oldFCS, newFCS *models.FinalClarifiedSpe
 	// Detect build config changes (NEW)
 	changes.BuildConfigChanged = cd.hasBuildConfigChanged(oldFCS, newFCS)
 
+	// Detect cross-cutting concern changes, which touch every handler
+	changes.CrossCuttingChanged = cd.hasCrossCuttingChanged(oldFCS, newFCS)
+
 	// Set HasChanges flag
 	changes.HasChanges = len(changes.AddedRequirements) > 0 ||
 		len(changes.ModifiedRequirements) > 0 ||
@@ -96,7 +99,8 @@ func (cd *ChangeDetector) DetectChanges(oldFCS, newFCS *models.FinalClarifiedSpe
 		len(changes.AddedAPIContracts) > 0 ||
 		len(changes.ModifiedAPIContracts) > 0 ||
 		changes.ArchitectureChanged ||
-		changes.BuildConfigChanged
+		changes.BuildConfigChanged ||
+		changes.CrossCuttingChanged
 
 	log.Debug().
 		Int("added_entities", len(changes.AddedEntities)).
@@ -104,6 +108,7 @@ func (cd *ChangeDetector) DetectChanges(oldFCS, newFCS *models.FinalClarifiedSpe
 		Int("deleted_entities", len(changes.DeletedEntities)).
 		Bool("architecture_changed", changes.ArchitectureChanged).
 		Bool("build_config_changed", changes.BuildConfigChanged).
+		Bool("cross_cutting_changed", changes.CrossCuttingChanged).
 		Msg("Detected FCS changes")
 
 	return changes, nil
@@ -367,15 +372,13 @@ func (cd *ChangeDetector) detectAPIChanges(
 	oldAPIs := make(map[string]*models.APIContract)
 	for i := range oldFCS.APIContracts {
 		api := &oldFCS.APIContracts[i]
-		key := fmt.Sprintf("%s %s", api.Method, api.Endpoint)
-		oldAPIs[key] = api
+		oldAPIs[api.Key()] = api
 	}
 
 	newAPIs := make(map[string]*models.APIContract)
 	for i := range newFCS.APIContracts {
 		api := &newFCS.APIContracts[i]
-		key := fmt.Sprintf("%s %s", api.Method, api.Endpoint)
-		newAPIs[key] = api
+		newAPIs[api.Key()] = api
 	}
 
 	// Find added and modified APIs
@@ -442,6 +445,21 @@ func (cd *ChangeDetector) hasBuildConfigChanged(old, updated *models.FinalClarif
 	return string(oldJSON) != string(newJSON)
 }
 
+// hasCrossCuttingChanged checks if the cross-cutting concerns changed
+func (cd *ChangeDetector) hasCrossCuttingChanged(old, updated *models.FinalClarifiedSpecification) bool {
+	oldJSON, err := json.Marshal(old.CrossCutting)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to marshal old cross-cutting concerns, assuming changed")
+		return true
+	}
+	newJSON, err := json.Marshal(updated.CrossCutting)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to marshal new cross-cutting concerns, assuming changed")
+		return true
+	}
+	return string(oldJSON) != string(newJSON)
+}
+
 // getAllEntityNames returns all entity names from FCS
 func (cd *ChangeDetector) getAllEntityNames(fcs *models.FinalClarifiedSpecification) []string {
 	names := make([]string, len(fcs.DataModel.Entities))
@@ -455,7 +473,7 @@ func (cd *ChangeDetector) getAllEntityNames(fcs *models.FinalClarifiedSpecificat
 func (cd *ChangeDetector) getAllAPIEndpoints(fcs *models.FinalClarifiedSpecification) []string {
 	endpoints := make([]string, len(fcs.APIContracts))
 	for i, api := range fcs.APIContracts {
-		endpoints[i] = fmt.Sprintf("%s %s", api.Method, api.Endpoint)
+		endpoints[i] = api.Key()
 	}
 	return endpoints
 }
@@ -477,9 +495,9 @@ func (afc *AffectedFilesCalculator) CalculateAffectedFiles(
 	changes *FCSChanges,
 	allFiles []string,
 ) []string {
-	// If architecture or build config changed, regenerate everything
-	if changes.ArchitectureChanged || changes.BuildConfigChanged {
-		log.Debug().Msg("Architecture or build config changed, regenerating all files")
+	// If architecture, build config or cross-cutting concerns changed, regenerate everything
+	if changes.ArchitectureChanged || changes.BuildConfigChanged || changes.CrossCuttingChanged {
+		log.Debug().Msg("Architecture, build config or cross-cutting concerns changed, regenerating all files")
 		return allFiles
 	}
 
@@ -0,0 +1,186 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// checkProbePrompt is the minimal request sent to confirm the model responds
+const checkProbePrompt = "Reply with the single word OK."
+
+// CheckConfig configures a read-only check of a generation run
+type CheckConfig struct {
+	LLMClient llm.Client
+
+	// PlannerClient plans instead of LLMClient, as for EngineConfig (optional)
+	PlannerClient llm.Client
+
+	// Planner settings, as for EngineConfig
+	PlanLimits     models.PlanLimits
+	MaxReplans     int
+	FileLayout     models.FileLayout
+	ProtectedPaths models.ProtectedPaths
+	Codebase       *models.Codebase
+	Preamble       string
+	MigrationsDir  string
+
+	// PromptTemplates overrides sections of the coder prompt (optional)
+	PromptTemplates *PromptTemplates
+
+	// Style is the house style of coder prompts (optional)
+	Style models.StyleGuide
+
+	// OutputDir holds the existing files apply_patch prompts quote (optional)
+	OutputDir string
+
+	// MaxTokens is the output budget of each request; a prompt that leaves
+	// no room for it in the model's context window, even with its context
+	// tightened or its file split, is a problem
+	MaxTokens int
+
+	// Probe sends a minimal request to confirm the model is reachable with
+	// the configured credentials
+	Probe bool
+}
+
+// CheckResult reports what a check found
+type CheckResult struct {
+	Plan *models.GenerationPlan
+
+	// Prompts is the number of source file prompts built
+	Prompts int
+
+	// LargestPrompt is the file with the largest prompt, and its estimated size
+	LargestPrompt       string
+	LargestPromptTokens int64
+
+	// ContextWindow is the model's context window in tokens, 0 when unknown
+	ContextWindow int64
+
+	// Probed reports whether the probe request succeeded
+	Probed bool
+
+	// Problems lists everything that would make the run fail
+	Problems []string
+}
+
+// Passed reports whether the check found no problems
+func (r *CheckResult) Passed() bool {
+	return len(r.Problems) == 0
+}
+
+// Check validates a generation run without writing anything: it validates
+// the FCS, plans the run (the planning request is the only LLM call, apart
+// from the optional probe), and filters the context and builds the prompt of
+// every source file, as the code phase would. Problems that would make the
+// run fail are collected in the result; an error means the check itself
+// could not run.
+func Check(ctx context.Context, cfg CheckConfig, fcs *models.FinalClarifiedSpecification) (*CheckResult, error) {
+	if cfg.LLMClient == nil {
+		return nil, fmt.Errorf("LLM client is required")
+	}
+	if fcs == nil {
+		return nil, fmt.Errorf("FCS is required")
+	}
+
+	result := &CheckResult{ContextWindow: llm.ContextWindowFor(cfg.LLMClient.Model())}
+
+	if err := fcs.Validate(); err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("FCS is invalid: %v", err))
+		return result, nil
+	}
+
+	if cfg.Probe {
+		if _, err := cfg.LLMClient.Generate(ctx, checkProbePrompt); err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("model %s did not respond: %v", modelName(cfg.LLMClient), err))
+			return result, nil
+		}
+		result.Probed = true
+	}
+
+	planner, err := NewPlanner(PlannerConfig{
+		LLMClient:  phaseClient(cfg.PlannerClient, cfg.LLMClient),
+		Limits:     cfg.PlanLimits,
+		Layout:     cfg.FileLayout,
+		Protected:  cfg.ProtectedPaths,
+		Codebase:   cfg.Codebase,
+		MaxReplans: cfg.MaxReplans,
+		Preamble:   cfg.Preamble,
+
+		MigrationsDir: cfg.MigrationsDir,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create planner: %w", err)
+	}
+	plan, err := planner.Plan(ctx, fcs)
+	if err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("planning failed: %v", err))
+		return result, nil
+	}
+	result.Plan = plan
+
+	coder, err := NewCoder(CoderConfig{
+		LLMClient:       cfg.LLMClient,
+		OutputDir:       cfg.OutputDir,
+		Preamble:        cfg.Preamble,
+		MaxTokens:       cfg.MaxTokens,
+		PromptTemplates: cfg.PromptTemplates,
+		Style:           cfg.Style,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create coder: %w", err)
+	}
+	c := coder.(*llmCoder)
+	c.SetFCS(fcs)
+
+	for _, task := range c.getAllTasks(plan) {
+		if !task.WritesFile() {
+			continue
+		}
+		if task.TargetPath == "" {
+			result.Problems = append(result.Problems, fmt.Sprintf("task %s has no target path", task.ID))
+			continue
+		}
+
+		filteredFCS := c.filterContext(task, plan, fcs)
+		tokens := c.promptTokens(c.client, task, plan, filteredFCS)
+		result.Prompts++
+		if tokens > result.LargestPromptTokens {
+			result.LargestPrompt = task.TargetPath
+			result.LargestPromptTokens = tokens
+		}
+		if err := c.checkWindow(c.client, task, plan, filteredFCS); err != nil {
+			result.Problems = append(result.Problems, err.Error())
+		}
+	}
+
+	logctx.Logger(ctx).Info().
+		Str("plan_id", plan.ID).
+		Int("prompts", result.Prompts).
+		Int64("largest_prompt_tokens", result.LargestPromptTokens).
+		Int("problems", len(result.Problems)).
+		Msg("Generation check completed")
+
+	return result, nil
+}
+
+// promptTokens estimates the size of the request that generates a file,
+// built the way requestCode builds it for client
+func (c *llmCoder) promptTokens(client llm.Client, task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS) int64 {
+	var prompt string
+	if _, ok := client.(llm.CacheableClient); ok {
+		var sb strings.Builder
+		for _, msg := range c.buildCodeGenerationPromptWithCache(task, plan, filteredFCS) {
+			sb.WriteString(msg.Content)
+		}
+		prompt = sb.String()
+	} else {
+		prompt = c.buildCodeGenerationPrompt(task, plan, filteredFCS)
+	}
+	return countTokens(client, prompt)
+}
@@ -0,0 +1,64 @@
+package generate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const checkPlanResponse = `{
+	"file_tree": {"root": ".", "files": [{"path": "cmd/app/main.go"}, {"path": "internal/store/store.go"}]},
+	"phases": [{"name": "code", "order": 1, "tasks": [
+		{"id": "main", "type": "generate_file", "target_path": "cmd/app/main.go"},
+		{"id": "store", "type": "generate_file", "target_path": "internal/store/store.go"},
+		{"id": "build", "type": "run_command"}
+	]}]
+}`
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		model        string
+		maxTokens    int
+		wantProblems int
+	}{
+		{"prompts fit the context window", "claude-sonnet-4-5", 4096, 0},
+		{"output budget overflows the context window", "gpt-4", 8000, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &pricedLLMClient{scriptedLLMClient: scriptedLLMClient{responses: []string{"OK", checkPlanResponse}}, provider: "mock", model: tt.model}
+			fcs := &models.FinalClarifiedSpecification{ID: "fcs-1"}
+
+			result, err := Check(context.Background(), CheckConfig{LLMClient: client, MaxTokens: tt.maxTokens, Probe: true}, fcs)
+			require.NoError(t, err)
+			assert.Len(t, result.Problems, tt.wantProblems)
+			assert.Equal(t, tt.wantProblems == 0, result.Passed())
+
+			assert.True(t, result.Probed)
+			require.Len(t, client.prompts, 2, "the probe and the planning request are the only LLM calls")
+			assert.Equal(t, checkProbePrompt, client.prompts[0])
+
+			require.NotNil(t, result.Plan)
+			assert.Equal(t, 2, result.Prompts, "only generate_file tasks have prompts")
+			assert.Positive(t, result.LargestPromptTokens)
+			assert.NotEmpty(t, result.LargestPrompt)
+		})
+	}
+}
+
+func TestCheck_PlanningFails(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{"not a plan"}}
+
+	result, err := Check(context.Background(), CheckConfig{LLMClient: client}, &models.FinalClarifiedSpecification{ID: "fcs-1"})
+	require.NoError(t, err)
+	assert.False(t, result.Passed())
+	require.Len(t, result.Problems, 1)
+	assert.Contains(t, result.Problems[0], "planning failed")
+	assert.Nil(t, result.Plan)
+	assert.Len(t, client.prompts, 1, "no probe unless requested")
+}
@@ -0,0 +1,129 @@
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/langgraph-go/graph/store"
+)
+
+// checkpointName is the file holding the checkpoint of a run
+const checkpointName = "checkpoint.json"
+
+// generationNodes lists the workflow nodes in the order they run
+var generationNodes = []string{
+	"start", "analyze_fcs", "create_plan", "approve_plan", "generate",
+	"generate_docs", "apply_patches", "end",
+}
+
+// RunCheckpoint is the workflow state saved after the last node of a run
+// that finished, so an interrupted run can be resumed from there
+type RunCheckpoint struct {
+	RunID   string        `json:"run_id"`
+	Node    string        `json:"node"`
+	SavedAt time.Time     `json:"saved_at"`
+	State   StateSnapshot `json:"state"`
+}
+
+// CheckpointPath returns the checkpoint file of a run
+func CheckpointPath(outputDir, runID string) string {
+	return filepath.Join(StateLogDir(outputDir, runID), checkpointName)
+}
+
+// LoadRunCheckpoint reads the checkpoint of a run
+func LoadRunCheckpoint(outputDir, runID string) (*RunCheckpoint, error) {
+	//nolint:gosec // G304: Reading a checkpoint under the output directory
+	data, err := os.ReadFile(CheckpointPath(outputDir, runID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("run %s has no checkpoint in %s", runID, outputDir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var checkpoint RunCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	if !slices.Contains(generationNodes, checkpoint.Node) {
+		return nil, fmt.Errorf("checkpoint has unknown node %q", checkpoint.Node)
+	}
+	return &checkpoint, nil
+}
+
+// Save atomically writes the checkpoint into the run's directory
+func (c *RunCheckpoint) Save(outputDir string) error {
+	path := CheckpointPath(outputDir, c.RunID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath) // Clean up temp file, ignore error
+		return fmt.Errorf("failed to rename checkpoint: %w", err)
+	}
+	return nil
+}
+
+// ResumeNode returns the node a resumed run starts at: the node that failed,
+// or the one after the last node that finished. It returns "" when the run
+// completed. Plan approval is skipped after planning: only the run that
+// created the plan asks for it.
+func (c *RunCheckpoint) ResumeNode() string {
+	if c.State.Error != "" {
+		return c.Node
+	}
+	i := slices.Index(generationNodes, c.Node)
+	if i < 0 || i == len(generationNodes)-1 {
+		return ""
+	}
+	if generationNodes[i+1] == "approve_plan" {
+		i++
+	}
+	return generationNodes[i+1]
+}
+
+// checkpointStore keeps the steps of a run in memory like the store it
+// embeds, and also writes the latest step to the run's checkpoint file so
+// the run can be resumed by another process
+type checkpointStore struct {
+	*store.MemStore[GenerationState]
+}
+
+// SaveStep records the state after a node. A checkpoint that cannot be
+// written is logged; the run goes ahead without it.
+func (s checkpointStore) SaveStep(ctx context.Context, runID string, step int, nodeID string, state GenerationState) error {
+	if err := s.MemStore.SaveStep(ctx, runID, step, nodeID, state); err != nil {
+		return err
+	}
+	if state.OutputDir == "" {
+		return nil
+	}
+
+	checkpoint := RunCheckpoint{
+		RunID:   runID,
+		Node:    nodeID,
+		SavedAt: time.Now(),
+		State:   NewStateSnapshot(state),
+	}
+	if err := checkpoint.Save(state.OutputDir); err != nil {
+		logctx.Logger(ctx).Warn().Err(err).Str("node", nodeID).Msg("Failed to save workflow checkpoint")
+	}
+	return nil
+}
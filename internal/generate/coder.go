@@ -5,13 +5,22 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/logctx"
 	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/promptguard"
+	"github.com/dshills/gocreator/pkg/fsops"
 	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/dshills/gocreator/pkg/postprocess"
 	"github.com/rs/zerolog/log"
 )
 
@@ -24,6 +33,29 @@ type Coder interface {
 	GenerateFile(ctx context.Context, task models.GenerationTask, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) (models.Patch, error)
 }
 
+// PackageObserver receives the code patches of a package directory once all
+// of its files scheduled in the run have been generated
+type PackageObserver func(patches []models.Patch)
+
+// ObservingCoder is implemented by coders that report each package as soon as
+// it is complete, letting later phases start before the whole plan is done
+type ObservingCoder interface {
+	Coder
+
+	// GenerateObserved behaves like Generate and calls observe, from the
+	// generating goroutine, after the last file of each package
+	GenerateObserved(ctx context.Context, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification, observe PackageObserver) ([]models.Patch, error)
+}
+
+// WorkspaceCoder is implemented by coders that can call into existing sibling
+// modules. The graph hands over the modules the FCS depends on before Generate.
+type WorkspaceCoder interface {
+	Coder
+
+	// SetSiblingModules sets the modules, with their APIs, shown in every prompt
+	SetSiblingModules(modules []SiblingModule)
+}
+
 // llmCoder implements Coder using an LLM to generate code
 type llmCoder struct {
 	client        llm.Client
@@ -31,13 +63,144 @@ type llmCoder struct {
 	metrics       *models.GenerationMetrics
 	stateManager  *IncrementalStateManager
 	incremental   bool
+	outputDir     string
+	mergeStrategy MergeStrategy
+	critic        *critic
+	ensemble      *ensemble
+	preamble      string
+	siblings      []SiblingModule
+	batch         bool
+	stream        bool
+	speculative   bool
+	maxParallel   int // Files generated at once in speculative mode
+	audit         fsops.Logger
+	knowledge     *FixKnowledge
+	events        chan<- models.ProgressEvent
+	maxTokens     int // Output budget of each request, 0 for the planned size
+
+	// Per-file cost ceiling (USD, 0 for none) and the cheaper model used
+	// for files over it; downgrades records those files
+	costCeiling     float64
+	downgradeClient llm.Client
+	downgradesMu    sync.Mutex
+	downgrades      []models.FileDowngrade
+
+	// Files requested so far, and files the model emitted through tool use
+	// ahead of their own task
+	emittedMu sync.Mutex
+	requested map[string]bool
+	emitted   map[string]string
+
+	// Team overrides of prompt sections (optional)
+	prompts *PromptTemplates
+
+	// House style rendered into every prompt
+	style models.StyleGuide
+
+	// Entity declarations rendered from the data model, and the templates
+	// of the files they fully determine (optional)
+	types      *typeRegistry
+	scaffolds  templates.TemplateGenerator
+	modulePath string
+	dialect    models.SQLDialect
+
+	// HTTP routes of the API contracts and the framework of their router
+	routes    []models.HTTPRoute
+	framework string
+
+	// Post-processing of generated Go files
+	postprocess *postprocess.Pipeline
+
+	// Prompts each file was generated from
+	promptLog
 }
 
+// MergeStrategy controls how hand-edited files are reconciled with regenerated output
+type MergeStrategy string
+
+const (
+	// MergeStrategyMarkers leaves diff3-style conflict markers in the file
+	MergeStrategyMarkers MergeStrategy = "markers"
+
+	// MergeStrategyLLM asks the LLM to resolve conflicts, falling back to markers
+	MergeStrategyLLM MergeStrategy = "llm"
+)
+
 // CoderConfig contains configuration for creating a coder
 type CoderConfig struct {
-	LLMClient   llm.Client
-	OutputDir   string // Required for incremental state management
-	Incremental bool   // Enable incremental regeneration
+	LLMClient     llm.Client
+	OutputDir     string        // Required for incremental state management
+	Incremental   bool          // Enable incremental regeneration
+	MergeStrategy MergeStrategy // Conflict handling for hand-edited files (default: markers)
+
+	// CriticClasses selects file classes (handlers, auth, concurrency or path
+	// globs) that get a second review pass. Empty disables the critic.
+	CriticClasses []string
+	AuditLogger   fsops.Logger // Records critic passes and ensemble decisions (optional)
+
+	// EnsembleClient is a second model that also generates files matching
+	// EnsembleClasses; the better of the two candidates is kept. Either empty
+	// disables the ensemble.
+	EnsembleClient  llm.Client
+	EnsembleClasses []string
+
+	// Preamble is organization-wide guidance placed ahead of every prompt
+	Preamble string
+
+	// Batch submits each dependency level's files as one provider batch job
+	// (about half the price, but jobs may take hours). Clients without a
+	// batch API generate interactively.
+	Batch bool
+
+	// Stream writes responses to .gocreator/partial under OutputDir as they
+	// arrive instead of buffering them, and resumes a response left there by
+	// an interrupted run. Requires OutputDir and a streaming client.
+	Stream bool
+
+	// EventChan receives the progress of streamed responses (optional)
+	EventChan chan<- models.ProgressEvent
+
+	// FileCostCeiling caps the projected worst-case cost (USD) of each file.
+	// Files over it with the primary model are generated with DowngradeClient
+	// when that fits, and written as a stub otherwise. Zero disables the cap.
+	FileCostCeiling float64
+	DowngradeClient llm.Client
+
+	// MaxTokens is the output budget of each request. Requests are made to
+	// fit the model's context window with room for it, and files planned
+	// larger are generated in parts. Zero reserves the planned file size.
+	MaxTokens int
+
+	// FixKnowledge remembers how repairs fixed problems across runs and
+	// passes the fix on when the same problem recurs (optional)
+	FixKnowledge *FixKnowledge
+
+	// PromptTemplates overrides the requirements and coding standards
+	// sections of the prompt (optional)
+	PromptTemplates *PromptTemplates
+
+	// Style is the house style every prompt asks for (optional)
+	Style models.StyleGuide
+
+	// PostProcess runs its custom transforms over every generated Go file
+	// after the built-in steps. Nil runs the built-in steps only.
+	PostProcess *postprocess.Pipeline
+
+	// Scaffolds renders entity, repository and schema files from the data
+	// model instead of requesting them. Nil requests every file.
+	Scaffolds templates.TemplateGenerator
+
+	// Dialect is the database scaffolded schemas and migrations are written
+	// for (default: PostgreSQL)
+	Dialect models.SQLDialect
+
+	// Speculative is experimental: files are generated MaxParallel at a time
+	// by dependency level, and workers a level leaves idle start on the next
+	// level against the APIs its dependencies had in the previous output in
+	// OutputDir. Those files are kept when the generated APIs match and
+	// generated again otherwise.
+	Speculative bool
+	MaxParallel int
 }
 
 // NewCoder creates a new Coder instance
@@ -46,9 +209,56 @@ func NewCoder(cfg CoderConfig) (Coder, error) {
 		return nil, fmt.Errorf("LLM client is required")
 	}
 
+	mergeStrategy := cfg.MergeStrategy
+	switch mergeStrategy {
+	case "":
+		mergeStrategy = MergeStrategyMarkers
+	case MergeStrategyMarkers, MergeStrategyLLM:
+	default:
+		return nil, fmt.Errorf("invalid merge strategy: %s (must be markers or llm)", cfg.MergeStrategy)
+	}
+
+	if cfg.FileCostCeiling < 0 {
+		return nil, fmt.Errorf("file cost ceiling must not be negative")
+	}
+	if cfg.MaxTokens < 0 {
+		return nil, fmt.Errorf("max tokens must not be negative")
+	}
+
+	for _, class := range cfg.CriticClasses {
+		if _, err := path.Match(class, ""); err != nil {
+			return nil, fmt.Errorf("invalid critic class pattern %q: %w", class, err)
+		}
+	}
+	for _, class := range cfg.EnsembleClasses {
+		if _, err := path.Match(class, ""); err != nil {
+			return nil, fmt.Errorf("invalid ensemble class pattern %q: %w", class, err)
+		}
+	}
+
 	coder := &llmCoder{
-		client:      cfg.LLMClient,
-		incremental: cfg.Incremental,
+		client:          cfg.LLMClient,
+		incremental:     cfg.Incremental,
+		outputDir:       cfg.OutputDir,
+		mergeStrategy:   mergeStrategy,
+		critic:          newCritic(cfg.LLMClient, cfg.CriticClasses, cfg.AuditLogger, cfg.Preamble),
+		ensemble:        newEnsemble(cfg.EnsembleClient, cfg.LLMClient, cfg.EnsembleClasses, cfg.AuditLogger, cfg.Preamble),
+		preamble:        cfg.Preamble,
+		batch:           cfg.Batch,
+		speculative:     cfg.Speculative,
+		maxParallel:     cfg.MaxParallel,
+		stream:          cfg.Stream && cfg.OutputDir != "",
+		audit:           cfg.AuditLogger,
+		costCeiling:     cfg.FileCostCeiling,
+		downgradeClient: cfg.DowngradeClient,
+		knowledge:       cfg.FixKnowledge,
+		events:          cfg.EventChan,
+		maxTokens:       cfg.MaxTokens,
+		prompts:         cfg.PromptTemplates,
+		style:           cfg.Style,
+		postprocess:     cfg.PostProcess,
+		scaffolds:       cfg.Scaffolds,
+		dialect:         cfg.Dialect,
 		metrics: &models.GenerationMetrics{
 			PhaseTimings:  make(map[string]time.Duration),
 			CostBreakdown: make(map[string]float64),
@@ -63,9 +273,19 @@ func NewCoder(cfg CoderConfig) (Coder, error) {
 	return coder, nil
 }
 
-// SetFCS sets the FCS and initializes the context filter
+// SetFCS sets the FCS, initializes the context filter and renders the
+// entity declarations
 func (c *llmCoder) SetFCS(fcs *models.FinalClarifiedSpecification) {
 	c.contextFilter = NewContextFilter(fcs)
+	c.types = newTypeRegistry(fcs.DataModel, fcs.BuildConfig.PersistenceStrategy())
+	c.modulePath = templates.InferModulePath(fcs)
+	c.routes = fcs.HTTPRoutes()
+	c.framework = fcs.Architecture.Framework()
+}
+
+// SetSiblingModules sets the existing modules generated code may import
+func (c *llmCoder) SetSiblingModules(modules []SiblingModule) {
+	c.siblings = modules
 }
 
 // GetMetrics returns the generation metrics
@@ -75,6 +295,11 @@ func (c *llmCoder) GetMetrics() *models.GenerationMetrics {
 
 // Generate creates source code files based on the generation plan
 func (c *llmCoder) Generate(ctx context.Context, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) ([]models.Patch, error) {
+	return c.GenerateObserved(ctx, plan, fcs, nil)
+}
+
+// GenerateObserved creates source code files and reports each completed package
+func (c *llmCoder) GenerateObserved(ctx context.Context, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification, observe PackageObserver) ([]models.Patch, error) {
 	if plan == nil {
 		return nil, fmt.Errorf("generation plan is required")
 	}
@@ -86,21 +311,23 @@ func (c *llmCoder) Generate(ctx context.Context, plan *models.GenerationPlan, fc
 
 	var tasksToGenerate []models.GenerationTask
 	var allFiles []string
+	var state *IncrementalState
 
 	// Determine which tasks need generation (incremental or full)
 	if c.incremental && c.stateManager != nil {
-		log.Info().Msg("Incremental regeneration mode enabled")
+		logctx.Logger(ctx).Info().Msg("Incremental regeneration mode enabled")
 
 		// Load previous state
-		state, err := c.stateManager.Load()
+		var err error
+		state, err = c.stateManager.Load()
 		if err != nil {
-			log.Warn().Err(err).Msg("Failed to load incremental state, performing full generation")
+			logctx.Logger(ctx).Warn().Err(err).Msg("Failed to load incremental state, performing full generation")
 			tasksToGenerate = c.getAllTasks(plan)
 		} else {
 			// Detect changes
 			tasksToGenerate, allFiles, err = c.detectAndFilterChanges(state, plan, fcs)
 			if err != nil {
-				log.Warn().Err(err).Msg("Failed to detect changes, performing full generation")
+				logctx.Logger(ctx).Warn().Err(err).Msg("Failed to detect changes, performing full generation")
 				tasksToGenerate = c.getAllTasks(plan)
 			}
 		}
@@ -109,7 +336,7 @@ func (c *llmCoder) Generate(ctx context.Context, plan *models.GenerationPlan, fc
 		tasksToGenerate = c.getAllTasks(plan)
 	}
 
-	log.Info().
+	logctx.Logger(ctx).Info().
 		Str("plan_id", plan.ID).
 		Int("total_tasks", len(c.getAllTasks(plan))).
 		Int("tasks_to_generate", len(tasksToGenerate)).
@@ -119,22 +346,85 @@ func (c *llmCoder) Generate(ctx context.Context, plan *models.GenerationPlan, fc
 	startTime := time.Now()
 	allPatches := make([]models.Patch, 0, len(tasksToGenerate))
 
+	// generatedPatches holds the unmerged LLM output; it becomes the merge base
+	// recorded in state so hand edits survive the next regeneration as well
+	generatedPatches := make([]models.Patch, 0, len(tasksToGenerate))
+
+	// Files still to generate per package directory, for observe
+	pending := make(map[string]int)
+	packagePatches := make(map[string][]models.Patch)
+	for _, task := range tasksToGenerate {
+		if task.WritesFile() {
+			pending[filepath.Dir(filepath.Clean(task.TargetPath))]++
+		}
+	}
+
+	// Batch mode: generate files up front as provider batch jobs
+	var batched map[string]models.Patch
+	if c.batch && len(pending) > 0 {
+		if batchClient, ok := c.client.(llm.BatchClient); ok {
+			var err error
+			batched, err = c.generateBatched(ctx, batchClient, tasksToGenerate, plan, fcs)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			logctx.Logger(ctx).Warn().
+				Str("provider", c.client.Provider()).
+				Msg("Provider has no batch API, generating files interactively")
+		}
+	}
+
+	// Speculative mode: generate the files no batch job did up front, in
+	// parallel by dependency level
+	if c.speculative && len(pending) > 0 {
+		speculated, err := c.generateSpeculative(ctx, tasksToGenerate, batched, plan, fcs)
+		if err != nil {
+			return nil, err
+		}
+		if batched == nil {
+			batched = make(map[string]models.Patch, len(speculated))
+		}
+		for id, patch := range speculated {
+			batched[id] = patch
+		}
+	}
+
 	// Generate files for filtered tasks
 	for _, task := range tasksToGenerate {
-		if task.Type != "generate_file" {
-			log.Debug().
+		if !task.WritesFile() {
+			logctx.Logger(ctx).Debug().
 				Str("task_id", task.ID).
 				Str("task_type", task.Type).
-				Msg("Skipping non-generate_file task")
+				Msg("Skipping task that writes no file")
 			continue
 		}
 
-		patch, err := c.GenerateFile(ctx, task, plan, fcs)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate file for task %s: %w", task.ID, err)
+		patch, ok := batched[task.ID]
+		if !ok {
+			var err error
+			patch, err = c.GenerateFile(ctx, task, plan, fcs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate file for task %s: %w", task.ID, err)
+			}
+		}
+
+		generatedPatches = append(generatedPatches, patch)
+
+		if state != nil {
+			patch = c.reconcileHumanEdits(ctx, state, patch)
 		}
 
 		allPatches = append(allPatches, patch)
+
+		if observe != nil {
+			dir := filepath.Dir(filepath.Clean(task.TargetPath))
+			packagePatches[dir] = append(packagePatches[dir], patch)
+			pending[dir]--
+			if pending[dir] == 0 {
+				observe(packagePatches[dir])
+			}
+		}
 	}
 
 	duration := time.Since(startTime)
@@ -142,8 +432,8 @@ func (c *llmCoder) Generate(ctx context.Context, plan *models.GenerationPlan, fc
 	// Update incremental state if enabled and files were generated
 	// Skip state update when FCS is unchanged (no patches generated)
 	if c.incremental && c.stateManager != nil && fcs != nil && len(allPatches) > 0 {
-		if err := c.updateIncrementalState(fcs, allPatches, allFiles); err != nil {
-			log.Warn().Err(err).Msg("Failed to update incremental state")
+		if err := c.updateIncrementalState(fcs, generatedPatches, allFiles); err != nil {
+			logctx.Logger(ctx).Warn().Err(err).Msg("Failed to update incremental state")
 		}
 	}
 
@@ -161,7 +451,7 @@ func (c *llmCoder) Generate(ctx context.Context, plan *models.GenerationPlan, fc
 		c.metrics.TotalOutputTokens = cacheMetrics.OutputTokens
 		c.metrics.TotalLLMCalls += int(cacheMetrics.CacheHits + cacheMetrics.CacheMisses)
 
-		log.Info().
+		logctx.Logger(ctx).Info().
 			Int64("cache_hits", cacheMetrics.CacheHits).
 			Int64("cache_misses", cacheMetrics.CacheMisses).
 			Float64("cache_hit_rate_pct", cacheMetrics.CacheHitRate()).
@@ -170,7 +460,7 @@ func (c *llmCoder) Generate(ctx context.Context, plan *models.GenerationPlan, fc
 			Msg("Prompt cache performance")
 	}
 
-	log.Info().
+	logctx.Logger(ctx).Info().
 		Str("plan_id", plan.ID).
 		Int("files_generated", len(allPatches)).
 		Dur("duration", duration).
@@ -239,7 +529,7 @@ func (c *llmCoder) detectAndFilterChanges(
 		}
 
 		for _, task := range allTasks {
-			if task.Type != "generate_file" {
+			if !task.WritesFile() {
 				continue
 			}
 			normalizedTaskPath := normalizePath(task.TargetPath)
@@ -267,7 +557,7 @@ func (c *llmCoder) detectAndFilterChanges(
 
 	var tasksToGenerate []models.GenerationTask
 	for _, task := range allTasks {
-		if task.Type != "generate_file" {
+		if !task.WritesFile() {
 			continue
 		}
 
@@ -297,29 +587,39 @@ func (c *llmCoder) detectAndFilterChanges(
 	return tasksToGenerate, allFiles, nil
 }
 
-// updateIncrementalState updates the state after successful generation
+// updateIncrementalState updates the state after successful generation. Each
+// Go file's dependencies are the entities its AST references; files that are
+// not Go or do not parse fall back to the entities their filtered context holds.
 func (c *llmCoder) updateIncrementalState(
 	fcs *models.FinalClarifiedSpecification,
 	patches []models.Patch,
-	_ []string, // allFiles - reserved for future use
+	allFiles []string,
 ) error {
-	// Build dependency graph from context filter with normalized paths
+	entities := entityIdentifiers(fcs)
+	dirs := projectDirs(allFiles)
+
+	// Build dependency graph with normalized paths
 	dependencyGraph := make(map[string][]string)
-	if c.contextFilter != nil {
-		// Extract dependencies from filtered FCS data
-		for _, patch := range patches {
-			// Normalize the target file path for consistent storage
-			normalizedPath := normalizePath(patch.TargetFile)
-
-			// Use context filter to determine dependencies
-			filteredFCS := c.contextFilter.FilterForFile(patch.TargetFile, nil, fcs)
-			if filteredFCS != nil {
-				deps := []string{}
-				for _, entity := range filteredFCS.DataModel.Entities {
-					deps = append(deps, entity.Name)
-				}
-				dependencyGraph[normalizedPath] = deps
+	for _, patch := range patches {
+		// Normalize the target file path for consistent storage
+		normalizedPath := normalizePath(patch.TargetFile)
+
+		if deps, ok := astDependencies(normalizedPath, extractContentFromDiff(patch.Diff), entities, dirs); ok {
+			dependencyGraph[normalizedPath] = deps
+			continue
+		}
+
+		// Use context filter to determine dependencies
+		if c.contextFilter == nil {
+			continue
+		}
+		filteredFCS := c.contextFilter.FilterForFile(patch.TargetFile, nil, fcs)
+		if filteredFCS != nil {
+			deps := []string{}
+			for _, entity := range filteredFCS.DataModel.Entities {
+				deps = append(deps, entity.Name)
 			}
+			dependencyGraph[normalizedPath] = deps
 		}
 	}
 
@@ -327,64 +627,230 @@ func (c *llmCoder) updateIncrementalState(
 	return c.stateManager.UpdateState(fcs, patches, dependencyGraph)
 }
 
+// reconcileHumanEdits three-way merges a regenerated file with the copy on disk
+// when that copy was edited by hand since the last generation. The base is the
+// content recorded in incremental state. Files that were not edited, or that
+// have never been generated, are returned unchanged.
+func (c *llmCoder) reconcileHumanEdits(ctx context.Context, state *IncrementalState, patch models.Patch) models.Patch {
+	if c.outputDir == "" {
+		return patch
+	}
+
+	fileState, tracked := state.GeneratedFiles[normalizePath(patch.TargetFile)]
+	if !tracked {
+		return patch
+	}
+
+	//nolint:gosec // G304: Reading previously generated file inside the output directory
+	onDisk, err := os.ReadFile(filepath.Join(c.outputDir, patch.TargetFile))
+	if err != nil {
+		// Missing or unreadable file: nothing to preserve
+		return patch
+	}
+
+	ours := string(onDisk)
+	if ComputeFileChecksum(ours) == fileState.Checksum {
+		return patch
+	}
+
+	base := fileState.Content
+	if base == "" {
+		logctx.Logger(ctx).Warn().
+			Str("file", patch.TargetFile).
+			Msg("File was edited by hand but no base content is recorded, merging against an empty base")
+	}
+
+	theirs := extractContentFromDiff(patch.Diff)
+	result := ThreeWayMerge(base, ours, theirs)
+	merged := result.Content
+
+	if result.HasConflicts() && c.mergeStrategy == MergeStrategyLLM {
+		resolved, err := c.resolveConflicts(ctx, patch.TargetFile, merged)
+		if err != nil {
+			logctx.Logger(ctx).Warn().
+				Err(err).
+				Str("file", patch.TargetFile).
+				Msg("LLM conflict resolution failed, keeping conflict markers")
+		} else {
+			merged = resolved
+			result.Conflicts = 0
+		}
+	}
+
+	if result.HasConflicts() {
+		logctx.Logger(ctx).Warn().
+			Str("file", patch.TargetFile).
+			Int("conflicts", result.Conflicts).
+			Msg("Hand edits conflict with regenerated code, conflict markers written")
+	} else {
+		logctx.Logger(ctx).Info().
+			Str("file", patch.TargetFile).
+			Msg("Merged hand edits into regenerated file")
+	}
+
+	// The merged content already holds what is on disk
+	patch.Diff = newFileDiff(patch.TargetFile, merged)
+	patch.BaseChecksum = ""
+	return patch
+}
+
+// resolveConflicts asks the LLM to resolve the conflict blocks in content
+func (c *llmCoder) resolveConflicts(ctx context.Context, targetPath, content string) (string, error) {
+	var sb strings.Builder
+
+	sb.WriteString("You are an expert Go developer resolving merge conflicts.\n\n")
+	sb.WriteString(fmt.Sprintf("# File\n%s\n\n", targetPath))
+	sb.WriteString("# Conflict Format\n")
+	sb.WriteString("Each conflict has three sections:\n")
+	sb.WriteString("- ours: hand edits made after the last generation\n")
+	sb.WriteString("- base: the previously generated code\n")
+	sb.WriteString("- theirs: the newly generated code for the updated specification\n\n")
+	sb.WriteString("# Requirements\n")
+	sb.WriteString("- Keep the intent of the hand edits\n")
+	sb.WriteString("- Apply the specification changes from the regenerated code\n")
+	sb.WriteString("- Remove every conflict marker\n\n")
+	sb.WriteString("# Content\n")
+	sb.WriteString(content)
+	sb.WriteString("\n\n# Output Format\n\n")
+	sb.WriteString("Return ONLY the complete resolved file, no additional explanation or markdown.\n")
+
+	response, err := c.client.Generate(ctx, withPreamble(c.preamble, sb.String()))
+	if err != nil {
+		return "", fmt.Errorf("LLM conflict resolution failed: %w", err)
+	}
+
+	resolved := c.cleanCodeResponse(response)
+	if containsConflictMarkers(resolved) {
+		return "", fmt.Errorf("resolved content still contains conflict markers")
+	}
+
+	return resolved + "\n", nil
+}
+
 // GenerateFile generates a single file based on task inputs
 func (c *llmCoder) GenerateFile(ctx context.Context, task models.GenerationTask, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) (models.Patch, error) {
-	log.Debug().
-		Str("task_id", task.ID).
+	ctx = logctx.WithTaskID(ctx, task.ID)
+
+	logctx.Logger(ctx).Debug().
 		Str("target_path", task.TargetPath).
 		Msg("Generating file with filtered context")
 
-	startTime := time.Now()
+	// Entity, repository and schema files are rendered from the data model
+	if code, ok := c.renderScaffold(ctx, task, plan); ok {
+		patch := c.filePatch(ctx, task, nil, code)
+		patch.Template = true
+		return patch, nil
+	}
+
+	filteredFCS := c.filterContext(task, plan, fcs)
 
-	// Filter FCS for this specific file
-	var filteredFCS *FilteredFCS
-	if c.contextFilter != nil {
-		filteredFCS = c.contextFilter.FilterForFile(task.TargetPath, plan, fcs)
+	// The model may have written this file alongside an earlier one
+	if code, ok := c.takeEmitted(task.TargetPath); ok {
+		logctx.Logger(ctx).Debug().
+			Str("target_path", task.TargetPath).
+			Msg("Using file emitted with an earlier file")
+		return c.finishFile(ctx, task, plan, filteredFCS, code)
+	}
 
-		// Track metrics
-		metric := models.ContextFilterMetrics{
-			FilePath:             task.TargetPath,
-			OriginalEntityCount:  filteredFCS.OriginalEntityCount,
-			FilteredEntityCount:  filteredFCS.FilteredEntityCount,
-			OriginalPackageCount: filteredFCS.OriginalPackageCount,
-			FilteredPackageCount: filteredFCS.FilteredPackageCount,
-			ReductionPercentage:  filteredFCS.ReductionPercentage,
-			FilterDuration:       time.Since(startTime),
+	// Files over the cost ceiling skip the primary model and its review passes
+	client, downgrade := c.chooseClient(ctx, task, plan, filteredFCS)
+	if downgrade != nil {
+		code := stubFile(*downgrade)
+		if client != nil {
+			var err error
+			if code, err = c.requestWithinWindow(ctx, client, task, plan, filteredFCS); err != nil {
+				return models.Patch{}, err
+			}
+			if code, err = postProcess(c.postprocess, task.TargetPath, task.Inputs, code); err != nil {
+				return models.Patch{}, err
+			}
 		}
-		c.metrics.AddContextFilterMetrics(metric)
+		return c.filePatch(ctx, task, filteredFCS, code), nil
 	}
 
-	// Try to use prompt caching if the client supports it (Anthropic only)
-	var response string
-	var err error
+	code, err := c.requestWithinWindow(ctx, client, task, plan, filteredFCS)
+	if err != nil {
+		return models.Patch{}, err
+	}
 
-	if cacheableClient, ok := c.client.(llm.CacheableClient); ok {
-		// Client supports caching - use cached prompts
-		log.Debug().
-			Str("provider", c.client.Provider()).
-			Str("task_id", task.ID).
-			Msg("Using prompt caching for code generation")
+	return c.finishFile(ctx, task, plan, filteredFCS, code)
+}
 
-		messages := c.buildCodeGenerationPromptWithCache(task, plan, filteredFCS)
-		response, err = cacheableClient.GenerateWithCache(ctx, messages)
-	} else {
-		// Client doesn't support caching - use standard generation
-		log.Debug().
-			Str("provider", c.client.Provider()).
-			Str("task_id", task.ID).
-			Msg("Client doesn't support caching, using standard generation")
+// filterContext filters the FCS for a task's file and records the reduction
+func (c *llmCoder) filterContext(task models.GenerationTask, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) *FilteredFCS {
+	if c.contextFilter == nil {
+		return nil
+	}
 
-		prompt := c.buildCodeGenerationPrompt(task, plan, filteredFCS)
-		response, err = c.client.Generate(ctx, prompt)
+	startTime := time.Now()
+	filteredFCS := c.contextFilter.FilterForFile(task.TargetPath, plan, fcs)
+
+	// Track metrics
+	metric := models.ContextFilterMetrics{
+		FilePath:             task.TargetPath,
+		OriginalEntityCount:  filteredFCS.OriginalEntityCount,
+		FilteredEntityCount:  filteredFCS.FilteredEntityCount,
+		OriginalPackageCount: filteredFCS.OriginalPackageCount,
+		FilteredPackageCount: filteredFCS.FilteredPackageCount,
+		ReductionPercentage:  filteredFCS.ReductionPercentage,
+		FilterDuration:       time.Since(startTime),
 	}
+	c.metrics.AddContextFilterMetrics(metric)
+
+	return filteredFCS
+}
 
+// finishFile post-processes a Go file or checks the format of another file,
+// runs the ensemble and critic passes over a file's generated code, and
+// returns the patch creating it. A Go file that does not parse fails with a
+// *postprocess.SyntaxError.
+func (c *llmCoder) finishFile(ctx context.Context, task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS, code string) (models.Patch, error) {
+	// Go files: strip markdown, fix the package clause and imports
+	code, err := postProcess(c.postprocess, task.TargetPath, task.Inputs, code)
 	if err != nil {
-		return models.Patch{}, fmt.Errorf("LLM code generation failed: %w", err)
+		return models.Patch{}, err
 	}
+	generated := code
 
-	// Clean the response (remove markdown code blocks if present)
-	code := c.cleanCodeResponse(response)
+	// Non-Go files: check the format and ask once for a fix
+	code = c.repairAncillary(ctx, task, plan, filteredFCS, code)
+
+	// Critical files: generate a competing candidate with the ensemble model
+	if c.ensemble != nil {
+		if classes := c.ensemble.matchClasses(task.TargetPath, code); len(classes) > 0 {
+			code = c.ensemble.Choose(ctx, task.TargetPath, code, classes, func(ctx context.Context, client llm.Client) (string, error) {
+				return c.requestCode(ctx, client, task, plan, filteredFCS)
+			}).Content
+		}
+	}
+
+	// Second pass: critic review for selected file classes
+	if c.critic != nil {
+		if classes := c.critic.matchClasses(task.TargetPath, code); len(classes) > 0 {
+			code = c.critic.Review(ctx, task.TargetPath, code, classes).Content
+		}
+	}
 
+	// A reviewed file is processed again; one that no longer parses is
+	// written as generated
+	if code != generated {
+		if reviewed, err := postProcess(c.postprocess, task.TargetPath, task.Inputs, code); err != nil {
+			logctx.Logger(ctx).Warn().Err(err).
+				Str("target_path", task.TargetPath).
+				Msg("Reviewed file does not parse, keeping the generated code")
+			code = generated
+		} else {
+			code = reviewed
+		}
+	}
+
+	return c.filePatch(ctx, task, filteredFCS, code), nil
+}
+
+// filePatch returns the patch creating a file with the given code. For an
+// apply_patch task it modifies the file as it is on disk, keeping that base
+// so the change can be merged if the file changes before it is applied.
+func (c *llmCoder) filePatch(ctx context.Context, task models.GenerationTask, filteredFCS *FilteredFCS, code string) models.Patch {
 	// Calculate checksum
 	hash := sha256.Sum256([]byte(code))
 	checksum := hex.EncodeToString(hash[:])
@@ -392,13 +858,19 @@ func (c *llmCoder) GenerateFile(ctx context.Context, task models.GenerationTask,
 	// Create patch for new file creation
 	patch := models.Patch{
 		TargetFile: task.TargetPath,
-		Diff:       c.createFileDiff(code),
+		Diff:       newFileDiff(task.TargetPath, code),
 		AppliedAt:  time.Now(),
 		Reversible: true,
 	}
+	if base, ok := c.currentFile(task); ok {
+		// A file left as it is keeps the creation diff, which applies as no change
+		if diff := modifyFileDiff(task.TargetPath, base, code); diff != "" {
+			patch.Diff = diff
+			patch.BaseChecksum = ComputeFileChecksum(base)
+		}
+	}
 
-	logEvent := log.Debug().
-		Str("task_id", task.ID).
+	logEvent := logctx.Logger(ctx).Debug().
 		Str("target_path", task.TargetPath).
 		Str("checksum", checksum).
 		Int("lines", strings.Count(code, "\n")+1)
@@ -409,7 +881,66 @@ func (c *llmCoder) GenerateFile(ctx context.Context, task models.GenerationTask,
 
 	logEvent.Msg("File generated successfully")
 
-	return patch, nil
+	return patch
+}
+
+// requestCode asks client for the file's code: streamed to disk in stream
+// mode, through the emit_file tool when the client supports tool use, and as
+// a plain (cached when supported) text response otherwise
+func (c *llmCoder) requestCode(ctx context.Context, client llm.Client, task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS) (string, error) {
+	if streamingClient, ok := client.(llm.StreamingClient); ok && c.stream {
+		// Stream the response to disk so large files are not held in memory
+		messages := c.buildCodeGenerationPromptWithCache(task, plan, filteredFCS)
+		c.recordMessages(task.ID, task.TargetPath, messages)
+		response, err := c.streamCode(ctx, streamingClient, task.TargetPath, messages)
+		if err != nil {
+			return "", fmt.Errorf("LLM code generation failed: %w", err)
+		}
+		return c.cleanCodeResponse(response), nil
+	}
+
+	if emitter, ok := client.(llm.FileEmittingClient); ok {
+		// Tool use delivers the file verbatim, without markdown to clean up
+		code, err := c.emitCode(ctx, emitter, task, plan, filteredFCS)
+		if !errors.Is(err, llm.ErrToolUseUnsupported) {
+			return code, err
+		}
+	}
+
+	return c.requestText(ctx, client, task, plan, filteredFCS)
+}
+
+// requestText generates a file as a plain text response
+func (c *llmCoder) requestText(ctx context.Context, client llm.Client, task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS) (string, error) {
+	var response string
+	var err error
+
+	if cacheableClient, ok := client.(llm.CacheableClient); ok {
+		// Client supports caching - use cached prompts
+		logctx.Logger(ctx).Debug().
+			Str("provider", client.Provider()).
+			Msg("Using prompt caching for code generation")
+
+		messages := c.buildCodeGenerationPromptWithCache(task, plan, filteredFCS)
+		c.recordMessages(task.ID, task.TargetPath, messages)
+		response, err = cacheableClient.GenerateWithCache(ctx, messages)
+	} else {
+		// Client doesn't support caching - use standard generation
+		logctx.Logger(ctx).Debug().
+			Str("provider", client.Provider()).
+			Msg("Client doesn't support caching, using standard generation")
+
+		prompt := c.buildCodeGenerationPrompt(task, plan, filteredFCS)
+		c.record(task.ID, task.TargetPath, prompt)
+		response, err = client.Generate(ctx, prompt)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("LLM code generation failed: %w", err)
+	}
+
+	// Clean the response (remove markdown code blocks if present)
+	return c.cleanCodeResponse(response), nil
 }
 
 // buildCodeGenerationPrompt constructs the LLM prompt for code generation
@@ -417,26 +948,34 @@ func (c *llmCoder) buildCodeGenerationPrompt(task models.GenerationTask, plan *m
 	var sb strings.Builder
 
 	sb.WriteString("You are an expert Go developer generating production-ready code.\n\n")
+	// Determine file type and provide specific instructions
+	fileName := filepath.Base(task.TargetPath)
+	fileType := c.determineFileType(fileName)
+	format, ancillary := ancillaryFormats[fileType]
+
 	sb.WriteString("# Task\n")
-	sb.WriteString(fmt.Sprintf("Generate a Go source file for: %s\n\n", task.TargetPath))
+	if ancillary {
+		sb.WriteString(fmt.Sprintf("Generate the %s file: %s\n\n", format.name, task.TargetPath))
+	} else {
+		sb.WriteString(fmt.Sprintf("Generate a Go source file for: %s\n\n", task.TargetPath))
+	}
 
 	// Include filtered FCS context if available
 	if filteredFCS != nil {
+		sb.WriteString(promptguard.Instructions)
 		sb.WriteString("# Project Context (Filtered)\n\n")
-		sb.WriteString(c.contextFilter.FormatFilteredFCS(filteredFCS))
+		sb.WriteString(promptguard.Fence(c.contextFilter.FormatFilteredFCS(filteredFCS)))
 		sb.WriteString("\n")
 	}
 
-	// Determine file type and provide specific instructions
-	fileName := filepath.Base(task.TargetPath)
-	fileType := c.determineFileType(fileName)
+	sb.WriteString(formatSiblingModules(c.siblings))
 
 	sb.WriteString(fmt.Sprintf("# File Type: %s\n\n", fileType))
 
 	// Get file purpose from plan
 	filePurpose := c.getFilePurpose(task.TargetPath, plan)
 	if filePurpose != "" {
-		sb.WriteString(fmt.Sprintf("# Purpose\n%s\n\n", filePurpose))
+		sb.WriteString(fmt.Sprintf("# Purpose\n%s\n\n", promptguard.Inline(filePurpose)))
 	}
 
 	// Add context from task inputs
@@ -468,101 +1007,41 @@ func (c *llmCoder) buildCodeGenerationPrompt(task models.GenerationTask, plan *m
 		sb.WriteString("\n")
 	}
 
+	sb.WriteString(formatDependencyAPIPrompt(task))
+	sb.WriteString(c.formatEntityTypes(task, plan, filteredFCS))
+	sb.WriteString(formatConflictsPrompt(task))
+	sb.WriteString(c.formatCurrentFile(task))
+	sb.WriteString(formatFilePart(task))
+
 	// Type-specific instructions
 	sb.WriteString("# Requirements\n\n")
 
-	switch fileType {
-	case "go.mod":
-		sb.WriteString("Generate a go.mod file with:\n")
-		sb.WriteString("- Correct module path\n")
-		sb.WriteString("- Go version from build config\n")
-		sb.WriteString("- Required dependencies with versions\n")
-		sb.WriteString("- Proper formatting\n\n")
-
-	case "main.go":
-		sb.WriteString("Generate a main.go file with:\n")
-		sb.WriteString("- package main declaration\n")
-		sb.WriteString("- Proper imports\n")
-		sb.WriteString("- main() function with initialization\n")
-		sb.WriteString("- Error handling and logging\n")
-		sb.WriteString("- Graceful shutdown handling\n\n")
-
-	case "model":
-		sb.WriteString("Generate a model/entity file with:\n")
-		sb.WriteString("- Proper package declaration\n")
-		sb.WriteString("- Struct definitions with JSON tags\n")
-		sb.WriteString("- Validation methods\n")
-		sb.WriteString("- Constructor functions\n")
-		sb.WriteString("- Godoc comments for all exported types and functions\n\n")
-
-	case "repository":
-		sb.WriteString("Generate a repository file with:\n")
-		sb.WriteString("- Interface definition for repository contract\n")
-		sb.WriteString("- Concrete implementation struct\n")
-		sb.WriteString("- Constructor function\n")
-		sb.WriteString("- All CRUD methods with proper error handling\n")
-		sb.WriteString("- Context support for cancellation\n\n")
-
-	case "service":
-		sb.WriteString("Generate a service file with:\n")
-		sb.WriteString("- Service interface definition\n")
-		sb.WriteString("- Service struct with dependencies\n")
-		sb.WriteString("- Constructor with dependency injection\n")
-		sb.WriteString("- Business logic methods\n")
-		sb.WriteString("- Proper error handling and logging\n\n")
-
-	case "handler":
-		sb.WriteString("Generate an HTTP handler file with:\n")
-		sb.WriteString("- Handler struct with service dependencies\n")
-		sb.WriteString("- HTTP handler functions\n")
-		sb.WriteString("- Request validation\n")
-		sb.WriteString("- Proper HTTP status codes\n")
-		sb.WriteString("- JSON encoding/decoding\n\n")
-
-	case "test":
-		sb.WriteString("Generate a test file with:\n")
-		sb.WriteString("- Table-driven tests using testing package\n")
-		sb.WriteString("- Test setup and teardown\n")
-		sb.WriteString("- Mocks for dependencies\n")
-		sb.WriteString("- Comprehensive test cases including edge cases\n")
-		sb.WriteString("- Proper assertions\n\n")
+	if ancillary {
+		writeAncillaryRequirements(&sb, format, task)
+	} else {
+		sb.WriteString(c.requirementsSection(task, plan, filteredFCS, fileType))
+	}
 
-	default:
-		sb.WriteString("Generate a well-structured Go file with:\n")
-		sb.WriteString("- Proper package declaration\n")
-		sb.WriteString("- Clear, idiomatic Go code\n")
-		sb.WriteString("- Proper error handling\n")
-		sb.WriteString("- Comprehensive documentation\n\n")
-	}
-
-	// General coding standards
-	sb.WriteString("# Coding Standards\n\n")
-	sb.WriteString("1. **Go Best Practices**:\n")
-	sb.WriteString("   - Follow Go idioms and conventions\n")
-	sb.WriteString("   - Accept interfaces, return structs\n")
-	sb.WriteString("   - Use meaningful variable names\n")
-	sb.WriteString("   - Keep functions small and focused\n\n")
-
-	sb.WriteString("2. **Error Handling**:\n")
-	sb.WriteString("   - Return errors, don't panic\n")
-	sb.WriteString("   - Wrap errors with context using fmt.Errorf\n")
-	sb.WriteString("   - Use sentinel errors for known conditions\n\n")
-
-	sb.WriteString("3. **Documentation**:\n")
-	sb.WriteString("   - Add godoc comments for all exported symbols\n")
-	sb.WriteString("   - Comments should explain why, not what\n")
-	sb.WriteString("   - Keep line length under 100 characters\n\n")
-
-	sb.WriteString("4. **Testing**:\n")
-	sb.WriteString("   - Write testable code\n")
-	sb.WriteString("   - Use dependency injection\n")
-	sb.WriteString("   - Avoid global state\n\n")
+	if filteredFCS != nil && !ancillary {
+		writeEnumGuidelines(&sb, filteredFCS.DataModel.Enums)
+		writeCrossCuttingCodeGuidelines(&sb, filteredFCS.CrossCutting, task.TargetPath)
+		c.writePersistenceCodeGuidelines(&sb, task.TargetPath)
+		c.writeHTTPCodeGuidelines(&sb, task.TargetPath)
+	}
+
+	if ancillary {
+		writeAncillaryOutputFormat(&sb, format)
+		return withPreamble(c.preamble, sb.String())
+	}
+
+	sb.WriteString(c.standardsSection())
+	sb.WriteString(formatStyleGuide(c.style))
 
 	sb.WriteString("# Output Format\n\n")
 	sb.WriteString("Return ONLY the Go source code, no additional explanation or markdown.\n")
 	sb.WriteString("The code should be complete, correctly formatted, and ready to use.\n")
 
-	return sb.String()
+	return withPreamble(c.preamble, sb.String())
 }
 
 // buildCodeGenerationPromptWithCache constructs cacheable LLM prompts for code generation
@@ -570,28 +1049,14 @@ func (c *llmCoder) buildCodeGenerationPrompt(task models.GenerationTask, plan *m
 // to leverage Anthropic's prompt caching for 60-80% token savings
 func (c *llmCoder) buildCodeGenerationPromptWithCache(task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS) []llm.CacheableMessage {
 	builder := llm.NewPromptBuilder("5m") // 5-minute cache TTL
+	addPreamble(builder, c.preamble)
 
 	// CACHEABLE PART 1: Coding standards and best practices (completely static across all files)
 	var standards strings.Builder
 	standards.WriteString("You are an expert Go developer generating production-ready code.\n\n")
-	standards.WriteString("# Coding Standards\n\n")
-	standards.WriteString("1. **Go Best Practices**:\n")
-	standards.WriteString("   - Follow Go idioms and conventions\n")
-	standards.WriteString("   - Accept interfaces, return structs\n")
-	standards.WriteString("   - Use meaningful variable names\n")
-	standards.WriteString("   - Keep functions small and focused\n\n")
-	standards.WriteString("2. **Error Handling**:\n")
-	standards.WriteString("   - Return errors, don't panic\n")
-	standards.WriteString("   - Wrap errors with context using fmt.Errorf\n")
-	standards.WriteString("   - Use sentinel errors for known conditions\n\n")
-	standards.WriteString("3. **Documentation**:\n")
-	standards.WriteString("   - Add godoc comments for all exported symbols\n")
-	standards.WriteString("   - Comments should explain why, not what\n")
-	standards.WriteString("   - Keep line length under 100 characters\n\n")
-	standards.WriteString("4. **Testing**:\n")
-	standards.WriteString("   - Write testable code\n")
-	standards.WriteString("   - Use dependency injection\n")
-	standards.WriteString("   - Avoid global state\n\n")
+	standards.WriteString(c.standardsSection())
+	standards.WriteString(formatStyleGuide(c.style))
+	standards.WriteString(promptguard.Instructions)
 
 	builder.AddCacheable(standards.String())
 
@@ -599,26 +1064,36 @@ func (c *llmCoder) buildCodeGenerationPromptWithCache(task models.GenerationTask
 	if filteredFCS != nil {
 		var fcsContext strings.Builder
 		fcsContext.WriteString("# Project Context (Filtered)\n\n")
-		fcsContext.WriteString(c.contextFilter.FormatFilteredFCS(filteredFCS))
+		fcsContext.WriteString(promptguard.Fence(c.contextFilter.FormatFilteredFCS(filteredFCS)))
 		fcsContext.WriteString("\n")
 		builder.AddCacheable(fcsContext.String())
 	}
 
+	// CACHEABLE PART 3: Sibling module APIs (stable across all files in this generation run)
+	if siblings := formatSiblingModules(c.siblings); siblings != "" {
+		builder.AddCacheable(siblings)
+	}
+
 	// DYNAMIC PART: Task-specific instructions (changes for each file)
 	var taskInstructions strings.Builder
-	taskInstructions.WriteString("# Task\n")
-	taskInstructions.WriteString(fmt.Sprintf("Generate a Go source file for: %s\n\n", task.TargetPath))
-
 	// Determine file type and provide specific instructions
 	fileName := filepath.Base(task.TargetPath)
 	fileType := c.determineFileType(fileName)
+	format, ancillary := ancillaryFormats[fileType]
+
+	taskInstructions.WriteString("# Task\n")
+	if ancillary {
+		taskInstructions.WriteString(fmt.Sprintf("Generate the %s file: %s\n\n", format.name, task.TargetPath))
+	} else {
+		taskInstructions.WriteString(fmt.Sprintf("Generate a Go source file for: %s\n\n", task.TargetPath))
+	}
 
 	taskInstructions.WriteString(fmt.Sprintf("# File Type: %s\n\n", fileType))
 
 	// Get file purpose from plan
 	filePurpose := c.getFilePurpose(task.TargetPath, plan)
 	if filePurpose != "" {
-		taskInstructions.WriteString(fmt.Sprintf("# Purpose\n%s\n\n", filePurpose))
+		taskInstructions.WriteString(fmt.Sprintf("# Purpose\n%s\n\n", promptguard.Inline(filePurpose)))
 	}
 
 	// Add context from task inputs
@@ -650,82 +1125,94 @@ func (c *llmCoder) buildCodeGenerationPromptWithCache(task models.GenerationTask
 		taskInstructions.WriteString("\n")
 	}
 
+	taskInstructions.WriteString(formatDependencyAPIPrompt(task))
+	taskInstructions.WriteString(c.formatEntityTypes(task, plan, filteredFCS))
+	taskInstructions.WriteString(formatConflictsPrompt(task))
+	taskInstructions.WriteString(c.formatCurrentFile(task))
+	taskInstructions.WriteString(formatFilePart(task))
+
 	// Type-specific instructions
 	taskInstructions.WriteString("# Requirements\n\n")
 
-	switch fileType {
-	case "go.mod":
-		taskInstructions.WriteString("Generate a go.mod file with:\n")
-		taskInstructions.WriteString("- Correct module path\n")
-		taskInstructions.WriteString("- Go version from build config\n")
-		taskInstructions.WriteString("- Required dependencies with versions\n")
-		taskInstructions.WriteString("- Proper formatting\n\n")
-
-	case "main.go":
-		taskInstructions.WriteString("Generate a main.go file with:\n")
-		taskInstructions.WriteString("- package main declaration\n")
-		taskInstructions.WriteString("- Proper imports\n")
-		taskInstructions.WriteString("- main() function with initialization\n")
-		taskInstructions.WriteString("- Error handling and logging\n")
-		taskInstructions.WriteString("- Graceful shutdown handling\n\n")
-
-	case "model":
-		taskInstructions.WriteString("Generate a model/entity file with:\n")
-		taskInstructions.WriteString("- Proper package declaration\n")
-		taskInstructions.WriteString("- Struct definitions with JSON tags\n")
-		taskInstructions.WriteString("- Validation methods\n")
-		taskInstructions.WriteString("- Constructor functions\n")
-		taskInstructions.WriteString("- Godoc comments for all exported types and functions\n\n")
-
-	case "repository":
-		taskInstructions.WriteString("Generate a repository file with:\n")
-		taskInstructions.WriteString("- Interface definition for repository contract\n")
-		taskInstructions.WriteString("- Concrete implementation struct\n")
-		taskInstructions.WriteString("- Constructor function\n")
-		taskInstructions.WriteString("- All CRUD methods with proper error handling\n")
-		taskInstructions.WriteString("- Context support for cancellation\n\n")
-
-	case "service":
-		taskInstructions.WriteString("Generate a service file with:\n")
-		taskInstructions.WriteString("- Service interface definition\n")
-		taskInstructions.WriteString("- Service struct with dependencies\n")
-		taskInstructions.WriteString("- Constructor with dependency injection\n")
-		taskInstructions.WriteString("- Business logic methods\n")
-		taskInstructions.WriteString("- Proper error handling and logging\n\n")
-
-	case "handler":
-		taskInstructions.WriteString("Generate an HTTP handler file with:\n")
-		taskInstructions.WriteString("- Handler struct with service dependencies\n")
-		taskInstructions.WriteString("- HTTP handler functions\n")
-		taskInstructions.WriteString("- Request validation\n")
-		taskInstructions.WriteString("- Proper HTTP status codes\n")
-		taskInstructions.WriteString("- JSON encoding/decoding\n\n")
-
-	case "test":
-		taskInstructions.WriteString("Generate a test file with:\n")
-		taskInstructions.WriteString("- Table-driven tests using testing package\n")
-		taskInstructions.WriteString("- Test setup and teardown\n")
-		taskInstructions.WriteString("- Mocks for dependencies\n")
-		taskInstructions.WriteString("- Comprehensive test cases including edge cases\n")
-		taskInstructions.WriteString("- Proper assertions\n\n")
+	if ancillary {
+		writeAncillaryRequirements(&taskInstructions, format, task)
+	} else {
+		taskInstructions.WriteString(c.requirementsSection(task, plan, filteredFCS, fileType))
+	}
 
-	default:
-		taskInstructions.WriteString("Generate a well-structured Go file with:\n")
-		taskInstructions.WriteString("- Proper package declaration\n")
-		taskInstructions.WriteString("- Clear, idiomatic Go code\n")
-		taskInstructions.WriteString("- Proper error handling\n")
-		taskInstructions.WriteString("- Comprehensive documentation\n\n")
+	if filteredFCS != nil && !ancillary {
+		writeEnumGuidelines(&taskInstructions, filteredFCS.DataModel.Enums)
+		writeCrossCuttingCodeGuidelines(&taskInstructions, filteredFCS.CrossCutting, task.TargetPath)
+		c.writePersistenceCodeGuidelines(&taskInstructions, task.TargetPath)
+		c.writeHTTPCodeGuidelines(&taskInstructions, task.TargetPath)
 	}
 
-	taskInstructions.WriteString("# Output Format\n\n")
-	taskInstructions.WriteString("Return ONLY the Go source code, no additional explanation or markdown.\n")
-	taskInstructions.WriteString("The code should be complete, correctly formatted, and ready to use.\n")
+	if ancillary {
+		writeAncillaryOutputFormat(&taskInstructions, format)
+	} else {
+		taskInstructions.WriteString("# Output Format\n\n")
+		taskInstructions.WriteString("Return ONLY the Go source code, no additional explanation or markdown.\n")
+		taskInstructions.WriteString("The code should be complete, correctly formatted, and ready to use.\n")
+	}
 
 	builder.AddDynamic(taskInstructions.String())
 
 	return builder.Build()
 }
 
+// formatDependencyAPIPrompt renders the task's dependency APIs as a prompt
+// section, or "" when the task has none
+func formatDependencyAPIPrompt(task models.GenerationTask) string {
+	api, _ := task.Inputs[DependencyAPIInput].(string)
+	if api == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Dependency API\n\n")
+	sb.WriteString("These are the exported declarations (bodies omitted) of the packages this file depends on.\n")
+	sb.WriteString("Use only these functions, methods, types and fields from them; do not guess at other names or signatures.\n\n")
+	sb.WriteString("```go\n")
+	sb.WriteString(api)
+	sb.WriteString("```\n\n")
+	return sb.String()
+}
+
+// formatCurrentFile renders the file an apply_patch task modifies as a
+// prompt section, or "" for a task that creates its file or a file that
+// cannot be read
+func (c *llmCoder) formatCurrentFile(task models.GenerationTask) string {
+	content, ok := c.currentFile(task)
+	if !ok {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Current File\n\n")
+	sb.WriteString("This file already exists. Modify it for the task rather than rewriting it:\n")
+	sb.WriteString("- Keep its package clause and every existing declaration other code may use\n")
+	sb.WriteString("- Add or change only what the task needs, in the file's existing style\n")
+	sb.WriteString("- Return the complete modified file\n\n")
+	sb.WriteString(promptguard.Fence(content))
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// currentFile reads the file an apply_patch task modifies from the output
+// directory, reporting false for a task that creates its file or a file that
+// cannot be read
+func (c *llmCoder) currentFile(task models.GenerationTask) (string, bool) {
+	if task.Type != "apply_patch" || c.outputDir == "" {
+		return "", false
+	}
+	//nolint:gosec // G304: Reading an existing file inside the output directory
+	content, err := os.ReadFile(filepath.Join(c.outputDir, filepath.FromSlash(task.TargetPath)))
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
+}
+
 // determineFileType determines the type of file being generated
 func (c *llmCoder) determineFileType(fileName string) string {
 	switch {
@@ -735,6 +1222,8 @@ func (c *llmCoder) determineFileType(fileName string) string {
 		return "main.go"
 	case strings.HasSuffix(fileName, "_test.go"):
 		return "test"
+	case filepath.Ext(fileName) != ".go":
+		return ancillaryFileType(fileName)
 	case strings.Contains(fileName, "model") || strings.Contains(fileName, "entity"):
 		return "model"
 	case strings.Contains(fileName, "repository") || strings.Contains(fileName, "repo"):
@@ -743,12 +1232,6 @@ func (c *llmCoder) determineFileType(fileName string) string {
 		return "service"
 	case strings.Contains(fileName, "handler"):
 		return "handler"
-	case fileName == "Makefile":
-		return "Makefile"
-	case fileName == "Dockerfile":
-		return "Dockerfile"
-	case fileName == "README.md":
-		return "documentation"
 	default:
 		return "source"
 	}
@@ -768,30 +1251,15 @@ func (c *llmCoder) getFilePurpose(targetPath string, plan *models.GenerationPlan
 func (c *llmCoder) cleanCodeResponse(response string) string {
 	response = strings.TrimSpace(response)
 
-	// Remove markdown code blocks
-	if strings.HasPrefix(response, "```go") {
-		response = strings.TrimPrefix(response, "```go")
-		response = strings.TrimSuffix(response, "```")
-	} else if strings.HasPrefix(response, "```") {
-		response = strings.TrimPrefix(response, "```")
-		response = strings.TrimSuffix(response, "```")
+	// Remove markdown code blocks, with any language tag (```go, ```yaml)
+	if strings.HasPrefix(response, "```") {
+		if newline := strings.IndexByte(response, '\n'); newline >= 0 {
+			response = response[newline+1:]
+		} else {
+			response = strings.TrimPrefix(response, "```")
+		}
+		response = strings.TrimSuffix(strings.TrimSpace(response), "```")
 	}
 
 	return strings.TrimSpace(response)
 }
-
-// createFileDiff creates a unified diff for creating a new file
-func (c *llmCoder) createFileDiff(content string) string {
-	// For new files, we create a simple diff format
-	// In a real implementation, you'd use a proper diff library
-	var sb strings.Builder
-
-	lines := strings.Split(content, "\n")
-	sb.WriteString(fmt.Sprintf("@@ -0,0 +1,%d @@\n", len(lines)))
-
-	for _, line := range lines {
-		sb.WriteString("+" + line + "\n")
-	}
-
-	return sb.String()
-}
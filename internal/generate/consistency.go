@@ -0,0 +1,447 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/promptguard"
+)
+
+// consistencyConflictsInput is the task input through which a regeneration
+// request lists the conflicts the previous version of the file caused
+const consistencyConflictsInput = "consistency_conflicts"
+
+// maxConsistencyRounds bounds the targeted regenerations of conflicting files
+const maxConsistencyRounds = 2
+
+// Kinds of consistencyConflict
+const (
+	conflictDuplicateSymbol = "duplicate_symbol"
+	conflictImportCycle     = "import_cycle"
+)
+
+// consistencyConflict is a problem between generated files that no single
+// file shows: a declaration made twice in one package, or packages importing
+// each other
+type consistencyConflict struct {
+	Kind    string
+	Symbol  string   // Duplicated declaration, Type.Method for a method
+	Package string   // Directory of the duplicated declaration
+	Cycle   []string // Package directories of the cycle, the first repeated at the end
+	Files   []string // Files involved, in generation order
+
+	// Files to regenerate, each with what to change
+	Targets map[string]string
+}
+
+func (c consistencyConflict) String() string {
+	if c.Kind == conflictImportCycle {
+		return "import cycle " + strings.Join(c.Cycle, " -> ")
+	}
+	return fmt.Sprintf("%s declared more than once in %s (%s)", c.Symbol, c.Package, strings.Join(c.Files, ", "))
+}
+
+// parsedPatch is a generated Go file of the patches checked together
+type parsedPatch struct {
+	path  string // Slash-separated target path
+	dir   string
+	index int // Position in generation order
+	file  *ast.File
+}
+
+// checkConsistency builds the symbol table and package import graph of the
+// Go files among patches and returns the duplicate declarations and import
+// cycles they contain. Files that do not parse are left to the build.
+func checkConsistency(patches []models.Patch) []consistencyConflict {
+	fset := token.NewFileSet()
+	var files []parsedPatch
+	dirs := make(map[string]bool)
+	for i, patch := range patches {
+		target := filepath.ToSlash(normalizePath(patch.TargetFile))
+		if !strings.HasSuffix(target, ".go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, target, extractContentFromDiff(patch.Diff), parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		dir := path.Dir(target)
+		files = append(files, parsedPatch{path: target, dir: dir, index: i, file: file})
+		dirs[dir] = true
+	}
+
+	conflicts := duplicateSymbols(files)
+	return append(conflicts, importCycles(files, dirs)...)
+}
+
+// duplicateSymbols reports top-level names declared more than once in a
+// package. External test packages (pkg_test) are a package of their own. The
+// first file declaring a name keeps it; the others are asked to drop theirs.
+func duplicateSymbols(files []parsedPatch) []consistencyConflict {
+	type symbolKey struct{ dir, pkg, name string }
+	declared := make(map[symbolKey][]string)
+	var order []symbolKey
+
+	for _, f := range files {
+		for _, name := range topLevelNames(f.file) {
+			key := symbolKey{dir: f.dir, pkg: f.file.Name.Name, name: name}
+			if _, ok := declared[key]; !ok {
+				order = append(order, key)
+			}
+			declared[key] = append(declared[key], f.path)
+		}
+	}
+
+	var conflicts []consistencyConflict
+	for _, key := range order {
+		paths := declared[key]
+		if len(paths) < 2 {
+			continue
+		}
+		conflict := consistencyConflict{
+			Kind:    conflictDuplicateSymbol,
+			Symbol:  key.name,
+			Package: key.dir,
+			Files:   uniqueStrings(paths),
+			Targets: make(map[string]string),
+		}
+		owner := paths[0]
+		for _, p := range paths[1:] {
+			if p == owner {
+				conflict.Targets[p] = fmt.Sprintf("%s is declared more than once in this file; declare it once", key.name)
+			} else {
+				conflict.Targets[p] = fmt.Sprintf("%s is already declared in %s of the same package; do not declare it again, use that declaration", key.name, owner)
+			}
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+// topLevelNames returns the package-level names file declares, with methods
+// as Type.Method. Blank names and init functions may repeat and are left out.
+func topLevelNames(file *ast.File) []string {
+	var names []string
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			name := decl.Name.Name
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				name = receiverType(decl.Recv.List[0].Type) + "." + name
+			} else if name == "init" {
+				continue
+			}
+			if name != "_" {
+				names = append(names, name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					names = append(names, spec.Name.Name)
+				case *ast.ValueSpec:
+					for _, ident := range spec.Names {
+						if ident.Name != "_" {
+							names = append(names, ident.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// receiverType returns the name of a method's receiver type, without
+// pointer and type parameters
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// importCycles reports cycles in the import graph of the project's packages,
+// built from the imports of their non-test files. Each strongly connected
+// set of packages is reported once, as its shortest cycle through the package
+// generated first: that package, the lowest layer, should not import the next
+// one, so the files with that import are asked to drop it.
+func importCycles(files []parsedPatch, dirs map[string]bool) []consistencyConflict {
+	edges := make(map[string]map[string][]string) // dir -> imported dir -> importing files
+	first := make(map[string]int)
+	for _, f := range files {
+		if _, ok := first[f.dir]; !ok {
+			first[f.dir] = f.index
+		}
+		if strings.HasSuffix(f.path, "_test.go") {
+			continue
+		}
+		for _, imp := range f.file.Imports {
+			importPath, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+			if dir := importedDir(importPath, dirs); dir != "" {
+				if edges[f.dir] == nil {
+					edges[f.dir] = make(map[string][]string)
+				}
+				edges[f.dir][dir] = append(edges[f.dir][dir], f.path)
+			}
+		}
+	}
+
+	var conflicts []consistencyConflict
+	for _, component := range stronglyConnected(edges) {
+		start := component[0]
+		for _, dir := range component[1:] {
+			if first[dir] < first[start] {
+				start = dir
+			}
+		}
+		if len(component) == 1 && edges[start][start] == nil {
+			continue
+		}
+
+		cycle := shortestCycle(edges, start, component)
+		importing := uniqueStrings(edges[cycle[0]][cycle[1]])
+		conflict := consistencyConflict{
+			Kind:    conflictImportCycle,
+			Cycle:   cycle,
+			Targets: make(map[string]string, len(importing)),
+		}
+		for i := 0; i+1 < len(cycle); i++ {
+			conflict.Files = append(conflict.Files, edges[cycle[i]][cycle[i+1]]...)
+		}
+		conflict.Files = uniqueStrings(conflict.Files)
+		for _, p := range importing {
+			conflict.Targets[p] = fmt.Sprintf("importing package %s creates the import cycle %s; do not import it: "+
+				"take what this package needs from %s as parameters or a small interface declared here instead",
+				cycle[1], strings.Join(cycle, " -> "), cycle[1])
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+// importedDir returns the project package directory an import path names, or
+// "" for imports outside the project. The longest matching directory wins,
+// whatever the module path.
+func importedDir(importPath string, dirs map[string]bool) string {
+	best := ""
+	for dir := range dirs {
+		if dir != "." && (importPath == dir || strings.HasSuffix(importPath, "/"+dir)) && len(dir) > len(best) {
+			best = dir
+		}
+	}
+	return best
+}
+
+// stronglyConnected returns the strongly connected components of the graph,
+// each sorted, in the order of their smallest directory
+func stronglyConnected(edges map[string]map[string][]string) [][]string {
+	nodes := make(map[string]bool)
+	for from, tos := range edges {
+		nodes[from] = true
+		for to := range tos {
+			nodes[to] = true
+		}
+	}
+	sorted := slices.Sorted(maps.Keys(nodes))
+
+	// Tarjan's algorithm
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+	next := 0
+
+	var visit func(node string)
+	visit = func(node string) {
+		index[node] = next
+		low[node] = next
+		next++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, to := range slices.Sorted(maps.Keys(edges[node])) {
+			if _, seen := index[to]; !seen {
+				visit(to)
+				low[node] = min(low[node], low[to])
+			} else if onStack[to] {
+				low[node] = min(low[node], index[to])
+			}
+		}
+
+		if low[node] == index[node] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == node {
+					break
+				}
+			}
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+	for _, node := range sorted {
+		if _, seen := index[node]; !seen {
+			visit(node)
+		}
+	}
+
+	sort.Slice(components, func(i, j int) bool { return components[i][0] < components[j][0] })
+	return components
+}
+
+// shortestCycle returns the shortest path from start back to itself within
+// component, start repeated at the end
+func shortestCycle(edges map[string]map[string][]string, start string, component []string) []string {
+	if edges[start][start] != nil {
+		return []string{start, start}
+	}
+	inComponent := make(map[string]bool, len(component))
+	for _, dir := range component {
+		inComponent[dir] = true
+	}
+
+	previous := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, to := range slices.Sorted(maps.Keys(edges[node])) {
+			if to == start {
+				cycle := []string{start}
+				for n := node; n != start; n = previous[n] {
+					cycle = append([]string{n}, cycle...)
+				}
+				return append([]string{start}, cycle...)
+			}
+			if _, seen := previous[to]; !seen && inComponent[to] {
+				previous[to] = node
+				queue = append(queue, to)
+			}
+		}
+	}
+	return []string{start, start}
+}
+
+// resolveConflicts checks the generated code for conflicts between files and
+// regenerates the files each conflict names, told what to change, until none
+// remain or maxConsistencyRounds is reached. Conflicts left are logged and
+// left to build validation and repair.
+func (gg *GenerationGraph) resolveConflicts(ctx context.Context, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification, patches []models.Patch) []models.Patch {
+	tasks := make(map[string]models.GenerationTask)
+	for _, phase := range plan.Phases {
+		for _, task := range phase.Tasks {
+			if task.WritesFile() {
+				tasks[filepath.ToSlash(normalizePath(task.TargetPath))] = task
+			}
+		}
+	}
+
+	conflicts := checkConsistency(patches)
+	for round := 1; round <= maxConsistencyRounds && len(conflicts) > 0; round++ {
+		notes := make(map[string][]string)
+		for _, conflict := range conflicts {
+			for file, note := range conflict.Targets {
+				notes[file] = append(notes[file], note)
+			}
+		}
+
+		logctx.Logger(ctx).Info().
+			Int("round", round).
+			Int("conflicts", len(conflicts)).
+			Int("files", len(notes)).
+			Msg("Regenerating files that conflict with other generated files")
+
+		for i, patch := range patches {
+			target := filepath.ToSlash(normalizePath(patch.TargetFile))
+			task, ok := tasks[target]
+			if !ok || len(notes[target]) == 0 {
+				continue
+			}
+
+			retry := task
+			retry.Inputs = make(map[string]interface{}, len(task.Inputs)+1)
+			for key, value := range task.Inputs {
+				retry.Inputs[key] = value
+			}
+			retry.Inputs[consistencyConflictsInput] = notes[target]
+
+			regenerated, err := gg.coder.GenerateFile(ctx, retry, plan, fcs)
+			if err != nil {
+				logctx.Logger(ctx).Warn().Err(err).
+					Str("target_path", patch.TargetFile).
+					Msg("Failed to regenerate conflicting file; keeping it")
+				continue
+			}
+			patches[i] = regenerated
+		}
+
+		conflicts = checkConsistency(patches)
+	}
+
+	for _, conflict := range conflicts {
+		logctx.Logger(ctx).Warn().
+			Str("kind", conflict.Kind).
+			Strs("files", conflict.Files).
+			Msgf("Generated files still conflict: %s", conflict)
+	}
+	return patches
+}
+
+// formatConflictsPrompt renders the conflicts a regenerated file must fix as
+// a prompt section, or "" when the task lists none
+func formatConflictsPrompt(task models.GenerationTask) string {
+	notes := inputStrings(task.Inputs, consistencyConflictsInput)
+	if len(notes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Conflicts With Other Files\n\n")
+	sb.WriteString("The previous version of this file conflicted with other generated files of the project. Fix these:\n")
+	for _, note := range notes {
+		sb.WriteString("- " + promptguard.Inline(note) + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// uniqueStrings returns values with repeats removed, in order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
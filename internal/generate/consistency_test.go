@@ -0,0 +1,92 @@
+package generate
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goPatches returns creation patches of files (path -> content), in order
+func goPatches(files ...string) []models.Patch {
+	patches := make([]models.Patch, 0, len(files)/2)
+	for i := 0; i+1 < len(files); i += 2 {
+		patches = append(patches, models.Patch{TargetFile: files[i], Diff: newFileDiff(files[i], files[i+1])})
+	}
+	return patches
+}
+
+func TestCheckConsistency_DuplicateSymbols(t *testing.T) {
+	conflicts := checkConsistency(goPatches(
+		"internal/models/user.go", "package models\n\ntype User struct{}\n\nfunc (u *User) Name() string { return \"\" }\n\nfunc init() {}\n\nvar _ = 1\n",
+		"internal/models/order.go", "package models\n\ntype User struct{}\n\nfunc (u User) Name() string { return \"\" }\n\nfunc init() {}\n\nvar _ = 2\n\nconst Limit, Limit = 1, 2\n",
+		"internal/models/user_test.go", "package models_test\n\ntype User struct{}\n",
+		"internal/store/user.go", "package store\n\ntype User struct{}\n",
+		"internal/store/broken.go", "package store\n\ntype User struct",
+	))
+
+	require.Len(t, conflicts, 3)
+	assert.Equal(t, conflictDuplicateSymbol, conflicts[0].Kind)
+	assert.Equal(t, "User", conflicts[0].Symbol)
+	assert.Equal(t, "internal/models", conflicts[0].Package)
+	assert.Equal(t, []string{"internal/models/user.go", "internal/models/order.go"}, conflicts[0].Files, "the test package and other packages may reuse the name")
+	assert.Equal(t, map[string]string{
+		"internal/models/order.go": "User is already declared in internal/models/user.go of the same package; do not declare it again, use that declaration",
+	}, conflicts[0].Targets, "the first declaration is kept")
+
+	assert.Equal(t, "User.Name", conflicts[1].Symbol, "methods are keyed by receiver type")
+	assert.Equal(t, "Limit", conflicts[2].Symbol)
+	assert.Equal(t, "Limit is declared more than once in this file; declare it once", conflicts[2].Targets["internal/models/order.go"])
+}
+
+func TestCheckConsistency_ImportCycles(t *testing.T) {
+	conflicts := checkConsistency(goPatches(
+		"internal/models/user.go", "package models\n\nimport \"example.com/app/internal/service\"\n\nvar _ = service.New\n",
+		"internal/models/models_test.go", "package models\n\nimport _ \"example.com/app/internal/api\"\n",
+		"internal/service/service.go", "package service\n\nimport _ \"example.com/app/internal/store\"\n\nfunc New() {}\n",
+		"internal/store/store.go", "package store\n\nimport _ \"example.com/app/internal/models\"\n",
+		"internal/api/api.go", "package api\n\nimport (\n\t_ \"example.com/app/internal/models\"\n\t_ \"example.com/app/internal/service\"\n)\n",
+		"cmd/app/main.go", "package main\n\nimport _ \"example.com/app/internal/api\"\n\nfunc main() {}\n",
+	))
+
+	require.Len(t, conflicts, 1)
+	conflict := conflicts[0]
+	assert.Equal(t, conflictImportCycle, conflict.Kind)
+	assert.Equal(t, []string{"internal/models", "internal/service", "internal/store", "internal/models"}, conflict.Cycle,
+		"the cycle starts at the package generated first; test imports do not count")
+	assert.Equal(t, []string{"internal/models/user.go", "internal/service/service.go", "internal/store/store.go"}, conflict.Files)
+	require.Len(t, conflict.Targets, 1)
+	assert.Contains(t, conflict.Targets["internal/models/user.go"], "importing package internal/service creates the import cycle internal/models -> internal/service -> internal/store -> internal/models")
+	assert.Equal(t, "import cycle internal/models -> internal/service -> internal/store -> internal/models", conflict.String())
+
+	assert.Empty(t, checkConsistency(goPatches("internal/store/store.go", "package store\n\nimport _ \"github.com/other/store\"\n")),
+		"imports outside the project are not followed")
+}
+
+func TestResolveConflicts(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{"package models\n\nfunc (o Order) Total() int { return 0 }\n"}}
+	coder, err := NewCoder(CoderConfig{LLMClient: client})
+	require.NoError(t, err)
+	gg := &GenerationGraph{coder: coder}
+
+	plan := &models.GenerationPlan{Phases: []models.GenerationPhase{{Name: "models", Tasks: []models.GenerationTask{
+		{ID: "user", Type: "generate_file", TargetPath: "internal/models/user.go"},
+		{ID: "order", Type: "generate_file", TargetPath: "internal/models/order.go"},
+	}}}}
+	patches := goPatches(
+		"internal/models/user.go", "package models\n\ntype User struct{}\n\ntype Order struct{}\n",
+		"internal/models/order.go", "package models\n\ntype Order struct{}\n\nfunc (o Order) Total() int { return 0 }\n",
+	)
+
+	resolved := gg.resolveConflicts(context.Background(), plan, nil, patches)
+
+	require.Len(t, client.prompts, 1, "only the conflicting file is regenerated")
+	assert.Contains(t, client.prompts[0], "# Conflicts With Other Files")
+	assert.Contains(t, client.prompts[0], "- Order is already declared in internal/models/user.go of the same package")
+	assert.Equal(t, "internal/models/order.go", resolved[1].TargetFile)
+	assert.False(t, strings.Contains(extractContentFromDiff(resolved[1].Diff), "type Order"))
+	assert.Empty(t, checkConsistency(resolved))
+}
@@ -4,22 +4,37 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/dshills/gocreator/internal/models"
 	"github.com/rs/zerolog/log"
 )
 
-// ContextFilter filters FCS content to include only relevant portions for a specific generation task
+// ContextFilter filters FCS content to include only relevant portions for a specific generation task.
+//
+// The indexes are built once from the FCS and only read afterwards, so one
+// filter serves concurrent workers, and filtering a file looks entities up
+// instead of scanning the data model. Filtered results share the FCS's
+// slices wherever nothing is left out; they must be treated as read-only.
 type ContextFilter struct {
+	// fcs is the specification the indexes were built from
+	fcs *models.FinalClarifiedSpecification
 	// depGraph maps entity names to their dependencies
 	depGraph map[string][]string
 	// entityPackages maps entity names to their packages
 	entityPackages map[string]string
 	// packageDeps maps package names to other packages they depend on
 	packageDeps map[string][]string
+	// lowerEntityNames holds the lowercased entity names in declaration order
+	lowerEntityNames []string
+	// packageEntities maps lowercased package names to their entities in declaration order
+	packageEntities map[string][]string
+	// entityPackageNames lists the distinct packages of the entities
+	entityPackageNames []string
 }
 
-// FilteredFCS represents a filtered subset of the FCS for a specific task
+// FilteredFCS represents a filtered subset of the FCS for a specific task.
+// Its slices may share the FCS's backing arrays and must not be modified.
 type FilteredFCS struct {
 	// Original FCS metadata (always included)
 	SchemaVersion string
@@ -38,7 +53,8 @@ type FilteredFCS struct {
 	// API contracts (only relevant ones)
 	APIContracts []models.APIContract
 
-	// Testing and build config (always included)
+	// Cross-cutting concerns, testing and build config (always included)
+	CrossCutting    models.CrossCutting
 	TestingStrategy models.TestingStrategy
 	BuildConfig     models.BuildConfig
 
@@ -53,9 +69,12 @@ type FilteredFCS struct {
 // NewContextFilter creates a new ContextFilter from an FCS
 func NewContextFilter(fcs *models.FinalClarifiedSpecification) *ContextFilter {
 	cf := &ContextFilter{
-		depGraph:       make(map[string][]string),
-		entityPackages: make(map[string]string),
-		packageDeps:    make(map[string][]string),
+		fcs:              fcs,
+		depGraph:         make(map[string][]string),
+		entityPackages:   make(map[string]string, len(fcs.DataModel.Entities)),
+		packageDeps:      make(map[string][]string, len(fcs.Architecture.Packages)),
+		lowerEntityNames: make([]string, 0, len(fcs.DataModel.Entities)),
+		packageEntities:  make(map[string][]string),
 	}
 
 	// Build dependency graph from FCS
@@ -66,9 +85,16 @@ func NewContextFilter(fcs *models.FinalClarifiedSpecification) *ContextFilter {
 
 // buildDependencyGraph constructs the dependency graph from FCS
 func (cf *ContextFilter) buildDependencyGraph(fcs *models.FinalClarifiedSpecification) {
-	// Map entity names to packages
+	// Map entity names to packages, and index them for file matching
 	for _, entity := range fcs.DataModel.Entities {
 		cf.entityPackages[entity.Name] = entity.Package
+		cf.lowerEntityNames = append(cf.lowerEntityNames, strings.ToLower(entity.Name))
+
+		pkg := strings.ToLower(entity.Package)
+		if _, seen := cf.packageEntities[pkg]; !seen {
+			cf.entityPackageNames = append(cf.entityPackageNames, entity.Package)
+		}
+		cf.packageEntities[pkg] = append(cf.packageEntities[pkg], entity.Name)
 	}
 
 	// Build entity dependencies from relationships
@@ -156,11 +182,16 @@ func (cf *ContextFilter) extractEntityReference(typeStr string) string {
 
 // FilterForFile creates a filtered FCS containing only relevant context for a specific file
 func (cf *ContextFilter) FilterForFile(filePath string, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) *FilteredFCS {
+	// The indexes describe the FCS the filter was built from
+	if fcs != cf.fcs {
+		return NewContextFilter(fcs).FilterForFile(filePath, plan, fcs)
+	}
+
 	log.Debug().
 		Str("file_path", filePath).
 		Msg("Filtering FCS for file")
 
-	// Determine what entities/packages this file needs
+	// Determine what entities/packages this file needs; nil means all entities
 	relevantEntities := cf.determineRelevantEntities(filePath, plan, fcs)
 	relevantPackages := cf.determineRelevantPackages(filePath, plan, relevantEntities)
 
@@ -169,6 +200,7 @@ func (cf *ContextFilter) FilterForFile(filePath string, plan *models.GenerationP
 		SchemaVersion:        fcs.SchemaVersion,
 		ID:                   fcs.ID,
 		Version:              fcs.Version,
+		CrossCutting:         fcs.CrossCutting,
 		TestingStrategy:      fcs.TestingStrategy,
 		BuildConfig:          fcs.BuildConfig,
 		OriginalEntityCount:  len(fcs.DataModel.Entities),
@@ -178,6 +210,7 @@ func (cf *ContextFilter) FilterForFile(filePath string, plan *models.GenerationP
 	// Filter entities
 	filtered.DataModel.Entities = cf.filterEntities(fcs.DataModel.Entities, relevantEntities)
 	filtered.DataModel.Relationships = cf.filterRelationships(fcs.DataModel.Relationships, relevantEntities)
+	filtered.DataModel.Enums = fcs.DataModel.Enums // Include all enums so field types stay consistent
 	filtered.FilteredEntityCount = len(filtered.DataModel.Entities)
 
 	// Filter packages
@@ -211,7 +244,107 @@ func (cf *ContextFilter) FilterForFile(filePath string, plan *models.GenerationP
 	return filtered
 }
 
-// determineRelevantEntities identifies which entities are relevant for a file
+// tightenLevels is the number of levels Tighten can shrink a context by
+const tightenLevels = 3
+
+// Tighten returns a copy of a filtered FCS with less context, for a prompt
+// that does not fit the model's context window. Each level drops more:
+//
+//  1. entities other than the named ones and their direct dependencies,
+//     with the relationships and enums only those dropped refer to
+//  2. requirements that do not mention a named entity, and the
+//     non-functional requirements
+//  3. the remaining requirements, relationships and API contracts
+//
+// entities names what the file declares; with none the entities are kept.
+func (cf *ContextFilter) Tighten(filtered *FilteredFCS, entities []string, level int) *FilteredFCS {
+	tightened := *filtered
+
+	if level >= 1 && len(entities) > 0 {
+		keep := make(map[string]bool, len(entities))
+		for _, name := range entities {
+			keep[name] = true
+			for _, dep := range cf.depGraph[name] {
+				keep[dep] = true
+			}
+		}
+		tightened.DataModel.Entities = cf.filterEntities(filtered.DataModel.Entities, keep)
+		tightened.DataModel.Relationships = cf.filterRelationships(filtered.DataModel.Relationships, keep)
+		tightened.DataModel.Enums = referencedEnums(filtered.DataModel.Enums, tightened.DataModel.Entities)
+		tightened.FilteredEntityCount = len(tightened.DataModel.Entities)
+	}
+
+	if level >= 2 {
+		functional := filtered.Requirements.Functional
+		if len(entities) > 0 {
+			functional = nil
+			for _, req := range filtered.Requirements.Functional {
+				if mentionsAny(req.Description, entities) {
+					functional = append(functional, req)
+				}
+			}
+		}
+		tightened.Requirements = models.Requirements{Functional: functional}
+	}
+
+	if level >= 3 {
+		tightened.Requirements = models.Requirements{}
+		tightened.DataModel.Relationships = nil
+		tightened.APIContracts = nil
+	}
+
+	totalOriginal := tightened.OriginalEntityCount + tightened.OriginalPackageCount
+	if totalOriginal > 0 {
+		totalFiltered := tightened.FilteredEntityCount + tightened.FilteredPackageCount
+		tightened.ReductionPercentage = float64(totalOriginal-totalFiltered) / float64(totalOriginal) * 100
+	}
+	return &tightened
+}
+
+// referencedEnums returns the enums an attribute of the entities refers to
+func referencedEnums(enums []models.Enum, entities []models.Entity) []models.Enum {
+	var referenced []models.Enum
+	for _, e := range enums {
+		for _, entity := range entities {
+			if attributesMention(entity, e.Name) {
+				referenced = append(referenced, e)
+				break
+			}
+		}
+	}
+	return referenced
+}
+
+// attributesMention reports whether an attribute type of entity names typeName
+func attributesMention(entity models.Entity, typeName string) bool {
+	for _, typeStr := range entity.Attributes {
+		if mentionsAny(typeStr, []string{typeName}) {
+			return true
+		}
+	}
+	return false
+}
+
+// mentionsAny reports whether text contains one of the names as a word,
+// ignoring case and plurals
+func mentionsAny(text string, names []string) bool {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	for _, word := range words {
+		for _, name := range names {
+			name = strings.ToLower(name)
+			if word == name || word == name+"s" || word == name+"es" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// determineRelevantEntities identifies which entities are relevant for a
+// file. It returns nil when no entity could be matched and the file gets
+// them all, so the data model is neither copied nor turned into a set.
 func (cf *ContextFilter) determineRelevantEntities(filePath string, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) map[string]bool {
 	relevant := make(map[string]bool)
 
@@ -235,14 +368,12 @@ func (cf *ContextFilter) determineRelevantEntities(filePath string, plan *models
 	var primaryEntity string
 
 	// Check if filename contains an entity name
-	for _, entity := range fcs.DataModel.Entities {
-		entityLower := strings.ToLower(entity.Name)
-		fileNameLower := strings.ToLower(fileName)
-
+	fileNameLower := strings.ToLower(fileName)
+	for i, entityLower := range cf.lowerEntityNames {
 		if strings.Contains(fileNameLower, entityLower) {
-			primaryEntity = entity.Name
+			primaryEntity = fcs.DataModel.Entities[i].Name
 			log.Debug().
-				Str("entity", entity.Name).
+				Str("entity", primaryEntity).
 				Str("file", fileName).
 				Msg("Matched entity from filename")
 			break
@@ -251,42 +382,44 @@ func (cf *ContextFilter) determineRelevantEntities(filePath string, plan *models
 
 	// If not found, check package match
 	if primaryEntity == "" {
-		for _, entity := range fcs.DataModel.Entities {
-			if strings.EqualFold(entity.Package, packageName) {
-				primaryEntity = entity.Name
-				log.Debug().
-					Str("entity", entity.Name).
-					Str("package", packageName).
-					Msg("Matched entity from package")
-				break
-			}
+		if entities := cf.packageEntities[strings.ToLower(packageName)]; len(entities) > 0 {
+			primaryEntity = entities[0]
+			log.Debug().
+				Str("entity", primaryEntity).
+				Str("package", packageName).
+				Msg("Matched entity from package")
 		}
 	}
 
-	// If we found a primary entity, include it and its dependencies
-	if primaryEntity != "" {
+	// Interface and contract files define what other packages consume, so they
+	// need their package's entities and those of its consumers
+	if isContractFile(filePath) {
+		if primaryEntity != "" {
+			cf.addEntityWithDependencies(primaryEntity, relevant, 0)
+		}
+		cf.addPlannedEntities(filePath, plan, relevant)
+		for _, pkg := range append([]string{packageName}, consumerPackages(filePath, fcs)...) {
+			for _, entityName := range cf.packageEntities[strings.ToLower(pkg)] {
+				cf.addEntityWithDependencies(entityName, relevant, 0)
+			}
+		}
+		log.Debug().
+			Str("file", fileName).
+			Int("entities", len(relevant)).
+			Msg("Matched entities for interface/contract file")
+	} else if primaryEntity != "" {
+		// If we found a primary entity, include it and its dependencies
 		cf.addEntityWithDependencies(primaryEntity, relevant, 0)
 	} else {
 		// For files without a clear entity (main.go, config.go, etc.)
-		// Check task inputs for entity hints
-		task := cf.findTaskForFile(filePath, plan)
-		if task != nil && task.Inputs != nil {
-			if entities, ok := task.Inputs["entities"].([]interface{}); ok {
-				for _, e := range entities {
-					if entityName, ok := e.(string); ok {
-						cf.addEntityWithDependencies(entityName, relevant, 0)
-					}
-				}
-			}
-		}
+		// Check the plan's task inputs and file tree for entity hints
+		cf.addPlannedEntities(filePath, plan, relevant)
 
 		// For handler/service files without specific entity, include entities from the same package
 		if strings.Contains(fileName, "handler") || strings.Contains(fileName, "service") ||
 			strings.Contains(fileName, "repository") {
-			for _, entity := range fcs.DataModel.Entities {
-				if strings.EqualFold(entity.Package, packageName) {
-					cf.addEntityWithDependencies(entity.Name, relevant, 0)
-				}
+			for _, entityName := range cf.packageEntities[strings.ToLower(packageName)] {
+				cf.addEntityWithDependencies(entityName, relevant, 0)
 			}
 		}
 	}
@@ -296,14 +429,90 @@ func (cf *ContextFilter) determineRelevantEntities(filePath string, plan *models
 		log.Warn().
 			Str("file_path", filePath).
 			Msg("No relevant entities identified, including all entities")
-		for _, entity := range fcs.DataModel.Entities {
-			relevant[entity.Name] = true
-		}
+		return nil
 	}
 
 	return relevant
 }
 
+// contractPackageNames are package directories holding interfaces that other packages consume
+var contractPackageNames = map[string]bool{
+	"contract":   true,
+	"contracts":  true,
+	"interface":  true,
+	"interfaces": true,
+	"port":       true,
+	"ports":      true,
+}
+
+// isContractFile reports whether a file declares interfaces or contracts by
+// naming convention: *_interface.go, interfaces.go, *_contract.go,
+// contracts.go, ports.go, or any file in a contract package directory
+func isContractFile(filePath string) bool {
+	dir := filepath.Base(filepath.Dir(filePath))
+	if contractPackageNames[strings.ToLower(dir)] {
+		return true
+	}
+
+	stem := strings.TrimSuffix(strings.ToLower(filepath.Base(filePath)), ".go")
+	for _, suffix := range []string{"interface", "interfaces", "contract", "contracts", "ports"} {
+		if stem == suffix || strings.HasSuffix(stem, "_"+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// consumerPackages returns the names of the FCS packages that depend on the
+// package containing filePath, with dependencies given as names or paths
+func consumerPackages(filePath string, fcs *models.FinalClarifiedSpecification) []string {
+	dir := filepath.ToSlash(filepath.Dir(filePath))
+	name := filepath.Base(dir)
+
+	var consumers []string
+	for _, pkg := range fcs.Architecture.Packages {
+		for _, dep := range pkg.Dependencies {
+			dep = strings.TrimSuffix(filepath.ToSlash(dep), "/")
+			if strings.EqualFold(dep, name) || dep == dir || strings.HasSuffix(dep, "/"+dir) {
+				consumers = append(consumers, pkg.Name)
+				break
+			}
+		}
+	}
+	return consumers
+}
+
+// addPlannedEntities adds the entities the plan assigns to a file, from its
+// task's "entities" input and its file tree entry
+func (cf *ContextFilter) addPlannedEntities(filePath string, plan *models.GenerationPlan, relevant map[string]bool) {
+	task := cf.findTaskForFile(filePath, plan)
+	if task != nil && task.Inputs != nil {
+		switch entities := task.Inputs["entities"].(type) {
+		case []interface{}:
+			for _, e := range entities {
+				if entityName, ok := e.(string); ok {
+					cf.addEntityWithDependencies(entityName, relevant, 0)
+				}
+			}
+		case []string:
+			for _, entityName := range entities {
+				cf.addEntityWithDependencies(entityName, relevant, 0)
+			}
+		}
+	}
+
+	if plan == nil {
+		return
+	}
+	for _, file := range plan.FileTree.Files {
+		if filepath.Clean(file.Path) == filepath.Clean(filePath) {
+			for _, entityName := range file.Entities {
+				cf.addEntityWithDependencies(entityName, relevant, 0)
+			}
+		}
+	}
+}
+
 // addEntityWithDependencies recursively adds an entity and its dependencies
 func (cf *ContextFilter) addEntityWithDependencies(entityName string, relevant map[string]bool, depth int) {
 	// Prevent infinite recursion
@@ -330,7 +539,8 @@ func (cf *ContextFilter) addEntityWithDependencies(entityName string, relevant m
 	}
 }
 
-// determineRelevantPackages identifies which packages are relevant
+// determineRelevantPackages identifies which packages are relevant; nil
+// relevantEntities stands for all entities
 func (cf *ContextFilter) determineRelevantPackages(filePath string, _ *models.GenerationPlan, relevantEntities map[string]bool) map[string]bool {
 	relevant := make(map[string]bool)
 
@@ -343,18 +553,26 @@ func (cf *ContextFilter) determineRelevantPackages(filePath string, _ *models.Ge
 	}
 
 	// Include packages containing relevant entities
-	for entityName := range relevantEntities {
-		if pkg, exists := cf.entityPackages[entityName]; exists {
-			relevant[pkg] = true
+	addPackage := func(pkg string) {
+		relevant[pkg] = true
 
-			// Include package dependencies
-			if deps, exists := cf.packageDeps[pkg]; exists {
-				for _, dep := range deps {
-					relevant[dep] = true
-				}
+		// Include package dependencies
+		if deps, exists := cf.packageDeps[pkg]; exists {
+			for _, dep := range deps {
+				relevant[dep] = true
 			}
 		}
 	}
+	if relevantEntities == nil {
+		for _, pkg := range cf.entityPackageNames {
+			addPackage(pkg)
+		}
+	}
+	for entityName := range relevantEntities {
+		if pkg, exists := cf.entityPackages[entityName]; exists {
+			addPackage(pkg)
+		}
+	}
 
 	// Always include common packages
 	commonPackages := []string{"main", "config", "common", "util"}
@@ -383,9 +601,13 @@ func (cf *ContextFilter) findTaskForFile(filePath string, plan *models.Generatio
 	return nil
 }
 
-// filterEntities returns only relevant entities
+// filterEntities returns only relevant entities; nil relevant keeps them all,
+// sharing the original slice
 func (cf *ContextFilter) filterEntities(entities []models.Entity, relevant map[string]bool) []models.Entity {
-	var filtered []models.Entity
+	if relevant == nil {
+		return entities[:len(entities):len(entities)]
+	}
+	filtered := make([]models.Entity, 0, min(len(relevant), len(entities)))
 	for _, entity := range entities {
 		if relevant[entity.Name] {
 			filtered = append(filtered, entity)
@@ -394,8 +616,12 @@ func (cf *ContextFilter) filterEntities(entities []models.Entity, relevant map[s
 	return filtered
 }
 
-// filterRelationships returns only relationships between relevant entities
+// filterRelationships returns only relationships between relevant entities;
+// nil relevant keeps them all, sharing the original slice
 func (cf *ContextFilter) filterRelationships(relationships []models.Relationship, relevant map[string]bool) []models.Relationship {
+	if relevant == nil {
+		return relationships[:len(relationships):len(relationships)]
+	}
 	var filtered []models.Relationship
 	for _, rel := range relationships {
 		if relevant[rel.From] && relevant[rel.To] {
@@ -418,8 +644,9 @@ func (cf *ContextFilter) filterPackages(packages []models.Package, relevant map[
 
 // filterAPIContracts returns only API contracts relevant to the file
 func (cf *ContextFilter) filterAPIContracts(contracts []models.APIContract, filePath string, relevantPackages map[string]bool) []models.APIContract {
-	// For handler files, include all contracts
-	if strings.Contains(filePath, "handler") || strings.Contains(filePath, "api") {
+	// For handler, gRPC server and .proto files, include all contracts
+	if strings.Contains(filePath, "handler") || strings.Contains(filePath, "api") ||
+		strings.Contains(filePath, "grpc") || strings.Contains(filePath, "server") || strings.HasSuffix(filePath, ".proto") {
 		return contracts
 	}
 
@@ -496,20 +723,64 @@ func (cf *ContextFilter) FormatFilteredFCS(filtered *FilteredFCS) string {
 		sb.WriteString("\n")
 	}
 
+	// Enums
+	if len(filtered.DataModel.Enums) > 0 {
+		sb.WriteString("## Enums\n\n")
+		for _, e := range filtered.DataModel.Enums {
+			sb.WriteString(fmt.Sprintf("### %s\n", e.Name))
+			sb.WriteString(fmt.Sprintf("**Package**: %s | **Backing Type**: %s\n\n", e.Package, e.Backing()))
+			if e.Description != "" {
+				sb.WriteString(fmt.Sprintf("%s\n\n", e.Description))
+			}
+			sb.WriteString("**Values**:\n")
+			for _, value := range e.Values {
+				sb.WriteString(fmt.Sprintf("- `%s`: %q\n", e.ConstName(value), value))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
 	// API Contracts
 	if len(filtered.APIContracts) > 0 {
 		sb.WriteString("## API Contracts\n\n")
 		for _, contract := range filtered.APIContracts {
+			if contract.IsGRPC() {
+				sb.WriteString(fmt.Sprintf("- **%s** in service `%s`: %s\n", contract.Signature(), contract.GRPCService().FullName(), contract.Description))
+				writeMessageFields(&sb, contract.RequestMessage(), contract.Request)
+				writeMessageFields(&sb, contract.ResponseMessage(), contract.Response)
+				continue
+			}
 			sb.WriteString(fmt.Sprintf("- **%s %s**: %s\n", contract.Method, contract.Endpoint, contract.Description))
 		}
 		sb.WriteString("\n")
 	}
 
+	// Cross-Cutting Concerns
+	if filtered.CrossCutting.Enabled() {
+		sb.WriteString("## Cross-Cutting Concerns\n\n")
+		sb.WriteString(fmt.Sprintf("**Middleware Package**: %s\n\n", filtered.CrossCutting.MiddlewarePackage()))
+		for _, concern := range filtered.CrossCutting.Ordered() {
+			sb.WriteString(fmt.Sprintf("- **%s** (`%s`)", concern.Name, concern.Func()))
+			if concern.Description != "" {
+				sb.WriteString(fmt.Sprintf(": %s", concern.Description))
+			}
+			sb.WriteString("\n")
+			for _, key := range sortedSettingKeys(concern.Settings) {
+				sb.WriteString(fmt.Sprintf("  - %s: %s\n", key, concern.Settings[key]))
+			}
+			if len(concern.Exclude) > 0 {
+				sb.WriteString(fmt.Sprintf("  - Excluded paths: %s\n", strings.Join(concern.Exclude, ", ")))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
 	// Testing Strategy
 	sb.WriteString("## Testing Strategy\n\n")
 	sb.WriteString(fmt.Sprintf("- Coverage Target: %.1f%%\n", filtered.TestingStrategy.CoverageTarget))
 	sb.WriteString(fmt.Sprintf("- Unit Tests: %t\n", filtered.TestingStrategy.UnitTests))
 	sb.WriteString(fmt.Sprintf("- Integration Tests: %t\n", filtered.TestingStrategy.IntegrationTests))
+	sb.WriteString(fmt.Sprintf("- Test Framework: %s\n", filtered.TestingStrategy.Framework()))
 	sb.WriteString("\n")
 
 	// Build Config
@@ -520,3 +791,11 @@ func (cf *ContextFilter) FormatFilteredFCS(filtered *FilteredFCS) string {
 
 	return sb.String()
 }
+
+// writeMessageFields lists the fields of a protobuf message, sorted by name
+func writeMessageFields(sb *strings.Builder, message string, schema models.ContractSchema) {
+	if len(schema.Fields) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("  - %s: %s\n", message, strings.Join(schema.FieldList(), ", ")))
+}
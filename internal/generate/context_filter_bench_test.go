@@ -1,9 +1,11 @@
 package generate
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/dshills/gocreator/internal/models"
+	"github.com/rs/zerolog"
 )
 
 // BenchmarkContextFilter benchmarks the context filtering performance
@@ -151,3 +153,79 @@ func createLargeFCS() *models.FinalClarifiedSpecification {
 
 	return fcs
 }
+
+// hugeEntityCount is the size of the FCS the scaling benchmarks filter
+const hugeEntityCount = 5000
+
+// createHugeFCS creates an FCS with n entities spread over packages of 25,
+// each entity referencing the previous one
+func createHugeFCS(n int) *models.FinalClarifiedSpecification {
+	fcs := &models.FinalClarifiedSpecification{SchemaVersion: "1.0", ID: "huge-fcs", Version: "1.0.0"}
+
+	packages := (n + 24) / 25
+	for p := 0; p < packages; p++ {
+		pkg := models.Package{Name: fmt.Sprintf("domain%03d", p), Path: fmt.Sprintf("internal/domain%03d", p)}
+		if p > 0 {
+			pkg.Dependencies = []string{fmt.Sprintf("domain%03d", p-1)}
+		}
+		fcs.Architecture.Packages = append(fcs.Architecture.Packages, pkg)
+	}
+
+	fcs.DataModel.Entities = make([]models.Entity, 0, n)
+	for i := 0; i < n; i++ {
+		entity := models.Entity{
+			Name:       fmt.Sprintf("Widget%04d", i),
+			Package:    fmt.Sprintf("domain%03d", i/25),
+			Attributes: map[string]string{"ID": "string", "Name": "string", "CreatedAt": "time.Time"},
+		}
+		if i > 0 {
+			previous := fmt.Sprintf("Widget%04d", i-1)
+			entity.Attributes["Parent"] = "*" + previous
+			fcs.DataModel.Relationships = append(fcs.DataModel.Relationships, models.Relationship{From: entity.Name, To: previous, Type: "belongs_to"})
+		}
+		fcs.DataModel.Entities = append(fcs.DataModel.Entities, entity)
+	}
+	return fcs
+}
+
+// BenchmarkContextFilter_HugeFCS measures filtering a 5k-entity FCS. One
+// filter per file must stay well under a millisecond and allocate in
+// proportion to the entities it keeps, not to the size of the FCS.
+func BenchmarkContextFilter_HugeFCS(b *testing.B) {
+	// Measure the filter, not the per-entity debug logging
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+
+	fcs := createHugeFCS(hugeEntityCount)
+	plan := &models.GenerationPlan{}
+
+	b.Run("NewContextFilter", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = NewContextFilter(fcs)
+		}
+	})
+
+	cf := NewContextFilter(fcs)
+
+	b.Run("FilterForFile_Entity", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = cf.FilterForFile("internal/domain100/widget2500.go", plan, fcs)
+		}
+	})
+
+	b.Run("FilterForFile_Package", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = cf.FilterForFile("internal/domain150/service.go", plan, fcs)
+		}
+	})
+
+	b.Run("FilterForFile_Fallback", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = cf.FilterForFile("cmd/server/main.go", plan, fcs)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package generate
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/dshills/gocreator/internal/models"
@@ -512,6 +513,52 @@ func TestFormatFilteredFCS(t *testing.T) {
 	t.Logf("Formatted FCS length: %d characters", len(formatted))
 }
 
+func TestFormatFilteredFCS_Enums(t *testing.T) {
+	fcs := createTestFCS()
+	fcs.DataModel.Enums = []models.Enum{{
+		Name:    "OrderStatus",
+		Package: "order",
+		Values:  []string{"pending", "in_progress"},
+	}}
+	cf := NewContextFilter(fcs)
+
+	// Enums are kept even for files that do not touch their package
+	filtered := cf.FilterForFile("internal/user/user.go", &models.GenerationPlan{}, fcs)
+	if len(filtered.DataModel.Enums) != 1 {
+		t.Fatalf("Expected 1 enum in filtered FCS, got %d", len(filtered.DataModel.Enums))
+	}
+
+	formatted := cf.FormatFilteredFCS(filtered)
+	for _, want := range []string{"## Enums", "### OrderStatus", "**Backing Type**: string", "- `OrderStatusInProgress`: \"in_progress\""} {
+		if !contains(formatted, want) {
+			t.Errorf("Formatted FCS missing %q", want)
+		}
+	}
+}
+
+func TestFormatFilteredFCS_CrossCutting(t *testing.T) {
+	fcs := createTestFCS()
+	fcs.CrossCutting = models.CrossCutting{Concerns: []models.Concern{
+		{Name: models.ConcernAuth},
+		{Name: models.ConcernCORS, Settings: map[string]string{"allowed_origins": "https://example.com"}, Exclude: []string{"/health"}},
+	}}
+	cf := NewContextFilter(fcs)
+
+	filtered := cf.FilterForFile("internal/user/user.go", &models.GenerationPlan{}, fcs)
+	formatted := cf.FormatFilteredFCS(filtered)
+	for _, want := range []string{
+		"## Cross-Cutting Concerns",
+		"**Middleware Package**: internal/middleware",
+		"- **cors** (`CORS`)",
+		"  - allowed_origins: https://example.com",
+		"  - Excluded paths: /health",
+	} {
+		if !contains(formatted, want) {
+			t.Errorf("Formatted FCS missing %q", want)
+		}
+	}
+}
+
 func TestTransitiveDependencies(t *testing.T) {
 	fcs := createTestFCS()
 	cf := NewContextFilter(fcs)
@@ -574,6 +621,71 @@ func TestDetermineRelevantPackages(t *testing.T) {
 	t.Logf("Relevant packages: %v", relevant)
 }
 
+func TestIsContractFile(t *testing.T) {
+	tests := map[string]bool{
+		"internal/order/order_interface.go": true,
+		"internal/order/interfaces.go":      true,
+		"internal/order/store_contract.go":  true,
+		"internal/contracts/store.go":       true,
+		"internal/ports/repository.go":      true,
+		"internal/order/service.go":         false,
+		"internal/order/interfaceutil.go":   false,
+		"cmd/app/main.go":                   false,
+	}
+	for path, want := range tests {
+		if got := isContractFile(path); got != want {
+			t.Errorf("isContractFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestFilterForFile_ContractPackage(t *testing.T) {
+	fcs := createTestFCS()
+	fcs.Architecture.Packages = append(fcs.Architecture.Packages, models.Package{Name: "contracts", Path: "internal/contracts"})
+	fcs.Architecture.Packages[2].Dependencies = []string{"internal/contracts"} // product
+	cf := NewContextFilter(fcs)
+
+	filtered := cf.FilterForFile("internal/contracts/gateway.go", &models.GenerationPlan{}, fcs)
+
+	// The product package consumes the contracts, so its entities are needed
+	entityMap := make(map[string]bool)
+	for _, entity := range filtered.DataModel.Entities {
+		entityMap[entity.Name] = true
+	}
+	for _, name := range []string{"Product", "Category"} {
+		if !entityMap[name] {
+			t.Errorf("Entity %s should be included for a contract consumed by product", name)
+		}
+	}
+	if filtered.FilteredEntityCount != 2 {
+		t.Errorf("Contract file should not fall back to every entity, got %d", filtered.FilteredEntityCount)
+	}
+}
+
+func TestFilterForFile_InterfaceFile(t *testing.T) {
+	fcs := createTestFCS()
+	cf := NewContextFilter(fcs)
+	plan := &models.GenerationPlan{FileTree: models.FileTree{Files: []models.File{
+		{Path: "internal/product/interfaces.go", Entities: []string{"Country"}},
+	}}}
+
+	filtered := cf.FilterForFile("internal/product/interfaces.go", plan, fcs)
+
+	entityMap := make(map[string]bool)
+	for _, entity := range filtered.DataModel.Entities {
+		entityMap[entity.Name] = true
+	}
+	// Package entities, those of the consuming order package and planned entities
+	for _, name := range []string{"Product", "Category", "Order", "User", "Country"} {
+		if !entityMap[name] {
+			t.Errorf("Entity %s should be included for product interfaces", name)
+		}
+	}
+	if entityMap["Payment"] {
+		t.Error("Payment does not consume product and should be excluded")
+	}
+}
+
 func TestMetricsTracking(t *testing.T) {
 	fcs := createTestFCS()
 	cf := NewContextFilter(fcs)
@@ -616,3 +728,57 @@ func findInString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestFilterForFile_SharesUnfilteredSlices(t *testing.T) {
+	fcs := createHugeFCS(100)
+	cf := NewContextFilter(fcs)
+
+	// No entity matches main.go, so it gets them all without copies
+	filtered := cf.FilterForFile("cmd/server/main.go", &models.GenerationPlan{}, fcs)
+	if len(filtered.DataModel.Entities) != 100 || &filtered.DataModel.Entities[0] != &fcs.DataModel.Entities[0] {
+		t.Fatal("Expected the unfiltered entities to share the FCS slice")
+	}
+	if len(filtered.DataModel.Relationships) != 99 || &filtered.DataModel.Relationships[0] != &fcs.DataModel.Relationships[0] {
+		t.Fatal("Expected the unfiltered relationships to share the FCS slice")
+	}
+
+	// Appending to a shared view must not write into the FCS
+	extended := append(filtered.DataModel.Entities, models.Entity{Name: "Extra"})
+	extended[0].Name = "Changed"
+	if fcs.DataModel.Entities[0].Name != "Widget0000" {
+		t.Error("Appending to the filtered entities modified the FCS")
+	}
+}
+
+func TestFilterForFile_HugeFCS(t *testing.T) {
+	fcs := createHugeFCS(hugeEntityCount)
+	cf := NewContextFilter(fcs)
+	plan := &models.GenerationPlan{}
+
+	filtered := cf.FilterForFile("internal/domain100/widget2500.go", plan, fcs)
+	var names []string
+	for _, entity := range filtered.DataModel.Entities {
+		names = append(names, entity.Name)
+	}
+	// The entity and its chain of parents, down to the dependency depth limit
+	want := []string{"Widget2495", "Widget2496", "Widget2497", "Widget2498", "Widget2499", "Widget2500"}
+	if strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Errorf("Filtered entities = %v, want %v", names, want)
+	}
+	if len(filtered.DataModel.Relationships) != len(want)-1 {
+		t.Errorf("Expected %d relationships, got %d", len(want)-1, len(filtered.DataModel.Relationships))
+	}
+
+	// Package matching is case-insensitive and uses the package's first entity
+	filtered = cf.FilterForFile("internal/DOMAIN150/service.go", plan, fcs)
+	if len(filtered.DataModel.Entities) == 0 || filtered.DataModel.Entities[len(filtered.DataModel.Entities)-1].Name != "Widget3750" {
+		t.Errorf("Expected the first entity of domain150, got %v", filtered.DataModel.Entities)
+	}
+
+	// A filter asked about another FCS filters that FCS
+	other := createTestFCS()
+	filtered = cf.FilterForFile("internal/user/user.go", plan, other)
+	if filtered.OriginalEntityCount != len(other.DataModel.Entities) || filtered.FilteredEntityCount == 0 {
+		t.Errorf("Expected the other FCS to be filtered, got %d of %d entities", filtered.FilteredEntityCount, filtered.OriginalEntityCount)
+	}
+}
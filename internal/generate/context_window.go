@@ -0,0 +1,342 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// filePartInput is the task input through which a request for part of a
+// file learns which entities it declares; it holds a filePart
+const filePartInput = "file_part"
+
+// filePart is a part of a file generated in several requests, because the
+// whole file does not fit the model's context window or output budget
+type filePart struct {
+	// Entities are declared by this part
+	Entities []string
+	// Others are declared by the file's other parts
+	Others []string
+	// Lines is the planned size of this part
+	Lines int
+}
+
+// windowFit is how a file's request is made to fit the model's limits
+type windowFit struct {
+	// filteredFCS is the context to send, tightened when needed
+	filteredFCS *FilteredFCS
+	// tokens is the estimated prompt size with that context, 0 when the
+	// model's context window is not known
+	tokens int64
+	// level is how far the context was tightened, 0 for not at all
+	level int
+	// split means the file is generated in parts instead
+	split bool
+}
+
+// countTokens estimates the tokens client's model reads for text
+func countTokens(client llm.Client, text string) int64 {
+	return llm.TokenizerFor(llm.Provider(client.Provider()), client.Model()).CountTokens(text)
+}
+
+// requestWithinWindow asks client for a file's code like requestCode, after
+// making sure the request fits the model: a prompt that leaves no room for
+// the output in the context window is sent with its context tightened, and
+// a file that still does not fit, or is planned larger than the output
+// budget, is generated in parts that are merged into one file. This fails
+// before any request when neither helps, rather than part way through
+// generation.
+func (c *llmCoder) requestWithinWindow(ctx context.Context, client llm.Client, task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS) (string, error) {
+	fit, err := c.fitWindow(client, task, plan, filteredFCS)
+	if err != nil {
+		return "", err
+	}
+	if !fit.split {
+		if fit.level > 0 {
+			logctx.Logger(ctx).Info().
+				Str("target_path", task.TargetPath).
+				Int("level", fit.level).
+				Int64("prompt_tokens", fit.tokens).
+				Int64("context_window", llm.ContextWindowFor(client.Model())).
+				Msg("Tightened file context to fit the context window")
+		}
+		return c.requestCode(ctx, client, task, plan, fit.filteredFCS)
+	}
+
+	parts := c.splitTask(task, plan, filteredFCS)
+	logctx.Logger(ctx).Info().
+		Str("target_path", task.TargetPath).
+		Int("parts", len(parts)).
+		Msg("Generating file in parts to fit the model's limits")
+
+	codes := make([]string, len(parts))
+	for i, part := range parts {
+		code, err := c.requestWithinWindow(ctx, client, part.task, plan, part.filteredFCS)
+		if err != nil {
+			return "", err
+		}
+		codes[i] = code
+	}
+	return mergeFileParts(task.TargetPath, codes)
+}
+
+// checkWindow reports why a file's request cannot be made to fit the model,
+// without sending it
+func (c *llmCoder) checkWindow(client llm.Client, task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS) error {
+	fit, err := c.fitWindow(client, task, plan, filteredFCS)
+	if err != nil || !fit.split {
+		return err
+	}
+	for _, part := range c.splitTask(task, plan, filteredFCS) {
+		if err := c.checkWindow(client, part.task, plan, part.filteredFCS); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fitWindow decides how a file's request fits the model: as it is, with its
+// context tightened, or split in parts. The output takes the coder's output
+// budget, or the file's planned size without one.
+func (c *llmCoder) fitWindow(client llm.Client, task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS) (windowFit, error) {
+	fit := windowFit{filteredFCS: filteredFCS}
+	entities := fileEntities(task, plan)
+	output, sized := plannedOutputTokens(task, plan)
+	reserve := output
+	if c.maxTokens > 0 {
+		reserve = int64(c.maxTokens)
+
+		// The response would be cut off at the output budget
+		if sized && output > reserve && len(entities) > 1 {
+			fit.split = true
+			return fit, nil
+		}
+	}
+
+	window := llm.ContextWindowFor(client.Model())
+	if window == 0 {
+		return fit, nil
+	}
+	fit.tokens = c.promptTokens(client, task, plan, filteredFCS)
+	if fit.tokens+reserve <= window {
+		return fit, nil
+	}
+
+	if filteredFCS != nil && c.contextFilter != nil {
+		for level := 1; level <= tightenLevels; level++ {
+			tightened := c.contextFilter.Tighten(filteredFCS, entities, level)
+			fit.filteredFCS, fit.level = tightened, level
+			fit.tokens = c.promptTokens(client, task, plan, tightened)
+			if fit.tokens+reserve <= window {
+				return fit, nil
+			}
+		}
+	}
+
+	if len(entities) > 1 {
+		return windowFit{filteredFCS: filteredFCS, split: true}, nil
+	}
+	return fit, fmt.Errorf(
+		"prompt for %s is ~%d tokens; with %d output tokens it exceeds the %d-token context window of %s, and the file declares too few entities to split",
+		task.TargetPath, fit.tokens, reserve, window, modelName(client))
+}
+
+// taskPart is the request for one part of a file
+type taskPart struct {
+	task        models.GenerationTask
+	filteredFCS *FilteredFCS
+}
+
+// splitTask splits a file's task in two parts declaring half of its
+// entities each, with the context narrowed to those entities
+func (c *llmCoder) splitTask(task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS) []taskPart {
+	entities := fileEntities(task, plan)
+	lines := int64(0)
+	if output, sized := plannedOutputTokens(task, plan); sized {
+		lines = output / costCeilingTokensPerLine
+	}
+	var others []string
+	if part, ok := task.Inputs[filePartInput].(filePart); ok {
+		others = part.Others
+	}
+
+	half := len(entities) / 2
+	groups := [][]string{entities[:half:half], entities[half:]}
+	parts := make([]taskPart, len(groups))
+	for i, group := range groups {
+		inputs := make(map[string]interface{}, len(task.Inputs)+1)
+		for key, value := range task.Inputs {
+			inputs[key] = value
+		}
+		names := make([]interface{}, len(group))
+		for j, name := range group {
+			names[j] = name
+		}
+		inputs["entities"] = names
+		inputs[filePartInput] = filePart{
+			Entities: group,
+			Others:   append(append([]string(nil), others...), groups[1-i]...),
+			Lines:    int(lines) * len(group) / len(entities),
+		}
+
+		part := task
+		part.Inputs = inputs
+		parts[i] = taskPart{task: part, filteredFCS: filteredFCS}
+		if filteredFCS != nil && c.contextFilter != nil {
+			parts[i].filteredFCS = c.contextFilter.Tighten(filteredFCS, group, 1)
+		}
+	}
+	return parts
+}
+
+// fileEntities returns the entities a file's task declares: its part's, or
+// those the task and the plan's file tree name
+func fileEntities(task models.GenerationTask, plan *models.GenerationPlan) []string {
+	if part, ok := task.Inputs[filePartInput].(filePart); ok {
+		return part.Entities
+	}
+
+	var entities []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			entities = append(entities, name)
+		}
+	}
+	switch names := task.Inputs["entities"].(type) {
+	case []interface{}:
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				add(s)
+			}
+		}
+	case []string:
+		for _, name := range names {
+			add(name)
+		}
+	}
+	if plan != nil {
+		target := filepath.Clean(task.TargetPath)
+		for _, file := range plan.FileTree.Files {
+			if filepath.Clean(file.Path) == target {
+				for _, name := range file.Entities {
+					add(name)
+				}
+			}
+		}
+	}
+	return entities
+}
+
+// plannedOutputTokens returns the expected size of a file's response, and
+// whether the plan sized the file; unsized files take a typical size
+func plannedOutputTokens(task models.GenerationTask, plan *models.GenerationPlan) (int64, bool) {
+	lines := plannedLines(plan, task.TargetPath)
+	if part, ok := task.Inputs[filePartInput].(filePart); ok {
+		lines = part.Lines
+	}
+	if lines > 0 {
+		return int64(lines * costCeilingTokensPerLine), true
+	}
+	return estimateSourceOutputTokens, false
+}
+
+// formatFilePart tells the model which part of a split file it generates
+func formatFilePart(task models.GenerationTask) string {
+	part, ok := task.Inputs[filePartInput].(filePart)
+	if !ok {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# File Part\n\n")
+	sb.WriteString("This file is too large for one response, so it is generated in parts that are merged into one file.\n")
+	sb.WriteString(fmt.Sprintf("Generate only the part declaring: %s\n", strings.Join(part.Entities, ", ")))
+	if len(part.Others) > 0 {
+		sb.WriteString(fmt.Sprintf("The other parts declare %s; use them where needed, but do not declare them or their methods again.\n", strings.Join(part.Others, ", ")))
+	}
+	if filepath.Ext(task.TargetPath) == ".go" {
+		sb.WriteString("Return this part as a complete Go file with the package clause and only the imports it uses.\n\n")
+	} else {
+		sb.WriteString("Return only this part; it is appended to the other parts.\n\n")
+	}
+	return sb.String()
+}
+
+// mergeFileParts joins the parts of a split file. Go parts keep the first
+// part's package clause and file comment, share one import block and keep
+// their declarations in order; other files are concatenated.
+func mergeFileParts(target string, parts []string) (string, error) {
+	if filepath.Ext(target) != ".go" {
+		var sb strings.Builder
+		for _, part := range parts {
+			sb.WriteString(strings.TrimRight(part, "\n"))
+			sb.WriteString("\n")
+		}
+		return sb.String(), nil
+	}
+
+	var header, pkg string
+	var imports []string
+	seen := make(map[string]bool)
+	var bodies []string
+	for i, part := range parts {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, target, part, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			return "", fmt.Errorf("part %d of %s is not valid Go: %w", i+1, target, err)
+		}
+		if i == 0 {
+			header = part[:fset.Position(file.Package).Offset]
+			pkg = file.Name.Name
+		} else if file.Name.Name != pkg {
+			return "", fmt.Errorf("part %d of %s declares package %s, not %s", i+1, target, file.Name.Name, pkg)
+		}
+
+		for _, imp := range file.Imports {
+			spec := imp.Path.Value
+			if imp.Name != nil {
+				spec = imp.Name.Name + " " + spec
+			}
+			if !seen[spec] {
+				seen[spec] = true
+				imports = append(imports, spec)
+			}
+		}
+
+		end := file.Name.End()
+		if len(file.Decls) > 0 {
+			end = file.Decls[len(file.Decls)-1].End()
+		}
+		bodies = append(bodies, strings.TrimSpace(part[fset.Position(end).Offset:]))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(header)
+	sb.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	if len(imports) > 0 {
+		sb.WriteString("import (\n")
+		for _, spec := range imports {
+			sb.WriteString("\t" + spec + "\n")
+		}
+		sb.WriteString(")\n\n")
+	}
+	sb.WriteString(strings.Join(bodies, "\n\n"))
+	sb.WriteString("\n")
+
+	// A body that does not parse is left for validation to report
+	formatted, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return sb.String(), nil
+	}
+	return string(formatted), nil
+}
@@ -0,0 +1,166 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// windowFCS has a User and an Order entity and many requirements, most of
+// which concern neither
+func windowFCS() *models.FinalClarifiedSpecification {
+	fcs := &models.FinalClarifiedSpecification{
+		ID: "fcs-1",
+		DataModel: models.DataModel{Entities: []models.Entity{
+			{Name: "User", Package: "store", Attributes: map[string]string{"id": "string", "email": "string"}},
+			{Name: "Order", Package: "store", Attributes: map[string]string{"id": "string", "user": "User"}},
+		}},
+	}
+	fcs.Requirements.Functional = append(fcs.Requirements.Functional,
+		models.FunctionalRequirement{ID: "FR-USER", Description: "Users sign up with an email address"})
+	for i := 0; i < 300; i++ {
+		fcs.Requirements.Functional = append(fcs.Requirements.Functional, models.FunctionalRequirement{
+			ID:          fmt.Sprintf("FR-%03d", i),
+			Description: strings.Repeat("The reporting dashboard aggregates shipment metrics per warehouse region. ", 2),
+		})
+	}
+	return fcs
+}
+
+func TestFitWindow_TightensContext(t *testing.T) {
+	task := models.GenerationTask{ID: "t1", Type: "generate_file", TargetPath: "internal/store/user.go"}
+	plan := &models.GenerationPlan{FileTree: models.FileTree{Files: []models.File{
+		{Path: "internal/store/user.go", Entities: []string{"User"}},
+	}}}
+	client := &pricedLLMClient{scriptedLLMClient: scriptedLLMClient{responses: []string{"package store"}}, provider: "openai", model: "gpt-4"}
+
+	coder, err := NewCoder(CoderConfig{LLMClient: client, MaxTokens: 2048})
+	require.NoError(t, err)
+	c := coder.(*llmCoder)
+	c.SetFCS(windowFCS())
+	filtered := c.filterContext(task, plan, windowFCS())
+	require.Greater(t, c.promptTokens(client, task, plan, filtered)+2048, int64(8192), "the full context does not fit gpt-4")
+
+	fit, err := c.fitWindow(client, task, plan, filtered)
+	require.NoError(t, err)
+	assert.False(t, fit.split)
+	assert.Equal(t, 2, fit.level, "dropping the requirements about other entities is enough")
+	assert.LessOrEqual(t, fit.tokens+2048, int64(8192))
+	require.Len(t, fit.filteredFCS.Requirements.Functional, 1)
+	assert.Equal(t, "FR-USER", fit.filteredFCS.Requirements.Functional[0].ID)
+	assert.Len(t, filtered.Requirements.Functional, 301, "tightening leaves the filtered FCS alone")
+
+	_, err = coder.GenerateFile(context.Background(), task, plan, windowFCS())
+	require.NoError(t, err)
+	require.Len(t, client.prompts, 1)
+	assert.NotContains(t, client.prompts[0], "FR-000")
+}
+
+func TestFitWindow_CannotFit(t *testing.T) {
+	task := models.GenerationTask{ID: "t1", Type: "generate_file", TargetPath: "internal/store/user.go"}
+	client := &pricedLLMClient{scriptedLLMClient: scriptedLLMClient{responses: []string{"package store"}}, provider: "openai", model: "gpt-4"}
+
+	// The preamble goes with every prompt and is never tightened
+	coder, err := NewCoder(CoderConfig{LLMClient: client, MaxTokens: 2048, Preamble: strings.Repeat("Follow the house style. ", 2000)})
+	require.NoError(t, err)
+
+	_, err = coder.GenerateFile(context.Background(), task, &models.GenerationPlan{}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the 8192-token context window of openai/gpt-4")
+	assert.Contains(t, err.Error(), "too few entities to split")
+	assert.Empty(t, client.prompts, "nothing is sent")
+}
+
+func TestGenerateFile_SplitsOverOutputBudget(t *testing.T) {
+	task := models.GenerationTask{ID: "t1", Type: "generate_file", TargetPath: "internal/store/store.go"}
+	plan := &models.GenerationPlan{FileTree: models.FileTree{Files: []models.File{
+		{Path: "internal/store/store.go", EstimatedLines: 400, Entities: []string{"User", "Order"}},
+	}}}
+	client := &scriptedLLMClient{responses: []string{
+		"// Package store keeps users and orders\npackage store\n\nimport \"errors\"\n\n// User is a customer\ntype User struct{ ID string }\n\nvar errNoUser = errors.New(\"no user\")\n",
+		"package store\n\nimport (\n\t\"errors\"\n\t\"time\"\n)\n\n// Order is a purchase\ntype Order struct {\n\tUser    *User\n\tPlaced  time.Time\n}\n\nvar errNoOrder = errors.New(\"no order\")\n",
+	}}
+
+	// 400 planned lines do not fit in 2048 output tokens
+	coder, err := NewCoder(CoderConfig{LLMClient: client, MaxTokens: 2048})
+	require.NoError(t, err)
+
+	patch, err := coder.GenerateFile(context.Background(), task, plan, windowFCS())
+	require.NoError(t, err)
+	require.Len(t, client.prompts, 2)
+	assert.Contains(t, client.prompts[0], "Generate only the part declaring: User\nThe other parts declare Order;")
+	assert.Contains(t, client.prompts[1], "Generate only the part declaring: Order\nThe other parts declare User;")
+
+	code := extractContentFromDiff(patch.Diff)
+	file, err := parser.ParseFile(token.NewFileSet(), "store.go", code, parser.ParseComments)
+	require.NoError(t, err, code)
+	assert.Equal(t, "store", file.Name.Name)
+	assert.Equal(t, "Package store keeps users and orders\n", file.Doc.Text())
+	require.Len(t, file.Imports, 2, "imports are merged")
+	assert.Contains(t, code, "type User struct")
+	assert.Contains(t, code, "type Order struct")
+	assert.Less(t, strings.Index(code, "type User"), strings.Index(code, "type Order"))
+}
+
+func TestMergeFileParts(t *testing.T) {
+	merged, err := mergeFileParts("Makefile", []string{"build:\n\tgo build\n\n", "test:\n\tgo test\n"})
+	require.NoError(t, err)
+	assert.Equal(t, "build:\n\tgo build\ntest:\n\tgo test\n", merged)
+
+	merged, err = mergeFileParts("store.go", []string{
+		"package store\n\nfunc A() {}\n",
+		"package store\n\nimport str \"strings\"\n\nfunc B() string { return str.TrimSpace(\"\") }\n",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "package store\n\nimport (\n\tstr \"strings\"\n)\n\nfunc A() {}\n\nfunc B() string { return str.TrimSpace(\"\") }\n", merged)
+
+	_, err = mergeFileParts("store.go", []string{"package store\n", "package other\n"})
+	assert.ErrorContains(t, err, "part 2 of store.go declares package other, not store")
+
+	_, err = mergeFileParts("store.go", []string{"package store\n", "here is the code:"})
+	assert.ErrorContains(t, err, "part 2 of store.go is not valid Go")
+}
+
+func TestContextFilter_Tighten(t *testing.T) {
+	fcs := windowFCS()
+	fcs.DataModel.Enums = []models.Enum{{Name: "Status", Values: []string{"open"}}, {Name: "Region", Values: []string{"eu"}}}
+	fcs.DataModel.Entities = append(fcs.DataModel.Entities, models.Entity{Name: "Invoice", Package: "billing", Attributes: map[string]string{"status": "Status", "order": "Order"}})
+	fcs.DataModel.Relationships = []models.Relationship{{From: "Order", To: "User", Type: "belongs_to"}, {From: "Invoice", To: "Order", Type: "belongs_to"}}
+	fcs.APIContracts = []models.APIContract{{Endpoint: "/users", Method: "GET"}}
+	cf := NewContextFilter(fcs)
+	filtered := cf.FilterForFile("internal/api/handler.go", nil, fcs)
+	require.Len(t, filtered.DataModel.Entities, 3)
+
+	tightened := cf.Tighten(filtered, []string{"Invoice"}, 1)
+	assert.Equal(t, []string{"Order", "Invoice"}, entityNames(tightened.DataModel.Entities), "direct dependencies stay")
+	assert.Len(t, tightened.DataModel.Relationships, 1)
+	require.Len(t, tightened.DataModel.Enums, 1)
+	assert.Equal(t, "Status", tightened.DataModel.Enums[0].Name)
+	assert.Len(t, tightened.Requirements.Functional, 301)
+
+	tightened = cf.Tighten(filtered, []string{"User"}, 2)
+	require.Len(t, tightened.Requirements.Functional, 1, "only requirements mentioning the entity stay")
+	assert.NotEmpty(t, tightened.APIContracts)
+
+	tightened = cf.Tighten(filtered, []string{"User"}, 3)
+	assert.Empty(t, tightened.Requirements.Functional)
+	assert.Empty(t, tightened.APIContracts)
+	assert.Empty(t, tightened.DataModel.Relationships)
+
+	assert.Len(t, filtered.DataModel.Entities, 3, "the filtered FCS is not modified")
+}
+
+func entityNames(entities []models.Entity) []string {
+	names := make([]string, len(entities))
+	for i, e := range entities {
+		names[i] = e.Name
+	}
+	return names
+}
@@ -0,0 +1,266 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// File classes that can be routed through the critic review pass or ensemble
+// generation.
+// Any other class value is treated as a path glob such as "internal/auth/*".
+const (
+	CriticClassHandlers    = "handlers"
+	CriticClassAuth        = "auth"
+	CriticClassConcurrency = "concurrency"
+)
+
+// Critic verdicts
+const (
+	criticVerdictApprove = "APPROVE"
+	criticVerdictRevise  = "REVISE"
+)
+
+// CriticResult is the outcome of reviewing a generated file
+type CriticResult struct {
+	Verdict  string   // APPROVE or REVISE
+	Findings []string // Issues reported by the reviewer
+	Content  string   // Final file content (the revision when accepted, otherwise the original)
+	Revised  bool     // True when the corrected version replaced the original
+}
+
+// critic reviews generated files of selected classes for bugs and security issues
+type critic struct {
+	client   llm.Client
+	classes  []string
+	audit    fsops.Logger
+	preamble string
+}
+
+// newCritic creates a critic for the given classes, or nil if none are selected
+func newCritic(client llm.Client, classes []string, audit fsops.Logger, preamble string) *critic {
+	if len(classes) == 0 {
+		return nil
+	}
+	return &critic{client: client, classes: classes, audit: audit, preamble: preamble}
+}
+
+// matchClasses returns the selected classes that targetPath or content belongs to
+func (cr *critic) matchClasses(targetPath, content string) []string {
+	return matchFileClasses(cr.classes, targetPath, content)
+}
+
+// matchFileClasses returns the classes that targetPath or content belongs to
+func matchFileClasses(classes []string, targetPath, content string) []string {
+	lowerPath := strings.ToLower(targetPath)
+	var matched []string
+
+	for _, class := range classes {
+		var hit bool
+		switch class {
+		case CriticClassHandlers:
+			hit = strings.Contains(lowerPath, "handler") ||
+				strings.Contains(content, "http.ResponseWriter") ||
+				strings.Contains(content, "http.HandlerFunc")
+		case CriticClassAuth:
+			hit = containsAny(lowerPath, "auth", "login", "session", "token", "jwt", "password") ||
+				containsAny(content, "bcrypt", "jwt.", "crypto/subtle", "Authorization")
+		case CriticClassConcurrency:
+			hit = containsAny(content, "go func", "sync.Mutex", "sync.RWMutex", "sync.WaitGroup", "errgroup.", "chan ", "atomic.")
+		default:
+			hit = matchGlob(class, targetPath)
+		}
+		if hit {
+			matched = append(matched, class)
+		}
+	}
+
+	return matched
+}
+
+// Review runs the critic pass on a generated file. Review failures keep the
+// original content so the critic can never block generation.
+func (cr *critic) Review(ctx context.Context, targetPath, content string, classes []string) CriticResult {
+	result := CriticResult{Verdict: criticVerdictApprove, Content: content}
+
+	cr.record(ctx, "critic_first_pass", "Generated file selected for critic review", map[string]interface{}{
+		"path":     targetPath,
+		"classes":  classes,
+		"checksum": ComputeFileChecksum(content),
+		"lines":    strings.Count(content, "\n") + 1,
+	})
+
+	response, err := cr.client.Generate(ctx, cr.buildPrompt(targetPath, content, classes))
+	if err != nil {
+		logctx.Logger(ctx).Warn().
+			Err(err).
+			Str("file", targetPath).
+			Msg("Critic review failed, keeping generated code")
+		cr.record(ctx, "critic_review_failed", "Critic review call failed, original kept", map[string]interface{}{
+			"path":  targetPath,
+			"error": err.Error(),
+		})
+		return result
+	}
+
+	verdict, findings, revised := parseCriticResponse(response)
+	result.Verdict = verdict
+	result.Findings = findings
+
+	rationale := "Critic approved the generated file"
+	details := map[string]interface{}{
+		"path":     targetPath,
+		"verdict":  verdict,
+		"findings": findings,
+	}
+
+	if verdict == criticVerdictRevise {
+		switch {
+		case strings.TrimSpace(revised) == "":
+			rationale = "Critic requested changes but returned no corrected file, original kept"
+		case strings.HasSuffix(targetPath, ".go") && !parsesAsGo(revised) && parsesAsGo(content):
+			rationale = "Critic revision does not parse as Go, original kept"
+		default:
+			result.Content = revised
+			result.Revised = true
+			rationale = "Critic revision replaced the generated file"
+			details["revised_checksum"] = ComputeFileChecksum(revised)
+			details["diff"] = fsops.NewUnifiedDiffEngine().Diff(targetPath, ensureTrailingNewline(content), ensureTrailingNewline(revised))
+		}
+	}
+
+	cr.record(ctx, "critic_review", rationale, details)
+
+	logctx.Logger(ctx).Info().
+		Str("file", targetPath).
+		Str("verdict", verdict).
+		Int("findings", len(findings)).
+		Bool("revised", result.Revised).
+		Msg("Critic review completed")
+
+	return result
+}
+
+// buildPrompt constructs the review prompt
+func (cr *critic) buildPrompt(targetPath, content string, classes []string) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are a senior Go reviewer auditing generated code before it is committed.\n\n")
+	sb.WriteString(fmt.Sprintf("# File\n%s (%s)\n\n", targetPath, strings.Join(classes, ", ")))
+	sb.WriteString("# Review Checklist\n")
+	sb.WriteString("- Logic bugs, unhandled errors and nil dereferences\n")
+	sb.WriteString("- Security issues: injection, missing input validation, secrets in code, unsafe comparisons of credentials\n")
+	sb.WriteString("- Concurrency issues: data races, leaked goroutines, missing context cancellation, deadlocks\n")
+	sb.WriteString("- HTTP handlers: status codes, request body limits, error responses that leak internals\n\n")
+	sb.WriteString("# Code\n")
+	sb.WriteString(content)
+	sb.WriteString("\n\n# Output Format\n\n")
+	sb.WriteString("Respond in exactly this format:\n")
+	sb.WriteString("VERDICT: APPROVE or REVISE\n")
+	sb.WriteString("FINDINGS:\n- one line per issue (omit when approving)\n")
+	sb.WriteString("CODE:\nthe complete corrected file (only when the verdict is REVISE)\n")
+
+	return withPreamble(cr.preamble, sb.String())
+}
+
+// record writes a critic decision to the audit log when one is configured
+func (cr *critic) record(ctx context.Context, decision, rationale string, details map[string]interface{}) {
+	if cr.audit == nil {
+		return
+	}
+
+	if err := cr.audit.LogDecision(ctx, models.DecisionLog{
+		LogEntry: models.LogEntry{
+			Level:     "info",
+			Component: "generate",
+			Operation: "critic",
+			Message:   rationale,
+			Context:   details,
+		},
+		Decision:  decision,
+		Rationale: rationale,
+	}); err != nil {
+		logctx.Logger(ctx).Warn().Err(err).Str("decision", decision).Msg("Failed to record critic decision")
+	}
+}
+
+// parseCriticResponse extracts the verdict, findings and corrected code.
+// Responses without a recognizable verdict are treated as approvals.
+func parseCriticResponse(response string) (string, []string, string) {
+	verdict := criticVerdictApprove
+	var findings []string
+
+	lines := strings.Split(strings.TrimSpace(response), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		upper := strings.ToUpper(trimmed)
+
+		switch {
+		case strings.HasPrefix(upper, "VERDICT:"):
+			if strings.Contains(upper, criticVerdictRevise) {
+				verdict = criticVerdictRevise
+			}
+		case strings.HasPrefix(trimmed, "- "):
+			findings = append(findings, strings.TrimPrefix(trimmed, "- "))
+		case strings.HasPrefix(upper, "CODE:"):
+			code := strings.Join(lines[i+1:], "\n")
+			return verdict, findings, stripCodeFence(code)
+		}
+	}
+
+	return verdict, findings, ""
+}
+
+// stripCodeFence removes a surrounding markdown code block if present
+func stripCodeFence(code string) string {
+	code = strings.TrimSpace(code)
+	if strings.HasPrefix(code, "```") {
+		if idx := strings.Index(code, "\n"); idx >= 0 {
+			code = code[idx+1:]
+		}
+		code = strings.TrimSuffix(strings.TrimSpace(code), "```")
+	}
+	return strings.TrimSpace(code)
+}
+
+// parsesAsGo reports whether src is syntactically valid Go
+func parsesAsGo(src string) bool {
+	_, err := parser.ParseFile(token.NewFileSet(), "", src, parser.AllErrors)
+	return err == nil
+}
+
+// matchGlob matches a path glob against the full path or its base name
+func matchGlob(pattern, targetPath string) bool {
+	targetPath = normalizePath(targetPath)
+	if ok, _ := path.Match(pattern, targetPath); ok {
+		return true
+	}
+	ok, _ := path.Match(pattern, path.Base(targetPath))
+	return ok
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, substrs ...string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureTrailingNewline terminates non-empty content with a newline
+func ensureTrailingNewline(content string) string {
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		return content + "\n"
+	}
+	return content
+}
@@ -0,0 +1,175 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingLLMClient always returns an error
+type failingLLMClient struct{ mockMergeLLMClient }
+
+func (f *failingLLMClient) Generate(_ context.Context, _ string) (string, error) {
+	return "", errors.New("provider unavailable")
+}
+
+func criticDecisions(logger *fsops.MemoryLogger) []models.DecisionLog {
+	var decisions []models.DecisionLog
+	for _, entry := range logger.GetEntries() {
+		if d, ok := entry.(models.DecisionLog); ok {
+			decisions = append(decisions, d)
+		}
+	}
+	return decisions
+}
+
+func TestCritic_MatchClasses(t *testing.T) {
+	cr := newCritic(&mockMergeLLMClient{}, []string{CriticClassHandlers, CriticClassAuth, CriticClassConcurrency, "internal/billing/*.go"}, nil, "")
+	require.NotNil(t, cr)
+
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []string
+	}{
+		{"handler by path", "internal/api/user_handler.go", "package api\n", []string{CriticClassHandlers}},
+		{"handler by content", "internal/api/routes.go", "func list(w http.ResponseWriter, r *http.Request) {}", []string{CriticClassHandlers}},
+		{"auth by path", "internal/auth/service.go", "package auth\n", []string{CriticClassAuth}},
+		{"concurrency by content", "internal/worker/pool.go", "var mu sync.Mutex\n", []string{CriticClassConcurrency}},
+		{"glob", "internal/billing/invoice.go", "package billing\n", []string{"internal/billing/*.go"}},
+		{"several classes", "internal/auth/handler.go", "go func() {}()", []string{CriticClassHandlers, CriticClassAuth, CriticClassConcurrency}},
+		{"no match", "internal/models/user.go", "package models\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cr.matchClasses(tt.path, tt.content))
+		})
+	}
+}
+
+func TestNewCritic_NoClasses(t *testing.T) {
+	assert.Nil(t, newCritic(&mockMergeLLMClient{}, nil, nil, ""))
+}
+
+func TestParseCriticResponse(t *testing.T) {
+	verdict, findings, code := parseCriticResponse("VERDICT: APPROVE\n")
+	assert.Equal(t, criticVerdictApprove, verdict)
+	assert.Empty(t, findings)
+	assert.Empty(t, code)
+
+	verdict, findings, code = parseCriticResponse("VERDICT: REVISE\nFINDINGS:\n- unchecked error\n- missing body limit\nCODE:\n```go\npackage api\n```\n")
+	assert.Equal(t, criticVerdictRevise, verdict)
+	assert.Equal(t, []string{"unchecked error", "missing body limit"}, findings)
+	assert.Equal(t, "package api", code)
+
+	verdict, _, _ = parseCriticResponse("Looks fine to me.")
+	assert.Equal(t, criticVerdictApprove, verdict, "unrecognized responses are approvals")
+}
+
+func TestCritic_Review(t *testing.T) {
+	original := "package api\n\nfunc Handle() {}\n"
+
+	tests := []struct {
+		name          string
+		client        llm.Client
+		wantVerdict   string
+		wantContent   string
+		wantRevised   bool
+		wantDecisions []string
+	}{
+		{
+			name:          "approve keeps content",
+			client:        &mockMergeLLMClient{response: "VERDICT: APPROVE"},
+			wantVerdict:   criticVerdictApprove,
+			wantContent:   original,
+			wantDecisions: []string{"critic_first_pass", "critic_review"},
+		},
+		{
+			name:          "revision replaces content",
+			client:        &mockMergeLLMClient{response: "VERDICT: REVISE\nFINDINGS:\n- missing doc\nCODE:\n```go\npackage api\n\n// Handle handles requests\nfunc Handle() {}\n```"},
+			wantVerdict:   criticVerdictRevise,
+			wantContent:   "package api\n\n// Handle handles requests\nfunc Handle() {}",
+			wantRevised:   true,
+			wantDecisions: []string{"critic_first_pass", "critic_review"},
+		},
+		{
+			name:          "unparsable revision keeps original",
+			client:        &mockMergeLLMClient{response: "VERDICT: REVISE\nFINDINGS:\n- bug\nCODE:\npackage api\n\nfunc Handle( {"},
+			wantVerdict:   criticVerdictRevise,
+			wantContent:   original,
+			wantDecisions: []string{"critic_first_pass", "critic_review"},
+		},
+		{
+			name:          "revision without code keeps original",
+			client:        &mockMergeLLMClient{response: "VERDICT: REVISE\nFINDINGS:\n- bug"},
+			wantVerdict:   criticVerdictRevise,
+			wantContent:   original,
+			wantDecisions: []string{"critic_first_pass", "critic_review"},
+		},
+		{
+			name:          "review failure keeps original",
+			client:        &failingLLMClient{},
+			wantVerdict:   criticVerdictApprove,
+			wantContent:   original,
+			wantDecisions: []string{"critic_first_pass", "critic_review_failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := fsops.NewMemoryLogger()
+			cr := newCritic(tt.client, []string{CriticClassHandlers}, audit, "")
+
+			result := cr.Review(context.Background(), "internal/api/handler.go", original, []string{CriticClassHandlers})
+
+			assert.Equal(t, tt.wantVerdict, result.Verdict)
+			assert.Equal(t, tt.wantContent, result.Content)
+			assert.Equal(t, tt.wantRevised, result.Revised)
+
+			var got []string
+			for _, d := range criticDecisions(audit) {
+				assert.Equal(t, "critic", d.LogEntry.Operation)
+				assert.Equal(t, "internal/api/handler.go", d.LogEntry.Context["path"])
+				got = append(got, d.Decision)
+			}
+			assert.Equal(t, tt.wantDecisions, got)
+		})
+	}
+}
+
+func TestCritic_ReviewRecordsRevisionDiff(t *testing.T) {
+	audit := fsops.NewMemoryLogger()
+	client := &mockMergeLLMClient{response: "VERDICT: REVISE\n- unchecked error\nCODE:\npackage api\n\nfunc Handle() error { return nil }\n"}
+	cr := newCritic(client, []string{CriticClassHandlers}, audit, "")
+
+	result := cr.Review(context.Background(), "handler.go", "package api\n\nfunc Handle() {}\n", []string{CriticClassHandlers})
+	require.True(t, result.Revised)
+
+	decisions := criticDecisions(audit)
+	require.Len(t, decisions, 2)
+	review := decisions[1]
+	assert.Equal(t, []string{"unchecked error"}, review.LogEntry.Context["findings"])
+	assert.Contains(t, review.LogEntry.Context["diff"], "-func Handle() {}")
+	assert.Contains(t, review.LogEntry.Context["diff"], "+func Handle() error { return nil }")
+	assert.NotEqual(t, decisions[0].LogEntry.Context["checksum"], review.LogEntry.Context["revised_checksum"])
+
+	require.Len(t, client.prompts, 1)
+	assert.Contains(t, client.prompts[0], "handler.go (handlers)")
+}
+
+func TestNewCoder_InvalidCriticClass(t *testing.T) {
+	_, err := NewCoder(CoderConfig{
+		LLMClient:     &mockMergeLLMClient{},
+		CriticClasses: []string{"internal/[auth"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid critic class pattern")
+}
@@ -0,0 +1,57 @@
+package generate
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/promptguard"
+)
+
+// writeCrossCuttingCodeGuidelines writes the middleware rules for the file at
+// targetPath. Files in the middleware package implement the concerns; every
+// other file leaves them to the middleware and wraps registered routes.
+func writeCrossCuttingCodeGuidelines(sb *strings.Builder, cc models.CrossCutting, targetPath string) {
+	if !cc.Enabled() {
+		return
+	}
+
+	pkg := cc.MiddlewarePackage()
+	alias := path.Base(pkg)
+	ordered := cc.Ordered()
+
+	sb.WriteString("# Cross-Cutting Concerns\n\n")
+	if path.Dir(filepath.ToSlash(targetPath)) == pkg {
+		sb.WriteString(fmt.Sprintf("This file is in the middleware package %s. Declare one exported middleware per concern, "+
+			"each with the signature func(next http.Handler) http.Handler:\n", promptguard.Inline(pkg)))
+		for _, concern := range ordered {
+			sb.WriteString(fmt.Sprintf("- %s for the %s concern\n", concern.Func(), concern.Name))
+		}
+		sb.WriteString("- Read the settings and excluded paths of each concern from the project context; skip excluded paths\n")
+		sb.WriteString("- Take configuration through constructor parameters or options, not global variables\n\n")
+		return
+	}
+
+	funcs := make([]string, 0, len(ordered))
+	for _, concern := range ordered {
+		funcs = append(funcs, fmt.Sprintf("%s.%s", alias, concern.Func()))
+	}
+	sb.WriteString(fmt.Sprintf("Package %s provides middleware for these concerns: %s.\n",
+		promptguard.Inline(pkg), strings.Join(funcs, ", ")))
+	sb.WriteString("- Do not implement authentication, logging, tracing, CORS or rate limiting inside handlers\n")
+	sb.WriteString("- If this file registers routes, wrap every handler with all of the middleware above, ")
+	sb.WriteString("outermost first in the order listed\n\n")
+}
+
+// sortedSettingKeys returns the keys of a concern's settings in order
+func sortedSettingKeys(settings map[string]string) []string {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,447 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/promptguard"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// docFileName is the file holding a generated package's documentation
+const docFileName = "doc.go"
+
+// maxDocSymbols caps the exported symbols listed in a documentation prompt
+const maxDocSymbols = 40
+
+// DocWriter writes package-level documentation for generated packages
+type DocWriter interface {
+	// Generate returns a doc.go patch for each package with generated code
+	// that has no package comment yet. Files of those packages that were not
+	// regenerated are read from outputDir.
+	Generate(ctx context.Context, codePatches []models.Patch, fcs *models.FinalClarifiedSpecification, outputDir string) ([]models.Patch, error)
+}
+
+// DocWriterConfig contains configuration for creating a doc writer
+type DocWriterConfig struct {
+	LLMClient llm.Client
+
+	// Preamble is organization-wide guidance placed ahead of every prompt
+	Preamble string
+}
+
+// llmDocWriter implements DocWriter, asking the LLM for each package's
+// documentation and falling back to a summary of its exported API
+type llmDocWriter struct {
+	client   llm.Client
+	preamble string
+}
+
+// NewDocWriter creates a new DocWriter instance
+func NewDocWriter(cfg DocWriterConfig) (DocWriter, error) {
+	if cfg.LLMClient == nil {
+		return nil, fmt.Errorf("LLM client is required")
+	}
+	return &llmDocWriter{client: cfg.LLMClient, preamble: cfg.Preamble}, nil
+}
+
+// exportedSymbol is an exported declaration shown in a package's documentation
+type exportedSymbol struct {
+	Kind    string // type, func, const or var
+	Name    string
+	Decl    string // Signature or type kind, e.g. "func New(cfg Config) *Store" or "struct"
+	Summary string // First sentence of the doc comment
+}
+
+// docPackage is a generated package and what its documentation is derived from
+type docPackage struct {
+	dir     string
+	name    string
+	purpose string
+	symbols []exportedSymbol
+}
+
+// Generate implements DocWriter. Packages are documented in directory order;
+// a package whose documentation cannot be generated gets the fallback.
+func (w *llmDocWriter) Generate(ctx context.Context, codePatches []models.Patch, fcs *models.FinalClarifiedSpecification, outputDir string) ([]models.Patch, error) {
+	packages := docPackages(codePatches, fcs, outputDir)
+
+	patches := make([]models.Patch, 0, len(packages))
+	for _, pkg := range packages {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("documentation generation interrupted: %w", err)
+		}
+
+		content, err := w.generateDoc(ctx, pkg)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("documentation generation interrupted: %w", ctx.Err())
+			}
+			logctx.Logger(ctx).Warn().
+				Err(err).
+				Str("package", pkg.dir).
+				Msg("Failed to generate package documentation, using exported API summary")
+			content = renderPackageDoc(pkg)
+		}
+
+		target := path.Join(pkg.dir, docFileName)
+		patches = append(patches, models.Patch{
+			TargetFile: target,
+			Diff:       newFileDiff(target, content),
+			AppliedAt:  time.Now(),
+			Reversible: true,
+		})
+	}
+
+	logctx.Logger(ctx).Debug().
+		Int("packages", len(patches)).
+		Msg("Package documentation generated")
+
+	return patches, nil
+}
+
+// generateDoc asks the LLM for a package's doc.go and checks that it only
+// holds a package comment and clause
+func (w *llmDocWriter) generateDoc(ctx context.Context, pkg docPackage) (string, error) {
+	response, err := w.client.Generate(ctx, w.buildDocPrompt(pkg))
+	if err != nil {
+		return "", fmt.Errorf("LLM documentation generation failed: %w", err)
+	}
+	content := cleanDocResponse(response)
+	if err := checkDocFile(content, pkg.name); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// buildDocPrompt constructs the LLM prompt for a package's doc.go
+func (w *llmDocWriter) buildDocPrompt(pkg docPackage) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are an expert Go developer writing package documentation for godoc.\n\n")
+	sb.WriteString("# Task\n")
+	sb.WriteString(fmt.Sprintf("Write %s for package %s (directory %s).\n\n", docFileName, pkg.name, pkg.dir))
+
+	sb.WriteString(promptguard.Instructions)
+	if pkg.purpose != "" {
+		sb.WriteString(fmt.Sprintf("# Purpose\n%s\n\n", promptguard.Inline(pkg.purpose)))
+	}
+
+	sb.WriteString("# Exported API\n")
+	var api strings.Builder
+	for _, sym := range pkg.symbols {
+		api.WriteString(sym.Decl)
+		if sym.Summary != "" {
+			api.WriteString(" // " + sym.Summary)
+		}
+		api.WriteString("\n")
+	}
+	if api.Len() == 0 {
+		api.WriteString("(none)\n")
+	}
+	sb.WriteString(promptguard.Fence(api.String()))
+	sb.WriteString("\n")
+
+	sb.WriteString("# Requirements\n")
+	sb.WriteString(fmt.Sprintf("- Start the comment with \"Package %s\" followed by what the package is for\n", pkg.name))
+	sb.WriteString("- Describe the key types and how they fit together, using [Name] doc links\n")
+	sb.WriteString("- Include a short example usage as an indented code block using only the API above\n")
+	sb.WriteString(fmt.Sprintf("- The file contains only the doc comment and `package %s`: no imports or declarations\n\n", pkg.name))
+
+	sb.WriteString("Return ONLY the Go source code, without markdown formatting or explanations.\n")
+
+	return withPreamble(w.preamble, sb.String())
+}
+
+// cleanDocResponse removes markdown formatting around a generated doc.go
+func cleanDocResponse(response string) string {
+	response = strings.TrimSpace(response)
+	if strings.HasPrefix(response, "```") {
+		response = strings.TrimPrefix(response, "```go")
+		response = strings.TrimPrefix(response, "```")
+		response = strings.TrimSuffix(response, "```")
+	}
+	return strings.TrimSpace(response)
+}
+
+// checkDocFile reports why content is not a documentation-only file for pkg
+func checkDocFile(content, pkg string) error {
+	file, err := parser.ParseFile(token.NewFileSet(), docFileName, content, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("generated %s does not parse: %w", docFileName, err)
+	}
+	if file.Name.Name != pkg {
+		return fmt.Errorf("generated %s declares package %s, want %s", docFileName, file.Name.Name, pkg)
+	}
+	if file.Doc == nil {
+		return fmt.Errorf("generated %s has no package comment", docFileName)
+	}
+	if len(file.Decls) > 0 {
+		return fmt.Errorf("generated %s contains declarations", docFileName)
+	}
+	return nil
+}
+
+// renderPackageDoc renders documentation from the package's purpose and
+// exported API, for when the LLM's is unusable
+func renderPackageDoc(pkg docPackage) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("// Package %s was generated by GoCreator.\n", pkg.name))
+	if purpose := strings.TrimSpace(pkg.purpose); purpose != "" {
+		if !strings.HasSuffix(purpose, ".") {
+			purpose += "."
+		}
+		sb.WriteString("//\n")
+		for _, line := range strings.Split(purpose, "\n") {
+			sb.WriteString(strings.TrimRight("// "+line, " ") + "\n")
+		}
+	}
+
+	var types, funcs []exportedSymbol
+	for _, sym := range pkg.symbols {
+		switch sym.Kind {
+		case "type":
+			types = append(types, sym)
+		case "func":
+			funcs = append(funcs, sym)
+		}
+	}
+	writeList := func(title string, symbols []exportedSymbol) {
+		if len(symbols) == 0 {
+			return
+		}
+		sb.WriteString("//\n// # " + title + "\n//\n")
+		for _, sym := range symbols {
+			line := fmt.Sprintf("//   - [%s]", sym.Name)
+			if sym.Summary != "" {
+				line += ": " + sym.Summary
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	writeList("Key Types", types)
+	writeList("Functions", funcs)
+
+	sb.WriteString(fmt.Sprintf("package %s\n", pkg.name))
+	return sb.String()
+}
+
+// docPackages returns the packages of the generated code that need a doc.go,
+// sorted by directory. Commands, packages whose plan includes doc.go and
+// packages with a file that already has a package comment are skipped, as
+// are packages that contain only tests.
+func docPackages(codePatches []models.Patch, fcs *models.FinalClarifiedSpecification, outputDir string) []docPackage {
+	sources := make(map[string]map[string]string) // Directory -> file name -> content
+	for _, patch := range codePatches {
+		file := filepath.ToSlash(filepath.Clean(patch.TargetFile))
+		if !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		dir := path.Dir(file)
+		if sources[dir] == nil {
+			sources[dir] = make(map[string]string)
+		}
+		sources[dir][path.Base(file)] = extractContentFromDiff(patch.Diff)
+	}
+
+	var packages []docPackage
+	for dir, files := range sources {
+		if _, planned := files[docFileName]; planned {
+			continue
+		}
+		addUnchangedSources(filepath.Join(outputDir, filepath.FromSlash(dir)), files)
+
+		pkg, ok := inspectPackage(dir, files)
+		if !ok {
+			continue
+		}
+		pkg.purpose = packagePurpose(fcs, dir, pkg.name)
+		packages = append(packages, pkg)
+	}
+
+	sort.Slice(packages, func(i, j int) bool { return packages[i].dir < packages[j].dir })
+	return packages
+}
+
+// addUnchangedSources adds the package's files on disk that were not
+// regenerated, so incremental runs see the whole package. The doc.go of an
+// earlier run is not one of them.
+func addUnchangedSources(dir string, files map[string]string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == docFileName || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if _, ok := files[name]; ok {
+			continue
+		}
+		//nolint:gosec // G304: Reading a generated package in the output directory
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			files[name] = string(content)
+		}
+	}
+}
+
+// inspectPackage collects a package's name and exported API from its source
+// files. ok is false for commands, packages that already document
+// themselves and packages none of whose files parse.
+func inspectPackage(dir string, files map[string]string) (docPackage, bool) {
+	pkg := docPackage{dir: dir}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fset := token.NewFileSet()
+	for _, name := range names {
+		file, err := parser.ParseFile(fset, name, files[name], parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		if file.Doc != nil || file.Name.Name == "main" {
+			return docPackage{}, false
+		}
+		if pkg.name == "" {
+			pkg.name = file.Name.Name
+		}
+		pkg.symbols = append(pkg.symbols, exportedSymbols(fset, file)...)
+	}
+	if pkg.name == "" {
+		return docPackage{}, false
+	}
+
+	if len(pkg.symbols) > maxDocSymbols {
+		pkg.symbols = pkg.symbols[:maxDocSymbols]
+	}
+	return pkg, true
+}
+
+// exportedSymbols returns the exported top-level types, functions, constants
+// and variables of a file in source order. Methods are left to the types.
+func exportedSymbols(fset *token.FileSet, file *ast.File) []exportedSymbol {
+	var symbols []exportedSymbol
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil || !d.Name.IsExported() {
+				continue
+			}
+			signature := &ast.FuncDecl{Name: d.Name, Type: d.Type}
+			symbols = append(symbols, exportedSymbol{
+				Kind:    "func",
+				Name:    d.Name.Name,
+				Decl:    renderNode(fset, signature),
+				Summary: firstSentence(d.Doc),
+			})
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if !s.Name.IsExported() {
+						continue
+					}
+					comment := s.Doc
+					if comment == nil && len(d.Specs) == 1 {
+						comment = d.Doc
+					}
+					symbols = append(symbols, exportedSymbol{
+						Kind:    "type",
+						Name:    s.Name.Name,
+						Decl:    "type " + s.Name.Name + " " + typeKind(s.Type),
+						Summary: firstSentence(comment),
+					})
+				case *ast.ValueSpec:
+					kind := "var"
+					if d.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, name := range s.Names {
+						if name.IsExported() {
+							symbols = append(symbols, exportedSymbol{Kind: kind, Name: name.Name, Decl: kind + " " + name.Name})
+						}
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// typeKind describes a type's underlying form without its fields
+func typeKind(expr ast.Expr) string {
+	switch expr.(type) {
+	case *ast.StructType:
+		return "struct"
+	case *ast.InterfaceType:
+		return "interface"
+	case *ast.FuncType:
+		return "func"
+	case *ast.MapType:
+		return "map"
+	case *ast.ArrayType:
+		return "slice"
+	case *ast.ChanType:
+		return "chan"
+	default:
+		return "defined type"
+	}
+}
+
+// renderNode prints an AST node as Go source
+func renderNode(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// firstSentence returns the first sentence of a doc comment
+func firstSentence(comment *ast.CommentGroup) string {
+	if comment == nil {
+		return ""
+	}
+	return new(doc.Package).Synopsis(comment.Text())
+}
+
+// packagePurpose returns the FCS purpose of the package in dir, matched by
+// path (relative or module-qualified) and then by name
+func packagePurpose(fcs *models.FinalClarifiedSpecification, dir, name string) string {
+	if fcs == nil {
+		return ""
+	}
+	for _, pkg := range fcs.Architecture.Packages {
+		p := strings.Trim(filepath.ToSlash(pkg.Path), "./")
+		if p != "" && (p == dir || strings.HasSuffix(p, "/"+dir)) {
+			return pkg.Purpose
+		}
+	}
+	for _, pkg := range fcs.Architecture.Packages {
+		if pkg.Name == name {
+			return pkg.Purpose
+		}
+	}
+	return ""
+}
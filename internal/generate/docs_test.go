@@ -0,0 +1,143 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const storeSource = `package store
+
+// Store keeps users in memory. It is safe for concurrent use.
+type Store struct{}
+
+// Option configures a Store
+type Option func(*Store)
+
+// ErrNotFound is returned for unknown users
+var ErrNotFound = errNotFound()
+
+// New creates an empty Store.
+func New(opts ...Option) *Store { return &Store{} }
+
+// Get returns a user by ID
+func (s *Store) Get(id string) (string, error) { return "", nil }
+
+func errNotFound() error { return nil }
+`
+
+func docPatch(file, content string) models.Patch {
+	return models.Patch{TargetFile: file, Diff: newFileDiff(file, content)}
+}
+
+func docFCS() *models.FinalClarifiedSpecification {
+	return &models.FinalClarifiedSpecification{
+		Architecture: models.Architecture{Packages: []models.Package{
+			{Name: "store", Path: "github.com/acme/app/internal/store", Purpose: "In-memory user storage"},
+		}},
+	}
+}
+
+func TestDocPackages(t *testing.T) {
+	outputDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(outputDir, "internal", "store"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "internal", "store", "user.go"),
+		[]byte("package store\n\n// User is a stored user\ntype User struct{}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "internal", "store", docFileName),
+		[]byte("// Package store is stale.\npackage store\n"), 0o600))
+
+	packages := docPackages([]models.Patch{
+		docPatch("internal/store/store.go", storeSource),
+		docPatch("internal/store/store_test.go", "// Package store tests.\npackage store\n"),
+		docPatch("cmd/app/main.go", "package main\n\nfunc main() {}\n"),
+		docPatch("internal/api/api.go", "// Package api serves HTTP.\npackage api\n"),
+		docPatch("internal/plain/plain.go", "package plain\n"),
+		docPatch("internal/plain/doc.go", "// Package plain is planned.\npackage plain\n"),
+		docPatch("README.md", "# App\n"),
+	}, docFCS(), outputDir)
+
+	require.Len(t, packages, 1, "commands, documented packages and planned doc.go files are skipped")
+	pkg := packages[0]
+	assert.Equal(t, "internal/store", pkg.dir)
+	assert.Equal(t, "store", pkg.name)
+	assert.Equal(t, "In-memory user storage", pkg.purpose)
+
+	var names []string
+	for _, sym := range pkg.symbols {
+		names = append(names, sym.Name)
+	}
+	assert.Equal(t, []string{"Store", "Option", "ErrNotFound", "New", "User"}, names, "unchanged files on disk are included")
+	assert.Equal(t, "func New(opts ...Option) *Store", pkg.symbols[3].Decl)
+	assert.Equal(t, "Store keeps users in memory.", pkg.symbols[0].Summary)
+	assert.Equal(t, "type Option func", pkg.symbols[1].Decl)
+}
+
+func TestRenderPackageDoc(t *testing.T) {
+	content := renderPackageDoc(docPackage{
+		name:    "store",
+		purpose: "In-memory user storage",
+		symbols: []exportedSymbol{
+			{Kind: "type", Name: "Store", Summary: "Store keeps users in memory."},
+			{Kind: "var", Name: "ErrNotFound"},
+			{Kind: "func", Name: "New"},
+		},
+	})
+
+	require.NoError(t, checkDocFile(content, "store"))
+	assert.Equal(t, `// Package store was generated by GoCreator.
+//
+// In-memory user storage.
+//
+// # Key Types
+//
+//   - [Store]: Store keeps users in memory.
+//
+// # Functions
+//
+//   - [New]
+package store
+`, content)
+}
+
+func TestCheckDocFile(t *testing.T) {
+	assert.NoError(t, checkDocFile("// Package store keeps users.\npackage store\n", "store"))
+	assert.ErrorContains(t, checkDocFile("package store\n", "store"), "no package comment")
+	assert.ErrorContains(t, checkDocFile("// Package api serves HTTP.\npackage api\n", "store"), "declares package api")
+	assert.ErrorContains(t, checkDocFile("// Package store keeps users.\npackage store\n\nvar X = 1\n", "store"), "declarations")
+	assert.ErrorContains(t, checkDocFile("Package store keeps users.", "store"), "does not parse")
+}
+
+func TestDocWriter_Generate(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{
+		"```go\n// Package store keeps users in memory.\n//\n//\ts := store.New()\npackage store\n```",
+		"Here is the documentation you asked for.",
+	}}
+	writer, err := NewDocWriter(DocWriterConfig{LLMClient: client})
+	require.NoError(t, err)
+
+	patches, err := writer.Generate(context.Background(), []models.Patch{
+		docPatch("internal/store/store.go", storeSource),
+		docPatch("internal/users/users.go", "package users\n\n// List returns all users\nfunc List() []string { return nil }\n"),
+	}, docFCS(), t.TempDir())
+	require.NoError(t, err)
+
+	require.Len(t, patches, 2)
+	assert.Equal(t, "internal/store/doc.go", patches[0].TargetFile)
+	assert.Equal(t, "// Package store keeps users in memory.\n//\n//\ts := store.New()\npackage store\n", extractContentFromDiff(patches[0].Diff))
+	assert.Equal(t, "internal/users/doc.go", patches[1].TargetFile)
+	assert.Contains(t, extractContentFromDiff(patches[1].Diff), "//   - [List]: List returns all users", "unusable responses fall back to the API summary")
+
+	require.Len(t, client.prompts, 2)
+	assert.Contains(t, client.prompts[0], "In-memory user storage")
+	assert.Contains(t, client.prompts[0], "func New(opts ...Option) *Store // New creates an empty Store.")
+}
+
+func TestNewDocWriter_RequiresClient(t *testing.T) {
+	_, err := NewDocWriter(DocWriterConfig{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,149 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// emitInstruction tells the model how to deliver a file through the emit_file
+// tool, and which planned files it may write in the same response
+func emitInstruction(target string, companions []string) string {
+	var sb strings.Builder
+	sb.WriteString("\n# Output\n\n")
+	sb.WriteString(fmt.Sprintf("Deliver the file by calling the %s tool with the path %q and the complete file content.\n", llm.EmitFileTool, target))
+	if len(companions) > 0 {
+		sb.WriteString("If it helps to write them together (for example a type and its tests), you may also emit these planned files, each with its own call:\n")
+		for _, companion := range companions {
+			sb.WriteString(fmt.Sprintf("- %s\n", companion))
+		}
+		sb.WriteString("Do not emit any other files.\n")
+	}
+	return sb.String()
+}
+
+// emitCode generates a file through the emit_file tool. Planned files in the
+// same directory that have not been requested yet may be emitted with it; they
+// are kept for their own tasks, which then make no request. A provider without
+// tool use returns llm.ErrToolUseUnsupported unwrapped.
+func (c *llmCoder) emitCode(ctx context.Context, emitter llm.FileEmittingClient, task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS) (string, error) {
+	target := filepath.ToSlash(filepath.Clean(task.TargetPath))
+
+	// Only the primary model writes ahead: ensemble candidates are compared
+	// file by file
+	var companions []string
+	if llm.Client(emitter) == c.client {
+		companions = c.companions(plan, target)
+	}
+
+	messages := c.buildCodeGenerationPromptWithCache(task, plan, filteredFCS)
+	messages = append([]llm.CacheableMessage(nil), messages...)
+	last := &messages[len(messages)-1]
+	last.Content += emitInstruction(target, companions)
+	c.recordMessages(task.ID, target, messages)
+
+	files, err := emitter.EmitFiles(ctx, messages)
+	if errors.Is(err, llm.ErrToolUseUnsupported) {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("LLM code generation failed: %w", err)
+	}
+
+	allowed := make(map[string]bool, len(companions))
+	for _, companion := range companions {
+		allowed[companion] = true
+	}
+
+	var code, other string
+	found := false
+	others := 0
+	for _, file := range files {
+		path := filepath.ToSlash(filepath.Clean(file.Path))
+		content := strings.TrimSpace(file.Content)
+		switch {
+		case path == target:
+			code, found = content, true
+		case allowed[path]:
+			c.keepEmitted(path, content)
+			c.recordMessages(task.ID, path, messages)
+		default:
+			other = content
+			others++
+			logctx.Logger(ctx).Debug().
+				Str("target_path", target).
+				Str("emitted_path", path).
+				Msg("Ignoring unplanned emitted file")
+		}
+	}
+
+	if !found {
+		// A single file under another name is the requested file misnamed
+		if others != 1 {
+			return "", fmt.Errorf("LLM code generation failed: %s was not emitted", target)
+		}
+		code = other
+	}
+	return code, nil
+}
+
+// companions returns the planned files in target's directory that have not
+// been requested yet, and marks target as requested
+func (c *llmCoder) companions(plan *models.GenerationPlan, target string) []string {
+	c.emittedMu.Lock()
+	defer c.emittedMu.Unlock()
+
+	if c.requested == nil {
+		c.requested = make(map[string]bool)
+	}
+	c.requested[target] = true
+
+	var companions []string
+	for _, task := range c.getAllTasks(plan) {
+		if !task.WritesFile() || task.TargetPath == "" {
+			continue
+		}
+		path := filepath.ToSlash(filepath.Clean(task.TargetPath))
+		if path != target && filepath.Dir(path) == filepath.Dir(target) && !c.requested[path] {
+			companions = append(companions, path)
+		}
+	}
+	sort.Strings(companions)
+	return companions
+}
+
+// keepEmitted stores a file emitted ahead of its own task, unless that task
+// was requested in the meantime
+func (c *llmCoder) keepEmitted(path, code string) {
+	c.emittedMu.Lock()
+	defer c.emittedMu.Unlock()
+
+	if c.requested[path] {
+		return
+	}
+	if c.emitted == nil {
+		c.emitted = make(map[string]string)
+	}
+	c.requested[path] = true
+	c.emitted[path] = code
+}
+
+// takeEmitted returns and forgets the code emitted for path ahead of its task
+func (c *llmCoder) takeEmitted(path string) (string, bool) {
+	c.emittedMu.Lock()
+	defer c.emittedMu.Unlock()
+
+	path = filepath.ToSlash(filepath.Clean(path))
+	code, ok := c.emitted[path]
+	if ok {
+		delete(c.emitted, path)
+	}
+	return code, ok
+}
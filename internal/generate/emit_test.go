@@ -0,0 +1,103 @@
+package generate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emittingLLMClient is a scriptedLLMClient with tool use, returning one
+// scripted set of files per EmitFiles call
+type emittingLLMClient struct {
+	scriptedLLMClient
+	files   [][]llm.EmittedFile
+	err     error
+	emitted []string // Last message of each EmitFiles call
+}
+
+func (e *emittingLLMClient) EmitFiles(_ context.Context, messages []llm.CacheableMessage) ([]llm.EmittedFile, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.emitted = append(e.emitted, messages[len(messages)-1].Content)
+	files := e.files[0]
+	e.files = e.files[1:]
+	return files, nil
+}
+
+func emitPlan() *models.GenerationPlan {
+	return &models.GenerationPlan{
+		ID: "emit_plan",
+		Phases: []models.GenerationPhase{{Name: "models", Order: 1, Tasks: []models.GenerationTask{
+			{ID: "user", Type: "generate_file", TargetPath: "internal/models/user.go"},
+			{ID: "user_test", Type: "generate_file", TargetPath: "internal/models/user_test.go"},
+			{ID: "service", Type: "generate_file", TargetPath: "internal/service/service.go"},
+		}}},
+	}
+}
+
+func TestGenerateFile_EmitsCompanionFiles(t *testing.T) {
+	client := &emittingLLMClient{files: [][]llm.EmittedFile{
+		{
+			{Path: "internal/models/user.go", Content: "package models\n\ntype User struct{}\n"},
+			{Path: "./internal/models/user_test.go", Content: "package models\n"},
+			{Path: "internal/service/service.go", Content: "package service\n"},
+		},
+		{{Path: "internal/service/service.go", Content: "package service\n"}},
+	}}
+	coder, err := NewCoder(CoderConfig{LLMClient: client})
+	require.NoError(t, err)
+	plan := emitPlan()
+	tasks := plan.Phases[0].Tasks
+
+	patch, err := coder.GenerateFile(context.Background(), tasks[0], plan, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "package models\n\ntype User struct{}\n", extractContentFromDiff(patch.Diff))
+	require.Len(t, client.emitted, 1)
+	assert.Contains(t, client.emitted[0], "emit_file")
+	assert.Contains(t, client.emitted[0], "- internal/models/user_test.go")
+	assert.NotContains(t, client.emitted[0], "- internal/service/service.go", "only files in the same directory are offered")
+
+	patch, err = coder.GenerateFile(context.Background(), tasks[1], plan, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "package models\n", extractContentFromDiff(patch.Diff))
+	assert.Len(t, client.emitted, 1, "the companion file makes no request")
+
+	// Files outside the offered companions are not kept
+	_, err = coder.GenerateFile(context.Background(), tasks[2], plan, nil)
+	require.NoError(t, err)
+	assert.Len(t, client.emitted, 2)
+	assert.Empty(t, client.prompts)
+}
+
+func TestGenerateFile_EmitMissingTarget(t *testing.T) {
+	client := &emittingLLMClient{files: [][]llm.EmittedFile{{
+		{Path: "internal/models/user_test.go", Content: "package models\n"},
+	}}}
+	coder, err := NewCoder(CoderConfig{LLMClient: client})
+	require.NoError(t, err)
+	plan := emitPlan()
+
+	_, err = coder.GenerateFile(context.Background(), plan.Phases[0].Tasks[0], plan, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "internal/models/user.go was not emitted")
+}
+
+func TestGenerateFile_EmitFallsBackToText(t *testing.T) {
+	client := &emittingLLMClient{
+		scriptedLLMClient: scriptedLLMClient{responses: []string{"```go\npackage models\n```"}},
+		err:               llm.ErrToolUseUnsupported,
+	}
+	coder, err := NewCoder(CoderConfig{LLMClient: client})
+	require.NoError(t, err)
+	plan := emitPlan()
+
+	patch, err := coder.GenerateFile(context.Background(), plan.Phases[0].Tasks[0], plan, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "package models\n", extractContentFromDiff(patch.Diff))
+	assert.Len(t, client.prompts, 1)
+}
@@ -2,14 +2,18 @@ package generate
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/logctx"
 	"github.com/dshills/gocreator/internal/models"
 	"github.com/dshills/gocreator/pkg/fsops"
 	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/dshills/gocreator/pkg/postprocess"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
@@ -18,14 +22,20 @@ import (
 type Engine interface {
 	// Generate creates a complete Go project from an FCS
 	Generate(ctx context.Context, fcs *models.FinalClarifiedSpecification, outputDir string) (*models.GenerationOutput, error)
+
+	// Resume continues an interrupted run from its checkpoint in outputDir
+	Resume(ctx context.Context, runID, outputDir string) (*models.GenerationOutput, error)
 }
 
 // engine implements the Engine interface
 type engine struct {
 	graph        *GenerationGraph
+	coder        Coder
+	tester       Tester
 	fileOps      fsops.FileOps
 	logDecisions bool
 	eventChan    chan<- models.ProgressEvent
+	provenance   models.Provenance
 }
 
 // EngineConfig contains configuration for the generation engine
@@ -36,6 +46,125 @@ type EngineConfig struct {
 	EventChan    chan<- models.ProgressEvent
 	Incremental  bool   // Enable incremental regeneration
 	OutputDir    string // Output directory (required for incremental)
+	TemplatesDir string // Templates replacing the built-in boilerplate templates (optional)
+
+	// MergeStrategy controls how hand-edited files are merged on incremental runs
+	MergeStrategy MergeStrategy
+
+	// Project overrides the module path and binary name inferred from the FCS
+	Project templates.ProjectSettings
+
+	// PlanLimits bounds generated plans; MaxReplans caps simplification requests
+	PlanLimits models.PlanLimits
+	MaxReplans int
+
+	// FileLayout sets how the planner splits source into files
+	FileLayout models.FileLayout
+
+	// ProtectedPaths are output paths people own; the planner keeps files out of them
+	ProtectedPaths models.ProtectedPaths
+
+	// Codebase is the existing module in the output directory to generate
+	// into (optional); see AnalyzeCodebase
+	Codebase *models.Codebase
+
+	// Timeouts bounds the planning and generation phases
+	Timeouts PhaseTimeouts
+
+	// ApprovePlan reviews the generation plan before any code is generated
+	// and returns the plan to generate from (optional)
+	ApprovePlan PlanApprover
+
+	// CriticClasses enables the critic review pass for matching files
+	CriticClasses []string
+	AuditLogger   fsops.Logger // Audit log for critic passes and ensemble decisions (optional)
+
+	// EnsembleClient generates a competing candidate for files matching
+	// EnsembleClasses; the primary model adjudicates between them
+	EnsembleClient  llm.Client
+	EnsembleClasses []string
+
+	// Preamble holds organization standards prepended to planner, coder and tester prompts
+	Preamble string
+
+	// PromptTemplates overrides sections of the coder prompt (optional)
+	PromptTemplates *PromptTemplates
+
+	// Style is the house style of coder and tester prompts (optional)
+	Style models.StyleGuide
+
+	// PostProcess holds the custom transforms run over generated Go files (optional)
+	PostProcess *postprocess.Pipeline
+
+	// TestParallelism bounds the packages whose tests are generated concurrently
+	TestParallelism int
+
+	// PackageDocs writes a doc.go with package documentation for each generated package
+	PackageDocs bool
+
+	// Scaffolds renders entity, repository and schema files from the data
+	// model with templates instead of requesting them
+	Scaffolds bool
+
+	// MigrationsDir is the directory the plan gets migrations of the data
+	// model in (optional); Dialect is the database their SQL is written for
+	MigrationsDir string
+	Dialect       models.SQLDialect
+
+	// RecordState persists each workflow node's state delta for `gocreator debug state`
+	RecordState bool
+
+	// Checkpoint saves the workflow state after each node for `gocreator resume`
+	Checkpoint bool
+
+	// Batch generates source files as provider batch jobs, one per dependency level
+	Batch bool
+
+	// Stream writes source file responses to disk as they arrive, resuming
+	// responses left by an interrupted run
+	Stream bool
+
+	// Speculative generates source files CodeParallelism at a time by
+	// dependency level, starting files of the next level early against the
+	// APIs of the previous output (experimental)
+	Speculative     bool
+	CodeParallelism int
+
+	// FileCostCeiling caps the projected worst-case cost (USD) of each source
+	// file; files over it are generated with DowngradeClient or as stubs
+	FileCostCeiling float64
+	DowngradeClient llm.Client
+
+	// MaxTokens is the output budget of each source file request (optional)
+	MaxTokens int
+
+	// FixKnowledge is the fix knowledge base shared by runs (optional)
+	FixKnowledge *FixKnowledge
+
+	// GeneratorVersion is the gocreator version recorded in the generation manifest
+	GeneratorVersion string
+
+	// Temperature is the sampling temperature of LLMClient, recorded with the
+	// provider and model in the run's provenance
+	Temperature float64
+
+	// PlannerClient, CoderClient and TesterClient run their phase instead of
+	// LLMClient (optional). CoderClient also writes package docs.
+	PlannerClient llm.Client
+	CoderClient   llm.Client
+	TesterClient  llm.Client
+
+	// Budget counts every LLM call of the run against its cost and token
+	// caps, stopping the run when one is crossed (optional)
+	Budget *CostGovernor
+}
+
+// phaseClient returns the client of a phase, falling back to the engine's
+func phaseClient(client, fallback llm.Client) llm.Client {
+	if client == nil {
+		return fallback
+	}
+	return client
 }
 
 // NewEngine creates a new generation engine
@@ -47,19 +176,61 @@ func NewEngine(cfg EngineConfig) (Engine, error) {
 		return nil, fmt.Errorf("file operations handler is required")
 	}
 
+	plannerClient := cfg.Budget.Wrap(phaseClient(cfg.PlannerClient, cfg.LLMClient))
+	coderClient := cfg.Budget.Wrap(phaseClient(cfg.CoderClient, cfg.LLMClient))
+	testerClient := cfg.Budget.Wrap(phaseClient(cfg.TesterClient, cfg.LLMClient))
+
 	// Create planner
 	planner, err := NewPlanner(PlannerConfig{
-		LLMClient: cfg.LLMClient,
+		LLMClient:  plannerClient,
+		Limits:     cfg.PlanLimits,
+		Layout:     cfg.FileLayout,
+		Protected:  cfg.ProtectedPaths,
+		Codebase:   cfg.Codebase,
+		MaxReplans: cfg.MaxReplans,
+		Preamble:   cfg.Preamble,
+
+		MigrationsDir: cfg.MigrationsDir,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create planner: %w", err)
 	}
 
+	// Create template generator
+	templateGen, err := templates.NewTemplateGeneratorWithOverrides(cfg.TemplatesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create template generator: %w", err)
+	}
+	var scaffolds templates.TemplateGenerator
+	if cfg.Scaffolds {
+		scaffolds = templateGen
+	}
+
 	// Create coder
 	coder, err := NewCoder(CoderConfig{
-		LLMClient:   cfg.LLMClient,
-		OutputDir:   cfg.OutputDir,
-		Incremental: cfg.Incremental,
+		LLMClient:       coderClient,
+		OutputDir:       cfg.OutputDir,
+		Incremental:     cfg.Incremental,
+		MergeStrategy:   cfg.MergeStrategy,
+		CriticClasses:   cfg.CriticClasses,
+		AuditLogger:     cfg.AuditLogger,
+		EnsembleClient:  cfg.Budget.Wrap(cfg.EnsembleClient),
+		EnsembleClasses: cfg.EnsembleClasses,
+		Preamble:        cfg.Preamble,
+		Batch:           cfg.Batch,
+		Stream:          cfg.Stream,
+		Speculative:     cfg.Speculative,
+		MaxParallel:     cfg.CodeParallelism,
+		EventChan:       cfg.EventChan,
+		FileCostCeiling: cfg.FileCostCeiling,
+		DowngradeClient: cfg.Budget.Wrap(cfg.DowngradeClient),
+		MaxTokens:       cfg.MaxTokens,
+		FixKnowledge:    cfg.FixKnowledge,
+		PromptTemplates: cfg.PromptTemplates,
+		Style:           cfg.Style,
+		PostProcess:     cfg.PostProcess,
+		Scaffolds:       scaffolds,
+		Dialect:         cfg.Dialect,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create coder: %w", err)
@@ -67,25 +238,48 @@ func NewEngine(cfg EngineConfig) (Engine, error) {
 
 	// Create tester
 	tester, err := NewTester(TesterConfig{
-		LLMClient: cfg.LLMClient,
+		LLMClient:   testerClient,
+		Preamble:    cfg.Preamble,
+		Style:       cfg.Style,
+		PostProcess: cfg.PostProcess,
+		MaxParallel: cfg.TestParallelism,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create tester: %w", err)
 	}
 
-	// Create template generator
-	templateGen, err := templates.NewTemplateGenerator()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create template generator: %w", err)
+	// Create doc writer
+	var docWriter DocWriter
+	if cfg.PackageDocs {
+		docWriter, err = NewDocWriter(DocWriterConfig{
+			LLMClient: coderClient,
+			Preamble:  cfg.Preamble,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create doc writer: %w", err)
+		}
+	}
+
+	var stateManager *IncrementalStateManager
+	if cfg.Incremental && cfg.OutputDir != "" {
+		stateManager = NewIncrementalStateManager(cfg.OutputDir)
 	}
 
 	// Create generation graph
 	graph, err := NewGenerationGraph(GenerationGraphConfig{
-		Planner:           planner,
-		Coder:             coder,
-		Tester:            tester,
-		TemplateGenerator: templateGen,
-		EventChan:         cfg.EventChan,
+		Planner:             planner,
+		Coder:               coder,
+		Tester:              tester,
+		DocWriter:           docWriter,
+		TemplateGenerator:   templateGen,
+		Project:             cfg.Project,
+		Estimate:            NewEstimateConfig(coderClient),
+		Timeouts:            cfg.Timeouts,
+		ApprovePlan:         cfg.ApprovePlan,
+		EnableCheckpointing: cfg.Checkpoint,
+		RecordState:         cfg.RecordState,
+		StateManager:        stateManager,
+		EventChan:           cfg.EventChan,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create generation graph: %w", err)
@@ -93,15 +287,40 @@ func NewEngine(cfg EngineConfig) (Engine, error) {
 
 	return &engine{
 		graph:        graph,
+		coder:        coder,
+		tester:       tester,
 		fileOps:      cfg.FileOps,
 		logDecisions: cfg.LogDecisions,
 		eventChan:    cfg.EventChan,
+		provenance: models.Provenance{
+			GeneratorVersion: cfg.GeneratorVersion,
+			Provider:         coderClient.Provider(),
+			Model:            coderClient.Model(),
+			Temperature:      cfg.Temperature,
+			PhaseModels:      phaseModels(coderClient, map[string]llm.Client{"planner": plannerClient, "tester": testerClient}),
+		},
 	}, nil
 }
 
+// phaseModels returns the provider/model of the phases whose model differs
+// from the coder's, or nil when every phase shares it
+func phaseModels(coder llm.Client, phases map[string]llm.Client) map[string]string {
+	var names map[string]string
+	for phase, client := range phases {
+		if modelName(client) == modelName(coder) {
+			continue
+		}
+		if names == nil {
+			names = make(map[string]string)
+		}
+		names[phase] = modelName(client)
+	}
+	return names
+}
+
 // Generate creates a complete Go project from an FCS
 func (e *engine) Generate(ctx context.Context, fcs *models.FinalClarifiedSpecification, outputDir string) (*models.GenerationOutput, error) {
-	log.Info().
+	logctx.Logger(ctx).Info().
 		Str("fcs_id", fcs.ID).
 		Str("output_dir", outputDir).
 		Msg("Starting autonomous code generation")
@@ -115,12 +334,16 @@ func (e *engine) Generate(ctx context.Context, fcs *models.FinalClarifiedSpecifi
 	output := &models.GenerationOutput{
 		SchemaVersion: "1.0",
 		ID:            uuid.New().String(),
+		RunID:         NewRunID(),
 		Status:        models.OutputStatusPending,
 		Metadata: models.OutputMetadata{
 			StartedAt: startTime,
 		},
 	}
 
+	// Every event of the run carries its ID
+	ctx = logctx.WithRunID(ctx, output.RunID)
+
 	// Transition to in-progress
 	if err := output.TransitionTo(models.OutputStatusInProgress); err != nil {
 		return nil, fmt.Errorf("failed to transition output status: %w", err)
@@ -132,13 +355,82 @@ func (e *engine) Generate(ctx context.Context, fcs *models.FinalClarifiedSpecifi
 			"fcs_id":     fcs.ID,
 			"output_dir": outputDir,
 			"output_id":  output.ID,
+			"run_id":     output.RunID,
 			"go_version": fcs.BuildConfig.GoVersion,
 			"packages":   len(fcs.Architecture.Packages),
 		})
 	}
 
 	// Execute the generation workflow
-	workflowOutput, err := e.graph.Execute(ctx, fcs, outputDir)
+	workflowOutput, err := e.graph.ExecuteRun(ctx, output.RunID, fcs, outputDir)
+	return e.finishRun(ctx, fcs, outputDir, output, workflowOutput, err)
+}
+
+// Resume continues an interrupted run from its checkpoint in outputDir. The
+// FCS and the work of the nodes that finished come from the checkpoint.
+func (e *engine) Resume(ctx context.Context, runID, outputDir string) (*models.GenerationOutput, error) {
+	checkpoint, err := LoadRunCheckpoint(outputDir, runID)
+	if err != nil {
+		return nil, err
+	}
+	fcs := checkpoint.State.FCS
+	if fcs == nil {
+		return nil, fmt.Errorf("checkpoint of run %s has no FCS", runID)
+	}
+	if checkpoint.ResumeNode() == "" {
+		return nil, fmt.Errorf("run %s already finished; nothing to resume", runID)
+	}
+
+	// Every event of the run carries its ID
+	ctx = logctx.WithRunID(ctx, runID)
+
+	logctx.Logger(ctx).Info().
+		Str("checkpoint_node", checkpoint.Node).
+		Time("checkpoint_saved_at", checkpoint.SavedAt).
+		Str("output_dir", outputDir).
+		Msg("Resuming code generation from checkpoint")
+
+	e.emitEvent(models.NewPhaseStartedEvent("initialization", "Resuming generation workflow"))
+
+	output := &models.GenerationOutput{
+		SchemaVersion: "1.0",
+		ID:            uuid.New().String(),
+		RunID:         runID,
+		Status:        models.OutputStatusPending,
+		Metadata: models.OutputMetadata{
+			StartedAt: time.Now(),
+		},
+	}
+
+	if err := output.TransitionTo(models.OutputStatusInProgress); err != nil {
+		return nil, fmt.Errorf("failed to transition output status: %w", err)
+	}
+
+	if e.logDecisions {
+		e.logDecision(ctx, "resuming_generation", "Resuming code generation after the last finished workflow node", map[string]interface{}{
+			"fcs_id":          fcs.ID,
+			"output_dir":      outputDir,
+			"output_id":       output.ID,
+			"run_id":          runID,
+			"checkpoint_node": checkpoint.Node,
+		})
+	}
+
+	workflowOutput, err := e.graph.ResumeRun(ctx, checkpoint, outputDir)
+	return e.finishRun(ctx, fcs, outputDir, output, workflowOutput, err)
+}
+
+// finishRun writes the patches of a finished workflow to outputDir and
+// completes output. err is the error the workflow returned.
+func (e *engine) finishRun(
+	ctx context.Context,
+	fcs *models.FinalClarifiedSpecification,
+	outputDir string,
+	output *models.GenerationOutput,
+	workflowOutput *models.GenerationOutput,
+	err error,
+) (*models.GenerationOutput, error) {
+	startTime := output.Metadata.StartedAt
 	if err != nil {
 		output.Status = models.OutputStatusFailed
 		e.logDecision(ctx, "generation_failed", "Code generation workflow failed", map[string]interface{}{
@@ -153,6 +445,11 @@ func (e *engine) Generate(ctx context.Context, fcs *models.FinalClarifiedSpecifi
 		return output, fmt.Errorf("workflow returned nil output")
 	}
 
+	// Report the files the cost ceiling kept from the primary model
+	if reporter, ok := e.coder.(DowngradeReporter); ok {
+		output.Metadata.Downgrades = reporter.Downgrades()
+	}
+
 	// Get patches from workflow output
 	// In a real implementation, the graph would pass patches through state
 	// For now, we'll extract them from the workflow output
@@ -167,6 +464,17 @@ func (e *engine) Generate(ctx context.Context, fcs *models.FinalClarifiedSpecifi
 		return nil, fmt.Errorf("failed to apply patches: %w", err)
 	}
 
+	// Point internal imports at the module path of the go.mod that owns them
+	e.enforceModuleImports(ctx, outputDir, output)
+
+	// Join an enclosing go.work so sibling modules resolve for the new module
+	e.joinWorkspace(ctx, outputDir)
+
+	// Record what the files were generated with, and which files were
+	// generated so upgrades leave other code alone
+	output.Metadata.Provenance = runProvenance(e.provenance, output.Files, e.coder, e.tester)
+	e.recordManifest(fcs, outputDir, output)
+
 	// Calculate metadata
 	output.Metadata.FilesCount = len(output.Files)
 	output.Metadata.LinesCount = e.countTotalLines(output.Files)
@@ -198,7 +506,7 @@ func (e *engine) Generate(ctx context.Context, fcs *models.FinalClarifiedSpecifi
 		})
 	}
 
-	log.Info().
+	logctx.Logger(ctx).Info().
 		Str("output_id", output.ID).
 		Int("files", len(output.Files)).
 		Int("lines", output.Metadata.LinesCount).
@@ -208,9 +516,59 @@ func (e *engine) Generate(ctx context.Context, fcs *models.FinalClarifiedSpecifi
 	return output, nil
 }
 
+// rebasePatch rewrites a generated patch, which always describes a complete new
+// file, as a diff from the target's current content. Files that already match
+// the generated content yield an empty diff. A patch that modifies a file that
+// has changed since it was generated is merged three ways with the file on
+// disk; the hunks both changed differently keep what is on disk and are
+// returned as conflicts.
+func (e *engine) rebasePatch(ctx context.Context, patch models.Patch) (models.Patch, []models.PatchConflict, error) {
+	content := extractContentFromDiff(patch.Diff)
+
+	exists, err := e.fileOps.Exists(ctx, patch.TargetFile)
+	if err != nil {
+		return models.Patch{}, nil, fmt.Errorf("failed to check if %s exists: %w", patch.TargetFile, err)
+	}
+
+	current := ""
+	if exists {
+		current, err = e.fileOps.ReadFile(ctx, patch.TargetFile)
+		if err != nil {
+			return models.Patch{}, nil, fmt.Errorf("failed to read %s: %w", patch.TargetFile, err)
+		}
+	}
+
+	var conflicts []models.PatchConflict
+	if exists && patch.BaseChecksum != "" && e.fileOps.GenerateChecksum(current) != patch.BaseChecksum {
+		var rejected []RejectedHunk
+		content, rejected = RejectingMerge(extractBaseFromDiff(patch.Diff), current, content)
+		for _, hunk := range rejected {
+			conflicts = append(conflicts, models.PatchConflict{
+				Path:     patch.TargetFile,
+				Line:     hunk.Line,
+				Kept:     hunk.Ours,
+				Rejected: hunk.Theirs,
+			})
+		}
+	}
+
+	rebased, err := e.fileOps.GeneratePatch(ctx, patch.TargetFile, current, content)
+	if err != nil {
+		return models.Patch{}, nil, fmt.Errorf("failed to diff %s: %w", patch.TargetFile, err)
+	}
+	rebased.AppliedAt = patch.AppliedAt
+	rebased.Reversible = patch.Reversible
+	rebased.Template = patch.Template
+	if exists {
+		rebased.BaseChecksum = e.fileOps.GenerateChecksum(current)
+	}
+
+	return rebased, conflicts, nil
+}
+
 // applyPatches applies all patches to the file system and populates the output
 func (e *engine) applyPatches(ctx context.Context, patches []models.Patch, output *models.GenerationOutput) error {
-	log.Debug().
+	logctx.Logger(ctx).Debug().
 		Int("patches", len(patches)).
 		Msg("Applying patches to file system")
 
@@ -221,7 +579,7 @@ func (e *engine) applyPatches(ctx context.Context, patches []models.Patch, outpu
 	generatedFiles := make([]models.GeneratedFile, 0, len(patches))
 
 	for i, patch := range patches {
-		log.Debug().
+		logctx.Logger(ctx).Debug().
 			Int("patch", i+1).
 			Int("total", len(patches)).
 			Str("target", patch.TargetFile).
@@ -231,17 +589,56 @@ func (e *engine) applyPatches(ctx context.Context, patches []models.Patch, outpu
 		e.emitEvent(models.NewFileGeneratingEvent(patch.TargetFile, "file_writing"))
 		fileStart := time.Now()
 
-		// Validate patch before applying
-		if err := e.fileOps.ValidatePatch(ctx, patch); err != nil {
-			log.Warn().
-				Err(err).
+		// Diff against the file on disk so existing files get genuine modifications
+		rebased, conflicts, err := e.rebasePatch(ctx, patch)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			output.Metadata.Conflicts = append(output.Metadata.Conflicts, conflicts...)
+			logctx.Logger(ctx).Warn().
 				Str("target", patch.TargetFile).
-				Msg("Patch validation failed, attempting to apply anyway")
+				Int("hunks", len(conflicts)).
+				Msg("File changed on disk since it was generated, rejected conflicting hunks")
+			if e.logDecisions {
+				lines := make([]int, len(conflicts))
+				for idx, conflict := range conflicts {
+					lines[idx] = conflict.Line
+				}
+				e.logDecision(ctx, "patch_hunks_rejected", "Kept the lines on disk where a generated change conflicts with edits made since", map[string]interface{}{
+					"file":  patch.TargetFile,
+					"lines": lines,
+				})
+			}
 		}
-
-		// Apply patch with backup
-		if err := e.fileOps.ApplyPatchWithBackup(ctx, patch); err != nil {
-			return fmt.Errorf("failed to apply patch to %s: %w", patch.TargetFile, err)
+		patch = rebased
+		patches[i] = rebased
+
+		if patch.Diff != "" {
+			// Validate patch before applying; protected files are left untouched
+			err := e.fileOps.ValidatePatch(ctx, patch)
+			if errors.Is(err, fsops.ErrProtectedPath) {
+				logctx.Logger(ctx).Warn().
+					Str("target", patch.TargetFile).
+					Msg("Skipping patch for protected path")
+				if e.logDecisions {
+					e.logDecision(ctx, "protected_path_skipped", "Left a protected file untouched", map[string]interface{}{
+						"file": patch.TargetFile,
+					})
+				}
+				continue
+			}
+			if err != nil {
+				logctx.Logger(ctx).Warn().
+					Err(err).
+					Str("target", patch.TargetFile).
+					Msg("Patch validation failed, attempting to apply anyway")
+			}
+
+			// Apply patch with backup
+			if err := e.fileOps.ApplyPatchWithBackup(ctx, patch); err != nil {
+				return fmt.Errorf("failed to apply patch to %s: %w", patch.TargetFile, err)
+			}
 		}
 
 		// Read the file content after applying patch
@@ -261,6 +658,9 @@ func (e *engine) applyPatches(ctx context.Context, patches []models.Patch, outpu
 			GeneratedAt: patch.AppliedAt,
 			Generator:   "langgraph-generation-workflow",
 		}
+		if patch.Template {
+			generatedFile.Generator = generatorTemplate
+		}
 
 		// Verify checksum
 		if !generatedFile.VerifyChecksum() {
@@ -294,7 +694,7 @@ func (e *engine) applyPatches(ctx context.Context, patches []models.Patch, outpu
 	phaseDuration := time.Since(phaseStart)
 	e.emitEvent(models.NewPhaseCompletedEvent("file_writing", phaseDuration, len(generatedFiles)))
 
-	log.Debug().
+	logctx.Logger(ctx).Debug().
 		Int("files", len(generatedFiles)).
 		Msg("All patches applied successfully")
 
@@ -310,9 +710,113 @@ func (e *engine) countTotalLines(files []models.GeneratedFile) int {
 	return total
 }
 
+// enforceModuleImports rewrites internal imports of the generated Go files
+// that do not use their module's path, as when the LLM imports the template
+// placeholder module or the bare project name. Multi-module layouts resolve
+// each file against its nearest go.mod. The number of rewritten imports is
+// recorded in the output metadata; a file that cannot be rewritten is logged
+// and keeps its imports for build validation to report.
+func (e *engine) enforceModuleImports(ctx context.Context, outputDir string, output *models.GenerationOutput) {
+	layout, err := DetectModuleLayout(outputDir)
+	if err != nil {
+		logctx.Logger(ctx).Warn().
+			Err(err).
+			Str("output_dir", outputDir).
+			Msg("Failed to detect modules for import checking")
+		return
+	}
+	if len(layout.Modules) == 0 {
+		return
+	}
+
+	for i, file := range output.Files {
+		if !strings.HasSuffix(file.Path, ".go") {
+			continue
+		}
+		fixed, fixes := layout.FixImports(filepath.ToSlash(file.Path), file.Content)
+		if len(fixes) == 0 {
+			continue
+		}
+		if err := e.fileOps.WriteFile(ctx, file.Path, fixed); err != nil {
+			logctx.Logger(ctx).Warn().
+				Err(err).
+				Str("file", file.Path).
+				Msg("Failed to rewrite imports")
+			continue
+		}
+		output.Files[i].Content = fixed
+		output.Files[i].Checksum = e.fileOps.GenerateChecksum(fixed)
+		output.Metadata.ImportsFixed += len(fixes)
+
+		for _, fix := range fixes {
+			logctx.Logger(ctx).Debug().
+				Str("file", fix.File).
+				Str("from", fix.From).
+				Str("to", fix.To).
+				Msg("Rewrote import to match module path")
+		}
+	}
+
+	if output.Metadata.ImportsFixed > 0 {
+		logctx.Logger(ctx).Info().
+			Int("imports", output.Metadata.ImportsFixed).
+			Msg("Fixed imports that did not match the go.mod module path")
+		if e.logDecisions {
+			e.logDecision(ctx, "imports_fixed", "Rewrote internal imports to the go.mod module path", map[string]interface{}{
+				"imports": output.Metadata.ImportsFixed,
+			})
+		}
+	}
+}
+
+// joinWorkspace adds the generated module to the go.work file above it. A
+// workspace that cannot be updated is logged; the generated files stand.
+func (e *engine) joinWorkspace(ctx context.Context, outputDir string) {
+	if hasGoMod, err := e.fileOps.Exists(ctx, "go.mod"); err != nil || !hasGoMod {
+		return
+	}
+
+	workspace, err := DetectWorkspace(outputDir)
+	if err == nil {
+		var added bool
+		added, err = workspace.UseOutputDir()
+		if added && e.logDecisions {
+			e.logDecision(ctx, "workspace_joined", "Added the generated module to go.work", map[string]interface{}{
+				"go_work": workspace.GoWork,
+			})
+		}
+	}
+	if err != nil {
+		logctx.Logger(ctx).Warn().
+			Err(err).
+			Str("output_dir", outputDir).
+			Msg("Failed to add generated module to go.work")
+	}
+}
+
+// recordManifest adds the written files to the output directory's generation
+// manifest. A manifest that cannot be saved is logged; the generated files stand.
+func (e *engine) recordManifest(fcs *models.FinalClarifiedSpecification, outputDir string, output *models.GenerationOutput) {
+	manifest, err := LoadManifest(outputDir)
+	if err == nil {
+		if manifest == nil {
+			manifest = NewManifest()
+		}
+		manifest.Record(fcs, output.Files, output.Metadata.Provenance)
+		manifest.RecordDowngrades(output.Metadata.Downgrades)
+		err = manifest.Save(outputDir)
+	}
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Str("output_dir", outputDir).
+			Msg("Failed to record generation manifest")
+	}
+}
+
 // logDecision logs a generation decision for audit and replay
-func (e *engine) logDecision(_ context.Context, decision, rationale string, context map[string]interface{}) {
-	log.Info().
+func (e *engine) logDecision(ctx context.Context, decision, rationale string, context map[string]interface{}) {
+	logctx.Logger(ctx).Info().
 		Str("decision", decision).
 		Str("rationale", rationale).
 		Interface("context", context).
@@ -322,23 +826,6 @@ func (e *engine) logDecision(_ context.Context, decision, rationale string, cont
 	// using the models.DecisionLog structure
 }
 
-// Resume resumes generation from a checkpoint
-func (e *engine) Resume(_ context.Context, checkpointID string) (*models.GenerationOutput, error) {
-	log.Info().
-		Str("checkpoint_id", checkpointID).
-		Msg("Resuming generation from checkpoint")
-
-	// Resume functionality is implemented via incremental generation
-	// Use the --resume flag with the generate command, which will:
-	// 1. Check for existing state in .gocreator/state.json
-	// 2. Automatically enable incremental mode if state exists
-	// 3. Regenerate only changed files based on FCS diff
-	//
-	// This provides similar functionality to checkpoint resume without
-	// requiring complex mid-execution state persistence.
-	return nil, fmt.Errorf("direct Resume() call not supported - use --resume flag with generate command instead")
-}
-
 // emitEvent sends a progress event to the event channel if configured
 func (e *engine) emitEvent(event models.ProgressEvent) {
 	if e.eventChan != nil {
@@ -0,0 +1,258 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"go/format"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// Ensemble candidate labels used in the adjudication prompt
+const (
+	ensembleCandidateA = "A" // Primary model
+	ensembleCandidateB = "B" // Ensemble model
+)
+
+// EnsembleCandidate is one model's version of a critical file
+type EnsembleCandidate struct {
+	Label    string   // A (primary model) or B (ensemble model)
+	Provider string   // Provider that generated the candidate
+	Model    string   // Model that generated the candidate
+	Content  string   // Generated file content
+	Issues   []string // Parse and format problems found by the checks
+	Err      error    // Generation failure, if any
+}
+
+// passed reports whether the candidate was generated and passed its checks
+func (c EnsembleCandidate) passed() bool {
+	return c.Err == nil && len(c.Issues) == 0
+}
+
+// EnsembleResult is the outcome of generating a file with two models
+type EnsembleResult struct {
+	Winner     string // Label of the kept candidate
+	Reason     string // Why it was kept
+	Content    string // Content of the kept candidate
+	Candidates []EnsembleCandidate
+}
+
+// ensemble generates critical files with a second model and keeps the better
+// of the two candidates
+type ensemble struct {
+	client   llm.Client // Second model generating the competing candidate
+	judge    llm.Client // Primary model adjudicating between candidates
+	classes  []string
+	audit    fsops.Logger
+	preamble string
+}
+
+// newEnsemble creates an ensemble for the given classes, or nil if there is no
+// second model or no class is selected
+func newEnsemble(client, judge llm.Client, classes []string, audit fsops.Logger, preamble string) *ensemble {
+	if client == nil || len(classes) == 0 {
+		return nil
+	}
+	return &ensemble{client: client, judge: judge, classes: classes, audit: audit, preamble: preamble}
+}
+
+// matchClasses returns the selected classes that targetPath or content belongs to
+func (en *ensemble) matchClasses(targetPath, content string) []string {
+	return matchFileClasses(en.classes, targetPath, content)
+}
+
+// Choose generates a second candidate for a file with generate, checks both
+// and keeps the better one. A candidate that fails its checks loses to one
+// that passes; when both pass or both fail the primary model adjudicates.
+// Any failure along the way keeps the primary candidate.
+func (en *ensemble) Choose(ctx context.Context, targetPath, primary string, classes []string, generate func(ctx context.Context, client llm.Client) (string, error)) EnsembleResult {
+	a := en.candidate(ensembleCandidateA, en.judge, targetPath, primary, nil)
+	secondary, err := generate(ctx, en.client)
+	b := en.candidate(ensembleCandidateB, en.client, targetPath, secondary, err)
+	if err != nil {
+		logctx.Logger(ctx).Warn().
+			Err(err).
+			Str("file", targetPath).
+			Msg("Ensemble generation failed, keeping primary candidate")
+	}
+
+	result := EnsembleResult{Candidates: []EnsembleCandidate{a, b}}
+	switch {
+	case b.Err != nil:
+		result.Winner, result.Reason = ensembleCandidateA, "ensemble model failed to generate a candidate"
+	case a.passed() && !b.passed():
+		result.Winner, result.Reason = ensembleCandidateA, "ensemble candidate failed checks: "+strings.Join(b.Issues, "; ")
+	case b.passed() && !a.passed():
+		result.Winner, result.Reason = ensembleCandidateB, "primary candidate failed checks: "+strings.Join(a.Issues, "; ")
+	default:
+		result.Winner, result.Reason = en.adjudicate(ctx, targetPath, classes, a, b)
+	}
+
+	result.Content = a.Content
+	if result.Winner == ensembleCandidateB {
+		result.Content = b.Content
+	}
+
+	en.record(ctx, targetPath, classes, result)
+
+	logctx.Logger(ctx).Info().
+		Str("file", targetPath).
+		Str("winner", result.Winner).
+		Str("reason", result.Reason).
+		Msg("Ensemble candidate selected")
+
+	return result
+}
+
+// candidate builds a candidate and runs its checks
+func (en *ensemble) candidate(label string, client llm.Client, targetPath, content string, err error) EnsembleCandidate {
+	c := EnsembleCandidate{
+		Label:    label,
+		Provider: client.Provider(),
+		Model:    client.Model(),
+		Content:  content,
+		Err:      err,
+	}
+	if err == nil {
+		c.Issues = checkCandidate(targetPath, content)
+	}
+	return c
+}
+
+// checkCandidate reports parse and gofmt problems in a generated Go file
+func checkCandidate(targetPath, content string) []string {
+	if strings.TrimSpace(content) == "" {
+		return []string{"empty file"}
+	}
+	if !strings.HasSuffix(targetPath, ".go") {
+		return nil
+	}
+	if !parsesAsGo(content) {
+		return []string{"does not parse as Go"}
+	}
+	formatted, err := format.Source([]byte(content))
+	if err != nil {
+		return []string{fmt.Sprintf("gofmt failed: %v", err)}
+	}
+	if string(formatted) != ensureTrailingNewline(content) {
+		return []string{"not gofmt formatted"}
+	}
+	return nil
+}
+
+// adjudicate asks the primary model which candidate is better. Failed calls and
+// unrecognized answers keep candidate A.
+func (en *ensemble) adjudicate(ctx context.Context, targetPath string, classes []string, a, b EnsembleCandidate) (string, string) {
+	response, err := en.judge.Generate(ctx, en.buildPrompt(targetPath, classes, a, b))
+	if err != nil {
+		logctx.Logger(ctx).Warn().
+			Err(err).
+			Str("file", targetPath).
+			Msg("Ensemble adjudication failed, keeping primary candidate")
+		return ensembleCandidateA, "adjudication failed: " + err.Error()
+	}
+
+	winner, reason := parseAdjudication(response)
+	if winner == "" {
+		return ensembleCandidateA, "adjudication gave no winner, primary candidate kept"
+	}
+	return winner, reason
+}
+
+// buildPrompt constructs the adjudication prompt
+func (en *ensemble) buildPrompt(targetPath string, classes []string, a, b EnsembleCandidate) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are a senior Go reviewer choosing between two implementations of the same generated file.\n\n")
+	sb.WriteString(fmt.Sprintf("# File\n%s (%s)\n\n", targetPath, strings.Join(classes, ", ")))
+	sb.WriteString("# Criteria\n")
+	sb.WriteString("- Correctness: logic bugs, unhandled errors and nil dereferences\n")
+	sb.WriteString("- Security: injection, missing input validation, unsafe handling of credentials\n")
+	sb.WriteString("- Concurrency: data races, leaked goroutines, missing context cancellation\n")
+	sb.WriteString("- Completeness and idiomatic Go\n\n")
+	for _, c := range []EnsembleCandidate{a, b} {
+		sb.WriteString(fmt.Sprintf("# Candidate %s\n", c.Label))
+		if len(c.Issues) > 0 {
+			sb.WriteString(fmt.Sprintf("Check failures: %s\n", strings.Join(c.Issues, "; ")))
+		}
+		sb.WriteString(c.Content)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("# Output Format\n\n")
+	sb.WriteString("Respond in exactly this format:\n")
+	sb.WriteString("WINNER: A or B\n")
+	sb.WriteString("REASON: one sentence explaining the choice\n")
+
+	return withPreamble(en.preamble, sb.String())
+}
+
+// parseAdjudication extracts the winning label and reason.
+// The winner is empty when the response names neither candidate.
+func parseAdjudication(response string) (string, string) {
+	var winner, reason string
+	for _, line := range strings.Split(strings.TrimSpace(response), "\n") {
+		trimmed := strings.TrimLeft(strings.TrimSpace(line), "*# ")
+		upper := strings.ToUpper(trimmed)
+
+		switch {
+		case strings.HasPrefix(upper, "WINNER:"):
+			switch strings.Trim(strings.TrimSpace(upper[len("WINNER:"):]), "*.") {
+			case ensembleCandidateA:
+				winner = ensembleCandidateA
+			case ensembleCandidateB:
+				winner = ensembleCandidateB
+			}
+		case strings.HasPrefix(upper, "REASON:"):
+			reason = strings.TrimSpace(trimmed[len("REASON:"):])
+		}
+	}
+	return winner, reason
+}
+
+// record writes both candidates and the decision to the audit log when one is configured
+func (en *ensemble) record(ctx context.Context, targetPath string, classes []string, result EnsembleResult) {
+	if en.audit == nil {
+		return
+	}
+
+	candidates := make([]map[string]interface{}, 0, len(result.Candidates))
+	for _, c := range result.Candidates {
+		entry := map[string]interface{}{
+			"label":    c.Label,
+			"provider": c.Provider,
+			"model":    c.Model,
+			"issues":   c.Issues,
+		}
+		if c.Err != nil {
+			entry["error"] = c.Err.Error()
+		} else {
+			entry["checksum"] = ComputeFileChecksum(c.Content)
+			entry["content"] = c.Content
+		}
+		candidates = append(candidates, entry)
+	}
+
+	rationale := fmt.Sprintf("Kept candidate %s: %s", result.Winner, result.Reason)
+	if err := en.audit.LogDecision(ctx, models.DecisionLog{
+		LogEntry: models.LogEntry{
+			Level:     "info",
+			Component: "generate",
+			Operation: "ensemble",
+			Message:   rationale,
+			Context: map[string]interface{}{
+				"path":       targetPath,
+				"classes":    classes,
+				"winner":     result.Winner,
+				"candidates": candidates,
+			},
+		},
+		Decision:  "ensemble_selection",
+		Rationale: rationale,
+	}); err != nil {
+		logctx.Logger(ctx).Warn().Err(err).Str("file", targetPath).Msg("Failed to record ensemble decision")
+	}
+}
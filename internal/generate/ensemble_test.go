@@ -0,0 +1,110 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEnsemble_Disabled(t *testing.T) {
+	assert.Nil(t, newEnsemble(nil, &mockMergeLLMClient{}, []string{CriticClassAuth}, nil, ""))
+	assert.Nil(t, newEnsemble(&mockMergeLLMClient{}, &mockMergeLLMClient{}, nil, nil, ""))
+}
+
+func TestCheckCandidate(t *testing.T) {
+	assert.Empty(t, checkCandidate("auth.go", "package auth\n\nfunc Login() {}\n"))
+	assert.Empty(t, checkCandidate("auth.go", "package auth\n\nfunc Login() {}"), "missing trailing newline is fine")
+	assert.Equal(t, []string{"does not parse as Go"}, checkCandidate("auth.go", "package auth\n\nfunc Login( {"))
+	assert.Equal(t, []string{"not gofmt formatted"}, checkCandidate("auth.go", "package auth\nfunc Login(){}\n"))
+	assert.Equal(t, []string{"empty file"}, checkCandidate("auth.go", "  \n"))
+	assert.Empty(t, checkCandidate("config.yaml", "key: [value"), "only Go files are parsed")
+}
+
+func TestParseAdjudication(t *testing.T) {
+	winner, reason := parseAdjudication("WINNER: B\nREASON: checks the token expiry")
+	assert.Equal(t, ensembleCandidateB, winner)
+	assert.Equal(t, "checks the token expiry", reason)
+
+	winner, _ = parseAdjudication("**WINNER: A.**")
+	assert.Equal(t, ensembleCandidateA, winner)
+
+	winner, _ = parseAdjudication("Both look fine.")
+	assert.Empty(t, winner)
+}
+
+func TestEnsemble_Choose(t *testing.T) {
+	valid := "package auth\n\nfunc Login() error { return nil }\n"
+	broken := "package auth\n\nfunc Login( {"
+
+	tests := []struct {
+		name        string
+		judge       string
+		primary     string
+		secondary   string
+		genErr      error
+		wantWinner  string
+		wantContent string
+		wantJudged  bool
+	}{
+		{"judge picks ensemble", "WINNER: B\nREASON: returns errors", "package auth\n\nfunc Login() {}\n", valid, nil, ensembleCandidateB, valid, true},
+		{"judge picks primary", "WINNER: A\nREASON: simpler", valid, "package auth\n\nfunc Login() {}\n", nil, ensembleCandidateA, valid, true},
+		{"unrecognized verdict keeps primary", "no idea", valid, "package auth\n\nfunc Login() {}\n", nil, ensembleCandidateA, valid, true},
+		{"broken ensemble candidate loses", "WINNER: B", valid, broken, nil, ensembleCandidateA, valid, false},
+		{"broken primary candidate loses", "WINNER: A", broken, valid, nil, ensembleCandidateB, valid, false},
+		{"ensemble failure keeps primary", "WINNER: B", valid, "", errors.New("rate limited"), ensembleCandidateA, valid, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			judge := &mockMergeLLMClient{response: tt.judge}
+			audit := fsops.NewMemoryLogger()
+			en := newEnsemble(&mockMergeLLMClient{}, judge, []string{CriticClassAuth}, audit, "")
+
+			result := en.Choose(context.Background(), "internal/auth/login.go", tt.primary, []string{CriticClassAuth}, func(_ context.Context, _ llm.Client) (string, error) {
+				return tt.secondary, tt.genErr
+			})
+
+			assert.Equal(t, tt.wantWinner, result.Winner)
+			assert.Equal(t, tt.wantContent, result.Content)
+			assert.NotEmpty(t, result.Reason)
+			assert.Equal(t, tt.wantJudged, len(judge.prompts) == 1)
+
+			decisions := criticDecisions(audit)
+			require.Len(t, decisions, 1)
+			assert.Equal(t, "ensemble_selection", decisions[0].Decision)
+			assert.Equal(t, "ensemble", decisions[0].LogEntry.Operation)
+			assert.Equal(t, tt.wantWinner, decisions[0].LogEntry.Context["winner"])
+			assert.Len(t, decisions[0].LogEntry.Context["candidates"], 2, "both candidates are recorded")
+		})
+	}
+}
+
+func TestEnsemble_AdjudicationPrompt(t *testing.T) {
+	judge := &mockMergeLLMClient{response: "WINNER: A"}
+	en := newEnsemble(&mockMergeLLMClient{}, judge, []string{CriticClassAuth}, nil, "")
+
+	en.Choose(context.Background(), "auth.go", "package auth\nfunc A(){}\n", []string{CriticClassAuth}, func(_ context.Context, _ llm.Client) (string, error) {
+		return "package auth\nfunc B(){}\n", nil
+	})
+
+	require.Len(t, judge.prompts, 1)
+	prompt := judge.prompts[0]
+	assert.Contains(t, prompt, "auth.go (auth)")
+	assert.Contains(t, prompt, "# Candidate A\nCheck failures: not gofmt formatted\npackage auth\nfunc A(){}")
+	assert.Contains(t, prompt, "# Candidate B\nCheck failures: not gofmt formatted\npackage auth\nfunc B(){}")
+}
+
+func TestNewCoder_InvalidEnsembleClass(t *testing.T) {
+	_, err := NewCoder(CoderConfig{
+		LLMClient:       &mockMergeLLMClient{},
+		EnsembleClient:  &mockMergeLLMClient{},
+		EnsembleClasses: []string{"internal/[auth"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid ensemble class pattern")
+}
@@ -0,0 +1,485 @@
+package generate
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// typeRegistry holds the Go declaration of every entity in the data model,
+// rendered from its attributes without the LLM. Files are generated
+// independently, so the file declaring an entity and every file using it are
+// shown the same declaration instead of each guessing at its fields.
+type typeRegistry struct {
+	types         []entityType // In data model order
+	enums         []models.Enum
+	relationships []models.Relationship
+	persistence   string // Persistence strategy, which GORM adds column tags for
+}
+
+// entityType is the canonical declaration of one entity
+type entityType struct {
+	entity   string // Entity name in the data model
+	typeName string
+	pkg      string // Package as given in the data model
+	pkgName  string // Go package name, "" when pkg does not give one
+	fields   []entityField
+	decl     string   // gofmt'ed type declaration
+	imports  []string // Standard library imports of the field types
+	refs     []string // Data model packages of the entities and enums the fields qualify
+}
+
+// entityField is one field of an entity's declaration
+type entityField struct {
+	name  string
+	typ   string // Go type within the entity's package
+	tag   string // JSON name, also the column name of scaffolded storage
+	spec  string // Attribute type as the data model gives it
+	known bool   // typ was derived from spec rather than defaulted
+}
+
+// specTypes maps the type names specifications use for attributes to Go types
+var specTypes = map[string]string{
+	"text": "string", "email": "string", "url": "string", "uri": "string", "uuid": "string",
+	"guid": "string", "id": "string", "phone": "string", "password": "string", "hash": "string",
+	"str": "string", "varchar": "string", "char": "string", "enum": "string",
+	"integer": "int", "number": "float64", "float": "float64", "double": "float64",
+	"decimal": "float64", "money": "float64", "currency": "float64", "real": "float64",
+	"long": "int64", "bigint": "int64", "serial": "int64",
+	"boolean": "bool", "flag": "bool",
+	"timestamp": "time.Time", "datetime": "time.Time", "date": "time.Time", "time": "time.Time", "duration": "time.Duration",
+	"binary": "[]byte", "blob": "[]byte", "bytes": "[]byte",
+	"json": "map[string]any", "object": "map[string]any",
+}
+
+// goBuiltinTypes are the predeclared Go types attributes may name directly
+var goBuiltinTypes = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true, "any": true, "error": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// qualifierImports maps the package qualifiers of standard library types
+// attributes may name to their import paths
+var qualifierImports = map[string]string{
+	"time": "time",
+	"json": "encoding/json",
+	"big":  "math/big",
+	"url":  "net/url",
+	"net":  "net",
+}
+
+// typeQualifier matches the package qualifiers within a Go type
+var typeQualifier = regexp.MustCompile(`(?:^|[^\w.])(\w+)\.`)
+
+// fieldInitialisms are the words field names spell in capitals
+var fieldInitialisms = map[string]bool{
+	"id": true, "url": true, "uri": true, "api": true, "http": true, "https": true, "json": true,
+	"xml": true, "html": true, "uuid": true, "ip": true, "sql": true, "sku": true, "tls": true,
+	"ttl": true, "ui": true, "uid": true, "cpu": true, "dns": true, "tcp": true, "udp": true,
+}
+
+// newTypeRegistry renders the declarations of the data model's entities for
+// a persistence strategy. It returns nil when there are none.
+func newTypeRegistry(dm models.DataModel, persistence string) *typeRegistry {
+	if len(dm.Entities) == 0 {
+		return nil
+	}
+
+	r := &typeRegistry{enums: dm.Enums, relationships: dm.Relationships, persistence: persistence}
+	var entities []models.Entity
+	for _, entity := range dm.Entities {
+		typeName := entityTypeName(entity.Name)
+		if typeName == "" {
+			continue
+		}
+		r.types = append(r.types, entityType{
+			entity:   entity.Name,
+			typeName: typeName,
+			pkg:      strings.Trim(path.Clean(strings.ReplaceAll(entity.Package, "\\", "/")), "/"),
+			pkgName:  goPackageName(entity.Package),
+		})
+		entities = append(entities, entity)
+	}
+
+	// Every type is known before any is rendered, as fields refer to others
+	for i := range r.types {
+		r.render(&r.types[i], entities[i], dm)
+	}
+	return r
+}
+
+// goPackageName returns the Go package name of a data model package, given
+// as a name, a directory or an import path; "" when it does not end in one
+func goPackageName(pkg string) string {
+	name := path.Base(strings.Trim(strings.ReplaceAll(pkg, "\\", "/"), "/"))
+	if !token.IsIdentifier(name) || versionDir.MatchString(name) {
+		return ""
+	}
+	return name
+}
+
+// render sets the fields of t from entity's attributes, sorted by name, and
+// renders its declaration with the imports and packages the field types need
+func (r *typeRegistry) render(t *entityType, entity models.Entity, dm models.DataModel) {
+	// Attributes giving the same field name keep the first in sorted order
+	seen := make(map[string]bool, len(entity.Attributes))
+	for _, attr := range slices.Sorted(maps.Keys(entity.Attributes)) {
+		name := goFieldName(attr)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		spec := entity.Attributes[attr]
+		typ, known := r.goType(spec, t.pkgName, dm)
+		t.fields = append(t.fields, entityField{name: name, typ: typ, tag: models.SnakeCase(name), spec: spec, known: known})
+	}
+	sort.Slice(t.fields, func(i, j int) bool { return t.fields[i].name < t.fields[j].name })
+
+	var sb strings.Builder
+	sb.WriteString("package p\n\n")
+	sb.WriteString(fmt.Sprintf("type %s struct {\n", t.typeName))
+	imports := make(map[string]bool)
+	refs := make(map[string]bool)
+	for _, f := range t.fields {
+		sb.WriteString(fmt.Sprintf("\t%s %s `%s`", f.name, f.typ, r.fieldTag(f)))
+		if !f.known {
+			sb.WriteString(" // " + strings.Join(strings.Fields(f.spec), " "))
+		}
+		sb.WriteString("\n")
+
+		for _, m := range typeQualifier.FindAllStringSubmatch(f.typ, -1) {
+			if imp, ok := qualifierImports[m[1]]; ok {
+				imports[imp] = true
+			} else if pkg, ok := r.packageNamed(m[1], dm); ok {
+				refs[pkg] = true
+			}
+		}
+	}
+	sb.WriteString("}\n")
+
+	decl := sb.String()
+	if formatted, err := format.Source([]byte(decl)); err == nil {
+		decl = string(formatted)
+	}
+	t.decl = strings.TrimPrefix(decl, "package p\n\n")
+	t.imports = slices.Sorted(maps.Keys(imports))
+	t.refs = slices.Sorted(maps.Keys(refs))
+}
+
+// fieldTag returns the struct tag of a field: its JSON name, and under GORM
+// the column of a field with a column type, the ID being the primary key
+func (r *typeRegistry) fieldTag(f entityField) string {
+	tag := fmt.Sprintf("json:%q", f.tag)
+	if r.persistence != models.PersistenceGORM || r.sqlType(f.typ, "") == "" {
+		return tag
+	}
+	if f.name == "ID" {
+		return tag + fmt.Sprintf(" gorm:\"column:%s;primaryKey\"", f.tag)
+	}
+	return tag + fmt.Sprintf(" gorm:\"column:%s\"", f.tag)
+}
+
+// packageNamed returns the data model package of the entities or enums whose
+// Go package name is name
+func (r *typeRegistry) packageNamed(name string, dm models.DataModel) (string, bool) {
+	for _, t := range r.types {
+		if t.pkgName == name {
+			return t.pkg, true
+		}
+	}
+	for _, enum := range dm.Enums {
+		if goPackageName(enum.Package) == name {
+			return enum.Package, true
+		}
+	}
+	return "", false
+}
+
+// goType returns the Go type of an attribute typed spec in package pkgName,
+// reporting false when spec names no type it knows, which then gives string.
+// Entities and enums of other packages are qualified by their package name.
+func (r *typeRegistry) goType(spec, pkgName string, dm models.DataModel) (string, bool) {
+	spec = strings.TrimSpace(spec)
+	lower := strings.ToLower(spec)
+
+	switch {
+	case strings.HasPrefix(spec, "*"):
+		elem, known := r.goType(spec[1:], pkgName, dm)
+		return "*" + elem, known
+	case strings.HasPrefix(spec, "[]"):
+		elem, known := r.goType(spec[2:], pkgName, dm)
+		return "[]" + elem, known
+	case strings.HasPrefix(spec, "map[") && strings.Contains(spec, "]"):
+		end := strings.Index(spec, "]")
+		key, keyKnown := r.goType(spec[4:end], pkgName, dm)
+		elem, elemKnown := r.goType(spec[end+1:], pkgName, dm)
+		return "map[" + key + "]" + elem, keyKnown && elemKnown
+	}
+	for _, prefix := range []string{"list of ", "array of ", "set of "} {
+		if strings.HasPrefix(lower, prefix) {
+			elem, known := r.goType(spec[len(prefix):], pkgName, dm)
+			return "[]" + elem, known
+		}
+	}
+
+	if goBuiltinTypes[spec] {
+		return spec, true
+	}
+	if qualifier, name, ok := strings.Cut(spec, "."); ok && token.IsIdentifier(qualifier) && token.IsIdentifier(name) {
+		return spec, true
+	}
+	if typ, ok := r.namedType(spec, pkgName, dm); ok {
+		return typ, true
+	}
+
+	// "email string" or "unique integer": the first word naming a type wins
+	words := strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range append([]string{lower}, words...) {
+		if goBuiltinTypes[word] {
+			return word, true
+		}
+		if typ, ok := specTypes[word]; ok {
+			return typ, true
+		}
+	}
+	return "string", false
+}
+
+// namedType returns the type of the entity or enum named name, qualified when
+// it belongs to a package other than pkgName
+func (r *typeRegistry) namedType(name, pkgName string, dm models.DataModel) (string, bool) {
+	qualify := func(typeName, pkg string) string {
+		if other := goPackageName(pkg); other != "" && pkgName != "" && other != pkgName {
+			return other + "." + typeName
+		}
+		return typeName
+	}
+
+	for _, t := range r.types {
+		if t.entity == name || t.typeName == name {
+			return qualify(t.typeName, t.pkg), true
+		}
+	}
+	if enum, ok := dm.FindEnum(name); ok {
+		return qualify(enum.Name, enum.Package), true
+	}
+	return "", false
+}
+
+// goFieldName returns the exported field name of an attribute, so
+// "password_hash" gives PasswordHash and "userId" gives UserID
+func goFieldName(attr string) string {
+	var sb strings.Builder
+	for _, word := range strings.FieldsFunc(attr, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		for _, hump := range camelHumps(word) {
+			if fieldInitialisms[strings.ToLower(hump)] {
+				sb.WriteString(strings.ToUpper(hump))
+				continue
+			}
+			runes := []rune(hump)
+			runes[0] = unicode.ToUpper(runes[0])
+			sb.WriteString(string(runes))
+		}
+	}
+
+	name := sb.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "F" + name
+	}
+	return name
+}
+
+// camelHumps splits a word where a lower case letter or digit is followed by
+// an upper case one, so "userId" gives "user" and "Id"
+func camelHumps(word string) []string {
+	var humps []string
+	runes := []rune(word)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && !unicode.IsUpper(runes[i-1]) {
+			humps = append(humps, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(humps, string(runes[start:]))
+}
+
+// dirInPackage reports whether a directory holds a data model package given
+// as a name, a directory or an import path
+func dirInPackage(dir, pkg string) bool {
+	if dir == "." || pkg == "." || pkg == "" {
+		return false
+	}
+	return dir == pkg || strings.HasSuffix(dir, "/"+pkg) || strings.HasSuffix(pkg, "/"+dir)
+}
+
+// owners returns the planned file that declares each entity: the file named
+// after it in its package, else the one the plan gives it, else the
+// package's models or types file, else the package's first file. Entities
+// whose package has no planned Go file have no owner.
+func (r *typeRegistry) owners(plan *models.GenerationPlan) map[string]string {
+	owners := make(map[string]string, len(r.types))
+	if plan == nil {
+		return owners
+	}
+
+	var tasks []models.GenerationTask
+	for _, phase := range plan.Phases {
+		for _, task := range phase.Tasks {
+			if strings.HasSuffix(task.TargetPath, ".go") && !strings.HasSuffix(task.TargetPath, "_test.go") {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+
+	for _, t := range r.types {
+		best, bestRank := "", 5
+		for _, task := range tasks {
+			target := normalizePath(task.TargetPath)
+			if !dirInPackage(path.Dir(target), t.pkg) {
+				continue
+			}
+
+			stem := strings.TrimSuffix(path.Base(target), ".go")
+			rank := 4
+			switch {
+			case strings.ReplaceAll(stem, "_", "") == strings.ToLower(t.typeName):
+				rank = 1
+			case slices.Contains(inputStrings(task.Inputs, "entities"), t.entity):
+				rank = 2
+			case modelFileStems[stem] || stem == t.pkgName:
+				rank = 3
+			}
+			if rank < bestRank {
+				best, bestRank = target, rank
+			}
+		}
+		if best != "" {
+			owners[t.entity] = best
+		}
+	}
+	return owners
+}
+
+// modelFileStems are the names of files that declare a package's types
+var modelFileStems = map[string]bool{
+	"models": true, "model": true, "entities": true, "entity": true, "types": true, "domain": true,
+}
+
+// formatEntityTypes renders the declarations of the entities a Go file works
+// with as a prompt section: the ones the file declares, and those declared
+// elsewhere that it must use as they are. It returns "" for other files and
+// when no entity is relevant.
+func (c *llmCoder) formatEntityTypes(task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS) string {
+	if c.types == nil || filteredFCS == nil || !strings.HasSuffix(task.TargetPath, ".go") {
+		return ""
+	}
+
+	relevant := make(map[string]bool, len(filteredFCS.DataModel.Entities))
+	for _, entity := range filteredFCS.DataModel.Entities {
+		relevant[entity.Name] = true
+	}
+
+	target := normalizePath(task.TargetPath)
+	owners := c.types.owners(plan)
+	var declared []entityType
+	used := make(map[string][]entityType) // By package
+	var usedPkgs []string
+	for _, t := range c.types.types {
+		switch owner := owners[t.entity]; {
+		case owner == target:
+			declared = append(declared, t)
+		case relevant[t.entity]:
+			if _, ok := used[t.pkg]; !ok {
+				usedPkgs = append(usedPkgs, t.pkg)
+			}
+			used[t.pkg] = append(used[t.pkg], t)
+		}
+	}
+	if len(declared) == 0 && len(usedPkgs) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Entity Types\n\n")
+	sb.WriteString("These declarations are rendered from the data model and shared by every file of the project. ")
+	sb.WriteString("Use the type names, field names, field types and JSON tags exactly as shown.\n\n")
+
+	if len(declared) > 0 {
+		sb.WriteString("Declare these types in this file exactly as shown. You may add doc comments and methods, ")
+		sb.WriteString("but do not rename, retype, add or remove fields:\n\n")
+		writeEntityTypes(&sb, declared, "")
+	}
+
+	if len(usedPkgs) > 0 {
+		sb.WriteString("These types are declared by other files. Use them as shown and do not declare them again:\n\n")
+		for _, pkg := range usedPkgs {
+			types := used[pkg]
+			var files []string
+			for _, t := range types {
+				if owner := owners[t.entity]; owner != "" && !slices.Contains(files, owner) {
+					files = append(files, owner)
+				}
+			}
+			writeEntityTypes(&sb, types, strings.Join(files, ", "))
+		}
+	}
+	return sb.String()
+}
+
+// writeEntityTypes writes the declarations of one package's entities as a Go
+// block, noting the files that declare them when known
+func writeEntityTypes(sb *strings.Builder, types []entityType, files string) {
+	sb.WriteString("```go\n")
+	if files != "" {
+		sb.WriteString(fmt.Sprintf("// Declared in %s\n", files))
+	}
+	if pkgName := types[0].pkgName; pkgName != "" {
+		sb.WriteString(fmt.Sprintf("package %s\n\n", pkgName))
+	}
+
+	var imports []string
+	for _, t := range types {
+		for _, imp := range t.imports {
+			if !slices.Contains(imports, imp) {
+				imports = append(imports, imp)
+			}
+		}
+	}
+	sort.Strings(imports)
+	switch len(imports) {
+	case 0:
+	case 1:
+		sb.WriteString(fmt.Sprintf("import %q\n\n", imports[0]))
+	default:
+		sb.WriteString("import (\n")
+		for _, imp := range imports {
+			sb.WriteString(fmt.Sprintf("\t%q\n", imp))
+		}
+		sb.WriteString(")\n\n")
+	}
+
+	for i, t := range types {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(t.decl)
+	}
+	sb.WriteString("```\n\n")
+}
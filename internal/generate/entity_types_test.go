@@ -0,0 +1,100 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func entityTypesFCS() *models.FinalClarifiedSpecification {
+	return &models.FinalClarifiedSpecification{DataModel: models.DataModel{
+		Entities: []models.Entity{
+			{Name: "User", Package: "internal/models", Attributes: map[string]string{
+				"id": "UUID", "email": "email string", "created_at": "timestamp", "status": "UserStatus", "rating": "Stars",
+			}},
+			{Name: "Order", Package: "internal/models", Attributes: map[string]string{
+				"ID": "string", "userId": "User", "items": "[]OrderItem", "total": "decimal",
+			}},
+			{Name: "order item", Package: "internal/models", Attributes: map[string]string{"sku": "string", "qty": "int"}},
+			{Name: "Session", Package: "internal/auth", Attributes: map[string]string{"user": "*User", "tags": "map[string]list of text"}},
+		},
+		Enums: []models.Enum{{Name: "UserStatus", Package: "internal/models", Values: []string{"active"}}},
+	}}
+}
+
+func TestTypeRegistry_Render(t *testing.T) {
+	registry := newTypeRegistry(entityTypesFCS().DataModel, "")
+	require.NotNil(t, registry)
+	require.Len(t, registry.types, 4)
+
+	user := registry.types[0]
+	assert.Equal(t, "internal/models", user.pkg)
+	assert.Equal(t, "models", user.pkgName)
+	assert.Equal(t, "type User struct {\n"+
+		"\tCreatedAt time.Time  `json:\"created_at\"`\n"+
+		"\tEmail     string     `json:\"email\"`\n"+
+		"\tID        string     `json:\"id\"`\n"+
+		"\tRating    string     `json:\"rating\"` // Stars\n"+
+		"\tStatus    UserStatus `json:\"status\"`\n"+
+		"}\n", user.decl, "fields are sorted; unknown types become strings noting the spec type")
+	assert.Equal(t, []string{"time"}, user.imports)
+
+	assert.Equal(t, "type Order struct {\n"+
+		"\tID     string      `json:\"id\"`\n"+
+		"\tItems  []OrderItem `json:\"items\"`\n"+
+		"\tTotal  float64     `json:\"total\"`\n"+
+		"\tUserID User        `json:\"user_id\"`\n"+
+		"}\n", registry.types[1].decl)
+	assert.Equal(t, "OrderItem", registry.types[2].typeName)
+
+	assert.Equal(t, "type Session struct {\n"+
+		"\tTags map[string][]string `json:\"tags\"`\n"+
+		"\tUser *models.User        `json:\"user\"`\n"+
+		"}\n", registry.types[3].decl, "entities of other packages are qualified")
+
+	assert.Nil(t, newTypeRegistry(models.DataModel{}, ""))
+}
+
+func TestTypeRegistry_Owners(t *testing.T) {
+	registry := newTypeRegistry(entityTypesFCS().DataModel, "")
+	plan := &models.GenerationPlan{Phases: []models.GenerationPhase{{Tasks: []models.GenerationTask{
+		{TargetPath: "internal/models/user_test.go"},
+		{TargetPath: "internal/models/models.go"},
+		{TargetPath: "internal/models/order_item.go"},
+		{TargetPath: "internal/models/user.go"},
+		{TargetPath: "internal/models/checkout.go", Inputs: map[string]interface{}{"entities": []interface{}{"Order"}}},
+		{TargetPath: "internal/handlers/user.go"},
+	}}}}
+
+	assert.Equal(t, map[string]string{
+		"User":       "internal/models/user.go",
+		"Order":      "internal/models/checkout.go",
+		"order item": "internal/models/order_item.go",
+	}, registry.owners(plan), "a package without planned files owns nothing")
+}
+
+func TestFormatEntityTypes(t *testing.T) {
+	fcs := entityTypesFCS()
+	coder := &llmCoder{}
+	coder.SetFCS(fcs)
+	plan := &models.GenerationPlan{Phases: []models.GenerationPhase{{Tasks: []models.GenerationTask{
+		{TargetPath: "internal/models/user.go"},
+		{TargetPath: "internal/auth/session.go"},
+	}}}}
+	filtered := coder.contextFilter.FilterForFile("internal/auth/session.go", plan, fcs)
+
+	declaring := coder.formatEntityTypes(models.GenerationTask{TargetPath: "internal/auth/session.go"}, plan, filtered)
+	assert.Contains(t, declaring, "# Entity Types")
+	assert.Contains(t, declaring, "Declare these types in this file exactly as shown")
+	assert.Contains(t, declaring, "package auth\n\ntype Session struct {")
+	assert.Contains(t, declaring, "These types are declared by other files")
+	assert.Contains(t, declaring, "// Declared in internal/models/user.go\npackage models\n\nimport \"time\"\n\ntype User struct {")
+
+	prompt := coder.buildCodeGenerationPrompt(models.GenerationTask{TargetPath: "internal/auth/session.go"}, plan, filtered)
+	assert.Contains(t, prompt, declaring)
+
+	assert.Empty(t, coder.formatEntityTypes(models.GenerationTask{TargetPath: "internal/auth/README.md"}, plan, filtered))
+	assert.Empty(t, coder.formatEntityTypes(models.GenerationTask{TargetPath: "internal/auth/session.go"}, plan, nil))
+}
@@ -0,0 +1,25 @@
+package generate
+
+import (
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// writeEnumGuidelines writes the rules for declaring and using the
+// specification's enums. It writes nothing when there are none.
+func writeEnumGuidelines(sb *strings.Builder, enums []models.Enum) {
+	if len(enums) == 0 {
+		return
+	}
+
+	sb.WriteString("# Enums\n\n")
+	sb.WriteString("The specification defines enums. Wherever this file declares or uses them:\n")
+	sb.WriteString("- Declare each enum only in its listed package, as a named type over its backing type ")
+	sb.WriteString("(e.g. `type OrderStatus string`), with one typed constant per value named as listed\n")
+	sb.WriteString("- String-backed constants hold the value text; integer-backed constants use iota in the listed order\n")
+	sb.WriteString("- Give each enum a `String() string` method returning the value text and a ")
+	sb.WriteString("`Parse<Name>(s string) (<Name>, error)` function that rejects unknown values\n")
+	sb.WriteString("- Type struct fields, parameters and return values with the enum, never with the raw backing type\n")
+	sb.WriteString("- Compare and assign with the constants, not with string or integer literals\n\n")
+}
@@ -0,0 +1,165 @@
+package generate
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// Heuristic per-call budgets used to project plan costs before any LLM call is made
+const (
+	estimatePromptOverheadTokens  = 1200 // Instructions, guidelines and file metadata in each prompt
+	estimateSourceOutputTokens    = 1500 // Typical generated source file
+	estimateTestOutputTokens      = 2000 // Typical generated test file
+	estimateOutputTokensPerSecond = 60.0
+)
+
+// EstimateConfig configures plan cost projection
+type EstimateConfig struct {
+	Provider string
+	Model    string
+	Pricing  llm.Pricing
+
+	// OutputTokensPerSecond is the expected generation speed (default: 60)
+	OutputTokensPerSecond float64
+}
+
+// NewEstimateConfig derives an estimate configuration from an LLM client's provider and model
+func NewEstimateConfig(client llm.Client) EstimateConfig {
+	return EstimateConfig{
+		Provider: client.Provider(),
+		Model:    client.Model(),
+		Pricing:  llm.PricingFor(llm.Provider(client.Provider()), client.Model()),
+	}
+}
+
+// EstimatePlan projects the cost and duration of the code and test phases of
+// a plan, attributing each file to the priorities of the requirements it
+// implements. Incremental runs regenerate fewer files, so this is an upper bound.
+func EstimatePlan(plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification, cfg EstimateConfig) *models.PlanEstimate {
+	if cfg.OutputTokensPerSecond <= 0 {
+		cfg.OutputTokensPerSecond = estimateOutputTokensPerSecond
+	}
+
+	estimate := &models.PlanEstimate{
+		Provider: cfg.Provider,
+		Model:    cfg.Model,
+		Phases:   []string{"generate_packages", "generate_tests"},
+	}
+	if plan == nil {
+		return estimate
+	}
+
+	inputTokens := estimateInputTokens(fcs)
+	var requirements []models.FunctionalRequirement
+	if fcs != nil {
+		requirements = fcs.Requirements.Functional
+	}
+
+	generatedBy := make(map[string]string, len(plan.FileTree.Files))
+	var sources []string
+	for _, file := range plan.FileTree.Files {
+		generatedBy[filepath.Clean(file.Path)] = file.GeneratedBy
+		if strings.HasSuffix(file.Path, ".go") && !strings.HasSuffix(file.Path, "_test.go") {
+			sources = append(sources, file.Path)
+		}
+	}
+	owners := assignRequirements(sources, plan, requirements)
+
+	call := func(outputTokens int64) models.CostEstimate {
+		return estimateCall(cfg, inputTokens, outputTokens)
+	}
+
+	items := make(map[[2]string]models.CostEstimate)
+	attribute := func(phase, file string, cost models.CostEstimate) {
+		reqs := owners[file]
+		if len(reqs) == 0 {
+			key := [2]string{phase, models.PriorityShared}
+			sum := items[key]
+			sum.Add(cost)
+			items[key] = sum
+			return
+		}
+
+		// Split the file's budget evenly across the requirements it implements
+		for i, req := range reqs {
+			priority := strings.ToLower(strings.TrimSpace(req.Priority))
+			if priority == "" {
+				priority = models.PriorityUnspecified
+			}
+			share := cost.Scale(1 / float64(len(reqs)))
+			if i > 0 {
+				share.Calls = 0 // The call itself is counted once
+			}
+			key := [2]string{phase, priority}
+			sum := items[key]
+			sum.Add(share)
+			items[key] = sum
+		}
+	}
+
+	for _, phase := range plan.Phases {
+		for _, task := range phase.Tasks {
+			if !task.WritesFile() || task.TargetPath == "" {
+				continue
+			}
+			if generatedBy[filepath.Clean(task.TargetPath)] == "template" {
+				continue
+			}
+			attribute("generate_packages", task.TargetPath, call(estimateSourceOutputTokens))
+		}
+	}
+
+	for _, source := range sources {
+		attribute("generate_tests", source, call(estimateTestOutputTokens))
+	}
+
+	phaseOrder := make(map[string]int, len(estimate.Phases))
+	for i, phase := range estimate.Phases {
+		phaseOrder[phase] = i
+	}
+	for key, cost := range items {
+		estimate.Items = append(estimate.Items, models.EstimateItem{Phase: key[0], Priority: key[1], Estimate: cost})
+	}
+	sort.Slice(estimate.Items, func(i, j int) bool {
+		a, b := estimate.Items[i], estimate.Items[j]
+		if a.Phase != b.Phase {
+			return phaseOrder[a.Phase] < phaseOrder[b.Phase]
+		}
+		return a.Priority < b.Priority
+	})
+
+	return estimate
+}
+
+// estimateInputTokens returns the input tokens of one generation prompt. Every
+// prompt carries (a filtered view of) the FCS; its full size is the upper bound.
+func estimateInputTokens(fcs *models.FinalClarifiedSpecification) int64 {
+	inputTokens := int64(estimatePromptOverheadTokens)
+	if fcs != nil {
+		if data, err := json.Marshal(fcs); err == nil {
+			inputTokens += int64(len(data) / 4)
+		}
+	}
+	return inputTokens
+}
+
+// estimateCall returns the projected cost of one LLM call
+func estimateCall(cfg EstimateConfig, inputTokens, outputTokens int64) models.CostEstimate {
+	tokensPerSecond := cfg.OutputTokensPerSecond
+	if tokensPerSecond <= 0 {
+		tokensPerSecond = estimateOutputTokensPerSecond
+	}
+	return models.CostEstimate{
+		Calls:        1,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		CostUSD:      cfg.Pricing.Cost(inputTokens, outputTokens),
+		Duration:     time.Duration(float64(outputTokens) / tokensPerSecond * float64(time.Second)),
+	}
+}
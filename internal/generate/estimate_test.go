@@ -0,0 +1,112 @@
+package generate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func estimateTestPlan() *models.GenerationPlan {
+	return &models.GenerationPlan{
+		FileTree: models.FileTree{
+			Files: []models.File{
+				{Path: "go.mod", GeneratedBy: "template"},
+				{Path: "internal/billing/invoice.go", Purpose: "Invoice generation"},
+				{Path: "internal/theme/theme.go", Purpose: "Dark theme support"},
+			},
+		},
+		Phases: []models.GenerationPhase{{
+			Name: "code",
+			Tasks: []models.GenerationTask{
+				{ID: "gomod", Type: "generate_file", TargetPath: "go.mod"},
+				{ID: "invoice", Type: "generate_file", TargetPath: "internal/billing/invoice.go"},
+				{ID: "theme", Type: "generate_file", TargetPath: "internal/theme/theme.go"},
+				{ID: "build", Type: "run_command"},
+			},
+		}},
+	}
+}
+
+func TestEstimatePlan(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{
+		Requirements: models.Requirements{
+			Functional: []models.FunctionalRequirement{
+				{ID: "FR-001", Description: "Generate invoice documents for billing", Priority: "High"},
+				{ID: "FR-002", Description: "Offer a dark theme option", Priority: "low"},
+			},
+		},
+	}
+	cfg := EstimateConfig{
+		Model:                 "test-model",
+		Pricing:               llm.Pricing{InputPerMTok: 1, OutputPerMTok: 10},
+		OutputTokensPerSecond: 100,
+	}
+
+	estimate := EstimatePlan(estimateTestPlan(), fcs, cfg)
+	require.NotNil(t, estimate)
+	assert.Equal(t, "test-model", estimate.Model)
+
+	phases := estimate.ByPhase()
+	require.Len(t, phases, 2)
+	assert.Equal(t, "generate_packages", phases[0].Name)
+	assert.Equal(t, 2, phases[0].Estimate.Calls, "template files and commands cost nothing")
+	assert.Equal(t, int64(2*estimateSourceOutputTokens), phases[0].Estimate.OutputTokens)
+	assert.Equal(t, 2*15*time.Second, phases[0].Estimate.Duration)
+	assert.Equal(t, "generate_tests", phases[1].Name)
+	assert.Equal(t, 2, phases[1].Estimate.Calls)
+
+	priorities := estimate.ByPriority()
+	require.Len(t, priorities, 2)
+	assert.ElementsMatch(t, []string{"high", "low"}, []string{priorities[0].Name, priorities[1].Name})
+	assert.InDelta(t, priorities[0].Estimate.CostUSD, priorities[1].Estimate.CostUSD, 1e-9)
+
+	total := estimate.Total()
+	assert.Equal(t, 4, total.Calls)
+	assert.InDelta(t, cfg.Pricing.Cost(total.InputTokens, total.OutputTokens), total.CostUSD, 1e-9)
+
+	remainingPhases, remaining := estimate.Remaining(map[string]bool{"generate_packages": true})
+	assert.Equal(t, []string{"generate_tests"}, remainingPhases)
+	assert.Equal(t, phases[1].Estimate, remaining)
+}
+
+func TestEstimatePlan_SplitsSharedFiles(t *testing.T) {
+	plan := &models.GenerationPlan{
+		FileTree: models.FileTree{Files: []models.File{{Path: "internal/app/app.go"}}},
+		Phases: []models.GenerationPhase{{
+			Name:  "code",
+			Tasks: []models.GenerationTask{{ID: "app", Type: "generate_file", TargetPath: "internal/app/app.go"}},
+		}},
+	}
+	fcs := &models.FinalClarifiedSpecification{
+		Requirements: models.Requirements{
+			Functional: []models.FunctionalRequirement{
+				{ID: "FR-001", Description: "First", Priority: "high"},
+				{ID: "FR-002", Description: "Second"},
+			},
+		},
+	}
+
+	estimate := EstimatePlan(plan, fcs, EstimateConfig{Pricing: llm.Pricing{InputPerMTok: 1, OutputPerMTok: 1}})
+
+	byPriority := make(map[string]models.CostEstimate)
+	for _, group := range estimate.ByPriority() {
+		byPriority[group.Name] = group.Estimate
+	}
+	require.Contains(t, byPriority, "high")
+	require.Contains(t, byPriority, models.PriorityUnspecified)
+	assert.InDelta(t, byPriority["high"].CostUSD, byPriority[models.PriorityUnspecified].CostUSD, 1e-9)
+	assert.Equal(t, 2, estimate.Total().Calls, "one code and one test call, counted once each")
+}
+
+func TestEstimatePlan_NoRequirements(t *testing.T) {
+	estimate := EstimatePlan(estimateTestPlan(), nil, EstimateConfig{})
+
+	priorities := estimate.ByPriority()
+	require.Len(t, priorities, 1)
+	assert.Equal(t, models.PriorityShared, priorities[0].Name)
+	assert.Zero(t, priorities[0].Estimate.CostUSD, "no pricing configured")
+}
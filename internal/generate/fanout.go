@@ -0,0 +1,105 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/langgraph-go/graph"
+)
+
+// fanOutBranch is a node run by a fan-out alongside its other branches
+type fanOutBranch struct {
+	name    string
+	fn      graph.NodeFunc[GenerationState]
+	timeout time.Duration // 0 for no limit
+}
+
+// fanOut returns a node that runs its branches concurrently on the state it
+// is given and fans their results back in. The branch deltas are reduced in
+// branch order, so the merged state does not depend on which branch finished
+// first, and each branch is recorded in the state log as a node routing to
+// next. The first branch to fail cancels the others and stops the run.
+//
+// The engine cannot fan out itself: langgraph-go ends the workflow after a
+// parallel route, and its concurrent mode hands each node the state from
+// before its predecessor's delta.
+func fanOut(next string, branches ...fanOutBranch) graph.NodeFunc[GenerationState] {
+	return func(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
+		branchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		results := make([]graph.NodeResult[GenerationState], len(branches))
+		started := make([]time.Time, len(branches))
+		failed := -1
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for i, b := range branches {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				started[i] = time.Now()
+				results[i] = runBranch(logctx.With(branchCtx, logctx.NodeField, b.name), b, s)
+
+				if r := results[i]; r.Err != nil || r.Delta.Error != nil || r.Route.Terminal {
+					mu.Lock()
+					if failed < 0 {
+						failed = i
+						cancel()
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		recorder, _ := ctx.Value(stateRecorderKey{}).(*stateRecorder)
+		var delta GenerationState
+		for i, b := range branches {
+			if !results[i].Route.Terminal {
+				results[i].Route = graph.Goto(next)
+			}
+			if recorder != nil {
+				recorder.record(b.name, started[i], results[i])
+			}
+			delta = reduceGenerationState(delta, results[i].Delta)
+		}
+
+		if failed >= 0 {
+			// Siblings cancelled by the failure report the cancellation;
+			// the run reports what caused it
+			delta.Error = results[failed].Delta.Error
+			return graph.NodeResult[GenerationState]{
+				Delta: delta,
+				Route: graph.Stop(),
+				Err:   results[failed].Err,
+			}
+		}
+		return graph.NodeResult[GenerationState]{
+			Delta: delta,
+			Route: graph.Goto(next),
+		}
+	}
+}
+
+// runBranch runs a branch under its own deadline, reporting a missed deadline
+// the way the engine reports a node timeout
+func runBranch(ctx context.Context, b fanOutBranch, s GenerationState) graph.NodeResult[GenerationState] {
+	if b.timeout == 0 {
+		return b.fn(ctx, s)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	result := b.fn(ctx, s)
+	if ctx.Err() == context.DeadlineExceeded {
+		result.Err = &graph.EngineError{
+			Message: fmt.Sprintf("node %s exceeded timeout of %v", b.name, b.timeout),
+			Code:    "NODE_TIMEOUT",
+		}
+	}
+	return result
+}
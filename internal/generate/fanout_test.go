@@ -0,0 +1,98 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/langgraph-go/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// phaseBranch returns a branch that waits for ready, then completes phase
+// with delta
+func phaseBranch(name string, ready <-chan struct{}, delta GenerationState) fanOutBranch {
+	return fanOutBranch{name: name, fn: func(ctx context.Context, _ GenerationState) graph.NodeResult[GenerationState] {
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return graph.NodeResult[GenerationState]{Delta: GenerationState{Error: ctx.Err()}, Route: graph.Stop()}
+		}
+		delta.CurrentPhase = name
+		delta.CompletedPhases = []string{name}
+		return graph.NodeResult[GenerationState]{Delta: delta, Route: graph.Goto("ignored")}
+	}}
+}
+
+func TestFanOut_MergesBranchesInOrder(t *testing.T) {
+	outputDir := t.TempDir()
+	recorder, err := newStateRecorder(outputDir, "run-1", GenerationState{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = recorder.Close() })
+
+	// code waits for config, so the branches finish out of order, and only
+	// if they run at the same time
+	configDone := make(chan struct{})
+	testsReady := make(chan struct{})
+	close(testsReady)
+	code := phaseBranch("generate_packages", configDone, GenerationState{CodePatches: []models.Patch{{TargetFile: "main.go"}}})
+	tests := phaseBranch("generate_tests", testsReady, GenerationState{TestPatches: []models.Patch{{TargetFile: "main_test.go"}}})
+	config := phaseBranch("generate_config", testsReady, GenerationState{ConfigPatches: []models.Patch{{TargetFile: "go.mod"}}})
+	configFn := config.fn
+	config.fn = func(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
+		defer close(configDone)
+		return configFn(ctx, s)
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), stateRecorderKey{}, recorder), 5*time.Second)
+	defer cancel()
+	result := fanOut("generate_docs", code, tests, config)(ctx, GenerationState{CurrentPhase: "create_plan"})
+
+	require.NoError(t, result.Delta.Error)
+	assert.Equal(t, graph.Goto("generate_docs"), result.Route)
+	assert.Equal(t, "main.go", result.Delta.CodePatches[0].TargetFile)
+	assert.Equal(t, "main_test.go", result.Delta.TestPatches[0].TargetFile)
+	assert.Equal(t, "go.mod", result.Delta.ConfigPatches[0].TargetFile)
+	assert.Equal(t, "generate_config", result.Delta.CurrentPhase, "the last branch's phase, whichever finished last")
+	assert.Equal(t, []string{"generate_packages", "generate_tests", "generate_config"}, result.Delta.CompletedPhases)
+
+	transitions, err := LoadStateTransitions(outputDir, "run-1")
+	require.NoError(t, err)
+	require.Len(t, transitions, 4)
+	for i, name := range []string{"generate_packages", "generate_tests", "generate_config"} {
+		assert.Equal(t, name, transitions[i+1].Node)
+		assert.Equal(t, "generate_docs", transitions[i+1].Next)
+	}
+}
+
+func TestFanOut_FailureCancelsBranches(t *testing.T) {
+	failure := errors.New("rate limited")
+	failing := fanOutBranch{name: "generate_config", fn: func(context.Context, GenerationState) graph.NodeResult[GenerationState] {
+		return graph.NodeResult[GenerationState]{Delta: GenerationState{Error: failure}, Route: graph.Stop()}
+	}}
+	never := make(chan struct{})
+	blocked := phaseBranch("generate_packages", never, GenerationState{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result := fanOut("generate_docs", blocked, failing)(ctx, GenerationState{})
+
+	assert.True(t, result.Route.Terminal)
+	assert.Equal(t, failure, result.Delta.Error, "the failure is reported, not the cancellation it caused")
+	assert.NoError(t, ctx.Err(), "the blocked branch was cancelled, not timed out")
+}
+
+func TestFanOut_BranchTimeout(t *testing.T) {
+	never := make(chan struct{})
+	slow := phaseBranch("generate_tests", never, GenerationState{})
+	slow.timeout = 10 * time.Millisecond
+
+	result := fanOut("generate_docs", slow)(context.Background(), GenerationState{})
+
+	assert.True(t, result.Route.Terminal)
+	require.Error(t, result.Err)
+	assert.ErrorContains(t, result.Err, "node generate_tests exceeded timeout of 10ms")
+}
@@ -0,0 +1,185 @@
+package generate
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// fuzzTarget is a generated function worth fuzzing: a top-level function
+// taking a single []byte or string and returning an error, the shape of most
+// parsers and decoders
+type fuzzTarget struct {
+	Func    string // Function under test
+	Name    string // Fuzz function name, e.g. FuzzParseConfig
+	Input   string // "[]byte" or "string"
+	Results int    // Number of results, the last being the error
+}
+
+// findFuzzTargets returns the package name of a generated source file and
+// the functions in it that match the fuzz heuristic, in source order. Code
+// that does not parse has no targets.
+func findFuzzTargets(code string) (string, []fuzzTarget) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, parser.SkipObjectResolution)
+	if err != nil {
+		return "", nil
+	}
+
+	var targets []fuzzTarget
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Type.TypeParams != nil || fn.Name.Name == "init" || fn.Name.Name == "main" {
+			continue
+		}
+		params := fn.Type.Params.List
+		if len(params) != 1 || len(params[0].Names) > 1 {
+			continue
+		}
+		input := fuzzInputType(params[0].Type)
+		if input == "" || fn.Type.Results == nil {
+			continue
+		}
+
+		results := 0
+		var last ast.Expr
+		for _, field := range fn.Type.Results.List {
+			n := len(field.Names)
+			if n == 0 {
+				n = 1
+			}
+			results += n
+			last = field.Type
+		}
+		if ident, ok := last.(*ast.Ident); !ok || ident.Name != "error" {
+			continue
+		}
+
+		targets = append(targets, fuzzTarget{
+			Func:    fn.Name.Name,
+			Name:    "Fuzz" + exportName(fn.Name.Name),
+			Input:   input,
+			Results: results,
+		})
+	}
+	return file.Name.Name, targets
+}
+
+// fuzzInputType returns "[]byte" or "string" for the parameter types the
+// fuzz heuristic accepts, or ""
+func fuzzInputType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if t.Name == "string" {
+			return "string"
+		}
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && (ident.Name == "byte" || ident.Name == "uint8") {
+			return "[]byte"
+		}
+	}
+	return ""
+}
+
+// exportName upper-cases the first letter of name
+func exportName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
+
+// fuzzTestFilePath returns the fuzz test file generated for a source file
+func fuzzTestFilePath(sourceFile string) string {
+	return strings.TrimSuffix(sourceFile, ".go") + "_fuzz_test.go"
+}
+
+// renderFuzzFile renders a test file with one fuzz function per target. Each
+// target is seeded with an empty and a short input and only has to return
+// without panicking; go test runs the seeds as ordinary tests and
+// go test -fuzz explores from them.
+func renderFuzzFile(pkg string, targets []fuzzTarget) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "package %s\n\nimport \"testing\"\n", pkg)
+
+	for _, target := range targets {
+		blanks := strings.TrimSuffix(strings.Repeat("_, ", target.Results), ", ")
+		seed := func(s string) string {
+			if target.Input == "[]byte" {
+				return fmt.Sprintf("[]byte(%q)", s)
+			}
+			return fmt.Sprintf("%q", s)
+		}
+
+		fmt.Fprintf(&sb, "\n// %s checks that %s returns an error rather than panicking on malformed input\n", target.Name, target.Func)
+		fmt.Fprintf(&sb, "func %s(f *testing.F) {\n", target.Name)
+		fmt.Fprintf(&sb, "\tf.Add(%s)\n", seed(""))
+		fmt.Fprintf(&sb, "\tf.Add(%s)\n", seed("example"))
+		fmt.Fprintf(&sb, "\tf.Fuzz(func(t *testing.T, input %s) {\n", target.Input)
+		fmt.Fprintf(&sb, "\t\t%s = %s(input)\n", blanks, target.Func)
+		sb.WriteString("\t})\n}\n")
+	}
+	return sb.String()
+}
+
+// fuzzTestFiles renders a fuzz test file for each source file (path ->
+// content) with fuzz targets, keyed by the fuzz file path. Targets whose name
+// is already declared in the package's tests (testCode, by test file path) or
+// by an earlier file of the same package are skipped.
+func fuzzTestFiles(sourceFiles []string, code map[string]string, testCode map[string]string) map[string]string {
+	declared := make(map[string]map[string]bool) // Directory -> fuzz function names
+	files := make(map[string]string)
+
+	for _, sourceFile := range sourceFiles {
+		content, ok := code[sourceFile]
+		if !ok {
+			continue
+		}
+		pkg, targets := findFuzzTargets(content)
+		if len(targets) == 0 {
+			continue
+		}
+
+		dir := filepath.Dir(sourceFile)
+		if declared[dir] == nil {
+			declared[dir] = make(map[string]bool)
+			for testFile, test := range testCode {
+				if filepath.Dir(testFile) == dir {
+					for _, name := range declaredFuncs(test) {
+						declared[dir][name] = true
+					}
+				}
+			}
+		}
+
+		kept := targets[:0]
+		for _, target := range targets {
+			if declared[dir][target.Name] {
+				continue
+			}
+			declared[dir][target.Name] = true
+			kept = append(kept, target)
+		}
+		if len(kept) > 0 {
+			files[fuzzTestFilePath(sourceFile)] = renderFuzzFile(pkg, kept)
+		}
+	}
+	return files
+}
+
+// declaredFuncs returns the top-level function names declared in code
+func declaredFuncs(code string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+			names = append(names, fn.Name.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,100 @@
+package generate
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fuzzSource = `package config
+
+import "io"
+
+type Config struct{ Name string }
+
+func Parse(data []byte) (*Config, error) { return nil, nil }
+
+func parseLine(line string) (string, string, error) { return "", "", nil }
+
+func Validate(s string) error { return nil }
+
+func Load(r io.Reader) (*Config, error) { return nil, nil }
+
+func Join(a, b string) (string, error) { return "", nil }
+
+func Name(s string) string { return s }
+
+func (c *Config) Decode(data []byte) error { return nil }
+`
+
+func TestFindFuzzTargets(t *testing.T) {
+	pkg, targets := findFuzzTargets(fuzzSource)
+	assert.Equal(t, "config", pkg)
+	assert.Equal(t, []fuzzTarget{
+		{Func: "Parse", Name: "FuzzParse", Input: "[]byte", Results: 2},
+		{Func: "parseLine", Name: "FuzzParseLine", Input: "string", Results: 3},
+		{Func: "Validate", Name: "FuzzValidate", Input: "string", Results: 1},
+	}, targets, "readers, two parameters, no error result and methods are not fuzzed")
+
+	_, targets = findFuzzTargets("package broken\n\nfunc Parse(")
+	assert.Empty(t, targets)
+}
+
+func TestRenderFuzzFile(t *testing.T) {
+	_, targets := findFuzzTargets(fuzzSource)
+	code := renderFuzzFile("config", targets)
+
+	_, err := parser.ParseFile(token.NewFileSet(), "config_fuzz_test.go", code, 0)
+	require.NoError(t, err, code)
+	assert.Contains(t, code, "func FuzzParse(f *testing.F) {\n\tf.Add([]byte(\"\"))")
+	assert.Contains(t, code, "f.Fuzz(func(t *testing.T, input []byte) {\n\t\t_, _ = Parse(input)")
+	assert.Contains(t, code, "_, _, _ = parseLine(input)")
+	assert.Contains(t, code, "\t\t_ = Validate(input)")
+}
+
+func TestTestPipeline_FuzzTests(t *testing.T) {
+	tester, err := NewTester(TesterConfig{LLMClient: &scriptedLLMClient{responses: []string{
+		"package config\n\nimport \"testing\"\n\nfunc FuzzValidate(f *testing.F) {}\n",
+	}}})
+	require.NoError(t, err)
+
+	plan := &models.GenerationPlan{
+		FileTree: models.FileTree{Files: []models.File{{Path: "internal/config/config.go"}}},
+	}
+	fcs := &models.FinalClarifiedSpecification{TestingStrategy: models.TestingStrategy{FuzzTests: true}}
+
+	run := tester.StartPipeline(context.Background(), plan, fcs)
+	run.AddPackage([]models.Patch{
+		{TargetFile: "internal/config/config.go", Diff: newFileDiff("internal/config/config.go", fuzzSource)},
+	})
+	patches, err := run.Wait()
+	require.NoError(t, err)
+	require.Len(t, patches, 2)
+
+	assert.Equal(t, "internal/config/config_test.go", patches[0].TargetFile)
+	assert.Equal(t, "internal/config/config_fuzz_test.go", patches[1].TargetFile)
+	fuzzCode := extractContentFromDiff(patches[1].Diff)
+	assert.Contains(t, fuzzCode, "func FuzzParse(")
+	assert.NotContains(t, fuzzCode, "func FuzzValidate(", "already declared by the generated test")
+}
+
+func TestTestPipeline_FuzzTestsDisabled(t *testing.T) {
+	tester, err := NewTester(TesterConfig{LLMClient: &scriptedLLMClient{responses: []string{stdlibTest}}})
+	require.NoError(t, err)
+
+	plan := &models.GenerationPlan{
+		FileTree: models.FileTree{Files: []models.File{{Path: "internal/config/config.go"}}},
+	}
+	run := tester.StartPipeline(context.Background(), plan, &models.FinalClarifiedSpecification{})
+	run.AddPackage([]models.Patch{
+		{TargetFile: "internal/config/config.go", Diff: newFileDiff("internal/config/config.go", fuzzSource)},
+	})
+	patches, err := run.Wait()
+	require.NoError(t, err)
+	require.Len(t, patches, 1)
+}
@@ -0,0 +1,425 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrBudgetExceeded is the cause of a run stopped by its cost or token cap
+var ErrBudgetExceeded = errors.New("generation budget exceeded")
+
+// BudgetLimits caps what a whole run may spend across all LLM calls. A zero
+// limit is disabled.
+type BudgetLimits struct {
+	MaxCost   float64 // USD, at the provider's list price
+	MaxTokens int64   // Input plus output tokens
+}
+
+// IsZero reports whether no limits are set
+func (l BudgetLimits) IsZero() bool {
+	return l == BudgetLimits{}
+}
+
+// BudgetUsage is what a run has spent so far. Tokens are estimated at four
+// bytes each, as elsewhere in estimates, and priced without cache discounts,
+// so the usage errs high.
+type BudgetUsage struct {
+	InputTokens  int64
+	OutputTokens int64
+	Cost         float64
+}
+
+// Tokens returns the input plus output tokens
+func (u BudgetUsage) Tokens() int64 {
+	return u.InputTokens + u.OutputTokens
+}
+
+// BudgetExceededError reports the cap a run crossed and what it had spent
+type BudgetExceededError struct {
+	Usage  BudgetUsage
+	Limits BudgetLimits
+}
+
+// Error implements the error interface
+func (e *BudgetExceededError) Error() string {
+	var crossed []string
+	if e.Limits.MaxCost > 0 && e.Usage.Cost >= e.Limits.MaxCost {
+		crossed = append(crossed, fmt.Sprintf("spent $%.2f of the $%.2f cost cap", e.Usage.Cost, e.Limits.MaxCost))
+	}
+	if e.Limits.MaxTokens > 0 && e.Usage.Tokens() >= e.Limits.MaxTokens {
+		crossed = append(crossed, fmt.Sprintf("used %d of the %d token cap", e.Usage.Tokens(), e.Limits.MaxTokens))
+	}
+	return fmt.Sprintf("%v: %s", ErrBudgetExceeded, strings.Join(crossed, ", "))
+}
+
+// Is matches ErrBudgetExceeded
+func (e *BudgetExceededError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// BudgetConfirmFunc asks whether a run may continue past a crossed cap
+type BudgetConfirmFunc func(ctx context.Context, usage BudgetUsage, limits BudgetLimits) bool
+
+// CostGovernorConfig configures a CostGovernor
+type CostGovernorConfig struct {
+	Limits BudgetLimits
+
+	// Confirm is asked when a cap is crossed; continuing raises the cap by
+	// its configured amount. Nil stops the run at the first crossed cap.
+	Confirm BudgetConfirmFunc
+
+	// Stop cancels the run with the *BudgetExceededError, so every phase
+	// stops at its last checkpoint (optional)
+	Stop context.CancelCauseFunc
+
+	// EventChan receives token and cost updates after each call (optional)
+	EventChan chan<- models.ProgressEvent
+}
+
+// CostGovernor tracks the tokens and cost of every LLM call of a run through
+// the clients it wraps, and refuses further calls once a cap is crossed.
+// Calls in flight when a cap is crossed finish and are counted.
+type CostGovernor struct {
+	mu     sync.Mutex
+	limits BudgetLimits
+	usage  BudgetUsage
+	err    error
+
+	// confirmMu holds calls back while the user is asked to continue
+	confirmMu sync.Mutex
+
+	step      BudgetLimits
+	confirm   BudgetConfirmFunc
+	stop      context.CancelCauseFunc
+	eventChan chan<- models.ProgressEvent
+}
+
+// NewCostGovernor creates a governor, or nil when no limit is set. A nil
+// governor wraps nothing.
+func NewCostGovernor(config CostGovernorConfig) *CostGovernor {
+	if config.Limits.IsZero() {
+		return nil
+	}
+	return &CostGovernor{
+		limits:    config.Limits,
+		step:      config.Limits,
+		confirm:   config.Confirm,
+		stop:      config.Stop,
+		eventChan: config.EventChan,
+	}
+}
+
+// Usage returns what the run has spent so far
+func (g *CostGovernor) Usage() BudgetUsage {
+	if g == nil {
+		return BudgetUsage{}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.usage
+}
+
+// Err returns the *BudgetExceededError that stopped the run, or nil
+func (g *CostGovernor) Err() error {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// Wrap returns client with its calls counted against the budget, keeping the
+// optional interfaces the client implements
+func (g *CostGovernor) Wrap(client llm.Client) llm.Client {
+	if g == nil || client == nil {
+		return client
+	}
+
+	governed := &governedClient{Client: client, governor: g}
+	cacheable, isCacheable := client.(llm.CacheableClient)
+	batch, isBatch := client.(llm.BatchClient)
+	switch {
+	case isCacheable && isBatch:
+		return &governedCacheableBatchClient{
+			governedCacheableClient: &governedCacheableClient{governedClient: governed, cacheable: cacheable},
+			batch:                   batch,
+		}
+	case isCacheable:
+		return &governedCacheableClient{governedClient: governed, cacheable: cacheable}
+	case isBatch:
+		return &governedBatchClient{governedClient: governed, batch: batch}
+	}
+	return governed
+}
+
+// enforce returns the budget error once a cap stopped the run. A crossed cap
+// asks Confirm, holding other calls back until it answers: continuing raises
+// the crossed caps by their configured amount, otherwise the run stops.
+func (g *CostGovernor) enforce(ctx context.Context) error {
+	g.confirmMu.Lock()
+	defer g.confirmMu.Unlock()
+
+	g.mu.Lock()
+	err, usage, limits := g.err, g.usage, g.limits
+	g.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if !crossed(usage, limits) {
+		return nil
+	}
+
+	if g.confirm != nil && g.confirm(ctx, usage, limits) {
+		g.mu.Lock()
+		for g.limits.MaxCost > 0 && g.usage.Cost >= g.limits.MaxCost {
+			g.limits.MaxCost += g.step.MaxCost
+		}
+		for g.limits.MaxTokens > 0 && g.usage.Tokens() >= g.limits.MaxTokens {
+			g.limits.MaxTokens += g.step.MaxTokens
+		}
+		log.Info().
+			Float64("max_cost", g.limits.MaxCost).
+			Int64("max_tokens", g.limits.MaxTokens).
+			Msg("Budget raised; generation continues")
+		g.mu.Unlock()
+		return nil
+	}
+
+	err = &BudgetExceededError{Usage: usage, Limits: limits}
+	g.mu.Lock()
+	g.err = err
+	g.mu.Unlock()
+	log.Warn().Err(err).Msg("Stopping generation at its budget")
+	if g.stop != nil {
+		g.stop(err)
+	}
+	return err
+}
+
+// crossed reports whether usage reached a cap
+func crossed(usage BudgetUsage, limits BudgetLimits) bool {
+	return (limits.MaxCost > 0 && usage.Cost >= limits.MaxCost) ||
+		(limits.MaxTokens > 0 && usage.Tokens() >= limits.MaxTokens)
+}
+
+// record adds a call's prompt and response to the usage, priced at client's
+// model, and reports the new totals
+func (g *CostGovernor) record(client llm.Client, promptBytes, responseBytes int) {
+	inputTokens := int64(promptBytes / 4)
+	outputTokens := int64(responseBytes / 4)
+	cost := llm.PricingFor(llm.Provider(client.Provider()), client.Model()).Cost(inputTokens, outputTokens)
+
+	g.mu.Lock()
+	g.usage.InputTokens += inputTokens
+	g.usage.OutputTokens += outputTokens
+	g.usage.Cost += cost
+	usage := g.usage
+	g.mu.Unlock()
+
+	g.emitEvent(models.NewTokensUsedEvent(client.Provider(), inputTokens, outputTokens, 0, usage.InputTokens, usage.OutputTokens, 0, 0))
+	g.emitEvent(models.NewCostUpdateEvent(client.Provider(), cost, usage.Cost, 0))
+}
+
+// emitEvent sends a progress event to the event channel if configured
+func (g *CostGovernor) emitEvent(event models.ProgressEvent) {
+	if g.eventChan == nil {
+		return
+	}
+	select {
+	case g.eventChan <- event:
+	default:
+		// Totals are cumulative, so the next update makes up for a lost one
+	}
+}
+
+// after records a finished call and stops the run if it crossed a cap. The
+// call's response is still returned: it has been paid for.
+func (g *CostGovernor) after(ctx context.Context, client llm.Client, promptBytes, responseBytes int) {
+	g.record(client, promptBytes, responseBytes)
+	_ = g.enforce(ctx)
+}
+
+// governedClient counts each call against its governor's budget
+type governedClient struct {
+	llm.Client
+	governor *CostGovernor
+}
+
+// Generate produces text while the budget allows
+func (c *governedClient) Generate(ctx context.Context, prompt string) (string, error) {
+	if err := c.governor.enforce(ctx); err != nil {
+		return "", err
+	}
+	text, err := c.Client.Generate(ctx, prompt)
+	c.governor.after(ctx, c.Client, len(prompt), len(text))
+	return text, err
+}
+
+// GenerateStructured produces structured output while the budget allows. The
+// response is counted at the size of its JSON encoding.
+func (c *governedClient) GenerateStructured(ctx context.Context, prompt string, schema interface{}) (interface{}, error) {
+	if err := c.governor.enforce(ctx); err != nil {
+		return nil, err
+	}
+	output, err := c.Client.GenerateStructured(ctx, prompt, schema)
+	c.governor.after(ctx, c.Client, len(prompt), len(fmt.Sprint(output)))
+	return output, err
+}
+
+// StructuredOutput reports whether the wrapped client validates structured
+// output
+func (c *governedClient) StructuredOutput() bool {
+	return llm.SupportsStructuredOutput(c.Client)
+}
+
+// Chat processes messages while the budget allows
+func (c *governedClient) Chat(ctx context.Context, messages []llm.Message) (string, error) {
+	if err := c.governor.enforce(ctx); err != nil {
+		return "", err
+	}
+	text, err := c.Client.Chat(ctx, messages)
+	promptBytes := 0
+	for _, msg := range messages {
+		promptBytes += len(msg.Content)
+	}
+	c.governor.after(ctx, c.Client, promptBytes, len(text))
+	return text, err
+}
+
+// GenerateStream streams a response while the budget allows, counting the
+// bytes written to w. A client without streaming support generates the
+// whole response and writes it to w.
+func (c *governedClient) GenerateStream(ctx context.Context, messages []llm.CacheableMessage, w io.Writer) (int64, error) {
+	if err := c.governor.enforce(ctx); err != nil {
+		return 0, err
+	}
+
+	var n int64
+	var err error
+	switch client := c.Client.(type) {
+	case llm.StreamingClient:
+		n, err = client.GenerateStream(ctx, messages, w)
+	default:
+		var text string
+		if cacheable, ok := client.(llm.CacheableClient); ok {
+			text, err = cacheable.GenerateWithCache(ctx, messages)
+		} else {
+			chat := make([]llm.Message, len(messages))
+			for i, msg := range messages {
+				chat[i] = llm.Message{Role: msg.Role, Content: msg.Content}
+			}
+			text, err = client.Chat(ctx, chat)
+		}
+		if err == nil {
+			var written int
+			written, err = io.WriteString(w, text)
+			n = int64(written)
+		}
+	}
+	c.governor.after(ctx, c.Client, cacheableBytes(messages), int(n))
+	return n, err
+}
+
+// EmitFiles requests files through tool use while the budget allows. A
+// client without tool use returns llm.ErrToolUseUnsupported.
+func (c *governedClient) EmitFiles(ctx context.Context, messages []llm.CacheableMessage) ([]llm.EmittedFile, error) {
+	emitter, ok := c.Client.(llm.FileEmittingClient)
+	if !ok {
+		return nil, llm.ErrToolUseUnsupported
+	}
+	if err := c.governor.enforce(ctx); err != nil {
+		return nil, err
+	}
+	files, err := emitter.EmitFiles(ctx, messages)
+	responseBytes := 0
+	for _, file := range files {
+		responseBytes += len(file.Path) + len(file.Content)
+	}
+	c.governor.after(ctx, c.Client, cacheableBytes(messages), responseBytes)
+	return files, err
+}
+
+// cacheableBytes returns the size of the messages' content
+func cacheableBytes(messages []llm.CacheableMessage) int {
+	n := 0
+	for _, msg := range messages {
+		n += len(msg.Content)
+	}
+	return n
+}
+
+// governedCacheableClient is a governedClient whose client supports prompt caching
+type governedCacheableClient struct {
+	*governedClient
+	cacheable llm.CacheableClient
+}
+
+// GenerateWithCache generates text with cacheable messages while the budget allows
+func (c *governedCacheableClient) GenerateWithCache(ctx context.Context, messages []llm.CacheableMessage) (string, error) {
+	if err := c.governor.enforce(ctx); err != nil {
+		return "", err
+	}
+	text, err := c.cacheable.GenerateWithCache(ctx, messages)
+	c.governor.after(ctx, c.Client, cacheableBytes(messages), len(text))
+	return text, err
+}
+
+// GetCacheMetrics returns the wrapped client's cache metrics
+func (c *governedCacheableClient) GetCacheMetrics() llm.PromptCacheMetrics {
+	return c.cacheable.GetCacheMetrics()
+}
+
+// ResetCacheMetrics resets the wrapped client's cache metrics
+func (c *governedCacheableClient) ResetCacheMetrics() {
+	c.cacheable.ResetCacheMetrics()
+}
+
+// governedBatchClient is a governedClient whose client supports batch jobs
+type governedBatchClient struct {
+	*governedClient
+	batch llm.BatchClient
+}
+
+// GenerateBatch submits a batch job while the budget allows
+func (c *governedBatchClient) GenerateBatch(ctx context.Context, requests []llm.BatchRequest) ([]llm.BatchResult, error) {
+	return governBatch(ctx, c.governedClient, c.batch, requests)
+}
+
+// governedCacheableBatchClient is a governedCacheableClient whose client also
+// supports batch jobs
+type governedCacheableBatchClient struct {
+	*governedCacheableClient
+	batch llm.BatchClient
+}
+
+// GenerateBatch submits a batch job while the budget allows
+func (c *governedCacheableBatchClient) GenerateBatch(ctx context.Context, requests []llm.BatchRequest) ([]llm.BatchResult, error) {
+	return governBatch(ctx, c.governedClient, c.batch, requests)
+}
+
+// governBatch submits a batch job and counts every request in it. A job is
+// submitted whole, so it may carry the run past its cap.
+func governBatch(ctx context.Context, c *governedClient, batch llm.BatchClient, requests []llm.BatchRequest) ([]llm.BatchResult, error) {
+	if err := c.governor.enforce(ctx); err != nil {
+		return nil, err
+	}
+	results, err := batch.GenerateBatch(ctx, requests)
+	promptBytes, responseBytes := 0, 0
+	for _, request := range requests {
+		promptBytes += len(request.Prompt)
+	}
+	for _, result := range results {
+		responseBytes += len(result.Text)
+	}
+	c.governor.after(ctx, c.Client, promptBytes, responseBytes)
+	return results, err
+}
@@ -0,0 +1,90 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostGovernor_StopsAtTokenCap(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{strings.Repeat("x", 400)}}
+	events := make(chan models.ProgressEvent, 10)
+	var stopped error
+	governor := NewCostGovernor(CostGovernorConfig{
+		Limits:    BudgetLimits{MaxTokens: 100},
+		Stop:      func(cause error) { stopped = cause },
+		EventChan: events,
+	})
+	governed := governor.Wrap(client)
+
+	// The call that crosses the cap returns its response and stops the run
+	response, err := governed.Generate(context.Background(), strings.Repeat("p", 40))
+	require.NoError(t, err)
+	assert.Len(t, response, 400)
+	require.ErrorIs(t, stopped, ErrBudgetExceeded)
+	assert.Contains(t, stopped.Error(), "used 110 of the 100 token cap")
+	assert.Equal(t, BudgetUsage{InputTokens: 10, OutputTokens: 100}, governor.Usage())
+
+	// Later calls are refused without a request
+	_, err = governed.Generate(context.Background(), "more")
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	var budgetErr *BudgetExceededError
+	require.True(t, errors.As(governor.Err(), &budgetErr))
+	assert.EqualValues(t, 100, budgetErr.Limits.MaxTokens)
+	assert.Len(t, client.prompts, 1)
+
+	require.Len(t, events, 2)
+	tokens := <-events
+	assert.Equal(t, models.EventTokensUsed, tokens.Type)
+	assert.EqualValues(t, 100, tokens.Data["total_output"])
+	assert.Equal(t, models.EventCostUpdate, (<-events).Type)
+}
+
+func TestCostGovernor_ConfirmRaisesCap(t *testing.T) {
+	client := &pricedLLMClient{
+		scriptedLLMClient: scriptedLLMClient{responses: []string{strings.Repeat("x", 40000)}},
+		provider:          "anthropic",
+		model:             "claude-opus-4-1",
+	}
+	callCost := llm.PricingFor(llm.ProviderAnthropic, "claude-opus-4-1").Cost(0, 10000)
+	require.Positive(t, callCost)
+
+	var asked []BudgetLimits
+	governor := NewCostGovernor(CostGovernorConfig{
+		Limits: BudgetLimits{MaxCost: callCost * 1.25},
+		Confirm: func(_ context.Context, _ BudgetUsage, limits BudgetLimits) bool {
+			asked = append(asked, limits)
+			return len(asked) == 1
+		},
+	})
+	governed := governor.Wrap(client)
+
+	// Continuing raises the cap by its amount
+	for i := 0; i < 3; i++ {
+		_, err := governed.Generate(context.Background(), "")
+		require.NoError(t, err)
+	}
+	require.Len(t, asked, 2)
+	assert.InDelta(t, callCost*1.25, asked[0].MaxCost, 1e-9)
+	assert.InDelta(t, callCost*2.5, asked[1].MaxCost, 1e-9)
+	require.ErrorIs(t, governor.Err(), ErrBudgetExceeded)
+	assert.Contains(t, governor.Err().Error(), "cost cap")
+
+	_, err := governed.Generate(context.Background(), "")
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+}
+
+func TestCostGovernor_Disabled(t *testing.T) {
+	governor := NewCostGovernor(CostGovernorConfig{})
+	assert.Nil(t, governor)
+
+	client := &scriptedLLMClient{responses: []string{"ok"}}
+	assert.Same(t, client, governor.Wrap(client))
+	assert.NoError(t, governor.Err())
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/logctx"
 	"github.com/dshills/gocreator/internal/models"
 	"github.com/dshills/langgraph-go/graph"
 	"github.com/dshills/langgraph-go/graph/emit"
@@ -21,11 +23,13 @@ type GenerationState struct {
 	Plan            *models.GenerationPlan
 	CodePatches     []models.Patch
 	TestPatches     []models.Patch
+	DocPatches      []models.Patch
 	ConfigPatches   []models.Patch
 	AllPatches      []models.Patch
 	Output          *models.GenerationOutput
 	Error           error
 	OutputDir       string
+	Workspace       *Workspace // Sibling modules the FCS depends on, nil outside a workspace
 	PackageList     []string
 	CurrentPhase    string
 	CompletedPhases []string
@@ -46,6 +50,9 @@ func reduceGenerationState(prev, delta GenerationState) GenerationState {
 	if delta.TestPatches != nil {
 		prev.TestPatches = delta.TestPatches
 	}
+	if delta.DocPatches != nil {
+		prev.DocPatches = delta.DocPatches
+	}
 	if delta.ConfigPatches != nil {
 		prev.ConfigPatches = delta.ConfigPatches
 	}
@@ -61,6 +68,9 @@ func reduceGenerationState(prev, delta GenerationState) GenerationState {
 	if delta.OutputDir != "" {
 		prev.OutputDir = delta.OutputDir
 	}
+	if delta.Workspace != nil {
+		prev.Workspace = delta.Workspace
+	}
 	if delta.PackageList != nil {
 		prev.PackageList = delta.PackageList
 	}
@@ -85,13 +95,72 @@ func reduceGenerationState(prev, delta GenerationState) GenerationState {
 	return prev
 }
 
+// DefaultNodeTimeout bounds workflow nodes without a configured phase timeout
+const DefaultNodeTimeout = 10 * time.Minute
+
+// PhaseTimeouts bounds the execution time of the LLM-backed workflow nodes.
+// Zero uses DefaultNodeTimeout; a negative value disables the limit.
+type PhaseTimeouts struct {
+	Plan     time.Duration // create_plan
+	Packages time.Duration // generate_packages
+	Tests    time.Duration // generate_tests; added to Packages when tests are fed the code
+	Docs     time.Duration // generate_docs
+	Config   time.Duration // generate_config
+}
+
+// nodeTimeout maps a configured phase timeout to a node policy timeout
+func nodeTimeout(d time.Duration) time.Duration {
+	switch {
+	case d < 0:
+		return 0 // No limit; the engine default is disabled
+	case d == 0:
+		return DefaultNodeTimeout
+	default:
+		return d
+	}
+}
+
+// pipelinedTimeout bounds a node that runs two phases concurrently by their
+// combined budget; either phase being unlimited makes the node unlimited
+func pipelinedTimeout(a, b time.Duration) time.Duration {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return a + b
+}
+
+// timedNode is a workflow node with its own timeout policy. The node context
+// is derived from the run context, so cancellation and the deadline both reach
+// every LLM and file operation started by the node.
+type timedNode struct {
+	graph.NodeFunc[GenerationState]
+	timeout time.Duration
+}
+
+// Policy implements the optional graph node policy interface
+func (n timedNode) Policy() graph.NodePolicy {
+	return graph.NodePolicy{Timeout: n.timeout}
+}
+
+// PlanApprover reviews a generation plan before code generation begins and
+// returns the plan to generate from, which may be an edited copy. An error
+// stops the run.
+type PlanApprover func(ctx context.Context, plan *models.GenerationPlan) (*models.GenerationPlan, error)
+
 // GenerationGraph creates the LangGraph-Go workflow for code generation
 type GenerationGraph struct {
 	engine            *graph.Engine[GenerationState]
 	planner           Planner
 	coder             Coder
 	tester            Tester
+	docWriter         DocWriter
 	templateGenerator TemplateGenerator
+	project           templates.ProjectSettings
+	estimate          EstimateConfig
+	timeouts          PhaseTimeouts
+	approvePlan       PlanApprover
+	recordState       bool
+	stateManager      *IncrementalStateManager
 	eventChan         chan<- models.ProgressEvent
 }
 
@@ -100,8 +169,15 @@ type GenerationGraphConfig struct {
 	Planner             Planner
 	Coder               Coder
 	Tester              Tester
+	DocWriter           DocWriter // Writes doc.go for each generated package (optional)
 	TemplateGenerator   TemplateGenerator
-	EnableCheckpointing bool
+	Project             templates.ProjectSettings // Configured module path and binary name
+	Estimate            EstimateConfig            // Pricing used for the projected budget shown after planning
+	Timeouts            PhaseTimeouts             // Per-node deadlines for the LLM-backed phases
+	ApprovePlan         PlanApprover              // Reviews the plan before code generation (optional)
+	EnableCheckpointing bool                      // Save the state after each node under <output>/.gocreator/runs for resuming
+	RecordState         bool                      // Persist each node's state delta under <output>/.gocreator/runs
+	StateManager        *IncrementalStateManager  // Skips unchanged template files on incremental runs (optional)
 	EventChan           chan<- models.ProgressEvent
 }
 
@@ -124,22 +200,35 @@ func NewGenerationGraph(cfg GenerationGraphConfig) (*GenerationGraph, error) {
 		planner:           cfg.Planner,
 		coder:             cfg.Coder,
 		tester:            cfg.Tester,
+		docWriter:         cfg.DocWriter,
 		templateGenerator: cfg.TemplateGenerator,
+		project:           cfg.Project,
+		estimate:          cfg.Estimate,
+		timeouts:          cfg.Timeouts,
+		approvePlan:       cfg.ApprovePlan,
+		recordState:       cfg.RecordState,
+		stateManager:      cfg.StateManager,
 		eventChan:         cfg.EventChan,
 	}
 
-	// Create store and emitter
-	st := store.NewMemStore[GenerationState]()
+	// Create store and emitter; the checkpoint store also persists each step
+	var st store.Store[GenerationState] = store.NewMemStore[GenerationState]()
+	if cfg.EnableCheckpointing {
+		st = checkpointStore{MemStore: store.NewMemStore[GenerationState]()}
+	}
 	emitter := emit.NewLogEmitter(os.Stdout, false)
 
 	// Create engine with options
 	// NOTE: Using sequential execution (no WithMaxConcurrent) because concurrent execution
-	// in langgraph-go v0.3.0-alpha has a bug where deltas are not merged between nodes
+	// in langgraph-go v0.3.0-alpha has a bug where deltas are not merged between nodes.
+	// The generate node runs code, test and config generation concurrently itself.
+	// Every node carries its own timeout policy, so the engine default is left unset
+	// to let a disabled phase timeout mean no limit.
 	engine := graph.New(
 		reduceGenerationState,
 		st,
 		emitter,
-		graph.WithDefaultNodeTimeout(10*time.Minute),
+		graph.WithDefaultNodeTimeout(0),
 	)
 
 	// Build the workflow nodes
@@ -155,42 +244,44 @@ func NewGenerationGraph(cfg GenerationGraphConfig) (*GenerationGraph, error) {
 // buildGraph constructs the generation workflow nodes
 func (gg *GenerationGraph) buildGraph(engine *graph.Engine[GenerationState]) error {
 	// Node 1: Start - Initialize state
-	if err := engine.Add("start", graph.NodeFunc[GenerationState](gg.startNode)); err != nil {
+	if err := engine.Add("start", gg.node("start", gg.startNode, DefaultNodeTimeout)); err != nil {
 		return fmt.Errorf("failed to add start node: %w", err)
 	}
 
 	// Node 2: Analyze FCS - Validate and prepare FCS
-	if err := engine.Add("analyze_fcs", graph.NodeFunc[GenerationState](gg.analyzeFCSNode)); err != nil {
+	if err := engine.Add("analyze_fcs", gg.node("analyze_fcs", gg.analyzeFCSNode, DefaultNodeTimeout)); err != nil {
 		return fmt.Errorf("failed to add analyze_fcs node: %w", err)
 	}
 
 	// Node 3: Create Plan - Generate architectural plan
-	if err := engine.Add("create_plan", graph.NodeFunc[GenerationState](gg.createPlanNode)); err != nil {
+	if err := engine.Add("create_plan", gg.node("create_plan", gg.createPlanNode, nodeTimeout(gg.timeouts.Plan))); err != nil {
 		return fmt.Errorf("failed to add create_plan node: %w", err)
 	}
 
-	// Node 4: Generate Packages - Generate source code
-	if err := engine.Add("generate_packages", graph.NodeFunc[GenerationState](gg.generatePackagesNode)); err != nil {
-		return fmt.Errorf("failed to add generate_packages node: %w", err)
+	// Node 4: Approve Plan - Wait for the plan to be reviewed; the reviewer
+	// takes as long as they need, so the node has no deadline
+	if err := engine.Add("approve_plan", gg.node("approve_plan", gg.approvePlanNode, 0)); err != nil {
+		return fmt.Errorf("failed to add approve_plan node: %w", err)
 	}
 
-	// Node 5: Generate Tests - Generate test files
-	if err := engine.Add("generate_tests", graph.NodeFunc[GenerationState](gg.generateTestsNode)); err != nil {
-		return fmt.Errorf("failed to add generate_tests node: %w", err)
+	// Node 5: Generate - Generate source code, tests and configuration files
+	// concurrently; each branch carries its own deadline, so the node has none
+	if err := engine.Add("generate", timedNode{NodeFunc: gg.generateNode}); err != nil {
+		return fmt.Errorf("failed to add generate node: %w", err)
 	}
 
-	// Node 6: Generate Config - Generate configuration files
-	if err := engine.Add("generate_config", graph.NodeFunc[GenerationState](gg.generateConfigNode)); err != nil {
-		return fmt.Errorf("failed to add generate_config node: %w", err)
+	// Node 6: Generate Docs - Generate package documentation
+	if err := engine.Add("generate_docs", gg.node("generate_docs", gg.generateDocsNode, nodeTimeout(gg.timeouts.Docs))); err != nil {
+		return fmt.Errorf("failed to add generate_docs node: %w", err)
 	}
 
 	// Node 7: Apply Patches - Collect and prepare patches
-	if err := engine.Add("apply_patches", graph.NodeFunc[GenerationState](gg.applyPatchesNode)); err != nil {
+	if err := engine.Add("apply_patches", gg.node("apply_patches", gg.applyPatchesNode, DefaultNodeTimeout)); err != nil {
 		return fmt.Errorf("failed to add apply_patches node: %w", err)
 	}
 
 	// Node 8: End - Finalize output
-	if err := engine.Add("end", graph.NodeFunc[GenerationState](gg.endNode)); err != nil {
+	if err := engine.Add("end", gg.node("end", gg.endNode, DefaultNodeTimeout)); err != nil {
 		return fmt.Errorf("failed to add end node: %w", err)
 	}
 
@@ -202,10 +293,35 @@ func (gg *GenerationGraph) buildGraph(engine *graph.Engine[GenerationState]) err
 	return nil
 }
 
-// Execute runs the generation workflow
+// node wraps a node function with its timeout policy and state recording
+func (gg *GenerationGraph) node(name string, fn graph.NodeFunc[GenerationState], timeout time.Duration) graph.Node[GenerationState] {
+	return timedNode{NodeFunc: loggedNode(name, recordedNode(name, fn)), timeout: timeout}
+}
+
+// loggedNode adds the node name to every event logged while the node runs
+func loggedNode(name string, fn graph.NodeFunc[GenerationState]) graph.NodeFunc[GenerationState] {
+	return func(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
+		return fn(logctx.With(ctx, logctx.NodeField, name), s)
+	}
+}
+
+// Execute runs the generation workflow under a new run ID
 func (gg *GenerationGraph) Execute(ctx context.Context, fcs *models.FinalClarifiedSpecification, outputDir string) (*models.GenerationOutput, error) {
+	return gg.ExecuteRun(ctx, NewRunID(), fcs, outputDir)
+}
+
+// NewRunID returns a unique workflow run ID
+func NewRunID() string {
+	return fmt.Sprintf("gen-%s", uuid.New().String())
+}
+
+// ExecuteRun runs the generation workflow under runID. When state recording
+// is enabled, every node's delta is appended to the run's state log.
+func (gg *GenerationGraph) ExecuteRun(ctx context.Context, runID string, fcs *models.FinalClarifiedSpecification, outputDir string) (*models.GenerationOutput, error) {
 	// Create initial state
 	// NOTE: All fields must be explicitly initialized for proper state tracking
+	ctx = logctx.WithRunID(ctx, runID)
+
 	initialState := GenerationState{
 		FCS:             fcs,
 		Plan:            nil,
@@ -216,19 +332,76 @@ func (gg *GenerationGraph) Execute(ctx context.Context, fcs *models.FinalClarifi
 		Output:          nil,
 		Error:           nil,
 		OutputDir:       outputDir,
+		Workspace:       nil,
 		PackageList:     nil,
 		CurrentPhase:    "",
 		CompletedPhases: nil,
 	}
 
-	log.Info().
+	logctx.Logger(ctx).Info().
 		Str("fcs_id", fcs.ID).
 		Str("output_dir", outputDir).
 		Msg("Starting generation workflow execution")
 
+	if gg.recordState {
+		recorder, err := newStateRecorder(outputDir, runID, initialState)
+		if err != nil {
+			// Debugging aid only; the run goes ahead without it
+			logctx.Logger(ctx).Warn().Err(err).Msg("State recording disabled")
+		} else {
+			defer func() { _ = recorder.Close() }()
+			ctx = context.WithValue(ctx, stateRecorderKey{}, recorder)
+		}
+	}
+
+	return gg.run(ctx, runID, "start", initialState)
+}
+
+// ResumeRun continues the run of a checkpoint in outputDir, starting at the
+// node that failed or the one after the last node that finished. The state
+// log of the run, when recorded, is continued.
+func (gg *GenerationGraph) ResumeRun(ctx context.Context, checkpoint *RunCheckpoint, outputDir string) (*models.GenerationOutput, error) {
+	runID := checkpoint.RunID
+	ctx = logctx.WithRunID(ctx, runID)
+
+	node := checkpoint.ResumeNode()
+	if node == "" {
+		return nil, fmt.Errorf("run %s already finished; nothing to resume", runID)
+	}
+
+	// The failed node runs again on the state it failed on
+	state := checkpoint.State.State()
+	state.Error = nil
+	state.OutputDir = outputDir
+
+	logctx.Logger(ctx).Info().
+		Str("checkpoint_node", checkpoint.Node).
+		Str("resume_node", node).
+		Str("output_dir", outputDir).
+		Msg("Resuming generation workflow execution")
+
+	if gg.recordState {
+		recorder, err := resumeStateRecorder(outputDir, runID, state)
+		if err != nil {
+			logctx.Logger(ctx).Warn().Err(err).Msg("State recording disabled")
+		} else {
+			defer func() { _ = recorder.Close() }()
+			ctx = context.WithValue(ctx, stateRecorderKey{}, recorder)
+		}
+	}
+
+	if err := gg.engine.StartAt(node); err != nil {
+		return nil, fmt.Errorf("failed to resume at %s: %w", node, err)
+	}
+	defer func() { _ = gg.engine.StartAt("start") }()
+
+	return gg.run(ctx, runID, node, state)
+}
+
+// run executes the workflow from node and collects the patches of the final state
+func (gg *GenerationGraph) run(ctx context.Context, runID, node string, initialState GenerationState) (*models.GenerationOutput, error) {
 	// Execute the graph
-	executionID := fmt.Sprintf("gen-%s", uuid.New().String())
-	finalState, err := gg.engine.Run(ctx, executionID, initialState)
+	finalState, err := gg.engine.Run(ctx, runID, initialState)
 	if err != nil {
 		return nil, fmt.Errorf("generation workflow failed: %w", err)
 	}
@@ -250,13 +423,15 @@ func (gg *GenerationGraph) Execute(ctx context.Context, fcs *models.FinalClarifi
 	output := &models.GenerationOutput{
 		SchemaVersion: "1.0",
 		ID:            uuid.New().String(),
+		RunID:         runID,
 		PlanID:        finalState.Plan.ID,
 		Patches:       finalState.AllPatches,
 		Status:        models.OutputStatusInProgress,
 	}
 
-	log.Info().
+	logctx.Logger(ctx).Info().
 		Str("output_id", output.ID).
+		Str("start_node", node).
 		Int("patches", len(finalState.AllPatches)).
 		Msg("Generation workflow completed successfully")
 
@@ -265,8 +440,8 @@ func (gg *GenerationGraph) Execute(ctx context.Context, fcs *models.FinalClarifi
 
 // Node implementations
 
-func (gg *GenerationGraph) startNode(_ context.Context, _ GenerationState) graph.NodeResult[GenerationState] {
-	log.Debug().Msg("Starting generation workflow")
+func (gg *GenerationGraph) startNode(ctx context.Context, _ GenerationState) graph.NodeResult[GenerationState] {
+	logctx.Logger(ctx).Debug().Msg("Starting generation workflow")
 
 	return graph.NodeResult[GenerationState]{
 		Delta: GenerationState{
@@ -277,8 +452,8 @@ func (gg *GenerationGraph) startNode(_ context.Context, _ GenerationState) graph
 	}
 }
 
-func (gg *GenerationGraph) analyzeFCSNode(_ context.Context, s GenerationState) graph.NodeResult[GenerationState] {
-	log.Debug().Msg("Analyzing FCS")
+func (gg *GenerationGraph) analyzeFCSNode(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
+	logctx.Logger(ctx).Debug().Msg("Analyzing FCS")
 
 	// Emit phase started event
 	gg.emitEvent(models.NewPhaseStartedEvent("analyze_fcs", "Validating specification"))
@@ -305,16 +480,40 @@ func (gg *GenerationGraph) analyzeFCSNode(_ context.Context, s GenerationState)
 		}
 	}
 
-	log.Debug().
+	logctx.Logger(ctx).Debug().
 		Str("fcs_id", s.FCS.ID).
 		Int("packages", len(s.FCS.Architecture.Packages)).
 		Msg("FCS validated successfully")
 
+	// Brownfield: sibling modules the FCS depends on are wired into go.mod and
+	// their APIs are shown to the coder
+	var workspace *Workspace
+	if s.OutputDir != "" {
+		var err error
+		workspace, err = DetectWorkspace(s.OutputDir)
+		if err != nil {
+			logctx.Logger(ctx).Warn().
+				Err(err).
+				Str("output_dir", s.OutputDir).
+				Msg("Failed to detect sibling modules")
+		}
+	}
+	if workspace != nil {
+		workspace.Modules = workspace.Dependencies(s.FCS)
+		for _, module := range workspace.Modules {
+			logctx.Logger(ctx).Info().
+				Str("module", module.Path).
+				Str("dir", module.Dir).
+				Msg("Using sibling module")
+		}
+	}
+
 	// Emit phase completed event
 	gg.emitEvent(models.NewPhaseCompletedEvent("analyze_fcs", time.Since(phaseStart), 0))
 
 	return graph.NodeResult[GenerationState]{
 		Delta: GenerationState{
+			Workspace:       workspace,
 			CurrentPhase:    "analyze_fcs",
 			CompletedPhases: []string{"analyze_fcs"},
 		},
@@ -323,7 +522,7 @@ func (gg *GenerationGraph) analyzeFCSNode(_ context.Context, s GenerationState)
 }
 
 func (gg *GenerationGraph) createPlanNode(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
-	log.Debug().Msg("Creating generation plan")
+	logctx.Logger(ctx).Debug().Msg("Creating generation plan")
 
 	// Emit phase started event
 	gg.emitEvent(models.NewPhaseStartedEvent("create_plan", "Analyzing architecture and creating generation plan"))
@@ -341,7 +540,7 @@ func (gg *GenerationGraph) createPlanNode(ctx context.Context, s GenerationState
 		}
 	}
 
-	log.Debug().
+	logctx.Logger(ctx).Debug().
 		Str("plan_id", plan.ID).
 		Int("phases", len(plan.Phases)).
 		Msg("Generation plan created")
@@ -349,12 +548,22 @@ func (gg *GenerationGraph) createPlanNode(ctx context.Context, s GenerationState
 	// Emit phase completed event
 	gg.emitEvent(models.NewPhaseCompletedEvent("create_plan", time.Since(phaseStart), 0))
 
+	// Project the remaining budget so users can abort before the expensive phases
+	if gg.eventChan != nil {
+		gg.emitEvent(models.NewEstimateEvent(EstimatePlan(plan, s.FCS, gg.estimate)))
+	}
+
 	// Extract package list
 	packageList := make([]string, len(s.FCS.Architecture.Packages))
 	for i, pkg := range s.FCS.Architecture.Packages {
 		packageList[i] = pkg.Name
 	}
 
+	next := "generate"
+	if gg.approvePlan != nil {
+		next = "approve_plan"
+	}
+
 	return graph.NodeResult[GenerationState]{
 		Delta: GenerationState{
 			Plan:            plan,
@@ -362,12 +571,102 @@ func (gg *GenerationGraph) createPlanNode(ctx context.Context, s GenerationState
 			CurrentPhase:    "create_plan",
 			CompletedPhases: []string{"create_plan"},
 		},
-		Route: graph.Goto("generate_packages"),
+		Route: graph.Goto(next),
 	}
 }
 
-func (gg *GenerationGraph) generatePackagesNode(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
-	log.Debug().
+// approvePlanNode hands the plan to the approver and continues with the plan
+// it returns. A resumed run has no approver, so a run stopped at approval
+// cannot go on with a plan nobody approved.
+func (gg *GenerationGraph) approvePlanNode(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
+	var plan *models.GenerationPlan
+	err := fmt.Errorf("the run stopped at plan approval, which a resumed run cannot give; generate again from the plan")
+	if gg.approvePlan != nil {
+		logctx.Logger(ctx).Debug().Str("plan_id", s.Plan.ID).Msg("Waiting for plan approval")
+		plan, err = gg.approvePlan(ctx, s.Plan)
+		if err == nil && plan == nil {
+			err = fmt.Errorf("approver returned no plan")
+		}
+	}
+	if err == nil {
+		if verr := plan.Validate(); verr != nil {
+			err = fmt.Errorf("approved plan is invalid: %w", verr)
+		}
+	}
+	if err != nil {
+		gg.emitEvent(models.NewErrorEvent("approve_plan", fmt.Sprintf("Plan not approved: %v", err), ""))
+		return graph.NodeResult[GenerationState]{
+			Delta: GenerationState{
+				Error: fmt.Errorf("plan not approved: %w", err),
+			},
+			Route: graph.Stop(),
+		}
+	}
+
+	logctx.Logger(ctx).Info().
+		Str("plan_id", plan.ID).
+		Int("phases", len(plan.Phases)).
+		Int("files", len(plan.FileTree.Files)).
+		Msg("Generation plan approved")
+
+	return graph.NodeResult[GenerationState]{
+		Delta: GenerationState{
+			Plan:            plan,
+			CurrentPhase:    "approve_plan",
+			CompletedPhases: []string{"approve_plan"},
+		},
+		Route: graph.Goto("generate"),
+	}
+}
+
+// generateNode runs generate_packages, generate_tests and generate_config
+// side by side on the approved plan. A coder that reports finished packages
+// feeds them to generate_tests, so each package's tests are written against
+// its real API while the remaining packages are still being generated; those
+// tests wait on the code, so they share its budget. Other coders' tests are
+// written from the plan alone, alongside the code.
+func (gg *GenerationGraph) generateNode(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
+	packagesTimeout := nodeTimeout(gg.timeouts.Packages)
+	testsTimeout := nodeTimeout(gg.timeouts.Tests)
+
+	var feed chan []models.Patch
+	if _, ok := gg.coder.(ObservingCoder); ok {
+		feed = make(chan []models.Patch)
+		testsTimeout = pipelinedTimeout(packagesTimeout, testsTimeout)
+	}
+
+	return fanOut("generate_docs",
+		fanOutBranch{
+			name:    "generate_packages",
+			timeout: packagesTimeout,
+			fn: func(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
+				return gg.generatePackagesNode(ctx, s, feed)
+			},
+		},
+		fanOutBranch{
+			name:    "generate_tests",
+			timeout: testsTimeout,
+			fn: func(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
+				return gg.generateTestsNode(ctx, s, feed)
+			},
+		},
+		fanOutBranch{
+			name:    "generate_config",
+			timeout: nodeTimeout(gg.timeouts.Config),
+			fn:      gg.generateConfigNode,
+		},
+	)(ctx, s)
+}
+
+// generatePackagesNode generates the source code. With a feed, each finished
+// package is sent to generate_tests and the feed is closed once code
+// generation ends.
+func (gg *GenerationGraph) generatePackagesNode(ctx context.Context, s GenerationState, feed chan<- []models.Patch) graph.NodeResult[GenerationState] {
+	if feed != nil {
+		defer close(feed)
+	}
+
+	logctx.Logger(ctx).Debug().
 		Bool("plan_is_nil", s.Plan == nil).
 		Str("current_phase", s.CurrentPhase).
 		Int("completed_phases", len(s.CompletedPhases)).
@@ -379,7 +678,7 @@ func (gg *GenerationGraph) generatePackagesNode(ctx context.Context, s Generatio
 
 	// Validate plan exists
 	if s.Plan == nil {
-		log.Error().
+		logctx.Logger(ctx).Error().
 			Str("current_phase", s.CurrentPhase).
 			Strs("completed_phases", s.CompletedPhases).
 			Msg("Plan is nil in generatePackagesNode - state was not properly accumulated")
@@ -392,8 +691,23 @@ func (gg *GenerationGraph) generatePackagesNode(ctx context.Context, s Generatio
 		}
 	}
 
+	if wc, ok := gg.coder.(WorkspaceCoder); ok && s.Workspace != nil {
+		wc.SetSiblingModules(s.Workspace.Modules)
+	}
+
 	// Generate code using coder
-	patches, err := gg.coder.Generate(ctx, s.Plan, s.FCS)
+	var patches []models.Patch
+	var err error
+	if feed != nil {
+		patches, err = gg.coder.(ObservingCoder).GenerateObserved(ctx, s.Plan, s.FCS, func(code []models.Patch) {
+			select {
+			case feed <- code:
+			case <-ctx.Done():
+			}
+		})
+	} else {
+		patches, err = gg.coder.Generate(ctx, s.Plan, s.FCS)
+	}
 	if err != nil {
 		gg.emitEvent(models.NewErrorEvent("generate_packages", fmt.Sprintf("Failed to generate code: %v", err), ""))
 		return graph.NodeResult[GenerationState]{
@@ -404,7 +718,10 @@ func (gg *GenerationGraph) generatePackagesNode(ctx context.Context, s Generatio
 		}
 	}
 
-	log.Debug().
+	// Declarations and imports are only consistent across files once all exist
+	patches = gg.resolveConflicts(ctx, s.Plan, s.FCS, patches)
+
+	logctx.Logger(ctx).Debug().
 		Int("patches", len(patches)).
 		Msg("Code generation completed")
 
@@ -417,32 +734,53 @@ func (gg *GenerationGraph) generatePackagesNode(ctx context.Context, s Generatio
 			CurrentPhase:    "generate_packages",
 			CompletedPhases: []string{"generate_packages"},
 		},
-		Route: graph.Goto("generate_tests"),
+		Route: graph.Goto("generate_docs"),
 	}
 }
 
-func (gg *GenerationGraph) generateTestsNode(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
-	log.Debug().Msg("Generating test files")
+// generateTestsNode generates the test files. With a feed, each package is
+// tested as generate_packages finishes it, and the files no package arrived
+// for are tested from the plan once the feed is closed.
+func (gg *GenerationGraph) generateTestsNode(ctx context.Context, s GenerationState, feed <-chan []models.Patch) graph.NodeResult[GenerationState] {
+	logctx.Logger(ctx).Debug().Msg("Generating test files")
 
 	var patches []models.Patch
-	// Validate plan exists before generating tests
-	if s.Plan == nil {
-		log.Warn().Msg("Generation plan not found, skipping test generation")
-		patches = []models.Patch{}
-	} else {
-		// Generate tests using tester
-		var err error
-		patches, err = gg.tester.Generate(ctx, s.PackageList, s.Plan)
-		if err != nil {
-			// Log error but don't fail - tests are important but not critical
-			log.Warn().
-				Err(err).
-				Msg("Failed to generate some test files")
-			patches = []models.Patch{}
+	var err error
+	switch {
+	case s.Plan == nil:
+		// Validate plan exists before generating tests
+		logctx.Logger(ctx).Warn().Msg("Generation plan not found, skipping test generation")
+	case feed != nil:
+		tests := gg.tester.StartPipeline(ctx, s.Plan, s.FCS)
+		for code := range feed {
+			tests.AddPackage(code)
+		}
+		patches, err = tests.Wait()
+	default:
+		patches, err = gg.tester.Generate(ctx, s.PackageList, s.Plan, s.FCS)
+	}
+	if err != nil && ctx.Err() != nil {
+		// Cancellation and deadlines stop the run instead of skipping tests
+		gg.emitEvent(models.NewErrorEvent("generate_tests", fmt.Sprintf("Test generation interrupted: %v", err), ""))
+		return graph.NodeResult[GenerationState]{
+			Delta: GenerationState{
+				Error: fmt.Errorf("failed to generate tests: %w", err),
+			},
+			Route: graph.Stop(),
 		}
 	}
+	if err != nil {
+		// Log error but don't fail - tests are important but not critical
+		logctx.Logger(ctx).Warn().
+			Err(err).
+			Msg("Failed to generate some test files")
+		patches = nil
+	}
+	if patches == nil {
+		patches = []models.Patch{}
+	}
 
-	log.Debug().
+	logctx.Logger(ctx).Debug().
 		Int("patches", len(patches)).
 		Msg("Test generation completed")
 
@@ -452,67 +790,131 @@ func (gg *GenerationGraph) generateTestsNode(ctx context.Context, s GenerationSt
 			CurrentPhase:    "generate_tests",
 			CompletedPhases: []string{"generate_tests"},
 		},
-		Route: graph.Goto("generate_config"),
+		Route: graph.Goto("generate_docs"),
+	}
+}
+
+func (gg *GenerationGraph) generateDocsNode(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
+	patches := []models.Patch{}
+	if gg.docWriter != nil && len(s.CodePatches) > 0 {
+		logctx.Logger(ctx).Debug().Msg("Generating package documentation")
+
+		var err error
+		patches, err = gg.docWriter.Generate(ctx, s.CodePatches, s.FCS, s.OutputDir)
+		if err != nil {
+			return graph.NodeResult[GenerationState]{
+				Delta: GenerationState{
+					Error: fmt.Errorf("failed to generate package documentation: %w", err),
+				},
+				Route: graph.Stop(),
+			}
+		}
+	}
+
+	return graph.NodeResult[GenerationState]{
+		Delta: GenerationState{
+			DocPatches:      patches,
+			CurrentPhase:    "generate_docs",
+			CompletedPhases: []string{"generate_docs"},
+		},
+		Route: graph.Goto("apply_patches"),
 	}
 }
 
 func (gg *GenerationGraph) generateConfigNode(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
-	log.Debug().Msg("Generating configuration files")
+	logctx.Logger(ctx).Debug().Msg("Generating configuration files")
 
 	var configPatches []models.Patch
 
 	// Validate plan and FCS exist
 	if s.Plan == nil || s.FCS == nil {
-		log.Warn().Msg("Plan or FCS not found, skipping config generation")
+		logctx.Logger(ctx).Warn().Msg("Plan or FCS not found, skipping config generation")
 		configPatches = []models.Patch{}
 	} else {
 		// Extract template data from FCS
 		templateData := templates.ExtractTemplateData(s.FCS)
+		templateData.ApplySettings(gg.project)
+		if s.Workspace != nil {
+			// A go.work file resolves sibling modules; otherwise go.mod replaces them
+			for _, module := range s.Workspace.Modules {
+				templateData.AddLocalModule(module.Path, module.Dir, s.Workspace.GoWork == "")
+			}
+		}
 
-		// Generate boilerplate files using templates
-		boilerplateFiles := []string{"go.mod", ".gitignore", "Dockerfile", "Makefile", "README.md"}
-
-		for _, fileName := range boilerplateFiles {
-			// Check if this file is in the plan
-			shouldGenerate := false
-			for _, file := range s.Plan.FileTree.Files {
-				if file.Path == fileName ||
-					(len(file.Path) > len(fileName) && file.Path[len(file.Path)-len(fileName):] == fileName) {
-					shouldGenerate = true
-					break
-				}
+		// Render with one timestamp so it can be recorded and reproduced
+		now := time.Now().Truncate(time.Second)
+		templateData.Year = now.Year()
+		templateData.GeneratedAt = now.Format(time.RFC3339)
+
+		// Incremental runs skip template files whose template and inputs are unchanged
+		var state *IncrementalState
+		if gg.stateManager != nil {
+			var err error
+			if state, err = gg.stateManager.Load(); err != nil {
+				logctx.Logger(ctx).Warn().Err(err).Msg("Failed to load incremental state, rendering all template files")
+				state = nil
 			}
+		}
+		var rendered []FileState
 
-			if !shouldGenerate {
-				continue
+		// Generate boilerplate files using templates
+		for _, target := range templateTargets(s.Plan, s.FCS, templateData) {
+			fileName := target.path
+
+			if state != nil {
+				reason := gg.templateFileChange(ctx, state, s.OutputDir, fileName, target.data)
+				if reason == "" {
+					logctx.Logger(ctx).Debug().
+						Str("file", fileName).
+						Msg("Template file unchanged, skipping")
+					continue
+				}
+				logctx.Logger(ctx).Info().
+					Str("file", fileName).
+					Str("reason", reason).
+					Msg("Regenerating template file")
 			}
 
-			content, err := gg.templateGenerator.GenerateBoilerplate(ctx, fileName, templateData)
+			content, err := gg.templateGenerator.GenerateBoilerplate(ctx, fileName, target.data)
 			if err != nil {
-				log.Warn().
+				logctx.Logger(ctx).Warn().
 					Err(err).
 					Str("file", fileName).
 					Msg("Failed to generate boilerplate file")
 				continue
 			}
+			content = normalizeOutput(fileName, content)
 
 			// Create patch for this file
 			patch := models.Patch{
 				TargetFile: fileName,
-				Diff:       content,
-				AppliedAt:  time.Now(),
+				Diff:       newFileDiff(fileName, content),
+				AppliedAt:  now,
 				Reversible: true,
+				Template:   true,
 			}
 			configPatches = append(configPatches, patch)
-
-			log.Debug().
+			rendered = append(rendered, FileState{
+				Path:             fileName,
+				Checksum:         ComputeFileChecksum(content),
+				GeneratedAt:      now,
+				TemplateChecksum: gg.templateGenerator.TemplateChecksum(fileName),
+			})
+
+			logctx.Logger(ctx).Debug().
 				Str("file", fileName).
 				Int("size", len(content)).
 				Msg("Generated boilerplate file from template")
 		}
+
+		if state != nil && len(rendered) > 0 {
+			if err := gg.stateManager.RecordTemplateFiles(rendered); err != nil {
+				logctx.Logger(ctx).Warn().Err(err).Msg("Failed to record template files in incremental state")
+			}
+		}
 	}
 
-	log.Debug().
+	logctx.Logger(ctx).Debug().
 		Int("patches", len(configPatches)).
 		Msg("Configuration generation completed")
 
@@ -522,21 +924,85 @@ func (gg *GenerationGraph) generateConfigNode(ctx context.Context, s GenerationS
 			CurrentPhase:    "generate_config",
 			CompletedPhases: []string{"generate_config"},
 		},
-		Route: graph.Goto("apply_patches"),
+		Route: graph.Goto("generate_docs"),
+	}
+}
+
+// templateTarget is a planned file rendered from a template, with the data
+// rendered into it
+type templateTarget struct {
+	path string
+	data templates.TemplateData
+}
+
+// templateTargets returns the boilerplate files the plan lists. A
+// multi-module project gets a go.mod in each planned module directory
+// instead of the project root.
+func templateTargets(plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification, data templates.TemplateData) []templateTarget {
+	multiModule := fcs.BuildConfig.IsMultiModule()
+
+	var targets []templateTarget
+	for _, fileName := range templates.BoilerplateFiles {
+		if fileName == "go.mod" && multiModule {
+			continue
+		}
+
+		// Check if this file is in the plan
+		for _, file := range plan.FileTree.Files {
+			if file.Path == fileName ||
+				(len(file.Path) > len(fileName) && file.Path[len(file.Path)-len(fileName):] == fileName) {
+				targets = append(targets, templateTarget{path: fileName, data: data})
+				break
+			}
+		}
+	}
+
+	for _, module := range fcs.BuildConfig.Modules {
+		if plan.FileTree.Has(module.GoModPath()) {
+			targets = append(targets, templateTarget{path: module.GoModPath(), data: data.ForModule(module, fcs)})
+		}
+	}
+	return targets
+}
+
+// templateFileChange reports why a boilerplate file must be rendered again, or
+// "" when it is still on disk and neither its template nor the data rendered
+// into it changed since it was recorded in state
+func (gg *GenerationGraph) templateFileChange(ctx context.Context, state *IncrementalState, outputDir, fileName string, data templates.TemplateData) string {
+	recorded, ok := state.GeneratedFiles[normalizePath(fileName)]
+	switch {
+	case !ok || recorded.TemplateChecksum == "":
+		return "no recorded template checksum"
+	case recorded.TemplateChecksum != gg.templateGenerator.TemplateChecksum(fileName):
+		return "template changed"
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, fileName)); err != nil {
+		return "file missing"
+	}
+
+	// Render with the recorded timestamp so only changed inputs alter the checksum
+	data.Year = recorded.GeneratedAt.Year()
+	data.GeneratedAt = recorded.GeneratedAt.Format(time.RFC3339)
+	content, err := gg.templateGenerator.GenerateBoilerplate(ctx, fileName, data)
+	if err != nil || ComputeFileChecksum(content) != recorded.Checksum {
+		return "template inputs changed"
 	}
+	return ""
 }
 
-func (gg *GenerationGraph) applyPatchesNode(_ context.Context, s GenerationState) graph.NodeResult[GenerationState] {
-	log.Debug().Msg("Collecting patches for application")
+func (gg *GenerationGraph) applyPatchesNode(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
+	logctx.Logger(ctx).Debug().Msg("Collecting patches for application")
 
 	// Collect all patches
 	allPatches := append([]models.Patch{}, s.CodePatches...)
 	allPatches = append(allPatches, s.TestPatches...)
+	allPatches = append(allPatches, s.DocPatches...)
 	allPatches = append(allPatches, s.ConfigPatches...)
 
-	log.Debug().
+	logctx.Logger(ctx).Debug().
 		Int("code_patches", len(s.CodePatches)).
 		Int("test_patches", len(s.TestPatches)).
+		Int("doc_patches", len(s.DocPatches)).
 		Int("config_patches", len(s.ConfigPatches)).
 		Int("total_patches", len(allPatches)).
 		Msg("Patches collected for application")
@@ -551,8 +1017,8 @@ func (gg *GenerationGraph) applyPatchesNode(_ context.Context, s GenerationState
 	}
 }
 
-func (gg *GenerationGraph) endNode(_ context.Context, _ GenerationState) graph.NodeResult[GenerationState] {
-	log.Debug().Msg("Finalizing generation output")
+func (gg *GenerationGraph) endNode(ctx context.Context, _ GenerationState) graph.NodeResult[GenerationState] {
+	logctx.Logger(ctx).Debug().Msg("Finalizing generation output")
 
 	return graph.NodeResult[GenerationState]{
 		Delta: GenerationState{
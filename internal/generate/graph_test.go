@@ -0,0 +1,179 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runConfigNode renders the boilerplate for a plan containing go.mod and
+// Makefile, writes it to outputDir and returns the rendered paths
+func runConfigNode(t *testing.T, templatesDir, outputDir string) []string {
+	t.Helper()
+	gen, err := templates.NewTemplateGeneratorWithOverrides(templatesDir)
+	require.NoError(t, err)
+	gg := &GenerationGraph{templateGenerator: gen, stateManager: NewIncrementalStateManager(outputDir)}
+
+	result := gg.generateConfigNode(context.Background(), GenerationState{
+		FCS: &models.FinalClarifiedSpecification{BuildConfig: models.BuildConfig{GoVersion: "1.22"}},
+		Plan: &models.GenerationPlan{FileTree: models.FileTree{Files: []models.File{
+			{Path: "go.mod"},
+			{Path: "Makefile"},
+		}}},
+		OutputDir: outputDir,
+	})
+
+	var paths []string
+	for _, patch := range result.Delta.ConfigPatches {
+		paths = append(paths, patch.TargetFile)
+		content := extractContentFromDiff(patch.Diff)
+		require.NoError(t, os.WriteFile(filepath.Join(outputDir, patch.TargetFile), []byte(content), 0600))
+	}
+	return paths
+}
+
+func TestGenerateConfigNode_TemplateChanges(t *testing.T) {
+	templatesDir := t.TempDir()
+	outputDir := t.TempDir()
+	makefile := filepath.Join(templatesDir, "Makefile.tmpl")
+	require.NoError(t, os.WriteFile(makefile, []byte("build:\n\tgo build -o {{.BinaryName}} ./...\n"), 0600))
+
+	assert.Equal(t, []string{"go.mod", "Makefile"}, runConfigNode(t, templatesDir, outputDir), "nothing recorded yet")
+	assert.Empty(t, runConfigNode(t, templatesDir, outputDir), "templates and inputs unchanged")
+
+	state, err := NewIncrementalStateManager(outputDir).Load()
+	require.NoError(t, err)
+	assert.True(t, state.GeneratedFiles["Makefile"].Template)
+	assert.NotEmpty(t, state.GeneratedFiles["Makefile"].TemplateChecksum)
+
+	require.NoError(t, os.WriteFile(makefile, []byte("build:\n\tgo build -trimpath -o {{.BinaryName}} ./...\n"), 0600))
+	assert.Equal(t, []string{"Makefile"}, runConfigNode(t, templatesDir, outputDir), "only the changed template's file")
+
+	require.NoError(t, os.Remove(filepath.Join(outputDir, "go.mod")))
+	assert.Equal(t, []string{"go.mod"}, runConfigNode(t, templatesDir, outputDir), "deleted files are restored")
+}
+
+func TestGenerateConfigNode_OpenAPI(t *testing.T) {
+	gen, err := templates.NewTemplateGenerator()
+	require.NoError(t, err)
+	outputDir := t.TempDir()
+	gg := &GenerationGraph{templateGenerator: gen, stateManager: NewIncrementalStateManager(outputDir)}
+
+	fcs := &models.FinalClarifiedSpecification{APIContracts: []models.APIContract{{Endpoint: "/users", Method: "GET"}}}
+	plan := &models.GenerationPlan{}
+	planOpenAPI(plan, fcs)
+	run := func() map[string]string {
+		result := gg.generateConfigNode(context.Background(), GenerationState{FCS: fcs, Plan: plan, OutputDir: outputDir})
+		rendered := make(map[string]string)
+		for _, patch := range result.Delta.ConfigPatches {
+			rendered[patch.TargetFile] = extractContentFromDiff(patch.Diff)
+			require.NoError(t, os.WriteFile(filepath.Join(outputDir, patch.TargetFile), []byte(rendered[patch.TargetFile]), 0600))
+		}
+		return rendered
+	}
+
+	rendered := run()
+	require.Contains(t, rendered, templates.OpenAPIPath)
+	assert.Contains(t, rendered[templates.OpenAPIPath], "operationId: getUsers")
+	assert.Empty(t, run(), "contracts unchanged")
+
+	// Incremental runs keep the document in sync with the contracts
+	fcs.APIContracts = append(fcs.APIContracts, models.APIContract{Endpoint: "/users", Method: "POST"})
+	rendered = run()
+	require.Contains(t, rendered, templates.OpenAPIPath)
+	assert.Contains(t, rendered[templates.OpenAPIPath], "operationId: postUsers")
+}
+
+func TestGenerateConfigNode_Modules(t *testing.T) {
+	gen, err := templates.NewTemplateGenerator()
+	require.NoError(t, err)
+	gg := &GenerationGraph{templateGenerator: gen}
+
+	fcs := &models.FinalClarifiedSpecification{
+		Architecture: models.Architecture{Dependencies: []models.Dependency{
+			{Name: "github.com/go-chi/chi/v5", Version: "v5.1.0"},
+		}},
+		BuildConfig: models.BuildConfig{GoVersion: "1.22", Modules: []models.ModuleConfig{
+			{Dir: "services/api", Path: "example.com/shop/api", Requires: []string{"example.com/shop/shared", "github.com/go-chi/chi/v5"}},
+			{Dir: "shared", Path: "example.com/shop/shared"},
+		}},
+	}
+	result := gg.generateConfigNode(context.Background(), GenerationState{
+		FCS: fcs,
+		Plan: &models.GenerationPlan{FileTree: models.FileTree{Files: []models.File{
+			{Path: "go.mod"},
+			{Path: "go.work"},
+			{Path: "services/api/go.mod"},
+			{Path: "shared/go.mod"},
+		}}},
+		OutputDir: t.TempDir(),
+	})
+
+	rendered := make(map[string]string)
+	var paths []string
+	for _, patch := range result.Delta.ConfigPatches {
+		paths = append(paths, patch.TargetFile)
+		rendered[patch.TargetFile] = extractContentFromDiff(patch.Diff)
+	}
+
+	// The modules replace the root go.mod
+	assert.Equal(t, []string{"go.work", "services/api/go.mod", "shared/go.mod"}, paths)
+	assert.Contains(t, rendered["go.work"], "\t./services/api\n\t./shared\n")
+
+	api := rendered["services/api/go.mod"]
+	assert.Contains(t, api, "module example.com/shop/api\n")
+	assert.Contains(t, api, "github.com/go-chi/chi/v5 v5.1.0")
+	assert.Contains(t, api, "example.com/shop/shared v0.0.0")
+	assert.Contains(t, api, "example.com/shop/shared => ../../shared")
+
+	shared := rendered["shared/go.mod"]
+	assert.Contains(t, shared, "module example.com/shop/shared\n")
+	assert.NotContains(t, shared, "chi", "dependencies go to the modules requiring them")
+	assert.NotContains(t, shared, "replace")
+}
+
+func TestApprovePlanNode(t *testing.T) {
+	plan := &models.GenerationPlan{ID: "plan-1", FileTree: models.FileTree{Root: "."}, Phases: []models.GenerationPhase{{
+		Name: "packages", Order: 1, Tasks: []models.GenerationTask{{ID: "store", Type: "generate_file", TargetPath: "store.go"}},
+	}}}
+	state := GenerationState{Plan: plan}
+
+	edited := *plan
+	edited.Phases = []models.GenerationPhase{{
+		Name: "packages", Order: 1, Tasks: []models.GenerationTask{{ID: "users", Type: "generate_file", TargetPath: "users.go"}},
+	}}
+	gg := &GenerationGraph{approvePlan: func(_ context.Context, got *models.GenerationPlan) (*models.GenerationPlan, error) {
+		assert.Same(t, plan, got)
+		return &edited, nil
+	}}
+	result := gg.approvePlanNode(context.Background(), state)
+	require.NoError(t, result.Delta.Error)
+	assert.Same(t, &edited, result.Delta.Plan, "generation uses the edited plan")
+
+	escaping := edited
+	escaping.Phases = []models.GenerationPhase{{
+		Name: "packages", Order: 1, Tasks: []models.GenerationTask{{ID: "users", Type: "generate_file", TargetPath: "../users.go"}},
+	}}
+	gg.approvePlan = func(context.Context, *models.GenerationPlan) (*models.GenerationPlan, error) { return &escaping, nil }
+	result = gg.approvePlanNode(context.Background(), state)
+	assert.ErrorContains(t, result.Delta.Error, "approved plan is invalid: target path outside root")
+	assert.Nil(t, result.Delta.Plan)
+
+	gg.approvePlan = func(context.Context, *models.GenerationPlan) (*models.GenerationPlan, error) {
+		return nil, fmt.Errorf("aborted")
+	}
+	result = gg.approvePlanNode(context.Background(), state)
+	assert.ErrorContains(t, result.Delta.Error, "plan not approved: aborted")
+
+	// A resumed run has nobody to approve the plan
+	result = (&GenerationGraph{}).approvePlanNode(context.Background(), state)
+	assert.ErrorContains(t, result.Delta.Error, "plan not approved")
+	assert.Nil(t, result.Delta.Plan)
+}
@@ -0,0 +1,128 @@
+package generate
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// ImpactReport projects what an incremental regeneration against a new FCS
+// would redo, from the stored state of the last generation, without calling
+// the LLM
+type ImpactReport struct {
+	Changes *FCSChanges
+
+	// FullRebuild is set when every file would be regenerated, with the reason
+	FullRebuild       bool
+	FullRebuildReason string
+
+	TotalFiles int            // Files recorded by the last generation
+	Files      []ImpactedFile // Files that would be regenerated, by path
+	Packages   []string       // Package directories of those files, sorted
+
+	// Estimate covers the LLM calls for the affected files and their tests.
+	// Files the planner adds for new entities or requirements are not known
+	// before planning and are not included.
+	Estimate models.CostEstimate
+}
+
+// ImpactedFile is a generated file an FCS change would regenerate
+type ImpactedFile struct {
+	Path     string
+	Template bool     // Re-rendered without an LLM call
+	Entities []string // Changed FCS entities the file depends on
+}
+
+// AnalyzeImpact compares newFCS with the FCS of the last generation in state,
+// using the same change detection and file dependency graph as incremental
+// regeneration, and estimates the cost of regenerating the affected files
+func AnalyzeImpact(state *IncrementalState, newFCS *models.FinalClarifiedSpecification, cfg EstimateConfig) (*ImpactReport, error) {
+	if state == nil || len(state.GeneratedFiles) == 0 {
+		return nil, fmt.Errorf("no generated files recorded; run an incremental generation first")
+	}
+
+	report := &ImpactReport{TotalFiles: len(state.GeneratedFiles)}
+	allFiles := make([]string, 0, len(state.GeneratedFiles))
+	for file := range state.GeneratedFiles {
+		allFiles = append(allFiles, file)
+	}
+	sort.Strings(allFiles)
+
+	newChecksum, err := ComputeFCSChecksum(newFCS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute FCS checksum: %w", err)
+	}
+	if newChecksum == state.FCSChecksum {
+		report.Changes = &FCSChanges{}
+		return report, nil
+	}
+
+	var affected []string
+	changedEntities := make(map[string]bool)
+	if state.PreviousFCS == nil {
+		// Matches the conservative fallback of incremental regeneration
+		report.Changes = &FCSChanges{HasChanges: true}
+		report.FullRebuild = true
+		report.FullRebuildReason = "the stored state has no previous FCS to compare with"
+		affected = allFiles
+	} else {
+		report.Changes, err = NewChangeDetector().DetectChanges(state.PreviousFCS, newFCS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect changes: %w", err)
+		}
+		if !report.Changes.HasChanges {
+			return report, nil
+		}
+		affected = NewAffectedFilesCalculator(state.DependencyGraph).CalculateAffectedFiles(report.Changes, allFiles)
+
+		switch {
+		case report.Changes.ArchitectureChanged:
+			report.FullRebuild, report.FullRebuildReason = true, "the architecture changed"
+		case report.Changes.BuildConfigChanged:
+			report.FullRebuild, report.FullRebuildReason = true, "the build configuration changed"
+		case report.Changes.CrossCuttingChanged:
+			report.FullRebuild, report.FullRebuildReason = true, "the cross-cutting concerns changed"
+		}
+		for _, names := range [][]string{report.Changes.AddedEntities, report.Changes.ModifiedEntities, report.Changes.DeletedEntities} {
+			for _, name := range names {
+				changedEntities[name] = true
+			}
+		}
+	}
+	sort.Strings(affected)
+
+	inputTokens := estimateInputTokens(newFCS)
+	packages := make(map[string]bool)
+	for _, file := range affected {
+		fileState := state.GeneratedFiles[file]
+		impacted := ImpactedFile{Path: file, Template: fileState.Template}
+		for _, dep := range state.DependencyGraph[file] {
+			if changedEntities[dep] {
+				impacted.Entities = append(impacted.Entities, dep)
+			}
+		}
+		report.Files = append(report.Files, impacted)
+
+		if strings.HasSuffix(file, ".go") {
+			packages[path.Dir(filepath.ToSlash(file))] = true
+		}
+		// Tests are regenerated with their source file, as in EstimatePlan
+		if fileState.Template || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		report.Estimate.Add(estimateCall(cfg, inputTokens, estimateSourceOutputTokens))
+		if strings.HasSuffix(file, ".go") {
+			report.Estimate.Add(estimateCall(cfg, inputTokens, estimateTestOutputTokens))
+		}
+	}
+
+	for dir := range packages {
+		report.Packages = append(report.Packages, dir)
+	}
+	sort.Strings(report.Packages)
+	return report, nil
+}
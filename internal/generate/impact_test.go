@@ -0,0 +1,102 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func impactTestState(t *testing.T, fcs *models.FinalClarifiedSpecification) *IncrementalState {
+	t.Helper()
+	checksum, err := ComputeFCSChecksum(fcs)
+	require.NoError(t, err)
+	return &IncrementalState{
+		FCSChecksum: checksum,
+		PreviousFCS: fcs,
+		GeneratedFiles: map[string]FileState{
+			"go.mod":                        {Path: "go.mod", Template: true},
+			"internal/models/user.go":       {Path: "internal/models/user.go"},
+			"internal/models/user_test.go":  {Path: "internal/models/user_test.go"},
+			"internal/models/product.go":    {Path: "internal/models/product.go"},
+			"internal/handlers/checkout.go": {Path: "internal/handlers/checkout.go"},
+		},
+		DependencyGraph: map[string][]string{
+			"internal/models/user.go":       {"User"},
+			"internal/models/user_test.go":  {"User"},
+			"internal/models/product.go":    {"Product"},
+			"internal/handlers/checkout.go": {"User", "Product"},
+		},
+	}
+}
+
+func impactTestFCS(userAttrs map[string]string) *models.FinalClarifiedSpecification {
+	return &models.FinalClarifiedSpecification{
+		ID: "fcs-shop",
+		DataModel: models.DataModel{
+			Entities: []models.Entity{
+				{Name: "User", Attributes: userAttrs},
+				{Name: "Product", Attributes: map[string]string{"sku": "string"}},
+			},
+		},
+	}
+}
+
+func TestAnalyzeImpact(t *testing.T) {
+	state := impactTestState(t, impactTestFCS(map[string]string{"id": "string"}))
+	newFCS := impactTestFCS(map[string]string{"id": "string", "email": "string"})
+	cfg := EstimateConfig{Model: "test-model", Pricing: llm.Pricing{InputPerMTok: 1, OutputPerMTok: 10}}
+
+	report, err := AnalyzeImpact(state, newFCS, cfg)
+	require.NoError(t, err)
+	assert.True(t, report.Changes.HasChanges)
+	assert.Equal(t, []string{"User"}, report.Changes.ModifiedEntities)
+	assert.False(t, report.FullRebuild)
+	assert.Equal(t, 5, report.TotalFiles)
+
+	assert.Equal(t, []ImpactedFile{
+		{Path: "internal/handlers/checkout.go", Entities: []string{"User"}},
+		{Path: "internal/models/user.go", Entities: []string{"User"}},
+		{Path: "internal/models/user_test.go", Entities: []string{"User"}},
+	}, report.Files)
+	assert.Equal(t, []string{"internal/handlers", "internal/models"}, report.Packages)
+
+	// Two source files, each with a code and a test call; the test file rides along
+	assert.Equal(t, 4, report.Estimate.Calls)
+	assert.Equal(t, int64(2*estimateSourceOutputTokens+2*estimateTestOutputTokens), report.Estimate.OutputTokens)
+	assert.Positive(t, report.Estimate.CostUSD)
+}
+
+func TestAnalyzeImpact_Unchanged(t *testing.T) {
+	fcs := impactTestFCS(map[string]string{"id": "string"})
+	report, err := AnalyzeImpact(impactTestState(t, fcs), fcs, EstimateConfig{})
+	require.NoError(t, err)
+	assert.False(t, report.Changes.HasChanges)
+	assert.Empty(t, report.Files)
+	assert.Zero(t, report.Estimate.Calls)
+}
+
+func TestAnalyzeImpact_FullRebuild(t *testing.T) {
+	state := impactTestState(t, impactTestFCS(map[string]string{"id": "string"}))
+	newFCS := impactTestFCS(map[string]string{"id": "string"})
+	newFCS.BuildConfig.GoVersion = "1.23"
+
+	report, err := AnalyzeImpact(state, newFCS, EstimateConfig{})
+	require.NoError(t, err)
+	assert.True(t, report.FullRebuild)
+	assert.Equal(t, "the build configuration changed", report.FullRebuildReason)
+	assert.Len(t, report.Files, 5)
+	assert.True(t, report.Files[0].Template, "go.mod is re-rendered from its template")
+	assert.Equal(t, 6, report.Estimate.Calls)
+
+	state.PreviousFCS = nil
+	report, err = AnalyzeImpact(state, newFCS, EstimateConfig{})
+	require.NoError(t, err)
+	assert.True(t, report.FullRebuild)
+	assert.Contains(t, report.FullRebuildReason, "no previous FCS")
+
+	_, err = AnalyzeImpact(&IncrementalState{}, newFCS, EstimateConfig{})
+	assert.Error(t, err)
+}
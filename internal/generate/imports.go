@@ -0,0 +1,263 @@
+package generate
+
+import (
+	"bufio"
+	"fmt"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+)
+
+// ImportFix is an internal import rewritten to match its module's go.mod
+type ImportFix struct {
+	File string `json:"file"` // Slash-separated, relative to the output directory
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ModuleLayout describes the modules of a generated project: each go.mod in
+// the tree with the package directories it owns, plus the module paths the
+// project may import from outside it.
+type ModuleLayout struct {
+	Modules  []LayoutModule // Deepest directory first, so the first match owns a file
+	External []string       // Sibling, required and replaced module paths
+	goroot   string
+}
+
+// LayoutModule is one go.mod within the project
+type LayoutModule struct {
+	Dir      string          // Slash-separated, relative to the output directory; "." for the root
+	Path     string          // Module path from its go.mod
+	Packages map[string]bool // Package directories relative to Dir, excluding nested modules
+}
+
+// DetectModuleLayout scans outputDir for go.mod files and the directories
+// holding Go files. Sibling modules of the workspace count as external.
+func DetectModuleLayout(outputDir string) (*ModuleLayout, error) {
+	layout := &ModuleLayout{goroot: build.Default.GOROOT}
+	var goDirs []string
+
+	err := filepath.WalkDir(outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != outputDir && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(outputDir, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		dir := filepath.ToSlash(rel)
+
+		switch {
+		case d.Name() == "go.mod":
+			//nolint:gosec // G304: Reading a go.mod inside the output directory
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", p, err)
+			}
+			modulePath := parseModulePath(string(data))
+			if modulePath == "" {
+				return nil
+			}
+			layout.Modules = append(layout.Modules, LayoutModule{Dir: dir, Path: modulePath, Packages: make(map[string]bool)})
+			layout.External = append(layout.External, parseModDependencies(string(data))...)
+		case strings.HasSuffix(d.Name(), ".go"):
+			goDirs = append(goDirs, dir)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", outputDir, err)
+	}
+
+	sort.SliceStable(layout.Modules, func(i, j int) bool {
+		return dirDepth(layout.Modules[i].Dir) > dirDepth(layout.Modules[j].Dir)
+	})
+	for _, dir := range goDirs {
+		if module := layout.owner(dir); module != nil {
+			module.Packages[relToModule(module.Dir, dir)] = true
+		}
+	}
+
+	if workspace, err := DetectWorkspace(outputDir); err == nil && workspace != nil {
+		for _, sibling := range workspace.Modules {
+			layout.External = append(layout.External, sibling.Path)
+		}
+	}
+	return layout, nil
+}
+
+// owner returns the module whose directory most closely encloses dir
+func (l *ModuleLayout) owner(dir string) *LayoutModule {
+	for i := range l.Modules {
+		module := &l.Modules[i]
+		if module.Dir == "." || dir == module.Dir || strings.HasPrefix(dir, module.Dir+"/") {
+			return module
+		}
+	}
+	return nil
+}
+
+// FixImports rewrites the internal imports of one Go file (slash-separated,
+// relative to the output directory) to the module path of the go.mod that owns
+// it. An import is internal when a proper suffix of it names a package
+// directory of that module, as in "github.com/example/project/internal/store"
+// or "internal/store" for a module with internal/store. Standard library
+// imports and imports under a module of the layout, a sibling module or a
+// go.mod requirement are left alone. A single-element suffix is only taken
+// from paths that cannot be third-party: dotless ones and the template
+// placeholder module. Files that do not parse are returned unchanged.
+func (l *ModuleLayout) FixImports(file, content string) (string, []ImportFix) {
+	module := l.owner(path.Dir(file))
+	if module == nil {
+		return content, nil
+	}
+
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, file, content, parser.ImportsOnly)
+	if err != nil {
+		return content, nil
+	}
+
+	var fixes []ImportFix
+	var offsets [][2]int
+	for _, spec := range parsed.Imports {
+		from, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		to := l.internalImport(module, from)
+		if to == "" || to == from {
+			continue
+		}
+		fixes = append(fixes, ImportFix{File: file, From: from, To: to})
+		offsets = append(offsets, [2]int{fset.Position(spec.Path.Pos()).Offset, fset.Position(spec.Path.End()).Offset})
+	}
+	if len(fixes) == 0 {
+		return content, nil
+	}
+
+	// Replace from the end so earlier offsets stay valid
+	fixed := content
+	for i := len(fixes) - 1; i >= 0; i-- {
+		fixed = fixed[:offsets[i][0]] + strconv.Quote(fixes[i].To) + fixed[offsets[i][1]:]
+	}
+	// Re-sort the import block; the rewrite stands even if formatting fails
+	if formatted, err := format.Source([]byte(fixed)); err == nil {
+		fixed = string(formatted)
+	}
+	return fixed, fixes
+}
+
+// internalImport returns the module path import imp should use, or "" when
+// imp is not internal to module
+func (l *ModuleLayout) internalImport(module *LayoutModule, imp string) string {
+	if l.isKnownImport(imp) {
+		return ""
+	}
+
+	elems := strings.Split(imp, "/")
+	dotless := !strings.Contains(elems[0], ".")
+	for k := 0; k < len(elems); k++ {
+		if k == 0 && !dotless {
+			continue
+		}
+		if k == len(elems)-1 && !dotless && strings.Join(elems[:k], "/") != templates.DefaultModulePath {
+			break
+		}
+		suffix := strings.Join(elems[k:], "/")
+		if module.Packages[suffix] {
+			return module.Path + "/" + suffix
+		}
+	}
+	return ""
+}
+
+// isKnownImport reports whether imp is in the standard library or under a
+// module path the project knows
+func (l *ModuleLayout) isKnownImport(imp string) bool {
+	for _, module := range l.Modules {
+		if underModule(imp, module.Path) {
+			return true
+		}
+	}
+	for _, modulePath := range l.External {
+		if underModule(imp, modulePath) {
+			return true
+		}
+	}
+
+	first, _, _ := strings.Cut(imp, "/")
+	if strings.Contains(first, ".") || l.goroot == "" {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(l.goroot, "src", filepath.FromSlash(imp)))
+	return err == nil && info.IsDir()
+}
+
+// underModule reports whether imp is modulePath or one of its packages
+func underModule(imp, modulePath string) bool {
+	return imp == modulePath || strings.HasPrefix(imp, modulePath+"/")
+}
+
+// dirDepth counts the elements of a slash-separated relative directory
+func dirDepth(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
+
+// relToModule returns dir relative to the module directory moduleDir
+func relToModule(moduleDir, dir string) string {
+	if moduleDir == "." {
+		return dir
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(dir, moduleDir), "/")
+}
+
+// parseModDependencies returns the module paths a go.mod requires or replaces
+func parseModDependencies(goMod string) []string {
+	var paths []string
+	block := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(goMod))
+	for scanner.Scan() {
+		line := stripModComment(scanner.Text())
+		switch {
+		case block != "" && line == ")":
+			block = ""
+			continue
+		case line == "require (" || line == "replace (":
+			block = strings.TrimSuffix(line, " (")
+			continue
+		case block == "":
+			directive, rest, ok := strings.Cut(line, " ")
+			if !ok || (directive != "require" && directive != "replace") {
+				continue
+			}
+			line = strings.TrimSpace(rest)
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			paths = append(paths, unquoteModPath(fields[0]))
+		}
+	}
+	return paths
+}
@@ -0,0 +1,74 @@
+package generate
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleLayout_FixImports(t *testing.T) {
+	root := t.TempDir()
+	writeWorkspaceFile(t, filepath.Join(root, "go.mod"), "module example.com/shop\n\ngo 1.22\n\nrequire (\n\tgithub.com/go-chi/chi/v5 v5.0.12\n)\n")
+	writeWorkspaceFile(t, filepath.Join(root, "internal", "store", "store.go"), "package store\n")
+	writeWorkspaceFile(t, filepath.Join(root, "config", "config.go"), "package config\n")
+	writeWorkspaceFile(t, filepath.Join(root, "middleware", "middleware.go"), "package middleware\n")
+	writeWorkspaceFile(t, filepath.Join(root, "tools", "go.mod"), "module example.com/shop/tools\n")
+	writeWorkspaceFile(t, filepath.Join(root, "tools", "lint", "lint.go"), "package lint\n")
+
+	layout, err := DetectModuleLayout(root)
+	require.NoError(t, err)
+	require.Len(t, layout.Modules, 2)
+	assert.Equal(t, "tools", layout.Modules[0].Dir)
+	assert.Equal(t, map[string]bool{"lint": true}, layout.Modules[0].Packages)
+
+	source := `package main
+
+import (
+	"fmt"
+
+	"example.com/shop/internal/store"
+	"github.com/example/project/config"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/other/thing/middleware"
+	"internal/store/v2"
+	"shop/internal/store"
+)
+`
+	fixed, fixes := layout.FixImports("cmd/api/main.go", source)
+	assert.Equal(t, []ImportFix{
+		{File: "cmd/api/main.go", From: "github.com/example/project/config", To: "example.com/shop/config"},
+		{File: "cmd/api/main.go", From: "shop/internal/store", To: "example.com/shop/internal/store"},
+	}, fixes)
+	assert.Contains(t, fixed, "\t\"example.com/shop/config\"\n")
+	assert.Contains(t, fixed, "\t\"fmt\"\n")
+	assert.Contains(t, fixed, "\"github.com/go-chi/chi/v5/middleware\"")
+	assert.Contains(t, fixed, "\"github.com/other/thing/middleware\"")
+	assert.NotContains(t, fixed, "github.com/example/project")
+
+	// Files in the nested module resolve against its go.mod
+	_, fixes = layout.FixImports("tools/cmd/check/main.go", "package main\n\nimport \"github.com/example/project/lint\"\n")
+	assert.Equal(t, []ImportFix{
+		{File: "tools/cmd/check/main.go", From: "github.com/example/project/lint", To: "example.com/shop/tools/lint"},
+	}, fixes)
+
+	unchanged := "package main\n\nimport \"example.com/shop/config\"\n"
+	fixed, fixes = layout.FixImports("cmd/api/main.go", unchanged)
+	assert.Empty(t, fixes)
+	assert.Equal(t, unchanged, fixed)
+}
+
+func TestParseModDependencies(t *testing.T) {
+	goMod := `module example.com/shop
+
+require github.com/google/uuid v1.6.0
+
+require (
+	github.com/go-chi/chi/v5 v5.0.12 // indirect
+)
+
+replace example.com/billing => ../billing
+`
+	assert.Equal(t, []string{"github.com/google/uuid", "github.com/go-chi/chi/v5", "example.com/billing"}, parseModDependencies(goMod))
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
 	"github.com/rs/zerolog/log"
 )
 
@@ -55,8 +56,15 @@ type FileState struct {
 	// Template indicates if this file was generated from a template
 	Template bool `json:"template"`
 
+	// TemplateChecksum is the SHA-256 checksum of the template source the file
+	// was rendered from, so a changed template regenerates only its files
+	TemplateChecksum string `json:"template_checksum,omitempty"`
+
 	// TaskID is the generation task ID that created this file
 	TaskID string `json:"task_id"`
+
+	// Content is the generated content, kept as the merge base for hand edits
+	Content string `json:"content,omitempty"`
 }
 
 // IncrementalStateManager manages incremental state persistence
@@ -192,78 +200,144 @@ func normalizePath(path string) string {
 	return filepath.Clean(path)
 }
 
+// stateFileMu serializes updates of state files. The coder and the generation
+// graph keep managers of their own for one file and update it from branches
+// that run concurrently.
+var stateFileMu sync.Mutex
+
+// update applies change to the state saved on disk and saves the result, so
+// updates made through other managers of the same file are kept
+func (ism *IncrementalStateManager) update(change func(state *IncrementalState)) error {
+	stateFileMu.Lock()
+	defer stateFileMu.Unlock()
+
+	state, err := ism.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	change(state)
+	return ism.Save(state)
+}
+
 // UpdateState updates the state after successful generation
 func (ism *IncrementalStateManager) UpdateState(
 	fcs *models.FinalClarifiedSpecification,
 	patches []models.Patch,
 	dependencyGraph map[string][]string,
 ) error {
-	if ism.state == nil {
-		state, err := ism.Load()
-		if err != nil {
-			return fmt.Errorf("failed to load state: %w", err)
-		}
-		ism.state = state
-	}
-
 	// Compute new FCS checksum
 	fcsChecksum, err := ComputeFCSChecksum(fcs)
 	if err != nil {
 		return fmt.Errorf("failed to compute FCS checksum: %w", err)
 	}
 
-	// Update FCS checksum and store the complete FCS for next comparison
-	ism.state.FCSChecksum = fcsChecksum
-	ism.state.PreviousFCS = fcs
-	ism.state.LastGeneration = time.Now()
+	return ism.update(func(state *IncrementalState) {
+		// Update FCS checksum and store the complete FCS for next comparison
+		state.FCSChecksum = fcsChecksum
+		state.PreviousFCS = fcs
+		state.LastGeneration = time.Now()
 
-	// Update dependency graph with normalized paths
-	for path, deps := range dependencyGraph {
-		normalizedPath := normalizePath(path)
-		ism.state.DependencyGraph[normalizedPath] = deps
-	}
+		// Update dependency graph with normalized paths
+		for path, deps := range dependencyGraph {
+			normalizedPath := normalizePath(path)
+			state.DependencyGraph[normalizedPath] = deps
+		}
 
-	// Update file states from patches
-	for _, patch := range patches {
-		// Normalize the target file path
-		normalizedPath := normalizePath(patch.TargetFile)
-
-		// Extract file content from diff (simplified - assumes new file creation)
-		content := extractContentFromDiff(patch.Diff)
-		checksum := ComputeFileChecksum(content)
-
-		fileState := FileState{
-			Path:         normalizedPath,
-			Checksum:     checksum,
-			GeneratedAt:  patch.AppliedAt,
-			Dependencies: dependencyGraph[patch.TargetFile], // Use original path to look up in incoming graph
-			Template:     isTemplateFile(normalizedPath),
+		// Update file states from patches
+		for _, patch := range patches {
+			// Normalize the target file path
+			normalizedPath := normalizePath(patch.TargetFile)
+
+			// Extract file content from diff (simplified - assumes new file creation)
+			content := extractContentFromDiff(patch.Diff)
+			checksum := ComputeFileChecksum(content)
+
+			fileState := FileState{
+				Path:         normalizedPath,
+				Checksum:     checksum,
+				GeneratedAt:  patch.AppliedAt,
+				Dependencies: dependencyGraph[patch.TargetFile], // Use original path to look up in incoming graph
+				Template:     isTemplateFile(normalizedPath),
+				Content:      content,
+			}
+
+			state.GeneratedFiles[normalizedPath] = fileState
 		}
+	})
+}
 
-		ism.state.GeneratedFiles[normalizedPath] = fileState
-	}
+// RecordTemplateFiles records files rendered from templates, keeping the
+// state of every other file
+func (ism *IncrementalStateManager) RecordTemplateFiles(files []FileState) error {
+	return ism.update(func(state *IncrementalState) {
+		for _, file := range files {
+			file.Path = normalizePath(file.Path)
+			file.Template = true
+			state.GeneratedFiles[file.Path] = file
+		}
+	})
+}
+
+// newFileDiff renders generated content as a unified diff creating targetPath.
+// Every generated file passes through here, so this is where its whitespace
+// is normalized (see normalizeOutput).
+func newFileDiff(targetPath, content string) string {
+	return fsops.NewUnifiedDiffEngine().Diff(targetPath, "", normalizeOutput(targetPath, content))
+}
 
-	// Save updated state
-	return ism.Save(ism.state)
+// modifyFileDiff renders generated content as a unified diff modifying
+// targetPath from base. The whole file is kept as context, so the base the
+// content was generated from can be read back when the file has changed on
+// disk in the meantime (see extractBaseFromDiff).
+func modifyFileDiff(targetPath, base, content string) string {
+	return fsops.NewUnifiedDiffEngineWithContext(fsops.FullContext).Diff(targetPath, base, normalizeOutput(targetPath, content))
 }
 
-// extractContentFromDiff extracts file content from a unified diff
-// This is a simplified version that assumes new file creation (all lines start with +)
+// extractContentFromDiff extracts the new file content from a unified diff
+// that creates its file or carries the whole file as context
 func extractContentFromDiff(diff string) string {
-	lines := []string{}
+	return diffSide(diff, '+')
+}
+
+// extractBaseFromDiff extracts the old file content from a unified diff that
+// carries the whole file as context
+func extractBaseFromDiff(diff string) string {
+	return diffSide(diff, '-')
+}
+
+// diffSide returns the context lines of a unified diff together with the
+// lines of the given kind: '+' for the new side, '-' for the old one
+func diffSide(diff string, kind byte) string {
+	var sb strings.Builder
+	inHunk := false
+	last := ""
 	for _, line := range splitLines(diff) {
-		// Skip diff header lines
-		if len(line) > 0 && line[0] == '+' && !isHeaderLine(line) {
-			// Remove the leading '+' prefix
-			lines = append(lines, line[1:])
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+			// Skip file header lines
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file" applies to the line before it
+			if last != "" {
+				sb.WriteString(last[:len(last)-1])
+				last = ""
+			}
+		case line == "":
+			// A blank context line whose leading space was stripped
+			sb.WriteString(last)
+			last = "\n"
+		case line[0] == ' ' || line[0] == kind:
+			sb.WriteString(last)
+			last = line[1:] + "\n"
+		default:
+			// A line of the other side ends the one before it
+			sb.WriteString(last)
+			last = ""
 		}
 	}
-	result := joinLines(lines)
-	// If original diff ended with newline and we have content, add trailing newline
-	if len(diff) > 0 && diff[len(diff)-1] == '\n' && len(result) > 0 {
-		result += "\n"
-	}
-	return result
+	sb.WriteString(last)
+	return sb.String()
 }
 
 func splitLines(s string) []string {
@@ -281,24 +355,6 @@ func splitLines(s string) []string {
 	return result
 }
 
-func joinLines(lines []string) string {
-	result := ""
-	for i, line := range lines {
-		result += line
-		if i < len(lines)-1 {
-			result += "\n"
-		}
-	}
-	return result
-}
-
-func isHeaderLine(line string) bool {
-	if len(line) < 3 {
-		return false
-	}
-	return line[:3] == "+@@" || line[:3] == "+++"
-}
-
 // GetState returns the current state (loads if not already loaded)
 func (ism *IncrementalStateManager) GetState() (*IncrementalState, error) {
 	if ism.state == nil {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -285,6 +286,16 @@ func TestExtractContentFromDiff(t *testing.T) {
 			diff: "",
 			want: "",
 		},
+		{
+			name: "unified new file diff",
+			diff: "--- /dev/null\n+++ b/file.go\n@@ -0,0 +1,2 @@\n+package main\n+++counter\n",
+			want: "package main\n++counter\n",
+		},
+		{
+			name: "no newline at end of file",
+			diff: "--- /dev/null\n+++ b/notes.txt\n@@ -0,0 +1,2 @@\n+first\n+last\n\\ No newline at end of file\n",
+			want: "first\nlast",
+		},
 	}
 
 	for _, tt := range tests {
@@ -295,6 +306,31 @@ func TestExtractContentFromDiff(t *testing.T) {
 	}
 }
 
+func TestModifyFileDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		content string
+	}{
+		{"change a middle line", "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n", "a\nb\nc\nd\nE\nf\ng\nh\ni\nj\n"},
+		{"add lines", "package store\n\ntype Item struct{}\n", "package store\n\ntype Item struct{}\n\ntype Order struct{}\n"},
+		{"base without trailing newline", "first\nlast", "first\nlast\n"},
+		{"change the last line without trailing newline", "first\nlast", "First\nlast\nmore\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := modifyFileDiff("notes.txt", tt.base, tt.content)
+			assert.Equal(t, tt.base, extractBaseFromDiff(diff))
+			assert.Equal(t, tt.content, extractContentFromDiff(diff))
+
+			applied, err := fsops.NewUnifiedDiffEngine().Apply(tt.base, diff)
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, applied)
+		})
+	}
+}
+
 func TestIncrementalStateManager_ConcurrentAccess(t *testing.T) {
 	tempDir := t.TempDir()
 	manager := NewIncrementalStateManager(tempDir)
@@ -326,3 +362,29 @@ func TestIncrementalStateManager_ConcurrentAccess(t *testing.T) {
 		<-done
 	}
 }
+
+func TestIncrementalStateManager_UpdatesThroughTwoManagers(t *testing.T) {
+	tempDir := t.TempDir()
+	coder := NewIncrementalStateManager(tempDir)
+	graph := NewIncrementalStateManager(tempDir)
+
+	// Both managers load before either updates, as concurrent branches do
+	_, err := coder.Load()
+	require.NoError(t, err)
+	_, err = graph.Load()
+	require.NoError(t, err)
+
+	fcs := &models.FinalClarifiedSpecification{ID: "fcs-1"}
+	patches := []models.Patch{{TargetFile: "main.go", Diff: newFileDiff("main.go", "package main\n")}}
+
+	done := make(chan error, 2)
+	go func() { done <- coder.UpdateState(fcs, patches, nil) }()
+	go func() { done <- graph.RecordTemplateFiles([]FileState{{Path: "Makefile", Checksum: "c"}}) }()
+	require.NoError(t, <-done)
+	require.NoError(t, <-done)
+
+	state, err := NewIncrementalStateManager(tempDir).Load()
+	require.NoError(t, err)
+	assert.Contains(t, state.GeneratedFiles, "main.go")
+	assert.True(t, state.GeneratedFiles["Makefile"].Template, "neither update overwrites the other")
+}
@@ -0,0 +1,249 @@
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// fixKnowledgeVersion is the knowledge base file format version
+const fixKnowledgeVersion = "1"
+
+// maxFixEntries bounds the knowledge base; the least recently used entries
+// are dropped first
+const maxFixEntries = 500
+
+// maxFixLines bounds the removed and added lines kept for one fix
+const maxFixLines = 12
+
+// Patterns that vary between occurrences of the same problem
+var (
+	quotedPattern = regexp.MustCompile("\"[^\"]*\"|'[^']*'|`[^`]*`")
+	numberPattern = regexp.MustCompile(`\d+`)
+	spacePattern  = regexp.MustCompile(`\s+`)
+)
+
+// FixKnowledge is a local memory of repairs shared by every run: the
+// signature of a problem a check reported, mapped to the change that fixed
+// it. A repair for a known signature includes the earlier fix in its prompt,
+// so recurring failures are fixed at the first attempt.
+type FixKnowledge struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]*FixEntry
+}
+
+// FixEntry is the remembered fix for one problem signature
+type FixEntry struct {
+	Signature string    `json:"signature"`
+	Format    string    `json:"format"`  // Format of the repaired file, e.g. YAML
+	Problem   string    `json:"problem"` // Problem as last reported
+	Fix       string    `json:"fix"`     // Lines the repair removed (-) and added (+)
+	Fixed     int       `json:"fixed"`   // Repairs that applied this signature's fix
+	Used      int       `json:"used"`    // Repair prompts that included the fix
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// fixKnowledgeFile is the knowledge base on disk
+type fixKnowledgeFile struct {
+	Version string      `json:"version"`
+	Entries []*FixEntry `json:"entries"`
+}
+
+// DefaultFixKnowledgePath returns the knowledge base in the user's cache
+// directory, shared by the projects of that user
+func DefaultFixKnowledgePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "gocreator", "fixes.json"), nil
+}
+
+// LoadFixKnowledge reads the knowledge base at path. A missing file is an
+// empty knowledge base that the first recorded fix creates.
+func LoadFixKnowledge(path string) (*FixKnowledge, error) {
+	k := &FixKnowledge{path: path, entries: make(map[string]*FixEntry)}
+
+	//nolint:gosec // G304: Reading the configured knowledge base - required for fix lookup
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return k, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fix knowledge base: %w", err)
+	}
+
+	var file fixKnowledgeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse fix knowledge base %s: %w", path, err)
+	}
+	for _, entry := range file.Entries {
+		if entry != nil && entry.Signature != "" {
+			k.entries[entry.Signature] = entry
+		}
+	}
+	return k, nil
+}
+
+// Len returns the number of remembered fixes
+func (k *FixKnowledge) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.entries)
+}
+
+// Lookup returns the remembered fix for a problem reported for a file of
+// the format, and counts the use
+func (k *FixKnowledge) Lookup(format, problem string) (FixEntry, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	entry, ok := k.entries[fixSignature(format, problem)]
+	if !ok {
+		return FixEntry{}, false
+	}
+	entry.Used++
+	entry.UpdatedAt = time.Now()
+	if err := k.save(); err != nil {
+		log.Warn().Err(err).Msg("Failed to save fix knowledge base")
+	}
+	return *entry, true
+}
+
+// Record remembers how a repair fixed a problem, from the rejected and the
+// repaired content, and saves the knowledge base
+func (k *FixKnowledge) Record(format, problem, rejected, repaired string) error {
+	fix := describeFix(rejected, repaired)
+	if fix == "" {
+		return nil
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	signature := fixSignature(format, problem)
+	entry, ok := k.entries[signature]
+	if !ok {
+		entry = &FixEntry{Signature: signature, Format: format}
+		k.entries[signature] = entry
+	}
+	entry.Problem = problem
+	entry.Fix = fix
+	entry.Fixed++
+	entry.UpdatedAt = time.Now()
+
+	k.evict()
+	return k.save()
+}
+
+// evict drops the least recently used entries over maxFixEntries
+func (k *FixKnowledge) evict() {
+	if len(k.entries) <= maxFixEntries {
+		return
+	}
+	entries := k.sortedEntries()
+	for _, entry := range entries[maxFixEntries:] {
+		delete(k.entries, entry.Signature)
+	}
+}
+
+// sortedEntries returns the entries, most recently used first
+func (k *FixKnowledge) sortedEntries() []*FixEntry {
+	entries := make([]*FixEntry, 0, len(k.entries))
+	for _, entry := range k.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].UpdatedAt.Equal(entries[j].UpdatedAt) {
+			return entries[i].UpdatedAt.After(entries[j].UpdatedAt)
+		}
+		return entries[i].Signature < entries[j].Signature
+	})
+	return entries
+}
+
+// save atomically writes the knowledge base; the caller holds mu
+func (k *FixKnowledge) save() error {
+	if err := os.MkdirAll(filepath.Dir(k.path), 0750); err != nil {
+		return fmt.Errorf("failed to create fix knowledge directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(fixKnowledgeFile{Version: fixKnowledgeVersion, Entries: k.sortedEntries()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fix knowledge base: %w", err)
+	}
+
+	tempPath := k.path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write fix knowledge base: %w", err)
+	}
+	if err := os.Rename(tempPath, k.path); err != nil {
+		_ = os.Remove(tempPath) // Clean up temp file, ignore error
+		return fmt.Errorf("failed to rename fix knowledge base: %w", err)
+	}
+
+	log.Debug().
+		Str("path", k.path).
+		Int("entries", len(k.entries)).
+		Msg("Saved fix knowledge base")
+
+	return nil
+}
+
+// fixSignature identifies a class of problem: the format and the problem
+// with the parts that vary between occurrences (names, values, line
+// numbers) replaced
+func fixSignature(format, problem string) string {
+	signature := quotedPattern.ReplaceAllString(problem, `"_"`)
+	signature = numberPattern.ReplaceAllString(signature, "N")
+	signature = spacePattern.ReplaceAllString(strings.TrimSpace(signature), " ")
+	return strings.ToLower(format) + ": " + signature
+}
+
+// describeFix summarizes a repair as the lines it removed and added, in the
+// order they appear; unchanged lines are left out
+func describeFix(rejected, repaired string) string {
+	removed := lineChanges(rejected, repaired, "- ")
+	added := lineChanges(repaired, rejected, "+ ")
+	if len(removed) == 0 && len(added) == 0 {
+		return ""
+	}
+	return strings.Join(append(removed, added...), "\n")
+}
+
+// lineChanges returns the non-blank lines of from that other lacks, prefixed
+// with marker and capped at maxFixLines
+func lineChanges(from, other, marker string) []string {
+	remaining := make(map[string]int)
+	for _, line := range strings.Split(other, "\n") {
+		remaining[strings.TrimRight(line, " \t\r")]++
+	}
+
+	var changes []string
+	for _, line := range strings.Split(from, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if remaining[line] > 0 {
+			remaining[line]--
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(changes) == maxFixLines {
+			changes = append(changes, marker+"...")
+			break
+		}
+		changes = append(changes, marker+line)
+	}
+	return changes
+}
@@ -0,0 +1,81 @@
+package generate
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixSignature(t *testing.T) {
+	a := fixSignature("YAML", `invalid YAML: yaml: line 3: mapping key "port" already defined at line 2`)
+	b := fixSignature("YAML", `invalid YAML: yaml: line 14: mapping key "host" already defined at line 9`)
+	assert.Equal(t, a, b, "names and line numbers do not change the signature")
+	assert.Equal(t, `yaml: invalid YAML: yaml: line N: mapping key "_" already defined at line N`, a)
+
+	assert.NotEqual(t, a, fixSignature("JSON", `invalid YAML: yaml: line 3: mapping key "port" already defined at line 2`))
+	assert.NotEqual(t, a, fixSignature("YAML", "invalid YAML: yaml: line 3: found character that cannot start any token"))
+}
+
+func TestDescribeFix(t *testing.T) {
+	fix := describeFix("server:\n  port: 8080\n  port: 9090\n", "server:\n  port: 8080\n  host: localhost\n")
+	assert.Equal(t, "-   port: 9090\n+   host: localhost", fix)
+	assert.Empty(t, describeFix("a: 1\n", "a: 1\n\n"))
+}
+
+func TestFixKnowledge_RecordAndLookup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "fixes.json")
+	knowledge, err := LoadFixKnowledge(path)
+	require.NoError(t, err)
+	assert.Equal(t, 0, knowledge.Len())
+
+	problem := `invalid JSON: invalid character '}' looking for beginning of object key string`
+	require.NoError(t, knowledge.Record("JSON", problem, "{\"a\": 1,}\n", "{\"a\": 1}\n"))
+
+	// Another run reads the saved knowledge base
+	reloaded, err := LoadFixKnowledge(path)
+	require.NoError(t, err)
+	entry, ok := reloaded.Lookup("JSON", problem)
+	require.True(t, ok)
+	assert.Equal(t, "- {\"a\": 1,}\n+ {\"a\": 1}", entry.Fix)
+	assert.Equal(t, 1, entry.Fixed)
+	assert.Equal(t, 1, entry.Used)
+
+	_, ok = reloaded.Lookup("YAML", problem)
+	assert.False(t, ok)
+}
+
+func TestGenerateFile_RemembersAncillaryFix(t *testing.T) {
+	knowledge, err := LoadFixKnowledge(filepath.Join(t.TempDir(), "fixes.json"))
+	require.NoError(t, err)
+	plan := &models.GenerationPlan{}
+
+	// The first project's repair is remembered
+	first := &scriptedLLMClient{responses: []string{
+		"server:\n  port: 8080\n  port: 9090\n",
+		"server:\n  port: 8080\n",
+	}}
+	coder, err := NewCoder(CoderConfig{LLMClient: first, FixKnowledge: knowledge})
+	require.NoError(t, err)
+	_, err = coder.GenerateFile(context.Background(), models.GenerationTask{ID: "config", TargetPath: "config.yaml"}, plan, nil)
+	require.NoError(t, err)
+	require.Len(t, first.prompts, 2)
+	assert.NotContains(t, first.prompts[1], "fixed in an earlier file")
+	assert.Equal(t, 1, knowledge.Len())
+
+	// The same problem in another project gets the remembered fix
+	second := &scriptedLLMClient{responses: []string{
+		"db:\n  host: a\n  host: b\n",
+		"db:\n  host: a\n",
+	}}
+	coder, err = NewCoder(CoderConfig{LLMClient: second, FixKnowledge: knowledge})
+	require.NoError(t, err)
+	_, err = coder.GenerateFile(context.Background(), models.GenerationTask{ID: "db", TargetPath: "deploy/db.yml"}, plan, nil)
+	require.NoError(t, err)
+	require.Len(t, second.prompts, 2)
+	assert.Contains(t, second.prompts[1], "fixed in an earlier file")
+	assert.Contains(t, second.prompts[1], "-   port: 9090")
+}
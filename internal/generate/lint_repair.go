@@ -0,0 +1,149 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/validate"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// lintRepairFormat is the format under which lint repairs are remembered in
+// the fix knowledge base
+const lintRepairFormat = "golangci-lint"
+
+// LintRepairer regenerates the files of a project that golangci-lint reports
+// issues in
+type LintRepairer interface {
+	// Repair feeds the issues of a lint run back to the model one file at a
+	// time and lints again, until no issues remain or the attempts run out
+	Repair(ctx context.Context, projectRoot string, lint *models.LintResult) (*LintRepairResult, error)
+}
+
+// LintRepairConfig contains configuration for creating a lint repairer
+type LintRepairConfig struct {
+	LLMClient llm.Client
+	Validator validate.LintValidator // Should apply the severity policy the first result had
+	FileOps   fsops.FileOps          // Rooted at the project; writes the repaired files
+
+	// MaxAttempts bounds the lint reruns after repairs (default 2)
+	MaxAttempts int
+
+	// Preamble is organization-wide guidance placed ahead of every prompt
+	Preamble string
+
+	// Knowledge remembers repairs that clear an issue and suggests them when
+	// the same issue recurs (optional)
+	Knowledge *FixKnowledge
+
+	// Build rebuilds the project after each round; a round that breaks the
+	// build is rolled back (optional)
+	Build validate.BuildValidator
+}
+
+// LintRepairResult is the outcome of a lint repair
+type LintRepairResult struct {
+	Lint     *models.LintResult // Last lint result, with Repaired set
+	Attempts int                // Repair rounds run
+}
+
+// llmLintRepairer implements LintRepairer with the coder model
+type llmLintRepairer struct {
+	client      llm.Client
+	validator   validate.LintValidator
+	fileOps     fsops.FileOps
+	maxAttempts int
+	preamble    string
+	knowledge   *FixKnowledge
+	build       validate.BuildValidator
+}
+
+// NewLintRepairer creates a new LintRepairer instance
+func NewLintRepairer(cfg LintRepairConfig) (LintRepairer, error) {
+	if cfg.LLMClient == nil {
+		return nil, fmt.Errorf("LLM client is required")
+	}
+	if cfg.Validator == nil {
+		return nil, fmt.Errorf("lint validator is required")
+	}
+	if cfg.FileOps == nil {
+		return nil, fmt.Errorf("file operations handler is required")
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 2
+	}
+	return &llmLintRepairer{
+		client:      cfg.LLMClient,
+		validator:   cfg.Validator,
+		fileOps:     cfg.FileOps,
+		maxAttempts: cfg.MaxAttempts,
+		preamble:    cfg.Preamble,
+		knowledge:   cfg.Knowledge,
+		build:       cfg.Build,
+	}, nil
+}
+
+// Repair implements LintRepairer. Each round regenerates every Go file the
+// last lint run reported issues in, then lints again. A regenerated file that
+// does not parse is discarded, and a round that breaks the build is rolled
+// back. Repair stops early when a round changes nothing.
+func (r *llmLintRepairer) Repair(ctx context.Context, projectRoot string, lint *models.LintResult) (*LintRepairResult, error) {
+	loop := &repairLoop[*models.LintResult, models.LintIssue]{
+		kind:        "lint",
+		client:      r.client,
+		fileOps:     r.fileOps,
+		maxAttempts: r.maxAttempts,
+		validate: func(ctx context.Context) (*models.LintResult, error) {
+			return r.validator.Validate(ctx, projectRoot)
+		},
+		passed:    func(lint *models.LintResult) bool { return lint.Success },
+		targets:   lintRepairTargets,
+		prompt:    r.buildLintRepairPrompt,
+		knowledge: r.knowledge,
+		format:    lintRepairFormat,
+		signature: func(issue models.LintIssue) string { return issue.Rule + ": " + issue.Message },
+	}
+	if r.build != nil {
+		loop.check = func(ctx context.Context) (bool, error) {
+			build, err := r.build.Validate(ctx, projectRoot)
+			if err != nil {
+				return false, err
+			}
+			return build.Success, nil
+		}
+	}
+
+	outcome, err := loop.run(ctx, lint)
+	outcome.last.Repaired = outcome.repaired
+	return &LintRepairResult{Lint: outcome.last, Attempts: outcome.attempts}, err
+}
+
+// buildLintRepairPrompt constructs the LLM prompt that fixes a file's lint issues
+func (r *llmLintRepairer) buildLintRepairPrompt(target repairTarget[models.LintIssue], content, knownFix string) string {
+	issues := make([]string, len(target.problems))
+	for i, issue := range target.problems {
+		issues[i] = fmt.Sprintf("%s:%d:%d: %s (%s)", issue.File, issue.Line, issue.Column, issue.Message, issue.Rule)
+	}
+
+	return repairPrompt{
+		role:     "an expert Go developer fixing the issues a linter reports",
+		task:     fmt.Sprintf("Fix the issues `golangci-lint` reports for %s.", target.file),
+		heading:  "Lint Issues",
+		problems: issues,
+		noun:     "issue",
+		knownFix: knownFix,
+		files:    []repairPromptFile{{heading: "Current File", content: content}},
+		requirements: []string{
+			"Fix every issue listed by changing the code; do not add nolint directives",
+			"Keep the behavior, the package clause and the exported declarations unchanged",
+			"The file must still compile",
+		},
+	}.build(r.preamble)
+}
+
+// lintRepairTargets returns the Go source files a lint run reported issues in
+func lintRepairTargets(lint *models.LintResult) []repairTarget[models.LintIssue] {
+	return repairTargets(lint.Issues, func(issue models.LintIssue) string { return issue.File })
+}
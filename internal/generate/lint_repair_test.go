@@ -0,0 +1,116 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintRepairer_Repair(t *testing.T) {
+	root, fileOps := newRepairProject(t)
+	validator := &markerLintValidator{markerValidator{marker: "brokenCall"}}
+	client := &scriptedLLMClient{responses: []string{
+		"```go\npackage store\n\nvar Items = []string{}\n```",
+		"package main\n\nfunc main() {}",
+	}}
+	knowledge, err := LoadFixKnowledge(filepath.Join(t.TempDir(), "fixes.json"))
+	require.NoError(t, err)
+
+	repairer, err := NewLintRepairer(LintRepairConfig{
+		LLMClient: client,
+		Validator: validator,
+		FileOps:   fileOps,
+		Knowledge: knowledge,
+	})
+	require.NoError(t, err)
+
+	lint, err := validator.Validate(context.Background(), root)
+	require.NoError(t, err)
+	require.False(t, lint.Success)
+
+	result, err := repairer.Repair(context.Background(), root, lint)
+	require.NoError(t, err)
+	assert.True(t, result.Lint.Success)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, []string{"internal/store/store.go", "main.go"}, result.Lint.Repaired)
+	assert.Equal(t, 2, validator.runs)
+
+	// Each prompt carries the file and its issues with the linter that reported them
+	require.Len(t, client.prompts, 2)
+	assert.Contains(t, client.prompts[0], "internal/store/store.go:3:1: Error return value of `brokenCall` is not checked (errcheck)")
+	assert.Contains(t, client.prompts[0], "var Items = brokenCall()")
+
+	assert.Equal(t, 1, knowledge.Len(), "both files had the same problem signature")
+}
+
+func TestLintRepairer_AttemptsRunOut(t *testing.T) {
+	root, fileOps := newRepairProject(t)
+	validator := &markerLintValidator{markerValidator{marker: "brokenCall"}}
+
+	// The model keeps the issue; an unparsable response is discarded
+	client := &scriptedLLMClient{responses: []string{
+		"package store\n\nvar Items = brokenCall()\n",
+		"func main() {",
+		"package store\n\nvar Items = brokenCall()\n",
+		"func main() {",
+	}}
+	repairer, err := NewLintRepairer(LintRepairConfig{
+		LLMClient:   client,
+		Validator:   validator,
+		FileOps:     fileOps,
+		MaxAttempts: 2,
+	})
+	require.NoError(t, err)
+
+	lint, err := validator.Validate(context.Background(), root)
+	require.NoError(t, err)
+
+	result, err := repairer.Repair(context.Background(), root, lint)
+	require.NoError(t, err)
+	assert.False(t, result.Lint.Success)
+	assert.Equal(t, 2, result.Attempts)
+	assert.Len(t, result.Lint.Issues, 2)
+	assert.Equal(t, []string{"internal/store/store.go"}, result.Lint.Repaired)
+	assert.Equal(t, 3, validator.runs)
+}
+
+func TestLintRepairer_RollsBackBrokenBuild(t *testing.T) {
+	root, fileOps := newRepairProject(t)
+	validator := &markerLintValidator{markerValidator{marker: "brokenCall"}}
+	build := &markerBuildValidator{markerValidator{marker: "undefinedCall"}}
+
+	// The store repair clears its issue but no longer compiles
+	client := &scriptedLLMClient{responses: []string{
+		"package store\n\nvar Items = undefinedCall()\n",
+		"package main\n\nfunc main() {}",
+	}}
+	repairer, err := NewLintRepairer(LintRepairConfig{
+		LLMClient:   client,
+		Validator:   validator,
+		FileOps:     fileOps,
+		MaxAttempts: 2,
+		Build:       build,
+	})
+	require.NoError(t, err)
+
+	lint, err := validator.Validate(context.Background(), root)
+	require.NoError(t, err)
+
+	result, err := repairer.Repair(context.Background(), root, lint)
+	require.NoError(t, err)
+	assert.False(t, result.Lint.Success)
+	assert.Equal(t, 1, result.Attempts, "a round that breaks the build ends the repair")
+	assert.Empty(t, result.Lint.Repaired)
+	assert.Equal(t, 1, build.runs)
+	assert.Equal(t, 1, validator.runs, "the lint run is not repeated")
+
+	for _, file := range []string{"main.go", "internal/store/store.go"} {
+		content, err := os.ReadFile(filepath.Join(root, file))
+		require.NoError(t, err)
+		assert.Equal(t, repairProjectFiles[file], string(content), "%s is rolled back", file)
+	}
+}
@@ -0,0 +1,402 @@
+package generate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+// FileOwner is what produced a generated file
+type FileOwner string
+
+const (
+	// FileOwnerTemplate files are rendered from a built-in template and can be
+	// re-rendered without an LLM
+	FileOwnerTemplate FileOwner = "template"
+
+	// FileOwnerPrompt files are written by the LLM from a prompt
+	FileOwnerPrompt FileOwner = "prompt"
+)
+
+// ManifestFile records one generated file
+type ManifestFile struct {
+	// Owner is what produced the file
+	Owner FileOwner `json:"owner"`
+
+	// Checksum is the checksum of the content as generated, so later edits
+	// by people can be told apart from generated content
+	Checksum string `json:"checksum"`
+
+	// GeneratorVersion is the gocreator version that wrote the file
+	GeneratorVersion string `json:"generator_version,omitempty"`
+
+	// GeneratedAt is when the file was written
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// Model is the provider/model that wrote a prompt-owned file, or "stub"
+	// for files the cost ceiling replaced with a stub
+	Model string `json:"model,omitempty"`
+
+	// PromptHash is the SHA-256 of the prompt the file was generated from
+	PromptHash string `json:"prompt_hash,omitempty"`
+
+	// TaskID is the plan task that generated a prompt-owned file, or
+	// "test:<source file>" for a test file
+	TaskID string `json:"task_id,omitempty"`
+
+	// InputTokens and OutputTokens estimate what generating a prompt-owned
+	// file used, at four bytes per token
+	InputTokens  int64 `json:"input_tokens,omitempty"`
+	OutputTokens int64 `json:"output_tokens,omitempty"`
+
+	// StartedAt is when the first prompt for a prompt-owned file was sent
+	StartedAt *time.Time `json:"started_at,omitempty"`
+}
+
+// Manifest records which files of an output directory gocreator generated
+// and how, so upgrades can tell generated files from code people wrote
+type Manifest struct {
+	// Version is the manifest format version
+	Version string `json:"version"`
+
+	// FCS is the specification the files were generated from; templates are
+	// re-rendered from it
+	FCS *models.FinalClarifiedSpecification `json:"fcs,omitempty"`
+
+	// Files maps slash-separated paths relative to the output directory to
+	// their record
+	Files map[string]ManifestFile `json:"files"`
+
+	// Provenance holds the generator and model settings of the last run that
+	// recorded files; prompt hashes are kept on each file
+	Provenance *models.Provenance `json:"provenance,omitempty"`
+}
+
+// NewManifest creates an empty manifest
+func NewManifest() *Manifest {
+	return &Manifest{
+		Version: "1.0",
+		Files:   make(map[string]ManifestFile),
+	}
+}
+
+// ManifestPath returns the manifest file for an output directory
+func ManifestPath(outputDir string) string {
+	return filepath.Join(outputDir, ".gocreator", "manifest.json")
+}
+
+// generatorTemplate is the Generator of files rendered from templates,
+// matching the GeneratedBy the plan gives them
+const generatorTemplate = "template"
+
+// FileOwnerOf returns the owner of a generated file from its path alone, for
+// files recorded without a generator. Boilerplate at the project root comes
+// from templates; everything else from prompts.
+func FileOwnerOf(path string) FileOwner {
+	if templates.IsBoilerplatePath(filepath.ToSlash(filepath.Clean(path))) {
+		return FileOwnerTemplate
+	}
+	return FileOwnerPrompt
+}
+
+// fileOwner returns the owner of a generated file. Files the generation
+// marked as rendered from templates, such as the go.mod of each module and
+// the files rendered from the data model, are template-owned wherever they are.
+func fileOwner(file models.GeneratedFile) FileOwner {
+	if file.Generator == generatorTemplate {
+		return FileOwnerTemplate
+	}
+	return FileOwnerOf(file.Path)
+}
+
+// LoadManifest reads the manifest for an output directory.
+// It returns nil without error when no manifest has been saved.
+func LoadManifest(outputDir string) (*Manifest, error) {
+	manifest, err := LoadManifestFile(ManifestPath(outputDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return manifest, err
+}
+
+// LoadManifestFile reads a manifest from path, such as a copy kept from an
+// earlier run
+func LoadManifestFile(path string) (*Manifest, error) {
+	//nolint:gosec // G304: Manifest paths come from the user or the output directory
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	for path, file := range manifest.Files {
+		if file.Owner != FileOwnerTemplate && file.Owner != FileOwnerPrompt {
+			return nil, fmt.Errorf("manifest entry %s has unknown owner %q", path, file.Owner)
+		}
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]ManifestFile)
+	}
+
+	return &manifest, nil
+}
+
+// Save atomically writes the manifest into the output directory
+func (m *Manifest) Save(outputDir string) error {
+	manifestPath := ManifestPath(outputDir)
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0750); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	tempPath := manifestPath + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tempPath, manifestPath); err != nil {
+		_ = os.Remove(tempPath) // Clean up temp file, ignore error
+		return fmt.Errorf("failed to rename manifest: %w", err)
+	}
+
+	log.Debug().
+		Str("path", manifestPath).
+		Int("files", len(m.Files)).
+		Msg("Saved generation manifest")
+
+	return nil
+}
+
+// Record adds or replaces the entries for generated files, stamping them with
+// the run's provenance. Files from earlier runs that were not regenerated
+// keep their entries.
+func (m *Manifest) Record(fcs *models.FinalClarifiedSpecification, files []models.GeneratedFile, provenance *models.Provenance) {
+	m.FCS = fcs
+
+	var settings models.Provenance
+	if provenance != nil {
+		settings = *provenance
+		settings.PromptHashes = nil
+		settings.Files = nil
+		m.Provenance = &settings
+	}
+	model := ""
+	if settings.Provider != "" {
+		model = settings.Provider + "/" + settings.Model
+	}
+
+	for _, file := range files {
+		path := filepath.ToSlash(filepath.Clean(file.Path))
+		entry := ManifestFile{
+			Owner:            fileOwner(file),
+			Checksum:         file.Checksum,
+			GeneratorVersion: settings.GeneratorVersion,
+			GeneratedAt:      file.GeneratedAt,
+		}
+		if entry.Owner == FileOwnerPrompt {
+			entry.Model = model
+			if tester := settings.PhaseModels["tester"]; tester != "" && strings.HasSuffix(path, "_test.go") {
+				entry.Model = tester
+			}
+		}
+		if provenance != nil {
+			entry.PromptHash = provenance.PromptHashes[path]
+			if generated, ok := provenance.Files[path]; ok {
+				entry.TaskID = generated.TaskID
+				entry.InputTokens = generated.InputTokens
+				entry.OutputTokens = generated.OutputTokens
+				if !generated.StartedAt.IsZero() {
+					startedAt := generated.StartedAt
+					entry.StartedAt = &startedAt
+				}
+			}
+		}
+		m.Files[path] = entry
+	}
+}
+
+// RecordDowngrades notes the cheaper model, or the stub, that wrote the
+// files the cost ceiling kept from the primary model
+func (m *Manifest) RecordDowngrades(downgrades []models.FileDowngrade) {
+	for _, downgrade := range downgrades {
+		path := filepath.ToSlash(filepath.Clean(downgrade.Path))
+		if entry, ok := m.Files[path]; ok {
+			entry.Model = downgrade.To
+			m.Files[path] = entry
+		}
+	}
+}
+
+// Paths returns the recorded paths in order
+func (m *Manifest) Paths() []string {
+	paths := make([]string, 0, len(m.Files))
+	for path := range m.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ManifestFileStatus is how a recorded file compares with its content as generated
+type ManifestFileStatus string
+
+const (
+	// ManifestFileIntact files still hold their generated content
+	ManifestFileIntact ManifestFileStatus = "intact"
+
+	// ManifestFileModified files were changed since generation
+	ManifestFileModified ManifestFileStatus = "modified"
+
+	// ManifestFileMissing files were deleted since generation
+	ManifestFileMissing ManifestFileStatus = "missing"
+)
+
+// ManifestCheck is the result of re-checking one recorded file
+type ManifestCheck struct {
+	Path   string
+	Entry  ManifestFile
+	Status ManifestFileStatus
+}
+
+// VerifyManifest re-computes the checksum of every file recorded in the
+// output directory's manifest and compares it with the recorded checksum.
+// Checks are returned in path order.
+func VerifyManifest(ctx context.Context, outputDir string) (*Manifest, []ManifestCheck, error) {
+	manifest, err := LoadManifest(outputDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("no manifest at %s", ManifestPath(outputDir))
+	}
+
+	paths := manifest.Paths()
+	checks := make([]ManifestCheck, 0, len(paths))
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		check := ManifestCheck{Path: path, Entry: manifest.Files[path], Status: ManifestFileIntact}
+		data, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(path)))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			check.Status = ManifestFileMissing
+		case err != nil:
+			return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+		default:
+			sum := sha256.Sum256(data)
+			if hex.EncodeToString(sum[:]) != check.Entry.Checksum {
+				check.Status = ManifestFileModified
+			}
+		}
+		checks = append(checks, check)
+	}
+	return manifest, checks, nil
+}
+
+// ManifestChange is how a file differs between two manifests
+type ManifestChange string
+
+const (
+	// ManifestFileAdded files are recorded only in the newer manifest
+	ManifestFileAdded ManifestChange = "added"
+
+	// ManifestFileRemoved files are recorded only in the older manifest
+	ManifestFileRemoved ManifestChange = "removed"
+
+	// ManifestFileChanged files were generated differently: with other
+	// content, another model, another prompt or another gocreator version
+	ManifestFileChanged ManifestChange = "changed"
+
+	// ManifestFileUnchanged files were generated alike in both
+	ManifestFileUnchanged ManifestChange = "unchanged"
+)
+
+// ManifestDiff compares one file across two manifests
+type ManifestDiff struct {
+	Path   string
+	Change ManifestChange
+	Old    *ManifestFile // Nil when added
+	New    *ManifestFile // Nil when removed
+
+	// Fields lists what differs for a changed file: content, model, prompt,
+	// generator or owner
+	Fields []string
+}
+
+// DiffManifests compares the files recorded in two manifests, such as those
+// of two runs of the same specification, in path order. Timestamps, task IDs
+// and token counts differ between any two runs and are not compared.
+func DiffManifests(older, newer *Manifest) []ManifestDiff {
+	paths := older.Paths()
+	for _, path := range newer.Paths() {
+		if _, ok := older.Files[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	diffs := make([]ManifestDiff, 0, len(paths))
+	for _, path := range paths {
+		oldEntry, inOld := older.Files[path]
+		newEntry, inNew := newer.Files[path]
+		diff := ManifestDiff{Path: path}
+		switch {
+		case !inOld:
+			diff.Change = ManifestFileAdded
+			diff.New = &newEntry
+		case !inNew:
+			diff.Change = ManifestFileRemoved
+			diff.Old = &oldEntry
+		default:
+			diff.Old, diff.New = &oldEntry, &newEntry
+			diff.Fields = changedFields(oldEntry, newEntry)
+			diff.Change = ManifestFileUnchanged
+			if len(diff.Fields) > 0 {
+				diff.Change = ManifestFileChanged
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// changedFields lists the recorded fields that differ between two entries
+// of the same file
+func changedFields(older, newer ManifestFile) []string {
+	var fields []string
+	for _, field := range []struct {
+		name     string
+		old, new string
+	}{
+		{"content", older.Checksum, newer.Checksum},
+		{"model", older.Model, newer.Model},
+		{"prompt", older.PromptHash, newer.PromptHash},
+		{"generator", older.GeneratorVersion, newer.GeneratorVersion},
+		{"owner", string(older.Owner), string(newer.Owner)},
+	} {
+		if field.old != field.new {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,215 @@
+package generate
+
+import (
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Conflict markers emitted when both sides changed the same region.
+// They mirror git's diff3 style so editors and tooling recognise them.
+const (
+	conflictMarkerOurs   = "<<<<<<< ours (hand edited)"
+	conflictMarkerBase   = "||||||| base (last generated)"
+	conflictMarkerSep    = "======="
+	conflictMarkerTheirs = ">>>>>>> theirs (regenerated)"
+)
+
+// MergeResult is the outcome of a three-way merge
+type MergeResult struct {
+	// Content is the merged file content, including conflict markers if any
+	Content string
+
+	// Conflicts is the number of regions that could not be merged automatically
+	Conflicts int
+}
+
+// HasConflicts reports whether the merge left conflict markers in the content
+func (r MergeResult) HasConflicts() bool {
+	return r.Conflicts > 0
+}
+
+// ThreeWayMerge merges two descendants of a common base line by line.
+// base is the last generated version, ours is the on-disk (hand edited) version
+// and theirs is the freshly generated version. Regions changed on only one side
+// are taken from that side; regions changed differently on both sides are
+// wrapped in diff3-style conflict markers.
+func ThreeWayMerge(base, ours, theirs string) MergeResult {
+	result := MergeResult{}
+	result.Content = mergeLines(base, ours, theirs, func(sb *strings.Builder, baseChunk, oursChunk, theirsChunk []string) {
+		result.Conflicts++
+		writeConflict(sb, baseChunk, oursChunk, theirsChunk)
+	})
+	return result
+}
+
+// RejectedHunk is a region of a three-way merge that both sides changed
+// differently, which RejectingMerge resolved to ours
+type RejectedHunk struct {
+	Line   int    // First line of the kept region in the merged content, 1-based
+	Base   string // The region in the common base
+	Ours   string // The region on disk, which was kept
+	Theirs string // The region as generated, which was rejected
+}
+
+// RejectingMerge merges two descendants of a common base line by line like
+// ThreeWayMerge, but keeps ours for every region both sides changed
+// differently instead of writing conflict markers, and returns the rejected
+// regions of theirs.
+func RejectingMerge(base, ours, theirs string) (string, []RejectedHunk) {
+	var rejected []RejectedHunk
+	content := mergeLines(base, ours, theirs, func(sb *strings.Builder, baseChunk, oursChunk, theirsChunk []string) {
+		rejected = append(rejected, RejectedHunk{
+			Line:   strings.Count(sb.String(), "\n") + 1,
+			Base:   strings.Join(baseChunk, ""),
+			Ours:   strings.Join(oursChunk, ""),
+			Theirs: strings.Join(theirsChunk, ""),
+		})
+		writeLines(sb, oursChunk)
+	})
+	return content, rejected
+}
+
+// mergeLines merges ours and theirs against base region by region, calling
+// conflict to write each region both sides changed differently
+func mergeLines(base, ours, theirs string, conflict func(sb *strings.Builder, base, ours, theirs []string)) string {
+	baseLines := splitLinesKeepEOL(base)
+	oursLines := splitLinesKeepEOL(ours)
+	theirsLines := splitLinesKeepEOL(theirs)
+
+	oursMatch := matchLines(base, ours, len(baseLines))
+	theirsMatch := matchLines(base, theirs, len(baseLines))
+
+	var sb strings.Builder
+
+	i, j, k := 0, 0, 0
+	for {
+		// Find the next base line that survived unchanged on both sides
+		syncBase := -1
+		for b := i; b < len(baseLines); b++ {
+			if oursMatch[b] >= j && theirsMatch[b] >= k {
+				syncBase = b
+				break
+			}
+		}
+
+		baseEnd, oursEnd, theirsEnd := len(baseLines), len(oursLines), len(theirsLines)
+		if syncBase >= 0 {
+			baseEnd, oursEnd, theirsEnd = syncBase, oursMatch[syncBase], theirsMatch[syncBase]
+		}
+
+		baseChunk := baseLines[i:baseEnd]
+		oursChunk := oursLines[j:oursEnd]
+		theirsChunk := theirsLines[k:theirsEnd]
+
+		switch {
+		case equalLines(oursChunk, baseChunk):
+			writeLines(&sb, theirsChunk)
+		case equalLines(theirsChunk, baseChunk), equalLines(oursChunk, theirsChunk):
+			writeLines(&sb, oursChunk)
+		default:
+			conflict(&sb, baseChunk, oursChunk, theirsChunk)
+		}
+
+		if syncBase < 0 {
+			break
+		}
+
+		sb.WriteString(baseLines[syncBase])
+		i, j, k = syncBase+1, oursEnd+1, theirsEnd+1
+	}
+
+	return sb.String()
+}
+
+// matchLines returns, for every line of base, the index of the matching line in
+// other according to a line-level LCS diff, or -1 when the line was removed
+func matchLines(base, other string, baseCount int) []int {
+	matches := make([]int, baseCount)
+	for idx := range matches {
+		matches[idx] = -1
+	}
+
+	dmp := diffmatchpatch.New()
+	baseRunes, otherRunes, _ := dmp.DiffLinesToRunes(base, other)
+	diffs := dmp.DiffMainRunes(baseRunes, otherRunes, false)
+
+	// Each rune stands for one line, so rune counts are line counts
+	bi, oi := 0, 0
+	for _, d := range diffs {
+		n := len([]rune(d.Text))
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			for x := 0; x < n; x++ {
+				if bi < baseCount {
+					matches[bi] = oi
+				}
+				bi++
+				oi++
+			}
+		case diffmatchpatch.DiffDelete:
+			bi += n
+		case diffmatchpatch.DiffInsert:
+			oi += n
+		}
+	}
+
+	return matches
+}
+
+// splitLinesKeepEOL splits content into lines, keeping each line's terminator
+func splitLinesKeepEOL(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(sb *strings.Builder, lines []string) {
+	for _, line := range lines {
+		sb.WriteString(line)
+	}
+}
+
+// writeConflict writes a diff3-style conflict block, making sure every marker
+// starts on its own line even when a side lacks a trailing newline
+func writeConflict(sb *strings.Builder, base, ours, theirs []string) {
+	writeSection := func(marker string, lines []string) {
+		sb.WriteString(marker + "\n")
+		writeLines(sb, lines)
+		if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+			sb.WriteString("\n")
+		}
+	}
+
+	writeSection(conflictMarkerOurs, ours)
+	writeSection(conflictMarkerBase, base)
+	writeSection(conflictMarkerSep, theirs)
+	sb.WriteString(conflictMarkerTheirs + "\n")
+}
+
+// containsConflictMarkers reports whether content still holds unresolved markers
+func containsConflictMarkers(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "<<<<<<< ") || strings.HasPrefix(line, ">>>>>>> ") {
+			return true
+		}
+	}
+	return false
+}
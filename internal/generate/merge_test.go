@@ -0,0 +1,264 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreeWayMerge(t *testing.T) {
+	base := "package user\n\ntype User struct {\n\tID string\n}\n\nfunc New() *User {\n\treturn &User{}\n}\n"
+
+	tests := []struct {
+		name          string
+		ours          string
+		theirs        string
+		wantContent   string
+		wantConflicts int
+	}{
+		{
+			name:        "no changes",
+			ours:        base,
+			theirs:      base,
+			wantContent: base,
+		},
+		{
+			name:        "only ours changed",
+			ours:        strings.Replace(base, "return &User{}", "return &User{ID: \"x\"}", 1),
+			theirs:      base,
+			wantContent: strings.Replace(base, "return &User{}", "return &User{ID: \"x\"}", 1),
+		},
+		{
+			name:        "only theirs changed",
+			ours:        base,
+			theirs:      strings.Replace(base, "\tID string\n", "\tID   string\n\tName string\n", 1),
+			wantContent: strings.Replace(base, "\tID string\n", "\tID   string\n\tName string\n", 1),
+		},
+		{
+			name:   "non-overlapping changes on both sides",
+			ours:   strings.Replace(base, "return &User{}", "return &User{ID: \"x\"}", 1),
+			theirs: strings.Replace(base, "\tID string\n", "\tID   string\n\tName string\n", 1),
+			wantContent: strings.Replace(
+				strings.Replace(base, "return &User{}", "return &User{ID: \"x\"}", 1),
+				"\tID string\n", "\tID   string\n\tName string\n", 1),
+		},
+		{
+			name:        "identical changes on both sides",
+			ours:        strings.Replace(base, "package user", "package users", 1),
+			theirs:      strings.Replace(base, "package user", "package users", 1),
+			wantContent: strings.Replace(base, "package user", "package users", 1),
+		},
+		{
+			name:   "conflicting changes",
+			ours:   strings.Replace(base, "return &User{}", "return &User{ID: \"ours\"}", 1),
+			theirs: strings.Replace(base, "return &User{}", "return &User{ID: \"theirs\"}", 1),
+			wantContent: strings.Replace(base, "\treturn &User{}\n",
+				conflictMarkerOurs+"\n\treturn &User{ID: \"ours\"}\n"+
+					conflictMarkerBase+"\n\treturn &User{}\n"+
+					conflictMarkerSep+"\n\treturn &User{ID: \"theirs\"}\n"+
+					conflictMarkerTheirs+"\n", 1),
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ThreeWayMerge(base, tt.ours, tt.theirs)
+			assert.Equal(t, tt.wantContent, result.Content)
+			assert.Equal(t, tt.wantConflicts, result.Conflicts)
+			assert.Equal(t, tt.wantConflicts > 0, result.HasConflicts())
+		})
+	}
+}
+
+func TestThreeWayMerge_MissingTrailingNewline(t *testing.T) {
+	result := ThreeWayMerge("a\nb", "a\nours", "a\ntheirs")
+
+	require.True(t, result.HasConflicts())
+	assert.Equal(t, "a\n"+
+		conflictMarkerOurs+"\nours\n"+
+		conflictMarkerBase+"\nb\n"+
+		conflictMarkerSep+"\ntheirs\n"+
+		conflictMarkerTheirs+"\n", result.Content)
+	assert.True(t, containsConflictMarkers(result.Content))
+}
+
+func TestReconcileHumanEdits(t *testing.T) {
+	outputDir := t.TempDir()
+	base := "package main\n\nfunc a() {}\n\nfunc b() {}\n"
+	human := "package main\n\n// a is hand documented\nfunc a() {}\n\nfunc b() {}\n"
+	regenerated := "package main\n\nfunc a() {}\n\nfunc b() {}\n\nfunc c() {}"
+
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "main.go"), []byte(human), 0600))
+
+	coder := &llmCoder{outputDir: outputDir, mergeStrategy: MergeStrategyMarkers}
+	state := &IncrementalState{
+		GeneratedFiles: map[string]FileState{
+			"main.go": {Path: "main.go", Checksum: ComputeFileChecksum(base), Content: base},
+		},
+	}
+
+	patch := models.Patch{TargetFile: "main.go", Diff: newFileDiff("main.go", regenerated)}
+
+	t.Run("merges hand edit with regenerated code", func(t *testing.T) {
+		merged := coder.reconcileHumanEdits(context.Background(), state, patch)
+		assert.Equal(t,
+			"package main\n\n// a is hand documented\nfunc a() {}\n\nfunc b() {}\n\nfunc c() {}\n",
+			extractContentFromDiff(merged.Diff))
+	})
+
+	t.Run("untouched file is overwritten", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(outputDir, "main.go"), []byte(base), 0600))
+		result := coder.reconcileHumanEdits(context.Background(), state, patch)
+		assert.Equal(t, patch.Diff, result.Diff)
+	})
+
+	t.Run("untracked file is overwritten", func(t *testing.T) {
+		untracked := models.Patch{TargetFile: "other.go", Diff: newFileDiff("other.go", regenerated)}
+		result := coder.reconcileHumanEdits(context.Background(), state, untracked)
+		assert.Equal(t, untracked.Diff, result.Diff)
+	})
+}
+
+// mockMergeLLMClient returns a fixed response for conflict resolution
+type mockMergeLLMClient struct {
+	response string
+	prompts  []string
+}
+
+func (m *mockMergeLLMClient) Generate(_ context.Context, prompt string) (string, error) {
+	m.prompts = append(m.prompts, prompt)
+	return m.response, nil
+}
+
+func (m *mockMergeLLMClient) GenerateStructured(_ context.Context, _ string, _ interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (m *mockMergeLLMClient) Chat(_ context.Context, _ []llm.Message) (string, error) {
+	return m.response, nil
+}
+
+func (m *mockMergeLLMClient) Provider() string { return "mock" }
+func (m *mockMergeLLMClient) Model() string    { return "mock-model" }
+
+func TestReconcileHumanEdits_LLMResolution(t *testing.T) {
+	outputDir := t.TempDir()
+	base := "package main\n\nconst name = \"base\"\n"
+	human := "package main\n\nconst name = \"human\"\n"
+	regenerated := "package main\n\nconst name = \"regenerated\""
+
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "main.go"), []byte(human), 0600))
+
+	state := &IncrementalState{
+		GeneratedFiles: map[string]FileState{
+			"main.go": {Path: "main.go", Checksum: ComputeFileChecksum(base), Content: base},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		response    string
+		wantMarkers bool
+	}{
+		{
+			name:     "resolved by LLM",
+			response: "```go\npackage main\n\nconst name = \"human\"\n```",
+		},
+		{
+			name:        "unresolved response falls back to markers",
+			response:    "<<<<<<< ours\nstill broken\n>>>>>>> theirs",
+			wantMarkers: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockMergeLLMClient{response: tt.response}
+			coder := &llmCoder{client: client, outputDir: outputDir, mergeStrategy: MergeStrategyLLM}
+			patch := models.Patch{TargetFile: "main.go", Diff: newFileDiff("main.go", regenerated)}
+
+			merged := extractContentFromDiff(coder.reconcileHumanEdits(context.Background(), state, patch).Diff)
+
+			require.Len(t, client.prompts, 1)
+			assert.Contains(t, client.prompts[0], conflictMarkerOurs)
+			assert.Equal(t, tt.wantMarkers, containsConflictMarkers(merged))
+			if !tt.wantMarkers {
+				assert.Equal(t, human, merged)
+			}
+		})
+	}
+}
+
+func TestNewCoder_MergeStrategy(t *testing.T) {
+	client := &mockMergeLLMClient{}
+
+	_, err := NewCoder(CoderConfig{LLMClient: client, MergeStrategy: "bogus"})
+	assert.Error(t, err)
+
+	coder, err := NewCoder(CoderConfig{LLMClient: client})
+	require.NoError(t, err)
+	assert.Equal(t, MergeStrategyMarkers, coder.(*llmCoder).mergeStrategy)
+}
+
+func TestRejectingMerge(t *testing.T) {
+	base := "a\nb\nc\nd\ne\nf\n"
+	ours := "a\nB (ours)\nc\nd\ne\nF (ours)\n"
+	theirs := "a\nb\nc\nD (theirs)\ne\nF (theirs)\n"
+
+	content, rejected := RejectingMerge(base, ours, theirs)
+
+	assert.Equal(t, "a\nB (ours)\nc\nD (theirs)\ne\nF (ours)\n", content, "clean changes of both sides merge")
+	assert.Equal(t, []RejectedHunk{{Line: 6, Base: "f\n", Ours: "F (ours)\n", Theirs: "F (theirs)\n"}}, rejected)
+}
+
+func TestRebasePatch_MergesDrift(t *testing.T) {
+	root := t.TempDir()
+	fileOps, err := fsops.New(fsops.Config{RootDir: root})
+	require.NoError(t, err)
+	e := &engine{fileOps: fileOps}
+
+	base := "package store\n\nconst Version = 1\n\nfunc Name() string { return \"store\" }\n\nfunc Close() {}\n"
+	generated := "package store\n\nconst Version = 2\n\nfunc Name() string { return \"store\" }\n\nfunc Close() {}\n\nfunc Size() int { return 0 }\n"
+	patch := models.Patch{
+		TargetFile:   "store.go",
+		Diff:         modifyFileDiff("store.go", base, generated),
+		BaseChecksum: ComputeFileChecksum(base),
+	}
+
+	t.Run("unchanged file", func(t *testing.T) {
+		writeProjectFiles(t, root, map[string]string{"store.go": base})
+		rebased, conflicts, err := e.rebasePatch(context.Background(), patch)
+		require.NoError(t, err)
+		assert.Empty(t, conflicts)
+		applied, err := fsops.NewUnifiedDiffEngine().Apply(base, rebased.Diff)
+		require.NoError(t, err)
+		assert.Equal(t, generated, applied)
+	})
+
+	t.Run("drifted file", func(t *testing.T) {
+		drifted := "package store\n\nconst Version = 3\n\nfunc Name() string { return \"shop\" }\n\nfunc Close() {}\n"
+		writeProjectFiles(t, root, map[string]string{"store.go": drifted})
+		rebased, conflicts, err := e.rebasePatch(context.Background(), patch)
+		require.NoError(t, err)
+		assert.Equal(t, []models.PatchConflict{{
+			Path:     "store.go",
+			Line:     3,
+			Kept:     "const Version = 3\n",
+			Rejected: "const Version = 2\n",
+		}}, conflicts)
+
+		applied, err := fsops.NewUnifiedDiffEngine().Apply(drifted, rebased.Diff)
+		require.NoError(t, err)
+		assert.Equal(t, "package store\n\nconst Version = 3\n\nfunc Name() string { return \"shop\" }\n\nfunc Close() {}\n\nfunc Size() int { return 0 }\n", applied,
+			"the generated function is added and the edits on disk are kept")
+	})
+}
@@ -0,0 +1,266 @@
+package generate
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// migrationsPhase is the plan phase holding the data model's migrations
+const migrationsPhase = "migrations"
+
+// relationKind is how a relationship between two entities is stored
+type relationKind int
+
+const (
+	relationParent relationKind = iota + 1 // To references From
+	relationChild                          // From references To
+	relationJoin                           // A join table references both
+)
+
+// relationKinds maps relationship types, lowercased without separators, to
+// how they are stored
+var relationKinds = map[string]relationKind{
+	"onetomany": relationParent, "hasmany": relationParent, "1n": relationParent, "1m": relationParent,
+	"onetoone": relationParent, "hasone": relationParent, "11": relationParent,
+	"manytoone": relationChild, "belongsto": relationChild, "n1": relationChild, "m1": relationChild,
+	"manytomany": relationJoin, "nm": relationJoin, "mn": relationJoin, "nn": relationJoin, "mm": relationJoin,
+}
+
+// relationKindOf returns how a relationship of a type is stored, so
+// "one-to-many", "has_many" and "1:N" all make To reference From
+func relationKindOf(typ string) relationKind {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(typ) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return relationKinds[sb.String()]
+}
+
+// tables returns the tables of the data model in a dialect, in creation
+// order: the table of each storable entity with the foreign keys its
+// relationships give it, then a join table per pair of entities related
+// many-to-many. A referencing table whose entity has no <entity>_id field
+// gets a nullable column for the key. Tables come after the tables they
+// reference; in a cycle, the first table in data model order loses its
+// references to the others.
+func (r *typeRegistry) tables(dialect models.SQLDialect) []templates.ScaffoldEntity {
+	var stored []templates.ScaffoldEntity
+	index := make(map[string]int, len(r.types))
+	for _, t := range r.types {
+		if entity, ok := r.storedEntity(t, dialect); ok {
+			if dialect == models.DialectMySQL && entity.Fields[0].SQLType == "TEXT" {
+				entity.Fields[0].SQLType = "VARCHAR(255)" // MySQL cannot index TEXT
+			}
+			index[t.entity] = len(stored)
+			stored = append(stored, entity)
+		}
+	}
+	if len(stored) == 0 {
+		return nil
+	}
+
+	lookup := func(name string) (int, bool) {
+		for _, t := range r.types {
+			if strings.EqualFold(t.entity, name) || t.typeName == entityTypeName(name) {
+				i, ok := index[t.entity]
+				return i, ok
+			}
+		}
+		return 0, false
+	}
+
+	var joins []templates.ScaffoldEntity
+	for _, rel := range r.relationships {
+		from, okFrom := lookup(rel.From)
+		to, okTo := lookup(rel.To)
+		if !okFrom || !okTo {
+			continue
+		}
+		switch relationKindOf(rel.Type) {
+		case relationParent:
+			addReference(&stored[to], stored[from])
+		case relationChild:
+			addReference(&stored[from], stored[to])
+		case relationJoin:
+			if join, ok := joinTable(stored[from], stored[to]); ok && !slices.ContainsFunc(joins, func(t templates.ScaffoldEntity) bool {
+				return t.Table == join.Table ||
+					(t.References[0].Table == join.References[1].Table && t.References[1].Table == join.References[0].Table)
+			}) {
+				joins = append(joins, join)
+			}
+		}
+	}
+	return append(orderTables(stored), joins...)
+}
+
+// addReference gives table a foreign key to the key of ref, in the column
+// <ref>_id. A field with that column and the key's Go type takes the key's
+// column type.
+func addReference(table *templates.ScaffoldEntity, ref templates.ScaffoldEntity) {
+	column := models.SnakeCase(ref.Name) + "_id"
+	if slices.ContainsFunc(table.References, func(r templates.ScaffoldReference) bool { return r.Column == column }) {
+		return
+	}
+
+	key := ref.Key()
+	reference := templates.ScaffoldReference{Column: column, Table: ref.Table, RefColumn: key.Column}
+	if i := slices.IndexFunc(table.Fields, func(f templates.ScaffoldField) bool { return f.Column == column }); i < 0 {
+		reference.SQLType = key.SQLType
+	} else if table.Fields[i].Type == key.Type {
+		table.Fields[i].SQLType = key.SQLType
+	}
+	table.References = append(table.References, reference)
+}
+
+// joinTable returns the join table of a many-to-many relationship, e.g.
+// user_roles for User and Role. An entity related to itself has none.
+func joinTable(from, to templates.ScaffoldEntity) (templates.ScaffoldEntity, bool) {
+	if from.Table == to.Table {
+		return templates.ScaffoldEntity{}, false
+	}
+	join := templates.ScaffoldEntity{Table: models.SnakeCase(from.Name) + "_" + to.Table}
+	for _, ref := range []templates.ScaffoldEntity{from, to} {
+		key := ref.Key()
+		join.References = append(join.References, templates.ScaffoldReference{
+			Column:    models.SnakeCase(ref.Name) + "_id",
+			SQLType:   key.SQLType,
+			Table:     ref.Table,
+			RefColumn: key.Column,
+		})
+	}
+	return join, true
+}
+
+// orderTables orders tables after the tables they reference, taking the
+// first table whose references are in place each time. When only tables
+// referencing each other are left, the first loses its references to the
+// rest.
+func orderTables(tables []templates.ScaffoldEntity) []templates.ScaffoldEntity {
+	placed := make(map[string]bool, len(tables))
+	ordered := make([]templates.ScaffoldEntity, 0, len(tables))
+	ready := func(table templates.ScaffoldEntity) bool {
+		for _, ref := range table.References {
+			if ref.Table != table.Table && !placed[ref.Table] {
+				return false
+			}
+		}
+		return true
+	}
+
+	remaining := slices.Clone(tables)
+	for len(remaining) > 0 {
+		i := slices.IndexFunc(remaining, ready)
+		if i < 0 {
+			i = 0
+			table := &remaining[0]
+			table.References = slices.DeleteFunc(slices.Clone(table.References), func(ref templates.ScaffoldReference) bool {
+				return ref.Table != table.Table && !placed[ref.Table]
+			})
+		}
+		placed[remaining[i].Table] = true
+		ordered = append(ordered, remaining[i])
+		remaining = slices.Delete(remaining, i, i+1)
+	}
+	return ordered
+}
+
+// migrationTables returns the tables the planner writes migrations for, none
+// when migrations are off, the FCS fixes the file tree, or the migrations
+// directory is protected or already holds files of the codebase
+func (p *llmPlanner) migrationTables(fcs *models.FinalClarifiedSpecification) []templates.ScaffoldEntity {
+	if p.migrationsDir == "" || fcs == nil || fcs.FileTree != nil {
+		return nil
+	}
+	if _, protected := p.protected.Match(p.migrationsDir + "/000001_create.up.sql"); protected {
+		return nil
+	}
+	if p.codebase != nil && slices.ContainsFunc(p.codebase.Files, func(file string) bool {
+		return path.Dir(file) == p.migrationsDir
+	}) {
+		return nil
+	}
+
+	registry := newTypeRegistry(fcs.DataModel, fcs.BuildConfig.PersistenceStrategy())
+	if registry == nil {
+		return nil
+	}
+	return registry.tables(models.DialectPostgres)
+}
+
+// planMigrations adds golang-migrate migrations of the data model's tables to
+// the plan: an up and a down migration per table in the migrations
+// directory, numbered in creation order, generated in a phase after the
+// others. SQL files the model planned in the directory are replaced.
+func (p *llmPlanner) planMigrations(plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) {
+	tables := p.migrationTables(fcs)
+	if len(tables) == 0 {
+		return
+	}
+
+	dir := p.migrationsDir
+	isMigration := func(target string) bool {
+		target = path.Clean(filepath.ToSlash(target))
+		return path.Dir(target) == dir && strings.HasSuffix(target, ".sql")
+	}
+	plan.FileTree.Files = slices.DeleteFunc(plan.FileTree.Files, func(file models.File) bool { return isMigration(file.Path) })
+	phase := -1
+	for i := range plan.Phases {
+		plan.Phases[i].Tasks = slices.DeleteFunc(plan.Phases[i].Tasks, func(task models.GenerationTask) bool {
+			return task.TargetPath != "" && isMigration(task.TargetPath)
+		})
+		if plan.Phases[i].Name == migrationsPhase {
+			phase = i
+		}
+	}
+	if phase < 0 {
+		plan.Phases = append(plan.Phases, models.GenerationPhase{Name: migrationsPhase, Order: len(plan.Phases) + 1})
+		phase = len(plan.Phases) - 1
+	}
+
+	if !slices.ContainsFunc(plan.FileTree.Directories, func(d models.Directory) bool {
+		return path.Clean(filepath.ToSlash(d.Path)) == dir
+	}) {
+		plan.FileTree.Directories = append(plan.FileTree.Directories, models.Directory{
+			Path:    dir,
+			Purpose: "SQL migrations of the data model in golang-migrate format",
+		})
+	}
+
+	for i, table := range tables {
+		for _, direction := range []string{"up", "down"} {
+			id := fmt.Sprintf("migration_%06d_%s", i+1, direction)
+			purpose := "Creates the " + table.Table + " table"
+			if direction == "down" {
+				purpose = "Drops the " + table.Table + " table"
+			}
+			target := fmt.Sprintf("%s/%06d_create_%s.%s.sql", dir, i+1, table.Table, direction)
+			plan.FileTree.Files = append(plan.FileTree.Files, models.File{Path: target, Purpose: purpose, GeneratedBy: id})
+			plan.Phases[phase].Tasks = append(plan.Phases[phase].Tasks, models.GenerationTask{
+				ID:          id,
+				Type:        "generate_file",
+				TargetPath:  target,
+				CanParallel: true,
+			})
+		}
+	}
+}
+
+// writeMigrationGuidelines tells the model the migrations are planned for it
+func (p *llmPlanner) writeMigrationGuidelines(sb *strings.Builder, fcs *models.FinalClarifiedSpecification) {
+	if len(p.migrationTables(fcs)) == 0 {
+		return
+	}
+
+	sb.WriteString("## Database Migrations\n")
+	sb.WriteString(fmt.Sprintf("- SQL migrations of the Data Model are added to %s/ in golang-migrate format (000001_create_users.up.sql, 000001_create_users.down.sql, ...); do not plan files there\n", p.migrationsDir))
+	sb.WriteString("- Code that sets up the database may apply them with github.com/golang-migrate/migrate\n\n")
+}
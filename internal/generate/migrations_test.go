@@ -0,0 +1,134 @@
+package generate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func migrationsDataModel() models.DataModel {
+	return models.DataModel{
+		Entities: []models.Entity{
+			{Name: "Order", Package: "internal/models", Attributes: map[string]string{"id": "int64", "user_id": "uuid", "total": "decimal"}},
+			{Name: "User", Package: "internal/models", Attributes: map[string]string{"id": "uuid", "email": "string", "team_id": "int"}},
+			{Name: "Team", Package: "internal/models", Attributes: map[string]string{"id": "int", "name": "string"}},
+			{Name: "Role", Package: "internal/models", Attributes: map[string]string{"id": "string", "name": "string"}},
+			{Name: "Note", Package: "internal/models", Attributes: map[string]string{"text": "string"}},
+		},
+		Relationships: []models.Relationship{
+			{From: "Order", To: "User", Type: "belongs_to"},
+			{From: "Team", To: "User", Type: "1:N"},
+			{From: "user", To: "Role", Type: "Many-to-Many"},
+			{From: "Role", To: "User", Type: "many_to_many"},
+			{From: "Note", To: "User", Type: "many-to-one"},
+			{From: "User", To: "Team", Type: "related"},
+		},
+	}
+}
+
+func tableNames(tables []templates.ScaffoldEntity) []string {
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = table.Table
+	}
+	return names
+}
+
+func TestTypeRegistry_Tables(t *testing.T) {
+	registry := newTypeRegistry(migrationsDataModel(), "")
+	tables := registry.tables(models.DialectPostgres)
+	assert.Equal(t, []string{"teams", "users", "orders", "roles", "user_roles"}, tableNames(tables),
+		"referenced tables come first; entities without a key have none")
+
+	assert.Equal(t, []string{
+		"id BIGINT PRIMARY KEY",
+		"total DOUBLE PRECISION NOT NULL",
+		"user_id TEXT NOT NULL",
+		"FOREIGN KEY (user_id) REFERENCES users (id)",
+	}, tables[2].ColumnDefs())
+	assert.Equal(t, []string{
+		"id TEXT PRIMARY KEY",
+		"email TEXT NOT NULL",
+		"team_id BIGINT NOT NULL",
+		"FOREIGN KEY (team_id) REFERENCES teams (id)",
+	}, tables[1].ColumnDefs())
+	assert.Equal(t, []string{
+		"user_id TEXT NOT NULL",
+		"role_id TEXT NOT NULL",
+		"PRIMARY KEY (user_id, role_id)",
+		"FOREIGN KEY (user_id) REFERENCES users (id)",
+		"FOREIGN KEY (role_id) REFERENCES roles (id)",
+	}, tables[4].ColumnDefs())
+
+	mysql := registry.tables(models.DialectMySQL)
+	assert.Equal(t, "id VARCHAR(255) PRIMARY KEY", mysql[1].ColumnDefs()[0], "MySQL keys cannot be TEXT")
+	assert.Equal(t, "user_id VARCHAR(255) NOT NULL", mysql[2].ColumnDefs()[2], "references take the key's type")
+	assert.Equal(t, "total DOUBLE NOT NULL", mysql[2].ColumnDefs()[1])
+	assert.Equal(t, "id INTEGER PRIMARY KEY", registry.tables(models.DialectSQLite)[0].ColumnDefs()[0])
+
+	assert.Nil(t, newTypeRegistry(models.DataModel{Entities: []models.Entity{{Name: "Note", Attributes: map[string]string{"text": "string"}}}}, "").tables(""))
+}
+
+func TestTypeRegistry_TablesAddColumnsAndBreakCycles(t *testing.T) {
+	registry := newTypeRegistry(models.DataModel{
+		Entities: []models.Entity{
+			{Name: "Employee", Attributes: map[string]string{"id": "int64", "name": "string"}},
+			{Name: "Department", Attributes: map[string]string{"id": "int64", "name": "string"}},
+		},
+		Relationships: []models.Relationship{
+			{From: "Department", To: "Employee", Type: "one-to-many"},
+			{From: "Employee", To: "Department", Type: "one-to-one"},
+		},
+	}, "")
+
+	tables := registry.tables(models.DialectPostgres)
+	assert.Equal(t, []string{"employees", "departments"}, tableNames(tables))
+	assert.Equal(t, []string{
+		"id BIGINT PRIMARY KEY",
+		"name TEXT NOT NULL",
+	}, tables[0].ColumnDefs(), "the first table of a cycle drops its reference")
+	assert.Equal(t, []string{
+		"id BIGINT PRIMARY KEY",
+		"name TEXT NOT NULL",
+		"employee_id BIGINT",
+		"FOREIGN KEY (employee_id) REFERENCES employees (id)",
+	}, tables[1].ColumnDefs(), "a missing reference column is added, nullable")
+}
+
+func TestRenderScaffold_Migrations(t *testing.T) {
+	gen, err := templates.NewTemplateGenerator()
+	require.NoError(t, err)
+	coder, err := NewCoder(CoderConfig{LLMClient: &scriptedLLMClient{}, Scaffolds: gen, Dialect: models.DialectSQLite})
+	require.NoError(t, err)
+	c := coder.(*llmCoder)
+	c.SetFCS(&models.FinalClarifiedSpecification{DataModel: migrationsDataModel()})
+	plan := &models.GenerationPlan{}
+
+	up, ok := c.renderScaffold(context.Background(), models.GenerationTask{Type: "generate_file", TargetPath: "migrations/000005_create_user_roles.up.sql"}, plan)
+	require.True(t, ok)
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS user_roles (\n"+
+		"    user_id TEXT NOT NULL,\n"+
+		"    role_id TEXT NOT NULL,\n"+
+		"    PRIMARY KEY (user_id, role_id),\n"+
+		"    FOREIGN KEY (user_id) REFERENCES users (id),\n"+
+		"    FOREIGN KEY (role_id) REFERENCES roles (id)\n"+
+		");\n", up)
+
+	schema, ok := c.renderScaffold(context.Background(), models.GenerationTask{Type: "generate_file", TargetPath: "db/schema.down.sql"}, plan)
+	require.True(t, ok)
+	assert.Equal(t, "DROP TABLE IF EXISTS user_roles;\n\n"+
+		"DROP TABLE IF EXISTS roles;\n\nDROP TABLE IF EXISTS orders;\n\n"+
+		"DROP TABLE IF EXISTS users;\n\nDROP TABLE IF EXISTS teams;\n", schema, "referencing tables are dropped first")
+}
+
+func TestRelationKindOf(t *testing.T) {
+	assert.Equal(t, relationParent, relationKindOf("One-To-Many"))
+	assert.Equal(t, relationParent, relationKindOf("has_one"))
+	assert.Equal(t, relationChild, relationKindOf("N:1"))
+	assert.Equal(t, relationJoin, relationKindOf("M:N"))
+	assert.Zero(t, relationKindOf("uses"))
+}
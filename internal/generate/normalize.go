@@ -0,0 +1,96 @@
+package generate
+
+import (
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// indentWidth is the number of spaces leading tabs expand to, for file types
+// whose syntax forbids tab indentation (YAML) or whose tools render it
+// inconsistently. Other files keep their tabs: Go is gofmt's business and
+// Makefile recipes and shell heredocs (<<-) depend on them.
+var indentWidth = map[string]int{
+	".yaml": 2,
+	".yml":  2,
+	".json": 2,
+	".toml": 2,
+	".md":   4,
+}
+
+// normalizeOutput makes whitespace in a generated file deterministic, so that
+// runs with different models only differ where the code does: line endings
+// become LF, trailing spaces and tabs are stripped, leading tabs are expanded
+// per indentWidth and the file ends in exactly one newline. Raw string
+// literals in Go files keep their trailing whitespace, and Markdown keeps
+// two-space hard line breaks. Normalizing twice changes nothing.
+func normalizeOutput(path, content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+
+	ext := strings.ToLower(filepath.Ext(path))
+	var keep map[int]bool
+	if ext == ".go" {
+		keep = rawStringLines(content)
+	}
+	width := indentWidth[ext]
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if keep[i] {
+			continue
+		}
+		trimmed := strings.TrimRight(line, " \t")
+		if ext == ".md" && strings.TrimSpace(trimmed) != "" && strings.HasSuffix(line, "  ") {
+			trimmed += "  " // Hard line break
+		}
+		if width > 0 {
+			trimmed = expandIndent(trimmed, width)
+		}
+		lines[i] = trimmed
+	}
+
+	content = strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	if content == "" {
+		return ""
+	}
+	return content + "\n"
+}
+
+// expandIndent replaces each tab in a line's leading whitespace with width spaces
+func expandIndent(line string, width int) string {
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	if !strings.Contains(line[:indent], "\t") {
+		return line
+	}
+	return strings.ReplaceAll(line[:indent], "\t", strings.Repeat(" ", width)) + line[indent:]
+}
+
+// rawStringLines returns the (0-based) lines of Go source that end inside a
+// raw string literal, where trailing whitespace is part of the value. Source
+// that does not scan cleanly is scanned as far as possible.
+func rawStringLines(src string) map[int]bool {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(file, []byte(src), func(token.Position, string) {}, 0)
+
+	lines := make(map[int]bool)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.STRING || !strings.HasPrefix(lit, "`") {
+			continue
+		}
+		start := file.Line(pos)
+		end := start + strings.Count(lit, "\n")
+		for line := start; line < end; line++ {
+			lines[line-1] = true
+		}
+	}
+	return lines
+}
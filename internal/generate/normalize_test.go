@@ -0,0 +1,78 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{
+			name:    "line endings",
+			path:    "main.go",
+			content: "package main\r\n\r\nfunc main() {}\r",
+			want:    "package main\n\nfunc main() {}\n",
+		},
+		{
+			name:    "trailing whitespace and blank lines at EOF",
+			path:    "main.go",
+			content: "package main \t\n\nfunc main() {}  \n\n\n",
+			want:    "package main\n\nfunc main() {}\n",
+		},
+		{
+			name:    "Go raw strings keep trailing spaces",
+			path:    "banner.go",
+			content: "package banner\n\nconst Banner = `hello  \nworld \n`  \n",
+			want:    "package banner\n\nconst Banner = `hello  \nworld \n`\n",
+		},
+		{
+			name:    "Go keeps tab indentation",
+			path:    "main.go",
+			content: "package main\n\nfunc main() {\n\tprintln()\n}\n",
+			want:    "package main\n\nfunc main() {\n\tprintln()\n}\n",
+		},
+		{
+			name:    "YAML tabs become spaces",
+			path:    "config/app.YML",
+			content: "server:\n\tport: 8080\n\t\thost: x\t\n",
+			want:    "server:\n  port: 8080\n    host: x\n",
+		},
+		{
+			name:    "Makefile recipes keep tabs",
+			path:    "Makefile",
+			content: "build:\n\tgo build ./... \n",
+			want:    "build:\n\tgo build ./...\n",
+		},
+		{
+			name:    "Markdown hard line breaks",
+			path:    "README.md",
+			content: "line one    \nline two \n\tcode\n  \n",
+			want:    "line one  \nline two\n    code\n",
+		},
+		{
+			name:    "whitespace only",
+			path:    "empty.txt",
+			content: " \n\n",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeOutput(tt.path, tt.content)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, got, normalizeOutput(tt.path, got), "normalizing is idempotent")
+		})
+	}
+}
+
+func TestNewFileDiff_Normalizes(t *testing.T) {
+	diff := newFileDiff("main.go", "package main \r\n\r\n\r\n")
+	assert.Equal(t, "package main\n", extractContentFromDiff(diff))
+}
@@ -0,0 +1,162 @@
+package generate
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+// packageAPI renders the exported declarations of generated source files as
+// Go code with function bodies, unexported struct fields and variable values
+// removed. Files that do not parse are skipped. It returns "" when nothing is
+// exported.
+func packageAPI(patches []models.Patch) string {
+	files := make([]goSource, 0, len(patches))
+	for _, patch := range patches {
+		files = append(files, goSource{path: patch.TargetFile, content: extractContentFromDiff(patch.Diff)})
+	}
+	return sourceAPI(files)
+}
+
+// goSource is one Go file of a package whose API is summarised
+type goSource struct {
+	path    string
+	content string
+}
+
+// sourceAPI renders the exported declarations of one package's files in path
+// order. Test files and files that do not parse are skipped.
+func sourceAPI(files []goSource) string {
+	sorted := append([]goSource(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].path < sorted[j].path })
+
+	fset := token.NewFileSet()
+	var pkgName string
+	var decls []string
+
+	for _, src := range sorted {
+		if !strings.HasSuffix(src.path, ".go") || strings.HasSuffix(src.path, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Base(src.path), src.content, parser.SkipObjectResolution)
+		if err != nil {
+			log.Debug().Err(err).Str("file", src.path).Msg("Skipping unparsable file in package API")
+			continue
+		}
+		if pkgName == "" {
+			pkgName = file.Name.Name
+		}
+
+		for _, decl := range file.Decls {
+			if node := exportedDecl(decl); node != nil {
+				var buf bytes.Buffer
+				if err := printer.Fprint(&buf, fset, node); err == nil {
+					decls = append(decls, buf.String())
+				}
+			}
+		}
+	}
+
+	if len(decls) == 0 {
+		return ""
+	}
+	return "package " + pkgName + "\n\n" + strings.Join(decls, "\n\n") + "\n"
+}
+
+// exportedDecl returns the exported part of a declaration, or nil if it has none
+func exportedDecl(decl ast.Decl) ast.Node {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if !d.Name.IsExported() || (d.Recv != nil && !exportedReceiver(d.Recv)) {
+			return nil
+		}
+		return &ast.FuncDecl{Recv: d.Recv, Name: d.Name, Type: d.Type}
+
+	case *ast.GenDecl:
+		var specs []ast.Spec
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				if s.Name.IsExported() {
+					specs = append(specs, &ast.TypeSpec{Name: s.Name, TypeParams: s.TypeParams, Assign: s.Assign, Type: exportedFields(s.Type)})
+				}
+			case *ast.ValueSpec:
+				if !anyExported(s.Names) {
+					continue
+				}
+				value := &ast.ValueSpec{Names: s.Names, Type: s.Type}
+				if d.Tok == token.CONST {
+					value.Values = s.Values
+				}
+				specs = append(specs, value)
+			}
+		}
+		if len(specs) == 0 {
+			return nil
+		}
+
+		gen := &ast.GenDecl{Tok: d.Tok, Specs: specs}
+		if len(specs) > 1 || d.Lparen.IsValid() {
+			gen.Lparen, gen.Rparen = 1, 1
+		}
+		return gen
+	}
+	return nil
+}
+
+// exportedReceiver reports whether a method belongs to an exported type
+func exportedReceiver(recv *ast.FieldList) bool {
+	if len(recv.List) == 0 {
+		return false
+	}
+	expr := recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.IsExported()
+		default:
+			return false
+		}
+	}
+}
+
+// exportedFields drops unexported named fields from a struct type
+func exportedFields(expr ast.Expr) ast.Expr {
+	st, ok := expr.(*ast.StructType)
+	if !ok || st.Fields == nil {
+		return expr
+	}
+
+	fields := &ast.FieldList{Opening: st.Fields.Opening, Closing: st.Fields.Closing}
+	for _, field := range st.Fields.List {
+		// Embedded fields are kept; their promoted members may be exported
+		if len(field.Names) == 0 || anyExported(field.Names) {
+			fields.List = append(fields.List, &ast.Field{Names: field.Names, Type: field.Type, Tag: field.Tag})
+		}
+	}
+	return &ast.StructType{Struct: st.Struct, Fields: fields}
+}
+
+func anyExported(names []*ast.Ident) bool {
+	for _, name := range names {
+		if name.IsExported() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package generate
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLLMClient records prompts from concurrent callers
+type recordingLLMClient struct {
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (r *recordingLLMClient) Generate(_ context.Context, prompt string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prompts = append(r.prompts, prompt)
+	return "```go\npackage app\n```", nil
+}
+
+func (r *recordingLLMClient) GenerateStructured(_ context.Context, _ string, _ interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (r *recordingLLMClient) Chat(_ context.Context, _ []llm.Message) (string, error) {
+	return "", nil
+}
+
+func (r *recordingLLMClient) Provider() string { return "mock" }
+func (r *recordingLLMClient) Model() string    { return "mock-model" }
+
+const apiSource = `package store
+
+import "sync"
+
+// MaxItems limits the store
+const MaxItems = 10
+
+const internalLimit = 5
+
+// Store holds items
+type Store struct {
+	Name  string
+	items []string
+	mu    sync.Mutex
+}
+
+type cache struct{}
+
+// New creates a store
+func New(name string) *Store {
+	return &Store{Name: name}
+}
+
+// Add stores an item
+func (s *Store) Add(item string) error {
+	s.items = append(s.items, item)
+	return nil
+}
+
+func (s *Store) reset() {}
+
+func (c cache) Get() string { return "" }
+`
+
+func TestPackageAPI(t *testing.T) {
+	api := packageAPI([]models.Patch{
+		{TargetFile: "internal/store/store.go", Diff: newFileDiff("internal/store/store.go", apiSource)},
+		{TargetFile: "internal/store/store_test.go", Diff: newFileDiff("internal/store/store_test.go", "package store\n\nfunc TestHidden() {}\n")},
+		{TargetFile: "internal/store/broken.go", Diff: newFileDiff("internal/store/broken.go", "package store\n\nfunc Broken( {\n")},
+	})
+
+	assert.True(t, strings.HasPrefix(api, "package store\n"))
+	assert.Contains(t, api, "const MaxItems = 10")
+	assert.Contains(t, api, "func New(name string) *Store")
+	assert.Contains(t, api, "func (s *Store) Add(item string) error")
+	assert.Contains(t, api, "Name string")
+
+	for _, hidden := range []string{"internalLimit", "items", "mu ", "cache", "reset", "TestHidden", "Broken", "return"} {
+		assert.NotContains(t, api, hidden)
+	}
+}
+
+func TestPackageAPI_NothingExported(t *testing.T) {
+	assert.Empty(t, packageAPI([]models.Patch{
+		{TargetFile: "main.go", Diff: newFileDiff("main.go", "package main\n\nfunc main() {}\n")},
+	}))
+}
+
+func TestTestPipeline_UsesPackageAPI(t *testing.T) {
+	client := &recordingLLMClient{}
+	tester, err := NewTester(TesterConfig{LLMClient: client, MaxParallel: 2})
+	require.NoError(t, err)
+
+	plan := &models.GenerationPlan{
+		FileTree: models.FileTree{Files: []models.File{
+			{Path: "internal/store/store.go"},
+			{Path: "internal/app/app.go"},
+			{Path: "go.mod"},
+		}},
+	}
+
+	run := tester.StartPipeline(context.Background(), plan, nil)
+	run.AddPackage([]models.Patch{
+		{TargetFile: "internal/store/store.go", Diff: newFileDiff("internal/store/store.go", apiSource)},
+	})
+
+	patches, err := run.Wait()
+	require.NoError(t, err)
+	require.Len(t, patches, 2)
+
+	// Plan order, regardless of which package finished first
+	assert.Equal(t, "internal/store/store_test.go", patches[0].TargetFile)
+	assert.Equal(t, "internal/app/app_test.go", patches[1].TargetFile)
+
+	require.Len(t, client.prompts, 2)
+	var withAPI int
+	for _, prompt := range client.prompts {
+		if strings.Contains(prompt, "# Package API") {
+			withAPI++
+			assert.Contains(t, prompt, "func New(name string) *Store")
+		}
+	}
+	assert.Equal(t, 1, withAPI, "only the added package has a known API")
+}
+
+func TestTestPipeline_Cancelled(t *testing.T) {
+	tester, err := NewTester(TesterConfig{LLMClient: &recordingLLMClient{}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	plan := &models.GenerationPlan{
+		FileTree: models.FileTree{Files: []models.File{{Path: "internal/app/app.go"}}},
+	}
+	_, err = tester.StartPipeline(ctx, plan, nil).Wait()
+	assert.ErrorIs(t, err, context.Canceled)
+}
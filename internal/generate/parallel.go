@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/dshills/gocreator/internal/logctx"
 	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/plangraph"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/sync/errgroup"
 )
@@ -21,6 +24,16 @@ type ParallelGenerationConfig struct {
 	// EnableParallel controls whether parallel generation is enabled
 	// If false, generation will be sequential
 	EnableParallel bool
+
+	// Speculative is experimental. While a level leaves workers idle, they
+	// generate files of the next level against the dependency APIs Predictor
+	// predicts. Each file is given its dependency APIs as the
+	// DependencyAPIInput task input; a speculative file is kept only if its
+	// dependencies end up with the predicted APIs, and is regenerated otherwise.
+	Speculative bool
+
+	// Predictor predicts dependency APIs for speculative generation
+	Predictor SignaturePredictor
 }
 
 // DefaultParallelConfig returns default parallel generation configuration
@@ -35,6 +48,10 @@ func DefaultParallelConfig() ParallelGenerationConfig {
 type ParallelCoder struct {
 	coder  Coder
 	config ParallelGenerationConfig
+
+	// Speculative files kept and discarded
+	speculativeHits   int64
+	speculativeMisses int64
 }
 
 // NewParallelCoder creates a new parallel coder that wraps an existing coder
@@ -42,6 +59,10 @@ func NewParallelCoder(coder Coder, config ParallelGenerationConfig) *ParallelCod
 	if config.MaxParallel <= 0 {
 		config.MaxParallel = 4
 	}
+	if config.Speculative && config.Predictor == nil {
+		log.Warn().Msg("Speculative generation needs a signature predictor, disabling it")
+		config.Speculative = false
+	}
 
 	return &ParallelCoder{
 		coder:  coder,
@@ -52,12 +73,18 @@ func NewParallelCoder(coder Coder, config ParallelGenerationConfig) *ParallelCod
 // Generate creates source code files in parallel based on the generation plan
 // Uses worker pool pattern with bounded concurrency to avoid overwhelming LLM API
 func (pc *ParallelCoder) Generate(ctx context.Context, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) ([]models.Patch, error) {
+	// The task graph is keyed by task ID, so a duplicate would silently
+	// replace another task
+	if err := plan.CheckTaskIDs(); err != nil {
+		return nil, fmt.Errorf("cannot schedule generation plan: %w", err)
+	}
+
 	if !pc.config.EnableParallel {
 		// Fall back to sequential generation
 		return pc.coder.Generate(ctx, plan, fcs)
 	}
 
-	log.Info().
+	logctx.Logger(ctx).Info().
 		Str("plan_id", plan.ID).
 		Int("phases", len(plan.Phases)).
 		Int("max_parallel", pc.config.MaxParallel).
@@ -66,17 +93,17 @@ func (pc *ParallelCoder) Generate(ctx context.Context, plan *models.GenerationPl
 	startTime := time.Now()
 
 	// Build dependency graph from tasks
-	taskGraph := pc.buildDependencyGraph(plan)
+	taskGraph := plangraph.Build(plan)
 
 	// Generate files respecting dependencies
-	patches, err := pc.generateWithDependencies(ctx, plan, fcs, taskGraph)
+	patches, _, err := pc.generateWithDependencies(ctx, plan, fcs, taskGraph)
 	if err != nil {
 		return nil, fmt.Errorf("parallel generation failed: %w", err)
 	}
 
 	duration := time.Since(startTime)
 
-	log.Info().
+	logctx.Logger(ctx).Info().
 		Str("plan_id", plan.ID).
 		Int("files_generated", len(patches)).
 		Dur("duration", duration).
@@ -90,134 +117,9 @@ func (pc *ParallelCoder) GenerateFile(ctx context.Context, task models.Generatio
 	return pc.coder.GenerateFile(ctx, task, plan, fcs)
 }
 
-// taskNode represents a task in the dependency graph
-type taskNode struct {
-	task         models.GenerationTask
-	dependencies []string
-	level        int // Execution level (0 = no dependencies, 1 = depends on level 0, etc.)
-}
-
-// dependencyGraph represents tasks organized by execution level
-type dependencyGraph struct {
-	nodes  map[string]*taskNode
-	levels [][]string // taskIDs organized by level
-}
-
-// buildDependencyGraph creates a dependency graph from the generation plan
-func (pc *ParallelCoder) buildDependencyGraph(plan *models.GenerationPlan) *dependencyGraph {
-	graph := &dependencyGraph{
-		nodes: make(map[string]*taskNode),
-	}
-
-	// Build phase->tasks mapping
-	phaseToTasks := make(map[string][]string)
-	for _, phase := range plan.Phases {
-		for _, task := range phase.Tasks {
-			if task.Type == "generate_file" {
-				phaseToTasks[phase.Name] = append(phaseToTasks[phase.Name], task.ID)
-			}
-		}
-	}
-
-	// Build nodes from phases, resolving phase dependencies to task dependencies
-	for _, phase := range plan.Phases {
-		for _, task := range phase.Tasks {
-			if task.Type == "generate_file" {
-				// Resolve phase dependencies to task dependencies
-				var taskDeps []string
-				for _, depPhaseName := range phase.Dependencies {
-					// Add all tasks from the dependency phase as dependencies
-					if depTasks, exists := phaseToTasks[depPhaseName]; exists {
-						taskDeps = append(taskDeps, depTasks...)
-					}
-				}
-
-				graph.nodes[task.ID] = &taskNode{
-					task:         task,
-					dependencies: taskDeps, // Resolved task-level dependencies
-					level:        -1,       // Will be computed
-				}
-			}
-		}
-	}
-
-	// Compute levels based on resolved task dependencies
-	pc.computeLevels(graph)
-
-	return graph
-}
-
-// computeLevels assigns each task to an execution level based on phase dependencies
-func (pc *ParallelCoder) computeLevels(graph *dependencyGraph) {
-	// Iteratively compute levels
-	maxIterations := len(graph.nodes) + 1
-	for iteration := 0; iteration < maxIterations; iteration++ {
-		changed := false
-
-		for _, node := range graph.nodes {
-			if node.level >= 0 {
-				continue // Already computed
-			}
-
-			// Check if all dependencies have assigned levels
-			maxDepLevel := -1
-			allDepsReady := true
-
-			for _, depID := range node.dependencies {
-				depNode, exists := graph.nodes[depID]
-				if !exists {
-					// Dependency not in graph (might be non-generate_file task or phase name)
-					// Assume it's ready
-					continue
-				}
-
-				if depNode.level < 0 {
-					allDepsReady = false
-					break
-				}
-
-				if depNode.level > maxDepLevel {
-					maxDepLevel = depNode.level
-				}
-			}
-
-			// If all dependencies ready (or no dependencies), assign level
-			if allDepsReady {
-				node.level = maxDepLevel + 1
-				changed = true
-			}
-		}
-
-		if !changed {
-			break
-		}
-	}
-
-	// Organize tasks by level
-	levelMap := make(map[int][]string)
-	for taskID, node := range graph.nodes {
-		if node.level < 0 {
-			node.level = 0 // Fallback for tasks with no computable level
-		}
-		levelMap[node.level] = append(levelMap[node.level], taskID)
-	}
-
-	// Convert to ordered slice
-	maxLevel := 0
-	for level := range levelMap {
-		if level > maxLevel {
-			maxLevel = level
-		}
-	}
-
-	graph.levels = make([][]string, maxLevel+1)
-	for level := 0; level <= maxLevel; level++ {
-		graph.levels[level] = levelMap[level]
-	}
-}
-
-// generateWithDependencies generates files in parallel while respecting dependencies
-func (pc *ParallelCoder) generateWithDependencies(ctx context.Context, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification, graph *dependencyGraph) ([]models.Patch, error) {
+// generateWithDependencies generates files in parallel while respecting
+// dependencies, and returns the patches in order of completion and by task ID
+func (pc *ParallelCoder) generateWithDependencies(ctx context.Context, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification, graph *plangraph.Graph) ([]models.Patch, map[string]models.Patch, error) {
 	var allPatches []models.Patch
 	var patchesMu sync.Mutex
 
@@ -225,30 +127,62 @@ func (pc *ParallelCoder) generateWithDependencies(ctx context.Context, plan *mod
 	completedTasks := make(map[string]bool)
 	var tasksMu sync.RWMutex
 
+	// Generated patches by task, for dependency APIs in speculative mode
+	taskPatches := make(map[string]models.Patch)
+
+	// Files of the next level generated while this level ran
+	speculated := make(map[string]speculation)
+	var speculatedMu sync.Mutex
+
 	// Process each level sequentially, but parallelize within each level
-	for levelIdx, levelTasks := range graph.levels {
+	for levelIdx, levelTasks := range graph.Levels {
 		if len(levelTasks) == 0 {
 			continue
 		}
 
-		log.Debug().
+		logctx.Logger(ctx).Debug().
 			Int("level", levelIdx).
 			Int("tasks", len(levelTasks)).
 			Msg("Processing generation level")
 
+		// Dependencies are all in earlier levels, so their patches are final.
+		// Speculative files generated against the same APIs are kept.
+		generated := make(map[string]models.Patch, len(taskPatches))
+		for id, patch := range taskPatches {
+			generated[id] = patch
+		}
+		var pending []string
+		levelAPIs := make(map[string]map[string]string)
+		for _, taskID := range levelTasks {
+			if pc.config.Speculative {
+				apis, _ := dependencyAPIs(graph, graph.Nodes[taskID], generated, nil)
+				if pc.keepSpeculation(taskID, speculated[taskID], apis) {
+					patch := speculated[taskID].patch
+					allPatches = append(allPatches, patch)
+					taskPatches[taskID] = patch
+					completedTasks[taskID] = true
+					continue
+				}
+				levelAPIs[taskID] = apis
+			}
+			pending = append(pending, taskID)
+		}
+		clear(speculated)
+
 		// Use errgroup for bounded concurrency
 		g, gCtx := errgroup.WithContext(ctx)
 		g.SetLimit(pc.config.MaxParallel)
 
 		// Process all tasks in this level in parallel
-		for _, taskID := range levelTasks {
-			g.Go(func() error {
-				node := graph.nodes[taskID]
+		for _, taskID := range pending {
+			node := graph.Nodes[taskID]
+			apis := levelAPIs[taskID]
 
+			g.Go(func() error {
 				// Verify task dependencies are completed
-				for _, depTaskID := range node.dependencies {
+				for _, depTaskID := range node.Dependencies {
 					// Skip dependencies that are not in the graph (external/non-generate-file tasks)
-					if graph.nodes[depTaskID] == nil {
+					if graph.Nodes[depTaskID] == nil {
 						continue
 					}
 
@@ -261,8 +195,13 @@ func (pc *ParallelCoder) generateWithDependencies(ctx context.Context, plan *mod
 					}
 				}
 
+				task := node.Task
+				if len(apis) > 0 {
+					task = withDependencyAPI(task, apis)
+				}
+
 				// Generate file - call pc.GenerateFile to respect method overrides
-				patch, err := pc.GenerateFile(gCtx, node.task, plan, fcs)
+				patch, err := pc.GenerateFile(logctx.WithTaskID(gCtx, taskID), task, plan, fcs)
 				if err != nil {
 					return fmt.Errorf("failed to generate file for task %s: %w", taskID, err)
 				}
@@ -270,6 +209,7 @@ func (pc *ParallelCoder) generateWithDependencies(ctx context.Context, plan *mod
 				// Store patch
 				patchesMu.Lock()
 				allPatches = append(allPatches, patch)
+				taskPatches[taskID] = patch
 				patchesMu.Unlock()
 
 				// Mark task as completed
@@ -277,9 +217,9 @@ func (pc *ParallelCoder) generateWithDependencies(ctx context.Context, plan *mod
 				completedTasks[taskID] = true
 				tasksMu.Unlock()
 
-				log.Debug().
+				logctx.Logger(ctx).Debug().
 					Str("task_id", taskID).
-					Str("file", node.task.TargetPath).
+					Str("file", node.Task.TargetPath).
 					Int("level", levelIdx).
 					Msg("File generated successfully")
 
@@ -287,18 +227,72 @@ func (pc *ParallelCoder) generateWithDependencies(ctx context.Context, plan *mod
 			})
 		}
 
+		// Workers this level leaves idle start on the next level
+		if pc.config.Speculative && levelIdx+1 < len(graph.Levels) {
+			idle := pc.config.MaxParallel - len(pending)
+			for _, taskID := range graph.Levels[levelIdx+1] {
+				if idle <= 0 {
+					break
+				}
+				node := graph.Nodes[taskID]
+				predicted, ok := dependencyAPIs(graph, node, generated, pc.config.Predictor)
+				if !ok {
+					continue
+				}
+				idle--
+
+				g.Go(func() error {
+					patch, err := pc.GenerateFile(logctx.WithTaskID(gCtx, taskID), withDependencyAPI(node.Task, predicted), plan, fcs)
+					if err != nil {
+						// The file is generated again with the next level
+						logctx.Logger(ctx).Debug().
+							Err(err).
+							Str("task_id", taskID).
+							Msg("Speculative generation failed")
+						return nil
+					}
+					speculatedMu.Lock()
+					speculated[taskID] = speculation{patch: patch, predicted: predicted}
+					speculatedMu.Unlock()
+					return nil
+				})
+			}
+		}
+
 		// Wait for all tasks in this level to complete
 		if err := g.Wait(); err != nil {
-			return allPatches, fmt.Errorf("level %d generation failed: %w", levelIdx, err)
+			return allPatches, taskPatches, fmt.Errorf("level %d generation failed: %w", levelIdx, err)
 		}
 
-		log.Debug().
+		logctx.Logger(ctx).Debug().
 			Int("level", levelIdx).
 			Int("completed", len(levelTasks)).
 			Msg("Level completed successfully")
 	}
 
-	return allPatches, nil
+	return allPatches, taskPatches, nil
+}
+
+// keepSpeculation reports whether a speculative file was generated against
+// the dependency APIs the file has now that its dependencies are generated,
+// counting the outcome. Tasks without a speculative file report false.
+func (pc *ParallelCoder) keepSpeculation(taskID string, spec speculation, apis map[string]string) bool {
+	if spec.predicted == nil {
+		return false
+	}
+	if sameAPIs(spec.predicted, apis) {
+		atomic.AddInt64(&pc.speculativeHits, 1)
+		log.Debug().Str("task_id", taskID).Msg("Kept speculatively generated file")
+		return true
+	}
+	atomic.AddInt64(&pc.speculativeMisses, 1)
+	log.Debug().Str("task_id", taskID).Msg("Dependency APIs differ from the prediction, regenerating file")
+	return false
+}
+
+// SpeculationStats returns how many speculative files were kept and discarded
+func (pc *ParallelCoder) SpeculationStats() (hits, misses int) {
+	return int(atomic.LoadInt64(&pc.speculativeHits)), int(atomic.LoadInt64(&pc.speculativeMisses))
 }
 
 // GenerationStats tracks statistics about parallel generation
@@ -309,6 +303,10 @@ type GenerationStats struct {
 	ActualMaxWorkers int
 	Duration         time.Duration
 	FilesPerSecond   float64
+
+	// SpeculativeHits and SpeculativeMisses count speculative files kept and regenerated
+	SpeculativeHits   int
+	SpeculativeMisses int
 }
 
 // ParallelCoderWithStats wraps ParallelCoder to collect generation statistics
@@ -347,6 +345,7 @@ func (pcs *ParallelCoderWithStats) Generate(ctx context.Context, plan *models.Ge
 		pcs.stats.FilesPerSecond = float64(len(patches)) / pcs.stats.Duration.Seconds()
 	}
 	pcs.stats.ActualMaxWorkers = int(pcs.maxWorkers)
+	pcs.stats.SpeculativeHits, pcs.stats.SpeculativeMisses = pcs.SpeculationStats()
 	pcs.statsMu.Unlock()
 
 	return patches, err
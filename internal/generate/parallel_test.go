@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/plangraph"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -254,6 +258,21 @@ func TestParallelCoder_ErrorHandling(t *testing.T) {
 	}
 }
 
+func TestParallelCoder_DuplicateTaskIDs(t *testing.T) {
+	plan := createSimplePlan(3)
+	plan.Phases[0].Tasks[2].ID = plan.Phases[0].Tasks[0].ID
+
+	for _, enableParallel := range []bool{true, false} {
+		baseCoder := newMockParallelCoder()
+		pc := NewParallelCoder(baseCoder, ParallelGenerationConfig{EnableParallel: enableParallel})
+
+		_, err := pc.Generate(context.Background(), plan, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `duplicate task ID "task_0"`)
+		assert.Zero(t, atomic.LoadInt64(&baseCoder.generateCount), "no file is generated")
+	}
+}
+
 func TestParallelCoder_BoundedConcurrency(t *testing.T) {
 	ctx := context.Background()
 	baseCoder := newMockParallelCoder()
@@ -309,26 +328,22 @@ func TestBuildDependencyGraph(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			baseCoder := newMockParallelCoder()
-			config := DefaultParallelConfig()
-			pc := NewParallelCoder(baseCoder, config)
+			graph := plangraph.Build(tt.plan)
 
-			graph := pc.buildDependencyGraph(tt.plan)
-
-			assert.Equal(t, tt.expectedLevels, len(graph.levels),
+			assert.Equal(t, tt.expectedLevels, len(graph.Levels),
 				"should compute correct number of levels")
 
 			// Verify all nodes have valid levels
-			for _, node := range graph.nodes {
-				assert.GreaterOrEqual(t, node.level, 0,
+			for _, node := range graph.Nodes {
+				assert.GreaterOrEqual(t, node.Level, 0,
 					"all nodes should have valid level")
 			}
 
 			// Verify level ordering respects dependencies
-			for taskID, node := range graph.nodes {
-				for _, depID := range node.dependencies {
-					if depNode, exists := graph.nodes[depID]; exists {
-						assert.Less(t, depNode.level, node.level,
+			for taskID, node := range graph.Nodes {
+				for _, depID := range node.Dependencies {
+					if depNode, exists := graph.Nodes[depID]; exists {
+						assert.Less(t, depNode.Level, node.Level,
 							"dependency %s should be at lower level than %s", depID, taskID)
 					}
 				}
@@ -436,6 +451,174 @@ func TestContextCancellation(t *testing.T) {
 	t.Logf("Generated %d files (error: %v)", len(patches), err)
 }
 
+// apiRecordingCoder generates a User type for model files and records the
+// dependency API each file was generated against
+type apiRecordingCoder struct {
+	mockParallelCoder
+	apis map[string][]string // Task ID -> DependencyAPIInput of each generation
+}
+
+func (c *apiRecordingCoder) GenerateFile(ctx context.Context, task models.GenerationTask, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) (models.Patch, error) {
+	if _, err := c.mockParallelCoder.GenerateFile(ctx, task, plan, fcs); err != nil {
+		return models.Patch{}, err
+	}
+	c.mu.Lock()
+	api, _ := task.Inputs[DependencyAPIInput].(string)
+	c.apis[task.ID] = append(c.apis[task.ID], api)
+	c.mu.Unlock()
+
+	content := "package service\n"
+	if task.ID == "model" {
+		content = "package models\n\n// User is a customer\ntype User struct {\n\tName string\n}\n"
+	}
+	return models.Patch{TargetFile: task.TargetPath, Diff: newFileDiff(task.TargetPath, content)}, nil
+}
+
+type fixedPredictor map[string]string
+
+func (p fixedPredictor) PredictAPI(dir string) (string, bool) {
+	api, ok := p[dir]
+	return api, ok
+}
+
+func TestParallelCoder_Speculative(t *testing.T) {
+	plan := &models.GenerationPlan{
+		ID: "speculative_plan",
+		Phases: []models.GenerationPhase{
+			{Name: "models", Tasks: []models.GenerationTask{
+				{ID: "model", Type: "generate_file", TargetPath: "internal/models/user.go"},
+			}},
+			{Name: "services", Dependencies: []string{"models"}, Tasks: []models.GenerationTask{
+				{ID: "service", Type: "generate_file", TargetPath: "internal/service/user.go"},
+				{ID: "handler", Type: "generate_file", TargetPath: "internal/service/handler.go"},
+			}},
+		},
+	}
+	userAPI := packageAPI([]models.Patch{{
+		TargetFile: "internal/models/user.go",
+		Diff:       newFileDiff("internal/models/user.go", "package models\n\n// User is a customer\ntype User struct {\n\tName string\n}\n"),
+	}})
+
+	tests := []struct {
+		name        string
+		predicted   string
+		hits        int
+		misses      int
+		generations int
+	}{
+		{name: "prediction holds", predicted: userAPI, hits: 2, generations: 3},
+		{name: "prediction differs", predicted: "package models\n\ntype Account struct{}\n", misses: 2, generations: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coder := &apiRecordingCoder{mockParallelCoder: *newMockParallelCoder(), apis: make(map[string][]string)}
+			pc := NewParallelCoderWithStats(coder, ParallelGenerationConfig{
+				MaxParallel:    4,
+				EnableParallel: true,
+				Speculative:    true,
+				Predictor:      fixedPredictor{"internal/models": tt.predicted},
+			})
+
+			patches, err := pc.Generate(context.Background(), plan, nil)
+			require.NoError(t, err)
+			assert.Len(t, patches, 3)
+
+			stats := pc.Stats()
+			assert.Equal(t, tt.hits, stats.SpeculativeHits)
+			assert.Equal(t, tt.misses, stats.SpeculativeMisses)
+			assert.Equal(t, int64(tt.generations), atomic.LoadInt64(&coder.generateCount))
+
+			// The kept service file was generated against the real model API
+			apis := coder.apis["service"]
+			assert.Contains(t, apis[len(apis)-1], "type User struct")
+		})
+	}
+}
+
+func TestParallelCoder_SpeculativeNeedsPredictor(t *testing.T) {
+	coder := newMockParallelCoder()
+	pc := NewParallelCoder(coder, ParallelGenerationConfig{MaxParallel: 4, EnableParallel: true, Speculative: true})
+
+	patches, err := pc.Generate(context.Background(), createPlanWithLinearDeps(3), nil)
+	require.NoError(t, err)
+	assert.Len(t, patches, 3)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&coder.generateCount))
+}
+
+// fileLLMClient answers each file prompt with the content planned for its
+// target file and records the prompts by target file
+type fileLLMClient struct {
+	scriptedLLMClient
+	files   map[string]string
+	byPath  map[string][]string
+	pathsMu sync.Mutex
+}
+
+func (f *fileLLMClient) Generate(_ context.Context, prompt string) (string, error) {
+	const marker = "Generate a Go source file for: "
+	start := strings.Index(prompt, marker)
+	if start < 0 {
+		return "", fmt.Errorf("not a file prompt")
+	}
+	target := prompt[start+len(marker):]
+	target = target[:strings.Index(target, "\n")]
+
+	f.pathsMu.Lock()
+	f.byPath[target] = append(f.byPath[target], prompt)
+	f.pathsMu.Unlock()
+	return f.files[target], nil
+}
+
+func TestCoder_Speculative(t *testing.T) {
+	userFile := "package models\n\n// User is a customer\ntype User struct {\n\tName string\n}\n"
+	plan := &models.GenerationPlan{
+		ID: "speculative_plan",
+		Phases: []models.GenerationPhase{
+			{Name: "models", Tasks: []models.GenerationTask{
+				{ID: "model", Type: "generate_file", TargetPath: "internal/models/user.go"},
+			}},
+			{Name: "services", Dependencies: []string{"models"}, Tasks: []models.GenerationTask{
+				{ID: "service", Type: "generate_file", TargetPath: "internal/service/service.go"},
+			}},
+		},
+	}
+
+	for _, speculative := range []bool{true, false} {
+		t.Run(fmt.Sprintf("speculative=%v", speculative), func(t *testing.T) {
+			// The previous output, whose API the model keeps
+			dir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "internal", "models"), 0o750))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "internal", "models", "user.go"), []byte(userFile), 0o600))
+
+			client := &fileLLMClient{
+				files: map[string]string{
+					"internal/models/user.go":     userFile,
+					"internal/service/service.go": "package service\n\n// Service serves users\ntype Service struct{}\n",
+				},
+				byPath: make(map[string][]string),
+			}
+			coder, err := NewCoder(CoderConfig{LLMClient: client, OutputDir: dir, Speculative: speculative, MaxParallel: 2})
+			require.NoError(t, err)
+
+			patches, err := coder.Generate(context.Background(), plan, nil)
+			require.NoError(t, err)
+			require.Len(t, patches, 2)
+			assert.Equal(t, "internal/models/user.go", patches[0].TargetFile, "patches keep plan order")
+			assert.Equal(t, "internal/service/service.go", patches[1].TargetFile)
+
+			prompts := client.byPath["internal/service/service.go"]
+			require.Len(t, prompts, 1, "a file started early against the kept API is not generated again")
+			if speculative {
+				assert.Contains(t, prompts[0], "# Dependency API")
+				assert.Contains(t, prompts[0], "type User struct")
+			} else {
+				assert.NotContains(t, prompts[0], "# Dependency API")
+			}
+		})
+	}
+}
+
 // Helper functions
 
 func createSimplePlan(numFiles int) *models.GenerationPlan {
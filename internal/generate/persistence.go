@@ -0,0 +1,138 @@
+package generate
+
+import (
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// sqlcFiles are the files rendered for the sqlc persistence strategy, with
+// their purpose
+var sqlcFiles = []models.File{
+	{Path: templates.SQLCConfigPath, Purpose: "sqlc configuration generating the database code into " + templates.SQLCPackageDir},
+	{Path: templates.SQLCSchemaPath, Purpose: "Schema of the data model's tables, read by sqlc"},
+	{Path: templates.SQLCQueriesPath, Purpose: "Queries of the repositories, compiled to Go by sqlc"},
+}
+
+// planPersistence adds what the FCS's persistence strategy needs to the plan.
+// Under sqlc, the sqlc configuration, schema and queries are added to the
+// first phase, and the Go files of postgres packages are marked as rendered
+// from templates, being wrappers around the code sqlc generates.
+func planPersistence(plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) {
+	if fcs.BuildConfig.PersistenceStrategy() != models.PersistenceSQLC {
+		return
+	}
+	if registry := newTypeRegistry(fcs.DataModel, models.PersistenceSQLC); registry == nil || len(registry.tables("")) == 0 {
+		return
+	}
+
+	for i, file := range plan.FileTree.Files {
+		p := path.Clean(filepath.ToSlash(file.Path))
+		if postgresDirs[path.Base(path.Dir(p))] && strings.HasSuffix(p, ".go") && !strings.HasSuffix(p, "_test.go") {
+			plan.FileTree.Files[i].GeneratedBy = "template"
+		}
+	}
+
+	addTemplateFiles(plan, sqlcFiles)
+}
+
+// addTemplateFiles marks files as rendered from templates in the plan's file
+// tree, adding those it lacks, and adds a task for each file no task targets
+// to the first phase
+func addTemplateFiles(plan *models.GenerationPlan, files []models.File) {
+	if len(plan.Phases) == 0 {
+		plan.Phases = append(plan.Phases, models.GenerationPhase{Name: "setup", Order: 1})
+	}
+	first := 0
+	for i, phase := range plan.Phases {
+		if phase.Order < plan.Phases[first].Order {
+			first = i
+		}
+	}
+
+	planned := make(map[string]bool)
+	for _, phase := range plan.Phases {
+		for _, task := range phase.Tasks {
+			planned[path.Clean(filepath.ToSlash(task.TargetPath))] = true
+		}
+	}
+	for _, file := range files {
+		if i := slices.IndexFunc(plan.FileTree.Files, func(f models.File) bool {
+			return path.Clean(filepath.ToSlash(f.Path)) == file.Path
+		}); i >= 0 {
+			plan.FileTree.Files[i].GeneratedBy = "template"
+		} else {
+			file.GeneratedBy = "template"
+			plan.FileTree.Files = append(plan.FileTree.Files, file)
+		}
+		if !planned[file.Path] {
+			plan.Phases[first].Tasks = append(plan.Phases[first].Tasks, models.GenerationTask{
+				ID:          "create_" + strings.NewReplacer("/", "_", ".", "_").Replace(file.Path),
+				Type:        "generate_file",
+				TargetPath:  file.Path,
+				CanParallel: true,
+			})
+		}
+	}
+}
+
+// writePersistenceGuidelines tells the model how the repository layer is
+// implemented under the FCS's persistence strategy
+func writePersistenceGuidelines(sb *strings.Builder, build models.BuildConfig) {
+	switch build.PersistenceStrategy() {
+	case models.PersistenceSQLC:
+		sb.WriteString("## Persistence (sqlc)\n")
+		sb.WriteString("- sqlc.yaml, db/schema.sql and db/queries.sql are added to the plan; do not plan them, nor the Go code sqlc generates into internal/db\n")
+		sb.WriteString("- Plan the repository implementations in a postgres package, e.g. internal/repository/postgres/user_repository.go; they wrap the generated queries\n")
+		sb.WriteString("- Repository constructors take a db.DBTX, which *sql.DB and *sql.Tx satisfy\n\n")
+	case models.PersistenceGORM:
+		sb.WriteString("## Persistence (GORM)\n")
+		sb.WriteString("- Repositories use gorm.io/gorm; entities carry gorm column tags\n")
+		sb.WriteString("- List gorm.io/gorm and the GORM driver of the database (e.g. gorm.io/driver/postgres) as dependencies\n")
+		sb.WriteString("- Code that sets up the database opens a *gorm.DB and passes it to the repository constructors\n\n")
+	case models.PersistenceEnt:
+		sb.WriteString("## Persistence (ent)\n")
+		sb.WriteString("- Plan an ent schema per entity in ent/schema/<entity>.go, and ent/generate.go with the directive //go:generate go run -mod=mod entgo.io/ent/cmd/ent generate ./schema\n")
+		sb.WriteString("- Add a run_command task \"go generate ./ent\" after the schemas; do not plan the client code ent generates\n")
+		sb.WriteString("- Repositories take the generated *ent.Client and convert between ent's types and the entity types\n\n")
+	}
+}
+
+// writePersistenceCodeGuidelines tells the model how a repository file talks
+// to the database under GORM or ent; database/sql needs no guidance and sqlc
+// repositories are rendered from templates
+func (c *llmCoder) writePersistenceCodeGuidelines(sb *strings.Builder, target string) {
+	if c.types == nil || !isRepositoryFile(target) {
+		return
+	}
+
+	switch c.types.persistence {
+	case models.PersistenceGORM:
+		sb.WriteString("## Persistence\n")
+		sb.WriteString("- Implement the repository with gorm.io/gorm over a *gorm.DB taken by its constructor\n")
+		sb.WriteString("- Use the entity types as GORM models; their gorm tags name the columns and the primary key\n")
+		sb.WriteString("- Pass the context with db.WithContext(ctx) and wrap gorm.ErrRecordNotFound when a record does not exist\n\n")
+	case models.PersistenceEnt:
+		sb.WriteString("## Persistence\n")
+		sb.WriteString("- Implement the repository with the ent client generated from ent/schema, taken as *ent.Client by its constructor\n")
+		sb.WriteString("- Convert between ent's generated types and the entity types; do not return ent types from the repository\n")
+		sb.WriteString("- Wrap the error when ent.IsNotFound(err) reports a record does not exist\n\n")
+	}
+}
+
+// isRepositoryFile reports whether a Go file implements repositories: it is
+// in a postgres or repository package, or named after a repository or store
+func isRepositoryFile(target string) bool {
+	target = normalizePath(target)
+	if !strings.HasSuffix(target, ".go") || strings.HasSuffix(target, "_test.go") {
+		return false
+	}
+	dir := path.Base(path.Dir(target))
+	stem := strings.TrimSuffix(path.Base(target), ".go")
+	return postgresDirs[dir] || dir == "repository" || dir == "repositories" || dir == "store" ||
+		strings.HasSuffix(stem, "repository") || strings.HasSuffix(stem, "repo") || strings.HasSuffix(stem, "store")
+}
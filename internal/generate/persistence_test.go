@@ -0,0 +1,142 @@
+package generate
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sqlcTask(id, target string) models.GenerationTask {
+	return models.GenerationTask{ID: id, Type: "generate_file", TargetPath: target}
+}
+
+func TestRenderScaffold_SQLC(t *testing.T) {
+	c, client, plan, fcs := scaffoldFixture(t)
+	fcs.BuildConfig.Persistence = models.PersistenceSQLC
+	c.SetFCS(fcs)
+	ctx := context.Background()
+
+	kind, data, ok := c.scaffoldFile(scaffoldTask(plan, "pg"), plan)
+	require.True(t, ok)
+	assert.Equal(t, templates.ScaffoldSQLC, kind)
+	assert.Contains(t, data.ProjectImports, c.modulePath+"/internal/db")
+
+	pg, ok := c.renderScaffold(ctx, scaffoldTask(plan, "pg"), plan)
+	require.True(t, ok)
+	_, err := parser.ParseFile(token.NewFileSet(), "repository.go", pg, parser.AllErrors)
+	require.NoError(t, err, pg)
+	assert.Contains(t, pg, "func NewUserRepository(conn db.DBTX) *UserRepository")
+	assert.Contains(t, pg, "Status:    string(user.Status),", "enums are converted to the column's type")
+	assert.Contains(t, pg, "Status:    models.UserStatus(row.Status),")
+	assert.Contains(t, pg, "row, err := r.q.GetOrder(ctx, id)")
+	assert.Contains(t, pg, "UserID: order.UserID,", "sqlc spells id as ID")
+	assert.Contains(t, pg, "rows, err := r.q.ListOrders(ctx)")
+
+	queries, ok := c.renderScaffold(ctx, sqlcTask("queries", templates.SQLCQueriesPath), plan)
+	require.True(t, ok)
+	assert.Contains(t, queries, "-- name: CreateUser :exec\nINSERT INTO users (id, created_at, email, status) VALUES ($1, $2, $3, $4);\n")
+	assert.Contains(t, queries, "-- name: UpdateOrder :execrows\nUPDATE orders SET total = $1, user_id = $2 WHERE id = $3;\n")
+	assert.Contains(t, queries, "-- name: ListOrders :many\n")
+
+	config, ok := c.renderScaffold(ctx, sqlcTask("config", templates.SQLCConfigPath), plan)
+	require.True(t, ok)
+	assert.Contains(t, config, `engine: "postgresql"`)
+	assert.Contains(t, config, `out: "internal/db"`)
+	assert.Empty(t, client.prompts)
+
+	c.dialect = models.DialectMySQL
+	queries, _ = c.renderScaffold(ctx, sqlcTask("queries", templates.SQLCQueriesPath), plan)
+	assert.Contains(t, queries, "UPDATE orders SET total = ?, user_id = ? WHERE id = ?;")
+	config, _ = c.renderScaffold(ctx, sqlcTask("config", templates.SQLCConfigPath), plan)
+	assert.Contains(t, config, `engine: "mysql"`)
+}
+
+func TestRenderScaffold_GORMAndEnt(t *testing.T) {
+	c, _, plan, fcs := scaffoldFixture(t)
+	fcs.BuildConfig.Persistence = models.PersistenceGORM
+	c.SetFCS(fcs)
+	ctx := context.Background()
+
+	user, ok := c.renderScaffold(ctx, scaffoldTask(plan, "user"), plan)
+	require.True(t, ok)
+	assert.Contains(t, user, "ID        string     `json:\"id\" gorm:\"column:id;primaryKey\"`")
+	assert.Contains(t, user, "Status    UserStatus `json:\"status\" gorm:\"column:status\"`")
+	assert.Contains(t, user, "Orders    []Order    `json:\"orders\"`", "associations are left to GORM")
+
+	_, ok = c.renderScaffold(ctx, scaffoldTask(plan, "repo"), plan)
+	assert.True(t, ok, "repository interfaces do not depend on the strategy")
+	_, _, ok = c.scaffoldFile(scaffoldTask(plan, "pg"), plan)
+	assert.False(t, ok, "GORM repositories are written by the model")
+	_, _, ok = c.scaffoldFile(sqlcTask("queries", templates.SQLCQueriesPath), plan)
+	assert.False(t, ok)
+
+	var sb strings.Builder
+	c.writePersistenceCodeGuidelines(&sb, "internal/repository/postgres/repository.go")
+	assert.Contains(t, sb.String(), "gorm.io/gorm")
+
+	fcs.BuildConfig.Persistence = models.PersistenceEnt
+	c.SetFCS(fcs)
+	user, _ = c.renderScaffold(ctx, scaffoldTask(plan, "user"), plan)
+	assert.NotContains(t, user, "gorm")
+	_, _, ok = c.scaffoldFile(scaffoldTask(plan, "pg"), plan)
+	assert.False(t, ok, "ent repositories are written by the model")
+
+	sb.Reset()
+	c.writePersistenceCodeGuidelines(&sb, "internal/service/user_service.go")
+	assert.Empty(t, sb.String(), "only repository files are guided")
+	c.writePersistenceCodeGuidelines(&sb, "internal/store/user_store.go")
+	assert.Contains(t, sb.String(), "*ent.Client")
+}
+
+func TestPlanPersistence(t *testing.T) {
+	_, _, _, fcs := scaffoldFixture(t)
+	plan := &models.GenerationPlan{
+		FileTree: models.FileTree{Files: []models.File{
+			{Path: "internal/repository/postgres/user_repository.go"},
+			{Path: "internal/repository/postgres/user_repository_test.go"},
+			{Path: "db/schema.sql"},
+		}},
+		Phases: []models.GenerationPhase{
+			{Name: "core", Order: 2},
+			{Name: "setup", Order: 1, Tasks: []models.GenerationTask{sqlcTask("schema", "db/schema.sql")}},
+		},
+	}
+
+	planPersistence(plan, fcs)
+	assert.Len(t, plan.FileTree.Files, 3, "raw SQL plans nothing")
+
+	fcs.BuildConfig.Persistence = models.PersistenceSQLC
+	planPersistence(plan, fcs)
+	generatedBy := make(map[string]string)
+	for _, file := range plan.FileTree.Files {
+		generatedBy[file.Path] = file.GeneratedBy
+	}
+	assert.Equal(t, map[string]string{
+		"internal/repository/postgres/user_repository.go":      "template",
+		"internal/repository/postgres/user_repository_test.go": "",
+		"db/schema.sql":  "template",
+		"sqlc.yaml":      "template",
+		"db/queries.sql": "template",
+	}, generatedBy)
+
+	var targets []string
+	for _, task := range plan.Phases[1].Tasks {
+		targets = append(targets, task.TargetPath)
+	}
+	assert.Equal(t, []string{"db/schema.sql", "sqlc.yaml", "db/queries.sql"}, targets, "added to the first phase once")
+	assert.Empty(t, plan.Phases[0].Tasks)
+}
+
+func TestSQLCFieldName(t *testing.T) {
+	assert.Equal(t, "ID", sqlcFieldName("id"))
+	assert.Equal(t, "UserID", sqlcFieldName("user_id"))
+	assert.Equal(t, "ApiKey", sqlcFieldName("api_key"))
+	assert.Equal(t, "CreatedAt", sqlcFieldName("created_at"))
+}
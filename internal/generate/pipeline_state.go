@@ -0,0 +1,173 @@
+package generate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PipelinePhase is a macro-phase of the full pipeline
+type PipelinePhase string
+
+const (
+	// PipelinePhaseClarify produces the FCS from the specification
+	PipelinePhaseClarify PipelinePhase = "clarify"
+
+	// PipelinePhaseGenerate writes the project from the FCS
+	PipelinePhaseGenerate PipelinePhase = "generate"
+
+	// PipelinePhaseValidate builds, lints and tests the generated project
+	PipelinePhaseValidate PipelinePhase = "validate"
+)
+
+// pipelinePhases lists the macro-phases in the order they run
+var pipelinePhases = []PipelinePhase{PipelinePhaseClarify, PipelinePhaseGenerate, PipelinePhaseValidate}
+
+// PipelineArtifactFCS names the clarified specification artifact
+const PipelineArtifactFCS = "fcs"
+
+// PipelineState records how far the full pipeline got for an output
+// directory, so a resumed run can skip macro-phases that already finished
+type PipelineState struct {
+	// Version is the state file format version
+	Version string `json:"version"`
+
+	// SpecFile is the specification the pipeline ran on
+	SpecFile string `json:"spec_file"`
+
+	// SpecChecksum is the SHA-256 checksum of the specification content
+	SpecChecksum string `json:"spec_checksum"`
+
+	// LastCompleted is the last macro-phase that finished, empty if none
+	LastCompleted PipelinePhase `json:"last_completed,omitempty"`
+
+	// Artifacts maps artifact names to paths relative to the output directory
+	Artifacts map[string]string `json:"artifacts,omitempty"`
+
+	// UpdatedAt is when the state was last saved
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewPipelineState creates a state for a fresh pipeline run
+func NewPipelineState(specFile string, specContent []byte) *PipelineState {
+	return &PipelineState{
+		Version:      "1.0",
+		SpecFile:     specFile,
+		SpecChecksum: ComputeSpecChecksum(specContent),
+		Artifacts:    make(map[string]string),
+	}
+}
+
+// ComputeSpecChecksum computes the SHA-256 checksum of specification content
+func ComputeSpecChecksum(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+// PipelineStatePath returns the pipeline state file for an output directory
+func PipelineStatePath(outputDir string) string {
+	return filepath.Join(outputDir, ".gocreator", "pipeline.json")
+}
+
+// LoadPipelineState reads the pipeline state for an output directory.
+// It returns nil without error when no state has been saved.
+func LoadPipelineState(outputDir string) (*PipelineState, error) {
+	data, err := os.ReadFile(PipelineStatePath(outputDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline state: %w", err)
+	}
+
+	var state PipelineState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline state: %w", err)
+	}
+	if state.LastCompleted != "" && phaseIndex(state.LastCompleted) < 0 {
+		return nil, fmt.Errorf("pipeline state has unknown phase %q", state.LastCompleted)
+	}
+	if state.Artifacts == nil {
+		state.Artifacts = make(map[string]string)
+	}
+
+	return &state, nil
+}
+
+// Save atomically writes the pipeline state into the output directory
+func (s *PipelineState) Save(outputDir string) error {
+	statePath := PipelineStatePath(outputDir)
+	if err := os.MkdirAll(filepath.Dir(statePath), 0750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	s.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pipeline state: %w", err)
+	}
+
+	tempPath := statePath + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write pipeline state: %w", err)
+	}
+	if err := os.Rename(tempPath, statePath); err != nil {
+		_ = os.Remove(tempPath) // Clean up temp file, ignore error
+		return fmt.Errorf("failed to rename pipeline state: %w", err)
+	}
+
+	log.Debug().
+		Str("path", statePath).
+		Str("last_completed", string(s.LastCompleted)).
+		Msg("Saved pipeline state")
+
+	return nil
+}
+
+// Complete marks phase as finished. Completing a phase again invalidates the
+// phases after it, which must then run again.
+func (s *PipelineState) Complete(phase PipelinePhase) {
+	s.LastCompleted = phase
+}
+
+// Done reports whether phase has finished
+func (s *PipelineState) Done(phase PipelinePhase) bool {
+	if s == nil || s.LastCompleted == "" || phaseIndex(phase) < 0 {
+		return false
+	}
+	return phaseIndex(phase) <= phaseIndex(s.LastCompleted)
+}
+
+// ResumePhase returns the macro-phase a resumed run starts from. Once every
+// phase has finished, validation runs again.
+func (s *PipelineState) ResumePhase() PipelinePhase {
+	if s == nil || s.LastCompleted == "" {
+		return PipelinePhaseClarify
+	}
+	next := phaseIndex(s.LastCompleted) + 1
+	if next >= len(pipelinePhases) {
+		return PipelinePhaseValidate
+	}
+	return pipelinePhases[next]
+}
+
+// Matches reports whether the state was recorded for this specification content
+func (s *PipelineState) Matches(specContent []byte) bool {
+	return s != nil && s.SpecChecksum == ComputeSpecChecksum(specContent)
+}
+
+// phaseIndex returns the position of phase in the pipeline, or -1
+func phaseIndex(phase PipelinePhase) int {
+	for i, p := range pipelinePhases {
+		if p == phase {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,99 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineState_LoadAndSave(t *testing.T) {
+	tempDir := t.TempDir()
+	spec := []byte("name: notes\n")
+
+	state, err := LoadPipelineState(tempDir)
+	require.NoError(t, err)
+	assert.Nil(t, state, "no state before the first save")
+
+	state = NewPipelineState("spec.yaml", spec)
+	state.Artifacts[PipelineArtifactFCS] = filepath.Join(".gocreator", "fcs.json")
+	state.Complete(PipelinePhaseClarify)
+	require.NoError(t, state.Save(tempDir))
+
+	_, err = os.Stat(PipelineStatePath(tempDir) + ".tmp")
+	assert.True(t, os.IsNotExist(err), "temp file is renamed into place")
+
+	loaded, err := LoadPipelineState(tempDir)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, "spec.yaml", loaded.SpecFile)
+	assert.Equal(t, PipelinePhaseClarify, loaded.LastCompleted)
+	assert.Equal(t, filepath.Join(".gocreator", "fcs.json"), loaded.Artifacts[PipelineArtifactFCS])
+	assert.False(t, loaded.UpdatedAt.IsZero())
+	assert.True(t, loaded.Matches(spec))
+	assert.False(t, loaded.Matches([]byte("name: todo\n")))
+}
+
+func TestPipelineState_LoadRejectsUnknownPhase(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, ".gocreator"), 0750))
+	require.NoError(t, os.WriteFile(PipelineStatePath(tempDir), []byte(`{"last_completed":"deploy"}`), 0600))
+
+	_, err := LoadPipelineState(tempDir)
+	assert.ErrorContains(t, err, "unknown phase")
+}
+
+func TestPipelineState_ResumePhase(t *testing.T) {
+	tests := []struct {
+		name       string
+		last       PipelinePhase
+		wantResume PipelinePhase
+		wantDone   []PipelinePhase
+		wantTodo   []PipelinePhase
+	}{
+		{
+			name:       "nothing finished",
+			wantResume: PipelinePhaseClarify,
+			wantTodo:   []PipelinePhase{PipelinePhaseClarify, PipelinePhaseGenerate, PipelinePhaseValidate},
+		},
+		{
+			name:       "clarified",
+			last:       PipelinePhaseClarify,
+			wantResume: PipelinePhaseGenerate,
+			wantDone:   []PipelinePhase{PipelinePhaseClarify},
+			wantTodo:   []PipelinePhase{PipelinePhaseGenerate, PipelinePhaseValidate},
+		},
+		{
+			name:       "generated",
+			last:       PipelinePhaseGenerate,
+			wantResume: PipelinePhaseValidate,
+			wantDone:   []PipelinePhase{PipelinePhaseClarify, PipelinePhaseGenerate},
+			wantTodo:   []PipelinePhase{PipelinePhaseValidate},
+		},
+		{
+			name:       "validated runs validation again",
+			last:       PipelinePhaseValidate,
+			wantResume: PipelinePhaseValidate,
+			wantDone:   []PipelinePhase{PipelinePhaseClarify, PipelinePhaseGenerate, PipelinePhaseValidate},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := NewPipelineState("spec.yaml", nil)
+			if tt.last != "" {
+				state.Complete(tt.last)
+			}
+
+			assert.Equal(t, tt.wantResume, state.ResumePhase())
+			for _, phase := range tt.wantDone {
+				assert.True(t, state.Done(phase), phase)
+			}
+			for _, phase := range tt.wantTodo {
+				assert.False(t, state.Done(phase), phase)
+			}
+		})
+	}
+}
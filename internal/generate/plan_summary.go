@@ -0,0 +1,115 @@
+package generate
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/plangraph"
+)
+
+// PlanSummary is a digest of a generation plan for review before a run
+type PlanSummary struct {
+	ID       string
+	FCSID    string
+	Phases   []PhaseSummary   // In execution order
+	Packages []PackageSummary // Sorted by directory
+	Tasks    int
+	Files    int // Files in the file tree
+	Template int // Files rendered from templates
+	LLM      int // Files written by the LLM
+	Levels   int // Dependency levels of the file tasks, generated one after another
+	Parallel int // File tasks of the widest level, generated at once
+	Estimate *models.PlanEstimate
+}
+
+// PhaseSummary describes one plan phase
+type PhaseSummary struct {
+	Name         string
+	Order        int
+	Dependencies []string
+	TaskTypes    map[string]int // Task count per task type
+	Tasks        int
+}
+
+// PackageSummary lists the planned files of one directory
+type PackageSummary struct {
+	Dir      string
+	Files    []PlannedFile
+	Template int
+	LLM      int
+}
+
+// PlannedFile is a file from the plan's file tree
+type PlannedFile struct {
+	Path     string
+	Template bool // Rendered from a template rather than written by the LLM
+}
+
+// SummarizePlan digests plan into phases, packages and the template/LLM split,
+// with token estimates from EstimatePlan. fcs is optional; without it the
+// input token estimate leaves out the specification.
+func SummarizePlan(plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification, cfg EstimateConfig) *PlanSummary {
+	summary := &PlanSummary{
+		ID:       plan.ID,
+		FCSID:    plan.FCSID,
+		Estimate: EstimatePlan(plan, fcs, cfg),
+	}
+
+	for _, phase := range plan.Phases {
+		ps := PhaseSummary{
+			Name:         phase.Name,
+			Order:        phase.Order,
+			Dependencies: phase.Dependencies,
+			TaskTypes:    make(map[string]int),
+			Tasks:        len(phase.Tasks),
+		}
+		for _, task := range phase.Tasks {
+			ps.TaskTypes[task.Type]++
+		}
+		summary.Phases = append(summary.Phases, ps)
+		summary.Tasks += ps.Tasks
+	}
+	sort.SliceStable(summary.Phases, func(i, j int) bool {
+		return summary.Phases[i].Order < summary.Phases[j].Order
+	})
+
+	if graph := plangraph.Build(plan); len(graph.Nodes) > 0 {
+		summary.Levels = len(graph.Levels)
+		summary.Parallel = graph.Width()
+	}
+
+	packages := make(map[string]*PackageSummary)
+	for _, file := range plan.FileTree.Files {
+		p := filepath.ToSlash(filepath.Clean(file.Path))
+		planned := PlannedFile{
+			Path:     p,
+			Template: file.GeneratedBy == "template" || FileOwnerOf(p) == FileOwnerTemplate,
+		}
+
+		dir := path.Dir(p)
+		pkg, ok := packages[dir]
+		if !ok {
+			pkg = &PackageSummary{Dir: dir}
+			packages[dir] = pkg
+		}
+		pkg.Files = append(pkg.Files, planned)
+		if planned.Template {
+			pkg.Template++
+			summary.Template++
+		} else {
+			pkg.LLM++
+			summary.LLM++
+		}
+		summary.Files++
+	}
+
+	for _, pkg := range packages {
+		sort.Slice(pkg.Files, func(i, j int) bool { return pkg.Files[i].Path < pkg.Files[j].Path })
+		summary.Packages = append(summary.Packages, *pkg)
+	}
+	sort.Slice(summary.Packages, func(i, j int) bool { return summary.Packages[i].Dir < summary.Packages[j].Dir })
+
+	return summary
+}
@@ -0,0 +1,59 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizePlan(t *testing.T) {
+	plan := &models.GenerationPlan{
+		ID:    "plan-1",
+		FCSID: "fcs-1",
+		Phases: []models.GenerationPhase{
+			{Name: "core", Order: 2, Dependencies: []string{"setup"}, Tasks: []models.GenerationTask{
+				{ID: "t2", Type: "generate_file", TargetPath: "internal/app/app.go"},
+				{ID: "t3", Type: "run_command"},
+			}},
+			{Name: "setup", Order: 1, Tasks: []models.GenerationTask{
+				{ID: "t1", Type: "generate_file", TargetPath: "go.mod"},
+			}},
+		},
+		FileTree: models.FileTree{Files: []models.File{
+			{Path: "go.mod"},
+			{Path: "internal/app/app.go"},
+			{Path: "internal/app/schema.sql", GeneratedBy: "template"},
+			{Path: "./internal/app/store.go"},
+		}},
+	}
+
+	summary := SummarizePlan(plan, nil, EstimateConfig{Model: "m"})
+
+	assert.Equal(t, "plan-1", summary.ID)
+	require.Len(t, summary.Phases, 2)
+	assert.Equal(t, "setup", summary.Phases[0].Name, "phases are in execution order")
+	assert.Equal(t, []string{"setup"}, summary.Phases[1].Dependencies)
+	assert.Equal(t, map[string]int{"generate_file": 1, "run_command": 1}, summary.Phases[1].TaskTypes)
+	assert.Equal(t, 3, summary.Tasks)
+	assert.Equal(t, 2, summary.Levels, "app.go is generated after go.mod")
+	assert.Equal(t, 1, summary.Parallel)
+
+	assert.Equal(t, 4, summary.Files)
+	assert.Equal(t, 2, summary.Template)
+	assert.Equal(t, 2, summary.LLM)
+	require.Len(t, summary.Packages, 2)
+	assert.Equal(t, ".", summary.Packages[0].Dir)
+	assert.Equal(t, []PlannedFile{{Path: "go.mod", Template: true}}, summary.Packages[0].Files)
+	assert.Equal(t, "internal/app", summary.Packages[1].Dir)
+	assert.Equal(t, []PlannedFile{
+		{Path: "internal/app/app.go"},
+		{Path: "internal/app/schema.sql", Template: true},
+		{Path: "internal/app/store.go"},
+	}, summary.Packages[1].Files)
+
+	require.NotNil(t, summary.Estimate)
+	assert.Equal(t, "m", summary.Estimate.Model)
+	assert.Positive(t, summary.Estimate.Total().OutputTokens)
+}
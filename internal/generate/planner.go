@@ -3,14 +3,19 @@ package generate
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/dshills/gocreator/internal/logctx"
 	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/promptguard"
+	"github.com/dshills/gocreator/internal/schema"
 	"github.com/dshills/gocreator/pkg/llm"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
 )
 
 // Planner creates generation plans from FCS
@@ -21,12 +26,48 @@ type Planner interface {
 
 // llmPlanner implements Planner using an LLM to analyze the FCS and create a plan
 type llmPlanner struct {
-	client llm.Client
+	client     llm.Client
+	limits     models.PlanLimits
+	layout     models.FileLayout
+	protected  models.ProtectedPaths
+	codebase   *models.Codebase
+	maxReplans int
+	preamble   string
+
+	// Directory the data model's migrations are planned in, "" for none
+	migrationsDir string
 }
 
+// DefaultMaxReplans is the number of simplification attempts when a plan exceeds its limits
+const DefaultMaxReplans = 2
+
 // PlannerConfig contains configuration for creating a planner
 type PlannerConfig struct {
 	LLMClient llm.Client
+
+	// Limits bounds the file tree of generated plans (zero fields are unlimited)
+	Limits models.PlanLimits
+
+	// Layout sets how source is split into files (zero lets the LLM decide)
+	Layout models.FileLayout
+
+	// Protected lists output paths people own; plans targeting them are re-planned
+	Protected models.ProtectedPaths
+
+	// Codebase is the existing module generation adds to (optional). Plans
+	// modify its files with apply_patch tasks and follow its package layout.
+	Codebase *models.Codebase
+
+	// MaxReplans caps re-planning requests when Limits are exceeded.
+	// Zero uses DefaultMaxReplans; negative disables re-planning.
+	MaxReplans int
+
+	// Preamble is organization-wide guidance placed ahead of every prompt
+	Preamble string
+
+	// MigrationsDir is the directory the plan gets golang-migrate migrations
+	// of the data model's tables in (optional)
+	MigrationsDir string
 }
 
 // NewPlanner creates a new Planner instance
@@ -35,14 +76,40 @@ func NewPlanner(cfg PlannerConfig) (Planner, error) {
 		return nil, fmt.Errorf("LLM client is required")
 	}
 
+	maxReplans := cfg.MaxReplans
+	switch {
+	case maxReplans == 0:
+		maxReplans = DefaultMaxReplans
+	case maxReplans < 0:
+		maxReplans = 0
+	}
+
 	return &llmPlanner{
-		client: cfg.LLMClient,
+		client:     cfg.LLMClient,
+		limits:     cfg.Limits,
+		layout:     cfg.Layout,
+		protected:  cfg.Protected,
+		codebase:   cfg.Codebase,
+		maxReplans: maxReplans,
+		preamble:   cfg.Preamble,
+
+		migrationsDir: migrationsDir(cfg.MigrationsDir),
 	}, nil
 }
 
+// migrationsDir returns the configured migrations directory, slash-separated
+// and relative to the output directory
+func migrationsDir(dir string) string {
+	dir = strings.Trim(path.Clean(filepath.ToSlash(dir)), "/")
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
 // Plan creates a detailed generation plan from an FCS
 func (p *llmPlanner) Plan(ctx context.Context, fcs *models.FinalClarifiedSpecification) (*models.GenerationPlan, error) {
-	log.Info().
+	logctx.Logger(ctx).Info().
 		Str("fcs_id", fcs.ID).
 		Msg("Starting generation plan creation")
 
@@ -59,8 +126,14 @@ func (p *llmPlanner) Plan(ctx context.Context, fcs *models.FinalClarifiedSpecifi
 		return nil, fmt.Errorf("generated plan is invalid: %w", err)
 	}
 
+	// Ask the LLM to rework plans that exceed the size limits or break the file layout
+	plan, err = p.enforceLimits(ctx, fcs, plan)
+	if err != nil {
+		return nil, err
+	}
+
 	duration := time.Since(startTime)
-	log.Info().
+	logctx.Logger(ctx).Info().
 		Str("plan_id", plan.ID).
 		Str("fcs_id", fcs.ID).
 		Int("phases", len(plan.Phases)).
@@ -73,7 +146,7 @@ func (p *llmPlanner) Plan(ctx context.Context, fcs *models.FinalClarifiedSpecifi
 
 // generatePlan uses the LLM to analyze the FCS and create a generation plan
 func (p *llmPlanner) generatePlan(ctx context.Context, fcs *models.FinalClarifiedSpecification) (*models.GenerationPlan, error) {
-	log.Debug().
+	logctx.Logger(ctx).Debug().
 		Str("fcs_id", fcs.ID).
 		Msg("Sending planning request to LLM")
 
@@ -81,9 +154,17 @@ func (p *llmPlanner) generatePlan(ctx context.Context, fcs *models.FinalClarifie
 	var response string
 	var err error
 
-	if cacheableClient, ok := p.client.(llm.CacheableClient); ok {
+	if llm.SupportsStructuredOutput(p.client) {
+		// The client returns JSON validated against the plan response schema
+		logctx.Logger(ctx).Debug().
+			Str("provider", p.client.Provider()).
+			Str("fcs_id", fcs.ID).
+			Msg("Requesting the plan as structured output")
+
+		response, err = p.requestPlan(ctx, p.buildPlanningPrompt(fcs))
+	} else if cacheableClient, ok := p.client.(llm.CacheableClient); ok {
 		// Client supports caching - use cached prompts
-		log.Debug().
+		logctx.Logger(ctx).Debug().
 			Str("provider", p.client.Provider()).
 			Str("fcs_id", fcs.ID).
 			Msg("Using prompt caching for planning")
@@ -93,7 +174,7 @@ func (p *llmPlanner) generatePlan(ctx context.Context, fcs *models.FinalClarifie
 	} else {
 		// Client doesn't support caching - use standard generation
 		prompt := p.buildPlanningPrompt(fcs)
-		log.Debug().
+		logctx.Logger(ctx).Debug().
 			Str("provider", p.client.Provider()).
 			Str("fcs_id", fcs.ID).
 			Int("prompt_length", len(prompt)).
@@ -106,7 +187,7 @@ func (p *llmPlanner) generatePlan(ctx context.Context, fcs *models.FinalClarifie
 		return nil, fmt.Errorf("LLM planning request failed: %w", err)
 	}
 
-	log.Debug().
+	logctx.Logger(ctx).Debug().
 		Str("fcs_id", fcs.ID).
 		Int("response_length", len(response)).
 		Msg("Received planning response from LLM")
@@ -116,76 +197,361 @@ func (p *llmPlanner) generatePlan(ctx context.Context, fcs *models.FinalClarifie
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse plan response: %w", err)
 	}
+	normalizePlan(ctx, plan)
 
 	// Set plan metadata
 	plan.ID = uuid.New().String()
 	plan.FCSID = fcs.ID
-	plan.SchemaVersion = "1.0"
+	plan.SchemaVersion = models.PlanSchemaVersion
 	plan.CreatedAt = time.Now()
 
 	return plan, nil
 }
 
-// buildPlanningPrompt constructs the LLM prompt for planning
-func (p *llmPlanner) buildPlanningPrompt(fcs *models.FinalClarifiedSpecification) string {
+// requestPlan sends a planning prompt and returns the plan's JSON. Clients
+// with structured output return it validated against the plan response
+// schema; other clients return text that may wrap it in markdown.
+func (p *llmPlanner) requestPlan(ctx context.Context, prompt string) (string, error) {
+	if !llm.SupportsStructuredOutput(p.client) {
+		return p.client.Generate(ctx, prompt)
+	}
+	output, err := p.client.GenerateStructured(ctx, prompt, schema.PlanResponse())
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(output)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode structured plan: %w", err)
+	}
+	return string(data), nil
+}
+
+// normalizePlan repairs duplicate or missing task IDs and gaps in the phase
+// order of an LLM-produced plan, which would otherwise break the task graph
+func normalizePlan(ctx context.Context, plan *models.GenerationPlan) {
+	if changes := plan.Normalize(); len(changes) > 0 {
+		logctx.Logger(ctx).Warn().
+			Strs("changes", changes).
+			Msg("Normalized phase orders and task IDs of the generation plan")
+	}
+}
+
+// checkPlan combines size limit, file layout, protected path, middleware
+// package, file tree and existing codebase violations into one
+// *models.PlanLimitError
+func (p *llmPlanner) checkPlan(fcs *models.FinalClarifiedSpecification, plan *models.GenerationPlan) error {
+	var violations []string
+	for _, err := range []error{
+		plan.CheckLimits(p.limits),
+		plan.CheckFileLayout(p.layout, fcs.DataModel.Entities),
+		plan.CheckProtectedPaths(p.protected),
+		plan.CheckMiddlewarePackage(fcs.CrossCutting),
+		plan.CheckFileTree(fcs.FileTree),
+		plan.CheckCodebase(p.codebase),
+	} {
+		if err == nil {
+			continue
+		}
+		var limitErr *models.PlanLimitError
+		if !errors.As(err, &limitErr) {
+			return err
+		}
+		violations = append(violations, limitErr.Violations...)
+	}
+
+	if len(violations) > 0 {
+		return &models.PlanLimitError{Violations: violations}
+	}
+	return nil
+}
+
+// enforceLimits re-plans until the plan fits within the configured limits and file layout
+func (p *llmPlanner) enforceLimits(ctx context.Context, fcs *models.FinalClarifiedSpecification, plan *models.GenerationPlan) (*models.GenerationPlan, error) {
+	for attempt := 1; ; attempt++ {
+		limitErr := p.checkPlan(fcs, plan)
+		if limitErr == nil {
+			return plan, nil
+		}
+
+		var violations *models.PlanLimitError
+		if !errors.As(limitErr, &violations) || attempt > p.maxReplans {
+			return nil, fmt.Errorf("generated plan still violates its limits after %d re-planning attempts: %w", attempt-1, limitErr)
+		}
+
+		logctx.Logger(ctx).Warn().
+			Str("fcs_id", fcs.ID).
+			Int("attempt", attempt).
+			Strs("violations", violations.Violations).
+			Msg("Generation plan exceeds limits, re-planning")
+
+		response, err := p.requestPlan(ctx, p.buildReplanPrompt(fcs, plan, violations.Violations))
+		if err != nil {
+			return nil, fmt.Errorf("LLM re-planning request failed: %w", err)
+		}
+
+		simplified, err := p.parsePlanResponse(response, fcs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse re-planned response: %w", err)
+		}
+		normalizePlan(ctx, simplified)
+		if err := simplified.Validate(); err != nil {
+			return nil, fmt.Errorf("re-planned plan is invalid: %w", err)
+		}
+
+		simplified.ID = plan.ID
+		simplified.FCSID = plan.FCSID
+		simplified.SchemaVersion = plan.SchemaVersion
+		simplified.CreatedAt = time.Now()
+		plan = simplified
+	}
+}
+
+// buildReplanPrompt asks the LLM to simplify a plan that exceeded its limits
+func (p *llmPlanner) buildReplanPrompt(fcs *models.FinalClarifiedSpecification, plan *models.GenerationPlan, violations []string) string {
 	var sb strings.Builder
 
-	sb.WriteString("You are an expert Go architect creating a detailed generation plan for a Go project.\n\n")
-	sb.WriteString("# Task\n")
-	sb.WriteString("Analyze the following Final Clarified Specification and create a comprehensive generation plan.\n\n")
+	sb.WriteString(p.buildPlanningPrompt(fcs))
+	sb.WriteString("\n# Plan Simplification Required\n\n")
+	sb.WriteString(fmt.Sprintf("Your previous plan listed %d files and exceeded these limits:\n", len(plan.FileTree.Files)))
+	for _, v := range violations {
+		sb.WriteString(fmt.Sprintf("- %s\n", v))
+	}
+	sb.WriteString("\nCreate a simpler plan that satisfies every limit. Merge closely related files, flatten deep ")
+	sb.WriteString("directory nesting, and combine small packages instead of dropping required functionality.\n")
+	if p.layout.Strategy != models.FileSplitAuto {
+		sb.WriteString("Keep to the File Layout rules above when moving entities between files.\n")
+	}
+	if len(p.protected) > 0 {
+		sb.WriteString("Move any file out of the Protected Paths above; do not drop it if it is required.\n")
+	}
+	if fcs.CrossCutting.Enabled() {
+		sb.WriteString("Keep the middleware package from the Cross-Cutting Concerns above.\n")
+	}
+	if fcs.FileTree != nil {
+		sb.WriteString("Keep the Required File Tree above unchanged and plan a generate_file task for every Go file in it.\n")
+	}
+	if p.codebase != nil {
+		sb.WriteString("Modify files of the Existing Codebase above only with apply_patch tasks, and keep new files in its package structure.\n")
+	}
+	sb.WriteString("Return ONLY the JSON plan, no additional text or explanation.\n")
 
-	// Include FCS details
-	sb.WriteString("# Final Clarified Specification\n\n")
+	return sb.String()
+}
 
-	// Requirements
-	sb.WriteString("## Requirements\n")
-	sb.WriteString("### Functional Requirements\n")
-	for _, req := range fcs.Requirements.Functional {
-		sb.WriteString(fmt.Sprintf("- %s: %s (Priority: %s)\n", req.ID, req.Description, req.Priority))
+// writeLimitGuidelines describes the configured plan limits, if any
+func (p *llmPlanner) writeLimitGuidelines(sb *strings.Builder) {
+	if p.limits.IsZero() {
+		return
+	}
+
+	sb.WriteString("## Plan Size Limits\n")
+	if p.limits.MaxFiles > 0 {
+		sb.WriteString(fmt.Sprintf("- At most %d files in the file tree\n", p.limits.MaxFiles))
+	}
+	if p.limits.MaxDirectories > 0 {
+		sb.WriteString(fmt.Sprintf("- At most %d directories\n", p.limits.MaxDirectories))
+	}
+	if p.limits.MaxDepth > 0 {
+		sb.WriteString(fmt.Sprintf("- Directories nested at most %d levels deep\n", p.limits.MaxDepth))
+	}
+	if p.limits.MaxFilesPerPackage > 0 {
+		sb.WriteString(fmt.Sprintf("- At most %d non-test .go files per package\n", p.limits.MaxFilesPerPackage))
 	}
 	sb.WriteString("\n")
+}
 
-	// Architecture
-	sb.WriteString("## Architecture\n")
-	sb.WriteString("### Packages\n")
-	for _, pkg := range fcs.Architecture.Packages {
-		sb.WriteString(fmt.Sprintf("- %s (%s): %s\n", pkg.Name, pkg.Path, pkg.Purpose))
-		if len(pkg.Dependencies) > 0 {
-			sb.WriteString(fmt.Sprintf("  Dependencies: %s\n", strings.Join(pkg.Dependencies, ", ")))
-		}
+// writeProtectedGuidelines lists output paths the plan must not touch, if any
+func (p *llmPlanner) writeProtectedGuidelines(sb *strings.Builder) {
+	if len(p.protected) == 0 {
+		return
+	}
+
+	sb.WriteString("## Protected Paths\n")
+	sb.WriteString("These paths in the output directory are maintained by people. Do not place files or directories in them:\n")
+	for _, pattern := range p.protected {
+		sb.WriteString(fmt.Sprintf("- %s\n", pattern))
 	}
 	sb.WriteString("\n")
+}
 
-	// Dependencies
-	if len(fcs.Architecture.Dependencies) > 0 {
-		sb.WriteString("### External Dependencies\n")
-		for _, dep := range fcs.Architecture.Dependencies {
-			sb.WriteString(fmt.Sprintf("- %s %s: %s\n", dep.Name, dep.Version, dep.Purpose))
+// writeCrossCuttingGuidelines asks for a middleware package and router wiring
+// for the cross-cutting concerns, if any
+func writeCrossCuttingGuidelines(sb *strings.Builder, cc models.CrossCutting) {
+	if !cc.Enabled() {
+		return
+	}
+
+	funcs := make([]string, 0, len(cc.Concerns))
+	for _, concern := range cc.Ordered() {
+		funcs = append(funcs, concern.Func())
+	}
+
+	sb.WriteString("## Cross-Cutting Concerns\n")
+	sb.WriteString("The specification lists concerns that apply to every HTTP handler. Plan them once as middleware ")
+	sb.WriteString("instead of inside each handler or requirement:\n")
+	sb.WriteString(fmt.Sprintf("- Add a Go file in package %s (e.g. %s/middleware.go)\n",
+		promptguard.Inline(cc.MiddlewarePackage()), promptguard.Inline(cc.MiddlewarePackage())))
+	sb.WriteString(fmt.Sprintf("- It declares one func(http.Handler) http.Handler per concern: %s\n", strings.Join(funcs, ", ")))
+	sb.WriteString(fmt.Sprintf("- The file that registers routes wraps every handler in that order, outermost first: %s\n", strings.Join(funcs, ", ")))
+	sb.WriteString("- Plan a test file for the middleware package\n\n")
+}
+
+// writeGRPCGuidelines asks for a .proto file per gRPC service, the buf
+// configuration compiling them, and a server wired into main, if any
+func writeGRPCGuidelines(sb *strings.Builder, fcs *models.FinalClarifiedSpecification) {
+	services := fcs.GRPCServices()
+	if len(services) == 0 {
+		return
+	}
+
+	sb.WriteString("## gRPC Services\n")
+	sb.WriteString("The specification declares gRPC services. Plan them as Protocol Buffers definitions compiled with buf:\n")
+	for _, service := range services {
+		sb.WriteString(fmt.Sprintf("- %s: a generate_file task for %s, and a Go file implementing %sServer from package %s\n",
+			promptguard.Inline(service.FullName()), promptguard.Inline(service.ProtoPath()),
+			promptguard.Inline(service.Name), promptguard.Inline(service.GoPackageDir())))
+	}
+	sb.WriteString("- Mark buf.yaml and buf.gen.yaml with generated_by=\"template\" at the project root\n")
+	sb.WriteString("- Do not plan the *.pb.go files under gen/: add a run_command task \"buf generate\" after the .proto files instead\n")
+	sb.WriteString("- Each server struct embeds the generated Unimplemented<Service>Server and calls the service layer\n")
+	sb.WriteString("- main creates a grpc.Server, registers every service with its generated Register<Service>Server function and serves on a configurable port\n")
+	sb.WriteString("- Plan a test file for every server, using the generated client over bufconn\n\n")
+}
+
+// writeModuleGuidelines asks for the packages of a multi-module project to be
+// placed in their modules, if it has several
+func writeModuleGuidelines(sb *strings.Builder, build models.BuildConfig) {
+	if !build.IsMultiModule() {
+		return
+	}
+
+	sb.WriteString("## Go Modules\n")
+	sb.WriteString("The project is a workspace of several Go modules tied together by go.work at the project root:\n")
+	for _, module := range build.Modules {
+		sb.WriteString(fmt.Sprintf("- %s in %s/", promptguard.Inline(module.Path), promptguard.Inline(module.Dir)))
+		if len(module.Requires) > 0 {
+			sb.WriteString(fmt.Sprintf(", requiring %s", promptguard.Inline(strings.Join(module.Requires, ", "))))
 		}
 		sb.WriteString("\n")
 	}
+	sb.WriteString("- Plan a generate_file task for every module's go.mod in the setup phase; mark each go.mod and go.work with generated_by=\"template\"\n")
+	sb.WriteString("- Do not plan a go.mod at the project root\n")
+	sb.WriteString("- Place every package, main package and test inside the directory of the module it belongs to\n")
+	sb.WriteString("- Import packages of another module by that module's path, and only from modules it requires\n\n")
+}
 
-	// Data Model
-	if len(fcs.DataModel.Entities) > 0 {
-		sb.WriteString("## Data Model\n")
-		for _, entity := range fcs.DataModel.Entities {
-			sb.WriteString(fmt.Sprintf("- %s (package: %s)\n", entity.Name, entity.Package))
+// writeCodebaseGuidelines describes the existing codebase the plan adds to,
+// if any: its packages with their files and types, and how to change them
+func (p *llmPlanner) writeCodebaseGuidelines(sb *strings.Builder) {
+	if p.codebase == nil {
+		return
+	}
+
+	var existing strings.Builder
+	existing.WriteString(fmt.Sprintf("Module: %s\n", p.codebase.ModulePath))
+	pseudo := p.codebase.PseudoFCS()
+	for i, pkg := range pseudo.Architecture.Packages {
+		existing.WriteString(fmt.Sprintf("- package %s (%s)\n", pkg.Name, pkg.Path))
+		if len(pkg.Dependencies) > 0 {
+			existing.WriteString(fmt.Sprintf("  Imports: %s\n", strings.Join(pkg.Dependencies, ", ")))
+		}
+		existing.WriteString(fmt.Sprintf("  Files: %s\n", strings.Join(p.codebase.Packages[i].Files, ", ")))
+	}
+	if len(pseudo.DataModel.Entities) > 0 {
+		existing.WriteString("Types:\n")
+		for _, entity := range pseudo.DataModel.Entities {
+			existing.WriteString(fmt.Sprintf("- %s.%s\n", entity.Package, entity.Name))
 		}
-		sb.WriteString("\n")
 	}
 
-	// Build Config
-	sb.WriteString("## Build Configuration\n")
-	sb.WriteString(fmt.Sprintf("- Go Version: %s\n", fcs.BuildConfig.GoVersion))
-	sb.WriteString(fmt.Sprintf("- Output Path: %s\n", fcs.BuildConfig.OutputPath))
+	sb.WriteString("## Existing Codebase\n")
+	sb.WriteString("The project already exists. Plan only the files to add or change for the specification:\n\n")
+	sb.WriteString(promptguard.Fence(existing.String()))
 	sb.WriteString("\n")
+	sb.WriteString("- Modify an existing file with an apply_patch task targeting it; never plan generate_file for a file that exists\n")
+	sb.WriteString("- List in the file tree only the files you create or modify\n")
+	sb.WriteString("- Put new Go files in an existing package, or in a new package beside an existing one, matching its naming\n")
+	sb.WriteString("- Reuse the existing types and packages instead of redefining them\n")
+	sb.WriteString("- Do not plan go.mod, Makefile, Dockerfile, README.md or other boilerplate that already exists\n\n")
+}
 
-	// Testing Strategy
-	sb.WriteString("## Testing Strategy\n")
-	sb.WriteString(fmt.Sprintf("- Coverage Target: %.1f%%\n", fcs.TestingStrategy.CoverageTarget))
-	sb.WriteString(fmt.Sprintf("- Unit Tests: %t\n", fcs.TestingStrategy.UnitTests))
-	sb.WriteString(fmt.Sprintf("- Integration Tests: %t\n", fcs.TestingStrategy.IntegrationTests))
+// writeFileTreeGuidelines lists the user-authored file tree the plan must
+// adopt, if any
+func writeFileTreeGuidelines(sb *strings.Builder, tree *models.FileTree) {
+	if tree == nil {
+		return
+	}
+
+	var files strings.Builder
+	for _, dir := range tree.Directories {
+		files.WriteString(fmt.Sprintf("- %s/", promptguard.Inline(strings.TrimSuffix(dir.Path, "/"))))
+		if dir.Purpose != "" {
+			files.WriteString(": " + promptguard.Inline(dir.Purpose))
+		}
+		files.WriteString("\n")
+	}
+	for _, file := range tree.Files {
+		files.WriteString("- " + promptguard.Inline(file.Path))
+		if file.Purpose != "" {
+			files.WriteString(": " + promptguard.Inline(file.Purpose))
+		}
+		files.WriteString("\n")
+	}
+
+	sb.WriteString("## Required File Tree\n")
+	sb.WriteString("The project layout is fixed. Use exactly these directories and files as the file tree: do not add, ")
+	sb.WriteString("rename, move or drop any. Only plan the phases and tasks, with one generate_file task per Go file ")
+	sb.WriteString("whose target_path is the file's path.\n")
+	sb.WriteString(promptguard.Fence(files.String()))
 	sb.WriteString("\n")
+}
+
+// writeLayoutGuidelines describes the configured file split strategy, if any
+func (p *llmPlanner) writeLayoutGuidelines(sb *strings.Builder) {
+	switch p.layout.Strategy {
+	case models.FileSplitPerEntity:
+		sb.WriteString("## File Layout\n")
+		sb.WriteString("- Put each Data Model entity in its own file, named after the entity (e.g. user.go for User)\n")
+		sb.WriteString("- List the entity a file defines in its \"entities\" field, e.g. \"entities\": [\"User\"]\n")
+	case models.FileSplitPerPackage:
+		sb.WriteString("## File Layout\n")
+		sb.WriteString("- Group all Data Model entities of a package into a single file (e.g. models.go)\n")
+		sb.WriteString("- List the entities a file defines in its \"entities\" field, e.g. \"entities\": [\"User\", \"Order\"]\n")
+	case models.FileSplitBounded:
+		sb.WriteString("## File Layout\n")
+		if p.layout.MaxFileLines > 0 {
+			sb.WriteString(fmt.Sprintf("- Keep every Go source file under %d lines; split larger concerns into several files\n", p.layout.MaxFileLines))
+		}
+		sb.WriteString("- Give every Go source file an \"estimated_lines\" field with its expected length\n")
+	default:
+		return
+	}
+	sb.WriteString("\n")
+}
+
+// buildPlanningPrompt constructs the LLM prompt for planning
+func (p *llmPlanner) buildPlanningPrompt(fcs *models.FinalClarifiedSpecification) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are an expert Go architect creating a detailed generation plan for a Go project.\n\n")
+	sb.WriteString("# Task\n")
+	sb.WriteString("Analyze the following Final Clarified Specification and create a comprehensive generation plan.\n\n")
+
+	// Include FCS details
+	sb.WriteString(promptguard.Instructions)
+	writeSpecification(&sb, fcs)
+
+	p.writeLimitGuidelines(&sb)
+	p.writeLayoutGuidelines(&sb)
+	p.writeProtectedGuidelines(&sb)
+	writeCrossCuttingGuidelines(&sb, fcs.CrossCutting)
+	writeGRPCGuidelines(&sb, fcs)
+	writeModuleGuidelines(&sb, fcs.BuildConfig)
+	writeFileTreeGuidelines(&sb, fcs.FileTree)
+	p.writeCodebaseGuidelines(&sb)
+	p.writeMigrationGuidelines(&sb, fcs)
+	writePersistenceGuidelines(&sb, fcs.BuildConfig)
+	writeHTTPGuidelines(&sb, fcs)
 
 	// Instructions for the plan
 	sb.WriteString("# Instructions\n\n")
@@ -241,13 +607,113 @@ func (p *llmPlanner) buildPlanningPrompt(fcs *models.FinalClarifiedSpecification
 
 	sb.WriteString("Return ONLY the JSON plan, no additional text or explanation.\n")
 
-	return sb.String()
+	return withPreamble(p.preamble, sb.String())
+}
+
+// writeSpecification writes the FCS as a fenced data block. Free-text fields
+// are folded onto one line so spec content cannot imitate prompt sections.
+func writeSpecification(sb *strings.Builder, fcs *models.FinalClarifiedSpecification) {
+	var spec strings.Builder
+
+	// Requirements
+	spec.WriteString("## Requirements\n")
+	spec.WriteString("### Functional Requirements\n")
+	for _, req := range fcs.Requirements.Functional {
+		spec.WriteString(fmt.Sprintf("- %s: %s (Priority: %s)\n", promptguard.Inline(req.ID), promptguard.Inline(req.Description), promptguard.Inline(req.Priority)))
+	}
+	spec.WriteString("\n")
+
+	// Architecture
+	spec.WriteString("## Architecture\n")
+	spec.WriteString("### Packages\n")
+	for _, pkg := range fcs.Architecture.Packages {
+		spec.WriteString(fmt.Sprintf("- %s (%s): %s\n", promptguard.Inline(pkg.Name), promptguard.Inline(pkg.Path), promptguard.Inline(pkg.Purpose)))
+		if len(pkg.Dependencies) > 0 {
+			spec.WriteString(fmt.Sprintf("  Dependencies: %s\n", promptguard.Inline(strings.Join(pkg.Dependencies, ", "))))
+		}
+	}
+	spec.WriteString("\n")
+
+	// Dependencies
+	if len(fcs.Architecture.Dependencies) > 0 {
+		spec.WriteString("### External Dependencies\n")
+		for _, dep := range fcs.Architecture.Dependencies {
+			spec.WriteString(fmt.Sprintf("- %s %s: %s\n", promptguard.Inline(dep.Name), promptguard.Inline(dep.Version), promptguard.Inline(dep.Purpose)))
+		}
+		spec.WriteString("\n")
+	}
+
+	// Data Model
+	if len(fcs.DataModel.Entities) > 0 {
+		spec.WriteString("## Data Model\n")
+		for _, entity := range fcs.DataModel.Entities {
+			spec.WriteString(fmt.Sprintf("- %s (package: %s)\n", promptguard.Inline(entity.Name), promptguard.Inline(entity.Package)))
+		}
+		spec.WriteString("\n")
+	}
+
+	// Enums
+	if len(fcs.DataModel.Enums) > 0 {
+		spec.WriteString("## Enums\n")
+		for _, e := range fcs.DataModel.Enums {
+			spec.WriteString(fmt.Sprintf("- %s (package: %s, type: %s): %s\n", promptguard.Inline(e.Name), promptguard.Inline(e.Package),
+				promptguard.Inline(e.Backing()), promptguard.Inline(strings.Join(e.Values, ", "))))
+		}
+		spec.WriteString("\n")
+	}
+
+	// Cross-Cutting Concerns
+	if fcs.CrossCutting.Enabled() {
+		spec.WriteString("## Cross-Cutting Concerns\n")
+		for _, concern := range fcs.CrossCutting.Ordered() {
+			spec.WriteString(fmt.Sprintf("- %s", promptguard.Inline(concern.Name)))
+			if concern.Description != "" {
+				spec.WriteString(fmt.Sprintf(": %s", promptguard.Inline(concern.Description)))
+			}
+			spec.WriteString("\n")
+		}
+		spec.WriteString("\n")
+	}
+
+	// gRPC Services
+	if services := fcs.GRPCServices(); len(services) > 0 {
+		spec.WriteString("## gRPC Services\n")
+		for _, service := range services {
+			spec.WriteString(fmt.Sprintf("- %s\n", promptguard.Inline(service.FullName())))
+			for _, method := range service.Methods {
+				spec.WriteString(fmt.Sprintf("  - %s", promptguard.Inline(method.Signature())))
+				if method.Description != "" {
+					spec.WriteString(fmt.Sprintf(": %s", promptguard.Inline(method.Description)))
+				}
+				spec.WriteString("\n")
+			}
+		}
+		spec.WriteString("\n")
+	}
+
+	// Build Config
+	spec.WriteString("## Build Configuration\n")
+	spec.WriteString(fmt.Sprintf("- Go Version: %s\n", promptguard.Inline(fcs.BuildConfig.GoVersion)))
+	spec.WriteString(fmt.Sprintf("- Output Path: %s\n", promptguard.Inline(fcs.BuildConfig.OutputPath)))
+	spec.WriteString("\n")
+
+	// Testing Strategy
+	spec.WriteString("## Testing Strategy\n")
+	spec.WriteString(fmt.Sprintf("- Coverage Target: %.1f%%\n", fcs.TestingStrategy.CoverageTarget))
+	spec.WriteString(fmt.Sprintf("- Unit Tests: %t\n", fcs.TestingStrategy.UnitTests))
+	spec.WriteString(fmt.Sprintf("- Integration Tests: %t\n", fcs.TestingStrategy.IntegrationTests))
+	spec.WriteString(fmt.Sprintf("- Test Framework: %s\n", promptguard.Inline(fcs.TestingStrategy.Framework())))
+
+	sb.WriteString("# Final Clarified Specification\n\n")
+	sb.WriteString(promptguard.Fence(spec.String()))
+	sb.WriteString("\n")
 }
 
 // buildPlanningPromptWithCache constructs cacheable LLM prompts for planning
 // This method separates static (cacheable) planning guidelines from dynamic (project-specific) FCS content
 func (p *llmPlanner) buildPlanningPromptWithCache(fcs *models.FinalClarifiedSpecification) []llm.CacheableMessage {
 	builder := llm.NewPromptBuilder("5m") // 5-minute cache TTL
+	addPreamble(builder, p.preamble)
 
 	// CACHEABLE PART: Static planning guidelines and schema (same across all projects)
 	var guidelines strings.Builder
@@ -296,6 +762,7 @@ func (p *llmPlanner) buildPlanningPromptWithCache(fcs *models.FinalClarifiedSpec
 	guidelines.WriteString("   - Dockerfile\n")
 	guidelines.WriteString("   - Makefile\n")
 	guidelines.WriteString("   - README.md\n\n")
+	guidelines.WriteString(promptguard.Instructions)
 
 	builder.AddCacheable(guidelines.String())
 
@@ -303,57 +770,19 @@ func (p *llmPlanner) buildPlanningPromptWithCache(fcs *models.FinalClarifiedSpec
 	var fcsContent strings.Builder
 	fcsContent.WriteString("# Task\n")
 	fcsContent.WriteString("Analyze the following Final Clarified Specification and create a comprehensive generation plan.\n\n")
-	fcsContent.WriteString("# Final Clarified Specification\n\n")
-
-	// Requirements
-	fcsContent.WriteString("## Requirements\n")
-	fcsContent.WriteString("### Functional Requirements\n")
-	for _, req := range fcs.Requirements.Functional {
-		fcsContent.WriteString(fmt.Sprintf("- %s: %s (Priority: %s)\n", req.ID, req.Description, req.Priority))
-	}
-	fcsContent.WriteString("\n")
-
-	// Architecture
-	fcsContent.WriteString("## Architecture\n")
-	fcsContent.WriteString("### Packages\n")
-	for _, pkg := range fcs.Architecture.Packages {
-		fcsContent.WriteString(fmt.Sprintf("- %s (%s): %s\n", pkg.Name, pkg.Path, pkg.Purpose))
-		if len(pkg.Dependencies) > 0 {
-			fcsContent.WriteString(fmt.Sprintf("  Dependencies: %s\n", strings.Join(pkg.Dependencies, ", ")))
-		}
-	}
-	fcsContent.WriteString("\n")
-
-	// Dependencies
-	if len(fcs.Architecture.Dependencies) > 0 {
-		fcsContent.WriteString("### External Dependencies\n")
-		for _, dep := range fcs.Architecture.Dependencies {
-			fcsContent.WriteString(fmt.Sprintf("- %s %s: %s\n", dep.Name, dep.Version, dep.Purpose))
-		}
-		fcsContent.WriteString("\n")
-	}
-
-	// Data Model
-	if len(fcs.DataModel.Entities) > 0 {
-		fcsContent.WriteString("## Data Model\n")
-		for _, entity := range fcs.DataModel.Entities {
-			fcsContent.WriteString(fmt.Sprintf("- %s (package: %s)\n", entity.Name, entity.Package))
-		}
-		fcsContent.WriteString("\n")
-	}
-
-	// Build Config
-	fcsContent.WriteString("## Build Configuration\n")
-	fcsContent.WriteString(fmt.Sprintf("- Go Version: %s\n", fcs.BuildConfig.GoVersion))
-	fcsContent.WriteString(fmt.Sprintf("- Output Path: %s\n", fcs.BuildConfig.OutputPath))
-	fcsContent.WriteString("\n")
-
-	// Testing Strategy
-	fcsContent.WriteString("## Testing Strategy\n")
-	fcsContent.WriteString(fmt.Sprintf("- Coverage Target: %.1f%%\n", fcs.TestingStrategy.CoverageTarget))
-	fcsContent.WriteString(fmt.Sprintf("- Unit Tests: %t\n", fcs.TestingStrategy.UnitTests))
-	fcsContent.WriteString(fmt.Sprintf("- Integration Tests: %t\n", fcs.TestingStrategy.IntegrationTests))
-	fcsContent.WriteString("\n")
+	writeSpecification(&fcsContent, fcs)
+
+	p.writeLimitGuidelines(&fcsContent)
+	p.writeLayoutGuidelines(&fcsContent)
+	p.writeProtectedGuidelines(&fcsContent)
+	writeCrossCuttingGuidelines(&fcsContent, fcs.CrossCutting)
+	writeGRPCGuidelines(&fcsContent, fcs)
+	writeModuleGuidelines(&fcsContent, fcs.BuildConfig)
+	writeFileTreeGuidelines(&fcsContent, fcs.FileTree)
+	p.writeCodebaseGuidelines(&fcsContent)
+	p.writeMigrationGuidelines(&fcsContent, fcs)
+	writePersistenceGuidelines(&fcsContent, fcs.BuildConfig)
+	writeHTTPGuidelines(&fcsContent, fcs)
 
 	fcsContent.WriteString("Return ONLY the JSON plan, no additional text or explanation.\n")
 
@@ -362,8 +791,10 @@ func (p *llmPlanner) buildPlanningPromptWithCache(fcs *models.FinalClarifiedSpec
 	return builder.Build()
 }
 
-// parsePlanResponse parses the LLM response into a GenerationPlan
-func (p *llmPlanner) parsePlanResponse(response string, _ *models.FinalClarifiedSpecification) (*models.GenerationPlan, error) {
+// parsePlanResponse parses the LLM response into a GenerationPlan. When the
+// FCS fixes the file tree, the plan adopts it; otherwise it gets the module
+// files and data model migrations the model may have left out.
+func (p *llmPlanner) parsePlanResponse(response string, fcs *models.FinalClarifiedSpecification) (*models.GenerationPlan, error) {
 	// Clean the response (remove markdown code blocks if present)
 	response = strings.TrimSpace(response)
 	response = strings.TrimPrefix(response, "```json")
@@ -380,9 +811,11 @@ func (p *llmPlanner) parsePlanResponse(response string, _ *models.FinalClarified
 				Purpose string `json:"purpose"`
 			} `json:"directories"`
 			Files []struct {
-				Path        string `json:"path"`
-				Purpose     string `json:"purpose"`
-				GeneratedBy string `json:"generated_by"`
+				Path           string   `json:"path"`
+				Purpose        string   `json:"purpose"`
+				GeneratedBy    string   `json:"generated_by"`
+				Entities       []string `json:"entities"`
+				EstimatedLines int      `json:"estimated_lines"`
 			} `json:"files"`
 		} `json:"file_tree"`
 		Phases []struct {
@@ -424,9 +857,11 @@ func (p *llmPlanner) parsePlanResponse(response string, _ *models.FinalClarified
 	// Convert files
 	for i, file := range planData.FileTree.Files {
 		plan.FileTree.Files[i] = models.File{
-			Path:        file.Path,
-			Purpose:     file.Purpose,
-			GeneratedBy: file.GeneratedBy,
+			Path:           file.Path,
+			Purpose:        file.Purpose,
+			GeneratedBy:    file.GeneratedBy,
+			Entities:       file.Entities,
+			EstimatedLines: file.EstimatedLines,
 		}
 	}
 
@@ -453,5 +888,132 @@ func (p *llmPlanner) parsePlanResponse(response string, _ *models.FinalClarified
 		}
 	}
 
+	if fcs != nil && fcs.FileTree != nil {
+		adoptFileTree(plan, *fcs.FileTree)
+	} else if fcs != nil {
+		planModules(plan, fcs.BuildConfig)
+		p.planMigrations(plan, fcs)
+		planPersistence(plan, fcs)
+		planRouter(plan, fcs)
+		planOpenAPI(plan, fcs)
+	}
+
 	return plan, nil
 }
+
+// adoptFileTree replaces the plan's file tree with the required one and drops
+// generate_file tasks for files outside it. Details the LLM planned for a
+// required file (task link, entities, size estimate) fill in what the
+// required tree leaves empty.
+func adoptFileTree(plan *models.GenerationPlan, required models.FileTree) {
+	planned := make(map[string]models.File, len(plan.FileTree.Files))
+	for _, file := range plan.FileTree.Files {
+		planned[path.Clean(filepath.ToSlash(file.Path))] = file
+	}
+
+	tree := models.FileTree{
+		Root:        required.Root,
+		Directories: append([]models.Directory(nil), required.Directories...),
+		Files:       make([]models.File, len(required.Files)),
+	}
+	if tree.Root == "" {
+		tree.Root = plan.FileTree.Root
+	}
+	for i, file := range required.Files {
+		if llmFile, ok := planned[path.Clean(filepath.ToSlash(file.Path))]; ok {
+			if file.Purpose == "" {
+				file.Purpose = llmFile.Purpose
+			}
+			if file.GeneratedBy == "" {
+				file.GeneratedBy = llmFile.GeneratedBy
+			}
+			if len(file.Entities) == 0 {
+				file.Entities = llmFile.Entities
+			}
+			if file.EstimatedLines == 0 {
+				file.EstimatedLines = llmFile.EstimatedLines
+			}
+		}
+		tree.Files[i] = file
+	}
+	plan.FileTree = tree
+
+	for i, phase := range plan.Phases {
+		tasks := make([]models.GenerationTask, 0, len(phase.Tasks))
+		for _, task := range phase.Tasks {
+			if task.Type == "generate_file" && task.TargetPath != "" && !required.Has(task.TargetPath) {
+				continue
+			}
+			tasks = append(tasks, task)
+		}
+		plan.Phases[i].Tasks = tasks
+	}
+}
+
+// planModules fits the plan of a multi-module project to its modules. A
+// go.mod the model placed at the project root, which would swallow the
+// modules, is dropped; go.work and every module's go.mod are listed, and the
+// first phase generates the go.mod files the model left out.
+func planModules(plan *models.GenerationPlan, build models.BuildConfig) {
+	if !build.IsMultiModule() {
+		return
+	}
+
+	files := make([]models.File, 0, len(plan.FileTree.Files)+len(build.Modules)+1)
+	for _, file := range plan.FileTree.Files {
+		if path.Clean(filepath.ToSlash(file.Path)) != "go.mod" {
+			files = append(files, file)
+		}
+	}
+	plan.FileTree.Files = files
+
+	planned := make(map[string]bool)
+	for i, phase := range plan.Phases {
+		tasks := make([]models.GenerationTask, 0, len(phase.Tasks))
+		for _, task := range phase.Tasks {
+			target := path.Clean(filepath.ToSlash(task.TargetPath))
+			if task.TargetPath != "" && target == "go.mod" {
+				continue
+			}
+			planned[target] = true
+			tasks = append(tasks, task)
+		}
+		plan.Phases[i].Tasks = tasks
+	}
+
+	if !plan.FileTree.Has("go.work") {
+		plan.FileTree.Files = append(plan.FileTree.Files, models.File{
+			Path:        "go.work",
+			Purpose:     "Go workspace tying the modules together",
+			GeneratedBy: "template",
+		})
+	}
+
+	if len(plan.Phases) == 0 {
+		plan.Phases = append(plan.Phases, models.GenerationPhase{Name: "setup", Order: 1})
+	}
+	first := 0
+	for i, phase := range plan.Phases {
+		if phase.Order < plan.Phases[first].Order {
+			first = i
+		}
+	}
+
+	for _, module := range build.Modules {
+		goMod := module.GoModPath()
+		if !plan.FileTree.Has(goMod) {
+			plan.FileTree.Files = append(plan.FileTree.Files, models.File{
+				Path:        goMod,
+				Purpose:     "Module definition for " + module.Path,
+				GeneratedBy: "template",
+			})
+		}
+		if !planned[goMod] {
+			plan.Phases[first].Tasks = append(plan.Phases[first].Tasks, models.GenerationTask{
+				ID:         "create_gomod_" + strings.ReplaceAll(module.Dir, "/", "_"),
+				Type:       "generate_file",
+				TargetPath: goMod,
+			})
+		}
+	}
+}
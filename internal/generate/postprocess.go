@@ -0,0 +1,46 @@
+package generate
+
+import (
+	"go/token"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/dshills/gocreator/pkg/postprocess"
+)
+
+// versionDir matches a major version directory, whose package is named after
+// the directory above it
+var versionDir = regexp.MustCompile(`^v[0-9]+$`)
+
+// postProcess runs generated code through pipeline when target is a Go file.
+// Other files are returned as they are.
+func postProcess(pipeline *postprocess.Pipeline, target string, inputs map[string]interface{}, code string) (string, error) {
+	if !strings.HasSuffix(target, ".go") {
+		return code, nil
+	}
+	return pipeline.Process(target, plannedPackage(target, inputs), code)
+}
+
+// plannedPackage returns the package name the plan gives a Go file: the
+// package of its task inputs, or else main under cmd/ and the directory name
+// elsewhere. It returns "" when the name cannot be told, as for files in the
+// project root or a major version directory.
+func plannedPackage(target string, inputs map[string]interface{}) string {
+	if pkg := path.Base(strings.TrimSpace(inputString(inputs, "package"))); token.IsIdentifier(pkg) && !versionDir.MatchString(pkg) {
+		return pkg
+	}
+
+	dir := path.Dir(path.Clean(filepath.ToSlash(target)))
+	switch {
+	case dir == ".":
+		return ""
+	case dir == "cmd" || strings.HasPrefix(dir, "cmd/"):
+		return "main"
+	}
+	if name := path.Base(dir); token.IsIdentifier(name) && !versionDir.MatchString(name) {
+		return name
+	}
+	return ""
+}
@@ -0,0 +1,28 @@
+package generate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlannedPackage(t *testing.T) {
+	tests := []struct {
+		target string
+		inputs map[string]interface{}
+		want   string
+	}{
+		{target: "internal/store/user.go", want: "store"},
+		{target: "internal/store/user_test.go", want: "store"},
+		{target: "internal/store/user.go", inputs: map[string]interface{}{"package": "internal/persistence"}, want: "persistence"},
+		{target: "internal/store/user.go", inputs: map[string]interface{}{"package": "not a name"}, want: "store"},
+		{target: "cmd/server/main.go", want: "main"},
+		{target: "main.go", want: ""},
+		{target: "internal/big-store/store.go", want: ""},
+		{target: "api/v2/api.go", want: ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, plannedPackage(tt.target, tt.inputs), tt.target)
+	}
+}
@@ -0,0 +1,32 @@
+package generate
+
+import (
+	"strings"
+
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// preambleHeading introduces the organization preamble in every prompt
+const preambleHeading = "# Organization Standards\n\nThese standards apply to all generated code and take precedence over the guidelines that follow.\n\n"
+
+// formatPreamble returns the preamble section, or "" when no preamble is configured
+func formatPreamble(preamble string) string {
+	preamble = strings.TrimSpace(preamble)
+	if preamble == "" {
+		return ""
+	}
+	return preambleHeading + preamble + "\n\n"
+}
+
+// withPreamble prepends the organization preamble to a single-string prompt
+func withPreamble(preamble, prompt string) string {
+	return formatPreamble(preamble) + prompt
+}
+
+// addPreamble adds the organization preamble as the first cacheable block.
+// It is identical across every call in a run, so it shares the cached prefix.
+func addPreamble(builder *llm.PromptBuilder, preamble string) {
+	if section := formatPreamble(preamble); section != "" {
+		builder.AddCacheable(strings.TrimRight(section, "\n"))
+	}
+}
@@ -0,0 +1,79 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPreamble = "Log with log/slog only.\nNever call os.Exit outside main."
+
+func TestPreamble_PlainPrompts(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{ID: "fcs"}
+	plan := &models.GenerationPlan{FileTree: models.FileTree{Files: []models.File{{Path: "internal/app/app.go"}}}}
+	task := models.GenerationTask{ID: "t1", Type: "generate_file", TargetPath: "internal/app/app.go"}
+
+	prompts := map[string]string{
+		"planner": (&llmPlanner{preamble: testPreamble}).buildPlanningPrompt(fcs),
+		"replan":  (&llmPlanner{preamble: testPreamble}).buildReplanPrompt(fcs, plan, []string{"too big"}),
+		"coder":   (&llmCoder{preamble: testPreamble}).buildCodeGenerationPrompt(task, plan, nil),
+		"tester":  (&llmTester{preamble: testPreamble}).buildTestGenerationPrompt("internal/app/app.go", plan, nil, nil, "", models.DefaultTestFramework),
+		"critic":  (&critic{preamble: testPreamble}).buildPrompt("internal/app/app.go", "package app", []string{CriticClassHandlers}),
+	}
+
+	for name, prompt := range prompts {
+		t.Run(name, func(t *testing.T) {
+			assert.True(t, strings.HasPrefix(prompt, "# Organization Standards"), "preamble leads the prompt")
+			assert.Contains(t, prompt, testPreamble)
+			assert.Equal(t, 1, strings.Count(prompt, "# Organization Standards"))
+		})
+	}
+}
+
+func TestPreamble_CacheableBlock(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{ID: "fcs"}
+	plan := &models.GenerationPlan{}
+	task := models.GenerationTask{ID: "t1", Type: "generate_file", TargetPath: "main.go"}
+
+	planner := &llmPlanner{preamble: testPreamble}
+	coder := &llmCoder{preamble: testPreamble}
+
+	for name, messages := range map[string][]string{
+		"planner": systemContents(t, planner.buildPlanningPromptWithCache(fcs)),
+		"coder":   systemContents(t, coder.buildCodeGenerationPromptWithCache(task, plan, nil)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.NotEmpty(t, messages)
+			assert.True(t, strings.HasPrefix(messages[0], "# Organization Standards"))
+			assert.Contains(t, messages[0], testPreamble)
+		})
+	}
+}
+
+func TestPreamble_Unset(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{ID: "fcs"}
+
+	assert.NotContains(t, (&llmPlanner{}).buildPlanningPrompt(fcs), "Organization Standards")
+	assert.NotContains(t, (&llmPlanner{preamble: "  \n"}).buildPlanningPrompt(fcs), "Organization Standards")
+	for _, msg := range (&llmPlanner{}).buildPlanningPromptWithCache(fcs) {
+		assert.NotContains(t, msg.Content, "Organization Standards")
+	}
+}
+
+// systemContents returns the content of cached system messages, failing if
+// they are not marked for caching
+func systemContents(t *testing.T, messages []llm.CacheableMessage) []string {
+	t.Helper()
+	var contents []string
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			require.NotNil(t, msg.Cache, "system block is cacheable")
+			contents = append(contents, msg.Content)
+		}
+	}
+	return contents
+}
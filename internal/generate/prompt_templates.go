@@ -0,0 +1,293 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/promptguard"
+	"github.com/rs/zerolog/log"
+)
+
+// PromptFileTypes are the file types whose coder prompt requirements can be
+// overridden, each by <type>.tmpl in the prompt templates directory. source
+// covers Go files of no other type.
+var PromptFileTypes = []string{"model", "repository", "service", "handler", "test", "source"}
+
+// standardsTemplate names the override of the coding standards of every Go file
+const standardsTemplate = "standards"
+
+// PromptTemplates are a team's Go text/template overrides of sections of
+// the coder prompt. <type>.tmpl replaces the requirements of the file types in
+// PromptFileTypes and standards.tmpl the coding standards of every Go file.
+// The task, its context, the dependency APIs and the output format stay as
+// the engine writes them. Files named _*.tmpl hold templates the others share.
+type PromptTemplates struct {
+	dir string
+	set *template.Template
+}
+
+// PromptTemplateData is the data prompt templates are executed with.
+// standards.tmpl is executed with Standards only, so the section is the same
+// for every file and stays in the cached prompt prefix.
+type PromptTemplateData struct {
+	Path         string                 // File to generate, relative to the output directory
+	FileType     string                 // One of PromptFileTypes
+	Purpose      string                 // The file's purpose from the plan
+	Package      string                 // Task inputs the prompt lists
+	Entities     []string               //
+	Dependencies []string               //
+	Inputs       map[string]interface{} // All task inputs
+	FCS          *FilteredFCS           // Specification filtered to the file; nil without one
+	Context      string                 // FCS as the prompt shows it, fenced
+	Standards    string                 // Default coding standards
+	Requirements string                 // Default requirements of the file type
+}
+
+// promptTemplateFuncs are the functions available to prompt templates.
+// Specification text is the user's; inline and fence mark it as data the way
+// the engine's own prompt sections do.
+var promptTemplateFuncs = template.FuncMap{
+	"inline": promptguard.Inline,
+	"fence":  promptguard.Fence,
+	"join":   strings.Join,
+}
+
+// LoadPromptTemplates parses the prompt templates in dir. Each override is
+// executed once against sample data, so references to unknown fields are
+// reported here rather than when a file is generated.
+func LoadPromptTemplates(dir string) (*PromptTemplates, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt templates: %w", err)
+	}
+	if len(paths) == 0 {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("failed to read prompt templates: %w", err)
+		}
+		return nil, fmt.Errorf("no prompt templates (*.tmpl) in %s", dir)
+	}
+	sort.Strings(paths)
+
+	var overrides []string
+	for _, p := range paths {
+		name := strings.TrimSuffix(filepath.Base(p), ".tmpl")
+		switch {
+		case strings.HasPrefix(name, "_"):
+		case name == standardsTemplate || slices.Contains(PromptFileTypes, name):
+			overrides = append(overrides, name)
+		default:
+			return nil, fmt.Errorf("unknown prompt template %s: name it after a file type (%s) or standards, or prefix shared templates with _",
+				filepath.Base(p), strings.Join(PromptFileTypes, ", "))
+		}
+	}
+
+	set, err := template.New("prompts").Funcs(promptTemplateFuncs).ParseFiles(paths...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt templates: %w", err)
+	}
+
+	templates := &PromptTemplates{dir: dir, set: set}
+	for _, name := range overrides {
+		sample := PromptTemplateData{
+			Path:         "internal/app/app.go",
+			FileType:     name,
+			Inputs:       map[string]interface{}{},
+			FCS:          &FilteredFCS{},
+			Standards:    codingStandards,
+			Requirements: fileTypeRequirements(name),
+		}
+		if _, _, err := templates.render(name, sample); err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+// Dir returns the directory the templates were loaded from
+func (p *PromptTemplates) Dir() string {
+	return p.dir
+}
+
+// render executes the override named name, reporting false when there is none
+func (p *PromptTemplates) render(name string, data PromptTemplateData) (string, bool, error) {
+	if p == nil {
+		return "", false, nil
+	}
+	tmpl := p.set.Lookup(name + ".tmpl")
+	if tmpl == nil {
+		return "", false, nil
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", true, fmt.Errorf("prompt template %s.tmpl: %w", name, err)
+	}
+	return strings.TrimSpace(sb.String()) + "\n\n", true, nil
+}
+
+// codingStandards are the default coding standards of Go files
+const codingStandards = `# Coding Standards
+
+1. **Go Best Practices**:
+   - Follow Go idioms and conventions
+   - Accept interfaces, return structs
+   - Use meaningful variable names
+   - Keep functions small and focused
+
+2. **Error Handling**:
+   - Return errors, don't panic
+   - Wrap errors with context using fmt.Errorf
+   - Use sentinel errors for known conditions
+
+3. **Documentation**:
+   - Add godoc comments for all exported symbols
+   - Comments should explain why, not what
+   - Keep line length under 100 characters
+
+4. **Testing**:
+   - Write testable code
+   - Use dependency injection
+   - Avoid global state
+
+`
+
+// fileTypeRequirements returns the default requirements of a Go file type
+func fileTypeRequirements(fileType string) string {
+	switch fileType {
+	case "go.mod":
+		return "Generate a go.mod file with:\n" +
+			"- Correct module path\n" +
+			"- Go version from build config\n" +
+			"- Required dependencies with versions\n" +
+			"- Proper formatting\n\n"
+
+	case "main.go":
+		return "Generate a main.go file with:\n" +
+			"- package main declaration\n" +
+			"- Proper imports\n" +
+			"- main() function with initialization\n" +
+			"- Error handling and logging\n" +
+			"- Graceful shutdown handling\n\n"
+
+	case "model":
+		return "Generate a model/entity file with:\n" +
+			"- Proper package declaration\n" +
+			"- Struct definitions with JSON tags\n" +
+			"- Validation methods\n" +
+			"- Constructor functions\n" +
+			"- Godoc comments for all exported types and functions\n\n"
+
+	case "repository":
+		return "Generate a repository file with:\n" +
+			"- Interface definition for repository contract\n" +
+			"- Concrete implementation struct\n" +
+			"- Constructor function\n" +
+			"- All CRUD methods with proper error handling\n" +
+			"- Context support for cancellation\n\n"
+
+	case "service":
+		return "Generate a service file with:\n" +
+			"- Service interface definition\n" +
+			"- Service struct with dependencies\n" +
+			"- Constructor with dependency injection\n" +
+			"- Business logic methods\n" +
+			"- Proper error handling and logging\n\n"
+
+	case "handler":
+		return "Generate an HTTP handler file with:\n" +
+			"- Handler struct with service dependencies\n" +
+			"- HTTP handler functions\n" +
+			"- Request validation\n" +
+			"- Proper HTTP status codes\n" +
+			"- JSON encoding/decoding\n\n"
+
+	case "test":
+		return "Generate a test file with:\n" +
+			"- Table-driven tests using testing package\n" +
+			"- Test setup and teardown\n" +
+			"- Mocks for dependencies\n" +
+			"- Comprehensive test cases including edge cases\n" +
+			"- Proper assertions\n\n"
+
+	default:
+		return "Generate a well-structured Go file with:\n" +
+			"- Proper package declaration\n" +
+			"- Clear, idiomatic Go code\n" +
+			"- Proper error handling\n" +
+			"- Comprehensive documentation\n\n"
+	}
+}
+
+// requirementsSection returns the requirements of a Go file: the team's
+// override for its type when there is one, the defaults otherwise
+func (c *llmCoder) requirementsSection(task models.GenerationTask, plan *models.GenerationPlan, filteredFCS *FilteredFCS, fileType string) string {
+	requirements := fileTypeRequirements(fileType)
+	if c.prompts == nil || !slices.Contains(PromptFileTypes, fileType) {
+		return requirements
+	}
+
+	data := PromptTemplateData{
+		Path:         task.TargetPath,
+		FileType:     fileType,
+		Purpose:      c.getFilePurpose(task.TargetPath, plan),
+		Package:      inputString(task.Inputs, "package"),
+		Entities:     inputStrings(task.Inputs, "entities"),
+		Dependencies: inputStrings(task.Inputs, "dependencies"),
+		Inputs:       task.Inputs,
+		FCS:          filteredFCS,
+		Standards:    codingStandards,
+		Requirements: requirements,
+	}
+	if filteredFCS != nil && c.contextFilter != nil {
+		data.Context = promptguard.Fence(c.contextFilter.FormatFilteredFCS(filteredFCS))
+	}
+
+	rendered, ok, err := c.prompts.render(fileType, data)
+	if err != nil {
+		log.Warn().Err(err).Str("file", task.TargetPath).Msg("Prompt template failed, using the default requirements")
+		return requirements
+	}
+	if !ok {
+		return requirements
+	}
+	return rendered
+}
+
+// standardsSection returns the coding standards of Go files: the team's
+// override when there is one, the defaults otherwise
+func (c *llmCoder) standardsSection() string {
+	rendered, ok, err := c.prompts.render(standardsTemplate, PromptTemplateData{Standards: codingStandards})
+	if err != nil {
+		log.Warn().Err(err).Msg("Prompt template failed, using the default coding standards")
+		return codingStandards
+	}
+	if !ok {
+		return codingStandards
+	}
+	return rendered
+}
+
+// inputString returns a string task input
+func inputString(inputs map[string]interface{}, key string) string {
+	s, _ := inputs[key].(string)
+	return s
+}
+
+// inputStrings returns a list task input, as decoded from the plan's JSON
+func inputStrings(inputs map[string]interface{}, key string) []string {
+	var values []string
+	switch list := inputs[key].(type) {
+	case []interface{}:
+		for _, v := range list {
+			values = append(values, fmt.Sprintf("%v", v))
+		}
+	case []string:
+		values = list
+	}
+	return values
+}
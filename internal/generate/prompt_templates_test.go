@@ -0,0 +1,77 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePromptTemplates(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	return dir
+}
+
+func TestLoadPromptTemplates(t *testing.T) {
+	dir := writePromptTemplates(t, map[string]string{
+		"_house.tmpl": `{{define "house"}}- Log with log/slog{{end}}`,
+		"service.tmpl": `Generate the {{.Package}} service in {{.Path}} for {{join .Entities ", "}}:
+{{template "house"}}
+- Return *AppError from every method
+{{.Requirements}}`,
+		"standards.tmpl": `{{.Standards}}5. **House Style**:
+   - No init functions`,
+	})
+	templates, err := LoadPromptTemplates(dir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, templates.Dir())
+
+	coder := &llmCoder{prompts: templates}
+	plan := &models.GenerationPlan{}
+	task := models.GenerationTask{ID: "t1", Type: "generate_file", TargetPath: "internal/order/service.go", Inputs: map[string]interface{}{
+		"package":  "order",
+		"entities": []interface{}{"Order", "LineItem"},
+	}}
+
+	prompt := coder.buildCodeGenerationPrompt(task, plan, nil)
+	assert.Contains(t, prompt, "Generate the order service in internal/order/service.go for Order, LineItem:\n- Log with log/slog\n")
+	assert.Contains(t, prompt, "- Return *AppError from every method\nGenerate a service file with:\n", "the defaults are available to extend")
+	assert.Contains(t, prompt, "4. **Testing**:")
+	assert.Contains(t, prompt, "5. **House Style**:\n   - No init functions\n")
+	assert.Contains(t, prompt, "# Output Format", "the engine keeps the output format")
+
+	// The standards are the same for every file, so they stay cacheable
+	cached := systemContents(t, coder.buildCodeGenerationPromptWithCache(task, plan, nil))
+	assert.Contains(t, strings.Join(cached, ""), "5. **House Style**:")
+
+	// File types without an override keep the default requirements
+	task.TargetPath = "internal/order/handler.go"
+	assert.Contains(t, coder.buildCodeGenerationPrompt(task, plan, nil), "Generate an HTTP handler file with:\n")
+}
+
+func TestLoadPromptTemplates_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"empty", map[string]string{"notes.md": "x"}, "no prompt templates"},
+		{"unknown type", map[string]string{"controller.tmpl": "x"}, "unknown prompt template controller.tmpl"},
+		{"syntax", map[string]string{"model.tmpl": "{{.Path"}, "failed to parse prompt templates"},
+		{"unknown field", map[string]string{"model.tmpl": "{{.Style}}"}, "prompt template model.tmpl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPromptTemplates(writePromptTemplates(t, tt.files))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
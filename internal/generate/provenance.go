@@ -0,0 +1,117 @@
+package generate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// PromptReporter is implemented by coders and testers that record the
+// prompts each file was generated from
+type PromptReporter interface {
+	// Prompts maps generated file paths to their prompt record
+	Prompts() map[string]PromptRecord
+}
+
+// PromptRecord describes the prompts sent to generate one file
+type PromptRecord struct {
+	Hash        string    // SHA-256 of the last prompt sent
+	TaskID      string    // Plan task ID, or "test:<source file>" for a test file
+	InputTokens int64     // Across every prompt sent for the file, at four bytes each
+	SentAt      time.Time // When the first prompt was sent
+}
+
+// promptLog records the prompts sent for each file
+type promptLog struct {
+	mu      sync.Mutex
+	records map[string]PromptRecord
+}
+
+// record stores the hash of the prompt parts sent by task to generate path
+func (p *promptLog) record(taskID, path string, parts ...string) {
+	hash := sha256.New()
+	size := 0
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+		size += len(part)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.records == nil {
+		p.records = make(map[string]PromptRecord)
+	}
+	path = filepath.ToSlash(filepath.Clean(path))
+	record, ok := p.records[path]
+	if !ok {
+		record.SentAt = time.Now()
+	}
+	record.Hash = hex.EncodeToString(hash.Sum(nil))
+	record.TaskID = taskID
+	record.InputTokens += int64(size / 4)
+	p.records[path] = record
+}
+
+// recordMessages stores the hash of a cacheable prompt sent by task to
+// generate path
+func (p *promptLog) recordMessages(taskID, path string, messages []llm.CacheableMessage) {
+	parts := make([]string, 0, 2*len(messages))
+	for _, msg := range messages {
+		parts = append(parts, msg.Role, msg.Content)
+	}
+	p.record(taskID, path, parts...)
+}
+
+// Prompts returns a copy of the prompt records
+func (p *promptLog) Prompts() map[string]PromptRecord {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	records := make(map[string]PromptRecord, len(p.records))
+	for path, record := range p.records {
+		records[path] = record
+	}
+	return records
+}
+
+// runProvenance completes a run's provenance with the prompt hashes and
+// per-file records of the written files, taken from each component that
+// records them. Output tokens are estimated from the file as written.
+func runProvenance(base models.Provenance, files []models.GeneratedFile, components ...any) *models.Provenance {
+	recorded := make(map[string]PromptRecord)
+	for _, component := range components {
+		if reporter, ok := component.(PromptReporter); ok {
+			for path, record := range reporter.Prompts() {
+				recorded[path] = record
+			}
+		}
+	}
+
+	provenance := base
+	provenance.PromptHashes = nil
+	provenance.Files = nil
+	for _, file := range files {
+		path := filepath.ToSlash(filepath.Clean(file.Path))
+		record, ok := recorded[path]
+		if !ok {
+			continue
+		}
+		if provenance.PromptHashes == nil {
+			provenance.PromptHashes = make(map[string]string)
+			provenance.Files = make(map[string]models.FileProvenance)
+		}
+		provenance.PromptHashes[path] = record.Hash
+		provenance.Files[path] = models.FileProvenance{
+			TaskID:       record.TaskID,
+			InputTokens:  record.InputTokens,
+			OutputTokens: int64(len(file.Content) / 4),
+			StartedAt:    record.SentAt,
+		}
+	}
+	return &provenance
+}
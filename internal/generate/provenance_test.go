@@ -0,0 +1,64 @@
+package generate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunProvenance(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{"```go\npackage models\n```"}}
+	coder, err := NewCoder(CoderConfig{LLMClient: client})
+	require.NoError(t, err)
+	plan := emitPlan()
+
+	_, err = coder.GenerateFile(context.Background(), plan.Phases[0].Tasks[0], plan, nil)
+	require.NoError(t, err)
+	require.Len(t, client.prompts, 1)
+
+	base := models.Provenance{GeneratorVersion: "0.3.0", Provider: "mock", Model: "mock-model"}
+	provenance := runProvenance(base, []models.GeneratedFile{
+		{Path: "./internal/models/user.go", Content: "package models\n"},
+		{Path: "go.mod"},
+	}, coder, "not a reporter")
+
+	sum := sha256.Sum256([]byte(client.prompts[0] + "\x00"))
+	assert.Equal(t, map[string]string{"internal/models/user.go": hex.EncodeToString(sum[:])}, provenance.PromptHashes)
+	assert.Equal(t, "mock-model", provenance.Model)
+	assert.Nil(t, base.PromptHashes, "the base provenance is not modified")
+
+	// Each file records the task that generated it and its estimated tokens
+	require.Contains(t, provenance.Files, "internal/models/user.go")
+	generated := provenance.Files["internal/models/user.go"]
+	assert.Equal(t, "user", generated.TaskID)
+	assert.Equal(t, int64(len(client.prompts[0])/4), generated.InputTokens)
+	assert.Equal(t, int64(len("package models\n")/4), generated.OutputTokens)
+	assert.False(t, generated.StartedAt.IsZero())
+	assert.NotContains(t, provenance.Files, "go.mod", "templates are not prompted")
+
+	// The same prompt hashes the same, so a changed hash means a changed prompt
+	again := &scriptedLLMClient{responses: []string{"package models"}}
+	coder2, err := NewCoder(CoderConfig{LLMClient: again})
+	require.NoError(t, err)
+	_, err = coder2.GenerateFile(context.Background(), plan.Phases[0].Tasks[0], plan, nil)
+	require.NoError(t, err)
+	assert.Equal(t, provenance.PromptHashes, runProvenance(base, []models.GeneratedFile{{Path: "internal/models/user.go"}}, coder2).PromptHashes)
+}
+
+func TestPromptLog_Retries(t *testing.T) {
+	var prompts promptLog
+	prompts.record("test:internal/app/app.go", "internal/app/app_test.go", "first prompt")
+	first := prompts.Prompts()["internal/app/app_test.go"]
+	prompts.record("test:internal/app/app.go", "./internal/app/app_test.go", "second, longer prompt")
+
+	record := prompts.Prompts()["internal/app/app_test.go"]
+	assert.NotEqual(t, first.Hash, record.Hash, "the last prompt is kept")
+	assert.Equal(t, first.SentAt, record.SentAt, "generation started with the first prompt")
+	assert.Equal(t, int64(len("first prompt")/4+len("second, longer prompt")/4), record.InputTokens)
+	assert.Equal(t, "test:internal/app/app.go", record.TaskID)
+}
@@ -0,0 +1,153 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/validate"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// repairFormat is the format under which build repairs are remembered in the
+// fix knowledge base
+const repairFormat = "Go"
+
+// maxRepairErrors caps the compile errors of one file quoted in its repair prompt
+const maxRepairErrors = 20
+
+// BuildRepairer regenerates the files of a project that fail to compile
+type BuildRepairer interface {
+	// Repair feeds the compile errors of a failed build back to the model
+	// one file at a time and rebuilds, until the project builds or the
+	// attempts run out
+	Repair(ctx context.Context, projectRoot string, build *models.BuildResult) (*BuildRepairResult, error)
+}
+
+// BuildRepairConfig contains configuration for creating a build repairer
+type BuildRepairConfig struct {
+	LLMClient llm.Client
+	Validator validate.BuildValidator
+	FileOps   fsops.FileOps // Rooted at the project; writes the repaired files
+
+	// MaxAttempts bounds the rebuilds after repairs (default 2)
+	MaxAttempts int
+
+	// Preamble is organization-wide guidance placed ahead of every prompt
+	Preamble string
+
+	// Knowledge remembers repairs that compile and suggests them when the
+	// same error recurs (optional)
+	Knowledge *FixKnowledge
+}
+
+// BuildRepairResult is the outcome of a build repair
+type BuildRepairResult struct {
+	Build    *models.BuildResult // Last build result
+	Attempts int                 // Repair rounds run
+	Repaired []string            // Files rewritten, in order of first repair
+}
+
+// llmBuildRepairer implements BuildRepairer with the coder model
+type llmBuildRepairer struct {
+	client      llm.Client
+	validator   validate.BuildValidator
+	fileOps     fsops.FileOps
+	maxAttempts int
+	preamble    string
+	knowledge   *FixKnowledge
+}
+
+// NewBuildRepairer creates a new BuildRepairer instance
+func NewBuildRepairer(cfg BuildRepairConfig) (BuildRepairer, error) {
+	if cfg.LLMClient == nil {
+		return nil, fmt.Errorf("LLM client is required")
+	}
+	if cfg.Validator == nil {
+		return nil, fmt.Errorf("build validator is required")
+	}
+	if cfg.FileOps == nil {
+		return nil, fmt.Errorf("file operations handler is required")
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 2
+	}
+	return &llmBuildRepairer{
+		client:      cfg.LLMClient,
+		validator:   cfg.Validator,
+		fileOps:     cfg.FileOps,
+		maxAttempts: cfg.MaxAttempts,
+		preamble:    cfg.Preamble,
+		knowledge:   cfg.Knowledge,
+	}, nil
+}
+
+// Repair implements BuildRepairer. Each round regenerates every Go file the
+// last build reported errors in, then rebuilds. A regenerated file that does
+// not parse is discarded. Repair stops early when a round changes nothing.
+func (r *llmBuildRepairer) Repair(ctx context.Context, projectRoot string, build *models.BuildResult) (*BuildRepairResult, error) {
+	modulePath := r.modulePath(ctx)
+	loop := &repairLoop[*models.BuildResult, models.CompilationError]{
+		kind:        "build",
+		client:      r.client,
+		fileOps:     r.fileOps,
+		maxAttempts: r.maxAttempts,
+		validate: func(ctx context.Context) (*models.BuildResult, error) {
+			return r.validator.Validate(ctx, projectRoot)
+		},
+		passed:  func(build *models.BuildResult) bool { return build.Success },
+		targets: buildRepairTargets,
+		prompt: func(target repairTarget[models.CompilationError], content, knownFix string) string {
+			return r.buildRepairPrompt(target, content, modulePath, knownFix)
+		},
+		knowledge: r.knowledge,
+		format:    repairFormat,
+		signature: func(e models.CompilationError) string { return e.Message },
+	}
+
+	outcome, err := loop.run(ctx, build)
+	return &BuildRepairResult{Build: outcome.last, Attempts: outcome.attempts, Repaired: outcome.repaired}, err
+}
+
+// buildRepairPrompt constructs the LLM prompt that fixes a file's compile errors
+func (r *llmBuildRepairer) buildRepairPrompt(target repairTarget[models.CompilationError], content, modulePath, knownFix string) string {
+	task := fmt.Sprintf("Fix the compile errors `go build` reports for %s", target.file)
+	if modulePath != "" {
+		task += fmt.Sprintf(" in module %s", modulePath)
+	}
+
+	errs := make([]string, len(target.problems))
+	for i, e := range target.problems {
+		errs[i] = fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+	}
+
+	return repairPrompt{
+		role:     "an expert Go developer fixing a file that does not compile",
+		task:     task + ".",
+		heading:  "Compile Errors",
+		problems: errs,
+		noun:     "error",
+		knownFix: knownFix,
+		files:    []repairPromptFile{{heading: "Current File", content: content}},
+		requirements: []string{
+			"Fix every error listed; keep the package clause and all behavior that compiles unchanged",
+			"Do not remove exported declarations: other files may use them",
+			"Add or remove imports as the code needs; import project packages under the module path",
+		},
+	}.build(r.preamble)
+}
+
+// modulePath returns the module path declared in the project's go.mod, or ""
+func (r *llmBuildRepairer) modulePath(ctx context.Context) string {
+	content, err := r.fileOps.ReadFile(ctx, "go.mod")
+	if err != nil {
+		return ""
+	}
+	return parseModulePath(content)
+}
+
+// buildRepairTargets returns the Go source files a build reported errors in
+func buildRepairTargets(build *models.BuildResult) []repairTarget[models.CompilationError] {
+	return repairTargets(build.Errors, func(e models.CompilationError) string { return e.File })
+}
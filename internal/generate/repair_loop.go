@@ -0,0 +1,325 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/promptguard"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// repairTarget is a file a validator reported problems in
+type repairTarget[P any] struct {
+	file     string // Relative to the project root, slash-separated
+	problems []P
+}
+
+// repairLoop drives the build, lint and test repairs. Each round asks the
+// model to correct every file the last result reports problems in, writes
+// back the corrections that parse and validates again, until the result
+// passes, a round changes nothing or the attempts run out. R is the result
+// type of the validator and P the type of the problems it reports.
+type repairLoop[R, P any] struct {
+	kind        string // What is repaired: build, lint or test
+	client      llm.Client
+	fileOps     fsops.FileOps
+	maxAttempts int
+
+	validate func(ctx context.Context) (R, error)
+	passed   func(result R) bool
+	targets  func(result R) []repairTarget[P]
+
+	// prompt builds the request that corrects a target, given the file's
+	// content and a remembered fix of its first problem, if any
+	prompt func(target repairTarget[P], content, knownFix string) string
+
+	// redirect, when set, returns the file a response corrects, which can be
+	// another than the target, and the response without what named it
+	redirect func(target repairTarget[P], code string) (string, string, error)
+
+	// check, when set, runs after the writes of each round; a round it
+	// rejects is rolled back and ends the repair
+	check func(ctx context.Context) (bool, error)
+
+	// knowledge, when set, remembers the repairs that clear a file's
+	// problems under format, by the signature of its first problem
+	knowledge *FixKnowledge
+	format    string
+	signature func(problem P) string
+}
+
+// repairOutcome is where a repairLoop stopped
+type repairOutcome[R any] struct {
+	last     R        // Last validation result
+	attempts int      // Repair rounds run
+	repaired []string // Files rewritten, in order of first repair
+}
+
+// run repairs the problems of result
+func (l *repairLoop[R, P]) run(ctx context.Context, result R) (*repairOutcome[R], error) {
+	outcome := &repairOutcome[R]{last: result}
+	seen := make(map[string]bool)
+
+	for outcome.attempts < l.maxAttempts && !l.passed(outcome.last) {
+		if err := ctx.Err(); err != nil {
+			return outcome, fmt.Errorf("%s repair interrupted: %w", l.kind, err)
+		}
+
+		targets := l.targets(outcome.last)
+		if len(targets) == 0 {
+			break
+		}
+		outcome.attempts++
+
+		// The content each file had before this round, and its first problem
+		before := make(map[string]string)
+		problems := make(map[string]string)
+		var written []string
+		for _, target := range targets {
+			file, original, code, err := l.repairFile(ctx, target)
+			if err != nil {
+				if ctx.Err() != nil {
+					return outcome, fmt.Errorf("%s repair interrupted: %w", l.kind, ctx.Err())
+				}
+				logctx.Logger(ctx).Warn().
+					Err(err).
+					Str("file", target.file).
+					Int("attempt", outcome.attempts).
+					Msgf("Failed to repair %s problems; keeping file", l.kind)
+				continue
+			}
+			if err := l.fileOps.WriteFile(ctx, file, code); err != nil {
+				// Protected paths are owned by people and stay as they are
+				logctx.Logger(ctx).Warn().
+					Err(err).
+					Str("file", file).
+					Msg("Failed to write repaired file; keeping it")
+				continue
+			}
+			if _, ok := before[file]; !ok {
+				before[file] = original
+				written = append(written, file)
+			}
+			if l.signature != nil {
+				problems[file] = l.signature(target.problems[0])
+			}
+		}
+		if len(written) == 0 {
+			break
+		}
+
+		if l.check != nil {
+			ok, err := l.check(ctx)
+			if err != nil || !ok {
+				l.rollBack(ctx, before)
+				if err != nil {
+					return outcome, fmt.Errorf("check after %s repair failed: %w", l.kind, err)
+				}
+				logctx.Logger(ctx).Warn().
+					Int("attempt", outcome.attempts).
+					Strs("files", written).
+					Msgf("The %s repairs broke the project; rolled them back", l.kind)
+				break
+			}
+		}
+		for _, file := range written {
+			if !seen[file] {
+				seen[file] = true
+				outcome.repaired = append(outcome.repaired, file)
+			}
+		}
+
+		result, err := l.validate(ctx)
+		if err != nil {
+			return outcome, fmt.Errorf("%s rerun after repair failed: %w", l.kind, err)
+		}
+		outcome.last = result
+
+		remaining := l.targets(result)
+		logctx.Logger(ctx).Info().
+			Int("attempt", outcome.attempts).
+			Int("files", len(written)).
+			Int("remaining_files", len(remaining)).
+			Msgf("Validated %s again after repairing files", l.kind)
+
+		l.remember(ctx, remaining, before, problems)
+	}
+
+	return outcome, nil
+}
+
+// repairFile asks the model to correct a target and returns the file the
+// response corrects, with its content before and after
+func (l *repairLoop[R, P]) repairFile(ctx context.Context, target repairTarget[P]) (string, string, string, error) {
+	content, err := l.fileOps.ReadFile(ctx, target.file)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var knownFix string
+	if l.knowledge != nil {
+		if known, ok := l.knowledge.Lookup(l.format, l.signature(target.problems[0])); ok {
+			knownFix = known.Fix
+			logctx.Logger(ctx).Debug().
+				Str("file", target.file).
+				Str("signature", known.Signature).
+				Msg("Including a remembered fix in the repair request")
+		}
+	}
+
+	response, err := l.client.Generate(ctx, l.prompt(target, content, knownFix))
+	if err != nil {
+		return "", "", "", fmt.Errorf("LLM %s repair failed: %w", l.kind, err)
+	}
+
+	file, original, code := target.file, content, cleanDocResponse(response)
+	if l.redirect != nil {
+		if file, code, err = l.redirect(target, code); err != nil {
+			return "", "", "", err
+		}
+		if file != target.file {
+			if original, err = l.fileOps.ReadFile(ctx, file); err != nil {
+				return "", "", "", fmt.Errorf("failed to read file: %w", err)
+			}
+		}
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), path.Base(file), code, parser.AllErrors); err != nil {
+		return "", "", "", fmt.Errorf("repaired %s does not parse: %w", file, err)
+	}
+	return file, original, code + "\n", nil
+}
+
+// rollBack restores the files of a round to their content before it
+func (l *repairLoop[R, P]) rollBack(ctx context.Context, before map[string]string) {
+	for file, original := range before {
+		if err := l.fileOps.WriteFile(ctx, file, original); err != nil {
+			logctx.Logger(ctx).Error().
+				Err(err).
+				Str("file", file).
+				Msg("Failed to roll back repaired file")
+		}
+	}
+}
+
+// remember records the repairs of files no target remains for
+func (l *repairLoop[R, P]) remember(ctx context.Context, remaining []repairTarget[P], before, problems map[string]string) {
+	if l.knowledge == nil {
+		return
+	}
+	failing := make(map[string]bool, len(remaining))
+	for _, target := range remaining {
+		failing[target.file] = true
+	}
+	for file, original := range before {
+		if failing[file] {
+			continue
+		}
+		current, err := l.fileOps.ReadFile(ctx, file)
+		if err != nil {
+			continue
+		}
+		if err := l.knowledge.Record(l.format, problems[file], original, current); err != nil {
+			logctx.Logger(ctx).Warn().Err(err).Msg("Failed to record fix")
+		}
+	}
+}
+
+// repairTargets groups problems by the Go source file fileOf reports them
+// in, sorted by file. Problems without a file in the project, such as a
+// failed module download, cannot be repaired by regenerating a file.
+func repairTargets[P any](problems []P, fileOf func(problem P) string) []repairTarget[P] {
+	index := make(map[string]int)
+	var targets []repairTarget[P]
+	for _, problem := range problems {
+		file := filepath.ToSlash(filepath.Clean(fileOf(problem)))
+		if !strings.HasSuffix(file, ".go") || strings.HasPrefix(file, "../") || filepath.IsAbs(file) {
+			continue
+		}
+		i, ok := index[file]
+		if !ok {
+			i = len(targets)
+			index[file] = i
+			targets = append(targets, repairTarget[P]{file: file})
+		}
+		targets[i].problems = append(targets[i].problems, problem)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].file < targets[j].file })
+	return targets
+}
+
+// repairPrompt lays out a repair request: the task, the problems and a
+// remembered fix of the first, the files and the requirements
+type repairPrompt struct {
+	role         string // Who the model is, such as "an expert Go developer fixing failing tests"
+	task         string
+	heading      string // Of the problems, such as "Compile Errors"
+	problems     []string
+	noun         string // One problem, as in "The first error was fixed"
+	knownFix     string
+	files        []repairPromptFile
+	requirements []string
+}
+
+// repairPromptFile is a file quoted in a repair request
+type repairPromptFile struct {
+	heading string
+	content string
+	omitted bool // Listed by name only
+}
+
+// build renders the prompt with the preamble ahead of it
+func (p repairPrompt) build(preamble string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("You are %s.\n\n", p.role))
+	sb.WriteString("# Task\n")
+	sb.WriteString(p.task + "\n\n")
+
+	sb.WriteString(promptguard.Instructions)
+
+	sb.WriteString(fmt.Sprintf("# %s\n", p.heading))
+	var report strings.Builder
+	for i, problem := range p.problems {
+		if i == maxRepairErrors {
+			report.WriteString(fmt.Sprintf("... and %d more\n", len(p.problems)-maxRepairErrors))
+			break
+		}
+		report.WriteString(problem + "\n")
+	}
+	sb.WriteString(promptguard.Fence(report.String()))
+	sb.WriteString("\n")
+
+	if p.knownFix != "" {
+		sb.WriteString(fmt.Sprintf("The first %s was fixed in an earlier file by removing (-) and adding (+) these lines:\n\n", p.noun))
+		sb.WriteString(promptguard.Fence(p.knownFix))
+		sb.WriteString("Apply the equivalent change to this file if it fits.\n\n")
+	}
+
+	for _, file := range p.files {
+		if file.omitted {
+			sb.WriteString(fmt.Sprintf("# %s (not shown)\n\n", file.heading))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("# %s\n", file.heading))
+		sb.WriteString(promptguard.Fence(file.content))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("# Requirements\n")
+	for _, requirement := range p.requirements {
+		sb.WriteString("- " + requirement + "\n")
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("Return ONLY the complete corrected Go source file, without markdown formatting or explanations.\n")
+
+	return withPreamble(preamble, sb.String())
+}
@@ -0,0 +1,138 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// repairProjectFiles is the project the repair tests start from
+var repairProjectFiles = map[string]string{
+	"go.mod":                  "module example.com/shop\n\ngo 1.22\n",
+	"main.go":                 "package main\n\nfunc main() { brokenCall() }\n",
+	"internal/store/store.go": "package store\n\nvar Items = brokenCall()\n",
+
+	"internal/cart/cart.go":      "package cart\n\nfunc Total(items []int) int { return 1 } // wrongTotal\n",
+	"internal/cart/cart_test.go": "package cart\n\nimport \"testing\"\n\nfunc TestTotal(t *testing.T) {\n\tt.Run(\"empty\", func(t *testing.T) {\n\t\tif got := Total(nil); got != 0 {\n\t\t\tt.Errorf(\"got %d, want 0\", got)\n\t\t}\n\t})\n}\n",
+}
+
+func newRepairProject(t *testing.T) (string, fsops.FileOps) {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range repairProjectFiles {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0o600))
+	}
+	fileOps, err := fsops.New(fsops.Config{RootDir: root})
+	require.NoError(t, err)
+	return root, fileOps
+}
+
+// markerValidator counts its runs and finds the Go files of the repair
+// project that still contain its marker
+type markerValidator struct {
+	marker string
+	runs   int
+}
+
+func (v *markerValidator) marked(projectRoot string) ([]string, error) {
+	v.runs++
+	var files []string
+	for name := range repairProjectFiles {
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(projectRoot, name))
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(string(content), v.marker) {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// markerBuildValidator fails the build for every file that still contains
+// its marker
+type markerBuildValidator struct{ markerValidator }
+
+func (v *markerBuildValidator) Validate(_ context.Context, projectRoot string) (*models.BuildResult, error) {
+	files, err := v.marked(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.BuildResult{Success: len(files) == 0}
+	for _, file := range files {
+		result.Errors = append(result.Errors, models.CompilationError{File: file, Line: 3, Column: 2, Message: "undefined: " + v.marker})
+	}
+	return result, nil
+}
+
+// markerLintValidator reports an issue for every file that still contains
+// its marker
+type markerLintValidator struct{ markerValidator }
+
+func (v *markerLintValidator) Validate(_ context.Context, projectRoot string) (*models.LintResult, error) {
+	files, err := v.marked(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.LintResult{Success: len(files) == 0}
+	for _, file := range files {
+		result.Issues = append(result.Issues, models.LintIssue{
+			File: file, Line: 3, Column: 1, Severity: "error", Rule: "errcheck",
+			Message: "Error return value of `" + v.marker + "` is not checked",
+		})
+	}
+	return result, nil
+}
+
+// markerTestValidator fails TestTotal for as long as a file still contains
+// its marker
+type markerTestValidator struct{ markerValidator }
+
+func (v *markerTestValidator) Validate(_ context.Context, projectRoot string) (*models.TestResult, error) {
+	files, err := v.marked(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return &models.TestResult{Success: true, TotalTests: 1, PassedTests: 1}, nil
+	}
+	return &models.TestResult{
+		TotalTests:  1,
+		FailedTests: 1,
+		Failures: []models.TestFailure{{
+			Package:  "example.com/shop/internal/cart",
+			Test:     "TestTotal/empty",
+			Message:  "cart_test.go:8: got 1, want 0",
+			Location: "cart_test.go:8",
+		}},
+	}, nil
+}
+
+func TestRepairTargets(t *testing.T) {
+	errs := []models.CompilationError{
+		{File: "unknown", Message: "build failed: go: module not found"},
+		{File: "./b.go", Line: 1},
+		{File: "a.go", Line: 2},
+		{File: "b.go", Line: 3},
+		{File: "../outside.go", Line: 1},
+	}
+
+	targets := repairTargets(errs, func(e models.CompilationError) string { return e.File })
+	require.Len(t, targets, 2)
+	assert.Equal(t, "a.go", targets[0].file)
+	assert.Equal(t, "b.go", targets[1].file)
+	assert.Len(t, targets[1].problems, 2)
+}
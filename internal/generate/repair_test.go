@@ -0,0 +1,88 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRepairer_Repair(t *testing.T) {
+	root, fileOps := newRepairProject(t)
+	validator := &markerBuildValidator{markerValidator{marker: "brokenCall"}}
+	client := &scriptedLLMClient{responses: []string{
+		"```go\npackage store\n\nvar Items = []string{}\n```",
+		"package main\n\nfunc main() {}",
+	}}
+	knowledge, err := LoadFixKnowledge(filepath.Join(t.TempDir(), "fixes.json"))
+	require.NoError(t, err)
+
+	repairer, err := NewBuildRepairer(BuildRepairConfig{
+		LLMClient: client,
+		Validator: validator,
+		FileOps:   fileOps,
+		Knowledge: knowledge,
+	})
+	require.NoError(t, err)
+
+	build, err := validator.Validate(context.Background(), root)
+	require.NoError(t, err)
+	require.False(t, build.Success)
+
+	result, err := repairer.Repair(context.Background(), root, build)
+	require.NoError(t, err)
+	assert.True(t, result.Build.Success)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, []string{"internal/store/store.go", "main.go"}, result.Repaired)
+	assert.Equal(t, 2, validator.runs)
+
+	// Each prompt carries the file, its errors and the module path
+	require.Len(t, client.prompts, 2)
+	assert.Contains(t, client.prompts[0], "internal/store/store.go:3:2: undefined: brokenCall")
+	assert.Contains(t, client.prompts[0], "var Items = brokenCall()")
+	assert.Contains(t, client.prompts[0], "example.com/shop")
+
+	store, err := os.ReadFile(filepath.Join(root, "internal/store/store.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package store\n\nvar Items = []string{}\n", string(store))
+
+	// Repairs that compile are remembered
+	assert.Equal(t, 1, knowledge.Len(), "both files had the same problem signature")
+}
+
+func TestBuildRepairer_AttemptsRunOut(t *testing.T) {
+	root, fileOps := newRepairProject(t)
+	validator := &markerBuildValidator{markerValidator{marker: "brokenCall"}}
+
+	// The model keeps the error; an unparsable response is discarded
+	client := &scriptedLLMClient{responses: []string{
+		"package store\n\nvar Items = brokenCall()\n",
+		"func main() {",
+		"package store\n\nvar Items = brokenCall()\n",
+		"func main() {",
+	}}
+	repairer, err := NewBuildRepairer(BuildRepairConfig{
+		LLMClient:   client,
+		Validator:   validator,
+		FileOps:     fileOps,
+		MaxAttempts: 2,
+	})
+	require.NoError(t, err)
+
+	build, err := validator.Validate(context.Background(), root)
+	require.NoError(t, err)
+
+	result, err := repairer.Repair(context.Background(), root, build)
+	require.NoError(t, err)
+	assert.False(t, result.Build.Success)
+	assert.Equal(t, 2, result.Attempts)
+	assert.Len(t, client.prompts, 4)
+	assert.Equal(t, 3, validator.runs)
+
+	main, err := os.ReadFile(filepath.Join(root, "main.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(main), "func main() { brokenCall() }", "an unparsable repair is not written")
+}
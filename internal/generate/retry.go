@@ -0,0 +1,303 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/dshills/gocreator/pkg/postprocess"
+)
+
+// DefaultRetryAttempts is how often each failed task is attempted again
+const DefaultRetryAttempts = 2
+
+// testTaskPrefix starts the IDs given to test tasks, which the plan does not name
+const testTaskPrefix = "test:"
+
+// FailedTask is a planned file a finished run did not produce because its
+// task failed and was skipped, as test files are
+type FailedTask struct {
+	ID     string // Plan task ID, or "test:<source file>" for a test file
+	Phase  string // generate_packages or generate_tests
+	Target string // Slash-separated, relative to the output directory
+	Source string // Source file a test covers
+
+	task models.GenerationTask
+}
+
+// RetryResult is the outcome of retrying one failed task
+type RetryResult struct {
+	Task     FailedTask
+	Attempts int
+	Err      error // Last error; nil once the file is written
+}
+
+// RetryConfig configures RetryFailedTasks
+type RetryConfig struct {
+	LLMClient llm.Client
+	FileOps   fsops.FileOps
+	OutputDir string
+
+	// Attempts per task (default: DefaultRetryAttempts)
+	Attempts int
+
+	// Preamble is organization-wide guidance placed ahead of every prompt
+	Preamble string
+
+	// PromptTemplates overrides sections of the coder prompt (optional)
+	PromptTemplates *PromptTemplates
+
+	// Style is the house style of coder and tester prompts (optional)
+	Style models.StyleGuide
+
+	// PostProcess holds the custom transforms run over generated Go files (optional)
+	PostProcess *postprocess.Pipeline
+
+	// FixKnowledge is the fix knowledge base shared by runs (optional)
+	FixKnowledge *FixKnowledge
+
+	// GeneratorVersion is recorded in the manifest for the written files
+	GeneratorVersion string
+
+	// Temperature is the sampling temperature of LLMClient, recorded in the manifest
+	Temperature float64
+}
+
+// LoadFinishedRun replays the state log of a run, which must have reached
+// apply_patches without an error
+func LoadFinishedRun(outputDir, runID string) (GenerationState, error) {
+	transitions, err := LoadStateTransitions(outputDir, runID)
+	if err != nil {
+		return GenerationState{}, err
+	}
+	if len(transitions) == 0 {
+		return GenerationState{}, fmt.Errorf("run %s has no recorded transitions", runID)
+	}
+
+	state := ReplayState(transitions, len(transitions)-1)
+	finished := false
+	for _, phase := range state.CompletedPhases {
+		finished = finished || phase == "apply_patches"
+	}
+	if state.Error != nil || !finished || state.Plan == nil {
+		return GenerationState{}, fmt.Errorf("run %s did not finish; continue it with 'gocreator resume %s' instead", runID, runID)
+	}
+	return state, nil
+}
+
+// FindFailedTasks returns the tasks of a finished run whose files are neither
+// in outputDir nor in its manifest: code files first, in plan order, then the
+// tests of the planned source files. Files recorded in the manifest and since
+// deleted by people are not failures.
+func FindFailedTasks(state GenerationState, outputDir string) ([]FailedTask, error) {
+	if state.Plan == nil {
+		return nil, fmt.Errorf("run has no generation plan")
+	}
+	manifest, err := LoadManifest(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := func(file string) bool {
+		slashed := filepath.ToSlash(filepath.Clean(file))
+		if manifest != nil {
+			if _, ok := manifest.Files[slashed]; ok {
+				return false
+			}
+		}
+		_, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(slashed)))
+		return os.IsNotExist(err)
+	}
+
+	var failed []FailedTask
+	codeFailed := make(map[string]bool)
+	for _, phase := range state.Plan.Phases {
+		for _, task := range phase.Tasks {
+			if task.Type != "generate_file" || task.TargetPath == "" || !missing(task.TargetPath) {
+				continue
+			}
+			target := filepath.ToSlash(filepath.Clean(task.TargetPath))
+			codeFailed[target] = true
+			failed = append(failed, FailedTask{ID: task.ID, Phase: "generate_packages", Target: target, task: task})
+		}
+	}
+
+	tester := &llmTester{}
+	for _, source := range tester.getSourceFiles(state.Plan) {
+		testFile := filepath.ToSlash(tester.getTestFilePath(source))
+		if !missing(testFile) {
+			continue
+		}
+		source = filepath.ToSlash(filepath.Clean(source))
+		if !codeFailed[source] && missing(source) {
+			// Neither planned as a task nor on disk; nothing to test
+			continue
+		}
+		failed = append(failed, FailedTask{ID: testTaskPrefix + source, Phase: "generate_tests", Target: testFile, Source: source})
+	}
+	return failed, nil
+}
+
+// RetryFailedTasks attempts each failed task again with fresh LLM calls and
+// merges the files it produces into the output directory, its manifest and,
+// when the project has one, its incremental state. Code is retried before
+// tests, so a test is written against the code retried in the same call.
+// Tasks that fail every attempt are reported and left for another retry.
+func RetryFailedTasks(ctx context.Context, cfg RetryConfig, state GenerationState, tasks []FailedTask) ([]RetryResult, error) {
+	if cfg.LLMClient == nil {
+		return nil, fmt.Errorf("LLM client is required")
+	}
+	if cfg.FileOps == nil {
+		return nil, fmt.Errorf("file operations handler is required")
+	}
+	if cfg.Attempts <= 0 {
+		cfg.Attempts = DefaultRetryAttempts
+	}
+
+	coder, err := NewCoder(CoderConfig{
+		LLMClient:       cfg.LLMClient,
+		Preamble:        cfg.Preamble,
+		FixKnowledge:    cfg.FixKnowledge,
+		PromptTemplates: cfg.PromptTemplates,
+		Style:           cfg.Style,
+		PostProcess:     cfg.PostProcess,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create coder: %w", err)
+	}
+	tester := &llmTester{client: cfg.LLMClient, preamble: cfg.Preamble, style: cfg.Style, postprocess: cfg.PostProcess, maxParallel: 1}
+
+	var requirements []models.FunctionalRequirement
+	if state.FCS != nil {
+		requirements = state.FCS.Requirements.Functional
+	}
+	assignments := assignRequirements(tester.getSourceFiles(state.Plan), state.Plan, requirements)
+	framework := testFramework(state.FCS, cfg.Style.TestFramework)
+
+	results := make([]RetryResult, 0, len(tasks))
+	var patches []models.Patch
+	var files []models.GeneratedFile
+	var predictor SignaturePredictor
+
+	for _, phase := range []string{"generate_packages", "generate_tests"} {
+		for _, task := range tasks {
+			if task.Phase != phase {
+				continue
+			}
+			if phase == "generate_tests" && predictor == nil {
+				// Read after the code retries so tests see the retried API
+				predictor = NewPreviousOutputPredictor(cfg.OutputDir)
+			}
+
+			taskCtx := logctx.WithTaskID(ctx, task.ID)
+			result := RetryResult{Task: task}
+			var content string
+			for result.Attempts < cfg.Attempts {
+				result.Attempts++
+				var patch models.Patch
+				if phase == "generate_packages" {
+					patch, result.Err = coder.GenerateFile(taskCtx, task.task, state.Plan, state.FCS)
+					content = extractContentFromDiff(patch.Diff)
+				} else {
+					api, _ := predictor.PredictAPI(path.Dir(task.Source))
+					patch, content, result.Err = tester.generateTestFile(taskCtx, task.Source, state.Plan, assignments[task.Source], nil, api, framework)
+				}
+				if result.Err == nil {
+					patch.TargetFile = task.Target
+					patches = append(patches, patch)
+					break
+				}
+				if ctx.Err() != nil {
+					return results, ctx.Err()
+				}
+				logctx.Logger(taskCtx).Warn().
+					Err(result.Err).
+					Int("attempt", result.Attempts).
+					Str("target", task.Target).
+					Msg("Retry attempt failed")
+			}
+
+			if result.Err == nil {
+				if err := cfg.FileOps.WriteFile(taskCtx, task.Target, content); err != nil {
+					result.Err = fmt.Errorf("failed to write %s: %w", task.Target, err)
+					patches = patches[:len(patches)-1]
+				} else {
+					file := models.GeneratedFile{
+						Path:        task.Target,
+						Content:     content,
+						Checksum:    cfg.FileOps.GenerateChecksum(content),
+						GeneratedAt: time.Now(),
+						Generator:   "retry-failed",
+					}
+					if patches[len(patches)-1].Template {
+						file.Generator = generatorTemplate
+					}
+					files = append(files, file)
+					logctx.Logger(taskCtx).Info().
+						Int("attempts", result.Attempts).
+						Str("target", task.Target).
+						Msg("Retried task succeeded")
+				}
+			}
+			results = append(results, result)
+		}
+	}
+
+	if len(files) > 0 {
+		provenance := runProvenance(models.Provenance{
+			GeneratorVersion: cfg.GeneratorVersion,
+			Provider:         cfg.LLMClient.Provider(),
+			Model:            cfg.LLMClient.Model(),
+			Temperature:      cfg.Temperature,
+		}, files, coder, tester)
+		if err := recordRetriedFiles(cfg, state.FCS, patches, files, provenance); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// recordRetriedFiles adds the retried files to the manifest and, when the
+// project was generated incrementally, to its incremental state
+func recordRetriedFiles(cfg RetryConfig, fcs *models.FinalClarifiedSpecification, patches []models.Patch, files []models.GeneratedFile, provenance *models.Provenance) error {
+	manifest, err := LoadManifest(cfg.OutputDir)
+	if err != nil {
+		return err
+	}
+	if manifest == nil {
+		manifest = NewManifest()
+	}
+	// Keep the FCS the rest of the manifest was generated from
+	recordedFCS := manifest.FCS
+	if recordedFCS == nil {
+		recordedFCS = fcs
+	}
+	manifest.Record(recordedFCS, files, provenance)
+	if err := manifest.Save(cfg.OutputDir); err != nil {
+		return err
+	}
+
+	stateManager := NewIncrementalStateManager(cfg.OutputDir)
+	if _, err := os.Stat(stateManager.stateFilePath); err != nil {
+		return nil
+	}
+	state, err := stateManager.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load incremental state: %w", err)
+	}
+	stateFCS := state.PreviousFCS
+	if stateFCS == nil {
+		stateFCS = fcs
+	}
+	if err := stateManager.UpdateState(stateFCS, patches, nil); err != nil {
+		return fmt.Errorf("failed to update incremental state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/langgraph-go/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordFinishedRun writes the state log of a run that generated plan
+func recordFinishedRun(t *testing.T, outputDir, runID string, plan *models.GenerationPlan) {
+	t.Helper()
+	recorder, err := newStateRecorder(outputDir, runID, GenerationState{FCS: &models.FinalClarifiedSpecification{ID: "fcs-shop"}})
+	require.NoError(t, err)
+	recorder.record("create_plan", time.Now(), graph.NodeResult[GenerationState]{
+		Delta: GenerationState{Plan: plan, CompletedPhases: []string{"create_plan"}},
+		Route: graph.Goto("generate_packages"),
+	})
+	recorder.record("apply_patches", time.Now(), graph.NodeResult[GenerationState]{
+		Delta: GenerationState{AllPatches: []models.Patch{}, CompletedPhases: []string{"apply_patches"}},
+		Route: graph.Goto("end"),
+	})
+	require.NoError(t, recorder.Close())
+}
+
+func retryTestPlan() *models.GenerationPlan {
+	return &models.GenerationPlan{
+		ID: "plan-shop",
+		FileTree: models.FileTree{Files: []models.File{
+			{Path: "internal/store/store.go"},
+			{Path: "internal/api/api.go"},
+		}},
+		Phases: []models.GenerationPhase{{
+			Name: "code",
+			Tasks: []models.GenerationTask{
+				{ID: "store", Type: "generate_file", TargetPath: "internal/store/store.go"},
+				{ID: "api", Type: "generate_file", TargetPath: "internal/api/api.go"},
+			},
+		}},
+	}
+}
+
+func TestRetryFailedTasks(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkspaceFile(t, filepath.Join(dir, "internal", "store", "store.go"), "package store\n\nfunc Open() {}\n")
+	recordFinishedRun(t, dir, "gen-1", retryTestPlan())
+
+	state, err := LoadFinishedRun(dir, "gen-1")
+	require.NoError(t, err)
+
+	failed, err := FindFailedTasks(state, dir)
+	require.NoError(t, err)
+	require.Len(t, failed, 3)
+	assert.Equal(t, FailedTask{ID: "api", Phase: "generate_packages", Target: "internal/api/api.go", task: retryTestPlan().Phases[0].Tasks[1]}, failed[0])
+	assert.Equal(t, "test:internal/store/store.go", failed[1].ID)
+	assert.Equal(t, "internal/store/store_test.go", failed[1].Target)
+	assert.Equal(t, "test:internal/api/api.go", failed[2].ID)
+
+	fileOps, err := fsops.New(fsops.Config{RootDir: dir})
+	require.NoError(t, err)
+	client := &scriptedLLMClient{responses: []string{"package api\n\nfunc Serve() {}\n", stdlibTest}}
+
+	results, err := RetryFailedTasks(context.Background(), RetryConfig{
+		LLMClient:        client,
+		FileOps:          fileOps,
+		OutputDir:        dir,
+		GeneratorVersion: "test",
+	}, state, failed)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	for _, result := range results {
+		assert.NoError(t, result.Err, result.Task.ID)
+		assert.Equal(t, 1, result.Attempts)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "internal", "api", "api.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "func Serve()")
+	assert.FileExists(t, filepath.Join(dir, "internal", "api", "api_test.go"))
+	assert.Contains(t, client.prompts[2], "func Serve()", "the API test is written against the retried code")
+
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Contains(t, manifest.Files, "internal/store/store_test.go")
+
+	failed, err = FindFailedTasks(state, dir)
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+}
+
+func TestRetryFailedTasks_KeepsFailures(t *testing.T) {
+	dir := t.TempDir()
+	writeWorkspaceFile(t, filepath.Join(dir, "internal", "store", "store.go"), "package store\n")
+	recordFinishedRun(t, dir, "gen-1", retryTestPlan())
+
+	state, err := LoadFinishedRun(dir, "gen-1")
+	require.NoError(t, err)
+	failed, err := FindFailedTasks(state, dir)
+	require.NoError(t, err)
+
+	fileOps, err := fsops.New(fsops.Config{RootDir: dir})
+	require.NoError(t, err)
+	results, err := RetryFailedTasks(context.Background(), RetryConfig{
+		LLMClient: &failingLLMClient{},
+		FileOps:   fileOps,
+		OutputDir: dir,
+		Attempts:  3,
+	}, state, failed[:1])
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Error(t, results[0].Err)
+	assert.Equal(t, 3, results[0].Attempts)
+	assert.NoFileExists(t, filepath.Join(dir, "internal", "api", "api.go"))
+}
+
+func TestLoadFinishedRun_Unfinished(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := newStateRecorder(dir, "gen-1", GenerationState{})
+	require.NoError(t, err)
+	recorder.record("create_plan", time.Now(), graph.NodeResult[GenerationState]{
+		Delta: GenerationState{Plan: retryTestPlan(), CompletedPhases: []string{"create_plan"}},
+		Route: graph.Goto("generate_packages"),
+	})
+	require.NoError(t, recorder.Close())
+
+	_, err = LoadFinishedRun(dir, "gen-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did not finish")
+}
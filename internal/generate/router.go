@@ -0,0 +1,144 @@
+package generate
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// httpHandlerStyle is how the handlers of an HTTP framework are declared and
+// read path parameters
+type httpHandlerStyle struct {
+	signature string
+	param     string
+}
+
+// httpHandlerStyles maps each HTTP framework to its handler style
+var httpHandlerStyles = map[string]httpHandlerStyle{
+	models.HTTPFrameworkStdlib: {signature: "(w http.ResponseWriter, r *http.Request)", param: `r.PathValue("id")`},
+	models.HTTPFrameworkChi:    {signature: "(w http.ResponseWriter, r *http.Request)", param: `chi.URLParam(r, "id")`},
+	models.HTTPFrameworkGin:    {signature: "(c *gin.Context)", param: `c.Param("id")`},
+	models.HTTPFrameworkEcho:   {signature: "(c echo.Context) error", param: `c.Param("id")`},
+}
+
+// httpDirs are the directories of packages that handle HTTP requests
+var httpDirs = map[string]bool{
+	"api": true, "handler": true, "handlers": true, "http": true, "httpapi": true,
+	"rest": true, "server": true, "transport": true, "web": true,
+}
+
+// planRouter adds the HTTP router, rendered from the template of the
+// architecture's HTTP framework, to the first phase when the FCS has HTTP
+// API contracts
+func planRouter(plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) {
+	if len(fcs.HTTPRoutes()) == 0 {
+		return
+	}
+	addTemplateFiles(plan, []models.File{{
+		Path:    templates.RouterPath,
+		Purpose: fmt.Sprintf("Routes the HTTP API with %s and serves it with graceful shutdown", fcs.Architecture.Framework()),
+	}})
+}
+
+// planOpenAPI adds openapi.yaml, rendered from the HTTP API contracts with
+// the boilerplate files, to the plan's file tree when the FCS has any. Tasks
+// the model planned for it are dropped.
+func planOpenAPI(plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) {
+	if len(fcs.HTTPRoutes()) == 0 {
+		return
+	}
+	for i := range plan.Phases {
+		plan.Phases[i].Tasks = slices.DeleteFunc(plan.Phases[i].Tasks, func(task models.GenerationTask) bool {
+			return normalizePath(task.TargetPath) == templates.OpenAPIPath
+		})
+	}
+	if i := slices.IndexFunc(plan.FileTree.Files, func(f models.File) bool {
+		return normalizePath(f.Path) == templates.OpenAPIPath
+	}); i >= 0 {
+		plan.FileTree.Files[i].GeneratedBy = "template"
+		return
+	}
+	plan.FileTree.Files = append(plan.FileTree.Files, models.File{
+		Path:        templates.OpenAPIPath,
+		Purpose:     "OpenAPI description of the HTTP API contracts",
+		GeneratedBy: "template",
+	})
+}
+
+// writeHTTPGuidelines tells the model the router is rendered from a template
+// and how the handlers and main are wired to it
+func writeHTTPGuidelines(sb *strings.Builder, fcs *models.FinalClarifiedSpecification) {
+	if len(fcs.HTTPRoutes()) == 0 {
+		return
+	}
+	framework := fcs.Architecture.Framework()
+
+	sb.WriteString(fmt.Sprintf("## HTTP Router (%s)\n", framework))
+	sb.WriteString(fmt.Sprintf("- %s is added to the plan; do not plan it. It declares the server.Handlers interface, "+
+		"with one method per HTTP API contract, NewRouter and Run, which shuts the server down gracefully\n", templates.RouterPath))
+	sb.WriteString(fmt.Sprintf("- %s describes the contracts and is rendered from them; do not plan it\n", templates.OpenAPIPath))
+	sb.WriteString("- Plan a handler type implementing server.Handlers, e.g. in internal/handler/handler.go, and its test\n")
+	sb.WriteString("- main passes the handlers and the cross-cutting middleware, outermost first, to server.NewRouter, " +
+		"and serves the router with server.Run under a context canceled on SIGINT and SIGTERM\n")
+	if framework != models.HTTPFrameworkStdlib {
+		sb.WriteString(fmt.Sprintf("- Do not plan another router or framework than %s\n", framework))
+	}
+	sb.WriteString("\n")
+}
+
+// routerScaffold returns the data of the HTTP router
+func (c *llmCoder) routerScaffold() (templates.ScaffoldKind, templates.ScaffoldData, bool) {
+	if len(c.routes) == 0 {
+		return "", templates.ScaffoldData{}, false
+	}
+
+	data := templates.ScaffoldData{Package: path.Base(path.Dir(templates.RouterPath)), Framework: c.framework}
+	for _, route := range c.routes {
+		data.Routes = append(data.Routes, templates.ScaffoldRoute{
+			Method:      route.Method,
+			Path:        route.Path,
+			Pattern:     route.Pattern(c.framework),
+			Handler:     route.Handler,
+			Description: strings.Join(strings.Fields(route.Description), " "),
+		})
+	}
+	return templates.ScaffoldRouter, data, true
+}
+
+// writeHTTPCodeGuidelines tells the model how the handlers and main of a
+// service with HTTP API contracts are wired to the rendered router
+func (c *llmCoder) writeHTTPCodeGuidelines(sb *strings.Builder, target string) {
+	target = normalizePath(target)
+	if len(c.routes) == 0 || target == templates.RouterPath || !isHTTPFile(target) {
+		return
+	}
+	style := httpHandlerStyles[c.framework]
+	server := templates.RouterPath
+	if c.modulePath != "" {
+		server = c.modulePath + "/" + path.Dir(templates.RouterPath)
+	}
+
+	sb.WriteString(fmt.Sprintf("## HTTP Framework (%s)\n", c.framework))
+	sb.WriteString(fmt.Sprintf("- Package server (%s) routes the requests to its Handlers interface and is rendered from a template; do not route requests elsewhere\n", server))
+	sb.WriteString(fmt.Sprintf("- Handlers implement server.Handlers, one method per route with the signature %s, named:", style.signature))
+	for _, route := range c.routes {
+		sb.WriteString(fmt.Sprintf(" %s (%s %s)", route.Handler, route.Method, route.Path))
+	}
+	sb.WriteString(fmt.Sprintf("\n- Read path parameters with %s\n", style.param))
+	sb.WriteString("- main builds the handlers, passes them and the cross-cutting middleware, outermost first, to server.NewRouter, " +
+		"and calls server.Run with a context from signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)\n\n")
+}
+
+// isHTTPFile reports whether a Go file may handle HTTP requests or start the
+// server: it is in package main or in a package named after HTTP handling
+func isHTTPFile(target string) bool {
+	if !strings.HasSuffix(target, ".go") || strings.HasSuffix(target, "_test.go") {
+		return false
+	}
+	dir := path.Dir(target)
+	return httpDirs[path.Base(dir)] || path.Base(target) == "main.go" || strings.HasPrefix(dir, "cmd/")
+}
@@ -0,0 +1,154 @@
+package generate
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func routerFixture(t *testing.T) (*llmCoder, *scriptedLLMClient, *models.GenerationPlan, *models.FinalClarifiedSpecification) {
+	t.Helper()
+	c, client, plan, fcs := scaffoldFixture(t)
+	fcs.APIContracts = []models.APIContract{
+		{Endpoint: "/users", Method: "GET", Description: "List the users"},
+		{Endpoint: "/users/:id", Method: "GET"},
+		{Endpoint: "/users", Method: "POST"},
+		{Protocol: "grpc", Service: "orders.v1.OrderService", Method: "CreateOrder"},
+	}
+	c.SetFCS(fcs)
+	return c, client, plan, fcs
+}
+
+func TestRenderScaffold_Router(t *testing.T) {
+	c, client, plan, fcs := routerFixture(t)
+	ctx := context.Background()
+	task := models.GenerationTask{ID: "router", Type: "generate_file", TargetPath: templates.RouterPath}
+
+	tests := []struct {
+		framework string
+		want      []string
+	}{
+		{framework: "", want: []string{
+			"GetUsers(w http.ResponseWriter, r *http.Request)",
+			`mux.HandleFunc("GET /users/{id}", h.GetUsersByID)`,
+			"return logger(recoverer(handler))",
+		}},
+		{framework: models.HTTPFrameworkChi, want: []string{
+			`"github.com/go-chi/chi/v5"`,
+			`r.MethodFunc("POST", "/users", h.PostUsers)`,
+			"r.Use(middleware...)",
+		}},
+		{framework: models.HTTPFrameworkGin, want: []string{
+			"GetUsersByID(c *gin.Context)",
+			`router.Handle("GET", "/users/:id", h.GetUsersByID)`,
+			"router.Use(gin.Logger(), gin.Recovery())",
+		}},
+		{framework: models.HTTPFrameworkEcho, want: []string{
+			"PostUsers(c echo.Context) error",
+			`e.Add("GET", "/users/:id", h.GetUsersByID)`,
+			"e.Use(echomiddleware.Logger(), echomiddleware.Recover())",
+		}},
+	}
+	for _, tt := range tests {
+		fcs.Architecture.HTTPFramework = tt.framework
+		c.SetFCS(fcs)
+
+		router, ok := c.renderScaffold(ctx, task, plan)
+		require.True(t, ok, tt.framework)
+		_, err := parser.ParseFile(token.NewFileSet(), "router.go", router, parser.AllErrors)
+		require.NoError(t, err, router)
+		assert.Contains(t, router, "package server")
+		assert.Contains(t, router, "// GetUsers handles GET /users: List the users")
+		assert.Contains(t, router, "func Run(ctx context.Context, addr string, handler http.Handler) error")
+		assert.Contains(t, router, "srv.Shutdown(shutdownCtx)")
+		for _, want := range tt.want {
+			assert.Contains(t, router, want, tt.framework)
+		}
+	}
+	assert.Empty(t, client.prompts)
+
+	fcs.APIContracts = nil
+	c.SetFCS(fcs)
+	_, _, ok := c.scaffoldFile(task, plan)
+	assert.False(t, ok, "no router without HTTP contracts")
+}
+
+func TestPlanRouter(t *testing.T) {
+	_, _, _, fcs := routerFixture(t)
+	plan := &models.GenerationPlan{
+		FileTree: models.FileTree{Files: []models.File{{Path: "cmd/shop/main.go"}}},
+		Phases:   []models.GenerationPhase{{Name: "setup", Order: 1}},
+	}
+
+	planRouter(plan, fcs)
+	require.Len(t, plan.FileTree.Files, 2)
+	assert.Equal(t, templates.RouterPath, plan.FileTree.Files[1].Path)
+	assert.Equal(t, "template", plan.FileTree.Files[1].GeneratedBy)
+	require.Len(t, plan.Phases[0].Tasks, 1)
+	assert.Equal(t, templates.RouterPath, plan.Phases[0].Tasks[0].TargetPath)
+
+	planRouter(plan, fcs)
+	assert.Len(t, plan.Phases[0].Tasks, 1, "planned once")
+
+	var sb strings.Builder
+	fcs.Architecture.HTTPFramework = models.HTTPFrameworkGin
+	writeHTTPGuidelines(&sb, fcs)
+	assert.Contains(t, sb.String(), "## HTTP Router (gin)")
+	assert.Contains(t, sb.String(), "server.Handlers")
+
+	sb.Reset()
+	empty := &models.GenerationPlan{}
+	planRouter(empty, &models.FinalClarifiedSpecification{})
+	writeHTTPGuidelines(&sb, &models.FinalClarifiedSpecification{})
+	assert.Empty(t, empty.Phases)
+	assert.Empty(t, sb.String())
+}
+
+func TestPlanOpenAPI(t *testing.T) {
+	_, _, _, fcs := routerFixture(t)
+	plan := &models.GenerationPlan{Phases: []models.GenerationPhase{{Name: "docs", Order: 1, Tasks: []models.GenerationTask{
+		{ID: "spec", Type: "generate_file", TargetPath: "openapi.yaml"},
+		{ID: "readme", Type: "generate_file", TargetPath: "docs/api.md"},
+	}}}}
+
+	planOpenAPI(plan, fcs)
+	assert.Equal(t, []models.File{{Path: templates.OpenAPIPath, Purpose: "OpenAPI description of the HTTP API contracts", GeneratedBy: "template"}}, plan.FileTree.Files)
+	require.Len(t, plan.Phases[0].Tasks, 1, "the model does not write the document")
+	assert.Equal(t, "docs/api.md", plan.Phases[0].Tasks[0].TargetPath)
+
+	planOpenAPI(plan, fcs)
+	assert.Len(t, plan.FileTree.Files, 1)
+
+	empty := &models.GenerationPlan{}
+	planOpenAPI(empty, &models.FinalClarifiedSpecification{})
+	assert.Empty(t, empty.FileTree.Files)
+}
+
+func TestWriteHTTPCodeGuidelines(t *testing.T) {
+	c, _, _, fcs := routerFixture(t)
+	fcs.Architecture.HTTPFramework = models.HTTPFrameworkEcho
+	c.SetFCS(fcs)
+
+	var sb strings.Builder
+	c.writeHTTPCodeGuidelines(&sb, "internal/handler/users.go")
+	assert.Contains(t, sb.String(), "(c echo.Context) error")
+	assert.Contains(t, sb.String(), "GetUsersByID (GET /users/{id})")
+	assert.Contains(t, sb.String(), "github.com/acme/shop/internal/server")
+
+	sb.Reset()
+	c.writeHTTPCodeGuidelines(&sb, "cmd/shop/main.go")
+	assert.Contains(t, sb.String(), "signal.NotifyContext")
+
+	sb.Reset()
+	c.writeHTTPCodeGuidelines(&sb, "internal/service/user_service.go")
+	c.writeHTTPCodeGuidelines(&sb, "internal/handler/users_test.go")
+	c.writeHTTPCodeGuidelines(&sb, templates.RouterPath)
+	assert.Empty(t, sb.String())
+}
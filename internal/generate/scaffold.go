@@ -0,0 +1,472 @@
+package generate
+
+import (
+	"context"
+	"go/token"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// postgresDirs are package directories holding PostgreSQL implementations
+var postgresDirs = map[string]bool{"postgres": true, "postgresql": true, "pg": true}
+
+// scaffoldReserved are names a scaffolded repository method already uses,
+// which a parameter named after the entity must not shadow
+var scaffoldReserved = map[string]bool{
+	"all": true, "conn": true, "ctx": true, "db": true, "err": true, "id": true, "n": true, "q": true, "r": true,
+	"result": true, "row": true, "rows": true,
+}
+
+// sqlTypes maps the Go types of stored fields to column types per dialect
+var sqlTypes = map[models.SQLDialect]map[string]string{
+	models.DialectPostgres: {
+		"string": "TEXT", "bool": "BOOLEAN", "[]byte": "BYTEA", "time.Time": "TIMESTAMPTZ",
+		"int": "BIGINT", "int64": "BIGINT", "uint": "BIGINT", "uint32": "BIGINT", "uint64": "BIGINT",
+		"int8": "SMALLINT", "int16": "SMALLINT", "uint8": "SMALLINT", "int32": "INTEGER", "uint16": "INTEGER",
+		"float32": "REAL", "float64": "DOUBLE PRECISION",
+	},
+	models.DialectMySQL: {
+		"string": "TEXT", "bool": "BOOLEAN", "[]byte": "BLOB", "time.Time": "DATETIME(6)",
+		"int": "BIGINT", "int64": "BIGINT", "uint": "BIGINT UNSIGNED", "uint32": "INT UNSIGNED", "uint64": "BIGINT UNSIGNED",
+		"int8": "TINYINT", "int16": "SMALLINT", "uint8": "TINYINT UNSIGNED", "int32": "INT", "uint16": "SMALLINT UNSIGNED",
+		"float32": "FLOAT", "float64": "DOUBLE",
+	},
+	models.DialectSQLite: {
+		"string": "TEXT", "bool": "BOOLEAN", "[]byte": "BLOB", "time.Time": "TIMESTAMP",
+		"int": "INTEGER", "int64": "INTEGER", "uint": "INTEGER", "uint32": "INTEGER", "uint64": "INTEGER",
+		"int8": "INTEGER", "int16": "INTEGER", "uint8": "INTEGER", "int32": "INTEGER", "uint16": "INTEGER",
+		"float32": "REAL", "float64": "REAL",
+	},
+}
+
+// sqlcTypes maps column types to the Go types sqlc gives NOT NULL columns of
+// them, per dialect
+var sqlcTypes = map[models.SQLDialect]map[string]string{
+	models.DialectPostgres: {
+		"TEXT": "string", "BOOLEAN": "bool", "BYTEA": "[]byte", "TIMESTAMPTZ": "time.Time",
+		"BIGINT": "int64", "INTEGER": "int32", "SMALLINT": "int16", "REAL": "float32", "DOUBLE PRECISION": "float64",
+	},
+	models.DialectMySQL: {
+		"TEXT": "string", "VARCHAR(255)": "string", "BOOLEAN": "bool", "BLOB": "[]byte", "DATETIME(6)": "time.Time",
+		"BIGINT": "int64", "BIGINT UNSIGNED": "uint64", "INT": "int32", "INT UNSIGNED": "uint32",
+		"SMALLINT": "int16", "SMALLINT UNSIGNED": "uint16", "TINYINT": "int8", "TINYINT UNSIGNED": "uint8",
+		"FLOAT": "float64", "DOUBLE": "float64",
+	},
+	models.DialectSQLite: {
+		"TEXT": "string", "BOOLEAN": "bool", "BLOB": "[]byte", "TIMESTAMP": "time.Time",
+		"INTEGER": "int64", "REAL": "float64",
+	},
+}
+
+// renderScaffold renders a planned file the data model fully determines
+// from its template, reporting false for files the model writes. A template
+// that fails is logged and the file left to the model.
+func (c *llmCoder) renderScaffold(ctx context.Context, task models.GenerationTask, plan *models.GenerationPlan) (string, bool) {
+	kind, data, ok := c.scaffoldFile(task, plan)
+	if !ok {
+		return "", false
+	}
+
+	code, err := c.scaffolds.GenerateScaffold(ctx, kind, data)
+	if err == nil {
+		code, err = postProcess(c.postprocess, task.TargetPath, task.Inputs, code)
+	}
+	if err != nil {
+		logctx.Logger(ctx).Warn().
+			Err(err).
+			Str("target_path", task.TargetPath).
+			Str("scaffold", string(kind)).
+			Msg("Scaffold template failed, generating file with the model")
+		return "", false
+	}
+
+	logctx.Logger(ctx).Info().
+		Str("target_path", task.TargetPath).
+		Str("scaffold", string(kind)).
+		Int("entities", len(data.Entities)).
+		Msg("Rendered file from the data model")
+	return code, true
+}
+
+// scaffoldFile returns the template kind and data of a planned file the data
+// model fully determines:
+//
+//   - the file declaring entities, when it is named after one of them or is
+//     the package's models or types file
+//   - <entity>_repository.go, or repository.go for every entity, declaring
+//     repository interfaces
+//   - the same files in a postgres directory, or postgres_<entity>.go,
+//     implementing them with database/sql
+//   - schema.sql, or a migration named create_<table>, creating the tables
+//     with the foreign keys of their relationships; a .down.sql migration
+//     drops them
+//
+// Under the sqlc persistence strategy, sqlc.yaml and db/queries.sql are
+// rendered too, and the database/sql implementations become wrappers around
+// the queries sqlc generates. Under GORM and ent the model writes them.
+//
+// The HTTP router, at templates.RouterPath, is determined by the HTTP API
+// contracts instead, and rendered in the architecture's HTTP framework.
+//
+// Repositories and tables need an entity with an ID key of a string or
+// integer type and at least one other stored field. Only fields with a
+// column type are stored, so entity and collection fields are left out.
+func (c *llmCoder) scaffoldFile(task models.GenerationTask, plan *models.GenerationPlan) (templates.ScaffoldKind, templates.ScaffoldData, bool) {
+	if c.scaffolds == nil || task.Type != "generate_file" {
+		return "", templates.ScaffoldData{}, false
+	}
+	target := normalizePath(task.TargetPath)
+	if target == templates.RouterPath {
+		return c.routerScaffold()
+	}
+	if c.types == nil {
+		return "", templates.ScaffoldData{}, false
+	}
+
+	dir, base := path.Dir(target), path.Base(target)
+	sqlc := c.types.persistence == models.PersistenceSQLC
+	switch {
+	case sqlc && target == templates.SQLCConfigPath:
+		return templates.ScaffoldSQLCConfig, templates.ScaffoldData{Dialect: c.dialect}, true
+	case sqlc && target == templates.SQLCQueriesPath:
+		return c.queriesScaffold()
+	case strings.HasSuffix(base, ".sql"):
+		return c.schemaScaffold(strings.TrimSuffix(base, ".sql"))
+	}
+	if !strings.HasSuffix(base, ".go") || strings.HasSuffix(base, "_test.go") {
+		return "", templates.ScaffoldData{}, false
+	}
+
+	pkg := plannedPackage(target, task.Inputs)
+	if pkg == "" || pkg == "main" {
+		return "", templates.ScaffoldData{}, false
+	}
+	stem := strings.ReplaceAll(strings.TrimSuffix(base, ".go"), "_", "")
+	owners := c.types.owners(plan)
+
+	// A file declaring entities is scaffolded when nothing but them is expected in it
+	var owned []entityType
+	for _, t := range c.types.types {
+		if owners[t.entity] == target {
+			owned = append(owned, t)
+		}
+	}
+	if len(owned) > 0 {
+		named := slices.ContainsFunc(owned, func(t entityType) bool { return stem == strings.ToLower(t.typeName) })
+		if !named && !modelFileStems[stem] {
+			return "", templates.ScaffoldData{}, false
+		}
+		return c.entityScaffold(pkg, dir, owned, owners, plan)
+	}
+
+	postgres := postgresDirs[path.Base(dir)]
+	kind := templates.ScaffoldRepositories
+	if postgres {
+		kind = templates.ScaffoldPostgres
+	}
+
+	data := templates.ScaffoldData{Package: pkg, Imports: []string{"context"}, Dialect: c.dialect}
+	if kind == templates.ScaffoldPostgres {
+		data.Imports = []string{"context", "database/sql", "fmt"}
+	}
+
+	all := stem == "repository" || stem == "repositories" || (postgres && stem == "store")
+	for _, t := range c.types.types {
+		name := strings.ToLower(t.typeName)
+		switch {
+		case all:
+		case stem == name+"repository" || stem == name+"repo":
+		case postgres && (stem == name || stem == name+"store"):
+		case stem == "postgres"+name || stem == name+"postgres":
+			kind = templates.ScaffoldPostgres
+			data.Imports = []string{"context", "database/sql", "fmt"}
+		default:
+			continue
+		}
+
+		entity, ok := c.types.storedEntity(t, c.dialect)
+		if !ok {
+			if all {
+				continue
+			}
+			return "", templates.ScaffoldData{}, false
+		}
+		typ, imp, ok := c.entityRef(t, dir, owners)
+		if ok && kind == templates.ScaffoldPostgres && sqlc {
+			entity, ok = c.sqlcEntity(entity, typ)
+		}
+		if !ok {
+			if all {
+				continue
+			}
+			return "", templates.ScaffoldData{}, false
+		}
+		entity.Type = typ
+		if imp != "" && !slices.Contains(data.ProjectImports, imp) {
+			data.ProjectImports = append(data.ProjectImports, imp)
+		}
+		data.Entities = append(data.Entities, entity)
+	}
+	if len(data.Entities) == 0 {
+		return "", templates.ScaffoldData{}, false
+	}
+	if kind == templates.ScaffoldPostgres {
+		switch c.types.persistence {
+		case models.PersistenceGORM, models.PersistenceEnt:
+			return "", templates.ScaffoldData{}, false
+		case models.PersistenceSQLC:
+			kind = templates.ScaffoldSQLC
+			data.ProjectImports = append(data.ProjectImports, c.modulePath+"/"+templates.SQLCPackageDir)
+		}
+	}
+	slices.Sort(data.ProjectImports)
+	return kind, data, true
+}
+
+// sqlcEntity returns entity, referred to as typ, with the fields and Go
+// types sqlc gives its columns and its field types as a file referring to it
+// that way does. It reports false for an entity with a field of another
+// package's type, which the file would have to import.
+func (c *llmCoder) sqlcEntity(entity templates.ScaffoldEntity, typ string) (templates.ScaffoldEntity, bool) {
+	qualifier, _, qualified := strings.Cut(typ, ".")
+	types, ok := sqlcTypes[c.dialect]
+	if !ok {
+		types = sqlcTypes[models.DialectPostgres]
+	}
+
+	entity.Fields = slices.Clone(entity.Fields)
+	for i, field := range entity.Fields {
+		if strings.Contains(field.Type, ".") && field.Type != "time.Time" {
+			return templates.ScaffoldEntity{}, false
+		}
+		if qualified && !goBuiltinTypes[field.Type] && field.Type != "time.Time" && field.Type != "[]byte" {
+			field.Type = qualifier + "." + field.Type
+		}
+		field.SQLCName = sqlcFieldName(field.Column)
+		if sqlcType := types[field.SQLType]; sqlcType != field.Type {
+			field.SQLCType = sqlcType
+		}
+		entity.Fields[i] = field
+	}
+	return entity, true
+}
+
+// sqlcFieldName returns the name sqlc gives the field of a column, so
+// user_id gives UserID and api_key gives ApiKey
+func sqlcFieldName(column string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(column, "_") {
+		if part == "id" {
+			sb.WriteString("ID")
+			continue
+		}
+		runes := []rune(part)
+		if len(runes) > 0 {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		sb.WriteString(string(runes))
+	}
+	return sb.String()
+}
+
+// queriesScaffold returns the data of the queries sqlc generates the
+// repositories' database code from: those of every table but join tables
+func (c *llmCoder) queriesScaffold() (templates.ScaffoldKind, templates.ScaffoldData, bool) {
+	data := templates.ScaffoldData{Dialect: c.dialect}
+	for _, table := range c.types.tables(c.dialect) {
+		if len(table.Fields) > 0 {
+			data.Entities = append(data.Entities, table)
+		}
+	}
+	if len(data.Entities) == 0 {
+		return "", templates.ScaffoldData{}, false
+	}
+	return templates.ScaffoldQueries, data, true
+}
+
+// entityScaffold returns the data of a file declaring entities, importing the
+// packages their fields refer to
+func (c *llmCoder) entityScaffold(pkg, dir string, owned []entityType, owners map[string]string, plan *models.GenerationPlan) (templates.ScaffoldKind, templates.ScaffoldData, bool) {
+	data := templates.ScaffoldData{Package: pkg}
+	for _, t := range owned {
+		if t.pkgName != pkg {
+			return "", templates.ScaffoldData{}, false
+		}
+		for _, imp := range t.imports {
+			if !slices.Contains(data.Imports, imp) {
+				data.Imports = append(data.Imports, imp)
+			}
+		}
+		for _, ref := range t.refs {
+			refDir, ok := c.packageDir(ref, owners, plan)
+			if !ok || path.Base(refDir) != goPackageName(ref) {
+				return "", templates.ScaffoldData{}, false
+			}
+			if imp := c.modulePath + "/" + refDir; refDir != dir && !slices.Contains(data.ProjectImports, imp) {
+				data.ProjectImports = append(data.ProjectImports, imp)
+			}
+		}
+		data.Entities = append(data.Entities, templates.ScaffoldEntity{
+			Entity: t.entity,
+			Name:   t.typeName,
+			Type:   t.typeName,
+			Decl:   t.decl,
+		})
+	}
+	slices.Sort(data.Imports)
+	slices.Sort(data.ProjectImports)
+	return templates.ScaffoldEntities, data, true
+}
+
+// schemaScaffold returns the data of a SQL file named stem: schema for every
+// table, or a migration creating (dropping, for .down) one table
+func (c *llmCoder) schemaScaffold(stem string) (templates.ScaffoldKind, templates.ScaffoldData, bool) {
+	data := templates.ScaffoldData{Drop: strings.HasSuffix(stem, ".down")}
+	stem = strings.TrimSuffix(strings.TrimSuffix(stem, ".down"), ".up")
+
+	for _, table := range c.types.tables(c.dialect) {
+		if stem == "schema" || stem == table.Table || strings.HasSuffix(stem, "create_"+table.Table) {
+			data.Entities = append(data.Entities, table)
+		}
+	}
+	if len(data.Entities) == 0 {
+		return "", templates.ScaffoldData{}, false
+	}
+	if data.Drop {
+		slices.Reverse(data.Entities) // Referencing tables go first
+	}
+	return templates.ScaffoldSchema, data, true
+}
+
+// storedEntity returns an entity as stored in a table of a dialect: its ID
+// key first, then the other fields with a column type. It reports false for
+// an entity without a string or integer ID, or without another stored field.
+func (r *typeRegistry) storedEntity(t entityType, dialect models.SQLDialect) (templates.ScaffoldEntity, bool) {
+	entity := templates.ScaffoldEntity{
+		Entity:  t.entity,
+		Name:    t.typeName,
+		Type:    t.typeName,
+		Var:     paramName(t.typeName),
+		Plural:  pluralize(t.typeName),
+		Table:   pluralize(models.SnakeCase(t.typeName)),
+		Dialect: dialect,
+	}
+
+	var key *templates.ScaffoldField
+	var values []templates.ScaffoldField
+	for _, f := range t.fields {
+		sqlType := r.sqlType(f.typ, dialect)
+		if sqlType == "" {
+			continue
+		}
+		field := templates.ScaffoldField{Name: f.name, Type: f.typ, Column: f.tag, SQLType: sqlType}
+		if f.name == "ID" {
+			key = &field
+			continue
+		}
+		values = append(values, field)
+	}
+	if key == nil || len(values) == 0 || !goBuiltinTypes[key.Type] || (key.Type != "string" && !strings.Contains(key.Type, "int")) {
+		return templates.ScaffoldEntity{}, false
+	}
+	entity.Fields = append([]templates.ScaffoldField{*key}, values...)
+	return entity, true
+}
+
+// sqlType returns the column type of a field's Go type in a dialect
+// (PostgreSQL when unset), "" when it has none. Enums are stored as their
+// backing type.
+func (r *typeRegistry) sqlType(typ string, dialect models.SQLDialect) string {
+	types, ok := sqlTypes[dialect]
+	if !ok {
+		types = sqlTypes[models.DialectPostgres]
+	}
+	if sqlType, ok := types[typ]; ok {
+		return sqlType
+	}
+	_, name, qualified := strings.Cut(typ, ".")
+	if !qualified {
+		name = typ
+	}
+	for _, enum := range r.enums {
+		if enum.Name == name {
+			return types[enum.Backing()]
+		}
+	}
+	return ""
+}
+
+// entityRef returns how a file in dir refers to an entity's type, with the
+// import that needs. It reports false when the entity has no declaring file
+// whose directory is named after its package.
+func (c *llmCoder) entityRef(t entityType, dir string, owners map[string]string) (string, string, bool) {
+	owner, ok := owners[t.entity]
+	if !ok {
+		return "", "", false
+	}
+	ownerDir := path.Dir(owner)
+	if ownerDir == dir {
+		return t.typeName, "", true
+	}
+	if t.pkgName == "" || path.Base(ownerDir) != t.pkgName {
+		return "", "", false
+	}
+	return t.pkgName + "." + t.typeName, c.modulePath + "/" + ownerDir, true
+}
+
+// packageDir returns the planned directory of a data model package: that of
+// the files declaring its entities, or else the first planned Go file in it
+func (c *llmCoder) packageDir(pkg string, owners map[string]string, plan *models.GenerationPlan) (string, bool) {
+	for _, t := range c.types.types {
+		if owner, ok := owners[t.entity]; ok && t.pkg == strings.Trim(pkg, "/") {
+			return path.Dir(owner), true
+		}
+	}
+	for _, task := range c.getAllTasks(plan) {
+		target := normalizePath(task.TargetPath)
+		if strings.HasSuffix(target, ".go") && dirInPackage(path.Dir(target), strings.Trim(pkg, "/")) {
+			return path.Dir(target), true
+		}
+	}
+	return "", false
+}
+
+// paramName returns the parameter name of a value of a type, so OrderItem
+// gives orderItem and APIKey gives apiKey
+func paramName(typeName string) string {
+	runes := []rune(typeName)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	if upper > 1 && upper < len(runes) {
+		upper-- // The last capital starts the next word
+	}
+	for i := 0; i < upper; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+
+	name := string(runes)
+	if token.IsKeyword(name) || scaffoldReserved[name] {
+		return "v"
+	}
+	return name
+}
+
+// pluralize returns the plural of a snake_case table name
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s") || strings.HasSuffix(name, "x") || strings.HasSuffix(name, "z") ||
+		strings.HasSuffix(name, "ch") || strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
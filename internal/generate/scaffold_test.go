@@ -0,0 +1,170 @@
+package generate
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scaffoldFixture(t *testing.T) (*llmCoder, *scriptedLLMClient, *models.GenerationPlan, *models.FinalClarifiedSpecification) {
+	t.Helper()
+
+	gen, err := templates.NewTemplateGenerator()
+	require.NoError(t, err)
+	client := &scriptedLLMClient{responses: []string{"package service\n\nfunc Register() {}\n"}}
+	coder, err := NewCoder(CoderConfig{LLMClient: client, Scaffolds: gen})
+	require.NoError(t, err)
+	c := coder.(*llmCoder)
+
+	fcs := &models.FinalClarifiedSpecification{
+		Architecture: models.Architecture{Packages: []models.Package{{Name: "models", Path: "github.com/acme/shop/internal/models"}}},
+		DataModel: models.DataModel{
+			Entities: []models.Entity{
+				{Name: "User", Package: "internal/models", Attributes: map[string]string{
+					"id": "uuid", "email": "email string", "status": "UserStatus", "created_at": "timestamp", "orders": "[]Order",
+				}},
+				{Name: "Order", Package: "internal/models", Attributes: map[string]string{"id": "int64", "user_id": "uuid", "total": "decimal"}},
+				{Name: "Session", Package: "internal/auth", Attributes: map[string]string{"token": "string", "user": "*User"}},
+			},
+			Enums: []models.Enum{{Name: "UserStatus", Package: "internal/models", Values: []string{"active", "banned"}}},
+		},
+	}
+	c.SetFCS(fcs)
+
+	plan := &models.GenerationPlan{Phases: []models.GenerationPhase{{Tasks: []models.GenerationTask{
+		{ID: "user", Type: "generate_file", TargetPath: "internal/models/user.go"},
+		{ID: "order", Type: "generate_file", TargetPath: "internal/models/order.go"},
+		{ID: "session", Type: "generate_file", TargetPath: "internal/auth/session.go"},
+		{ID: "repo", Type: "generate_file", TargetPath: "internal/repository/user_repository.go"},
+		{ID: "pg", Type: "generate_file", TargetPath: "internal/repository/postgres/repository.go"},
+		{ID: "up", Type: "generate_file", TargetPath: "migrations/001_create_users.up.sql"},
+		{ID: "down", Type: "generate_file", TargetPath: "migrations/001_create_users.down.sql"},
+		{ID: "schema", Type: "generate_file", TargetPath: "db/schema.sql"},
+		{ID: "service", Type: "generate_file", TargetPath: "internal/service/user_service.go"},
+	}}}}
+	return c, client, plan, fcs
+}
+
+func scaffoldTask(plan *models.GenerationPlan, id string) models.GenerationTask {
+	for _, task := range plan.Phases[0].Tasks {
+		if task.ID == id {
+			return task
+		}
+	}
+	return models.GenerationTask{}
+}
+
+func TestScaffoldFile(t *testing.T) {
+	c, _, plan, _ := scaffoldFixture(t)
+
+	tests := []struct {
+		id       string
+		kind     templates.ScaffoldKind
+		entities []string
+		imports  []string
+	}{
+		{id: "user", kind: templates.ScaffoldEntities, entities: []string{"User"}},
+		{id: "session", kind: templates.ScaffoldEntities, entities: []string{"Session"}, imports: []string{"github.com/acme/shop/internal/models"}},
+		{id: "repo", kind: templates.ScaffoldRepositories, entities: []string{"User"}, imports: []string{"github.com/acme/shop/internal/models"}},
+		{id: "pg", kind: templates.ScaffoldPostgres, entities: []string{"User", "Order"}, imports: []string{"github.com/acme/shop/internal/models"}},
+		{id: "up", kind: templates.ScaffoldSchema, entities: []string{"User"}},
+		{id: "schema", kind: templates.ScaffoldSchema, entities: []string{"User", "Order"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			kind, data, ok := c.scaffoldFile(scaffoldTask(plan, tt.id), plan)
+			require.True(t, ok)
+			assert.Equal(t, tt.kind, kind)
+			var names []string
+			for _, entity := range data.Entities {
+				names = append(names, entity.Name)
+			}
+			assert.Equal(t, tt.entities, names)
+			assert.Equal(t, tt.imports, data.ProjectImports)
+		})
+	}
+
+	_, _, ok := c.scaffoldFile(scaffoldTask(plan, "service"), plan)
+	assert.False(t, ok, "business logic is left to the model")
+
+	patch := scaffoldTask(plan, "user")
+	patch.Type = "apply_patch"
+	_, _, ok = c.scaffoldFile(patch, plan)
+	assert.False(t, ok, "existing files are modified by the model")
+
+	c.scaffolds = nil
+	_, _, ok = c.scaffoldFile(scaffoldTask(plan, "user"), plan)
+	assert.False(t, ok)
+}
+
+func TestRenderScaffold(t *testing.T) {
+	c, client, plan, fcs := scaffoldFixture(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"user", "session", "repo", "pg"} {
+		task := scaffoldTask(plan, id)
+		code, ok := c.renderScaffold(ctx, task, plan)
+		require.True(t, ok, id)
+		_, err := parser.ParseFile(token.NewFileSet(), task.TargetPath, code, parser.AllErrors)
+		require.NoError(t, err, code)
+	}
+
+	user, _ := c.renderScaffold(ctx, scaffoldTask(plan, "user"), plan)
+	assert.Equal(t, "package models\n\nimport \"time\"\n\n// User is the User entity of the data model\ntype User struct {\n"+
+		"\tCreatedAt time.Time  `json:\"created_at\"`\n"+
+		"\tEmail     string     `json:\"email\"`\n"+
+		"\tID        string     `json:\"id\"`\n"+
+		"\tOrders    []Order    `json:\"orders\"`\n"+
+		"\tStatus    UserStatus `json:\"status\"`\n"+
+		"}\n", user)
+
+	repo, _ := c.renderScaffold(ctx, scaffoldTask(plan, "repo"), plan)
+	assert.Contains(t, repo, "Get(ctx context.Context, id string) (*models.User, error)")
+
+	pg, _ := c.renderScaffold(ctx, scaffoldTask(plan, "pg"), plan)
+	assert.Contains(t, pg, "`INSERT INTO users (id, created_at, email, status) VALUES ($1, $2, $3, $4)`,\n\t\tuser.ID, user.CreatedAt, user.Email, user.Status,")
+	assert.Contains(t, pg, "`UPDATE orders SET total = $1, user_id = $2 WHERE id = $3`")
+	assert.Contains(t, pg, "func (r *OrderRepository) Get(ctx context.Context, id int64) (*models.Order, error)")
+	assert.NotContains(t, pg, "orders,", "collections are not stored")
+
+	up, _ := c.renderScaffold(ctx, scaffoldTask(plan, "up"), plan)
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS users (\n"+
+		"    id TEXT PRIMARY KEY,\n"+
+		"    created_at TIMESTAMPTZ NOT NULL,\n"+
+		"    email TEXT NOT NULL,\n"+
+		"    status TEXT NOT NULL\n"+
+		");\n", up)
+	down, _ := c.renderScaffold(ctx, scaffoldTask(plan, "down"), plan)
+	assert.Equal(t, "DROP TABLE IF EXISTS users;\n", down)
+
+	generated, err := c.GenerateFile(ctx, scaffoldTask(plan, "schema"), plan, fcs)
+	require.NoError(t, err)
+	assert.Contains(t, extractContentFromDiff(generated.Diff), "CREATE TABLE IF NOT EXISTS orders (")
+	assert.True(t, generated.Template, "scaffolded files are template-owned")
+	assert.Empty(t, client.prompts, "scaffolded files make no request")
+
+	generated, err = c.GenerateFile(ctx, scaffoldTask(plan, "service"), plan, fcs)
+	require.NoError(t, err)
+	assert.False(t, generated.Template)
+	assert.Len(t, client.prompts, 1)
+}
+
+func TestParamNameAndPluralize(t *testing.T) {
+	assert.Equal(t, "orderItem", paramName("OrderItem"))
+	assert.Equal(t, "apiKey", paramName("APIKey"))
+	assert.Equal(t, "url", paramName("URL"))
+	assert.Equal(t, "v", paramName("Type"), "keywords are not names")
+	assert.Equal(t, "v", paramName("Rows"), "method locals are not shadowed")
+
+	assert.Equal(t, "users", pluralize("user"))
+	assert.Equal(t, "categories", pluralize("category"))
+	assert.Equal(t, "keys", pluralize("key"))
+	assert.Equal(t, "addresses", pluralize("address"))
+	assert.Equal(t, "order_items", pluralize("order_item"))
+}
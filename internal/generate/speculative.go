@@ -0,0 +1,217 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/plangraph"
+)
+
+// DependencyAPIInput is the task input holding the exported API of the
+// packages a file depends on. Speculative parallel generation sets it, with
+// predicted APIs for dependencies that are still being generated.
+const DependencyAPIInput = "dependency_api"
+
+// SignaturePredictor predicts the exported API of a package before its files
+// are generated
+type SignaturePredictor interface {
+	// PredictAPI returns the predicted API of the package directory dir
+	// (slash-separated, relative to the project root), or false when it has
+	// no prediction
+	PredictAPI(dir string) (string, bool)
+}
+
+// previousOutputPredictor predicts that a package keeps the API its files on
+// disk have, which holds for most packages when a project is regenerated
+type previousOutputPredictor struct {
+	root  string
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// NewPreviousOutputPredictor creates a predictor that reads the API of each
+// package from the previous generation in outputDir
+func NewPreviousOutputPredictor(outputDir string) SignaturePredictor {
+	return &previousOutputPredictor{root: outputDir, cache: make(map[string]string)}
+}
+
+// PredictAPI summarises the non-test Go files in dir
+func (p *previousOutputPredictor) PredictAPI(dir string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if api, ok := p.cache[dir]; ok {
+		return api, api != ""
+	}
+
+	pkgDir := filepath.Join(p.root, filepath.FromSlash(dir))
+	entries, err := os.ReadDir(pkgDir)
+	var files []goSource
+	if err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+				continue
+			}
+			//nolint:gosec // G304: Reading previously generated files in the output directory
+			content, err := os.ReadFile(filepath.Join(pkgDir, entry.Name()))
+			if err != nil {
+				continue
+			}
+			files = append(files, goSource{path: path.Join(dir, entry.Name()), content: string(content)})
+		}
+	}
+
+	api := sourceAPI(files)
+	p.cache[dir] = api
+	return api, api != ""
+}
+
+// speculation is a file generated before its dependencies finished
+type speculation struct {
+	patch     models.Patch
+	predicted map[string]string // Dependency package dir -> API the file was generated against
+}
+
+// dependencyDirs groups the generated-file dependencies of node by package directory
+func dependencyDirs(graph *plangraph.Graph, node *plangraph.Node) map[string][]string {
+	dirs := make(map[string][]string)
+	for _, depID := range node.Dependencies {
+		dep := graph.Nodes[depID]
+		if dep == nil {
+			continue
+		}
+		dir := path.Dir(filepath.ToSlash(filepath.Clean(dep.Task.TargetPath)))
+		dirs[dir] = append(dirs[dir], depID)
+	}
+	return dirs
+}
+
+// dependencyAPIs returns the API of each package node depends on, computed
+// from the generated patches. With a predictor, packages whose files are not
+// all generated yet use the predicted API; without a prediction for one of
+// them, it returns false.
+func dependencyAPIs(graph *plangraph.Graph, node *plangraph.Node, patches map[string]models.Patch, predictor SignaturePredictor) (map[string]string, bool) {
+	apis := make(map[string]string)
+	for dir, depIDs := range dependencyDirs(graph, node) {
+		generated := make([]models.Patch, 0, len(depIDs))
+		for _, depID := range depIDs {
+			if patch, ok := patches[depID]; ok {
+				generated = append(generated, patch)
+			}
+		}
+
+		if len(generated) == len(depIDs) {
+			apis[dir] = packageAPI(generated)
+			continue
+		}
+		if predictor == nil {
+			return nil, false
+		}
+		api, ok := predictor.PredictAPI(dir)
+		if !ok {
+			return nil, false
+		}
+		apis[dir] = api
+	}
+	return apis, true
+}
+
+// sameAPIs reports whether two dependency API sets are identical
+func sameAPIs(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for dir, api := range a {
+		if other, ok := b[dir]; !ok || other != api {
+			return false
+		}
+	}
+	return true
+}
+
+// withDependencyAPI returns task with its dependency APIs as an input. The
+// inputs are copied because tasks share their map with the plan.
+func withDependencyAPI(task models.GenerationTask, apis map[string]string) models.GenerationTask {
+	inputs := make(map[string]interface{}, len(task.Inputs)+1)
+	for k, v := range task.Inputs {
+		inputs[k] = v
+	}
+	inputs[DependencyAPIInput] = formatDependencyAPIs(apis)
+	task.Inputs = inputs
+	return task
+}
+
+// formatDependencyAPIs renders the APIs in directory order, each under a
+// comment naming its package directory
+func formatDependencyAPIs(apis map[string]string) string {
+	dirs := make([]string, 0, len(apis))
+	for dir, api := range apis {
+		if api != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+
+	var sb strings.Builder
+	for _, dir := range dirs {
+		sb.WriteString(fmt.Sprintf("// package dir %s\n%s\n", dir, apis[dir]))
+	}
+	return sb.String()
+}
+
+// generateSpeculative generates the file tasks done has no patch for with a
+// speculative ParallelCoder and returns the patches by task ID. The tasks keep
+// the phases and dependencies they have in plan.
+func (c *llmCoder) generateSpeculative(ctx context.Context, tasks []models.GenerationTask, done map[string]models.Patch, plan *models.GenerationPlan, fcs *models.FinalClarifiedSpecification) (map[string]models.Patch, error) {
+	include := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		if _, ok := done[task.ID]; task.WritesFile() && !ok {
+			include[task.ID] = true
+		}
+	}
+	if len(include) == 0 {
+		return nil, nil
+	}
+
+	remaining := *plan
+	remaining.Phases = make([]models.GenerationPhase, 0, len(plan.Phases))
+	for _, phase := range plan.Phases {
+		phase.Tasks = slices.DeleteFunc(slices.Clone(phase.Tasks), func(task models.GenerationTask) bool {
+			return !include[task.ID]
+		})
+		remaining.Phases = append(remaining.Phases, phase)
+	}
+
+	var predictor SignaturePredictor
+	if c.outputDir != "" {
+		predictor = NewPreviousOutputPredictor(c.outputDir)
+	}
+	pc := NewParallelCoder(c, ParallelGenerationConfig{
+		MaxParallel:    c.maxParallel,
+		EnableParallel: true,
+		Speculative:    true,
+		Predictor:      predictor,
+	})
+
+	_, patches, err := pc.generateWithDependencies(ctx, &remaining, fcs, plangraph.Build(&remaining))
+	if err != nil {
+		return nil, fmt.Errorf("speculative generation failed: %w", err)
+	}
+
+	hits, misses := pc.SpeculationStats()
+	logctx.Logger(ctx).Info().
+		Int("files", len(patches)).
+		Int("speculative_hits", hits).
+		Int("speculative_misses", misses).
+		Msg("Speculative generation completed")
+	return patches, nil
+}
@@ -0,0 +1,332 @@
+package generate
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/langgraph-go/graph"
+	"github.com/rs/zerolog/log"
+)
+
+// InitialStateNode names the pseudo-node recorded for the state a run starts from
+const InitialStateNode = "initial"
+
+// ResumedStateNode names the pseudo-node recorded for the state a resumed run
+// continues from
+const ResumedStateNode = "resumed"
+
+// stateLogName is the file holding one StateTransition per line
+const stateLogName = "state.jsonl"
+
+// StateFields lists the JSON names of the StateSnapshot fields in declaration order
+var StateFields = []string{
+	"fcs", "plan", "code_patches", "test_patches", "doc_patches", "config_patches", "all_patches",
+	"output", "error", "output_dir", "workspace", "package_list", "current_phase", "completed_phases",
+}
+
+// StateSnapshot is the JSON form of a GenerationState. Nil and empty slices
+// are kept distinct because the reducer treats them differently.
+type StateSnapshot struct {
+	FCS             *models.FinalClarifiedSpecification `json:"fcs,omitempty"`
+	Plan            *models.GenerationPlan              `json:"plan,omitempty"`
+	CodePatches     []models.Patch                      `json:"code_patches"`
+	TestPatches     []models.Patch                      `json:"test_patches"`
+	DocPatches      []models.Patch                      `json:"doc_patches"`
+	ConfigPatches   []models.Patch                      `json:"config_patches"`
+	AllPatches      []models.Patch                      `json:"all_patches"`
+	Output          *models.GenerationOutput            `json:"output,omitempty"`
+	Error           string                              `json:"error,omitempty"`
+	OutputDir       string                              `json:"output_dir,omitempty"`
+	Workspace       *Workspace                          `json:"workspace,omitempty"`
+	PackageList     []string                            `json:"package_list"`
+	CurrentPhase    string                              `json:"current_phase,omitempty"`
+	CompletedPhases []string                            `json:"completed_phases"`
+}
+
+// NewStateSnapshot converts a state to its JSON form
+func NewStateSnapshot(s GenerationState) StateSnapshot {
+	snapshot := StateSnapshot{
+		FCS:             s.FCS,
+		Plan:            s.Plan,
+		CodePatches:     s.CodePatches,
+		TestPatches:     s.TestPatches,
+		DocPatches:      s.DocPatches,
+		ConfigPatches:   s.ConfigPatches,
+		AllPatches:      s.AllPatches,
+		Output:          s.Output,
+		OutputDir:       s.OutputDir,
+		Workspace:       s.Workspace,
+		PackageList:     s.PackageList,
+		CurrentPhase:    s.CurrentPhase,
+		CompletedPhases: s.CompletedPhases,
+	}
+	if s.Error != nil {
+		snapshot.Error = s.Error.Error()
+	}
+	return snapshot
+}
+
+// State converts the snapshot back into a GenerationState. Errors keep only their message.
+func (s StateSnapshot) State() GenerationState {
+	state := GenerationState{
+		FCS:             s.FCS,
+		Plan:            s.Plan,
+		CodePatches:     s.CodePatches,
+		TestPatches:     s.TestPatches,
+		DocPatches:      s.DocPatches,
+		ConfigPatches:   s.ConfigPatches,
+		AllPatches:      s.AllPatches,
+		Output:          s.Output,
+		OutputDir:       s.OutputDir,
+		Workspace:       s.Workspace,
+		PackageList:     s.PackageList,
+		CurrentPhase:    s.CurrentPhase,
+		CompletedPhases: s.CompletedPhases,
+	}
+	if s.Error != "" {
+		state.Error = errors.New(s.Error)
+	}
+	return state
+}
+
+// ChangedFields lists the state fields a delta sets, using the snapshot's JSON names
+func (s StateSnapshot) ChangedFields() []string {
+	var fields []string
+	add := func(set bool, name string) {
+		if set {
+			fields = append(fields, name)
+		}
+	}
+	add(s.FCS != nil, "fcs")
+	add(s.Plan != nil, "plan")
+	add(s.CodePatches != nil, "code_patches")
+	add(s.TestPatches != nil, "test_patches")
+	add(s.DocPatches != nil, "doc_patches")
+	add(s.ConfigPatches != nil, "config_patches")
+	add(s.AllPatches != nil, "all_patches")
+	add(s.Output != nil, "output")
+	add(s.Error != "", "error")
+	add(s.OutputDir != "", "output_dir")
+	add(s.Workspace != nil, "workspace")
+	add(s.PackageList != nil, "package_list")
+	add(s.CurrentPhase != "", "current_phase")
+	add(s.CompletedPhases != nil, "completed_phases")
+	return fields
+}
+
+// StateTransition records the delta one workflow node returned
+type StateTransition struct {
+	Seq       int           `json:"seq"`
+	Node      string        `json:"node"`
+	Next      string        `json:"next,omitempty"` // Routed node, "end" for a terminal route
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Err       string        `json:"err,omitempty"` // Error the node returned to the engine
+	Delta     StateSnapshot `json:"delta"`
+}
+
+// StateLogDir returns the directory holding the state log of a run
+func StateLogDir(outputDir, runID string) string {
+	return filepath.Join(outputDir, ".gocreator", "runs", runID)
+}
+
+// ReplayState rebuilds the state after transition index through by applying
+// each recorded delta with the workflow reducer. A resumed run's state
+// replaces the state replayed before it.
+func ReplayState(transitions []StateTransition, through int) GenerationState {
+	var state GenerationState
+	for i := 0; i <= through && i < len(transitions); i++ {
+		if transitions[i].Node == ResumedStateNode {
+			state = transitions[i].Delta.State()
+			continue
+		}
+		state = reduceGenerationState(state, transitions[i].Delta.State())
+	}
+	return state
+}
+
+// LoadStateTransitions reads the recorded transitions of a run in order
+func LoadStateTransitions(outputDir, runID string) ([]StateTransition, error) {
+	path := filepath.Join(StateLogDir(outputDir, runID), stateLogName)
+
+	//nolint:gosec // G304: Reading a state log under the output directory
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var transitions []StateTransition
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 256<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var t StateTransition
+		if err := json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			return nil, fmt.Errorf("failed to parse state log line %d: %w", line, err)
+		}
+		transitions = append(transitions, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read state log: %w", err)
+	}
+
+	sort.SliceStable(transitions, func(i, j int) bool { return transitions[i].Seq < transitions[j].Seq })
+	return transitions, nil
+}
+
+// ListStateRuns returns the run IDs with a state log, most recent first
+func ListStateRuns(outputDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(outputDir, ".gocreator", "runs"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	type run struct {
+		id      string
+		modTime time.Time
+	}
+	var runs []run
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(StateLogDir(outputDir, entry.Name()), stateLogName))
+		if err != nil {
+			continue
+		}
+		runs = append(runs, run{id: entry.Name(), modTime: info.ModTime()})
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].modTime.After(runs[j].modTime) })
+
+	ids := make([]string, len(runs))
+	for i, r := range runs {
+		ids[i] = r.id
+	}
+	return ids, nil
+}
+
+// stateRecorder appends node transitions to a run's state log as they happen,
+// so the log survives a run that fails or is interrupted
+type stateRecorder struct {
+	mu   sync.Mutex
+	file *os.File
+	seq  int
+}
+
+type stateRecorderKey struct{}
+
+// newStateRecorder creates the state log for a run and records its initial state
+func newStateRecorder(outputDir, runID string, initial GenerationState) (*stateRecorder, error) {
+	return openStateRecorder(outputDir, runID, os.O_TRUNC, 0, StateTransition{Node: InitialStateNode, StartedAt: time.Now(), Delta: NewStateSnapshot(initial)})
+}
+
+// resumeStateRecorder appends to the state log of a resumed run, recording
+// the state it continues from
+func resumeStateRecorder(outputDir, runID string, resumed GenerationState) (*stateRecorder, error) {
+	seq := 0
+	if transitions, err := LoadStateTransitions(outputDir, runID); err == nil && len(transitions) > 0 {
+		seq = transitions[len(transitions)-1].Seq + 1
+	}
+	return openStateRecorder(outputDir, runID, os.O_APPEND, seq, StateTransition{Node: ResumedStateNode, StartedAt: time.Now(), Delta: NewStateSnapshot(resumed)})
+}
+
+// openStateRecorder opens the state log of a run with flag and writes first
+func openStateRecorder(outputDir, runID string, flag, seq int, first StateTransition) (*stateRecorder, error) {
+	dir := StateLogDir(outputDir, runID)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create state log directory: %w", err)
+	}
+
+	//nolint:gosec // G304: Creating the state log under the output directory
+	file, err := os.OpenFile(filepath.Join(dir, stateLogName), os.O_CREATE|os.O_WRONLY|flag, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state log: %w", err)
+	}
+
+	r := &stateRecorder{file: file, seq: seq}
+	r.write(first)
+	return r, nil
+}
+
+// record appends one node's result
+func (r *stateRecorder) record(node string, started time.Time, result graph.NodeResult[GenerationState]) {
+	t := StateTransition{
+		Node:      node,
+		Next:      result.Route.To,
+		StartedAt: started,
+		Duration:  time.Since(started),
+		Delta:     NewStateSnapshot(result.Delta),
+	}
+	if result.Route.Terminal {
+		t.Next = "end"
+	}
+	if result.Err != nil {
+		t.Err = result.Err.Error()
+	}
+	r.write(t)
+}
+
+func (r *stateRecorder) write(t StateTransition) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// A node that outlives its timeout may finish after the run has closed the log
+	if r.file == nil {
+		return
+	}
+
+	t.Seq = r.seq
+	r.seq++
+
+	data, err := json.Marshal(t)
+	if err == nil {
+		_, err = r.file.Write(append(data, '\n'))
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("node", t.Node).Msg("Failed to record state transition")
+	}
+}
+
+// Close stops recording
+func (r *stateRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// recordedNode records each result of fn with the recorder carried in the node context
+func recordedNode(name string, fn graph.NodeFunc[GenerationState]) graph.NodeFunc[GenerationState] {
+	return func(ctx context.Context, s GenerationState) graph.NodeResult[GenerationState] {
+		recorder, _ := ctx.Value(stateRecorderKey{}).(*stateRecorder)
+		if recorder == nil {
+			return fn(ctx, s)
+		}
+
+		started := time.Now()
+		result := fn(ctx, s)
+		recorder.record(name, started, result)
+		return result
+	}
+}
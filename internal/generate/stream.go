@@ -0,0 +1,187 @@
+package generate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/internal/logctx"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// resumeInstruction asks the model to continue a response cut off by an
+// interrupted run
+const resumeInstruction = "Your response above was cut off. Continue it from exactly where it stops, without repeating anything. Do not add explanations or markdown fences that are not part of the file."
+
+// partialDir holds responses that are being streamed to disk
+func partialDir(outputDir string) string {
+	return filepath.Join(outputDir, ".gocreator", "partial")
+}
+
+// partialPrefix is the file name prefix shared by the partial responses for
+// a target file
+func partialPrefix(target string) string {
+	return strings.ReplaceAll(filepath.ToSlash(filepath.Clean(target)), "/", "_") + "."
+}
+
+// partialPath names the partial response for a request. The name includes a
+// hash of the model and prompt, so a changed spec or model starts over
+// instead of continuing a response to a different request.
+func partialPath(outputDir, target string, client llm.Client, messages []llm.CacheableMessage) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\x00%s\x00", client.Provider(), client.Model())
+	for _, msg := range messages {
+		_, _ = fmt.Fprintf(h, "%s\x00%s\x00", msg.Role, msg.Content)
+	}
+	return filepath.Join(partialDir(outputDir), partialPrefix(target)+hex.EncodeToString(h.Sum(nil))[:12]+".part")
+}
+
+// streamCode streams the response for target into a partial file under
+// .gocreator/partial rather than holding it in memory. The file is only
+// appended to, so an interrupted run leaves a prefix of the response, and
+// the next run asks the model to continue from there. The partial files of
+// target are removed once a response is complete.
+func (c *llmCoder) streamCode(ctx context.Context, client llm.StreamingClient, target string, messages []llm.CacheableMessage) (string, error) {
+	partial := partialPath(c.outputDir, target, client, messages)
+	if err := os.MkdirAll(filepath.Dir(partial), 0750); err != nil {
+		return "", fmt.Errorf("failed to create partial response directory: %w", err)
+	}
+
+	//nolint:gosec // G304: Path is built from the output directory and a hash
+	received, err := os.ReadFile(partial)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read partial response: %w", err)
+	}
+	if len(received) > 0 {
+		logctx.Logger(ctx).Info().
+			Str("file", target).
+			Int("bytes", len(received)).
+			Msg("Resuming interrupted response")
+		messages = append(messages[:len(messages):len(messages)],
+			llm.CacheableMessage{Role: "assistant", Content: string(received)},
+			llm.CacheableMessage{Role: "user", Content: resumeInstruction},
+		)
+	}
+
+	//nolint:gosec // G304: Path is built from the output directory and a hash
+	f, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to open partial response: %w", err)
+	}
+	var w io.Writer = f
+	progress := newStreamProgress(c.events, target)
+	if progress != nil {
+		w = io.MultiWriter(f, progress)
+	}
+	_, streamErr := client.GenerateStream(ctx, messages, w)
+	if err := f.Close(); err != nil && streamErr == nil {
+		streamErr = fmt.Errorf("failed to write partial response: %w", err)
+	}
+	if streamErr != nil {
+		return "", streamErr
+	}
+
+	//nolint:gosec // G304: Path is built from the output directory and a hash
+	response, err := os.ReadFile(partial)
+	if err != nil {
+		return "", fmt.Errorf("failed to read streamed response: %w", err)
+	}
+	removePartials(c.outputDir, target)
+	if progress != nil {
+		progress.finish()
+	}
+	return string(response), nil
+}
+
+// streamProgressInterval is the least time between progress events of one
+// streamed response
+const streamProgressInterval = 200 * time.Millisecond
+
+// maxStreamLine bounds the last line kept for display
+const maxStreamLine = 200
+
+// streamProgress turns a streamed response into progress events carrying
+// the tokens received so far and the line being written, so the code can be
+// watched as it arrives. Events are dropped rather than slowing the stream.
+type streamProgress struct {
+	events  chan<- models.ProgressEvent
+	path    string
+	started time.Time
+	sent    time.Time
+	bytes   int64
+	line    []byte // Line being received
+	last    string // Last complete line that is not blank
+}
+
+// newStreamProgress returns nil when there is nobody to report to
+func newStreamProgress(events chan<- models.ProgressEvent, path string) *streamProgress {
+	if events == nil {
+		return nil
+	}
+	return &streamProgress{events: events, path: path, started: time.Now()}
+}
+
+// Write records a chunk of the response
+func (p *streamProgress) Write(b []byte) (int, error) {
+	p.bytes += int64(len(b))
+	for _, c := range b {
+		if c == '\n' {
+			if strings.TrimSpace(string(p.line)) != "" {
+				p.last = string(p.line)
+			}
+			p.line = p.line[:0]
+			continue
+		}
+		if len(p.line) < maxStreamLine {
+			p.line = append(p.line, c)
+		}
+	}
+
+	if now := time.Now(); now.Sub(p.sent) >= streamProgressInterval {
+		p.sent = now
+		p.send(false)
+	}
+	return len(b), nil
+}
+
+// finish reports the complete response
+func (p *streamProgress) finish() {
+	p.send(true)
+}
+
+func (p *streamProgress) send(done bool) {
+	line := string(p.line)
+	if strings.TrimSpace(line) == "" {
+		line = p.last
+	}
+	// Tokens are estimated at four bytes each, as elsewhere in estimates
+	event := models.NewFileStreamingEvent(p.path, p.bytes/4, line, done, time.Since(p.started))
+	select {
+	case p.events <- event:
+	default:
+	}
+}
+
+// removePartials deletes the partial responses for target, including those
+// left by requests with an earlier prompt
+func removePartials(outputDir, target string) {
+	dir := partialDir(outputDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	prefix := partialPrefix(target)
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".part") {
+			_ = os.Remove(filepath.Join(dir, name))
+		}
+	}
+}
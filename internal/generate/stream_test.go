@@ -0,0 +1,121 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamingLLMClient streams its chunks in order, failing after cutAfter
+// chunks when cutAfter is positive
+type streamingLLMClient struct {
+	scriptedLLMClient
+	chunks   []string
+	cutAfter int
+	requests [][]llm.CacheableMessage
+}
+
+func (s *streamingLLMClient) GenerateStream(_ context.Context, messages []llm.CacheableMessage, w io.Writer) (int64, error) {
+	s.requests = append(s.requests, messages)
+	var written int64
+	for i, chunk := range s.chunks {
+		if s.cutAfter > 0 && i == s.cutAfter {
+			return written, errors.New("connection reset")
+		}
+		n, err := io.WriteString(w, chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func TestStreamCode_ResumesInterruptedResponse(t *testing.T) {
+	outputDir := t.TempDir()
+	coder := &llmCoder{outputDir: outputDir, stream: true}
+	messages := []llm.CacheableMessage{
+		{Role: "system", Content: "context"},
+		{Role: "user", Content: "generate internal/store/store.go"},
+	}
+
+	client := &streamingLLMClient{chunks: []string{"package store\n", "\ntype Store struct{}\n"}, cutAfter: 1}
+	_, err := coder.streamCode(context.Background(), client, "internal/store/store.go", messages)
+	require.Error(t, err)
+
+	partial := partialPath(outputDir, "internal/store/store.go", client, messages)
+	//nolint:gosec // G304: Test reads its own temp file
+	data, err := os.ReadFile(partial)
+	require.NoError(t, err, "an interrupted response stays on disk")
+	assert.Equal(t, "package store\n", string(data))
+
+	// The next run continues the response instead of starting over
+	client.chunks = []string{"\ntype Store struct{}\n"}
+	client.cutAfter = 0
+	response, err := coder.streamCode(context.Background(), client, "internal/store/store.go", messages)
+	require.NoError(t, err)
+	assert.Equal(t, "package store\n\ntype Store struct{}\n", response)
+
+	require.Len(t, client.requests, 2)
+	resumed := client.requests[1]
+	require.Len(t, resumed, 4)
+	assert.Equal(t, llm.CacheableMessage{Role: "assistant", Content: "package store\n"}, resumed[2])
+	assert.Equal(t, resumeInstruction, resumed[3].Content)
+	assert.Len(t, messages, 2, "the caller's messages are not modified")
+
+	assert.NoFileExists(t, partial, "completed responses are removed")
+}
+
+func TestStreamCode_RemovesStalePartials(t *testing.T) {
+	outputDir := t.TempDir()
+	coder := &llmCoder{outputDir: outputDir, stream: true}
+	client := &streamingLLMClient{chunks: []string{"package main\n"}}
+
+	old := partialPath(outputDir, "cmd/app/main.go", client, []llm.CacheableMessage{{Role: "user", Content: "old prompt"}})
+	other := partialPath(outputDir, "cmd/app/app.go", client, []llm.CacheableMessage{{Role: "user", Content: "old prompt"}})
+	require.NoError(t, os.MkdirAll(partialDir(outputDir), 0750))
+	require.NoError(t, os.WriteFile(old, []byte("package ma"), 0600))
+	require.NoError(t, os.WriteFile(other, []byte("package ma"), 0600))
+
+	response, err := coder.streamCode(context.Background(), client, "cmd/app/main.go", []llm.CacheableMessage{{Role: "user", Content: "new prompt"}})
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", response, "a partial for a different prompt is not resumed")
+	require.Len(t, client.requests, 1)
+	assert.Len(t, client.requests[0], 1)
+
+	assert.NoFileExists(t, old)
+	assert.FileExists(t, other, "partials of other files are kept")
+}
+
+func TestStreamCode_ReportsProgress(t *testing.T) {
+	events := make(chan models.ProgressEvent, 10)
+	coder := &llmCoder{outputDir: t.TempDir(), stream: true, events: events}
+	client := &streamingLLMClient{chunks: []string{"package main\n\nfunc main() {\n", "\trun()\n}\n"}}
+
+	_, err := coder.streamCode(context.Background(), client, "cmd/app/main.go", []llm.CacheableMessage{{Role: "user", Content: "generate"}})
+	require.NoError(t, err)
+	close(events)
+
+	// The first chunk is reported immediately, the rest when the response completes
+	var received []models.ProgressEvent
+	for event := range events {
+		received = append(received, event)
+	}
+	require.Len(t, received, 2)
+	assert.Equal(t, models.EventFileStreaming, received[0].Type)
+	assert.Equal(t, "func main() {", received[0].Data["line"])
+	assert.Equal(t, false, received[0].Data["done"])
+
+	done := received[1].Data
+	assert.Equal(t, "cmd/app/main.go", done["path"])
+	assert.Equal(t, true, done["done"])
+	assert.Equal(t, int64(len("package main\n\nfunc main() {\n\trun()\n}\n")/4), done["tokens"])
+	assert.Equal(t, "}", done["line"])
+}
@@ -0,0 +1,49 @@
+package generate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// formatStyleGuide returns the house style section of coder and tester
+// prompts, or "" when no style is configured. The test framework is not
+// listed: it reaches tests through testFramework.
+func formatStyleGuide(style models.StyleGuide) string {
+	var rules []string
+	switch style.ErrorWrapping {
+	case models.ErrorWrappingFmt:
+		rules = append(rules, `Errors: wrap with fmt.Errorf("doing x: %w", err); do NOT import github.com/pkg/errors`)
+	case models.ErrorWrappingPkgErrors:
+		rules = append(rules, "Errors: wrap with errors.Wrap or errors.Wrapf from github.com/pkg/errors; do NOT wrap with fmt.Errorf and %w")
+	}
+	if importPath, ok := models.LoggingLibraries[style.Logging]; ok {
+		rules = append(rules, fmt.Sprintf("Logging: log only through %s (%s); do NOT import any other logging library", style.Logging, importPath))
+	}
+	if style.Context == models.ContextFirstParam {
+		rules = append(rules, "Context: context.Context is the first parameter, named ctx, of every function that takes one; "+
+			"pass the caller's ctx on and call context.Background or context.TODO only in package main")
+	}
+	if style.Initialisms {
+		rules = append(rules, fmt.Sprintf("Naming: write initialisms in one case in identifiers (%s), e.g. userID and parseURL, never userId or parseUrl",
+			strings.Join(models.Initialisms, ", ")))
+	}
+	for _, rule := range style.Rules {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# House Style\n\n")
+	sb.WriteString("Follow the team's conventions in all generated code:\n")
+	for _, rule := range rules {
+		sb.WriteString("- " + rule + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
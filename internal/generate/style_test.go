@@ -0,0 +1,66 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testStyle = models.StyleGuide{
+	ErrorWrapping: models.ErrorWrappingPkgErrors,
+	Logging:       "zap",
+	Context:       models.ContextFirstParam,
+	Initialisms:   true,
+	TestFramework: models.TestFrameworkStdlib,
+	Rules:         []string{"Return early instead of nesting else blocks"},
+}
+
+func TestFormatStyleGuide(t *testing.T) {
+	section := formatStyleGuide(testStyle)
+
+	assert.True(t, strings.HasPrefix(section, "# House Style"))
+	assert.Contains(t, section, "errors.Wrap or errors.Wrapf from github.com/pkg/errors")
+	assert.Contains(t, section, "log only through zap (go.uber.org/zap)")
+	assert.Contains(t, section, "context.Context is the first parameter")
+	assert.Contains(t, section, "userID and parseURL")
+	assert.Contains(t, section, "- Return early instead of nesting else blocks\n")
+	assert.NotContains(t, section, "stdlib", "the test framework reaches tests through testFramework")
+
+	assert.Empty(t, formatStyleGuide(models.StyleGuide{}))
+	assert.Empty(t, formatStyleGuide(models.StyleGuide{TestFramework: models.TestFrameworkGomega, Rules: []string{" "}}))
+}
+
+func TestStyle_Prompts(t *testing.T) {
+	plan := &models.GenerationPlan{FileTree: models.FileTree{Files: []models.File{{Path: "internal/app/app.go"}}}}
+	task := models.GenerationTask{ID: "t1", Type: "generate_file", TargetPath: "internal/app/app.go"}
+	coder := &llmCoder{style: testStyle}
+
+	prompts := map[string]string{
+		"coder":  coder.buildCodeGenerationPrompt(task, plan, nil),
+		"tester": (&llmTester{style: testStyle}).buildTestGenerationPrompt("internal/app/app.go", plan, nil, nil, "", models.TestFrameworkStdlib),
+	}
+	cached := systemContents(t, coder.buildCodeGenerationPromptWithCache(task, plan, nil))
+	require.NotEmpty(t, cached)
+	prompts["coder cached"] = cached[0]
+
+	for name, prompt := range prompts {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, 1, strings.Count(prompt, "# House Style"))
+			assert.Contains(t, prompt, "log only through zap")
+		})
+	}
+
+	assert.NotContains(t, (&llmCoder{}).buildCodeGenerationPrompt(task, plan, nil), "# House Style")
+}
+
+func TestTestFramework_StyleFallback(t *testing.T) {
+	gomega := &models.FinalClarifiedSpecification{TestingStrategy: models.TestingStrategy{TestFramework: models.TestFrameworkGomega}}
+
+	assert.Equal(t, models.TestFrameworkGomega, testFramework(gomega, models.TestFrameworkStdlib), "the spec's framework wins")
+	assert.Equal(t, models.TestFrameworkStdlib, testFramework(&models.FinalClarifiedSpecification{}, models.TestFrameworkStdlib))
+	assert.Equal(t, models.TestFrameworkStdlib, testFramework(nil, models.TestFrameworkStdlib))
+	assert.Equal(t, models.DefaultTestFramework, testFramework(nil, ""))
+}
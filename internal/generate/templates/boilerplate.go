@@ -4,8 +4,14 @@ package templates
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 	"time"
@@ -22,6 +28,7 @@ type TemplateData struct {
 	ModuleName     string
 	GoVersion      string
 	ProjectName    string
+	BinaryName     string
 	Description    string
 	Dependencies   []models.Dependency
 	Packages       []models.Package
@@ -29,8 +36,70 @@ type TemplateData struct {
 	Year           int
 	GeneratedAt    string
 	CoverageTarget float64
+	TestFramework  string
+	Replaces       []ModuleReplace
+	GRPC           bool                  // The FCS declares gRPC services, compiled from proto/ with buf
+	Persistence    string                // Persistence strategy of the repository layer
+	HTTPFramework  string                // Framework the HTTP router is built with
+	OpenAPI        string                // OpenAPI document of the HTTP API contracts, "" without any
+	Modules        []models.ModuleConfig // Modules of a multi-module project, used by go.work
 }
 
+// ModuleReplace points a required module at a local directory
+type ModuleReplace struct {
+	Path string
+	Dir  string
+}
+
+// testFrameworkModules are the modules generated tests import for each test
+// framework; the standard library needs none
+var testFrameworkModules = map[string]models.Dependency{
+	models.TestFrameworkTestify: {Name: "github.com/stretchr/testify", Version: "v1.9.0", Purpose: "Test assertions"},
+	models.TestFrameworkGomega:  {Name: "github.com/onsi/gomega", Version: "v1.34.1", Purpose: "Test matchers"},
+}
+
+// grpcModules are the modules code generated from .proto files imports
+var grpcModules = []models.Dependency{
+	{Name: "google.golang.org/grpc", Version: "v1.66.0", Purpose: "gRPC server and client"},
+	{Name: "google.golang.org/protobuf", Version: "v1.34.2", Purpose: "Protocol buffer messages"},
+}
+
+// persistenceModules are the modules the repository layer imports for each
+// persistence strategy; database/sql and the code sqlc generates need none
+var persistenceModules = map[string]models.Dependency{
+	models.PersistenceGORM: {Name: "gorm.io/gorm", Version: "v1.25.12", Purpose: "ORM of the repository layer"},
+	models.PersistenceEnt:  {Name: "entgo.io/ent", Version: "v0.14.1", Purpose: "Entity framework of the repository layer"},
+}
+
+// httpFrameworkModules are the modules the HTTP router imports for each
+// framework; net/http needs none
+var httpFrameworkModules = map[string]models.Dependency{
+	models.HTTPFrameworkChi:  {Name: "github.com/go-chi/chi/v5", Version: "v5.1.0", Purpose: "HTTP router"},
+	models.HTTPFrameworkGin:  {Name: "github.com/gin-gonic/gin", Version: "v1.10.0", Purpose: "HTTP framework"},
+	models.HTTPFrameworkEcho: {Name: "github.com/labstack/echo/v4", Version: "v4.12.0", Purpose: "HTTP framework"},
+}
+
+// BoilerplateFiles are the files rendered from templates at the project root.
+// buf.yaml and buf.gen.yaml are only planned for projects with gRPC services,
+// openapi.yaml for projects with HTTP API contracts, go.work only for
+// multi-module projects, whose go.mod files are rendered in each module's
+// directory instead.
+var BoilerplateFiles = []string{"go.mod", ".gitignore", "Dockerfile", "Makefile", "README.md", "buf.yaml", "buf.gen.yaml", OpenAPIPath, "go.work"}
+
+// IsBoilerplatePath reports whether path, relative to the project root, is
+// one of the BoilerplateFiles
+func IsBoilerplatePath(path string) bool {
+	for _, name := range BoilerplateFiles {
+		if path == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalModuleVersion is required for modules resolved from the local workspace
+const LocalModuleVersion = "v0.0.0"
+
 // TemplateGenerator generates boilerplate files from templates without LLM calls
 type TemplateGenerator interface {
 	// GenerateGoMod generates a go.mod file
@@ -53,24 +122,72 @@ type TemplateGenerator interface {
 
 	// GenerateBoilerplate generates any boilerplate file by path
 	GenerateBoilerplate(ctx context.Context, path string, data TemplateData) (string, error)
+
+	// TemplateChecksum returns the SHA-256 checksum of the template source a
+	// boilerplate file is rendered from, or "" if the path has no template
+	TemplateChecksum(path string) string
+
+	// GenerateScaffold renders a source file of the given kind from data
+	// derived from the data model
+	GenerateScaffold(ctx context.Context, kind ScaffoldKind, data ScaffoldData) (string, error)
+}
+
+// templateNames lists the templates in the order they are loaded
+var templateNames = []string{
+	"go.mod.tmpl",
+	".gitignore.tmpl",
+	"Dockerfile.tmpl",
+	"Makefile.tmpl",
+	"README.md.tmpl",
+	"buf.yaml.tmpl",
+	"buf.gen.yaml.tmpl",
+	"openapi.yaml.tmpl",
+	"go.work.tmpl",
+	"entity.go.tmpl",
+	"repository.go.tmpl",
+	"postgres.go.tmpl",
+	"schema.sql.tmpl",
+	"sqlc.go.tmpl",
+	"queries.sql.tmpl",
+	"sqlc.yaml.tmpl",
+	"router_stdlib.go.tmpl",
+	"router_chi.go.tmpl",
+	"router_gin.go.tmpl",
+	"router_echo.go.tmpl",
 }
 
 // templateGenerator implements TemplateGenerator
 type templateGenerator struct {
 	templates      map[string]*template.Template
+	checksums      map[string]string // maps template names to source checksums
 	boilerplateMap map[string]string // maps file paths to template names
+	overrideDir    string            // directory whose templates replace the built-in ones
 }
 
 // NewTemplateGenerator creates a new template-based generator
 func NewTemplateGenerator() (TemplateGenerator, error) {
+	return NewTemplateGeneratorWithOverrides("")
+}
+
+// NewTemplateGeneratorWithOverrides creates a generator whose templates in
+// dir, named like the built-in ones (e.g. Makefile.tmpl), replace the built-in
+// templates. Templates missing from dir fall back to the built-in ones; an
+// empty dir uses only built-in templates.
+func NewTemplateGeneratorWithOverrides(dir string) (TemplateGenerator, error) {
 	gen := &templateGenerator{
-		templates: make(map[string]*template.Template),
+		templates:   make(map[string]*template.Template),
+		checksums:   make(map[string]string),
+		overrideDir: dir,
 		boilerplateMap: map[string]string{
-			"go.mod":     "go.mod.tmpl",
-			".gitignore": ".gitignore.tmpl",
-			"Dockerfile": "Dockerfile.tmpl",
-			"Makefile":   "Makefile.tmpl",
-			"README.md":  "README.md.tmpl",
+			"go.mod":       "go.mod.tmpl",
+			".gitignore":   ".gitignore.tmpl",
+			"Dockerfile":   "Dockerfile.tmpl",
+			"Makefile":     "Makefile.tmpl",
+			"README.md":    "README.md.tmpl",
+			"buf.yaml":     "buf.yaml.tmpl",
+			"buf.gen.yaml": "buf.gen.yaml.tmpl",
+			OpenAPIPath:    "openapi.yaml.tmpl",
+			"go.work":      "go.work.tmpl",
 		},
 	}
 
@@ -86,16 +203,15 @@ func NewTemplateGenerator() (TemplateGenerator, error) {
 	return gen, nil
 }
 
-// loadTemplates loads all template files from the embedded filesystem
+// loadTemplates loads all template files from the override directory,
+// falling back to the embedded filesystem
 func (g *templateGenerator) loadTemplates() error {
-	for _, tmplName := range []string{
-		"go.mod.tmpl",
-		".gitignore.tmpl",
-		"Dockerfile.tmpl",
-		"Makefile.tmpl",
-		"README.md.tmpl",
-	} {
-		content, err := templateFS.ReadFile("files/" + tmplName)
+	if err := g.checkOverrideDir(); err != nil {
+		return err
+	}
+
+	for _, tmplName := range templateNames {
+		content, source, err := g.readTemplate(tmplName)
 		if err != nil {
 			return fmt.Errorf("failed to read template %s: %w", tmplName, err)
 		}
@@ -105,15 +221,75 @@ func (g *templateGenerator) loadTemplates() error {
 			return fmt.Errorf("failed to parse template %s: %w", tmplName, err)
 		}
 
+		hash := sha256.Sum256(content)
 		g.templates[tmplName] = tmpl
+		g.checksums[tmplName] = hex.EncodeToString(hash[:])
 		log.Debug().
 			Str("template", tmplName).
+			Str("source", source).
 			Msg("Template loaded")
 	}
 
 	return nil
 }
 
+// readTemplate returns the source of a template and where it came from
+func (g *templateGenerator) readTemplate(tmplName string) ([]byte, string, error) {
+	if g.overrideDir != "" {
+		overridePath := filepath.Join(g.overrideDir, tmplName)
+		//nolint:gosec // G304: Reading user-provided template overrides - intended functionality
+		content, err := os.ReadFile(overridePath)
+		if err == nil {
+			return content, overridePath, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, "", err
+		}
+	}
+
+	content, err := templateFS.ReadFile("files/" + tmplName)
+	return content, "built-in", err
+}
+
+// checkOverrideDir rejects a missing override directory and templates in it
+// that do not replace a built-in one, which are most likely misnamed
+func (g *templateGenerator) checkOverrideDir() error {
+	if g.overrideDir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(g.overrideDir)
+	if err != nil {
+		return fmt.Errorf("failed to read templates directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".tmpl") {
+			continue
+		}
+		known := false
+		for _, tmplName := range templateNames {
+			if name == tmplName {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("template %s in %s does not replace a built-in template (%s)", name, g.overrideDir, strings.Join(templateNames, ", "))
+		}
+	}
+	return nil
+}
+
+// TemplateChecksum returns the checksum of the template source for a boilerplate path
+func (g *templateGenerator) TemplateChecksum(path string) string {
+	normalizedPath := path
+	if idx := strings.LastIndex(path, "/"); idx != -1 {
+		normalizedPath = path[idx+1:]
+	}
+	return g.checksums[g.boilerplateMap[normalizedPath]]
+}
+
 // IsBoilerplateFile returns true if the file should be generated via template
 func (g *templateGenerator) IsBoilerplateFile(path string) bool {
 	// Normalize path - handle both absolute and relative paths
@@ -181,7 +357,16 @@ func (g *templateGenerator) executeTemplate(_ context.Context, templateName stri
 	if data.GeneratedAt == "" {
 		data.GeneratedAt = time.Now().Format(time.RFC3339)
 	}
+	if data.BinaryName == "" {
+		data.BinaryName = data.ProjectName
+	}
 
+	return g.render(tmpl, data)
+}
+
+// render executes a loaded template with data
+func (g *templateGenerator) render(tmpl *template.Template, data any) (string, error) {
+	templateName := tmpl.Name()
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
 		return "", fmt.Errorf("failed to execute template %s: %w", templateName, err)
@@ -195,23 +380,20 @@ func (g *templateGenerator) executeTemplate(_ context.Context, templateName stri
 	return buf.String(), nil
 }
 
+// DefaultModulePath is used when no module path is configured or inferable
+const DefaultModulePath = "github.com/example/project"
+
+// ProjectSettings holds configured project identity that takes precedence
+// over values inferred from the FCS
+type ProjectSettings struct {
+	ModulePath string
+	BinaryName string
+}
+
 // ExtractTemplateData extracts template data from an FCS
 func ExtractTemplateData(fcs *models.FinalClarifiedSpecification) TemplateData {
-	// Extract module name from packages or use a default
-	moduleName := "github.com/example/project"
-	if len(fcs.Architecture.Packages) > 0 {
-		// Try to extract from first package path
-		firstPath := fcs.Architecture.Packages[0].Path
-		if idx := strings.Index(firstPath, "/"); idx > 0 {
-			moduleName = firstPath[:strings.LastIndex(firstPath, "/")]
-		}
-	}
-
-	// Extract project name from module name
-	projectName := "project"
-	if parts := strings.Split(moduleName, "/"); len(parts) > 0 {
-		projectName = parts[len(parts)-1]
-	}
+	moduleName := InferModulePath(fcs)
+	projectName := path.Base(moduleName)
 
 	// Build description from requirements
 	description := "A Go application"
@@ -223,6 +405,7 @@ func ExtractTemplateData(fcs *models.FinalClarifiedSpecification) TemplateData {
 		ModuleName:     moduleName,
 		GoVersion:      fcs.BuildConfig.GoVersion,
 		ProjectName:    projectName,
+		BinaryName:     projectName,
 		Description:    description,
 		Dependencies:   fcs.Architecture.Dependencies,
 		Packages:       fcs.Architecture.Packages,
@@ -230,7 +413,184 @@ func ExtractTemplateData(fcs *models.FinalClarifiedSpecification) TemplateData {
 		Year:           time.Now().Year(),
 		GeneratedAt:    time.Now().Format(time.RFC3339),
 		CoverageTarget: fcs.TestingStrategy.CoverageTarget,
+		TestFramework:  fcs.TestingStrategy.Framework(),
+		GRPC:           fcs.HasGRPC(),
+		Persistence:    fcs.BuildConfig.PersistenceStrategy(),
+		HTTPFramework:  fcs.Architecture.Framework(),
+		Modules:        fcs.BuildConfig.Modules,
+	}
+	data.requireTestFramework()
+	if data.GRPC {
+		data.requireModules(grpcModules...)
+	}
+	if module, ok := persistenceModules[data.Persistence]; ok {
+		data.requireModules(module)
+	}
+	if module, ok := httpFrameworkModules[data.HTTPFramework]; ok {
+		data.requireModules(module)
+	}
+
+	openAPI, err := RenderOpenAPI(fcs)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to describe the API contracts in OpenAPI")
+	}
+	data.OpenAPI = openAPI
+
+	return data
+}
+
+// requireTestFramework adds the selected test framework's module to the
+// dependencies unless the FCS already lists it
+func (d *TemplateData) requireTestFramework() {
+	if module, ok := testFrameworkModules[d.TestFramework]; ok {
+		d.requireModules(module)
+	}
+}
+
+// requireModules adds the modules the FCS does not already list to the
+// dependencies; listed modules keep their pinned version
+func (d *TemplateData) requireModules(modules ...models.Dependency) {
+	deps := make([]models.Dependency, 0, len(d.Dependencies)+len(modules))
+	deps = append(deps, d.Dependencies...)
+	for _, module := range modules {
+		listed := false
+		for _, dep := range deps {
+			if dep.Name == module.Name {
+				listed = true
+				break
+			}
+		}
+		if !listed {
+			deps = append(deps, module)
+		}
 	}
+	d.Dependencies = deps
+}
 
+// ForModule returns the data rendering the go.mod of one module of a
+// multi-module project. The module requires the architecture dependencies it
+// lists, the test framework and gRPC modules, and the project's modules it
+// lists. go.work resolves those too, but replace directives keep the module
+// buildable and tidy-able on its own.
+func (d TemplateData) ForModule(module models.ModuleConfig, fcs *models.FinalClarifiedSpecification) TemplateData {
+	data := d
+	data.ModuleName = module.Path
+	data.ProjectName = path.Base(module.Path)
+	data.BinaryName = data.ProjectName
+	data.Replaces = nil
+
+	data.Dependencies = nil
+	for _, dep := range fcs.Architecture.Dependencies {
+		if slices.Contains(module.Requires, dep.Name) {
+			data.Dependencies = append(data.Dependencies, dep)
+		}
+	}
+	data.requireTestFramework()
+	if data.GRPC {
+		data.requireModules(grpcModules...)
+	}
+
+	for _, required := range module.Requires {
+		other, ok := fcs.BuildConfig.Module(required)
+		if !ok {
+			continue
+		}
+		dir := relativeModuleDir(module.Dir, other.Dir)
+		data.AddLocalModule(other.Path, dir, true)
+	}
 	return data
 }
+
+// relativeModuleDir returns the path from one module directory to another in
+// the form replace directives need, e.g. ../shared
+func relativeModuleDir(from, to string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(from), filepath.FromSlash(to))
+	if err != nil {
+		return "./" + to
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel
+}
+
+// PackagePatterns returns the go command package patterns covering the
+// project: ./... for a single module, each module's directory otherwise
+func (d TemplateData) PackagePatterns() string {
+	if len(d.Modules) == 0 {
+		return "./..."
+	}
+	patterns := make([]string, len(d.Modules))
+	for i, module := range d.Modules {
+		patterns[i] = "./" + module.Dir + "/..."
+	}
+	return strings.Join(patterns, " ")
+}
+
+// ApplySettings overrides inferred values with configured project settings.
+// A configured module path also renames the project after its last element.
+func (d *TemplateData) ApplySettings(settings ProjectSettings) {
+	if settings.ModulePath != "" {
+		d.ModuleName = settings.ModulePath
+		d.ProjectName = path.Base(settings.ModulePath)
+		d.BinaryName = d.ProjectName
+	}
+	if settings.BinaryName != "" {
+		d.BinaryName = settings.BinaryName
+	}
+}
+
+// AddLocalModule requires a module that lives next to the project. Without a
+// go.work file to resolve it, replace points the requirement at dir.
+func (d *TemplateData) AddLocalModule(modulePath, dir string, replace bool) {
+	deps := make([]models.Dependency, 0, len(d.Dependencies)+1)
+	found := false
+	for _, dep := range d.Dependencies {
+		if dep.Name == modulePath {
+			found = true
+			if dep.Version == "" {
+				dep.Version = LocalModuleVersion
+			}
+		}
+		deps = append(deps, dep)
+	}
+	if !found {
+		deps = append(deps, models.Dependency{Name: modulePath, Version: LocalModuleVersion})
+	}
+	d.Dependencies = deps
+
+	if replace {
+		d.Replaces = append(d.Replaces, ModuleReplace{Path: modulePath, Dir: dir})
+	}
+}
+
+// InferModulePath derives the module path from fully qualified package paths
+// in the FCS, such as github.com/acme/service/internal/api. Relative package
+// paths carry no module information, so DefaultModulePath is returned.
+func InferModulePath(fcs *models.FinalClarifiedSpecification) string {
+	for _, pkg := range fcs.Architecture.Packages {
+		pkgPath := strings.Trim(pkg.Path, "/")
+
+		// Module paths start with a domain name
+		first, _, found := strings.Cut(pkgPath, "/")
+		if !found || !strings.Contains(first, ".") {
+			continue
+		}
+
+		// Conventional layout directories mark the end of the module path
+		for _, dir := range []string{"/cmd/", "/internal/", "/pkg/"} {
+			if idx := strings.Index(pkgPath+"/", dir); idx > 0 {
+				return pkgPath[:idx]
+			}
+		}
+
+		// Otherwise treat the last element as the package directory
+		if strings.Count(pkgPath, "/") < 2 {
+			return pkgPath
+		}
+		return path.Dir(pkgPath)
+	}
+
+	return DefaultModulePath
+}
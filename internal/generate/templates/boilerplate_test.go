@@ -2,6 +2,8 @@ package templates
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -114,6 +116,22 @@ func TestTemplateGenerator_GenerateGoMod(t *testing.T) {
 				assert.Contains(t, content, "github.com/rs/zerolog v1.29.0")
 			},
 		},
+		{
+			name: "go.mod with local module replace",
+			data: TemplateData{
+				ModuleName: "github.com/test/project",
+				GoVersion:  "1.21",
+				Replaces:   []ModuleReplace{{Path: "github.com/test/billing", Dir: "../billing"}},
+				Dependencies: []models.Dependency{
+					{Name: "github.com/test/billing", Version: LocalModuleVersion},
+				},
+			},
+			assertions: func(t *testing.T, content string) {
+				assert.Contains(t, content, "github.com/test/billing v0.0.0")
+				assert.Contains(t, content, "replace (")
+				assert.Contains(t, content, "github.com/test/billing => ../billing")
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -371,8 +389,9 @@ func TestExtractTemplateData(t *testing.T) {
 			},
 			assertions: func(t *testing.T, data TemplateData) {
 				assert.Equal(t, "1.22", data.GoVersion)
-				assert.Len(t, data.Dependencies, 1)
+				assert.Len(t, data.Dependencies, 2)
 				assert.Equal(t, "github.com/gin-gonic/gin", data.Dependencies[0].Name)
+				assert.Equal(t, "github.com/stretchr/testify", data.Dependencies[1].Name, "default test framework is required")
 				assert.Len(t, data.BuildFlags, 2)
 				assert.Equal(t, 90.0, data.CoverageTarget)
 			},
@@ -387,6 +406,152 @@ func TestExtractTemplateData(t *testing.T) {
 	}
 }
 
+func TestExtractTemplateData_TestFramework(t *testing.T) {
+	tests := []struct {
+		framework string
+		deps      []string
+	}{
+		{"", []string{"github.com/stretchr/testify"}},
+		{models.TestFrameworkTestify, []string{"github.com/stretchr/testify"}},
+		{models.TestFrameworkGomega, []string{"github.com/onsi/gomega"}},
+		{models.TestFrameworkStdlib, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.framework, func(t *testing.T) {
+			fcs := &models.FinalClarifiedSpecification{
+				TestingStrategy: models.TestingStrategy{TestFramework: tt.framework},
+			}
+
+			data := ExtractTemplateData(fcs)
+
+			var deps []string
+			for _, dep := range data.Dependencies {
+				deps = append(deps, dep.Name)
+			}
+			assert.Equal(t, tt.deps, deps)
+		})
+	}
+
+	// A framework the FCS already depends on keeps its pinned version
+	fcs := &models.FinalClarifiedSpecification{
+		Architecture: models.Architecture{Dependencies: []models.Dependency{
+			{Name: "github.com/stretchr/testify", Version: "v1.8.4"},
+		}},
+	}
+	data := ExtractTemplateData(fcs)
+	require.Len(t, data.Dependencies, 1)
+	assert.Equal(t, "v1.8.4", data.Dependencies[0].Version)
+}
+
+func TestExtractTemplateData_GRPC(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{
+		APIContracts: []models.APIContract{{Protocol: models.ProtocolGRPC, Service: "orders.v1.OrderService", Method: "CreateOrder"}},
+		Architecture: models.Architecture{Dependencies: []models.Dependency{
+			{Name: "google.golang.org/grpc", Version: "v1.60.0"},
+		}},
+	}
+
+	data := ExtractTemplateData(fcs)
+	assert.True(t, data.GRPC)
+	var deps []string
+	for _, dep := range data.Dependencies {
+		deps = append(deps, dep.Name+"@"+dep.Version)
+	}
+	assert.Equal(t, []string{"google.golang.org/grpc@v1.60.0", "github.com/stretchr/testify@v1.9.0", "google.golang.org/protobuf@v1.34.2"}, deps)
+
+	gen, err := NewTemplateGenerator()
+	require.NoError(t, err)
+	data.ModuleName = "github.com/acme/orders"
+
+	bufGen, err := gen.GenerateBoilerplate(context.Background(), "buf.gen.yaml", data)
+	require.NoError(t, err)
+	assert.Contains(t, bufGen, "value: github.com/acme/orders/gen")
+	bufYAML, err := gen.GenerateBoilerplate(context.Background(), "buf.yaml", data)
+	require.NoError(t, err)
+	assert.Contains(t, bufYAML, "- path: proto")
+
+	makefile, err := gen.GenerateMakefile(context.Background(), data)
+	require.NoError(t, err)
+	assert.Contains(t, makefile, "proto:\n")
+	data.GRPC = false
+	makefile, err = gen.GenerateMakefile(context.Background(), data)
+	require.NoError(t, err)
+	assert.NotContains(t, makefile, "buf generate")
+}
+
+func TestExtractTemplateData_Persistence(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{BuildConfig: models.BuildConfig{Persistence: models.PersistenceGORM}}
+	data := ExtractTemplateData(fcs)
+	assert.Equal(t, models.PersistenceGORM, data.Persistence)
+	assert.Contains(t, data.Dependencies, persistenceModules[models.PersistenceGORM])
+
+	data = ExtractTemplateData(&models.FinalClarifiedSpecification{})
+	assert.Equal(t, models.PersistenceRawSQL, data.Persistence)
+	assert.Len(t, data.Dependencies, 1, "only the test framework")
+
+	gen, err := NewTemplateGenerator()
+	require.NoError(t, err)
+	data.Persistence = models.PersistenceSQLC
+	makefile, err := gen.GenerateMakefile(context.Background(), data)
+	require.NoError(t, err)
+	assert.Contains(t, makefile, "sqlc:\n")
+	assert.Contains(t, makefile, "@sqlc generate")
+
+	data.Persistence = models.PersistenceEnt
+	makefile, err = gen.GenerateMakefile(context.Background(), data)
+	require.NoError(t, err)
+	assert.Contains(t, makefile, "ent:\n")
+	assert.NotContains(t, makefile, "sqlc")
+}
+
+func TestExtractTemplateData_HTTPFramework(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{Architecture: models.Architecture{HTTPFramework: models.HTTPFrameworkChi}}
+	data := ExtractTemplateData(fcs)
+	assert.Equal(t, models.HTTPFrameworkChi, data.HTTPFramework)
+	assert.Contains(t, data.Dependencies, httpFrameworkModules[models.HTTPFrameworkChi])
+
+	// A pinned version is kept
+	fcs.Architecture.Dependencies = []models.Dependency{{Name: "github.com/go-chi/chi/v5", Version: "v5.0.12"}}
+	data = ExtractTemplateData(fcs)
+	assert.Contains(t, data.Dependencies, models.Dependency{Name: "github.com/go-chi/chi/v5", Version: "v5.0.12"})
+	assert.NotContains(t, data.Dependencies, httpFrameworkModules[models.HTTPFrameworkChi])
+
+	data = ExtractTemplateData(&models.FinalClarifiedSpecification{})
+	assert.Equal(t, models.HTTPFrameworkStdlib, data.HTTPFramework)
+	assert.Len(t, data.Dependencies, 1, "only the test framework")
+}
+
+func TestExtractTemplateData_Modules(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{BuildConfig: models.BuildConfig{GoVersion: "1.22", Modules: []models.ModuleConfig{
+		{Dir: "services/api", Path: "example.com/shop/api"},
+		{Dir: "shared", Path: "example.com/shop/shared"},
+	}}}
+
+	data := ExtractTemplateData(fcs)
+	assert.Equal(t, "./services/api/... ./shared/...", data.PackagePatterns())
+
+	gen, err := NewTemplateGenerator()
+	require.NoError(t, err)
+	goWork, err := gen.GenerateBoilerplate(context.Background(), "go.work", data)
+	require.NoError(t, err)
+	assert.Equal(t, "go 1.22\n\nuse (\n\t./services/api\n\t./shared\n)\n", goWork)
+
+	makefile, err := gen.GenerateMakefile(context.Background(), data)
+	require.NoError(t, err)
+	assert.Contains(t, makefile, "$(GOTEST) -v -race ./services/api/... ./shared/...")
+	assert.Contains(t, makefile, "@gofmt -w services/api shared")
+	assert.Contains(t, makefile, "$(GOCMD) work sync")
+
+	// A single module keeps ./...
+	data.Modules = nil
+	assert.Equal(t, "./...", data.PackagePatterns())
+	makefile, err = gen.GenerateMakefile(context.Background(), data)
+	require.NoError(t, err)
+	assert.Contains(t, makefile, "$(GOTEST) -v -race ./...")
+	assert.NotContains(t, makefile, "work sync")
+}
+
 func TestTemplateData_DefaultValues(t *testing.T) {
 	gen, err := NewTemplateGenerator()
 	require.NoError(t, err)
@@ -490,3 +655,160 @@ func BenchmarkTemplateGenerator_GenerateAllBoilerplate(b *testing.B) {
 		}
 	}
 }
+
+func TestInferModulePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		packages []models.Package
+		want     string
+	}{
+		{"cmd layout", []models.Package{{Path: "github.com/acme/service/cmd/server"}}, "github.com/acme/service"},
+		{"internal layout", []models.Package{{Path: "github.com/acme/service/internal/api/v1"}}, "github.com/acme/service"},
+		{"flat package", []models.Package{{Path: "gitlab.com/team/tool/config"}}, "gitlab.com/team/tool"},
+		{"module root", []models.Package{{Path: "example.com/tool"}}, "example.com/tool"},
+		{"relative paths skipped", []models.Package{{Path: "internal/models"}, {Path: "github.com/acme/app/pkg/x"}}, "github.com/acme/app"},
+		{"no qualified paths", []models.Package{{Path: "internal/models"}}, DefaultModulePath},
+		{"no packages", nil, DefaultModulePath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fcs := &models.FinalClarifiedSpecification{Architecture: models.Architecture{Packages: tt.packages}}
+			assert.Equal(t, tt.want, InferModulePath(fcs))
+		})
+	}
+}
+
+func TestTemplateData_ApplySettings(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{
+		Architecture: models.Architecture{
+			Packages: []models.Package{{Path: "internal/models"}},
+		},
+	}
+
+	data := ExtractTemplateData(fcs)
+	assert.Equal(t, DefaultModulePath, data.ModuleName)
+	assert.Equal(t, "project", data.BinaryName)
+
+	data.ApplySettings(ProjectSettings{ModulePath: "github.com/acme/inventory"})
+	assert.Equal(t, "github.com/acme/inventory", data.ModuleName)
+	assert.Equal(t, "inventory", data.ProjectName)
+	assert.Equal(t, "inventory", data.BinaryName)
+
+	data.ApplySettings(ProjectSettings{BinaryName: "inventoryd"})
+	assert.Equal(t, "github.com/acme/inventory", data.ModuleName)
+	assert.Equal(t, "inventoryd", data.BinaryName)
+
+	gen, err := NewTemplateGenerator()
+	require.NoError(t, err)
+
+	makefile, err := gen.GenerateMakefile(context.Background(), data)
+	require.NoError(t, err)
+	assert.Contains(t, makefile, "BINARY_NAME=inventoryd")
+	assert.Contains(t, makefile, "CMD_DIR=./cmd/inventoryd")
+
+	goMod, err := gen.GenerateGoMod(context.Background(), data)
+	require.NoError(t, err)
+	assert.Contains(t, goMod, "module github.com/acme/inventory")
+}
+
+func TestTemplateData_AddLocalModule(t *testing.T) {
+	declared := []models.Dependency{{Name: "github.com/acme/billing"}}
+	data := TemplateData{Dependencies: declared}
+
+	data.AddLocalModule("github.com/acme/billing", "../billing", true)
+	data.AddLocalModule("github.com/acme/auth", "../auth", false)
+
+	assert.Equal(t, []models.Dependency{
+		{Name: "github.com/acme/billing", Version: LocalModuleVersion},
+		{Name: "github.com/acme/auth", Version: LocalModuleVersion},
+	}, data.Dependencies)
+	assert.Equal(t, []ModuleReplace{{Path: "github.com/acme/billing", Dir: "../billing"}}, data.Replaces)
+	assert.Empty(t, declared[0].Version, "the FCS dependencies are not modified")
+}
+
+func TestNewTemplateGeneratorWithOverrides(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Makefile.tmpl"), []byte("build:\n\tgo build -o {{.BinaryName}}\n"), 0600))
+
+	builtin, err := NewTemplateGenerator()
+	require.NoError(t, err)
+	gen, err := NewTemplateGeneratorWithOverrides(dir)
+	require.NoError(t, err)
+
+	data := TemplateData{ModuleName: "example.com/app", GoVersion: "1.22", BinaryName: "app"}
+	content, err := gen.GenerateBoilerplate(context.Background(), "Makefile", data)
+	require.NoError(t, err)
+	assert.Equal(t, "build:\n\tgo build -o app\n", content)
+
+	assert.NotEqual(t, builtin.TemplateChecksum("Makefile"), gen.TemplateChecksum("Makefile"))
+	assert.Equal(t, builtin.TemplateChecksum("go.mod"), gen.TemplateChecksum("go.mod"), "missing overrides fall back to the built-in template")
+	assert.Empty(t, gen.TemplateChecksum("main.go"))
+}
+
+func TestNewTemplateGeneratorWithOverrides_Errors(t *testing.T) {
+	_, err := NewTemplateGeneratorWithOverrides(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "failed to read templates directory")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "makefile.tmpl"), []byte("build:\n"), 0600))
+	_, err = NewTemplateGeneratorWithOverrides(dir)
+	assert.ErrorContains(t, err, "makefile.tmpl")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "makefile.tmpl"), []byte("{{.Broken"), 0600))
+	require.NoError(t, os.Rename(filepath.Join(dir, "makefile.tmpl"), filepath.Join(dir, "Makefile.tmpl")))
+	_, err = NewTemplateGeneratorWithOverrides(dir)
+	assert.ErrorContains(t, err, "failed to parse template Makefile.tmpl")
+}
+
+func TestTemplateGenerator_GenerateScaffold(t *testing.T) {
+	gen, err := NewTemplateGenerator()
+	require.NoError(t, err)
+
+	data := ScaffoldData{
+		Package:        "repository",
+		Imports:        []string{"context"},
+		ProjectImports: []string{"github.com/test/project/internal/models"},
+		Entities: []ScaffoldEntity{{
+			Entity: "line item",
+			Name:   "LineItem",
+			Type:   "models.LineItem",
+			Var:    "lineItem",
+			Table:  "line_items",
+			Fields: []ScaffoldField{
+				{Name: "ID", Type: "int64", Column: "id", SQLType: "BIGINT"},
+				{Name: "Qty", Type: "int", Column: "qty", SQLType: "INTEGER"},
+				{Name: "SKU", Type: "string", Column: "sku", SQLType: "TEXT"},
+			},
+		}},
+	}
+
+	content, err := gen.GenerateScaffold(context.Background(), ScaffoldRepositories, data)
+	require.NoError(t, err)
+	assert.Contains(t, content, "type LineItemRepository interface {")
+	assert.Contains(t, content, "Create(ctx context.Context, lineItem *models.LineItem) error")
+	assert.Contains(t, content, "Delete(ctx context.Context, id int64) error")
+
+	data.Package = "postgres"
+	data.Imports = []string{"context", "database/sql", "fmt"}
+	content, err = gen.GenerateScaffold(context.Background(), ScaffoldPostgres, data)
+	require.NoError(t, err)
+	assert.Contains(t, content, "`UPDATE line_items SET qty = $1, sku = $2 WHERE id = $3`")
+	assert.Contains(t, content, "lineItem.Qty, lineItem.SKU, lineItem.ID,")
+
+	content, err = gen.GenerateScaffold(context.Background(), ScaffoldSchema, data)
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE IF NOT EXISTS line_items (\n"+
+		"    id BIGINT PRIMARY KEY,\n"+
+		"    qty INTEGER NOT NULL,\n"+
+		"    sku TEXT NOT NULL\n"+
+		");\n", content)
+
+	data.Drop = true
+	content, err = gen.GenerateScaffold(context.Background(), ScaffoldSchema, data)
+	require.NoError(t, err)
+	assert.Equal(t, "DROP TABLE IF EXISTS line_items;\n", content)
+
+	_, err = gen.GenerateScaffold(context.Background(), ScaffoldKind("handler"), data)
+	assert.Error(t, err)
+}
@@ -0,0 +1,323 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/dshills/gocreator/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIPath is where the OpenAPI description of the HTTP API contracts is
+// rendered
+const OpenAPIPath = "openapi.yaml"
+
+// bodyField names the contract field holding a whole body that is not an
+// object, as the OpenAPI importer writes it
+const bodyField = "body"
+
+// bodyMethods are the HTTP methods whose requests carry a body; the other
+// methods take their fields as query parameters
+var bodyMethods = map[string]bool{"POST": true, "PUT": true, "PATCH": true}
+
+// openAPITypes maps Go types and the type names specifications use to the
+// schema they are described by
+var openAPITypes = map[string]openAPISchema{
+	"string": {Type: "string"}, "bool": {Type: "boolean"}, "any": {},
+	"int": {Type: "integer"}, "int8": {Type: "integer"}, "int16": {Type: "integer"},
+	"int32": {Type: "integer", Format: "int32"}, "int64": {Type: "integer", Format: "int64"},
+	"uint": {Type: "integer"}, "uint8": {Type: "integer"}, "uint16": {Type: "integer"},
+	"uint32": {Type: "integer"}, "uint64": {Type: "integer"}, "byte": {Type: "integer"}, "rune": {Type: "integer"},
+	"float32": {Type: "number", Format: "float"}, "float64": {Type: "number", Format: "double"},
+	"time.time": {Type: "string", Format: "date-time"}, "time.duration": {Type: "string"},
+	"text": {Type: "string"}, "str": {Type: "string"}, "varchar": {Type: "string"}, "char": {Type: "string"},
+	"uuid": {Type: "string", Format: "uuid"}, "guid": {Type: "string", Format: "uuid"}, "id": {Type: "string"},
+	"email": {Type: "string", Format: "email"}, "url": {Type: "string", Format: "uri"}, "uri": {Type: "string", Format: "uri"},
+	"phone": {Type: "string"}, "password": {Type: "string", Format: "password"}, "hash": {Type: "string"}, "enum": {Type: "string"},
+	"integer": {Type: "integer"}, "long": {Type: "integer", Format: "int64"}, "bigint": {Type: "integer", Format: "int64"},
+	"serial": {Type: "integer", Format: "int64"}, "number": {Type: "number"}, "float": {Type: "number", Format: "float"},
+	"double": {Type: "number", Format: "double"}, "decimal": {Type: "number"}, "money": {Type: "number"},
+	"currency": {Type: "number"}, "real": {Type: "number"}, "boolean": {Type: "boolean"}, "flag": {Type: "boolean"},
+	"timestamp": {Type: "string", Format: "date-time"}, "datetime": {Type: "string", Format: "date-time"},
+	"date": {Type: "string", Format: "date"}, "time": {Type: "string", Format: "date-time"}, "duration": {Type: "string"},
+	"binary": {Type: "string", Format: "binary"}, "blob": {Type: "string", Format: "binary"},
+	"bytes": {Type: "string", Format: "byte"}, "[]byte": {Type: "string", Format: "byte"},
+	"json": {Type: "object"}, "object": {Type: "object"},
+}
+
+// typeWord splits an attribute type such as "unique email string" into words
+var typeWord = regexp.MustCompile(`[A-Za-z0-9]+`)
+
+// openAPIDocument is the part of the OpenAPI document rendered from the FCS;
+// the template adds the OpenAPI version and the info object
+type openAPIDocument struct {
+	Paths      map[string]openAPIPathItem `yaml:"paths"`
+	Components *openAPIComponents         `yaml:"components,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `yaml:"schemas"`
+}
+
+type openAPIPathItem struct {
+	Get     *openAPIOperation `yaml:"get,omitempty"`
+	Put     *openAPIOperation `yaml:"put,omitempty"`
+	Post    *openAPIOperation `yaml:"post,omitempty"`
+	Delete  *openAPIOperation `yaml:"delete,omitempty"`
+	Options *openAPIOperation `yaml:"options,omitempty"`
+	Head    *openAPIOperation `yaml:"head,omitempty"`
+	Patch   *openAPIOperation `yaml:"patch,omitempty"`
+	Trace   *openAPIOperation `yaml:"trace,omitempty"`
+}
+
+// set stores the operation of an HTTP method, reporting false for a method
+// OpenAPI has no operation for
+func (p *openAPIPathItem) set(method string, op *openAPIOperation) bool {
+	operations := map[string]**openAPIOperation{
+		"GET": &p.Get, "PUT": &p.Put, "POST": &p.Post, "DELETE": &p.Delete,
+		"OPTIONS": &p.Options, "HEAD": &p.Head, "PATCH": &p.Patch, "TRACE": &p.Trace,
+	}
+	slot, ok := operations[method]
+	if ok {
+		*slot = op
+	}
+	return ok
+}
+
+type openAPIOperation struct {
+	OperationID string                     `yaml:"operationId"`
+	Summary     string                     `yaml:"summary,omitempty"`
+	Parameters  []openAPIParameter         `yaml:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `yaml:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `yaml:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string         `yaml:"name"`
+	In       string         `yaml:"in"`
+	Required bool           `yaml:"required,omitempty"`
+	Schema   *openAPISchema `yaml:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `yaml:"required"`
+	Content  map[string]openAPIMediaType `yaml:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `yaml:"description"`
+	Content     map[string]openAPIMediaType `yaml:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `yaml:"$ref,omitempty"`
+	Type                 string                    `yaml:"type,omitempty"`
+	Format               string                    `yaml:"format,omitempty"`
+	Description          string                    `yaml:"description,omitempty"`
+	Enum                 []string                  `yaml:"enum,omitempty"`
+	Properties           map[string]*openAPISchema `yaml:"properties,omitempty"`
+	Items                *openAPISchema            `yaml:"items,omitempty"`
+	AdditionalProperties *openAPISchema            `yaml:"additionalProperties,omitempty"`
+}
+
+// RenderOpenAPI describes the HTTP API contracts of the FCS as the paths and
+// components of an OpenAPI 3 document in YAML, the inverse of importing one:
+// each route becomes an operation named after its handler, path fields
+// become path parameters, other request fields the JSON body of POST, PUT
+// and PATCH requests or the query parameters of the others, and the entities
+// and enums of the data model become component schemas the fields
+// reference. It returns "" when the FCS has no HTTP API contracts.
+func RenderOpenAPI(fcs *models.FinalClarifiedSpecification) (string, error) {
+	routes := fcs.HTTPRoutes()
+	if len(routes) == 0 {
+		return "", nil
+	}
+
+	r := &openAPIRenderer{named: make(map[string]bool)}
+	for _, entity := range fcs.DataModel.Entities {
+		r.named[entity.Name] = true
+	}
+	for _, enum := range fcs.DataModel.Enums {
+		r.named[enum.Name] = true
+	}
+
+	doc := openAPIDocument{Paths: make(map[string]openAPIPathItem)}
+
+	for _, route := range routes {
+		item := doc.Paths[route.Path]
+		if item.set(route.Method, r.operation(route)) {
+			doc.Paths[route.Path] = item
+		}
+	}
+
+	if schemas := r.components(fcs.DataModel); len(schemas) > 0 {
+		doc.Components = &openAPIComponents{Schemas: schemas}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return "", fmt.Errorf("failed to render OpenAPI document: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to render OpenAPI document: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// openAPIRenderer converts contract and attribute types to schemas
+type openAPIRenderer struct {
+	named map[string]bool // Entities and enums, which are referenced
+}
+
+// operation describes one route
+func (r *openAPIRenderer) operation(route models.HTTPRoute) *openAPIOperation {
+	op := &openAPIOperation{
+		OperationID: lowerFirst(route.Handler),
+		Summary:     route.Description,
+		Responses:   map[string]openAPIResponse{"200": r.response(route)},
+	}
+
+	fields := make(map[string]string, len(route.Request.Fields))
+	for name, typ := range route.Request.Fields {
+		fields[name] = typ
+	}
+	for _, segment := range strings.Split(route.Path, "/") {
+		name, ok := strings.CutPrefix(segment, "{")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSuffix(name, "}")
+		typ := fields[name]
+		delete(fields, name)
+		if typ == "" {
+			typ = "string"
+		}
+		op.Parameters = append(op.Parameters, openAPIParameter{Name: name, In: "path", Required: true, Schema: r.schema(typ)})
+	}
+	if len(fields) == 0 {
+		return op
+	}
+
+	if bodyMethods[route.Method] {
+		op.RequestBody = &openAPIRequestBody{Required: true, Content: jsonContent(r.body(fields))}
+		return op
+	}
+	for _, name := range sortedFieldNames(fields) {
+		op.Parameters = append(op.Parameters, openAPIParameter{Name: name, In: "query", Schema: r.schema(fields[name])})
+	}
+	return op
+}
+
+// response describes the success response of a route
+func (r *openAPIRenderer) response(route models.HTTPRoute) openAPIResponse {
+	response := openAPIResponse{Description: route.Description}
+	if response.Description == "" {
+		response.Description = "Successful response"
+	}
+	if len(route.Response.Fields) > 0 {
+		response.Content = jsonContent(r.body(route.Response.Fields))
+	}
+	return response
+}
+
+// body returns the schema of a JSON body: the type of its body field alone,
+// else an object of the fields
+func (r *openAPIRenderer) body(fields map[string]string) *openAPISchema {
+	if typ, ok := fields[bodyField]; ok && len(fields) == 1 {
+		return r.schema(typ)
+	}
+	object := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema, len(fields))}
+	for name, typ := range fields {
+		object.Properties[name] = r.schema(typ)
+	}
+	return object
+}
+
+// components returns the schemas of the data model's entities and enums
+func (r *openAPIRenderer) components(dm models.DataModel) map[string]*openAPISchema {
+	schemas := make(map[string]*openAPISchema)
+	for _, entity := range dm.Entities {
+		object := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema, len(entity.Attributes))}
+		for name, typ := range entity.Attributes {
+			object.Properties[name] = r.schema(typ)
+		}
+		schemas[entity.Name] = object
+	}
+	for _, enum := range dm.Enums {
+		schemas[enum.Name] = &openAPISchema{Type: "string", Description: enum.Description, Enum: enum.Values}
+	}
+	return schemas
+}
+
+// schema returns the schema of a Go or specification type: entities and
+// enums are referenced, slices become arrays and maps objects. Types naming
+// nothing known are described as strings.
+func (r *openAPIRenderer) schema(typ string) *openAPISchema {
+	typ = strings.TrimPrefix(strings.TrimSpace(typ), "*")
+	lower := strings.ToLower(typ)
+
+	if known, ok := openAPITypes[lower]; ok {
+		return &known
+	}
+	switch {
+	case strings.HasPrefix(typ, "[]"):
+		return &openAPISchema{Type: "array", Items: r.schema(typ[2:])}
+	case strings.HasPrefix(typ, "map[") && strings.Contains(typ, "]"):
+		return &openAPISchema{Type: "object", AdditionalProperties: r.schema(typ[strings.Index(typ, "]")+1:])}
+	}
+	for _, prefix := range []string{"list of ", "array of ", "set of "} {
+		if strings.HasPrefix(lower, prefix) {
+			return &openAPISchema{Type: "array", Items: r.schema(typ[len(prefix):])}
+		}
+	}
+
+	name := typ
+	if _, unqualified, ok := strings.Cut(typ, "."); ok {
+		name = unqualified
+	}
+	if r.named[name] {
+		return &openAPISchema{Ref: "#/components/schemas/" + name}
+	}
+
+	// "unique email string": the first word naming a type wins
+	for _, word := range typeWord.FindAllString(lower, -1) {
+		if known, ok := openAPITypes[word]; ok {
+			return &known
+		}
+	}
+	return &openAPISchema{Type: "string"}
+}
+
+// jsonContent returns the JSON media type of a body
+func jsonContent(schema *openAPISchema) map[string]openAPIMediaType {
+	return map[string]openAPIMediaType{"application/json": {Schema: schema}}
+}
+
+// sortedFieldNames returns the names of contract fields in order
+func sortedFieldNames(fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// lowerFirst returns s with its first letter in lower case
+func lowerFirst(s string) string {
+	runes := []rune(s)
+	if len(runes) > 0 {
+		runes[0] = unicode.ToLower(runes[0])
+	}
+	return string(runes)
+}
@@ -0,0 +1,101 @@
+package templates
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/clarify/importers"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func openAPIFixture() *models.FinalClarifiedSpecification {
+	return &models.FinalClarifiedSpecification{
+		Requirements: models.Requirements{Functional: []models.FunctionalRequirement{{ID: "FR-001", Description: "Manage users"}}},
+		DataModel: models.DataModel{
+			Entities: []models.Entity{{Name: "User", Attributes: map[string]string{
+				"id": "uuid", "email": "email string", "status": "UserStatus", "created_at": "timestamp", "tags": "[]string",
+			}}},
+			Enums: []models.Enum{{Name: "UserStatus", Values: []string{"active", "banned"}}},
+		},
+		APIContracts: []models.APIContract{
+			{Endpoint: "/users", Method: "GET", Description: "List users",
+				Request:  models.ContractSchema{Fields: map[string]string{"limit": "int"}},
+				Response: models.ContractSchema{Fields: map[string]string{"body": "[]User"}}},
+			{Endpoint: "/users", Method: "POST",
+				Request:  models.ContractSchema{Fields: map[string]string{"email": "string", "status": "UserStatus"}},
+				Response: models.ContractSchema{Fields: map[string]string{"body": "User"}}},
+			{Endpoint: "/users/:id", Method: "DELETE"},
+			{Protocol: "grpc", Service: "users.v1.UserService", Method: "GetUser"},
+		},
+	}
+}
+
+func TestRenderOpenAPI(t *testing.T) {
+	fcs := openAPIFixture()
+	out, err := RenderOpenAPI(fcs)
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(out), &doc))
+	paths := doc["paths"].(map[string]any)
+	assert.Len(t, paths, 2, "gRPC methods are not described")
+
+	list := paths["/users"].(map[string]any)["get"].(map[string]any)
+	assert.Equal(t, "getUsers", list["operationId"])
+	assert.Equal(t, []any{map[string]any{"name": "limit", "in": "query", "schema": map[string]any{"type": "integer"}}}, list["parameters"])
+	assert.Contains(t, out, "$ref: '#/components/schemas/User'")
+
+	create := paths["/users"].(map[string]any)["post"].(map[string]any)
+	body := create["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/UserStatus"}, body["properties"].(map[string]any)["status"])
+
+	remove := paths["/users/{id}"].(map[string]any)["delete"].(map[string]any)
+	assert.Equal(t, []any{map[string]any{"name": "id", "in": "path", "required": true, "schema": map[string]any{"type": "string"}}}, remove["parameters"])
+	assert.Equal(t, map[string]any{"description": "Successful response"}, remove["responses"].(map[string]any)["200"])
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	user := schemas["User"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "format": "uuid"}, user["id"])
+	assert.Equal(t, map[string]any{"type": "string", "format": "email"}, user["email"])
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, user["created_at"])
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": "string"}}, user["tags"])
+	assert.Equal(t, []any{"active", "banned"}, schemas["UserStatus"].(map[string]any)["enum"])
+
+	out, err = RenderOpenAPI(&models.FinalClarifiedSpecification{})
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestRenderOpenAPI_RoundTrip(t *testing.T) {
+	fcs := openAPIFixture()
+	gen, err := NewTemplateGenerator()
+	require.NoError(t, err)
+
+	data := ExtractTemplateData(fcs)
+	require.NotEmpty(t, data.OpenAPI)
+	assert.True(t, gen.IsBoilerplateFile(OpenAPIPath))
+	document, err := gen.GenerateBoilerplate(context.Background(), OpenAPIPath, data)
+	require.NoError(t, err)
+	assert.Contains(t, document, "openapi: 3.0.3\n")
+
+	// Importing the document gives back the HTTP contracts
+	result, err := importers.Import(document)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, data.ProjectName, result.Name)
+	require.Len(t, result.APIContracts, 3)
+
+	byRoute := make(map[string]models.APIContract)
+	for _, contract := range result.APIContracts {
+		byRoute[contract.Method+" "+contract.Endpoint] = contract
+	}
+	assert.Equal(t, map[string]string{"limit": "int"}, byRoute["GET /users"].Request.Fields)
+	assert.Equal(t, map[string]string{"body": "[]User"}, byRoute["GET /users"].Response.Fields)
+	assert.Equal(t, map[string]string{"email": "string", "status": "UserStatus"}, byRoute["POST /users"].Request.Fields)
+	assert.Equal(t, map[string]string{"id": "string"}, byRoute["DELETE /users/{id}"].Request.Fields)
+	assert.Len(t, result.Entities, 1)
+	assert.Len(t, result.Enums, 1)
+}
@@ -0,0 +1,281 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// ScaffoldKind selects the template a scaffolded source file is rendered from
+type ScaffoldKind string
+
+const (
+	// ScaffoldEntities declares entity structs
+	ScaffoldEntities ScaffoldKind = "entity"
+
+	// ScaffoldRepositories declares a repository interface per entity
+	ScaffoldRepositories ScaffoldKind = "repository"
+
+	// ScaffoldPostgres implements the repositories with database/sql against PostgreSQL
+	ScaffoldPostgres ScaffoldKind = "postgres"
+
+	// ScaffoldSchema creates (or, in a down migration, drops) the entities' tables
+	ScaffoldSchema ScaffoldKind = "schema"
+
+	// ScaffoldSQLC implements the repositories as wrappers around the queries
+	// sqlc generates
+	ScaffoldSQLC ScaffoldKind = "sqlc"
+
+	// ScaffoldQueries declares the queries sqlc generates code for
+	ScaffoldQueries ScaffoldKind = "queries"
+
+	// ScaffoldSQLCConfig configures sqlc
+	ScaffoldSQLCConfig ScaffoldKind = "sqlc-config"
+
+	// ScaffoldRouter routes the HTTP API contracts to a handler interface and
+	// serves them with graceful shutdown, in the architecture's HTTP framework
+	ScaffoldRouter ScaffoldKind = "router"
+)
+
+// RouterPath is where the HTTP router is rendered
+const RouterPath = "internal/server/router.go"
+
+// sqlc layout of projects whose persistence strategy is sqlc: the Go code
+// sqlc generates from the schema and queries goes to SQLCPackageDir
+const (
+	SQLCConfigPath  = "sqlc.yaml"
+	SQLCSchemaPath  = "db/schema.sql"
+	SQLCQueriesPath = "db/queries.sql"
+	SQLCPackageDir  = "internal/db"
+)
+
+// scaffoldTemplates maps each kind to its template
+var scaffoldTemplates = map[ScaffoldKind]string{
+	ScaffoldEntities:     "entity.go.tmpl",
+	ScaffoldRepositories: "repository.go.tmpl",
+	ScaffoldPostgres:     "postgres.go.tmpl",
+	ScaffoldSchema:       "schema.sql.tmpl",
+	ScaffoldSQLC:         "sqlc.go.tmpl",
+	ScaffoldQueries:      "queries.sql.tmpl",
+	ScaffoldSQLCConfig:   "sqlc.yaml.tmpl",
+	ScaffoldRouter:       "router_stdlib.go.tmpl",
+}
+
+// routerTemplates maps HTTP frameworks other than net/http to the template
+// of their router
+var routerTemplates = map[string]string{
+	models.HTTPFrameworkChi:  "router_chi.go.tmpl",
+	models.HTTPFrameworkGin:  "router_gin.go.tmpl",
+	models.HTTPFrameworkEcho: "router_echo.go.tmpl",
+}
+
+// sqlcEngines maps dialects to the engines sqlc names them by
+var sqlcEngines = map[models.SQLDialect]string{
+	models.DialectPostgres: "postgresql",
+	models.DialectMySQL:    "mysql",
+	models.DialectSQLite:   "sqlite",
+}
+
+// ScaffoldData is the data model derived content of a scaffolded file
+type ScaffoldData struct {
+	Package        string   // Go package of the file
+	Imports        []string // Standard library imports
+	ProjectImports []string // Imports of the project's packages
+	Entities       []ScaffoldEntity
+	Drop           bool // Schema only: drop the tables instead of creating them
+	Dialect        models.SQLDialect
+	Framework      string          // Router only: the HTTP framework
+	Routes         []ScaffoldRoute // Router only
+}
+
+// ScaffoldRoute is a route of the HTTP router
+type ScaffoldRoute struct {
+	Method      string
+	Path        string // Path with {name} parameters, as documented
+	Pattern     string // Path as the framework writes it
+	Handler     string // Handlers method serving the route
+	Description string
+}
+
+// ScaffoldEntity is one entity of a scaffolded file
+type ScaffoldEntity struct {
+	Entity string // Name in the data model
+	Name   string // Go type name
+	Type   string // Go type as the file refers to it, e.g. models.User
+	Var    string // Parameter name for a value of the type
+	Plural string // Go type name in plural, naming the query listing the entities
+	Table  string
+	Decl   string          // Type declaration, for entity files
+	Fields []ScaffoldField // Stored fields, the key first; none for a join table
+
+	// References are the foreign keys of the entity's table. A join table
+	// has nothing but references, which together form its key.
+	References []ScaffoldReference
+
+	Dialect models.SQLDialect // Dialect of the queries on the table
+}
+
+// ScaffoldField is a field of an entity stored in a table column
+type ScaffoldField struct {
+	Name    string // Go field name
+	Type    string // Go type within the entity's package; qualified in a sqlc wrapper
+	Column  string
+	SQLType string
+
+	// SQLCName and SQLCType are the field and Go type sqlc gives the column;
+	// SQLCType is "" when it is Type
+	SQLCName string
+	SQLCType string
+}
+
+// ToSQLC returns expr, a value of the field, converted to the type sqlc
+// gives the column
+func (f ScaffoldField) ToSQLC(expr string) string {
+	if f.SQLCType == "" {
+		return expr
+	}
+	return f.SQLCType + "(" + expr + ")"
+}
+
+// FromSQLC returns expr, a value of the column as sqlc gives it, converted
+// to the field's type
+func (f ScaffoldField) FromSQLC(expr string) string {
+	if f.SQLCType == "" {
+		return expr
+	}
+	return f.Type + "(" + expr + ")"
+}
+
+// Engine returns the sqlc engine of the dialect, postgresql when unset
+func (d ScaffoldData) Engine() string {
+	if engine, ok := sqlcEngines[d.Dialect]; ok {
+		return engine
+	}
+	return sqlcEngines[models.DialectPostgres]
+}
+
+// SingleImport returns the file's import when it has exactly one
+func (d ScaffoldData) SingleImport() string {
+	imports := append(slices.Clone(d.Imports), d.ProjectImports...)
+	if len(imports) != 1 {
+		return ""
+	}
+	return imports[0]
+}
+
+// ScaffoldReference is a foreign key of a table
+type ScaffoldReference struct {
+	Column    string
+	SQLType   string // Set when the column is not a stored field of the entity
+	Table     string // Referenced table
+	RefColumn string // Referenced key column
+}
+
+// Key returns the field holding the entity's primary key
+func (e ScaffoldEntity) Key() ScaffoldField {
+	return e.Fields[0]
+}
+
+// Values returns the stored fields other than the key
+func (e ScaffoldEntity) Values() []ScaffoldField {
+	return e.Fields[1:]
+}
+
+// Columns returns the stored columns, the key first, separated by commas
+func (e ScaffoldEntity) Columns() string {
+	columns := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		columns[i] = field.Column
+	}
+	return strings.Join(columns, ", ")
+}
+
+// param returns the nth positional parameter of a query in the entity's
+// dialect: $n in PostgreSQL, ? in MySQL and SQLite
+func (e ScaffoldEntity) param(n int) string {
+	if e.Dialect == models.DialectMySQL || e.Dialect == models.DialectSQLite {
+		return "?"
+	}
+	return fmt.Sprintf("$%d", n)
+}
+
+// Placeholders returns a positional parameter for each stored column
+func (e ScaffoldEntity) Placeholders() string {
+	params := make([]string, len(e.Fields))
+	for i := range e.Fields {
+		params[i] = e.param(i + 1)
+	}
+	return strings.Join(params, ", ")
+}
+
+// Assignments returns the SET clause updating every column but the key, whose
+// parameter follows theirs
+func (e ScaffoldEntity) Assignments() string {
+	values := e.Values()
+	assignments := make([]string, len(values))
+	for i, field := range values {
+		assignments[i] = fmt.Sprintf("%s = %s", field.Column, e.param(i+1))
+	}
+	return strings.Join(assignments, ", ")
+}
+
+// KeyParam returns the parameter of the key in an UPDATE
+func (e ScaffoldEntity) KeyParam() string {
+	return e.param(len(e.Fields))
+}
+
+// IDParam returns the parameter of the key in a query filtering by nothing else
+func (e ScaffoldEntity) IDParam() string {
+	return e.param(1)
+}
+
+// ColumnDefs returns the column and constraint definitions of the entity's
+// table. Reference columns that are not fields may be NULL, except in a join
+// table, where they form the primary key.
+func (e ScaffoldEntity) ColumnDefs() []string {
+	join := len(e.Fields) == 0
+	defs := make([]string, 0, len(e.Fields)+2*len(e.References)+1)
+	for i, field := range e.Fields {
+		constraint := "NOT NULL"
+		if i == 0 {
+			constraint = "PRIMARY KEY"
+		}
+		defs = append(defs, fmt.Sprintf("%s %s %s", field.Column, field.SQLType, constraint))
+	}
+
+	var key []string
+	for _, ref := range e.References {
+		if ref.SQLType == "" {
+			continue
+		}
+		def := ref.Column + " " + ref.SQLType
+		if join {
+			def += " NOT NULL"
+			key = append(key, ref.Column)
+		}
+		defs = append(defs, def)
+	}
+	if join {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(key, ", ")))
+	}
+	for _, ref := range e.References {
+		defs = append(defs, fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)", ref.Column, ref.Table, ref.RefColumn))
+	}
+	return defs
+}
+
+// GenerateScaffold renders a scaffolded source file
+func (g *templateGenerator) GenerateScaffold(_ context.Context, kind ScaffoldKind, data ScaffoldData) (string, error) {
+	name := scaffoldTemplates[kind]
+	if router, ok := routerTemplates[data.Framework]; ok && kind == ScaffoldRouter {
+		name = router
+	}
+	tmpl, exists := g.templates[name]
+	if !exists {
+		return "", fmt.Errorf("no template for scaffold %q", kind)
+	}
+	return g.render(tmpl, data)
+}
@@ -0,0 +1,135 @@
+package generate
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// testFramework resolves the framework tests are generated with: the
+// testing strategy's, else fallback (the house style's), else the default.
+// Unknown names, which only reach here from hand-edited FCS files, are
+// skipped.
+func testFramework(fcs *models.FinalClarifiedSpecification, fallback string) string {
+	if fcs != nil && models.IsTestFramework(fcs.TestingStrategy.TestFramework) {
+		return fcs.TestingStrategy.TestFramework
+	}
+	if models.IsTestFramework(fallback) {
+		return fallback
+	}
+	return models.DefaultTestFramework
+}
+
+// foreignTestImports returns the test library imports in code that do not
+// belong to framework, sorted. Code that does not parse is left to build
+// validation.
+func foreignTestImports(code, framework string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, parser.ImportsOnly)
+	if err != nil {
+		return nil
+	}
+
+	var foreign []string
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if models.ForeignTestLibrary(path, framework) {
+			foreign = append(foreign, path)
+		}
+	}
+	sort.Strings(foreign)
+	return foreign
+}
+
+// writeTestFrameworkGuidance writes the assertion rules for framework
+func writeTestFrameworkGuidance(sb *strings.Builder, framework string) {
+	sb.WriteString("4. **Assertions**:\n")
+	switch framework {
+	case models.TestFrameworkStdlib:
+		sb.WriteString("   - Use only the standard library testing package: t.Errorf, t.Fatalf and reflect.DeepEqual\n")
+		sb.WriteString("   - Do NOT import testify, gomega or any other assertion library\n")
+	case models.TestFrameworkGomega:
+		sb.WriteString("   - Use gomega matchers with g := gomega.NewWithT(t) inside standard Go test functions\n")
+		sb.WriteString("   - Import github.com/onsi/gomega; do NOT import testify or ginkgo\n")
+	default:
+		sb.WriteString("   - Use testify/assert for checks and testify/require when the test cannot continue\n")
+		sb.WriteString("   - Import github.com/stretchr/testify only; do NOT import gomega or ginkgo\n")
+	}
+	sb.WriteString("   - Check all return values (including errors)\n")
+	sb.WriteString("   - Verify state changes when applicable\n\n")
+}
+
+// writeTestFrameworkExample writes an example test in framework's style
+func writeTestFrameworkExample(sb *strings.Builder, framework string) {
+	var check string
+	switch framework {
+	case models.TestFrameworkStdlib:
+		check = "" +
+			"            if (err != nil) != tt.wantErr {\n" +
+			"                t.Fatalf(\"FunctionName() error = %v, wantErr %v\", err, tt.wantErr)\n" +
+			"            }\n" +
+			"            if !reflect.DeepEqual(got, tt.want) {\n" +
+			"                t.Errorf(\"FunctionName() = %v, want %v\", got, tt.want)\n" +
+			"            }\n"
+	case models.TestFrameworkGomega:
+		check = "" +
+			"            g := gomega.NewWithT(t)\n" +
+			"            if tt.wantErr {\n" +
+			"                g.Expect(err).To(gomega.HaveOccurred())\n" +
+			"                return\n" +
+			"            }\n" +
+			"            g.Expect(err).NotTo(gomega.HaveOccurred())\n" +
+			"            g.Expect(got).To(gomega.Equal(tt.want))\n"
+	default:
+		check = "" +
+			"            if tt.wantErr {\n" +
+			"                assert.Error(t, err)\n" +
+			"                return\n" +
+			"            }\n" +
+			"            require.NoError(t, err)\n" +
+			"            assert.Equal(t, tt.want, got)\n"
+	}
+
+	sb.WriteString("```go\n")
+	sb.WriteString("func TestFunctionName(t *testing.T) {\n")
+	sb.WriteString("    tests := []struct {\n")
+	sb.WriteString("        name    string\n")
+	sb.WriteString("        input   InputType\n")
+	sb.WriteString("        want    OutputType\n")
+	sb.WriteString("        wantErr bool\n")
+	sb.WriteString("    }{\n")
+	sb.WriteString("        {\n")
+	sb.WriteString("            name:    \"successful case\",\n")
+	sb.WriteString("            input:   validInput,\n")
+	sb.WriteString("            want:    expectedOutput,\n")
+	sb.WriteString("            wantErr: false,\n")
+	sb.WriteString("        },\n")
+	sb.WriteString("        {\n")
+	sb.WriteString("            name:    \"error case\",\n")
+	sb.WriteString("            input:   invalidInput,\n")
+	sb.WriteString("            want:    OutputType{},\n")
+	sb.WriteString("            wantErr: true,\n")
+	sb.WriteString("        },\n")
+	sb.WriteString("    }\n\n")
+	sb.WriteString("    for _, tt := range tests {\n")
+	sb.WriteString("        t.Run(tt.name, func(t *testing.T) {\n")
+	sb.WriteString("            got, err := FunctionName(tt.input)\n")
+	sb.WriteString(check)
+	sb.WriteString("        })\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("}\n")
+	sb.WriteString("```\n\n")
+}
+
+// foreignImportsNote asks a retry to drop imports outside the framework
+func foreignImportsNote(framework string, foreign []string) string {
+	return fmt.Sprintf("\n\nIMPORTANT: A previous attempt imported %s, which the %s test framework does not allow. Use only %s.\n",
+		strings.Join(foreign, ", "), framework, framework)
+}
@@ -0,0 +1,132 @@
+package generate
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedLLMClient returns its responses in order, repeating the last one
+type scriptedLLMClient struct {
+	mu        sync.Mutex
+	responses []string
+	prompts   []string
+}
+
+func (s *scriptedLLMClient) Generate(_ context.Context, prompt string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prompts = append(s.prompts, prompt)
+	response := s.responses[0]
+	if len(s.responses) > 1 {
+		s.responses = s.responses[1:]
+	}
+	return response, nil
+}
+
+func (s *scriptedLLMClient) GenerateStructured(_ context.Context, _ string, _ interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (s *scriptedLLMClient) Chat(_ context.Context, _ []llm.Message) (string, error) {
+	return "", nil
+}
+
+func (s *scriptedLLMClient) Provider() string { return "mock" }
+func (s *scriptedLLMClient) Model() string    { return "mock-model" }
+
+const (
+	testifyTest = "package app\n\nimport (\n\t\"testing\"\n\n\t\"github.com/stretchr/testify/assert\"\n)\n\nfunc TestApp(t *testing.T) { assert.True(t, true) }\n"
+	gomegaTest  = "package app\n\nimport (\n\t\"testing\"\n\n\t\"github.com/onsi/gomega\"\n)\n\nfunc TestApp(t *testing.T) { gomega.NewWithT(t).Expect(true).To(gomega.BeTrue()) }\n"
+	stdlibTest  = "package app\n\nimport \"testing\"\n\nfunc TestApp(t *testing.T) {}\n"
+)
+
+func TestForeignTestImports(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		framework string
+		want      []string
+	}{
+		{"testify allowed", testifyTest, models.TestFrameworkTestify, nil},
+		{"testify under stdlib", testifyTest, models.TestFrameworkStdlib, []string{"github.com/stretchr/testify/assert"}},
+		{"testify under gomega", testifyTest, models.TestFrameworkGomega, []string{"github.com/stretchr/testify/assert"}},
+		{"gomega allowed", gomegaTest, models.TestFrameworkGomega, nil},
+		{"gomega under testify", gomegaTest, models.TestFrameworkTestify, []string{"github.com/onsi/gomega"}},
+		{"stdlib always allowed", stdlibTest, models.TestFrameworkGomega, nil},
+		{"ginkgo never allowed", "package app\n\nimport . \"github.com/onsi/ginkgo/v2\"\n", models.TestFrameworkGomega, []string{"github.com/onsi/ginkgo/v2"}},
+		{"prefix must match a path element", "package app\n\nimport \"github.com/onsi/gomegax\"\n", models.TestFrameworkTestify, nil},
+		{"unparsable code is left to build validation", "not go", models.TestFrameworkStdlib, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, foreignTestImports(tt.code, tt.framework))
+		})
+	}
+}
+
+func TestTestPrompt_Framework(t *testing.T) {
+	plan := &models.GenerationPlan{}
+	tester := &llmTester{}
+
+	stdlib := tester.buildTestGenerationPrompt("internal/app/app.go", plan, nil, nil, "", models.TestFrameworkStdlib)
+	assert.Contains(t, stdlib, "Use only the standard library testing package")
+	assert.Contains(t, stdlib, "reflect.DeepEqual(got, tt.want)")
+	assert.NotContains(t, stdlib, "assert.Equal")
+
+	gomega := tester.buildTestGenerationPrompt("internal/app/app.go", plan, nil, nil, "", models.TestFrameworkGomega)
+	assert.Contains(t, gomega, "gomega.NewWithT(t)")
+	assert.NotContains(t, gomega, "require.NoError")
+
+	testify := tester.buildTestGenerationPrompt("internal/app/app.go", plan, nil, nil, "", models.TestFrameworkTestify)
+	assert.Contains(t, testify, "require.NoError(t, err)")
+}
+
+func TestGenerateTestFile_EnforcesFramework(t *testing.T) {
+	plan := &models.GenerationPlan{}
+
+	t.Run("retry drops foreign imports", func(t *testing.T) {
+		client := &scriptedLLMClient{responses: []string{testifyTest, stdlibTest}}
+		tester := &llmTester{client: client}
+
+		_, code, err := tester.generateTestFile(context.Background(), "internal/app/app.go", plan, nil, nil, "", models.TestFrameworkStdlib)
+		require.NoError(t, err)
+		assert.Equal(t, strings.TrimSpace(stdlibTest), strings.TrimSpace(code))
+		require.Len(t, client.prompts, 2)
+		assert.Contains(t, client.prompts[1], "A previous attempt imported github.com/stretchr/testify/assert")
+	})
+
+	t.Run("persistent violation is rejected", func(t *testing.T) {
+		client := &scriptedLLMClient{responses: []string{gomegaTest}}
+		tester := &llmTester{client: client}
+
+		_, _, err := tester.generateTestFile(context.Background(), "internal/app/app.go", plan, nil, nil, "", models.TestFrameworkTestify)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "github.com/onsi/gomega")
+		assert.Len(t, client.prompts, 2)
+	})
+
+	t.Run("pipeline uses the FCS framework", func(t *testing.T) {
+		client := &scriptedLLMClient{responses: []string{gomegaTest}}
+		tester, err := NewTester(TesterConfig{LLMClient: client})
+		require.NoError(t, err)
+
+		plan := &models.GenerationPlan{FileTree: models.FileTree{Files: []models.File{{Path: "internal/app/app.go"}}}}
+		fcs := &models.FinalClarifiedSpecification{
+			TestingStrategy: models.TestingStrategy{TestFramework: models.TestFrameworkGomega},
+		}
+
+		patches, err := tester.Generate(context.Background(), []string{"internal/app"}, plan, fcs)
+		require.NoError(t, err)
+		assert.Len(t, patches, 1)
+		require.Len(t, client.prompts, 1)
+		assert.Contains(t, client.prompts[0], "gomega.NewWithT(t)")
+	})
+}
@@ -0,0 +1,273 @@
+package generate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/validate"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/gocreator/pkg/llm"
+)
+
+// fixesMarker starts the first line of a test repair response that corrects
+// an implementation file instead of the test file
+const fixesMarker = "// Fixes: "
+
+// maxRepairSourceBytes caps the implementation sources quoted in a test
+// repair prompt; files past it are listed by name only
+const maxRepairSourceBytes = 48 * 1024
+
+// TestRepairer regenerates failing tests, or the code they test
+type TestRepairer interface {
+	// Repair feeds the failures of a test run back to the model one test
+	// file at a time and reruns the tests, until they pass or the attempts
+	// run out
+	Repair(ctx context.Context, projectRoot string, tests *models.TestResult) (*TestRepairResult, error)
+}
+
+// TestRepairConfig contains configuration for creating a test repairer
+type TestRepairConfig struct {
+	LLMClient llm.Client
+	Validator validate.TestValidator
+	FileOps   fsops.FileOps // Rooted at the project; writes the repaired files
+
+	// MaxAttempts bounds the test reruns after repairs (default 2)
+	MaxAttempts int
+
+	// Preamble is organization-wide guidance placed ahead of every prompt
+	Preamble string
+}
+
+// TestRepairResult is the outcome of a test repair
+type TestRepairResult struct {
+	Tests    *models.TestResult // Last test result
+	Attempts int                // Repair rounds run
+	Repaired []string           // Files rewritten, test or implementation, in order of first repair
+}
+
+// llmTestRepairer implements TestRepairer with the tester model
+type llmTestRepairer struct {
+	client      llm.Client
+	validator   validate.TestValidator
+	fileOps     fsops.FileOps
+	maxAttempts int
+	preamble    string
+}
+
+// NewTestRepairer creates a new TestRepairer instance
+func NewTestRepairer(cfg TestRepairConfig) (TestRepairer, error) {
+	if cfg.LLMClient == nil {
+		return nil, fmt.Errorf("LLM client is required")
+	}
+	if cfg.Validator == nil {
+		return nil, fmt.Errorf("test validator is required")
+	}
+	if cfg.FileOps == nil {
+		return nil, fmt.Errorf("file operations handler is required")
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 2
+	}
+	return &llmTestRepairer{
+		client:      cfg.LLMClient,
+		validator:   cfg.Validator,
+		fileOps:     cfg.FileOps,
+		maxAttempts: cfg.MaxAttempts,
+		preamble:    cfg.Preamble,
+	}, nil
+}
+
+// Repair implements TestRepairer. Each round sends every test file with
+// failing tests, with the package's implementation, and writes back the file
+// the model corrects: the test file, or an implementation file it names.
+// A response that does not parse is discarded. Repair stops early when a
+// round changes nothing.
+func (r *llmTestRepairer) Repair(ctx context.Context, projectRoot string, tests *models.TestResult) (*TestRepairResult, error) {
+	goMod, _ := r.fileOps.ReadFile(ctx, "go.mod")
+	modulePath := parseModulePath(goMod)
+
+	loop := &repairLoop[*models.TestResult, models.TestFailure]{
+		kind:        "test",
+		client:      r.client,
+		fileOps:     r.fileOps,
+		maxAttempts: r.maxAttempts,
+		validate: func(ctx context.Context) (*models.TestResult, error) {
+			return r.validator.Validate(ctx, projectRoot)
+		},
+		passed: func(tests *models.TestResult) bool { return tests.Success },
+		targets: func(tests *models.TestResult) []repairTarget[models.TestFailure] {
+			return failingTestFiles(projectRoot, modulePath, tests.Failures)
+		},
+		prompt: func(target repairTarget[models.TestFailure], content, _ string) string {
+			return r.buildTestRepairPrompt(target, content, packageSources(projectRoot, path.Dir(target.file)))
+		},
+		redirect: func(target repairTarget[models.TestFailure], code string) (string, string, error) {
+			return fixedFile(projectRoot, target.file, code)
+		},
+	}
+
+	outcome, err := loop.run(ctx, tests)
+	return &TestRepairResult{Tests: outcome.last, Attempts: outcome.attempts, Repaired: outcome.repaired}, err
+}
+
+// fixedFile returns the file a test repair response corrects and its code:
+// the test file, or the implementation file the response names on its first
+// line, which must be one the prompt showed
+func fixedFile(projectRoot, testFile, code string) (string, string, error) {
+	first, rest, ok := strings.Cut(code, "\n")
+	if !ok || !strings.HasPrefix(first, fixesMarker) {
+		return testFile, code, nil
+	}
+	named := path.Join(path.Dir(testFile), path.Base(strings.TrimSpace(strings.TrimPrefix(first, fixesMarker))))
+	if packageSources(projectRoot, path.Dir(testFile))[named] == "" {
+		return "", "", fmt.Errorf("response names %s, which is not an implementation file shown", named)
+	}
+	return named, strings.TrimSpace(rest), nil
+}
+
+// buildTestRepairPrompt constructs the LLM prompt that fixes a test file's failures
+func (r *llmTestRepairer) buildTestRepairPrompt(target repairTarget[models.TestFailure], testCode string, sources map[string]string) string {
+	failures := make([]string, len(target.problems))
+	for i, failure := range target.problems {
+		report := fmt.Sprintf("--- FAIL: %s", failure.Test)
+		if failure.Location != "" {
+			report += fmt.Sprintf(" (%s)", failure.Location)
+		}
+		if failure.Message != "" {
+			report += "\n" + failure.Message
+		}
+		failures[i] = report
+	}
+
+	files := []repairPromptFile{{heading: "Test File: " + target.file, content: testCode}}
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		files = append(files, repairPromptFile{
+			heading: "Implementation File: " + name,
+			content: sources[name],
+			omitted: sources[name] == "",
+		})
+	}
+
+	return repairPrompt{
+		role:     "an expert Go developer fixing failing tests",
+		task:     fmt.Sprintf("Tests in %s fail. Find out whether the test or the code under test is wrong, and fix that file.", target.file),
+		heading:  "Failures",
+		problems: failures,
+		files:    files,
+		requirements: []string{
+			"Prefer fixing the test: the implementation follows the specification. Fix the implementation only when the failure shows a bug in it",
+			"Keep every test that passes, and keep the tests' requirement references",
+			"Do not delete or skip a failing test to make it pass",
+			fmt.Sprintf("To fix an implementation file, start the response with the line `%s<file name>`", fixesMarker),
+		},
+	}.build(r.preamble)
+}
+
+// failingTestFiles groups test failures by the test file that defines the
+// failing test, sorted by path. The file is taken from the failure's
+// location, or found by the test's name among the package's test files;
+// failures of packages outside the module are left out.
+func failingTestFiles(projectRoot, modulePath string, failures []models.TestFailure) []repairTarget[models.TestFailure] {
+	byPath := make(map[string]*repairTarget[models.TestFailure])
+	for _, failure := range failures {
+		dir, ok := packageDir(failure.Package, modulePath)
+		if !ok {
+			continue
+		}
+		file := testFileOf(projectRoot, dir, failure)
+		if file == "" {
+			continue
+		}
+		if byPath[file] == nil {
+			byPath[file] = &repairTarget[models.TestFailure]{file: file}
+		}
+		byPath[file].problems = append(byPath[file].problems, failure)
+	}
+
+	files := make([]repairTarget[models.TestFailure], 0, len(byPath))
+	for _, file := range byPath {
+		files = append(files, *file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].file < files[j].file })
+	return files
+}
+
+// packageDir maps an import path of the module to its directory
+func packageDir(importPath, modulePath string) (string, bool) {
+	switch {
+	case modulePath == "":
+		return "", false
+	case importPath == modulePath:
+		return ".", true
+	case strings.HasPrefix(importPath, modulePath+"/"):
+		return strings.TrimPrefix(importPath, modulePath+"/"), true
+	}
+	return "", false
+}
+
+// testFileOf returns the test file in dir that holds a failing test, or ""
+func testFileOf(projectRoot, dir string, failure models.TestFailure) string {
+	if file, _, ok := strings.Cut(failure.Location, ":"); ok && strings.HasSuffix(file, "_test.go") {
+		candidate := path.Join(dir, path.Base(file))
+		if _, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(candidate))); err == nil {
+			return candidate
+		}
+	}
+
+	// Subtests fail with their parent, which is what the file declares
+	name, _, _ := strings.Cut(failure.Test, "/")
+	entries, err := os.ReadDir(filepath.Join(projectRoot, filepath.FromSlash(dir)))
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		//nolint:gosec // G304: Reading a test file of the project being validated
+		content, err := os.ReadFile(filepath.Join(projectRoot, filepath.FromSlash(dir), entry.Name()))
+		if err == nil && strings.Contains(string(content), "func "+name+"(") {
+			return path.Join(dir, entry.Name())
+		}
+	}
+	return ""
+}
+
+// packageSources returns the non-test Go files of a package directory by
+// path. Files past maxRepairSourceBytes map to "" and are listed by name only.
+func packageSources(projectRoot, dir string) map[string]string {
+	sources := make(map[string]string)
+	entries, err := os.ReadDir(filepath.Join(projectRoot, filepath.FromSlash(dir)))
+	if err != nil {
+		return sources
+	}
+
+	total := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		rel := path.Join(dir, name)
+		//nolint:gosec // G304: Reading a source file of the project being validated
+		content, err := os.ReadFile(filepath.Join(projectRoot, filepath.FromSlash(rel)))
+		if err != nil || total+len(content) > maxRepairSourceBytes {
+			sources[rel] = ""
+			continue
+		}
+		total += len(content)
+		sources[rel] = string(content)
+	}
+	return sources
+}
@@ -0,0 +1,102 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestRepairer_FixesImplementation(t *testing.T) {
+	root, fileOps := newRepairProject(t)
+	validator := &markerTestValidator{markerValidator{marker: "wrongTotal"}}
+	client := &scriptedLLMClient{responses: []string{
+		"// Fixes: cart.go\npackage cart\n\nfunc Total(items []int) int {\n\tsum := 0\n\tfor _, item := range items {\n\t\tsum += item\n\t}\n\treturn sum\n}",
+	}}
+
+	repairer, err := NewTestRepairer(TestRepairConfig{
+		LLMClient: client,
+		Validator: validator,
+		FileOps:   fileOps,
+	})
+	require.NoError(t, err)
+
+	tests, err := validator.Validate(context.Background(), root)
+	require.NoError(t, err)
+	require.False(t, tests.Success)
+
+	result, err := repairer.Repair(context.Background(), root, tests)
+	require.NoError(t, err)
+	assert.True(t, result.Tests.Success)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, []string{"internal/cart/cart.go"}, result.Repaired)
+	assert.Equal(t, 2, validator.runs)
+
+	// The prompt carries the failure, the test file and the code under test
+	require.Len(t, client.prompts, 1)
+	assert.Contains(t, client.prompts[0], "--- FAIL: TestTotal/empty (cart_test.go:8)")
+	assert.Contains(t, client.prompts[0], "# Test File: internal/cart/cart_test.go")
+	assert.Contains(t, client.prompts[0], "# Implementation File: internal/cart/cart.go")
+	assert.Contains(t, client.prompts[0], "return 1 } // wrongTotal")
+
+	cart, err := os.ReadFile(filepath.Join(root, "internal/cart/cart.go"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(cart), "package cart\n"), "the marker line is not written")
+	assert.NotContains(t, string(cart), "wrongTotal")
+}
+
+func TestTestRepairer_StopsWhenNothingChanges(t *testing.T) {
+	root, fileOps := newRepairProject(t)
+	validator := &markerTestValidator{markerValidator{marker: "wrongTotal"}}
+
+	// The rewritten test still fails because the implementation is wrong;
+	// then the model names a file it was not shown, which is discarded
+	client := &scriptedLLMClient{responses: []string{
+		"package cart\n\nimport \"testing\"\n\nfunc TestTotal(t *testing.T) {}",
+		"// Fixes: other.go\npackage cart\n",
+	}}
+	repairer, err := NewTestRepairer(TestRepairConfig{
+		LLMClient:   client,
+		Validator:   validator,
+		FileOps:     fileOps,
+		MaxAttempts: 3,
+	})
+	require.NoError(t, err)
+
+	tests, err := validator.Validate(context.Background(), root)
+	require.NoError(t, err)
+
+	result, err := repairer.Repair(context.Background(), root, tests)
+	require.NoError(t, err)
+	assert.False(t, result.Tests.Success)
+	assert.Equal(t, 2, result.Attempts, "a round that changes nothing stops the repair")
+	assert.Equal(t, []string{"internal/cart/cart_test.go"}, result.Repaired)
+	assert.Equal(t, 2, validator.runs)
+	assert.NoFileExists(t, filepath.Join(root, "internal/cart/other.go"))
+}
+
+func TestFailingTestFiles(t *testing.T) {
+	root, _ := newRepairProject(t)
+	failures := []models.TestFailure{
+		{Package: "example.com/shop/internal/cart", Test: "TestTotal/empty"},
+		{Package: "example.com/shop/internal/cart", Test: "TestTotal", Location: "cart_test.go:7"},
+		{Package: "example.com/other", Test: "TestOther"},
+		{Package: "example.com/shop/internal/cart", Test: "TestMissing"},
+	}
+
+	files := failingTestFiles(root, "example.com/shop", failures)
+	require.Len(t, files, 1)
+	assert.Equal(t, "internal/cart/cart_test.go", files[0].file)
+	assert.Len(t, files[0].problems, 2)
+
+	dir, ok := packageDir("example.com/shop", "example.com/shop")
+	assert.True(t, ok)
+	assert.Equal(t, ".", dir)
+	_, ok = packageDir("example.com/shopping", "example.com/shop")
+	assert.False(t, ok)
+}
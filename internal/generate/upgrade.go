@@ -0,0 +1,210 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/rs/zerolog/log"
+)
+
+// UpgradeStatus is what an upgrade did with a generated file
+type UpgradeStatus string
+
+const (
+	// UpgradeStatusUpdated files were rewritten from the current templates
+	UpgradeStatusUpdated UpgradeStatus = "updated"
+
+	// UpgradeStatusCurrent files already match the current templates
+	UpgradeStatusCurrent UpgradeStatus = "current"
+
+	// UpgradeStatusSkipped files were left alone; the reason says why
+	UpgradeStatusSkipped UpgradeStatus = "skipped"
+
+	// UpgradeStatusRegenerate files were written from prompts by another
+	// gocreator version and are worth regenerating
+	UpgradeStatusRegenerate UpgradeStatus = "regenerate"
+)
+
+// UpgradeResult describes the outcome for one file in the manifest
+type UpgradeResult struct {
+	Path   string
+	Owner  FileOwner
+	Status UpgradeStatus
+	Reason string
+}
+
+// UpgradeOptions controls an upgrade
+type UpgradeOptions struct {
+	// GeneratorVersion is the running gocreator version
+	GeneratorVersion string
+
+	// Project overrides the module path and binary name inferred from the FCS
+	Project templates.ProjectSettings
+
+	// TemplatesDir holds templates replacing the built-in ones (optional)
+	TemplatesDir string
+
+	// DryRun reports what would change without writing anything
+	DryRun bool
+
+	// Force rewrites template-owned files that were edited since generation
+	Force bool
+}
+
+// Upgrade re-renders the template-owned files recorded in the output
+// directory's manifest with the current templates and reports prompt-owned
+// files written by another gocreator version. Files not in the manifest are
+// never touched, and template-owned files edited since generation are skipped
+// unless opts.Force is set. Files rendered from the data model are skipped
+// too; regenerating the project renders them again.
+func Upgrade(ctx context.Context, outputDir string, fileOps fsops.FileOps, opts UpgradeOptions) ([]UpgradeResult, error) {
+	manifest, err := LoadManifest(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("no generation manifest at %s; regenerate the project to create one", ManifestPath(outputDir))
+	}
+	if manifest.FCS == nil {
+		return nil, fmt.Errorf("generation manifest has no specification to render templates from")
+	}
+
+	templateGen, err := templates.NewTemplateGeneratorWithOverrides(opts.TemplatesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create template generator: %w", err)
+	}
+
+	data := templates.ExtractTemplateData(manifest.FCS)
+	data.ApplySettings(opts.Project)
+	workspace, err := DetectWorkspace(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	if workspace != nil {
+		for _, module := range workspace.Modules {
+			data.AddLocalModule(module.Path, module.Dir, workspace.GoWork == "")
+		}
+	}
+
+	results := make([]UpgradeResult, 0, len(manifest.Files))
+	changed := false
+	for _, path := range manifest.Paths() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		entry := manifest.Files[path]
+		result := UpgradeResult{Path: path, Owner: entry.Owner}
+
+		exists, err := fileOps.Exists(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", path, err)
+		}
+		if !exists {
+			if entry.Owner == FileOwnerPrompt {
+				continue // Nothing to recommend for code people removed
+			}
+			result.Status = UpgradeStatusSkipped
+			result.Reason = "deleted since generation"
+			results = append(results, result)
+			continue
+		}
+		current, err := fileOps.Checksum(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to checksum %s: %w", path, err)
+		}
+		edited := current != entry.Checksum
+
+		if entry.Owner == FileOwnerPrompt {
+			if entry.GeneratorVersion == opts.GeneratorVersion {
+				continue
+			}
+			result.Status = UpgradeStatusRegenerate
+			result.Reason = "generated by " + describeVersion(entry.GeneratorVersion)
+			if edited {
+				result.Reason += ", edited since generation"
+			}
+			results = append(results, result)
+			continue
+		}
+
+		// Files rendered from the data model need the plan to render again
+		if !templates.IsBoilerplatePath(filepath.Base(path)) {
+			result.Status = UpgradeStatusSkipped
+			result.Reason = "rendered from the data model; regenerate to update"
+			results = append(results, result)
+			continue
+		}
+
+		// Render with the original timestamps so unchanged templates match;
+		// the go.mod of a module gets that module's data
+		fileData := data
+		if module, ok := manifest.FCS.BuildConfig.ModuleOf(path); ok && path == module.GoModPath() {
+			fileData = data.ForModule(module, manifest.FCS)
+		}
+		fileData.GeneratedAt = entry.GeneratedAt.Format(time.RFC3339)
+		fileData.Year = entry.GeneratedAt.Year()
+		content, err := templateGen.GenerateBoilerplate(ctx, path, fileData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", path, err)
+		}
+		checksum := fileOps.GenerateChecksum(content)
+
+		switch {
+		case checksum == current:
+			result.Status = UpgradeStatusCurrent
+		case edited && !opts.Force:
+			result.Status = UpgradeStatusSkipped
+			result.Reason = "edited since generation"
+		default:
+			result.Status = UpgradeStatusUpdated
+			if !opts.DryRun {
+				err := fileOps.AtomicWrite(ctx, path, content)
+				if errors.Is(err, fsops.ErrProtectedPath) {
+					result.Status = UpgradeStatusSkipped
+					result.Reason = "protected path"
+					break
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to write %s: %w", path, err)
+				}
+			}
+		}
+
+		// The file now holds this version's template output
+		if result.Status != UpgradeStatusSkipped && (entry.Checksum != checksum || entry.GeneratorVersion != opts.GeneratorVersion) {
+			entry.Checksum = checksum
+			entry.GeneratorVersion = opts.GeneratorVersion
+			manifest.Files[path] = entry
+			changed = true
+		}
+		results = append(results, result)
+	}
+
+	if changed && !opts.DryRun {
+		if err := manifest.Save(outputDir); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Info().
+		Str("output_dir", outputDir).
+		Int("files", len(manifest.Files)).
+		Bool("dry_run", opts.DryRun).
+		Msg("Upgrade completed")
+
+	return results, nil
+}
+
+// describeVersion names a recorded generator version for reports
+func describeVersion(version string) string {
+	if version == "" {
+		return "an unknown gocreator version"
+	}
+	return "gocreator " + version
+}
@@ -0,0 +1,265 @@
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/generate/templates"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupUpgradeProject writes a project generated by gocreator 0.1.0 whose
+// .gitignore was edited by hand and whose README.md was deleted
+func setupUpgradeProject(t *testing.T) (string, fsops.FileOps) {
+	t.Helper()
+	dir := t.TempDir()
+	fileOps, err := fsops.New(fsops.Config{RootDir: dir})
+	require.NoError(t, err)
+
+	generated := map[string]string{
+		"Makefile":            "build:\n\tgo build ./...\n",
+		".gitignore":          "/bin\n",
+		"README.md":           "# notes\n",
+		"internal/app/app.go": "package app\n",
+	}
+	onDisk := map[string]string{
+		"Makefile":            generated["Makefile"],
+		".gitignore":          "/bin\n/tmp\n",
+		"internal/app/app.go": generated["internal/app/app.go"],
+	}
+	for path, content := range onDisk {
+		require.NoError(t, fileOps.WriteFile(context.Background(), path, content))
+	}
+
+	manifest := NewManifest()
+	manifest.FCS = &models.FinalClarifiedSpecification{BuildConfig: models.BuildConfig{GoVersion: "1.22"}}
+	for path, content := range generated {
+		manifest.Files[path] = ManifestFile{
+			Owner:            FileOwnerOf(path),
+			Checksum:         fileOps.GenerateChecksum(content),
+			GeneratorVersion: "0.1.0",
+			GeneratedAt:      time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		}
+	}
+	require.NoError(t, manifest.Save(dir))
+
+	return dir, fileOps
+}
+
+func TestUpgrade(t *testing.T) {
+	dir, fileOps := setupUpgradeProject(t)
+
+	results, err := Upgrade(context.Background(), dir, fileOps, UpgradeOptions{GeneratorVersion: "0.2.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []UpgradeResult{
+		{Path: ".gitignore", Owner: FileOwnerTemplate, Status: UpgradeStatusSkipped, Reason: "edited since generation"},
+		{Path: "Makefile", Owner: FileOwnerTemplate, Status: UpgradeStatusUpdated},
+		{Path: "README.md", Owner: FileOwnerTemplate, Status: UpgradeStatusSkipped, Reason: "deleted since generation"},
+		{Path: "internal/app/app.go", Owner: FileOwnerPrompt, Status: UpgradeStatusRegenerate, Reason: "generated by gocreator 0.1.0"},
+	}, results)
+
+	gitignore, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, "/bin\n/tmp\n", string(gitignore), "hand edits are kept")
+
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "0.2.0", manifest.Files["Makefile"].GeneratorVersion)
+	assert.Equal(t, "0.1.0", manifest.Files["internal/app/app.go"].GeneratorVersion)
+
+	// The re-rendered Makefile is recognised as generated on the next run
+	results, err = Upgrade(context.Background(), dir, fileOps, UpgradeOptions{GeneratorVersion: "0.2.0"})
+	require.NoError(t, err)
+	assert.Equal(t, UpgradeStatusCurrent, results[1].Status)
+}
+
+func TestUpgrade_DryRunAndForce(t *testing.T) {
+	dir, fileOps := setupUpgradeProject(t)
+	before, err := os.ReadFile(ManifestPath(dir))
+	require.NoError(t, err)
+
+	results, err := Upgrade(context.Background(), dir, fileOps, UpgradeOptions{GeneratorVersion: "0.2.0", DryRun: true, Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, UpgradeStatusUpdated, results[0].Status, "forced over the hand edit")
+
+	gitignore, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, "/bin\n/tmp\n", string(gitignore), "dry run writes nothing")
+	after, err := os.ReadFile(ManifestPath(dir))
+	require.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+
+	_, err = Upgrade(context.Background(), dir, fileOps, UpgradeOptions{GeneratorVersion: "0.2.0", Force: true})
+	require.NoError(t, err)
+	gitignore, err = os.ReadFile(filepath.Join(dir, ".gitignore"))
+	require.NoError(t, err)
+	assert.NotEqual(t, "/bin\n/tmp\n", string(gitignore))
+}
+
+func TestUpgrade_RequiresManifest(t *testing.T) {
+	dir := t.TempDir()
+	fileOps, err := fsops.New(fsops.Config{RootDir: dir})
+	require.NoError(t, err)
+
+	_, err = Upgrade(context.Background(), dir, fileOps, UpgradeOptions{GeneratorVersion: "0.2.0"})
+	assert.ErrorContains(t, err, "no generation manifest")
+}
+
+func TestManifest_Record(t *testing.T) {
+	manifest := NewManifest()
+	manifest.Files["internal/old.go"] = ManifestFile{Owner: FileOwnerPrompt, Checksum: "old"}
+
+	fcs := &models.FinalClarifiedSpecification{ID: "fcs-1"}
+	manifest.Record(fcs, []models.GeneratedFile{
+		{Path: "go.mod", Checksum: "a"},
+		{Path: "cmd/app/main.go", Checksum: "b"},
+		{Path: "docs/README.md", Checksum: "c"},
+	}, &models.Provenance{
+		GeneratorVersion: "0.2.0",
+		Provider:         "anthropic",
+		Model:            "claude-sonnet-4-5-20250929",
+		PromptHashes:     map[string]string{"cmd/app/main.go": "hash-b"},
+		Files: map[string]models.FileProvenance{"cmd/app/main.go": {
+			TaskID:       "main",
+			InputTokens:  1200,
+			OutputTokens: 300,
+			StartedAt:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		}},
+	})
+	manifest.RecordDowngrades([]models.FileDowngrade{{Path: "./docs/README.md", To: models.DowngradeStub}})
+
+	assert.Same(t, fcs, manifest.FCS)
+	assert.Equal(t, []string{"cmd/app/main.go", "docs/README.md", "go.mod", "internal/old.go"}, manifest.Paths())
+	assert.Equal(t, FileOwnerTemplate, manifest.Files["go.mod"].Owner)
+	assert.Equal(t, FileOwnerPrompt, manifest.Files["docs/README.md"].Owner, "only root boilerplate is template-owned")
+	assert.Equal(t, "0.2.0", manifest.Files["cmd/app/main.go"].GeneratorVersion)
+	assert.Equal(t, "anthropic/claude-sonnet-4-5-20250929", manifest.Files["cmd/app/main.go"].Model)
+	assert.Equal(t, "hash-b", manifest.Files["cmd/app/main.go"].PromptHash)
+	assert.Equal(t, "main", manifest.Files["cmd/app/main.go"].TaskID)
+	assert.Equal(t, int64(1200), manifest.Files["cmd/app/main.go"].InputTokens)
+	assert.Equal(t, int64(300), manifest.Files["cmd/app/main.go"].OutputTokens)
+	require.NotNil(t, manifest.Files["cmd/app/main.go"].StartedAt)
+	assert.Nil(t, manifest.Files["go.mod"].StartedAt)
+	assert.Empty(t, manifest.Files["go.mod"].Model, "templates are not written by a model")
+	assert.Equal(t, models.DowngradeStub, manifest.Files["docs/README.md"].Model)
+	require.NotNil(t, manifest.Provenance)
+	assert.Equal(t, "anthropic", manifest.Provenance.Provider)
+	assert.Nil(t, manifest.Provenance.PromptHashes, "prompt hashes are kept per file")
+	assert.Nil(t, manifest.Provenance.Files)
+	assert.Equal(t, "old", manifest.Files["internal/old.go"].Checksum, "earlier entries are kept")
+}
+
+func TestManifest_RecordNestedModule(t *testing.T) {
+	dir := t.TempDir()
+	fileOps, err := fsops.New(fsops.Config{RootDir: dir})
+	require.NoError(t, err)
+
+	fcs := &models.FinalClarifiedSpecification{BuildConfig: models.BuildConfig{
+		GoVersion: "1.22",
+		Modules:   []models.ModuleConfig{{Dir: "services/api", Path: "github.com/acme/shop/api"}},
+	}}
+	generatedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	gen, err := templates.NewTemplateGenerator()
+	require.NoError(t, err)
+	data := templates.ExtractTemplateData(fcs).ForModule(fcs.BuildConfig.Modules[0], fcs)
+	data.GeneratedAt = generatedAt.Format(time.RFC3339)
+	data.Year = generatedAt.Year()
+	goMod, err := gen.GenerateBoilerplate(context.Background(), "go.mod", data)
+	require.NoError(t, err)
+
+	contents := map[string]string{
+		"services/api/go.mod":                  goMod,
+		"services/api/internal/models/user.go": "package models\n\ntype User struct{}\n",
+		"services/api/internal/app/app.go":     "package app\n",
+	}
+	for path, content := range contents {
+		require.NoError(t, fileOps.WriteFile(context.Background(), path, content))
+	}
+
+	manifest := NewManifest()
+	manifest.Record(fcs, []models.GeneratedFile{
+		{Path: "services/api/go.mod", Checksum: fileOps.GenerateChecksum(goMod), GeneratedAt: generatedAt, Generator: generatorTemplate},
+		{Path: "services/api/internal/models/user.go", Checksum: fileOps.GenerateChecksum(contents["services/api/internal/models/user.go"]), GeneratedAt: generatedAt, Generator: generatorTemplate},
+		{Path: "services/api/internal/app/app.go", Checksum: fileOps.GenerateChecksum(contents["services/api/internal/app/app.go"]), GeneratedAt: generatedAt},
+	}, &models.Provenance{GeneratorVersion: "0.2.0", Provider: "anthropic", Model: "claude-sonnet-4-5-20250929"})
+	assert.Equal(t, FileOwnerTemplate, manifest.Files["services/api/go.mod"].Owner, "a module's go.mod is template-owned below the root")
+	assert.Equal(t, FileOwnerTemplate, manifest.Files["services/api/internal/models/user.go"].Owner, "files rendered from the data model are template-owned")
+	assert.Empty(t, manifest.Files["services/api/internal/models/user.go"].Model)
+	assert.Equal(t, FileOwnerPrompt, manifest.Files["services/api/internal/app/app.go"].Owner)
+	require.NoError(t, manifest.Save(dir))
+
+	// The module's go.mod renders with the module's data
+	results, err := Upgrade(context.Background(), dir, fileOps, UpgradeOptions{GeneratorVersion: "0.2.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []UpgradeResult{
+		{Path: "services/api/go.mod", Owner: FileOwnerTemplate, Status: UpgradeStatusCurrent},
+		{Path: "services/api/internal/models/user.go", Owner: FileOwnerTemplate, Status: UpgradeStatusSkipped, Reason: "rendered from the data model; regenerate to update"},
+	}, results)
+}
+
+func TestVerifyManifest(t *testing.T) {
+	dir := t.TempDir()
+	_, _, err := VerifyManifest(context.Background(), dir)
+	require.Error(t, err, "a directory without a manifest cannot be verified")
+
+	fileOps, err := fsops.New(fsops.Config{RootDir: dir})
+	require.NoError(t, err)
+	ctx := context.Background()
+	var files []models.GeneratedFile
+	for path, content := range map[string]string{
+		"go.mod":              "module example.com/app\n",
+		"internal/app/app.go": "package app\n",
+		"internal/app/db.go":  "package app\n\n// DB\n",
+	} {
+		require.NoError(t, fileOps.WriteFile(ctx, path, content))
+		files = append(files, models.GeneratedFile{Path: path, Checksum: fileOps.GenerateChecksum(content)})
+	}
+	manifest := NewManifest()
+	manifest.Record(nil, files, &models.Provenance{GeneratorVersion: "0.2.0"})
+	require.NoError(t, manifest.Save(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "internal", "app", "app.go"), []byte("package app // edited\n"), 0o600))
+	require.NoError(t, os.Remove(filepath.Join(dir, "internal", "app", "db.go")))
+
+	loaded, checks, err := VerifyManifest(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "0.2.0", loaded.Provenance.GeneratorVersion)
+	require.Len(t, checks, 3)
+	assert.Equal(t, ManifestCheck{Path: "go.mod", Entry: manifest.Files["go.mod"], Status: ManifestFileIntact}, checks[0])
+	assert.Equal(t, ManifestFileModified, checks[1].Status)
+	assert.Equal(t, "internal/app/app.go", checks[1].Path)
+	assert.Equal(t, ManifestFileMissing, checks[2].Status)
+}
+
+func TestDiffManifests(t *testing.T) {
+	older := NewManifest()
+	older.Files["go.mod"] = ManifestFile{Owner: FileOwnerTemplate, Checksum: "a", GeneratorVersion: "0.2.0"}
+	older.Files["internal/app/app.go"] = ManifestFile{Owner: FileOwnerPrompt, Checksum: "b", Model: "anthropic/m1", PromptHash: "p1", TaskID: "app", InputTokens: 10}
+	older.Files["internal/app/db.go"] = ManifestFile{Owner: FileOwnerPrompt, Checksum: "c"}
+
+	newer := NewManifest()
+	newer.Files["go.mod"] = ManifestFile{Owner: FileOwnerTemplate, Checksum: "a", GeneratorVersion: "0.3.0"}
+	newer.Files["internal/app/app.go"] = ManifestFile{Owner: FileOwnerPrompt, Checksum: "b", Model: "anthropic/m1", PromptHash: "p1", TaskID: "app_2", InputTokens: 12}
+	newer.Files["internal/app/store.go"] = ManifestFile{Owner: FileOwnerPrompt, Checksum: "d", Model: "anthropic/m2"}
+
+	diffs := DiffManifests(older, newer)
+	require.Len(t, diffs, 4)
+	assert.Equal(t, "go.mod", diffs[0].Path)
+	assert.Equal(t, ManifestFileChanged, diffs[0].Change)
+	assert.Equal(t, []string{"generator"}, diffs[0].Fields)
+	assert.Equal(t, ManifestFileUnchanged, diffs[1].Change, "task IDs and tokens vary between runs")
+	assert.Equal(t, ManifestFileRemoved, diffs[2].Change)
+	assert.Nil(t, diffs[2].New)
+	assert.Equal(t, "internal/app/store.go", diffs[3].Path)
+	assert.Equal(t, ManifestFileAdded, diffs[3].Change)
+	assert.Equal(t, "anthropic/m2", diffs[3].New.Model)
+
+	newer.Files["internal/app/app.go"] = ManifestFile{Owner: FileOwnerPrompt, Checksum: "b2", Model: "anthropic/m2", PromptHash: "p2"}
+	assert.Equal(t, []string{"content", "model", "prompt"}, DiffManifests(older, newer)[1].Fields)
+}
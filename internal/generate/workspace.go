@@ -0,0 +1,357 @@
+package generate
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+// maxSiblingAPIBytes caps the API summary of one sibling module in prompts
+const maxSiblingAPIBytes = 24 * 1024
+
+// SiblingModule is an existing Go module next to the generated project
+type SiblingModule struct {
+	Path string `json:"path"`          // Module path from its go.mod
+	Dir  string `json:"dir"`           // Directory relative to the output directory, slash-separated
+	API  string `json:"api,omitempty"` // Exported declarations, set only for modules the FCS depends on
+}
+
+// Workspace describes the modules surrounding the output directory. With a
+// go.work file the workspace resolves sibling modules; without one the
+// generated go.mod needs replace directives.
+type Workspace struct {
+	Root      string          `json:"root"`              // Directory holding go.work, or the output directory's parent
+	GoWork    string          `json:"go_work,omitempty"` // Path of the go.work file, empty when there is none
+	OutputDir string          `json:"output_dir"`        // Absolute output directory module dirs are relative to
+	Modules   []SiblingModule `json:"modules,omitempty"` // Sibling modules, excluding the output directory
+}
+
+// DetectWorkspace finds the modules next to outputDir. The nearest go.work
+// above outputDir lists them; failing that, every directory beside outputDir
+// with a go.mod is a sibling. It returns nil when there is neither.
+func DetectWorkspace(outputDir string) (*Workspace, error) {
+	out, err := filepath.Abs(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve output directory: %w", err)
+	}
+
+	goWork := findGoWork(filepath.Dir(out))
+
+	var ws *Workspace
+	if goWork != "" {
+		ws, err = workspaceFromGoWork(goWork, out)
+	} else {
+		ws, err = workspaceFromSiblings(filepath.Dir(out), out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ws.GoWork == "" && len(ws.Modules) == 0 {
+		return nil, nil
+	}
+
+	log.Debug().
+		Str("root", ws.Root).
+		Str("go_work", ws.GoWork).
+		Int("modules", len(ws.Modules)).
+		Msg("Detected sibling modules")
+
+	return ws, nil
+}
+
+// findGoWork returns the nearest go.work in dir or its parents
+func findGoWork(dir string) string {
+	for {
+		candidate := filepath.Join(dir, "go.work")
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// workspaceFromGoWork reads the modules a go.work file uses
+func workspaceFromGoWork(goWork, outputDir string) (*Workspace, error) {
+	data, err := os.ReadFile(goWork)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", goWork, err)
+	}
+
+	ws := &Workspace{Root: filepath.Dir(goWork), GoWork: goWork, OutputDir: outputDir}
+	for _, use := range parseGoWorkUses(string(data)) {
+		dir := filepath.Clean(filepath.Join(ws.Root, filepath.FromSlash(use)))
+		if dir == outputDir {
+			continue
+		}
+		if module, ok := readSiblingModule(dir, outputDir); ok {
+			ws.Modules = append(ws.Modules, module)
+		}
+	}
+	return ws, nil
+}
+
+// workspaceFromSiblings treats every module directory in parent as a sibling
+func workspaceFromSiblings(parent, outputDir string) (*Workspace, error) {
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Workspace{Root: parent, OutputDir: outputDir}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", parent, err)
+	}
+
+	ws := &Workspace{Root: parent, OutputDir: outputDir}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		dir := filepath.Join(parent, entry.Name())
+		if dir == outputDir {
+			continue
+		}
+		if module, ok := readSiblingModule(dir, outputDir); ok {
+			ws.Modules = append(ws.Modules, module)
+		}
+	}
+	return ws, nil
+}
+
+// readSiblingModule reads the module path of the go.mod in dir
+func readSiblingModule(dir, outputDir string) (SiblingModule, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return SiblingModule{}, false
+	}
+	modulePath := parseModulePath(string(data))
+	if modulePath == "" {
+		return SiblingModule{}, false
+	}
+
+	rel, err := filepath.Rel(outputDir, dir)
+	if err != nil {
+		return SiblingModule{}, false
+	}
+	return SiblingModule{Path: modulePath, Dir: filepath.ToSlash(rel)}, true
+}
+
+// parseModulePath returns the path of the module directive in a go.mod file
+func parseModulePath(goMod string) string {
+	scanner := bufio.NewScanner(strings.NewReader(goMod))
+	for scanner.Scan() {
+		line := stripModComment(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			return unquoteModPath(strings.TrimSpace(rest))
+		}
+	}
+	return ""
+}
+
+// parseGoWorkUses returns the directories named by use directives, in both
+// the single-line and the block form
+func parseGoWorkUses(goWork string) []string {
+	var uses []string
+	inBlock := false
+
+	scanner := bufio.NewScanner(strings.NewReader(goWork))
+	for scanner.Scan() {
+		line := stripModComment(scanner.Text())
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			uses = append(uses, unquoteModPath(line))
+		case line == "use (":
+			inBlock = true
+		case strings.HasPrefix(line, "use ") || strings.HasPrefix(line, "use\t"):
+			uses = append(uses, unquoteModPath(strings.TrimSpace(line[len("use"):])))
+		}
+	}
+	return uses
+}
+
+func stripModComment(line string) string {
+	if i := strings.Index(line, "//"); i >= 0 {
+		line = line[:i]
+	}
+	return strings.TrimSpace(line)
+}
+
+func unquoteModPath(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
+// Dependencies returns the sibling modules the FCS declares as dependencies,
+// either as external dependencies or as package dependencies, with their
+// exported APIs loaded
+func (w *Workspace) Dependencies(fcs *models.FinalClarifiedSpecification) []SiblingModule {
+	if w == nil || fcs == nil {
+		return nil
+	}
+
+	var declared []string
+	for _, dep := range fcs.Architecture.Dependencies {
+		declared = append(declared, dep.Name)
+	}
+	for _, pkg := range fcs.Architecture.Packages {
+		declared = append(declared, pkg.Dependencies...)
+	}
+
+	var modules []SiblingModule
+	for _, module := range w.Modules {
+		for _, name := range declared {
+			if name == module.Path || strings.HasPrefix(name, module.Path+"/") {
+				module.API = moduleAPI(filepath.Join(w.OutputDir, filepath.FromSlash(module.Dir)), module.Path)
+				modules = append(modules, module)
+				break
+			}
+		}
+	}
+	return modules
+}
+
+// moduleAPI summarises the exported declarations of every importable package
+// in a module. Internal, testdata and vendor directories, hidden directories
+// and nested modules are skipped.
+func moduleAPI(dir, modulePath string) string {
+	sources := make(map[string][]goSource)
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != dir {
+				if name == "internal" || name == "testdata" || name == "vendor" ||
+					strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+					return filepath.SkipDir
+				}
+				if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return nil
+		}
+		pkgDir := filepath.Dir(p)
+		sources[pkgDir] = append(sources[pkgDir], goSource{path: p, content: string(content)})
+		return nil
+	})
+	if err != nil {
+		log.Debug().Err(err).Str("module", modulePath).Msg("Failed to read sibling module")
+	}
+
+	dirs := make([]string, 0, len(sources))
+	for pkgDir := range sources {
+		dirs = append(dirs, pkgDir)
+	}
+	sort.Strings(dirs)
+
+	var sb strings.Builder
+	for _, pkgDir := range dirs {
+		api := sourceAPI(sources[pkgDir])
+		if api == "" {
+			continue
+		}
+
+		importPath := modulePath
+		if rel, err := filepath.Rel(dir, pkgDir); err == nil && rel != "." {
+			importPath = path.Join(modulePath, filepath.ToSlash(rel))
+		}
+
+		block := fmt.Sprintf("// import %q\n%s\n", importPath, api)
+		if sb.Len()+len(block) > maxSiblingAPIBytes {
+			sb.WriteString("// ... remaining packages omitted\n")
+			break
+		}
+		sb.WriteString(block)
+	}
+	return sb.String()
+}
+
+// UseOutputDir adds the output directory to the go.work file unless a use
+// directive already names it. It does nothing without a go.work file.
+func (w *Workspace) UseOutputDir() (bool, error) {
+	if w == nil || w.GoWork == "" {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(w.GoWork)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", w.GoWork, err)
+	}
+
+	for _, use := range parseGoWorkUses(string(data)) {
+		if filepath.Clean(filepath.Join(w.Root, filepath.FromSlash(use))) == w.OutputDir {
+			return false, nil
+		}
+	}
+
+	rel, err := filepath.Rel(w.Root, w.OutputDir)
+	if err != nil {
+		return false, fmt.Errorf("failed to relate %s to the workspace: %w", w.OutputDir, err)
+	}
+	use := filepath.ToSlash(rel)
+	if !strings.HasPrefix(use, ".") {
+		use = "./" + use
+	}
+
+	content := string(data)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += fmt.Sprintf("\nuse %s\n", use)
+
+	if err := os.WriteFile(w.GoWork, []byte(content), 0600); err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", w.GoWork, err)
+	}
+	return true, nil
+}
+
+// formatSiblingModules renders the prompt section describing existing modules
+// generated code may import. It returns "" when there are none.
+func formatSiblingModules(modules []SiblingModule) string {
+	if len(modules) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Existing Sibling Modules\n\n")
+	sb.WriteString("The project depends on these modules from the surrounding workspace. ")
+	sb.WriteString("Import them by module path and use only the declarations listed; do not reimplement them.\n\n")
+
+	for _, module := range modules {
+		sb.WriteString(fmt.Sprintf("## %s (%s)\n\n", module.Path, module.Dir))
+		if module.API == "" {
+			sb.WriteString("No exported API found.\n\n")
+			continue
+		}
+		sb.WriteString("```go\n")
+		sb.WriteString(module.API)
+		sb.WriteString("```\n\n")
+	}
+	return sb.String()
+}
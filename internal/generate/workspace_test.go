@@ -0,0 +1,114 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeWorkspaceFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestDetectWorkspace_Siblings(t *testing.T) {
+	root := t.TempDir()
+	writeWorkspaceFile(t, filepath.Join(root, "billing", "go.mod"), "module github.com/acme/billing\n\ngo 1.22\n")
+	writeWorkspaceFile(t, filepath.Join(root, "notes", "README.md"), "not a module\n")
+
+	ws, err := DetectWorkspace(filepath.Join(root, "service"))
+	require.NoError(t, err)
+	require.NotNil(t, ws)
+
+	assert.Empty(t, ws.GoWork)
+	assert.Equal(t, []SiblingModule{{Path: "github.com/acme/billing", Dir: "../billing"}}, ws.Modules)
+}
+
+func TestDetectWorkspace_None(t *testing.T) {
+	ws, err := DetectWorkspace(filepath.Join(t.TempDir(), "service"))
+	require.NoError(t, err)
+	assert.Nil(t, ws)
+}
+
+func TestDetectWorkspace_GoWork(t *testing.T) {
+	root := t.TempDir()
+	writeWorkspaceFile(t, filepath.Join(root, "go.work"), "go 1.22\n\nuse (\n\t./libs/auth // shared auth\n\t\"./libs/billing\"\n)\n\nuse ./apps/service\n")
+	writeWorkspaceFile(t, filepath.Join(root, "libs", "auth", "go.mod"), "module github.com/acme/auth\n")
+	writeWorkspaceFile(t, filepath.Join(root, "libs", "billing", "go.mod"), "// Billing\nmodule \"github.com/acme/billing\"\n")
+	writeWorkspaceFile(t, filepath.Join(root, "libs", "unused", "go.mod"), "module github.com/acme/unused\n")
+
+	ws, err := DetectWorkspace(filepath.Join(root, "apps", "service"))
+	require.NoError(t, err)
+	require.NotNil(t, ws)
+
+	assert.Equal(t, filepath.Join(root, "go.work"), ws.GoWork)
+	assert.Equal(t, []SiblingModule{
+		{Path: "github.com/acme/auth", Dir: "../../libs/auth"},
+		{Path: "github.com/acme/billing", Dir: "../../libs/billing"},
+	}, ws.Modules)
+}
+
+func TestWorkspace_Dependencies(t *testing.T) {
+	root := t.TempDir()
+	writeWorkspaceFile(t, filepath.Join(root, "billing", "go.mod"), "module github.com/acme/billing\n")
+	writeWorkspaceFile(t, filepath.Join(root, "billing", "invoice", "invoice.go"),
+		"package invoice\n\n// Total sums an invoice\nfunc Total(lines []int) int {\n\treturn 0\n}\n\nfunc round() {}\n")
+	writeWorkspaceFile(t, filepath.Join(root, "billing", "internal", "db", "db.go"), "package db\n\nfunc Open() {}\n")
+	writeWorkspaceFile(t, filepath.Join(root, "auth", "go.mod"), "module github.com/acme/auth\n")
+
+	ws, err := DetectWorkspace(filepath.Join(root, "service"))
+	require.NoError(t, err)
+	require.NotNil(t, ws)
+
+	fcs := &models.FinalClarifiedSpecification{
+		Architecture: models.Architecture{
+			Dependencies: []models.Dependency{{Name: "github.com/acme/billing/invoice"}},
+		},
+	}
+
+	deps := ws.Dependencies(fcs)
+	require.Len(t, deps, 1)
+	assert.Equal(t, "github.com/acme/billing", deps[0].Path)
+	assert.Contains(t, deps[0].API, `// import "github.com/acme/billing/invoice"`)
+	assert.Contains(t, deps[0].API, "func Total(lines []int) int")
+	assert.NotContains(t, deps[0].API, "round")
+	assert.NotContains(t, deps[0].API, "Open", "internal packages cannot be imported")
+
+	prompt := formatSiblingModules(deps)
+	assert.Contains(t, prompt, "# Existing Sibling Modules")
+	assert.Contains(t, prompt, "github.com/acme/billing (../billing)")
+	assert.Empty(t, formatSiblingModules(nil))
+}
+
+func TestWorkspace_UseOutputDir(t *testing.T) {
+	root := t.TempDir()
+	goWork := filepath.Join(root, "go.work")
+	writeWorkspaceFile(t, goWork, "go 1.22\n\nuse ./libs/auth")
+	writeWorkspaceFile(t, filepath.Join(root, "libs", "auth", "go.mod"), "module github.com/acme/auth\n")
+
+	ws, err := DetectWorkspace(filepath.Join(root, "apps", "service"))
+	require.NoError(t, err)
+
+	added, err := ws.UseOutputDir()
+	require.NoError(t, err)
+	assert.True(t, added)
+
+	data, err := os.ReadFile(goWork)
+	require.NoError(t, err)
+	assert.Equal(t, "go 1.22\n\nuse ./libs/auth\n\nuse ./apps/service\n", string(data))
+
+	// Already listed
+	added, err = ws.UseOutputDir()
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	// No go.work to update
+	added, err = (*Workspace)(nil).UseOutputDir()
+	require.NoError(t, err)
+	assert.False(t, added)
+}
@@ -0,0 +1,58 @@
+// Package logctx carries correlation IDs in context-scoped zerolog loggers, so
+// every event logged for a generation run or one of its tasks can be filtered
+// by run_id and task_id even when parallel work interleaves in the log.
+package logctx
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Correlation fields added to the events of a context's logger
+const (
+	RunIDField  = "run_id"
+	TaskIDField = "task_id"
+	NodeField   = "node"
+)
+
+// WithRunID returns ctx with a logger that adds runID to every event
+func WithRunID(ctx context.Context, runID string) context.Context {
+	return With(ctx, RunIDField, runID)
+}
+
+// WithTaskID returns ctx with a logger that adds taskID to every event
+func WithTaskID(ctx context.Context, taskID string) context.Context {
+	return With(ctx, TaskIDField, taskID)
+}
+
+// fieldKey stores the value of a correlation field in a context
+type fieldKey string
+
+// With returns ctx with a logger that adds key=value to every event, on top
+// of the fields of the logger ctx already carries. An empty value, or one ctx
+// already carries, leaves ctx unchanged.
+func With(ctx context.Context, key, value string) context.Context {
+	if value == "" || Field(ctx, key) == value {
+		return ctx
+	}
+	logger := Logger(ctx).With().Str(key, value).Logger()
+	return context.WithValue(logger.WithContext(ctx), fieldKey(key), value)
+}
+
+// Field returns the value With gave key in ctx, or ""
+func Field(ctx context.Context, key string) string {
+	value, _ := ctx.Value(fieldKey(key)).(string)
+	return value
+}
+
+// Logger returns the logger of ctx, or the global logger when ctx has none
+func Logger(ctx context.Context) *zerolog.Logger {
+	if ctx != nil {
+		if logger := zerolog.Ctx(ctx); logger != nil && logger.GetLevel() != zerolog.Disabled {
+			return logger
+		}
+	}
+	return &log.Logger
+}
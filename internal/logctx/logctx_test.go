@@ -0,0 +1,68 @@
+package logctx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureGlobal replaces the global logger with one writing to a buffer
+func captureGlobal(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = previous })
+	return &buf
+}
+
+func TestWith_AddsCorrelationFields(t *testing.T) {
+	buf := captureGlobal(t)
+
+	ctx := WithRunID(context.Background(), "gen-1")
+	ctx = With(ctx, NodeField, "generate_packages")
+	taskCtx := WithTaskID(ctx, "task_7")
+
+	Logger(taskCtx).Info().Msg("generating")
+	Logger(ctx).Info().Msg("node done")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var task, node map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &task))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &node))
+
+	assert.Equal(t, "gen-1", task[RunIDField])
+	assert.Equal(t, "generate_packages", task[NodeField])
+	assert.Equal(t, "task_7", task[TaskIDField])
+	assert.Equal(t, "gen-1", node[RunIDField])
+	assert.NotContains(t, node, TaskIDField)
+}
+
+func TestWith_SameValueOnce(t *testing.T) {
+	buf := captureGlobal(t)
+
+	ctx := WithRunID(context.Background(), "gen-1")
+	assert.Equal(t, ctx, WithRunID(ctx, "gen-1"))
+	assert.Equal(t, ctx, WithTaskID(ctx, ""))
+
+	Logger(WithRunID(ctx, "gen-1")).Info().Msg("once")
+	assert.Equal(t, 1, strings.Count(buf.String(), `"run_id"`))
+	assert.Equal(t, "gen-1", Field(ctx, RunIDField))
+}
+
+func TestLogger_FallsBackToGlobal(t *testing.T) {
+	buf := captureGlobal(t)
+
+	Logger(context.Background()).Info().Msg("plain")
+	assert.Contains(t, buf.String(), `"message":"plain"`)
+	assert.NotContains(t, buf.String(), RunIDField)
+}
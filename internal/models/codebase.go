@@ -0,0 +1,138 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Codebase describes an existing Go module that generation adds to instead
+// of creating a project from scratch
+type Codebase struct {
+	ModulePath string            `json:"module_path"`          // Module path from the root go.mod
+	GoVersion  string            `json:"go_version,omitempty"` // Go version from the root go.mod
+	Packages   []CodebasePackage `json:"packages,omitempty"`   // Go packages, sorted by directory
+	Files      []string          `json:"files"`                // Every file, slash-separated and sorted
+}
+
+// CodebasePackage is a Go package of an existing codebase
+type CodebasePackage struct {
+	Dir     string         `json:"dir"`               // Directory relative to the module root, slash-separated
+	Name    string         `json:"name"`              // Package name from its package clauses
+	Files   []string       `json:"files"`             // Non-test Go files, relative to the module root
+	Imports []string       `json:"imports,omitempty"` // Directories of the module's packages it imports
+	Types   []CodebaseType `json:"types,omitempty"`   // Exported struct types
+}
+
+// CodebaseType is an exported struct type of an existing package
+type CodebaseType struct {
+	Name   string            `json:"name"`
+	Fields map[string]string `json:"fields,omitempty"` // Exported field names to their Go types
+}
+
+// Has reports whether the codebase holds the file at p, relative to its root
+func (c *Codebase) Has(p string) bool {
+	p = path.Clean(filepath.ToSlash(p))
+	i := sort.SearchStrings(c.Files, p)
+	return i < len(c.Files) && c.Files[i] == p
+}
+
+// Package returns the package in the directory dir, relative to the root
+func (c *Codebase) Package(dir string) (CodebasePackage, bool) {
+	dir = path.Clean(filepath.ToSlash(dir))
+	for _, pkg := range c.Packages {
+		if pkg.Dir == dir {
+			return pkg, true
+		}
+	}
+	return CodebasePackage{}, false
+}
+
+// FitsLayout reports whether a new Go file in dir follows the existing
+// package structure: it joins an existing package, or starts a package
+// beside one
+func (c *Codebase) FitsLayout(dir string) bool {
+	dir = path.Clean(filepath.ToSlash(dir))
+	for _, pkg := range c.Packages {
+		if pkg.Dir == dir || (dir != "." && pkg.Dir != "." && path.Dir(pkg.Dir) == path.Dir(dir)) {
+			return true
+		}
+	}
+	// Top-level directories sit beside a package at the module root
+	_, rootPackage := c.Package(".")
+	return rootPackage && path.Dir(dir) == "."
+}
+
+// PseudoFCS describes the codebase as a specification: its packages with the
+// module's packages they import, and its exported struct types as entities
+func (c *Codebase) PseudoFCS() *FinalClarifiedSpecification {
+	fcs := &FinalClarifiedSpecification{
+		BuildConfig: BuildConfig{GoVersion: c.GoVersion},
+	}
+	for _, pkg := range c.Packages {
+		fcs.Architecture.Packages = append(fcs.Architecture.Packages, Package{
+			Name:         pkg.Name,
+			Path:         pkg.Dir,
+			Dependencies: pkg.Imports,
+		})
+		for _, typ := range pkg.Types {
+			fcs.DataModel.Entities = append(fcs.DataModel.Entities, Entity{
+				Name:       typ.Name,
+				Package:    pkg.Name,
+				Attributes: typ.Fields,
+			})
+		}
+	}
+	return fcs
+}
+
+// CheckCodebase reports plans that do not fit an existing codebase as a
+// *PlanLimitError: files that exist must be modified by an apply_patch task
+// rather than created, apply_patch tasks need a file to modify, and new Go
+// source files belong in an existing package or a package beside one
+func (p *GenerationPlan) CheckCodebase(codebase *Codebase) error {
+	if codebase == nil {
+		return nil
+	}
+
+	var violations []string
+	// Existing files patched, or already reported for a generate_file task
+	handled := make(map[string]bool)
+	for _, phase := range p.Phases {
+		for _, task := range phase.Tasks {
+			if task.TargetPath == "" {
+				continue
+			}
+			target := path.Clean(filepath.ToSlash(task.TargetPath))
+			switch {
+			case task.Type == "generate_file" && codebase.Has(target):
+				violations = append(violations, fmt.Sprintf("file %s already exists; modify it with an apply_patch task instead of generate_file", target))
+				handled[target] = true
+			case task.Type == "apply_patch" && !codebase.Has(target):
+				violations = append(violations, fmt.Sprintf("apply_patch task %s targets %s, which does not exist; create it with generate_file", task.ID, target))
+			case task.Type == "apply_patch":
+				handled[target] = true
+			}
+		}
+	}
+
+	for _, file := range p.FileTree.Files {
+		target := path.Clean(filepath.ToSlash(file.Path))
+		if codebase.Has(target) {
+			if !handled[target] {
+				violations = append(violations, fmt.Sprintf("file %s already exists; list it only with an apply_patch task that modifies it", target))
+			}
+			continue
+		}
+		if strings.HasSuffix(target, ".go") && !strings.HasSuffix(target, "_test.go") && !codebase.FitsLayout(path.Dir(target)) {
+			violations = append(violations, fmt.Sprintf("new file %s is outside the existing package structure; place it in an existing package or a package beside one", target))
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PlanLimitError{Violations: violations}
+	}
+	return nil
+}
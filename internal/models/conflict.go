@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SpecConflict is a value the specifications merged into one disagree on.
+// Path locates it in the spec, e.g. requirements[FR-001].description or
+// data_model.entities[User].attributes.email; list items are selected by
+// their id, name or method and endpoint.
+type SpecConflict struct {
+	ID      string           `json:"id"`
+	Path    string           `json:"path"`
+	Sources []ConflictSource `json:"sources"`
+
+	// Resolution is the value the FCS uses. Empty leaves the conflict
+	// unresolved, and the FCS cannot be generated from.
+	Resolution string `json:"resolution,omitempty"`
+}
+
+// ConflictSource is the value one of the merged specifications gives a
+// conflicting path: strings as written, other values as JSON
+type ConflictSource struct {
+	Spec  string `json:"spec"`
+	Value string `json:"value"`
+}
+
+// Resolved reports whether the conflict was given a resolution
+func (c SpecConflict) Resolved() bool {
+	return strings.TrimSpace(c.Resolution) != ""
+}
+
+// Specs lists the specifications giving value, in merge order
+func (c SpecConflict) Specs(value string) []string {
+	var specs []string
+	for _, source := range c.Sources {
+		if source.Value == value {
+			specs = append(specs, source.Spec)
+		}
+	}
+	return specs
+}
+
+// Values lists the distinct values of the conflict, in merge order
+func (c SpecConflict) Values() []string {
+	var values []string
+	for _, source := range c.Sources {
+		if !slices.Contains(values, source.Value) {
+			values = append(values, source.Value)
+		}
+	}
+	return values
+}
+
+// UnresolvedConflicts returns the conflicts between the merged
+// specifications that have no resolution yet
+func (f *FinalClarifiedSpecification) UnresolvedConflicts() []SpecConflict {
+	var unresolved []SpecConflict
+	for _, conflict := range f.Metadata.Conflicts {
+		if !conflict.Resolved() {
+			unresolved = append(unresolved, conflict)
+		}
+	}
+	return unresolved
+}
+
+// validateConflicts reports the conflicts left unresolved
+func (f *FinalClarifiedSpecification) validateConflicts() error {
+	unresolved := f.UnresolvedConflicts()
+	if len(unresolved) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(unresolved))
+	for _, conflict := range unresolved {
+		ids = append(ids, fmt.Sprintf("%s (%s)", conflict.ID, conflict.Path))
+	}
+	return fmt.Errorf("%d unresolved conflicts between the merged specifications: %s", len(unresolved), strings.Join(ids, ", "))
+}
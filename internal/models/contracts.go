@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Declaration kinds an output contract can require of an exported identifier
+const (
+	ContractKindInterface = "interface"
+	ContractKindStruct    = "struct"
+	ContractKindType      = "type" // Any type declaration
+	ContractKindFunc      = "func"
+	ContractKindConst     = "const"
+	ContractKindVar       = "var"
+)
+
+// ContractKinds lists the supported declaration kinds
+var ContractKinds = []string{ContractKindInterface, ContractKindStruct, ContractKindType, ContractKindFunc, ContractKindConst, ContractKindVar}
+
+// RouteMethods lists the HTTP methods a route contract can name
+var RouteMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// OutputContract is a postcondition on the generated code that validation
+// checks mechanically. Exactly one of File, Exports (with Package) or Route is
+// set.
+type OutputContract struct {
+	File        string `json:"file,omitempty"`        // File that must exist, relative to the project root
+	Package     string `json:"package,omitempty"`     // Package directory that must declare Exports
+	Exports     string `json:"exports,omitempty"`     // Exported identifier the package declares
+	Kind        string `json:"kind,omitempty"`        // Declaration kind of Exports (default: any)
+	Route       string `json:"route,omitempty"`       // "METHOD /path" that must be routed
+	Description string `json:"description,omitempty"` // Why the contract exists
+}
+
+// String describes the contract, e.g. "GET /users is routed"
+func (c OutputContract) String() string {
+	switch {
+	case c.File != "":
+		return fmt.Sprintf("file %s exists", c.File)
+	case c.Exports != "" && c.Kind != "":
+		return fmt.Sprintf("%s exports %s %s", c.Package, c.Kind, c.Exports)
+	case c.Exports != "":
+		return fmt.Sprintf("%s exports %s", c.Package, c.Exports)
+	default:
+		return fmt.Sprintf("%s is routed", c.Route)
+	}
+}
+
+// RouteParts splits Route into its upper-cased method and path
+func (c OutputContract) RouteParts() (string, string) {
+	method, routePath, _ := strings.Cut(strings.TrimSpace(c.Route), " ")
+	return strings.ToUpper(method), strings.TrimSpace(routePath)
+}
+
+// Validate reports a contract that is not exactly one well-formed assertion
+func (c OutputContract) Validate() error {
+	forms := 0
+	for _, set := range []bool{c.File != "", c.Package != "" || c.Exports != "", c.Route != ""} {
+		if set {
+			forms++
+		}
+	}
+	if forms != 1 {
+		return fmt.Errorf("contract must set exactly one of file, package with exports, or route")
+	}
+
+	switch {
+	case c.File != "":
+		return validateContractPath("file", c.File)
+	case c.Route != "":
+		method, routePath := c.RouteParts()
+		if !containsString(RouteMethods, method) {
+			return fmt.Errorf("route %q must start with one of %s", c.Route, strings.Join(RouteMethods, ", "))
+		}
+		if !strings.HasPrefix(routePath, "/") {
+			return fmt.Errorf("route %q must have a path starting with /", c.Route)
+		}
+		return nil
+	}
+
+	if c.Package == "" || c.Exports == "" {
+		return fmt.Errorf("contract must set both package and exports")
+	}
+	if err := validateContractPath("package", c.Package); err != nil {
+		return err
+	}
+	if !token.IsIdentifier(c.Exports) || !token.IsExported(c.Exports) {
+		return fmt.Errorf("exports %q must be an exported Go identifier", c.Exports)
+	}
+	if c.Kind != "" && !containsString(ContractKinds, c.Kind) {
+		return fmt.Errorf("unsupported kind %q (supported: %s)", c.Kind, strings.Join(ContractKinds, ", "))
+	}
+	return nil
+}
+
+// ValidateContracts validates each contract, naming the first invalid one
+func ValidateContracts(contracts []OutputContract) error {
+	for i, contract := range contracts {
+		if err := contract.Validate(); err != nil {
+			return fmt.Errorf("contracts[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateContractPath rejects paths outside the project
+func validateContractPath(field, p string) error {
+	slashed := filepath.ToSlash(p)
+	if path.IsAbs(slashed) || filepath.IsAbs(p) {
+		return fmt.Errorf("%s %q must be relative to the project", field, p)
+	}
+	for _, elem := range strings.Split(slashed, "/") {
+		if elem == ".." {
+			return fmt.Errorf("%s %q must not leave the project", field, p)
+		}
+	}
+	return nil
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Cross-cutting concerns applied to every HTTP handler through middleware
+const (
+	ConcernLogging   = "logging"
+	ConcernTracing   = "tracing"
+	ConcernCORS      = "cors"
+	ConcernRateLimit = "rate_limit"
+	ConcernAuth      = "auth"
+)
+
+// Concerns lists the supported concerns in the order their middleware wraps a
+// handler, outermost first
+var Concerns = []string{ConcernLogging, ConcernTracing, ConcernCORS, ConcernRateLimit, ConcernAuth}
+
+// concernFuncs names the middleware function generated for each concern
+var concernFuncs = map[string]string{
+	ConcernLogging:   "Logging",
+	ConcernTracing:   "Tracing",
+	ConcernCORS:      "CORS",
+	ConcernRateLimit: "RateLimit",
+	ConcernAuth:      "Auth",
+}
+
+// DefaultMiddlewarePackage is where middleware is generated when unspecified
+const DefaultMiddlewarePackage = "internal/middleware"
+
+// Concern is a cross-cutting behaviour every handler gets from middleware
+// instead of each requirement restating it
+type Concern struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Settings    map[string]string `json:"settings,omitempty"` // e.g. allowed_origins, requests_per_second
+	Exclude     []string          `json:"exclude,omitempty"`  // Endpoint paths the middleware skips, e.g. /health
+}
+
+// Func returns the name of the middleware function for the concern
+func (c Concern) Func() string {
+	return concernFuncs[c.Name]
+}
+
+// CrossCutting describes the concerns applied to every HTTP handler
+type CrossCutting struct {
+	Package  string    `json:"package,omitempty"`
+	Concerns []Concern `json:"concerns,omitempty"`
+}
+
+// Enabled reports whether any concern is configured
+func (c CrossCutting) Enabled() bool {
+	return len(c.Concerns) > 0
+}
+
+// MiddlewarePackage returns the package path middleware is generated in
+func (c CrossCutting) MiddlewarePackage() string {
+	if c.Package == "" {
+		return DefaultMiddlewarePackage
+	}
+	return path.Clean(filepath.ToSlash(c.Package))
+}
+
+// Ordered returns the concerns in the order their middleware wraps a handler,
+// outermost first
+func (c CrossCutting) Ordered() []Concern {
+	ordered := make([]Concern, 0, len(c.Concerns))
+	for _, name := range Concerns {
+		for _, concern := range c.Concerns {
+			if concern.Name == name {
+				ordered = append(ordered, concern)
+			}
+		}
+	}
+	return ordered
+}
+
+// Validate reports unknown or repeated concerns and a package outside the project
+func (c CrossCutting) Validate() error {
+	pkg := filepath.ToSlash(c.Package)
+	if path.IsAbs(pkg) || filepath.IsAbs(c.Package) {
+		return fmt.Errorf("middleware package %q must be relative to the project", c.Package)
+	}
+	for _, elem := range strings.Split(pkg, "/") {
+		if elem == ".." {
+			return fmt.Errorf("middleware package %q must not leave the project", c.Package)
+		}
+	}
+
+	seen := make(map[string]bool, len(c.Concerns))
+	for _, concern := range c.Concerns {
+		if !IsConcern(concern.Name) {
+			return fmt.Errorf("unsupported concern %q (supported: %s)", concern.Name, strings.Join(Concerns, ", "))
+		}
+		if seen[concern.Name] {
+			return fmt.Errorf("concern %s is listed more than once", concern.Name)
+		}
+		seen[concern.Name] = true
+	}
+	return nil
+}
+
+// IsConcern reports whether name is a supported cross-cutting concern
+func IsConcern(name string) bool {
+	_, ok := concernFuncs[name]
+	return ok
+}
+
+// CheckMiddlewarePackage reports a plan without a file in the middleware
+// package when cross-cutting concerns are configured, as a *PlanLimitError
+func (p *GenerationPlan) CheckMiddlewarePackage(cc CrossCutting) error {
+	if !cc.Enabled() {
+		return nil
+	}
+
+	pkg := cc.MiddlewarePackage()
+	for _, file := range p.FileTree.Files {
+		if path.Dir(path.Clean(filepath.ToSlash(file.Path))) == pkg && strings.HasSuffix(file.Path, ".go") {
+			return nil
+		}
+	}
+	return &PlanLimitError{Violations: []string{
+		fmt.Sprintf("cross-cutting concerns need middleware in package %s, but the plan has no Go file there", pkg),
+	}}
+}
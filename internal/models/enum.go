@@ -0,0 +1,166 @@
+package models
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// DefaultEnumBackingType is the Go type enums are backed by when unspecified
+const DefaultEnumBackingType = "string"
+
+// EnumBackingTypes lists the Go types an enum may be backed by
+var EnumBackingTypes = []string{"string", "int", "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64"}
+
+// Enum is a named set of values the coder turns into typed constants with
+// String and Parse helpers
+type Enum struct {
+	Name        string   `json:"name"`
+	Package     string   `json:"package,omitempty"`
+	BackingType string   `json:"backing_type,omitempty"`
+	Values      []string `json:"values"`
+	Description string   `json:"description,omitempty"`
+}
+
+// Backing returns the Go type the enum is backed by, defaulting to string
+func (e Enum) Backing() string {
+	if e.BackingType == "" {
+		return DefaultEnumBackingType
+	}
+	return e.BackingType
+}
+
+// ConstName returns the Go constant for value, e.g. OrderStatus and
+// "in_progress" give OrderStatusInProgress
+func (e Enum) ConstName(value string) string {
+	var sb strings.Builder
+	sb.WriteString(e.Name)
+	words := strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if strings.ToUpper(word) == word {
+			word = strings.ToLower(word)
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	return sb.String()
+}
+
+// ParseFunc returns the name of the function parsing the enum from a string
+func (e Enum) ParseFunc() string {
+	return "Parse" + e.Name
+}
+
+// Validate reports the first problem with the enum definition
+func (e Enum) Validate() error {
+	if !token.IsIdentifier(e.Name) || !token.IsExported(e.Name) {
+		return fmt.Errorf("enum name %q must be an exported Go identifier", e.Name)
+	}
+	if !IsEnumBackingType(e.Backing()) {
+		return fmt.Errorf("enum %s has unsupported backing_type %q (supported: %s)", e.Name, e.BackingType, strings.Join(EnumBackingTypes, ", "))
+	}
+	if len(e.Values) == 0 {
+		return fmt.Errorf("enum %s must have at least one value", e.Name)
+	}
+
+	consts := make(map[string]string, len(e.Values))
+	for _, value := range e.Values {
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("enum %s has an empty value", e.Name)
+		}
+		name := e.ConstName(value)
+		if name == e.Name {
+			return fmt.Errorf("enum %s value %q has no letters or digits", e.Name, value)
+		}
+		if previous, ok := consts[name]; ok {
+			return fmt.Errorf("enum %s values %q and %q both become %s", e.Name, previous, value, name)
+		}
+		consts[name] = value
+	}
+	return nil
+}
+
+// IsEnumBackingType reports whether name is a supported enum backing type
+func IsEnumBackingType(name string) bool {
+	for _, t := range EnumBackingTypes {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FindEnum returns the enum with the given name
+func (d DataModel) FindEnum(name string) (Enum, bool) {
+	for _, e := range d.Enums {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Enum{}, false
+}
+
+// EnumAttributes maps the attributes of entity that are typed with an enum,
+// directly or as a pointer, slice or map element, to the enum's name
+func (d DataModel) EnumAttributes(entity Entity) map[string]string {
+	attrs := make(map[string]string)
+	for attr, typ := range entity.Attributes {
+		if e, ok := d.FindEnum(baseTypeName(typ)); ok {
+			attrs[attr] = e.Name
+		}
+	}
+	return attrs
+}
+
+// ValidateEnums reports duplicate enums, enums named like entities and the
+// first malformed enum
+func (d DataModel) ValidateEnums() error {
+	entities := make(map[string]bool, len(d.Entities))
+	for _, entity := range d.Entities {
+		entities[entity.Name] = true
+	}
+
+	seen := make(map[string]bool, len(d.Enums))
+	for _, e := range d.Enums {
+		if err := e.Validate(); err != nil {
+			return err
+		}
+		if seen[e.Name] {
+			return fmt.Errorf("enum %s is defined more than once", e.Name)
+		}
+		if entities[e.Name] {
+			return fmt.Errorf("enum %s has the same name as an entity", e.Name)
+		}
+		seen[e.Name] = true
+	}
+	return nil
+}
+
+// baseTypeName strips pointer, slice, map and package qualifiers from a type,
+// so "[]*domain.Status" gives "Status"
+func baseTypeName(typ string) string {
+	typ = strings.TrimSpace(typ)
+	for {
+		switch {
+		case strings.HasPrefix(typ, "*"):
+			typ = typ[1:]
+		case strings.HasPrefix(typ, "[]"):
+			typ = typ[2:]
+		case strings.HasPrefix(typ, "map["):
+			end := strings.Index(typ, "]")
+			if end < 0 {
+				return typ
+			}
+			typ = typ[end+1:]
+		default:
+			if dot := strings.LastIndex(typ, "."); dot >= 0 {
+				typ = typ[dot+1:]
+			}
+			return strings.TrimSpace(typ)
+		}
+	}
+}
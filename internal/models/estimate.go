@@ -0,0 +1,133 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// PriorityShared is the priority class for files not attributed to any requirement
+const PriorityShared = "shared"
+
+// PriorityUnspecified is the priority class for requirements without a priority
+const PriorityUnspecified = "unspecified"
+
+// CostEstimate is a projected token, cost and time budget
+type CostEstimate struct {
+	Calls        int           `json:"calls"`
+	InputTokens  int64         `json:"input_tokens"`
+	OutputTokens int64         `json:"output_tokens"`
+	CostUSD      float64       `json:"cost_usd"`
+	Duration     time.Duration `json:"duration"`
+}
+
+// Add accumulates another estimate into e
+func (e *CostEstimate) Add(other CostEstimate) {
+	e.Calls += other.Calls
+	e.InputTokens += other.InputTokens
+	e.OutputTokens += other.OutputTokens
+	e.CostUSD += other.CostUSD
+	e.Duration += other.Duration
+}
+
+// Scale returns the estimate with tokens, cost and duration multiplied by factor.
+// Calls are not scaled.
+func (e CostEstimate) Scale(factor float64) CostEstimate {
+	return CostEstimate{
+		Calls:        e.Calls,
+		InputTokens:  int64(float64(e.InputTokens) * factor),
+		OutputTokens: int64(float64(e.OutputTokens) * factor),
+		CostUSD:      e.CostUSD * factor,
+		Duration:     time.Duration(float64(e.Duration) * factor),
+	}
+}
+
+// EstimateItem is the projected budget of one phase attributed to one priority class
+type EstimateItem struct {
+	Phase    string       `json:"phase"`
+	Priority string       `json:"priority"`
+	Estimate CostEstimate `json:"estimate"`
+}
+
+// EstimateGroup is an aggregated estimate for one phase or priority class
+type EstimateGroup struct {
+	Name     string
+	Estimate CostEstimate
+}
+
+// PlanEstimate projects the cost and duration of executing a generation plan
+type PlanEstimate struct {
+	Provider string         `json:"provider,omitempty"`
+	Model    string         `json:"model,omitempty"`
+	Phases   []string       `json:"phases"` // Estimated phases in execution order
+	Items    []EstimateItem `json:"items"`
+}
+
+// Total returns the projected budget of the whole plan
+func (p *PlanEstimate) Total() CostEstimate {
+	var total CostEstimate
+	for _, item := range p.Items {
+		total.Add(item.Estimate)
+	}
+	return total
+}
+
+// ByPhase returns the projected budget per phase in execution order
+func (p *PlanEstimate) ByPhase() []EstimateGroup {
+	sums := make(map[string]CostEstimate)
+	for _, item := range p.Items {
+		sum := sums[item.Phase]
+		sum.Add(item.Estimate)
+		sums[item.Phase] = sum
+	}
+
+	groups := make([]EstimateGroup, 0, len(p.Phases))
+	for _, phase := range p.Phases {
+		groups = append(groups, EstimateGroup{Name: phase, Estimate: sums[phase]})
+	}
+	return groups
+}
+
+// ByPriority returns the projected budget per priority class over the given
+// phases (all phases when none are given), most expensive first
+func (p *PlanEstimate) ByPriority(phases ...string) []EstimateGroup {
+	include := make(map[string]bool, len(phases))
+	for _, phase := range phases {
+		include[phase] = true
+	}
+
+	sums := make(map[string]CostEstimate)
+	for _, item := range p.Items {
+		if len(include) > 0 && !include[item.Phase] {
+			continue
+		}
+		sum := sums[item.Priority]
+		sum.Add(item.Estimate)
+		sums[item.Priority] = sum
+	}
+
+	groups := make([]EstimateGroup, 0, len(sums))
+	for name, sum := range sums {
+		groups = append(groups, EstimateGroup{Name: name, Estimate: sum})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Estimate.CostUSD != groups[j].Estimate.CostUSD {
+			return groups[i].Estimate.CostUSD > groups[j].Estimate.CostUSD
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// Remaining returns the phases not yet completed and their projected budget
+func (p *PlanEstimate) Remaining(completed map[string]bool) ([]string, CostEstimate) {
+	var phases []string
+	var remaining CostEstimate
+	for _, group := range p.ByPhase() {
+		if completed[group.Name] {
+			continue
+		}
+		phases = append(phases, group.Name)
+		remaining.Add(group.Estimate)
+	}
+	return phases, remaining
+}
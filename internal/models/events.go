@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
 
 // EventType represents the type of progress event
 type EventType string
@@ -18,6 +23,9 @@ const (
 	// EventFileCompleted indicates a file has been generated
 	EventFileCompleted EventType = "file_completed"
 
+	// EventFileStreaming carries the progress of a file whose response is streamed
+	EventFileStreaming EventType = "file_streaming"
+
 	// EventTokensUsed indicates tokens were consumed
 	EventTokensUsed EventType = "tokens_used"
 
@@ -26,8 +34,20 @@ const (
 
 	// EventError indicates an error occurred
 	EventError EventType = "error"
+
+	// EventPackageValidated indicates a validation check finished for one package
+	EventPackageValidated EventType = "package_validated"
+
+	// EventEstimate carries the projected budget of a generation plan
+	EventEstimate EventType = "estimate"
 )
 
+// EventTypes lists the event types of a generation run
+var EventTypes = []EventType{
+	EventPhaseStarted, EventPhaseCompleted, EventFileGenerating, EventFileCompleted, EventFileStreaming,
+	EventTokensUsed, EventCostUpdate, EventError, EventPackageValidated, EventEstimate,
+}
+
 // ProgressEvent represents a progress event during generation
 type ProgressEvent struct {
 	Type      EventType              `json:"type"`
@@ -35,6 +55,64 @@ type ProgressEvent struct {
 	Data      map[string]interface{} `json:"data"`
 }
 
+// EventSink receives the progress events published on an EventBus
+type EventSink interface {
+	// HandleEvent receives an event. Events arrive one at a time in the
+	// order they were published; a sink that does slow work, such as
+	// network I/O, queues it rather than blocking the bus.
+	HandleEvent(event ProgressEvent)
+}
+
+// EventBus delivers each published progress event to every subscribed
+// sink, such as the console progress display, an event log file, a webhook
+// or a trace exporter
+type EventBus struct {
+	mu    sync.Mutex
+	sinks []EventSink
+}
+
+// NewEventBus creates a bus delivering to sinks
+func NewEventBus(sinks ...EventSink) *EventBus {
+	return &EventBus{sinks: sinks}
+}
+
+// Subscribe adds a sink, which receives the events published from then on
+func (b *EventBus) Subscribe(sink EventSink) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sinks = append(b.sinks, sink)
+}
+
+// Publish delivers event to every sink in the order they subscribed
+func (b *EventBus) Publish(event ProgressEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, sink := range b.sinks {
+		sink.HandleEvent(event)
+	}
+}
+
+// Run publishes each event received from events until it is closed
+func (b *EventBus) Run(events <-chan ProgressEvent) {
+	for event := range events {
+		b.Publish(event)
+	}
+}
+
+// Close closes the sinks that implement io.Closer, so they flush what they
+// have queued, and returns their errors joined
+func (b *EventBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var errs []error
+	for _, sink := range b.sinks {
+		if closer, ok := sink.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // PhaseStartedData contains data for phase started events
 type PhaseStartedData struct {
 	Phase       string `json:"phase"`
@@ -62,6 +140,15 @@ type FileCompletedData struct {
 	Duration time.Duration `json:"duration,omitempty"`
 }
 
+// FileStreamingData contains data for file streaming events
+type FileStreamingData struct {
+	Path     string        `json:"path"`
+	Tokens   int64         `json:"tokens"`         // Output tokens received so far, estimated from the text
+	Line     string        `json:"line,omitempty"` // Last line received
+	Done     bool          `json:"done,omitempty"` // The response is complete
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
 // TokensUsedData contains data for token usage events
 type TokensUsedData struct {
 	Provider     string  `json:"provider"`
@@ -89,6 +176,15 @@ type ErrorData struct {
 	File    string `json:"file,omitempty"`
 }
 
+// PackageValidatedData contains data for package validated events
+type PackageValidatedData struct {
+	Package  string        `json:"package"`
+	Check    string        `json:"check"` // build, vet or test
+	Success  bool          `json:"success"`
+	Duration time.Duration `json:"duration,omitempty"`
+	Excerpt  string        `json:"excerpt,omitempty"` // First lines of failure output
+}
+
 // NewPhaseStartedEvent creates a phase started event
 func NewPhaseStartedEvent(phase, description string) ProgressEvent {
 	return ProgressEvent{
@@ -140,6 +236,21 @@ func NewFileCompletedEvent(path, phase string, lines int, duration time.Duration
 	}
 }
 
+// NewFileStreamingEvent creates a file streaming event
+func NewFileStreamingEvent(path string, tokens int64, line string, done bool, duration time.Duration) ProgressEvent {
+	return ProgressEvent{
+		Type:      EventFileStreaming,
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"path":     path,
+			"tokens":   tokens,
+			"line":     line,
+			"done":     done,
+			"duration": duration,
+		},
+	}
+}
+
 // NewTokensUsedEvent creates a tokens used event
 func NewTokensUsedEvent(provider string, inputTokens, outputTokens, cachedTokens, totalInput, totalOutput, totalCached int64, cacheHitRate float64) ProgressEvent {
 	return ProgressEvent{
@@ -172,6 +283,17 @@ func NewCostUpdateEvent(provider string, incrementalCost, totalCost, estimatedTo
 	}
 }
 
+// NewEstimateEvent creates a plan estimate event
+func NewEstimateEvent(estimate *PlanEstimate) ProgressEvent {
+	return ProgressEvent{
+		Type:      EventEstimate,
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"estimate": estimate,
+		},
+	}
+}
+
 // NewErrorEvent creates an error event
 func NewErrorEvent(phase, message, file string) ProgressEvent {
 	return ProgressEvent{
@@ -184,3 +306,18 @@ func NewErrorEvent(phase, message, file string) ProgressEvent {
 		},
 	}
 }
+
+// NewPackageValidatedEvent creates a package validated event
+func NewPackageValidatedEvent(pkg, check string, success bool, duration time.Duration, excerpt string) ProgressEvent {
+	return ProgressEvent{
+		Type:      EventPackageValidated,
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"package":  pkg,
+			"check":    check,
+			"success":  success,
+			"duration": duration,
+			"excerpt":  excerpt,
+		},
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -22,6 +23,14 @@ type FCSMetadata struct {
 	OriginalSpec   string                 `json:"original_spec"`
 	Clarifications []AppliedClarification `json:"clarifications,omitempty"`
 	Hash           string                 `json:"hash"`
+
+	// Sources names the specifications merged into the FCS, in merge order;
+	// empty when it was clarified from one
+	Sources []string `json:"sources,omitempty"`
+
+	// Conflicts are the values the merged specifications disagree on. Each
+	// must be resolved before the FCS is generated from.
+	Conflicts []SpecConflict `json:"conflicts,omitempty"`
 }
 
 // FunctionalRequirement represents a functional requirement
@@ -73,6 +82,10 @@ type Architecture struct {
 	Packages     []Package       `json:"packages"`
 	Dependencies []Dependency    `json:"dependencies,omitempty"`
 	Patterns     []DesignPattern `json:"patterns,omitempty"`
+
+	// HTTPFramework is the router the HTTP API is served with, one of
+	// HTTPFrameworks. Empty selects DefaultHTTPFramework.
+	HTTPFramework string `json:"http_framework,omitempty"`
 }
 
 // Entity represents a domain entity
@@ -94,20 +107,75 @@ type Relationship struct {
 type DataModel struct {
 	Entities      []Entity       `json:"entities"`
 	Relationships []Relationship `json:"relationships,omitempty"`
+	Enums         []Enum         `json:"enums,omitempty"`
 }
 
-// ContractSchema represents a request or response schema
+// ContractSchema represents a request or response schema. In a gRPC
+// contract, Message names the protobuf message and Fields map its field names
+// to protobuf types.
 type ContractSchema struct {
-	Fields map[string]string `json:"fields"`
+	Message string            `json:"message,omitempty"`
+	Fields  map[string]string `json:"fields"`
 }
 
-// APIContract represents an API endpoint contract
+// APIContract represents an API endpoint contract: an HTTP endpoint, or an
+// RPC method of a gRPC service when Protocol is grpc
 type APIContract struct {
-	Endpoint    string         `json:"endpoint"`
-	Method      string         `json:"method"`
+	Endpoint    string         `json:"endpoint"` // HTTP path; unused for gRPC
+	Method      string         `json:"method"`   // HTTP method, or the RPC method name
 	Description string         `json:"description"`
 	Request     ContractSchema `json:"request,omitempty"`
 	Response    ContractSchema `json:"response"`
+	Protocol    string         `json:"protocol,omitempty"`  // http (default) or grpc
+	Service     string         `json:"service,omitempty"`   // gRPC service, optionally qualified, e.g. orders.v1.OrderService
+	Streaming   string         `json:"streaming,omitempty"` // gRPC streaming: client, server or bidi; empty for unary
+}
+
+// Test frameworks generated tests can be written with
+const (
+	TestFrameworkTestify = "testify"
+	TestFrameworkStdlib  = "stdlib"
+	TestFrameworkGomega  = "gomega"
+)
+
+// DefaultTestFramework is used when the testing strategy selects none
+const DefaultTestFramework = TestFrameworkTestify
+
+// TestFrameworks lists the supported test frameworks
+var TestFrameworks = []string{TestFrameworkTestify, TestFrameworkStdlib, TestFrameworkGomega}
+
+// IsTestFramework reports whether name is a supported test framework
+func IsTestFramework(name string) bool {
+	for _, framework := range TestFrameworks {
+		if name == framework {
+			return true
+		}
+	}
+	return false
+}
+
+// TestLibraries maps the import path prefixes of known test libraries to
+// the framework that owns them. Libraries owned by no supported framework
+// are never allowed.
+var TestLibraries = map[string]string{
+	"github.com/stretchr/testify":       TestFrameworkTestify,
+	"github.com/onsi/gomega":            TestFrameworkGomega,
+	"github.com/onsi/ginkgo":            "",
+	"gotest.tools":                      "",
+	"github.com/smartystreets/goconvey": "",
+	"github.com/frankban/quicktest":     "",
+	"github.com/matryer/is":             "",
+}
+
+// ForeignTestLibrary reports whether importPath is a test library that
+// framework does not allow
+func ForeignTestLibrary(importPath, framework string) bool {
+	for prefix, owner := range TestLibraries {
+		if importPath == prefix || strings.HasPrefix(importPath, prefix+"/") {
+			return owner != framework
+		}
+	}
+	return false
 }
 
 // TestingStrategy describes the testing approach
@@ -116,6 +184,40 @@ type TestingStrategy struct {
 	UnitTests        bool     `json:"unit_tests"`
 	IntegrationTests bool     `json:"integration_tests"`
 	Frameworks       []string `json:"frameworks,omitempty"`
+	TestFramework    string   `json:"test_framework,omitempty"`
+	FuzzTests        bool     `json:"fuzz_tests,omitempty"` // Fuzz functions taking []byte or string and returning an error
+}
+
+// Framework returns the selected test framework, or DefaultTestFramework
+func (t TestingStrategy) Framework() string {
+	if t.TestFramework == "" {
+		return DefaultTestFramework
+	}
+	return t.TestFramework
+}
+
+// Persistence strategies the repository layer can be generated with
+const (
+	PersistenceRawSQL = "raw-sql" // database/sql with hand-written queries
+	PersistenceSQLC   = "sqlc"    // Wrappers around code sqlc generates from queries.sql
+	PersistenceGORM   = "gorm"
+	PersistenceEnt    = "ent"
+)
+
+// DefaultPersistence is used when the build config selects no strategy
+const DefaultPersistence = PersistenceRawSQL
+
+// PersistenceStrategies lists the supported persistence strategies
+var PersistenceStrategies = []string{PersistenceRawSQL, PersistenceSQLC, PersistenceGORM, PersistenceEnt}
+
+// IsPersistenceStrategy reports whether name is a supported persistence strategy
+func IsPersistenceStrategy(name string) bool {
+	for _, strategy := range PersistenceStrategies {
+		if name == strategy {
+			return true
+		}
+	}
+	return false
 }
 
 // BuildConfig contains build configuration
@@ -123,21 +225,48 @@ type BuildConfig struct {
 	GoVersion  string   `json:"go_version"`
 	OutputPath string   `json:"output_path"`
 	BuildFlags []string `json:"build_flags,omitempty"`
+
+	// Persistence is how the repository layer talks to the database, one of
+	// PersistenceStrategies. Empty selects DefaultPersistence.
+	Persistence string `json:"persistence,omitempty"`
+
+	// Modules splits the project into several Go modules tied together by a
+	// go.work file at the project root. Empty generates a single module.
+	Modules []ModuleConfig `json:"modules,omitempty"`
+}
+
+// PersistenceStrategy returns the selected persistence strategy, or
+// DefaultPersistence
+func (b BuildConfig) PersistenceStrategy() string {
+	if b.Persistence == "" {
+		return DefaultPersistence
+	}
+	return b.Persistence
 }
 
+// FCSSchemaVersion is the schema version of the FCS documents this version
+// writes. Documents of older versions are migrated to it when read.
+const FCSSchemaVersion = "1.0"
+
 // FinalClarifiedSpecification represents the complete, clarified specification
 type FinalClarifiedSpecification struct {
-	SchemaVersion   string          `json:"schema_version"`
-	ID              string          `json:"id"`
-	Version         string          `json:"version"`
-	OriginalSpecID  string          `json:"original_spec_id"`
-	Metadata        FCSMetadata     `json:"metadata"`
-	Requirements    Requirements    `json:"requirements"`
-	Architecture    Architecture    `json:"architecture"`
-	DataModel       DataModel       `json:"data_model,omitempty"`
-	APIContracts    []APIContract   `json:"api_contracts,omitempty"`
-	TestingStrategy TestingStrategy `json:"testing_strategy,omitempty"`
-	BuildConfig     BuildConfig     `json:"build_config,omitempty"`
+	SchemaVersion   string           `json:"schema_version"`
+	ID              string           `json:"id"`
+	Version         string           `json:"version"`
+	OriginalSpecID  string           `json:"original_spec_id"`
+	Metadata        FCSMetadata      `json:"metadata"`
+	Requirements    Requirements     `json:"requirements"`
+	Architecture    Architecture     `json:"architecture"`
+	DataModel       DataModel        `json:"data_model,omitempty"`
+	APIContracts    []APIContract    `json:"api_contracts,omitempty"`
+	CrossCutting    CrossCutting     `json:"cross_cutting,omitempty"`
+	Contracts       []OutputContract `json:"contracts,omitempty"`
+	TestingStrategy TestingStrategy  `json:"testing_strategy,omitempty"`
+	BuildConfig     BuildConfig      `json:"build_config,omitempty"`
+
+	// FileTree is a user-authored layout the planner adopts verbatim, only
+	// filling in the tasks. Nil lets the planner choose the layout.
+	FileTree *FileTree `json:"file_tree,omitempty"`
 }
 
 // Validate validates the FCS
@@ -147,6 +276,20 @@ func (f *FinalClarifiedSpecification) Validate() error {
 		return fmt.Errorf("cyclic dependency detected in package dependencies")
 	}
 
+	if f.FileTree != nil {
+		if err := f.FileTree.Validate(f.Architecture.Packages); err != nil {
+			return fmt.Errorf("invalid file tree: %w", err)
+		}
+	}
+
+	if err := f.BuildConfig.Validate(f.Architecture.Dependencies); err != nil {
+		return fmt.Errorf("invalid build config: %w", err)
+	}
+
+	if err := f.validateConflicts(); err != nil {
+		return err
+	}
+
 	// Verify hash if present
 	if f.Metadata.Hash != "" {
 		computedHash, err := f.ComputeHash()
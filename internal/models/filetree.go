@@ -0,0 +1,171 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Validate checks a user-authored file tree the planner must adopt: paths are
+// unique, relative and inside the project, every package of the architecture
+// has a Go file, and Go files outside cmd/ and the project root belong to an
+// architecture package. Package checks are skipped when packages is empty.
+func (t FileTree) Validate(packages []Package) error {
+	if len(t.Files) == 0 {
+		return fmt.Errorf("file tree lists no files")
+	}
+
+	seen := make(map[string]bool, len(t.Directories)+len(t.Files))
+	for _, p := range t.paths() {
+		if err := validateTreePath(p); err != nil {
+			return err
+		}
+		clean := path.Clean(filepath.ToSlash(p))
+		if seen[clean] {
+			return fmt.Errorf("file tree lists %s more than once", clean)
+		}
+		seen[clean] = true
+	}
+
+	if len(packages) == 0 {
+		return nil
+	}
+
+	dirs := t.GoPackageDirs()
+	for _, pkg := range packages {
+		if pkg.Path == "" {
+			continue
+		}
+		found := false
+		for _, dir := range dirs {
+			if packageAt(pkg.Path, dir) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("package %s (%s) has no Go file in the file tree", pkg.Name, pkg.Path)
+		}
+	}
+
+	for _, dir := range dirs {
+		if dir == "." || dir == "cmd" || strings.HasPrefix(dir, "cmd/") {
+			continue
+		}
+		covered := false
+		for _, pkg := range packages {
+			if packageAt(pkg.Path, dir) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return fmt.Errorf("file tree directory %s is not a package of the architecture", dir)
+		}
+	}
+	return nil
+}
+
+// paths returns the directory and file paths of the tree
+func (t FileTree) paths() []string {
+	paths := make([]string, 0, len(t.Directories)+len(t.Files))
+	for _, dir := range t.Directories {
+		paths = append(paths, dir.Path)
+	}
+	for _, file := range t.Files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+// validateTreePath reports a file tree path that is empty, absolute or
+// leaves the project
+func validateTreePath(p string) error {
+	slashed := filepath.ToSlash(p)
+	if strings.TrimSpace(slashed) == "" {
+		return fmt.Errorf("file tree path is empty")
+	}
+	if path.IsAbs(slashed) || filepath.IsAbs(p) {
+		return fmt.Errorf("file tree path %q must be relative to the project", p)
+	}
+	for _, elem := range strings.Split(slashed, "/") {
+		if elem == ".." {
+			return fmt.Errorf("file tree path %q must not leave the project", p)
+		}
+	}
+	return nil
+}
+
+// GoPackageDirs returns the slash-separated directories holding non-test Go
+// files, in order of first appearance
+func (t FileTree) GoPackageDirs() []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, file := range t.Files {
+		p := path.Clean(filepath.ToSlash(file.Path))
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			continue
+		}
+		dir := path.Dir(p)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// packageAt reports whether an architecture package path, relative or
+// module-qualified, names the project directory dir
+func packageAt(pkgPath, dir string) bool {
+	p := strings.Trim(path.Clean(filepath.ToSlash(pkgPath)), "/")
+	if p == "." || p == "" {
+		return dir == "."
+	}
+	return p == dir || strings.HasSuffix(p, "/"+dir)
+}
+
+// Has reports whether the tree lists the file at p
+func (t FileTree) Has(p string) bool {
+	p = path.Clean(filepath.ToSlash(p))
+	for _, file := range t.Files {
+		if path.Clean(filepath.ToSlash(file.Path)) == p {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckFileTree reports the Go files of a required file tree that no
+// generate_file task produces, as a *PlanLimitError. Test files are written
+// by the test phase and template files by the config phase, so they need no
+// task.
+func (p *GenerationPlan) CheckFileTree(required *FileTree) error {
+	if required == nil {
+		return nil
+	}
+
+	tasks := make(map[string]bool)
+	for _, phase := range p.Phases {
+		for _, task := range phase.Tasks {
+			if task.Type == "generate_file" && task.TargetPath != "" {
+				tasks[path.Clean(filepath.ToSlash(task.TargetPath))] = true
+			}
+		}
+	}
+
+	var violations []string
+	for _, file := range required.Files {
+		target := path.Clean(filepath.ToSlash(file.Path))
+		if !strings.HasSuffix(target, ".go") || strings.HasSuffix(target, "_test.go") || file.GeneratedBy == "template" || tasks[target] {
+			continue
+		}
+		violations = append(violations, fmt.Sprintf("required file %s has no generate_file task", target))
+	}
+
+	if len(violations) > 0 {
+		return &PlanLimitError{Violations: violations}
+	}
+	return nil
+}
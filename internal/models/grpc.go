@@ -0,0 +1,201 @@
+package models
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// API contract protocols
+const (
+	ProtocolHTTP = "http"
+	ProtocolGRPC = "grpc"
+)
+
+// Protocols lists the supported API contract protocols
+var Protocols = []string{ProtocolHTTP, ProtocolGRPC}
+
+// Streaming modes of a gRPC method; a unary method has none
+const (
+	StreamingClient = "client"
+	StreamingServer = "server"
+	StreamingBidi   = "bidi"
+)
+
+// StreamingModes lists the supported gRPC streaming modes
+var StreamingModes = []string{StreamingClient, StreamingServer, StreamingBidi}
+
+// IsGRPC reports whether the contract is an RPC method of a gRPC service
+func (c APIContract) IsGRPC() bool {
+	return strings.EqualFold(c.Protocol, ProtocolGRPC)
+}
+
+// Key identifies the contract: "GET /users" for HTTP, the full method name
+// such as "orders.v1.OrderService/CreateOrder" for gRPC
+func (c APIContract) Key() string {
+	if c.IsGRPC() {
+		return c.GRPCService().FullName() + "/" + c.Method
+	}
+	return fmt.Sprintf("%s %s", c.Method, c.Endpoint)
+}
+
+// RequestMessage returns the protobuf request message, defaulting to the
+// method name followed by Request
+func (c APIContract) RequestMessage() string {
+	if c.Request.Message != "" {
+		return c.Request.Message
+	}
+	return c.Method + "Request"
+}
+
+// ResponseMessage returns the protobuf response message, defaulting to the
+// method name followed by Response
+func (c APIContract) ResponseMessage() string {
+	if c.Response.Message != "" {
+		return c.Response.Message
+	}
+	return c.Method + "Response"
+}
+
+// Signature returns the contract's rpc declaration in protobuf syntax, e.g.
+// "rpc ListOrders(ListOrdersRequest) returns (stream Order)"
+func (c APIContract) Signature() string {
+	request, response := c.RequestMessage(), c.ResponseMessage()
+	if c.Streaming == StreamingClient || c.Streaming == StreamingBidi {
+		request = "stream " + request
+	}
+	if c.Streaming == StreamingServer || c.Streaming == StreamingBidi {
+		response = "stream " + response
+	}
+	return fmt.Sprintf("rpc %s(%s) returns (%s)", c.Method, request, response)
+}
+
+// GRPCService returns the service the contract belongs to
+func (c APIContract) GRPCService() GRPCService {
+	service := GRPCService{Name: c.Service}
+	if idx := strings.LastIndex(c.Service, "."); idx >= 0 {
+		service.Package, service.Name = c.Service[:idx], c.Service[idx+1:]
+	}
+	if service.Package == "" {
+		service.Package = strings.ToLower(strings.TrimSuffix(service.Name, "Service")) + ".v1"
+	}
+	return service
+}
+
+// Validate reports the first problem with a contract. HTTP contracts are
+// free-form; a gRPC contract needs an exported service and method name.
+func (c APIContract) Validate() error {
+	protocol := strings.ToLower(c.Protocol)
+	if protocol != "" && protocol != ProtocolHTTP && protocol != ProtocolGRPC {
+		return fmt.Errorf("unsupported protocol %q (supported: %s)", c.Protocol, strings.Join(Protocols, ", "))
+	}
+	if !c.IsGRPC() {
+		return nil
+	}
+
+	service := c.GRPCService()
+	if !token.IsIdentifier(service.Name) || !token.IsExported(service.Name) {
+		return fmt.Errorf("gRPC service %q must end in an exported identifier", c.Service)
+	}
+	if !token.IsIdentifier(c.Method) || !token.IsExported(c.Method) {
+		return fmt.Errorf("gRPC method %q of %s must be an exported identifier", c.Method, service.Name)
+	}
+	switch c.Streaming {
+	case "", StreamingClient, StreamingServer, StreamingBidi:
+	default:
+		return fmt.Errorf("gRPC method %s has unsupported streaming %q (supported: %s)", c.Method, c.Streaming, strings.Join(StreamingModes, ", "))
+	}
+	return nil
+}
+
+// FieldList returns the schema's fields as "name type", sorted by name
+func (s ContractSchema) FieldList() []string {
+	names := make([]string, 0, len(s.Fields))
+	for name := range s.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]string, len(names))
+	for i, name := range names {
+		fields[i] = name + " " + s.Fields[name]
+	}
+	return fields
+}
+
+// GRPCService is a gRPC service the contracts declare methods of
+type GRPCService struct {
+	Package string        // Protobuf package, e.g. orders.v1
+	Name    string        // Service name, e.g. OrderService
+	Methods []APIContract // In the order the contracts list them
+}
+
+// FullName returns the service's fully qualified name
+func (s GRPCService) FullName() string {
+	return s.Package + "." + s.Name
+}
+
+// ProtoPath returns the .proto file declaring the service, relative to the
+// project root, e.g. proto/orders/v1/order_service.proto
+func (s GRPCService) ProtoPath() string {
+	return path.Join("proto", strings.ReplaceAll(s.Package, ".", "/"), SnakeCase(s.Name)+".proto")
+}
+
+// GoPackageDir returns the directory, relative to the project root, that
+// code generated from the .proto file is written to, e.g. gen/orders/v1
+func (s GRPCService) GoPackageDir() string {
+	return path.Join("gen", strings.ReplaceAll(s.Package, ".", "/"))
+}
+
+// GRPCServices groups the gRPC contracts of the FCS by service, sorted by
+// full name
+func (f *FinalClarifiedSpecification) GRPCServices() []GRPCService {
+	byName := make(map[string]*GRPCService)
+	for _, contract := range f.APIContracts {
+		if !contract.IsGRPC() {
+			continue
+		}
+		service := contract.GRPCService()
+		if byName[service.FullName()] == nil {
+			byName[service.FullName()] = &service
+		}
+		byName[service.FullName()].Methods = append(byName[service.FullName()].Methods, contract)
+	}
+
+	services := make([]GRPCService, 0, len(byName))
+	for _, service := range byName {
+		services = append(services, *service)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].FullName() < services[j].FullName() })
+	return services
+}
+
+// HasGRPC reports whether any API contract is a gRPC method
+func (f *FinalClarifiedSpecification) HasGRPC() bool {
+	for _, contract := range f.APIContracts {
+		if contract.IsGRPC() {
+			return true
+		}
+	}
+	return false
+}
+
+// SnakeCase turns an identifier into lower snake case, e.g. OrderService
+// into order_service
+func SnakeCase(name string) string {
+	var sb strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// HTTP frameworks the router of a generated service can be built with
+const (
+	HTTPFrameworkStdlib = "net/http"
+	HTTPFrameworkChi    = "chi"
+	HTTPFrameworkGin    = "gin"
+	HTTPFrameworkEcho   = "echo"
+)
+
+// DefaultHTTPFramework is used when the architecture selects none
+const DefaultHTTPFramework = HTTPFrameworkStdlib
+
+// HTTPFrameworks lists the supported HTTP frameworks
+var HTTPFrameworks = []string{HTTPFrameworkStdlib, HTTPFrameworkChi, HTTPFrameworkGin, HTTPFrameworkEcho}
+
+// IsHTTPFramework reports whether name is a supported HTTP framework
+func IsHTTPFramework(name string) bool {
+	for _, framework := range HTTPFrameworks {
+		if name == framework {
+			return true
+		}
+	}
+	return false
+}
+
+// Framework returns the selected HTTP framework, or DefaultHTTPFramework
+func (a Architecture) Framework() string {
+	if a.HTTPFramework == "" {
+		return DefaultHTTPFramework
+	}
+	return a.HTTPFramework
+}
+
+// HTTPRoute is an HTTP endpoint of the API contracts
+type HTTPRoute struct {
+	Method      string // Upper-case HTTP method
+	Path        string // Path with {name} parameters
+	Handler     string // Name of the handler method, e.g. GetUsersByID
+	Description string
+	Request     ContractSchema
+	Response    ContractSchema
+}
+
+// Pattern returns the path of the route as a framework writes it: with
+// :name parameters for gin and echo, {name} otherwise. net/http writes the
+// root /{$}, / matching every path.
+func (r HTTPRoute) Pattern(framework string) string {
+	if framework == HTTPFrameworkStdlib && r.Path == "/" {
+		return "/{$}"
+	}
+	if framework != HTTPFrameworkGin && framework != HTTPFrameworkEcho {
+		return r.Path
+	}
+	segments := strings.Split(r.Path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = ":" + strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// HTTPRoutes returns the routes of the HTTP API contracts in the order they
+// are listed, one per method and path. Paths get {name} parameters whether
+// the contract writes {name} or :name.
+func (f *FinalClarifiedSpecification) HTTPRoutes() []HTTPRoute {
+	var routes []HTTPRoute
+	seen := make(map[string]bool)
+	for _, contract := range f.APIContracts {
+		if contract.IsGRPC() || strings.TrimSpace(contract.Endpoint) == "" {
+			continue
+		}
+		route := HTTPRoute{
+			Method:      strings.ToUpper(strings.TrimSpace(contract.Method)),
+			Path:        routePath(contract.Endpoint),
+			Description: contract.Description,
+			Request:     contract.Request,
+			Response:    contract.Response,
+		}
+		if route.Method == "" {
+			route.Method = "GET"
+		}
+		if seen[route.Method+" "+route.Path] {
+			continue
+		}
+		seen[route.Method+" "+route.Path] = true
+		route.Handler = handlerName(route.Method, route.Path)
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// routePath returns an endpoint with a leading slash, without a trailing one
+// and with its :name parameters written {name}
+func routePath(endpoint string) string {
+	segments := strings.Split(strings.Trim(strings.TrimSpace(endpoint), "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// handlerName names the handler of a route after its method and path, so
+// GET /users/{id} gives GetUsersByID, POST /orders/{id}/items gives
+// PostOrdersByIDItems and GET / gives GetRoot
+func handlerName(method, routePath string) string {
+	var sb strings.Builder
+	sb.WriteString(exportedWord(strings.ToLower(method)))
+	for _, segment := range strings.Split(routePath, "/") {
+		param := strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+		if param {
+			segment = strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+			sb.WriteString("By")
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+			if strings.EqualFold(word, "id") {
+				sb.WriteString("ID")
+				continue
+			}
+			sb.WriteString(exportedWord(word))
+		}
+	}
+	if routePath == "/" {
+		sb.WriteString("Root")
+	}
+	return sb.String()
+}
+
+// exportedWord returns word with its first letter in upper case
+func exportedWord(word string) string {
+	runes := []rune(word)
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	return string(runes)
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ModuleConfig is one Go module of a multi-module project
+type ModuleConfig struct {
+	Dir      string   `json:"dir"`                // Directory relative to the project root, slash-separated
+	Path     string   `json:"path"`               // Module path declared in its go.mod
+	Requires []string `json:"requires,omitempty"` // Other modules of the project and architecture dependencies it imports
+}
+
+// GoModPath returns the path of the module's go.mod relative to the project root
+func (m ModuleConfig) GoModPath() string {
+	return path.Join(m.Dir, "go.mod")
+}
+
+// IsMultiModule reports whether the project is split into several modules
+// tied together by a go.work file
+func (b BuildConfig) IsMultiModule() bool {
+	return len(b.Modules) > 0
+}
+
+// Module returns the module of the project with the given module path
+func (b BuildConfig) Module(modulePath string) (ModuleConfig, bool) {
+	for _, module := range b.Modules {
+		if module.Path == modulePath {
+			return module, true
+		}
+	}
+	return ModuleConfig{}, false
+}
+
+// ModuleOf returns the module whose directory holds the file at p, relative
+// to the project root
+func (b BuildConfig) ModuleOf(p string) (ModuleConfig, bool) {
+	p = path.Clean(filepath.ToSlash(p))
+	for _, module := range b.Modules {
+		if p == module.Dir || strings.HasPrefix(p, module.Dir+"/") {
+			return module, true
+		}
+	}
+	return ModuleConfig{}, false
+}
+
+// Validate checks the modules of a multi-module project: each needs a
+// module path and its own directory below the project root, and may only
+// require other modules of the project or the given dependencies
+func (b BuildConfig) Validate(dependencies []Dependency) error {
+	dirs := make(map[string]bool, len(b.Modules))
+	paths := make(map[string]bool, len(b.Modules))
+	for _, module := range b.Modules {
+		if module.Path == "" {
+			return fmt.Errorf("module in %q has no module path", module.Dir)
+		}
+		if paths[module.Path] {
+			return fmt.Errorf("module %s is declared more than once", module.Path)
+		}
+		paths[module.Path] = true
+
+		dir := filepath.ToSlash(module.Dir)
+		if dir == "" || dir == "." || path.IsAbs(dir) || filepath.IsAbs(module.Dir) || dir != path.Clean(dir) ||
+			dir == ".." || strings.HasPrefix(dir, "../") {
+			return fmt.Errorf("module %s: dir %q must be a clean directory below the project root", module.Path, module.Dir)
+		}
+		if dirs[dir] {
+			return fmt.Errorf("module %s: dir %s holds another module", module.Path, dir)
+		}
+		dirs[dir] = true
+	}
+
+	for _, module := range b.Modules {
+		for _, other := range b.Modules {
+			if other.Dir != module.Dir && strings.HasPrefix(module.Dir, other.Dir+"/") {
+				return fmt.Errorf("module %s: dir %s is nested in module %s", module.Path, module.Dir, other.Path)
+			}
+		}
+	}
+
+	external := make(map[string]bool, len(dependencies))
+	for _, dep := range dependencies {
+		external[dep.Name] = true
+	}
+	for _, module := range b.Modules {
+		for _, required := range module.Requires {
+			switch {
+			case required == module.Path:
+				return fmt.Errorf("module %s requires itself", module.Path)
+			case !paths[required] && !external[required]:
+				return fmt.Errorf("module %s requires %s, which is neither a module of the project nor an architecture dependency", module.Path, required)
+			}
+		}
+	}
+	return nil
+}
@@ -45,21 +45,94 @@ type Patch struct {
 	Diff       string    `json:"diff"`
 	AppliedAt  time.Time `json:"applied_at,omitempty"`
 	Reversible bool      `json:"reversible"`
+
+	// BaseChecksum is the SHA-256 of the content the diff was computed against,
+	// empty when the patch creates the file
+	BaseChecksum string `json:"base_checksum,omitempty"`
+
+	// Template marks a file rendered from a template instead of written by
+	// the model
+	Template bool `json:"template,omitempty"`
 }
 
 // OutputMetadata contains metadata about the generation output
 type OutputMetadata struct {
-	StartedAt   time.Time     `json:"started_at"`
-	CompletedAt *time.Time    `json:"completed_at,omitempty"`
-	Duration    time.Duration `json:"duration,omitempty"`
-	FilesCount  int           `json:"files_count"`
-	LinesCount  int           `json:"lines_count"`
+	StartedAt    time.Time     `json:"started_at"`
+	CompletedAt  *time.Time    `json:"completed_at,omitempty"`
+	Duration     time.Duration `json:"duration,omitempty"`
+	FilesCount   int           `json:"files_count"`
+	LinesCount   int           `json:"lines_count"`
+	ImportsFixed int           `json:"imports_fixed,omitempty"` // Internal imports rewritten to the go.mod module path
+
+	// Downgrades lists the files generated with a cheaper model or as a stub
+	// because the primary model's projected cost exceeded the per-file ceiling
+	Downgrades []FileDowngrade `json:"downgrades,omitempty"`
+
+	// Conflicts lists the generated hunks rejected because the file changed
+	// in the same place on disk after the change was generated
+	Conflicts []PatchConflict `json:"conflicts,omitempty"`
+
+	// Provenance records what the run was generated with
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// Provenance records the generator, model settings and prompts a generation
+// run used, so output that differs between runs can be traced to what changed
+type Provenance struct {
+	GeneratorVersion string  `json:"generator_version"`
+	Provider         string  `json:"provider"`
+	Model            string  `json:"model"` // As configured; a dated snapshot pins the exact model version
+	Temperature      float64 `json:"temperature"`
+
+	// PhaseModels maps the phases run with a model other than Model
+	// (planner, tester) to its provider/model
+	PhaseModels map[string]string `json:"phase_models,omitempty"`
+
+	// PromptHashes maps the path of each LLM-written file to the SHA-256 of
+	// the prompt it was generated from
+	PromptHashes map[string]string `json:"prompt_hashes,omitempty"`
+
+	// Files maps the path of each LLM-written file to how it was generated
+	Files map[string]FileProvenance `json:"files,omitempty"`
 }
 
+// FileProvenance records how one LLM-written file was generated. Tokens are
+// estimated at four bytes each.
+type FileProvenance struct {
+	TaskID       string    `json:"task_id,omitempty"` // Plan task ID, or "test:<source file>" for a test file
+	InputTokens  int64     `json:"input_tokens"`      // Across every prompt sent for the file
+	OutputTokens int64     `json:"output_tokens"`     // Of the file as written
+	StartedAt    time.Time `json:"started_at"`        // When the first prompt was sent
+}
+
+// FileDowngrade records a file that was not generated with the primary
+// model to cap its cost
+type FileDowngrade struct {
+	Path         string  `json:"path"`
+	From         string  `json:"from"`          // Primary provider/model
+	To           string  `json:"to"`            // Cheaper provider/model, or "stub"
+	ProjectedUSD float64 `json:"projected_usd"` // Worst-case cost with the primary model
+	CeilingUSD   float64 `json:"ceiling_usd"`
+}
+
+// PatchConflict records a hunk of a generated change that was not applied:
+// the target changed on disk since the change was generated, and differently
+// in the same lines, so the lines on disk were kept
+type PatchConflict struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`     // First line of the kept lines in the written file
+	Kept     string `json:"kept"`     // Lines on disk, left in place
+	Rejected string `json:"rejected"` // Generated lines that were not applied
+}
+
+// DowngradeStub is the FileDowngrade.To of files written as a stub
+const DowngradeStub = "stub"
+
 // GenerationOutput represents the output of the generation process
 type GenerationOutput struct {
 	SchemaVersion string          `json:"schema_version"`
 	ID            string          `json:"id"`
+	RunID         string          `json:"run_id,omitempty"` // Workflow run, names the state log under .gocreator/runs
 	PlanID        string          `json:"plan_id"`
 	Files         []GeneratedFile `json:"files"`
 	Patches       []Patch         `json:"patches,omitempty"`
@@ -2,7 +2,9 @@ package models
 
 import (
 	"fmt"
+	"math"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
@@ -31,6 +33,12 @@ func (t *GenerationTask) Validate() error {
 	return nil
 }
 
+// WritesFile reports whether the coder writes the task's target file: a
+// generate_file task creates it, an apply_patch task modifies an existing one
+func (t GenerationTask) WritesFile() bool {
+	return t.Type == "generate_file" || t.Type == "apply_patch"
+}
+
 // GenerationPhase represents a phase in the generation plan
 type GenerationPhase struct {
 	Name         string           `json:"name"`
@@ -47,9 +55,11 @@ type Directory struct {
 
 // File represents a file in the file tree
 type File struct {
-	Path        string `json:"path"`
-	Purpose     string `json:"purpose,omitempty"`
-	GeneratedBy string `json:"generated_by,omitempty"`
+	Path           string   `json:"path"`
+	Purpose        string   `json:"purpose,omitempty"`
+	GeneratedBy    string   `json:"generated_by,omitempty"`
+	Entities       []string `json:"entities,omitempty"`        // Data model entities the file defines
+	EstimatedLines int      `json:"estimated_lines,omitempty"` // Planner's size estimate for the file
 }
 
 // FileTree represents the target directory structure
@@ -59,6 +69,10 @@ type FileTree struct {
 	Files       []File      `json:"files,omitempty"`
 }
 
+// PlanSchemaVersion is the schema version of the generation plans this
+// version writes
+const PlanSchemaVersion = "1.0"
+
 // GenerationPlan represents a detailed plan for code generation
 type GenerationPlan struct {
 	SchemaVersion string            `json:"schema_version"`
@@ -94,9 +108,124 @@ func (p *GenerationPlan) Validate() error {
 		}
 	}
 
+	if err := p.validatePhaseOrder(); err != nil {
+		return err
+	}
+
+	return p.CheckTaskIDs()
+}
+
+// validatePhaseOrder checks that phase names are unique and that phases are
+// listed in order, numbered 1, 2, 3, ... without gaps
+func (p *GenerationPlan) validatePhaseOrder() error {
+	names := make(map[string]bool, len(p.Phases))
+	for i, phase := range p.Phases {
+		if names[phase.Name] {
+			return fmt.Errorf("duplicate phase name: %q", phase.Name)
+		}
+		names[phase.Name] = true
+
+		if phase.Order != i+1 {
+			return fmt.Errorf("phase %q has order %d, expected %d: phase orders must be contiguous from 1 in listing order", phase.Name, phase.Order, i+1)
+		}
+	}
 	return nil
 }
 
+// CheckTaskIDs checks that every task has an ID and that no two tasks share one
+func (p *GenerationPlan) CheckTaskIDs() error {
+	phaseOf := make(map[string]string)
+	for _, phase := range p.Phases {
+		for i, task := range phase.Tasks {
+			if task.ID == "" {
+				return fmt.Errorf("task %d in phase %q has no ID", i+1, phase.Name)
+			}
+			if first, ok := phaseOf[task.ID]; ok {
+				return fmt.Errorf("duplicate task ID %q in phases %q and %q", task.ID, first, phase.Name)
+			}
+			phaseOf[task.ID] = phase.Name
+		}
+	}
+	return nil
+}
+
+// Normalize repairs the identifiers of a planner-produced plan so that it
+// passes Validate: phases are sorted by order, phases without a positive
+// order last, and renumbered 1, 2, 3, ...; tasks without an ID or with the
+// ID of an earlier task get a new one derived from it, and files generated by
+// a renamed task are relinked. The result depends only on the plan, so the
+// same plan always normalizes the same way. Normalize returns a description
+// of each change.
+func (p *GenerationPlan) Normalize() []string {
+	var changes []string
+
+	sortKey := func(order int) int {
+		if order <= 0 {
+			return math.MaxInt
+		}
+		return order
+	}
+	sort.SliceStable(p.Phases, func(i, j int) bool {
+		return sortKey(p.Phases[i].Order) < sortKey(p.Phases[j].Order)
+	})
+	for i := range p.Phases {
+		if p.Phases[i].Order != i+1 {
+			changes = append(changes, fmt.Sprintf("phase %q order %d renumbered to %d", p.Phases[i].Name, p.Phases[i].Order, i+1))
+			p.Phases[i].Order = i + 1
+		}
+	}
+
+	// IDs already in use, so a new ID never takes a later task's ID
+	taken := make(map[string]bool)
+	for _, phase := range p.Phases {
+		for _, task := range phase.Tasks {
+			taken[task.ID] = true
+		}
+	}
+
+	claimed := make(map[string]bool)
+	relinked := make(map[string]string) // Target path to the new ID of its task
+	for i := range p.Phases {
+		phase := &p.Phases[i]
+		for j := range phase.Tasks {
+			task := &phase.Tasks[j]
+			if task.ID != "" && !claimed[task.ID] {
+				claimed[task.ID] = true
+				continue
+			}
+
+			base := task.ID
+			if base == "" {
+				base = fmt.Sprintf("task-%d-%d", phase.Order, j+1)
+			}
+			id := base
+			for n := 2; taken[id]; n++ {
+				id = fmt.Sprintf("%s-%d", base, n)
+			}
+			taken[id] = true
+			claimed[id] = true
+
+			if task.ID == "" {
+				changes = append(changes, fmt.Sprintf("task %d in phase %q had no ID, assigned %q", j+1, phase.Name, id))
+			} else {
+				changes = append(changes, fmt.Sprintf("task %q in phase %q duplicated an earlier ID, renamed to %q", task.ID, phase.Name, id))
+			}
+			if task.TargetPath != "" {
+				relinked[filepath.Clean(task.TargetPath)] = id
+			}
+			task.ID = id
+		}
+	}
+
+	for i := range p.FileTree.Files {
+		if id, ok := relinked[filepath.Clean(p.FileTree.Files[i].Path)]; ok {
+			p.FileTree.Files[i].GeneratedBy = id
+		}
+	}
+
+	return changes
+}
+
 // isPathWithinRoot checks if a path is within the root directory
 func (p *GenerationPlan) isPathWithinRoot(targetPath string) bool {
 	// Clean the target path
@@ -190,3 +319,214 @@ func (p *GenerationPlan) GetTaskByID(taskID string) *GenerationTask {
 	}
 	return nil
 }
+
+// PlanLimits bounds the size and shape of a generation plan. Zero values disable a limit.
+type PlanLimits struct {
+	MaxFiles           int `json:"max_files,omitempty"`             // Files in the file tree
+	MaxDirectories     int `json:"max_directories,omitempty"`       // Distinct directories, including parents implied by file paths
+	MaxDepth           int `json:"max_depth,omitempty"`             // Directory nesting below the root
+	MaxFilesPerPackage int `json:"max_files_per_package,omitempty"` // Non-test .go files in one directory
+}
+
+// IsZero reports whether no limits are set
+func (l PlanLimits) IsZero() bool {
+	return l == PlanLimits{}
+}
+
+// PlanLimitError lists every limit a plan exceeds
+type PlanLimitError struct {
+	Violations []string
+}
+
+// Error implements the error interface
+func (e *PlanLimitError) Error() string {
+	return fmt.Sprintf("plan exceeds limits: %s", strings.Join(e.Violations, "; "))
+}
+
+// CheckLimits reports the limits the plan's file tree exceeds as a *PlanLimitError
+func (p *GenerationPlan) CheckLimits(limits PlanLimits) error {
+	if limits.IsZero() {
+		return nil
+	}
+
+	dirs := make(map[string]bool)
+	perPackage := make(map[string]int)
+	maxDepth, deepest := 0, ""
+
+	addDir := func(dir string) {
+		dir = filepath.ToSlash(filepath.Clean(dir))
+		if dir == "." || dir == "" {
+			return
+		}
+		for parent := dir; parent != "." && !dirs[parent]; parent = filepath.ToSlash(filepath.Dir(parent)) {
+			dirs[parent] = true
+		}
+		if depth := strings.Count(dir, "/") + 1; depth > maxDepth {
+			maxDepth, deepest = depth, dir
+		}
+	}
+
+	for _, dir := range p.FileTree.Directories {
+		addDir(dir.Path)
+	}
+	for _, file := range p.FileTree.Files {
+		dir := filepath.Dir(file.Path)
+		addDir(dir)
+		if strings.HasSuffix(file.Path, ".go") && !strings.HasSuffix(file.Path, "_test.go") {
+			perPackage[filepath.ToSlash(dir)]++
+		}
+	}
+
+	var violations []string
+	if limits.MaxFiles > 0 && len(p.FileTree.Files) > limits.MaxFiles {
+		violations = append(violations, fmt.Sprintf("%d files (max %d)", len(p.FileTree.Files), limits.MaxFiles))
+	}
+	if limits.MaxDirectories > 0 && len(dirs) > limits.MaxDirectories {
+		violations = append(violations, fmt.Sprintf("%d directories (max %d)", len(dirs), limits.MaxDirectories))
+	}
+	if limits.MaxDepth > 0 && maxDepth > limits.MaxDepth {
+		violations = append(violations, fmt.Sprintf("depth %d at %s (max %d)", maxDepth, deepest, limits.MaxDepth))
+	}
+	if limits.MaxFilesPerPackage > 0 {
+		pkgs := make([]string, 0, len(perPackage))
+		for pkg, count := range perPackage {
+			if count > limits.MaxFilesPerPackage {
+				pkgs = append(pkgs, pkg)
+			}
+		}
+		sort.Strings(pkgs)
+		for _, pkg := range pkgs {
+			violations = append(violations, fmt.Sprintf("%d files in package %s (max %d)", perPackage[pkg], pkg, limits.MaxFilesPerPackage))
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PlanLimitError{Violations: violations}
+	}
+	return nil
+}
+
+// FileSplitStrategy controls how a plan distributes data model entities across files
+type FileSplitStrategy string
+
+const (
+	FileSplitAuto       FileSplitStrategy = ""            // The planner decides
+	FileSplitPerEntity  FileSplitStrategy = "per_entity"  // Each entity in its own file
+	FileSplitPerPackage FileSplitStrategy = "per_package" // All entities of a package in one file
+	FileSplitBounded    FileSplitStrategy = "bounded"     // Source files capped at an estimated line count
+)
+
+// FileLayout describes how a plan must split generated source into files
+type FileLayout struct {
+	Strategy     FileSplitStrategy `json:"strategy,omitempty"`
+	MaxFileLines int               `json:"max_file_lines,omitempty"` // Line budget per source file for FileSplitBounded
+}
+
+// SQLDialect is the database generated schemas and migrations are written for
+type SQLDialect string
+
+const (
+	DialectPostgres SQLDialect = "postgres"
+	DialectMySQL    SQLDialect = "mysql"
+	DialectSQLite   SQLDialect = "sqlite"
+)
+
+// CheckFileLayout reports files that break the layout as a *PlanLimitError.
+// Only non-test .go files the LLM generates are checked; template files are exempt.
+func (p *GenerationPlan) CheckFileLayout(layout FileLayout, entities []Entity) error {
+	var violations []string
+
+	switch layout.Strategy {
+	case FileSplitAuto:
+		return nil
+
+	case FileSplitPerEntity, FileSplitPerPackage:
+		violations = p.checkEntityPlacement(layout.Strategy, entities)
+
+	case FileSplitBounded:
+		for _, file := range p.FileTree.Files {
+			if !isPlannedSource(file) {
+				continue
+			}
+			switch {
+			case file.EstimatedLines <= 0:
+				violations = append(violations, fmt.Sprintf("file %s has no estimated_lines", file.Path))
+			case layout.MaxFileLines > 0 && file.EstimatedLines > layout.MaxFileLines:
+				violations = append(violations, fmt.Sprintf("file %s estimated at %d lines (max %d)", file.Path, file.EstimatedLines, layout.MaxFileLines))
+			}
+		}
+
+	default:
+		return fmt.Errorf("unknown file split strategy: %s", layout.Strategy)
+	}
+
+	if len(violations) > 0 {
+		return &PlanLimitError{Violations: violations}
+	}
+	return nil
+}
+
+// checkEntityPlacement verifies every entity is defined in exactly one file and
+// that files group entities as the strategy requires
+func (p *GenerationPlan) checkEntityPlacement(strategy FileSplitStrategy, entities []Entity) []string {
+	known := make(map[string]string, len(entities))
+	for _, entity := range entities {
+		known[strings.ToLower(entity.Name)] = entity.Name
+	}
+
+	definedIn := make(map[string][]string)
+	entityFiles := make(map[string][]string) // package directory -> files defining entities
+	var violations []string
+
+	for _, file := range p.FileTree.Files {
+		if !isPlannedSource(file) {
+			continue
+		}
+
+		var names []string
+		for _, name := range file.Entities {
+			if canonical, ok := known[strings.ToLower(name)]; ok {
+				names = append(names, canonical)
+				definedIn[canonical] = append(definedIn[canonical], file.Path)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+
+		if strategy == FileSplitPerEntity && len(names) > 1 {
+			violations = append(violations, fmt.Sprintf("file %s defines %d entities (%s); per_entity allows 1", file.Path, len(names), strings.Join(names, ", ")))
+		}
+		dir := filepath.ToSlash(filepath.Dir(file.Path))
+		entityFiles[dir] = append(entityFiles[dir], file.Path)
+	}
+
+	for _, entity := range entities {
+		switch files := definedIn[entity.Name]; {
+		case len(files) == 0:
+			violations = append(violations, fmt.Sprintf("entity %s is not assigned to a file", entity.Name))
+		case len(files) > 1:
+			violations = append(violations, fmt.Sprintf("entity %s is defined in %d files (%s)", entity.Name, len(files), strings.Join(files, ", ")))
+		}
+	}
+
+	if strategy == FileSplitPerPackage {
+		pkgs := make([]string, 0, len(entityFiles))
+		for pkg, files := range entityFiles {
+			if len(files) > 1 {
+				pkgs = append(pkgs, pkg)
+			}
+		}
+		sort.Strings(pkgs)
+		for _, pkg := range pkgs {
+			violations = append(violations, fmt.Sprintf("package %s spreads its entities over %d files; per_package allows 1", pkg, len(entityFiles[pkg])))
+		}
+	}
+
+	return violations
+}
+
+// isPlannedSource reports whether the file is Go source the LLM will write
+func isPlannedSource(file File) bool {
+	return strings.HasSuffix(file.Path, ".go") && !strings.HasSuffix(file.Path, "_test.go") && file.GeneratedBy != "template"
+}
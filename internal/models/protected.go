@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ProtectedPaths are slash-separated globs for paths inside the output tree
+// that people own and generation must never write. "*" matches within one
+// path element and "**" matches any number of elements. A pattern also
+// protects everything below a matching directory, so "scripts" and
+// "scripts/**" are equivalent.
+type ProtectedPaths []string
+
+// Validate reports the first malformed pattern
+func (p ProtectedPaths) Validate() error {
+	for _, pattern := range p {
+		cleaned := strings.Trim(filepath.ToSlash(pattern), "/")
+		if cleaned == "" {
+			return fmt.Errorf("protected path pattern is empty")
+		}
+		if path.IsAbs(filepath.ToSlash(pattern)) || filepath.IsAbs(pattern) {
+			return fmt.Errorf("protected path %q must be relative to the output directory", pattern)
+		}
+		for _, elem := range strings.Split(cleaned, "/") {
+			if elem == ".." {
+				return fmt.Errorf("protected path %q must not leave the output directory", pattern)
+			}
+			if _, err := path.Match(elem, ""); err != nil {
+				return fmt.Errorf("protected path %q is not a valid glob: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns the first pattern protecting target, a path relative to the
+// output directory
+func (p ProtectedPaths) Match(target string) (string, bool) {
+	target = path.Clean(filepath.ToSlash(target))
+	if target == "." || strings.HasPrefix(target, "../") {
+		return "", false
+	}
+	elems := strings.Split(strings.TrimPrefix(target, "./"), "/")
+
+	for _, pattern := range p {
+		patternElems := strings.Split(strings.Trim(path.Clean(filepath.ToSlash(pattern)), "/"), "/")
+		for n := 1; n <= len(elems); n++ {
+			if matchElems(patternElems, elems[:n]) {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchElems matches path elements against pattern elements, where "**"
+// matches zero or more elements
+func matchElems(pattern, elems []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for skip := 0; skip <= len(elems); skip++ {
+				if matchElems(pattern[1:], elems[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elems) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], elems[0]); err != nil || !ok {
+			return false
+		}
+		pattern, elems = pattern[1:], elems[1:]
+	}
+	return len(elems) == 0
+}
+
+// CheckProtectedPaths reports planned files that fall inside protected paths
+// as a *PlanLimitError
+func (p *GenerationPlan) CheckProtectedPaths(protected ProtectedPaths) error {
+	if len(protected) == 0 {
+		return nil
+	}
+
+	var violations []string
+	for _, file := range p.FileTree.Files {
+		if pattern, ok := protected.Match(file.Path); ok {
+			violations = append(violations, fmt.Sprintf("file %s is inside protected path %s", file.Path, pattern))
+		}
+	}
+	for _, dir := range p.FileTree.Directories {
+		if pattern, ok := protected.Match(dir.Path); ok {
+			violations = append(violations, fmt.Sprintf("directory %s is inside protected path %s", dir.Path, pattern))
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PlanLimitError{Violations: violations}
+	}
+	return nil
+}
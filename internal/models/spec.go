@@ -51,6 +51,11 @@ type InputSpecification struct {
 	Metadata         SpecMetadata           `json:"metadata"`
 	ValidationErrors []ValidationError      `json:"validation_errors,omitempty"`
 	State            SpecState              `json:"state"`
+
+	// Sources and Conflicts are set on a spec merged from several: the names
+	// of the merged specs and the values they disagree on
+	Sources   []string       `json:"sources,omitempty"`
+	Conflicts []SpecConflict `json:"conflicts,omitempty"`
 }
 
 // Validate validates the input specification
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+)
+
+// StructuralProblems lists the references in the FCS that do not resolve
+// and the identifiers that are not unique: requirement IDs, entity, enum and
+// package names, the packages entities live in and packages depend on, the
+// entities relationships join and the types attributes name. Change
+// detection matches requirements, entities and packages by these keys, so
+// an FCS with problems regenerates unpredictably.
+func (f *FinalClarifiedSpecification) StructuralProblems() []string {
+	var problems []string
+	problems = append(problems, f.requirementProblems()...)
+	problems = append(problems, f.packageProblems()...)
+	problems = append(problems, f.dataModelProblems()...)
+	return problems
+}
+
+// requirementProblems reports empty and duplicate requirement IDs, which
+// are unique across functional and non-functional requirements
+func (f *FinalClarifiedSpecification) requirementProblems() []string {
+	var problems []string
+	seen := make(map[string]bool)
+	check := func(kind string, i int, id string) {
+		switch {
+		case strings.TrimSpace(id) == "":
+			problems = append(problems, fmt.Sprintf("%s requirement %d has no ID", kind, i+1))
+		case seen[id]:
+			problems = append(problems, fmt.Sprintf("requirement ID %s is used more than once", id))
+		}
+		seen[id] = true
+	}
+	for i, req := range f.Requirements.Functional {
+		check("functional", i, req.ID)
+	}
+	for i, req := range f.Requirements.NonFunctional {
+		check("non-functional", i, req.ID)
+	}
+	return problems
+}
+
+// packageProblems reports empty and duplicate package names, dependencies
+// on undeclared packages and dependency cycles
+func (f *FinalClarifiedSpecification) packageProblems() []string {
+	var problems []string
+	names := make(map[string]bool, len(f.Architecture.Packages))
+	for i, pkg := range f.Architecture.Packages {
+		switch {
+		case strings.TrimSpace(pkg.Name) == "":
+			problems = append(problems, fmt.Sprintf("package %d has no name", i+1))
+		case names[pkg.Name]:
+			problems = append(problems, fmt.Sprintf("package %s is declared more than once", pkg.Name))
+		}
+		names[pkg.Name] = true
+	}
+	for _, pkg := range f.Architecture.Packages {
+		for _, dep := range pkg.Dependencies {
+			if !names[dep] {
+				problems = append(problems, fmt.Sprintf("package %s depends on undeclared package %s", pkg.Name, dep))
+			}
+		}
+	}
+	if f.HasCyclicDependencies() {
+		problems = append(problems, "package dependencies are cyclic")
+	}
+	return problems
+}
+
+// dataModelProblems reports empty and duplicate entity names, entities in
+// undeclared packages, attributes of unknown types, relationships between
+// unknown entities and malformed enums
+func (f *FinalClarifiedSpecification) dataModelProblems() []string {
+	var problems []string
+	types := make(map[string]bool, len(f.DataModel.Entities)+len(f.DataModel.Enums))
+	for _, e := range f.DataModel.Enums {
+		types[e.Name] = true
+	}
+
+	entities := make(map[string]bool, len(f.DataModel.Entities))
+	for i, entity := range f.DataModel.Entities {
+		switch {
+		case strings.TrimSpace(entity.Name) == "":
+			problems = append(problems, fmt.Sprintf("entity %d has no name", i+1))
+		case entities[entity.Name]:
+			problems = append(problems, fmt.Sprintf("entity %s is defined more than once", entity.Name))
+		}
+		entities[entity.Name] = true
+		types[entity.Name] = true
+
+		if entity.Package != "" && len(f.Architecture.Packages) > 0 && !f.declaresPackage(entity.Package) {
+			problems = append(problems, fmt.Sprintf("entity %s is in undeclared package %s", entity.Name, entity.Package))
+		}
+	}
+
+	for _, entity := range f.DataModel.Entities {
+		for _, attr := range sortedAttributes(entity.Attributes) {
+			if name, ok := localTypeName(entity.Attributes[attr]); ok && !types[name] {
+				problems = append(problems, fmt.Sprintf("attribute %s.%s has unknown type %s", entity.Name, attr, name))
+			}
+		}
+	}
+
+	for _, rel := range f.DataModel.Relationships {
+		for _, end := range []string{rel.From, rel.To} {
+			if !entities[end] {
+				problems = append(problems, fmt.Sprintf("relationship %s -> %s references unknown entity %s", rel.From, rel.To, end))
+			}
+		}
+	}
+
+	if err := f.DataModel.ValidateEnums(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	return problems
+}
+
+// declaresPackage reports whether the architecture declares a package with
+// the given name, path or last path element
+func (f *FinalClarifiedSpecification) declaresPackage(name string) bool {
+	for _, pkg := range f.Architecture.Packages {
+		if pkg.Name == name || pkg.Path == name || path.Base(pkg.Path) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// localTypeName returns the name of the type an attribute type refers to
+// when it is declared in the FCS rather than by Go or another package: an
+// exported identifier with no package qualifier, such as the entity in
+// "[]*Order"
+func localTypeName(typ string) (string, bool) {
+	name := baseTypeName(typ)
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return "", false
+	}
+	if strings.Contains(typ, "."+name) {
+		return "", false
+	}
+	return name, true
+}
+
+func sortedAttributes(attrs map[string]string) []string {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
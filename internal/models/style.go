@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error wrapping styles
+const (
+	ErrorWrappingFmt       = "fmt_errorf" // fmt.Errorf with %w
+	ErrorWrappingPkgErrors = "pkg_errors" // errors.Wrap from github.com/pkg/errors
+)
+
+// ErrorWrappingStyles lists the supported error wrapping styles
+var ErrorWrappingStyles = []string{ErrorWrappingFmt, ErrorWrappingPkgErrors}
+
+// LoggingLibraries maps the supported logging libraries to their import path
+var LoggingLibraries = map[string]string{
+	"slog":    "log/slog",
+	"zerolog": "github.com/rs/zerolog",
+	"zap":     "go.uber.org/zap",
+	"logrus":  "github.com/sirupsen/logrus",
+	"log":     "log",
+}
+
+// ContextFirstParam requires context.Context as the first parameter of every
+// function that takes one, and forbids root contexts outside package main
+const ContextFirstParam = "first_param"
+
+// Initialisms are written in one case in Go identifiers (ID, not Id)
+var Initialisms = []string{"API", "HTML", "HTTP", "ID", "JSON", "SQL", "URI", "URL", "UUID", "XML"}
+
+// StyleGuide is a team's house style for generated code. It is rendered into
+// every coder and tester prompt and checked after generation. Empty fields
+// set no rule.
+type StyleGuide struct {
+	ErrorWrapping string   `json:"error_wrapping,omitempty"`
+	Logging       string   `json:"logging,omitempty"` // A key of LoggingLibraries
+	Context       string   `json:"context,omitempty"`
+	Initialisms   bool     `json:"initialisms,omitempty"`    // Initialisms keep one case, e.g. userID
+	TestFramework string   `json:"test_framework,omitempty"` // Used when the testing strategy selects none
+	Rules         []string `json:"rules,omitempty"`          // Free-form rules, prompted but not checked
+}
+
+// Enabled reports whether the style sets any rule
+func (s StyleGuide) Enabled() bool {
+	return s.ErrorWrapping != "" || s.Logging != "" || s.Context != "" || s.Initialisms ||
+		s.TestFramework != "" || len(s.Rules) > 0
+}
+
+// Checkable reports whether the style sets a rule generated code can be
+// checked against; free-form rules are only prompted
+func (s StyleGuide) Checkable() bool {
+	return s.ErrorWrapping != "" || s.Logging != "" || s.Context != "" || s.Initialisms || s.TestFramework != ""
+}
+
+// Validate checks that every named style is supported
+func (s StyleGuide) Validate() error {
+	if s.ErrorWrapping != "" && !containsString(ErrorWrappingStyles, s.ErrorWrapping) {
+		return fmt.Errorf("error_wrapping must be one of: %s", strings.Join(ErrorWrappingStyles, ", "))
+	}
+	if _, ok := LoggingLibraries[s.Logging]; s.Logging != "" && !ok {
+		return fmt.Errorf("logging must be one of: slog, zerolog, zap, logrus, log")
+	}
+	if s.Context != "" && s.Context != ContextFirstParam {
+		return fmt.Errorf("context must be %s", ContextFirstParam)
+	}
+	if s.TestFramework != "" && !IsTestFramework(s.TestFramework) {
+		return fmt.Errorf("test_framework must be one of: %s", strings.Join(TestFrameworks, ", "))
+	}
+	return nil
+}
@@ -51,6 +51,7 @@ type LintIssue struct {
 type LintResult struct {
 	Success  bool          `json:"success"`
 	Issues   []LintIssue   `json:"issues,omitempty"`
+	Repaired []string      `json:"repaired,omitempty"` // Files regenerated to fix earlier issues
 	Duration time.Duration `json:"duration"`
 }
 
@@ -73,16 +74,156 @@ type TestResult struct {
 	Duration    time.Duration `json:"duration"`
 }
 
+// RequirementCoverage maps functional requirements to the tests that reference them
+type RequirementCoverage struct {
+	Success           bool                `json:"success"`
+	TotalRequirements int                 `json:"total_requirements"`
+	Covered           map[string][]string `json:"covered,omitempty"`  // Requirement ID -> test files
+	Untested          []string            `json:"untested,omitempty"` // Requirement IDs without a test
+	Unknown           []string            `json:"unknown,omitempty"`  // Referenced IDs not in the specification
+}
+
+// EnumUsage reports whether generated code declares the specification's enums
+// and types entity fields with them
+type EnumUsage struct {
+	Success    bool        `json:"success"`
+	TotalEnums int         `json:"total_enums"`
+	Issues     []EnumIssue `json:"issues,omitempty"`
+}
+
+// EnumIssue is an enum that is missing, incomplete or bypassed by a raw type
+type EnumIssue struct {
+	Enum    string `json:"enum"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// MiddlewareUsage reports whether generated code declares the cross-cutting
+// middleware and wraps the handlers of every package that registers routes
+type MiddlewareUsage struct {
+	Success bool              `json:"success"`
+	Package string            `json:"package"`           // Middleware package checked
+	Wrapped []string          `json:"wrapped,omitempty"` // Files whose routes apply every middleware
+	Issues  []MiddlewareIssue `json:"issues,omitempty"`
+}
+
+// MiddlewareIssue is missing middleware or a route package that does not apply it
+type MiddlewareIssue struct {
+	Concern string `json:"concern"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// StyleResult reports where generated code departs from the configured
+// house style
+type StyleResult struct {
+	Success bool         `json:"success"`
+	Files   int          `json:"files"` // Go files checked
+	Issues  []StyleIssue `json:"issues,omitempty"`
+}
+
+// StyleIssue is code that breaks a house style rule
+type StyleIssue struct {
+	Rule    string `json:"rule"` // error_wrapping, logging, context, initialisms or test_framework
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// ContractResult reports which declared output contracts generated code violates
+type ContractResult struct {
+	Success    bool                `json:"success"`
+	Checked    int                 `json:"checked"`
+	Violations []ContractViolation `json:"violations,omitempty"`
+}
+
+// ContractViolation is an output contract the generated code does not meet
+type ContractViolation struct {
+	Contract string `json:"contract"` // The contract, e.g. "GET /users is routed"
+	Message  string `json:"message"`
+}
+
+// SmokeResult represents the result of running the project's built executable
+type SmokeResult struct {
+	Success   bool          `json:"success"`
+	Skipped   bool          `json:"skipped,omitempty"` // No executable target to run
+	Package   string        `json:"package,omitempty"` // Main package that was built
+	Command   string        `json:"command,omitempty"` // Executable and arguments as run
+	HealthURL string        `json:"health_url,omitempty"`
+	ExitCode  int           `json:"exit_code"`
+	Message   string        `json:"message,omitempty"` // Why the run failed or was skipped
+	Output    string        `json:"output,omitempty"`  // Tail of the combined stdout and stderr
+	Duration  time.Duration `json:"duration"`
+}
+
+// FuzzResult represents the result of briefly running each fuzz target in
+// the project with go test -fuzz
+type FuzzResult struct {
+	Success  bool          `json:"success"`
+	Targets  int           `json:"targets"` // Fuzz targets run
+	FuzzTime time.Duration `json:"fuzz_time"`
+	Failures []FuzzFailure `json:"failures,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// FuzzFailure is a fuzz target that failed or found a crashing input
+type FuzzFailure struct {
+	Package string `json:"package"` // Directory relative to the project root, e.g. ./internal/config
+	Target  string `json:"target"`
+	Message string `json:"message"`
+	Output  string `json:"output,omitempty"` // Tail of the go test output
+}
+
+// BuildFilesResult represents the result of checking the project's
+// Dockerfile and Makefile with the tools that consume them
+type BuildFilesResult struct {
+	Success  bool             `json:"success"`
+	Checks   []BuildFileCheck `json:"checks,omitempty"`
+	Duration time.Duration    `json:"duration"`
+}
+
+// Checked reports whether any check ran rather than being skipped
+func (r *BuildFilesResult) Checked() bool {
+	for _, check := range r.Checks {
+		if !check.Skipped {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildFileCheck is one check of a build file, e.g. make -n for one target
+type BuildFileCheck struct {
+	File     string        `json:"file"`              // Relative to the project root, e.g. Makefile
+	Tool     string        `json:"tool,omitempty"`    // docker, hadolint or make
+	Command  string        `json:"command,omitempty"` // Command as run, e.g. "make -n test"
+	Success  bool          `json:"success"`
+	Skipped  bool          `json:"skipped,omitempty"` // No tool to check the file with
+	Message  string        `json:"message,omitempty"` // Why the check failed or was skipped
+	Output   string        `json:"output,omitempty"`  // Tail of the tool's output on failure
+	Duration time.Duration `json:"duration"`
+}
+
 // ValidationReport represents a complete validation report
 type ValidationReport struct {
-	SchemaVersion string           `json:"schema_version"`
-	ID            string           `json:"id"`
-	OutputID      string           `json:"output_id"`
-	BuildResult   BuildResult      `json:"build_result"`
-	LintResult    LintResult       `json:"lint_result"`
-	TestResult    TestResult       `json:"test_result"`
-	OverallStatus ValidationStatus `json:"overall_status"`
-	CreatedAt     time.Time        `json:"created_at"`
+	SchemaVersion       string               `json:"schema_version"`
+	ID                  string               `json:"id"`
+	OutputID            string               `json:"output_id"`
+	BuildResult         BuildResult          `json:"build_result"`
+	LintResult          LintResult           `json:"lint_result"`
+	TestResult          TestResult           `json:"test_result"`
+	RequirementCoverage *RequirementCoverage `json:"requirement_coverage,omitempty"`
+	EnumUsage           *EnumUsage           `json:"enum_usage,omitempty"`
+	MiddlewareUsage     *MiddlewareUsage     `json:"middleware_usage,omitempty"`
+	Contracts           *ContractResult      `json:"contracts,omitempty"`
+	Style               *StyleResult         `json:"style,omitempty"`
+	SmokeResult         *SmokeResult         `json:"smoke_result,omitempty"`
+	FuzzResult          *FuzzResult          `json:"fuzz_result,omitempty"`
+	BuildFiles          *BuildFilesResult    `json:"build_files,omitempty"`
+	OverallStatus       ValidationStatus     `json:"overall_status"`
+	CreatedAt           time.Time            `json:"created_at"`
 }
 
 // Validate validates the validation report
@@ -107,8 +248,35 @@ func (v *ValidationReport) Validate() error {
 	return nil
 }
 
-// ComputeOverallStatus computes the overall validation status
+// ComputeOverallStatus computes the overall validation status.
+// Requirement coverage, enum usage, middleware usage, output contracts, the
+// house style, the smoke run, the fuzz run and the build file checks only
+// count when they were checked.
 func (v *ValidationReport) ComputeOverallStatus() ValidationStatus {
+	if v.RequirementCoverage != nil && !v.RequirementCoverage.Success {
+		return ValidationStatusFail
+	}
+	if v.EnumUsage != nil && !v.EnumUsage.Success {
+		return ValidationStatusFail
+	}
+	if v.MiddlewareUsage != nil && !v.MiddlewareUsage.Success {
+		return ValidationStatusFail
+	}
+	if v.Contracts != nil && !v.Contracts.Success {
+		return ValidationStatusFail
+	}
+	if v.Style != nil && !v.Style.Success {
+		return ValidationStatusFail
+	}
+	if v.SmokeResult != nil && !v.SmokeResult.Success {
+		return ValidationStatusFail
+	}
+	if v.FuzzResult != nil && !v.FuzzResult.Success {
+		return ValidationStatusFail
+	}
+	if v.BuildFiles != nil && !v.BuildFiles.Success {
+		return ValidationStatusFail
+	}
 	if v.BuildResult.Success && v.LintResult.Success && v.TestResult.Success {
 		return ValidationStatusPass
 	}
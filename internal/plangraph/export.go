@@ -0,0 +1,174 @@
+package plangraph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// WriteDOT writes the plan as a Graphviz digraph. Each phase is a cluster
+// holding its tasks, and phase dependencies are edges between clusters.
+// File-writing tasks are labeled with their execution level; tasks of the same
+// level run in parallel. Other tasks are dashed.
+func WriteDOT(w io.Writer, plan *models.GenerationPlan) error {
+	graph := Build(plan)
+	phases := orderedPhases(plan)
+	clusters := phaseIndex(phases)
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "digraph %s {\n", dotID(planName(plan)))
+	fmt.Fprintln(b, "  rankdir=LR;")
+	fmt.Fprintln(b, "  compound=true;")
+	fmt.Fprintln(b, "  node [shape=box, fontsize=10];")
+
+	for i, phase := range phases {
+		fmt.Fprintf(b, "\n  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(b, "    label=%s;\n", dotID(fmt.Sprintf("%d. %s", phase.Order, phase.Name)))
+		// Edges between clusters are drawn between their anchors
+		fmt.Fprintf(b, "    %s [shape=point, style=invis];\n", dotID(phaseAnchor(i)))
+		for _, task := range phase.Tasks {
+			label, dashed := taskLabel(task, graph)
+			fmt.Fprintf(b, "    %s [label=%s", dotID(task.ID), dotID(label))
+			if dashed {
+				fmt.Fprint(b, ", style=dashed")
+			}
+			fmt.Fprintln(b, "];")
+		}
+		fmt.Fprintln(b, "  }")
+	}
+
+	if edges := phaseEdges(phases, clusters); len(edges) > 0 {
+		fmt.Fprintln(b)
+		for _, edge := range edges {
+			fmt.Fprintf(b, "  %s -> %s [ltail=cluster_%d, lhead=cluster_%d];\n",
+				dotID(phaseAnchor(edge[0])), dotID(phaseAnchor(edge[1])), edge[0], edge[1])
+		}
+	}
+	fmt.Fprintln(b, "}")
+	return b.Flush()
+}
+
+// WriteMermaid writes the plan as a Mermaid flowchart, laid out as WriteDOT
+// lays it out: a subgraph per phase and edges between dependent phases
+func WriteMermaid(w io.Writer, plan *models.GenerationPlan) error {
+	graph := Build(plan)
+	phases := orderedPhases(plan)
+	clusters := phaseIndex(phases)
+
+	// Task IDs come from the planner, so nodes get generated IDs
+	nodeIDs := make(map[string]string)
+	nodeID := func(taskID string) string {
+		if id, ok := nodeIDs[taskID]; ok {
+			return id
+		}
+		id := fmt.Sprintf("t%d", len(nodeIDs))
+		nodeIDs[taskID] = id
+		return id
+	}
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintln(b, "flowchart LR")
+	for i, phase := range phases {
+		fmt.Fprintf(b, "  subgraph %s [%s]\n", phaseAnchor(i), mermaidText(fmt.Sprintf("%d. %s", phase.Order, phase.Name)))
+		if len(phase.Tasks) == 0 {
+			// An empty subgraph is not rendered
+			fmt.Fprintf(b, "    %s_empty[%s]\n", phaseAnchor(i), mermaidText("no tasks"))
+		}
+		for _, task := range phase.Tasks {
+			label, _ := taskLabel(task, graph)
+			fmt.Fprintf(b, "    %s[%s]\n", nodeID(task.ID), mermaidText(label))
+		}
+		fmt.Fprintln(b, "  end")
+	}
+
+	for _, edge := range phaseEdges(phases, clusters) {
+		fmt.Fprintf(b, "  %s --> %s\n", phaseAnchor(edge[0]), phaseAnchor(edge[1]))
+	}
+
+	var dashed []string
+	for _, phase := range phases {
+		for _, task := range phase.Tasks {
+			if _, ok := graph.Nodes[task.ID]; !ok {
+				dashed = append(dashed, nodeID(task.ID))
+			}
+		}
+	}
+	if len(dashed) > 0 {
+		fmt.Fprintln(b, "  classDef command stroke-dasharray: 5 5")
+		fmt.Fprintf(b, "  class %s command\n", strings.Join(dashed, ","))
+	}
+	return b.Flush()
+}
+
+// orderedPhases returns the phases of plan in execution order
+func orderedPhases(plan *models.GenerationPlan) []models.GenerationPhase {
+	phases := append([]models.GenerationPhase(nil), plan.Phases...)
+	sort.SliceStable(phases, func(i, j int) bool { return phases[i].Order < phases[j].Order })
+	return phases
+}
+
+// phaseIndex maps phase names to their position in phases
+func phaseIndex(phases []models.GenerationPhase) map[string]int {
+	index := make(map[string]int, len(phases))
+	for i, phase := range phases {
+		if _, ok := index[phase.Name]; !ok {
+			index[phase.Name] = i
+		}
+	}
+	return index
+}
+
+// phaseEdges returns the dependencies between phases as (from, to) index
+// pairs; dependencies on phases the plan does not have are left out
+func phaseEdges(phases []models.GenerationPhase, index map[string]int) [][2]int {
+	var edges [][2]int
+	for i, phase := range phases {
+		for _, dep := range phase.Dependencies {
+			if from, ok := index[dep]; ok {
+				edges = append(edges, [2]int{from, i})
+			}
+		}
+	}
+	return edges
+}
+
+// taskLabel describes a task, and reports whether it is outside the graph
+func taskLabel(task models.GenerationTask, graph *Graph) (string, bool) {
+	name := task.TargetPath
+	if name == "" {
+		name = task.ID
+	}
+	node, ok := graph.Nodes[task.ID]
+	if !ok {
+		return fmt.Sprintf("%s\n%s", name, task.Type), true
+	}
+	return fmt.Sprintf("%s\nlevel %d", name, node.Level), false
+}
+
+// planName names the exported graph
+func planName(plan *models.GenerationPlan) string {
+	if plan.ID == "" {
+		return "plan"
+	}
+	return "plan " + plan.ID
+}
+
+func phaseAnchor(i int) string {
+	return fmt.Sprintf("phase_%d", i)
+}
+
+// dotID quotes s as a DOT identifier
+func dotID(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// mermaidText quotes s as Mermaid node text
+func mermaidText(s string) string {
+	r := strings.NewReplacer(`"`, "#quot;", "\n", "<br/>")
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,147 @@
+// Package plangraph builds the dependency graph of a generation plan and
+// exports it for visualization.
+//
+// The graph holds the tasks that write files. A task depends on every such
+// task of the phases its own phase depends on, and tasks are grouped into
+// execution levels: the tasks of a level depend only on tasks of earlier
+// levels, so each level can be generated in parallel.
+package plangraph
+
+import (
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// Node is a file-writing task of the graph
+type Node struct {
+	Task         models.GenerationTask
+	Phase        string   // Name of the phase the task belongs to
+	Dependencies []string // IDs of the tasks that must be generated first
+	Level        int      // Execution level (0 = no dependencies, 1 = depends on level 0, etc.)
+}
+
+// Graph is the task dependency graph of a plan, organized by execution level
+type Graph struct {
+	Nodes  map[string]*Node
+	Levels [][]string // Task IDs by level, in plan order within a level
+}
+
+// Build creates the dependency graph of plan
+func Build(plan *models.GenerationPlan) *Graph {
+	graph := &Graph{
+		Nodes: make(map[string]*Node),
+	}
+
+	// Build phase->tasks mapping
+	phaseToTasks := make(map[string][]string)
+	for _, phase := range plan.Phases {
+		for _, task := range phase.Tasks {
+			if task.WritesFile() {
+				phaseToTasks[phase.Name] = append(phaseToTasks[phase.Name], task.ID)
+			}
+		}
+	}
+
+	// Build nodes from phases, resolving phase dependencies to task dependencies
+	var order []string
+	for _, phase := range plan.Phases {
+		for _, task := range phase.Tasks {
+			if !task.WritesFile() {
+				continue
+			}
+			var taskDeps []string
+			for _, depPhaseName := range phase.Dependencies {
+				// Add all tasks from the dependency phase as dependencies
+				taskDeps = append(taskDeps, phaseToTasks[depPhaseName]...)
+			}
+
+			if _, seen := graph.Nodes[task.ID]; !seen {
+				order = append(order, task.ID)
+			}
+			graph.Nodes[task.ID] = &Node{
+				Task:         task,
+				Phase:        phase.Name,
+				Dependencies: taskDeps,
+				Level:        -1, // Will be computed
+			}
+		}
+	}
+
+	computeLevels(graph, order)
+
+	return graph
+}
+
+// computeLevels assigns each task to an execution level based on its
+// dependencies and groups the tasks by level in the given order
+func computeLevels(graph *Graph, order []string) {
+	// Iteratively compute levels
+	maxIterations := len(graph.Nodes) + 1
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		changed := false
+
+		for _, node := range graph.Nodes {
+			if node.Level >= 0 {
+				continue // Already computed
+			}
+
+			// Check if all dependencies have assigned levels
+			maxDepLevel := -1
+			allDepsReady := true
+
+			for _, depID := range node.Dependencies {
+				depNode, exists := graph.Nodes[depID]
+				if !exists {
+					// Dependency not in graph; assume it's ready
+					continue
+				}
+
+				if depNode.Level < 0 {
+					allDepsReady = false
+					break
+				}
+
+				if depNode.Level > maxDepLevel {
+					maxDepLevel = depNode.Level
+				}
+			}
+
+			// If all dependencies ready (or no dependencies), assign level
+			if allDepsReady {
+				node.Level = maxDepLevel + 1
+				changed = true
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	maxLevel := 0
+	for _, node := range graph.Nodes {
+		if node.Level < 0 {
+			node.Level = 0 // Fallback for tasks with no computable level, as in a cycle
+		}
+		if node.Level > maxLevel {
+			maxLevel = node.Level
+		}
+	}
+
+	graph.Levels = make([][]string, maxLevel+1)
+	for _, taskID := range order {
+		level := graph.Nodes[taskID].Level
+		graph.Levels[level] = append(graph.Levels[level], taskID)
+	}
+}
+
+// Width returns the number of tasks of the widest level, the most that can
+// be generated at once
+func (g *Graph) Width() int {
+	width := 0
+	for _, level := range g.Levels {
+		if len(level) > width {
+			width = len(level)
+		}
+	}
+	return width
+}
@@ -0,0 +1,85 @@
+package plangraph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func file(id, path string) models.GenerationTask {
+	return models.GenerationTask{ID: id, Type: "generate_file", TargetPath: path}
+}
+
+// testPlan has two independent model files, a service depending on them,
+// and a command run after everything
+func testPlan() *models.GenerationPlan {
+	return &models.GenerationPlan{
+		ID: "plan-1",
+		Phases: []models.GenerationPhase{
+			{Name: "finish", Order: 3, Dependencies: []string{"service"}, Tasks: []models.GenerationTask{
+				{ID: "tidy", Type: "run_command", Inputs: map[string]interface{}{"command": "go mod tidy"}},
+			}},
+			{Name: "models", Order: 1, Tasks: []models.GenerationTask{
+				file("user", "internal/models/user.go"),
+				file("order", "internal/models/order.go"),
+			}},
+			{Name: "service", Order: 2, Dependencies: []string{"models"}, Tasks: []models.GenerationTask{
+				file("svc", "internal/service/service.go"),
+			}},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	graph := Build(testPlan())
+
+	require.Len(t, graph.Nodes, 3, "only file-writing tasks are nodes")
+	assert.Equal(t, [][]string{{"user", "order"}, {"svc"}}, graph.Levels, "levels keep plan order")
+	assert.Equal(t, 2, graph.Width())
+	assert.Equal(t, []string{"user", "order"}, graph.Nodes["svc"].Dependencies)
+	assert.Equal(t, "service", graph.Nodes["svc"].Phase)
+}
+
+func TestBuild_Cycle(t *testing.T) {
+	plan := &models.GenerationPlan{Phases: []models.GenerationPhase{
+		{Name: "a", Dependencies: []string{"b"}, Tasks: []models.GenerationTask{file("a", "a.go")}},
+		{Name: "b", Dependencies: []string{"a"}, Tasks: []models.GenerationTask{file("b", "b.go")}},
+	}}
+
+	graph := Build(plan)
+	assert.Equal(t, [][]string{{"a", "b"}}, graph.Levels, "tasks in a cycle fall back to level 0")
+}
+
+func TestWriteDOT(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, WriteDOT(&out, testPlan()))
+	dot := out.String()
+
+	assert.True(t, strings.HasPrefix(dot, `digraph "plan plan-1" {`))
+	assert.Contains(t, dot, `label="1. models";`)
+	assert.Contains(t, dot, `"user" [label="internal/models/user.go\nlevel 0"];`)
+	assert.Contains(t, dot, `"svc" [label="internal/service/service.go\nlevel 1"];`)
+	assert.Contains(t, dot, `"tidy" [label="tidy\nrun_command", style=dashed];`)
+	// Phases are clustered in execution order
+	assert.Contains(t, dot, `"phase_0" -> "phase_1" [ltail=cluster_0, lhead=cluster_1];`)
+	assert.Contains(t, dot, `"phase_1" -> "phase_2" [ltail=cluster_1, lhead=cluster_2];`)
+	assert.Less(t, strings.Index(dot, "1. models"), strings.Index(dot, "3. finish"))
+	assert.True(t, strings.HasSuffix(dot, "}\n"))
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, WriteMermaid(&out, testPlan()))
+	mermaid := out.String()
+
+	assert.Contains(t, mermaid, "flowchart LR\n")
+	assert.Contains(t, mermaid, `  subgraph phase_0 ["1. models"]`)
+	assert.Contains(t, mermaid, `    t0["internal/models/user.go<br/>level 0"]`)
+	assert.Contains(t, mermaid, `    t3["tidy<br/>run_command"]`)
+	assert.Contains(t, mermaid, "  phase_1 --> phase_2\n")
+	assert.Contains(t, mermaid, "  class t3 command\n")
+	assert.Equal(t, 3, strings.Count(mermaid, "  end\n"))
+}
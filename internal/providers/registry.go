@@ -34,19 +34,23 @@ type Registry struct {
 	metrics         *MetricsCollector        // Metrics collection
 }
 
-// NewRegistry creates and initializes a new provider registry from configuration
-func NewRegistry(configPath string) (*Registry, error) {
+// providerValidationTimeout bounds credential validation; it accommodates retry logic in LLM adapters
+const providerValidationTimeout = 30 * time.Second
+
+// NewRegistry creates and initializes a new provider registry from configuration.
+// Provider credentials are validated under ctx.
+func NewRegistry(ctx context.Context, configPath string) (*Registry, error) {
 	// Load configuration
 	config, err := LoadConfig(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	return NewRegistryFromConfig(config)
+	return NewRegistryFromConfig(ctx, config)
 }
 
 // NewRegistryFromConfig creates a registry from an already-loaded configuration
-func NewRegistryFromConfig(config *MultiProviderConfig) (*Registry, error) {
+func NewRegistryFromConfig(ctx context.Context, config *MultiProviderConfig) (*Registry, error) {
 	registry := &Registry{
 		providers:       make(map[string]LLMProvider),
 		roleMap:         config.Roles,
@@ -66,8 +70,7 @@ func NewRegistryFromConfig(config *MultiProviderConfig) (*Registry, error) {
 
 	// Validate all providers in parallel
 	validator := NewValidator(registry.providers)
-	// Use 30-second timeout to accommodate retry logic in LLM adapters
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, providerValidationTimeout)
 	defer cancel()
 
 	if err := validator.ValidateAll(ctx); err != nil {
@@ -0,0 +1,139 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// Migration upgrades an FCS document from one schema version to the next
+type Migration struct {
+	From string
+	To   string
+
+	// Apply rewrites the decoded document in place; Migrate sets its
+	// schema_version afterwards
+	Apply func(doc map[string]interface{}) error
+}
+
+// fcsMigrations upgrades FCS documents one schema version at a time, up to
+// models.FCSSchemaVersion. A change to the FCS that older documents do not
+// decode into correctly bumps the version and adds its migration here.
+var fcsMigrations []Migration
+
+// baseFCSVersion is the version of FCS documents without a schema_version
+const baseFCSVersion = "1.0"
+
+// MigrateFCS upgrades a raw FCS document to models.FCSSchemaVersion and
+// returns it with the versions it was migrated from, oldest first; a
+// document of the current version is returned unchanged. A document without
+// a schema_version is taken to be of version 1.0. Documents of a newer
+// version than this build supports are refused.
+func MigrateFCS(data []byte) ([]byte, []string, error) {
+	return migrate(data, models.FCSSchemaVersion, fcsMigrations)
+}
+
+// LoadFCS migrates an FCS document to the current schema version and decodes
+// it. The hash of a migrated FCS is recomputed, as migration changes the
+// content it covers.
+func LoadFCS(data []byte) (*models.FinalClarifiedSpecification, []string, error) {
+	migrated, from, err := MigrateFCS(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fcs models.FinalClarifiedSpecification
+	if err := json.Unmarshal(migrated, &fcs); err != nil {
+		return nil, nil, err
+	}
+	if len(from) > 0 && fcs.Metadata.Hash != "" {
+		if fcs.Metadata.Hash, err = fcs.ComputeHash(); err != nil {
+			return nil, nil, fmt.Errorf("failed to rehash migrated FCS: %w", err)
+		}
+	}
+	return &fcs, from, nil
+}
+
+// migrate applies migrations to a document until it reaches current
+func migrate(data []byte, current string, migrations []Migration) ([]byte, []string, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+
+	version := baseFCSVersion
+	if raw, ok := doc["schema_version"]; ok {
+		s, isString := raw.(string)
+		if !isString {
+			return nil, nil, fmt.Errorf("schema_version must be a string, not %v", raw)
+		}
+		if s == current {
+			return data, nil, nil
+		}
+		if s != "" {
+			version = s
+		}
+	}
+	if newer(version, current) {
+		return nil, nil, fmt.Errorf("schema version %s is newer than %s, the latest this version of gocreator reads; upgrade gocreator", version, current)
+	}
+
+	// Documents written before schema_version existed get the version stamped
+	doc["schema_version"] = version
+	var from []string
+	for version != current {
+		step, ok := findMigration(migrations, version)
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported schema version %s: no migration to %s", version, current)
+		}
+		if err := step.Apply(doc); err != nil {
+			return nil, nil, fmt.Errorf("failed to migrate schema version %s to %s: %w", step.From, step.To, err)
+		}
+		from = append(from, version)
+		version = step.To
+		doc["schema_version"] = version
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode migrated document: %w", err)
+	}
+	return migrated, from, nil
+}
+
+// findMigration returns the migration starting at version
+func findMigration(migrations []Migration, version string) (Migration, bool) {
+	for _, m := range migrations {
+		if m.From == version {
+			return m, true
+		}
+	}
+	return Migration{}, false
+}
+
+// newer reports whether dotted version a is newer than b. Versions that do
+// not parse as numbers are never newer.
+func newer(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		an, bn := 0, 0
+		var err error
+		if i < len(as) {
+			if an, err = strconv.Atoi(as[i]); err != nil {
+				return false
+			}
+		}
+		if i < len(bs) {
+			if bn, err = strconv.Atoi(bs[i]); err != nil {
+				return false
+			}
+		}
+		if an != bn {
+			return an > bn
+		}
+	}
+	return false
+}
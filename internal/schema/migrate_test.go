@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// renameOriginalSpec stands in for a migration: documents before 1.0 called
+// original_spec_id "spec_id"
+func renameOriginalSpec(doc map[string]interface{}) error {
+	id, ok := doc["spec_id"]
+	if !ok {
+		return fmt.Errorf("no spec_id")
+	}
+	doc["original_spec_id"] = id
+	delete(doc, "spec_id")
+	return nil
+}
+
+func TestMigrateFCS(t *testing.T) {
+	current := []byte(`{"schema_version": "1.0", "id": "fcs-1"}`)
+	migrated, from, err := MigrateFCS(current)
+	require.NoError(t, err)
+	assert.Equal(t, current, migrated, "documents of the current version are returned as they are")
+	assert.Empty(t, from)
+
+	migrated, from, err = MigrateFCS([]byte(`{"id": "fcs-1"}`))
+	require.NoError(t, err)
+	assert.Empty(t, from)
+	assert.JSONEq(t, `{"schema_version": "1.0", "id": "fcs-1"}`, string(migrated), "documents without a version are 1.0")
+
+	_, _, err = MigrateFCS([]byte(`{"schema_version": "1.10"}`))
+	assert.ErrorContains(t, err, "newer than 1.0")
+	assert.ErrorContains(t, err, "upgrade gocreator")
+
+	_, _, err = MigrateFCS([]byte(`{"schema_version": 1}`))
+	assert.ErrorContains(t, err, "schema_version must be a string")
+}
+
+func TestMigrate_Chain(t *testing.T) {
+	migrations := []Migration{
+		{From: "0.9", To: "1.0", Apply: renameOriginalSpec},
+		{From: "0.8", To: "0.9", Apply: func(doc map[string]interface{}) error {
+			doc["spec_id"] = doc["source"]
+			delete(doc, "source")
+			return nil
+		}},
+	}
+
+	migrated, from, err := migrate([]byte(`{"schema_version": "0.8", "source": "spec"}`), "1.0", migrations)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0.8", "0.9"}, from)
+	assert.JSONEq(t, `{"schema_version": "1.0", "original_spec_id": "spec"}`, string(migrated))
+
+	_, _, err = migrate([]byte(`{"schema_version": "0.7"}`), "1.0", migrations)
+	assert.ErrorContains(t, err, "unsupported schema version 0.7")
+
+	_, _, err = migrate([]byte(`{"schema_version": "0.9"}`), "1.0", migrations)
+	assert.ErrorContains(t, err, "failed to migrate schema version 0.9 to 1.0: no spec_id")
+}
+
+func TestLoadFCS_RehashesMigratedFCS(t *testing.T) {
+	saved := fcsMigrations
+	fcsMigrations = []Migration{{From: "0.9", To: models.FCSSchemaVersion, Apply: renameOriginalSpec}}
+	t.Cleanup(func() { fcsMigrations = saved })
+
+	old := map[string]interface{}{
+		"schema_version": "0.9",
+		"id":             "fcs-1",
+		"spec_id":        "spec",
+		"metadata":       map[string]interface{}{"hash": "computed-by-an-old-version"},
+	}
+	data, err := json.Marshal(old)
+	require.NoError(t, err)
+
+	fcs, from, err := LoadFCS(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0.9"}, from)
+	assert.Equal(t, "spec", fcs.OriginalSpecID)
+	assert.Equal(t, models.FCSSchemaVersion, fcs.SchemaVersion)
+	assert.NoError(t, fcs.Validate(), "the hash matches the migrated content")
+}
@@ -0,0 +1,254 @@
+// Package schema provides the JSON Schemas of the documents GoCreator reads
+// and writes, the Final Clarified Specification and the generation plan, and
+// of the structured responses it asks LLMs for, validation of documents
+// against them, and the migration of FCS documents written by older versions
+// to the current schema version.
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// Draft is the JSON Schema dialect of the generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// baseID prefixes the $id of every generated schema
+const baseID = "https://github.com/dshills/gocreator/schemas/"
+
+// Schema is a JSON Schema, limited to the keywords the generated schemas use
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+
+	// reject makes the schema match nothing; it is written as false
+	reject bool
+}
+
+// rejectAll is the schema of properties an object does not declare
+var rejectAll = &Schema{reject: true}
+
+// MarshalJSON writes a rejecting schema as false
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.reject {
+		return []byte("false"), nil
+	}
+	type plain Schema
+	return json.Marshal((*plain)(s))
+}
+
+// Types lists the JSON types a schema allows
+type Types []string
+
+// MarshalJSON writes a single type as a string rather than a list
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// FCS returns the JSON Schema of a Final Clarified Specification of the
+// current schema version
+func FCS() *Schema {
+	s := reflectSchema(reflect.TypeOf(models.FinalClarifiedSpecification{}))
+	s.ID = baseID + "fcs.schema.json"
+	s.Title = "GoCreator Final Clarified Specification"
+	s.Properties["schema_version"].Enum = []string{models.FCSSchemaVersion}
+	return s
+}
+
+// Plan returns the JSON Schema of a generation plan of the current schema
+// version
+func Plan() *Schema {
+	s := reflectSchema(reflect.TypeOf(models.GenerationPlan{}))
+	s.ID = baseID + "plan.schema.json"
+	s.Title = "GoCreator Generation Plan"
+	s.Properties["schema_version"].Enum = []string{models.PlanSchemaVersion}
+	return s
+}
+
+// PlanResponse returns the JSON Schema of the plan the planner asks the LLM
+// for: the file tree and phases of a generation plan, which the planner
+// completes with its metadata
+func PlanResponse() *Schema {
+	s := Plan()
+	s.ID = baseID + "plan-response.schema.json"
+	s.Title = "GoCreator Plan Response"
+	s.Properties = map[string]*Schema{
+		"file_tree": s.Properties["file_tree"],
+		"phases":    s.Properties["phases"],
+	}
+	s.Required = []string{"file_tree", "phases"}
+	return s
+}
+
+// Ambiguities returns the JSON Schema of the ambiguities the clarifier asks
+// the LLM to find in a specification
+func Ambiguities() *Schema {
+	s := reflectSchema(reflect.TypeOf(struct {
+		Ambiguities []models.Ambiguity `json:"ambiguities"`
+	}{}))
+	s.ID = baseID + "ambiguities.schema.json"
+	s.Title = "GoCreator Specification Ambiguities"
+	ambiguity := s.Defs["Ambiguity"]
+	ambiguity.Properties["type"].Enum = []string{
+		"missing_constraint", "conflict", "unclear_requirement", "ambiguous_terminology", "underspecified_feature",
+	}
+	ambiguity.Properties["severity"].Enum = []string{"critical", "important", "minor"}
+	return s
+}
+
+// Questions returns the JSON Schema of the clarification questions the
+// clarifier asks the LLM for. Questions get their ID and answer later, so
+// the LLM provides neither, and each offers two to four options.
+func Questions() *Schema {
+	s := reflectSchema(reflect.TypeOf(struct {
+		Questions []models.Question `json:"questions"`
+	}{}))
+	s.ID = baseID + "questions.schema.json"
+	s.Title = "GoCreator Clarification Questions"
+	question := s.Defs["Question"]
+	delete(question.Properties, "id")
+	delete(question.Properties, "user_answer")
+	question.Required = slices.DeleteFunc(question.Required, func(name string) bool { return name == "id" })
+	minOptions, maxOptions := 2, 4
+	question.Properties["options"].MinItems = &minOptions
+	question.Properties["options"].MaxItems = &maxOptions
+	return s
+}
+
+// reflectSchema derives the schema of the JSON encoding of a struct type: its
+// properties come from the json tags, every property without omitempty is
+// required, and properties not declared are rejected. Named struct types
+// other than the root become definitions.
+func reflectSchema(root reflect.Type) *Schema {
+	r := &reflector{defs: make(map[string]*Schema)}
+	s := r.object(root)
+	s.SchemaURI = Draft
+	if len(r.defs) > 0 {
+		s.Defs = r.defs
+	}
+	return s
+}
+
+// reflector collects the definitions of the struct types it visits
+type reflector struct {
+	defs map[string]*Schema
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor returns the schema of the JSON encoding of a Go type
+func (r *reflector) schemaFor(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		return nullable(r.schemaFor(t.Elem()))
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices encode as base64 strings
+			return &Schema{Type: Types{"string"}}
+		}
+		return &Schema{Type: Types{"array", "null"}, Items: r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object", "null"}, AdditionalProperties: r.schemaFor(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if name == "" {
+			return r.object(t)
+		}
+		if _, ok := r.defs[name]; !ok {
+			// Reserve the name first so recursive types terminate
+			r.defs[name] = &Schema{}
+			*r.defs[name] = *r.object(t)
+		}
+		return &Schema{Ref: "#/$defs/" + name}
+	}
+	// Interfaces hold any value
+	return &Schema{}
+}
+
+// object returns the schema of a struct's fields, including those of
+// embedded structs
+func (r *reflector) object(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 Types{"object"},
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: rejectAll,
+	}
+	r.addFields(s, t)
+	sort.Strings(s.Required)
+	return s
+}
+
+// addFields adds the exported fields of a struct to an object schema
+func (r *reflector) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			r.addFields(s, field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = r.schemaFor(field.Type)
+		if !slices.Contains(strings.Split(options, ","), "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// nullable extends a schema to accept null
+func nullable(s *Schema) *Schema {
+	switch {
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: Types{"null"}}}}
+	case len(s.Type) == 0:
+		return s
+	case !slices.Contains(s.Type, "null"):
+		s.Type = append(s.Type, "null")
+	}
+	return s
+}
@@ -0,0 +1,120 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFiles(t *testing.T) {
+	for name, s := range map[string]*Schema{"fcs.schema.json": FCS(), "plan.schema.json": Plan()} {
+		t.Run(name, func(t *testing.T) {
+			want, err := json.MarshalIndent(s, "", "  ")
+			require.NoError(t, err)
+			got, err := os.ReadFile(filepath.Join("..", "..", "schemas", name))
+			require.NoError(t, err)
+			assert.Equal(t, string(want)+"\n", string(got),
+				"schemas/%s is stale; regenerate it with gocreator validate-fcs --print-schema", name)
+		})
+	}
+}
+
+func TestFCS_AcceptsWrittenFCS(t *testing.T) {
+	fcs := models.FinalClarifiedSpecification{
+		SchemaVersion: models.FCSSchemaVersion,
+		ID:            "fcs-1",
+		Metadata:      models.FCSMetadata{CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Requirements: models.Requirements{
+			Functional: []models.FunctionalRequirement{{ID: "FR-001", Description: "List users"}},
+		},
+		Architecture: models.Architecture{
+			Packages: []models.Package{{Name: "store", Path: "internal/store"}},
+		},
+		DataModel: models.DataModel{
+			Entities: []models.Entity{{Name: "User", Package: "store", Attributes: map[string]string{"ID": "string"}}},
+		},
+		BuildConfig: models.BuildConfig{
+			GoVersion: "1.22",
+			Modules:   []models.ModuleConfig{{Dir: "api", Path: "example.com/api"}},
+		},
+		FileTree: &models.FileTree{Root: ".", Files: []models.File{{Path: "internal/store/store.go"}}},
+	}
+	data, err := json.Marshal(fcs)
+	require.NoError(t, err)
+	assert.NoError(t, FCS().Validate(data))
+
+	plan := models.GenerationPlan{
+		SchemaVersion: models.PlanSchemaVersion,
+		ID:            "plan-1",
+		Phases: []models.GenerationPhase{{Name: "packages", Order: 1, Tasks: []models.GenerationTask{{
+			ID: "store", Type: "generate_file", TargetPath: "internal/store/store.go", Inputs: map[string]interface{}{"lines": 40},
+		}}}},
+	}
+	data, err = json.Marshal(plan)
+	require.NoError(t, err)
+	assert.NoError(t, Plan().Validate(data))
+}
+
+func TestFCS_ReportsViolations(t *testing.T) {
+	doc := `{
+		"schema_version": "1.0",
+		"id": 7,
+		"version": "1.0",
+		"original_spec_id": "spec",
+		"metadata": {"created_at": "yesterday", "original_spec": "spec", "hash": ""},
+		"requirements": {"functional": [{"id": "FR-001"}]},
+		"architecture": {"packages": null, "dependencies": null, "patterns": null},
+		"file_tree": {"root": ".", "files": [{"path": "main.go", "generatedBy": "llm"}]}
+	}`
+
+	err := FCS().Validate([]byte(doc))
+	var invalid *ValidationError
+	require.True(t, errors.As(err, &invalid), "got %v", err)
+
+	var got []string
+	for _, v := range invalid.Violations {
+		got = append(got, v.String())
+	}
+	assert.Equal(t, []string{
+		"/file_tree/files/0/generatedBy: unknown property",
+		"/id: must be string, not number",
+		"/metadata/created_at: must be an RFC 3339 date-time",
+		"/requirements/functional/0: missing required property \"description\"",
+	}, got)
+}
+
+func TestFCS_RejectsOtherSchemaVersions(t *testing.T) {
+	err := FCS().Validate([]byte(`{"schema_version": "2.0"}`))
+	var invalid *ValidationError
+	require.True(t, errors.As(err, &invalid))
+	assert.Contains(t, invalid.Violations, Violation{Path: "/schema_version", Message: `must be one of "1.0"`})
+
+	assert.ErrorContains(t, FCS().Validate([]byte(`{`)), "invalid JSON")
+}
+
+func TestResponseSchemas(t *testing.T) {
+	assert.NoError(t, PlanResponse().Validate([]byte(`{"file_tree": {"root": "."}, "phases": []}`)))
+	assert.ErrorContains(t, PlanResponse().Validate([]byte(`{"file_tree": {"root": "."}, "phases": [], "id": "plan-1"}`)),
+		"/id: unknown property", "the planner sets the plan's metadata")
+
+	assert.NoError(t, Ambiguities().Validate([]byte(`{"ambiguities": [{"type": "conflict", "description": "d", "severity": "minor"}]}`)))
+	assert.ErrorContains(t, Ambiguities().Validate([]byte(`{"ambiguities": [{"type": "conflict", "description": "d", "severity": "high"}]}`)),
+		`/ambiguities/0/severity: must be one of "critical", "important", "minor"`)
+
+	question := func(options string) []byte {
+		return []byte(`{"questions": [{"question": "q", "options": [` + options + `]}]}`)
+	}
+	assert.NoError(t, Questions().Validate(question(`{"label": "a"}, {"label": "b"}`)))
+	assert.ErrorContains(t, Questions().Validate(question(`{"label": "a"}`)), "must have at least 2 items, not 1")
+	assert.ErrorContains(t, Questions().Validate(question(`{"label": "a"}, {"label": "b"}, {"label": "c"}, {"label": "d"}, {"label": "e"}`)),
+		"must have at most 4 items, not 5")
+	assert.ErrorContains(t, Questions().Validate([]byte(`{"questions": [{"id": "q1", "question": "q", "options": []}]}`)),
+		"/questions/0/id: unknown property")
+}
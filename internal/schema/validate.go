@@ -0,0 +1,215 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Violation is a place where a document does not match its schema
+type Violation struct {
+	Path    string // JSON Pointer to the offending value, "" for the document
+	Message string
+}
+
+// String renders the violation as "path: message", with "/" as the path of
+// the document itself
+func (v Violation) String() string {
+	path := v.Path
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + v.Message
+}
+
+// ValidationError lists every violation of a document against its schema
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = v.String()
+	}
+	return fmt.Sprintf("document does not match the schema (%d violations): %s", len(e.Violations), strings.Join(lines, "; "))
+}
+
+// Validate checks a JSON document against the schema and returns a
+// *ValidationError listing every violation, sorted by path
+func (s *Schema) Validate(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	v := &validator{root: s}
+	v.check(s, doc, "")
+	if len(v.violations) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.violations, func(i, j int) bool { return v.violations[i].Path < v.violations[j].Path })
+	return &ValidationError{Violations: v.violations}
+}
+
+// validator walks a decoded document, resolving references against the root
+type validator struct {
+	root       *Schema
+	violations []Violation
+}
+
+func (v *validator) report(path, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// check validates value at path against s
+func (v *validator) check(s *Schema, value interface{}, path string) {
+	s, ok := v.resolve(s)
+	if !ok {
+		v.report(path, "unresolved schema reference %s", s.Ref)
+		return
+	}
+
+	if len(s.AnyOf) > 0 {
+		// Report the violations inside the option the value's type fits, if any
+		var closest []Violation
+		for _, option := range s.AnyOf {
+			branch := &validator{root: v.root}
+			branch.check(option, value, path)
+			if len(branch.violations) == 0 {
+				return
+			}
+			if resolved, ok := v.resolve(option); ok && closest == nil && fitsType(resolved, value) {
+				closest = branch.violations
+			}
+		}
+		if closest == nil {
+			v.report(path, "matches none of the allowed schemas")
+		}
+		v.violations = append(v.violations, closest...)
+		return
+	}
+
+	if !fitsType(s, value) {
+		v.report(path, "must be %s, not %s", strings.Join(s.Type, " or "), typeOf(value))
+		return
+	}
+	if len(s.Enum) > 0 {
+		if str, ok := value.(string); !ok || !slices.Contains(s.Enum, str) {
+			v.report(path, "must be one of %s", strings.Join(quoteAll(s.Enum), ", "))
+		}
+	}
+	if s.Format == "date-time" {
+		if str, ok := value.(string); ok {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				v.report(path, "must be an RFC 3339 date-time")
+			}
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				v.report(path, "missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := path + "/" + escapePointer(name)
+			switch property, ok := s.Properties[name]; {
+			case ok:
+				v.check(property, value[name], child)
+			case s.AdditionalProperties == nil:
+			case s.AdditionalProperties.reject:
+				v.report(child, "unknown property")
+			default:
+				v.check(s.AdditionalProperties, value[name], child)
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(value) < *s.MinItems {
+			v.report(path, "must have at least %d items, not %d", *s.MinItems, len(value))
+		}
+		if s.MaxItems != nil && len(value) > *s.MaxItems {
+			v.report(path, "must have at most %d items, not %d", *s.MaxItems, len(value))
+		}
+		if s.Items != nil {
+			for i, item := range value {
+				v.check(s.Items, item, path+"/"+strconv.Itoa(i))
+			}
+		}
+	}
+}
+
+// resolve follows a reference to its definition
+func (v *validator) resolve(s *Schema) (*Schema, bool) {
+	if s.Ref == "" {
+		return s, true
+	}
+	def, ok := v.root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+	if !ok {
+		return s, false
+	}
+	return def, true
+}
+
+// fitsType reports whether a decoded JSON value is of a type the schema allows
+func fitsType(s *Schema, value interface{}) bool {
+	return len(s.Type) == 0 || slices.ContainsFunc(s.Type, func(t string) bool { return hasType(value, t) })
+}
+
+// hasType reports whether a decoded JSON value is of a JSON Schema type
+func hasType(value interface{}, t string) bool {
+	if t == "integer" {
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := number.Int64()
+		return err == nil
+	}
+	return typeOf(value) == t
+}
+
+// typeOf returns the JSON Schema type of a decoded JSON value
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// escapePointer escapes a property name as a JSON Pointer reference token
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return quoted
+}
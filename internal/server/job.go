@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// Status is the stage of a job
+type Status string
+
+const (
+	// StatusClarifying means the spec's clarification questions are being generated
+	StatusClarifying Status = "clarifying"
+
+	// StatusAwaitingAnswers means the job waits for answers to its questions
+	StatusAwaitingAnswers Status = "awaiting_answers"
+
+	// StatusClarified means the FCS is built and generation can be triggered
+	StatusClarified Status = "clarified"
+
+	// StatusQueued means generation waits for a free slot
+	StatusQueued Status = "queued"
+
+	// StatusGenerating means generation is running
+	StatusGenerating Status = "generating"
+
+	// StatusCompleted means generation finished
+	StatusCompleted Status = "completed"
+
+	// StatusFailed means clarification or generation failed
+	StatusFailed Status = "failed"
+
+	// StatusCanceled means the job was canceled, or the server shut down
+	StatusCanceled Status = "canceled"
+)
+
+// Terminal reports whether a job in status s is finished
+func (s Status) Terminal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCanceled
+}
+
+// EventJobStatus is the type of the event recorded when a job changes status
+const EventJobStatus models.EventType = "job_status"
+
+// JobInfo is the JSON representation of a job
+type JobInfo struct {
+	ID        string    `json:"id"`
+	Status    Status    `json:"status"`
+	SpecID    string    `json:"spec_id"`
+	Questions int       `json:"questions"`
+	FCSID     string    `json:"fcs_id,omitempty"`
+	OutputDir string    `json:"output_dir,omitempty"`
+	RunID     string    `json:"run_id,omitempty"`
+	Files     int       `json:"files,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// job is a submitted spec on its way through clarification and generation.
+// Its progress events are kept so a client can follow it from the start at
+// any time.
+type job struct {
+	id        string
+	outputDir string
+	ctx       context.Context // Canceled when the job is or the server shuts down
+	cancel    context.CancelCauseFunc
+
+	mu       sync.Mutex
+	status   Status
+	err      string
+	generate bool // Generate once clarified
+	started  bool // Generation has started, so outputDir holds its files
+	spec     *models.InputSpecification
+	request  *models.ClarificationRequest
+	fcs      *models.FinalClarifiedSpecification
+	output   *models.GenerationOutput
+	created  time.Time
+	updated  time.Time
+	events   []models.ProgressEvent
+	changed  chan struct{} // Closed and replaced when events are recorded
+}
+
+// setStatus moves the job to status, recording the change as an event. err
+// explains the status, if it needs explaining. A finished job keeps its
+// status.
+func (j *job) setStatus(status Status, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.setStatusLocked(status, err)
+}
+
+func (j *job) setStatusLocked(status Status, err error) {
+	if j.status.Terminal() {
+		return
+	}
+	j.status = status
+	j.err = ""
+	data := map[string]interface{}{"status": string(status)}
+	if err != nil {
+		j.err = err.Error()
+		data["error"] = j.err
+	}
+	j.recordLocked(models.ProgressEvent{Type: EventJobStatus, Timestamp: time.Now(), Data: data})
+}
+
+// record appends a progress event of the job's generation
+func (j *job) record(event models.ProgressEvent) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.recordLocked(event)
+}
+
+func (j *job) recordLocked(event models.ProgressEvent) {
+	j.events = append(j.events, event)
+	j.updated = event.Timestamp
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// eventsSince returns the events from index next on, a channel closed when
+// more are recorded, and whether the job is finished so no more will be
+func (j *job) eventsSince(next int) ([]models.ProgressEvent, <-chan struct{}, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var events []models.ProgressEvent
+	if next < len(j.events) {
+		events = append(events, j.events[next:]...)
+	}
+	return events, j.changed, j.status.Terminal()
+}
+
+// info returns the job's JSON representation
+func (j *job) info() JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	info := JobInfo{
+		ID:        j.id,
+		Status:    j.status,
+		SpecID:    j.spec.ID,
+		Error:     j.err,
+		CreatedAt: j.created,
+		UpdatedAt: j.updated,
+	}
+	if j.request != nil {
+		info.Questions = len(j.request.Questions)
+	}
+	if j.fcs != nil {
+		info.FCSID = j.fcs.ID
+	}
+	if j.started {
+		info.OutputDir = j.outputDir
+	}
+	if j.output != nil {
+		info.RunID = j.output.RunID
+		info.Files = len(j.output.Files)
+	}
+	return info
+}
@@ -0,0 +1,648 @@
+// Package server exposes GoCreator as a long-running HTTP API. Clients submit
+// specifications as jobs, answer their clarification questions, trigger
+// generation, and follow its progress as a stream of server-sent events.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dshills/gocreator/internal/clarify"
+	"github.com/dshills/gocreator/internal/models"
+	specparser "github.com/dshills/gocreator/internal/spec"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// maxRequestBytes caps the size of a request body, such as a submitted spec
+const maxRequestBytes = 10 << 20
+
+// keepAliveInterval is how often an idle event stream sends a comment, so
+// proxies keep it open through a long phase
+const keepAliveInterval = 15 * time.Second
+
+// errJobCanceled is the cause of a job canceled through the API
+var errJobCanceled = errors.New("job canceled")
+
+// errMaxDuration is the cause of a generation that ran past Config.MaxDuration
+var errMaxDuration = errors.New("generation exceeded limits.max_duration")
+
+// ParseFunc parses and validates the content of a spec submitted under name,
+// which may be empty
+type ParseFunc func(name string, format models.SpecFormat, content string) (*models.InputSpecification, error)
+
+// GenerateFunc generates the code of fcs into outputDir, sending progress
+// events to events. It must not send once it has returned.
+type GenerateFunc func(ctx context.Context, fcs *models.FinalClarifiedSpecification, outputDir string, events chan<- models.ProgressEvent) (*models.GenerationOutput, error)
+
+// Config configures the server
+type Config struct {
+	// Engine generates clarification questions and builds the FCS
+	Engine clarify.Engine
+
+	// Parse parses submitted specs. It must not read files the client names,
+	// such as imports, outside a directory meant for them, and refuses them
+	// with spec.ErrImportNotAllowed (see spec.ParseAndValidateWithinRoot).
+	Parse ParseFunc
+
+	// Generate runs the generation of a job
+	Generate GenerateFunc
+
+	// OutputRoot holds the generated code, in a directory named after each job
+	OutputRoot string
+
+	// Token is the bearer token API requests must carry (empty: no auth)
+	Token string
+
+	// MaxParallel is how many jobs generate at once; others queue (default: 1)
+	MaxParallel int
+
+	// ClarifyTimeout bounds the clarification of a job (0: no limit)
+	ClarifyTimeout time.Duration
+
+	// MaxDuration bounds the generation of a job (0: no limit)
+	MaxDuration time.Duration
+}
+
+// Server serves the jobs API. Jobs are kept in memory and run under the
+// context the server was created with rather than that of the request that
+// started them.
+type Server struct {
+	config Config
+	ctx    context.Context
+	slots  chan struct{} // Held by each running generation
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// NewServer creates a server whose jobs run until ctx is canceled
+func NewServer(ctx context.Context, config Config) (*Server, error) {
+	if config.Engine == nil {
+		return nil, fmt.Errorf("clarification engine is required")
+	}
+	if config.Parse == nil {
+		return nil, fmt.Errorf("spec parser is required")
+	}
+	if config.Generate == nil {
+		return nil, fmt.Errorf("generator is required")
+	}
+	if config.OutputRoot == "" {
+		return nil, fmt.Errorf("output root is required")
+	}
+	if config.MaxParallel <= 0 {
+		config.MaxParallel = 1
+	}
+
+	return &Server{
+		config: config,
+		ctx:    ctx,
+		slots:  make(chan struct{}, config.MaxParallel),
+		jobs:   make(map[string]*job),
+	}, nil
+}
+
+// Wait blocks until the clarification and generation of every job have
+// stopped, which they do once the server's context is canceled
+func (s *Server) Wait() {
+	s.wg.Wait()
+}
+
+// Handler returns the HTTP handler of the API
+func (s *Server) Handler() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("POST /v1/jobs", s.handleSubmit)
+	api.HandleFunc("GET /v1/jobs", s.handleList)
+	api.HandleFunc("GET /v1/jobs/{id}", s.handleGet)
+	api.HandleFunc("DELETE /v1/jobs/{id}", s.handleCancel)
+	api.HandleFunc("GET /v1/jobs/{id}/questions", s.handleQuestions)
+	api.HandleFunc("POST /v1/jobs/{id}/answers", s.handleAnswers)
+	api.HandleFunc("POST /v1/jobs/{id}/generate", s.handleGenerate)
+	api.HandleFunc("GET /v1/jobs/{id}/events", s.handleEvents)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.Handle("/v1/", s.authenticate(api))
+	return mux
+}
+
+// authenticate requires the configured bearer token, if any
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.config.Token == "" {
+		return next
+	}
+	want := []byte("Bearer " + s.config.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gocreator"`)
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// submitRequest is the body of POST /v1/jobs
+type submitRequest struct {
+	Spec       string                   `json:"spec"`
+	Name       string                   `json:"name,omitempty"` // File name; its extension gives the format
+	Format     models.SpecFormat        `json:"format,omitempty"`
+	Answers    map[string]models.Answer `json:"answers,omitempty"`
+	Autonomous bool                     `json:"autonomous,omitempty"` // Resolve ambiguities without asking
+	Generate   bool                     `json:"generate,omitempty"`   // Generate once clarified
+}
+
+// answersRequest is the body of POST /v1/jobs/{id}/answers
+type answersRequest struct {
+	Answers map[string]models.Answer `json:"answers"`
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var req submitRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Spec) == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("spec is required"))
+		return
+	}
+	format, err := specFormat(req.Name, req.Format)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	spec, err := s.config.Parse(req.Name, format, req.Spec)
+	if errors.Is(err, specparser.ErrImportNotAllowed) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Errorf("specification validation failed: %w", err))
+		return
+	}
+
+	j := s.newJob(spec, req.Generate)
+	log.Info().Str("job_id", j.id).Str("spec_id", spec.ID).Msg("Job submitted")
+	s.start(func() { s.clarify(j, req.Autonomous, req.Answers) })
+
+	w.Header().Set("Location", "/v1/jobs/"+j.id)
+	writeJSON(w, http.StatusAccepted, j.info())
+}
+
+func (s *Server) handleList(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	infos := make([]JobInfo, 0, len(jobs))
+	for _, j := range jobs {
+		infos = append(infos, j.info())
+	}
+	slices.SortFunc(infos, func(a, b JobInfo) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	writeJSON(w, http.StatusOK, map[string][]JobInfo{"jobs": infos})
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	if j := s.lookup(w, r); j != nil {
+		writeJSON(w, http.StatusOK, j.info())
+	}
+}
+
+// handleCancel cancels an unfinished job, and forgets a finished one. Its
+// generated code is kept.
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+
+	j.mu.Lock()
+	if j.status.Terminal() {
+		j.mu.Unlock()
+		s.mu.Lock()
+		delete(s.jobs, j.id)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	j.cancel(errJobCanceled)
+	// A job waiting on the client stops now; a running one once its work
+	// notices the cancellation
+	if j.status == StatusAwaitingAnswers || j.status == StatusClarified {
+		j.setStatusLocked(StatusCanceled, errJobCanceled)
+	}
+	j.mu.Unlock()
+
+	log.Info().Str("job_id", j.id).Msg("Job canceled")
+	writeJSON(w, http.StatusAccepted, j.info())
+}
+
+func (s *Server) handleQuestions(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+
+	j.mu.Lock()
+	status, request := j.status, j.request
+	j.mu.Unlock()
+	if status == StatusClarifying {
+		writeError(w, http.StatusConflict, fmt.Errorf("questions are still being generated"))
+		return
+	}
+
+	// An autonomous job asks none
+	questions := []models.Question{}
+	if request != nil {
+		questions = request.Questions
+	}
+	writeJSON(w, http.StatusOK, map[string][]models.Question{"questions": questions})
+}
+
+func (s *Server) handleAnswers(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+	var req answersRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	j.mu.Lock()
+	if j.status != StatusAwaitingAnswers {
+		status := j.status
+		j.mu.Unlock()
+		writeError(w, http.StatusConflict, fmt.Errorf("job is %s, not awaiting answers", status))
+		return
+	}
+	err := s.applyAnswersLocked(j, req.Answers)
+	j.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, j.info())
+}
+
+// handleGenerate queues a clarified job for generation, or marks a job still
+// being clarified to be queued once it is
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+
+	j.mu.Lock()
+	status := j.status
+	switch status {
+	case StatusClarified:
+		s.queueLocked(j)
+	case StatusClarifying, StatusAwaitingAnswers:
+		j.generate = true
+	default:
+		j.mu.Unlock()
+		writeError(w, http.StatusConflict, fmt.Errorf("job is %s", status))
+		return
+	}
+	j.mu.Unlock()
+	writeJSON(w, http.StatusAccepted, j.info())
+}
+
+// handleEvents streams the job's events as server-sent events: those
+// recorded so far, then each as it is recorded, until the job finishes. The
+// ID of an event is its index, so a client reconnecting with Last-Event-ID
+// resumes after the last event it received. Streams also end when the
+// server shuts down.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	next := 0
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+		id, err := strconv.Atoi(lastID)
+		if err != nil || id < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid Last-Event-ID %q", lastID))
+			return
+		}
+		next = id + 1
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		events, changed, finished := j.eventsSince(next)
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Warn().Err(err).Str("job_id", j.id).Msg("Failed to encode event")
+				data = []byte("{}")
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", next, event.Type, data); err != nil {
+				return
+			}
+			next++
+		}
+		flusher.Flush()
+		if finished {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// lookup returns the job named in the request path, or writes a 404
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) *job {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	j := s.jobs[id]
+	s.mu.Unlock()
+	if j == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %s not found", id))
+	}
+	return j
+}
+
+// newJob registers a job for spec
+func (s *Server) newJob(spec *models.InputSpecification, generate bool) *job {
+	id := uuid.New().String()
+	ctx, cancel := context.WithCancelCause(s.ctx)
+	now := time.Now()
+	j := &job{
+		id:        id,
+		outputDir: filepath.Join(s.config.OutputRoot, id),
+		ctx:       ctx,
+		cancel:    cancel,
+		generate:  generate,
+		spec:      spec,
+		created:   now,
+		updated:   now,
+		changed:   make(chan struct{}),
+	}
+	j.setStatus(StatusClarifying, nil)
+
+	s.mu.Lock()
+	s.jobs[id] = j
+	s.mu.Unlock()
+	return j
+}
+
+// start runs work in the background, counted by Wait
+func (s *Server) start(work func()) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		work()
+	}()
+}
+
+// clarify generates the job's questions, or with autonomous set resolves the
+// spec's ambiguities without asking. Answers submitted with the spec are
+// applied at once; when they do not answer every question, the job waits for
+// answers as if there were none.
+func (s *Server) clarify(j *job, autonomous bool, answers map[string]models.Answer) {
+	ctx := j.ctx
+	if s.config.ClarifyTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.ClarifyTimeout)
+		defer cancel()
+	}
+
+	if autonomous {
+		fcs, err := s.config.Engine.Clarify(ctx, j.spec, false)
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if j.ctx.Err() != nil {
+			j.stopLocked(nil)
+			return
+		}
+		if err != nil {
+			j.stopLocked(fmt.Errorf("clarification failed: %w", err))
+			return
+		}
+		s.clarifiedLocked(j, fcs)
+		return
+	}
+
+	request, err := s.config.Engine.GenerateRequest(ctx, j.spec)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.ctx.Err() != nil {
+		j.stopLocked(nil)
+		return
+	}
+	if err != nil {
+		j.stopLocked(fmt.Errorf("failed to generate clarification questions: %w", err))
+		return
+	}
+	j.request = request
+
+	if len(request.Questions) > 0 && answers == nil {
+		j.setStatusLocked(StatusAwaitingAnswers, nil)
+		return
+	}
+	if err := s.applyAnswersLocked(j, answers); err != nil {
+		j.setStatusLocked(StatusAwaitingAnswers, err)
+	}
+}
+
+// applyAnswersLocked builds the job's FCS from answers to its questions
+func (s *Server) applyAnswersLocked(j *job, answers map[string]models.Answer) error {
+	response := &models.ClarificationResponse{
+		SchemaVersion: j.request.SchemaVersion,
+		ID:            uuid.New().String(),
+		RequestID:     j.request.ID,
+		Answers:       answers,
+		AnsweredAt:    time.Now(),
+	}
+	if response.Answers == nil {
+		response.Answers = make(map[string]models.Answer)
+	}
+
+	fcs, err := s.config.Engine.ApplyAnswers(j.ctx, j.spec, j.request, response)
+	if err != nil {
+		return err
+	}
+	s.clarifiedLocked(j, fcs)
+	return nil
+}
+
+// clarifiedLocked records the job's FCS, and queues its generation if it
+// was asked for
+func (s *Server) clarifiedLocked(j *job, fcs *models.FinalClarifiedSpecification) {
+	if j.status.Terminal() {
+		return
+	}
+	j.fcs = fcs
+	j.setStatusLocked(StatusClarified, nil)
+	log.Info().Str("job_id", j.id).Str("fcs_id", fcs.ID).Msg("Job clarified")
+	if j.generate {
+		s.queueLocked(j)
+	}
+}
+
+// queueLocked queues the job's generation
+func (s *Server) queueLocked(j *job) {
+	j.setStatusLocked(StatusQueued, nil)
+	s.start(func() { s.runGeneration(j) })
+}
+
+// runGeneration generates the job's code once a slot is free, recording its
+// progress events
+func (s *Server) runGeneration(j *job) {
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-j.ctx.Done():
+		j.mu.Lock()
+		j.stopLocked(nil)
+		j.mu.Unlock()
+		return
+	}
+
+	j.mu.Lock()
+	if j.status.Terminal() {
+		j.mu.Unlock()
+		return
+	}
+	j.started = true
+	j.setStatusLocked(StatusGenerating, nil)
+	fcs := j.fcs
+	j.mu.Unlock()
+	log.Info().Str("job_id", j.id).Str("output_dir", j.outputDir).Msg("Generation started")
+
+	ctx := j.ctx
+	if s.config.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, s.config.MaxDuration, errMaxDuration)
+		defer cancel()
+	}
+
+	events := make(chan models.ProgressEvent, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range events {
+			// Streamed chunks of file content are too many to keep
+			if event.Type != models.EventFileStreaming {
+				j.record(event)
+			}
+		}
+	}()
+	output, err := s.config.Generate(ctx, fcs, j.outputDir, events)
+	close(events)
+	<-done
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.output = output
+	switch {
+	case err == nil:
+		j.setStatusLocked(StatusCompleted, nil)
+		log.Info().Str("job_id", j.id).Int("files", len(output.Files)).Msg("Generation completed")
+	case errors.Is(context.Cause(ctx), errMaxDuration):
+		j.setStatusLocked(StatusFailed, fmt.Errorf("code generation stopped: %w", errMaxDuration))
+	default:
+		j.stopLocked(fmt.Errorf("code generation failed: %w", err))
+	}
+}
+
+// stopLocked ends the job after err: it is canceled when its context is,
+// and failed otherwise
+func (j *job) stopLocked(err error) {
+	if j.ctx.Err() != nil {
+		j.setStatusLocked(StatusCanceled, context.Cause(j.ctx))
+		return
+	}
+	j.setStatusLocked(StatusFailed, err)
+	log.Error().Err(err).Str("job_id", j.id).Msg("Job failed")
+}
+
+// specFormat returns format, or without one the format given by the
+// extension of name
+func specFormat(name string, format models.SpecFormat) (models.SpecFormat, error) {
+	switch format {
+	case models.FormatYAML, models.FormatJSON, models.FormatMarkdown:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported format %q (must be yaml, json, or markdown)", format)
+	}
+
+	switch ext := filepath.Ext(name); ext {
+	case ".yaml", ".yml":
+		return models.FormatYAML, nil
+	case ".json":
+		return models.FormatJSON, nil
+	case ".md", ".markdown":
+		return models.FormatMarkdown, nil
+	case "":
+		return "", fmt.Errorf("format is required when the name has no extension")
+	default:
+		return "", fmt.Errorf("unsupported file extension: %s (must be .yaml, .json, or .md)", ext)
+	}
+}
+
+// decodeBody decodes the JSON request body into v, or writes a 400
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warn().Err(err).Msg("Failed to write response")
+	}
+}
+
+// writeError writes err as a JSON error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,307 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	specparser "github.com/dshills/gocreator/internal/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEngine asks the questions it is given and builds an FCS without an LLM
+type fakeEngine struct {
+	questions []models.Question
+}
+
+func (e *fakeEngine) Clarify(_ context.Context, spec *models.InputSpecification, _ bool) (*models.FinalClarifiedSpecification, error) {
+	return &models.FinalClarifiedSpecification{ID: "fcs-autonomous", OriginalSpecID: spec.ID}, nil
+}
+
+func (e *fakeEngine) AnalyzeOnly(context.Context, *models.InputSpecification) ([]models.Ambiguity, error) {
+	return nil, nil
+}
+
+func (e *fakeEngine) GenerateRequest(_ context.Context, spec *models.InputSpecification) (*models.ClarificationRequest, error) {
+	return &models.ClarificationRequest{SchemaVersion: "1.0", ID: "request-1", SpecID: spec.ID, Questions: e.questions}, nil
+}
+
+func (e *fakeEngine) ApplyAnswers(_ context.Context, spec *models.InputSpecification, request *models.ClarificationRequest, response *models.ClarificationResponse) (*models.FinalClarifiedSpecification, error) {
+	if err := response.ValidateAgainst(request); err != nil {
+		return nil, fmt.Errorf("invalid clarification response: %w", err)
+	}
+	return &models.FinalClarifiedSpecification{ID: "fcs-answered", OriginalSpecID: spec.ID}, nil
+}
+
+func parseFake(_ string, format models.SpecFormat, content string) (*models.InputSpecification, error) {
+	if strings.Contains(content, "invalid") {
+		return nil, fmt.Errorf("missing name")
+	}
+	return &models.InputSpecification{ID: "spec-1", Format: format, Content: content}, nil
+}
+
+// generateFake reports a phase and a streamed chunk, then writes one file
+func generateFake(_ context.Context, _ *models.FinalClarifiedSpecification, _ string, events chan<- models.ProgressEvent) (*models.GenerationOutput, error) {
+	events <- models.ProgressEvent{Type: models.EventPhaseStarted, Timestamp: time.Now(), Data: map[string]interface{}{"phase": "create_plan"}}
+	events <- models.ProgressEvent{Type: models.EventFileStreaming, Timestamp: time.Now(), Data: map[string]interface{}{"chunk": "package"}}
+	return &models.GenerationOutput{ID: "output-1", RunID: "run-1", Files: []models.GeneratedFile{{Path: "main.go"}}}, nil
+}
+
+func newTestServer(t *testing.T, config Config) *httptest.Server {
+	t.Helper()
+	if config.Engine == nil {
+		config.Engine = &fakeEngine{}
+	}
+	if config.Parse == nil {
+		config.Parse = parseFake
+	}
+	if config.Generate == nil {
+		config.Generate = generateFake
+	}
+	config.OutputRoot = t.TempDir()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	srv, err := NewServer(ctx, config)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		ts.Close()
+		cancel()
+		srv.Wait()
+	})
+	return ts
+}
+
+func doJSON(t *testing.T, method, url string, body interface{}, out interface{}) int {
+	t.Helper()
+	var reader bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reader).Encode(body))
+	}
+	req, err := http.NewRequest(method, url, &reader)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	if out != nil && resp.StatusCode < 300 && resp.StatusCode != http.StatusNoContent {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+func waitForStatus(t *testing.T, url string, status Status) JobInfo {
+	t.Helper()
+	var info JobInfo
+	require.Eventually(t, func() bool {
+		info = JobInfo{}
+		doJSON(t, http.MethodGet, url, nil, &info)
+		return info.Status == status
+	}, 5*time.Second, 10*time.Millisecond, "job never reached %s", status)
+	return info
+}
+
+// readEvents reads the job's event stream until the server ends it
+func readEvents(t *testing.T, url, lastEventID string) []string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url+"/events", nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	var id string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			events = append(events, id+" "+strings.TrimPrefix(line, "event: "))
+		}
+	}
+	return events
+}
+
+func TestServer_AnswerAndGenerate(t *testing.T) {
+	engine := &fakeEngine{questions: []models.Question{
+		{ID: "q1", Question: "Which database?", Options: []models.Option{{Label: "PostgreSQL"}, {Label: "SQLite"}}},
+		{ID: "q2", Question: "Which router?", Options: []models.Option{{Label: "chi"}, {Label: "net/http"}}},
+	}}
+	ts := newTestServer(t, Config{Engine: engine})
+
+	var info JobInfo
+	status := doJSON(t, http.MethodPost, ts.URL+"/v1/jobs", map[string]interface{}{"spec": "name: todo", "name": "todo.yaml"}, &info)
+	require.Equal(t, http.StatusAccepted, status)
+	jobURL := ts.URL + "/v1/jobs/" + info.ID
+
+	info = waitForStatus(t, jobURL, StatusAwaitingAnswers)
+	assert.Equal(t, 2, info.Questions)
+
+	var questions struct {
+		Questions []models.Question `json:"questions"`
+	}
+	require.Equal(t, http.StatusOK, doJSON(t, http.MethodGet, jobURL+"/questions", nil, &questions))
+	assert.Len(t, questions.Questions, 2)
+
+	// Every question must be answered
+	postgres, chi := "PostgreSQL", "chi"
+	status = doJSON(t, http.MethodPost, jobURL+"/answers", map[string]interface{}{
+		"answers": map[string]models.Answer{"q1": {SelectedOption: &postgres}},
+	}, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status = doJSON(t, http.MethodPost, jobURL+"/answers", map[string]interface{}{
+		"answers": map[string]models.Answer{"q1": {SelectedOption: &postgres}, "q2": {CustomAnswer: &chi}},
+	}, &info)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, StatusClarified, info.Status)
+	assert.Equal(t, "fcs-answered", info.FCSID)
+
+	require.Equal(t, http.StatusAccepted, doJSON(t, http.MethodPost, jobURL+"/generate", nil, nil))
+	events := readEvents(t, jobURL, "")
+	assert.Equal(t, []string{
+		"0 job_status", // clarifying
+		"1 job_status", // awaiting_answers
+		"2 job_status", // clarified
+		"3 job_status", // queued
+		"4 job_status", // generating
+		"5 phase_started",
+		"6 job_status", // completed
+	}, events, "streamed file content is not relayed")
+
+	info = waitForStatus(t, jobURL, StatusCompleted)
+	assert.Equal(t, "run-1", info.RunID)
+	assert.Equal(t, 1, info.Files)
+	assert.NotEmpty(t, info.OutputDir)
+
+	// A reconnecting client resumes after the last event it received
+	assert.Equal(t, []string{"5 phase_started", "6 job_status"}, readEvents(t, jobURL, "4"))
+}
+
+func TestServer_AutonomousGenerate(t *testing.T) {
+	ts := newTestServer(t, Config{})
+
+	var info JobInfo
+	status := doJSON(t, http.MethodPost, ts.URL+"/v1/jobs", map[string]interface{}{
+		"spec": "# Todo", "format": "markdown", "autonomous": true, "generate": true,
+	}, &info)
+	require.Equal(t, http.StatusAccepted, status)
+
+	info = waitForStatus(t, ts.URL+"/v1/jobs/"+info.ID, StatusCompleted)
+	assert.Equal(t, "fcs-autonomous", info.FCSID)
+	assert.Zero(t, info.Questions)
+
+	var list struct {
+		Jobs []JobInfo `json:"jobs"`
+	}
+	require.Equal(t, http.StatusOK, doJSON(t, http.MethodGet, ts.URL+"/v1/jobs", nil, &list))
+	require.Len(t, list.Jobs, 1)
+	assert.Equal(t, info.ID, list.Jobs[0].ID)
+}
+
+func TestServer_Cancel(t *testing.T) {
+	started := make(chan struct{})
+	ts := newTestServer(t, Config{Generate: func(ctx context.Context, _ *models.FinalClarifiedSpecification, _ string, _ chan<- models.ProgressEvent) (*models.GenerationOutput, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}})
+
+	var info JobInfo
+	doJSON(t, http.MethodPost, ts.URL+"/v1/jobs", map[string]interface{}{"spec": "name: todo", "format": "yaml", "generate": true}, &info)
+	jobURL := ts.URL + "/v1/jobs/" + info.ID
+	<-started
+
+	assert.Equal(t, http.StatusAccepted, doJSON(t, http.MethodDelete, jobURL, nil, nil))
+	info = waitForStatus(t, jobURL, StatusCanceled)
+	assert.Equal(t, "job canceled", info.Error)
+	assert.Equal(t, http.StatusConflict, doJSON(t, http.MethodPost, jobURL+"/generate", nil, nil))
+
+	// Deleting a finished job forgets it
+	assert.Equal(t, http.StatusNoContent, doJSON(t, http.MethodDelete, jobURL, nil, nil))
+	assert.Equal(t, http.StatusNotFound, doJSON(t, http.MethodGet, jobURL, nil, nil))
+}
+
+func TestServer_SubmitErrors(t *testing.T) {
+	ts := newTestServer(t, Config{})
+
+	tests := []struct {
+		name string
+		body map[string]interface{}
+		want int
+	}{
+		{name: "no spec", body: map[string]interface{}{"format": "yaml"}, want: http.StatusBadRequest},
+		{name: "no format", body: map[string]interface{}{"spec": "name: todo"}, want: http.StatusBadRequest},
+		{name: "unknown extension", body: map[string]interface{}{"spec": "name: todo", "name": "todo.txt"}, want: http.StatusBadRequest},
+		{name: "unknown field", body: map[string]interface{}{"spec": "name: todo", "format": "yaml", "mode": "fast"}, want: http.StatusBadRequest},
+		{name: "invalid spec", body: map[string]interface{}{"spec": "invalid", "format": "yaml"}, want: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, doJSON(t, http.MethodPost, ts.URL+"/v1/jobs", tt.body, nil))
+		})
+	}
+
+	assert.Equal(t, http.StatusNotFound, doJSON(t, http.MethodGet, ts.URL+"/v1/jobs/missing", nil, nil))
+}
+
+func TestServer_SubmitImports(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "specs")
+	require.NoError(t, os.MkdirAll(root, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "audit.yaml"), []byte("data_model:\n  entities:\n    - name: AuditEntry\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(parent, "secret.yaml"), []byte("requirements:\n  - id: FR-900\n    description: secret\n"), 0o600))
+	submitted := func(imports string) map[string]interface{} {
+		return map[string]interface{}{"format": "yaml", "spec": "name: todo\ndescription: Tasks\nrequirements: []\nimports:\n" + imports}
+	}
+
+	for name, root := range map[string]string{"no import root": "", "import root": root} {
+		t.Run(name, func(t *testing.T) {
+			ts := newTestServer(t, Config{Parse: func(_ string, format models.SpecFormat, content string) (*models.InputSpecification, error) {
+				return specparser.ParseAndValidateWithinRoot(format, content, root)
+			}})
+			for _, imports := range []string{"  - /etc/passwd\n", "  - ../../../../etc/passwd\n", "  - ../secret.yaml\n", "  - path: " + filepath.Join(parent, "secret.yaml") + "\n"} {
+				assert.Equal(t, http.StatusBadRequest, doJSON(t, http.MethodPost, ts.URL+"/v1/jobs", submitted(imports), nil), imports)
+			}
+
+			want := http.StatusAccepted
+			if root == "" {
+				want = http.StatusBadRequest
+			}
+			assert.Equal(t, want, doJSON(t, http.MethodPost, ts.URL+"/v1/jobs", submitted("  - audit.yaml\n"), nil), "an import inside the root")
+		})
+	}
+}
+
+func TestServer_Token(t *testing.T) {
+	ts := newTestServer(t, Config{Token: "secret"})
+
+	assert.Equal(t, http.StatusOK, doJSON(t, http.MethodGet, ts.URL+"/healthz", nil, nil), "health checks need no token")
+	assert.Equal(t, http.StatusUnauthorized, doJSON(t, http.MethodGet, ts.URL+"/v1/jobs", nil, nil))
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/v1/jobs", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,34 @@
+package spec
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/yamlutil"
+)
+
+// LoadContracts reads output contracts from a companion file, a YAML or JSON
+// document with a top-level contracts list in the same form as the spec section
+func LoadContracts(path string) ([]models.OutputContract, error) {
+	//nolint:gosec // G304: Reading user-specified contracts file - intended functionality
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contracts file: %w", err)
+	}
+
+	var data map[string]interface{}
+	if err := yamlutil.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse contracts file: %w", err)
+	}
+
+	contracts, ok := data["contracts"]
+	if !ok {
+		return nil, fmt.Errorf("contracts file %s has no contracts list", path)
+	}
+	if err := validateContractsStructure(contracts); err != nil {
+		return nil, fmt.Errorf("invalid contracts file %s: %w", path, err)
+	}
+
+	return buildContracts(data), nil
+}
@@ -3,6 +3,9 @@ package spec
 
 import (
 	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/dshills/gocreator/internal/models"
@@ -29,7 +32,7 @@ func (b *FCSBuilder) Build() (*models.FinalClarifiedSpecification, error) {
 	}
 
 	fcs := &models.FinalClarifiedSpecification{
-		SchemaVersion:  "1.0",
+		SchemaVersion:  models.FCSSchemaVersion,
 		ID:             uuid.New().String(),
 		Version:        "1.0",
 		OriginalSpecID: b.spec.ID,
@@ -68,6 +71,15 @@ func (b *FCSBuilder) Build() (*models.FinalClarifiedSpecification, error) {
 	}
 	fcs.APIContracts = apiContracts
 
+	// Build cross-cutting concerns if present
+	fcs.CrossCutting = buildCrossCutting(b.spec.ParsedData)
+
+	// Build output contracts if present
+	fcs.Contracts = buildContracts(b.spec.ParsedData)
+
+	// Build the required file tree if present
+	fcs.FileTree = buildFileTree(b.spec.ParsedData)
+
 	// Build testing strategy if present
 	testingStrategy, err := b.buildTestingStrategy()
 	if err != nil {
@@ -208,6 +220,13 @@ func (b *FCSBuilder) buildArchitecture() (models.Architecture, error) {
 		}
 	}
 
+	if framework, ok := archData["http_framework"].(string); ok && framework != "" {
+		if !models.IsHTTPFramework(framework) {
+			return arch, fmt.Errorf("unsupported http_framework %q (supported: %s)", framework, strings.Join(models.HTTPFrameworks, ", "))
+		}
+		arch.HTTPFramework = framework
+	}
+
 	return arch, nil
 }
 
@@ -216,6 +235,7 @@ func (b *FCSBuilder) buildDataModel() (models.DataModel, error) {
 	dm := models.DataModel{
 		Entities:      []models.Entity{},
 		Relationships: []models.Relationship{},
+		Enums:         []models.Enum{},
 	}
 
 	dmData, ok := b.spec.ParsedData["data_model"].(map[string]interface{})
@@ -258,9 +278,122 @@ func (b *FCSBuilder) buildDataModel() (models.DataModel, error) {
 		}
 	}
 
+	// Build enums
+	if enumsData, ok := dmData["enums"].([]interface{}); ok {
+		for _, enumItem := range enumsData {
+			if enumMap, ok := enumItem.(map[string]interface{}); ok {
+				dm.Enums = append(dm.Enums, buildEnum(enumMap))
+			}
+		}
+	}
+
 	return dm, nil
 }
 
+// buildEnum builds an enum from its specification entry
+func buildEnum(enumMap map[string]interface{}) models.Enum {
+	return models.Enum{
+		Name:        getString(enumMap, "name"),
+		Package:     getString(enumMap, "package"),
+		BackingType: getString(enumMap, "backing_type"),
+		Values:      getStringSlice(enumMap, "values"),
+		Description: getString(enumMap, "description"),
+	}
+}
+
+// buildCrossCutting extracts the cross-cutting concerns. Concerns may be
+// listed by name or as objects with settings and exclusions.
+func buildCrossCutting(data map[string]interface{}) models.CrossCutting {
+	cc := models.CrossCutting{Concerns: []models.Concern{}}
+
+	ccData, ok := data["cross_cutting"].(map[string]interface{})
+	if !ok {
+		return cc
+	}
+	cc.Package = getString(ccData, "package")
+
+	concerns, _ := ccData["concerns"].([]interface{})
+	for _, item := range concerns {
+		switch item := item.(type) {
+		case string:
+			cc.Concerns = append(cc.Concerns, models.Concern{Name: item})
+		case map[string]interface{}:
+			concern := models.Concern{
+				Name:        getString(item, "name"),
+				Description: getString(item, "description"),
+				Exclude:     getStringSlice(item, "exclude"),
+			}
+			if settings := getStringMap(item, "settings"); len(settings) > 0 {
+				concern.Settings = settings
+			}
+			cc.Concerns = append(cc.Concerns, concern)
+		}
+	}
+	return cc
+}
+
+// buildContracts extracts the output contracts checked after generation
+func buildContracts(data map[string]interface{}) []models.OutputContract {
+	items, ok := data["contracts"].([]interface{})
+	if !ok || len(items) == 0 {
+		return nil
+	}
+
+	contracts := make([]models.OutputContract, 0, len(items))
+	for _, item := range items {
+		contractMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		contracts = append(contracts, models.OutputContract{
+			File:        getString(contractMap, "file"),
+			Package:     getString(contractMap, "package"),
+			Exports:     getString(contractMap, "exports"),
+			Kind:        getString(contractMap, "kind"),
+			Route:       getString(contractMap, "route"),
+			Description: getString(contractMap, "description"),
+		})
+	}
+	return contracts
+}
+
+// buildFileTree extracts the file tree the planner must adopt. Directories
+// and files may be listed by path or as objects with a purpose.
+func buildFileTree(data map[string]interface{}) *models.FileTree {
+	treeData, ok := data["file_tree"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	tree := &models.FileTree{Root: getString(treeData, "root")}
+	dirs, _ := treeData["directories"].([]interface{})
+	for _, item := range dirs {
+		switch item := item.(type) {
+		case string:
+			tree.Directories = append(tree.Directories, models.Directory{Path: item})
+		case map[string]interface{}:
+			tree.Directories = append(tree.Directories, models.Directory{
+				Path:    getString(item, "path"),
+				Purpose: getString(item, "purpose"),
+			})
+		}
+	}
+	files, _ := treeData["files"].([]interface{})
+	for _, item := range files {
+		switch item := item.(type) {
+		case string:
+			tree.Files = append(tree.Files, models.File{Path: item})
+		case map[string]interface{}:
+			tree.Files = append(tree.Files, models.File{
+				Path:     getString(item, "path"),
+				Purpose:  getString(item, "purpose"),
+				Entities: getStringSlice(item, "entities"),
+			})
+		}
+	}
+	return tree
+}
+
 // buildAPIContracts extracts and builds the API contracts section
 func (b *FCSBuilder) buildAPIContracts() ([]models.APIContract, error) {
 	contracts := []models.APIContract{}
@@ -280,22 +413,30 @@ func (b *FCSBuilder) buildAPIContracts() ([]models.APIContract, error) {
 			Endpoint:    getString(contractMap, "endpoint"),
 			Method:      getString(contractMap, "method"),
 			Description: getString(contractMap, "description"),
+			Protocol:    strings.ToLower(getString(contractMap, "protocol")),
+			Service:     getString(contractMap, "service"),
+			Streaming:   strings.ToLower(getString(contractMap, "streaming")),
 		}
 
 		// Build request schema
 		if reqData, ok := contractMap["request"].(map[string]interface{}); ok {
 			contract.Request = models.ContractSchema{
-				Fields: getStringMap(reqData, "fields"),
+				Message: getString(reqData, "message"),
+				Fields:  getStringMap(reqData, "fields"),
 			}
 		}
 
 		// Build response schema
 		if respData, ok := contractMap["response"].(map[string]interface{}); ok {
 			contract.Response = models.ContractSchema{
-				Fields: getStringMap(respData, "fields"),
+				Message: getString(respData, "message"),
+				Fields:  getStringMap(respData, "fields"),
 			}
 		}
 
+		if err := contract.Validate(); err != nil {
+			return contracts, fmt.Errorf("invalid api contract %s: %w", contract.Key(), err)
+		}
 		contracts = append(contracts, contract)
 	}
 
@@ -328,6 +469,10 @@ func (b *FCSBuilder) buildTestingStrategy() (models.TestingStrategy, error) {
 		ts.IntegrationTests = integrationTests
 	}
 
+	if fuzzTests, ok := tsData["fuzz_tests"].(bool); ok {
+		ts.FuzzTests = fuzzTests
+	}
+
 	if frameworks, ok := tsData["frameworks"].([]interface{}); ok {
 		for _, fw := range frameworks {
 			if fwStr, ok := fw.(string); ok {
@@ -336,6 +481,13 @@ func (b *FCSBuilder) buildTestingStrategy() (models.TestingStrategy, error) {
 		}
 	}
 
+	if framework, ok := tsData["test_framework"].(string); ok && framework != "" {
+		if !models.IsTestFramework(framework) {
+			return ts, fmt.Errorf("unsupported test_framework %q (supported: %s)", framework, strings.Join(models.TestFrameworks, ", "))
+		}
+		ts.TestFramework = framework
+	}
+
 	return ts, nil
 }
 
@@ -368,6 +520,33 @@ func (b *FCSBuilder) buildBuildConfig() (models.BuildConfig, error) {
 		}
 	}
 
+	if persistence, ok := bcData["persistence"].(string); ok && persistence != "" {
+		if !models.IsPersistenceStrategy(persistence) {
+			return bc, fmt.Errorf("unsupported persistence %q (supported: %s)", persistence, strings.Join(models.PersistenceStrategies, ", "))
+		}
+		bc.Persistence = persistence
+	}
+
+	if modules, ok := bcData["modules"].([]interface{}); ok {
+		for _, item := range modules {
+			moduleMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			module := models.ModuleConfig{
+				Dir:  getString(moduleMap, "dir"),
+				Path: getString(moduleMap, "path"),
+			}
+			if module.Dir != "" {
+				module.Dir = path.Clean(filepath.ToSlash(module.Dir))
+			}
+			if requires := getStringSlice(moduleMap, "requires"); len(requires) > 0 {
+				module.Requires = requires
+			}
+			bc.Modules = append(bc.Modules, module)
+		}
+	}
+
 	return bc, nil
 }
 
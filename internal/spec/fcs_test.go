@@ -47,6 +47,7 @@ func TestFCSBuilder_Build(t *testing.T) {
 								"purpose": "Core library",
 							},
 						},
+						"http_framework": "chi",
 					},
 					"data_model": map[string]interface{}{
 						"entities": []interface{}{
@@ -78,6 +79,7 @@ func TestFCSBuilder_Build(t *testing.T) {
 				assert.Len(t, fcs.Architecture.Packages, 1)
 				assert.Equal(t, "main", fcs.Architecture.Packages[0].Name)
 				assert.Len(t, fcs.Architecture.Dependencies, 1)
+				assert.Equal(t, models.HTTPFrameworkChi, fcs.Architecture.HTTPFramework)
 
 				// Validate data model
 				assert.Len(t, fcs.DataModel.Entities, 1)
@@ -107,6 +109,7 @@ func TestFCSBuilder_Build(t *testing.T) {
 				assert.Len(t, fcs.Requirements.Functional, 1)
 				assert.Empty(t, fcs.Architecture.Packages)
 				assert.Empty(t, fcs.DataModel.Entities)
+				assert.Nil(t, fcs.FileTree)
 			},
 		},
 		{
@@ -181,6 +184,7 @@ func TestFCSBuilder_Build(t *testing.T) {
 						"go_version":  "1.23",
 						"output_path": "./bin",
 						"build_flags": []interface{}{"-tags=prod", "-ldflags=-s -w"},
+						"persistence": "gorm",
 					},
 				},
 			},
@@ -194,6 +198,43 @@ func TestFCSBuilder_Build(t *testing.T) {
 				assert.Equal(t, "1.23", fcs.BuildConfig.GoVersion)
 				assert.Equal(t, "./bin", fcs.BuildConfig.OutputPath)
 				assert.Len(t, fcs.BuildConfig.BuildFlags, 2)
+				assert.Equal(t, models.PersistenceGORM, fcs.BuildConfig.Persistence)
+			},
+		},
+		{
+			name: "With a required file tree",
+			spec: &models.InputSpecification{
+				ID:     "test-spec-tree",
+				Format: models.FormatYAML,
+				State:  models.SpecStateValid,
+				ParsedData: map[string]interface{}{
+					"name":         "TreeProject",
+					"description":  "Project with a fixed layout",
+					"requirements": []interface{}{},
+					"architecture": map[string]interface{}{
+						"packages": []interface{}{
+							map[string]interface{}{"name": "store", "path": "internal/store"},
+						},
+					},
+					"file_tree": map[string]interface{}{
+						"directories": []interface{}{
+							map[string]interface{}{"path": "internal/store", "purpose": "Persistence"},
+						},
+						"files": []interface{}{
+							"cmd/app/main.go",
+							map[string]interface{}{"path": "internal/store/user.go", "purpose": "User storage", "entities": []interface{}{"User"}},
+						},
+					},
+				},
+			},
+			wantErr: false,
+			validate: func(t *testing.T, fcs *models.FinalClarifiedSpecification) {
+				require.NotNil(t, fcs.FileTree)
+				assert.Equal(t, []models.Directory{{Path: "internal/store", Purpose: "Persistence"}}, fcs.FileTree.Directories)
+				assert.Equal(t, []models.File{
+					{Path: "cmd/app/main.go"},
+					{Path: "internal/store/user.go", Purpose: "User storage", Entities: []string{"User"}},
+				}, fcs.FileTree.Files)
 			},
 		},
 	}
@@ -246,6 +287,77 @@ func TestBuildFCS_ConvenienceFunction(t *testing.T) {
 	assert.Equal(t, "test-convenience", fcs.OriginalSpecID)
 }
 
+func TestBuildFCS_GRPCContracts(t *testing.T) {
+	newSpec := func(contract map[string]interface{}) *models.InputSpecification {
+		return &models.InputSpecification{
+			ID:     "test-grpc",
+			Format: models.FormatYAML,
+			State:  models.SpecStateValid,
+			ParsedData: map[string]interface{}{
+				"name":          "Orders",
+				"description":   "Order service",
+				"requirements":  []interface{}{map[string]interface{}{"id": "FR-001", "description": "Create orders"}},
+				"api_contracts": []interface{}{contract},
+			},
+		}
+	}
+
+	fcs, err := BuildFCS(newSpec(map[string]interface{}{
+		"protocol":  "gRPC",
+		"service":   "orders.v1.OrderService",
+		"method":    "WatchOrders",
+		"streaming": "Server",
+		"request":   map[string]interface{}{"fields": map[string]interface{}{"customer_id": "string"}},
+		"response":  map[string]interface{}{"message": "Order", "fields": map[string]interface{}{"id": "string"}},
+	}))
+	require.NoError(t, err)
+	require.Len(t, fcs.APIContracts, 1)
+	contract := fcs.APIContracts[0]
+	assert.True(t, contract.IsGRPC())
+	assert.Equal(t, models.StreamingServer, contract.Streaming)
+	assert.Equal(t, "Order", contract.Response.Message)
+	assert.Equal(t, map[string]string{"customer_id": "string"}, contract.Request.Fields)
+
+	_, err = BuildFCS(newSpec(map[string]interface{}{"protocol": "grpc", "method": "WatchOrders"}))
+	assert.ErrorContains(t, err, "gRPC service")
+}
+
+func TestBuildFCS_Modules(t *testing.T) {
+	newSpec := func(modules ...interface{}) *models.InputSpecification {
+		return &models.InputSpecification{
+			ID:     "test-modules",
+			Format: models.FormatYAML,
+			State:  models.SpecStateValid,
+			ParsedData: map[string]interface{}{
+				"name":         "Shop",
+				"description":  "Shop monorepo",
+				"requirements": []interface{}{map[string]interface{}{"id": "FR-001", "description": "Sell things"}},
+				"build_config": map[string]interface{}{"modules": modules},
+			},
+		}
+	}
+	shared := map[string]interface{}{"dir": "./shared/", "path": "example.com/shop/shared"}
+
+	fcs, err := BuildFCS(newSpec(shared, map[string]interface{}{
+		"dir":      "services/api",
+		"path":     "example.com/shop/api",
+		"requires": []interface{}{"example.com/shop/shared"},
+	}))
+	require.NoError(t, err)
+	require.True(t, fcs.BuildConfig.IsMultiModule())
+	assert.Equal(t, []models.ModuleConfig{
+		{Dir: "shared", Path: "example.com/shop/shared"},
+		{Dir: "services/api", Path: "example.com/shop/api", Requires: []string{"example.com/shop/shared"}},
+	}, fcs.BuildConfig.Modules)
+
+	_, err = BuildFCS(newSpec(shared, map[string]interface{}{
+		"dir":      "api",
+		"path":     "example.com/shop/api",
+		"requires": []interface{}{"example.com/shop/billing"},
+	}))
+	assert.ErrorContains(t, err, "requires example.com/shop/billing")
+}
+
 func TestFCSHashComputation(t *testing.T) {
 	spec := &models.InputSpecification{
 		ID:     "test-hash",
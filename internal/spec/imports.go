@@ -0,0 +1,521 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/yamlutil"
+)
+
+// importsKey is the spec section listing shared fragment files
+const importsKey = "imports"
+
+// fragmentSections are the top-level keys a fragment may hold. Name and
+// description document the fragment and are not merged.
+var fragmentSections = map[string]bool{
+	"name":         true,
+	"description":  true,
+	importsKey:     true,
+	"requirements": true,
+	"data_model":   true,
+}
+
+// namespacePattern matches the namespace an import may be given
+var namespacePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// identPattern matches the Go identifiers in an attribute type
+var identPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// ErrImportNotAllowed is returned by ResolveImportsWithin for an import
+// outside its root, or for any import when there is no root
+var ErrImportNotAllowed = errors.New("import not allowed")
+
+// SpecImport is one entry of a spec's imports list: a fragment file, relative
+// to the file that imports it, and an optional namespace its names are
+// prefixed with
+type SpecImport struct {
+	Path      string `json:"path" yaml:"path"`
+	Namespace string `json:"as,omitempty" yaml:"as,omitempty"`
+}
+
+// ResolveImports merges the fragment files listed under the imports section
+// of spec into its requirements and data model, and appends the merged
+// sections to its content so clarification sees them. Fragments hold shared
+// requirements and data_model sections and may import other fragments;
+// relative paths resolve against baseDir for the spec and against the
+// fragment's own directory for nested imports. With a namespace, entity and
+// enum names get it as a prefix (identity: User becomes IdentityUser) and
+// requirement IDs get it as a qualifier (identity.FR-001). Duplicate names or
+// IDs and import cycles are errors.
+func ResolveImports(spec *models.InputSpecification, baseDir string) error {
+	return resolveImports(spec, baseDir, false)
+}
+
+// ResolveImportsWithin resolves imports as ResolveImports does, for a spec
+// from an untrusted source such as an API client: paths resolve against
+// root, and absolute paths and paths leaving root, through ".." or a
+// symbolic link, are refused with ErrImportNotAllowed. An empty root refuses
+// every import.
+func ResolveImportsWithin(spec *models.InputSpecification, root string) error {
+	return resolveImports(spec, root, true)
+}
+
+func resolveImports(spec *models.InputSpecification, baseDir string, confined bool) error {
+	entries, err := parseImports(spec.ParsedData[importsKey], "specification")
+	if err != nil {
+		return err
+	}
+	delete(spec.ParsedData, importsKey)
+	if len(entries) == 0 {
+		return nil
+	}
+	// The imported sections are appended to the spec's own
+	if err := ValidateSchemaStructure(spec); err != nil {
+		return err
+	}
+
+	if confined && baseDir == "" {
+		return fmt.Errorf("%w: imports are disabled", ErrImportNotAllowed)
+	}
+	root, err := filepath.Abs(baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve import directory: %w", err)
+	}
+	r := &importResolver{
+		root:     root,
+		confined: confined,
+		loaded:   make(map[string]bool),
+		owners:   make(map[string]string),
+		renames:  make(map[string]map[string]string),
+	}
+	r.claimSpec(spec.ParsedData)
+	if err := r.resolve(entries, root, nil, nil); err != nil {
+		return err
+	}
+	r.apply(spec)
+	return nil
+}
+
+// importResolver accumulates the sections merged from fragments
+type importResolver struct {
+	root     string
+	confined bool                         // Imports must stay within root
+	loaded   map[string]bool              // Fragment path and namespace already merged
+	owners   map[string]string            // Entity, enum or requirement key -> where it is defined
+	renames  map[string]map[string]string // Namespace -> type name -> namespaced name
+	sources  []string                     // Merged fragments, for the content header
+
+	requirements  []interface{}
+	entities      []interface{}
+	relationships []interface{}
+	enums         []interface{}
+}
+
+// parseImports reads an imports section: a list of paths, or of objects
+// with a path and an optional namespace under "as"
+func parseImports(value interface{}, source string) ([]SpecImport, error) {
+	if value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: imports must be an array", source)
+	}
+
+	entries := make([]SpecImport, 0, len(items))
+	for i, item := range items {
+		var entry SpecImport
+		switch item := item.(type) {
+		case string:
+			entry.Path = item
+		case map[string]interface{}:
+			entry.Path = getString(item, "path")
+			entry.Namespace = getString(item, "as")
+		default:
+			return nil, fmt.Errorf("%s: imports[%d] must be a path or an object", source, i)
+		}
+		if strings.TrimSpace(entry.Path) == "" {
+			return nil, fmt.Errorf("%s: imports[%d] has no path", source, i)
+		}
+		if entry.Namespace != "" && !namespacePattern.MatchString(entry.Namespace) {
+			return nil, fmt.Errorf("%s: imports[%d] namespace %q must start with a letter and hold only letters, digits, '_' and '-'", source, i, entry.Namespace)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// resolve merges entries depth first, so a fragment's own imports come
+// before it. stack holds the fragments being resolved, to detect cycles;
+// namespace is the chain of namespaces the entries are imported under.
+func (r *importResolver) resolve(entries []SpecImport, baseDir string, namespace, stack []string) error {
+	for _, entry := range entries {
+		path := entry.Path
+		if r.confined && filepath.IsAbs(path) {
+			return fmt.Errorf("%w: %s is an absolute path", ErrImportNotAllowed, entry.Path)
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		path = filepath.Clean(path)
+		if r.confined {
+			if err := r.checkWithinRoot(path, entry.Path); err != nil {
+				return err
+			}
+		}
+		source := r.display(path)
+
+		for i, open := range stack {
+			if open == path {
+				cycle := make([]string, 0, len(stack)-i+1)
+				for _, p := range stack[i:] {
+					cycle = append(cycle, r.display(p))
+				}
+				return fmt.Errorf("import cycle: %s -> %s", strings.Join(cycle, " -> "), source)
+			}
+		}
+
+		ns := namespace
+		if entry.Namespace != "" {
+			ns = append(append([]string{}, namespace...), entry.Namespace)
+		}
+		// A fragment reached twice under the same namespace, as when two
+		// fragments share a common one, is merged once
+		key := path + "#" + strings.Join(ns, ".")
+		if r.loaded[key] {
+			continue
+		}
+		r.loaded[key] = true
+
+		data, err := readFragment(path)
+		if err != nil {
+			return err
+		}
+		nested, err := parseImports(data[importsKey], source)
+		if err != nil {
+			return err
+		}
+		if err := r.resolve(nested, filepath.Dir(path), ns, append(stack, path)); err != nil {
+			return err
+		}
+		if err := r.merge(data, ns, source); err != nil {
+			return err
+		}
+
+		if len(ns) > 0 {
+			source = fmt.Sprintf("%s (as %s)", source, strings.Join(ns, "."))
+		}
+		r.sources = append(r.sources, source)
+	}
+	return nil
+}
+
+// checkWithinRoot refuses a confined import whose path, as given or with
+// its symbolic links followed, is outside the root
+func (r *importResolver) checkWithinRoot(path, given string) error {
+	if !within(r.root, path) {
+		return fmt.Errorf("%w: %s is outside the import root", ErrImportNotAllowed, given)
+	}
+	root, err := filepath.EvalSymlinks(r.root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve import root: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		// Report the path as given rather than where it resolved to
+		return fmt.Errorf("failed to read imported fragment %s", given)
+	}
+	if !within(root, resolved) {
+		return fmt.Errorf("%w: %s is outside the import root", ErrImportNotAllowed, given)
+	}
+	return nil
+}
+
+// within reports whether path is dir or inside it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// display returns path relative to the spec directory when it is inside it
+func (r *importResolver) display(path string) string {
+	if rel, err := filepath.Rel(r.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+// readFragment reads a YAML or JSON fragment file
+func readFragment(path string) (map[string]interface{}, error) {
+	//nolint:gosec // G304: Reading a fragment file the spec imports - intended functionality
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read imported fragment: %w", err)
+	}
+
+	var data map[string]interface{}
+	if err := yamlutil.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse imported fragment %s: %w", path, err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("imported fragment %s is empty", path)
+	}
+	return data, nil
+}
+
+// claimSpec registers the names and IDs the spec defines itself. Duplicates
+// within the spec are left to validation.
+func (r *importResolver) claimSpec(data map[string]interface{}) {
+	const source = "the specification"
+
+	reqs, _ := data["requirements"].([]interface{})
+	for _, item := range reqs {
+		if reqMap, ok := item.(map[string]interface{}); ok {
+			r.owners["requirement:"+getString(reqMap, "id")] = source
+		}
+	}
+
+	dm, _ := data["data_model"].(map[string]interface{})
+	for _, section := range []string{"entities", "enums"} {
+		items, _ := dm[section].([]interface{})
+		for _, item := range items {
+			if itemMap, ok := item.(map[string]interface{}); ok {
+				r.owners["type:"+getString(itemMap, "name")] = source
+			}
+		}
+	}
+}
+
+// claim records that source defines name of the given kind, or reports the
+// definition it conflicts with. Entities and enums share the "type" kind,
+// since both become Go types.
+func (r *importResolver) claim(kind, name, source string) error {
+	if name == "" {
+		return nil
+	}
+	key := kind + ":" + name
+	if owner, ok := r.owners[key]; ok {
+		if kind == "type" {
+			kind = "entity or enum"
+		}
+		return fmt.Errorf("%s %q imported from %s is already defined in %s; import it under a namespace with \"as\"", kind, name, source, owner)
+	}
+	r.owners[key] = source
+	return nil
+}
+
+// merge adds the requirements and data model of one fragment, prefixed with
+// namespace
+func (r *importResolver) merge(data map[string]interface{}, namespace []string, source string) error {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !fragmentSections[key] {
+			return fmt.Errorf("%s: section %q cannot be imported; fragments hold requirements and data_model", source, key)
+		}
+	}
+
+	if reqs, ok := data["requirements"]; ok {
+		items, ok := reqs.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: requirements must be an array", source)
+		}
+		for _, item := range items {
+			reqMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			req := copyMap(reqMap)
+			if id := getString(req, "id"); id != "" && len(namespace) > 0 {
+				req["id"] = strings.Join(namespace, ".") + "." + id
+			}
+			if err := r.claim("requirement", getString(req, "id"), source); err != nil {
+				return err
+			}
+			r.requirements = append(r.requirements, req)
+		}
+	}
+
+	dmValue, ok := data["data_model"]
+	if !ok {
+		return nil
+	}
+	dm, ok := dmValue.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: data_model must be an object", source)
+	}
+	if err := validateDataModelStructure(dm); err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	// References may name types of fragments imported under the same
+	// namespace, as well as the fragment's own
+	nsKey := strings.Join(namespace, ".")
+	renames, ok := r.renames[nsKey]
+	if !ok {
+		renames = make(map[string]string)
+		r.renames[nsKey] = renames
+	}
+	addTypeRenames(renames, dm, namespace)
+	rename := func(name string) string {
+		if renamed, ok := renames[name]; ok {
+			return renamed
+		}
+		return name
+	}
+
+	entities, _ := dm["entities"].([]interface{})
+	for _, item := range entities {
+		entityMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		entity := copyMap(entityMap)
+		entity["name"] = rename(getString(entity, "name"))
+		if attrs, ok := entity["attributes"].(map[string]interface{}); ok {
+			renamed := make(map[string]interface{}, len(attrs))
+			for attr, typ := range attrs {
+				if typ, ok := typ.(string); ok {
+					renamed[attr] = identPattern.ReplaceAllStringFunc(typ, rename)
+				} else {
+					renamed[attr] = typ
+				}
+			}
+			entity["attributes"] = renamed
+		}
+		if err := r.claim("type", getString(entity, "name"), source); err != nil {
+			return err
+		}
+		r.entities = append(r.entities, entity)
+	}
+
+	enums, _ := dm["enums"].([]interface{})
+	for _, item := range enums {
+		enumMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		enum := copyMap(enumMap)
+		enum["name"] = rename(getString(enum, "name"))
+		if err := r.claim("type", getString(enum, "name"), source); err != nil {
+			return err
+		}
+		r.enums = append(r.enums, enum)
+	}
+
+	relationships, _ := dm["relationships"].([]interface{})
+	for _, item := range relationships {
+		relMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rel := copyMap(relMap)
+		rel["from"] = rename(getString(rel, "from"))
+		rel["to"] = rename(getString(rel, "to"))
+		r.relationships = append(r.relationships, rel)
+	}
+	return nil
+}
+
+// addTypeRenames maps the entity and enum names of a fragment data model to
+// their namespaced names
+func addTypeRenames(renames map[string]string, dm map[string]interface{}, namespace []string) {
+	if len(namespace) == 0 {
+		return
+	}
+
+	var prefix strings.Builder
+	for _, ns := range namespace {
+		prefix.WriteString(pascalCase(ns))
+	}
+	for _, section := range []string{"entities", "enums"} {
+		items, _ := dm[section].([]interface{})
+		for _, item := range items {
+			if itemMap, ok := item.(map[string]interface{}); ok {
+				if name := getString(itemMap, "name"); name != "" {
+					renames[name] = prefix.String() + name
+				}
+			}
+		}
+	}
+}
+
+// pascalCase joins the '-' and '_' separated words of s, each capitalised
+func pascalCase(s string) string {
+	var sb strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' }) {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	return sb.String()
+}
+
+// copyMap returns a shallow copy of m
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+// apply appends the merged sections to the spec data and content
+func (r *importResolver) apply(spec *models.InputSpecification) {
+	imported := make(map[string]interface{})
+
+	if len(r.requirements) > 0 {
+		reqs, _ := spec.ParsedData["requirements"].([]interface{})
+		spec.ParsedData["requirements"] = append(reqs, r.requirements...)
+		imported["requirements"] = r.requirements
+	}
+
+	dmImported := make(map[string]interface{})
+	for _, section := range []struct {
+		name  string
+		items []interface{}
+	}{
+		{"entities", r.entities},
+		{"relationships", r.relationships},
+		{"enums", r.enums},
+	} {
+		if len(section.items) > 0 {
+			dmImported[section.name] = section.items
+		}
+	}
+	if len(dmImported) > 0 {
+		dm, ok := spec.ParsedData["data_model"].(map[string]interface{})
+		if !ok {
+			dm = make(map[string]interface{})
+			spec.ParsedData["data_model"] = dm
+		}
+		for name, items := range dmImported {
+			existing, _ := dm[name].([]interface{})
+			dm[name] = append(existing, items.([]interface{})...)
+		}
+		imported["data_model"] = dmImported
+	}
+
+	if len(imported) == 0 {
+		return
+	}
+	rendered, err := yaml.Marshal(imported)
+	if err != nil {
+		// The parsed data holds the fragments either way
+		return
+	}
+	spec.Content = strings.TrimRight(spec.Content, "\n") +
+		"\n\n# Imported from " + strings.Join(r.sources, ", ") + "\n" + string(rendered)
+}
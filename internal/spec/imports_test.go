@@ -0,0 +1,194 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFragment writes a fragment file under dir
+func writeFragment(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestParseAndValidateWithImports(t *testing.T) {
+	dir := t.TempDir()
+	writeFragment(t, dir, "shared/audit.yaml", `
+name: Audit
+data_model:
+  entities:
+    - name: AuditEntry
+      attributes:
+        at: time.Time
+`)
+	writeFragment(t, dir, "shared/identity.yaml", `
+imports:
+  - audit.yaml
+requirements:
+  - id: FR-001
+    description: Users can sign in
+data_model:
+  entities:
+    - name: User
+      attributes:
+        id: string
+        roles: "[]Role"
+  enums:
+    - name: Role
+      values: [admin, member]
+  relationships:
+    - from: User
+      to: AuditEntry
+      type: one-to-many
+`)
+	writeFragment(t, dir, "shared/nfr.json", `{"requirements": [{"id": "NFR-001", "type": "nfr", "description": "p99 under 200ms"}]}`)
+
+	content := `name: Shop
+description: An online shop
+imports:
+  - path: shared/identity.yaml
+    as: identity
+  - shared/nfr.json
+requirements:
+  - id: FR-001
+    description: Customers can browse products
+data_model:
+  entities:
+    - name: User
+      attributes:
+        id: string
+`
+	inputSpec, err := ParseAndValidateWithImports(models.FormatYAML, content, dir)
+	require.NoError(t, err)
+	assert.NotContains(t, inputSpec.ParsedData, importsKey)
+
+	fcs, err := BuildFCS(inputSpec)
+	require.NoError(t, err)
+
+	var reqIDs []string
+	for _, fr := range fcs.Requirements.Functional {
+		reqIDs = append(reqIDs, fr.ID)
+	}
+	assert.Equal(t, []string{"FR-001", "identity.FR-001"}, reqIDs)
+	require.Len(t, fcs.Requirements.NonFunctional, 1)
+	assert.Equal(t, "NFR-001", fcs.Requirements.NonFunctional[0].ID)
+
+	var entities []string
+	for _, entity := range fcs.DataModel.Entities {
+		entities = append(entities, entity.Name)
+	}
+	assert.Equal(t, []string{"User", "IdentityAuditEntry", "IdentityUser"}, entities)
+	assert.Equal(t, "[]IdentityRole", fcs.DataModel.Entities[2].Attributes["roles"])
+	require.Len(t, fcs.DataModel.Enums, 1)
+	assert.Equal(t, "IdentityRole", fcs.DataModel.Enums[0].Name)
+	require.Len(t, fcs.DataModel.Relationships, 1)
+	assert.Equal(t, "IdentityAuditEntry", fcs.DataModel.Relationships[0].To)
+
+	assert.Contains(t, inputSpec.Content, "# Imported from shared/audit.yaml (as identity), shared/identity.yaml (as identity), shared/nfr.json")
+	assert.Contains(t, inputSpec.Content, "name: IdentityUser")
+}
+
+func TestResolveImports_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		fragments   map[string]string
+		imports     string
+		errContains string
+	}{
+		{
+			name:        "entity conflict without namespace",
+			fragments:   map[string]string{"user.yaml": "data_model:\n  entities:\n    - name: User\n"},
+			imports:     "  - user.yaml\n",
+			errContains: `entity or enum "User" imported from user.yaml is already defined in the specification`,
+		},
+		{
+			name: "requirement conflict between fragments",
+			fragments: map[string]string{
+				"a.yaml": "requirements:\n  - id: NFR-001\n    type: nfr\n",
+				"b.yaml": "requirements:\n  - id: NFR-001\n    type: nfr\n",
+			},
+			imports:     "  - a.yaml\n  - b.yaml\n",
+			errContains: `requirement "NFR-001" imported from b.yaml is already defined in a.yaml`,
+		},
+		{
+			name: "import cycle",
+			fragments: map[string]string{
+				"a.yaml": "imports:\n  - b.yaml\n",
+				"b.yaml": "imports:\n  - a.yaml\n",
+			},
+			imports:     "  - a.yaml\n",
+			errContains: "import cycle: a.yaml -> b.yaml -> a.yaml",
+		},
+		{
+			name:        "unsupported section",
+			fragments:   map[string]string{"arch.yaml": "architecture:\n  packages: []\n"},
+			imports:     "  - arch.yaml\n",
+			errContains: `arch.yaml: section "architecture" cannot be imported`,
+		},
+		{
+			name:        "missing fragment",
+			imports:     "  - missing.yaml\n",
+			errContains: "failed to read imported fragment",
+		},
+		{
+			name:        "invalid namespace",
+			imports:     "  - path: user.yaml\n    as: \"9lives\"\n",
+			errContains: `namespace "9lives"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.fragments {
+				writeFragment(t, dir, name, content)
+			}
+			content := "name: Shop\ndescription: An online shop\nrequirements: []\ndata_model:\n  entities:\n    - name: User\nimports:\n" + tt.imports
+
+			_, err := ParseAndValidateWithImports(models.FormatYAML, content, dir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestParseAndValidateWithinRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "specs")
+	writeFragment(t, root, "shared/audit.yaml", "data_model:\n  entities:\n    - name: AuditEntry\n")
+	writeFragment(t, parent, "secret.yaml", "requirements:\n  - id: FR-900\n    description: secret\n")
+	require.NoError(t, os.Symlink(filepath.Join(parent, "secret.yaml"), filepath.Join(root, "link.yaml")))
+	spec := "name: Shop\ndescription: An online shop\nrequirements: []\nimports:\n"
+
+	inputSpec, err := ParseAndValidateWithinRoot(models.FormatYAML, spec+"  - shared/audit.yaml\n", root)
+	require.NoError(t, err)
+	assert.Contains(t, inputSpec.Content, "AuditEntry")
+
+	_, err = ParseAndValidateWithinRoot(models.FormatYAML, "name: Shop\ndescription: An online shop\nrequirements: []\n", "")
+	require.NoError(t, err, "specs without imports need no root")
+
+	for name, imports := range map[string]string{
+		"absolute":     "  - " + filepath.Join(parent, "secret.yaml") + "\n",
+		"parent":       "  - ../secret.yaml\n",
+		"nested":       "  - shared/../../secret.yaml\n",
+		"symlink":      "  - link.yaml\n",
+		"etc":          "  - /etc/passwd\n",
+		"object entry": "  - path: ../../../etc/passwd\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseAndValidateWithinRoot(models.FormatYAML, spec+imports, root)
+			require.ErrorIs(t, err, ErrImportNotAllowed)
+			assert.NotContains(t, err.Error(), "FR-900", "nothing is read from outside the root")
+		})
+	}
+
+	_, err = ParseAndValidateWithinRoot(models.FormatYAML, spec+"  - shared/audit.yaml\n", "")
+	assert.ErrorIs(t, err, ErrImportNotAllowed, "no root refuses every import")
+}
@@ -0,0 +1,279 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/dshills/gocreator/internal/docpath"
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// mergeDocumentKeys are the top-level keys describing one document rather
+// than the project, which Merge does not compare. imported_from is set on
+// specs converted by an importer.
+var mergeDocumentKeys = map[string]bool{
+	"name":          true,
+	"description":   true,
+	"imported_from": true,
+}
+
+// MergeSource is a parsed and validated spec to merge, with the name its
+// values are attributed to, usually its file path
+type MergeSource struct {
+	Name string
+	Spec *models.InputSpecification
+}
+
+// Merge merges several specs into one, so a product spec, a security
+// requirements document and an API description can be clarified together.
+// The name comes from the first spec and the descriptions are joined. Other
+// sections merge key by key; lists of requirements, entities, enums, API
+// contracts and other items with an id, name, path or method and endpoint
+// merge item by item on that key, and other lists gain the items they lack.
+// A value the specs disagree on keeps the first spec's value and is recorded
+// as a conflict to resolve. The content of every spec is kept, under a header
+// naming it, so clarification sees them all.
+func Merge(sources []MergeSource) (*models.InputSpecification, error) {
+	if len(sources) < 2 {
+		return nil, fmt.Errorf("merging needs at least 2 specifications, got %d", len(sources))
+	}
+
+	m := &specMerger{sources: sources, seen: make(map[string]bool)}
+	data := map[string]interface{}{"name": sources[0].Spec.ParsedData["name"]}
+	names := make([]string, 0, len(sources))
+	var descriptions []string
+	var content strings.Builder
+	for _, source := range sources {
+		names = append(names, source.Name)
+		parsed := source.Spec.ParsedData
+		if description := strings.TrimSpace(getString(parsed, "description")); description != "" && !slices.Contains(descriptions, description) {
+			descriptions = append(descriptions, description)
+		}
+		for _, key := range sortedKeys(parsed) {
+			if mergeDocumentKeys[key] {
+				continue
+			}
+			value := cloneValue(parsed[key])
+			if existing, ok := data[key]; ok {
+				data[key] = m.merge(key, existing, value)
+			} else {
+				data[key] = value
+			}
+		}
+		content.WriteString(fmt.Sprintf("# Source: %s\n\n%s\n\n", source.Name, strings.TrimSpace(source.Spec.Content)))
+	}
+	data["description"] = strings.Join(descriptions, "\n\n")
+
+	spec := &models.InputSpecification{
+		SchemaVersion: "1.0",
+		ID:            uuid.New().String(),
+		Format:        sources[0].Spec.Format,
+		Content:       strings.TrimRight(content.String(), "\n") + "\n",
+		ParsedData:    data,
+		Metadata: models.SpecMetadata{
+			CreatedAt: time.Now(),
+			Version:   "1.0",
+		},
+		State:     models.SpecStateParsed,
+		Sources:   names,
+		Conflicts: m.conflicts,
+	}
+
+	if err := NewValidator().Validate(spec); err != nil {
+		spec.State = models.SpecStateInvalid
+		return nil, fmt.Errorf("merged specification is invalid: %w", err)
+	}
+	if err := spec.TransitionTo(models.SpecStateValid); err != nil {
+		return nil, fmt.Errorf("failed to transition to valid state: %w", err)
+	}
+	return spec, nil
+}
+
+// ApplyResolutions returns a copy of a merged spec with the resolved
+// conflicts set to their resolution. A resolution of a value that is not a
+// string is read as JSON or YAML.
+func ApplyResolutions(spec *models.InputSpecification, conflicts []models.SpecConflict) (*models.InputSpecification, error) {
+	resolved := *spec
+	resolved.ParsedData, _ = cloneValue(spec.ParsedData).(map[string]interface{})
+	for _, conflict := range conflicts {
+		if !conflict.Resolved() {
+			continue
+		}
+		current, ok := docpath.Lookup(resolved.ParsedData, conflict.Path)
+		if !ok {
+			return nil, fmt.Errorf("conflict %s: %s is not in the specification", conflict.ID, conflict.Path)
+		}
+		value, err := docpath.ParseValue(conflict.Resolution, current)
+		if err != nil {
+			return nil, fmt.Errorf("conflict %s: %w", conflict.ID, err)
+		}
+		if err := docpath.Set(resolved.ParsedData, conflict.Path, value); err != nil {
+			return nil, fmt.Errorf("conflict %s: %w", conflict.ID, err)
+		}
+	}
+	return &resolved, nil
+}
+
+// specMerger records the conflicts found while merging
+type specMerger struct {
+	sources   []MergeSource
+	conflicts []models.SpecConflict
+	seen      map[string]bool // Paths already recorded as conflicts
+}
+
+// merge merges next into base, the value already merged at path, and
+// returns the result
+func (m *specMerger) merge(path string, base, next interface{}) interface{} {
+	if baseMap, ok := base.(map[string]interface{}); ok {
+		if nextMap, ok := next.(map[string]interface{}); ok {
+			for _, key := range sortedKeys(nextMap) {
+				if existing, ok := baseMap[key]; ok {
+					baseMap[key] = m.merge(path+"."+key, existing, nextMap[key])
+				} else {
+					baseMap[key] = nextMap[key]
+				}
+			}
+			return baseMap
+		}
+	}
+	if baseList, ok := base.([]interface{}); ok {
+		if nextList, ok := next.([]interface{}); ok {
+			if merged, ok := m.mergeList(path, baseList, nextList); ok {
+				return merged
+			}
+		}
+	}
+
+	if renderValue(base) != renderValue(next) {
+		m.conflict(path)
+	}
+	return base
+}
+
+// mergeList merges two lists of objects. Lists of other values are not
+// merged, and differ when they hold different values.
+func (m *specMerger) mergeList(path string, base, next []interface{}) ([]interface{}, bool) {
+	if len(next) == 0 {
+		return base, true
+	}
+	if len(base) == 0 {
+		return next, true
+	}
+	if !isObjectList(base) || !isObjectList(next) {
+		return nil, false
+	}
+
+	keyed := isKeyedList(base) && isKeyedList(next)
+	for _, item := range next {
+		if !keyed {
+			if !containsValue(base, item) {
+				base = append(base, item)
+			}
+			continue
+		}
+
+		key := docpath.ItemKey(item.(map[string]interface{}))
+		if i := docpath.IndexOf(base, key); i >= 0 {
+			base[i] = m.merge(fmt.Sprintf("%s[%s]", path, key), base[i], item)
+		} else {
+			base = append(base, item)
+		}
+	}
+	return base, true
+}
+
+// conflict records that the specs disagree on the value at path, with the
+// value each spec gives it
+func (m *specMerger) conflict(path string) {
+	if m.seen[path] {
+		return
+	}
+	m.seen[path] = true
+
+	conflict := models.SpecConflict{
+		ID:   fmt.Sprintf("conflict-%d", len(m.conflicts)+1),
+		Path: path,
+	}
+	for _, source := range m.sources {
+		if value, ok := docpath.Lookup(source.Spec.ParsedData, path); ok {
+			conflict.Sources = append(conflict.Sources, models.ConflictSource{Spec: source.Name, Value: renderValue(value)})
+		}
+	}
+	m.conflicts = append(m.conflicts, conflict)
+}
+
+func isObjectList(list []interface{}) bool {
+	for _, item := range list {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isKeyedList(list []interface{}) bool {
+	for _, item := range list {
+		if docpath.ItemKey(item.(map[string]interface{})) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func containsValue(list []interface{}, value interface{}) bool {
+	rendered := renderValue(value)
+	for _, item := range list {
+		if renderValue(item) == rendered {
+			return true
+		}
+	}
+	return false
+}
+
+// renderValue renders a spec value for comparison and display: strings with
+// their whitespace collapsed, other values as JSON
+func renderValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strings.Join(strings.Fields(s), " ")
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// cloneValue returns a deep copy of a parsed spec value
+func cloneValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		cloned := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			cloned[k] = cloneValue(v)
+		}
+		return cloned
+	case []interface{}:
+		cloned := make([]interface{}, len(value))
+		for i, v := range value {
+			cloned[i] = cloneValue(v)
+		}
+		return cloned
+	default:
+		return value
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
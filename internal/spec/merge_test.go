@@ -0,0 +1,144 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/dshills/gocreator/internal/docpath"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mergeSource(t *testing.T, name string, format models.SpecFormat, content string) MergeSource {
+	t.Helper()
+	spec, err := ParseAndValidate(format, content)
+	require.NoError(t, err, name)
+	return MergeSource{Name: name, Spec: spec}
+}
+
+func TestMerge(t *testing.T) {
+	product := mergeSource(t, "product.md", models.FormatMarkdown, `---
+name: Shop
+description: An online shop
+requirements:
+  - id: FR-001
+    description: Customers place orders
+  - id: FR-002
+    description: Customers  browse the catalog
+data_model:
+  entities:
+    - name: User
+      attributes:
+        id: uuid
+        email: string
+architecture:
+  http_framework: chi
+---
+# Shop
+`)
+	security := mergeSource(t, "security.yaml", models.FormatYAML, `name: Shop security
+description: Security requirements
+requirements:
+  - id: FR-002
+    description: Customers browse the catalog
+  - id: FR-001
+    description: Only signed-in customers place orders
+  - id: NFR-001
+    type: nfr
+    description: Passwords are hashed with argon2id
+data_model:
+  entities:
+    - name: User
+      attributes:
+        email: string
+        password_hash: string
+`)
+	api := mergeSource(t, "api.yaml", models.FormatYAML, `name: Shop API
+description: An online shop
+imported_from: OpenAPI 3.0.3
+requirements:
+  - id: FR-001
+    description: Only signed-in customers place orders
+data_model:
+  entities:
+    - name: User
+      attributes:
+        id: string
+architecture:
+  http_framework: gin
+api_contracts:
+  - endpoint: /orders
+    method: post
+    response:
+      fields:
+        id: string
+`)
+
+	merged, err := Merge([]MergeSource{product, security, api})
+	require.NoError(t, err)
+	assert.Equal(t, models.SpecStateValid, merged.State)
+	assert.Equal(t, []string{"product.md", "security.yaml", "api.yaml"}, merged.Sources)
+	assert.Equal(t, "Shop", merged.ParsedData["name"])
+	assert.Equal(t, "An online shop\n\nSecurity requirements", merged.ParsedData["description"])
+	assert.NotContains(t, merged.ParsedData, "imported_from")
+	assert.Contains(t, merged.Content, "# Source: security.yaml\n\nname: Shop security")
+
+	reqs := merged.ParsedData["requirements"].([]interface{})
+	assert.Len(t, reqs, 3, "requirements merge on their ID")
+	user := merged.ParsedData["data_model"].(map[string]interface{})["entities"].([]interface{})[0].(map[string]interface{})
+	assert.Len(t, user["attributes"], 3)
+	assert.Len(t, merged.ParsedData["api_contracts"], 1)
+
+	// Conflicts are numbered in the order the specs are merged
+	require.Len(t, merged.Conflicts, 3)
+	requirement := merged.Conflicts[0]
+	assert.Equal(t, "requirements[FR-001].description", requirement.Path)
+	assert.Equal(t, []string{"Customers place orders", "Only signed-in customers place orders"}, requirement.Values())
+	assert.Equal(t, []string{"security.yaml", "api.yaml"}, requirement.Specs("Only signed-in customers place orders"))
+	assert.Equal(t, models.SpecConflict{
+		ID:   "conflict-2",
+		Path: "architecture.http_framework",
+		Sources: []models.ConflictSource{
+			{Spec: "product.md", Value: "chi"},
+			{Spec: "api.yaml", Value: "gin"},
+		},
+	}, merged.Conflicts[1])
+	assert.Equal(t, "data_model.entities[User].attributes.id", merged.Conflicts[2].Path)
+
+	// The first spec's value is kept until the conflict is resolved
+	assert.Equal(t, "chi", merged.ParsedData["architecture"].(map[string]interface{})["http_framework"])
+	conflicts := append([]models.SpecConflict{}, merged.Conflicts...)
+	conflicts[0].Resolution = "Only signed-in customers place orders"
+	conflicts[1].Resolution = "gin"
+	resolved, err := ApplyResolutions(merged, conflicts)
+	require.NoError(t, err)
+	fcs, err := BuildFCS(resolved)
+	require.NoError(t, err)
+	assert.Equal(t, "gin", fcs.Architecture.HTTPFramework)
+	assert.Equal(t, "Only signed-in customers place orders", fcs.Requirements.Functional[0].Description)
+	assert.Equal(t, "uuid", fcs.DataModel.Entities[0].Attributes["id"])
+	assert.Equal(t, "chi", merged.ParsedData["architecture"].(map[string]interface{})["http_framework"], "the merged spec is not changed")
+
+	_, err = Merge([]MergeSource{product})
+	assert.Error(t, err)
+}
+
+func TestApplyResolutions_Values(t *testing.T) {
+	spec := &models.InputSpecification{ParsedData: map[string]interface{}{
+		"requirements": []interface{}{},
+		"data_model": map[string]interface{}{"enums": []interface{}{
+			map[string]interface{}{"name": "identity.Status", "values": []interface{}{"active"}},
+		}},
+	}}
+	conflicts := []models.SpecConflict{{ID: "conflict-1", Path: "data_model.enums[identity.Status].values", Resolution: "[active, banned]"}}
+
+	resolved, err := ApplyResolutions(spec, conflicts)
+	require.NoError(t, err)
+	value, ok := docpath.Lookup(resolved.ParsedData, conflicts[0].Path)
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{"active", "banned"}, value)
+
+	conflicts[0].Path = "data_model.enums[Role].values"
+	_, err = ApplyResolutions(spec, conflicts)
+	assert.Error(t, err)
+}
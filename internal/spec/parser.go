@@ -41,13 +41,40 @@ func ParseSpec(format models.SpecFormat, content string) (*models.InputSpecifica
 	return spec, nil
 }
 
-// ParseAndValidate parses and validates a specification in one step
+// ParseAndValidate parses and validates a specification in one step.
+// Imported fragments resolve against the working directory.
 func ParseAndValidate(format models.SpecFormat, content string) (*models.InputSpecification, error) {
+	return ParseAndValidateWithImports(format, content, "")
+}
+
+// ParseAndValidateWithImports parses a specification, merges the fragments
+// it imports from paths relative to baseDir, and validates the result
+func ParseAndValidateWithImports(format models.SpecFormat, content, baseDir string) (*models.InputSpecification, error) {
+	return parseAndValidate(format, content, func(spec *models.InputSpecification) error {
+		return ResolveImports(spec, baseDir)
+	})
+}
+
+// ParseAndValidateWithinRoot parses and validates a specification from an
+// untrusted source, only merging fragments inside root (see
+// ResolveImportsWithin). An empty root refuses every import.
+func ParseAndValidateWithinRoot(format models.SpecFormat, content, root string) (*models.InputSpecification, error) {
+	return parseAndValidate(format, content, func(spec *models.InputSpecification) error {
+		return ResolveImportsWithin(spec, root)
+	})
+}
+
+func parseAndValidate(format models.SpecFormat, content string, resolve func(*models.InputSpecification) error) (*models.InputSpecification, error) {
 	spec, err := ParseSpec(format, content)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := resolve(spec); err != nil {
+		spec.State = models.SpecStateInvalid
+		return nil, fmt.Errorf("failed to resolve imports: %w", err)
+	}
+
 	validator := NewValidator()
 	if err := validator.Validate(spec); err != nil {
 		spec.State = models.SpecStateInvalid
@@ -137,6 +137,57 @@ func ValidateSchemaStructure(spec *models.InputSpecification) error {
 		}
 	}
 
+	// Validate cross-cutting concerns structure if present
+	if cc, ok := spec.ParsedData["cross_cutting"]; ok {
+		if ccMap, ok := cc.(map[string]interface{}); ok {
+			if err := validateCrossCuttingStructure(ccMap); err != nil {
+				return fmt.Errorf("invalid cross_cutting structure: %w", err)
+			}
+		} else {
+			return fmt.Errorf("cross_cutting must be an object")
+		}
+	}
+
+	// Validate output contracts structure if present
+	if contracts, ok := spec.ParsedData["contracts"]; ok {
+		if err := validateContractsStructure(contracts); err != nil {
+			return fmt.Errorf("invalid contracts structure: %w", err)
+		}
+	}
+
+	// Validate the required file tree if present
+	if tree, ok := spec.ParsedData["file_tree"]; ok {
+		if treeMap, ok := tree.(map[string]interface{}); ok {
+			if err := validateFileTreeStructure(spec, treeMap); err != nil {
+				return fmt.Errorf("invalid file_tree structure: %w", err)
+			}
+		} else {
+			return fmt.Errorf("file_tree must be an object")
+		}
+	}
+
+	// Validate testing strategy structure if present
+	if testing, ok := spec.ParsedData["testing_strategy"]; ok {
+		if testingMap, ok := testing.(map[string]interface{}); ok {
+			if err := validateTestingStrategyStructure(testingMap); err != nil {
+				return fmt.Errorf("invalid testing_strategy structure: %w", err)
+			}
+		} else {
+			return fmt.Errorf("testing_strategy must be an object")
+		}
+	}
+
+	// Validate build config structure if present
+	if build, ok := spec.ParsedData["build_config"]; ok {
+		if buildMap, ok := build.(map[string]interface{}); ok {
+			if err := validateBuildConfigStructure(buildMap); err != nil {
+				return fmt.Errorf("invalid build_config structure: %w", err)
+			}
+		} else {
+			return fmt.Errorf("build_config must be an object")
+		}
+	}
+
 	return nil
 }
 
@@ -217,6 +268,16 @@ func validateArchitectureStructure(arch map[string]interface{}) error {
 		}
 	}
 
+	if framework, ok := arch["http_framework"]; ok {
+		name, ok := framework.(string)
+		if !ok {
+			return fmt.Errorf("architecture.http_framework must be a string")
+		}
+		if name != "" && !models.IsHTTPFramework(name) {
+			return fmt.Errorf("unsupported http_framework %q (supported: %s)", name, strings.Join(models.HTTPFrameworks, ", "))
+		}
+	}
+
 	return nil
 }
 
@@ -254,6 +315,183 @@ func validateDataModelStructure(dataModel map[string]interface{}) error {
 		}
 	}
 
+	// If enums are present, validate structure and definitions
+	if enums, ok := dataModel["enums"]; ok {
+		if err := validateEnumsStructure(dataModel, enums); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateEnumsStructure validates data_model.enums and checks that enum
+// names, backing types and values can become Go constants
+func validateEnumsStructure(dataModel map[string]interface{}, enums interface{}) error {
+	items, ok := enums.([]interface{})
+	if !ok {
+		return fmt.Errorf("data_model.enums must be an array")
+	}
+
+	var dm models.DataModel
+	for i, item := range items {
+		enumMap, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("data_model.enums[%d] must be an object", i)
+		}
+		values, ok := enumMap["values"].([]interface{})
+		if !ok {
+			return fmt.Errorf("data_model.enums[%d].values must be an array", i)
+		}
+		for j, value := range values {
+			if _, ok := value.(string); !ok {
+				return fmt.Errorf("data_model.enums[%d].values[%d] must be a string", i, j)
+			}
+		}
+		dm.Enums = append(dm.Enums, buildEnum(enumMap))
+	}
+
+	if entities, ok := dataModel["entities"].([]interface{}); ok {
+		for _, item := range entities {
+			if entityMap, ok := item.(map[string]interface{}); ok {
+				dm.Entities = append(dm.Entities, models.Entity{Name: getString(entityMap, "name")})
+			}
+		}
+	}
+
+	if err := dm.ValidateEnums(); err != nil {
+		return fmt.Errorf("data_model.enums: %w", err)
+	}
+	return nil
+}
+
+// validateCrossCuttingStructure validates the cross-cutting concerns, which
+// are listed by name or as objects with a name
+func validateCrossCuttingStructure(cc map[string]interface{}) error {
+	if pkg, ok := cc["package"]; ok {
+		pkgPath, ok := pkg.(string)
+		if !ok {
+			return fmt.Errorf("cross_cutting.package must be a string")
+		}
+		if err := validatePath(pkgPath); err != nil {
+			return fmt.Errorf("cross_cutting.package: %w", err)
+		}
+	}
+
+	if concerns, ok := cc["concerns"]; ok {
+		items, ok := concerns.([]interface{})
+		if !ok {
+			return fmt.Errorf("cross_cutting.concerns must be an array")
+		}
+		for i, item := range items {
+			switch item := item.(type) {
+			case string:
+			case map[string]interface{}:
+				if _, ok := item["name"].(string); !ok {
+					return fmt.Errorf("cross_cutting.concerns[%d].name must be a string", i)
+				}
+			default:
+				return fmt.Errorf("cross_cutting.concerns[%d] must be a name or an object", i)
+			}
+		}
+	}
+
+	return buildCrossCutting(map[string]interface{}{"cross_cutting": cc}).Validate()
+}
+
+// validateContractsStructure validates a list of output contracts, each an
+// object with string fields
+func validateContractsStructure(contracts interface{}) error {
+	items, ok := contracts.([]interface{})
+	if !ok {
+		return fmt.Errorf("contracts must be an array")
+	}
+	for i, item := range items {
+		contractMap, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("contracts[%d] must be an object", i)
+		}
+		for key, value := range contractMap {
+			if _, ok := value.(string); !ok {
+				return fmt.Errorf("contracts[%d].%s must be a string", i, key)
+			}
+		}
+	}
+
+	return models.ValidateContracts(buildContracts(map[string]interface{}{"contracts": contracts}))
+}
+
+// validateFileTreeStructure validates the required file tree, whose entries
+// are paths or objects with a path, against the architecture's packages
+func validateFileTreeStructure(spec *models.InputSpecification, tree map[string]interface{}) error {
+	for _, key := range []string{"directories", "files"} {
+		entries, ok := tree[key]
+		if !ok {
+			continue
+		}
+		items, ok := entries.([]interface{})
+		if !ok {
+			return fmt.Errorf("file_tree.%s must be an array", key)
+		}
+		for i, item := range items {
+			switch item := item.(type) {
+			case string:
+			case map[string]interface{}:
+				if _, ok := item["path"].(string); !ok {
+					return fmt.Errorf("file_tree.%s[%d].path must be a string", key, i)
+				}
+			default:
+				return fmt.Errorf("file_tree.%s[%d] must be a path or an object", key, i)
+			}
+		}
+	}
+
+	arch, err := NewFCSBuilder(spec).buildArchitecture()
+	if err != nil {
+		return err
+	}
+	return buildFileTree(map[string]interface{}{"file_tree": tree}).Validate(arch.Packages)
+}
+
+// validateTestingStrategyStructure validates the testing strategy structure
+func validateTestingStrategyStructure(testing map[string]interface{}) error {
+	if fuzz, ok := testing["fuzz_tests"]; ok {
+		if _, isBool := fuzz.(bool); !isBool {
+			return fmt.Errorf("fuzz_tests must be a boolean")
+		}
+	}
+
+	framework, ok := testing["test_framework"]
+	if !ok {
+		return nil
+	}
+
+	name, ok := framework.(string)
+	if !ok {
+		return fmt.Errorf("test_framework must be a string")
+	}
+	if name != "" && !models.IsTestFramework(name) {
+		return fmt.Errorf("unsupported test_framework %q (supported: %s)", name, strings.Join(models.TestFrameworks, ", "))
+	}
+
+	return nil
+}
+
+// validateBuildConfigStructure validates the build config structure
+func validateBuildConfigStructure(build map[string]interface{}) error {
+	persistence, ok := build["persistence"]
+	if !ok {
+		return nil
+	}
+
+	name, ok := persistence.(string)
+	if !ok {
+		return fmt.Errorf("persistence must be a string")
+	}
+	if name != "" && !models.IsPersistenceStrategy(name) {
+		return fmt.Errorf("unsupported persistence %q (supported: %s)", name, strings.Join(models.PersistenceStrategies, ", "))
+	}
+
 	return nil
 }
 
@@ -265,6 +265,358 @@ func TestValidateSchemaStructure(t *testing.T) {
 			wantErr:     true,
 			errContains: "architecture must be an object",
 		},
+		{
+			name: "Supported test framework",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"testing_strategy": map[string]interface{}{
+						"test_framework": "gomega",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Unsupported test framework",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"testing_strategy": map[string]interface{}{
+						"test_framework": "ginkgo",
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: `unsupported test_framework "ginkgo"`,
+		},
+		{
+			name: "Supported persistence",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"build_config": map[string]interface{}{
+						"persistence": "sqlc",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Unsupported persistence",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"build_config": map[string]interface{}{
+						"persistence": "sqlx",
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: `unsupported persistence "sqlx"`,
+		},
+		{
+			name: "Unsupported HTTP framework",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"architecture": map[string]interface{}{
+						"http_framework": "fiber",
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: `unsupported http_framework "fiber"`,
+		},
+		{
+			name: "Valid enums",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"data_model": map[string]interface{}{
+						"enums": []interface{}{
+							map[string]interface{}{
+								"name":         "OrderStatus",
+								"backing_type": "int",
+								"values":       []interface{}{"pending", "shipped"},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Enum values are not strings",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"data_model": map[string]interface{}{
+						"enums": []interface{}{
+							map[string]interface{}{
+								"name":   "Priority",
+								"values": []interface{}{1, 2},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "data_model.enums[0].values[0] must be a string",
+		},
+		{
+			name: "Enum values collide",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"data_model": map[string]interface{}{
+						"enums": []interface{}{
+							map[string]interface{}{
+								"name":   "OrderStatus",
+								"values": []interface{}{"in_progress", "in-progress"},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "both become OrderStatusInProgress",
+		},
+		{
+			name: "Enum named like an entity",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"data_model": map[string]interface{}{
+						"entities": []interface{}{
+							map[string]interface{}{"name": "Order"},
+						},
+						"enums": []interface{}{
+							map[string]interface{}{
+								"name":   "Order",
+								"values": []interface{}{"open"},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "enum Order has the same name as an entity",
+		},
+		{
+			name: "Unsupported enum backing type",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"data_model": map[string]interface{}{
+						"enums": []interface{}{
+							map[string]interface{}{
+								"name":         "Level",
+								"backing_type": "float64",
+								"values":       []interface{}{"low"},
+							},
+						},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: `unsupported backing_type "float64"`,
+		},
+		{
+			name: "Valid cross-cutting concerns",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"cross_cutting": map[string]interface{}{
+						"package": "internal/http/middleware",
+						"concerns": []interface{}{
+							"logging",
+							map[string]interface{}{
+								"name":     "rate_limit",
+								"settings": map[string]interface{}{"requests_per_second": "10"},
+								"exclude":  []interface{}{"/health"},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Valid file tree",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"architecture": map[string]interface{}{
+						"packages": []interface{}{
+							map[string]interface{}{"name": "store", "path": "internal/store"},
+						},
+					},
+					"file_tree": map[string]interface{}{
+						"directories": []interface{}{"internal/store"},
+						"files": []interface{}{
+							"cmd/app/main.go",
+							map[string]interface{}{"path": "internal/store/store.go", "purpose": "Storage"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "File tree misses an architecture package",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"architecture": map[string]interface{}{
+						"packages": []interface{}{
+							map[string]interface{}{"name": "store", "path": "internal/store"},
+						},
+					},
+					"file_tree": map[string]interface{}{
+						"files": []interface{}{"cmd/app/main.go"},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "package store (internal/store) has no Go file",
+		},
+		{
+			name: "File tree entry is not a path or object",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"file_tree": map[string]interface{}{
+						"files": []interface{}{42},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "file_tree.files[0] must be a path or an object",
+		},
+		{
+			name: "Unsupported cross-cutting concern",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"cross_cutting": map[string]interface{}{
+						"concerns": []interface{}{"logging", "caching"},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: `unsupported concern "caching"`,
+		},
+		{
+			name: "Cross-cutting concern is not a name or object",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"cross_cutting": map[string]interface{}{
+						"concerns": []interface{}{42},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "cross_cutting.concerns[0] must be a name or an object",
+		},
+		{
+			name: "Valid output contracts",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"contracts": []interface{}{
+						map[string]interface{}{"file": "cmd/server/main.go"},
+						map[string]interface{}{"package": "internal/store", "exports": "Repository", "kind": "interface"},
+						map[string]interface{}{"route": "GET /users/{id}"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Output contract with two assertions",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"contracts": []interface{}{
+						map[string]interface{}{"file": "main.go", "route": "GET /"},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "contracts[0]: contract must set exactly one of",
+		},
+		{
+			name: "Output contract field is not a string",
+			spec: &models.InputSpecification{
+				Format: models.FormatYAML,
+				ParsedData: map[string]interface{}{
+					"name":         "TestProject",
+					"description":  "Test",
+					"requirements": []interface{}{},
+					"contracts": []interface{}{
+						map[string]interface{}{"route": 42},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "contracts[0].route must be a string",
+		},
 	}
 
 	for _, tt := range tests {
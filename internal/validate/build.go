@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/dshills/gocreator/internal/models"
+	"golang.org/x/sync/errgroup"
 )
 
 // BuildValidator validates Go compilation
@@ -22,20 +24,63 @@ type BuildValidator interface {
 
 // goBuildValidator implements BuildValidator using go build
 type goBuildValidator struct {
-	timeout time.Duration
+	timeout   time.Duration
+	vet       bool
+	parallel  ParallelValidationConfig
+	eventChan chan<- models.ProgressEvent
+}
+
+// BuildOption configures the build validator
+type BuildOption func(*goBuildValidator)
+
+// WithVet also runs go vet on each package that builds; vet findings are
+// reported as warnings and do not fail the build
+func WithVet(enabled bool) BuildOption {
+	return func(v *goBuildValidator) {
+		v.vet = enabled
+	}
+}
+
+// WithBuildEvents streams a progress event as each package finishes
+func WithBuildEvents(ch chan<- models.ProgressEvent) BuildOption {
+	return func(v *goBuildValidator) {
+		v.eventChan = ch
+	}
+}
+
+// WithBuildParallelism sets how many packages are built concurrently
+func WithBuildParallelism(config ParallelValidationConfig) BuildOption {
+	return func(v *goBuildValidator) {
+		v.parallel = config
+	}
 }
 
 // NewBuildValidator creates a new build validator
-func NewBuildValidator(timeout time.Duration) BuildValidator {
+func NewBuildValidator(timeout time.Duration, opts ...BuildOption) BuildValidator {
 	if timeout == 0 {
 		timeout = 2 * time.Minute // Default 2 minute timeout
 	}
-	return &goBuildValidator{
-		timeout: timeout,
+	v := &goBuildValidator{
+		timeout:  timeout,
+		parallel: DefaultParallelValidationConfig(),
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// packageBuildOutcome holds the raw toolchain output for one package
+type packageBuildOutcome struct {
+	buildOutput string
+	buildErr    error
+	vetOutput   string
+	vetErr      error
 }
 
-// Validate runs go build ./... and parses compilation errors
+// Validate builds every package under projectRoot and parses compilation errors.
+// Packages are built by a bounded worker pool so progress can be reported per
+// package; results are merged in package order to keep reports deterministic.
 func (b *goBuildValidator) Validate(ctx context.Context, projectRoot string) (*models.BuildResult, error) {
 	start := time.Now()
 	result := &models.BuildResult{
@@ -48,40 +93,177 @@ func (b *goBuildValidator) Validate(ctx context.Context, projectRoot string) (*m
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, b.timeout)
 	defer cancel()
 
-	// Run go build ./...
-	cmd := exec.CommandContext(ctxWithTimeout, "go", "build", "./...")
-	cmd.Dir = projectRoot
-
-	output, err := cmd.CombinedOutput()
-	result.Duration = time.Since(start)
-
+	packages, listOutput, err := listPackages(ctxWithTimeout, projectRoot, buildablePackages)
 	if err != nil {
-		// Check if it's a timeout
 		if ctxWithTimeout.Err() == context.DeadlineExceeded {
 			return nil, fmt.Errorf("build timed out after %v", b.timeout)
 		}
 
-		// Parse compilation errors from output
 		result.Success = false
-		errors, warnings := parseCompilationOutput(string(output), projectRoot)
-		result.Errors = errors
-		result.Warnings = warnings
-
-		// If we couldn't parse any errors, create a generic one
-		if len(errors) == 0 && len(warnings) == 0 {
-			result.Errors = []models.CompilationError{
-				{
-					File:    "unknown",
-					Line:    0,
-					Message: fmt.Sprintf("build failed: %v\nOutput: %s", err, string(output)),
-				},
+		b.recordFailure(result, listOutput, err, projectRoot, make(map[string]bool))
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+
+	outcomes := make([]packageBuildOutcome, len(packages))
+
+	g, gctx := errgroup.WithContext(ctxWithTimeout)
+	g.SetLimit(b.parallel.workerLimit())
+
+	for i, pkg := range packages {
+		g.Go(func() error {
+			outcome, err := b.validatePackage(gctx, projectRoot, pkg)
+			if err != nil {
+				return err
 			}
+			outcomes[i] = outcome
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Errors in a shared dependency are reported by every dependent package
+	seen := make(map[string]bool)
+
+	for _, outcome := range outcomes {
+		if outcome.buildErr != nil {
+			result.Success = false
+			b.recordFailure(result, outcome.buildOutput, outcome.buildErr, projectRoot, seen)
+			continue
+		}
+		if outcome.vetErr != nil {
+			b.recordVetFindings(result, outcome.vetOutput, projectRoot)
 		}
 	}
 
+	result.Duration = time.Since(start)
 	return result, nil
 }
 
+// validatePackage builds (and optionally vets) a single package, emitting a
+// progress event for each check. Only a timeout is returned as an error.
+func (b *goBuildValidator) validatePackage(ctx context.Context, projectRoot, pkg string) (packageBuildOutcome, error) {
+	var outcome packageBuildOutcome
+	pkgStart := time.Now()
+
+	//nolint:gosec // G204: Subprocess launched with go build - required for build validation
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", os.DevNull, pkg)
+	cmd.Dir = projectRoot
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return outcome, b.contextError(ctx)
+		}
+
+		outcome.buildOutput, outcome.buildErr = string(output), err
+		emitEvent(b.eventChan, models.NewPackageValidatedEvent(pkg, CheckBuild, false, time.Since(pkgStart), failureExcerpt(string(output))))
+		return outcome, nil
+	}
+
+	emitEvent(b.eventChan, models.NewPackageValidatedEvent(pkg, CheckBuild, true, time.Since(pkgStart), ""))
+
+	if !b.vet {
+		return outcome, nil
+	}
+
+	vetStart := time.Now()
+
+	//nolint:gosec // G204: Subprocess launched with go vet - required for build validation
+	vetCmd := exec.CommandContext(ctx, "go", "vet", pkg)
+	vetCmd.Dir = projectRoot
+
+	vetOutput, err := vetCmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return outcome, b.contextError(ctx)
+		}
+
+		outcome.vetOutput, outcome.vetErr = string(vetOutput), err
+		emitEvent(b.eventChan, models.NewPackageValidatedEvent(pkg, CheckVet, false, time.Since(vetStart), failureExcerpt(string(vetOutput))))
+		return outcome, nil
+	}
+
+	emitEvent(b.eventChan, models.NewPackageValidatedEvent(pkg, CheckVet, true, time.Since(vetStart), ""))
+	return outcome, nil
+}
+
+// contextError reports why a package build was interrupted
+func (b *goBuildValidator) contextError(ctx context.Context) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("build timed out after %v", b.timeout)
+	}
+	return fmt.Errorf("build cancelled: %w", ctx.Err())
+}
+
+// vetPrefix marks the build warnings that are go vet findings
+const vetPrefix = "vet: "
+
+// recordVetFindings records go vet diagnostics as warnings
+func (b *goBuildValidator) recordVetFindings(result *models.BuildResult, output, projectRoot string) {
+	errors, warnings := parseCompilationOutput(output, projectRoot)
+	for _, e := range errors {
+		result.Warnings = append(result.Warnings, models.CompilationWarning{
+			File:    e.File,
+			Line:    e.Line,
+			Message: vetPrefix + e.Message,
+		})
+	}
+	for _, w := range warnings {
+		w.Message = vetPrefix + w.Message
+		result.Warnings = append(result.Warnings, w)
+	}
+}
+
+// VetFindings returns the go vet findings among the warnings of a build
+func VetFindings(result *models.BuildResult) []models.CompilationWarning {
+	var findings []models.CompilationWarning
+	for _, w := range result.Warnings {
+		if strings.HasPrefix(w.Message, vetPrefix) {
+			findings = append(findings, w)
+		}
+	}
+	return findings
+}
+
+// recordFailure parses failed command output into result, skipping diagnostics
+// already recorded for another package
+func (b *goBuildValidator) recordFailure(result *models.BuildResult, output string, cmdErr error, projectRoot string, seen map[string]bool) {
+	errors, warnings := parseCompilationOutput(output, projectRoot)
+
+	// If we couldn't parse any errors, create a generic one
+	if len(errors) == 0 && len(warnings) == 0 {
+		errors = []models.CompilationError{
+			{
+				File:    "unknown",
+				Line:    0,
+				Message: fmt.Sprintf("build failed: %v\nOutput: %s", cmdErr, output),
+			},
+		}
+	}
+
+	for _, e := range errors {
+		key := fmt.Sprintf("%s:%d:%d:%s", e.File, e.Line, e.Column, e.Message)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result.Errors = append(result.Errors, e)
+	}
+
+	for _, w := range warnings {
+		key := fmt.Sprintf("%s:%d:%s", w.File, w.Line, w.Message)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result.Warnings = append(result.Warnings, w)
+	}
+}
+
 // parseCompilationOutput parses go build output for errors and warnings
 // Format: path/to/file.go:line:column: error message
 // or: path/to/file.go:line: error message
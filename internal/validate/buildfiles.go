@@ -0,0 +1,235 @@
+package validate
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+const (
+	// DefaultDockerBuildTimeout bounds docker build when unset
+	DefaultDockerBuildTimeout = 10 * time.Minute
+
+	buildFileToolTimeout = 30 * time.Second
+)
+
+// makeRulePattern matches a rule line of a Makefile and captures its targets,
+// leaving out variable assignments (:=, ::=) and recipe lines
+var makeRulePattern = regexp.MustCompile(`^([^\s:=#][^:=#]*?)\s*::?(?:[^=]|$)`)
+
+// BuildFilesValidator checks the project's Dockerfile and Makefile with the
+// tools that consume them, catching build files that templates and LLM edits
+// broke and that the Go checks never read
+type BuildFilesValidator interface {
+	Validate(ctx context.Context, projectRoot string) (*models.BuildFilesResult, error)
+}
+
+// toolBuildFilesValidator implements BuildFilesValidator with docker build
+// (or hadolint when docker is unavailable) and make -n
+type toolBuildFilesValidator struct {
+	dockerTimeout time.Duration
+}
+
+// BuildFilesOption configures the build files validator
+type BuildFilesOption func(*toolBuildFilesValidator)
+
+// WithDockerBuildTimeout bounds the docker build of the Dockerfile
+// (default: DefaultDockerBuildTimeout)
+func WithDockerBuildTimeout(timeout time.Duration) BuildFilesOption {
+	return func(v *toolBuildFilesValidator) {
+		v.dockerTimeout = timeout
+	}
+}
+
+// NewBuildFilesValidator creates a new build files validator
+func NewBuildFilesValidator(opts ...BuildFilesOption) BuildFilesValidator {
+	v := &toolBuildFilesValidator{dockerTimeout: DefaultDockerBuildTimeout}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if v.dockerTimeout <= 0 {
+		v.dockerTimeout = DefaultDockerBuildTimeout
+	}
+	return v
+}
+
+// Validate checks the Dockerfile and the Makefile at the project root, when
+// present. A file whose tools are not installed is reported as a skipped
+// check and does not fail the result.
+func (v *toolBuildFilesValidator) Validate(ctx context.Context, projectRoot string) (*models.BuildFilesResult, error) {
+	start := time.Now()
+	result := &models.BuildFilesResult{Success: true}
+	defer func() { result.Duration = time.Since(start) }()
+
+	if isRegularFile(filepath.Join(projectRoot, "Dockerfile")) {
+		result.Checks = append(result.Checks, v.checkDockerfile(ctx, projectRoot))
+	}
+	if isRegularFile(filepath.Join(projectRoot, "Makefile")) {
+		checks, err := v.checkMakefile(ctx, projectRoot)
+		if err != nil {
+			return nil, err
+		}
+		result.Checks = append(result.Checks, checks...)
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("build file checks interrupted: %w", ctx.Err())
+	}
+
+	for _, check := range result.Checks {
+		if !check.Skipped && !check.Success {
+			result.Success = false
+		}
+	}
+	return result, nil
+}
+
+// checkDockerfile builds the image when a docker daemon answers, and lints
+// the Dockerfile with hadolint otherwise
+func (v *toolBuildFilesValidator) checkDockerfile(ctx context.Context, projectRoot string) models.BuildFileCheck {
+	check := models.BuildFileCheck{File: "Dockerfile"}
+
+	if docker, err := exec.LookPath("docker"); err == nil && dockerDaemonAvailable(ctx, docker) {
+		check.Tool = "docker"
+		output, err := runBuildFileTool(ctx, v.dockerTimeout, projectRoot, &check, docker, "build", "--quiet", "--rm", "--file", "Dockerfile", ".")
+		if err == nil {
+			// --quiet prints only the image ID; the image itself is not kept
+			if id := strings.TrimSpace(output); id != "" {
+				//nolint:gosec // G204: Removing the image docker build just created
+				_ = exec.CommandContext(ctx, docker, "image", "rm", "--force", id).Run()
+			}
+		}
+		return check
+	}
+
+	if hadolint, err := exec.LookPath("hadolint"); err == nil {
+		check.Tool = "hadolint"
+		_, _ = runBuildFileTool(ctx, buildFileToolTimeout, projectRoot, &check, hadolint, "--no-color", "--failure-threshold", "error", "Dockerfile")
+		return check
+	}
+
+	check.Skipped = true
+	check.Message = "neither a docker daemon nor hadolint is available"
+	return check
+}
+
+// checkMakefile runs make -n for every explicit target, or for the default
+// goal when the Makefile declares none, so syntax errors and missing
+// prerequisites surface without running any recipe
+func (v *toolBuildFilesValidator) checkMakefile(ctx context.Context, projectRoot string) ([]models.BuildFileCheck, error) {
+	makeTool, err := exec.LookPath("make")
+	if err != nil {
+		return []models.BuildFileCheck{{File: "Makefile", Skipped: true, Message: "make is not installed"}}, nil
+	}
+
+	targets, err := makefileTargets(filepath.Join(projectRoot, "Makefile"))
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		targets = []string{""}
+	}
+
+	checks := make([]models.BuildFileCheck, 0, len(targets))
+	for _, target := range targets {
+		check := models.BuildFileCheck{File: "Makefile", Tool: "make"}
+		args := []string{"-n"}
+		if target != "" {
+			args = append(args, target)
+		}
+		_, _ = runBuildFileTool(ctx, buildFileToolTimeout, projectRoot, &check, makeTool, args...)
+		checks = append(checks, check)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return checks, nil
+}
+
+// makefileTargets returns the explicit targets of a Makefile in declaration
+// order. Special targets (.PHONY), pattern rules and targets built from
+// variables are left out.
+func makefileTargets(path string) ([]string, error) {
+	//nolint:gosec // G304: Reading the generated project's Makefile - required for validation
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Makefile: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var targets []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		match := makeRulePattern.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		for _, target := range strings.Fields(match[1]) {
+			if strings.HasPrefix(target, ".") || strings.ContainsAny(target, "%$") || seen[target] {
+				continue
+			}
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Makefile: %w", err)
+	}
+	return targets, nil
+}
+
+// runBuildFileTool runs one tool in the project root and records the outcome
+// on check; it returns the tool's output and error
+func runBuildFileTool(ctx context.Context, timeout time.Duration, projectRoot string, check *models.BuildFileCheck, tool string, args ...string) (string, error) {
+	start := time.Now()
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	//nolint:gosec // G204: Subprocess launched with docker, hadolint or make - required for build file validation
+	cmd := exec.CommandContext(runCtx, tool, args...)
+	cmd.Dir = projectRoot
+	out, err := cmd.CombinedOutput()
+	output := string(out)
+
+	check.Command = strings.TrimSpace(filepath.Base(tool) + " " + strings.Join(args, " "))
+	check.Duration = time.Since(start)
+	switch {
+	case ctx.Err() != nil:
+		check.Skipped = true
+		check.Message = "interrupted"
+	case runCtx.Err() != nil:
+		check.Message = fmt.Sprintf("did not finish within %v", timeout)
+		check.Output = outputTail(output)
+	case err != nil:
+		check.Message = fmt.Sprintf("exited with status %d", processExitCode(err))
+		check.Output = outputTail(output)
+	default:
+		check.Success = true
+	}
+	return output, err
+}
+
+// dockerDaemonAvailable reports whether the docker CLI can reach a daemon;
+// an installed client without one cannot build
+func dockerDaemonAvailable(ctx context.Context, docker string) bool {
+	ctx, cancel := context.WithTimeout(ctx, buildFileToolTimeout)
+	defer cancel()
+
+	//nolint:gosec // G204: Subprocess launched with docker - required for build file validation
+	err := exec.CommandContext(ctx, docker, "version", "--format", "{{.Server.Version}}").Run()
+	return err == nil
+}
+
+// isRegularFile reports whether path names a regular file
+func isRegularFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
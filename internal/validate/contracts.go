@@ -0,0 +1,267 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// ContractValidator checks generated code against the declared output contracts
+type ContractValidator interface {
+	Validate(ctx context.Context, projectRoot string) (*models.ContractResult, error)
+}
+
+// astContractValidator implements ContractValidator by parsing the project's Go files
+type astContractValidator struct {
+	contracts []models.OutputContract
+}
+
+// NewContractValidator creates a validator for contracts
+func NewContractValidator(contracts []models.OutputContract) ContractValidator {
+	return &astContractValidator{contracts: contracts}
+}
+
+// Validate reads the non-test Go files under projectRoot, plus any other files
+// the contracts name, and reports the contracts they violate
+func (v *astContractValidator) Validate(ctx context.Context, projectRoot string) (*models.ContractResult, error) {
+	sources, err := readGoSources(ctx, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, contract := range v.contracts {
+		if contract.File == "" {
+			continue
+		}
+		rel := path.Clean(filepath.ToSlash(contract.File))
+		if _, ok := sources[rel]; ok {
+			continue
+		}
+		//nolint:gosec // G304: Reading a file named by a contract - required for the existence check
+		content, err := os.ReadFile(filepath.Join(projectRoot, filepath.FromSlash(rel)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		sources[rel] = string(content)
+	}
+
+	return CheckContracts(v.contracts, sources), nil
+}
+
+// CheckContracts checks source files (slash-separated path -> content)
+// against contracts. A file contract holds when its path is among the sources;
+// an exports contract when a Go file directly in the package directory
+// declares the identifier with the required kind; a route contract when a
+// call such as mux.HandleFunc("GET /users/{id}", ...) or r.Get("/users/:id",
+// ...) registers the method and path. Path parameters match whatever their
+// names, and routes must be registered with their full path. Go files that do
+// not parse are left to build validation.
+func CheckContracts(contracts []models.OutputContract, sources map[string]string) *models.ContractResult {
+	result := &models.ContractResult{Checked: len(contracts)}
+	if len(contracts) == 0 {
+		result.Success = true
+		return result
+	}
+
+	paths := make([]string, 0, len(sources))
+	for p := range sources {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	files := make(map[string]*ast.File)
+	var routes []registeredRoute
+	for _, p := range paths {
+		if !strings.HasSuffix(p, ".go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), p, sources[p], 0)
+		if err != nil {
+			continue
+		}
+		files[p] = file
+		routes = append(routes, collectRoutes(file)...)
+	}
+
+	for _, contract := range contracts {
+		var message string
+		switch {
+		case contract.File != "":
+			if _, ok := sources[path.Clean(filepath.ToSlash(contract.File))]; !ok {
+				message = "file does not exist"
+			}
+		case contract.Route != "":
+			message = checkRouteContract(contract, routes)
+		default:
+			message = checkExportsContract(contract, paths, files)
+		}
+		if message != "" {
+			result.Violations = append(result.Violations, models.ContractViolation{
+				Contract: contract.String(),
+				Message:  message,
+			})
+		}
+	}
+
+	result.Success = len(result.Violations) == 0
+	return result
+}
+
+// checkExportsContract reports why the package does not declare the identifier,
+// or "" when it does
+func checkExportsContract(contract models.OutputContract, paths []string, files map[string]*ast.File) string {
+	pkg := path.Clean(filepath.ToSlash(contract.Package))
+	found := false
+	var kinds []string
+	for _, p := range paths {
+		file, ok := files[p]
+		if !ok || path.Dir(p) != pkg {
+			continue
+		}
+		found = true
+		for _, kind := range declarationKinds(file, contract.Exports) {
+			if contract.Kind == "" || kind == contract.Kind || (contract.Kind == models.ContractKindType && isTypeKind(kind)) {
+				return ""
+			}
+			kinds = append(kinds, kind)
+		}
+	}
+
+	switch {
+	case !found:
+		return fmt.Sprintf("package %s has no Go files", pkg)
+	case len(kinds) > 0:
+		return fmt.Sprintf("%s is declared as %s, not %s", contract.Exports, kinds[0], contract.Kind)
+	default:
+		return fmt.Sprintf("%s is not declared in %s", contract.Exports, pkg)
+	}
+}
+
+// declarationKinds returns the kinds of the top-level declarations of name in file
+func declarationKinds(file *ast.File, name string) []string {
+	var kinds []string
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil && d.Name.Name == name {
+				kinds = append(kinds, models.ContractKindFunc)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.Name != name {
+						continue
+					}
+					switch s.Type.(type) {
+					case *ast.InterfaceType:
+						kinds = append(kinds, models.ContractKindInterface)
+					case *ast.StructType:
+						kinds = append(kinds, models.ContractKindStruct)
+					default:
+						kinds = append(kinds, models.ContractKindType)
+					}
+				case *ast.ValueSpec:
+					for _, ident := range s.Names {
+						if ident.Name != name {
+							continue
+						}
+						if d.Tok == token.CONST {
+							kinds = append(kinds, models.ContractKindConst)
+						} else {
+							kinds = append(kinds, models.ContractKindVar)
+						}
+					}
+				}
+			}
+		}
+	}
+	return kinds
+}
+
+// isTypeKind reports whether kind is a type declaration
+func isTypeKind(kind string) bool {
+	return kind == models.ContractKindInterface || kind == models.ContractKindStruct || kind == models.ContractKindType
+}
+
+// registeredRoute is a handler registration found in the source
+type registeredRoute struct {
+	method string // Upper-case method, or "" when the route accepts any method
+	path   string // Normalized path
+}
+
+// checkRouteContract reports a route contract no registration satisfies, or ""
+func checkRouteContract(contract models.OutputContract, routes []registeredRoute) string {
+	method, routePath := contract.RouteParts()
+	routePath = normalizeRoutePath(routePath)
+	for _, route := range routes {
+		if route.path == routePath && (route.method == "" || route.method == method) {
+			return ""
+		}
+	}
+	return "no handler is registered for this route"
+}
+
+// collectRoutes returns the routes file registers
+func collectRoutes(file *ast.File) []registeredRoute {
+	var routes []registeredRoute
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !isRouteRegistration(call) {
+			return true
+		}
+		pattern, err := strconv.Unquote(call.Args[0].(*ast.BasicLit).Value)
+		if err != nil {
+			return true
+		}
+
+		route := registeredRoute{}
+		switch name := call.Fun.(*ast.SelectorExpr).Sel.Name; name {
+		case "Handle", "HandleFunc":
+			// net/http patterns may start with a method: "GET /users/{id}"
+			if method, rest, ok := strings.Cut(pattern, " "); ok {
+				route.method = strings.ToUpper(method)
+				pattern = strings.TrimSpace(rest)
+			}
+		default:
+			route.method = strings.ToUpper(name)
+		}
+		if i := strings.Index(pattern, "/"); i > 0 {
+			pattern = pattern[i:] // Drop a host: "example.com/users"
+		}
+		route.path = normalizeRoutePath(pattern)
+		routes = append(routes, route)
+		return true
+	})
+	return routes
+}
+
+// normalizeRoutePath replaces path parameters ({id}, {id:[0-9]+}, :id) with
+// {} and drops the trailing slash and net/http's {$} end marker
+func normalizeRoutePath(p string) string {
+	p = strings.TrimSuffix(p, "{$}")
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || (strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) {
+			segments[i] = "{}"
+		}
+	}
+	p = strings.Join(segments, "/")
+	if len(p) > 1 {
+		p = strings.TrimSuffix(p, "/")
+	}
+	return p
+}
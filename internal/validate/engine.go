@@ -21,8 +21,18 @@ type Engine struct {
 	buildValidator BuildValidator
 	lintValidator  LintValidator
 	testValidator  TestValidator
+	reqValidator   RequirementValidator
+	enumValidator  EnumValidator
+	mwValidator    MiddlewareValidator
+	ctValidator    ContractValidator
+	styleValidator StyleValidator
+	smokeValidator SmokeValidator
+	fuzzValidator  FuzzValidator
+	bfValidator    BuildFilesValidator
 	reportGen      ReportGenerator
 	concurrent     bool
+	eventChan      chan<- models.ProgressEvent
+	parallel       ParallelValidationConfig
 }
 
 // EngineOption configures the validation engine
@@ -49,6 +59,74 @@ func WithTestValidator(v TestValidator) EngineOption {
 	}
 }
 
+// WithRequirementValidator enables the requirement-to-test coverage check.
+// Untested requirements fail the overall validation.
+func WithRequirementValidator(v RequirementValidator) EngineOption {
+	return func(e *Engine) {
+		e.reqValidator = v
+	}
+}
+
+// WithEnumValidator enables the enum declaration and usage check. Missing
+// enum helpers or fields typed with the raw backing type fail the overall
+// validation.
+func WithEnumValidator(v EnumValidator) EngineOption {
+	return func(e *Engine) {
+		e.enumValidator = v
+	}
+}
+
+// WithMiddlewareValidator enables the cross-cutting middleware check. Missing
+// middleware or route registrations that skip it fail the overall validation.
+func WithMiddlewareValidator(v MiddlewareValidator) EngineOption {
+	return func(e *Engine) {
+		e.mwValidator = v
+	}
+}
+
+// WithContractValidator enables the output contract check. Violated contracts
+// fail the overall validation.
+func WithContractValidator(v ContractValidator) EngineOption {
+	return func(e *Engine) {
+		e.ctValidator = v
+	}
+}
+
+// WithStyleValidator enables the house style check. Code that breaks a
+// style rule fails the overall validation.
+func WithStyleValidator(v StyleValidator) EngineOption {
+	return func(e *Engine) {
+		e.styleValidator = v
+	}
+}
+
+// WithSmokeValidator enables a smoke run of the built executable after the
+// other checks. It only runs when the build succeeded; a failed run fails the
+// overall validation.
+func WithSmokeValidator(v SmokeValidator) EngineOption {
+	return func(e *Engine) {
+		e.smokeValidator = v
+	}
+}
+
+// WithFuzzValidator enables a short go test -fuzz run of every fuzz target
+// after the other checks. It only runs when the build succeeded; a target that
+// fails fails the overall validation.
+func WithFuzzValidator(v FuzzValidator) EngineOption {
+	return func(e *Engine) {
+		e.fuzzValidator = v
+	}
+}
+
+// WithBuildFilesValidator enables the Dockerfile and Makefile checks after
+// the other checks. They run whether or not the build succeeded; a failed
+// check fails the overall validation.
+func WithBuildFilesValidator(v BuildFilesValidator) EngineOption {
+	return func(e *Engine) {
+		e.bfValidator = v
+	}
+}
+
 // WithReportGenerator sets a custom report generator
 func WithReportGenerator(g ReportGenerator) EngineOption {
 	return func(e *Engine) {
@@ -65,20 +143,48 @@ func WithConcurrentValidation(concurrent bool) EngineOption {
 	}
 }
 
+// WithEventChan streams per-package progress events from the default
+// build and test validators. Custom validators are left untouched.
+func WithEventChan(ch chan<- models.ProgressEvent) EngineOption {
+	return func(e *Engine) {
+		e.eventChan = ch
+	}
+}
+
+// WithPackageParallelism sets per-package concurrency for the default build
+// and test validators
+func WithPackageParallelism(config ParallelValidationConfig) EngineOption {
+	return func(e *Engine) {
+		e.parallel = config
+	}
+}
+
 // NewEngine creates a new validation engine with default validators
 func NewEngine(opts ...EngineOption) *Engine {
 	e := &Engine{
-		buildValidator: NewBuildValidator(2 * time.Minute),
-		lintValidator:  NewLintValidator(WithSkipIfNotFound(true)),
-		testValidator:  NewTestValidator(WithTestTimeout(5 * time.Minute)),
-		reportGen:      NewReportGenerator(),
-		concurrent:     true, // Default to concurrent execution
+		lintValidator: NewLintValidator(WithSkipIfNotFound(true)),
+		reportGen:     NewReportGenerator(),
+		concurrent:    true, // Default to concurrent execution
+		parallel:      DefaultParallelValidationConfig(),
 	}
 
 	for _, opt := range opts {
 		opt(e)
 	}
 
+	// Default validators are created after options so they can pick up the event channel
+	if e.buildValidator == nil {
+		e.buildValidator = NewBuildValidator(2*time.Minute,
+			WithBuildEvents(e.eventChan),
+			WithBuildParallelism(e.parallel))
+	}
+	if e.testValidator == nil {
+		e.testValidator = NewTestValidator(
+			WithTestTimeout(5*time.Minute),
+			WithTestEvents(e.eventChan),
+			WithTestParallelism(e.parallel))
+	}
+
 	return e
 }
 
@@ -119,6 +225,78 @@ func (e *Engine) ValidateWithOutputID(ctx context.Context, projectRoot, outputID
 		return nil, fmt.Errorf("failed to generate report: %w", err)
 	}
 
+	if e.reqValidator != nil {
+		coverage, err := e.reqValidator.Validate(ctx, projectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("requirement coverage validation failed: %w", err)
+		}
+		report.RequirementCoverage = coverage
+		report.OverallStatus = report.ComputeOverallStatus()
+	}
+
+	if e.enumValidator != nil {
+		usage, err := e.enumValidator.Validate(ctx, projectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("enum usage validation failed: %w", err)
+		}
+		report.EnumUsage = usage
+		report.OverallStatus = report.ComputeOverallStatus()
+	}
+
+	if e.mwValidator != nil {
+		usage, err := e.mwValidator.Validate(ctx, projectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("middleware usage validation failed: %w", err)
+		}
+		report.MiddlewareUsage = usage
+		report.OverallStatus = report.ComputeOverallStatus()
+	}
+
+	if e.ctValidator != nil {
+		contracts, err := e.ctValidator.Validate(ctx, projectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("contract validation failed: %w", err)
+		}
+		report.Contracts = contracts
+		report.OverallStatus = report.ComputeOverallStatus()
+	}
+
+	if e.styleValidator != nil {
+		style, err := e.styleValidator.Validate(ctx, projectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("style validation failed: %w", err)
+		}
+		report.Style = style
+		report.OverallStatus = report.ComputeOverallStatus()
+	}
+
+	if e.smokeValidator != nil && buildResult.Success {
+		smoke, err := e.smokeValidator.Validate(ctx, projectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("smoke validation failed: %w", err)
+		}
+		report.SmokeResult = smoke
+		report.OverallStatus = report.ComputeOverallStatus()
+	}
+
+	if e.fuzzValidator != nil && buildResult.Success {
+		fuzz, err := e.fuzzValidator.Validate(ctx, projectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("fuzz validation failed: %w", err)
+		}
+		report.FuzzResult = fuzz
+		report.OverallStatus = report.ComputeOverallStatus()
+	}
+
+	if e.bfValidator != nil {
+		buildFiles, err := e.bfValidator.Validate(ctx, projectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("build file validation failed: %w", err)
+		}
+		report.BuildFiles = buildFiles
+		report.OverallStatus = report.ComputeOverallStatus()
+	}
+
 	return report, nil
 }
 
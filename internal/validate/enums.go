@@ -0,0 +1,296 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// EnumValidator checks that generated code declares the specification's enums
+// and types entity fields with them rather than with raw strings or integers
+type EnumValidator interface {
+	Validate(ctx context.Context, projectRoot string) (*models.EnumUsage, error)
+}
+
+// astEnumValidator implements EnumValidator by parsing the project's Go files
+type astEnumValidator struct {
+	dataModel models.DataModel
+}
+
+// NewEnumValidator creates a validator for the enums in dataModel
+func NewEnumValidator(dataModel models.DataModel) EnumValidator {
+	return &astEnumValidator{dataModel: dataModel}
+}
+
+// Validate parses the non-test Go files under projectRoot and reports enum issues
+func (v *astEnumValidator) Validate(ctx context.Context, projectRoot string) (*models.EnumUsage, error) {
+	sources, err := readGoSources(ctx, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	return CheckEnumUsage(v.dataModel, sources), nil
+}
+
+// readGoSources reads the non-test Go files under projectRoot, keyed by
+// slash-separated path relative to the root. Vendored and hidden directories
+// are skipped.
+func readGoSources(ctx context.Context, projectRoot string) (map[string]string, error) {
+	return readGoFiles(ctx, projectRoot, false)
+}
+
+// readGoFiles reads the Go files under projectRoot as readGoSources does,
+// including test files when tests is set
+func readGoFiles(ctx context.Context, projectRoot string, tests bool) (map[string]string, error) {
+	sources := make(map[string]string)
+
+	err := filepath.WalkDir(projectRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if path != projectRoot && (name == "vendor" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(d.Name(), ".go") || (!tests && strings.HasSuffix(d.Name(), "_test.go")) {
+			return nil
+		}
+
+		//nolint:gosec // G304: Reading generated source files - required for AST checks
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		rel, err := filepath.Rel(projectRoot, path)
+		if err != nil {
+			rel = path
+		}
+		sources[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan source files: %w", err)
+	}
+	return sources, nil
+}
+
+// declaration is where a Go identifier is declared
+type declaration struct {
+	file  string
+	line  int
+	expr  ast.Expr // Underlying type for type declarations
+	alias bool
+}
+
+// enumDeclarations collects the declarations CheckEnumUsage looks for
+type enumDeclarations struct {
+	types   map[string]declaration
+	structs map[string]map[string]declaration // Struct name -> field name -> field type
+	methods map[string]bool                   // "Type.Method"
+	funcs   map[string]bool
+	consts  map[string]bool
+}
+
+// CheckEnumUsage checks source files (path -> content) against the enums in
+// dataModel. Each enum needs a type over its backing type, a constant per
+// value, a String method and a Parse function, and entity struct fields for
+// enum-typed attributes must use the enum type. Files that do not parse are
+// left to build validation.
+func CheckEnumUsage(dataModel models.DataModel, sources map[string]string) *models.EnumUsage {
+	usage := &models.EnumUsage{TotalEnums: len(dataModel.Enums)}
+	if len(dataModel.Enums) == 0 {
+		usage.Success = true
+		return usage
+	}
+
+	decls := collectDeclarations(sources)
+
+	for _, e := range dataModel.Enums {
+		usage.Issues = append(usage.Issues, checkEnumDeclaration(e, decls)...)
+	}
+
+	for _, entity := range dataModel.Entities {
+		fields, ok := decls.structs[entity.Name]
+		if !ok {
+			continue
+		}
+		attrs := dataModel.EnumAttributes(entity)
+		names := make([]string, 0, len(attrs))
+		for attr := range attrs {
+			names = append(names, attr)
+		}
+		sort.Strings(names)
+
+		for _, attr := range names {
+			enum := attrs[attr]
+			field, ok := fields[normalizeFieldName(attr)]
+			if !ok {
+				continue
+			}
+			if got := baseIdent(field.expr); got != enum {
+				usage.Issues = append(usage.Issues, models.EnumIssue{
+					Enum:    enum,
+					File:    field.file,
+					Line:    field.line,
+					Message: fmt.Sprintf("field %s.%s has type %s, want %s", entity.Name, attr, types.ExprString(field.expr), enum),
+				})
+			}
+		}
+	}
+
+	usage.Success = len(usage.Issues) == 0
+	return usage
+}
+
+// checkEnumDeclaration reports what is missing from the declaration of e
+func checkEnumDeclaration(e models.Enum, decls *enumDeclarations) []models.EnumIssue {
+	decl, ok := decls.types[e.Name]
+	if !ok {
+		return []models.EnumIssue{{Enum: e.Name, Message: fmt.Sprintf("type %s is not declared", e.Name)}}
+	}
+
+	var issues []models.EnumIssue
+	issue := func(format string, args ...interface{}) {
+		issues = append(issues, models.EnumIssue{Enum: e.Name, File: decl.file, Line: decl.line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if decl.alias {
+		issue("type %s is an alias, want a defined type", e.Name)
+	} else if got := types.ExprString(decl.expr); got != e.Backing() {
+		issue("type %s is declared over %s, want %s", e.Name, got, e.Backing())
+	}
+	for _, value := range e.Values {
+		if name := e.ConstName(value); !decls.consts[name] {
+			issue("constant %s for value %q is not declared", name, value)
+		}
+	}
+	if !decls.methods[e.Name+".String"] {
+		issue("type %s has no String method", e.Name)
+	}
+	if !decls.funcs[e.ParseFunc()] {
+		issue("function %s is not declared", e.ParseFunc())
+	}
+	return issues
+}
+
+// collectDeclarations parses sources and records top-level declarations
+func collectDeclarations(sources map[string]string) *enumDeclarations {
+	decls := &enumDeclarations{
+		types:   make(map[string]declaration),
+		structs: make(map[string]map[string]declaration),
+		methods: make(map[string]bool),
+		funcs:   make(map[string]bool),
+		consts:  make(map[string]bool),
+	}
+
+	paths := make([]string, 0, len(sources))
+	for path := range sources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, sources[path], 0)
+		if err != nil {
+			continue
+		}
+
+		for _, d := range file.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					decls.funcs[d.Name.Name] = true
+				} else if len(d.Recv.List) > 0 {
+					decls.methods[baseIdent(d.Recv.List[0].Type)+"."+d.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				collectGenDecl(fset, path, d, decls)
+			}
+		}
+	}
+	return decls
+}
+
+// collectGenDecl records the types, struct fields and constants in d
+func collectGenDecl(fset *token.FileSet, path string, d *ast.GenDecl, decls *enumDeclarations) {
+	for _, spec := range d.Specs {
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			decls.types[spec.Name.Name] = declaration{
+				file:  path,
+				line:  fset.Position(spec.Pos()).Line,
+				expr:  spec.Type,
+				alias: spec.Assign.IsValid(),
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			fields := make(map[string]declaration)
+			for _, field := range st.Fields.List {
+				for _, name := range field.Names {
+					fields[normalizeFieldName(name.Name)] = declaration{file: path, line: fset.Position(name.Pos()).Line, expr: field.Type}
+				}
+			}
+			decls.structs[spec.Name.Name] = fields
+		case *ast.ValueSpec:
+			if d.Tok != token.CONST {
+				continue
+			}
+			for _, name := range spec.Names {
+				decls.consts[name.Name] = true
+			}
+		}
+	}
+}
+
+// normalizeFieldName folds an attribute or field name so "order_status"
+// matches OrderStatus
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, name))
+}
+
+// baseIdent returns the named type at the core of a type expression, so
+// []*domain.Status gives Status
+func baseIdent(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return baseIdent(t.X)
+	case *ast.ArrayType:
+		return baseIdent(t.Elt)
+	case *ast.MapType:
+		return baseIdent(t.Value)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return baseIdent(t.X)
+	}
+	return ""
+}
@@ -0,0 +1,202 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// DefaultFuzzTime is how long each fuzz target runs when unset
+const DefaultFuzzTime = 5 * time.Second
+
+// fuzzInputPattern matches the line go test prints when fuzzing finds a failing input
+var fuzzInputPattern = regexp.MustCompile(`(?m)Failing input written to (\S+)`)
+
+// FuzzValidator runs each fuzz target in the project briefly, catching
+// parsers and decoders that panic on malformed input
+type FuzzValidator interface {
+	Validate(ctx context.Context, projectRoot string) (*models.FuzzResult, error)
+}
+
+// goFuzzValidator implements FuzzValidator with go test -fuzz, one target at a time
+type goFuzzValidator struct {
+	fuzzTime time.Duration
+}
+
+// FuzzOption configures the fuzz validator
+type FuzzOption func(*goFuzzValidator)
+
+// WithFuzzTime sets how long each fuzz target runs (default: DefaultFuzzTime)
+func WithFuzzTime(d time.Duration) FuzzOption {
+	return func(v *goFuzzValidator) {
+		v.fuzzTime = d
+	}
+}
+
+// NewFuzzValidator creates a new fuzz validator
+func NewFuzzValidator(opts ...FuzzOption) FuzzValidator {
+	v := &goFuzzValidator{fuzzTime: DefaultFuzzTime}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if v.fuzzTime <= 0 {
+		v.fuzzTime = DefaultFuzzTime
+	}
+	return v
+}
+
+// fuzzPackage is a package directory and the fuzz targets declared in its tests
+type fuzzPackage struct {
+	dir     string // Relative to the project root, e.g. ./internal/config
+	targets []string
+}
+
+// Validate runs go test -fuzz for every fuzz target in turn; go test runs
+// one target per invocation and fuzzing already uses every CPU. A project
+// without fuzz targets passes with Targets == 0. Crashing inputs are left in
+// the package's testdata/fuzz directory, where go test replays them.
+func (v *goFuzzValidator) Validate(ctx context.Context, projectRoot string) (*models.FuzzResult, error) {
+	start := time.Now()
+	result := &models.FuzzResult{Success: true, FuzzTime: v.fuzzTime}
+	defer func() { result.Duration = time.Since(start) }()
+
+	packages, err := findFuzzPackages(ctx, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range packages {
+		for _, target := range pkg.targets {
+			result.Targets++
+			output, err := v.run(ctx, projectRoot, pkg.dir, target)
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("fuzz run interrupted: %w", ctx.Err())
+			}
+			if err == nil {
+				continue
+			}
+
+			message := fmt.Sprintf("exited with status %d", processExitCode(err))
+			if match := fuzzInputPattern.FindStringSubmatch(output); match != nil {
+				message = "found a failing input: " + match[1]
+			}
+			result.Success = false
+			result.Failures = append(result.Failures, models.FuzzFailure{
+				Package: pkg.dir,
+				Target:  target,
+				Message: message,
+				Output:  outputTail(output),
+			})
+		}
+	}
+	return result, nil
+}
+
+// run fuzzes one target, allowing time to build the test binary
+func (v *goFuzzValidator) run(ctx context.Context, projectRoot, dir, target string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, v.fuzzTime+smokeBuildTimeout)
+	defer cancel()
+
+	//nolint:gosec // G204: Subprocess launched with go test - required for fuzz validation
+	cmd := exec.CommandContext(ctx, "go", "test", "-run=^$", "-fuzz=^"+target+"$", "-fuzztime="+v.fuzzTime.String(), dir)
+	cmd.Dir = projectRoot
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// findFuzzPackages returns the packages under projectRoot whose test files
+// declare fuzz targets, sorted by directory with targets in name order.
+// Vendored and hidden directories and testdata are skipped.
+func findFuzzPackages(ctx context.Context, projectRoot string) ([]fuzzPackage, error) {
+	targets := make(map[string][]string)
+
+	err := filepath.WalkDir(projectRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if path != projectRoot && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), "_test.go") {
+			return nil
+		}
+
+		//nolint:gosec // G304: Reading generated test files - required to find fuzz targets
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		names := fuzzTargetNames(string(content))
+		if len(names) == 0 {
+			return nil
+		}
+
+		rel, err := filepath.Rel(projectRoot, filepath.Dir(path))
+		if err != nil {
+			rel = filepath.Dir(path)
+		}
+		dir := "./" + filepath.ToSlash(rel)
+		if rel == "." {
+			dir = "."
+		}
+		targets[dir] = append(targets[dir], names...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan test files: %w", err)
+	}
+
+	packages := make([]fuzzPackage, 0, len(targets))
+	for dir, names := range targets {
+		sort.Strings(names)
+		packages = append(packages, fuzzPackage{dir: dir, targets: names})
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].dir < packages[j].dir })
+	return packages, nil
+}
+
+// fuzzTargetNames returns the fuzz functions declared in a test file:
+// top-level FuzzXxx functions taking a single *testing.F. Files that do not
+// parse are left to test validation.
+func fuzzTargetNames(code string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, "Fuzz") || len(fn.Type.Params.List) != 1 {
+			continue
+		}
+		star, ok := fn.Type.Params.List[0].Type.(*ast.StarExpr)
+		if !ok {
+			continue
+		}
+		if sel, ok := star.X.(*ast.SelectorExpr); ok && sel.Sel.Name == "F" {
+			names = append(names, fn.Name.Name)
+		}
+	}
+	return names
+}
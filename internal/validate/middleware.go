@@ -0,0 +1,186 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// MiddlewareValidator checks that generated code declares the cross-cutting
+// middleware and wraps every registered route with it
+type MiddlewareValidator interface {
+	Validate(ctx context.Context, projectRoot string) (*models.MiddlewareUsage, error)
+}
+
+// astMiddlewareValidator implements MiddlewareValidator by parsing the project's Go files
+type astMiddlewareValidator struct {
+	crossCutting models.CrossCutting
+}
+
+// NewMiddlewareValidator creates a validator for the concerns in cc
+func NewMiddlewareValidator(cc models.CrossCutting) MiddlewareValidator {
+	return &astMiddlewareValidator{crossCutting: cc}
+}
+
+// Validate parses the non-test Go files under projectRoot and reports middleware issues
+func (v *astMiddlewareValidator) Validate(ctx context.Context, projectRoot string) (*models.MiddlewareUsage, error) {
+	sources, err := readGoSources(ctx, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	return CheckMiddlewareUsage(v.crossCutting, sources), nil
+}
+
+// routeMethods are the router methods whose calls register a handler
+var routeMethods = map[string]bool{
+	"Handle": true, "HandleFunc": true,
+	"Get": true, "Post": true, "Put": true, "Patch": true, "Delete": true,
+	"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true,
+}
+
+// CheckMiddlewareUsage checks source files (path -> content) against the
+// concerns in cc. The middleware package must declare an exported function per
+// concern, and every other file that registers routes must reference each of
+// them through its import of the middleware package. A route registration is
+// a call such as mux.HandleFunc or r.Get whose first argument is a string
+// literal path. Files that do not parse are left to build validation.
+func CheckMiddlewareUsage(cc models.CrossCutting, sources map[string]string) *models.MiddlewareUsage {
+	pkg := cc.MiddlewarePackage()
+	usage := &models.MiddlewareUsage{Package: pkg}
+	if !cc.Enabled() {
+		usage.Success = true
+		return usage
+	}
+
+	paths := make([]string, 0, len(sources))
+	for p := range sources {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	declared := make(map[string]bool)
+	var routeFiles []routeFile
+	for _, p := range paths {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, p, sources[p], 0)
+		if err != nil {
+			continue
+		}
+
+		if path.Dir(p) == pkg {
+			for _, d := range file.Decls {
+				if fn, ok := d.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.IsExported() {
+					declared[fn.Name.Name] = true
+				}
+			}
+			continue
+		}
+
+		if rf, ok := inspectRoutes(fset, p, file, pkg); ok {
+			routeFiles = append(routeFiles, rf)
+		}
+	}
+
+	ordered := cc.Ordered()
+	alias := path.Base(pkg)
+	for _, concern := range ordered {
+		if !declared[concern.Func()] {
+			usage.Issues = append(usage.Issues, models.MiddlewareIssue{
+				Concern: concern.Name,
+				Message: fmt.Sprintf("middleware %s.%s is not declared in %s", alias, concern.Func(), pkg),
+			})
+		}
+	}
+
+	for _, rf := range routeFiles {
+		wrapped := true
+		for _, concern := range ordered {
+			if rf.referenced[concern.Func()] {
+				continue
+			}
+			wrapped = false
+			usage.Issues = append(usage.Issues, models.MiddlewareIssue{
+				Concern: concern.Name,
+				File:    rf.file,
+				Line:    rf.line,
+				Message: fmt.Sprintf("routes are registered without %s.%s", alias, concern.Func()),
+			})
+		}
+		if wrapped {
+			usage.Wrapped = append(usage.Wrapped, rf.file)
+		}
+	}
+
+	usage.Success = len(usage.Issues) == 0
+	return usage
+}
+
+// routeFile is a file that registers routes and the middleware it references
+type routeFile struct {
+	file       string
+	line       int             // First route registration
+	referenced map[string]bool // Middleware functions referenced through the import
+}
+
+// inspectRoutes reports whether file registers routes and, if so, which
+// functions of the middleware package pkg it references
+func inspectRoutes(fset *token.FileSet, p string, file *ast.File, pkg string) (routeFile, bool) {
+	rf := routeFile{file: p, referenced: make(map[string]bool)}
+	alias := middlewareImportName(file, pkg)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			if rf.line == 0 && isRouteRegistration(n) {
+				rf.line = fset.Position(n.Pos()).Line
+			}
+		case *ast.SelectorExpr:
+			if x, ok := n.X.(*ast.Ident); ok && alias != "" && x.Name == alias {
+				rf.referenced[n.Sel.Name] = true
+			}
+		}
+		return true
+	})
+	return rf, rf.line > 0
+}
+
+// isRouteRegistration reports whether call registers a handler for a path
+func isRouteRegistration(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !routeMethods[sel.Sel.Name] || len(call.Args) < 2 {
+		return false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return false
+	}
+	pattern, err := strconv.Unquote(lit.Value)
+	return err == nil && strings.Contains(pattern, "/")
+}
+
+// middlewareImportName returns the name file uses for the middleware package
+// pkg, or "" when it does not import it
+func middlewareImportName(file *ast.File, pkg string) string {
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || (importPath != pkg && !strings.HasSuffix(importPath, "/"+pkg)) {
+			continue
+		}
+		if imp.Name != nil {
+			if imp.Name.Name == "_" || imp.Name.Name == "." {
+				return ""
+			}
+			return imp.Name.Name
+		}
+		return path.Base(pkg)
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package validate
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ParallelValidationConfig holds configuration for per-package parallel validation
+type ParallelValidationConfig struct {
+	// MaxParallel limits the number of packages built or tested at once
+	// Default: 4 (each worker runs its own go toolchain process)
+	MaxParallel int
+
+	// EnableParallel controls whether packages are validated concurrently
+	// If false, packages are validated one at a time
+	EnableParallel bool
+}
+
+// DefaultParallelValidationConfig returns default parallel validation configuration
+func DefaultParallelValidationConfig() ParallelValidationConfig {
+	return ParallelValidationConfig{
+		MaxParallel:    4,
+		EnableParallel: true,
+	}
+}
+
+// workerLimit returns the number of concurrent workers the config allows
+func (c ParallelValidationConfig) workerLimit() int {
+	if !c.EnableParallel {
+		return 1
+	}
+	if c.MaxParallel <= 0 {
+		return 4
+	}
+	return c.MaxParallel
+}
+
+// mergeCoverageProfiles concatenates per-package coverage profiles into dest,
+// keeping a single mode header. Missing profiles are skipped since packages
+// without tests may not write one.
+func mergeCoverageProfiles(dest string, profiles []string) error {
+	var sb strings.Builder
+	mode := ""
+
+	for _, profile := range profiles {
+		//nolint:gosec // G304: Reading coverage profile written by go test
+		file, err := os.Open(profile)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to open coverage profile: %w", err)
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "mode:") {
+				if mode == "" {
+					mode = line
+				}
+				continue
+			}
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+		scanErr := scanner.Err()
+		_ = file.Close()
+		if scanErr != nil {
+			return fmt.Errorf("failed to read coverage profile: %w", scanErr)
+		}
+	}
+
+	if mode == "" {
+		mode = "mode: set"
+	}
+
+	if err := os.WriteFile(dest, []byte(mode+"\n"+sb.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write coverage profile: %w", err)
+	}
+
+	return nil
+}
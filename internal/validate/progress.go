@@ -0,0 +1,124 @@
+package validate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// Check names reported in package validated events
+const (
+	CheckBuild = "build"
+	CheckVet   = "vet"
+	CheckTest  = "test"
+)
+
+// maxExcerptLines bounds the failure output attached to a progress event
+const maxExcerptLines = 10
+
+// emitEvent sends a progress event without blocking validation
+func emitEvent(ch chan<- models.ProgressEvent, event models.ProgressEvent) {
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- event:
+	default:
+		// Channel full, drop event rather than stall the go toolchain
+	}
+}
+
+// failureExcerpt returns the first few non-empty lines of command output
+func failureExcerpt(output string) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) == maxExcerptLines {
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// go list templates selecting which packages a check runs against.
+// Test-only packages are excluded from builds since go build rejects them.
+const (
+	buildablePackages = "{{if or .GoFiles .CgoFiles}}{{.ImportPath}}{{end}}"
+	testablePackages  = "{{if or .GoFiles .CgoFiles .TestGoFiles .XTestGoFiles}}{{.ImportPath}}{{end}}"
+	mainPackages      = "{{if and (eq .Name \"main\") (or .GoFiles .CgoFiles)}}{{.ImportPath}}{{end}}"
+)
+
+// listPackages returns the import paths of packages under projectRoot matching
+// the given go list template, in go list's (sorted) order
+func listPackages(ctx context.Context, projectRoot, filter string) ([]string, string, error) {
+	args := append([]string{"list", "-e", "-f", filter}, packagePatterns(ctx, projectRoot)...)
+	//nolint:gosec // G204: Subprocess launched with go list - required to find the packages to validate
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = projectRoot
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, stderr.String(), fmt.Errorf("failed to list packages: %w", err)
+	}
+
+	var packages []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if pkg := strings.TrimSpace(line); pkg != "" {
+			packages = append(packages, pkg)
+		}
+	}
+
+	return packages, stderr.String(), nil
+}
+
+// packagePatterns returns the package patterns covering the project: ./...
+// for a module, or each module's directory for a go.work workspace without a
+// go.mod at its root, where ./... matches nothing
+func packagePatterns(ctx context.Context, projectRoot string) []string {
+	if _, err := os.Stat(filepath.Join(projectRoot, "go.mod")); err == nil {
+		return []string{"./..."}
+	}
+	if _, err := os.Stat(filepath.Join(projectRoot, "go.work")); err != nil {
+		return []string{"./..."}
+	}
+
+	cmd := exec.CommandContext(ctx, "go", "list", "-m", "-f", "{{.Dir}}")
+	cmd.Dir = projectRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return []string{"./..."}
+	}
+	root, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return []string{"./..."}
+	}
+
+	var patterns []string
+	for _, dir := range strings.Split(string(output), "\n") {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue // Modules outside the project are not validated
+		}
+		patterns = append(patterns, "./"+filepath.ToSlash(rel)+"/...")
+	}
+	if len(patterns) == 0 {
+		return []string{"./..."}
+	}
+	return patterns
+}
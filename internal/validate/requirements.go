@@ -0,0 +1,143 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// RequirementTagPrefix is the structured comment that links a test to a requirement,
+// e.g. "// Requirement: FR-007". Several IDs may be listed separated by commas.
+const RequirementTagPrefix = "Requirement:"
+
+// requirementTagPattern matches "// Requirement: FR-001, FR-002" comments
+var requirementTagPattern = regexp.MustCompile(`//\s*Requirements?:\s*([A-Za-z0-9_.\-]+(?:\s*,\s*[A-Za-z0-9_.\-]+)*)`)
+
+// RequirementValidator checks that every functional requirement has a test referencing it
+type RequirementValidator interface {
+	Validate(ctx context.Context, projectRoot string) (*models.RequirementCoverage, error)
+}
+
+// tagRequirementValidator implements RequirementValidator by scanning test files for requirement tags
+type tagRequirementValidator struct {
+	requirements []models.FunctionalRequirement
+}
+
+// NewRequirementValidator creates a validator for the given functional requirements
+func NewRequirementValidator(requirements []models.FunctionalRequirement) RequirementValidator {
+	return &tagRequirementValidator{requirements: requirements}
+}
+
+// Validate scans *_test.go files under projectRoot and reports untested requirements
+func (v *tagRequirementValidator) Validate(ctx context.Context, projectRoot string) (*models.RequirementCoverage, error) {
+	testFiles := make(map[string]string)
+
+	err := filepath.WalkDir(projectRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if path != projectRoot && (name == "vendor" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(d.Name(), "_test.go") {
+			return nil
+		}
+
+		//nolint:gosec // G304: Reading generated test files - required for requirement coverage
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		rel, err := filepath.Rel(projectRoot, path)
+		if err != nil {
+			rel = path
+		}
+		testFiles[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan test files: %w", err)
+	}
+
+	return CheckRequirementCoverage(v.requirements, testFiles), nil
+}
+
+// ExtractRequirementRefs returns the requirement IDs referenced by tags in src, in order of appearance
+func ExtractRequirementRefs(src string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+
+	for _, match := range requirementTagPattern.FindAllStringSubmatch(src, -1) {
+		for _, id := range strings.Split(match[1], ",") {
+			id = strings.TrimSpace(id)
+			if id != "" && !seen[id] {
+				seen[id] = true
+				refs = append(refs, id)
+			}
+		}
+	}
+
+	return refs
+}
+
+// CheckRequirementCoverage maps requirements to the test files (path -> content) that reference them
+func CheckRequirementCoverage(requirements []models.FunctionalRequirement, testFiles map[string]string) *models.RequirementCoverage {
+	known := make(map[string]bool, len(requirements))
+	for _, req := range requirements {
+		known[req.ID] = true
+	}
+
+	paths := make([]string, 0, len(testFiles))
+	for path := range testFiles {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	covered := make(map[string][]string)
+	unknown := make(map[string]bool)
+	for _, path := range paths {
+		for _, id := range ExtractRequirementRefs(testFiles[path]) {
+			if !known[id] {
+				unknown[id] = true
+				continue
+			}
+			covered[id] = append(covered[id], path)
+		}
+	}
+
+	coverage := &models.RequirementCoverage{
+		TotalRequirements: len(requirements),
+		Covered:           covered,
+	}
+
+	for _, req := range requirements {
+		if _, ok := covered[req.ID]; !ok {
+			coverage.Untested = append(coverage.Untested, req.ID)
+		}
+	}
+
+	for id := range unknown {
+		coverage.Unknown = append(coverage.Unknown, id)
+	}
+	sort.Strings(coverage.Unknown)
+
+	coverage.Success = len(coverage.Untested) == 0
+	return coverage
+}
@@ -0,0 +1,205 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// Severity is how much a failed validation check matters
+type Severity string
+
+// Severities from highest to lowest
+const (
+	SeverityError   Severity = "error"   // Fails the run
+	SeverityWarning Severity = "warning" // Reported; fails the run only when failing on warnings
+	SeverityInfo    Severity = "info"    // Reported; fails the run only when failing on info
+	SeverityIgnore  Severity = "ignore"  // Not reported and never fails the run
+)
+
+// Check names that only appear in severity policies
+const (
+	CheckLint            = "lint"
+	CheckCoverage        = "coverage" // Test coverage below the required percentage
+	CheckRequirements    = "requirements"
+	CheckEnums           = "enums"
+	CheckMiddleware      = "middleware"
+	CheckOutputContracts = "contracts"
+	CheckStyle           = "style"
+	CheckSmoke           = "smoke"
+	CheckFuzz            = "fuzz"
+	CheckBuildFiles      = "build_files"
+)
+
+// defaultSeverities are the severities of checks whose failures did not fail
+// validation before severities were configurable; every other check is an
+// error
+var defaultSeverities = map[string]Severity{
+	CheckVet:      SeverityWarning,
+	CheckCoverage: SeverityWarning,
+}
+
+// rank orders severities; ignore and unknown severities rank lowest
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// AtLeast reports whether s is as severe as other
+func (s Severity) AtLeast(other Severity) bool {
+	return s.rank() >= other.rank()
+}
+
+// ParseSeverity parses a severity name
+func ParseSeverity(s string) (Severity, error) {
+	switch severity := Severity(strings.ToLower(strings.TrimSpace(s))); severity {
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityIgnore:
+		return severity, nil
+	default:
+		return "", fmt.Errorf("unknown severity %q (want error, warning, info or ignore)", s)
+	}
+}
+
+// CheckOutcome is the result of one validation check under a severity policy
+type CheckOutcome struct {
+	Check    string   `json:"check"`
+	Passed   bool     `json:"passed"`
+	Severity Severity `json:"severity"` // Severity of a failure
+}
+
+// SeverityPolicy maps failed validation checks, and lint issues by the
+// linter that reported them, to severities. The highest severity among the
+// failed checks of a run decides whether the run fails.
+type SeverityPolicy struct {
+	checks    map[string]Severity
+	lintRules map[string]Severity
+	failOn    Severity
+}
+
+// NewSeverityPolicy creates a severity policy from check and linter names
+// mapped to severity names. failOn is the lowest severity that fails a run;
+// empty fails on errors only.
+func NewSeverityPolicy(checks, lintRules map[string]string, failOn string) (*SeverityPolicy, error) {
+	p := &SeverityPolicy{
+		checks:    make(map[string]Severity, len(checks)),
+		lintRules: make(map[string]Severity, len(lintRules)),
+		failOn:    SeverityError,
+	}
+	for check, name := range checks {
+		severity, err := ParseSeverity(name)
+		if err != nil {
+			return nil, fmt.Errorf("check %s: %w", check, err)
+		}
+		p.checks[check] = severity
+	}
+	for rule, name := range lintRules {
+		severity, err := ParseSeverity(name)
+		if err != nil {
+			return nil, fmt.Errorf("lint rule %s: %w", rule, err)
+		}
+		p.lintRules[strings.ToLower(rule)] = severity
+	}
+	if failOn != "" {
+		severity, err := ParseSeverity(failOn)
+		if err != nil {
+			return nil, fmt.Errorf("fail on: %w", err)
+		}
+		if severity == SeverityIgnore {
+			return nil, fmt.Errorf("fail on: ignore is not a failing severity")
+		}
+		p.failOn = severity
+	}
+	return p, nil
+}
+
+// DefaultSeverityPolicy returns the policy without overrides: failed checks
+// are errors, except go vet findings and low coverage, which are warnings
+func DefaultSeverityPolicy() *SeverityPolicy {
+	p, _ := NewSeverityPolicy(nil, nil, "")
+	return p
+}
+
+// Severity returns the severity of a failed check
+func (p *SeverityPolicy) Severity(check string) Severity {
+	if severity, ok := p.checks[check]; ok {
+		return severity
+	}
+	if severity, ok := defaultSeverities[check]; ok {
+		return severity
+	}
+	return SeverityError
+}
+
+// LintSeverity returns the severity of a lint issue reported by the linter
+// rule; linters without their own severity take the lint check's
+func (p *SeverityPolicy) LintSeverity(rule string) Severity {
+	if severity, ok := p.lintRules[strings.ToLower(rule)]; ok {
+		return severity
+	}
+	return p.Severity(CheckLint)
+}
+
+// ApplyToLint sets the severity of every lint issue from the policy and
+// drops ignored issues; the result fails only when issues remain
+func (p *SeverityPolicy) ApplyToLint(result *models.LintResult) {
+	issues := result.Issues[:0]
+	for _, issue := range result.Issues {
+		severity := p.LintSeverity(issue.Rule)
+		if severity == SeverityIgnore {
+			continue
+		}
+		issue.Severity = string(severity)
+		issues = append(issues, issue)
+	}
+	result.Issues = issues
+	result.Success = len(issues) == 0
+}
+
+// Outcome records whether a check passed with the severity of its failure
+func (p *SeverityPolicy) Outcome(check string, passed bool) CheckOutcome {
+	return CheckOutcome{Check: check, Passed: passed, Severity: p.Severity(check)}
+}
+
+// LintOutcome records a lint result that ApplyToLint already mapped; a
+// failure is as severe as its most severe issue
+func (p *SeverityPolicy) LintOutcome(result *models.LintResult) CheckOutcome {
+	outcome := p.Outcome(CheckLint, result.Success)
+	if result.Success {
+		return outcome
+	}
+	outcome.Severity = SeverityIgnore
+	for _, issue := range result.Issues {
+		if severity := Severity(issue.Severity); severity.rank() > outcome.Severity.rank() {
+			outcome.Severity = severity
+		}
+	}
+	return outcome
+}
+
+// HighestSeverity returns the highest severity among the failed checks;
+// empty when every check passed or its failure is ignored
+func HighestSeverity(outcomes []CheckOutcome) Severity {
+	var highest Severity
+	for _, outcome := range outcomes {
+		if !outcome.Passed && outcome.Severity.rank() > highest.rank() {
+			highest = outcome.Severity
+		}
+	}
+	return highest
+}
+
+// Fails reports whether the failed checks of a run fail it: when the
+// highest severity reaches the policy's fail-on severity
+func (p *SeverityPolicy) Fails(outcomes []CheckOutcome) bool {
+	highest := HighestSeverity(outcomes)
+	return highest != "" && highest.AtLeast(p.failOn)
+}
@@ -0,0 +1,362 @@
+package validate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+const (
+	defaultSmokeStartupTimeout = 30 * time.Second
+	smokeBuildTimeout          = 2 * time.Minute
+	smokePollInterval          = 200 * time.Millisecond
+	smokeShutdownGrace         = 5 * time.Second
+	smokeOutputTail            = 4096
+)
+
+// smokeCrashPattern matches the first line of a Go runtime panic or fatal error
+var smokeCrashPattern = regexp.MustCompile(`(?m)^(panic: |fatal error: ).*$`)
+
+// SmokeValidator builds the project's executable and runs it once, catching
+// runtime panics and startup failures that compilation and unit tests miss
+type SmokeValidator interface {
+	Validate(ctx context.Context, projectRoot string) (*models.SmokeResult, error)
+}
+
+// goSmokeValidator implements SmokeValidator using go build and the built binary
+type goSmokeValidator struct {
+	pkg            string
+	args           []string
+	argsSet        bool
+	env            []string
+	healthURL      string
+	startupTimeout time.Duration
+}
+
+// SmokeOption configures the smoke validator
+type SmokeOption func(*goSmokeValidator)
+
+// WithSmokePackage sets the main package to build (default: the first main
+// package under ./cmd, then any main package)
+func WithSmokePackage(pkg string) SmokeOption {
+	return func(v *goSmokeValidator) {
+		v.pkg = pkg
+	}
+}
+
+// WithSmokeArgs sets the arguments the executable is run with
+// (default: --help, or none when a health check is configured)
+func WithSmokeArgs(args ...string) SmokeOption {
+	return func(v *goSmokeValidator) {
+		v.args = args
+		v.argsSet = true
+	}
+}
+
+// WithSmokeEnv adds KEY=VALUE entries to the executable's environment
+func WithSmokeEnv(env ...string) SmokeOption {
+	return func(v *goSmokeValidator) {
+		v.env = append(v.env, env...)
+	}
+}
+
+// WithHealthCheck runs the executable as a server: it passes once url answers
+// with a 2xx status, and fails if the process exits first
+func WithHealthCheck(url string) SmokeOption {
+	return func(v *goSmokeValidator) {
+		v.healthURL = url
+	}
+}
+
+// WithStartupTimeout bounds how long the executable may take to exit or to
+// pass its health check (default: 30s)
+func WithStartupTimeout(timeout time.Duration) SmokeOption {
+	return func(v *goSmokeValidator) {
+		v.startupTimeout = timeout
+	}
+}
+
+// NewSmokeValidator creates a new smoke validator
+func NewSmokeValidator(opts ...SmokeOption) SmokeValidator {
+	v := &goSmokeValidator{startupTimeout: defaultSmokeStartupTimeout}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if v.startupTimeout <= 0 {
+		v.startupTimeout = defaultSmokeStartupTimeout
+	}
+	if !v.argsSet && v.healthURL == "" {
+		v.args = []string{"--help"}
+	}
+	return v
+}
+
+// Validate builds the main package into a temporary directory and runs it.
+// Projects without a main package are reported as skipped, not failed.
+func (v *goSmokeValidator) Validate(ctx context.Context, projectRoot string) (*models.SmokeResult, error) {
+	start := time.Now()
+	result := &models.SmokeResult{HealthURL: v.healthURL}
+	defer func() { result.Duration = time.Since(start) }()
+
+	pkg := v.pkg
+	if pkg == "" {
+		var err error
+		pkg, err = v.detectMainPackage(ctx, projectRoot)
+		if err != nil {
+			return nil, err
+		}
+		if pkg == "" {
+			result.Success = true
+			result.Skipped = true
+			result.Message = "no main package to run"
+			return result, nil
+		}
+	}
+	result.Package = pkg
+
+	binDir, err := os.MkdirTemp("", "gocreator-smoke-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create smoke build directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(binDir) }()
+
+	binary := filepath.Join(binDir, "smoke")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+
+	if output, err := v.build(ctx, projectRoot, pkg, binary); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("smoke build interrupted: %w", ctx.Err())
+		}
+		result.Message = "failed to build " + pkg
+		result.Output = outputTail(output)
+		result.ExitCode = -1
+		return result, nil
+	}
+
+	result.Command = strings.TrimSpace(filepath.Base(binary) + " " + strings.Join(v.args, " "))
+
+	if v.healthURL == "" {
+		err = v.runToCompletion(ctx, projectRoot, binary, result)
+	} else {
+		err = v.runUntilHealthy(ctx, projectRoot, binary, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A recovered panic can still leave the process exiting cleanly
+	if crash := smokeCrashPattern.FindString(result.Output); crash != "" {
+		result.Success = false
+		result.Message = strings.TrimSpace(crash)
+	}
+
+	return result, nil
+}
+
+// detectMainPackage picks the executable to build, preferring ./cmd/...
+func (v *goSmokeValidator) detectMainPackage(ctx context.Context, projectRoot string) (string, error) {
+	packages, output, err := listPackages(ctx, projectRoot, mainPackages)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(output))
+	}
+	for _, pkg := range packages {
+		if strings.Contains(pkg, "/cmd/") {
+			return pkg, nil
+		}
+	}
+	if len(packages) > 0 {
+		return packages[0], nil
+	}
+	return "", nil
+}
+
+// build compiles pkg into binary and returns the toolchain output
+func (v *goSmokeValidator) build(ctx context.Context, projectRoot, pkg, binary string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, smokeBuildTimeout)
+	defer cancel()
+
+	//nolint:gosec // G204: Subprocess launched with go build - required for smoke validation
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", binary, pkg)
+	cmd.Dir = projectRoot
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// command prepares the built executable with the configured arguments and environment
+func (v *goSmokeValidator) command(ctx context.Context, projectRoot, binary string, output *syncBuffer) *exec.Cmd {
+	//nolint:gosec // G204: Running the project's own binary - required for smoke validation
+	cmd := exec.CommandContext(ctx, binary, v.args...)
+	cmd.Dir = projectRoot
+	cmd.Env = append(os.Environ(), v.env...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	return cmd
+}
+
+// runToCompletion runs the executable and passes when it exits zero in time
+func (v *goSmokeValidator) runToCompletion(ctx context.Context, projectRoot, binary string, result *models.SmokeResult) error {
+	runCtx, cancel := context.WithTimeout(ctx, v.startupTimeout)
+	defer cancel()
+
+	var output syncBuffer
+	err := v.command(runCtx, projectRoot, binary, &output).Run()
+	result.Output = outputTail(output.String())
+
+	switch {
+	case ctx.Err() != nil:
+		return fmt.Errorf("smoke run interrupted: %w", ctx.Err())
+	case runCtx.Err() != nil:
+		result.ExitCode = -1
+		result.Message = fmt.Sprintf("did not exit within %v", v.startupTimeout)
+	case err != nil:
+		result.ExitCode = processExitCode(err)
+		result.Message = fmt.Sprintf("exited with status %d", result.ExitCode)
+	default:
+		result.Success = true
+	}
+	return nil
+}
+
+// runUntilHealthy starts the executable, polls the health URL and stops the
+// process once it answers. Exiting before becoming healthy is a failure.
+func (v *goSmokeValidator) runUntilHealthy(ctx context.Context, projectRoot, binary string, result *models.SmokeResult) error {
+	procCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var output syncBuffer
+	cmd := v.command(procCtx, projectRoot, binary, &output)
+	if err := cmd.Start(); err != nil {
+		result.ExitCode = -1
+		result.Message = fmt.Sprintf("failed to start: %v", err)
+		return nil
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.NewTimer(v.startupTimeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(smokePollInterval)
+	defer ticker.Stop()
+
+	client := &http.Client{Timeout: smokePollInterval * 5}
+	var lastErr error
+
+	for {
+		select {
+		case <-ctx.Done():
+			<-exited
+			return fmt.Errorf("smoke run interrupted: %w", ctx.Err())
+
+		case err := <-exited:
+			result.Output = outputTail(output.String())
+			result.ExitCode = processExitCode(err)
+			result.Message = fmt.Sprintf("exited with status %d before %s became healthy", result.ExitCode, v.healthURL)
+			return nil
+
+		case <-deadline.C:
+			v.stop(cmd, exited)
+			result.Output = outputTail(output.String())
+			result.ExitCode = -1
+			result.Message = fmt.Sprintf("%s not healthy within %v", v.healthURL, v.startupTimeout)
+			if lastErr != nil {
+				result.Message += ": " + lastErr.Error()
+			}
+			return nil
+
+		case <-ticker.C:
+			if lastErr = v.probe(procCtx, client); lastErr != nil {
+				continue
+			}
+			v.stop(cmd, exited)
+			result.Output = outputTail(output.String())
+			result.Success = true
+			return nil
+		}
+	}
+}
+
+// probe performs one health check request
+func (v *goSmokeValidator) probe(ctx context.Context, client *http.Client) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.healthURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("health check returned %s", resp.Status)
+	}
+	return nil
+}
+
+// stop interrupts the process and kills it if it does not exit within the grace period
+func (v *goSmokeValidator) stop(cmd *exec.Cmd, exited <-chan error) {
+	if runtime.GOOS == "windows" {
+		_ = cmd.Process.Kill()
+	} else {
+		_ = cmd.Process.Signal(os.Interrupt)
+	}
+
+	select {
+	case <-exited:
+	case <-time.After(smokeShutdownGrace):
+		_ = cmd.Process.Kill()
+		<-exited
+	}
+}
+
+// processExitCode extracts the process exit status from a run error
+func processExitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		return -1
+	}
+	return 0
+}
+
+// outputTail keeps the end of long process output for the report
+func outputTail(output string) string {
+	if len(output) <= smokeOutputTail {
+		return output
+	}
+	return "..." + output[len(output)-smokeOutputTail:]
+}
+
+// syncBuffer is a bytes.Buffer safe for a running process to write while it is read
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,312 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// Style rules reported in StyleIssue.Rule, named after their style setting
+const (
+	styleRuleErrorWrapping = "error_wrapping"
+	styleRuleLogging       = "logging"
+	styleRuleContext       = "context"
+	styleRuleInitialisms   = "initialisms"
+	styleRuleTestFramework = "test_framework"
+)
+
+// pkgErrorsPath is the import path of the pkg_errors wrapping style
+const pkgErrorsPath = "github.com/pkg/errors"
+
+// StyleValidator checks generated code against the house style
+type StyleValidator interface {
+	Validate(ctx context.Context, projectRoot string) (*models.StyleResult, error)
+}
+
+// astStyleValidator implements StyleValidator by parsing the project's Go files
+type astStyleValidator struct {
+	style models.StyleGuide
+}
+
+// NewStyleValidator creates a validator for the rules of style
+func NewStyleValidator(style models.StyleGuide) StyleValidator {
+	return &astStyleValidator{style: style}
+}
+
+// Validate parses the Go files under projectRoot, tests included, and
+// reports where they break the style
+func (v *astStyleValidator) Validate(ctx context.Context, projectRoot string) (*models.StyleResult, error) {
+	sources, err := readGoFiles(ctx, projectRoot, true)
+	if err != nil {
+		return nil, err
+	}
+	return CheckHouseStyle(v.style, sources), nil
+}
+
+// CheckHouseStyle checks source files (path -> content) against the rules of
+// style that can be checked from the syntax tree:
+//
+//   - error_wrapping: fmt_errorf forbids github.com/pkg/errors and
+//     fmt.Errorf calls that format err without %w; pkg_errors forbids %w
+//   - logging: no other known logging library is imported
+//   - context: context.Context is the first parameter, and context.Background
+//     and context.TODO are only called in package main
+//   - initialisms: declared identifiers write initialisms in one case
+//   - test_framework: test files import no test library of another framework
+//
+// Only test_framework applies to test files. Files that do not parse are
+// left to build validation.
+func CheckHouseStyle(style models.StyleGuide, sources map[string]string) *models.StyleResult {
+	result := &models.StyleResult{}
+
+	paths := make([]string, 0, len(sources))
+	for p := range sources {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, p, sources[p], 0)
+		if err != nil {
+			continue
+		}
+		result.Files++
+
+		c := &styleChecker{style: style, fset: fset, path: p, file: file}
+		if strings.HasSuffix(p, "_test.go") {
+			c.checkTestFramework()
+		} else {
+			c.checkImports()
+			c.checkCode()
+		}
+		result.Issues = append(result.Issues, c.issues...)
+	}
+
+	result.Success = len(result.Issues) == 0
+	return result
+}
+
+// styleChecker collects the style issues of one file
+type styleChecker struct {
+	style  models.StyleGuide
+	fset   *token.FileSet
+	path   string
+	file   *ast.File
+	issues []models.StyleIssue
+}
+
+func (c *styleChecker) report(rule string, pos token.Pos, format string, args ...interface{}) {
+	c.issues = append(c.issues, models.StyleIssue{
+		Rule:    rule,
+		File:    c.path,
+		Line:    c.fset.Position(pos).Line,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkTestFramework reports test library imports foreign to the framework
+func (c *styleChecker) checkTestFramework() {
+	if c.style.TestFramework == "" {
+		return
+	}
+	for _, imp := range c.file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err == nil && models.ForeignTestLibrary(importPath, c.style.TestFramework) {
+			c.report(styleRuleTestFramework, imp.Pos(), "imports %s; tests use the %s framework", importPath, c.style.TestFramework)
+		}
+	}
+}
+
+// checkImports reports error wrapping and logging libraries the style forbids
+func (c *styleChecker) checkImports() {
+	for _, imp := range c.file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if c.style.ErrorWrapping == models.ErrorWrappingFmt && importPath == pkgErrorsPath {
+			c.report(styleRuleErrorWrapping, imp.Pos(), "imports %s; wrap errors with fmt.Errorf and %%w", pkgErrorsPath)
+		}
+		if c.style.Logging != "" {
+			if library := loggingLibrary(importPath); library != "" && library != c.style.Logging {
+				c.report(styleRuleLogging, imp.Pos(), "imports %s; log through %s", importPath, c.style.Logging)
+			}
+		}
+	}
+}
+
+// loggingLibrary returns the known logging library importPath belongs to, or
+// "". The standard log package only matches exactly, as log/slog and
+// log/syslog are not it.
+func loggingLibrary(importPath string) string {
+	for name, libraryPath := range models.LoggingLibraries {
+		if importPath == libraryPath || (libraryPath != "log" && strings.HasPrefix(importPath, libraryPath+"/")) {
+			return name
+		}
+	}
+	return ""
+}
+
+// checkCode walks the file for the error wrapping, context and initialism rules
+func (c *styleChecker) checkCode() {
+	fmtName := importName(c.file, "fmt")
+	contextName := importName(c.file, "context")
+	named := make(map[string]bool)
+
+	ast.Inspect(c.file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			if fmtName != "" && isPackageCall(n, fmtName, "Errorf") {
+				c.checkErrorf(n)
+			}
+			if c.style.Context == models.ContextFirstParam && contextName != "" && c.file.Name.Name != "main" &&
+				(isPackageCall(n, contextName, "Background") || isPackageCall(n, contextName, "TODO")) {
+				c.report(styleRuleContext, n.Pos(), "calls %s outside package main; pass the caller's ctx on",
+					n.Fun.(*ast.SelectorExpr).Sel.Name)
+			}
+		case *ast.FuncDecl:
+			if c.style.Context == models.ContextFirstParam && contextName != "" {
+				c.checkContextParam(n, contextName)
+			}
+			c.checkName(n.Name, named)
+		case *ast.TypeSpec:
+			c.checkName(n.Name, named)
+		case *ast.ValueSpec:
+			for _, name := range n.Names {
+				c.checkName(name, named)
+			}
+		case *ast.Field:
+			for _, name := range n.Names {
+				c.checkName(name, named)
+			}
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				for _, lhs := range n.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok {
+						c.checkName(ident, named)
+					}
+				}
+			}
+		}
+		return true
+	})
+}
+
+// checkErrorf reports fmt.Errorf calls that break the error wrapping style
+func (c *styleChecker) checkErrorf(call *ast.CallExpr) {
+	if len(call.Args) == 0 {
+		return
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return
+	}
+	wraps := strings.Contains(format, "%w")
+
+	switch c.style.ErrorWrapping {
+	case models.ErrorWrappingFmt:
+		if wraps {
+			return
+		}
+		for _, arg := range call.Args[1:] {
+			if ident, ok := arg.(*ast.Ident); ok && (ident.Name == "err" || strings.HasSuffix(ident.Name, "Err")) {
+				c.report(styleRuleErrorWrapping, call.Pos(), "fmt.Errorf formats %s without %%w; wrap it with %%w", ident.Name)
+				return
+			}
+		}
+	case models.ErrorWrappingPkgErrors:
+		if wraps {
+			c.report(styleRuleErrorWrapping, call.Pos(), "fmt.Errorf wraps with %%w; wrap with errors.Wrap from %s", pkgErrorsPath)
+		}
+	}
+}
+
+// checkContextParam reports a context.Context parameter that is not the first
+func (c *styleChecker) checkContextParam(fn *ast.FuncDecl, contextName string) {
+	index := 0
+	for _, field := range fn.Type.Params.List {
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		if sel, ok := field.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "Context" {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Name == contextName && index > 0 {
+				c.report(styleRuleContext, field.Pos(), "%s takes context.Context as parameter %d; make it the first", fn.Name.Name, index+1)
+				return
+			}
+		}
+		index += count
+	}
+}
+
+// mixedInitialism matches an initialism written in mixed case, e.g. the Id
+// of userId or the Url of UrlPath
+var mixedInitialism = func() *regexp.Regexp {
+	forms := make([]string, 0, len(models.Initialisms))
+	for _, initialism := range models.Initialisms {
+		forms = append(forms, initialism[:1]+strings.ToLower(initialism[1:]))
+	}
+	return regexp.MustCompile(`(` + strings.Join(forms, "|") + `)(s?)([A-Z0-9_]|$)`)
+}()
+
+// checkName reports a declared identifier that writes an initialism in mixed
+// case, once per name and file
+func (c *styleChecker) checkName(ident *ast.Ident, named map[string]bool) {
+	if !c.style.Initialisms || ident == nil || named[ident.Name] {
+		return
+	}
+	named[ident.Name] = true
+	if !mixedInitialism.MatchString(ident.Name) {
+		return
+	}
+	// A match consumes the next initialism's first letter, so repeat
+	fixed := ident.Name
+	for mixedInitialism.MatchString(fixed) {
+		fixed = mixedInitialism.ReplaceAllStringFunc(fixed, func(match string) string {
+			parts := mixedInitialism.FindStringSubmatch(match)
+			return strings.ToUpper(parts[1]) + parts[2] + parts[3]
+		})
+	}
+	c.report(styleRuleInitialisms, ident.Pos(), "%s should be %s", ident.Name, fixed)
+}
+
+// importName returns the name file uses for the standard package importPath,
+// or "" when it does not import it
+func importName(file *ast.File, importPath string) string {
+	for _, imp := range file.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err != nil || p != importPath {
+			continue
+		}
+		if imp.Name != nil {
+			if imp.Name.Name == "_" || imp.Name.Name == "." {
+				return ""
+			}
+			return imp.Name.Name
+		}
+		return importPath
+	}
+	return ""
+}
+
+// isPackageCall reports whether call calls pkg.name
+func isPackageCall(call *ast.CallExpr, pkg, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name == pkg
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/dshills/gocreator/internal/models"
+	"golang.org/x/sync/errgroup"
 )
 
 // TestValidator validates tests
@@ -25,6 +27,8 @@ type goTestValidator struct {
 	timeout         time.Duration
 	coverageProfile string
 	additionalFlags []string
+	parallel        ParallelValidationConfig
+	eventChan       chan<- models.ProgressEvent
 }
 
 // TestOption configures the test validator
@@ -51,12 +55,28 @@ func WithTestFlags(flags ...string) TestOption {
 	}
 }
 
+// WithTestEvents streams a progress event as each package's tests finish
+func WithTestEvents(ch chan<- models.ProgressEvent) TestOption {
+	return func(v *goTestValidator) {
+		v.eventChan = ch
+	}
+}
+
+// WithTestParallelism sets how many packages are tested concurrently. When
+// parallelism is disabled the whole module is tested with a single go test ./...
+func WithTestParallelism(config ParallelValidationConfig) TestOption {
+	return func(v *goTestValidator) {
+		v.parallel = config
+	}
+}
+
 // NewTestValidator creates a new test validator
 func NewTestValidator(opts ...TestOption) TestValidator {
 	v := &goTestValidator{
 		timeout:         5 * time.Minute, // Default 5 minute timeout
 		coverageProfile: "coverage.out",
 		additionalFlags: []string{},
+		parallel:        DefaultParallelValidationConfig(),
 	}
 	for _, opt := range opts {
 		opt(v)
@@ -86,15 +106,22 @@ func (t *goTestValidator) Validate(ctx context.Context, projectRoot string) (*mo
 		coverageFile = filepath.Join(projectRoot, coverageFile)
 	}
 
-	// Build command: go test ./... -coverprofile=coverage.out -v
-	args := []string{"test", "./...", "-coverprofile=" + coverageFile, "-v"}
-	args = append(args, t.additionalFlags...)
-
-	//nolint:gosec // G204: Subprocess launched with go test - required for test validation
-	cmd := exec.CommandContext(ctxWithTimeout, "go", args...)
-	cmd.Dir = projectRoot
-
-	output, err := cmd.CombinedOutput()
+	var output string
+	var err error
+	if t.parallel.EnableParallel {
+		output, err = t.runPerPackage(ctxWithTimeout, projectRoot, coverageFile)
+	} else {
+		// Build command: go test ./... -coverprofile=coverage.out -v
+		args := append([]string{"test"}, packagePatterns(ctxWithTimeout, projectRoot)...)
+		args = append(args, "-coverprofile="+coverageFile, "-v")
+		args = append(args, t.additionalFlags...)
+
+		//nolint:gosec // G204: Subprocess launched with go test - required for test validation
+		cmd := exec.CommandContext(ctxWithTimeout, "go", args...)
+		cmd.Dir = projectRoot
+
+		output, err = t.runStreaming(cmd)
+	}
 	result.Duration = time.Since(start)
 
 	// Check for timeout
@@ -103,7 +130,7 @@ func (t *goTestValidator) Validate(ctx context.Context, projectRoot string) (*mo
 	}
 
 	// Parse test output
-	totalTests, passedTests, failures := parseTestOutput(string(output))
+	totalTests, passedTests, failures := parseTestOutput(output)
 	result.TotalTests = totalTests
 	result.PassedTests = passedTests
 	result.FailedTests = len(failures)
@@ -123,14 +150,158 @@ func (t *goTestValidator) Validate(ctx context.Context, projectRoot string) (*mo
 	return result, nil
 }
 
+// runPerPackage tests each package in its own go test process using a bounded
+// worker pool. Output is concatenated in package order so failures are reported
+// deterministically, and per-package coverage profiles are merged into coverageFile.
+func (t *goTestValidator) runPerPackage(ctx context.Context, projectRoot, coverageFile string) (string, error) {
+	packages, listOutput, err := listPackages(ctx, projectRoot, testablePackages)
+	if err != nil {
+		return listOutput, err
+	}
+
+	profileDir, err := os.MkdirTemp("", "gocreator-coverage-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create coverage directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(profileDir) }()
+
+	outputs := make([]string, len(packages))
+	errs := make([]error, len(packages))
+	profiles := make([]string, len(packages))
+
+	var g errgroup.Group
+	g.SetLimit(t.parallel.workerLimit())
+
+	for i, pkg := range packages {
+		profiles[i] = filepath.Join(profileDir, fmt.Sprintf("%d.out", i))
+
+		g.Go(func() error {
+			args := []string{"test", pkg, "-coverprofile=" + profiles[i], "-v"}
+			args = append(args, t.additionalFlags...)
+
+			//nolint:gosec // G204: Subprocess launched with go test - required for test validation
+			cmd := exec.CommandContext(ctx, "go", args...)
+			cmd.Dir = projectRoot
+
+			outputs[i], errs[i] = t.runStreaming(cmd)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if err := mergeCoverageProfiles(coverageFile, profiles); err != nil {
+		return "", err
+	}
+
+	var failed int
+	for _, pkgErr := range errs {
+		if pkgErr != nil {
+			failed++
+		}
+	}
+
+	output := strings.Join(outputs, "")
+	if failed > 0 {
+		return output, fmt.Errorf("tests failed in %d of %d packages", failed, len(packages))
+	}
+
+	return output, nil
+}
+
+// runStreaming runs go test, emitting a package event as each package summary
+// line appears, and returns the combined output once the command exits
+func (t *goTestValidator) runStreaming(cmd *exec.Cmd) (string, error) {
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	if err := cmd.Start(); err != nil {
+		_ = pw.Close()
+		_ = pr.Close()
+		return "", err
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		_ = pw.Close()
+		waitErr <- err
+	}()
+
+	var output strings.Builder
+	var pkgOutput []string
+
+	scanner := bufio.NewScanner(pr)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		output.WriteString(line)
+		output.WriteString("\n")
+
+		if pkg, success, duration, ok := parsePackageSummary(line); ok {
+			excerpt := ""
+			if !success {
+				excerpt = failureExcerpt(strings.Join(pkgOutput, "\n"))
+			}
+			emitEvent(t.eventChan, models.NewPackageValidatedEvent(pkg, CheckTest, success, duration, excerpt))
+			pkgOutput = pkgOutput[:0]
+			continue
+		}
+
+		// Keep only failure-relevant lines for the excerpt
+		if !strings.HasPrefix(line, "=== ") && !strings.HasPrefix(line, "--- PASS") && line != "PASS" {
+			pkgOutput = append(pkgOutput, line)
+		}
+	}
+
+	// Drain anything left if scanning stopped early so the command can exit
+	_, _ = io.Copy(io.Discard, pr)
+
+	return output.String(), <-waitErr
+}
+
+// parsePackageSummary recognises the per-package summary lines printed by go test:
+//
+//	ok  	example.com/pkg	0.012s	coverage: 80.0% of statements
+//	FAIL	example.com/pkg	0.010s
+//	FAIL	example.com/pkg [build failed]
+func parsePackageSummary(line string) (pkg string, success bool, duration time.Duration, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return "", false, 0, false
+	}
+
+	switch fields[0] {
+	case "ok":
+		success = true
+	case "FAIL":
+		success = false
+	default:
+		return "", false, 0, false
+	}
+
+	pkg = fields[1]
+	if len(fields) >= 3 && strings.HasSuffix(fields[2], "s") {
+		duration, _ = time.ParseDuration(fields[2])
+	}
+
+	return pkg, success, duration, true
+}
+
 // parseTestOutput parses go test -v output for test results
 // Format:
 // === RUN   TestFoo
 // --- PASS: TestFoo (0.00s)
 // === RUN   TestBar
-// --- FAIL: TestBar (0.00s)
 //
 //	file_test.go:42: error message
+//
+// --- FAIL: TestBar (0.00s)
+// FAIL
+// FAIL	example.com/pkg	0.010s
+//
+// Failure messages are printed before the --- FAIL line and the package is
+// only known from the summary line that follows, so both are attached late.
 func parseTestOutput(output string) (totalTests int, passedTests int, failures []models.TestFailure) {
 	scanner := bufio.NewScanner(strings.NewReader(output))
 
@@ -139,31 +310,56 @@ func parseTestOutput(output string) (totalTests int, passedTests int, failures [
 	// Pattern for failure location
 	locationPattern := regexp.MustCompile(`^\s+([^:]+):(\d+): (.+)$`)
 
-	var currentPackage string
 	var currentFailure *models.TestFailure
+	var pending [][]string // location lines seen before the test's result line
+	pkgStart := 0          // index of the first failure not yet assigned a package
+
+	attach := func(f *models.TestFailure, loc []string) {
+		f.Location = fmt.Sprintf("%s:%s", loc[1], loc[2])
+		if f.Message == "" {
+			f.Message = loc[3]
+		} else {
+			f.Message += "\n" + loc[3]
+		}
+	}
+
+	flush := func() {
+		if currentFailure != nil {
+			failures = append(failures, *currentFailure)
+			currentFailure = nil
+		}
+	}
 
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		// Check for package indication
+		// Package summary line closes out the package's failures
 		if strings.HasPrefix(line, "?") || strings.HasPrefix(line, "ok") || strings.HasPrefix(line, "FAIL") {
 			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				pkg := parts[1]
-				if pkg != "" && !strings.HasPrefix(pkg, "[") {
-					currentPackage = pkg
+			if len(parts) >= 2 && !strings.HasPrefix(parts[1], "[") {
+				flush()
+				for i := pkgStart; i < len(failures); i++ {
+					if failures[i].Package == "" {
+						failures[i].Package = parts[1]
+					}
 				}
+				pkgStart = len(failures)
+				pending = nil
 			}
+			continue
+		}
+
+		// A new test starting ends the previous test's output
+		if strings.HasPrefix(line, "=== ") {
+			flush()
+			pending = nil
+			continue
 		}
 
 		// Check for test results
 		matches := resultPattern.FindStringSubmatch(line)
 		if matches != nil {
-			// Save previous failure if exists
-			if currentFailure != nil {
-				failures = append(failures, *currentFailure)
-				currentFailure = nil
-			}
+			flush()
 
 			status := matches[1]
 			testName := matches[2]
@@ -174,39 +370,33 @@ func parseTestOutput(output string) (totalTests int, passedTests int, failures [
 			case "PASS":
 				passedTests++
 			case "FAIL":
-				// Start tracking a new failure
+				// Start tracking a new failure with any output printed before it
 				currentFailure = &models.TestFailure{
-					Package:  currentPackage,
-					Test:     testName,
-					Message:  "",
-					Location: "",
+					Test: testName,
+				}
+				for _, loc := range pending {
+					attach(currentFailure, loc)
 				}
 			case "SKIP":
 				// Skip SKIP status from totals
 				totalTests-- // Don't count skipped tests
 			}
-		} else if currentFailure != nil {
-			// Check for failure details (location and message) only if not a result line
-			locMatches := locationPattern.FindStringSubmatch(line)
-			if locMatches != nil {
-				file := locMatches[1]
-				lineNum := locMatches[2]
-				message := locMatches[3]
-
-				currentFailure.Location = fmt.Sprintf("%s:%s", file, lineNum)
-				if currentFailure.Message == "" {
-					currentFailure.Message = message
-				} else {
-					currentFailure.Message += "\n" + message
-				}
+			pending = nil
+			continue
+		}
+
+		// Check for failure details (location and message)
+		if locMatches := locationPattern.FindStringSubmatch(line); locMatches != nil {
+			if currentFailure != nil {
+				attach(currentFailure, locMatches)
+			} else {
+				pending = append(pending, locMatches)
 			}
 		}
 	}
 
 	// Save last failure if exists
-	if currentFailure != nil {
-		failures = append(failures, *currentFailure)
-	}
+	flush()
 
 	return totalTests, passedTests, failures
 }
@@ -7,13 +7,14 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/dshills/gocreator/internal/logctx"
 	"github.com/dshills/gocreator/internal/models"
 )
 
 // AtomicWrite writes content to a file atomically using a temp file and rename
 // This ensures that the file is either fully written or not written at all
 func (f *fileOps) AtomicWrite(ctx context.Context, path, content string) error {
-	if err := f.ValidatePath(path); err != nil {
+	if err := f.validateWritePath(path); err != nil {
 		return err
 	}
 
@@ -28,6 +29,13 @@ func (f *fileOps) AtomicWrite(ctx context.Context, path, content string) error {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
+	// Hold a file slot until the temp file is closed
+	release, err := f.openSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	// Create a temporary file in the same directory
 	// This ensures the temp file is on the same filesystem as the target
 	tempFile, err := os.CreateTemp(dir, ".gocreator-temp-*")
@@ -60,6 +68,7 @@ func (f *fileOps) AtomicWrite(ctx context.Context, path, content string) error {
 	if err := tempFile.Close(); err != nil {
 		return fmt.Errorf("failed to close temp file: %w", err)
 	}
+	release()
 
 	// Set proper permissions on temp file before rename
 	if err := os.Chmod(tempPath, 0600); err != nil {
@@ -89,12 +98,17 @@ func (f *fileOps) AtomicWrite(ctx context.Context, path, content string) error {
 		return fmt.Errorf("failed to rename temp file to target: %w", err)
 	}
 
+	logctx.Logger(ctx).Debug().
+		Str("path", path).
+		Str("checksum", checksum).
+		Msg("Wrote file atomically")
+
 	return nil
 }
 
 // AtomicWriteWithBackup writes content atomically and creates a backup of existing file
 func (f *fileOps) AtomicWriteWithBackup(ctx context.Context, path, content string) (backupPath string, err error) {
-	if err := f.ValidatePath(path); err != nil {
+	if err := f.validateWritePath(path); err != nil {
 		return "", err
 	}
 
@@ -121,7 +135,12 @@ func (f *fileOps) AtomicWriteWithBackup(ctx context.Context, path, content strin
 		backupPath = path + ".backup"
 		backupAbsPath := absPath + ".backup"
 
-		// Write backup atomically
+		// Write backup atomically, holding a file slot until it is closed
+		release, err := f.openSlot(ctx)
+		if err != nil {
+			return "", err
+		}
+		defer release()
 		backupTempFile, err := os.CreateTemp(filepath.Dir(absPath), ".gocreator-backup-*")
 		if err != nil {
 			return "", fmt.Errorf("failed to create backup temp file: %w", err)
@@ -147,6 +166,7 @@ func (f *fileOps) AtomicWriteWithBackup(ctx context.Context, path, content strin
 		if err := backupTempFile.Close(); err != nil {
 			return "", fmt.Errorf("failed to close backup: %w", err)
 		}
+		release()
 
 		if err := os.Chmod(backupTempPath, 0600); err != nil {
 			return "", fmt.Errorf("failed to set permissions on backup: %w", err)
@@ -186,7 +206,7 @@ func (f *fileOps) RestoreFromBackup(ctx context.Context, path string) error {
 	backupPath := path + ".backup"
 
 	// Validate both paths
-	if err := f.ValidatePath(path); err != nil {
+	if err := f.validateWritePath(path); err != nil {
 		return err
 	}
 	if err := f.ValidatePath(backupPath); err != nil {
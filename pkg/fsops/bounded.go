@@ -1,11 +1,15 @@
 package fsops
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
 )
 
+// ErrProtectedPath is returned when a write targets a protected path
+var ErrProtectedPath = errors.New("path is protected")
+
 // ValidatePath validates that a path is safe and within the root directory
 // This is a critical security function that prevents path traversal attacks
 // Uses proper canonicalization and symlink resolution for security
@@ -39,6 +43,19 @@ func (f *fileOps) ValidatePath(path string) error {
 	return nil
 }
 
+// validateWritePath validates a path like ValidatePath and also rejects
+// paths inside the configured protected paths
+func (f *fileOps) validateWritePath(path string) error {
+	if err := f.ValidatePath(path); err != nil {
+		return err
+	}
+
+	if pattern, ok := f.protected.Match(path); ok {
+		return fmt.Errorf("%w: %s matches %s", ErrProtectedPath, path, pattern)
+	}
+	return nil
+}
+
 // IsWithinRoot checks if a path is within the root directory
 // Uses proper canonicalization and symlink resolution for security
 // Returns (isWithin bool, error)
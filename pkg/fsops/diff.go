@@ -0,0 +1,515 @@
+package fsops
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// DiffEngine generates and applies textual patches
+// FileOps delegates all patch handling to its engine so the patch format is pluggable
+type DiffEngine interface {
+	// Name identifies the patch format produced by the engine
+	Name() string
+
+	// Diff renders a patch that transforms oldContent into newContent
+	// Returns an empty string when the contents are identical
+	Diff(path, oldContent, newContent string) string
+
+	// Apply applies a patch to content and returns the patched content
+	// Returns error if the patch is malformed or does not match content
+	Apply(content, diff string) (string, error)
+
+	// Validate checks that a patch is well formed without applying it
+	Validate(diff string) error
+}
+
+// DefaultContextLines is the number of unchanged lines surrounding each hunk
+const DefaultContextLines = 3
+
+// FullContext as a context length makes a unified diff carry the whole file
+// as context, so both the old and the new content can be read back from it
+const FullContext = -1
+
+// noNewlineMarker follows a diff line whose content has no trailing newline
+const noNewlineMarker = `\ No newline at end of file`
+
+// devNull is the path used in file headers for created and deleted files
+const devNull = "/dev/null"
+
+// NewUnifiedDiffEngine creates a line-based engine producing standard unified
+// diffs with a/ and b/ path prefixes, suitable for git apply and patch -p1
+func NewUnifiedDiffEngine() DiffEngine {
+	return &unifiedDiffEngine{contextLines: DefaultContextLines}
+}
+
+// NewUnifiedDiffEngineWithContext creates a unified diff engine surrounding
+// each hunk with contextLines unchanged lines, or the whole file for FullContext
+func NewUnifiedDiffEngineWithContext(contextLines int) DiffEngine {
+	return &unifiedDiffEngine{contextLines: contextLines}
+}
+
+// NewDMPDiffEngine creates a character-based engine using diff-match-patch's
+// patch text format. Patches are compact but not readable by standard tooling.
+func NewDMPDiffEngine() DiffEngine {
+	return &dmpDiffEngine{}
+}
+
+// NewDiffEngineByName returns the engine whose Name matches name, so patches
+// can be applied with the format they were produced in
+func NewDiffEngineByName(name string) (DiffEngine, error) {
+	for _, engine := range []DiffEngine{NewUnifiedDiffEngine(), NewDMPDiffEngine()} {
+		if engine.Name() == name {
+			return engine, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown patch format: %q", name)
+}
+
+// unifiedDiffEngine implements DiffEngine using the unified diff format
+type unifiedDiffEngine struct {
+	contextLines int
+}
+
+// diffOp is a single line of a line-level diff
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// hunk is one @@ section of a unified diff
+type hunk struct {
+	oldStart, oldLines int
+	newStart, newLines int
+	ops                []diffOp
+}
+
+// Name returns the patch format name
+func (e *unifiedDiffEngine) Name() string {
+	return "unified"
+}
+
+// Diff renders a unified diff from oldContent to newContent
+func (e *unifiedDiffEngine) Diff(path, oldContent, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+
+	ops := diffLines(oldContent, newContent)
+	contextLines := e.contextLines
+	if contextLines < 0 {
+		contextLines = len(ops)
+	}
+
+	oldName, newName := "a/"+path, "b/"+path
+	if oldContent == "" {
+		oldName = devNull
+	}
+	if newContent == "" {
+		newName = devNull
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName))
+	for _, h := range groupHunks(ops, contextLines) {
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			formatRange(h.oldStart, h.oldLines), formatRange(h.newStart, h.newLines)))
+		for _, op := range h.ops {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.text)
+			if !strings.HasSuffix(op.text, "\n") {
+				sb.WriteString("\n" + noNewlineMarker + "\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// Apply applies a unified diff to content
+func (e *unifiedDiffEngine) Apply(content, diff string) (string, error) {
+	hunks, creates, err := parseUnifiedDiff(diff)
+	if err != nil {
+		return "", err
+	}
+
+	if creates && content != "" {
+		return "", fmt.Errorf("patch creates a file that already exists")
+	}
+
+	lines := splitLines(content)
+	var out strings.Builder
+	pos := 0
+
+	for i, h := range hunks {
+		var oldSide, newSide []string
+		for _, op := range h.ops {
+			if op.kind != '+' {
+				oldSide = append(oldSide, op.text)
+			}
+			if op.kind != '-' {
+				newSide = append(newSide, op.text)
+			}
+		}
+
+		expected := h.oldStart - 1
+		if h.oldLines == 0 {
+			expected = h.oldStart
+		}
+
+		at := locateHunk(lines, oldSide, expected, pos)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d of %d does not apply at line %d", i+1, len(hunks), h.oldStart)
+		}
+
+		for _, line := range lines[pos:at] {
+			out.WriteString(line)
+		}
+		for _, line := range newSide {
+			out.WriteString(line)
+		}
+		pos = at + len(oldSide)
+	}
+
+	for _, line := range lines[pos:] {
+		out.WriteString(line)
+	}
+
+	return out.String(), nil
+}
+
+// Validate checks that diff parses as a unified diff
+func (e *unifiedDiffEngine) Validate(diff string) error {
+	_, _, err := parseUnifiedDiff(diff)
+	return err
+}
+
+// diffLines computes a line-level diff with each line keeping its terminator
+func diffLines(oldContent, newContent string) []diffOp {
+	dmp := diffmatchpatch.New()
+	oldRunes, newRunes, lineArray := dmp.DiffLinesToRunes(oldContent, newContent)
+	diffs := dmp.DiffMainRunes(oldRunes, newRunes, false)
+
+	var ops []diffOp
+	for _, d := range diffs {
+		kind := byte(' ')
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			kind = '-'
+		case diffmatchpatch.DiffInsert:
+			kind = '+'
+		}
+		for _, r := range d.Text {
+			ops = append(ops, diffOp{kind: kind, text: lineArray[r]})
+		}
+	}
+
+	return ops
+}
+
+// groupHunks splits a line diff into hunks, merging changes separated by no
+// more than twice the context length
+func groupHunks(ops []diffOp, contextLines int) []hunk {
+	var hunks []hunk
+	oldLine, newLine := 0, 0 // lines consumed before ops[i]
+	i := 0
+
+	for i < len(ops) {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// Back up to include leading context
+		lead := 0
+		for lead < contextLines && i-lead-1 >= 0 && ops[i-lead-1].kind == ' ' {
+			lead++
+		}
+		start := i - lead
+		h := hunk{oldStart: oldLine - lead, newStart: newLine - lead}
+
+		// Extend until a run of unchanged lines too long to bridge
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := 0
+			for end+run < len(ops) && ops[end+run].kind == ' ' {
+				run++
+			}
+			if end+run == len(ops) || run > 2*contextLines {
+				end += minInt(run, contextLines)
+				break
+			}
+			end += run
+		}
+
+		h.ops = ops[start:end]
+		for _, op := range h.ops {
+			if op.kind != '+' {
+				h.oldLines++
+			}
+			if op.kind != '-' {
+				h.newLines++
+			}
+		}
+		hunks = append(hunks, h)
+
+		// Advance counters past the hunk
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+
+	return hunks
+}
+
+// formatRange renders a hunk range from a zero-based start line
+func formatRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return strconv.Itoa(start + 1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// parseUnifiedDiff parses the hunks of a single-file unified diff and reports
+// whether the diff creates its file
+func parseUnifiedDiff(diff string) ([]hunk, bool, error) {
+	lines := strings.Split(diff, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var hunks []hunk
+	creates := false
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if !strings.HasPrefix(line, "@@") {
+			if strings.HasPrefix(line, "--- ") && len(hunks) == 0 {
+				creates = strings.TrimSpace(strings.TrimPrefix(line, "--- ")) == devNull
+			}
+			// File headers and git extended headers carry no hunk content
+			continue
+		}
+
+		h, err := parseHunkHeader(line)
+		if err != nil {
+			return nil, false, err
+		}
+
+		oldSeen, newSeen := 0, 0
+		for oldSeen < h.oldLines || newSeen < h.newLines {
+			i++
+			if i >= len(lines) {
+				return nil, false, fmt.Errorf("hunk %q is truncated", line)
+			}
+
+			body := lines[i]
+			kind := byte(' ')
+			text := ""
+			if body != "" {
+				kind, text = body[0], body[1:]
+			}
+
+			switch kind {
+			case ' ':
+				oldSeen++
+				newSeen++
+			case '-':
+				oldSeen++
+			case '+':
+				newSeen++
+			case '\\':
+				dropLastNewline(&h)
+				continue
+			default:
+				return nil, false, fmt.Errorf("invalid line in hunk %q: %q", line, body)
+			}
+
+			h.ops = append(h.ops, diffOp{kind: kind, text: text + "\n"})
+		}
+
+		if oldSeen != h.oldLines || newSeen != h.newLines {
+			return nil, false, fmt.Errorf("hunk %q line counts do not match its header", line)
+		}
+
+		// A trailing marker applies to the hunk's final line
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], `\`) {
+			i++
+			dropLastNewline(&h)
+		}
+
+		hunks = append(hunks, h)
+	}
+
+	if len(hunks) == 0 {
+		return nil, false, fmt.Errorf("no hunks found in diff")
+	}
+
+	return hunks, creates, nil
+}
+
+// parseHunkHeader parses an "@@ -l,s +l,s @@" line
+func parseHunkHeader(line string) (hunk, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[0] != "@@" || fields[3] != "@@" ||
+		!strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return hunk{}, fmt.Errorf("invalid hunk header: %q", line)
+	}
+
+	oldStart, oldLines, err := parseRange(fields[1][1:])
+	if err != nil {
+		return hunk{}, fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+	newStart, newLines, err := parseRange(fields[2][1:])
+	if err != nil {
+		return hunk{}, fmt.Errorf("invalid hunk header %q: %w", line, err)
+	}
+
+	return hunk{oldStart: oldStart, oldLines: oldLines, newStart: newStart, newLines: newLines}, nil
+}
+
+// parseRange parses "start[,count]" where count defaults to 1
+func parseRange(s string) (int, int, error) {
+	startText, countText, hasCount := strings.Cut(s, ",")
+
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range start %q", startText)
+	}
+
+	count := 1
+	if hasCount {
+		count, err = strconv.Atoi(countText)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid range count %q", countText)
+		}
+	}
+
+	return start, count, nil
+}
+
+// dropLastNewline strips the terminator from the hunk's most recent line
+func dropLastNewline(h *hunk) {
+	if len(h.ops) > 0 {
+		last := &h.ops[len(h.ops)-1]
+		last.text = strings.TrimSuffix(last.text, "\n")
+	}
+}
+
+// locateHunk finds where oldSide matches lines, preferring the expected
+// position and then the nearest offset at or after minPos. Returns -1 if the
+// hunk matches nowhere.
+func locateHunk(lines, oldSide []string, expected, minPos int) int {
+	matches := func(at int) bool {
+		if at < minPos || at+len(oldSide) > len(lines) {
+			return false
+		}
+		for j, want := range oldSide {
+			if lines[at+j] != want {
+				return false
+			}
+		}
+		return true
+	}
+
+	for offset := 0; offset <= len(lines); offset++ {
+		if matches(expected + offset) {
+			return expected + offset
+		}
+		if offset > 0 && matches(expected-offset) {
+			return expected - offset
+		}
+	}
+
+	return -1
+}
+
+// splitLines splits content into lines, keeping each line's terminator
+func splitLines(content string) []string {
+	var lines []string
+	for content != "" {
+		idx := strings.IndexByte(content, '\n')
+		if idx < 0 {
+			lines = append(lines, content)
+			break
+		}
+		lines = append(lines, content[:idx+1])
+		content = content[idx+1:]
+	}
+	return lines
+}
+
+// dmpDiffEngine implements DiffEngine using diff-match-patch patch text
+type dmpDiffEngine struct{}
+
+// Name returns the patch format name
+func (e *dmpDiffEngine) Name() string {
+	return "dmp"
+}
+
+// Diff renders a diff-match-patch patch from oldContent to newContent
+func (e *dmpDiffEngine) Diff(_, oldContent, newContent string) string {
+	dmp := diffmatchpatch.New()
+	diffs := dmp.DiffMain(oldContent, newContent, false)
+
+	// Optimize the diffs
+	diffs = dmp.DiffCleanupSemantic(diffs)
+
+	return dmp.PatchToText(dmp.PatchMake(oldContent, diffs))
+}
+
+// Apply applies a diff-match-patch patch to content
+func (e *dmpDiffEngine) Apply(content, diff string) (string, error) {
+	dmp := diffmatchpatch.New()
+	patches, err := dmp.PatchFromText(diff)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse patch: %w", err)
+	}
+
+	if len(patches) == 0 {
+		return "", fmt.Errorf("no patches found in diff")
+	}
+
+	newContent, results := dmp.PatchApply(patches, content)
+	for i, result := range results {
+		if !result {
+			return "", fmt.Errorf("failed to apply patch %d of %d", i+1, len(patches))
+		}
+	}
+
+	return newContent, nil
+}
+
+// Validate checks that diff parses as diff-match-patch patch text
+func (e *dmpDiffEngine) Validate(diff string) error {
+	dmp := diffmatchpatch.New()
+	patches, err := dmp.PatchFromText(diff)
+	if err != nil {
+		return fmt.Errorf("failed to parse patch: %w", err)
+	}
+
+	if len(patches) == 0 {
+		return fmt.Errorf("no valid patches found")
+	}
+
+	return nil
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
+	"github.com/dshills/gocreator/internal/logctx"
 	"github.com/dshills/gocreator/internal/models"
 )
 
@@ -82,14 +84,25 @@ type FileOps interface {
 
 // fileOps implements the FileOps interface
 type fileOps struct {
-	rootDir string
-	logger  Logger
+	rootDir    string
+	logger     Logger
+	diffEngine DiffEngine
+	protected  models.ProtectedPaths
+	openFiles  chan struct{} // Nil when open files are not capped
 }
 
 // Config holds configuration for FileOps
 type Config struct {
-	RootDir string
-	Logger  Logger
+	RootDir    string
+	Logger     Logger
+	DiffEngine DiffEngine // Patch format (default: unified diff)
+
+	// ProtectedPaths are globs under RootDir that writes, patches and deletes refuse
+	ProtectedPaths models.ProtectedPaths
+
+	// MaxOpenFiles caps the files open at once across concurrent operations;
+	// further operations wait for one to close (0: unlimited)
+	MaxOpenFiles int
 }
 
 // New creates a new FileOps instance with the given configuration
@@ -97,6 +110,12 @@ func New(cfg Config) (FileOps, error) {
 	if cfg.RootDir == "" {
 		return nil, fmt.Errorf("root directory cannot be empty")
 	}
+	if err := cfg.ProtectedPaths.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.MaxOpenFiles < 0 {
+		return nil, fmt.Errorf("max open files must not be negative")
+	}
 
 	// Get absolute path of root directory
 	absRoot, err := filepath.Abs(cfg.RootDir)
@@ -117,15 +136,28 @@ func New(cfg Config) (FileOps, error) {
 		logger = &noopLogger{}
 	}
 
+	diffEngine := cfg.DiffEngine
+	if diffEngine == nil {
+		diffEngine = NewUnifiedDiffEngine()
+	}
+
+	var openFiles chan struct{}
+	if cfg.MaxOpenFiles > 0 {
+		openFiles = make(chan struct{}, cfg.MaxOpenFiles)
+	}
+
 	return &fileOps{
-		rootDir: absRoot,
-		logger:  logger,
+		rootDir:    absRoot,
+		logger:     logger,
+		diffEngine: diffEngine,
+		protected:  cfg.ProtectedPaths,
+		openFiles:  openFiles,
 	}, nil
 }
 
 // WriteFile writes content to a file within the bounded root
 func (f *fileOps) WriteFile(ctx context.Context, path, content string) error {
-	if err := f.ValidatePath(path); err != nil {
+	if err := f.validateWritePath(path); err != nil {
 		return err
 	}
 
@@ -156,10 +188,20 @@ func (f *fileOps) WriteFile(ctx context.Context, path, content string) error {
 	}
 
 	// Write the file
+	release, err := f.openSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
 	if err := os.WriteFile(absPath, []byte(content), 0600); err != nil {
 		return fmt.Errorf("failed to write file %s: %w", path, err)
 	}
 
+	logctx.Logger(ctx).Debug().
+		Str("path", path).
+		Str("checksum", checksum).
+		Msg("Wrote file")
+
 	return nil
 }
 
@@ -187,6 +229,12 @@ func (f *fileOps) ReadFile(ctx context.Context, path string) (string, error) {
 		return "", fmt.Errorf("failed to log operation: %w", err)
 	}
 
+	release, err := f.openSlot(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
 	//nolint:gosec // G304: File inclusion required for bounded file operations
 	data, err := os.ReadFile(absPath)
 	if err != nil {
@@ -198,7 +246,7 @@ func (f *fileOps) ReadFile(ctx context.Context, path string) (string, error) {
 
 // DeleteFile deletes a file within the bounded root
 func (f *fileOps) DeleteFile(ctx context.Context, path string) error {
-	if err := f.ValidatePath(path); err != nil {
+	if err := f.validateWritePath(path); err != nil {
 		return err
 	}
 
@@ -294,6 +342,21 @@ func (f *fileOps) GenerateChecksum(content string) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// openSlot waits until a file may be opened under MaxOpenFiles, giving up
+// when ctx is done, and returns the function that frees the slot. The
+// function may be called more than once.
+func (f *fileOps) openSlot(ctx context.Context) (func(), error) {
+	if f.openFiles == nil {
+		return func() {}, nil
+	}
+	select {
+	case f.openFiles <- struct{}{}:
+		return sync.OnceFunc(func() { <-f.openFiles }), nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a free file slot: %w", ctx.Err())
+	}
+}
+
 // getAbsolutePath returns the absolute path for a given relative path
 func (f *fileOps) getAbsolutePath(path string) (string, error) {
 	// Join with root directory
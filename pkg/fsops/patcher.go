@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/dshills/gocreator/internal/models"
-	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
 // PatchResult contains the result of applying a patch
@@ -18,9 +17,9 @@ type PatchResult struct {
 	LinesChanged int
 }
 
-// ApplyPatch applies a unified diff patch to a file
+// ApplyPatch applies a patch to a file using the configured diff engine
 func (f *fileOps) ApplyPatch(ctx context.Context, patch models.Patch) error {
-	if err := f.ValidatePath(patch.TargetFile); err != nil {
+	if err := f.validateWritePath(patch.TargetFile); err != nil {
 		return fmt.Errorf("invalid target file path: %w", err)
 	}
 
@@ -45,24 +44,9 @@ func (f *fileOps) ApplyPatch(ctx context.Context, patch models.Patch) error {
 	originalHash := f.GenerateChecksum(currentContent)
 
 	// Apply the patch
-	dmp := diffmatchpatch.New()
-	patches, err := dmp.PatchFromText(patch.Diff)
+	newContent, err := f.diffEngine.Apply(currentContent, patch.Diff)
 	if err != nil {
-		return fmt.Errorf("failed to parse patch: %w", err)
-	}
-
-	if len(patches) == 0 {
-		return fmt.Errorf("no patches found in diff")
-	}
-
-	// Apply patches
-	newContent, results := dmp.PatchApply(patches, currentContent)
-
-	// Check if all patches applied successfully
-	for i, result := range results {
-		if !result {
-			return fmt.Errorf("failed to apply patch %d of %d", i+1, len(patches))
-		}
+		return fmt.Errorf("failed to apply patch: %w", err)
 	}
 
 	// Calculate new checksum
@@ -101,17 +85,7 @@ func (f *fileOps) GeneratePatch(_ context.Context, targetFile, oldContent, newCo
 		return models.Patch{}, fmt.Errorf("invalid target file path: %w", err)
 	}
 
-	dmp := diffmatchpatch.New()
-	diffs := dmp.DiffMain(oldContent, newContent, false)
-
-	// Optimize the diffs
-	diffs = dmp.DiffCleanupSemantic(diffs)
-
-	// Create patches
-	patches := dmp.PatchMake(oldContent, diffs)
-
-	// Convert to text format
-	patchText := dmp.PatchToText(patches)
+	patchText := f.diffEngine.Diff(targetFile, oldContent, newContent)
 
 	patch := models.Patch{
 		TargetFile: targetFile,
@@ -179,6 +153,10 @@ func (f *fileOps) ReversePatch(ctx context.Context, patch models.Patch) (models.
 
 // ApplyPatchWithBackup applies a patch and creates a backup for reversal
 func (f *fileOps) ApplyPatchWithBackup(ctx context.Context, patch models.Patch) error {
+	if err := f.validateWritePath(patch.TargetFile); err != nil {
+		return fmt.Errorf("invalid target file path: %w", err)
+	}
+
 	// First check if file exists and back it up
 	exists, err := f.Exists(ctx, patch.TargetFile)
 	if err != nil {
@@ -205,7 +183,7 @@ func (f *fileOps) ApplyPatchWithBackup(ctx context.Context, patch models.Patch)
 
 // ValidatePatch validates a patch without applying it
 func (f *fileOps) ValidatePatch(ctx context.Context, patch models.Patch) error {
-	if err := f.ValidatePath(patch.TargetFile); err != nil {
+	if err := f.validateWritePath(patch.TargetFile); err != nil {
 		return fmt.Errorf("invalid target file path: %w", err)
 	}
 
@@ -214,16 +192,10 @@ func (f *fileOps) ValidatePatch(ctx context.Context, patch models.Patch) error {
 	}
 
 	// Try to parse the patch
-	dmp := diffmatchpatch.New()
-	patches, err := dmp.PatchFromText(patch.Diff)
-	if err != nil {
+	if err := f.diffEngine.Validate(patch.Diff); err != nil {
 		return fmt.Errorf("failed to parse patch: %w", err)
 	}
 
-	if len(patches) == 0 {
-		return fmt.Errorf("no valid patches found")
-	}
-
 	// Optionally, try to apply to current file to see if it would succeed
 	exists, err := f.Exists(ctx, patch.TargetFile)
 	if err != nil {
@@ -236,9 +208,8 @@ func (f *fileOps) ValidatePatch(ctx context.Context, patch models.Patch) error {
 			return fmt.Errorf("failed to read target file: %w", err)
 		}
 
-		_, results := dmp.PatchApply(patches, content)
-		if !allTrue(results) {
-			return fmt.Errorf("patch would fail to apply to current file state")
+		if _, err := f.diffEngine.Apply(content, patch.Diff); err != nil {
+			return fmt.Errorf("patch would fail to apply to current file state: %w", err)
 		}
 	}
 
@@ -289,16 +260,6 @@ func (f *fileOps) GetPatchStats(patch models.Patch) (added, removed, modified in
 	return added, removed, modified, nil
 }
 
-// allTrue checks if all boolean values in a slice are true
-func allTrue(values []bool) bool {
-	for _, v := range values {
-		if !v {
-			return false
-		}
-	}
-	return true
-}
-
 // minInt returns the minimum of two integers
 func minInt(a, b int) int {
 	if a < b {
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 
 	anthropicsdk "github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
@@ -15,25 +16,39 @@ import (
 // anthropicClient implements the Client interface for Anthropic (Claude)
 type anthropicClient struct {
 	baseClient
-	chatModel    *anthropic.ChatModel
+	chatModel    model.ChatModel
 	directClient anthropicsdk.Client // Direct SDK client for cache support
+	httpClient   *http.Client        // Client configured from config.Network, if any
 	cacheMetrics PromptCacheMetrics  // Track prompt cache usage
 }
 
 // newAnthropicClient creates a new Anthropic client
 func newAnthropicClient(config Config) (*anthropicClient, error) {
-	// Create langgraph-go Anthropic ChatModel
-	chatModel := anthropic.NewChatModel(config.APIKey, config.Model)
-
 	// Create direct Anthropic SDK client for cache support
-	directClient := anthropicsdk.NewClient(
-		option.WithAPIKey(config.APIKey),
-	)
+	opts := []option.RequestOption{option.WithAPIKey(config.APIKey)}
+	var httpClient *http.Client
+	if !config.Network.IsZero() {
+		var err error
+		httpClient, err = NewHTTPClient(config.Network, config.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+		opts = append(opts, option.WithHTTPClient(httpClient))
+	}
+	directClient := anthropicsdk.NewClient(opts...)
+
+	// Create langgraph-go Anthropic ChatModel, unless it has to send
+	// through the configured HTTP client
+	var chatModel model.ChatModel = anthropic.NewChatModel(config.APIKey, config.Model)
+	if httpClient != nil {
+		chatModel = &anthropicDirectChat{client: directClient, model: config.Model, maxTokens: config.MaxTokens}
+	}
 
 	return &anthropicClient{
 		baseClient:   baseClient{config: config},
 		chatModel:    chatModel,
 		directClient: directClient,
+		httpClient:   httpClient,
 		cacheMetrics: PromptCacheMetrics{},
 	}, nil
 }
@@ -66,53 +81,80 @@ func (c *anthropicClient) Generate(ctx context.Context, prompt string) (string,
 	return result, nil
 }
 
-// GenerateStructured produces structured output based on a schema
+// GenerateStructured produces structured output based on a schema. The model
+// returns it through a forced call of the respond tool, whose input schema is
+// schema; a response that does not match is answered with the violations as
+// the tool's result until one does.
 func (c *anthropicClient) GenerateStructured(ctx context.Context, prompt string, schema interface{}) (interface{}, error) {
-	// For structured output, we append schema information to the prompt
-	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	object, err := toolSchema(schema)
 	if err != nil {
-		return nil, c.wrapError("generate_structured", fmt.Errorf("failed to marshal schema: %w", err))
+		return nil, c.wrapError("generate_structured", err)
+	}
+	inputSchema := anthropicsdk.ToolInputSchemaParam{ExtraFields: make(map[string]any)}
+	for keyword, value := range object {
+		if keyword != "type" {
+			inputSchema.ExtraFields[keyword] = value
+		}
 	}
 
-	structuredPrompt := fmt.Sprintf(`%s
-
-Please respond with valid JSON that matches this schema:
-%s
-
-Return ONLY the JSON, with no additional text or explanation.`, prompt, schemaJSON)
-
-	var result string
+	params := anthropicsdk.MessageNewParams{
+		Model:     anthropicsdk.Model(c.config.Model),
+		MaxTokens: int64(c.config.MaxTokens),
+		Messages:  []anthropicsdk.MessageParam{anthropicsdk.NewUserMessage(anthropicsdk.NewTextBlock(prompt))},
+		Tools: []anthropicsdk.ToolUnionParam{{OfTool: &anthropicsdk.ToolParam{
+			Name:        StructuredOutputTool,
+			Description: anthropicsdk.String(structuredOutputDescription),
+			InputSchema: inputSchema,
+		}}},
+		ToolChoice: anthropicsdk.ToolChoiceUnionParam{OfTool: &anthropicsdk.ToolChoiceToolParam{Name: StructuredOutputTool}},
+	}
 
-	// Execute with retry logic
-	err = c.retry(ctx, "generate_structured", func() error {
-		// Create messages for the chat
-		messages := []model.Message{
-			{Role: model.RoleUser, Content: structuredPrompt},
+	var toolUseID string
+	output, err := generateValidated(schema, func(problems string) ([]byte, error) {
+		if problems != "" {
+			params.Messages = append(params.Messages,
+				anthropicsdk.NewUserMessage(anthropicsdk.NewToolResultBlock(toolUseID, problems, true)))
 		}
 
-		// Call ChatModel
-		out, err := c.chatModel.Chat(ctx, messages, nil)
+		var input []byte
+		err := c.retry(ctx, "generate_structured", func() error {
+			response, err := c.directClient.Messages.New(ctx, params)
+			if err != nil {
+				return err
+			}
+			c.recordUsage(response.Usage.CacheCreationInputTokens, response.Usage.CacheReadInputTokens,
+				response.Usage.InputTokens, response.Usage.OutputTokens)
+
+			if response.StopReason == anthropicsdk.StopReasonMaxTokens {
+				return truncatedCall("max_tokens", c.config.MaxTokens, "output")
+			}
+			for _, block := range response.Content {
+				if block.Type == "tool_use" && block.Name == StructuredOutputTool {
+					toolUseID, input = block.ID, block.Input
+					return nil
+				}
+			}
+			return fmt.Errorf("response did not call %s", StructuredOutputTool)
+		})
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		result = out.Text
-		return nil
+		params.Messages = append(params.Messages, anthropicsdk.NewAssistantMessage(
+			anthropicsdk.NewToolUseBlock(toolUseID, json.RawMessage(input), StructuredOutputTool)))
+		return input, nil
 	})
-
 	if err != nil {
 		return nil, c.wrapError("generate_structured", err)
 	}
-
-	// Parse the JSON response
-	var output interface{}
-	if err := json.Unmarshal([]byte(result), &output); err != nil {
-		return nil, c.wrapError("generate_structured", fmt.Errorf("failed to parse JSON response: %w", err))
-	}
-
 	return output, nil
 }
 
+// StructuredOutput implements StructuredOutputClient
+func (c *anthropicClient) StructuredOutput() bool {
+	return true
+}
+
 // Chat processes a sequence of messages and returns the assistant's response
 func (c *anthropicClient) Chat(ctx context.Context, messages []Message) (string, error) {
 	if len(messages) == 0 {
@@ -174,40 +216,7 @@ func (c *anthropicClient) GenerateWithCache(ctx context.Context, messages []Cach
 	}
 
 	// Build Anthropic SDK message request with cache support
-	var systemBlocks []anthropicsdk.TextBlockParam
-	var userMessages []anthropicsdk.MessageParam
-
-	for _, msg := range messages {
-		if msg.Role == "system" {
-			// Create text block parameter
-			textBlockParam := anthropicsdk.TextBlockParam{
-				Text: msg.Content,
-			}
-
-			// Add cache control if specified
-			if msg.Cache != nil {
-				cacheCtrl := anthropicsdk.NewCacheControlEphemeralParam()
-				if msg.Cache.TTL == "1h" {
-					cacheCtrl.TTL = anthropicsdk.CacheControlEphemeralTTLTTL1h
-				} else {
-					cacheCtrl.TTL = anthropicsdk.CacheControlEphemeralTTLTTL5m
-				}
-				textBlockParam.CacheControl = cacheCtrl
-			}
-			systemBlocks = append(systemBlocks, textBlockParam)
-		} else {
-			// User or assistant messages
-			textBlock := anthropicsdk.NewTextBlock(msg.Content)
-
-			// Build message parameter
-			userMsg := anthropicsdk.NewUserMessage(textBlock)
-			if msg.Role == "assistant" {
-				userMsg = anthropicsdk.NewAssistantMessage(textBlock)
-			}
-
-			userMessages = append(userMessages, userMsg)
-		}
-	}
+	systemBlocks, userMessages := anthropicMessages(messages)
 
 	var result string
 
@@ -240,20 +249,8 @@ func (c *anthropicClient) GenerateWithCache(ctx context.Context, messages []Cach
 		}
 
 		// Update cache metrics from usage
-		if response.Usage.CacheCreationInputTokens > 0 {
-			c.cacheMetrics.CacheCreationTokens += response.Usage.CacheCreationInputTokens
-			c.cacheMetrics.CacheMisses++
-		}
-		if response.Usage.CacheReadInputTokens > 0 {
-			c.cacheMetrics.CacheReadTokens += response.Usage.CacheReadInputTokens
-			c.cacheMetrics.CacheHits++
-		}
-		if response.Usage.InputTokens > 0 {
-			c.cacheMetrics.InputTokens += response.Usage.InputTokens
-		}
-		if response.Usage.OutputTokens > 0 {
-			c.cacheMetrics.OutputTokens += response.Usage.OutputTokens
-		}
+		c.recordUsage(response.Usage.CacheCreationInputTokens, response.Usage.CacheReadInputTokens,
+			response.Usage.InputTokens, response.Usage.OutputTokens)
 
 		return nil
 	})
@@ -265,6 +262,65 @@ func (c *anthropicClient) GenerateWithCache(ctx context.Context, messages []Cach
 	return result, nil
 }
 
+// anthropicMessages converts cacheable messages into system blocks, which
+// carry the cache control, and the conversation
+func anthropicMessages(messages []CacheableMessage) ([]anthropicsdk.TextBlockParam, []anthropicsdk.MessageParam) {
+	var systemBlocks []anthropicsdk.TextBlockParam
+	var userMessages []anthropicsdk.MessageParam
+
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			// Create text block parameter
+			textBlockParam := anthropicsdk.TextBlockParam{
+				Text: msg.Content,
+			}
+
+			// Add cache control if specified
+			if msg.Cache != nil {
+				cacheCtrl := anthropicsdk.NewCacheControlEphemeralParam()
+				if msg.Cache.TTL == "1h" {
+					cacheCtrl.TTL = anthropicsdk.CacheControlEphemeralTTLTTL1h
+				} else {
+					cacheCtrl.TTL = anthropicsdk.CacheControlEphemeralTTLTTL5m
+				}
+				textBlockParam.CacheControl = cacheCtrl
+			}
+			systemBlocks = append(systemBlocks, textBlockParam)
+		} else {
+			// User or assistant messages
+			textBlock := anthropicsdk.NewTextBlock(msg.Content)
+
+			// Build message parameter
+			userMsg := anthropicsdk.NewUserMessage(textBlock)
+			if msg.Role == "assistant" {
+				userMsg = anthropicsdk.NewAssistantMessage(textBlock)
+			}
+
+			userMessages = append(userMessages, userMsg)
+		}
+	}
+
+	return systemBlocks, userMessages
+}
+
+// recordUsage adds the token usage of a response to the cache metrics
+func (c *anthropicClient) recordUsage(cacheCreation, cacheRead, input, output int64) {
+	if cacheCreation > 0 {
+		c.cacheMetrics.CacheCreationTokens += cacheCreation
+		c.cacheMetrics.CacheMisses++
+	}
+	if cacheRead > 0 {
+		c.cacheMetrics.CacheReadTokens += cacheRead
+		c.cacheMetrics.CacheHits++
+	}
+	if input > 0 {
+		c.cacheMetrics.InputTokens += input
+	}
+	if output > 0 {
+		c.cacheMetrics.OutputTokens += output
+	}
+}
+
 // GetCacheMetrics returns the current prompt cache metrics
 func (c *anthropicClient) GetCacheMetrics() PromptCacheMetrics {
 	return c.cacheMetrics
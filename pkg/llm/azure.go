@@ -0,0 +1,223 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAzureAPIVersion is the Azure OpenAI API version used when
+// Config.APIVersion is not set
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// azureResource is the resource Entra ID tokens for Azure OpenAI are issued for
+const azureResource = "https://cognitiveservices.azure.com"
+
+// azureClient implements the Client interface for Azure OpenAI, which serves
+// the OpenAI chat completions API under a deployment of the resource
+type azureClient struct {
+	baseClient
+	httpClient *http.Client
+	url        string           // Chat completions URL of the deployment
+	tokens     *managedIdentity // Entra ID tokens when there is no API key
+}
+
+// newAzureClient creates a client for the deployment at config.BaseURL. It
+// authenticates with config.APIKey, or without one with the managed identity
+// of the Azure host it runs on.
+func newAzureClient(config Config) (*azureClient, error) {
+	httpClient, err := NewHTTPClient(config.Network, config.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	deployment := config.Deployment
+	if deployment == "" {
+		deployment = config.Model
+	}
+	apiVersion := config.APIVersion
+	if apiVersion == "" {
+		apiVersion = DefaultAzureAPIVersion
+	}
+
+	client := &azureClient{
+		baseClient: baseClient{config: config},
+		httpClient: httpClient,
+		url: fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+			strings.TrimSuffix(config.BaseURL, "/"), url.PathEscape(deployment), url.QueryEscape(apiVersion)),
+	}
+	if config.APIKey == "" {
+		client.tokens = newManagedIdentity()
+	}
+	return client, nil
+}
+
+// endpoint posts to the deployment with the API key, or a managed identity
+// token without one
+func (c *azureClient) endpoint() chatEndpoint {
+	return func(ctx context.Context, body []byte) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if c.tokens == nil {
+			req.Header.Set("api-key", c.config.APIKey)
+		} else {
+			token, err := c.tokens.token(ctx)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}
+}
+
+// Generate produces text from a single prompt
+func (c *azureClient) Generate(ctx context.Context, prompt string) (string, error) {
+	result, err := c.complete(ctx, "generate", []map[string]string{{"role": "user", "content": prompt}})
+	if err != nil {
+		return "", c.wrapError("generate", err)
+	}
+	return result, nil
+}
+
+// GenerateStructured produces structured output based on a schema through
+// function calling, like the openai provider
+func (c *azureClient) GenerateStructured(ctx context.Context, prompt string, schema interface{}) (interface{}, error) {
+	output, err := c.callFunction(ctx, c.httpClient, c.endpoint(), prompt, schema)
+	if err != nil {
+		return nil, c.wrapError("generate_structured", err)
+	}
+	return output, nil
+}
+
+// StructuredOutput implements StructuredOutputClient
+func (c *azureClient) StructuredOutput() bool {
+	return true
+}
+
+// Chat processes a sequence of messages and returns the assistant's response
+func (c *azureClient) Chat(ctx context.Context, messages []Message) (string, error) {
+	if len(messages) == 0 {
+		return "", c.wrapError("chat", fmt.Errorf("messages cannot be empty"))
+	}
+
+	chatMessages := make([]map[string]string, 0, len(messages))
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system", "user", "assistant":
+		default:
+			return "", c.wrapError("chat", fmt.Errorf("invalid message role: %s", msg.Role))
+		}
+		chatMessages = append(chatMessages, map[string]string{"role": msg.Role, "content": msg.Content})
+	}
+
+	result, err := c.complete(ctx, "chat", chatMessages)
+	if err != nil {
+		return "", c.wrapError("chat", err)
+	}
+	return result, nil
+}
+
+// GenerateStream implements StreamingClient with streaming chat completions
+func (c *azureClient) GenerateStream(ctx context.Context, messages []CacheableMessage, w io.Writer) (int64, error) {
+	input := openaiStreamInput{
+		openaiChatInput: openaiChatInput{
+			Model:               c.config.Model,
+			Temperature:         c.config.Temperature,
+			MaxCompletionTokens: c.config.MaxTokens,
+		},
+		Stream: true,
+	}
+	for _, msg := range messages {
+		input.Messages = append(input.Messages, map[string]string{"role": msg.Role, "content": msg.Content})
+	}
+	return c.streamChatCompletions(ctx, streamHTTPClient(c.httpClient), c.endpoint(), input, w)
+}
+
+// complete sends a chat completion request with retry logic and returns the
+// content of the first choice
+func (c *azureClient) complete(ctx context.Context, operation string, messages []map[string]string) (string, error) {
+	return c.chatCompletion(ctx, operation, c.httpClient, c.endpoint(), openaiChatInput{
+		Model:               c.config.Model,
+		Messages:            messages,
+		Temperature:         c.config.Temperature,
+		MaxCompletionTokens: c.config.MaxTokens,
+	})
+}
+
+// managedIdentity fetches Entra ID tokens for Azure OpenAI from the instance
+// metadata service of the Azure VM, container or App Service it runs on. A
+// user-assigned identity is chosen with AZURE_CLIENT_ID.
+type managedIdentity struct {
+	endpoint   string
+	clientID   string
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	current string
+	expires time.Time
+}
+
+// newManagedIdentity returns a token source for the host's managed identity
+func newManagedIdentity() *managedIdentity {
+	return &managedIdentity{
+		endpoint:   "http://169.254.169.254/metadata/identity/oauth2/token",
+		clientID:   os.Getenv("AZURE_CLIENT_ID"),
+		httpClient: metadataHTTPClient(),
+	}
+}
+
+// token returns a cached token, fetching a new one shortly before it expires
+func (m *managedIdentity) token(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.current != "" && time.Until(m.expires) > credentialRefreshWindow {
+		return m.current, nil
+	}
+
+	query := url.Values{"api-version": {"2018-02-01"}, "resource": {azureResource}}
+	if m.clientID != "" {
+		query.Set("client_id", m.clientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata", "true")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return "", &noRetryError{err: fmt.Errorf("no API key, and no managed identity is available: %w", err)}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("failed to get a managed identity token: %w", newHTTPError("GET /metadata/identity/oauth2/token", resp))
+	}
+
+	var output struct {
+		AccessToken string `json:"access_token"`
+		ExpiresOn   string `json:"expires_on"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&output); err != nil {
+		return "", fmt.Errorf("failed to parse managed identity token: %w", err)
+	}
+	expiresOn, err := strconv.ParseInt(output.ExpiresOn, 10, 64)
+	if err != nil || output.AccessToken == "" {
+		return "", fmt.Errorf("managed identity returned an invalid token")
+	}
+
+	m.current, m.expires = output.AccessToken, time.Unix(expiresOn, 0)
+	return m.current, nil
+}
@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func azureTestConfig(baseURL string) Config {
+	cfg := batchTestConfig(ProviderAzureOpenAI)
+	cfg.Model = "gpt-4o"
+	cfg.Deployment = "gpt-4o-prod"
+	cfg.BaseURL = baseURL + "/"
+	return cfg
+}
+
+func TestAzureClient_Chat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/openai/deployments/gpt-4o-prod/chat/completions", r.URL.Path)
+		assert.Equal(t, DefaultAzureAPIVersion, r.URL.Query().Get("api-version"))
+		assert.Equal(t, "test-key", r.Header.Get("api-key"))
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.EqualValues(t, 4096, body["max_completion_tokens"])
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"package main\n"}}]}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(azureTestConfig(server.URL))
+	require.NoError(t, err)
+	assert.Equal(t, "azure-openai", client.Provider())
+	assert.True(t, SupportsStructuredOutput(client))
+
+	response, err := client.Chat(context.Background(), []Message{
+		{Role: "system", Content: "context"},
+		{Role: "user", Content: "generate main.go"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", response)
+}
+
+func TestAzureClient_ManagedIdentity(t *testing.T) {
+	var tokenRequests int32
+	imds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokenRequests, 1)
+		assert.Equal(t, "true", r.Header.Get("Metadata"))
+		assert.Equal(t, azureResource, r.URL.Query().Get("resource"))
+		assert.Equal(t, "identity-id", r.URL.Query().Get("client_id"))
+		fmt.Fprintf(w, `{"access_token":"entra-token","expires_on":"%d"}`, time.Now().Add(time.Hour).Unix())
+	}))
+	defer imds.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer entra-token", r.Header.Get("Authorization"))
+		assert.Empty(t, r.Header.Get("api-key"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("AZURE_CLIENT_ID", "identity-id")
+	cfg := azureTestConfig(server.URL)
+	cfg.APIKey = ""
+	client, err := newAzureClient(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, client.tokens, "without a key the managed identity is used")
+	client.tokens.endpoint = imds.URL
+
+	for i := 0; i < 2; i++ {
+		response, err := client.Generate(context.Background(), "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "ok", response)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&tokenRequests), "the token is reused until it nears expiry")
+}
+
+func TestAzureClient_NoManagedIdentity(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	cfg := azureTestConfig(server.URL)
+	cfg.APIKey = ""
+	cfg.MaxRetries = 2
+	client, err := newAzureClient(cfg)
+	require.NoError(t, err)
+	client.tokens.endpoint = "http://127.0.0.1:1"
+
+	_, err = client.Generate(context.Background(), "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no managed identity is available")
+	assert.Zero(t, atomic.LoadInt32(&requests))
+}
+
+func TestAzureClient_GenerateStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/openai/deployments/gpt-4o-prod/chat/completions", r.URL.Path)
+		assert.Equal(t, "2025-01-01-preview", r.URL.Query().Get("api-version"))
+
+		var body openaiStreamInput
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.True(t, body.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"package \"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"main\\n\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	cfg := azureTestConfig(server.URL)
+	cfg.APIVersion = "2025-01-01-preview"
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+	streaming, ok := client.(StreamingClient)
+	require.True(t, ok)
+
+	var out bytes.Buffer
+	_, err = streaming.GenerateStream(context.Background(), streamMessages, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", out.String())
+}
@@ -0,0 +1,451 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	anthropicsdk "github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/dshills/gocreator/internal/logctx"
+)
+
+// DefaultBatchPollInterval is how often a batch job's status is checked when unset
+const DefaultBatchPollInterval = 30 * time.Second
+
+// BatchRequest is one prompt of a batch job
+type BatchRequest struct {
+	ID     string // Unique within the batch; results are matched by it
+	Prompt string
+}
+
+// BatchResult is the response to one BatchRequest
+type BatchResult struct {
+	ID   string
+	Text string
+	Err  error // Set when the request errored, expired or was canceled
+}
+
+// BatchClient extends Client with the provider's batch API (Anthropic and
+// OpenAI). Batch jobs run asynchronously, can take up to 24 hours and cost
+// about half as much as interactive requests, which suits large non-interactive
+// generations.
+type BatchClient interface {
+	Client
+
+	// GenerateBatch submits the prompts as one batch job, polls it until it
+	// ends and returns a result per request in request order. Requests that
+	// fail inside a finished job are reported in their result; the error is
+	// for the job as a whole. Cancelling ctx cancels the job.
+	GenerateBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error)
+}
+
+// batchJob is the provider-specific part of running a batch
+type batchJob interface {
+	// status reports whether the job has ended
+	status(ctx context.Context) (bool, error)
+	// results returns the text or error per request ID of an ended job
+	results(ctx context.Context) (map[string]BatchResult, error)
+	// cancel stops a job that is still running
+	cancel(ctx context.Context) error
+}
+
+// waitForBatch polls job until it ends and assembles the results in request
+// order. Requests without a result are reported as missing.
+func (b *baseClient) waitForBatch(ctx context.Context, id string, job batchJob, requests []BatchRequest) ([]BatchResult, error) {
+	interval := b.config.BatchPollInterval
+	if interval <= 0 {
+		interval = DefaultBatchPollInterval
+	}
+	started := time.Now()
+
+	for {
+		ended, err := job.status(ctx)
+		if err != nil && ctx.Err() == nil {
+			return nil, b.wrapError("batch", fmt.Errorf("failed to check batch %s: %w", id, err))
+		}
+		if ended {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			// Stop paying for a job nobody waits for
+			cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), b.config.Timeout)
+			if err := job.cancel(cancelCtx); err != nil {
+				logctx.Logger(ctx).Warn().Err(err).Str("batch_id", id).Msg("Failed to cancel batch job")
+			}
+			cancel()
+			return nil, fmt.Errorf("batch %s canceled: %w", id, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+
+	byID, err := job.results(ctx)
+	if err != nil {
+		return nil, b.wrapError("batch", fmt.Errorf("failed to read results of batch %s: %w", id, err))
+	}
+
+	results := make([]BatchResult, len(requests))
+	failed := 0
+	for i, req := range requests {
+		result, ok := byID[req.ID]
+		if !ok {
+			result = BatchResult{Err: fmt.Errorf("batch %s returned no result", id)}
+		}
+		result.ID = req.ID
+		if result.Err != nil {
+			failed++
+		}
+		results[i] = result
+	}
+
+	logctx.Logger(ctx).Info().
+		Str("provider", string(b.config.Provider)).
+		Str("batch_id", id).
+		Int("requests", len(requests)).
+		Int("failed", failed).
+		Dur("duration", time.Since(started)).
+		Msg("Batch job completed")
+
+	return results, nil
+}
+
+// GenerateBatch implements BatchClient with the Message Batches API
+func (c *anthropicClient) GenerateBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	params := anthropicsdk.MessageBatchNewParams{Requests: make([]anthropicsdk.MessageBatchNewParamsRequest, len(requests))}
+	for i, req := range requests {
+		params.Requests[i] = anthropicsdk.MessageBatchNewParamsRequest{
+			CustomID: req.ID,
+			Params: anthropicsdk.MessageBatchNewParamsRequestParams{
+				Model:       anthropicsdk.Model(c.config.Model),
+				MaxTokens:   int64(c.config.MaxTokens),
+				Temperature: anthropicsdk.Float(c.config.Temperature),
+				Messages:    []anthropicsdk.MessageParam{anthropicsdk.NewUserMessage(anthropicsdk.NewTextBlock(req.Prompt))},
+			},
+		}
+	}
+
+	var batch *anthropicsdk.MessageBatch
+	err := c.retry(ctx, "batch_submit", func() error {
+		var err error
+		batch, err = c.directClient.Messages.Batches.New(ctx, params)
+		return err
+	})
+	if err != nil {
+		return nil, c.wrapError("batch_submit", err)
+	}
+
+	logctx.Logger(ctx).Info().
+		Str("provider", string(c.config.Provider)).
+		Str("batch_id", batch.ID).
+		Int("requests", len(requests)).
+		Msg("Submitted batch job")
+
+	return c.waitForBatch(ctx, batch.ID, &anthropicBatchJob{client: c, id: batch.ID}, requests)
+}
+
+// anthropicBatchJob is a submitted Anthropic message batch
+type anthropicBatchJob struct {
+	client *anthropicClient
+	id     string
+}
+
+func (j *anthropicBatchJob) status(ctx context.Context) (bool, error) {
+	batch, err := j.client.directClient.Messages.Batches.Get(ctx, j.id)
+	if err != nil {
+		return false, err
+	}
+	return batch.ProcessingStatus == anthropicsdk.MessageBatchProcessingStatusEnded, nil
+}
+
+// results reads the JSONL results itself rather than through the SDK's
+// stream, whose line limit is smaller than a generated file
+func (j *anthropicBatchJob) results(ctx context.Context) (map[string]BatchResult, error) {
+	var resp *http.Response
+	err := j.client.directClient.Get(ctx, "v1/messages/batches/"+j.id+"/results", nil, &resp,
+		option.WithHeader("Accept", "application/x-jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	results := make(map[string]BatchResult)
+	err = scanJSONL(resp.Body, func(line []byte) error {
+		var entry anthropicsdk.MessageBatchIndividualResponse
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return err
+		}
+		result := BatchResult{ID: entry.CustomID}
+		switch entry.Result.Type {
+		case "succeeded":
+			for _, block := range entry.Result.Message.Content {
+				if block.Type == "text" {
+					result.Text += block.Text
+				}
+			}
+		case "errored":
+			result.Err = fmt.Errorf("request errored: %s", entry.Result.Error.Error.Message)
+		default:
+			result.Err = fmt.Errorf("request %s", entry.Result.Type)
+		}
+		results[entry.CustomID] = result
+		return nil
+	})
+	return results, err
+}
+
+func (j *anthropicBatchJob) cancel(ctx context.Context) error {
+	_, err := j.client.directClient.Messages.Batches.Cancel(ctx, j.id)
+	return err
+}
+
+// scanJSONL calls fn for each non-empty line of r. Lines hold whole
+// generated files, so they may be far longer than bufio's default limit.
+func scanJSONL(r io.Reader, fn func(line []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if err := fn(scanner.Bytes()); err != nil {
+			return fmt.Errorf("failed to parse batch results: %w", err)
+		}
+	}
+	return scanner.Err()
+}
+
+// openaiBatchRequest is one line of an OpenAI batch input file
+type openaiBatchRequest struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     openaiChatInput `json:"body"`
+}
+
+// openaiChatInput is the chat completion request of a batch line
+type openaiChatInput struct {
+	Model               string              `json:"model"`
+	Messages            []map[string]string `json:"messages"`
+	Temperature         float64             `json:"temperature"`
+	MaxCompletionTokens int                 `json:"max_completion_tokens,omitempty"`
+}
+
+// openaiBatch is the batch object of the OpenAI Batch API
+type openaiBatch struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	OutputFileID string `json:"output_file_id"`
+	ErrorFileID  string `json:"error_file_id"`
+}
+
+// openaiBatchOutput is one line of an OpenAI batch output or error file
+type openaiBatchOutput struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int `json:"status_code"`
+		Body       struct {
+			Choices []struct {
+				Message struct {
+					Content string `json:"content"`
+				} `json:"message"`
+			} `json:"choices"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// openaiBatchEnded are the terminal statuses of an OpenAI batch
+var openaiBatchEnded = map[string]bool{"completed": true, "failed": true, "expired": true, "cancelled": true}
+
+// GenerateBatch implements BatchClient with the Batch API: the requests are
+// uploaded as a JSONL file and run against /v1/chat/completions
+func (c *openaiClient) GenerateBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	for _, req := range requests {
+		line := openaiBatchRequest{
+			CustomID: req.ID,
+			Method:   http.MethodPost,
+			URL:      "/v1/chat/completions",
+			Body: openaiChatInput{
+				Model:               c.config.Model,
+				Messages:            []map[string]string{{"role": "user", "content": req.Prompt}},
+				Temperature:         c.config.Temperature,
+				MaxCompletionTokens: c.config.MaxTokens,
+			},
+		}
+		if err := encoder.Encode(line); err != nil {
+			return nil, c.wrapError("batch_submit", fmt.Errorf("failed to encode request %s: %w", req.ID, err))
+		}
+	}
+
+	var batch openaiBatch
+	err := c.retry(ctx, "batch_submit", func() error {
+		fileID, err := c.uploadBatchFile(ctx, input.Bytes())
+		if err != nil {
+			return err
+		}
+		body, _ := json.Marshal(map[string]string{
+			"input_file_id":     fileID,
+			"endpoint":          "/v1/chat/completions",
+			"completion_window": "24h",
+		})
+		return c.batchAPI(ctx, http.MethodPost, "/batches", "application/json", bytes.NewReader(body), &batch)
+	})
+	if err != nil {
+		return nil, c.wrapError("batch_submit", err)
+	}
+
+	logctx.Logger(ctx).Info().
+		Str("provider", string(c.config.Provider)).
+		Str("batch_id", batch.ID).
+		Int("requests", len(requests)).
+		Msg("Submitted batch job")
+
+	return c.waitForBatch(ctx, batch.ID, &openaiBatchJob{client: c, batch: batch}, requests)
+}
+
+// uploadBatchFile uploads a JSONL batch input file and returns its ID
+func (c *openaiClient) uploadBatchFile(ctx context.Context, content []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("purpose", "batch"); err != nil {
+		return "", err
+	}
+	part, err := form.CreateFormFile("file", "gocreator-batch.jsonl")
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(content); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	var file struct {
+		ID string `json:"id"`
+	}
+	if err := c.batchAPI(ctx, http.MethodPost, "/files", form.FormDataContentType(), &body, &file); err != nil {
+		return "", fmt.Errorf("failed to upload batch input: %w", err)
+	}
+	return file.ID, nil
+}
+
+// batchAPI sends a request to the OpenAI API and decodes the JSON response
+// into out, or copies it when out is an io.Writer
+func (c *openaiClient) batchAPI(ctx context.Context, method, path, contentType string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return newHTTPError(method+" "+path, resp)
+	}
+	if w, ok := out.(io.Writer); ok {
+		_, err = io.Copy(w, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// openaiBatchJob is a submitted OpenAI batch
+type openaiBatchJob struct {
+	client *openaiClient
+	batch  openaiBatch
+}
+
+func (j *openaiBatchJob) status(ctx context.Context) (bool, error) {
+	if err := j.client.batchAPI(ctx, http.MethodGet, "/batches/"+j.batch.ID, "", nil, &j.batch); err != nil {
+		return false, err
+	}
+	return openaiBatchEnded[j.batch.Status], nil
+}
+
+func (j *openaiBatchJob) results(ctx context.Context) (map[string]BatchResult, error) {
+	results := make(map[string]BatchResult)
+	for _, fileID := range []string{j.batch.OutputFileID, j.batch.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+		var content bytes.Buffer
+		if err := j.client.batchAPI(ctx, http.MethodGet, "/files/"+fileID+"/content", "", nil, &content); err != nil {
+			return nil, err
+		}
+
+		err := scanJSONL(&content, func(line []byte) error {
+			var output openaiBatchOutput
+			if err := json.Unmarshal(line, &output); err != nil {
+				return err
+			}
+			results[output.CustomID] = output.result()
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(results) == 0 && j.batch.Status != "completed" {
+		return nil, fmt.Errorf("batch %s", j.batch.Status)
+	}
+	return results, nil
+}
+
+func (j *openaiBatchJob) cancel(ctx context.Context) error {
+	return j.client.batchAPI(ctx, http.MethodPost, "/batches/"+j.batch.ID+"/cancel", "", nil, &j.batch)
+}
+
+// result converts an output line to a BatchResult
+func (o openaiBatchOutput) result() BatchResult {
+	result := BatchResult{ID: o.CustomID}
+	switch {
+	case o.Error != nil:
+		result.Err = fmt.Errorf("request errored: %s", o.Error.Message)
+	case o.Response == nil:
+		result.Err = fmt.Errorf("request has no response")
+	case o.Response.StatusCode >= 300:
+		message := fmt.Sprintf("HTTP %d", o.Response.StatusCode)
+		if o.Response.Body.Error != nil {
+			message += ": " + o.Response.Body.Error.Message
+		}
+		result.Err = fmt.Errorf("request errored: %s", message)
+	case len(o.Response.Body.Choices) == 0:
+		result.Err = fmt.Errorf("request returned no choices")
+	default:
+		result.Text = o.Response.Body.Choices[0].Message.Content
+	}
+	return result
+}
@@ -0,0 +1,200 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	anthropicsdk "github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func batchTestConfig(provider Provider) Config {
+	cfg := DefaultConfig()
+	cfg.Provider = provider
+	cfg.APIKey = "test-key"
+	cfg.MaxRetries = 0
+	cfg.BatchPollInterval = time.Millisecond
+	return cfg
+}
+
+func TestAnthropicClient_GenerateBatch(t *testing.T) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/messages/batches":
+			var body struct {
+				Requests []struct {
+					CustomID string `json:"custom_id"`
+				} `json:"requests"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Len(t, body.Requests, 2)
+			fmt.Fprint(w, `{"id":"msgbatch_1","type":"message_batch","processing_status":"in_progress"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/messages/batches/msgbatch_1":
+			status := "in_progress"
+			if atomic.AddInt32(&polls, 1) > 1 {
+				status = "ended"
+			}
+			fmt.Fprintf(w, `{"id":"msgbatch_1","type":"message_batch","processing_status":%q}`, status)
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/messages/batches/msgbatch_1/results":
+			// Results arrive in any order
+			fmt.Fprintln(w, `{"custom_id":"b","result":{"type":"errored","error":{"type":"error","error":{"type":"overloaded_error","message":"overloaded"}}}}`)
+			fmt.Fprintln(w, `{"custom_id":"a","result":{"type":"succeeded","message":{"id":"m","type":"message","role":"assistant","model":"claude","content":[{"type":"text","text":"package a"}]}}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &anthropicClient{
+		baseClient:   baseClient{config: batchTestConfig(ProviderAnthropic)},
+		directClient: anthropicsdk.NewClient(option.WithAPIKey("test-key"), option.WithBaseURL(server.URL), option.WithMaxRetries(0)),
+	}
+
+	results, err := client.GenerateBatch(context.Background(), []BatchRequest{{ID: "a", Prompt: "one"}, {ID: "b", Prompt: "two"}})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, BatchResult{ID: "a", Text: "package a"}, results[0])
+	assert.Equal(t, "b", results[1].ID)
+	assert.ErrorContains(t, results[1].Err, "overloaded")
+	assert.EqualValues(t, 2, atomic.LoadInt32(&polls))
+}
+
+func TestAnthropicClient_GenerateBatchCancel(t *testing.T) {
+	canceled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1/messages/batches/msgbatch_1/cancel":
+			close(canceled)
+			fmt.Fprint(w, `{"id":"msgbatch_1","type":"message_batch","processing_status":"canceling"}`)
+		default:
+			fmt.Fprint(w, `{"id":"msgbatch_1","type":"message_batch","processing_status":"in_progress"}`)
+		}
+	}))
+	defer server.Close()
+
+	cfg := batchTestConfig(ProviderAnthropic)
+	cfg.BatchPollInterval = time.Hour
+	client := &anthropicClient{
+		baseClient:   baseClient{config: cfg},
+		directClient: anthropicsdk.NewClient(option.WithAPIKey("test-key"), option.WithBaseURL(server.URL), option.WithMaxRetries(0)),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	_, err := client.GenerateBatch(ctx, []BatchRequest{{ID: "a", Prompt: "one"}})
+	require.ErrorIs(t, err, context.Canceled)
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("batch job was not canceled")
+	}
+}
+
+func TestOpenAIClient_GenerateBatch(t *testing.T) {
+	var input []openaiBatchRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/files":
+			assert.Equal(t, "batch", r.FormValue("purpose"))
+			file, _, err := r.FormFile("file")
+			if !assert.NoError(t, err) {
+				return
+			}
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				var line openaiBatchRequest
+				assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+				input = append(input, line)
+			}
+			fmt.Fprint(w, `{"id":"file-in"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/batches":
+			body, _ := io.ReadAll(r.Body)
+			assert.JSONEq(t, `{"input_file_id":"file-in","endpoint":"/v1/chat/completions","completion_window":"24h"}`, string(body))
+			fmt.Fprint(w, `{"id":"batch_1","status":"validating"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/batches/batch_1":
+			fmt.Fprint(w, `{"id":"batch_1","status":"completed","output_file_id":"file-out","error_file_id":"file-err"}`)
+		case r.URL.Path == "/files/file-out/content":
+			fmt.Fprintln(w, `{"custom_id":"a","response":{"status_code":200,"body":{"choices":[{"message":{"content":"package a"}}]}}}`)
+		case r.URL.Path == "/files/file-err/content":
+			fmt.Fprintln(w, `{"custom_id":"b","response":{"status_code":400,"body":{"error":{"message":"context too long"}}}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := batchTestConfig(ProviderOpenAI)
+	cfg.Model = "gpt-4o"
+	client := &openaiClient{
+		baseClient: baseClient{config: cfg},
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+	}
+
+	results, err := client.GenerateBatch(context.Background(), []BatchRequest{
+		{ID: "a", Prompt: "one"}, {ID: "b", Prompt: "two"}, {ID: "c", Prompt: "three"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, input, 3)
+	assert.Equal(t, "a", input[0].CustomID)
+	assert.Equal(t, "/v1/chat/completions", input[0].URL)
+	assert.Equal(t, "gpt-4o", input[0].Body.Model)
+	assert.Equal(t, "one", input[0].Body.Messages[0]["content"])
+
+	require.Len(t, results, 3)
+	assert.Equal(t, BatchResult{ID: "a", Text: "package a"}, results[0])
+	assert.ErrorContains(t, results[1].Err, "context too long")
+	assert.ErrorContains(t, results[2].Err, "no result")
+}
+
+func TestOpenAIClient_GenerateBatchSubmitError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"bad key"}}`)
+	}))
+	defer server.Close()
+
+	client := &openaiClient{
+		baseClient: baseClient{config: batchTestConfig(ProviderOpenAI)},
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+	}
+
+	_, err := client.GenerateBatch(context.Background(), []BatchRequest{{ID: "a", Prompt: "one"}})
+	assert.ErrorContains(t, err, "HTTP 401")
+}
+
+func TestQuotaLimiter_WrapPreservesBatch(t *testing.T) {
+	limiter := NewQuotaLimiter([]QuotaClass{{Provider: ProviderOpenAI, MaxParallel: 1}, {Provider: ProviderAnthropic, MaxParallel: 1}})
+
+	openai := limiter.Wrap(&openaiClient{baseClient: baseClient{config: batchTestConfig(ProviderOpenAI)}})
+	_, ok := openai.(BatchClient)
+	assert.True(t, ok)
+
+	anthropic := limiter.Wrap(&anthropicClient{baseClient: baseClient{config: batchTestConfig(ProviderAnthropic)}})
+	_, ok = anthropic.(BatchClient)
+	assert.True(t, ok)
+	_, ok = anthropic.(CacheableClient)
+	assert.True(t, ok)
+}
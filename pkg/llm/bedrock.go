@@ -0,0 +1,297 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// bedrockClient implements the Client interface for AWS Bedrock through the
+// Converse API, which serves Claude and the other Bedrock models alike
+type bedrockClient struct {
+	baseClient
+	httpClient  *http.Client
+	url         *url.URL // Converse URL of the model
+	region      string
+	credentials *awsCredentialChain // Signing credentials when there is no API key
+}
+
+// BedrockRegion returns region, or when it is empty the AWS_REGION or
+// AWS_DEFAULT_REGION environment variable
+func BedrockRegion(region string) string {
+	if region != "" {
+		return region
+	}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
+
+// BedrockEndpoint returns the Bedrock runtime endpoint of config: its base
+// URL, or the endpoint of its region
+func BedrockEndpoint(config Config) (string, error) {
+	if config.BaseURL != "" {
+		return strings.TrimSuffix(config.BaseURL, "/"), nil
+	}
+	region := BedrockRegion(config.Region)
+	if region == "" {
+		return "", fmt.Errorf("no AWS region: set the region or AWS_REGION")
+	}
+	return "https://bedrock-runtime." + region + ".amazonaws.com", nil
+}
+
+// newBedrockClient creates a client for config.Model, a Bedrock model or
+// inference profile ID such as "anthropic.claude-sonnet-4-5-20250929-v1:0".
+// It authenticates with config.APIKey, a Bedrock API key, or without one
+// signs requests with the AWS credentials of the environment or instance.
+func newBedrockClient(config Config) (*bedrockClient, error) {
+	endpoint, err := BedrockEndpoint(config)
+	if err != nil {
+		return nil, err
+	}
+	region := BedrockRegion(config.Region)
+	if region == "" && config.APIKey == "" {
+		return nil, fmt.Errorf("no AWS region to sign requests for: set the region or AWS_REGION")
+	}
+
+	httpClient, err := NewHTTPClient(config.Network, config.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	// The model ID is escaped in full, as its colon must be for signing
+	converseURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	converseURL.RawPath = converseURL.EscapedPath() + "/model/" + awsURIEscape(config.Model) + "/converse"
+	converseURL.Path += "/model/" + config.Model + "/converse"
+
+	client := &bedrockClient{
+		baseClient: baseClient{config: config},
+		httpClient: httpClient,
+		url:        converseURL,
+		region:     region,
+	}
+	if config.APIKey == "" {
+		client.credentials = newAWSCredentialChain()
+	}
+	return client, nil
+}
+
+// bedrockContent is a content block of a Converse message
+type bedrockContent struct {
+	Text       string             `json:"text,omitempty"`
+	ToolUse    *bedrockToolUse    `json:"toolUse,omitempty"`
+	ToolResult *bedrockToolResult `json:"toolResult,omitempty"`
+}
+
+// bedrockToolUse is the model's call of a tool
+type bedrockToolUse struct {
+	ToolUseID string          `json:"toolUseId"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+}
+
+// bedrockToolResult answers a tool call
+type bedrockToolResult struct {
+	ToolUseID string           `json:"toolUseId"`
+	Content   []bedrockContent `json:"content"`
+	Status    string           `json:"status,omitempty"`
+}
+
+// bedrockMessage is a Converse message
+type bedrockMessage struct {
+	Role    string           `json:"role"`
+	Content []bedrockContent `json:"content"`
+}
+
+// bedrockInput is a Converse request
+type bedrockInput struct {
+	Messages        []bedrockMessage `json:"messages"`
+	System          []bedrockContent `json:"system,omitempty"`
+	InferenceConfig struct {
+		MaxTokens   int     `json:"maxTokens,omitempty"`
+		Temperature float64 `json:"temperature"`
+	} `json:"inferenceConfig"`
+	ToolConfig interface{} `json:"toolConfig,omitempty"`
+}
+
+// bedrockOutput is a Converse response
+type bedrockOutput struct {
+	Output struct {
+		Message bedrockMessage `json:"message"`
+	} `json:"output"`
+	StopReason string `json:"stopReason"`
+}
+
+// Generate produces text from a single prompt
+func (c *bedrockClient) Generate(ctx context.Context, prompt string) (string, error) {
+	input := c.newInput([]bedrockMessage{{Role: "user", Content: []bedrockContent{{Text: prompt}}}})
+	output, err := c.converse(ctx, "generate", input)
+	if err != nil {
+		return "", c.wrapError("generate", err)
+	}
+	return output.text(), nil
+}
+
+// GenerateStructured produces structured output based on a schema. The model
+// returns it through a forced call of the respond tool, whose input schema
+// is schema; a response that does not match is answered with the violations
+// as the tool's result until one does.
+func (c *bedrockClient) GenerateStructured(ctx context.Context, prompt string, schema interface{}) (interface{}, error) {
+	inputSchema, err := toolSchema(schema)
+	if err != nil {
+		return nil, c.wrapError("generate_structured", err)
+	}
+
+	input := c.newInput([]bedrockMessage{{Role: "user", Content: []bedrockContent{{Text: prompt}}}})
+	input.ToolConfig = map[string]interface{}{
+		"tools": []interface{}{map[string]interface{}{
+			"toolSpec": map[string]interface{}{
+				"name":        StructuredOutputTool,
+				"description": structuredOutputDescription,
+				"inputSchema": map[string]interface{}{"json": inputSchema},
+			},
+		}},
+		"toolChoice": map[string]interface{}{"tool": map[string]string{"name": StructuredOutputTool}},
+	}
+
+	var call *bedrockToolUse
+	output, err := generateValidated(schema, func(problems string) ([]byte, error) {
+		if problems != "" {
+			input.Messages = append(input.Messages, bedrockMessage{Role: "user", Content: []bedrockContent{{
+				ToolResult: &bedrockToolResult{ToolUseID: call.ToolUseID, Content: []bedrockContent{{Text: problems}}, Status: "error"},
+			}}})
+		}
+
+		response, err := c.converse(ctx, "generate_structured", input)
+		if err != nil {
+			return nil, err
+		}
+		if response.StopReason == "max_tokens" {
+			return nil, truncatedCall("max_tokens", c.config.MaxTokens, "output")
+		}
+		call = nil
+		for _, block := range response.Output.Message.Content {
+			if block.ToolUse != nil && block.ToolUse.Name == StructuredOutputTool {
+				call = block.ToolUse
+				break
+			}
+		}
+		if call == nil {
+			return nil, fmt.Errorf("response did not use %s", StructuredOutputTool)
+		}
+
+		input.Messages = append(input.Messages, bedrockMessage{Role: "assistant", Content: []bedrockContent{{ToolUse: call}}})
+		return call.Input, nil
+	})
+	if err != nil {
+		return nil, c.wrapError("generate_structured", err)
+	}
+	return output, nil
+}
+
+// StructuredOutput implements StructuredOutputClient
+func (c *bedrockClient) StructuredOutput() bool {
+	return true
+}
+
+// Chat processes a sequence of messages and returns the assistant's response.
+// System messages become the Converse system prompt.
+func (c *bedrockClient) Chat(ctx context.Context, messages []Message) (string, error) {
+	if len(messages) == 0 {
+		return "", c.wrapError("chat", fmt.Errorf("messages cannot be empty"))
+	}
+
+	var system []bedrockContent
+	var chat []bedrockMessage
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			system = append(system, bedrockContent{Text: msg.Content})
+		case "user", "assistant":
+			chat = append(chat, bedrockMessage{Role: msg.Role, Content: []bedrockContent{{Text: msg.Content}}})
+		default:
+			return "", c.wrapError("chat", fmt.Errorf("invalid message role: %s", msg.Role))
+		}
+	}
+
+	input := c.newInput(chat)
+	input.System = system
+	output, err := c.converse(ctx, "chat", input)
+	if err != nil {
+		return "", c.wrapError("chat", err)
+	}
+	return output.text(), nil
+}
+
+// newInput returns a Converse request for messages with the configured
+// inference parameters
+func (c *bedrockClient) newInput(messages []bedrockMessage) bedrockInput {
+	input := bedrockInput{Messages: messages}
+	input.InferenceConfig.MaxTokens = c.config.MaxTokens
+	input.InferenceConfig.Temperature = c.config.Temperature
+	return input
+}
+
+// converse sends a Converse request with retry logic
+func (c *bedrockClient) converse(ctx context.Context, operation string, input bedrockInput) (*bedrockOutput, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var output bedrockOutput
+	err = c.retry(ctx, operation, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url.String(), bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if c.credentials == nil {
+			req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+		} else {
+			creds, err := c.credentials.credentials(ctx)
+			if err != nil {
+				return err
+			}
+			signV4(req, body, creds, c.region, "bedrock", time.Now())
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode >= 300 {
+			return newHTTPError("POST /model/{modelId}/converse", resp)
+		}
+
+		output = bedrockOutput{}
+		if err := json.NewDecoder(resp.Body).Decode(&output); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// text returns the text blocks of the response's message
+func (o *bedrockOutput) text() string {
+	var sb strings.Builder
+	for _, block := range o.Output.Message.Content {
+		sb.WriteString(block.Text)
+	}
+	return sb.String()
+}
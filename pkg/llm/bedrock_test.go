@@ -0,0 +1,231 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bedrockTestModel = "anthropic.claude-sonnet-4-5-20250929-v1:0"
+
+func bedrockTestConfig(baseURL string) Config {
+	cfg := batchTestConfig(ProviderBedrock)
+	cfg.Model = bedrockTestModel
+	cfg.APIKey = ""
+	cfg.Region = "us-east-1"
+	cfg.BaseURL = baseURL
+	return cfg
+}
+
+// TestSignV4 checks the signature against the get-vanilla case of the AWS
+// Signature Version 4 test suite
+func TestSignV4(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
+
+func TestBedrockClient_Chat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/model/anthropic.claude-sonnet-4-5-20250929-v1%3A0/converse", r.URL.EscapedPath())
+		auth := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDTEST/"), auth)
+		assert.Contains(t, auth, "/us-east-1/bedrock/aws4_request")
+		assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token")
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+
+		var body bedrockInput
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, []bedrockContent{{Text: "context"}}, body.System)
+		if assert.Len(t, body.Messages, 1) {
+			assert.Equal(t, "user", body.Messages[0].Role)
+		}
+		assert.Equal(t, 4096, body.InferenceConfig.MaxTokens)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"output":{"message":{"role":"assistant","content":[{"text":"package main\n"}]}},
+			"stopReason":"end_turn","usage":{"inputTokens":10,"outputTokens":3}}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+	client, err := NewClient(bedrockTestConfig(server.URL))
+	require.NoError(t, err)
+	assert.Equal(t, "bedrock", client.Provider())
+
+	response, err := client.Chat(context.Background(), []Message{
+		{Role: "system", Content: "context"},
+		{Role: "user", Content: "generate main.go"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", response)
+}
+
+func TestBedrockClient_APIKey(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		assert.Equal(t, "Bearer bedrock-key", r.Header.Get("Authorization"))
+		assert.Empty(t, r.Header.Get("X-Amz-Date"), "requests with an API key are not signed")
+		http.Error(w, `{"message":"The provided model identifier is invalid."}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	cfg := bedrockTestConfig(server.URL)
+	cfg.APIKey = "bedrock-key"
+	cfg.MaxRetries = 2
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+
+	_, err = client.Generate(context.Background(), "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model identifier is invalid")
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests), "a client error is not retried")
+}
+
+func TestBedrockClient_GenerateStructured(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages   []bedrockMessage `json:"messages"`
+			ToolConfig struct {
+				Tools []struct {
+					ToolSpec struct {
+						Name        string `json:"name"`
+						InputSchema struct {
+							JSON map[string]interface{} `json:"json"`
+						} `json:"inputSchema"`
+					} `json:"toolSpec"`
+				} `json:"tools"`
+				ToolChoice struct {
+					Tool struct {
+						Name string `json:"name"`
+					} `json:"tool"`
+				} `json:"toolChoice"`
+			} `json:"toolConfig"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if assert.Len(t, body.ToolConfig.Tools, 1) {
+			spec := body.ToolConfig.Tools[0].ToolSpec
+			assert.Equal(t, StructuredOutputTool, spec.Name)
+			assert.Equal(t, "object", spec.InputSchema.JSON["type"])
+			assert.NotContains(t, spec.InputSchema.JSON, "$schema")
+		}
+		assert.Equal(t, StructuredOutputTool, body.ToolConfig.ToolChoice.Tool.Name)
+
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&requests, 1) == 1 {
+			assert.Len(t, body.Messages, 1)
+			fmt.Fprint(w, `{"output":{"message":{"role":"assistant","content":[
+				{"toolUse":{"toolUseId":"t1","name":"respond","input":{"title":"gocreator"}}}]}},"stopReason":"tool_use"}`)
+			return
+		}
+
+		// The violations are the result of the first call
+		if assert.Len(t, body.Messages, 3) {
+			assert.NotNil(t, body.Messages[1].Content[0].ToolUse)
+			if result := body.Messages[2].Content[0].ToolResult; assert.NotNil(t, result) {
+				assert.Equal(t, "t1", result.ToolUseID)
+				assert.Equal(t, "error", result.Status)
+				assert.Contains(t, result.Content[0].Text, `missing required property "name"`)
+			}
+		}
+		fmt.Fprint(w, `{"output":{"message":{"role":"assistant","content":[
+			{"toolUse":{"toolUseId":"t2","name":"respond","input":{"name":"gocreator"}}}]}},"stopReason":"tool_use"}`)
+	}))
+	defer server.Close()
+
+	cfg := bedrockTestConfig(server.URL)
+	cfg.APIKey = "bedrock-key"
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+	assert.True(t, SupportsStructuredOutput(client))
+
+	output, err := client.GenerateStructured(context.Background(), "name the tool", newNameSchema())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "gocreator"}, output)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+}
+
+func TestBedrockClient_NoRegion(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	cfg := bedrockTestConfig("")
+	cfg.Region = ""
+	_, err := NewClient(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no AWS region")
+
+	t.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+	endpoint, err := BedrockEndpoint(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "https://bedrock-runtime.eu-west-1.amazonaws.com", endpoint)
+}
+
+func TestAWSCredentialChain_InstanceMetadata(t *testing.T) {
+	var tokenRequests int32
+	imds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+			atomic.AddInt32(&tokenRequests, 1)
+			assert.Equal(t, "21600", r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds"))
+			fmt.Fprint(w, "imds-token")
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/":
+			assert.Equal(t, "imds-token", r.Header.Get("X-aws-ec2-metadata-token"))
+			fmt.Fprint(w, "gocreator-role\n")
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/gocreator-role":
+			assert.Equal(t, "imds-token", r.Header.Get("X-aws-ec2-metadata-token"))
+			fmt.Fprintf(w, `{"Code":"Success","AccessKeyId":"ASIAROLE","SecretAccessKey":"role-secret","Token":"role-token","Expiration":%q}`,
+				time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer imds.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "")
+	chain := newAWSCredentialChain()
+	chain.imdsEndpoint = imds.URL
+
+	for i := 0; i < 2; i++ {
+		creds, err := chain.credentials(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "ASIAROLE", creds.AccessKeyID)
+		assert.Equal(t, "role-secret", creds.SecretAccessKey)
+		assert.Equal(t, "role-token", creds.SessionToken)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&tokenRequests), "credentials are reused until they near expiry")
+
+	// The environment takes precedence over the instance
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	creds, err := chain.credentials(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKIDENV", creds.AccessKeyID)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	_, err = newAWSCredentialChain().credentials(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AWS_EC2_METADATA_DISABLED")
+}
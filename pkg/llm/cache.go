@@ -6,6 +6,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,7 +21,8 @@ type CacheEntry struct {
 	HitCount  int
 }
 
-// Cache provides in-memory caching of LLM responses for development
+// Cache stores LLM responses by key: in memory with NewCache, or on disk with
+// the cache package
 type Cache interface {
 	// Get retrieves a cached response if available
 	Get(key string) (string, bool)
@@ -170,12 +173,28 @@ type CachedClient struct {
 	cache  Cache
 }
 
-// NewCachedClient creates a new cached LLM client
+// NewCachedClient creates a new cached LLM client. Prompt caching and batch
+// support of the client are kept, and streamed and tool use responses are
+// cached as well. Only successful responses are stored.
 func NewCachedClient(client Client, cache Cache) Client {
-	return &CachedClient{
+	cached := &CachedClient{
 		client: client,
 		cache:  cache,
 	}
+	cacheable, isCacheable := client.(CacheableClient)
+	batch, isBatch := client.(BatchClient)
+	switch {
+	case isCacheable && isBatch:
+		return &cachedCacheableBatchClient{
+			cachedCacheableClient: &cachedCacheableClient{CachedClient: cached, cacheable: cacheable},
+			batch:                 batch,
+		}
+	case isCacheable:
+		return &cachedCacheableClient{CachedClient: cached, cacheable: cacheable}
+	case isBatch:
+		return &cachedBatchClient{CachedClient: cached, batch: batch}
+	}
+	return cached
 }
 
 // Generate produces text from a single prompt (with caching)
@@ -229,6 +248,12 @@ func (c *CachedClient) GenerateStructured(ctx context.Context, prompt string, sc
 	return response, nil
 }
 
+// StructuredOutput reports whether the wrapped client validates structured
+// output
+func (c *CachedClient) StructuredOutput() bool {
+	return SupportsStructuredOutput(c.client)
+}
+
 // Chat processes a sequence of messages and returns the assistant's response (with caching)
 func (c *CachedClient) Chat(ctx context.Context, messages []Message) (string, error) {
 	// Generate cache key from messages
@@ -251,6 +276,50 @@ func (c *CachedClient) Chat(ctx context.Context, messages []Message) (string, er
 	return response, nil
 }
 
+// GenerateStream writes the response to messages to w, from the cache when
+// it holds one and streamed from the client otherwise
+func (c *CachedClient) GenerateStream(ctx context.Context, messages []CacheableMessage, w io.Writer) (int64, error) {
+	key := c.messagesCacheKey("messages", messages)
+	if cached, found := c.cache.Get(key); found {
+		n, err := io.WriteString(w, cached)
+		return int64(n), err
+	}
+
+	var response strings.Builder
+	n, err := streamResponse(ctx, c.client, messages, io.MultiWriter(w, &response))
+	if err != nil {
+		return n, err
+	}
+	c.cache.Set(key, response.String())
+	return n, nil
+}
+
+// EmitFiles has the response's files written through tool calls (with
+// caching)
+func (c *CachedClient) EmitFiles(ctx context.Context, messages []CacheableMessage) ([]EmittedFile, error) {
+	emitter, ok := c.client.(FileEmittingClient)
+	if !ok {
+		return nil, ErrToolUseUnsupported
+	}
+
+	key := c.messagesCacheKey("emit_files", messages)
+	if cached, found := c.cache.Get(key); found {
+		var files []EmittedFile
+		if err := json.Unmarshal([]byte(cached), &files); err == nil {
+			return files, nil
+		}
+	}
+
+	files, err := emitter.EmitFiles(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	if serialized, err := json.Marshal(files); err == nil {
+		c.cache.Set(key, string(serialized))
+	}
+	return files, nil
+}
+
 // Provider returns the name of the LLM provider
 func (c *CachedClient) Provider() string {
 	return c.client.Provider()
@@ -299,3 +368,110 @@ func (c *CachedClient) generateChatCacheKey(messages []Message) string {
 	hash := sha256.Sum256([]byte(keyData))
 	return hex.EncodeToString(hash[:])
 }
+
+// messagesCacheKey creates a cache key for the response of an operation on
+// cacheable messages. Cache control only changes what the request costs, so
+// it is not part of the key.
+func (c *CachedClient) messagesCacheKey(operation string, messages []CacheableMessage) string {
+	chat := make([]Message, len(messages))
+	for i, msg := range messages {
+		chat[i] = Message{Role: msg.Role, Content: msg.Content}
+	}
+	messagesJSON, _ := json.Marshal(chat)
+
+	keyData := fmt.Sprintf("%s:%s:%s:%s", c.client.Provider(), c.client.Model(), operation, string(messagesJSON))
+	hash := sha256.Sum256([]byte(keyData))
+	return hex.EncodeToString(hash[:])
+}
+
+// cachedCacheableClient is a CachedClient whose client supports prompt
+// caching
+type cachedCacheableClient struct {
+	*CachedClient
+	cacheable CacheableClient
+}
+
+// GenerateWithCache generates text with cacheable messages (with caching)
+func (c *cachedCacheableClient) GenerateWithCache(ctx context.Context, messages []CacheableMessage) (string, error) {
+	key := c.messagesCacheKey("messages", messages)
+	if cached, found := c.cache.Get(key); found {
+		return cached, nil
+	}
+
+	response, err := c.cacheable.GenerateWithCache(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+	c.cache.Set(key, response)
+	return response, nil
+}
+
+// GetCacheMetrics returns the wrapped client's prompt cache metrics
+func (c *cachedCacheableClient) GetCacheMetrics() PromptCacheMetrics {
+	return c.cacheable.GetCacheMetrics()
+}
+
+// ResetCacheMetrics resets the wrapped client's prompt cache metrics
+func (c *cachedCacheableClient) ResetCacheMetrics() {
+	c.cacheable.ResetCacheMetrics()
+}
+
+// cachedBatchClient is a CachedClient whose client supports batch jobs
+type cachedBatchClient struct {
+	*CachedClient
+	batch BatchClient
+}
+
+// GenerateBatch answers the requests the cache holds and submits the rest
+func (c *cachedBatchClient) GenerateBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error) {
+	return c.generateBatch(ctx, c.batch, requests)
+}
+
+// cachedCacheableBatchClient is a cachedCacheableClient whose client also
+// supports batch jobs
+type cachedCacheableBatchClient struct {
+	*cachedCacheableClient
+	batch BatchClient
+}
+
+// GenerateBatch answers the requests the cache holds and submits the rest
+func (c *cachedCacheableBatchClient) GenerateBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error) {
+	return c.generateBatch(ctx, c.batch, requests)
+}
+
+// generateBatch submits the requests the cache does not hold as one batch
+// job. A request is keyed like Generate with its prompt. The results of the
+// job are put back in request order among the cached ones by request ID.
+func (c *CachedClient) generateBatch(ctx context.Context, batch BatchClient, requests []BatchRequest) ([]BatchResult, error) {
+	results := make([]BatchResult, len(requests))
+	keys := make(map[string]string, len(requests))
+	slots := make(map[string]int, len(requests))
+	var pending []BatchRequest
+	for i, req := range requests {
+		key := c.generateCacheKey(req.Prompt, nil)
+		if cached, found := c.cache.Get(key); found {
+			results[i] = BatchResult{ID: req.ID, Text: cached}
+			continue
+		}
+		keys[req.ID] = key
+		slots[req.ID] = i
+		results[i] = BatchResult{ID: req.ID, Err: fmt.Errorf("batch returned no result for request %s", req.ID)}
+		pending = append(pending, req)
+	}
+	if len(pending) == 0 {
+		return results, nil
+	}
+
+	submitted, err := batch.GenerateBatch(ctx, pending)
+	for _, result := range submitted {
+		i, ok := slots[result.ID]
+		if !ok {
+			continue
+		}
+		results[i] = result
+		if result.Err == nil {
+			c.cache.Set(keys[result.ID], result.Text)
+		}
+	}
+	return results, err
+}
@@ -0,0 +1,314 @@
+// Package cache stores LLM responses on disk, so a rerun that sends the same
+// prompt to the same model gets the earlier response without a request.
+// Generation runs at temperature 0, which makes a stored response as good as
+// a new one.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/rs/zerolog/log"
+)
+
+// Config configures a disk cache
+type Config struct {
+	// Dir holds the cache entries; DefaultDir() when empty
+	Dir string
+
+	// TTL is how long an entry is served after it was stored; 0 keeps
+	// entries until they are evicted
+	TTL time.Duration
+
+	// MaxSize caps the bytes the entries take on disk; the least recently
+	// used entries are evicted past it. 0 for no limit
+	MaxSize int64
+}
+
+// DefaultDir returns the cache directory in the user's cache directory,
+// shared by the projects of that user
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "gocreator", "responses"), nil
+}
+
+// Disk is an llm.Cache keeping one file per response. Entries are written
+// atomically, so several runs can share the directory. A file that cannot
+// be read or written is a miss rather than an error: the request is then
+// sent to the provider.
+type Disk struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+
+	mu      sync.Mutex
+	size    int64 // Bytes taken by the entries
+	entries int
+	hits    int64
+	misses  int64
+}
+
+// entry is a cached response on disk
+type entry struct {
+	Response string    `json:"response"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// New opens the disk cache at cfg.Dir, creating the directory when needed
+func New(cfg Config) (*Disk, error) {
+	dir := cfg.Dir
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.TTL < 0 {
+		return nil, fmt.Errorf("cache TTL cannot be negative, got: %v", cfg.TTL)
+	}
+	if cfg.MaxSize < 0 {
+		return nil, fmt.Errorf("cache max size cannot be negative, got: %d", cfg.MaxSize)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create response cache directory: %w", err)
+	}
+
+	d := &Disk{dir: dir, ttl: cfg.TTL, maxSize: cfg.MaxSize}
+	files, err := d.files()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		d.size += f.size
+	}
+	d.entries = len(files)
+	return d, nil
+}
+
+// Dir returns the directory holding the entries
+func (d *Disk) Dir() string {
+	return d.dir
+}
+
+// path returns the file of the entry for key
+func (d *Disk) path(key string) string {
+	// Hash the key so any key is a safe file name
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(d.dir, name[:2], name+".json")
+}
+
+// Get returns the stored response for key, unless it expired
+func (d *Disk) Get(key string) (string, bool) {
+	path := d.path(key)
+	//nolint:gosec // G304: Reading an entry of the response cache
+	data, err := os.ReadFile(path)
+	var e entry
+	if err == nil {
+		err = json.Unmarshal(data, &e)
+	}
+	switch {
+	case err != nil:
+		if !os.IsNotExist(err) {
+			log.Debug().Err(err).Str("file", path).Msg("Unreadable LLM cache entry")
+		}
+		d.miss()
+		return "", false
+	case d.ttl > 0 && time.Since(e.StoredAt) > d.ttl:
+		d.remove(path)
+		d.miss()
+		return "", false
+	}
+
+	// The modification time orders entries for eviction
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	d.mu.Lock()
+	d.hits++
+	d.mu.Unlock()
+	log.Debug().Str("file", path).Msg("LLM cache hit")
+	return e.Response, true
+}
+
+// Set stores the response for key, evicting the least recently used
+// entries when the cache grows past its size limit
+func (d *Disk) Set(key string, response string) {
+	path := d.path(key)
+	data, err := json.Marshal(entry{Response: response, StoredAt: time.Now().UTC()})
+	if err != nil {
+		return
+	}
+	previous, statErr := os.Stat(path)
+	if err := writeAtomic(path, data); err != nil {
+		log.Warn().Err(err).Str("file", path).Msg("Failed to store LLM response in cache")
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.size += int64(len(data))
+	d.entries++
+	if statErr == nil {
+		d.size -= previous.Size()
+		d.entries--
+	}
+	if d.maxSize > 0 && d.size > d.maxSize {
+		d.evict()
+	}
+}
+
+// evict removes the least recently used entries until the cache fits its
+// size limit, recounting the directory since other runs share it. d.mu is
+// held.
+func (d *Disk) evict() {
+	files, err := d.files()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to evict LLM cache entries")
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].used.Before(files[j].used) })
+
+	d.size = 0
+	for _, f := range files {
+		d.size += f.size
+	}
+	d.entries = len(files)
+	evicted := 0
+	for _, f := range files {
+		if d.size <= d.maxSize {
+			break
+		}
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			continue
+		}
+		d.size -= f.size
+		d.entries--
+		evicted++
+	}
+	log.Debug().Int("evicted", evicted).Int64("size", d.size).Msg("Evicted LLM cache entries")
+}
+
+// Clear removes every entry and resets the statistics
+func (d *Disk) Clear() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	files, err := d.files()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to clear LLM cache")
+	}
+	for _, f := range files {
+		_ = os.Remove(f.path)
+	}
+	d.size, d.entries, d.hits, d.misses = 0, 0, 0, 0
+	log.Info().Str("dir", d.dir).Msg("LLM cache cleared")
+}
+
+// Stats returns the hits and misses of this process and the entries on disk
+func (d *Disk) Stats() llm.CacheStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	hitRate := 0.0
+	if total := d.hits + d.misses; total > 0 {
+		hitRate = float64(d.hits) / float64(total)
+	}
+	return llm.CacheStats{
+		Hits:        d.hits,
+		Misses:      d.misses,
+		Entries:     d.entries,
+		HitRate:     hitRate,
+		TotalSizeKB: d.size / 1024,
+	}
+}
+
+func (d *Disk) miss() {
+	d.mu.Lock()
+	d.misses++
+	d.mu.Unlock()
+}
+
+// remove deletes an expired entry
+func (d *Disk) remove(path string) {
+	info, err := os.Stat(path)
+	if err != nil || os.Remove(path) != nil {
+		return
+	}
+	d.mu.Lock()
+	d.size -= info.Size()
+	d.entries--
+	d.mu.Unlock()
+}
+
+// entryFile is an entry file found in the cache directory
+type entryFile struct {
+	path string
+	size int64
+	used time.Time
+}
+
+// files lists the entry files in the cache directory
+func (d *Disk) files() ([]entryFile, error) {
+	var files []entryFile
+	err := filepath.WalkDir(d.dir, func(path string, de fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if de.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		info, err := de.Info()
+		if err != nil {
+			// Removed by another run since the directory was read
+			return nil
+		}
+		files = append(files, entryFile{path: path, size: info.Size(), used: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response cache directory: %w", err)
+	}
+	return files, nil
+}
+
+// writeAtomic writes data to path through a temporary file, so readers
+// never see a partial entry
+func writeAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
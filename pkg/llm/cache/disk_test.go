@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisk_GetSet(t *testing.T) {
+	dir := t.TempDir()
+	d, err := New(Config{Dir: dir})
+	require.NoError(t, err)
+
+	_, found := d.Get("key")
+	assert.False(t, found)
+
+	d.Set("key", "response")
+	response, found := d.Get("key")
+	require.True(t, found)
+	assert.Equal(t, "response", response)
+
+	// Another run sees the entry
+	reopened, err := New(Config{Dir: dir})
+	require.NoError(t, err)
+	response, found = reopened.Get("key")
+	require.True(t, found)
+	assert.Equal(t, "response", response)
+	assert.Equal(t, 1, reopened.Stats().Entries)
+
+	stats := d.Stats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, 1, stats.Entries)
+
+	d.Set("key", "replaced")
+	assert.Equal(t, 1, d.Stats().Entries, "replacing an entry does not add one")
+
+	d.Clear()
+	_, found = reopened.Get("key")
+	assert.False(t, found)
+	assert.Zero(t, d.Stats().Entries)
+}
+
+func TestDisk_TTL(t *testing.T) {
+	d, err := New(Config{Dir: t.TempDir(), TTL: time.Hour})
+	require.NoError(t, err)
+
+	d.Set("fresh", "response")
+	_, found := d.Get("fresh")
+	assert.True(t, found)
+
+	// Backdate the entry past its TTL
+	path := d.path("stale")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	stored := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339Nano)
+	require.NoError(t, os.WriteFile(path, []byte(`{"response":"old","stored_at":"`+stored+`"}`), 0o600))
+
+	_, found = d.Get("stale")
+	assert.False(t, found, "an expired entry is a miss")
+	assert.NoFileExists(t, path, "an expired entry is removed")
+}
+
+func TestDisk_EvictsLeastRecentlyUsed(t *testing.T) {
+	// Room for three entries of about 210 bytes
+	d, err := New(Config{Dir: t.TempDir(), MaxSize: 700})
+	require.NoError(t, err)
+
+	response := strings.Repeat("x", 150)
+	d.Set("first", response)
+	d.Set("second", response)
+	d.Set("third", response)
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(d.path("first"), old, old))
+	require.NoError(t, os.Chtimes(d.path("second"), old.Add(time.Minute), old.Add(time.Minute)))
+
+	// Reading the first entry makes the second the least recently used
+	_, found := d.Get("first")
+	require.True(t, found)
+
+	d.Set("fourth", response)
+
+	_, found = d.Get("second")
+	assert.False(t, found, "the least recently used entry is evicted")
+	for _, key := range []string{"first", "third", "fourth"} {
+		_, found = d.Get(key)
+		assert.True(t, found, key)
+	}
+	assert.Equal(t, 3, d.Stats().Entries)
+}
+
+func TestDisk_UnreadableEntryIsMiss(t *testing.T) {
+	d, err := New(Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+
+	path := d.path("key")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte("{truncated"), 0o600))
+
+	_, found := d.Get("key")
+	assert.False(t, found)
+}
+
+func TestNew_RejectsNegativeLimits(t *testing.T) {
+	_, err := New(Config{Dir: t.TempDir(), TTL: -time.Second})
+	assert.ErrorContains(t, err, "TTL")
+
+	_, err = New(Config{Dir: t.TempDir(), MaxSize: -1})
+	assert.ErrorContains(t, err, "max size")
+}
+
+// countingClient answers every prompt and counts the requests it received
+type countingClient struct {
+	requests int
+}
+
+func (c *countingClient) Generate(_ context.Context, prompt string) (string, error) {
+	c.requests++
+	return "response to " + prompt, nil
+}
+
+func (c *countingClient) GenerateStructured(_ context.Context, _ string, _ interface{}) (interface{}, error) {
+	c.requests++
+	return nil, nil
+}
+
+func (c *countingClient) Chat(_ context.Context, messages []llm.Message) (string, error) {
+	c.requests++
+	return "response to " + messages[len(messages)-1].Content, nil
+}
+
+func (c *countingClient) Provider() string { return "counting" }
+
+func (c *countingClient) Model() string { return "model" }
+
+func TestDisk_CachedClientRerun(t *testing.T) {
+	dir := t.TempDir()
+	client := &countingClient{}
+
+	for run := 0; run < 2; run++ {
+		d, err := New(Config{Dir: dir})
+		require.NoError(t, err)
+		cached := llm.NewCachedClient(client, d)
+
+		var out strings.Builder
+		_, err = cached.(llm.StreamingClient).GenerateStream(context.Background(), []llm.CacheableMessage{
+			{Role: "system", Content: "be brief", Cache: llm.NewCacheControl("5m")},
+			{Role: "user", Content: "main.go"},
+		}, &out)
+		require.NoError(t, err)
+		assert.Equal(t, "response to main.go", out.String())
+	}
+	assert.Equal(t, 1, client.requests, "the rerun is answered from disk")
+}
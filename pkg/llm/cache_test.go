@@ -482,3 +482,64 @@ func BenchmarkCache_Concurrent(b *testing.B) {
 		}
 	})
 }
+
+// batchEchoClient answers batch requests with their prompts and records the
+// IDs it was sent
+type batchEchoClient struct {
+	echoClient
+	submitted []string
+}
+
+func (b *batchEchoClient) GenerateBatch(_ context.Context, requests []BatchRequest) ([]BatchResult, error) {
+	results := make([]BatchResult, len(requests))
+	for i, req := range requests {
+		b.submitted = append(b.submitted, req.ID)
+		results[i] = BatchResult{ID: req.ID, Text: req.Prompt}
+	}
+	return results, nil
+}
+
+func TestCachedClient_GenerateBatch(t *testing.T) {
+	batch := &batchEchoClient{}
+	client := NewCachedClient(batch, NewCache(CacheConfig{Enabled: true}))
+
+	_, err := client.Generate(context.Background(), "a.go")
+	require.NoError(t, err)
+
+	results, err := client.(BatchClient).GenerateBatch(context.Background(), []BatchRequest{
+		{ID: "a", Prompt: "a.go"},
+		{ID: "b", Prompt: "b.go"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, []string{"b"}, batch.submitted, "a cached prompt is not submitted")
+
+	_, err = client.(BatchClient).GenerateBatch(context.Background(), []BatchRequest{{ID: "b2", Prompt: "b.go"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, batch.submitted, "batch results are cached")
+}
+
+func TestCachedClient_GenerateBatchOrder(t *testing.T) {
+	batch := &batchEchoClient{}
+	client := NewCachedClient(batch, NewCache(CacheConfig{Enabled: true}))
+
+	_, err := client.Generate(context.Background(), "b.go")
+	require.NoError(t, err)
+
+	results, err := client.(BatchClient).GenerateBatch(context.Background(), []BatchRequest{
+		{ID: "a", Prompt: "a.go"},
+		{ID: "b", Prompt: "b.go"},
+		{ID: "c", Prompt: "c.go"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, batch.submitted)
+
+	var ids, texts []string
+	for _, result := range results {
+		require.NoError(t, result.Err)
+		ids = append(ids, result.ID)
+		texts = append(texts, result.Text)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, ids, "results are in request order")
+	assert.Equal(t, []string{"a.go", "b.go", "c.go"}, texts)
+}
@@ -0,0 +1,206 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// chatEndpoint builds an authorized POST request carrying body to the chat
+// completions endpoint of an API that follows OpenAI's. OpenAI and
+// OpenAI-compatible servers take a bearer token; Azure OpenAI addresses a
+// deployment and takes an API key or an Entra ID token.
+type chatEndpoint func(ctx context.Context, body []byte) (*http.Request, error)
+
+// bearerEndpoint posts to the /chat/completions endpoint under baseURL,
+// sending the API key as a bearer token when there is one
+func bearerEndpoint(baseURL, apiKey string) chatEndpoint {
+	return func(ctx context.Context, body []byte) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/chat/completions", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}
+}
+
+// chatCompletionOutput is a chat completion response
+type chatCompletionOutput struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// chatCompletion sends a chat completion request with retry logic and
+// returns the content of the first choice
+func (b *baseClient) chatCompletion(ctx context.Context, operation string, httpClient *http.Client, endpoint chatEndpoint, input any) (string, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var result string
+	err = b.retry(ctx, operation, func() error {
+		result, err = postChatCompletion(ctx, httpClient, endpoint, body)
+		return err
+	})
+	return result, err
+}
+
+// postChatCompletion sends one chat completion request and returns the
+// content of the first choice
+func postChatCompletion(ctx context.Context, httpClient *http.Client, endpoint chatEndpoint, body []byte) (string, error) {
+	req, err := endpoint(ctx, body)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return "", newHTTPError("POST /chat/completions", resp)
+	}
+
+	var output chatCompletionOutput
+	if err := json.NewDecoder(resp.Body).Decode(&output); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if output.Error != nil {
+		return "", fmt.Errorf("server error: %s", output.Error.Message)
+	}
+	if len(output.Choices) == 0 {
+		return "", fmt.Errorf("response has no choices")
+	}
+	return output.Choices[0].Message.Content, nil
+}
+
+// openaiToolCall is a function call in a chat completion message
+type openaiToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// openaiFunctionInput is a chat completion request offering functions. Its
+// messages carry tool calls and their results besides text.
+type openaiFunctionInput struct {
+	Model               string                   `json:"model"`
+	Messages            []map[string]interface{} `json:"messages"`
+	Temperature         float64                  `json:"temperature"`
+	MaxCompletionTokens int                      `json:"max_completion_tokens,omitempty"`
+	Tools               []interface{}            `json:"tools"`
+	ToolChoice          interface{}              `json:"tool_choice"`
+}
+
+// openaiFunctionOutput is a chat completion response to an openaiFunctionInput
+type openaiFunctionOutput struct {
+	Choices []struct {
+		FinishReason string `json:"finish_reason"`
+		Message      struct {
+			ToolCalls []openaiToolCall `json:"tool_calls"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// callFunction implements GenerateStructured with function calling: the
+// model returns the output through a forced call of the respond function,
+// whose parameters are schema, and a response that does not match is
+// answered with the violations as the function's result until one does
+func (b *baseClient) callFunction(ctx context.Context, httpClient *http.Client, endpoint chatEndpoint, prompt string, schema interface{}) (interface{}, error) {
+	parameters, err := toolSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	input := openaiFunctionInput{
+		Model:               b.config.Model,
+		Messages:            []map[string]interface{}{{"role": "user", "content": prompt}},
+		Temperature:         b.config.Temperature,
+		MaxCompletionTokens: b.config.MaxTokens,
+		Tools: []interface{}{map[string]interface{}{
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":        StructuredOutputTool,
+				"description": structuredOutputDescription,
+				"parameters":  parameters,
+			},
+		}},
+		ToolChoice: map[string]interface{}{
+			"type":     "function",
+			"function": map[string]string{"name": StructuredOutputTool},
+		},
+	}
+
+	var call openaiToolCall
+	return generateValidated(schema, func(problems string) ([]byte, error) {
+		if problems != "" {
+			input.Messages = append(input.Messages, map[string]interface{}{
+				"role": "tool", "tool_call_id": call.ID, "content": problems,
+			})
+		}
+		body, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		err = b.retry(ctx, "generate_structured", func() error {
+			req, err := endpoint(ctx, body)
+			if err != nil {
+				return err
+			}
+
+			resp, err := httpClient.Do(req)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode >= 300 {
+				return newHTTPError("POST /chat/completions", resp)
+			}
+
+			var output openaiFunctionOutput
+			if err := json.NewDecoder(resp.Body).Decode(&output); err != nil {
+				return fmt.Errorf("failed to parse response: %w", err)
+			}
+			if len(output.Choices) == 0 {
+				return fmt.Errorf("response has no choices")
+			}
+			if output.Choices[0].FinishReason == "length" {
+				return truncatedCall("max_completion_tokens", b.config.MaxTokens, "output")
+			}
+			for _, toolCall := range output.Choices[0].Message.ToolCalls {
+				if toolCall.Function.Name == StructuredOutputTool {
+					call = toolCall
+					return nil
+				}
+			}
+			return fmt.Errorf("response did not call %s", StructuredOutputTool)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		input.Messages = append(input.Messages, map[string]interface{}{
+			"role": "assistant", "content": nil, "tool_calls": []openaiToolCall{call},
+		})
+		return []byte(call.Function.Arguments), nil
+	})
+}
@@ -3,7 +3,6 @@ package llm
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/rs/zerolog/log"
 )
@@ -64,54 +63,15 @@ func (b *baseClient) Model() string {
 	return b.config.Model
 }
 
-// retry executes a function with exponential backoff retry logic
+// retry runs fn under the config's retry policy, backing off exponentially
+// between attempts
 func (b *baseClient) retry(ctx context.Context, operation string, fn func() error) error {
-	var lastErr error
-	delay := b.config.RetryDelay
-
-	for attempt := 0; attempt <= b.config.MaxRetries; attempt++ {
-		// Execute the operation
-		err := fn()
-
-		if err == nil {
-			if attempt > 0 {
-				log.Info().
-					Str("provider", string(b.config.Provider)).
-					Str("operation", operation).
-					Int("attempt", attempt+1).
-					Msg("Operation succeeded after retry")
-			}
-			return nil
-		}
-
-		lastErr = err
-
-		// Check if context was canceled
-		if ctx.Err() != nil {
-			return fmt.Errorf("%s canceled: %w", operation, ctx.Err())
-		}
-
-		// Don't retry if this was the last attempt
-		if attempt < b.config.MaxRetries {
-			log.Warn().
-				Err(err).
-				Str("provider", string(b.config.Provider)).
-				Str("operation", operation).
-				Int("attempt", attempt+1).
-				Dur("retry_delay", delay).
-				Msg("Operation failed, retrying")
-
-			// Wait before retry with exponential backoff
-			select {
-			case <-time.After(delay):
-				delay *= 2 // Exponential backoff
-			case <-ctx.Done():
-				return fmt.Errorf("%s canceled during retry: %w", operation, ctx.Err())
-			}
-		}
-	}
+	return retryCall(ctx, b.retryPolicy(), string(b.config.Provider), b.config.Model, operation, fn)
+}
 
-	return fmt.Errorf("%s failed after %d attempts: %w", operation, b.config.MaxRetries+1, lastErr)
+// retryPolicy returns the policy the client retries its requests under
+func (b *baseClient) retryPolicy() RetryPolicy {
+	return b.config.RetryPolicy()
 }
 
 // wrapError wraps an error with provider and operation context
@@ -133,19 +93,33 @@ func NewClient(config Config) (Client, error) {
 		Float64("temperature", config.Temperature).
 		Dur("timeout", config.Timeout).
 		Int("max_tokens", config.MaxTokens).
+		Str("base_url", config.BaseURL).
 		Msg("Creating LLM client")
 
+	// Each provider client applies the proxy and TLS settings to its own
+	// HTTP client, so clients with different settings can coexist
+	if !config.Network.IsZero() {
+		log.Info().
+			Bool("proxy", config.Network.ProxyURL != "").
+			Bool("custom_ca", config.Network.CABundle != "").
+			Bool("insecure_skip_verify", config.Network.InsecureSkipVerify).
+			Msg("Applying LLM network configuration")
+	}
+
 	// Create provider-specific client
-	switch config.Provider {
-	case ProviderAnthropic:
-		return newAnthropicClient(config)
-	case ProviderOpenAI:
-		return newOpenAIClient(config)
-	case ProviderGoogle:
-		return newGoogleClient(config)
-	default:
+	factory, ok := providerFactory(config.Provider)
+	if !ok {
 		return nil, fmt.Errorf("unsupported provider: %s", config.Provider)
 	}
+	client, err := factory(config)
+	if err != nil {
+		return nil, err
+	}
+	// Built-in clients retry each request themselves
+	if _, ok := client.(selfRetrying); !ok && config.MaxRetries > 0 {
+		client = NewRetryingClient(client, config.RetryPolicy())
+	}
+	return client, nil
 }
 
 // ValidateAPIKey checks if an API key is valid (basic validation)
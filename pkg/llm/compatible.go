@@ -0,0 +1,131 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// compatibleClient implements the Client interface for servers that speak
+// the OpenAI chat completions API, such as Ollama, vLLM and LM Studio
+type compatibleClient struct {
+	baseClient
+	httpClient *http.Client
+	baseURL    string
+}
+
+// newCompatibleClient creates a client for the server at config.BaseURL
+func newCompatibleClient(config Config) (*compatibleClient, error) {
+	httpClient, err := NewHTTPClient(config.Network, config.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	return &compatibleClient{
+		baseClient: baseClient{config: config},
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(config.BaseURL, "/"),
+	}, nil
+}
+
+// compatibleChatInput is a chat completion request. Servers implementing the
+// API commonly accept max_tokens but not OpenAI's newer max_completion_tokens.
+type compatibleChatInput struct {
+	Model       string              `json:"model"`
+	Messages    []map[string]string `json:"messages"`
+	Temperature float64             `json:"temperature"`
+	MaxTokens   int                 `json:"max_tokens,omitempty"`
+	Stream      bool                `json:"stream,omitempty"`
+}
+
+// Generate produces text from a single prompt
+func (c *compatibleClient) Generate(ctx context.Context, prompt string) (string, error) {
+	result, err := c.complete(ctx, "generate", []map[string]string{{"role": "user", "content": prompt}})
+	if err != nil {
+		return "", c.wrapError("generate", err)
+	}
+	return result, nil
+}
+
+// GenerateStructured produces structured output based on a schema, asked for
+// in the prompt since servers differ in their support for function calling.
+// A response that does not match the schema is answered with the violations
+// until one does.
+func (c *compatibleClient) GenerateStructured(ctx context.Context, prompt string, schema interface{}) (interface{}, error) {
+	structured, err := structuredPrompt(prompt, schema)
+	if err != nil {
+		return nil, c.wrapError("generate_structured", err)
+	}
+	messages := []map[string]string{{"role": "user", "content": structured}}
+
+	output, err := generateValidated(schema, func(problems string) ([]byte, error) {
+		if problems != "" {
+			messages = append(messages, map[string]string{"role": "user", "content": problems})
+		}
+		result, err := c.complete(ctx, "generate_structured", messages)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, map[string]string{"role": "assistant", "content": result})
+		return jsonFromText(result), nil
+	})
+	if err != nil {
+		return nil, c.wrapError("generate_structured", err)
+	}
+	return output, nil
+}
+
+// StructuredOutput implements StructuredOutputClient
+func (c *compatibleClient) StructuredOutput() bool {
+	return true
+}
+
+// Chat processes a sequence of messages and returns the assistant's response
+func (c *compatibleClient) Chat(ctx context.Context, messages []Message) (string, error) {
+	if len(messages) == 0 {
+		return "", c.wrapError("chat", fmt.Errorf("messages cannot be empty"))
+	}
+
+	chatMessages := make([]map[string]string, 0, len(messages))
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system", "user", "assistant":
+		default:
+			return "", c.wrapError("chat", fmt.Errorf("invalid message role: %s", msg.Role))
+		}
+		chatMessages = append(chatMessages, map[string]string{"role": msg.Role, "content": msg.Content})
+	}
+
+	result, err := c.complete(ctx, "chat", chatMessages)
+	if err != nil {
+		return "", c.wrapError("chat", err)
+	}
+	return result, nil
+}
+
+// GenerateStream implements StreamingClient with streaming chat completions
+func (c *compatibleClient) GenerateStream(ctx context.Context, messages []CacheableMessage, w io.Writer) (int64, error) {
+	input := compatibleChatInput{
+		Model:       c.config.Model,
+		Temperature: c.config.Temperature,
+		MaxTokens:   c.config.MaxTokens,
+		Stream:      true,
+	}
+	for _, msg := range messages {
+		input.Messages = append(input.Messages, map[string]string{"role": msg.Role, "content": msg.Content})
+	}
+	return c.streamChatCompletions(ctx, streamHTTPClient(c.httpClient), bearerEndpoint(c.baseURL, c.config.APIKey), input, w)
+}
+
+// complete sends a chat completion request with retry logic and returns the
+// content of the first choice
+func (c *compatibleClient) complete(ctx context.Context, operation string, messages []map[string]string) (string, error) {
+	return c.chatCompletion(ctx, operation, c.httpClient, bearerEndpoint(c.baseURL, c.config.APIKey), compatibleChatInput{
+		Model:       c.config.Model,
+		Messages:    messages,
+		Temperature: c.config.Temperature,
+		MaxTokens:   c.config.MaxTokens,
+	})
+}
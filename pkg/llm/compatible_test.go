@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compatibleTestConfig(baseURL string) Config {
+	cfg := batchTestConfig(ProviderOpenAICompatible)
+	cfg.Model = "qwen2.5-coder:7b"
+	cfg.APIKey = ""
+	cfg.BaseURL = baseURL + "/v1/"
+	return cfg
+}
+
+func TestCompatibleClient_Chat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"), "no key is sent to a server without one")
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "qwen2.5-coder:7b", body["model"])
+		assert.EqualValues(t, 4096, body["max_tokens"])
+		assert.NotContains(t, body, "stream")
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"package main\n"}}]}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(compatibleTestConfig(server.URL))
+	require.NoError(t, err)
+	assert.Equal(t, "openai-compatible", client.Provider())
+
+	response, err := client.Chat(context.Background(), []Message{
+		{Role: "system", Content: "context"},
+		{Role: "user", Content: "generate main.go"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", response)
+}
+
+func TestCompatibleClient_UnknownModel(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Error(w, `{"error":{"message":"model not found"}}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cfg := compatibleTestConfig(server.URL)
+	cfg.MaxRetries = 2
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+
+	_, err = client.Generate(context.Background(), "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model not found")
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests), "a client error is not retried")
+}
+
+func TestCompatibleClient_GenerateStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer local-key", r.Header.Get("Authorization"))
+
+		var body compatibleChatInput
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.True(t, body.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"package \"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"main\\n\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	cfg := compatibleTestConfig(server.URL)
+	cfg.APIKey = "local-key"
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+	streaming, ok := client.(StreamingClient)
+	require.True(t, ok)
+
+	var out bytes.Buffer
+	_, err = streaming.GenerateStream(context.Background(), streamMessages, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", out.String())
+}
+
+// echoClient is a third-party provider that answers with its prompt
+type echoClient struct {
+	model string
+}
+
+func (e *echoClient) Generate(_ context.Context, prompt string) (string, error) { return prompt, nil }
+
+func (e *echoClient) GenerateStructured(_ context.Context, _ string, _ interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (e *echoClient) Chat(_ context.Context, messages []Message) (string, error) {
+	return messages[len(messages)-1].Content, nil
+}
+
+func (e *echoClient) Provider() string { return "echo" }
+
+func (e *echoClient) Model() string { return e.model }
+
+func TestRegisterProvider(t *testing.T) {
+	cfg := batchTestConfig("echo")
+	cfg.APIKey = ""
+
+	_, err := NewClient(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid provider: echo")
+
+	RegisterProvider("echo", func(config Config) (Client, error) {
+		return &echoClient{model: config.Model}, nil
+	})
+	t.Cleanup(func() {
+		factoryMu.Lock()
+		delete(providerFactories, "echo")
+		factoryMu.Unlock()
+	})
+	assert.Contains(t, RegisteredProviders(), Provider("echo"))
+
+	// A registered provider needs no API key
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+	response, err := client.Generate(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", response)
+}
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -16,11 +17,36 @@ const (
 	ProviderOpenAI Provider = "openai"
 	// ProviderGoogle represents Google (Gemini) provider
 	ProviderGoogle Provider = "google"
+	// ProviderOpenAICompatible represents a self-hosted or third-party server
+	// speaking the OpenAI chat completions API (Ollama, vLLM, LM Studio),
+	// reached at Config.BaseURL
+	ProviderOpenAICompatible Provider = "openai-compatible"
+	// ProviderAzureOpenAI represents an Azure OpenAI resource at
+	// Config.BaseURL, serving the model through Config.Deployment
+	ProviderAzureOpenAI Provider = "azure-openai"
+	// ProviderBedrock represents AWS Bedrock (Claude and other models) in
+	// Config.Region
+	ProviderBedrock Provider = "bedrock"
 )
 
+// RequiresAPIKey reports whether the provider is a hosted API that rejects
+// requests without a key. Self-hosted and third-party providers may run
+// without one, and Azure OpenAI and Bedrock fall back to the credentials of
+// the environment or the instance they run on.
+func (p Provider) RequiresAPIKey() bool {
+	switch p {
+	case ProviderAnthropic, ProviderOpenAI, ProviderGoogle:
+		return true
+	default:
+		return false
+	}
+}
+
 // Config holds LLM client configuration
 type Config struct {
-	// Provider specifies which LLM provider to use (anthropic, openai, google)
+	// Provider specifies which LLM provider to use (anthropic, openai, google,
+	// openai-compatible, azure-openai, bedrock, or one added with
+	// RegisterProvider)
 	Provider Provider
 
 	// Model specifies the model name (e.g., "claude-sonnet-4-5", "gpt-4", "gemini-pro")
@@ -30,8 +56,28 @@ type Config struct {
 	Temperature float64
 
 	// APIKey is the authentication key for the provider
+	// Optional for providers that do not require one
 	APIKey string
 
+	// BaseURL is the API endpoint of the openai-compatible provider, up to
+	// and including the version (e.g., "http://localhost:11434/v1"), or the
+	// resource endpoint of azure-openai (e.g.,
+	// "https://my-resource.openai.azure.com"). For bedrock it replaces the
+	// regional endpoint, such as with a VPC endpoint.
+	BaseURL string
+
+	// Deployment is the azure-openai deployment serving Model
+	// Defaults to Model if not specified
+	Deployment string
+
+	// APIVersion is the azure-openai API version
+	// Defaults to DefaultAzureAPIVersion if not specified
+	APIVersion string
+
+	// Region is the AWS region of the bedrock provider
+	// Defaults to the AWS_REGION or AWS_DEFAULT_REGION environment variable
+	Region string
+
 	// Timeout specifies the maximum duration for API calls
 	Timeout time.Duration
 
@@ -44,6 +90,11 @@ type Config struct {
 	// RetryDelay specifies the initial delay between retries (exponential backoff)
 	RetryDelay time.Duration
 
+	// MaxRetryDelay caps the delay between retries, including the wait a
+	// provider asks for when rate limiting; a request it will not take for
+	// longer fails. Defaults to DefaultMaxRetryDelay if not specified
+	MaxRetryDelay time.Duration
+
 	// EnableCaching enables prompt caching (Anthropic only)
 	// When enabled, stable portions of prompts (FCS schema, guidelines) are cached
 	EnableCaching bool
@@ -51,6 +102,13 @@ type Config struct {
 	// CacheTTL specifies the cache time-to-live (5m or 1h)
 	// Defaults to 5m if not specified
 	CacheTTL string
+
+	// Network configures the outbound proxy, custom root CAs and TLS options
+	Network NetworkConfig
+
+	// BatchPollInterval is how often batch jobs are checked for completion
+	// Defaults to DefaultBatchPollInterval if not specified
+	BatchPollInterval time.Duration
 }
 
 // DefaultConfig returns a Config with sensible defaults
@@ -71,11 +129,13 @@ func DefaultConfig() Config {
 // Validate checks if the configuration is valid
 func (c Config) Validate() error {
 	// Validate provider
-	switch c.Provider {
-	case ProviderAnthropic, ProviderOpenAI, ProviderGoogle:
-		// Valid provider
-	default:
-		return fmt.Errorf("invalid provider: %s (must be one of: anthropic, openai, google)", c.Provider)
+	if _, ok := providerFactory(c.Provider); !ok {
+		registered := RegisteredProviders()
+		names := make([]string, 0, len(registered))
+		for _, name := range registered {
+			names = append(names, string(name))
+		}
+		return fmt.Errorf("invalid provider: %s (must be one of: %s)", c.Provider, strings.Join(names, ", "))
 	}
 
 	// Validate model name
@@ -89,10 +149,20 @@ func (c Config) Validate() error {
 	}
 
 	// Validate API key
-	if strings.TrimSpace(c.APIKey) == "" {
+	if c.Provider.RequiresAPIKey() && strings.TrimSpace(c.APIKey) == "" {
 		return fmt.Errorf("API key cannot be empty for provider: %s", c.Provider)
 	}
 
+	// Validate endpoint
+	if (c.Provider == ProviderOpenAICompatible || c.Provider == ProviderAzureOpenAI) && strings.TrimSpace(c.BaseURL) == "" {
+		return fmt.Errorf("base URL cannot be empty for provider: %s", c.Provider)
+	}
+	if c.BaseURL != "" {
+		if u, err := url.Parse(c.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("base URL must be an http or https URL, got: %s", c.BaseURL)
+		}
+	}
+
 	// Validate timeout
 	if c.Timeout <= 0 {
 		return fmt.Errorf("timeout must be positive, got: %v", c.Timeout)
@@ -108,10 +178,14 @@ func (c Config) Validate() error {
 		return fmt.Errorf("max retries cannot be negative, got: %d", c.MaxRetries)
 	}
 
-	if c.RetryDelay <= 0 {
+	if c.RetryDelay < 0 || (c.MaxRetries > 0 && c.RetryDelay == 0) {
 		return fmt.Errorf("retry delay must be positive, got: %v", c.RetryDelay)
 	}
 
+	if c.MaxRetryDelay < 0 {
+		return fmt.Errorf("max retry delay cannot be negative, got: %v", c.MaxRetryDelay)
+	}
+
 	// Validate cache TTL if caching is enabled
 	if c.EnableCaching {
 		if c.CacheTTL != "" && c.CacheTTL != "5m" && c.CacheTTL != "1h" {
@@ -120,6 +194,14 @@ func (c Config) Validate() error {
 		// Note: Default CacheTTL ("5m") is set in DefaultConfig()
 	}
 
+	if c.BatchPollInterval < 0 {
+		return fmt.Errorf("batch poll interval cannot be negative, got: %v", c.BatchPollInterval)
+	}
+
+	if err := c.Network.Validate(); err != nil {
+		return fmt.Errorf("invalid network config: %w", err)
+	}
+
 	return nil
 }
 
@@ -130,6 +212,16 @@ func (c Config) String() string {
 		apiKeyMasked = c.APIKey[:4] + "***"
 	}
 
-	return fmt.Sprintf("Provider=%s Model=%s Temperature=%.1f Timeout=%v MaxTokens=%d APIKey=%s",
+	description := fmt.Sprintf("Provider=%s Model=%s Temperature=%.1f Timeout=%v MaxTokens=%d APIKey=%s",
 		c.Provider, c.Model, c.Temperature, c.Timeout, c.MaxTokens, apiKeyMasked)
+	if c.BaseURL != "" {
+		description += " BaseURL=" + c.BaseURL
+	}
+	if c.Deployment != "" {
+		description += " Deployment=" + c.Deployment
+	}
+	if c.Region != "" {
+		description += " Region=" + c.Region
+	}
+	return description
 }
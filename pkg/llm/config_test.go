@@ -71,6 +71,77 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid config - openai-compatible without API key",
+			config: Config{
+				Provider:    ProviderOpenAICompatible,
+				Model:       "llama3.1:8b",
+				Temperature: 0.0,
+				BaseURL:     "http://localhost:11434/v1",
+				Timeout:     60 * time.Second,
+				MaxTokens:   4096,
+				MaxRetries:  3,
+				RetryDelay:  time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "openai-compatible without base URL",
+			config: Config{
+				Provider:    ProviderOpenAICompatible,
+				Model:       "llama3.1:8b",
+				Temperature: 0.0,
+				Timeout:     60 * time.Second,
+				MaxTokens:   4096,
+				MaxRetries:  3,
+				RetryDelay:  time.Second,
+			},
+			wantErr: true,
+			errMsg:  "base URL cannot be empty",
+		},
+		{
+			name: "openai-compatible with invalid base URL",
+			config: Config{
+				Provider:    ProviderOpenAICompatible,
+				Model:       "llama3.1:8b",
+				Temperature: 0.0,
+				BaseURL:     "localhost:11434",
+				Timeout:     60 * time.Second,
+				MaxTokens:   4096,
+				MaxRetries:  3,
+				RetryDelay:  time.Second,
+			},
+			wantErr: true,
+			errMsg:  "base URL must be an http or https URL",
+		},
+		{
+			name: "azure-openai without base URL",
+			config: Config{
+				Provider:    ProviderAzureOpenAI,
+				Model:       "gpt-4o",
+				Temperature: 0.0,
+				Timeout:     60 * time.Second,
+				MaxTokens:   4096,
+				MaxRetries:  3,
+				RetryDelay:  time.Second,
+			},
+			wantErr: true,
+			errMsg:  "base URL cannot be empty",
+		},
+		{
+			name: "valid config - bedrock without API key",
+			config: Config{
+				Provider:    ProviderBedrock,
+				Model:       "anthropic.claude-sonnet-4-5-20250929-v1:0",
+				Temperature: 0.0,
+				Region:      "us-east-1",
+				Timeout:     60 * time.Second,
+				MaxTokens:   4096,
+				MaxRetries:  3,
+				RetryDelay:  time.Second,
+			},
+			wantErr: false,
+		},
 		{
 			name: "invalid provider",
 			config: Config{
@@ -235,4 +306,7 @@ func TestProviderConstants(t *testing.T) {
 	assert.Equal(t, Provider("anthropic"), ProviderAnthropic)
 	assert.Equal(t, Provider("openai"), ProviderOpenAI)
 	assert.Equal(t, Provider("google"), ProviderGoogle)
+	assert.Equal(t, Provider("openai-compatible"), ProviderOpenAICompatible)
+	assert.Equal(t, Provider("azure-openai"), ProviderAzureOpenAI)
+	assert.Equal(t, Provider("bedrock"), ProviderBedrock)
 }
@@ -0,0 +1,155 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// credentialRefreshWindow is how long before they expire cached instance
+// credentials and tokens are refreshed
+const credentialRefreshWindow = 5 * time.Minute
+
+// metadataHTTPClient returns the client for instance metadata services,
+// which are link-local: requests must not go through a proxy, and a host
+// without one should fail fast
+func metadataHTTPClient() *http.Client {
+	transport := &http.Transport{Proxy: nil}
+	if systemTransport != nil {
+		transport = systemTransport.Clone()
+		transport.Proxy = nil
+	}
+	return &http.Client{Transport: transport, Timeout: 5 * time.Second}
+}
+
+// awsCredentials sign AWS requests
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Expires is when temporary credentials stop working, zero for keys
+	Expires time.Time
+}
+
+// awsCredentialChain resolves AWS credentials from the environment, and
+// otherwise from the IAM role of the EC2 instance (or ECS task with host
+// networking) it runs on, through IMDSv2
+type awsCredentialChain struct {
+	imdsEndpoint string
+	httpClient   *http.Client
+
+	mu     sync.Mutex
+	cached awsCredentials
+}
+
+// newAWSCredentialChain returns a chain reading the instance metadata
+// service at its standard address
+func newAWSCredentialChain() *awsCredentialChain {
+	return &awsCredentialChain{
+		imdsEndpoint: "http://169.254.169.254",
+		httpClient:   metadataHTTPClient(),
+	}
+}
+
+// credentials returns the environment's credentials, or the instance's,
+// cached until shortly before they expire
+func (c *awsCredentialChain) credentials(ctx context.Context) (awsCredentials, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return awsCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached.AccessKeyID != "" && time.Until(c.cached.Expires) > credentialRefreshWindow {
+		return c.cached, nil
+	}
+
+	if strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return awsCredentials{}, &noRetryError{err: fmt.Errorf("no AWS credentials: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are not set and AWS_EC2_METADATA_DISABLED is true")}
+	}
+	creds, err := c.instanceCredentials(ctx)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	c.cached = creds
+	return creds, nil
+}
+
+// instanceCredentials fetches the credentials of the instance's IAM role
+// from IMDSv2: a session token first, then the role name, then its
+// credentials
+func (c *awsCredentialChain) instanceCredentials(ctx context.Context) (awsCredentials, error) {
+	token, err := c.imds(ctx, http.MethodPut, "/latest/api/token", "")
+	if err != nil {
+		return awsCredentials{}, &noRetryError{err: fmt.Errorf("no AWS credentials in the environment, and no instance metadata service is available: %w", err)}
+	}
+
+	roles, err := c.imds(ctx, http.MethodGet, "/latest/meta-data/iam/security-credentials/", token)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to get the instance's IAM role: %w", err)
+	}
+	role := strings.TrimSpace(strings.SplitN(roles, "\n", 2)[0])
+	if role == "" {
+		return awsCredentials{}, &noRetryError{err: fmt.Errorf("the instance has no IAM role")}
+	}
+
+	document, err := c.imds(ctx, http.MethodGet, "/latest/meta-data/iam/security-credentials/"+role, token)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to get credentials of IAM role %s: %w", role, err)
+	}
+	var output struct {
+		Code            string
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string
+		Token           string
+		Expiration      time.Time
+	}
+	if err := json.Unmarshal([]byte(document), &output); err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to parse credentials of IAM role %s: %w", role, err)
+	}
+	if output.Code != "Success" || output.AccessKeyID == "" {
+		return awsCredentials{}, fmt.Errorf("instance metadata service returned no credentials for IAM role %s (%s)", role, output.Code)
+	}
+
+	return awsCredentials{
+		AccessKeyID:     output.AccessKeyID,
+		SecretAccessKey: output.SecretAccessKey,
+		SessionToken:    output.Token,
+		Expires:         output.Expiration,
+	}, nil
+}
+
+// imds sends a request to the instance metadata service and returns the
+// response body. The session token request asks for a token valid for six
+// hours; other requests present the token.
+func (c *awsCredentialChain) imds(ctx context.Context, method, path, token string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.imdsEndpoint+path, nil)
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+	} else {
+		req.Header.Set("X-aws-ec2-metadata-token", token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		return "", newHTTPError(method+" "+path, resp)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	anthropicsdk "github.com/anthropics/anthropic-sdk-go"
+	"github.com/dshills/langgraph-go/graph/model"
+)
+
+// The langgraph-go chat models create their SDK clients internally from
+// http.DefaultClient and cannot take another. Clients with network options
+// chat through the models below instead, which send every request through
+// the client's own HTTP client, so the options of one client never reach
+// another or the rest of the process.
+
+// anthropicDirectChat implements model.ChatModel with the Anthropic SDK
+type anthropicDirectChat struct {
+	client    anthropicsdk.Client
+	model     string
+	maxTokens int
+}
+
+// Chat sends the messages to the Messages API; tools are not offered
+func (m *anthropicDirectChat) Chat(ctx context.Context, messages []model.Message, _ []model.ToolSpec) (model.ChatOut, error) {
+	systemBlocks, turns := anthropicMessages(cacheableMessages(messages))
+	params := anthropicsdk.MessageNewParams{
+		Model:     anthropicsdk.Model(m.model),
+		MaxTokens: int64(m.maxTokens),
+		Messages:  turns,
+	}
+	if len(systemBlocks) > 0 {
+		params.System = systemBlocks
+	}
+
+	response, err := m.client.Messages.New(ctx, params)
+	if err != nil {
+		return model.ChatOut{}, err
+	}
+	var text strings.Builder
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return model.ChatOut{Text: text.String()}, nil
+}
+
+// chatCompletionModel implements model.ChatModel with a chat completions
+// endpoint
+type chatCompletionModel struct {
+	httpClient *http.Client
+	endpoint   chatEndpoint
+	input      openaiChatInput // Model and sampling settings; the messages are set per call
+}
+
+// Chat sends the messages as one chat completion request; tools are not offered
+func (m *chatCompletionModel) Chat(ctx context.Context, messages []model.Message, _ []model.ToolSpec) (model.ChatOut, error) {
+	input := m.input
+	input.Messages = make([]map[string]string, 0, len(messages))
+	for _, msg := range messages {
+		input.Messages = append(input.Messages, map[string]string{"role": msg.Role, "content": msg.Content})
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return model.ChatOut{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	text, err := postChatCompletion(ctx, m.httpClient, m.endpoint, body)
+	if err != nil {
+		return model.ChatOut{}, err
+	}
+	return model.ChatOut{Text: text}, nil
+}
+
+// googleDirectChat implements model.ChatModel with the Gemini REST API
+type googleDirectChat struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	model      string
+	config     googleGenerationConfig
+}
+
+// Chat sends the messages to generateContent; tools are not offered
+func (m *googleDirectChat) Chat(ctx context.Context, messages []model.Message, _ []model.ToolSpec) (model.ChatOut, error) {
+	body, err := json.Marshal(newGoogleInput(cacheableMessages(messages), m.config))
+	if err != nil {
+		return model.ChatOut{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent", m.baseURL, m.model)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return model.ChatOut{}, err
+	}
+	req.Header.Set("x-goog-api-key", m.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return model.ChatOut{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		return model.ChatOut{}, newHTTPError("POST :generateContent", resp)
+	}
+
+	var output googleStreamChunk
+	if err := json.NewDecoder(resp.Body).Decode(&output); err != nil {
+		return model.ChatOut{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if output.Error != nil {
+		return model.ChatOut{}, fmt.Errorf("server error: %s", output.Error.Message)
+	}
+	if len(output.Candidates) == 0 {
+		return model.ChatOut{}, fmt.Errorf("response has no candidates")
+	}
+	var text strings.Builder
+	for _, part := range output.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	return model.ChatOut{Text: text.String()}, nil
+}
+
+// cacheableMessages converts langgraph-go messages, without cache control
+func cacheableMessages(messages []model.Message) []CacheableMessage {
+	converted := make([]CacheableMessage, len(messages))
+	for i, msg := range messages {
+		converted[i] = CacheableMessage{Role: msg.Role, Content: msg.Content}
+	}
+	return converted
+}
@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dshills/langgraph-go/graph/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleDirectChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/gemini-test:generateContent", r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("x-goog-api-key"))
+
+		var body googleInput
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if assert.NotNil(t, body.SystemInstruction) {
+			assert.Equal(t, "context", body.SystemInstruction.Parts[0].Text)
+		}
+		if assert.Len(t, body.Contents, 2) {
+			assert.Equal(t, "model", body.Contents[1].Role)
+		}
+		assert.Equal(t, 100, body.GenerationConfig.MaxOutputTokens)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"package "},{"text":"main"}]},"finishReason":"STOP"}]}`)
+	}))
+	defer server.Close()
+
+	chat := &googleDirectChat{
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+		apiKey:     "test-key",
+		model:      "gemini-test",
+		config:     googleGenerationConfig{MaxOutputTokens: 100},
+	}
+	out, err := chat.Chat(context.Background(), []model.Message{
+		{Role: model.RoleSystem, Content: "context"},
+		{Role: model.RoleUser, Content: "write"},
+		{Role: model.RoleAssistant, Content: "draft"},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "package main", out.Text)
+}
+
+func TestChatCompletionModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var body openaiChatInput
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "gpt-test", body.Model)
+		assert.Equal(t, []map[string]string{{"role": "user", "content": "hello"}}, body.Messages)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"hi"}}]}`)
+	}))
+	defer server.Close()
+
+	chat := &chatCompletionModel{
+		httpClient: server.Client(),
+		endpoint:   bearerEndpoint(server.URL, "test-key"),
+		input:      openaiChatInput{Model: "gpt-test"},
+	}
+	out, err := chat.Chat(context.Background(), []model.Message{{Role: model.RoleUser, Content: "hello"}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", out.Text)
+}
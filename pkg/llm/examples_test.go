@@ -142,13 +142,16 @@ func ExampleClient_GenerateStructured() {
 	ctx := context.Background()
 	prompt := "Generate information about the Go programming language."
 
-	// Define expected schema
+	// Define expected schema: a JSON Schema describing an object
 	schema := map[string]interface{}{
-		"name":        "string",
-		"year":        "number",
-		"creator":     "string",
-		"description": "string",
-		"is_compiled": "boolean",
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name":        map[string]string{"type": "string"},
+			"year":        map[string]string{"type": "integer"},
+			"creator":     map[string]string{"type": "string"},
+			"is_compiled": map[string]string{"type": "boolean"},
+		},
+		"required": []string{"name", "year"},
 	}
 
 	response, err := client.GenerateStructured(ctx, prompt, schema)
@@ -2,8 +2,8 @@ package llm
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/dshills/langgraph-go/graph/model"
 	"github.com/dshills/langgraph-go/graph/model/google"
@@ -12,17 +12,40 @@ import (
 // googleClient implements the Client interface for Google (Gemini)
 type googleClient struct {
 	baseClient
-	chatModel *google.ChatModel
+	chatModel  model.ChatModel
+	httpClient *http.Client // Streaming requests
+	baseURL    string
 }
 
 // newGoogleClient creates a new Google client
 func newGoogleClient(config Config) (*googleClient, error) {
-	// Create langgraph-go Google ChatModel
-	chatModel := google.NewChatModel(config.APIKey, config.Model)
+	httpClient, err := NewHTTPClient(config.Network, config.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	baseURL := "https://generativelanguage.googleapis.com/v1beta"
+
+	// Create langgraph-go Google ChatModel, unless it has to send through
+	// the configured HTTP client
+	var chatModel model.ChatModel = google.NewChatModel(config.APIKey, config.Model)
+	if !config.Network.IsZero() {
+		chatModel = &googleDirectChat{
+			httpClient: httpClient,
+			baseURL:    baseURL,
+			apiKey:     config.APIKey,
+			model:      config.Model,
+			config: googleGenerationConfig{
+				Temperature:     config.Temperature,
+				MaxOutputTokens: config.MaxTokens,
+			},
+		}
+	}
 
 	return &googleClient{
 		baseClient: baseClient{config: config},
 		chatModel:  chatModel,
+		httpClient: httpClient,
+		baseURL:    baseURL,
 	}, nil
 }
 
@@ -54,53 +77,46 @@ func (c *googleClient) Generate(ctx context.Context, prompt string) (string, err
 	return result, nil
 }
 
-// GenerateStructured produces structured output based on a schema
+// GenerateStructured produces structured output based on a schema, asked for
+// in the prompt. A response that does not match the schema is answered with
+// the violations until one does.
 func (c *googleClient) GenerateStructured(ctx context.Context, prompt string, schema interface{}) (interface{}, error) {
-	// For structured output, we append schema information to the prompt
-	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	structured, err := structuredPrompt(prompt, schema)
 	if err != nil {
-		return nil, c.wrapError("generate_structured", fmt.Errorf("failed to marshal schema: %w", err))
+		return nil, c.wrapError("generate_structured", err)
 	}
+	messages := []model.Message{{Role: model.RoleUser, Content: structured}}
 
-	structuredPrompt := fmt.Sprintf(`%s
-
-Please respond with valid JSON that matches this schema:
-%s
-
-Return ONLY the JSON, with no additional text or explanation.`, prompt, schemaJSON)
-
-	var result string
-
-	// Execute with retry logic
-	err = c.retry(ctx, "generate_structured", func() error {
-		// Create messages for the chat
-		messages := []model.Message{
-			{Role: model.RoleUser, Content: structuredPrompt},
+	output, err := generateValidated(schema, func(problems string) ([]byte, error) {
+		if problems != "" {
+			messages = append(messages, model.Message{Role: model.RoleUser, Content: problems})
 		}
-
-		// Call ChatModel
-		out, err := c.chatModel.Chat(ctx, messages, nil)
+		var result string
+		err := c.retry(ctx, "generate_structured", func() error {
+			out, err := c.chatModel.Chat(ctx, messages, nil)
+			if err != nil {
+				return err
+			}
+			result = out.Text
+			return nil
+		})
 		if err != nil {
-			return err
+			return nil, err
 		}
-
-		result = out.Text
-		return nil
+		messages = append(messages, model.Message{Role: model.RoleAssistant, Content: result})
+		return jsonFromText(result), nil
 	})
-
 	if err != nil {
 		return nil, c.wrapError("generate_structured", err)
 	}
-
-	// Parse the JSON response
-	var output interface{}
-	if err := json.Unmarshal([]byte(result), &output); err != nil {
-		return nil, c.wrapError("generate_structured", fmt.Errorf("failed to parse JSON response: %w", err))
-	}
-
 	return output, nil
 }
 
+// StructuredOutput implements StructuredOutputClient
+func (c *googleClient) StructuredOutput() bool {
+	return true
+}
+
 // Chat processes a sequence of messages and returns the assistant's response
 func (c *googleClient) Chat(ctx context.Context, messages []Message) (string, error) {
 	if len(messages) == 0 {
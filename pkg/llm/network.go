@@ -0,0 +1,256 @@
+package llm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NetworkConfig holds outbound HTTP settings shared by all provider clients
+type NetworkConfig struct {
+	// ProxyURL routes API traffic through an HTTP(S) proxy, e.g. http://proxy.corp:3128.
+	// When empty, the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables apply.
+	ProxyURL string
+
+	// CABundle is a PEM file with additional root CAs trusted on top of the system pool
+	CABundle string
+
+	// TLSMinVersion is the minimum TLS version: "1.2" (default) or "1.3"
+	TLSMinVersion string
+
+	// InsecureSkipVerify disables server certificate verification. Debugging only.
+	InsecureSkipVerify bool
+}
+
+// IsZero reports whether no network options are set
+func (n NetworkConfig) IsZero() bool {
+	return n == NetworkConfig{}
+}
+
+// Validate checks the proxy URL, CA bundle and TLS options
+func (n NetworkConfig) Validate() error {
+	if n.ProxyURL != "" {
+		if _, err := parseProxyURL(n.ProxyURL); err != nil {
+			return err
+		}
+	}
+
+	if n.CABundle != "" {
+		if _, err := loadCABundle(n.CABundle); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tlsVersion(n.TLSMinVersion); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// systemTransport is the standard library transport the network options are
+// applied to, captured before anything else in the process can replace
+// http.DefaultTransport
+var systemTransport, _ = http.DefaultTransport.(*http.Transport)
+
+// NewHTTPTransport builds a transport that applies the network options
+func NewHTTPTransport(n NetworkConfig) (*http.Transport, error) {
+	if systemTransport == nil {
+		return nil, fmt.Errorf("default HTTP transport is not an *http.Transport")
+	}
+	transport := systemTransport.Clone()
+
+	if n.ProxyURL != "" {
+		proxyURL, err := parseProxyURL(n.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	minVersion, err := tlsVersion(n.TLSMinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: minVersion,
+		//nolint:gosec // G402: Explicit opt-in for debugging TLS interception
+		InsecureSkipVerify: n.InsecureSkipVerify,
+	}
+
+	if n.CABundle != "" {
+		pool, err := loadCABundle(n.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// NewHTTPClient builds an HTTP client that applies the network options
+func NewHTTPClient(n NetworkConfig, timeout time.Duration) (*http.Client, error) {
+	transport, err := NewHTTPTransport(n)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// providerEndpoints are the API base URLs probed by CheckConnectivity
+var providerEndpoints = map[Provider]string{
+	ProviderAnthropic: "https://api.anthropic.com/v1/models",
+	ProviderOpenAI:    "https://api.openai.com/v1/models",
+	ProviderGoogle:    "https://generativelanguage.googleapis.com/v1beta/models",
+}
+
+// CheckConnectivity verifies that the provider API is reachable through the
+// configured proxy and TLS settings. Any HTTP response, including 401, counts
+// as reachable; only proxy, DNS, TLS and timeout failures are reported.
+func CheckConnectivity(ctx context.Context, provider Provider, network NetworkConfig) error {
+	endpoint, ok := providerEndpoints[provider]
+	if !ok {
+		return fmt.Errorf("unsupported provider: %s", provider)
+	}
+	return CheckEndpoint(ctx, endpoint, network)
+}
+
+// CheckEndpoint verifies that endpoint is reachable like CheckConnectivity,
+// for servers without a fixed address such as the openai-compatible provider
+func CheckEndpoint(ctx context.Context, endpoint string, network NetworkConfig) error {
+	client, err := NewHTTPClient(network, 15*time.Second)
+	if err != nil {
+		return fmt.Errorf("invalid network configuration: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", endpoint, err)
+	}
+	_ = resp.Body.Close()
+
+	log.Debug().
+		Str("endpoint", endpoint).
+		Int("status", resp.StatusCode).
+		Msg("Provider endpoint reachable")
+
+	return nil
+}
+
+// ErrInvalidAPIKey is returned by PingAPIKey when the provider rejects the key
+var ErrInvalidAPIKey = errors.New("API key rejected by provider")
+
+// PingAPIKey verifies an API key with a cheap authenticated request that lists
+// models and consumes no tokens
+func PingAPIKey(ctx context.Context, provider Provider, apiKey string, network NetworkConfig) error {
+	endpoint, ok := providerEndpoints[provider]
+	if !ok {
+		return fmt.Errorf("unsupported provider: %s", provider)
+	}
+
+	client, err := NewHTTPClient(network, 15*time.Second)
+	if err != nil {
+		return fmt.Errorf("invalid network configuration: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	switch provider {
+	case ProviderAnthropic:
+		req.Header.Set("x-api-key", apiKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	case ProviderOpenAI:
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	case ProviderGoogle:
+		req.Header.Set("x-goog-api-key", apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", endpoint, err)
+	}
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w (HTTP %d)", ErrInvalidAPIKey, resp.StatusCode)
+	case resp.StatusCode == http.StatusBadRequest && provider == ProviderGoogle:
+		// Gemini reports malformed keys as 400 INVALID_ARGUMENT
+		return fmt.Errorf("%w (HTTP %d)", ErrInvalidAPIKey, resp.StatusCode)
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("unexpected response from %s: HTTP %d", endpoint, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// parseProxyURL validates a proxy URL
+func parseProxyURL(raw string) (*url.URL, error) {
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", raw, err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https or socks5", raw)
+	}
+
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", raw)
+	}
+
+	return proxyURL, nil
+}
+
+// loadCABundle returns the system pool extended with the certificates in path
+func loadCABundle(path string) (*x509.CertPool, error) {
+	//nolint:gosec // G304: Reading user-configured CA bundle
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA bundle %s contains no valid PEM certificates", path)
+	}
+
+	return pool, nil
+}
+
+// tlsVersion maps a version string to its crypto/tls constant
+func tlsVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("invalid TLS minimum version %q (must be 1.2 or 1.3)", version)
+	}
+}
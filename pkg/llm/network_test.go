@@ -0,0 +1,207 @@
+package llm
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeServerCA writes the test server certificate as a PEM bundle
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0600))
+	return path
+}
+
+// TestNetworkConfig_Validate tests proxy, CA bundle and TLS option validation
+func TestNetworkConfig_Validate(t *testing.T) {
+	badCA := filepath.Join(t.TempDir(), "bad.pem")
+	require.NoError(t, os.WriteFile(badCA, []byte("not a certificate"), 0600))
+
+	tests := []struct {
+		name    string
+		network NetworkConfig
+		errMsg  string
+	}{
+		{name: "zero value", network: NetworkConfig{}},
+		{name: "http proxy", network: NetworkConfig{ProxyURL: "http://proxy.corp:3128"}},
+		{name: "socks proxy", network: NetworkConfig{ProxyURL: "socks5://127.0.0.1:1080"}},
+		{name: "tls 1.3", network: NetworkConfig{TLSMinVersion: "1.3"}},
+		{name: "unsupported proxy scheme", network: NetworkConfig{ProxyURL: "ftp://proxy:21"}, errMsg: "scheme must be"},
+		{name: "proxy without host", network: NetworkConfig{ProxyURL: "http://"}, errMsg: "missing host"},
+		{name: "missing CA bundle", network: NetworkConfig{CABundle: "/nonexistent/ca.pem"}, errMsg: "failed to read CA bundle"},
+		{name: "invalid CA bundle", network: NetworkConfig{CABundle: badCA}, errMsg: "no valid PEM certificates"},
+		{name: "invalid TLS version", network: NetworkConfig{TLSMinVersion: "1.0"}, errMsg: "invalid TLS minimum version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.network.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+// TestNewHTTPTransport_Options verifies the proxy and TLS settings are applied
+func TestNewHTTPTransport_Options(t *testing.T) {
+	transport, err := NewHTTPTransport(NetworkConfig{ProxyURL: "http://proxy.corp:3128", TLSMinVersion: "1.3"})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.anthropic.com/v1/messages", nil)
+	require.NoError(t, err)
+	proxyURL, err := transport.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.corp:3128", proxyURL.String())
+	assert.Equal(t, uint16(tls.VersionTLS13), transport.TLSClientConfig.MinVersion)
+
+	// Building a transport must not leak settings into the next one
+	plain, err := NewHTTPTransport(NetworkConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), plain.TLSClientConfig.MinVersion)
+}
+
+// TestNewHTTPClient_CustomCA verifies a private CA is trusted only when configured
+func TestNewHTTPClient_CustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	plain, err := NewHTTPClient(NetworkConfig{}, 5*time.Second)
+	require.NoError(t, err)
+	_, err = plain.Get(server.URL)
+	require.Error(t, err, "self-signed server must be rejected without the CA bundle")
+
+	trusting, err := NewHTTPClient(NetworkConfig{CABundle: writeServerCA(t, server)}, 5*time.Second)
+	require.NoError(t, err)
+	resp, err := trusting.Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+// TestCheckConnectivity_UsesProxy verifies connectivity checks go through the proxy
+func TestCheckConnectivity_UsesProxy(t *testing.T) {
+	var connectHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodConnect {
+			connectHost = r.Host
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+
+	err := CheckConnectivity(context.Background(), ProviderOpenAI, NetworkConfig{ProxyURL: proxy.URL})
+	require.Error(t, err)
+	assert.Equal(t, "api.openai.com:443", connectHost)
+
+	err = CheckConnectivity(context.Background(), Provider("unknown"), NetworkConfig{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported provider")
+}
+
+// TestPingAPIKey verifies auth headers and the classification of responses
+func TestPingAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		provider   Provider
+		header     string
+		status     int
+		wantErr    bool
+		invalidKey bool
+	}{
+		{name: "anthropic accepted", provider: ProviderAnthropic, header: "X-Api-Key", status: http.StatusOK},
+		{name: "openai accepted", provider: ProviderOpenAI, header: "Authorization", status: http.StatusOK},
+		{name: "google accepted", provider: ProviderGoogle, header: "X-Goog-Api-Key", status: http.StatusOK},
+		{name: "anthropic rejected", provider: ProviderAnthropic, header: "X-Api-Key", status: http.StatusUnauthorized, wantErr: true, invalidKey: true},
+		{name: "google malformed key", provider: ProviderGoogle, header: "X-Goog-Api-Key", status: http.StatusBadRequest, wantErr: true, invalidKey: true},
+		{name: "server error", provider: ProviderOpenAI, header: "Authorization", status: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotHeader string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotHeader = r.Header.Get(tt.header)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			original := providerEndpoints[tt.provider]
+			providerEndpoints[tt.provider] = server.URL
+			defer func() { providerEndpoints[tt.provider] = original }()
+
+			err := PingAPIKey(context.Background(), tt.provider, "test-key-1234567890abcdef", NetworkConfig{})
+			assert.Contains(t, gotHeader, "test-key-1234567890abcdef")
+
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.invalidKey, errors.Is(err, ErrInvalidAPIKey))
+		})
+	}
+}
+
+// TestNewClient_NetworkOptionsPerClient verifies each client sends through
+// its own proxy and leaves the process-wide transport alone
+func TestNewClient_NetworkOptionsPerClient(t *testing.T) {
+	newProxy := func(hosts *[]string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodConnect {
+				*hosts = append(*hosts, r.Host)
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+	}
+	var openaiHosts, googleHosts []string
+	openaiProxy := newProxy(&openaiHosts)
+	defer openaiProxy.Close()
+	googleProxy := newProxy(&googleHosts)
+	defer googleProxy.Close()
+
+	defaultTransport := http.DefaultTransport
+
+	anthropicCfg := batchTestConfig(ProviderAnthropic)
+	anthropicCfg.Network = NetworkConfig{InsecureSkipVerify: true}
+	_, err := NewClient(anthropicCfg)
+	require.NoError(t, err)
+
+	openaiCfg := batchTestConfig(ProviderOpenAI)
+	openaiCfg.Network = NetworkConfig{ProxyURL: openaiProxy.URL}
+	openaiClient, err := NewClient(openaiCfg)
+	require.NoError(t, err)
+
+	googleCfg := batchTestConfig(ProviderGoogle)
+	googleCfg.Network = NetworkConfig{ProxyURL: googleProxy.URL}
+	googleClient, err := NewClient(googleCfg)
+	require.NoError(t, err)
+
+	assert.True(t, http.DefaultTransport == defaultTransport, "http.DefaultTransport is not replaced")
+
+	_, err = openaiClient.Generate(context.Background(), "hello")
+	require.Error(t, err)
+	_, err = googleClient.Chat(context.Background(), []Message{{Role: "user", Content: "hello"}})
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"api.openai.com:443"}, openaiHosts)
+	assert.Equal(t, []string{"generativelanguage.googleapis.com:443"}, googleHosts)
+}
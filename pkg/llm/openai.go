@@ -2,8 +2,8 @@ package llm
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/dshills/langgraph-go/graph/model"
 	"github.com/dshills/langgraph-go/graph/model/openai"
@@ -12,17 +12,39 @@ import (
 // openaiClient implements the Client interface for OpenAI (GPT)
 type openaiClient struct {
 	baseClient
-	chatModel *openai.ChatModel
+	chatModel  model.ChatModel
+	httpClient *http.Client // Batch API, streaming and function calling requests
+	baseURL    string
 }
 
 // newOpenAIClient creates a new OpenAI client
 func newOpenAIClient(config Config) (*openaiClient, error) {
-	// Create langgraph-go OpenAI ChatModel
-	chatModel := openai.NewChatModel(config.APIKey, config.Model)
+	httpClient, err := NewHTTPClient(config.Network, config.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	baseURL := "https://api.openai.com/v1"
+
+	// Create langgraph-go OpenAI ChatModel, unless it has to send through
+	// the configured HTTP client
+	var chatModel model.ChatModel = openai.NewChatModel(config.APIKey, config.Model)
+	if !config.Network.IsZero() {
+		chatModel = &chatCompletionModel{
+			httpClient: httpClient,
+			endpoint:   bearerEndpoint(baseURL, config.APIKey),
+			input: openaiChatInput{
+				Model:               config.Model,
+				Temperature:         config.Temperature,
+				MaxCompletionTokens: config.MaxTokens,
+			},
+		}
+	}
 
 	return &openaiClient{
 		baseClient: baseClient{config: config},
 		chatModel:  chatModel,
+		httpClient: httpClient,
+		baseURL:    baseURL,
 	}, nil
 }
 
@@ -54,54 +76,23 @@ func (c *openaiClient) Generate(ctx context.Context, prompt string) (string, err
 	return result, nil
 }
 
-// GenerateStructured produces structured output based on a schema
+// GenerateStructured produces structured output based on a schema. The model
+// returns it through a forced call of the respond function, whose parameters
+// are schema; a response that does not match is answered with the violations
+// as the function's result until one does.
 func (c *openaiClient) GenerateStructured(ctx context.Context, prompt string, schema interface{}) (interface{}, error) {
-	// For structured output, we append schema information to the prompt
-	// and request JSON formatted response
-	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
-	if err != nil {
-		return nil, c.wrapError("generate_structured", fmt.Errorf("failed to marshal schema: %w", err))
-	}
-
-	structuredPrompt := fmt.Sprintf(`%s
-
-Please respond with valid JSON that matches this schema:
-%s
-
-Return ONLY the JSON, with no additional text or explanation.`, prompt, schemaJSON)
-
-	var result string
-
-	// Execute with retry logic
-	err = c.retry(ctx, "generate_structured", func() error {
-		// Create messages for the chat
-		messages := []model.Message{
-			{Role: model.RoleUser, Content: structuredPrompt},
-		}
-
-		// Call ChatModel
-		out, err := c.chatModel.Chat(ctx, messages, nil)
-		if err != nil {
-			return err
-		}
-
-		result = out.Text
-		return nil
-	})
-
+	output, err := c.callFunction(ctx, c.httpClient, bearerEndpoint(c.baseURL, c.config.APIKey), prompt, schema)
 	if err != nil {
 		return nil, c.wrapError("generate_structured", err)
 	}
-
-	// Parse the JSON response
-	var output interface{}
-	if err := json.Unmarshal([]byte(result), &output); err != nil {
-		return nil, c.wrapError("generate_structured", fmt.Errorf("failed to parse JSON response: %w", err))
-	}
-
 	return output, nil
 }
 
+// StructuredOutput implements StructuredOutputClient
+func (c *openaiClient) StructuredOutput() bool {
+	return true
+}
+
 // Chat processes a sequence of messages and returns the assistant's response
 func (c *openaiClient) Chat(ctx context.Context, messages []Message) (string, error) {
 	if len(messages) == 0 {
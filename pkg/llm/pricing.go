@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"sort"
+	"strings"
+)
+
+// Pricing is the list price of a model in USD per million tokens
+type Pricing struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// Cost returns the price of a call with the given token counts
+func (p Pricing) Cost(inputTokens, outputTokens int64) float64 {
+	return (float64(inputTokens)*p.InputPerMTok + float64(outputTokens)*p.OutputPerMTok) / 1_000_000
+}
+
+// modelPricing lists known model families; the longest matching prefix wins
+var modelPricing = map[string]Pricing{
+	"claude-opus":       {InputPerMTok: 15, OutputPerMTok: 75},
+	"claude-sonnet":     {InputPerMTok: 3, OutputPerMTok: 15},
+	"claude-3-5-sonnet": {InputPerMTok: 3, OutputPerMTok: 15},
+	"claude-haiku":      {InputPerMTok: 0.8, OutputPerMTok: 4},
+	"claude-3-5-haiku":  {InputPerMTok: 0.8, OutputPerMTok: 4},
+	"gpt-4o":            {InputPerMTok: 2.5, OutputPerMTok: 10},
+	"gpt-4o-mini":       {InputPerMTok: 0.15, OutputPerMTok: 0.6},
+	"gpt-4-turbo":       {InputPerMTok: 10, OutputPerMTok: 30},
+	"gpt-4":             {InputPerMTok: 30, OutputPerMTok: 60},
+	"gpt-3.5-turbo":     {InputPerMTok: 0.5, OutputPerMTok: 1.5},
+	"gemini-1.5-pro":    {InputPerMTok: 1.25, OutputPerMTok: 5},
+	"gemini-1.5-flash":  {InputPerMTok: 0.075, OutputPerMTok: 0.3},
+	"gemini-2.0-flash":  {InputPerMTok: 0.1, OutputPerMTok: 0.4},
+}
+
+// providerPricing is the fallback for models missing from modelPricing
+var providerPricing = map[Provider]Pricing{
+	ProviderAnthropic: {InputPerMTok: 3, OutputPerMTok: 15},
+	ProviderOpenAI:    {InputPerMTok: 2.5, OutputPerMTok: 10},
+	ProviderGoogle:    {InputPerMTok: 1.25, OutputPerMTok: 5},
+
+	// Azure OpenAI and Bedrock list the prices of the models they host
+	ProviderAzureOpenAI: {InputPerMTok: 2.5, OutputPerMTok: 10},
+	ProviderBedrock:     {InputPerMTok: 3, OutputPerMTok: 15},
+}
+
+// modelContextWindows lists the context window in tokens of known model
+// families; the longest matching prefix wins
+var modelContextWindows = map[string]int64{
+	"claude":           200_000,
+	"gpt-4o":           128_000,
+	"gpt-4-turbo":      128_000,
+	"gpt-4":            8_192,
+	"gpt-3.5-turbo":    16_385,
+	"gemini-1.5-pro":   2_000_000,
+	"gemini-1.5-flash": 1_000_000,
+	"gemini-2.0-flash": 1_000_000,
+}
+
+// PricingFor returns the list price for a model, falling back to the
+// provider's mid-tier price when the model is not known
+func PricingFor(provider Provider, model string) Pricing {
+	if pricing, ok := longestPrefixMatch(modelPricing, model); ok {
+		return pricing
+	}
+
+	return providerPricing[provider]
+}
+
+// ContextWindowFor returns the context window of a model in tokens, or 0
+// when the model is not known
+func ContextWindowFor(model string) int64 {
+	window, _ := longestPrefixMatch(modelContextWindows, model)
+	return window
+}
+
+// ModelFamilies returns the model name prefixes with known pricing, sorted
+func ModelFamilies() []string {
+	families := make([]string, 0, len(modelPricing))
+	for family := range modelPricing {
+		families = append(families, family)
+	}
+	sort.Strings(families)
+	return families
+}
+
+// bedrockRegionPrefixes are the geographies of Bedrock cross-region
+// inference profiles ("us.anthropic.claude-...")
+var bedrockRegionPrefixes = []string{"us.", "eu.", "apac.", "us-gov.", "global."}
+
+// bedrockVendorPrefixes are the vendors of Bedrock model IDs
+// ("anthropic.claude-...")
+var bedrockVendorPrefixes = []string{"anthropic.", "amazon.", "meta.", "mistral.", "cohere.", "ai21.", "deepseek."}
+
+// baseModelName returns model lowercased and, for a Bedrock model ID or
+// inference profile, without its geography and vendor, so that it matches
+// the model families of the vendor's own API
+func baseModelName(model string) string {
+	model = strings.ToLower(model)
+	for _, prefixes := range [][]string{bedrockRegionPrefixes, bedrockVendorPrefixes} {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(model, prefix) {
+				model = strings.TrimPrefix(model, prefix)
+				break
+			}
+		}
+	}
+	return model
+}
+
+// longestPrefixMatch returns the value of the longest key that prefixes model
+func longestPrefixMatch[V any](values map[string]V, model string) (V, bool) {
+	model = baseModelName(model)
+
+	best := ""
+	for prefix := range values {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	value, ok := values[best]
+	return value, ok && best != ""
+}
@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricingFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		model    string
+		want     Pricing
+	}{
+		{name: "known family", provider: ProviderAnthropic, model: "claude-sonnet-4-5", want: modelPricing["claude-sonnet"]},
+		{name: "longest prefix wins", provider: ProviderOpenAI, model: "gpt-4o-mini-2024-07-18", want: modelPricing["gpt-4o-mini"]},
+		{name: "case insensitive", provider: ProviderGoogle, model: "Gemini-1.5-Flash", want: modelPricing["gemini-1.5-flash"]},
+		{name: "unknown model falls back to provider", provider: ProviderOpenAI, model: "o9-preview", want: providerPricing[ProviderOpenAI]},
+		{name: "bedrock model ID", provider: ProviderBedrock, model: "anthropic.claude-sonnet-4-5-20250929-v1:0", want: modelPricing["claude-sonnet"]},
+		{name: "unknown provider", provider: "other", model: "custom", want: Pricing{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PricingFor(tt.provider, tt.model))
+		})
+	}
+}
+
+func TestPricing_Cost(t *testing.T) {
+	p := Pricing{InputPerMTok: 3, OutputPerMTok: 15}
+	assert.InDelta(t, 0.003+0.015, p.Cost(1000, 1000), 1e-12)
+	assert.Zero(t, p.Cost(0, 0))
+}
+
+func TestContextWindowFor(t *testing.T) {
+	assert.EqualValues(t, 200_000, ContextWindowFor("claude-sonnet-4-5"))
+	assert.EqualValues(t, 128_000, ContextWindowFor("gpt-4o-mini"))
+	assert.EqualValues(t, 8_192, ContextWindowFor("gpt-4-0613"))
+	assert.EqualValues(t, 200_000, ContextWindowFor("us.anthropic.claude-sonnet-4-5-20250929-v1:0"), "Bedrock IDs match the vendor's model")
+	assert.Zero(t, ContextWindowFor("o9-preview"))
+}
+
+func TestModelFamilies(t *testing.T) {
+	families := ModelFamilies()
+	assert.Len(t, families, len(modelPricing))
+	assert.IsIncreasing(t, families)
+	assert.Contains(t, families, "claude-sonnet")
+}
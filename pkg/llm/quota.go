@@ -0,0 +1,270 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// QuotaClass caps the requests in flight to a provider, or to one of its
+// models when Model is set
+type QuotaClass struct {
+	Provider    Provider
+	Model       string // Optional; a model class takes precedence over its provider's
+	MaxParallel int
+}
+
+// String names the class, e.g. "anthropic" or "openai/gpt-4o"
+func (q QuotaClass) String() string {
+	if q.Model == "" {
+		return string(q.Provider)
+	}
+	return string(q.Provider) + "/" + q.Model
+}
+
+// QuotaLimiter bounds concurrent requests per quota class. Clients in the same
+// class share its slots, so a second client for the same provider does not
+// double the provider's quota. A nil QuotaLimiter limits nothing.
+type QuotaLimiter struct {
+	mu       sync.Mutex
+	classes  map[string]QuotaClass
+	slots    map[string]chan struct{}
+	throttle *Throttle
+}
+
+// NewQuotaLimiter creates a limiter for classes, or nil when there are none
+func NewQuotaLimiter(classes []QuotaClass) *QuotaLimiter {
+	if len(classes) == 0 {
+		return nil
+	}
+	q := &QuotaLimiter{
+		classes: make(map[string]QuotaClass),
+		slots:   make(map[string]chan struct{}),
+	}
+	for _, class := range classes {
+		q.classes[strings.ToLower(class.String())] = class
+	}
+	return q
+}
+
+// Class returns the class that applies to a provider and model: the model's
+// class if configured, otherwise the provider's
+func (q *QuotaLimiter) Class(provider, model string) (QuotaClass, bool) {
+	if q == nil {
+		return QuotaClass{}, false
+	}
+	for _, key := range []string{provider + "/" + model, provider} {
+		if class, ok := q.classes[strings.ToLower(key)]; ok {
+			return class, true
+		}
+	}
+	return QuotaClass{}, false
+}
+
+// MaxParallel returns the cap for a provider and model, or 0 when no class applies
+func (q *QuotaLimiter) MaxParallel(provider, model string) int {
+	class, _ := q.Class(provider, model)
+	return class.MaxParallel
+}
+
+// WithThrottle returns a limiter that also holds every wrapped client's
+// requests to the throttle, including clients no class applies to. It creates
+// the limiter when q is nil.
+func (q *QuotaLimiter) WithThrottle(throttle *Throttle) *QuotaLimiter {
+	if q == nil {
+		q = &QuotaLimiter{
+			classes: make(map[string]QuotaClass),
+			slots:   make(map[string]chan struct{}),
+		}
+	}
+	q.throttle = throttle
+	return q
+}
+
+// Wrap returns client limited to its class's slots and the limiter's
+// throttle. Clients neither applies to are returned unchanged; prompt
+// caching, batch and tool use support are preserved.
+func (q *QuotaLimiter) Wrap(client Client) Client {
+	class, ok := q.Class(client.Provider(), client.Model())
+	if !ok && (q == nil || q.throttle == nil) {
+		return client
+	}
+
+	var slots chan struct{}
+	if ok {
+		key := strings.ToLower(class.String())
+		q.mu.Lock()
+		var exists bool
+		slots, exists = q.slots[key]
+		if !exists {
+			slots = make(chan struct{}, class.MaxParallel)
+			q.slots[key] = slots
+		}
+		q.mu.Unlock()
+	}
+
+	limited := &limitedClient{Client: client, slots: slots, throttle: q.throttle}
+	cacheable, isCacheable := client.(CacheableClient)
+	batch, isBatch := client.(BatchClient)
+	switch {
+	case isCacheable && isBatch:
+		return &limitedCacheableBatchClient{
+			limitedCacheableClient: &limitedCacheableClient{limitedClient: limited, cacheable: cacheable},
+			batch:                  batch,
+		}
+	case isCacheable:
+		return &limitedCacheableClient{limitedClient: limited, cacheable: cacheable}
+	case isBatch:
+		return &limitedBatchClient{limitedClient: limited, batch: batch}
+	}
+	return limited
+}
+
+// limitedClient holds a class slot, and the throttle's slot while it holds
+// requests back, for the duration of each request
+type limitedClient struct {
+	Client
+	slots    chan struct{} // Nil when no class applies
+	throttle *Throttle
+}
+
+// acquire waits for a free class slot, and for the throttle while it holds
+// requests back, giving up when ctx is done. It returns the function that
+// frees what it took.
+func (c *limitedClient) acquire(ctx context.Context) (func(), error) {
+	if c.slots != nil {
+		select {
+		case c.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	releaseThrottle, err := c.throttle.acquire(ctx)
+	if err != nil {
+		if c.slots != nil {
+			<-c.slots
+		}
+		return nil, err
+	}
+	return func() {
+		releaseThrottle()
+		if c.slots != nil {
+			<-c.slots
+		}
+	}, nil
+}
+
+// Generate produces text once a slot is free
+func (c *limitedClient) Generate(ctx context.Context, prompt string) (string, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	return c.Client.Generate(ctx, prompt)
+}
+
+// GenerateStructured produces structured output once a slot is free
+func (c *limitedClient) GenerateStructured(ctx context.Context, prompt string, schema interface{}) (interface{}, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return c.Client.GenerateStructured(ctx, prompt, schema)
+}
+
+// StructuredOutput reports whether the wrapped client validates structured
+// output
+func (c *limitedClient) StructuredOutput() bool {
+	return SupportsStructuredOutput(c.Client)
+}
+
+// Chat processes messages once a slot is free
+func (c *limitedClient) Chat(ctx context.Context, messages []Message) (string, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	return c.Client.Chat(ctx, messages)
+}
+
+// GenerateStream streams a response once a slot is free, holding the slot
+// until the stream ends. A client without streaming support generates the
+// whole response and writes it to w.
+func (c *limitedClient) GenerateStream(ctx context.Context, messages []CacheableMessage, w io.Writer) (int64, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	return streamResponse(ctx, c.Client, messages, w)
+}
+
+// EmitFiles requests files through tool use once a slot is free. A client
+// without tool use returns ErrToolUseUnsupported without taking a slot.
+func (c *limitedClient) EmitFiles(ctx context.Context, messages []CacheableMessage) ([]EmittedFile, error) {
+	emitter, ok := c.Client.(FileEmittingClient)
+	if !ok {
+		return nil, ErrToolUseUnsupported
+	}
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return emitter.EmitFiles(ctx, messages)
+}
+
+// limitedCacheableClient is a limitedClient whose client supports prompt caching
+type limitedCacheableClient struct {
+	*limitedClient
+	cacheable CacheableClient
+}
+
+// GenerateWithCache generates text with cacheable messages once a slot is free
+func (c *limitedCacheableClient) GenerateWithCache(ctx context.Context, messages []CacheableMessage) (string, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	return c.cacheable.GenerateWithCache(ctx, messages)
+}
+
+// GetCacheMetrics returns the wrapped client's cache metrics
+func (c *limitedCacheableClient) GetCacheMetrics() PromptCacheMetrics {
+	return c.cacheable.GetCacheMetrics()
+}
+
+// ResetCacheMetrics resets the wrapped client's cache metrics
+func (c *limitedCacheableClient) ResetCacheMetrics() {
+	c.cacheable.ResetCacheMetrics()
+}
+
+// limitedBatchClient is a limitedClient whose client supports batch jobs
+type limitedBatchClient struct {
+	*limitedClient
+	batch BatchClient
+}
+
+// GenerateBatch submits a batch job without taking a slot: batch jobs run
+// asynchronously against the provider's separate batch quota
+func (c *limitedBatchClient) GenerateBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error) {
+	return c.batch.GenerateBatch(ctx, requests)
+}
+
+// limitedCacheableBatchClient is a limitedCacheableClient whose client also
+// supports batch jobs
+type limitedCacheableBatchClient struct {
+	*limitedCacheableClient
+	batch BatchClient
+}
+
+// GenerateBatch submits a batch job without taking a slot
+func (c *limitedCacheableBatchClient) GenerateBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error) {
+	return c.batch.GenerateBatch(ctx, requests)
+}
@@ -0,0 +1,158 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowClient records the peak number of concurrent requests
+type slowClient struct {
+	provider, model string
+	inFlight, peak  *int32
+}
+
+func (c *slowClient) Generate(ctx context.Context, prompt string) (string, error) {
+	n := atomic.AddInt32(c.inFlight, 1)
+	defer atomic.AddInt32(c.inFlight, -1)
+	for {
+		peak := atomic.LoadInt32(c.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(c.peak, peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return prompt, nil
+}
+
+func (c *slowClient) GenerateStructured(ctx context.Context, prompt string, _ interface{}) (interface{}, error) {
+	return c.Generate(ctx, prompt)
+}
+
+func (c *slowClient) Chat(ctx context.Context, _ []Message) (string, error) {
+	return c.Generate(ctx, "")
+}
+
+func (c *slowClient) Provider() string { return c.provider }
+func (c *slowClient) Model() string    { return c.model }
+
+// cacheableSlowClient is a slowClient with prompt caching
+type cacheableSlowClient struct {
+	*slowClient
+}
+
+func (c *cacheableSlowClient) GenerateWithCache(ctx context.Context, _ []CacheableMessage) (string, error) {
+	return c.Generate(ctx, "cached")
+}
+func (c *cacheableSlowClient) GetCacheMetrics() PromptCacheMetrics {
+	return PromptCacheMetrics{CacheHits: 3}
+}
+func (c *cacheableSlowClient) ResetCacheMetrics() {}
+
+func newSlowClient(provider, model string) *slowClient {
+	return &slowClient{provider: provider, model: model, inFlight: new(int32), peak: new(int32)}
+}
+
+func TestQuotaLimiter_Class(t *testing.T) {
+	q := NewQuotaLimiter([]QuotaClass{
+		{Provider: ProviderAnthropic, MaxParallel: 8},
+		{Provider: ProviderOpenAI, Model: "GPT-4o", MaxParallel: 2},
+	})
+
+	assert.Equal(t, 8, q.MaxParallel("anthropic", "claude-sonnet-4-5"))
+	assert.Equal(t, 2, q.MaxParallel("openai", "gpt-4o"), "model classes match case-insensitively")
+	assert.Equal(t, 0, q.MaxParallel("openai", "gpt-4o-mini"), "no provider-wide openai class")
+
+	var none *QuotaLimiter
+	assert.Nil(t, NewQuotaLimiter(nil))
+	assert.Equal(t, 0, none.MaxParallel("anthropic", "m"))
+	client := newSlowClient("anthropic", "m")
+	assert.Same(t, Client(client), none.Wrap(client))
+}
+
+func TestQuotaLimiter_SharedCap(t *testing.T) {
+	q := NewQuotaLimiter([]QuotaClass{{Provider: ProviderAnthropic, MaxParallel: 2}})
+	first := newSlowClient("anthropic", "claude-sonnet-4-5")
+	second := &slowClient{provider: "anthropic", model: "claude-haiku-4-5", inFlight: first.inFlight, peak: first.peak}
+	clients := []Client{q.Wrap(first), q.Wrap(second)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(client Client) {
+			defer wg.Done()
+			_, err := client.Generate(context.Background(), "p")
+			assert.NoError(t, err)
+		}(clients[i%2])
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(first.peak), "both clients share the provider cap")
+}
+
+func TestQuotaLimiter_PreservesCaching(t *testing.T) {
+	q := NewQuotaLimiter([]QuotaClass{{Provider: ProviderAnthropic, MaxParallel: 1}})
+
+	wrapped := q.Wrap(&cacheableSlowClient{newSlowClient("anthropic", "m")})
+	cacheable, ok := wrapped.(CacheableClient)
+	require.True(t, ok)
+	response, err := cacheable.GenerateWithCache(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "cached", response)
+	assert.Equal(t, int64(3), cacheable.GetCacheMetrics().CacheHits)
+
+	_, ok = q.Wrap(newSlowClient("anthropic", "m")).(CacheableClient)
+	assert.False(t, ok)
+}
+
+func TestQuotaLimiter_ContextCancelled(t *testing.T) {
+	q := NewQuotaLimiter([]QuotaClass{{Provider: ProviderGoogle, MaxParallel: 1}})
+	client := q.Wrap(newSlowClient("google", "gemini"))
+
+	// Hold the only slot
+	limited := client.(*limitedClient)
+	release, err := limited.acquire(context.Background())
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.Chat(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestQuotaLimiter_Throttle(t *testing.T) {
+	var over atomic.Bool
+	throttle := NewThrottle("test", over.Load)
+
+	// The throttle applies to clients without a class too
+	var none *QuotaLimiter
+	q := none.WithThrottle(throttle)
+	client := newSlowClient("openai", "gpt-4o")
+	wrapped := q.Wrap(client)
+
+	var wg sync.WaitGroup
+	run := func() {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = wrapped.Generate(context.Background(), "p")
+			}()
+		}
+		wg.Wait()
+	}
+
+	run()
+	assert.Greater(t, atomic.LoadInt32(client.peak), int32(1))
+
+	over.Store(true)
+	atomic.StoreInt32(client.peak, 0)
+	run()
+	assert.Equal(t, int32(1), atomic.LoadInt32(client.peak), "one request at a time while over")
+}
@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"sort"
+	"sync"
+)
+
+// ProviderFactory creates a client for a validated configuration
+type ProviderFactory func(config Config) (Client, error)
+
+var (
+	providerFactories = make(map[Provider]ProviderFactory)
+	factoryMu         sync.RWMutex
+)
+
+func init() {
+	RegisterProvider(ProviderAnthropic, func(config Config) (Client, error) {
+		return asClient(newAnthropicClient(config))
+	})
+	RegisterProvider(ProviderOpenAI, func(config Config) (Client, error) {
+		return asClient(newOpenAIClient(config))
+	})
+	RegisterProvider(ProviderGoogle, func(config Config) (Client, error) {
+		return asClient(newGoogleClient(config))
+	})
+	RegisterProvider(ProviderOpenAICompatible, func(config Config) (Client, error) {
+		return asClient(newCompatibleClient(config))
+	})
+	RegisterProvider(ProviderAzureOpenAI, func(config Config) (Client, error) {
+		return asClient(newAzureClient(config))
+	})
+	RegisterProvider(ProviderBedrock, func(config Config) (Client, error) {
+		return asClient(newBedrockClient(config))
+	})
+}
+
+// RegisterProvider makes a provider available to NewClient under name,
+// replacing any factory registered before. Third-party providers register
+// themselves from an init function; their API key is optional and the
+// factory checks whatever else it needs.
+func RegisterProvider(name Provider, factory ProviderFactory) {
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	providerFactories[name] = factory
+}
+
+// RegisteredProviders returns the names of the registered providers, sorted
+func RegisteredProviders() []Provider {
+	factoryMu.RLock()
+	defer factoryMu.RUnlock()
+
+	names := make([]Provider, 0, len(providerFactories))
+	for name := range providerFactories {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// providerFactory returns the factory registered for name
+func providerFactory(name Provider) (ProviderFactory, bool) {
+	factoryMu.RLock()
+	defer factoryMu.RUnlock()
+	factory, ok := providerFactories[name]
+	return factory, ok
+}
+
+// asClient returns a built-in client as a Client, keeping a failed
+// construction from yielding a non-nil interface
+func asClient[C Client](client C, err error) (Client, error) {
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
@@ -0,0 +1,441 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	anthropicsdk "github.com/anthropics/anthropic-sdk-go"
+	"github.com/dshills/gocreator/internal/logctx"
+)
+
+// DefaultMaxRetryDelay is the longest wait between attempts when
+// RetryPolicy.MaxDelay is not set
+const DefaultMaxRetryDelay = 2 * time.Minute
+
+// RetryPolicy controls how failed requests are retried. Rate-limited (429),
+// overloaded (529 and other 5xx) and timed-out requests and dropped
+// connections are retried; other client errors, such as an invalid key or
+// request, fail at once.
+type RetryPolicy struct {
+	// MaxRetries is the number of attempts after the first
+	MaxRetries int
+
+	// Delay is the backoff before the first retry; it doubles on each
+	// further retry
+	Delay time.Duration
+
+	// MaxDelay caps the backoff and the wait a provider asks for in its
+	// rate-limit headers. A request the provider will not take for longer
+	// fails rather than stall the run. Defaults to DefaultMaxRetryDelay.
+	MaxDelay time.Duration
+}
+
+// RetryPolicy returns the retry settings of the config
+func (c Config) RetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxRetries: c.MaxRetries, Delay: c.RetryDelay, MaxDelay: c.MaxRetryDelay}
+}
+
+func (p RetryPolicy) maxDelay() time.Duration {
+	if p.MaxDelay <= 0 {
+		return DefaultMaxRetryDelay
+	}
+	return p.MaxDelay
+}
+
+// backoff returns the wait before retry n (from 0): the delay doubled n
+// times and capped, with the upper half jittered so clients that failed
+// together do not retry together
+func (p RetryPolicy) backoff(n int) time.Duration {
+	limit := p.maxDelay()
+	delay := max(p.Delay, 0)
+	for i := 0; i < n && delay < limit; i++ {
+		delay *= 2
+	}
+	delay = min(delay, limit)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// wait returns the wait before retry n after err: the backoff, or longer
+// when the provider said when to come back. It fails when the provider asks
+// for longer than MaxDelay.
+func (p RetryPolicy) wait(n int, err error) (time.Duration, error) {
+	delay := p.backoff(n)
+	after, ok := retryAfter(err, time.Now())
+	if !ok {
+		return delay, nil
+	}
+	if limit := p.maxDelay(); after > limit {
+		return 0, fmt.Errorf("provider asked to wait %v, longer than the %v retry limit: %w", after.Round(time.Second), limit, err)
+	}
+	return max(delay, after), nil
+}
+
+// HTTPError is an unsuccessful response from a provider API called over
+// plain HTTP. Its header carries the provider's rate-limit information.
+type HTTPError struct {
+	Request    string // e.g. "POST /chat/completions"
+	StatusCode int
+	Header     http.Header
+	Message    string // Start of the response body
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s: HTTP %d: %s", e.Request, e.StatusCode, e.Message)
+}
+
+// newHTTPError reads the start of an unsuccessful response into an HTTPError
+func newHTTPError(request string, resp *http.Response) *HTTPError {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return &HTTPError{
+		Request:    request,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Message:    strings.TrimSpace(string(message)),
+	}
+}
+
+// statusPattern finds the status code in the text of SDK errors that do not
+// expose it ("OpenAI API error: POST ...: 429 Too Many Requests",
+// "googleapi: Error 503: ...")
+var statusPattern = regexp.MustCompile(`(?:HTTP|Error|:) ([45]\d\d)\b`)
+
+// responseOf returns the HTTP status and header of the response an error
+// reports, or 0 when it reports none
+func responseOf(err error) (int, http.Header) {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode, httpErr.Header
+	}
+	var apiErr *anthropicsdk.Error
+	if errors.As(err, &apiErr) {
+		var header http.Header
+		if apiErr.Response != nil {
+			header = apiErr.Response.Header
+		}
+		return apiErr.StatusCode, header
+	}
+	if match := statusPattern.FindStringSubmatch(err.Error()); match != nil {
+		status, _ := strconv.Atoi(match[1])
+		return status, nil
+	}
+	return 0, nil
+}
+
+// retryable reports whether another attempt could succeed where err failed
+func retryable(err error) bool {
+	var final *noRetryError
+	if errors.As(err, &final) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	status, _ := responseOf(err)
+	switch {
+	case status == 0:
+		// No response: the connection failed or timed out
+		return true
+	case status == http.StatusRequestTimeout, status == http.StatusConflict, status == http.StatusTooManyRequests:
+		return true
+	default:
+		// 5xx, including Anthropic's 529 overloaded
+		return status >= 500
+	}
+}
+
+// rateLimitHeaders pairs the provider headers counting what is left of a
+// rate limit with the headers saying when it resets
+var rateLimitHeaders = []struct{ remaining, reset string }{
+	{"anthropic-ratelimit-requests-remaining", "anthropic-ratelimit-requests-reset"},
+	{"anthropic-ratelimit-tokens-remaining", "anthropic-ratelimit-tokens-reset"},
+	{"anthropic-ratelimit-input-tokens-remaining", "anthropic-ratelimit-input-tokens-reset"},
+	{"anthropic-ratelimit-output-tokens-remaining", "anthropic-ratelimit-output-tokens-reset"},
+	{"x-ratelimit-remaining-requests", "x-ratelimit-reset-requests"},
+	{"x-ratelimit-remaining-tokens", "x-ratelimit-reset-tokens"},
+}
+
+// retryAfter returns how long the provider asked to wait before the request
+// err failed is sent again: the Retry-After header or, for a rate-limited
+// request, the reset of each exhausted limit
+func retryAfter(err error, now time.Time) (time.Duration, bool) {
+	status, header := responseOf(err)
+	if header == nil {
+		return 0, false
+	}
+
+	var wait time.Duration
+	found := false
+	use := func(d time.Duration) {
+		wait = max(wait, d)
+		found = true
+	}
+
+	if ms, err := strconv.ParseFloat(header.Get("retry-after-ms"), 64); err == nil && ms >= 0 {
+		use(time.Duration(ms * float64(time.Millisecond)))
+	} else if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+			use(time.Duration(seconds * float64(time.Second)))
+		} else if at, err := http.ParseTime(value); err == nil {
+			use(max(at.Sub(now), 0))
+		}
+	}
+
+	if status == http.StatusTooManyRequests {
+		for _, h := range rateLimitHeaders {
+			if header.Get(h.remaining) != "0" {
+				continue
+			}
+			if d, ok := parseReset(header.Get(h.reset), now); ok {
+				use(d)
+			}
+		}
+	}
+	return wait, found
+}
+
+// parseReset reads a rate-limit reset header: an RFC 3339 time (Anthropic)
+// or a duration such as "6m0s" or "20ms" (OpenAI)
+func parseReset(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return d, true
+	}
+	return 0, false
+}
+
+// retryCall runs fn until it succeeds, fails in a way another attempt cannot
+// fix, or runs out of retries, waiting between attempts as policy directs
+func retryCall(ctx context.Context, policy RetryPolicy, provider, model, operation string, fn func() error) error {
+	var lastErr error
+	started := time.Now()
+
+	for attempt := 0; attempt <= policy.MaxRetries; attempt++ {
+		err := fn()
+		if err == nil {
+			logctx.Logger(ctx).Debug().
+				Str("provider", provider).
+				Str("model", model).
+				Str("operation", operation).
+				Dur("duration", time.Since(started)).
+				Msg("LLM call completed")
+			if attempt > 0 {
+				logctx.Logger(ctx).Info().
+					Str("provider", provider).
+					Str("operation", operation).
+					Int("attempt", attempt+1).
+					Msg("Operation succeeded after retry")
+			}
+			return nil
+		}
+
+		lastErr = err
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s canceled: %w", operation, ctx.Err())
+		}
+
+		// Don't retry a failure that repeating the request cannot fix
+		if !retryable(err) {
+			return fmt.Errorf("%s failed: %w", operation, err)
+		}
+
+		if attempt == policy.MaxRetries {
+			break
+		}
+
+		delay, err := policy.wait(attempt, err)
+		if err != nil {
+			return fmt.Errorf("%s failed: %w", operation, err)
+		}
+		status, _ := responseOf(lastErr)
+		logctx.Logger(ctx).Warn().
+			Err(lastErr).
+			Str("provider", provider).
+			Str("operation", operation).
+			Int("attempt", attempt+1).
+			Int("status", status).
+			Dur("retry_delay", delay).
+			Msg("Operation failed, retrying")
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("%s canceled during retry: %w", operation, ctx.Err())
+		}
+	}
+
+	return fmt.Errorf("%s failed after %d attempts: %w", operation, policy.MaxRetries+1, lastErr)
+}
+
+// selfRetrying is implemented by the built-in clients, which retry each
+// request under their config's RetryPolicy themselves
+type selfRetrying interface {
+	retryPolicy() RetryPolicy
+}
+
+// NewRetryingClient returns client with failed requests retried under
+// policy, for clients that do not retry on their own, such as those added
+// with RegisterProvider (NewClient applies it to them). Retries back off
+// exponentially with jitter and wait as long as the provider's rate-limit
+// headers ask. Prompt caching, batch, streaming and tool use support are
+// kept.
+func NewRetryingClient(client Client, policy RetryPolicy) Client {
+	retrying := &retryingClient{Client: client, policy: policy}
+	cacheable, isCacheable := client.(CacheableClient)
+	batch, isBatch := client.(BatchClient)
+	switch {
+	case isCacheable && isBatch:
+		return &retryingCacheableBatchClient{
+			retryingCacheableClient: &retryingCacheableClient{retryingClient: retrying, cacheable: cacheable},
+			batch:                   batch,
+		}
+	case isCacheable:
+		return &retryingCacheableClient{retryingClient: retrying, cacheable: cacheable}
+	case isBatch:
+		return &retryingBatchClient{retryingClient: retrying, batch: batch}
+	}
+	return retrying
+}
+
+// retryingClient is a Client whose requests are retried under a policy
+type retryingClient struct {
+	Client
+	policy RetryPolicy
+}
+
+func (c *retryingClient) retry(ctx context.Context, operation string, fn func() error) error {
+	return retryCall(ctx, c.policy, string(c.Provider()), c.Model(), operation, fn)
+}
+
+// Generate generates text, retrying failed requests
+func (c *retryingClient) Generate(ctx context.Context, prompt string) (string, error) {
+	var text string
+	err := c.retry(ctx, "generate", func() error {
+		var err error
+		text, err = c.Client.Generate(ctx, prompt)
+		return err
+	})
+	return text, err
+}
+
+// GenerateStructured generates structured output, retrying failed requests
+func (c *retryingClient) GenerateStructured(ctx context.Context, prompt string, schema interface{}) (interface{}, error) {
+	var result interface{}
+	err := c.retry(ctx, "generate_structured", func() error {
+		var err error
+		result, err = c.Client.GenerateStructured(ctx, prompt, schema)
+		return err
+	})
+	return result, err
+}
+
+// StructuredOutput reports whether the wrapped client validates structured
+// output
+func (c *retryingClient) StructuredOutput() bool {
+	return SupportsStructuredOutput(c.Client)
+}
+
+// Chat sends the conversation, retrying failed requests
+func (c *retryingClient) Chat(ctx context.Context, messages []Message) (string, error) {
+	var text string
+	err := c.retry(ctx, "chat", func() error {
+		var err error
+		text, err = c.Client.Chat(ctx, messages)
+		return err
+	})
+	return text, err
+}
+
+// GenerateStream streams the response, retrying until its first byte is
+// written
+func (c *retryingClient) GenerateStream(ctx context.Context, messages []CacheableMessage, w io.Writer) (int64, error) {
+	var written int64
+	err := c.retry(ctx, "generate_stream", func() error {
+		n, err := streamResponse(ctx, c.Client, messages, w)
+		written += n
+		if err != nil && written > 0 {
+			return &noRetryError{err: err}
+		}
+		return err
+	})
+	return written, err
+}
+
+// EmitFiles has the response's files written through tool calls, retrying
+// failed requests
+func (c *retryingClient) EmitFiles(ctx context.Context, messages []CacheableMessage) ([]EmittedFile, error) {
+	emitter, ok := c.Client.(FileEmittingClient)
+	if !ok {
+		return nil, ErrToolUseUnsupported
+	}
+	var files []EmittedFile
+	err := c.retry(ctx, "emit_files", func() error {
+		var err error
+		files, err = emitter.EmitFiles(ctx, messages)
+		return err
+	})
+	return files, err
+}
+
+// retryingCacheableClient is a retryingClient whose client supports prompt
+// caching
+type retryingCacheableClient struct {
+	*retryingClient
+	cacheable CacheableClient
+}
+
+// GenerateWithCache generates text with cacheable messages, retrying failed
+// requests
+func (c *retryingCacheableClient) GenerateWithCache(ctx context.Context, messages []CacheableMessage) (string, error) {
+	var text string
+	err := c.retry(ctx, "generate_with_cache", func() error {
+		var err error
+		text, err = c.cacheable.GenerateWithCache(ctx, messages)
+		return err
+	})
+	return text, err
+}
+
+// GetCacheMetrics returns the wrapped client's cache metrics
+func (c *retryingCacheableClient) GetCacheMetrics() PromptCacheMetrics {
+	return c.cacheable.GetCacheMetrics()
+}
+
+// ResetCacheMetrics resets the wrapped client's cache metrics
+func (c *retryingCacheableClient) ResetCacheMetrics() {
+	c.cacheable.ResetCacheMetrics()
+}
+
+// retryingBatchClient is a retryingClient whose client supports batch jobs
+type retryingBatchClient struct {
+	*retryingClient
+	batch BatchClient
+}
+
+// GenerateBatch submits a batch job without retrying it: a resubmitted job
+// would generate and bill every request again
+func (c *retryingBatchClient) GenerateBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error) {
+	return c.batch.GenerateBatch(ctx, requests)
+}
+
+// retryingCacheableBatchClient is a retryingCacheableClient whose client
+// also supports batch jobs
+type retryingCacheableBatchClient struct {
+	*retryingCacheableClient
+	batch BatchClient
+}
+
+// GenerateBatch submits a batch job without retrying it
+func (c *retryingCacheableBatchClient) GenerateBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error) {
+	return c.batch.GenerateBatch(ctx, requests)
+}
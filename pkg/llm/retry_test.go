@@ -0,0 +1,211 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompatibleClient_RetriesRateLimit(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, `{"error":{"message":"slow down"}}`, http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"done"}}]}`))
+	}))
+	defer server.Close()
+
+	cfg := compatibleTestConfig(server.URL)
+	cfg.MaxRetries = 2
+	cfg.RetryDelay = time.Millisecond
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+
+	text, err := client.Generate(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "done", text)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+}
+
+func TestCompatibleClient_RateLimitBeyondMaxDelay(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "3600")
+		http.Error(w, "daily quota exhausted", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	cfg := compatibleTestConfig(server.URL)
+	cfg.MaxRetries = 2
+	cfg.RetryDelay = time.Millisecond
+	cfg.MaxRetryDelay = time.Minute
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+
+	_, err = client.Generate(context.Background(), "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "provider asked to wait 1h0m0s, longer than the 1m0s retry limit")
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests), "a wait past the limit fails at once")
+
+	var httpErr *HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusTooManyRequests, httpErr.StatusCode)
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&HTTPError{StatusCode: http.StatusTooManyRequests}, true},
+		{&HTTPError{StatusCode: 529}, true},
+		{&HTTPError{StatusCode: http.StatusBadGateway}, true},
+		{&HTTPError{StatusCode: http.StatusRequestTimeout}, true},
+		{&HTTPError{StatusCode: http.StatusUnauthorized}, false},
+		{&HTTPError{StatusCode: http.StatusBadRequest}, false},
+		{errors.New("connection reset by peer"), true},
+		{errors.New("OpenAI API error: POST \"https://api.openai.com/v1/chat/completions\": 429 Too Many Requests"), true},
+		{errors.New("google API error: googleapi: Error 403: permission denied"), false},
+		{&noRetryError{err: errors.New("failed to write response")}, false},
+		{fmt.Errorf("chat: %w", context.Canceled), false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, retryable(tt.err), tt.err.Error())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	rateLimited := func(header http.Header) error {
+		return &HTTPError{StatusCode: http.StatusTooManyRequests, Header: header}
+	}
+
+	wait, ok := retryAfter(rateLimited(http.Header{"Retry-After": {"7"}}), now)
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, wait)
+
+	wait, ok = retryAfter(rateLimited(http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}), now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, wait)
+
+	wait, ok = retryAfter(rateLimited(http.Header{"Retry-After-Ms": {"250"}, "Retry-After": {"1"}}), now)
+	assert.True(t, ok)
+	assert.Equal(t, 250*time.Millisecond, wait, "retry-after-ms is the more precise")
+
+	wait, ok = retryAfter(rateLimited(http.Header{
+		"Anthropic-Ratelimit-Tokens-Remaining":   {"0"},
+		"Anthropic-Ratelimit-Tokens-Reset":       {now.Add(40 * time.Second).Format(time.RFC3339)},
+		"Anthropic-Ratelimit-Requests-Remaining": {"12"},
+		"Anthropic-Ratelimit-Requests-Reset":     {now.Add(time.Hour).Format(time.RFC3339)},
+	}), now)
+	assert.True(t, ok)
+	assert.Equal(t, 40*time.Second, wait, "only exhausted limits count")
+
+	wait, ok = retryAfter(rateLimited(http.Header{
+		"X-Ratelimit-Remaining-Requests": {"0"},
+		"X-Ratelimit-Reset-Requests":     {"6m0s"},
+		"Retry-After":                    {"2"},
+	}), now)
+	assert.True(t, ok)
+	assert.Equal(t, 6*time.Minute, wait, "the longest wait applies")
+
+	_, ok = retryAfter(&HTTPError{StatusCode: http.StatusServiceUnavailable, Header: http.Header{
+		"X-Ratelimit-Remaining-Requests": {"0"},
+		"X-Ratelimit-Reset-Requests":     {"6m0s"},
+	}}, now)
+	assert.False(t, ok, "reset headers only explain a 429")
+
+	_, ok = retryAfter(errors.New("connection refused"), now)
+	assert.False(t, ok)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{Delay: time.Second, MaxDelay: 5 * time.Second}
+	for i := 0; i < 100; i++ {
+		first := policy.backoff(0)
+		assert.GreaterOrEqual(t, first, 500*time.Millisecond)
+		assert.LessOrEqual(t, first, time.Second)
+
+		third := policy.backoff(2)
+		assert.GreaterOrEqual(t, third, 2*time.Second)
+		assert.LessOrEqual(t, third, 4*time.Second)
+
+		capped := policy.backoff(10)
+		assert.GreaterOrEqual(t, capped, 2500*time.Millisecond)
+		assert.LessOrEqual(t, capped, 5*time.Second)
+	}
+}
+
+// flakyClient fails its first requests with an error
+type flakyClient struct {
+	echoClient
+	failures int32
+	err      error
+	calls    int32
+}
+
+func (f *flakyClient) Generate(ctx context.Context, prompt string) (string, error) {
+	if atomic.AddInt32(&f.calls, 1) <= f.failures {
+		return "", f.err
+	}
+	return f.echoClient.Generate(ctx, prompt)
+}
+
+func TestNewRetryingClient(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, Delay: time.Millisecond}
+
+	overloaded := &flakyClient{failures: 2, err: &HTTPError{Request: "POST /v1/messages", StatusCode: 529, Message: "overloaded"}}
+	text, err := NewRetryingClient(overloaded, policy).Generate(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", text)
+	assert.EqualValues(t, 3, overloaded.calls)
+
+	unauthorized := &flakyClient{failures: 5, err: &HTTPError{Request: "POST /v1/messages", StatusCode: http.StatusUnauthorized, Message: "invalid x-api-key"}}
+	_, err = NewRetryingClient(unauthorized, policy).Generate(context.Background(), "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "generate failed: POST /v1/messages: HTTP 401: invalid x-api-key")
+	assert.EqualValues(t, 1, unauthorized.calls, "a fatal error is not retried")
+
+	down := &flakyClient{failures: 10, err: errors.New("connection refused")}
+	_, err = NewRetryingClient(down, policy).Generate(context.Background(), "hello")
+	assert.ErrorContains(t, err, "generate failed after 4 attempts: connection refused")
+	assert.EqualValues(t, 4, down.calls)
+
+	_, streams := NewRetryingClient(&echoClient{}, policy).(StreamingClient)
+	assert.True(t, streams, "clients without streaming fall back to one write")
+}
+
+func TestNewClient_WrapsRegisteredProviders(t *testing.T) {
+	flaky := &flakyClient{failures: 1, err: &HTTPError{StatusCode: http.StatusServiceUnavailable}}
+	RegisterProvider("flaky", func(Config) (Client, error) { return flaky, nil })
+	t.Cleanup(func() {
+		factoryMu.Lock()
+		delete(providerFactories, "flaky")
+		factoryMu.Unlock()
+	})
+
+	cfg := batchTestConfig("flaky")
+	cfg.APIKey = ""
+	cfg.MaxRetries = 1
+	cfg.RetryDelay = time.Millisecond
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+
+	text, err := client.Generate(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", text)
+	assert.EqualValues(t, 2, flaky.calls)
+}
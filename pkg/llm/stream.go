@@ -0,0 +1,407 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	anthropicsdk "github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// StreamingClient extends Client with responses written out as they are
+// generated (Anthropic, OpenAI, Google and OpenAI-compatible servers).
+// Streaming a large response to a file keeps it out of memory and leaves what
+// was received so far on disk when a run is interrupted.
+type StreamingClient interface {
+	Client
+
+	// GenerateStream writes the response to messages to w as it arrives and
+	// returns the number of bytes written. System messages carry cache
+	// control where the provider supports prompt caching. A request is
+	// retried until its first byte is written; after that an error leaves w
+	// holding a prefix of the response. The client timeout bounds the wait
+	// for each chunk rather than the whole response.
+	GenerateStream(ctx context.Context, messages []CacheableMessage, w io.Writer) (int64, error)
+}
+
+// noRetryError marks an error that retry returns without another attempt
+type noRetryError struct {
+	err error
+}
+
+func (e *noRetryError) Error() string { return e.err.Error() }
+
+func (e *noRetryError) Unwrap() error { return e.err }
+
+// streamResponse writes client's response to messages to w, streamed when
+// the client supports it and in one piece otherwise
+func streamResponse(ctx context.Context, client Client, messages []CacheableMessage, w io.Writer) (int64, error) {
+	var text string
+	var err error
+	switch client := client.(type) {
+	case StreamingClient:
+		return client.GenerateStream(ctx, messages, w)
+	case CacheableClient:
+		text, err = client.GenerateWithCache(ctx, messages)
+	default:
+		chat := make([]Message, len(messages))
+		for i, msg := range messages {
+			chat[i] = Message{Role: msg.Role, Content: msg.Content}
+		}
+		text, err = client.Chat(ctx, chat)
+	}
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.WriteString(w, text)
+	return int64(n), err
+}
+
+// errStreamIdle cancels a streaming request that received nothing for a full timeout
+var errStreamIdle = errors.New("no data received")
+
+// stream runs a streaming request attempt by attempt. fn calls write for each
+// chunk of text; the configured timeout is reset on every chunk.
+func (b *baseClient) stream(ctx context.Context, w io.Writer, fn func(ctx context.Context, write func(string) error) error) (int64, error) {
+	var written int64
+	err := b.retry(ctx, "generate_stream", func() error {
+		attemptCtx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		timeout := b.config.Timeout
+		var idle *time.Timer
+		if timeout > 0 {
+			idle = time.AfterFunc(timeout, func() { cancel(errStreamIdle) })
+			defer idle.Stop()
+		}
+
+		var writeErr error
+		err := fn(attemptCtx, func(text string) error {
+			if idle != nil {
+				idle.Reset(timeout)
+			}
+			n, err := io.WriteString(w, text)
+			written += int64(n)
+			if err != nil {
+				writeErr = err
+			}
+			return err
+		})
+		switch {
+		case err == nil:
+			return nil
+		case writeErr != nil:
+			return &noRetryError{err: fmt.Errorf("failed to write response: %w", writeErr)}
+		case errors.Is(context.Cause(attemptCtx), errStreamIdle) && ctx.Err() == nil:
+			err = fmt.Errorf("%w for %s", errStreamIdle, timeout)
+		}
+		if written > 0 {
+			return &noRetryError{err: err}
+		}
+		return err
+	})
+	return written, err
+}
+
+// streamHTTPClient returns a copy of client without an overall timeout, which
+// would cut off long responses; stream applies the timeout between chunks.
+func streamHTTPClient(client *http.Client) *http.Client {
+	if client == nil {
+		return nil
+	}
+	streaming := *client
+	streaming.Timeout = 0
+	return &streaming
+}
+
+// GenerateStream implements StreamingClient with the Messages streaming API
+func (c *anthropicClient) GenerateStream(ctx context.Context, messages []CacheableMessage, w io.Writer) (int64, error) {
+	if !c.config.EnableCaching {
+		uncached := make([]CacheableMessage, len(messages))
+		for i, msg := range messages {
+			uncached[i] = CacheableMessage{Role: msg.Role, Content: msg.Content}
+		}
+		messages = uncached
+	}
+	system, conversation := anthropicMessages(messages)
+	params := anthropicsdk.MessageNewParams{
+		Model:     anthropicsdk.Model(c.config.Model),
+		MaxTokens: int64(c.config.MaxTokens),
+		Messages:  conversation,
+	}
+	if len(system) > 0 {
+		params.System = system
+	}
+
+	var opts []option.RequestOption
+	if c.httpClient != nil {
+		opts = append(opts, option.WithHTTPClient(streamHTTPClient(c.httpClient)))
+	}
+
+	n, err := c.stream(ctx, w, func(ctx context.Context, write func(string) error) error {
+		stream := c.directClient.Messages.NewStreaming(ctx, params, opts...)
+		defer func() { _ = stream.Close() }()
+
+		for stream.Next() {
+			event := stream.Current()
+			switch event.Type {
+			case "message_start":
+				usage := event.Message.Usage
+				c.recordUsage(usage.CacheCreationInputTokens, usage.CacheReadInputTokens, usage.InputTokens, 0)
+			case "message_delta":
+				c.recordUsage(0, 0, 0, event.Usage.OutputTokens)
+			case "content_block_delta":
+				if event.Delta.Type == "text_delta" {
+					if err := write(event.Delta.Text); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return stream.Err()
+	})
+	if err != nil {
+		return n, c.wrapError("generate_stream", err)
+	}
+	return n, nil
+}
+
+// openaiStreamInput is a streaming chat completion request
+type openaiStreamInput struct {
+	openaiChatInput
+	Stream bool `json:"stream"`
+}
+
+// openaiStreamChunk is one server-sent event of a streaming chat completion
+type openaiStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// GenerateStream implements StreamingClient with streaming chat completions.
+// Cache control is ignored: OpenAI caches long prompt prefixes by itself.
+func (c *openaiClient) GenerateStream(ctx context.Context, messages []CacheableMessage, w io.Writer) (int64, error) {
+	input := openaiStreamInput{
+		openaiChatInput: openaiChatInput{
+			Model:               c.config.Model,
+			Temperature:         c.config.Temperature,
+			MaxCompletionTokens: c.config.MaxTokens,
+		},
+		Stream: true,
+	}
+	for _, msg := range messages {
+		input.Messages = append(input.Messages, map[string]string{"role": msg.Role, "content": msg.Content})
+	}
+	return c.streamChatCompletions(ctx, streamHTTPClient(c.httpClient), bearerEndpoint(c.baseURL, c.config.APIKey), input, w)
+}
+
+// streamChatCompletions posts a streaming chat completion request to an API
+// that follows OpenAI's
+func (b *baseClient) streamChatCompletions(ctx context.Context, httpClient *http.Client, endpoint chatEndpoint, input any, w io.Writer) (int64, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return 0, b.wrapError("generate_stream", err)
+	}
+
+	n, err := b.stream(ctx, w, func(ctx context.Context, write func(string) error) error {
+		req, err := endpoint(ctx, body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "text/event-stream")
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode >= 300 {
+			return newHTTPError("POST /chat/completions", resp)
+		}
+		return readOpenAIStream(resp.Body, write)
+	})
+	if err != nil {
+		return n, b.wrapError("generate_stream", err)
+	}
+	return n, nil
+}
+
+// readOpenAIStream passes the content of each event of a chat completion
+// stream to write, until the [DONE] event
+func readOpenAIStream(r io.Reader, write func(string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return nil
+		}
+
+		var chunk openaiStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("stream error: %s", chunk.Error.Message)
+		}
+		for _, choice := range chunk.Choices {
+			if err := write(choice.Delta.Content); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// googleInput is a generateContent request, streamed or not
+type googleInput struct {
+	SystemInstruction *googleContent         `json:"systemInstruction,omitempty"`
+	Contents          []googleContent        `json:"contents"`
+	GenerationConfig  googleGenerationConfig `json:"generationConfig"`
+}
+
+type googleContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []googlePart `json:"parts"`
+}
+
+type googlePart struct {
+	Text string `json:"text"`
+}
+
+type googleGenerationConfig struct {
+	Temperature     float64 `json:"temperature"`
+	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
+}
+
+// googleStreamChunk is a generateContent response, or one server-sent event
+// of a streaming call
+type googleStreamChunk struct {
+	Candidates []struct {
+		Content      googleContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// newGoogleInput builds a generateContent request. System messages become
+// the system instruction; cache control is ignored.
+func newGoogleInput(messages []CacheableMessage, config googleGenerationConfig) googleInput {
+	input := googleInput{GenerationConfig: config}
+	for _, msg := range messages {
+		part := googlePart{Text: msg.Content}
+		switch msg.Role {
+		case "system":
+			if input.SystemInstruction == nil {
+				input.SystemInstruction = &googleContent{}
+			}
+			input.SystemInstruction.Parts = append(input.SystemInstruction.Parts, part)
+		case "assistant":
+			input.Contents = append(input.Contents, googleContent{Role: "model", Parts: []googlePart{part}})
+		default:
+			input.Contents = append(input.Contents, googleContent{Role: "user", Parts: []googlePart{part}})
+		}
+	}
+	return input
+}
+
+// GenerateStream implements StreamingClient with streamGenerateContent
+func (c *googleClient) GenerateStream(ctx context.Context, messages []CacheableMessage, w io.Writer) (int64, error) {
+	input := newGoogleInput(messages, googleGenerationConfig{
+		Temperature:     c.config.Temperature,
+		MaxOutputTokens: c.config.MaxTokens,
+	})
+	body, err := json.Marshal(input)
+	if err != nil {
+		return 0, c.wrapError("generate_stream", err)
+	}
+	httpClient := streamHTTPClient(c.httpClient)
+	endpoint := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", c.baseURL, c.config.Model)
+
+	n, err := c.stream(ctx, w, func(ctx context.Context, write func(string) error) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("x-goog-api-key", c.config.APIKey)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode >= 300 {
+			return newHTTPError("POST :streamGenerateContent", resp)
+		}
+		return readGoogleStream(resp.Body, write)
+	})
+	if err != nil {
+		return n, c.wrapError("generate_stream", err)
+	}
+	return n, nil
+}
+
+// readGoogleStream passes the text of each event of a generateContent stream
+// to write. The stream has no end marker; it is complete once a candidate
+// reports why it finished.
+func readGoogleStream(r io.Reader, write func(string) error) error {
+	finished := false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+
+		var chunk googleStreamChunk
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("stream error: %s", chunk.Error.Message)
+		}
+		for _, candidate := range chunk.Candidates {
+			for _, part := range candidate.Content.Parts {
+				if err := write(part.Text); err != nil {
+					return err
+				}
+			}
+			finished = finished || candidate.FinishReason != ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if !finished {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	anthropicsdk "github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var streamMessages = []CacheableMessage{
+	{Role: "system", Content: "context", Cache: &CacheControl{Type: "ephemeral"}},
+	{Role: "user", Content: "generate main.go"},
+}
+
+func TestAnthropicClient_GenerateStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Stream bool `json:"stream"`
+			System []struct {
+				CacheControl *struct{} `json:"cache_control"`
+			} `json:"system"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.True(t, body.Stream)
+		if assert.Len(t, body.System, 1) {
+			assert.NotNil(t, body.System[0].CacheControl)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		events := []string{
+			`{"type":"message_start","message":{"id":"m","type":"message","role":"assistant","model":"claude","content":[],"usage":{"input_tokens":10,"cache_read_input_tokens":90,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"package "}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"main\n"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":4}}`,
+			`{"type":"message_stop"}`,
+		}
+		for _, event := range events {
+			var typed struct {
+				Type string `json:"type"`
+			}
+			assert.NoError(t, json.Unmarshal([]byte(event), &typed))
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typed.Type, event)
+		}
+	}))
+	defer server.Close()
+
+	cfg := batchTestConfig(ProviderAnthropic)
+	cfg.EnableCaching = true
+	client := &anthropicClient{
+		baseClient:   baseClient{config: cfg},
+		directClient: anthropicsdk.NewClient(option.WithAPIKey("test-key"), option.WithBaseURL(server.URL), option.WithMaxRetries(0)),
+	}
+
+	var out bytes.Buffer
+	n, err := client.GenerateStream(context.Background(), streamMessages, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", out.String())
+	assert.EqualValues(t, out.Len(), n)
+
+	metrics := client.GetCacheMetrics()
+	assert.EqualValues(t, 90, metrics.CacheReadTokens)
+	assert.EqualValues(t, 10, metrics.InputTokens)
+	assert.EqualValues(t, 4, metrics.OutputTokens)
+}
+
+func TestOpenAIClient_GenerateStream(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		// The first attempt fails before any data and is retried
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var body struct {
+			Stream   bool                `json:"stream"`
+			Messages []map[string]string `json:"messages"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.True(t, body.Stream)
+		assert.Equal(t, "system", body.Messages[0]["role"])
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"package \"}}]}\n\n")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"main\\n\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	cfg := batchTestConfig(ProviderOpenAI)
+	cfg.MaxRetries = 1
+	cfg.RetryDelay = time.Millisecond
+	client := &openaiClient{baseClient: baseClient{config: cfg}, httpClient: server.Client(), baseURL: server.URL}
+
+	var out bytes.Buffer
+	_, err := client.GenerateStream(context.Background(), streamMessages, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", out.String())
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+}
+
+func TestOpenAIClient_GenerateStreamCutOff(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"package \"}}]}\n\n")
+	}))
+	defer server.Close()
+
+	cfg := batchTestConfig(ProviderOpenAI)
+	cfg.MaxRetries = 2
+	cfg.RetryDelay = time.Millisecond
+	client := &openaiClient{baseClient: baseClient{config: cfg}, httpClient: server.Client(), baseURL: server.URL}
+
+	var out bytes.Buffer
+	n, err := client.GenerateStream(context.Background(), streamMessages, &out)
+	require.Error(t, err)
+	assert.Equal(t, "package ", out.String(), "the received prefix is kept")
+	assert.EqualValues(t, 8, n)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests), "a stream that wrote data is not retried")
+}
+
+func TestGoogleClient_GenerateStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/gemini-test:streamGenerateContent", r.URL.Path)
+		assert.Equal(t, "sse", r.URL.Query().Get("alt"))
+		assert.Equal(t, "test-key", r.Header.Get("x-goog-api-key"))
+
+		var body googleInput
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if assert.NotNil(t, body.SystemInstruction) {
+			assert.Equal(t, "context", body.SystemInstruction.Parts[0].Text)
+		}
+		if assert.Len(t, body.Contents, 1) {
+			assert.Equal(t, "user", body.Contents[0].Role)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"package \"}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"main\\n\"}]},\"finishReason\":\"STOP\"}]}\n\n")
+	}))
+	defer server.Close()
+
+	cfg := batchTestConfig(ProviderGoogle)
+	cfg.Model = "gemini-test"
+	client := &googleClient{baseClient: baseClient{config: cfg}, httpClient: server.Client(), baseURL: server.URL}
+
+	var out bytes.Buffer
+	n, err := client.GenerateStream(context.Background(), streamMessages, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", out.String())
+	assert.EqualValues(t, out.Len(), n)
+}
+
+func TestGoogleClient_GenerateStreamCutOff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"package \"}]}}]}\n\n")
+	}))
+	defer server.Close()
+
+	client := &googleClient{baseClient: baseClient{config: batchTestConfig(ProviderGoogle)}, httpClient: server.Client(), baseURL: server.URL}
+
+	var out bytes.Buffer
+	_, err := client.GenerateStream(context.Background(), streamMessages, &out)
+	require.Error(t, err, "a stream without a finish reason was cut off")
+	assert.Equal(t, "package ", out.String())
+}
+
+func TestBaseClient_StreamIdleTimeout(t *testing.T) {
+	cfg := batchTestConfig(ProviderOpenAI)
+	cfg.Timeout = 20 * time.Millisecond
+	client := &baseClient{config: cfg}
+
+	var out bytes.Buffer
+	_, err := client.stream(context.Background(), &out, func(ctx context.Context, write func(string) error) error {
+		// Chunks arriving within the timeout keep the stream alive
+		for i := 0; i < 3; i++ {
+			time.Sleep(10 * time.Millisecond)
+			if err := write("x"); err != nil {
+				return err
+			}
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.ErrorIs(t, err, errStreamIdle)
+	assert.Equal(t, "xxx", out.String())
+}
+
+func TestQuotaLimiter_WrapStreams(t *testing.T) {
+	limiter := NewQuotaLimiter([]QuotaClass{{Provider: ProviderGoogle, MaxParallel: 1}})
+
+	for name, client := range map[string]Client{
+		"chat":      newSlowClient("google", "gemini"),
+		"cacheable": &cacheableSlowClient{newSlowClient("google", "gemini")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			streaming, ok := limiter.Wrap(client).(StreamingClient)
+			require.True(t, ok)
+
+			var out bytes.Buffer
+			n, err := streaming.GenerateStream(context.Background(), streamMessages, &out)
+			require.NoError(t, err)
+			assert.EqualValues(t, out.Len(), n)
+			if name == "cacheable" {
+				assert.Equal(t, "cached", out.String(), "clients without streaming write the whole response")
+			}
+		})
+	}
+}
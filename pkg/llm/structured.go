@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StructuredOutputTool is the name of the tool through which the model
+// returns structured output on providers with tool use
+const StructuredOutputTool = "respond"
+
+// structuredOutputDescription tells the model how to use the respond tool
+const structuredOutputDescription = "Return your complete answer. The input must match the schema exactly: every required property present, no properties it does not declare."
+
+// maxStructuredAttempts is how many responses GenerateStructured reads
+// before giving up on output that violates its schema
+const maxStructuredAttempts = 3
+
+// SchemaValidator is a schema that checks JSON documents against itself, such
+// as a JSON Schema with a validator. GenerateStructured validates responses
+// against schemas that implement it and asks the model again, with the
+// violations, when a response does not match.
+type SchemaValidator interface {
+	Validate(data []byte) error
+}
+
+// StructuredOutputClient extends Client with the guarantee that
+// GenerateStructured returns parsed JSON matching its schema: the output is
+// checked against the schema, and the model asked again with the violations
+// until it matches. Anthropic and OpenAI deliver the output through a forced
+// tool call; other providers are asked for JSON in the prompt.
+type StructuredOutputClient interface {
+	Client
+
+	// StructuredOutput reports whether GenerateStructured validates its
+	// output, which wrapped clients only do when their client does
+	StructuredOutput() bool
+}
+
+// SupportsStructuredOutput reports whether client's GenerateStructured
+// returns output validated against its schema. Callers without that
+// guarantee generate text and parse it themselves.
+func SupportsStructuredOutput(client Client) bool {
+	structured, ok := client.(StructuredOutputClient)
+	return ok && structured.StructuredOutput()
+}
+
+// generateValidated asks for output matching schema until a response parses
+// and validates. turn sends the request and returns the raw JSON of the
+// response; given the problems of the previous response, it asks again in
+// the same conversation.
+func generateValidated(schema interface{}, turn func(problems string) ([]byte, error)) (interface{}, error) {
+	problems := ""
+	var invalid error
+	for attempt := 0; attempt < maxStructuredAttempts; attempt++ {
+		data, err := turn(problems)
+		if err != nil {
+			return nil, err
+		}
+		output, err := checkStructured(schema, data)
+		if err == nil {
+			return output, nil
+		}
+		invalid = err
+		problems = fmt.Sprintf("The output does not match the schema: %v\nRespond again with the complete output, correcting these problems.", err)
+	}
+	return nil, fmt.Errorf("output still violates the schema after %d attempts: %w", maxStructuredAttempts, invalid)
+}
+
+// truncatedCall returns the error of a tool or function call cut off at the
+// output token limit, named limit in the provider's API. The call's input is
+// incomplete JSON, and asking again with the same limit ends the same way,
+// so the error is not retried and the request fails until the limit is
+// raised.
+func truncatedCall(limit string, maxTokens int, output string) error {
+	return &noRetryError{err: fmt.Errorf("response reached %s (%d) before completing the %s", limit, maxTokens, output)}
+}
+
+// checkStructured parses a response's JSON and validates it against schema
+// when the schema can validate
+func checkStructured(schema interface{}, data []byte) (interface{}, error) {
+	var output interface{}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if validator, ok := schema.(SchemaValidator); ok {
+		if err := validator.Validate(data); err != nil {
+			return nil, err
+		}
+	}
+	return output, nil
+}
+
+// toolSchema returns schema as the JSON object of a tool's input, without
+// the keywords that only identify a schema document. Tool inputs are
+// objects, so the schema must describe one.
+func toolSchema(schema interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil || object["type"] != "object" {
+		return nil, fmt.Errorf("schema for structured output must describe a JSON object")
+	}
+	delete(object, "$schema")
+	delete(object, "$id")
+	delete(object, "title")
+	return object, nil
+}
+
+// structuredPrompt asks for JSON matching schema in the prompt, for
+// providers without tool use
+func structuredPrompt(prompt string, schema interface{}) (string, error) {
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	return fmt.Sprintf(`%s
+
+Please respond with valid JSON that matches this schema:
+%s
+
+Return ONLY the JSON, with no additional text or explanation.`, prompt, schemaJSON), nil
+}
+
+// jsonFromText strips the markdown fence a model may wrap JSON in
+func jsonFromText(text string) []byte {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+	}
+	return []byte(strings.TrimSpace(text))
+}
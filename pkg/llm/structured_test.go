@@ -0,0 +1,194 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nameSchema is the schema of {"name": "..."}, validating that name is set
+type nameSchema map[string]interface{}
+
+func newNameSchema() nameSchema {
+	return nameSchema{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"type":                 "object",
+		"properties":           map[string]interface{}{"name": map[string]string{"type": "string"}},
+		"required":             []string{"name"},
+		"additionalProperties": false,
+	}
+}
+
+func (s nameSchema) Validate(data []byte) error {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if _, ok := doc["name"].(string); !ok {
+		return errors.New(`/: missing required property "name"`)
+	}
+	return nil
+}
+
+func TestAnthropicClient_GenerateStructured(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content []struct {
+					Type    string `json:"type"`
+					ID      string `json:"id"`
+					IsError bool   `json:"is_error"`
+					Content []struct {
+						Text string `json:"text"`
+					} `json:"content"`
+				} `json:"content"`
+			} `json:"messages"`
+			Tools []struct {
+				Name        string                 `json:"name"`
+				InputSchema map[string]interface{} `json:"input_schema"`
+			} `json:"tools"`
+			ToolChoice struct {
+				Type string `json:"type"`
+				Name string `json:"name"`
+			} `json:"tool_choice"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if assert.Len(t, body.Tools, 1) {
+			assert.Equal(t, StructuredOutputTool, body.Tools[0].Name)
+			assert.Equal(t, "object", body.Tools[0].InputSchema["type"])
+			assert.Equal(t, false, body.Tools[0].InputSchema["additionalProperties"])
+			assert.NotContains(t, body.Tools[0].InputSchema, "$schema")
+		}
+		assert.Equal(t, "tool", body.ToolChoice.Type)
+		assert.Equal(t, StructuredOutputTool, body.ToolChoice.Name)
+
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&requests, 1) == 1 {
+			assert.Len(t, body.Messages, 1)
+			fmt.Fprint(w, `{"id":"m1","type":"message","role":"assistant","model":"claude","stop_reason":"tool_use",
+				"content":[{"type":"tool_use","id":"t1","name":"respond","input":{"title":"gocreator"}}],
+				"usage":{"input_tokens":10,"output_tokens":5}}`)
+			return
+		}
+
+		// The violations are the result of the first call
+		if assert.Len(t, body.Messages, 3) {
+			assert.Equal(t, "tool_use", body.Messages[1].Content[0].Type)
+			result := body.Messages[2].Content[0]
+			assert.Equal(t, "tool_result", result.Type)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].Text, `missing required property "name"`)
+		}
+		fmt.Fprint(w, `{"id":"m2","type":"message","role":"assistant","model":"claude","stop_reason":"tool_use",
+			"content":[{"type":"tool_use","id":"t2","name":"respond","input":{"name":"gocreator"}}],
+			"usage":{"input_tokens":20,"output_tokens":5}}`)
+	}))
+	defer server.Close()
+
+	client := emitTestClient(server)
+	output, err := client.GenerateStructured(context.Background(), "name the tool", newNameSchema())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "gocreator"}, output)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+	assert.EqualValues(t, 10, client.GetCacheMetrics().OutputTokens)
+}
+
+func TestOpenAIClient_GenerateStructured(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+
+		var body struct {
+			Messages []map[string]interface{} `json:"messages"`
+			Tools    []struct {
+				Type     string `json:"type"`
+				Function struct {
+					Name       string                 `json:"name"`
+					Parameters map[string]interface{} `json:"parameters"`
+				} `json:"function"`
+			} `json:"tools"`
+			ToolChoice struct {
+				Function struct {
+					Name string `json:"name"`
+				} `json:"function"`
+			} `json:"tool_choice"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if assert.Len(t, body.Tools, 1) {
+			assert.Equal(t, "function", body.Tools[0].Type)
+			assert.Equal(t, StructuredOutputTool, body.Tools[0].Function.Name)
+			assert.Equal(t, []interface{}{"name"}, body.Tools[0].Function.Parameters["required"])
+		}
+		assert.Equal(t, StructuredOutputTool, body.ToolChoice.Function.Name)
+
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&requests, 1) == 1 {
+			fmt.Fprint(w, `{"choices":[{"finish_reason":"stop","message":{"role":"assistant","content":null,
+				"tool_calls":[{"id":"call_1","type":"function","function":{"name":"respond","arguments":"{\"name\": 7}"}}]}}]}`)
+			return
+		}
+
+		if assert.Len(t, body.Messages, 3) {
+			assert.Equal(t, "assistant", body.Messages[1]["role"])
+			assert.NotEmpty(t, body.Messages[1]["tool_calls"])
+			assert.Equal(t, "tool", body.Messages[2]["role"])
+			assert.Equal(t, "call_1", body.Messages[2]["tool_call_id"])
+			assert.Contains(t, body.Messages[2]["content"], `missing required property "name"`)
+		}
+		fmt.Fprint(w, `{"choices":[{"finish_reason":"stop","message":{"role":"assistant","content":null,
+			"tool_calls":[{"id":"call_2","type":"function","function":{"name":"respond","arguments":"{\"name\": \"gocreator\"}"}}]}}]}`)
+	}))
+	defer server.Close()
+
+	client := &openaiClient{baseClient: baseClient{config: batchTestConfig(ProviderOpenAI)}, httpClient: server.Client(), baseURL: server.URL}
+	output, err := client.GenerateStructured(context.Background(), "name the tool", newNameSchema())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "gocreator"}, output)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&requests))
+}
+
+func TestCompatibleClient_GenerateStructuredGivesUp(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []map[string]string `json:"messages"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		n := atomic.AddInt32(&requests, 1)
+		assert.Len(t, body.Messages, int(2*n-1), "each attempt continues the conversation")
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"`+"```json\\n{\\\"title\\\": \\\"x\\\"}\\n```"+`"}}]}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(compatibleTestConfig(server.URL))
+	require.NoError(t, err)
+	_, err = client.GenerateStructured(context.Background(), "name the tool", newNameSchema())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "still violates the schema after 3 attempts")
+	assert.Contains(t, err.Error(), `missing required property "name"`, "the fenced JSON is parsed")
+	assert.EqualValues(t, maxStructuredAttempts, atomic.LoadInt32(&requests))
+}
+
+func TestSupportsStructuredOutput(t *testing.T) {
+	client, err := NewClient(compatibleTestConfig("http://localhost:11434"))
+	require.NoError(t, err)
+	q := NewQuotaLimiter([]QuotaClass{{Provider: ProviderOpenAICompatible, MaxParallel: 1}})
+
+	assert.True(t, SupportsStructuredOutput(client))
+	assert.True(t, SupportsStructuredOutput(NewCachedClient(q.Wrap(client), NewCache(CacheConfig{Enabled: true}))))
+	assert.False(t, SupportsStructuredOutput(NewRetryingClient(newSlowClient("custom", "model"), RetryPolicy{})),
+		"clients added with RegisterProvider parse their own output")
+}
@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Throttle sends requests one at a time, across every client it applies to,
+// while its condition holds, e.g. while the process is over a soft memory
+// limit. Requests in flight when the condition starts to hold finish
+// normally. A nil Throttle holds nothing back.
+type Throttle struct {
+	reason string
+	over   func() bool
+	slot   chan struct{}
+	active atomic.Bool
+}
+
+// NewThrottle creates a throttle that holds requests back while over
+// reports true; reason describes the condition in log messages
+func NewThrottle(reason string, over func() bool) *Throttle {
+	return &Throttle{reason: reason, over: over, slot: make(chan struct{}, 1)}
+}
+
+// acquire takes the throttle's single slot while the condition holds,
+// giving up when ctx is done, and returns the function that frees it
+func (t *Throttle) acquire(ctx context.Context) (func(), error) {
+	if t == nil {
+		return func() {}, nil
+	}
+
+	over := t.over()
+	if t.active.Swap(over) != over {
+		if over {
+			log.Warn().Str("reason", t.reason).Msg("Reducing LLM requests to one at a time")
+		} else {
+			log.Info().Str("reason", t.reason).Msg("Restoring parallel LLM requests")
+		}
+	}
+	if !over {
+		return func() {}, nil
+	}
+
+	select {
+	case t.slot <- struct{}{}:
+		return func() { <-t.slot }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
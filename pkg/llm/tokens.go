@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer counts the tokens a model reads for a text
+type Tokenizer interface {
+	CountTokens(text string) int64
+}
+
+// tokenEstimator approximates a BPE tokenizer without its vocabulary. It
+// splits text the way the tokenizer's pre-tokenizer does, into words,
+// numbers, whitespace and punctuation, and prices each run by how many
+// characters one token of that kind covers on average. For source code and
+// English prose the estimates run slightly above the real counts, the safe
+// side for deciding whether a request fits a context window.
+type tokenEstimator struct {
+	name string
+
+	// wordChars is the average number of letters per token in a word
+	wordChars int
+	// digitChars is the number of digits per token in a number
+	digitChars int
+	// spaceChars is the number of whitespace characters per token in
+	// indentation and blank lines
+	spaceChars int
+	// punctChars is the average number of punctuation characters per token
+	punctChars int
+}
+
+var (
+	// cl100kEstimator approximates cl100k_base (GPT-4, GPT-3.5)
+	cl100kEstimator = &tokenEstimator{name: "cl100k", wordChars: 7, digitChars: 3, spaceChars: 8, punctChars: 2}
+
+	// o200kEstimator approximates o200k_base (GPT-4o), whose larger
+	// vocabulary covers longer words
+	o200kEstimator = &tokenEstimator{name: "o200k", wordChars: 8, digitChars: 3, spaceChars: 8, punctChars: 2}
+
+	// claudeEstimator approximates the Claude tokenizer, which splits words
+	// and indentation finer than tiktoken
+	claudeEstimator = &tokenEstimator{name: "claude", wordChars: 5, digitChars: 3, spaceChars: 4, punctChars: 2}
+)
+
+// TokenizerFor returns the token estimator for a model: the Claude estimator
+// for Anthropic, and a tiktoken estimator for the encoding of OpenAI models.
+// Other providers publish no tokenizer and get the cl100k estimate. Bedrock
+// model IDs are matched by the vendor's model name.
+func TokenizerFor(provider Provider, model string) Tokenizer {
+	model = baseModelName(model)
+	switch {
+	case provider == ProviderAnthropic || strings.HasPrefix(model, "claude"):
+		return claudeEstimator
+	case strings.HasPrefix(model, "gpt-4o"), strings.HasPrefix(model, "o1"), strings.HasPrefix(model, "o3"):
+		return o200kEstimator
+	default:
+		return cl100kEstimator
+	}
+}
+
+// String returns the name of the encoding the estimator approximates
+func (e *tokenEstimator) String() string {
+	return e.name
+}
+
+// runeClass is the kind of run a character belongs to
+type runeClass int
+
+const (
+	classLetter runeClass = iota
+	classDigit
+	classSpace
+	classPunct
+	classOther // Non-ASCII characters outside letters, about one token each
+)
+
+func classify(r rune) runeClass {
+	switch {
+	case r < utf8.RuneSelf && (unicode.IsLetter(r) || r == '_'):
+		return classLetter
+	case unicode.IsDigit(r):
+		return classDigit
+	case unicode.IsSpace(r):
+		return classSpace
+	case r < utf8.RuneSelf:
+		return classPunct
+	case unicode.IsLetter(r) && r < 0x2E80:
+		// Accented Latin, Greek and Cyrillic letters join words
+		return classLetter
+	default:
+		return classOther
+	}
+}
+
+// CountTokens estimates the number of tokens in text
+func (e *tokenEstimator) CountTokens(text string) int64 {
+	var tokens int64
+	start := 0
+	prev := -1
+	for i, r := range text {
+		class := int(classify(r))
+		if class != prev || class == int(classOther) || e.wordBreak(text, start, i, r) {
+			if prev >= 0 {
+				tokens += e.runTokens(runeClass(prev), text[start:i])
+			}
+			start = i
+		}
+		prev = class
+	}
+	if prev >= 0 {
+		tokens += e.runTokens(runeClass(prev), text[start:])
+	}
+	return tokens
+}
+
+// wordBreak reports whether a word run ends before r: identifiers split at
+// an underscore and where a lower-case letter is followed by an upper-case
+// one, as BPE merges rarely cross those boundaries
+func (e *tokenEstimator) wordBreak(text string, start, i int, r rune) bool {
+	if i == start || classify(r) != classLetter {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(text[:i])
+	return r == '_' || (unicode.IsUpper(r) && unicode.IsLower(last))
+}
+
+// runTokens prices one run of characters of the same class
+func (e *tokenEstimator) runTokens(class runeClass, run string) int64 {
+	n := utf8.RuneCountInString(run)
+	switch class {
+	case classLetter:
+		return ceilDiv(n, e.wordChars)
+	case classDigit:
+		return ceilDiv(n, e.digitChars)
+	case classSpace:
+		// A single space joins the word after it
+		if run == " " {
+			return 0
+		}
+		return ceilDiv(n, e.spaceChars)
+	case classPunct:
+		return ceilDiv(n, e.punctChars)
+	default:
+		return int64(n)
+	}
+}
+
+func ceilDiv(n, d int) int64 {
+	return int64((n + d - 1) / d)
+}
@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizerFor(t *testing.T) {
+	assert.Equal(t, claudeEstimator, TokenizerFor(ProviderAnthropic, "claude-sonnet-4-5"))
+	assert.Equal(t, o200kEstimator, TokenizerFor(ProviderOpenAI, "gpt-4o-mini"))
+	assert.Equal(t, cl100kEstimator, TokenizerFor(ProviderOpenAI, "gpt-4-turbo"))
+	assert.Equal(t, cl100kEstimator, TokenizerFor(ProviderGoogle, "gemini-1.5-pro"))
+	assert.Equal(t, claudeEstimator, TokenizerFor(Provider("bedrock"), "claude-3-5-haiku"))
+	assert.Equal(t, claudeEstimator, TokenizerFor(ProviderBedrock, "anthropic.claude-3-5-haiku-20241022-v1:0"))
+	assert.Equal(t, o200kEstimator, TokenizerFor(ProviderAzureOpenAI, "gpt-4o"))
+}
+
+func TestTokenEstimator_CountTokens(t *testing.T) {
+	for _, e := range []*tokenEstimator{cl100kEstimator, o200kEstimator, claudeEstimator} {
+		assert.Zero(t, e.CountTokens(""), e.name)
+		assert.EqualValues(t, 1, e.CountTokens("hello"), e.name)
+		assert.EqualValues(t, 2, e.CountTokens("hello world"), e.name+": a space joins the next word")
+		assert.EqualValues(t, 4, e.CountTokens("1234567890"), e.name+": numbers split in groups of three digits")
+		assert.EqualValues(t, 3, e.CountTokens("日本語"), e.name+": one token per ideograph")
+	}
+
+	// Identifiers split at case changes and underscores
+	assert.EqualValues(t, 3, cl100kEstimator.CountTokens("maxRetryDelay"))
+	assert.EqualValues(t, 2, cl100kEstimator.CountTokens("max_tokens"))
+}
+
+func TestTokenEstimator_SourceCode(t *testing.T) {
+	source := strings.Repeat(`// Validate checks the request before it is sent
+func (r *Request) Validate() error {
+	if r.MaxTokens <= 0 {
+		return fmt.Errorf("max tokens must be positive, got: %d", r.MaxTokens)
+	}
+	return nil
+}
+
+`, 20)
+
+	tiktoken := cl100kEstimator.CountTokens(source)
+	claude := claudeEstimator.CountTokens(source)
+	// Go source runs at three to four and a half characters per token
+	assert.InDelta(t, float64(len(source))/3.75, float64(tiktoken), float64(len(source))/3.75*0.2)
+	assert.Greater(t, claude, tiktoken, "the Claude tokenizer splits code finer")
+	assert.LessOrEqual(t, o200kEstimator.CountTokens(source), tiktoken)
+}
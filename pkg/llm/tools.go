@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	anthropicsdk "github.com/anthropics/anthropic-sdk-go"
+)
+
+// EmitFileTool is the name of the tool through which a FileEmittingClient's
+// model delivers files
+const EmitFileTool = "emit_file"
+
+// emitFileDescription tells the model how to use the emit_file tool
+const emitFileDescription = "Write one complete file. Call it once per file, with the file's path relative to the project root and its full content exactly as it should be saved: no markdown fences, no commentary."
+
+// EmittedFile is a file the model delivered by calling the emit_file tool
+type EmittedFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// FileEmittingClient extends Client with tool use (Anthropic). The model must
+// deliver its output by calling the emit_file tool, so file contents arrive
+// verbatim instead of as text that may be wrapped in markdown, and one
+// response may hold several files.
+type FileEmittingClient interface {
+	Client
+
+	// EmitFiles sends messages and returns the files the model emitted, in
+	// order. System messages carry cache control as for GenerateWithCache.
+	// A response without any emit_file call is an error.
+	EmitFiles(ctx context.Context, messages []CacheableMessage) ([]EmittedFile, error)
+}
+
+// ErrToolUseUnsupported is returned by EmitFiles of wrapped clients whose
+// provider has no tool use; callers fall back to plain text generation
+var ErrToolUseUnsupported = errors.New("provider does not support tool use")
+
+// errNoFilesEmitted is returned when the model answered without calling emit_file
+var errNoFilesEmitted = errors.New("response did not call " + EmitFileTool)
+
+// EmitFiles implements FileEmittingClient with a forced emit_file tool call
+func (c *anthropicClient) EmitFiles(ctx context.Context, messages []CacheableMessage) ([]EmittedFile, error) {
+	if !c.config.EnableCaching {
+		uncached := make([]CacheableMessage, len(messages))
+		for i, msg := range messages {
+			uncached[i] = CacheableMessage{Role: msg.Role, Content: msg.Content}
+		}
+		messages = uncached
+	}
+	system, conversation := anthropicMessages(messages)
+	params := anthropicsdk.MessageNewParams{
+		Model:     anthropicsdk.Model(c.config.Model),
+		MaxTokens: int64(c.config.MaxTokens),
+		Messages:  conversation,
+		Tools: []anthropicsdk.ToolUnionParam{{OfTool: &anthropicsdk.ToolParam{
+			Name:        EmitFileTool,
+			Description: anthropicsdk.String(emitFileDescription),
+			InputSchema: anthropicsdk.ToolInputSchemaParam{
+				Properties: map[string]interface{}{
+					"path":    map[string]string{"type": "string", "description": "File path relative to the project root"},
+					"content": map[string]string{"type": "string", "description": "Complete file content"},
+				},
+				Required: []string{"path", "content"},
+			},
+		}}},
+		// Any tool: the model has to emit at least one file
+		ToolChoice: anthropicsdk.ToolChoiceUnionParam{OfAny: &anthropicsdk.ToolChoiceAnyParam{}},
+	}
+	if len(system) > 0 {
+		params.System = system
+	}
+
+	var files []EmittedFile
+	err := c.retry(ctx, "emit_files", func() error {
+		response, err := c.directClient.Messages.New(ctx, params)
+		if err != nil {
+			return err
+		}
+		c.recordUsage(response.Usage.CacheCreationInputTokens, response.Usage.CacheReadInputTokens,
+			response.Usage.InputTokens, response.Usage.OutputTokens)
+
+		if response.StopReason == anthropicsdk.StopReasonMaxTokens {
+			return truncatedCall("max_tokens", c.config.MaxTokens, "files")
+		}
+
+		files = files[:0]
+		for _, block := range response.Content {
+			if block.Type != "tool_use" || block.Name != EmitFileTool {
+				continue
+			}
+			var file EmittedFile
+			if err := json.Unmarshal(block.Input, &file); err != nil {
+				return fmt.Errorf("invalid %s input: %w", EmitFileTool, err)
+			}
+			files = append(files, file)
+		}
+		if len(files) == 0 {
+			return errNoFilesEmitted
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, c.wrapError("emit_files", err)
+	}
+	return files, nil
+}
@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	anthropicsdk "github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emitTestClient returns an Anthropic client talking to server, retrying twice
+func emitTestClient(server *httptest.Server) *anthropicClient {
+	cfg := batchTestConfig(ProviderAnthropic)
+	cfg.MaxRetries = 2
+	cfg.EnableCaching = true
+	return &anthropicClient{
+		baseClient:   baseClient{config: cfg},
+		directClient: anthropicsdk.NewClient(option.WithAPIKey("test-key"), option.WithBaseURL(server.URL), option.WithMaxRetries(0)),
+	}
+}
+
+func TestAnthropicClient_EmitFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Tools []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+			ToolChoice struct {
+				Type string `json:"type"`
+			} `json:"tool_choice"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if assert.Len(t, body.Tools, 1) {
+			assert.Equal(t, EmitFileTool, body.Tools[0].Name)
+		}
+		assert.Equal(t, "any", body.ToolChoice.Type)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"m","type":"message","role":"assistant","model":"claude","stop_reason":"tool_use",
+			"content":[
+				{"type":"text","text":"Writing both files."},
+				{"type":"tool_use","id":"t1","name":"emit_file","input":{"path":"user.go","content":"package models\n"}},
+				{"type":"tool_use","id":"t2","name":"emit_file","input":{"path":"user_test.go","content":"package models\n\nimport \"testing\"\n"}}
+			],
+			"usage":{"input_tokens":10,"output_tokens":20}}`))
+	}))
+	defer server.Close()
+
+	client := emitTestClient(server)
+	files, err := client.EmitFiles(context.Background(), streamMessages)
+	require.NoError(t, err)
+	assert.Equal(t, []EmittedFile{
+		{Path: "user.go", Content: "package models\n"},
+		{Path: "user_test.go", Content: "package models\n\nimport \"testing\"\n"},
+	}, files)
+	assert.EqualValues(t, 20, client.GetCacheMetrics().OutputTokens)
+}
+
+func TestAnthropicClient_EmitFilesTruncated(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"m","type":"message","role":"assistant","model":"claude","stop_reason":"max_tokens",
+			"content":[{"type":"tool_use","id":"t1","name":"emit_file","input":{"path":"user.go"}}],
+			"usage":{"input_tokens":10,"output_tokens":20}}`))
+	}))
+	defer server.Close()
+
+	_, err := emitTestClient(server).EmitFiles(context.Background(), streamMessages)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max_tokens")
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests), "a truncated response is not retried")
+}
+
+func TestLimitedClient_EmitFilesUnsupported(t *testing.T) {
+	q := NewQuotaLimiter([]QuotaClass{{Provider: ProviderOpenAI, MaxParallel: 1}})
+	client := q.Wrap(newSlowClient("openai", "gpt-4o"))
+	_, err := client.(FileEmittingClient).EmitFiles(context.Background(), streamMessages)
+	assert.ErrorIs(t, err, ErrToolUseUnsupported)
+}
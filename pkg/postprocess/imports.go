@@ -0,0 +1,190 @@
+package postprocess
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// stdlib maps the standard library packages the pipeline manages to their
+// package name. Imports of other packages are left alone: their names cannot
+// be known without loading them.
+var stdlib = map[string]string{
+	"bufio": "bufio", "bytes": "bytes", "cmp": "cmp", "context": "context",
+	"crypto/hmac": "hmac", "crypto/rand": "rand", "crypto/sha1": "sha1", "crypto/sha256": "sha256",
+	"crypto/sha512": "sha512", "crypto/subtle": "subtle", "crypto/tls": "tls", "crypto/x509": "x509",
+	"database/sql": "sql", "encoding/base64": "base64", "encoding/binary": "binary", "encoding/csv": "csv",
+	"encoding/hex": "hex", "encoding/json": "json", "encoding/xml": "xml", "errors": "errors",
+	"flag": "flag", "fmt": "fmt", "hash/fnv": "fnv", "html/template": "template",
+	"io": "io", "io/fs": "fs", "log": "log", "log/slog": "slog",
+	"maps": "maps", "math": "math", "math/big": "big", "math/rand": "rand",
+	"mime": "mime", "mime/multipart": "multipart", "net": "net", "net/http": "http",
+	"net/http/httptest": "httptest", "net/mail": "mail", "net/netip": "netip", "net/url": "url",
+	"os": "os", "os/exec": "exec", "os/signal": "signal", "path": "path",
+	"path/filepath": "filepath", "reflect": "reflect", "regexp": "regexp", "runtime": "runtime",
+	"slices": "slices", "sort": "sort", "strconv": "strconv", "strings": "strings",
+	"sync": "sync", "sync/atomic": "atomic", "syscall": "syscall", "testing": "testing",
+	"text/tabwriter": "tabwriter", "text/template": "template", "time": "time", "unicode": "unicode",
+	"unicode/utf8": "utf8",
+}
+
+// stdlibByName maps the package names of stdlib that only one of its
+// packages has to that package's path
+var stdlibByName = func() map[string]string {
+	byName := make(map[string]string)
+	ambiguous := make(map[string]bool)
+	for importPath, name := range stdlib {
+		if _, ok := byName[name]; ok {
+			ambiguous[name] = true
+		}
+		byName[name] = importPath
+	}
+	for name := range ambiguous {
+		delete(byName, name)
+	}
+	return byName
+}()
+
+// fixImports removes the standard library imports code does not use and
+// adds those it refers to without importing, then formats it
+func fixImports(path, code string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, code, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	used := packageRefs(file)
+	imported := make(map[string]bool)
+	var edits []edit
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			importPath, _ := strconv.Unquote(imp.Path.Value)
+			name, known := stdlib[importPath]
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			imported[name] = true
+			if !known || name == "_" || name == "." || used[name] {
+				continue
+			}
+			if gen.Lparen.IsValid() {
+				edits = append(edits, lineEdit(fset, code, imp.Pos(), imp.End()))
+			} else {
+				edits = append(edits, lineEdit(fset, code, gen.Pos(), gen.End()))
+			}
+		}
+	}
+
+	var missing []string
+	for name := range used {
+		if importPath, ok := stdlibByName[name]; ok && !imported[name] {
+			missing = append(missing, importPath)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		edits = append(edits, insertImports(fset, file, missing))
+	}
+
+	if len(edits) == 0 {
+		return code, nil
+	}
+	// Back to front, a removal before an insertion at the same offset
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start > edits[j].start
+		}
+		return edits[i].end > edits[j].end
+	})
+	for _, e := range edits {
+		code = code[:e.start] + e.text + code[e.end:]
+	}
+
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		return "", fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	return string(formatted), nil
+}
+
+// packageRefs returns the names used as the package of a qualified
+// identifier that resolve to no declaration in the file
+func packageRefs(file *ast.File) map[string]bool {
+	refs := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			//nolint:staticcheck // Obj is nil for identifiers declared outside the file
+			if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil {
+				refs[x.Name] = true
+			}
+		}
+		return true
+	})
+	return refs
+}
+
+// edit replaces code[start:end] with text
+type edit struct {
+	start, end int
+	text       string
+}
+
+// lineEdit removes the node spanning from to to, with its whole line when
+// nothing else is on it
+func lineEdit(fset *token.FileSet, code string, from, to token.Pos) edit {
+	start, end := fset.Position(from).Offset, fset.Position(to).Offset
+	lineStart := strings.LastIndexByte(code[:start], '\n') + 1
+	lineEnd := len(code)
+	if i := strings.IndexByte(code[end:], '\n'); i >= 0 {
+		lineEnd = end + i + 1
+	}
+	if strings.TrimSpace(code[lineStart:start]) == "" && strings.TrimSpace(strings.TrimSuffix(code[end:lineEnd], "\n")) == "" {
+		return edit{start: lineStart, end: lineEnd}
+	}
+	return edit{start: start, end: end}
+}
+
+// insertImports adds importPaths to the first import declaration of file, or
+// after the package clause when it has none
+func insertImports(fset *token.FileSet, file *ast.File, importPaths []string) edit {
+	quoted := make([]string, len(importPaths))
+	for i, importPath := range importPaths {
+		quoted[i] = strconv.Quote(importPath)
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Lparen.IsValid() {
+			offset := fset.Position(gen.Lparen).Offset + 1
+			return edit{start: offset, end: offset, text: "\n\t" + strings.Join(quoted, "\n\t")}
+		}
+		offset := fset.Position(gen.Pos()).Offset
+		return edit{start: offset, end: offset, text: importDecl(quoted) + "\n"}
+	}
+
+	offset := fset.Position(file.Name.End()).Offset
+	return edit{start: offset, end: offset, text: "\n\n" + importDecl(quoted)}
+}
+
+// importDecl returns an import declaration of the quoted paths
+func importDecl(quoted []string) string {
+	if len(quoted) == 1 {
+		return "import " + quoted[0]
+	}
+	return "import (\n\t" + strings.Join(quoted, "\n\t") + "\n)"
+}
@@ -0,0 +1,270 @@
+// Package postprocess cleans up the Go code a model returns before it is
+// written: stray markdown is stripped, the file is parsed, its package clause
+// is made to match the plan, registered AST transforms run, and standard
+// library imports are added or removed as the code needs them.
+package postprocess
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dshills/gocreator/internal/models"
+)
+
+// File is a parsed Go file handed to each transform. Transforms edit AST in
+// place; the pipeline prints it once they have all run.
+type File struct {
+	Path    string // Slash-separated, relative to the output directory
+	Package string // Package name the plan expects, "" when unknown
+	Fset    *token.FileSet
+	AST     *ast.File
+}
+
+// Transform rewrites a generated Go file. An error fails the file.
+type Transform func(file *File) error
+
+var (
+	transforms  = make(map[string]Transform)
+	transformMu sync.RWMutex
+)
+
+// Register makes a transform available to New under name, replacing any
+// transform registered before. Programs embedding the generator register
+// their transforms from an init function and select them by name in
+// postprocess.transforms.
+func Register(name string, transform Transform) {
+	transformMu.Lock()
+	defer transformMu.Unlock()
+	transforms[name] = transform
+}
+
+// Registered returns the names of the registered transforms, sorted
+func Registered() []string {
+	transformMu.RLock()
+	defer transformMu.RUnlock()
+
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SyntaxError reports a generated file that does not parse as Go
+type SyntaxError struct {
+	Path   string
+	Errors []models.CompilationError
+}
+
+func (e *SyntaxError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("generated file %s does not parse", e.Path)
+	}
+	first := e.Errors[0]
+	msg := fmt.Sprintf("generated file %s does not parse: %d:%d: %s", e.Path, first.Line, first.Column, first.Message)
+	if more := len(e.Errors) - 1; more > 0 {
+		msg += fmt.Sprintf(" (and %d more)", more)
+	}
+	return msg
+}
+
+// Pipeline post-processes generated Go files. The zero value and a nil
+// *Pipeline run the built-in steps only.
+type Pipeline struct {
+	names      []string
+	transforms []Transform
+}
+
+// New creates a pipeline running the named registered transforms, in order,
+// after the built-in steps that fix the package clause
+func New(names ...string) (*Pipeline, error) {
+	transformMu.RLock()
+	defer transformMu.RUnlock()
+
+	p := &Pipeline{}
+	for _, name := range names {
+		transform, ok := transforms[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown transform %q (registered: %s)", name, strings.Join(registeredLocked(), ", "))
+		}
+		p.names = append(p.names, name)
+		p.transforms = append(p.transforms, transform)
+	}
+	return p, nil
+}
+
+// registeredLocked returns the registered names; the caller holds transformMu
+func registeredLocked() []string {
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return []string{"none"}
+	}
+	return names
+}
+
+// Transforms returns the names of the pipeline's custom transforms, in order
+func (p *Pipeline) Transforms() []string {
+	if p == nil {
+		return nil
+	}
+	return p.names
+}
+
+// Process runs code, the content of the Go file at path, through the
+// pipeline and returns the formatted result:
+//
+//  1. markdown around the code is stripped when the code does not parse
+//  2. the file is parsed; a *SyntaxError is returned when it does not
+//  3. the package clause is renamed to pkg unless it already matches, the
+//     file is a test in pkg_test, or it is in package main, as commands may
+//     be anywhere; an empty pkg leaves it as it is
+//  4. the custom transforms run
+//  5. unused standard library imports are removed, and missing ones whose
+//     name is unambiguous are added
+func (p *Pipeline) Process(path, pkg, code string) (string, error) {
+	fset, file, err := parseGo(path, code)
+	if err != nil {
+		if stripped := stripMarkdown(code); stripped != code {
+			fset, file, err = parseGo(path, stripped)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	renamePackage(file, pkg, strings.HasSuffix(path, "_test.go"))
+
+	if p != nil {
+		f := &File{Path: path, Package: pkg, Fset: fset, AST: file}
+		for i, transform := range p.transforms {
+			if err := transform(f); err != nil {
+				return "", fmt.Errorf("transform %s on %s: %w", p.names[i], path, err)
+			}
+		}
+		fset, file = f.Fset, f.AST
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return "", fmt.Errorf("failed to print %s: %w", path, err)
+	}
+	return fixImports(path, buf.String())
+}
+
+// parseGo parses code, returning the parse errors as a *SyntaxError
+func parseGo(path, code string) (*token.FileSet, *ast.File, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, code, parser.ParseComments)
+	if err == nil {
+		return fset, file, nil
+	}
+
+	syntaxErr := &SyntaxError{Path: path}
+	var list scanner.ErrorList
+	if errors.As(err, &list) {
+		for _, e := range list {
+			syntaxErr.Errors = append(syntaxErr.Errors, models.CompilationError{
+				File:    path,
+				Line:    e.Pos.Line,
+				Column:  e.Pos.Column,
+				Message: e.Msg,
+			})
+		}
+	} else {
+		syntaxErr.Errors = []models.CompilationError{{File: path, Message: err.Error()}}
+	}
+	return nil, nil, syntaxErr
+}
+
+// stripMarkdown returns the Go code within a model response: the first
+// fenced block tagged go or untagged, or else the lines from the package
+// clause (with the comments above it) on, without fence lines
+func stripMarkdown(code string) string {
+	lines := strings.Split(code, "\n")
+
+	if block, ok := fencedBlock(lines); ok {
+		return block
+	}
+
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "package ") {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return code
+	}
+	for start > 0 && isCommentLine(lines[start-1]) {
+		start--
+	}
+
+	var kept []string
+	for _, line := range lines[start:] {
+		if !strings.HasPrefix(strings.TrimSpace(line), "```") {
+			kept = append(kept, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n")) + "\n"
+}
+
+// fencedBlock returns the content of the first ``` or ```go block in lines
+func fencedBlock(lines []string) (string, bool) {
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(trimmed, "```") {
+			continue
+		}
+		tag := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "```" {
+			end++
+		}
+		if end == len(lines) {
+			return "", false
+		}
+		if tag == "" || tag == "go" || tag == "golang" {
+			return strings.TrimSpace(strings.Join(lines[i+1:end], "\n")) + "\n", true
+		}
+		i = end
+	}
+	return "", false
+}
+
+// isCommentLine reports whether line is blank or part of a comment, as the
+// doc comment and build constraints above a package clause are
+func isCommentLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "/*") ||
+		strings.HasPrefix(trimmed, "*")
+}
+
+// renamePackage sets the package clause of file to pkg where it differs
+func renamePackage(file *ast.File, pkg string, test bool) {
+	name := file.Name.Name
+	switch {
+	case pkg == "" || name == pkg:
+		return
+	case test && name == pkg+"_test":
+		return
+	case name == "main":
+		return
+	}
+	file.Name.Name = pkg
+}
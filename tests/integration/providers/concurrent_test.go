@@ -34,7 +34,7 @@ func TestConcurrentProviderUsage_MultipleTasksParallel(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create registry
-	registry, err := providers.NewRegistryFromConfig(config)
+	registry, err := providers.NewRegistryFromConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer func() {
 		_ = registry.Shutdown(context.Background())
@@ -111,7 +111,7 @@ func TestConcurrentProviderUsage_RaceConditions(t *testing.T) {
 	config, err := providers.LoadConfig("../../../tests/fixtures/providers/valid_config.yaml")
 	require.NoError(t, err)
 
-	registry, err := providers.NewRegistryFromConfig(config)
+	registry, err := providers.NewRegistryFromConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer func() {
 		_ = registry.Shutdown(context.Background())
@@ -178,7 +178,7 @@ func TestConcurrentProviderUsage_LoadBalancing(t *testing.T) {
 	config, err := providers.LoadConfig("../../../tests/fixtures/providers/valid_config.yaml")
 	require.NoError(t, err)
 
-	registry, err := providers.NewRegistryFromConfig(config)
+	registry, err := providers.NewRegistryFromConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer func() {
 		_ = registry.Shutdown(context.Background())
@@ -226,7 +226,7 @@ func TestConcurrentProviderUsage_ContextCancellation(t *testing.T) {
 	config, err := providers.LoadConfig("../../../tests/fixtures/providers/valid_config.yaml")
 	require.NoError(t, err)
 
-	registry, err := providers.NewRegistryFromConfig(config)
+	registry, err := providers.NewRegistryFromConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer func() {
 		_ = registry.Shutdown(context.Background())
@@ -25,7 +25,7 @@ func TestFallbackBehavior_PrimaryToFallback(t *testing.T) {
 	config, err := providers.LoadConfig("../../../tests/fixtures/providers/fallback_config.yaml")
 	require.NoError(t, err)
 
-	registry, err := providers.NewRegistryFromConfig(config)
+	registry, err := providers.NewRegistryFromConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer func() {
 		_ = registry.Shutdown(context.Background())
@@ -72,7 +72,7 @@ func TestFallbackBehavior_DefaultProvider(t *testing.T) {
 	config, err := providers.LoadConfig("../../../tests/fixtures/providers/valid_config.yaml")
 	require.NoError(t, err)
 
-	registry, err := providers.NewRegistryFromConfig(config)
+	registry, err := providers.NewRegistryFromConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer func() {
 		_ = registry.Shutdown(context.Background())
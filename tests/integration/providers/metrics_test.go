@@ -27,7 +27,7 @@ func TestMetrics_EndToEndFlow(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create registry
-	registry, err := providers.NewRegistryFromConfig(config)
+	registry, err := providers.NewRegistryFromConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer func() {
 		_ = registry.Shutdown(context.Background())
@@ -120,7 +120,7 @@ func TestMetrics_TimeRangeFiltering(t *testing.T) {
 	config, err := providers.LoadConfig("../../../tests/fixtures/providers/valid_config.yaml")
 	require.NoError(t, err)
 
-	registry, err := providers.NewRegistryFromConfig(config)
+	registry, err := providers.NewRegistryFromConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer func() {
 		_ = registry.Shutdown(context.Background())
@@ -185,7 +185,7 @@ func TestMetrics_NoData(t *testing.T) {
 	config, err := providers.LoadConfig("../../../tests/fixtures/providers/valid_config.yaml")
 	require.NoError(t, err)
 
-	registry, err := providers.NewRegistryFromConfig(config)
+	registry, err := providers.NewRegistryFromConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer func() {
 		_ = registry.Shutdown(context.Background())
@@ -216,7 +216,7 @@ func TestMetrics_ProviderRoleIsolation(t *testing.T) {
 	config, err := providers.LoadConfig("../../../tests/fixtures/providers/valid_config.yaml")
 	require.NoError(t, err)
 
-	registry, err := providers.NewRegistryFromConfig(config)
+	registry, err := providers.NewRegistryFromConfig(context.Background(), config)
 	require.NoError(t, err)
 	defer func() {
 		_ = registry.Shutdown(context.Background())
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/dshills/gocreator/internal/models"
 	"github.com/dshills/gocreator/pkg/fsops"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -473,3 +474,54 @@ func TestConcurrentBoundaryChecks(t *testing.T) {
 		assert.NoFileExists(t, attackPath)
 	}
 }
+
+func TestProtectedPaths(t *testing.T) {
+	rootDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	ops, err := fsops.New(fsops.Config{
+		RootDir:        rootDir,
+		Logger:         fsops.NewMemoryLogger(),
+		ProtectedPaths: models.ProtectedPaths{"docs/adr/**", "scripts/**"},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	adr := filepath.Join(rootDir, "docs", "adr", "0001.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(adr), 0750))
+	require.NoError(t, os.WriteFile(adr, []byte("# Decision\n"), 0600))
+
+	// Reads stay allowed
+	content, err := ops.ReadFile(ctx, "docs/adr/0001.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Decision\n", content)
+
+	patch, err := ops.CreateFilePatch(ctx, "scripts/build.sh", "#!/bin/sh\n")
+	require.NoError(t, err)
+
+	for name, write := range map[string]func() error{
+		"write":        func() error { return ops.WriteFile(ctx, "scripts/build.sh", "x") },
+		"atomic write": func() error { return ops.AtomicWrite(ctx, "docs/adr/0001.md", "x") },
+		"delete":       func() error { return ops.DeleteFile(ctx, "docs/adr/0001.md") },
+		"patch":        func() error { return ops.ApplyPatch(ctx, patch) },
+		"patch backup": func() error { return ops.ApplyPatchWithBackup(ctx, patch) },
+		"validate":     func() error { return ops.ValidatePatch(ctx, patch) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := write()
+			require.Error(t, err)
+			assert.ErrorIs(t, err, fsops.ErrProtectedPath)
+		})
+	}
+
+	// The protected tree is untouched and unprotected paths still work
+	data, err := os.ReadFile(adr)
+	require.NoError(t, err)
+	assert.Equal(t, "# Decision\n", string(data))
+	_, err = os.Stat(filepath.Join(rootDir, "scripts"))
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, ops.WriteFile(ctx, "docs/guide.md", "guide"))
+
+	_, err = fsops.New(fsops.Config{RootDir: rootDir, ProtectedPaths: models.ProtectedPaths{"/abs/**"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+package unit
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/bundle"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	bundleBase    = "package app\n\nfunc Version() string { return \"1.0\" }\n"
+	bundleUpdated = "package app\n\nfunc Version() string { return \"1.1\" }\n"
+	bundleNew     = "package app\n\nfunc Name() string { return \"app\" }\n"
+)
+
+// newBundleFileOps creates FileOps over a temp dir seeded with files
+func newBundleFileOps(t *testing.T, files map[string]string) (fsops.FileOps, string) {
+	t.Helper()
+	root := t.TempDir()
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o750))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o600))
+	}
+
+	fileOps, err := fsops.New(fsops.Config{RootDir: root, Logger: fsops.NewMemoryLogger()})
+	require.NoError(t, err)
+	return fileOps, root
+}
+
+// buildTestBundle creates a bundle that modifies version.go and creates name.go
+func buildTestBundle(t *testing.T) []byte {
+	t.Helper()
+	ctx := context.Background()
+	fileOps, _ := newBundleFileOps(t, nil)
+
+	modify, err := fileOps.GeneratePatch(ctx, "internal/app/version.go", bundleBase, bundleUpdated)
+	require.NoError(t, err)
+	modify.BaseChecksum = fileOps.GenerateChecksum(bundleBase)
+
+	create, err := fileOps.GeneratePatch(ctx, "internal/app/name.go", "", bundleNew)
+	require.NoError(t, err)
+
+	unchanged := models.Patch{TargetFile: "go.mod"}
+
+	output := &models.GenerationOutput{
+		ID:      "output-1",
+		Patches: []models.Patch{modify, create, unchanged},
+		Files: []models.GeneratedFile{
+			{Path: "internal/app/version.go", Checksum: fileOps.GenerateChecksum(bundleUpdated)},
+			{Path: "internal/app/name.go", Checksum: fileOps.GenerateChecksum(bundleNew)},
+			{Path: "go.mod", Checksum: fileOps.GenerateChecksum("module app\n")},
+		},
+	}
+
+	b, err := bundle.New(output, "unified")
+	require.NoError(t, err)
+	require.Len(t, b.Manifest.Files, 2, "unchanged files are omitted")
+
+	var buf bytes.Buffer
+	require.NoError(t, b.Write(&buf))
+	return buf.Bytes()
+}
+
+func TestBundle_RoundTrip(t *testing.T) {
+	data := buildTestBundle(t)
+
+	b, err := bundle.Read(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, bundle.SchemaVersion, b.Manifest.SchemaVersion)
+	assert.Equal(t, "output-1", b.Manifest.OutputID)
+	assert.Equal(t, "unified", b.Manifest.PatchFormat)
+	require.Len(t, b.Manifest.Files, 2)
+	assert.Equal(t, "internal/app/name.go", b.Manifest.Files[0].Path)
+	assert.Empty(t, b.Manifest.Files[0].BaseChecksum)
+	assert.Equal(t, "patches/internal/app/version.go.patch", b.Manifest.Files[1].Patch)
+
+	diff, ok := b.Patch("internal/app/version.go")
+	require.True(t, ok)
+	assert.Contains(t, diff, "+func Version() string { return \"1.1\" }")
+}
+
+func TestBundle_ReadRejectsTampering(t *testing.T) {
+	data := buildTestBundle(t)
+
+	// Rewrite the archive with one patch altered
+	var tampered bytes.Buffer
+	tr := tar.NewReader(bytes.NewReader(data))
+	tw := tar.NewWriter(&tampered)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		if header.Name == "patches/internal/app/version.go.patch" {
+			content = bytes.ReplaceAll(content, []byte("1.1"), []byte("6.6"))
+		}
+		header.Size = int64(len(content))
+		require.NoError(t, tw.WriteHeader(header))
+		_, err = tw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	_, err := bundle.Read(&tampered)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+}
+
+func TestBundle_Check(t *testing.T) {
+	b, err := bundle.Read(bytes.NewReader(buildTestBundle(t)))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  map[string]bundle.Status
+	}{
+		{
+			name:  "clean base",
+			files: map[string]string{"internal/app/version.go": bundleBase},
+			want:  map[string]bundle.Status{"internal/app/version.go": bundle.StatusPending, "internal/app/name.go": bundle.StatusNew},
+		},
+		{
+			name:  "already applied",
+			files: map[string]string{"internal/app/version.go": bundleUpdated, "internal/app/name.go": bundleNew},
+			want:  map[string]bundle.Status{"internal/app/version.go": bundle.StatusApplied, "internal/app/name.go": bundle.StatusApplied},
+		},
+		{
+			name:  "drifted and unexpected files",
+			files: map[string]string{"internal/app/version.go": bundleBase + "// local fix\n", "internal/app/name.go": "package app\n"},
+			want:  map[string]bundle.Status{"internal/app/version.go": bundle.StatusConflict, "internal/app/name.go": bundle.StatusConflict},
+		},
+		{
+			name:  "missing base file",
+			files: nil,
+			want:  map[string]bundle.Status{"internal/app/version.go": bundle.StatusConflict, "internal/app/name.go": bundle.StatusNew},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileOps, _ := newBundleFileOps(t, tt.files)
+
+			results, err := b.Check(context.Background(), fileOps)
+			require.NoError(t, err)
+
+			got := make(map[string]bundle.Status, len(results))
+			for _, r := range results {
+				got[r.Path] = r.Status
+				if r.Status == bundle.StatusConflict {
+					assert.NotEmpty(t, r.Reason)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBundle_Apply(t *testing.T) {
+	b, err := bundle.Read(bytes.NewReader(buildTestBundle(t)))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("applies clean tree", func(t *testing.T) {
+		fileOps, root := newBundleFileOps(t, map[string]string{"internal/app/version.go": bundleBase})
+
+		results, err := b.Apply(ctx, fileOps, bundle.ApplyOptions{})
+		require.NoError(t, err)
+		for _, r := range results {
+			assert.Equal(t, bundle.StatusApplied, r.Status, r.Path)
+		}
+
+		assertFileContent(t, filepath.Join(root, "internal/app/version.go"), bundleUpdated)
+		assertFileContent(t, filepath.Join(root, "internal/app/name.go"), bundleNew)
+
+		// Re-applying is a no-op
+		_, err = b.Apply(ctx, fileOps, bundle.ApplyOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("conflict writes nothing", func(t *testing.T) {
+		drifted := "// header\n" + bundleBase
+		fileOps, root := newBundleFileOps(t, map[string]string{"internal/app/version.go": drifted})
+
+		_, err := b.Apply(ctx, fileOps, bundle.ApplyOptions{})
+		var conflictErr *bundle.ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		require.Len(t, conflictErr.Conflicts, 1)
+		assert.Equal(t, "internal/app/version.go", conflictErr.Conflicts[0].Path)
+
+		assertFileContent(t, filepath.Join(root, "internal/app/version.go"), drifted)
+		assert.NoFileExists(t, filepath.Join(root, "internal/app/name.go"))
+	})
+
+	t.Run("force relocates hunks onto drifted file", func(t *testing.T) {
+		drifted := "// header\n" + bundleBase
+		fileOps, root := newBundleFileOps(t, map[string]string{"internal/app/version.go": drifted})
+
+		_, err := b.Apply(ctx, fileOps, bundle.ApplyOptions{Force: true})
+		require.NoError(t, err)
+		assertFileContent(t, filepath.Join(root, "internal/app/version.go"), "// header\n"+bundleUpdated)
+	})
+}
+
+func assertFileContent(t *testing.T, path, want string) {
+	t.Helper()
+	//nolint:gosec // G304: Reading test fixture
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+}
@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCheckpointEngine(t *testing.T, outputDir string) generate.Engine {
+	t.Helper()
+	fileOps, err := fsops.New(fsops.Config{RootDir: outputDir, Logger: &noopFsLogger{}})
+	require.NoError(t, err)
+
+	engine, err := generate.NewEngine(generate.EngineConfig{
+		LLMClient: &mockEngineLLMClient{
+			planResponse: `{
+				"file_tree": {"root": "` + outputDir + `", "files": [{"path": "main.go", "generated_by": "gen_main"}]},
+				"phases": [{"name": "setup", "order": 1, "tasks": [{"id": "gen_main", "type": "generate_file", "target_path": "main.go"}]}]
+			}`,
+			codeResponse: "package main\n\nfunc main() {}\n",
+			testResponse: "package main\n",
+		},
+		FileOps:     fileOps,
+		RecordState: true,
+		Checkpoint:  true,
+	})
+	require.NoError(t, err)
+	return engine
+}
+
+func TestEngine_ResumeFromCheckpoint(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "project")
+	engine := newCheckpointEngine(t, outputDir)
+
+	output, err := engine.Generate(context.Background(), createCompleteTestFCS(), outputDir)
+	require.NoError(t, err)
+	runID := output.RunID
+
+	// A finished run has nothing left to resume
+	checkpoint, err := generate.LoadRunCheckpoint(outputDir, runID)
+	require.NoError(t, err)
+	assert.Equal(t, "end", checkpoint.Node)
+	assert.Empty(t, checkpoint.ResumeNode())
+	_, err = engine.Resume(context.Background(), runID, outputDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already finished")
+
+	// Interrupt the run after planning
+	transitions, err := generate.LoadStateTransitions(outputDir, runID)
+	require.NoError(t, err)
+	require.Equal(t, "create_plan", transitions[3].Node)
+	interrupted := &generate.RunCheckpoint{
+		RunID: runID,
+		Node:  "create_plan",
+		State: generate.NewStateSnapshot(generate.ReplayState(transitions, 3)),
+	}
+	require.NoError(t, interrupted.Save(outputDir))
+	require.NoError(t, os.Remove(filepath.Join(outputDir, "main.go")))
+	assert.Equal(t, "generate", interrupted.ResumeNode())
+
+	resumed, err := engine.Resume(context.Background(), runID, outputDir)
+	require.NoError(t, err)
+	assert.Equal(t, runID, resumed.RunID)
+	assert.FileExists(t, filepath.Join(outputDir, "main.go"))
+
+	// The state log continues from the resumed state
+	transitions, err = generate.LoadStateTransitions(outputDir, runID)
+	require.NoError(t, err)
+	var nodes []string
+	for i, tr := range transitions {
+		assert.Equal(t, i, tr.Seq)
+		nodes = append(nodes, tr.Node)
+	}
+	assert.Equal(t, []string{
+		generate.ResumedStateNode, "generate_packages", "generate_tests", "generate_config", "generate_docs", "apply_patches", "end",
+	}, nodes[len(nodes)-7:])
+
+	_, err = generate.LoadFinishedRun(outputDir, runID)
+	assert.NoError(t, err)
+
+	checkpoint, err = generate.LoadRunCheckpoint(outputDir, runID)
+	require.NoError(t, err)
+	assert.Equal(t, "end", checkpoint.Node)
+}
+
+func TestRunCheckpoint_ResumeNode(t *testing.T) {
+	// A node that failed runs again
+	failed := generate.RunCheckpoint{Node: "generate", State: generate.StateSnapshot{Error: "rate limited"}}
+	assert.Equal(t, "generate", failed.ResumeNode())
+
+	finished := generate.RunCheckpoint{Node: "start"}
+	assert.Equal(t, "analyze_fcs", finished.ResumeNode())
+
+	// Only the run that planned asks for approval
+	planned := generate.RunCheckpoint{Node: "create_plan"}
+	assert.Equal(t, "generate", planned.ResumeNode())
+	aborted := generate.RunCheckpoint{Node: "approve_plan", State: generate.StateSnapshot{Error: "plan not approved"}}
+	assert.Equal(t, "approve_plan", aborted.ResumeNode())
+
+	_, err := generate.LoadRunCheckpoint(t.TempDir(), "gen-missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no checkpoint")
+}
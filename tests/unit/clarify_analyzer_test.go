@@ -2,6 +2,7 @@ package unit
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/dshills/gocreator/internal/clarify"
@@ -39,6 +40,28 @@ func (m *MockLLMClient) Model() string {
 	return "mock-model"
 }
 
+// structuredLLMClient answers GenerateStructured with a fixed document,
+// validated against the requested schema like a client with structured output
+type structuredLLMClient struct {
+	MockLLMClient
+	output  string
+	schemas []interface{}
+}
+
+func (m *structuredLLMClient) GenerateStructured(ctx context.Context, prompt string, schema interface{}) (interface{}, error) {
+	m.schemas = append(m.schemas, schema)
+	if err := schema.(llm.SchemaValidator).Validate([]byte(m.output)); err != nil {
+		return nil, err
+	}
+	var output interface{}
+	err := json.Unmarshal([]byte(m.output), &output)
+	return output, err
+}
+
+func (m *structuredLLMClient) StructuredOutput() bool {
+	return true
+}
+
 func TestLLMAnalyzer_Analyze(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -170,3 +193,25 @@ func TestGroupAmbiguities(t *testing.T) {
 	assert.Equal(t, 1, len(groups["missing_constraint"]))
 	assert.Equal(t, 1, len(groups["unclear_requirement"]))
 }
+
+func TestLLMAnalyzer_AnalyzeStructured(t *testing.T) {
+	client := &structuredLLMClient{output: `{"ambiguities": [
+		{"type": "conflict", "location": "FR-002", "description": "Deletes are both soft and hard", "severity": "critical"},
+		{"type": "missing_constraint", "description": "No rate limit"}
+	]}`}
+	// Text responses are not parsed
+	client.GenerateFunc = func(ctx context.Context, prompt string) (string, error) {
+		return "not JSON", nil
+	}
+
+	ambiguities, err := clarify.NewLLMAnalyzer(client).Analyze(context.Background(), &models.InputSpecification{ID: "spec-1", Content: "Build a store"})
+	require.NoError(t, err)
+	require.Len(t, ambiguities, 2)
+	assert.Equal(t, "conflict", ambiguities[0].Type)
+	assert.Equal(t, "important", ambiguities[1].Severity, "a missing severity still defaults")
+	require.Len(t, client.schemas, 1)
+
+	client.output = `{"ambiguities": [{"type": "typo", "description": "Misspelled entity"}]}`
+	_, err = clarify.NewLLMAnalyzer(client).Analyze(context.Background(), &models.InputSpecification{ID: "spec-1", Content: "Build a store"})
+	assert.ErrorContains(t, err, `/ambiguities/0/type: must be one of`)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/dshills/gocreator/internal/clarify"
 	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/spec"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -397,3 +398,127 @@ func TestClarificationResponse_ValidateAgainst(t *testing.T) {
 func ptrString(s string) *string {
 	return &s
 }
+
+func TestEngine_HTTPFrameworkQuestion(t *testing.T) {
+	mockClient := &MockLLMClient{
+		GenerateFunc: func(ctx context.Context, prompt string) (string, error) {
+			return `[]`, nil
+		},
+	}
+
+	engine, err := clarify.NewEngine(clarify.EngineConfig{LLMClient: mockClient})
+	require.NoError(t, err)
+
+	spec := &models.InputSpecification{
+		ID:      "spec-1",
+		Format:  models.FormatMarkdown,
+		Content: "Build a REST API exposing GET /users",
+		State:   models.SpecStateValid,
+	}
+
+	ctx := context.Background()
+	request, err := engine.GenerateRequest(ctx, spec)
+	require.NoError(t, err)
+	require.Len(t, request.Questions, 1)
+	question := request.Questions[0]
+	assert.Equal(t, "http-framework", question.ID)
+	assert.Len(t, question.Options, 4)
+
+	selected := models.HTTPFrameworkGin
+	response := &models.ClarificationResponse{
+		ID:        "resp-1",
+		RequestID: request.ID,
+		Answers: map[string]models.Answer{
+			question.ID: {QuestionID: question.ID, SelectedOption: &selected},
+		},
+	}
+	fcs, err := engine.ApplyAnswers(ctx, spec, request, response)
+	require.NoError(t, err)
+	assert.Equal(t, models.HTTPFrameworkGin, fcs.Architecture.HTTPFramework)
+
+	// Specs without an HTTP API, or selecting a framework, are not asked
+	spec.Content = "Build a command-line tool"
+	_, err = engine.GenerateRequest(ctx, spec)
+	assert.Error(t, err, "nothing to clarify")
+
+	spec.Content = "Build a REST API"
+	spec.ParsedData = map[string]interface{}{
+		"architecture": map[string]interface{}{"http_framework": "chi"},
+	}
+	_, err = engine.GenerateRequest(ctx, spec)
+	assert.Error(t, err)
+}
+
+func TestEngine_MergedSpecConflicts(t *testing.T) {
+	mockClient := &MockLLMClient{
+		GenerateFunc: func(ctx context.Context, prompt string) (string, error) {
+			return `[]`, nil
+		},
+	}
+
+	engine, err := clarify.NewEngine(clarify.EngineConfig{LLMClient: mockClient})
+	require.NoError(t, err)
+
+	parse := func(name, content string) spec.MergeSource {
+		parsed, err := spec.ParseAndValidate(models.FormatYAML, content)
+		require.NoError(t, err)
+		return spec.MergeSource{Name: name, Spec: parsed}
+	}
+	merged, err := spec.Merge([]spec.MergeSource{
+		parse("product.yaml", `name: Shop
+description: An online shop
+requirements:
+  - id: FR-001
+    description: Customers place orders
+build_config:
+  persistence: gorm
+`),
+		parse("security.yaml", `name: Shop security
+description: Security requirements
+requirements:
+  - id: FR-001
+    description: Only signed-in customers place orders
+build_config:
+  persistence: sqlc
+`),
+	})
+	require.NoError(t, err)
+	require.Len(t, merged.Conflicts, 2)
+
+	ctx := context.Background()
+	request, err := engine.GenerateRequest(ctx, merged)
+	require.NoError(t, err)
+	require.Len(t, request.Questions, 2)
+	question := request.Questions[1]
+	assert.Equal(t, "conflict-2", question.ID)
+	assert.Equal(t, "Which value should requirements[FR-001].description have?", question.Question)
+	require.Len(t, question.Options, 2)
+	assert.Equal(t, "security.yaml", question.Options[1].Label)
+	assert.Equal(t, "Only signed-in customers place orders", question.Options[1].Description)
+
+	selected := "security.yaml"
+	custom := "ent"
+	response := &models.ClarificationResponse{
+		ID:        "resp-1",
+		RequestID: request.ID,
+		Answers: map[string]models.Answer{
+			"conflict-1": {QuestionID: "conflict-1", CustomAnswer: &custom},
+			"conflict-2": {QuestionID: "conflict-2", SelectedOption: &selected},
+		},
+	}
+	fcs, err := engine.ApplyAnswers(ctx, merged, request, response)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"product.yaml", "security.yaml"}, fcs.Metadata.Sources)
+	assert.Empty(t, fcs.UnresolvedConflicts())
+	assert.Equal(t, "ent", fcs.BuildConfig.Persistence)
+	require.Len(t, fcs.Requirements.Functional, 1)
+	assert.Equal(t, "Only signed-in customers place orders", fcs.Requirements.Functional[0].Description)
+
+	// Unanswered conflicts are kept in the FCS, which does not validate
+	// until they are resolved
+	fcs, err = engine.Clarify(ctx, merged, false)
+	require.NoError(t, err)
+	assert.Len(t, fcs.UnresolvedConflicts(), 2)
+	assert.Equal(t, "Customers place orders", fcs.Requirements.Functional[0].Description)
+	assert.ErrorContains(t, fcs.Validate(), "2 unresolved conflicts between the merged specifications")
+}
@@ -0,0 +1,201 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/dshills/gocreator/internal/clarify"
+	"github.com/dshills/gocreator/internal/clarify/importers"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/spec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petStoreOpenAPI = `openapi: 3.0.3
+info:
+  title: Pet Store
+  version: 1.0.0
+paths:
+  /pets:
+    parameters:
+      - $ref: '#/components/parameters/Tenant'
+    get:
+      summary: List pets
+      tags: [pets]
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            format: int32
+      responses:
+        200:
+          description: The pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+    post:
+      description: |
+        Create a pet.
+        The ID is assigned by the store.
+      requestBody:
+        $ref: '#/components/requestBodies/NewPet'
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+  /pets/{id}:
+    delete:
+      operationId: deletePet
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "204":
+          description: Deleted
+components:
+  parameters:
+    Tenant:
+      name: X-Tenant
+      in: header
+      schema:
+        type: string
+  requestBodies:
+    NewPet:
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/NewPet'
+  schemas:
+    pet-status:
+      type: string
+      enum: [available, sold]
+    NewPet:
+      type: object
+      properties:
+        name:
+          type: string
+        tags:
+          type: array
+          items:
+            type: string
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/NewPet'
+        - type: object
+          properties:
+            id:
+              type: integer
+              format: int64
+            status:
+              $ref: '#/components/schemas/pet-status'
+            born:
+              type: [string, "null"]
+              format: date-time
+            owner:
+              $ref: 'https://example.com/owner.yaml'
+`
+
+func TestOpenAPIImporter_Import(t *testing.T) {
+	result, err := importers.Import(petStoreOpenAPI)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	assert.Equal(t, "OpenAPI 3.0.3", result.Source)
+	assert.Equal(t, "Pet Store", result.Name)
+	assert.NotEmpty(t, result.Description)
+
+	require.Len(t, result.Enums, 1)
+	assert.Equal(t, "PetStatus", result.Enums[0].Name)
+	assert.Equal(t, []string{"available", "sold"}, result.Enums[0].Values)
+
+	require.Len(t, result.Entities, 2)
+	assert.Equal(t, "NewPet", result.Entities[0].Name)
+	assert.Equal(t, map[string]string{"name": "string", "tags": "[]string"}, result.Entities[0].Attributes)
+	assert.Equal(t, "Pet", result.Entities[1].Name)
+	assert.Equal(t, map[string]string{
+		"name":   "string",
+		"tags":   "[]string",
+		"id":     "int64",
+		"status": "PetStatus",
+		"born":   "time.Time",
+		"owner":  "any",
+	}, result.Entities[1].Attributes)
+	assert.Equal(t, []string{"reference https://example.com/owner.yaml is not a component schema; typed as any"}, result.Warnings)
+
+	require.Len(t, result.APIContracts, 3)
+	list := result.APIContracts[0]
+	assert.Equal(t, "GET", list.Method)
+	assert.Equal(t, "/pets", list.Endpoint)
+	assert.Equal(t, "List pets", list.Description)
+	assert.Equal(t, map[string]string{"X-Tenant": "string", "limit": "int32"}, list.Request.Fields)
+	assert.Equal(t, map[string]string{"body": "[]Pet"}, list.Response.Fields)
+
+	create := result.APIContracts[1]
+	assert.Equal(t, "POST", create.Method)
+	assert.Equal(t, "Create a pet.", create.Description)
+	assert.Equal(t, map[string]string{"X-Tenant": "string", "name": "string", "tags": "[]string"}, create.Request.Fields)
+	assert.Equal(t, "int64", create.Response.Fields["id"])
+
+	remove := result.APIContracts[2]
+	assert.Equal(t, "DELETE", remove.Method)
+	assert.Equal(t, "deletePet", remove.Description)
+	assert.Empty(t, remove.Response.Fields)
+
+	require.Len(t, result.Requirements, 3)
+	assert.Equal(t, "FR-001", result.Requirements[0].ID)
+	assert.Equal(t, "System MUST handle GET /pets: List pets", result.Requirements[0].Description)
+	assert.Equal(t, "pets", result.Requirements[0].Category)
+}
+
+func TestOpenAPIImporter_Unsupported(t *testing.T) {
+	_, err := importers.Import("swagger: \"2.0\"\ninfo:\n  title: Old\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "convert the document to OpenAPI 3")
+
+	_, err = importers.Import("openapi: 3.0.0\ninfo:\n  version: 1.0.0\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "info.title is required")
+
+	// A GoCreator spec is not imported
+	result, err := importers.Import("name: shop\ndescription: A shop\nrequirements: []\n")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestOpenAPIImporter_ClarifiedSpec(t *testing.T) {
+	result, err := importers.Import(petStoreOpenAPI)
+	require.NoError(t, err)
+	content, err := result.Spec()
+	require.NoError(t, err)
+
+	inputSpec, err := spec.ParseAndValidate(models.FormatYAML, content)
+	require.NoError(t, err)
+	assert.True(t, importers.IsImported(inputSpec))
+
+	// Clarification carries the imported structure into the FCS
+	set := &clarify.QuestionSet{SchemaVersion: "1.0", RequestID: "req-1"}
+	fcs, err := set.Apply(inputSpec)
+	require.NoError(t, err)
+	assert.Len(t, fcs.Requirements.Functional, 3)
+	assert.Len(t, fcs.DataModel.Entities, 2)
+	assert.Len(t, fcs.DataModel.Enums, 1)
+	require.Len(t, fcs.APIContracts, 3)
+	assert.Equal(t, "[]Pet", fcs.APIContracts[0].Response.Fields["body"])
+
+	// A hand-written spec keeps its content-only FCS
+	handWritten, err := spec.ParseAndValidate(models.FormatYAML, "name: shop\ndescription: A shop\nrequirements:\n  - id: FR-001\n    description: Sell\n")
+	require.NoError(t, err)
+	fcs, err = set.Apply(handWritten)
+	require.NoError(t, err)
+	assert.Empty(t, fcs.Requirements.Functional)
+}
@@ -0,0 +1,114 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/clarify"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func interactiveRequest() *models.ClarificationRequest {
+	return &models.ClarificationRequest{
+		SchemaVersion: "1.0",
+		ID:            "req-1",
+		SpecID:        "spec-1",
+		Questions: []models.Question{
+			{
+				ID:       "q1",
+				Topic:    "Authentication",
+				Question: "Auth method?",
+				Options: []models.Option{
+					{Label: "JWT Tokens", Description: "Stateless tokens", Implications: "No server sessions"},
+					{Label: "OAuth"},
+				},
+			},
+			{
+				ID:       "q2",
+				Question: "Database?",
+				Options:  []models.Option{{Label: "PostgreSQL"}, {Label: "SQLite"}},
+			},
+		},
+	}
+}
+
+func interactiveSpec() *models.InputSpecification {
+	return &models.InputSpecification{ID: "spec-1", Format: models.FormatYAML, Content: questionSetSpec}
+}
+
+func clarifications(fcs *models.FinalClarifiedSpecification) map[string]string {
+	answers := make(map[string]string, len(fcs.Metadata.Clarifications))
+	for _, c := range fcs.Metadata.Clarifications {
+		answers[c.QuestionID] = c.Answer
+	}
+	return answers
+}
+
+func TestSession_Answers(t *testing.T) {
+	var out bytes.Buffer
+	// An option number, then an empty line that asks again and a custom answer
+	session := clarify.NewSession(clarify.SessionConfig{In: strings.NewReader("1\n\nMySQL"), Out: &out})
+
+	fcs, err := session.Run(context.Background(), interactiveSpec(), interactiveRequest())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"q1": "JWT Tokens", "q2": "MySQL"}, clarifications(fcs))
+
+	output := out.String()
+	assert.Contains(t, output, "--- Question 1 of 2: Authentication ---")
+	assert.Contains(t, output, "  1. JWT Tokens - Stateless tokens\n     Implications: No server sessions\n")
+	assert.Contains(t, output, "FCS revision 1: 1 of 2 questions answered")
+	assert.Contains(t, output, "FCS revision 2: 2 of 2 questions answered")
+	assert.Contains(t, output, "Please enter an answer.")
+}
+
+func TestSession_Back(t *testing.T) {
+	var out bytes.Buffer
+	// Going back from the first question stays there; going back from the
+	// second revises the first answer
+	session := clarify.NewSession(clarify.SessionConfig{In: strings.NewReader("back\noauth\nback\n1\nsqlite\n"), Out: &out})
+
+	fcs, err := session.Run(context.Background(), interactiveSpec(), interactiveRequest())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"q1": "JWT Tokens", "q2": "SQLite"}, clarifications(fcs))
+
+	output := out.String()
+	assert.Contains(t, output, "This is the first question.")
+	assert.Contains(t, output, "Revised q1: OAuth -> JWT Tokens")
+	assert.Contains(t, output, "FCS revision 3: 2 of 2 questions answered")
+}
+
+func TestSession_InputEnds(t *testing.T) {
+	session := clarify.NewSession(clarify.SessionConfig{In: strings.NewReader("2\n"), Out: io.Discard})
+
+	_, err := session.Run(context.Background(), interactiveSpec(), interactiveRequest())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "question q2")
+}
+
+func TestSession_NoQuestions(t *testing.T) {
+	var out bytes.Buffer
+	request := interactiveRequest()
+	request.Questions = nil
+	session := clarify.NewSession(clarify.SessionConfig{In: strings.NewReader(""), Out: &out})
+
+	fcs, err := session.Run(context.Background(), interactiveSpec(), request)
+	require.NoError(t, err)
+	assert.Empty(t, fcs.Metadata.Clarifications)
+	assert.Contains(t, out.String(), "No open questions")
+}
+
+func TestSession_Canceled(t *testing.T) {
+	in, answer := io.Pipe()
+	defer func() { _ = answer.Close() }()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	session := clarify.NewSession(clarify.SessionConfig{In: in, Out: io.Discard})
+	_, err := session.Run(ctx, interactiveSpec(), interactiveRequest())
+	assert.ErrorIs(t, err, context.Canceled)
+}
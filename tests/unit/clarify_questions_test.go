@@ -231,3 +231,22 @@ func TestPrioritizeQuestions(t *testing.T) {
 	// Third should be minor
 	assert.Equal(t, "q1", prioritized[2].ID)
 }
+
+func TestLLMQuestionGenerator_GenerateStructured(t *testing.T) {
+	client := &structuredLLMClient{output: `{"questions": [{
+		"topic": "Deletes",
+		"question": "Are deleted users kept?",
+		"options": [{"label": "Soft delete"}, {"label": "Hard delete"}]
+	}]}`}
+
+	ambiguities := []models.Ambiguity{{Type: "conflict", Description: "Deletes are both soft and hard", Severity: "critical"}}
+	questions, err := clarify.NewLLMQuestionGenerator(client).Generate(context.Background(), ambiguities)
+	require.NoError(t, err)
+	require.Len(t, questions, 1)
+	assert.NotEmpty(t, questions[0].ID, "questions get their ID after generation")
+	assert.Equal(t, "Hard delete", questions[0].Options[1].Label)
+
+	client.output = `{"questions": [{"question": "Are deleted users kept?", "options": [{"label": "Yes"}]}]}`
+	_, err = clarify.NewLLMQuestionGenerator(client).Generate(context.Background(), ambiguities)
+	assert.ErrorContains(t, err, "/questions/0/options: must have at least 2 items, not 1")
+}
@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/clarify"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const questionSetSpec = "name: shop\nrequirements: []\n"
+
+func exportedQuestionSet(t *testing.T) string {
+	t.Helper()
+	request := &models.ClarificationRequest{
+		SchemaVersion: "1.0",
+		ID:            "req-1",
+		SpecID:        "spec-1",
+		CreatedAt:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Questions: []models.Question{
+			{
+				ID:       "q1",
+				Topic:    "Authentication",
+				Question: "Auth method?",
+				Options: []models.Option{
+					{Label: "JWT Tokens", Description: "Stateless tokens"},
+					{Label: "OAuth"},
+				},
+			},
+			{
+				ID:       "q2",
+				Question: "Database?",
+				Options:  []models.Option{{Label: "PostgreSQL"}, {Label: "SQLite"}},
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "questions.yaml")
+	require.NoError(t, clarify.WriteQuestionSet(path, clarify.NewQuestionSet(request, "spec.yaml", questionSetSpec)))
+	return path
+}
+
+func TestQuestionSet_RoundTrip(t *testing.T) {
+	path := exportedQuestionSet(t)
+
+	//nolint:gosec // G304: Test reads its own temp file
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "--answers", "the file explains how to ingest it")
+
+	set, err := clarify.LoadQuestionSet(path)
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", set.RequestID)
+	require.Len(t, set.Questions, 2)
+	assert.Equal(t, "Stateless tokens", set.Questions[0].Options[0].Description)
+	require.NoError(t, set.CheckSpec(questionSetSpec))
+
+	// Answers are an option label in any case, or free text
+	set.Questions[0].Answer = "  jwt tokens "
+	set.Questions[1].Answer = "MySQL, managed by the platform team"
+	response, err := set.Response()
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", response.RequestID)
+	require.NotNil(t, response.Answers["q1"].SelectedOption)
+	assert.Equal(t, "JWT Tokens", *response.Answers["q1"].SelectedOption)
+	assert.Nil(t, response.Answers["q2"].SelectedOption)
+	require.NotNil(t, response.Answers["q2"].CustomAnswer)
+	assert.Equal(t, "MySQL, managed by the platform team", *response.Answers["q2"].CustomAnswer)
+
+	fcs, err := set.Apply(&models.InputSpecification{ID: "spec-2", Format: models.FormatYAML, Content: questionSetSpec})
+	require.NoError(t, err)
+	assert.Equal(t, "spec-2", fcs.OriginalSpecID)
+	assert.Len(t, fcs.Metadata.Clarifications, 2)
+}
+
+func TestQuestionSet_Unanswered(t *testing.T) {
+	set, err := clarify.LoadQuestionSet(exportedQuestionSet(t))
+	require.NoError(t, err)
+	set.Questions[0].Answer = "OAuth"
+
+	_, err = set.Response()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2 questions are not answered: q2")
+}
+
+func TestQuestionSet_SpecChanged(t *testing.T) {
+	set, err := clarify.LoadQuestionSet(exportedQuestionSet(t))
+	require.NoError(t, err)
+
+	err = set.CheckSpec(questionSetSpec + "description: changed\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--export-questions")
+}
@@ -0,0 +1,585 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveOutputDir(t *testing.T) {
+	data := config.NewOutputPathData("inventory", "github.com/acme/inventory", time.Date(2025, 3, 7, 14, 5, 9, 0, time.UTC))
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain path", pattern: "./generated", want: "./generated"},
+		{name: "project and date", pattern: "./generated/{{.ProjectName}}-{{.Date}}", want: "generated/inventory-2025-03-07"},
+		{name: "timestamp", pattern: "/tmp/out/{{.Timestamp}}", want: "/tmp/out/20250307-140509"},
+		{name: "module path", pattern: "./src/{{.ModulePath}}", want: "src/github.com/acme/inventory"},
+		{name: "unknown field", pattern: "./generated/{{.Branch}}", wantErr: true},
+		{name: "malformed template", pattern: "./generated/{{.ProjectName", wantErr: true},
+		{name: "empty result", pattern: "{{if false}}x{{end}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := config.ResolveOutputDir(tt.pattern, data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConfigValidate_Project(t *testing.T) {
+	valid := func() *config.Config {
+		return &config.Config{
+			LLM:        config.LLMConfig{Provider: "anthropic", Model: "m", MaxTokens: 1},
+			Workflow:   config.WorkflowConfig{MaxParallel: 1, CheckpointInterval: 1},
+			Validation: config.ValidationConfig{MaxParallel: 1},
+			Logging:    config.LoggingConfig{Level: "info", Format: "console"},
+		}
+	}
+
+	require.NoError(t, valid().Validate())
+
+	tests := []struct {
+		name    string
+		project config.ProjectConfig
+		wantErr string
+	}{
+		{"valid settings", config.ProjectConfig{ModulePath: "github.com/acme/app", BinaryName: "appd", OutputDir: "./out/{{.ProjectName}}"}, ""},
+		{"module path with spaces", config.ProjectConfig{ModulePath: "github.com/acme/my app"}, "project.module_path"},
+		{"module path trailing slash", config.ProjectConfig{ModulePath: "github.com/acme/app/"}, "project.module_path"},
+		{"binary name with separator", config.ProjectConfig{BinaryName: "bin/app"}, "project.binary_name"},
+		{"bad output template", config.ProjectConfig{OutputDir: "./out/{{.Nope}}"}, "project.output_dir"},
+		{"protected paths", config.ProjectConfig{ProtectedPaths: []string{"docs/adr/**", "scripts/**"}}, ""},
+		{"protected path outside output", config.ProjectConfig{ProtectedPaths: []string{"../docs/**"}}, "project.protected_paths"},
+		{"templates dir", config.ProjectConfig{TemplatesDir: os.TempDir()}, ""},
+		{"missing templates dir", config.ProjectConfig{TemplatesDir: "./no-such-templates"}, "project.templates_dir"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			cfg.Project = tt.project
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestConfigValidate_Database(t *testing.T) {
+	tests := []struct {
+		name     string
+		database config.DatabaseConfig
+		wantErr  string
+	}{
+		{"defaults", config.DatabaseConfig{}, ""},
+		{"mysql migrations", config.DatabaseConfig{Dialect: "mysql", Migrations: true, MigrationsDir: "db/migrations"}, ""},
+		{"unknown dialect", config.DatabaseConfig{Dialect: "oracle"}, "database.dialect"},
+		{"missing migrations dir", config.DatabaseConfig{Migrations: true}, "database.migrations_dir"},
+		{"migrations dir outside output", config.DatabaseConfig{Migrations: true, MigrationsDir: "../migrations"}, "database.migrations_dir"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				LLM:        config.LLMConfig{Provider: "anthropic", Model: "m", MaxTokens: 1},
+				Workflow:   config.WorkflowConfig{MaxParallel: 1, CheckpointInterval: 1},
+				Validation: config.ValidationConfig{MaxParallel: 1},
+				Logging:    config.LoggingConfig{Level: "info", Format: "console"},
+				Database:   tt.database,
+			}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestConfigValidate_Network(t *testing.T) {
+	tests := []struct {
+		name    string
+		network config.NetworkConfig
+		wantErr string
+	}{
+		{"defaults", config.NetworkConfig{}, ""},
+		{"proxy and tls 1.3", config.NetworkConfig{ProxyURL: "http://proxy.corp:3128", TLSMinVersion: "1.3"}, ""},
+		{"unsupported tls version", config.NetworkConfig{TLSMinVersion: "1.1"}, "llm.network.tls_min_version"},
+		{"missing ca bundle", config.NetworkConfig{CABundle: "/nonexistent/ca.pem"}, "llm.network.ca_bundle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				LLM:        config.LLMConfig{Provider: "anthropic", Model: "m", MaxTokens: 1, Network: tt.network},
+				Workflow:   config.WorkflowConfig{MaxParallel: 1, CheckpointInterval: 1},
+				Validation: config.ValidationConfig{MaxParallel: 1},
+				Logging:    config.LoggingConfig{Level: "info", Format: "console"},
+			}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestConfigValidate_Ensemble(t *testing.T) {
+	cfg := &config.Config{
+		LLM:        config.LLMConfig{Provider: "anthropic", Model: "m", MaxTokens: 1},
+		Workflow:   config.WorkflowConfig{MaxParallel: 1, CheckpointInterval: 1},
+		Validation: config.ValidationConfig{MaxParallel: 1},
+		Logging:    config.LoggingConfig{Level: "info", Format: "console"},
+	}
+
+	cfg.LLM.Ensemble = config.EnsembleConfig{Provider: "openai", Model: "gpt-4o"}
+	assert.NoError(t, cfg.Validate())
+	assert.True(t, cfg.LLM.Ensemble.Enabled())
+
+	cfg.LLM.Ensemble = config.EnsembleConfig{Provider: "openai"}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "llm.ensemble.model")
+}
+
+func TestConfigValidate_PhaseModels(t *testing.T) {
+	cfg := &config.Config{
+		LLM:        config.LLMConfig{Provider: "anthropic", Model: "claude-opus-4-1", MaxTokens: 1},
+		Workflow:   config.WorkflowConfig{MaxParallel: 1, CheckpointInterval: 1},
+		Validation: config.ValidationConfig{MaxParallel: 1},
+		Logging:    config.LoggingConfig{Level: "info", Format: "console"},
+	}
+
+	cfg.Models.Coder = config.PhaseModelConfig{Model: "claude-haiku-4-5"}
+	cfg.Models.Tester = config.PhaseModelConfig{Provider: "openai", Model: "gpt-4o-mini"}
+	assert.NoError(t, cfg.Validate())
+	assert.False(t, cfg.Models.Planner.Enabled())
+
+	cfg.Models.Tester = config.PhaseModelConfig{Provider: "openai"}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "models.tester.model")
+}
+
+func TestConfigValidate_BaseURL(t *testing.T) {
+	cfg := &config.Config{
+		LLM:        config.LLMConfig{Provider: "openai-compatible", Model: "llama3.1:8b", MaxTokens: 1},
+		Workflow:   config.WorkflowConfig{MaxParallel: 1, CheckpointInterval: 1},
+		Validation: config.ValidationConfig{MaxParallel: 1},
+		Logging:    config.LoggingConfig{Level: "info", Format: "console"},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "llm.base_url")
+
+	cfg.LLM.BaseURL = "http://localhost:11434/v1"
+	assert.NoError(t, cfg.Validate())
+
+	// A self-hosted second model needs the endpoint too
+	cfg.LLM = config.LLMConfig{Provider: "anthropic", Model: "m", MaxTokens: 1}
+	cfg.LLM.Downgrade = config.DowngradeConfig{Provider: "openai-compatible", Model: "llama3.1:8b"}
+	require.Error(t, cfg.Validate())
+
+	// Azure OpenAI is reached at the resource endpoint
+	cfg.LLM = config.LLMConfig{Provider: "azure-openai", Model: "gpt-4o", MaxTokens: 1}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "llm.base_url is required for provider azure-openai")
+}
+
+func TestConfigValidate_Concurrency(t *testing.T) {
+	cfg := &config.Config{
+		LLM:        config.LLMConfig{Provider: "anthropic", Model: "m", MaxTokens: 1},
+		Workflow:   config.WorkflowConfig{MaxParallel: 1, CheckpointInterval: 1},
+		Validation: config.ValidationConfig{MaxParallel: 1},
+		Logging:    config.LoggingConfig{Level: "info", Format: "console"},
+	}
+
+	cfg.LLM.Concurrency = []config.ConcurrencyClass{
+		{Provider: "anthropic", MaxParallel: 8},
+		{Provider: "openai", Model: "gpt-4o", MaxParallel: 2},
+		{Provider: "openai", MaxParallel: 4},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	tests := []struct {
+		name    string
+		class   config.ConcurrencyClass
+		wantErr string
+	}{
+		{"missing provider", config.ConcurrencyClass{MaxParallel: 1}, "llm.concurrency[3].provider"},
+		{"zero cap", config.ConcurrencyClass{Provider: "google"}, "llm.concurrency[3].max_parallel"},
+		{"duplicate class", config.ConcurrencyClass{Provider: "OpenAI", Model: "GPT-4o", MaxParallel: 1}, "repeats provider"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := *cfg
+			invalid.LLM.Concurrency = append(append([]config.ConcurrencyClass{}, cfg.LLM.Concurrency...), tt.class)
+			err := invalid.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLoadUnvalidated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("llm:\n  max_tokens: -1\n"), 0600))
+
+	_, err := config.Load(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "llm.max_tokens")
+
+	cfg, err := config.LoadUnvalidated(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, cfg.Source)
+	assert.Equal(t, -1, cfg.LLM.MaxTokens)
+	assert.Error(t, cfg.Validate())
+}
+
+func TestLoad_Timeouts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("timeouts:\n  plan: 90s\n  validate: 0\n"), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, cfg.Timeouts.Plan)
+	assert.Equal(t, 30*time.Minute, cfg.Timeouts.Code, "unset phases keep their default")
+	assert.Zero(t, cfg.Timeouts.Validate)
+
+	cfg.Timeouts.Tests = -time.Second
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timeouts")
+}
+
+func TestLoad_Retry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("llm:\n  retry:\n    max_retries: 5\n    max_delay: 10m\n"), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 5, cfg.LLM.Retry.MaxRetries)
+	assert.Equal(t, 2*time.Second, cfg.LLM.Retry.InitialDelay, "unset settings keep their default")
+	assert.Equal(t, 10*time.Minute, cfg.LLM.Retry.MaxDelay)
+
+	cfg.LLM.Retry.InitialDelay = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "llm.retry.initial_delay")
+
+	cfg.LLM.Retry.MaxRetries = 0
+	assert.NoError(t, cfg.Validate(), "no delay is needed without retries")
+}
+
+func TestLoad_Cache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("llm:\n  cache:\n    ttl: 24h\n"), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.True(t, cfg.LLM.Cache.Enabled, "responses are cached by default")
+	assert.Equal(t, 24*time.Hour, cfg.LLM.Cache.TTL)
+	assert.EqualValues(t, 512, cfg.LLM.Cache.MaxSizeMB)
+
+	cfg.LLM.Cache.MaxSizeMB = -1
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "llm.cache.max_size_mb")
+}
+
+func TestLoad_Limits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("limits:\n  max_duration: 2h\n  max_open_files: 32\n  max_cost: 5.5\n"), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, cfg.Limits.MaxDuration)
+	assert.Equal(t, 32, cfg.Limits.MaxOpenFiles)
+	assert.Equal(t, 5.5, cfg.Limits.MaxCost)
+	assert.Zero(t, cfg.Limits.SoftMemoryMB, "limits are off by default")
+	assert.Zero(t, cfg.Limits.MaxTokens)
+	assert.Equal(t, "abort", cfg.Limits.OnBudget)
+
+	cfg.Limits.OnBudget = "ignore"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "limits.on_budget")
+
+	cfg.Limits.OnBudget = "confirm"
+	cfg.Limits.SoftMemoryMB = -1
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "limits")
+}
+
+func TestLoad_FileStrategy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("plan:\n  file_strategy: per_entity\n"), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "per_entity", cfg.Plan.FileStrategy)
+	assert.Equal(t, 300, cfg.Plan.MaxFileLines)
+
+	cfg.Plan.FileStrategy = "one_big_file"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plan.file_strategy")
+
+	cfg.Plan.FileStrategy = "bounded"
+	cfg.Plan.MaxFileLines = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plan.max_file_lines")
+}
+
+func TestLoad_Approval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("workflow:\n  approval:\n    checkpoints: [plan]\n    default: abort\n"), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"plan"}, cfg.Workflow.Approval.Checkpoints)
+	assert.Equal(t, "abort", cfg.Workflow.Approval.Default)
+	assert.Equal(t, 5*time.Minute, cfg.Workflow.Approval.Timeout)
+
+	cfg.Workflow.Approval.Checkpoints = []string{"validate"}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "workflow.approval.checkpoints")
+
+	cfg.Workflow.Approval.Checkpoints = nil
+	cfg.Workflow.Approval.Default = "skip"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "workflow.approval.default")
+}
+
+func TestLoad_Speculative(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("llm:\n  provider: anthropic\n"), 0600))
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.False(t, cfg.Workflow.Speculative, "speculative generation is off by default")
+
+	require.NoError(t, os.WriteFile(path, []byte("workflow:\n  speculative: true\n"), 0600))
+	cfg, err = config.Load(path)
+	require.NoError(t, err)
+	assert.True(t, cfg.Workflow.Speculative)
+}
+
+func TestLoad_Severity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("validation:\n  severity:\n    checks:\n      lint: warning\n    lint_rules:\n      gosec: error\n    fail_on: warning\n"), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"lint": "warning"}, cfg.Validation.Severity.Checks)
+	assert.Equal(t, map[string]string{"gosec": "error"}, cfg.Validation.Severity.LintRules)
+	assert.Equal(t, "warning", cfg.Validation.Severity.FailOn)
+
+	cfg.Validation.Severity.Checks = map[string]string{"style": "info"}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown check")
+
+	cfg.Validation.Severity.Checks = map[string]string{"lint": "fatal"}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "validation.severity.checks.lint")
+
+	cfg.Validation.Severity.Checks = nil
+	cfg.Validation.Severity.FailOn = "ignore"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "validation.severity.fail_on")
+}
+
+func TestLoad_RepairAttempts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("validation:\n  enable_tests: true\n"), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Validation.MaxRepairAttempts, "compile errors are repaired by default")
+
+	cfg.Validation.MaxRepairAttempts = -1
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "validation.max_repair_attempts")
+}
+
+func TestLoad_TestRepair(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("validation:\n  repair_tests: true\n"), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.True(t, cfg.Validation.RepairTests)
+	assert.Equal(t, 2, cfg.Validation.MaxTestRepairAttempts)
+
+	cfg.Validation.MaxTestRepairAttempts = -1
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "validation.max_test_repair_attempts")
+}
+
+func TestLoad_LintRepair(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("validation:\n  repair_lint: true\n  max_lint_repair_attempts: 3\n"), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.True(t, cfg.Validation.RepairLint)
+	assert.Equal(t, 3, cfg.Validation.MaxLintRepairAttempts)
+
+	cfg.Validation.MaxLintRepairAttempts = -1
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "validation.max_lint_repair_attempts")
+}
+
+func TestPromptsConfig_LoadPreamble(t *testing.T) {
+	dir := t.TempDir()
+	preamblePath := filepath.Join(dir, "standards.md")
+	require.NoError(t, os.WriteFile(preamblePath, []byte("\nUse zerolog for logging.\n"), 0600))
+
+	inline, err := config.PromptsConfig{Preamble: "  No panics in libraries. "}.LoadPreamble()
+	require.NoError(t, err)
+	assert.Equal(t, "No panics in libraries.", inline)
+
+	fromFile, err := config.PromptsConfig{PreambleFile: preamblePath}.LoadPreamble()
+	require.NoError(t, err)
+	assert.Equal(t, "Use zerolog for logging.", fromFile)
+
+	path := filepath.Join(dir, "gocreator.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("prompts:\n  preamble: inline\n  preamble_file: "+preamblePath+"\n"), 0600))
+	_, err = config.Load(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "prompts.preamble")
+}
+
+func TestLoad_PromptTemplatesDir(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gocreator.yaml")
+
+	require.NoError(t, os.WriteFile(path, []byte("prompts:\n  templates_dir: "+dir+"\n"), 0600))
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, dir, cfg.Prompts.TemplatesDir)
+
+	require.NoError(t, os.WriteFile(path, []byte("prompts:\n  templates_dir: "+filepath.Join(dir, "missing")+"\n"), 0600))
+	_, err = config.Load(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "prompts.templates_dir")
+}
+
+func TestLoad_Style(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+
+	require.NoError(t, os.WriteFile(path, []byte(`style:
+  error_wrapping: fmt_errorf
+  logging: slog
+  context: first_param
+  initialisms: true
+  test_framework: stdlib
+  rules:
+    - Return early instead of nesting else blocks
+`), 0600))
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	guide := cfg.Style.Guide()
+	assert.Equal(t, "fmt_errorf", guide.ErrorWrapping)
+	assert.Equal(t, "slog", guide.Logging)
+	assert.True(t, guide.Initialisms)
+	assert.Equal(t, "stdlib", guide.TestFramework)
+	assert.Equal(t, []string{"Return early instead of nesting else blocks"}, guide.Rules)
+	assert.True(t, guide.Checkable())
+
+	for _, invalid := range []string{"error_wrapping: xerrors", "logging: glog", "context: last_param", "test_framework: ginkgo"} {
+		require.NoError(t, os.WriteFile(path, []byte("style:\n  "+invalid+"\n"), 0600))
+		_, err = config.Load(path)
+		require.Error(t, err, invalid)
+		assert.Contains(t, err.Error(), "style.", invalid)
+	}
+}
+
+func TestLoad_PostProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+
+	require.NoError(t, os.WriteFile(path, []byte("postprocess:\n  transforms: [license-header, sort-methods]\n"), 0600))
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"license-header", "sort-methods"}, cfg.PostProcess.Transforms)
+
+	require.NoError(t, os.WriteFile(path, []byte("style:\n  initialisms: true\n"), 0600))
+	cfg, err = config.Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.PostProcess.Transforms)
+}
+
+func TestConfigValidate_Events(t *testing.T) {
+	cfg := &config.Config{
+		LLM:        config.LLMConfig{Provider: "anthropic", Model: "m", MaxTokens: 1},
+		Workflow:   config.WorkflowConfig{MaxParallel: 1, CheckpointInterval: 1},
+		Validation: config.ValidationConfig{MaxParallel: 1},
+		Logging:    config.LoggingConfig{Level: "info", Format: "console"},
+	}
+	cfg.Events.Webhook = config.WebhookSinkConfig{URL: "https://ci.example.com/hook", Types: []string{"phase_completed", "error"}}
+	cfg.Events.OTLP = config.OTLPSinkConfig{Endpoint: "http://localhost:4318"}
+	assert.NoError(t, cfg.Validate())
+
+	invalid := *cfg
+	invalid.Events.Webhook.URL = "ci.example.com/hook"
+	err := invalid.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "events.webhook.url")
+
+	invalid = *cfg
+	invalid.Events.File.Types = []string{"phase_done"}
+	err = invalid.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `events.file.types has unknown event type "phase_done"`)
+}
+
+func TestLoad_Events(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gocreator.yaml")
+	content := "events:\n  file:\n    path: events.jsonl\n  webhook:\n    url: http://localhost:9000/hook\n    headers:\n      Authorization: Bearer ${HOOK_TOKEN}\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "events.jsonl", cfg.Events.File.Path)
+	assert.Equal(t, "http://localhost:9000/hook", cfg.Events.Webhook.URL)
+	assert.Equal(t, "Bearer ${HOOK_TOKEN}", cfg.Events.Webhook.Headers["authorization"])
+	assert.Equal(t, 10*time.Second, cfg.Events.Webhook.Timeout)
+	assert.Equal(t, "gocreator", cfg.Events.OTLP.ServiceName)
+	assert.Empty(t, cfg.Events.OTLP.Endpoint)
+}
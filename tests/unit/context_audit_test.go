@@ -0,0 +1,106 @@
+package unit
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contextRootAllowlist lists pipeline files that may create root contexts,
+// keyed by slash-separated path relative to the repository root. Commands
+// under cmd/ own the run context and are not audited.
+var contextRootAllowlist = map[string]string{
+	"internal/eventsink/eventsink.go": "event delivery outlives the run context so an interrupted run still reports its final events; close bounds it",
+}
+
+// TestPipelineDerivesContexts flags context.Background and context.TODO in
+// non-test pipeline code. A fresh root context detaches an operation from the
+// run context, so it ignores interrupts and phase timeouts.
+func TestPipelineDerivesContexts(t *testing.T) {
+	root := filepath.Join("..", "..")
+
+	var violations []string
+	for _, dir := range []string{"internal", "pkg"} {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if _, ok := contextRootAllowlist[rel]; ok {
+				return nil
+			}
+
+			found, err := rootContextCalls(path)
+			if err != nil {
+				return err
+			}
+			for _, line := range found {
+				violations = append(violations, rel+":"+line)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	assert.Empty(t, violations, "derive contexts from the caller instead of creating root contexts")
+}
+
+// rootContextCalls returns "line: call" for each context.Background or
+// context.TODO call in the file, honouring renamed imports
+func rootContextCalls(path string) ([]string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	name := ""
+	for _, imp := range file.Imports {
+		if importPath, _ := strconv.Unquote(imp.Path.Value); importPath == "context" {
+			name = "context"
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+		}
+	}
+	if name == "" || name == "_" {
+		return nil, nil
+	}
+
+	var found []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok || pkg.Name != name {
+			return true
+		}
+		if sel.Sel.Name == "Background" || sel.Sel.Name == "TODO" {
+			found = append(found, strconv.Itoa(fset.Position(call.Pos()).Line)+": context."+sel.Sel.Name)
+		}
+		return true
+	})
+	return found, nil
+}
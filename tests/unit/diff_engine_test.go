@@ -0,0 +1,253 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func numberedLines(n int) string {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		sb.WriteString(fmt.Sprintf("line %d\n", i))
+	}
+	return sb.String()
+}
+
+func TestUnifiedDiffEngine_RoundTrip(t *testing.T) {
+	engine := fsops.NewUnifiedDiffEngine()
+
+	long := numberedLines(30)
+
+	tests := []struct {
+		name       string
+		oldContent string
+		newContent string
+	}{
+		{"create file", "", "package main\n\nfunc main() {}\n"},
+		{"delete file", "package main\n", ""},
+		{"modify middle line", "a\nb\nc\n", "a\nB\nc\n"},
+		{"append lines", "a\nb\n", "a\nb\nc\nd\n"},
+		{"prepend lines", "c\nd\n", "a\nb\nc\nd\n"},
+		{"no trailing newline", "a\nb", "a\nc"},
+		{"add trailing newline", "a\nb", "a\nb\n"},
+		{"remove trailing newline", "a\nb\n", "a\nb"},
+		{"separate hunks", long, strings.Replace(strings.Replace(long, "line 2\n", "second\n", 1), "line 28\n", "twenty-eight\n", 1)},
+		{"blank lines", "a\n\n\nb\n", "a\n\nb\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := engine.Diff("file.go", tt.oldContent, tt.newContent)
+			require.NotEmpty(t, diff)
+			require.NoError(t, engine.Validate(diff))
+
+			got, err := engine.Apply(tt.oldContent, diff)
+			require.NoError(t, err)
+			assert.Equal(t, tt.newContent, got)
+		})
+	}
+}
+
+func TestUnifiedDiffEngine_Format(t *testing.T) {
+	engine := fsops.NewUnifiedDiffEngine()
+
+	t.Run("modification", func(t *testing.T) {
+		diff := engine.Diff("pkg/file.go", "a\nb\nc\n", "a\nB\nc\n")
+		assert.Equal(t, "--- a/pkg/file.go\n+++ b/pkg/file.go\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n", diff)
+	})
+
+	t.Run("creation", func(t *testing.T) {
+		diff := engine.Diff("new.go", "", "x\ny\n")
+		assert.Equal(t, "--- /dev/null\n+++ b/new.go\n@@ -0,0 +1,2 @@\n+x\n+y\n", diff)
+	})
+
+	t.Run("missing final newline", func(t *testing.T) {
+		diff := engine.Diff("f.txt", "a\n", "a\nb")
+		assert.Equal(t, "--- a/f.txt\n+++ b/f.txt\n@@ -1 +1,2 @@\n a\n+b\n\\ No newline at end of file\n", diff)
+	})
+
+	t.Run("distant changes produce separate hunks", func(t *testing.T) {
+		long := numberedLines(30)
+		modified := strings.Replace(strings.Replace(long, "line 2\n", "second\n", 1), "line 28\n", "twenty-eight\n", 1)
+		diff := engine.Diff("f.txt", long, modified)
+		assert.Equal(t, 2, strings.Count(diff, "\n@@ "))
+		assert.Contains(t, diff, "@@ -1,5 +1,5 @@")
+		assert.Contains(t, diff, "@@ -25,6 +25,6 @@")
+	})
+
+	t.Run("identical content", func(t *testing.T) {
+		assert.Empty(t, engine.Diff("f.txt", "same\n", "same\n"))
+	})
+}
+
+func TestUnifiedDiffEngine_ApplyRejectsMismatch(t *testing.T) {
+	engine := fsops.NewUnifiedDiffEngine()
+
+	diff := engine.Diff("f.txt", "a\nb\nc\n", "a\nB\nc\n")
+
+	_, err := engine.Apply("x\ny\nz\n", diff)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not apply")
+
+	creation := engine.Diff("f.txt", "", "new\n")
+	_, err = engine.Apply("existing\n", creation)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+}
+
+func TestUnifiedDiffEngine_ApplyWithOffset(t *testing.T) {
+	engine := fsops.NewUnifiedDiffEngine()
+
+	base := numberedLines(10)
+	diff := engine.Diff("f.txt", base, strings.Replace(base, "line 8\n", "eight\n", 1))
+
+	// Lines inserted above the hunk shift it down but it still applies
+	drifted := "header 1\nheader 2\n" + base
+	got, err := engine.Apply(drifted, diff)
+	require.NoError(t, err)
+	assert.Equal(t, "header 1\nheader 2\n"+strings.Replace(base, "line 8\n", "eight\n", 1), got)
+}
+
+func TestUnifiedDiffEngine_FullContext(t *testing.T) {
+	engine := fsops.NewUnifiedDiffEngineWithContext(fsops.FullContext)
+
+	base := numberedLines(30)
+	changed := strings.Replace(strings.Replace(base, "line 2\n", "two\n", 1), "line 29\n", "twenty-nine\n", 1)
+	diff := engine.Diff("f.txt", base, changed)
+
+	// One hunk carries every line of the file
+	assert.Equal(t, 1, strings.Count(diff, "@@ -"))
+	assert.Contains(t, diff, "@@ -1,30 +1,30 @@\n")
+	assert.Contains(t, diff, " line 15\n")
+
+	got, err := engine.Apply(base, diff)
+	require.NoError(t, err)
+	assert.Equal(t, changed, got)
+}
+
+func TestUnifiedDiffEngine_InvalidDiff(t *testing.T) {
+	engine := fsops.NewUnifiedDiffEngine()
+
+	tests := []struct {
+		name string
+		diff string
+	}{
+		{"no hunks", "This is not a valid diff"},
+		{"bad header", "@@ -a +b @@\n-x\n+y\n"},
+		{"truncated hunk", "@@ -1,3 +1,3 @@\n a\n-b\n"},
+		{"bad line prefix", "@@ -1 +1 @@\n*x\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, engine.Validate(tt.diff))
+		})
+	}
+}
+
+func TestUnifiedDiffEngine_GitApplyCompatible(t *testing.T) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+
+	tmpDir := t.TempDir()
+	oldContent := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n"
+	newContent := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello, world\")\n}\n"
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(oldContent), 0644))
+
+	diff := fsops.NewUnifiedDiffEngine().Diff("main.go", oldContent, newContent)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "change.patch"), []byte(diff), 0644))
+
+	cmd := exec.Command(gitPath, "apply", "change.patch")
+	cmd.Dir = tmpDir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	got, err := os.ReadFile(filepath.Join(tmpDir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, newContent, string(got))
+}
+
+func TestFileOps_DMPDiffEngine(t *testing.T) {
+	rootDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	engine := fsops.NewDMPDiffEngine()
+	ops, err := fsops.New(fsops.Config{
+		RootDir:    rootDir,
+		Logger:     fsops.NewMemoryLogger(),
+		DiffEngine: engine,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, ops.WriteFile(ctx, "f.txt", "Hello, World!"))
+
+	patch, err := ops.GeneratePatch(ctx, "f.txt", "Hello, World!", "Hello, Go!")
+	require.NoError(t, err)
+	assert.False(t, strings.HasPrefix(patch.Diff, "---"), "dmp patches have no file headers")
+	require.NoError(t, ops.ValidatePatch(ctx, patch))
+	require.NoError(t, ops.ApplyPatch(ctx, patch))
+
+	content, err := ops.ReadFile(ctx, "f.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, Go!", content)
+}
+
+func TestFileOps_ApplyUnifiedDiffFromTooling(t *testing.T) {
+	rootDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	ops, err := fsops.New(fsops.Config{
+		RootDir: rootDir,
+		Logger:  fsops.NewMemoryLogger(),
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, ops.WriteFile(ctx, "main.go", "package main\n\nfunc main() {}\n"))
+
+	// Diff as produced by git diff, including extended headers
+	patch := models.Patch{
+		TargetFile: "main.go",
+		Diff: "diff --git a/main.go b/main.go\n" +
+			"index 1111111..2222222 100644\n" +
+			"--- a/main.go\n" +
+			"+++ b/main.go\n" +
+			"@@ -1,3 +1,4 @@\n" +
+			" package main\n" +
+			" \n" +
+			"+// main is the entry point\n" +
+			" func main() {}\n",
+	}
+
+	require.NoError(t, ops.ValidatePatch(ctx, patch))
+	require.NoError(t, ops.ApplyPatch(ctx, patch))
+
+	content, err := ops.ReadFile(ctx, "main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\n// main is the entry point\nfunc main() {}\n", content)
+}
+
+func TestNewDiffEngineByName(t *testing.T) {
+	for _, name := range []string{"unified", "dmp"} {
+		engine, err := fsops.NewDiffEngineByName(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, engine.Name())
+	}
+
+	_, err := fsops.NewDiffEngineByName("git-binary")
+	assert.Error(t, err)
+}
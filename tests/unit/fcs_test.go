@@ -316,3 +316,361 @@ func TestFCS_DetectCyclicDependencies(t *testing.T) {
 		})
 	}
 }
+
+func TestEnum_ConstName(t *testing.T) {
+	e := models.Enum{Name: "OrderStatus"}
+
+	assert.Equal(t, "OrderStatusPending", e.ConstName("pending"))
+	assert.Equal(t, "OrderStatusInProgress", e.ConstName("in_progress"))
+	assert.Equal(t, "OrderStatusInProgress", e.ConstName("IN-PROGRESS"))
+	assert.Equal(t, "OrderStatusOnHold", e.ConstName("onHold"))
+	assert.Equal(t, "ParseOrderStatus", e.ParseFunc())
+	assert.Equal(t, "string", e.Backing())
+}
+
+func TestDataModel_EnumAttributes(t *testing.T) {
+	dm := models.DataModel{
+		Enums: []models.Enum{{Name: "OrderStatus", Values: []string{"pending"}}},
+	}
+	entity := models.Entity{
+		Name: "Order",
+		Attributes: map[string]string{
+			"id":       "string",
+			"status":   "OrderStatus",
+			"history":  "[]*domain.OrderStatus",
+			"statuses": "map[string]OrderStatus",
+		},
+	}
+
+	assert.Equal(t, map[string]string{
+		"status":   "OrderStatus",
+		"history":  "OrderStatus",
+		"statuses": "OrderStatus",
+	}, dm.EnumAttributes(entity))
+}
+
+func TestDataModel_ValidateEnums(t *testing.T) {
+	tests := []struct {
+		name    string
+		enums   []models.Enum
+		wantErr string
+	}{
+		{"valid", []models.Enum{{Name: "Level", BackingType: "uint8", Values: []string{"low", "high"}}}, ""},
+		{"unexported name", []models.Enum{{Name: "level", Values: []string{"low"}}}, "must be an exported Go identifier"},
+		{"no values", []models.Enum{{Name: "Level"}}, "must have at least one value"},
+		{"value without letters", []models.Enum{{Name: "Level", Values: []string{"--"}}}, "has no letters or digits"},
+		{"duplicate", []models.Enum{{Name: "Level", Values: []string{"low"}}, {Name: "Level", Values: []string{"low"}}}, "defined more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := models.DataModel{Enums: tt.enums}.ValidateEnums()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCrossCutting_Ordered(t *testing.T) {
+	cc := models.CrossCutting{Concerns: []models.Concern{
+		{Name: models.ConcernAuth},
+		{Name: models.ConcernLogging},
+		{Name: models.ConcernRateLimit},
+	}}
+
+	var funcs []string
+	for _, concern := range cc.Ordered() {
+		funcs = append(funcs, concern.Func())
+	}
+	assert.Equal(t, []string{"Logging", "RateLimit", "Auth"}, funcs, "outermost first")
+	assert.Equal(t, models.DefaultMiddlewarePackage, cc.MiddlewarePackage())
+
+	cc.Package = "./internal/http/middleware/"
+	assert.Equal(t, "internal/http/middleware", cc.MiddlewarePackage())
+}
+
+func TestCrossCutting_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cc      models.CrossCutting
+		wantErr string
+	}{
+		{"valid", models.CrossCutting{Concerns: []models.Concern{{Name: "cors"}, {Name: "auth"}}}, ""},
+		{"unsupported", models.CrossCutting{Concerns: []models.Concern{{Name: "caching"}}}, `unsupported concern "caching"`},
+		{"duplicate", models.CrossCutting{Concerns: []models.Concern{{Name: "auth"}, {Name: "auth"}}}, "listed more than once"},
+		{"absolute package", models.CrossCutting{Package: "/srv/middleware"}, "must be relative"},
+		{"package outside project", models.CrossCutting{Package: "../shared/middleware"}, "must not leave"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cc.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestFileTree_Validate(t *testing.T) {
+	packages := []models.Package{
+		{Name: "store", Path: "github.com/acme/app/internal/store"},
+		{Name: "api", Path: "internal/api"},
+	}
+	files := func(paths ...string) models.FileTree {
+		tree := models.FileTree{}
+		for _, p := range paths {
+			tree.Files = append(tree.Files, models.File{Path: p})
+		}
+		return tree
+	}
+
+	tests := []struct {
+		name     string
+		tree     models.FileTree
+		packages []models.Package
+		wantErr  string
+	}{
+		{"valid", files("cmd/app/main.go", "internal/store/store.go", "internal/api/api.go", "internal/api/api_test.go", "README.md"), packages, ""},
+		{"no packages to check", files("pkg/x/x.go"), nil, ""},
+		{"empty", models.FileTree{}, packages, "lists no files"},
+		{"absolute path", files("/srv/main.go"), nil, "must be relative"},
+		{"path outside project", files("../shared/x.go"), nil, "must not leave"},
+		{"duplicate", files("internal/api/api.go", "./internal/api/api.go"), nil, "more than once"},
+		{"package without files", files("internal/api/api.go"), packages, "package store (github.com/acme/app/internal/store) has no Go file"},
+		{"directory outside architecture", files("internal/store/store.go", "internal/api/api.go", "internal/util/util.go"), packages, "directory internal/util is not a package"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tree.Validate(tt.packages)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestFCS_ValidateFileTree(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{
+		Architecture: models.Architecture{Packages: []models.Package{{Name: "store", Path: "internal/store"}}},
+		FileTree:     &models.FileTree{Files: []models.File{{Path: "internal/api/api.go"}}},
+	}
+	assert.ErrorContains(t, fcs.Validate(), "invalid file tree")
+
+	fcs.FileTree = &models.FileTree{Files: []models.File{{Path: "internal/store/store.go"}}}
+	assert.NoError(t, fcs.Validate())
+}
+
+func TestOutputContract_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		contract models.OutputContract
+		wantErr  string
+	}{
+		{"file", models.OutputContract{File: "cmd/server/main.go"}, ""},
+		{"exports", models.OutputContract{Package: "internal/store", Exports: "Repository", Kind: models.ContractKindInterface}, ""},
+		{"route", models.OutputContract{Route: "get /users/{id}"}, ""},
+		{"nothing set", models.OutputContract{Description: "x"}, "exactly one of"},
+		{"two assertions", models.OutputContract{File: "main.go", Route: "GET /"}, "exactly one of"},
+		{"exports without package", models.OutputContract{Exports: "Repository"}, "both package and exports"},
+		{"unexported identifier", models.OutputContract{Package: "internal/store", Exports: "repository"}, "exported Go identifier"},
+		{"unsupported kind", models.OutputContract{Package: "internal/store", Exports: "Repository", Kind: "class"}, `unsupported kind "class"`},
+		{"file outside project", models.OutputContract{File: "../main.go"}, "must not leave"},
+		{"unknown method", models.OutputContract{Route: "FETCH /users"}, "must start with one of"},
+		{"route without path", models.OutputContract{Route: "GET users"}, "path starting with /"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.contract.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestOutputContract_String(t *testing.T) {
+	assert.Equal(t, "file go.mod exists", models.OutputContract{File: "go.mod"}.String())
+	assert.Equal(t, "internal/store exports interface Repository", models.OutputContract{Package: "internal/store", Exports: "Repository", Kind: "interface"}.String())
+	assert.Equal(t, "internal/store exports New", models.OutputContract{Package: "internal/store", Exports: "New"}.String())
+	assert.Equal(t, "GET /users is routed", models.OutputContract{Route: "GET /users"}.String())
+}
+
+func TestAPIContract_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		contract models.APIContract
+		wantErr  string
+	}{
+		{"http", models.APIContract{Endpoint: "/users", Method: "GET"}, ""},
+		{"grpc unary", models.APIContract{Protocol: "grpc", Service: "orders.v1.OrderService", Method: "CreateOrder"}, ""},
+		{"grpc streaming", models.APIContract{Protocol: "grpc", Service: "OrderService", Method: "WatchOrders", Streaming: "server"}, ""},
+		{"unknown protocol", models.APIContract{Protocol: "soap", Method: "Get"}, `unsupported protocol "soap"`},
+		{"grpc without service", models.APIContract{Protocol: "grpc", Method: "CreateOrder"}, "exported identifier"},
+		{"unexported method", models.APIContract{Protocol: "grpc", Service: "OrderService", Method: "createOrder"}, "must be an exported identifier"},
+		{"unknown streaming", models.APIContract{Protocol: "grpc", Service: "OrderService", Method: "Sync", Streaming: "both"}, `unsupported streaming "both"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.contract.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestFCS_GRPCServices(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{APIContracts: []models.APIContract{
+		{Endpoint: "/health", Method: "GET"},
+		{Protocol: "grpc", Service: "orders.v1.OrderService", Method: "CreateOrder"},
+		{Protocol: "grpc", Service: "InventoryService", Method: "Reserve", Request: models.ContractSchema{Message: "Reservation"}},
+		{Protocol: "grpc", Service: "orders.v1.OrderService", Method: "WatchOrders", Streaming: "server",
+			Response: models.ContractSchema{Message: "Order"}},
+	}}
+
+	assert.True(t, fcs.HasGRPC())
+	services := fcs.GRPCServices()
+	require.Len(t, services, 2)
+
+	inventory := services[0]
+	assert.Equal(t, "inventory.v1.InventoryService", inventory.FullName(), "an unqualified service gets a package")
+	assert.Equal(t, "proto/inventory/v1/inventory_service.proto", inventory.ProtoPath())
+	assert.Equal(t, "rpc Reserve(Reservation) returns (ReserveResponse)", inventory.Methods[0].Signature())
+
+	orders := services[1]
+	assert.Equal(t, "gen/orders/v1", orders.GoPackageDir())
+	require.Len(t, orders.Methods, 2)
+	assert.Equal(t, "rpc WatchOrders(WatchOrdersRequest) returns (stream Order)", orders.Methods[1].Signature())
+	assert.Equal(t, "orders.v1.OrderService/CreateOrder", orders.Methods[0].Key())
+	assert.Equal(t, "GET /health", fcs.APIContracts[0].Key())
+
+	assert.False(t, (&models.FinalClarifiedSpecification{}).HasGRPC())
+}
+
+func TestFCS_HTTPRoutes(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{APIContracts: []models.APIContract{
+		{Endpoint: "/users", Method: "get"},
+		{Endpoint: "/users/:id/", Method: "GET", Description: "Get a user"},
+		{Endpoint: "/users/{id}", Method: "GET"},
+		{Endpoint: "/orders/{order_id}/line-items", Method: "POST"},
+		{Protocol: "grpc", Service: "orders.v1.OrderService", Method: "CreateOrder"},
+	}}
+
+	routes := fcs.HTTPRoutes()
+	require.Len(t, routes, 3, "gRPC methods and repeated routes are left out")
+	assert.Equal(t, models.HTTPRoute{Method: "GET", Path: "/users", Handler: "GetUsers"}, routes[0])
+	assert.Equal(t, models.HTTPRoute{Method: "GET", Path: "/users/{id}", Handler: "GetUsersByID", Description: "Get a user"}, routes[1])
+	assert.Equal(t, "PostOrdersByOrderIDLineItems", routes[2].Handler)
+
+	assert.Equal(t, "/orders/{order_id}/line-items", routes[2].Pattern(models.HTTPFrameworkChi))
+	assert.Equal(t, "/orders/:order_id/line-items", routes[2].Pattern(models.HTTPFrameworkGin))
+	assert.Equal(t, "/users/:id", routes[1].Pattern(models.HTTPFrameworkEcho))
+
+	root := (&models.FinalClarifiedSpecification{APIContracts: []models.APIContract{{Endpoint: "/"}}}).HTTPRoutes()
+	require.Len(t, root, 1)
+	assert.Equal(t, models.HTTPRoute{Method: "GET", Path: "/", Handler: "GetRoot"}, root[0])
+	assert.Equal(t, "/{$}", root[0].Pattern(models.HTTPFrameworkStdlib), "/ matches every path in net/http")
+	assert.Equal(t, "/", root[0].Pattern(models.HTTPFrameworkChi))
+
+	assert.Equal(t, models.HTTPFrameworkStdlib, models.Architecture{}.Framework())
+	assert.Equal(t, models.HTTPFrameworkGin, models.Architecture{HTTPFramework: "gin"}.Framework())
+	assert.True(t, models.IsHTTPFramework("net/http"))
+	assert.False(t, models.IsHTTPFramework("fiber"))
+}
+
+func TestBuildConfig_Validate(t *testing.T) {
+	deps := []models.Dependency{{Name: "github.com/go-chi/chi/v5", Version: "v5.1.0"}}
+	shared := models.ModuleConfig{Dir: "shared", Path: "example.com/shop/shared"}
+	tests := []struct {
+		name    string
+		modules []models.ModuleConfig
+		wantErr string
+	}{
+		{"single module", nil, ""},
+		{"workspace", []models.ModuleConfig{shared, {Dir: "services/api", Path: "example.com/shop/api",
+			Requires: []string{"example.com/shop/shared", "github.com/go-chi/chi/v5"}}}, ""},
+		{"no path", []models.ModuleConfig{{Dir: "api"}}, "has no module path"},
+		{"duplicate path", []models.ModuleConfig{shared, {Dir: "other", Path: "example.com/shop/shared"}}, "declared more than once"},
+		{"root dir", []models.ModuleConfig{{Dir: ".", Path: "example.com/shop"}}, "below the project root"},
+		{"outside", []models.ModuleConfig{{Dir: "../api", Path: "example.com/shop/api"}}, "below the project root"},
+		{"shared dir", []models.ModuleConfig{shared, {Dir: "shared", Path: "example.com/shop/api"}}, "holds another module"},
+		{"nested", []models.ModuleConfig{shared, {Dir: "shared/api", Path: "example.com/shop/api"}}, "nested in module example.com/shop/shared"},
+		{"unknown require", []models.ModuleConfig{{Dir: "api", Path: "example.com/shop/api", Requires: []string{"example.com/shop/billing"}}},
+			"neither a module of the project nor an architecture dependency"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := models.BuildConfig{Modules: tt.modules}.Validate(deps)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBuildConfig_ModuleOf(t *testing.T) {
+	build := models.BuildConfig{Modules: []models.ModuleConfig{
+		{Dir: "services/api", Path: "example.com/shop/api"},
+		{Dir: "shared", Path: "example.com/shop/shared"},
+	}}
+
+	module, ok := build.ModuleOf("services/api/internal/handler.go")
+	require.True(t, ok)
+	assert.Equal(t, "example.com/shop/api", module.Path)
+	assert.Equal(t, "services/api/go.mod", module.GoModPath())
+
+	_, ok = build.ModuleOf("services/apis/main.go")
+	assert.False(t, ok)
+}
+
+func TestFCS_StructuralProblems(t *testing.T) {
+	fcs := &models.FinalClarifiedSpecification{
+		Requirements: models.Requirements{
+			Functional:    []models.FunctionalRequirement{{ID: "FR-001"}, {ID: ""}},
+			NonFunctional: []models.NonFunctionalRequirement{{ID: "FR-001", Type: "security"}},
+		},
+		Architecture: models.Architecture{Packages: []models.Package{
+			{Name: "models", Path: "internal/domain"},
+			{Name: "api", Path: "internal/api", Dependencies: []string{"models", "billing"}},
+		}},
+		DataModel: models.DataModel{
+			Entities: []models.Entity{
+				{Name: "User", Package: "domain", Attributes: map[string]string{"id": "uuid.UUID", "role": "Role", "tags": "[]string"}},
+				{Name: "Order", Package: "orders", Attributes: map[string]string{"buyer": "*User", "lines": "[]LineItem"}},
+				{Name: "User", Package: "models"},
+			},
+			Relationships: []models.Relationship{{From: "User", To: "Invoice", Type: "one-to-many"}},
+			Enums:         []models.Enum{{Name: "Role", Values: []string{"admin"}}},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"functional requirement 2 has no ID",
+		"requirement ID FR-001 is used more than once",
+		"package api depends on undeclared package billing",
+		"entity Order is in undeclared package orders",
+		"entity User is defined more than once",
+		"attribute Order.lines has unknown type LineItem",
+		"relationship User -> Invoice references unknown entity Invoice",
+	}, fcs.StructuralProblems())
+
+	assert.Empty(t, (&models.FinalClarifiedSpecification{}).StructuralProblems())
+}
@@ -0,0 +1,153 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/fcsdump"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func newDumpTestFCS() *models.FinalClarifiedSpecification {
+	return &models.FinalClarifiedSpecification{
+		SchemaVersion:  "1.0",
+		ID:             "fcs-1",
+		Version:        "1.0",
+		OriginalSpecID: "spec-1",
+		Metadata: models.FCSMetadata{
+			CreatedAt:      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			OriginalSpec:   "name: acme\nsecret roadmap",
+			Clarifications: []models.AppliedClarification{{QuestionID: "q1", Answer: "Use Postgres", AppliedTo: "architecture"}},
+			Hash:           "abc123",
+		},
+		Requirements: models.Requirements{
+			Functional: []models.FunctionalRequirement{{ID: "FR-001", Description: "Price | discount engine", Priority: "high"}},
+		},
+		Architecture: models.Architecture{
+			Packages: []models.Package{{Name: "models", Path: "internal/models", Purpose: "Domain types"}},
+		},
+		DataModel: models.DataModel{
+			Entities:      []models.Entity{{Name: "User", Package: "models", Attributes: map[string]string{"name": "string", "id": "int"}}},
+			Relationships: []models.Relationship{{From: "User", To: "Order", Type: "one-to-many", Description: "Customer orders"}},
+		},
+		TestingStrategy: models.TestingStrategy{CoverageTarget: 80, UnitTests: true},
+		BuildConfig:     models.BuildConfig{GoVersion: "1.22", OutputPath: "./bin"},
+	}
+}
+
+func TestParseSections(t *testing.T) {
+	sections, err := fcsdump.ParseSections([]string{"build_config", "data-model", "build_config"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"data_model", "build_config"}, sections, "document order without duplicates")
+
+	_, err = fcsdump.ParseSections([]string{"secrets"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "data_model")
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"json", "YAML", "markdown", "md", "table"} {
+		_, err := fcsdump.ParseFormat(name)
+		assert.NoError(t, err, name)
+	}
+	_, err := fcsdump.ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestRender_JSONSections(t *testing.T) {
+	var buf bytes.Buffer
+	err := fcsdump.Render(&buf, newDumpTestFCS(), fcsdump.Options{Sections: []string{"build_config", "data_model"}})
+	require.NoError(t, err)
+
+	var got map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Len(t, got, 2)
+	assert.Contains(t, got, "data_model")
+	assert.Less(t, strings.Index(buf.String(), "data_model"), strings.Index(buf.String(), "build_config"))
+
+	buf.Reset()
+	require.NoError(t, fcsdump.Render(&buf, newDumpTestFCS(), fcsdump.Options{}))
+	var full models.FinalClarifiedSpecification
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &full))
+	assert.Equal(t, "fcs-1", full.ID, "no sections renders the whole FCS")
+}
+
+func TestRender_YAMLKeepsJSONKeys(t *testing.T) {
+	var buf bytes.Buffer
+	err := fcsdump.Render(&buf, newDumpTestFCS(), fcsdump.Options{Format: fcsdump.FormatYAML, Sections: []string{"build_config"}})
+	require.NoError(t, err)
+
+	var got map[string]map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "1.22", got["build_config"]["go_version"], "numeric-looking strings stay strings")
+	assert.Equal(t, "./bin", got["build_config"]["output_path"])
+}
+
+func TestRender_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	err := fcsdump.Render(&buf, newDumpTestFCS(), fcsdump.Options{Format: fcsdump.FormatMarkdown, Sections: []string{"requirements", "data_model"}})
+	require.NoError(t, err)
+	out := buf.String()
+
+	assert.Contains(t, out, "## Requirements")
+	assert.Contains(t, out, `| FR-001 | high |  | Price \| discount engine |`)
+	assert.Contains(t, out, "### User (package models)")
+	assert.Less(t, strings.Index(out, "| id | int |"), strings.Index(out, "| name | string |"), "attributes sorted by name")
+	assert.NotContains(t, out, "## Metadata")
+}
+
+func TestRender_Table(t *testing.T) {
+	var buf bytes.Buffer
+	err := fcsdump.Render(&buf, newDumpTestFCS(), fcsdump.Options{Format: fcsdump.FormatTable, Sections: []string{"architecture"}})
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), "ARCHITECTURE")
+	assert.Regexp(t, `NAME\s+PATH\s+DEPENDENCIES\s+PURPOSE`, buf.String())
+	assert.Regexp(t, `models\s+internal/models\s+Domain types`, buf.String())
+}
+
+func TestRender_Redact(t *testing.T) {
+	fcs := newDumpTestFCS()
+
+	var buf bytes.Buffer
+	require.NoError(t, fcsdump.Render(&buf, fcs, fcsdump.Options{Redact: true}))
+	out := buf.String()
+
+	for _, secret := range []string{"secret roadmap", "Use Postgres", "discount engine", "Domain types", "Customer orders"} {
+		assert.NotContains(t, out, secret)
+	}
+	assert.Contains(t, out, "FR-001")
+	assert.Contains(t, out, `"User"`)
+	assert.Contains(t, out, fcsdump.RedactedText)
+	assert.Equal(t, "Domain types", fcs.Architecture.Packages[0].Purpose, "the input is not modified")
+}
+
+func TestRender_Conflicts(t *testing.T) {
+	fcs := newDumpTestFCS()
+	fcs.Metadata.Sources = []string{"product.md", "security.md"}
+	fcs.Metadata.Conflicts = []models.SpecConflict{{
+		ID:   "conflict-1",
+		Path: "requirements[FR-001].description",
+		Sources: []models.ConflictSource{
+			{Spec: "product.md", Value: "Guests check out"},
+			{Spec: "security.md", Value: "Only members check out"},
+		},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, fcsdump.Render(&buf, fcs, fcsdump.Options{Format: fcsdump.FormatMarkdown, Sections: []string{"metadata"}}))
+	out := buf.String()
+	assert.Contains(t, out, "product.md, security.md")
+	assert.Contains(t, out, "| conflict-1 | requirements[FR-001].description | product.md: Guests check out; security.md: Only members check out | (unresolved) |")
+
+	buf.Reset()
+	require.NoError(t, fcsdump.Render(&buf, fcs, fcsdump.Options{Redact: true}))
+	assert.NotContains(t, buf.String(), "check out")
+	assert.Contains(t, buf.String(), "requirements[FR-001].description")
+}
@@ -0,0 +1,188 @@
+package unit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/fcsedit"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEditTestFCS(t *testing.T) *models.FinalClarifiedSpecification {
+	t.Helper()
+	fcs := &models.FinalClarifiedSpecification{
+		SchemaVersion:  models.FCSSchemaVersion,
+		ID:             "fcs-1",
+		Version:        "1.0",
+		OriginalSpecID: "spec-1",
+		Metadata:       models.FCSMetadata{CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), OriginalSpec: "name: shop"},
+		Requirements: models.Requirements{
+			Functional: []models.FunctionalRequirement{{ID: "FR-001", Description: "Customers place orders", Priority: "high"}},
+		},
+		Architecture: models.Architecture{
+			Packages: []models.Package{
+				{Name: "models", Path: "internal/models"},
+				{Name: "api", Path: "internal/api", Dependencies: []string{"models"}},
+			},
+		},
+		DataModel: models.DataModel{
+			Entities: []models.Entity{{Name: "User", Package: "models", Attributes: map[string]string{"id": "uuid.UUID", "email": "string"}}},
+			Enums:    []models.Enum{{Name: "OrderStatus", Values: []string{"pending", "paid"}}},
+		},
+		BuildConfig: models.BuildConfig{GoVersion: "1.22", OutputPath: "./bin"},
+	}
+	hash, err := fcs.ComputeHash()
+	require.NoError(t, err)
+	fcs.Metadata.Hash = hash
+	return fcs
+}
+
+// assertRehashed checks an edited FCS passes validation with its new hash
+func assertRehashed(t *testing.T, original, edited *models.FinalClarifiedSpecification) {
+	t.Helper()
+	assert.NotEqual(t, original.Metadata.Hash, edited.Metadata.Hash)
+	assert.False(t, edited.Metadata.UpdatedAt.IsZero())
+	assert.NoError(t, edited.Validate())
+}
+
+func TestFCSEdit_AddEntity(t *testing.T) {
+	fcs := newEditTestFCS(t)
+
+	edited, err := fcsedit.AddEntity(fcs, models.Entity{
+		Name:       "Order",
+		Package:    "models",
+		Attributes: map[string]string{"buyer": "*User", "status": "OrderStatus", "placed_at": "time.Time"},
+	})
+	require.NoError(t, err)
+	require.Len(t, edited.DataModel.Entities, 2)
+	assert.Len(t, fcs.DataModel.Entities, 1, "the original is not changed")
+	assertRehashed(t, fcs, edited)
+
+	tests := []struct {
+		name   string
+		entity models.Entity
+		want   string
+	}{
+		{"duplicate entity", models.Entity{Name: "User"}, "entity User already exists"},
+		{"enum name", models.Entity{Name: "OrderStatus"}, "enum OrderStatus already exists"},
+		{"undeclared package", models.Entity{Name: "Order", Package: "billing"}, "undeclared package billing"},
+		{"unknown type", models.Entity{Name: "Order", Attributes: map[string]string{"items": "[]LineItem"}}, "attribute Order.items has unknown type LineItem"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fcsedit.AddEntity(fcs, tt.entity)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestFCSEdit_AddRequirement(t *testing.T) {
+	fcs := newEditTestFCS(t)
+
+	edited, err := fcsedit.AddFunctionalRequirement(fcs, models.FunctionalRequirement{Description: "Customers cancel orders"})
+	require.NoError(t, err)
+	assert.Equal(t, "FR-002", edited.Requirements.Functional[1].ID, "the next free ID")
+	assertRehashed(t, fcs, edited)
+
+	edited, err = fcsedit.AddNonFunctionalRequirement(edited, models.NonFunctionalRequirement{Description: "p99 under 200ms", Type: "performance"})
+	require.NoError(t, err)
+	assert.Equal(t, "NFR-001", edited.Requirements.NonFunctional[0].ID)
+
+	_, err = fcsedit.AddNonFunctionalRequirement(edited, models.NonFunctionalRequirement{ID: "FR-001", Description: "Duplicate", Type: "security"})
+	assert.ErrorContains(t, err, "requirement FR-001 already exists", "IDs are unique across both kinds")
+	_, err = fcsedit.AddNonFunctionalRequirement(edited, models.NonFunctionalRequirement{Description: "Untyped"})
+	assert.ErrorContains(t, err, "needs a type")
+	_, err = fcsedit.AddFunctionalRequirement(edited, models.FunctionalRequirement{ID: "FR-010"})
+	assert.ErrorContains(t, err, "needs a description")
+}
+
+func TestFCSEdit_Set(t *testing.T) {
+	fcs := newEditTestFCS(t)
+
+	edited, err := fcsedit.Set(fcs, "requirements.functional[FR-001].priority", "low")
+	require.NoError(t, err)
+	assert.Equal(t, "low", edited.Requirements.Functional[0].Priority)
+	assertRehashed(t, fcs, edited)
+
+	edited, err = fcsedit.Set(edited, "build_config.persistence", "gorm")
+	require.NoError(t, err)
+	assert.Equal(t, models.PersistenceGORM, edited.BuildConfig.Persistence, "a missing key is added")
+
+	edited, err = fcsedit.Set(edited, "data_model.enums[OrderStatus].values", "[pending, paid, shipped]")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pending", "paid", "shipped"}, edited.DataModel.Enums[0].Values)
+
+	tests := []struct {
+		name, path, value, want string
+	}{
+		{"dependency cycle", "architecture.packages[models].dependencies", `["api"]`, "package dependencies are cyclic"},
+		{"undeclared dependency", "architecture.packages[api].dependencies", "[models, billing]", "depends on undeclared package billing"},
+		{"renamed package", "architecture.packages[models].name", "domain", "package api depends on undeclared package models"},
+		{"empty requirement ID", "requirements.functional[FR-001].id", " ", "functional requirement 1 has no ID"},
+		{"misspelled key", "build_config.persistance", "gorm", "persistance"},
+		{"wrong type", "testing_strategy.coverage_target", "high", "coverage_target"},
+		{"unknown item", "requirements.functional[FR-404].priority", "low", "requirements.functional[FR-404] not found"},
+		{"hash", "metadata.hash", "abc", "recomputed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fcsedit.Set(fcs, tt.path, tt.value)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestFCSEdit_ExistingProblemsDoNotBlock(t *testing.T) {
+	fcs := newEditTestFCS(t)
+	fcs.DataModel.Relationships = []models.Relationship{{From: "User", To: "Order", Type: "one-to-many"}}
+	require.Equal(t, []string{"relationship User -> Order references unknown entity Order"}, fcs.StructuralProblems())
+
+	edited, err := fcsedit.Set(fcs, "requirements.functional[FR-001].description", "Customers place and pay for orders")
+	require.NoError(t, err, "edits are refused only for problems they introduce")
+
+	edited, err = fcsedit.AddEntity(edited, models.Entity{Name: "Order", Package: "models"})
+	require.NoError(t, err)
+	assert.Empty(t, edited.StructuralProblems())
+}
+
+func TestFCSEdit_ReplaceAndWrite(t *testing.T) {
+	fcs := newEditTestFCS(t)
+	data, err := json.Marshal(fcs)
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	doc["data_model"].(map[string]interface{})["relationships"] = []interface{}{
+		map[string]interface{}{"from": "User", "to": "Account", "type": "one-to-one"},
+	}
+	broken, err := json.Marshal(doc)
+	require.NoError(t, err)
+	_, err = fcsedit.Replace(fcs, broken)
+	var editErr *fcsedit.Error
+	require.ErrorAs(t, err, &editErr)
+	assert.Equal(t, []string{"relationship User -> Account references unknown entity Account"}, editErr.Problems)
+
+	edited, err := fcsedit.Replace(fcs, data)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "fcs.json")
+	require.NoError(t, fcsedit.Write(path, edited))
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	loaded, _, err := schema.LoadFCS(written)
+	require.NoError(t, err)
+	assert.Equal(t, edited.Metadata.Hash, loaded.Metadata.Hash)
+	assert.NoError(t, loaded.Validate())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left behind")
+}
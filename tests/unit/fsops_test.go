@@ -43,6 +43,14 @@ func TestNew(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative max open files",
+			cfg: fsops.Config{
+				RootDir:      t.TempDir(),
+				MaxOpenFiles: -1,
+			},
+			wantErr: true,
+		},
 		{
 			name: "nil logger (should use noop)",
 			cfg: fsops.Config{
@@ -474,3 +482,39 @@ func TestConcurrentOperations(t *testing.T) {
 		assert.True(t, exists)
 	}
 }
+
+func TestConcurrentOperations_MaxOpenFiles(t *testing.T) {
+	ops, err := fsops.New(fsops.Config{
+		RootDir:      t.TempDir(),
+		Logger:       fsops.NewMemoryLogger(),
+		MaxOpenFiles: 1,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, ops.WriteFile(ctx, "existing.txt", "old"))
+
+	// Every operation gets a turn; backups and reads inside a write do not
+	// wait on the slot the write holds
+	done := make(chan error)
+	for i := 0; i < 10; i++ {
+		go func(index int) {
+			path := filepath.Join("capped", "file"+string(rune('0'+index))+".txt")
+			if index%2 == 0 {
+				done <- ops.AtomicWrite(ctx, path, "atomic")
+				return
+			}
+			done <- ops.WriteFile(ctx, path, "plain")
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, <-done)
+	}
+
+	patch, err := ops.GeneratePatch(ctx, "existing.txt", "old", "new")
+	require.NoError(t, err)
+	require.NoError(t, ops.ApplyPatchWithBackup(ctx, patch))
+	content, err := ops.ReadFile(ctx, "existing.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "new", content)
+}
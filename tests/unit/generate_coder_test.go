@@ -126,14 +126,42 @@ func TestCoder_GenerateFile(t *testing.T) {
 				TargetPath: "./output/test.go",
 			},
 			plan:        createTestGenerationPlan(),
-			llmResponse: "```go\npackage test\n\nfunc Test() {}\n```",
+			llmResponse: "```go\npackage output\n\nfunc Test() {}\n```",
 			wantErr:     false,
 			validatePatch: func(t *testing.T, patch models.Patch) {
 				// Should strip markdown code blocks
-				assert.Contains(t, patch.Diff, "+package test")
+				assert.Contains(t, patch.Diff, "+package output")
 				assert.NotContains(t, patch.Diff, "```")
 			},
 		},
+		{
+			name: "generate with prose around the code",
+			task: models.GenerationTask{
+				ID:         "generate_file",
+				Type:       "generate_file",
+				TargetPath: "./output/test.go",
+			},
+			plan:        createTestGenerationPlan(),
+			llmResponse: "Here is the file:\n\n```go\npackage test\n\nfunc Test() string { return strings.TrimSpace(\" x \") }\n```\n\nIt trims.",
+			wantErr:     false,
+			validatePatch: func(t *testing.T, patch models.Patch) {
+				// Prose is stripped, the package follows the directory and imports are fixed
+				assert.Contains(t, patch.Diff, "+package output\n+\n+import \"strings\"\n")
+				assert.NotContains(t, patch.Diff, "Here is")
+				assert.NotContains(t, patch.Diff, "It trims")
+			},
+		},
+		{
+			name: "reject code that does not parse",
+			task: models.GenerationTask{
+				ID:         "generate_file",
+				Type:       "generate_file",
+				TargetPath: "./output/test.go",
+			},
+			plan:        createTestGenerationPlan(),
+			llmResponse: "package output\n\nfunc Test( {}\n",
+			wantErr:     true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -3,7 +3,9 @@ package unit
 import (
 	"context"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dshills/gocreator/internal/generate"
 	"github.com/dshills/gocreator/internal/models"
@@ -25,7 +27,7 @@ func (m *mockEngineLLMClient) Generate(ctx context.Context, prompt string) (stri
 	switch {
 	case contains(prompt, "generation plan"):
 		return m.planResponse, nil
-	case contains(prompt, "test"):
+	case contains(prompt, "writing comprehensive tests"):
 		return m.testResponse, nil
 	default:
 		return m.codeResponse, nil
@@ -49,7 +51,7 @@ func (m *mockEngineLLMClient) Model() string {
 }
 
 func contains(s, substr string) bool {
-	return len(s) > 0 && len(substr) > 0 && (s == substr || len(s) > len(substr))
+	return strings.Contains(s, substr)
 }
 
 func TestNewGenerationEngine(t *testing.T) {
@@ -177,6 +179,11 @@ func TestEngine_Generate(t *testing.T) {
 				if tt.validateOutput != nil {
 					tt.validateOutput(t, output)
 				}
+
+				manifest, err := generate.LoadManifest(tt.outputDir)
+				require.NoError(t, err)
+				require.NotNil(t, manifest, "generation records a manifest")
+				assert.Equal(t, generate.FileOwnerPrompt, manifest.Files["main.go"].Owner)
 			}
 		})
 	}
@@ -273,8 +280,162 @@ func TestEngine_OutputValidation(t *testing.T) {
 	}
 }
 
+func TestEngine_RegenerateDiffsAgainstExistingFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	mockClient := &mockEngineLLMClient{
+		planResponse: `{
+			"file_tree": {
+				"root": "` + tmpDir + `",
+				"directories": [],
+				"files": [{"path": "test.go", "purpose": "Test file", "generated_by": "gen_test"}]
+			},
+			"phases": [{
+				"name": "phase1",
+				"order": 1,
+				"tasks": [{
+					"id": "gen_test",
+					"type": "generate_file",
+					"target_path": "test.go",
+					"can_parallel": false
+				}]
+			}]
+		}`,
+		codeResponse: "package test\n\nfunc Test() {}\n",
+	}
+
+	fileOps, err := fsops.New(fsops.Config{
+		RootDir: tmpDir,
+		Logger:  &noopFsLogger{},
+	})
+	require.NoError(t, err)
+	require.NoError(t, fileOps.WriteFile(context.Background(), "test.go", "package stale\n"))
+
+	engine, err := generate.NewEngine(generate.EngineConfig{
+		LLMClient: mockClient,
+		FileOps:   fileOps,
+	})
+	require.NoError(t, err)
+
+	output, err := engine.Generate(context.Background(), createCompleteTestFCS(), tmpDir)
+	require.NoError(t, err)
+
+	var patch *models.Patch
+	for i := range output.Patches {
+		if output.Patches[i].TargetFile == "test.go" {
+			patch = &output.Patches[i]
+		}
+	}
+	require.NotNil(t, patch)
+	assert.True(t, strings.HasPrefix(patch.Diff, "--- a/test.go\n+++ b/test.go\n"), "existing files should get a modification diff")
+	assert.Contains(t, patch.Diff, "-package stale\n")
+
+	content, err := fileOps.ReadFile(context.Background(), "test.go")
+	require.NoError(t, err)
+	assert.NotEqual(t, "package stale\n", content)
+	assert.True(t, strings.HasSuffix(content, "\n"))
+
+	// A second run over the same output has nothing to change
+	output, err = engine.Generate(context.Background(), createCompleteTestFCS(), tmpDir)
+	require.NoError(t, err)
+	for _, p := range output.Patches {
+		if p.TargetFile == "test.go" {
+			assert.Empty(t, p.Diff)
+		}
+	}
+}
+
 // Helper functions
 
+// blockingLLMClient blocks every call until its context is done
+type blockingLLMClient struct {
+	mockEngineLLMClient
+}
+
+func (b *blockingLLMClient) Generate(ctx context.Context, _ string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestEngine_PhaseTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	engine, err := generate.NewEngine(generate.EngineConfig{
+		LLMClient: &blockingLLMClient{},
+		FileOps:   createMockFileOps(t),
+		Timeouts:  generate.PhaseTimeouts{Plan: 50 * time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = engine.Generate(context.Background(), createCompleteTestFCS(), tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "create_plan exceeded timeout of 50ms")
+	assert.Less(t, time.Since(start), 5*time.Second, "planner should stop at the phase deadline")
+}
+
+func TestEngine_CancellationReachesLLMCalls(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	engine, err := generate.NewEngine(generate.EngineConfig{
+		LLMClient: &blockingLLMClient{},
+		FileOps:   createMockFileOps(t),
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = engine.Generate(ctx, createCompleteTestFCS(), tmpDir)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second, "cancelling the run context should stop the planner")
+}
+
+func TestEngine_ApprovePlan(t *testing.T) {
+	outputDir := t.TempDir()
+
+	fileOps, err := fsops.New(fsops.Config{RootDir: outputDir, Logger: &noopFsLogger{}})
+	require.NoError(t, err)
+
+	var reviewed string
+	engine, err := generate.NewEngine(generate.EngineConfig{
+		LLMClient: &mockEngineLLMClient{
+			planResponse: `{
+				"file_tree": {"root": "` + outputDir + `", "files": [{"path": "main.go", "generated_by": "gen_main"}]},
+				"phases": [{"name": "setup", "order": 1, "tasks": [{"id": "gen_main", "type": "generate_file", "target_path": "main.go"}]}]
+			}`,
+			codeResponse: "package main\n\nfunc main() {}\n",
+			testResponse: "package main\n",
+		},
+		FileOps:     fileOps,
+		RecordState: true,
+		ApprovePlan: func(_ context.Context, plan *models.GenerationPlan) (*models.GenerationPlan, error) {
+			reviewed = plan.Phases[0].Tasks[0].TargetPath
+
+			// The reviewer renames the file
+			edited := *plan
+			edited.FileTree.Files = []models.File{{Path: "app.go", GeneratedBy: "gen_main"}}
+			edited.Phases = []models.GenerationPhase{{Name: "setup", Order: 1, Tasks: []models.GenerationTask{
+				{ID: "gen_main", Type: "generate_file", TargetPath: "app.go"},
+			}}}
+			return &edited, nil
+		},
+	})
+	require.NoError(t, err)
+
+	output, err := engine.Generate(context.Background(), createCompleteTestFCS(), outputDir)
+	require.NoError(t, err)
+	assert.Equal(t, "main.go", reviewed)
+	assert.FileExists(t, filepath.Join(outputDir, "app.go"))
+	assert.NoFileExists(t, filepath.Join(outputDir, "main.go"))
+
+	transitions, err := generate.LoadStateTransitions(outputDir, output.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, "approve_plan", transitions[4].Node)
+	assert.Equal(t, "generate", transitions[4].Next)
+}
+
 func createMockFileOps(t *testing.T) fsops.FileOps {
 	tmpDir := t.TempDir()
 	fileOps, err := fsops.New(fsops.Config{
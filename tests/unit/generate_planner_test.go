@@ -2,6 +2,7 @@ package unit
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/dshills/gocreator/internal/generate"
@@ -290,8 +291,594 @@ func TestPlanner_PlanValidation(t *testing.T) {
 	}
 }
 
+func TestPlanner_ReplansWhenLimitsExceeded(t *testing.T) {
+	oversized := `{
+		"file_tree": {
+			"root": "./output",
+			"files": [
+				{"path": "a/b/c/d/e.go", "purpose": "Deeply nested"},
+				{"path": "internal/x.go", "purpose": "X"},
+				{"path": "internal/y.go", "purpose": "Y"}
+			]
+		},
+		"phases": [{"name": "setup", "order": 1, "tasks": []}]
+	}`
+	simplified := `{
+		"file_tree": {
+			"root": "./output",
+			"files": [{"path": "internal/x.go", "purpose": "X"}]
+		},
+		"phases": [{"name": "setup", "order": 1, "tasks": []}]
+	}`
+	limits := models.PlanLimits{MaxFiles: 2, MaxDepth: 3}
+
+	tests := []struct {
+		name        string
+		maxReplans  int
+		responses   []string
+		wantErr     bool
+		wantCalls   int
+		wantReplans int
+	}{
+		{name: "plan within limits", responses: []string{simplified}, wantCalls: 1},
+		{name: "simplified on re-plan", responses: []string{oversized, simplified}, wantCalls: 2, wantReplans: 1},
+		{name: "still too large after retries", maxReplans: 1, responses: []string{oversized, oversized}, wantErr: true, wantCalls: 2, wantReplans: 1},
+		{name: "re-planning disabled", maxReplans: -1, responses: []string{oversized}, wantErr: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prompts []string
+			client := &mockPlannerLLMClient{
+				generateFunc: func(ctx context.Context, prompt string) (string, error) {
+					prompts = append(prompts, prompt)
+					return tt.responses[len(prompts)-1], nil
+				},
+			}
+
+			planner, err := generate.NewPlanner(generate.PlannerConfig{
+				LLMClient:  client,
+				Limits:     limits,
+				MaxReplans: tt.maxReplans,
+			})
+			require.NoError(t, err)
+
+			plan, err := planner.Plan(context.Background(), createTestFCS())
+			require.Len(t, prompts, tt.wantCalls)
+			assert.Contains(t, prompts[0], "At most 2 files")
+
+			replans := 0
+			for _, prompt := range prompts {
+				if strings.Contains(prompt, "# Plan Simplification Required") {
+					replans++
+					assert.Contains(t, prompt, "3 files (max 2)")
+					assert.Contains(t, prompt, "depth 4 at a/b/c/d (max 3)")
+				}
+			}
+			assert.Equal(t, tt.wantReplans, replans)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				var limitErr *models.PlanLimitError
+				assert.ErrorAs(t, err, &limitErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, plan.FileTree.Files, 1)
+			assert.NotEmpty(t, plan.ID)
+		})
+	}
+}
+
+func TestPlanner_ReplansAroundProtectedPaths(t *testing.T) {
+	intrusive := `{
+		"file_tree": {
+			"root": "./output",
+			"files": [
+				{"path": "internal/x.go", "purpose": "X"},
+				{"path": "scripts/build.sh", "purpose": "Build script"}
+			]
+		},
+		"phases": [{"name": "setup", "order": 1, "tasks": []}]
+	}`
+	compliant := `{
+		"file_tree": {
+			"root": "./output",
+			"files": [
+				{"path": "internal/x.go", "purpose": "X"},
+				{"path": "build/build.sh", "purpose": "Build script"}
+			]
+		},
+		"phases": [{"name": "setup", "order": 1, "tasks": []}]
+	}`
+
+	var prompts []string
+	client := &mockPlannerLLMClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			prompts = append(prompts, prompt)
+			if len(prompts) == 1 {
+				return intrusive, nil
+			}
+			return compliant, nil
+		},
+	}
+
+	planner, err := generate.NewPlanner(generate.PlannerConfig{
+		LLMClient: client,
+		Protected: models.ProtectedPaths{"scripts/**"},
+	})
+	require.NoError(t, err)
+
+	plan, err := planner.Plan(context.Background(), createTestFCS())
+	require.NoError(t, err)
+	require.Len(t, prompts, 2)
+	assert.Contains(t, prompts[0], "## Protected Paths")
+	assert.Contains(t, prompts[0], "- scripts/**")
+	assert.Contains(t, prompts[1], "file scripts/build.sh is inside protected path scripts/**")
+	assert.Contains(t, prompts[1], "Move any file out of the Protected Paths")
+	assert.Equal(t, "build/build.sh", plan.FileTree.Files[1].Path)
+}
+
+func TestPlanner_ReplansMiddlewarePackage(t *testing.T) {
+	withoutMiddleware := `{
+		"file_tree": {
+			"root": "./output",
+			"files": [{"path": "internal/api/routes.go", "purpose": "Routes"}]
+		},
+		"phases": [{"name": "setup", "order": 1, "tasks": []}]
+	}`
+	withMiddleware := `{
+		"file_tree": {
+			"root": "./output",
+			"files": [
+				{"path": "internal/api/routes.go", "purpose": "Routes"},
+				{"path": "internal/middleware/middleware.go", "purpose": "Logging and auth middleware"}
+			]
+		},
+		"phases": [{"name": "setup", "order": 1, "tasks": []}]
+	}`
+
+	var prompts []string
+	client := &mockPlannerLLMClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			prompts = append(prompts, prompt)
+			if len(prompts) == 1 {
+				return withoutMiddleware, nil
+			}
+			return withMiddleware, nil
+		},
+	}
+
+	planner, err := generate.NewPlanner(generate.PlannerConfig{LLMClient: client})
+	require.NoError(t, err)
+
+	fcs := createTestFCS()
+	fcs.CrossCutting = models.CrossCutting{Concerns: []models.Concern{{Name: models.ConcernLogging}, {Name: models.ConcernAuth}}}
+
+	plan, err := planner.Plan(context.Background(), fcs)
+	require.NoError(t, err)
+	require.Len(t, prompts, 2)
+	assert.Contains(t, prompts[0], "## Cross-Cutting Concerns")
+	assert.Contains(t, prompts[0], "Logging, Auth")
+	assert.Contains(t, prompts[1], "the plan has no Go file there")
+	assert.Len(t, plan.FileTree.Files, 2)
+}
+
+func TestPlanner_PlansGRPCServices(t *testing.T) {
+	var prompt string
+	client := &mockPlannerLLMClient{
+		generateFunc: func(ctx context.Context, p string) (string, error) {
+			prompt = p
+			return `{"file_tree": {"root": "./output", "files": []}, "phases": [{"name": "setup", "order": 1, "tasks": []}]}`, nil
+		},
+	}
+
+	planner, err := generate.NewPlanner(generate.PlannerConfig{LLMClient: client})
+	require.NoError(t, err)
+
+	fcs := createTestFCS()
+	fcs.APIContracts = []models.APIContract{
+		{Protocol: models.ProtocolGRPC, Service: "orders.v1.OrderService", Method: "CreateOrder", Description: "Create an order"},
+	}
+
+	_, err = planner.Plan(context.Background(), fcs)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "rpc CreateOrder(CreateOrderRequest) returns (CreateOrderResponse): Create an order")
+	assert.Contains(t, prompt, "a generate_file task for proto/orders/v1/order_service.proto")
+	assert.Contains(t, prompt, "buf.yaml and buf.gen.yaml")
+
+	// Specs without gRPC contracts are planned as before
+	_, err = planner.Plan(context.Background(), createTestFCS())
+	require.NoError(t, err)
+	assert.NotContains(t, prompt, "gRPC")
+}
+
+func TestPlanner_PlansModules(t *testing.T) {
+	var prompt string
+	client := &mockPlannerLLMClient{
+		generateFunc: func(ctx context.Context, p string) (string, error) {
+			prompt = p
+			return `{
+				"file_tree": {"root": "./output", "files": [
+					{"path": "go.mod", "generated_by": "template"},
+					{"path": "shared/go.mod", "generated_by": "template"},
+					{"path": "services/api/main.go"}
+				]},
+				"phases": [
+					{"name": "code", "order": 2, "tasks": [{"id": "main", "type": "generate_file", "target_path": "services/api/main.go"}]},
+					{"name": "setup", "order": 1, "tasks": [
+						{"id": "create_gomod", "type": "generate_file", "target_path": "go.mod"},
+						{"id": "shared_gomod", "type": "generate_file", "target_path": "shared/go.mod"}
+					]}
+				]
+			}`, nil
+		},
+	}
+
+	planner, err := generate.NewPlanner(generate.PlannerConfig{LLMClient: client})
+	require.NoError(t, err)
+
+	fcs := createTestFCS()
+	fcs.BuildConfig.Modules = []models.ModuleConfig{
+		{Dir: "services/api", Path: "example.com/shop/api", Requires: []string{"example.com/shop/shared"}},
+		{Dir: "shared", Path: "example.com/shop/shared"},
+	}
+
+	plan, err := planner.Plan(context.Background(), fcs)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "- example.com/shop/api in services/api/, requiring example.com/shop/shared")
+	assert.Contains(t, prompt, "Do not plan a go.mod at the project root")
+
+	// The root go.mod is dropped; go.work and every module's go.mod are planned
+	var files []string
+	for _, file := range plan.FileTree.Files {
+		files = append(files, file.Path)
+	}
+	assert.Equal(t, []string{"shared/go.mod", "services/api/main.go", "go.work", "services/api/go.mod"}, files)
+
+	var setup []string
+	for _, phase := range plan.Phases {
+		if phase.Name != "setup" {
+			continue
+		}
+		for _, task := range phase.Tasks {
+			setup = append(setup, task.ID+":"+task.TargetPath)
+		}
+	}
+	assert.Equal(t, []string{"shared_gomod:shared/go.mod", "create_gomod_services_api:services/api/go.mod"}, setup)
+
+	// Single-module specs are planned as before
+	_, err = planner.Plan(context.Background(), createTestFCS())
+	require.NoError(t, err)
+	assert.NotContains(t, prompt, "## Go Modules")
+}
+
+func TestPlanner_PlansMigrations(t *testing.T) {
+	var prompt string
+	client := &mockPlannerLLMClient{
+		generateFunc: func(ctx context.Context, p string) (string, error) {
+			prompt = p
+			return `{
+				"file_tree": {"root": "./output", "files": [
+					{"path": "internal/models/user.go"},
+					{"path": "db/migrations/001_init.sql"}
+				]},
+				"phases": [
+					{"name": "code", "order": 1, "tasks": [
+						{"id": "user", "type": "generate_file", "target_path": "internal/models/user.go"},
+						{"id": "init", "type": "generate_file", "target_path": "db/migrations/001_init.sql"}
+					]}
+				]
+			}`, nil
+		},
+	}
+
+	planner, err := generate.NewPlanner(generate.PlannerConfig{LLMClient: client, MigrationsDir: "db/migrations/"})
+	require.NoError(t, err)
+
+	fcs := createTestFCS()
+	fcs.DataModel = models.DataModel{
+		Entities: []models.Entity{
+			{Name: "Order", Package: "internal/models", Attributes: map[string]string{"id": "int64", "total": "decimal"}},
+			{Name: "User", Package: "internal/models", Attributes: map[string]string{"id": "uuid", "email": "string"}},
+			{Name: "Role", Package: "internal/models", Attributes: map[string]string{"id": "string", "name": "string"}},
+			{Name: "Address", Package: "internal/models", Attributes: map[string]string{"street": "string"}},
+		},
+		Relationships: []models.Relationship{
+			{From: "User", To: "Order", Type: "one-to-many"},
+			{From: "User", To: "Role", Type: "many-to-many"},
+		},
+	}
+
+	plan, err := planner.Plan(context.Background(), fcs)
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "SQL migrations of the Data Model are added to db/migrations/")
+
+	var files []string
+	for _, file := range plan.FileTree.Files {
+		files = append(files, file.Path)
+	}
+	assert.Equal(t, []string{
+		"internal/models/user.go",
+		"db/migrations/000001_create_users.up.sql", "db/migrations/000001_create_users.down.sql",
+		"db/migrations/000002_create_orders.up.sql", "db/migrations/000002_create_orders.down.sql",
+		"db/migrations/000003_create_roles.up.sql", "db/migrations/000003_create_roles.down.sql",
+		"db/migrations/000004_create_user_roles.up.sql", "db/migrations/000004_create_user_roles.down.sql",
+	}, files, "referenced tables come first; the model's migration is replaced")
+
+	require.Len(t, plan.Phases, 2)
+	assert.Len(t, plan.Phases[0].Tasks, 1)
+	assert.Equal(t, "migrations", plan.Phases[1].Name)
+	assert.Equal(t, 2, plan.Phases[1].Order)
+	assert.Len(t, plan.Phases[1].Tasks, 8)
+	assert.Equal(t, "db/migrations/000001_create_users.up.sql", plan.Phases[1].Tasks[0].TargetPath)
+
+	// Without a migrations directory nothing is added
+	planner, err = generate.NewPlanner(generate.PlannerConfig{LLMClient: client})
+	require.NoError(t, err)
+	plan, err = planner.Plan(context.Background(), fcs)
+	require.NoError(t, err)
+	assert.Len(t, plan.Phases, 1)
+	assert.NotContains(t, prompt, "## Database Migrations")
+}
+
+func TestPlanner_ReplansIntoExistingCodebase(t *testing.T) {
+	wholesale := `{
+		"file_tree": {"root": "./output", "files": [
+			{"path": "internal/store/store.go"},
+			{"path": "internal/store/orders.go"}
+		]},
+		"phases": [{"name": "code", "order": 1, "tasks": [
+			{"id": "store", "type": "generate_file", "target_path": "internal/store/store.go"},
+			{"id": "orders", "type": "generate_file", "target_path": "internal/store/orders.go"}
+		]}]
+	}`
+	patching := `{
+		"file_tree": {"root": "./output", "files": [
+			{"path": "internal/store/store.go"},
+			{"path": "internal/store/orders.go"}
+		]},
+		"phases": [{"name": "code", "order": 1, "tasks": [
+			{"id": "store", "type": "apply_patch", "target_path": "internal/store/store.go"},
+			{"id": "orders", "type": "generate_file", "target_path": "internal/store/orders.go"}
+		]}]
+	}`
+
+	var prompts []string
+	client := &mockPlannerLLMClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			prompts = append(prompts, prompt)
+			if len(prompts) == 1 {
+				return wholesale, nil
+			}
+			return patching, nil
+		},
+	}
+
+	planner, err := generate.NewPlanner(generate.PlannerConfig{
+		LLMClient: client,
+		Codebase: &models.Codebase{
+			ModulePath: "example.com/shop",
+			Packages: []models.CodebasePackage{{
+				Dir:   "internal/store",
+				Name:  "store",
+				Files: []string{"internal/store/store.go"},
+				Types: []models.CodebaseType{{Name: "Item"}},
+			}},
+			Files: []string{"go.mod", "internal/store/store.go"},
+		},
+	})
+	require.NoError(t, err)
+
+	plan, err := planner.Plan(context.Background(), createTestFCS())
+	require.NoError(t, err)
+	require.Len(t, prompts, 2)
+	assert.Contains(t, prompts[0], "## Existing Codebase")
+	assert.Contains(t, prompts[0], "- package store (internal/store)")
+	assert.Contains(t, prompts[0], "- store.Item")
+	assert.Contains(t, prompts[1], "file internal/store/store.go already exists; modify it with an apply_patch task instead of generate_file")
+	assert.Contains(t, prompts[1], "Modify files of the Existing Codebase above only with apply_patch tasks")
+	assert.Equal(t, "apply_patch", plan.Phases[0].Tasks[0].Type)
+}
+
+func TestPlanner_EnforcesFileLayout(t *testing.T) {
+	grouped := `{
+		"file_tree": {
+			"root": "./output",
+			"files": [{"path": "internal/models/models.go", "purpose": "Domain types", "entities": ["User", "Order"]}]
+		},
+		"phases": [{"name": "setup", "order": 1, "tasks": []}]
+	}`
+	split := `{
+		"file_tree": {
+			"root": "./output",
+			"files": [
+				{"path": "internal/models/user.go", "purpose": "User type", "entities": ["User"]},
+				{"path": "internal/models/order.go", "purpose": "Order type", "entities": ["Order"]}
+			]
+		},
+		"phases": [{"name": "setup", "order": 1, "tasks": []}]
+	}`
+
+	var prompts []string
+	client := &mockPlannerLLMClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			prompts = append(prompts, prompt)
+			return []string{grouped, split}[len(prompts)-1], nil
+		},
+	}
+
+	planner, err := generate.NewPlanner(generate.PlannerConfig{
+		LLMClient: client,
+		Layout:    models.FileLayout{Strategy: models.FileSplitPerEntity},
+	})
+	require.NoError(t, err)
+
+	fcs := createTestFCS()
+	fcs.DataModel.Entities = []models.Entity{{Name: "User", Package: "models"}, {Name: "Order", Package: "models"}}
+
+	plan, err := planner.Plan(context.Background(), fcs)
+	require.NoError(t, err)
+	require.Len(t, prompts, 2)
+	assert.Contains(t, prompts[0], "## File Layout")
+	assert.Contains(t, prompts[0], "Put each Data Model entity in its own file")
+	assert.Contains(t, prompts[1], "defines 2 entities (User, Order); per_entity allows 1")
+	require.Len(t, plan.FileTree.Files, 2)
+	assert.Equal(t, []string{"User"}, plan.FileTree.Files[0].Entities)
+}
+
 // Helper functions
 
+func TestPlanner_AdoptsRequiredFileTree(t *testing.T) {
+	missingTask := `{
+		"file_tree": {
+			"root": "./output",
+			"files": [
+				{"path": "main.go", "purpose": "Entry point", "generated_by": "gen_main"},
+				{"path": "internal/store/store.go", "purpose": "Store", "generated_by": "gen_store"}
+			]
+		},
+		"phases": [{"name": "setup", "order": 1, "tasks": [
+			{"id": "gen_main", "type": "generate_file", "target_path": "main.go"}
+		]}]
+	}`
+	complete := `{
+		"file_tree": {
+			"root": "./output",
+			"files": [{"path": "cmd/app/main.go", "purpose": "Entry point", "generated_by": "gen_main", "estimated_lines": 40}]
+		},
+		"phases": [{"name": "setup", "order": 1, "tasks": [
+			{"id": "gen_main", "type": "generate_file", "target_path": "cmd/app/main.go"},
+			{"id": "gen_store", "type": "generate_file", "target_path": "internal/store/store.go"}
+		]}]
+	}`
+
+	var prompts []string
+	client := &mockPlannerLLMClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			prompts = append(prompts, prompt)
+			if len(prompts) == 1 {
+				return missingTask, nil
+			}
+			return complete, nil
+		},
+	}
+
+	planner, err := generate.NewPlanner(generate.PlannerConfig{LLMClient: client})
+	require.NoError(t, err)
+
+	fcs := createTestFCS()
+	fcs.FileTree = &models.FileTree{
+		Directories: []models.Directory{{Path: "cmd/app"}},
+		Files: []models.File{
+			{Path: "cmd/app/main.go"},
+			{Path: "internal/store/store.go", Purpose: "In-memory store"},
+			{Path: "go.mod", GeneratedBy: "template"},
+		},
+	}
+
+	plan, err := planner.Plan(context.Background(), fcs)
+	require.NoError(t, err)
+	require.Len(t, prompts, 2)
+	assert.Contains(t, prompts[0], "## Required File Tree")
+	assert.Contains(t, prompts[0], "- internal/store/store.go: In-memory store")
+	assert.Contains(t, prompts[1], "required file cmd/app/main.go has no generate_file task")
+	assert.Contains(t, prompts[1], "Keep the Required File Tree above unchanged")
+
+	assert.Equal(t, "./output", plan.FileTree.Root)
+	assert.Equal(t, fcs.FileTree.Directories, plan.FileTree.Directories)
+	assert.Equal(t, []models.File{
+		{Path: "cmd/app/main.go", Purpose: "Entry point", GeneratedBy: "gen_main", EstimatedLines: 40},
+		{Path: "internal/store/store.go", Purpose: "In-memory store"},
+		{Path: "go.mod", GeneratedBy: "template"},
+	}, plan.FileTree.Files)
+	assert.Len(t, plan.Phases[0].Tasks, 2)
+}
+
+func TestPlanner_DropsTasksOutsideRequiredFileTree(t *testing.T) {
+	client := &mockPlannerLLMClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			return `{
+				"file_tree": {"root": "./output", "files": [{"path": "cmd/app/main.go"}, {"path": "internal/util/util.go"}]},
+				"phases": [{"name": "setup", "order": 1, "tasks": [
+					{"id": "gen_main", "type": "generate_file", "target_path": "cmd/app/main.go"},
+					{"id": "gen_util", "type": "generate_file", "target_path": "internal/util/util.go"},
+					{"id": "build", "type": "run_command"}
+				]}]
+			}`, nil
+		},
+	}
+
+	planner, err := generate.NewPlanner(generate.PlannerConfig{LLMClient: client})
+	require.NoError(t, err)
+
+	fcs := createTestFCS()
+	fcs.FileTree = &models.FileTree{Files: []models.File{{Path: "cmd/app/main.go"}}}
+
+	plan, err := planner.Plan(context.Background(), fcs)
+	require.NoError(t, err)
+	require.Len(t, plan.FileTree.Files, 1)
+	var ids []string
+	for _, task := range plan.Phases[0].Tasks {
+		ids = append(ids, task.ID)
+	}
+	assert.Equal(t, []string{"gen_main", "build"}, ids)
+}
+
+func TestPlanner_NormalizesTaskIDsAndPhaseOrder(t *testing.T) {
+	client := &mockPlannerLLMClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			return `{
+				"file_tree": {"root": "./output", "files": [{"path": "cmd/app/main.go"}, {"path": "internal/store/store.go"}]},
+				"phases": [
+					{"name": "app", "order": 4, "dependencies": ["store"], "tasks": [
+						{"id": "gen", "type": "generate_file", "target_path": "cmd/app/main.go"}
+					]},
+					{"name": "store", "order": 2, "tasks": [
+						{"id": "gen", "type": "generate_file", "target_path": "internal/store/store.go"}
+					]}
+				]
+			}`, nil
+		},
+	}
+
+	planner, err := generate.NewPlanner(generate.PlannerConfig{LLMClient: client})
+	require.NoError(t, err)
+
+	plan, err := planner.Plan(context.Background(), createTestFCS())
+	require.NoError(t, err)
+	require.Len(t, plan.Phases, 2)
+	assert.Equal(t, "store", plan.Phases[0].Name)
+	assert.Equal(t, 1, plan.Phases[0].Order)
+	assert.Equal(t, "gen", plan.Phases[0].Tasks[0].ID)
+	assert.Equal(t, 2, plan.Phases[1].Order)
+	assert.Equal(t, "gen-2", plan.Phases[1].Tasks[0].ID)
+}
+
+func TestPlanner_RequestsStructuredPlan(t *testing.T) {
+	client := &structuredLLMClient{output: `{
+		"file_tree": {"root": "./output", "files": [{"path": "internal/store/store.go", "entities": ["User"]}]},
+		"phases": [{"name": "store", "order": 1, "tasks": [
+			{"id": "store", "type": "generate_file", "target_path": "internal/store/store.go", "can_parallel": false}
+		]}]
+	}`}
+
+	planner, err := generate.NewPlanner(generate.PlannerConfig{LLMClient: client})
+	require.NoError(t, err)
+
+	plan, err := planner.Plan(context.Background(), createTestFCS())
+	require.NoError(t, err)
+	require.Len(t, client.schemas, 1, "the plan is requested as structured output")
+	require.Len(t, plan.Phases, 1)
+	assert.Equal(t, []string{"User"}, plan.FileTree.Files[0].Entities)
+	assert.Equal(t, models.PlanSchemaVersion, plan.SchemaVersion)
+
+	// A plan with properties the schema does not declare is rejected
+	client.output = `{"file_tree": {"root": "."}, "phases": [], "notes": "done"}`
+	_, err = planner.Plan(context.Background(), createTestFCS())
+	assert.ErrorContains(t, err, "/notes: unknown property")
+}
+
 func createTestFCS() *models.FinalClarifiedSpecification {
 	return &models.FinalClarifiedSpecification{
 		SchemaVersion: "1.0",
@@ -227,7 +227,7 @@ func TestTester_Generate(t *testing.T) {
 			})
 			require.NoError(t, err)
 
-			patches, err := tester.Generate(context.Background(), tt.packages, tt.plan)
+			patches, err := tester.Generate(context.Background(), tt.packages, tt.plan, nil)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -326,3 +326,67 @@ func createTestPlanForTester() *models.GenerationPlan {
 		},
 	}
 }
+
+func TestTester_GenerateEnforcesRequirementCoverage(t *testing.T) {
+	plan := &models.GenerationPlan{
+		FileTree: models.FileTree{
+			Root: "./output",
+			Files: []models.File{
+				{Path: "./output/cmd/app/main.go", Purpose: "Main entry point"},
+				{Path: "./output/internal/users/service.go", Purpose: "User account management"},
+				{Path: "./output/internal/orders/service.go", Purpose: "Order processing"},
+			},
+		},
+	}
+	fcs := &models.FinalClarifiedSpecification{
+		Requirements: models.Requirements{
+			Functional: []models.FunctionalRequirement{
+				{ID: "FR-001", Description: "Register new users"},
+				{ID: "FR-002", Description: "Place orders"},
+			},
+		},
+	}
+
+	var prompts []string
+	mockClient := &mockTesterLLMClient{
+		generateFunc: func(ctx context.Context, prompt string) (string, error) {
+			prompts = append(prompts, prompt)
+			switch {
+			case strings.Contains(prompt, "source file: ./output/internal/users/service.go"):
+				return "package users\n\n// Requirement: FR-001\nfunc TestRegister(t *testing.T) {}\n", nil
+			case strings.Contains(prompt, "previous attempt"):
+				return "package orders\n\n// Requirement: FR-002\nfunc TestPlace(t *testing.T) {}\n", nil
+			default:
+				return "package orders\n\nfunc TestSomething(t *testing.T) {}\n", nil
+			}
+		},
+	}
+
+	tester, err := generate.NewTester(generate.TesterConfig{LLMClient: mockClient})
+	require.NoError(t, err)
+
+	patches, err := tester.Generate(context.Background(), nil, plan, fcs)
+	require.NoError(t, err)
+	require.Len(t, patches, 3)
+
+	// Requirements are routed to the file whose purpose matches them
+	for _, prompt := range prompts {
+		if strings.Contains(prompt, "source file: ./output/internal/users/service.go") {
+			assert.Contains(t, prompt, "FR-001: Register new users")
+			assert.NotContains(t, prompt, "FR-002")
+		}
+		if strings.Contains(prompt, "source file: ./output/cmd/app/main.go") {
+			assert.NotContains(t, prompt, "Requirements Under Test")
+		}
+	}
+
+	// The orders test was regenerated once because FR-002 was untested
+	assert.Len(t, prompts, 4)
+	var ordersPatch models.Patch
+	for _, patch := range patches {
+		if strings.Contains(patch.TargetFile, "orders") {
+			ordersPatch = patch
+		}
+	}
+	assert.Contains(t, ordersPatch.Diff, "+// Requirement: FR-002")
+}
@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/dshills/gocreator/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// phaseLLMClient answers every prompt of its phase with one response
+type phaseLLMClient struct {
+	model    string
+	response string
+	calls    int32
+}
+
+func (p *phaseLLMClient) Generate(_ context.Context, _ string) (string, error) {
+	atomic.AddInt32(&p.calls, 1)
+	return p.response, nil
+}
+
+func (p *phaseLLMClient) GenerateStructured(_ context.Context, _ string, _ interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (p *phaseLLMClient) Chat(_ context.Context, _ []llm.Message) (string, error) { return "", nil }
+
+func (p *phaseLLMClient) Provider() string { return "mock" }
+
+func (p *phaseLLMClient) Model() string { return p.model }
+
+func TestEngine_PhaseModels(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "project")
+	fileOps, err := fsops.New(fsops.Config{RootDir: outputDir, Logger: &noopFsLogger{}})
+	require.NoError(t, err)
+
+	planner := &phaseLLMClient{model: "planner-model", response: `{
+		"file_tree": {"root": "` + outputDir + `", "files": [{"path": "main.go", "generated_by": "gen_main"}]},
+		"phases": [{"name": "setup", "order": 1, "tasks": [{"id": "gen_main", "type": "generate_file", "target_path": "main.go"}]}]
+	}`}
+	coder := &phaseLLMClient{model: "coder-model", response: "package main\n\nfunc main() {}\n"}
+	tester := &phaseLLMClient{model: "tester-model", response: "package main\n\nimport \"testing\"\n\nfunc TestMain(t *testing.T) {}\n"}
+	primary := &phaseLLMClient{model: "primary-model"}
+
+	engine, err := generate.NewEngine(generate.EngineConfig{
+		LLMClient:     primary,
+		PlannerClient: planner,
+		CoderClient:   coder,
+		TesterClient:  tester,
+		FileOps:       fileOps,
+	})
+	require.NoError(t, err)
+
+	output, err := engine.Generate(context.Background(), createCompleteTestFCS(), outputDir)
+	require.NoError(t, err)
+
+	// Each phase runs on its own model; the primary one is not used
+	assert.EqualValues(t, 1, atomic.LoadInt32(&planner.calls))
+	assert.Positive(t, atomic.LoadInt32(&coder.calls))
+	assert.Positive(t, atomic.LoadInt32(&tester.calls))
+	assert.Zero(t, atomic.LoadInt32(&primary.calls))
+
+	provenance := output.Metadata.Provenance
+	require.NotNil(t, provenance)
+	assert.Equal(t, "coder-model", provenance.Model)
+	assert.Equal(t, map[string]string{"planner": "mock/planner-model", "tester": "mock/tester-model"}, provenance.PhaseModels)
+
+	manifest, err := generate.LoadManifest(outputDir)
+	require.NoError(t, err)
+	require.NotNil(t, manifest)
+	assert.Equal(t, "mock/coder-model", manifest.Files["main.go"].Model)
+	assert.Equal(t, "mock/tester-model", manifest.Files["main_test.go"].Model)
+}
@@ -2,6 +2,8 @@ package unit
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -201,6 +203,49 @@ func TestGenerationPlan_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "parallel tasks cannot write to same file",
 		},
+		{
+			name: "invalid - duplicate task IDs across phases",
+			plan: &models.GenerationPlan{
+				Phases: []models.GenerationPhase{
+					{Name: "models", Order: 1, Tasks: []models.GenerationTask{{ID: "t1", Type: "generate_file", TargetPath: "a.go"}}},
+					{Name: "handlers", Order: 2, Tasks: []models.GenerationTask{{ID: "t1", Type: "generate_file", TargetPath: "b.go"}}},
+				},
+			},
+			wantErr: true,
+			errMsg:  `duplicate task ID "t1" in phases "models" and "handlers"`,
+		},
+		{
+			name: "invalid - task without ID",
+			plan: &models.GenerationPlan{
+				Phases: []models.GenerationPhase{
+					{Name: "models", Order: 1, Tasks: []models.GenerationTask{{Type: "generate_file", TargetPath: "a.go"}}},
+				},
+			},
+			wantErr: true,
+			errMsg:  `task 1 in phase "models" has no ID`,
+		},
+		{
+			name: "invalid - gap in phase orders",
+			plan: &models.GenerationPlan{
+				Phases: []models.GenerationPhase{
+					{Name: "models", Order: 1},
+					{Name: "handlers", Order: 3},
+				},
+			},
+			wantErr: true,
+			errMsg:  `phase "handlers" has order 3, expected 2`,
+		},
+		{
+			name: "invalid - duplicate phase names",
+			plan: &models.GenerationPlan{
+				Phases: []models.GenerationPhase{
+					{Name: "models", Order: 1},
+					{Name: "models", Order: 2},
+				},
+			},
+			wantErr: true,
+			errMsg:  `duplicate phase name: "models"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -216,6 +261,48 @@ func TestGenerationPlan_Validate(t *testing.T) {
 	}
 }
 
+func TestGenerationPlan_Normalize(t *testing.T) {
+	plan := &models.GenerationPlan{
+		Phases: []models.GenerationPhase{
+			{Name: "handlers", Order: 5, Tasks: []models.GenerationTask{
+				{ID: "t1", Type: "generate_file", TargetPath: "internal/api/api.go"},
+				{Type: "generate_file", TargetPath: "internal/api/routes.go"},
+			}},
+			{Name: "extras", Order: 0, Tasks: []models.GenerationTask{
+				{ID: "t1-2", Type: "generate_file", TargetPath: "internal/extra/extra.go"},
+			}},
+			{Name: "models", Order: 2, Tasks: []models.GenerationTask{
+				{ID: "t1", Type: "generate_file", TargetPath: "internal/models/models.go"},
+			}},
+		},
+		FileTree: models.FileTree{Files: []models.File{
+			{Path: "internal/api/api.go", GeneratedBy: "t1"},
+			{Path: "internal/models/models.go", GeneratedBy: "t1"},
+		}},
+	}
+
+	changes := plan.Normalize()
+	assert.Len(t, changes, 5)
+	require.NoError(t, plan.Validate())
+
+	var names []string
+	for _, phase := range plan.Phases {
+		names = append(names, fmt.Sprintf("%d:%s", phase.Order, phase.Name))
+	}
+	assert.Equal(t, []string{"1:models", "2:handlers", "3:extras"}, names, "phases are sorted by order, unordered ones last")
+
+	// The first task in phase order keeps a shared ID; later ones get a free one
+	assert.Equal(t, "t1", plan.Phases[0].Tasks[0].ID)
+	assert.Equal(t, "t1-3", plan.Phases[1].Tasks[0].ID, "t1-2 is already taken")
+	assert.Equal(t, "task-2-2", plan.Phases[1].Tasks[1].ID)
+	assert.Equal(t, "t1-2", plan.Phases[2].Tasks[0].ID)
+
+	assert.Equal(t, "t1-3", plan.FileTree.Files[0].GeneratedBy, "files follow their renamed task")
+	assert.Equal(t, "t1", plan.FileTree.Files[1].GeneratedBy)
+
+	assert.Empty(t, plan.Normalize(), "normalizing is idempotent")
+}
+
 func TestGenerationPlan_DetectCyclicDependencies(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -394,3 +481,256 @@ func TestFileTree_JSONMarshaling(t *testing.T) {
 	assert.Equal(t, len(fileTree.Directories), len(unmarshaled.Directories))
 	assert.Equal(t, len(fileTree.Files), len(unmarshaled.Files))
 }
+
+func TestGenerationPlan_CheckLimits(t *testing.T) {
+	plan := &models.GenerationPlan{
+		FileTree: models.FileTree{
+			Root:        "./output",
+			Directories: []models.Directory{{Path: "docs"}},
+			Files: []models.File{
+				{Path: "go.mod"},
+				{Path: "cmd/app/main.go"},
+				{Path: "internal/service/a.go"},
+				{Path: "internal/service/b.go"},
+				{Path: "internal/service/a_test.go"},
+				{Path: "internal/service/v1/handlers/http/router.go"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		limits         models.PlanLimits
+		wantViolations []string
+	}{
+		{name: "no limits", limits: models.PlanLimits{}},
+		{name: "within limits", limits: models.PlanLimits{MaxFiles: 6, MaxDirectories: 8, MaxDepth: 5, MaxFilesPerPackage: 2}},
+		{name: "too many files", limits: models.PlanLimits{MaxFiles: 5}, wantViolations: []string{"6 files (max 5)"}},
+		{name: "listed and implied directories", limits: models.PlanLimits{MaxDirectories: 7}, wantViolations: []string{"8 directories (max 7)"}},
+		{name: "too deep", limits: models.PlanLimits{MaxDepth: 3}, wantViolations: []string{"depth 5 at internal/service/v1/handlers/http (max 3)"}},
+		{name: "test files excluded from package cap", limits: models.PlanLimits{MaxFilesPerPackage: 1}, wantViolations: []string{"2 files in package internal/service (max 1)"}},
+		{
+			name:           "multiple violations",
+			limits:         models.PlanLimits{MaxFiles: 1, MaxDepth: 1},
+			wantViolations: []string{"6 files (max 1)", "depth 5 at internal/service/v1/handlers/http (max 1)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := plan.CheckLimits(tt.limits)
+			if len(tt.wantViolations) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var limitErr *models.PlanLimitError
+			require.ErrorAs(t, err, &limitErr)
+			assert.Equal(t, tt.wantViolations, limitErr.Violations)
+		})
+	}
+}
+
+func TestProtectedPaths_Match(t *testing.T) {
+	protected := models.ProtectedPaths{"docs/adr/**", "scripts", "*.local.yaml", "**/fixtures/*.golden"}
+
+	tests := []struct {
+		path        string
+		wantPattern string
+	}{
+		{"docs/adr/0001-record.md", "docs/adr/**"},
+		{"docs/adr", "docs/adr/**"},
+		{"docs/guide.md", ""},
+		{"scripts/release.sh", "scripts"},
+		{"scripts/ci/lint.sh", "scripts"},
+		{"scriptsx/run.sh", ""},
+		{"./scripts/run.sh", "scripts"},
+		{"dev.local.yaml", "*.local.yaml"},
+		{"config/dev.local.yaml", ""},
+		{"fixtures/a.golden", "**/fixtures/*.golden"},
+		{"internal/api/fixtures/b.golden", "**/fixtures/*.golden"},
+		{"internal/api/fixtures/b.json", ""},
+		{"../scripts/run.sh", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			pattern, ok := protected.Match(tt.path)
+			assert.Equal(t, tt.wantPattern != "", ok)
+			assert.Equal(t, tt.wantPattern, pattern)
+		})
+	}
+}
+
+func TestProtectedPaths_Validate(t *testing.T) {
+	assert.NoError(t, models.ProtectedPaths{"docs/adr/**", "scripts/"}.Validate())
+	assert.ErrorContains(t, models.ProtectedPaths{""}.Validate(), "empty")
+	assert.ErrorContains(t, models.ProtectedPaths{"/etc/**"}.Validate(), "relative")
+	assert.ErrorContains(t, models.ProtectedPaths{"../shared/**"}.Validate(), "must not leave")
+	assert.ErrorContains(t, models.ProtectedPaths{"docs/[adr"}.Validate(), "not a valid glob")
+}
+
+func TestGenerationPlan_CheckProtectedPaths(t *testing.T) {
+	plan := &models.GenerationPlan{
+		FileTree: models.FileTree{
+			Directories: []models.Directory{{Path: "scripts"}},
+			Files: []models.File{
+				{Path: "cmd/app/main.go"},
+				{Path: "docs/adr/0002-storage.md"},
+				{Path: "scripts/build.sh"},
+			},
+		},
+	}
+
+	assert.NoError(t, plan.CheckProtectedPaths(nil))
+
+	var limitErr *models.PlanLimitError
+	require.ErrorAs(t, plan.CheckProtectedPaths(models.ProtectedPaths{"docs/adr/**", "scripts/**"}), &limitErr)
+	assert.Equal(t, []string{
+		"file docs/adr/0002-storage.md is inside protected path docs/adr/**",
+		"file scripts/build.sh is inside protected path scripts/**",
+		"directory scripts is inside protected path scripts/**",
+	}, limitErr.Violations)
+}
+
+func TestGenerationPlan_CheckMiddlewarePackage(t *testing.T) {
+	cc := models.CrossCutting{Concerns: []models.Concern{{Name: models.ConcernLogging}}}
+	plan := &models.GenerationPlan{
+		FileTree: models.FileTree{Files: []models.File{
+			{Path: "cmd/app/main.go"},
+			{Path: "internal/middleware/README.md"},
+		}},
+	}
+
+	assert.NoError(t, plan.CheckMiddlewarePackage(models.CrossCutting{}))
+
+	var limitErr *models.PlanLimitError
+	require.ErrorAs(t, plan.CheckMiddlewarePackage(cc), &limitErr)
+	assert.Equal(t, []string{
+		"cross-cutting concerns need middleware in package internal/middleware, but the plan has no Go file there",
+	}, limitErr.Violations)
+
+	plan.FileTree.Files = append(plan.FileTree.Files, models.File{Path: "internal/middleware/middleware.go"})
+	assert.NoError(t, plan.CheckMiddlewarePackage(cc))
+}
+
+func TestGenerationPlan_CheckFileLayout(t *testing.T) {
+	entities := []models.Entity{{Name: "User", Package: "models"}, {Name: "Order", Package: "models"}}
+
+	plan := func(files ...models.File) *models.GenerationPlan {
+		return &models.GenerationPlan{FileTree: models.FileTree{Root: "./output", Files: files}}
+	}
+	perEntity := plan(
+		models.File{Path: "go.mod", GeneratedBy: "template"},
+		models.File{Path: "internal/models/user.go", Entities: []string{"User"}, EstimatedLines: 80},
+		models.File{Path: "internal/models/order.go", Entities: []string{"order"}, EstimatedLines: 120},
+		models.File{Path: "internal/models/user_test.go"},
+	)
+	grouped := plan(
+		models.File{Path: "internal/models/models.go", Entities: []string{"User", "Order"}, EstimatedLines: 450},
+	)
+
+	tests := []struct {
+		name           string
+		plan           *models.GenerationPlan
+		layout         models.FileLayout
+		wantViolations []string
+	}{
+		{name: "auto accepts anything", plan: grouped, layout: models.FileLayout{}},
+		{name: "per entity satisfied", plan: perEntity, layout: models.FileLayout{Strategy: models.FileSplitPerEntity}},
+		{
+			name:           "per entity rejects grouped file",
+			plan:           grouped,
+			layout:         models.FileLayout{Strategy: models.FileSplitPerEntity},
+			wantViolations: []string{"file internal/models/models.go defines 2 entities (User, Order); per_entity allows 1"},
+		},
+		{name: "per package satisfied", plan: grouped, layout: models.FileLayout{Strategy: models.FileSplitPerPackage}},
+		{
+			name:           "per package rejects split package",
+			plan:           perEntity,
+			layout:         models.FileLayout{Strategy: models.FileSplitPerPackage},
+			wantViolations: []string{"package internal/models spreads its entities over 2 files; per_package allows 1"},
+		},
+		{
+			name:   "unassigned and duplicated entities",
+			plan:   plan(models.File{Path: "a/user.go", Entities: []string{"User"}}, models.File{Path: "b/user.go", Entities: []string{"User"}}),
+			layout: models.FileLayout{Strategy: models.FileSplitPerEntity},
+			wantViolations: []string{
+				"entity User is defined in 2 files (a/user.go, b/user.go)",
+				"entity Order is not assigned to a file",
+			},
+		},
+		{name: "bounded within budget", plan: perEntity, layout: models.FileLayout{Strategy: models.FileSplitBounded, MaxFileLines: 200}},
+		{
+			name:           "bounded over budget",
+			plan:           grouped,
+			layout:         models.FileLayout{Strategy: models.FileSplitBounded, MaxFileLines: 300},
+			wantViolations: []string{"file internal/models/models.go estimated at 450 lines (max 300)"},
+		},
+		{
+			name:           "bounded requires estimates",
+			plan:           plan(models.File{Path: "main.go"}),
+			layout:         models.FileLayout{Strategy: models.FileSplitBounded, MaxFileLines: 300},
+			wantViolations: []string{"file main.go has no estimated_lines"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.plan.CheckFileLayout(tt.layout, entities)
+			if len(tt.wantViolations) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var limitErr *models.PlanLimitError
+			require.ErrorAs(t, err, &limitErr)
+			assert.Equal(t, tt.wantViolations, limitErr.Violations)
+		})
+	}
+
+	var limitErr *models.PlanLimitError
+	err := grouped.CheckFileLayout(models.FileLayout{Strategy: "huge"}, entities)
+	require.Error(t, err)
+	assert.False(t, errors.As(err, &limitErr), "unknown strategies are not re-plannable")
+}
+
+func TestGenerationPlan_CheckCodebase(t *testing.T) {
+	codebase := &models.Codebase{
+		ModulePath: "example.com/shop",
+		Packages: []models.CodebasePackage{
+			{Dir: "cmd/shop", Name: "main", Files: []string{"cmd/shop/main.go"}},
+			{Dir: "internal/store", Name: "store", Files: []string{"internal/store/store.go"}},
+		},
+		Files: []string{"Makefile", "cmd/shop/main.go", "go.mod", "internal/store/store.go"},
+	}
+	plan := &models.GenerationPlan{
+		FileTree: models.FileTree{Files: []models.File{
+			{Path: "cmd/shop/main.go"},
+			{Path: "internal/store/orders.go"},
+			{Path: "internal/api/handlers.go"},
+			{Path: "pkg/deep/client/client.go"},
+			{Path: "internal/store/store.go"},
+			{Path: "Makefile", GeneratedBy: "template"},
+		}},
+		Phases: []models.GenerationPhase{{Name: "code", Order: 1, Tasks: []models.GenerationTask{
+			{ID: "main", Type: "apply_patch", TargetPath: "cmd/shop/main.go"},
+			{ID: "orders", Type: "generate_file", TargetPath: "internal/store/orders.go"},
+			{ID: "handlers", Type: "generate_file", TargetPath: "internal/api/handlers.go"},
+			{ID: "client", Type: "generate_file", TargetPath: "pkg/deep/client/client.go"},
+			{ID: "store", Type: "generate_file", TargetPath: "internal/store/store.go"},
+			{ID: "config", Type: "apply_patch", TargetPath: "internal/store/config.go"},
+		}}},
+	}
+
+	assert.NoError(t, plan.CheckCodebase(nil))
+
+	var limitErr *models.PlanLimitError
+	require.ErrorAs(t, plan.CheckCodebase(codebase), &limitErr)
+	assert.Equal(t, []string{
+		"file internal/store/store.go already exists; modify it with an apply_patch task instead of generate_file",
+		"apply_patch task config targets internal/store/config.go, which does not exist; create it with generate_file",
+		"new file pkg/deep/client/client.go is outside the existing package structure; place it in an existing package or a package beside one",
+		"file Makefile already exists; list it only with an apply_patch task that modifies it",
+	}, limitErr.Violations)
+}
@@ -0,0 +1,144 @@
+package unit
+
+import (
+	"errors"
+	"go/ast"
+	"testing"
+
+	"github.com/dshills/gocreator/pkg/postprocess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline_Process(t *testing.T) {
+	var pipeline *postprocess.Pipeline
+
+	tests := []struct {
+		name string
+		path string
+		pkg  string
+		code string
+		want string
+	}{
+		{
+			name: "markdown and prose are stripped",
+			path: "internal/store/store.go",
+			pkg:  "store",
+			code: "Here is the store:\n\n```yaml\nkey: value\n```\n\n```go\npackage store\n\nfunc Open() {}\n```\n\nLet me know if you need more.",
+			want: "package store\n\nfunc Open() {}\n",
+		},
+		{
+			name: "prose before the package clause is stripped",
+			path: "internal/store/store.go",
+			pkg:  "store",
+			code: "Sure! The file follows.\n\n// Package store persists users.\npackage store\n\nfunc Open() {}\n```",
+			want: "// Package store persists users.\npackage store\n\nfunc Open() {}\n",
+		},
+		{
+			name: "package clause follows the plan",
+			path: "internal/store/store.go",
+			pkg:  "store",
+			code: "package storage\n\nfunc Open() {}\n",
+			want: "package store\n\nfunc Open() {}\n",
+		},
+		{
+			name: "external test package is kept",
+			path: "internal/store/store_test.go",
+			pkg:  "store",
+			code: "package store_test\n",
+			want: "package store_test\n",
+		},
+		{
+			name: "main package is kept",
+			path: "tools/gen/main.go",
+			pkg:  "gen",
+			code: "package main\n\nfunc main() {}\n",
+			want: "package main\n\nfunc main() {}\n",
+		},
+		{
+			name: "unknown package leaves the clause",
+			path: "store.go",
+			code: "package anything\n",
+			want: "package anything\n",
+		},
+		{
+			name: "unused standard imports are removed",
+			path: "internal/store/store.go",
+			pkg:  "store",
+			code: "package store\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\t_ \"embed\"\n\n\t\"github.com/google/uuid\"\n)\n\nfunc ID() string { return fmt.Sprint(1) }\n",
+			want: "package store\n\nimport (\n\t_ \"embed\"\n\t\"fmt\"\n\n\t\"github.com/google/uuid\"\n)\n\nfunc ID() string { return fmt.Sprint(1) }\n",
+		},
+		{
+			name: "missing standard imports are added",
+			path: "internal/store/store.go",
+			pkg:  "store",
+			code: "package store\n\nfunc Load(data []byte, v any) error {\n\tif err := json.Unmarshal(data, v); err != nil {\n\t\treturn fmt.Errorf(\"decode: %w\", err)\n\t}\n\treturn nil\n}\n",
+			want: "package store\n\nimport (\n\t\"encoding/json\"\n\t\"fmt\"\n)\n\nfunc Load(data []byte, v any) error {\n\tif err := json.Unmarshal(data, v); err != nil {\n\t\treturn fmt.Errorf(\"decode: %w\", err)\n\t}\n\treturn nil\n}\n",
+		},
+		{
+			name: "ambiguous and shadowed names are not imported",
+			path: "internal/store/store.go",
+			pkg:  "store",
+			code: "package store\n\nimport \"os\"\n\nfunc N(strings []string) int { _ = os.Args; return rand.Intn(len(strings)) }\n",
+			want: "package store\n\nimport \"os\"\n\nfunc N(strings []string) int { _ = os.Args; return rand.Intn(len(strings)) }\n",
+		},
+		{
+			name: "a replaced import keeps one declaration",
+			path: "internal/store/store.go",
+			pkg:  "store",
+			code: "package store\n\nimport \"os\"\n\nvar start = time.Now()\n",
+			want: "package store\n\nimport \"time\"\n\nvar start = time.Now()\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pipeline.Process(tt.path, tt.pkg, tt.code)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPipeline_SyntaxError(t *testing.T) {
+	_, err := (&postprocess.Pipeline{}).Process("internal/store/store.go", "store", "```go\npackage store\n\nfunc Open( {\n}\n```")
+
+	var syntaxErr *postprocess.SyntaxError
+	require.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, "internal/store/store.go", syntaxErr.Path)
+	require.NotEmpty(t, syntaxErr.Errors)
+	assert.Equal(t, 3, syntaxErr.Errors[0].Line, "positions are those of the code without markdown")
+	assert.Contains(t, err.Error(), "generated file internal/store/store.go does not parse: 3:")
+}
+
+func TestPipeline_Transforms(t *testing.T) {
+	postprocess.Register("test-rename-open", func(file *postprocess.File) error {
+		ast.Inspect(file.AST, func(n ast.Node) bool {
+			if fn, ok := n.(*ast.FuncDecl); ok && fn.Name.Name == "Open" {
+				fn.Name.Name = "Connect"
+			}
+			return true
+		})
+		return nil
+	})
+	postprocess.Register("test-reject", func(file *postprocess.File) error {
+		return errors.New("rejected " + file.Package)
+	})
+	assert.Contains(t, postprocess.Registered(), "test-rename-open")
+
+	pipeline, err := postprocess.New("test-rename-open")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test-rename-open"}, pipeline.Transforms())
+
+	got, err := pipeline.Process("internal/store/store.go", "store", "package storage\n\nfunc Open() {}\n")
+	require.NoError(t, err)
+	assert.Equal(t, "package store\n\nfunc Connect() {}\n", got, "transforms see the renamed package")
+
+	pipeline, err = postprocess.New("test-reject")
+	require.NoError(t, err)
+	_, err = pipeline.Process("internal/store/store.go", "store", "package store\n")
+	assert.EqualError(t, err, "transform test-reject on internal/store/store.go: rejected store")
+
+	_, err = postprocess.New("test-missing")
+	assert.ErrorContains(t, err, `unknown transform "test-missing"`)
+}
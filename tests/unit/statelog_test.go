@@ -0,0 +1,117 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/generate"
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/pkg/fsops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_RecordsStateTransitions(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "project")
+
+	fileOps, err := fsops.New(fsops.Config{RootDir: outputDir, Logger: &noopFsLogger{}})
+	require.NoError(t, err)
+
+	engine, err := generate.NewEngine(generate.EngineConfig{
+		LLMClient: &mockEngineLLMClient{
+			planResponse: `{
+				"file_tree": {"root": "` + outputDir + `", "files": [{"path": "main.go", "generated_by": "gen_main"}]},
+				"phases": [{"name": "setup", "order": 1, "tasks": [{"id": "gen_main", "type": "generate_file", "target_path": "main.go"}]}]
+			}`,
+			codeResponse: "package main\n\nfunc main() {}\n",
+			testResponse: "package main\n",
+		},
+		FileOps:     fileOps,
+		RecordState: true,
+	})
+	require.NoError(t, err)
+
+	output, err := engine.Generate(context.Background(), createCompleteTestFCS(), outputDir)
+	require.NoError(t, err)
+	require.NotEmpty(t, output.RunID)
+
+	runs, err := generate.ListStateRuns(outputDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{output.RunID}, runs)
+
+	transitions, err := generate.LoadStateTransitions(outputDir, output.RunID)
+	require.NoError(t, err)
+
+	var nodes []string
+	for i, tr := range transitions {
+		assert.Equal(t, i, tr.Seq)
+		nodes = append(nodes, tr.Node)
+	}
+	assert.Equal(t, []string{
+		generate.InitialStateNode, "start", "analyze_fcs", "create_plan",
+		"generate_packages", "generate_tests", "generate_config", "generate_docs", "apply_patches", "end",
+	}, nodes)
+	assert.Equal(t, "generate", transitions[3].Next)
+	for _, tr := range transitions[4:7] {
+		assert.Equal(t, "generate_docs", tr.Next, "%s fans in to generate_docs", tr.Node)
+	}
+	assert.Equal(t, "end", transitions[len(transitions)-1].Next)
+
+	beforePlan := generate.ReplayState(transitions, 2)
+	assert.Nil(t, beforePlan.Plan)
+	assert.NotNil(t, beforePlan.FCS, "initial state is part of the replay")
+
+	afterPlan := generate.ReplayState(transitions, 3)
+	require.NotNil(t, afterPlan.Plan)
+	assert.Contains(t, transitions[3].Delta.ChangedFields(), "plan")
+
+	final := generate.ReplayState(transitions, len(transitions)-1)
+	assert.Len(t, final.AllPatches, len(output.Patches))
+	assert.Contains(t, final.CompletedPhases, "generate_config")
+}
+
+func TestEngine_StateNotRecordedByDefault(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "project")
+
+	fileOps, err := fsops.New(fsops.Config{RootDir: outputDir, Logger: &noopFsLogger{}})
+	require.NoError(t, err)
+
+	engine, err := generate.NewEngine(generate.EngineConfig{
+		LLMClient: &mockEngineLLMClient{planResponse: `{"file_tree": {"root": "."}, "phases": []}`},
+		FileOps:   fileOps,
+	})
+	require.NoError(t, err)
+
+	_, _ = engine.Generate(context.Background(), createCompleteTestFCS(), outputDir)
+
+	runs, err := generate.ListStateRuns(outputDir)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+func TestStateSnapshot_RoundTrip(t *testing.T) {
+	state := generate.GenerationState{
+		Plan:            &models.GenerationPlan{ID: "plan-1"},
+		CodePatches:     []models.Patch{},
+		Error:           errors.New("coder failed"),
+		CurrentPhase:    "generate_packages",
+		CompletedPhases: []string{"create_plan"},
+	}
+
+	snapshot := generate.NewStateSnapshot(state)
+	assert.Equal(t, []string{"plan", "code_patches", "error", "current_phase", "completed_phases"}, snapshot.ChangedFields())
+
+	restored := snapshot.State()
+	assert.Equal(t, "plan-1", restored.Plan.ID)
+	assert.NotNil(t, restored.CodePatches, "empty slices stay distinct from nil")
+	assert.Nil(t, restored.TestPatches)
+	require.Error(t, restored.Error)
+	assert.Equal(t, "coder failed", restored.Error.Error())
+}
+
+func TestLoadStateTransitions_MissingRun(t *testing.T) {
+	_, err := generate.LoadStateTransitions(t.TempDir(), "gen-missing")
+	assert.Error(t, err)
+}
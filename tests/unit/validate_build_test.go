@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dshills/gocreator/internal/models"
 	"github.com/dshills/gocreator/internal/validate"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -190,6 +191,39 @@ func Add(a, b int) int {
 	assert.Empty(t, result.Errors)
 }
 
+func TestBuildValidator_Workspace(t *testing.T) {
+	// Workspace mode rejects -mod=mod, which some environments set
+	t.Setenv("GOFLAGS", "")
+
+	// A go.work at the root ties the modules together; ./... matches nothing there
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"go.work":           "go 1.25\n\nuse (\n\t./api\n\t./shared\n)\n",
+		"shared/go.mod":     "module example.com/shared\n\ngo 1.25\n",
+		"shared/shared.go":  "package shared\n\nfunc Greeting() string { return \"hello\" }\n",
+		"api/go.mod":        "module example.com/api\n\ngo 1.25\n\nrequire example.com/shared v0.0.0\n\nreplace example.com/shared => ../shared\n",
+		"api/main.go":       "package main\n\nimport \"example.com/shared\"\n\nfunc main() { println(shared.Greeting()) }\n",
+		"api/broken/bad.go": "package broken\n\nfunc Bad() int { return undefinedValue }\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+	}
+
+	validator := validate.NewBuildValidator(30 * time.Second)
+	result, err := validator.Validate(context.Background(), tmpDir)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	require.Len(t, result.Errors, 1, "every module is built; only the broken package fails")
+	assert.Equal(t, filepath.Join("api", "broken", "bad.go"), filepath.FromSlash(result.Errors[0].File))
+	assert.Contains(t, result.Errors[0].Message, "undefinedValue")
+
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "api", "broken", "bad.go")))
+	result, err = validator.Validate(context.Background(), tmpDir)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+}
+
 func TestBuildValidator_Timeout(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping timeout test in short mode")
@@ -239,3 +273,70 @@ func TestBuildValidator_InvalidProjectRoot(t *testing.T) {
 	assert.False(t, result.Success)
 	assert.NotEmpty(t, result.Errors)
 }
+
+func TestBuildValidator_PackageEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testproject\n\ngo 1.24\n"), 0644)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	require.NoError(t, err)
+
+	// Broken subpackage that nothing imports
+	err = os.MkdirAll(filepath.Join(tmpDir, "pkg", "broken"), 0755)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tmpDir, "pkg", "broken", "broken.go"), []byte("package broken\n\nfunc Bad() int {\n\treturn undefinedName\n}\n"), 0644)
+	require.NoError(t, err)
+
+	events := make(chan models.ProgressEvent, 10)
+	validator := validate.NewBuildValidator(30*time.Second, validate.WithBuildEvents(events), validate.WithVet(true))
+	result, err := validator.Validate(context.Background(), tmpDir)
+	close(events)
+
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+
+	got := make(map[string]models.ProgressEvent)
+	for event := range events {
+		assert.Equal(t, models.EventPackageValidated, event.Type)
+		got[event.Data["check"].(string)+" "+event.Data["package"].(string)] = event
+	}
+
+	require.Contains(t, got, "build testproject")
+	assert.True(t, got["build testproject"].Data["success"].(bool))
+	require.Contains(t, got, "vet testproject")
+	assert.True(t, got["vet testproject"].Data["success"].(bool))
+
+	require.Contains(t, got, "build testproject/pkg/broken")
+	broken := got["build testproject/pkg/broken"]
+	assert.False(t, broken.Data["success"].(bool))
+	assert.Contains(t, broken.Data["excerpt"].(string), "undefinedName")
+	assert.NotContains(t, got, "vet testproject/pkg/broken", "vet should not run on packages that fail to build")
+}
+
+func TestBuildValidator_ParallelDeterministicOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testproject\n\ngo 1.24\n"), 0644)
+	require.NoError(t, err)
+
+	pkgs := []string{"alpha", "bravo", "charlie", "delta"}
+	for _, pkg := range pkgs {
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, pkg), 0755))
+		src := "package " + pkg + "\n\nfunc Value() int {\n\treturn undefined" + pkg + "\n}\n"
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, pkg, pkg+".go"), []byte(src), 0644))
+	}
+
+	validator := validate.NewBuildValidator(30*time.Second,
+		validate.WithBuildParallelism(validate.ParallelValidationConfig{MaxParallel: 4, EnableParallel: true}))
+	result, err := validator.Validate(context.Background(), tmpDir)
+
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	require.Len(t, result.Errors, len(pkgs))
+	for i, pkg := range pkgs {
+		assert.Equal(t, filepath.Join(pkg, pkg+".go"), result.Errors[i].File)
+		assert.Contains(t, result.Errors[i].Message, "undefined"+pkg)
+	}
+}
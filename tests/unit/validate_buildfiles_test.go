@@ -0,0 +1,117 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildFileTools restricts PATH to make and, when hadolint is set, a fake
+// hadolint script, so docker is never used
+func buildFileTools(t *testing.T, hadolint string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake tools are shell scripts")
+	}
+	makeTool, err := exec.LookPath("make")
+	if err != nil {
+		t.Skip("make is not installed")
+	}
+
+	bin := t.TempDir()
+	require.NoError(t, os.Symlink(makeTool, filepath.Join(bin, "make")))
+	if hadolint != "" {
+		//nolint:gosec // G306: The fake tool must be executable
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "hadolint"), []byte("#!/bin/sh\n"+hadolint+"\n"), 0o755))
+	}
+	t.Setenv("PATH", bin)
+}
+
+const buildFilesMakefile = `BINARY := app
+
+.PHONY: build test
+
+build:
+	go build -o $(BINARY) ./...
+
+test: build
+	go test ./...
+
+%.o: %.c
+	cc -c $<
+`
+
+func TestBuildFilesValidator_Passes(t *testing.T) {
+	buildFileTools(t, "exit 0")
+	root := writeSmokeProject(t, map[string]string{
+		"Dockerfile": "FROM golang:1.22\nCOPY . .\n",
+		"Makefile":   buildFilesMakefile,
+	})
+
+	result, err := validate.NewBuildFilesValidator().Validate(context.Background(), root)
+	require.NoError(t, err)
+	assert.True(t, result.Success, "%+v", result.Checks)
+	assert.True(t, result.Checked())
+
+	var commands []string
+	for _, check := range result.Checks {
+		commands = append(commands, check.Command)
+	}
+	assert.Equal(t, []string{"hadolint --no-color --failure-threshold error Dockerfile", "make -n build", "make -n test"}, commands)
+}
+
+func TestBuildFilesValidator_ReportsBrokenFiles(t *testing.T) {
+	buildFileTools(t, "echo 'DL1000 unexpected FORM'; exit 1")
+	root := writeSmokeProject(t, map[string]string{
+		"Dockerfile": "FORM golang:1.22\n",
+		// Recipes indented with spaces instead of a tab
+		"Makefile": "build:\n    go build ./...\n",
+	})
+
+	result, err := validate.NewBuildFilesValidator().Validate(context.Background(), root)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	require.Len(t, result.Checks, 2)
+
+	assert.Equal(t, "hadolint", result.Checks[0].Tool)
+	assert.False(t, result.Checks[0].Success)
+	assert.Contains(t, result.Checks[0].Output, "DL1000")
+
+	assert.Equal(t, "make -n build", result.Checks[1].Command)
+	assert.False(t, result.Checks[1].Success)
+	assert.Contains(t, result.Checks[1].Output, "separator")
+
+	report := &models.ValidationReport{
+		BuildResult: models.BuildResult{Success: true},
+		LintResult:  models.LintResult{Success: true},
+		TestResult:  models.TestResult{Success: true},
+		BuildFiles:  result,
+	}
+	assert.Equal(t, models.ValidationStatusFail, report.ComputeOverallStatus())
+}
+
+func TestBuildFilesValidator_SkipsWithoutTools(t *testing.T) {
+	buildFileTools(t, "")
+	root := writeSmokeProject(t, map[string]string{"Dockerfile": "FROM scratch\n"})
+
+	result, err := validate.NewBuildFilesValidator().Validate(context.Background(), root)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.False(t, result.Checked())
+	require.Len(t, result.Checks, 1)
+	assert.True(t, result.Checks[0].Skipped)
+	assert.Contains(t, result.Checks[0].Message, "hadolint")
+
+	result, err = validate.NewBuildFilesValidator().Validate(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Empty(t, result.Checks)
+}
@@ -0,0 +1,145 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/spec"
+	"github.com/dshills/gocreator/internal/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const storeSource = `package store
+
+import "context"
+
+// Repository stores users
+type Repository interface {
+	Get(ctx context.Context, id string) (*User, error)
+}
+
+// User is a stored user
+type User struct{ ID string }
+
+// ErrNotFound is returned for unknown users
+var ErrNotFound = errors.New("not found")
+
+func New() Repository { return nil }
+`
+
+const routesSource = `package api
+
+import "net/http"
+
+func Routes(h *Handler, r chi.Router) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{userID}", h.GetUser)
+	mux.Handle("/health", h.Health())
+	r.Post("/orders/:id/", h.CreateOrder)
+	return mux
+}
+`
+
+func TestCheckContracts(t *testing.T) {
+	sources := map[string]string{
+		"internal/store/store.go": storeSource,
+		"internal/api/routes.go":  routesSource,
+		"cmd/server/main.go":      "package main\n\nfunc main() {}\n",
+		"Dockerfile":              "FROM scratch\n",
+	}
+
+	tests := []struct {
+		name     string
+		contract models.OutputContract
+		wantMsg  string
+	}{
+		{"file exists", models.OutputContract{File: "cmd/server/main.go"}, ""},
+		{"non-Go file exists", models.OutputContract{File: "./Dockerfile"}, ""},
+		{"file missing", models.OutputContract{File: "cmd/worker/main.go"}, "file does not exist"},
+		{"interface exported", models.OutputContract{Package: "internal/store", Exports: "Repository", Kind: models.ContractKindInterface}, ""},
+		{"any kind", models.OutputContract{Package: "internal/store", Exports: "ErrNotFound"}, ""},
+		{"struct is a type", models.OutputContract{Package: "internal/store", Exports: "User", Kind: models.ContractKindType}, ""},
+		{"func exported", models.OutputContract{Package: "internal/store", Exports: "New", Kind: models.ContractKindFunc}, ""},
+		{"wrong kind", models.OutputContract{Package: "internal/store", Exports: "User", Kind: models.ContractKindInterface}, "User is declared as struct, not interface"},
+		{"not declared", models.OutputContract{Package: "internal/store", Exports: "Cache"}, "Cache is not declared in internal/store"},
+		{"package missing", models.OutputContract{Package: "internal/cache", Exports: "Cache"}, "package internal/cache has no Go files"},
+		{"method pattern", models.OutputContract{Route: "GET /users/{id}"}, ""},
+		{"pattern without method", models.OutputContract{Route: "HEAD /health"}, ""},
+		{"router method", models.OutputContract{Route: "POST /orders/{id}"}, ""},
+		{"wrong method", models.OutputContract{Route: "DELETE /users/{id}"}, "no handler is registered for this route"},
+		{"unrouted path", models.OutputContract{Route: "GET /orders"}, "no handler is registered for this route"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validate.CheckContracts([]models.OutputContract{tt.contract}, sources)
+			assert.Equal(t, 1, result.Checked)
+			if tt.wantMsg == "" {
+				assert.True(t, result.Success, result.Violations)
+				return
+			}
+			require.Len(t, result.Violations, 1)
+			assert.False(t, result.Success)
+			assert.Equal(t, tt.contract.String(), result.Violations[0].Contract)
+			assert.Equal(t, tt.wantMsg, result.Violations[0].Message)
+		})
+	}
+}
+
+func TestCheckContracts_None(t *testing.T) {
+	result := validate.CheckContracts(nil, map[string]string{})
+	assert.True(t, result.Success)
+	assert.Zero(t, result.Checked)
+}
+
+func TestContractValidator_Validate(t *testing.T) {
+	root := t.TempDir()
+	writeFile := func(rel, content string) {
+		full := filepath.Join(root, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0750))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0600))
+	}
+	writeFile("internal/store/store.go", storeSource)
+	writeFile("internal/store/store_test.go", "package store\n")
+	writeFile("configs/app.yaml", "port: 8080\n")
+
+	contractsPath := filepath.Join(t.TempDir(), "contracts.yaml")
+	require.NoError(t, os.WriteFile(contractsPath, []byte(`contracts:
+  - file: configs/app.yaml
+  - file: internal/store/store_test.go
+  - package: internal/store
+    exports: Repository
+    kind: interface
+  - route: GET /users
+`), 0600))
+
+	contracts, err := spec.LoadContracts(contractsPath)
+	require.NoError(t, err)
+	require.Len(t, contracts, 4)
+
+	result, err := validate.NewContractValidator(contracts).Validate(context.Background(), root)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, 4, result.Checked)
+	assert.Equal(t, []models.ContractViolation{
+		{Contract: "GET /users is routed", Message: "no handler is registered for this route"},
+	}, result.Violations)
+}
+
+func TestLoadContracts_Invalid(t *testing.T) {
+	dir := t.TempDir()
+
+	missing := filepath.Join(dir, "missing.yaml")
+	require.NoError(t, os.WriteFile(missing, []byte("files: []\n"), 0600))
+	_, err := spec.LoadContracts(missing)
+	assert.ErrorContains(t, err, "no contracts list")
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"contracts": [{"route": "GET users"}]}`), 0600))
+	_, err = spec.LoadContracts(invalid)
+	assert.ErrorContains(t, err, "contracts[0]: route")
+}
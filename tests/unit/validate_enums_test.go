@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEnumDataModel() models.DataModel {
+	return models.DataModel{
+		Entities: []models.Entity{{
+			Name:       "Order",
+			Package:    "domain",
+			Attributes: map[string]string{"id": "string", "status": "OrderStatus"},
+		}},
+		Enums: []models.Enum{{
+			Name:    "OrderStatus",
+			Package: "domain",
+			Values:  []string{"pending", "in_progress"},
+		}},
+	}
+}
+
+const orderStatusSource = `package domain
+
+import "fmt"
+
+type OrderStatus string
+
+const (
+	OrderStatusPending    OrderStatus = "pending"
+	OrderStatusInProgress OrderStatus = "in_progress"
+)
+
+func (s OrderStatus) String() string { return string(s) }
+
+func ParseOrderStatus(s string) (OrderStatus, error) {
+	switch OrderStatus(s) {
+	case OrderStatusPending, OrderStatusInProgress:
+		return OrderStatus(s), nil
+	}
+	return "", fmt.Errorf("unknown order status %q", s)
+}
+`
+
+func TestCheckEnumUsage(t *testing.T) {
+	t.Run("declared and used", func(t *testing.T) {
+		usage := validate.CheckEnumUsage(testEnumDataModel(), map[string]string{
+			"internal/domain/status.go": orderStatusSource,
+			"internal/domain/order.go":  "package domain\n\ntype Order struct {\n\tID     string\n\tStatus OrderStatus\n}\n",
+		})
+
+		assert.True(t, usage.Success)
+		assert.Equal(t, 1, usage.TotalEnums)
+		assert.Empty(t, usage.Issues)
+	})
+
+	t.Run("field typed with raw string", func(t *testing.T) {
+		usage := validate.CheckEnumUsage(testEnumDataModel(), map[string]string{
+			"internal/domain/status.go": orderStatusSource,
+			"internal/domain/order.go":  "package domain\n\ntype Order struct {\n\tID     string\n\tStatus string\n}\n",
+		})
+
+		assert.False(t, usage.Success)
+		require.Len(t, usage.Issues, 1)
+		assert.Equal(t, models.EnumIssue{
+			Enum:    "OrderStatus",
+			File:    "internal/domain/order.go",
+			Line:    5,
+			Message: "field Order.status has type string, want OrderStatus",
+		}, usage.Issues[0])
+	})
+
+	t.Run("incomplete declaration", func(t *testing.T) {
+		usage := validate.CheckEnumUsage(testEnumDataModel(), map[string]string{
+			"internal/domain/status.go": "package domain\n\ntype OrderStatus int\n\nconst OrderStatusPending OrderStatus = 0\n",
+		})
+
+		var messages []string
+		for _, issue := range usage.Issues {
+			messages = append(messages, issue.Message)
+		}
+		assert.Equal(t, []string{
+			"type OrderStatus is declared over int, want string",
+			`constant OrderStatusInProgress for value "in_progress" is not declared`,
+			"type OrderStatus has no String method",
+			"function ParseOrderStatus is not declared",
+		}, messages)
+	})
+
+	t.Run("missing type", func(t *testing.T) {
+		usage := validate.CheckEnumUsage(testEnumDataModel(), map[string]string{})
+
+		require.Len(t, usage.Issues, 1)
+		assert.Equal(t, "type OrderStatus is not declared", usage.Issues[0].Message)
+	})
+
+	t.Run("no enums", func(t *testing.T) {
+		assert.True(t, validate.CheckEnumUsage(models.DataModel{}, nil).Success)
+	})
+}
+
+func TestEngine_EnumUsageFailsReport(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":                   "module testproject\n\ngo 1.24\n",
+		"main.go":                  "package main\n\nfunc main() {}\n",
+		"internal/domain/order.go": "package domain\n\ntype Order struct {\n\tStatus string\n}\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(tmpDir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+
+	engine := validate.NewEngine(validate.WithEnumValidator(validate.NewEnumValidator(testEnumDataModel())))
+	report, err := engine.Validate(context.Background(), tmpDir)
+	require.NoError(t, err)
+
+	require.NotNil(t, report.EnumUsage)
+	assert.False(t, report.EnumUsage.Success)
+	assert.True(t, report.BuildResult.Success)
+	assert.Equal(t, models.ValidationStatusFail, report.OverallStatus)
+}
@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fuzzParserSource = `package parser
+
+import "errors"
+
+// Parse reads a "key=value" pair
+func Parse(input string) (string, error) {
+	for i := 0; i < len(input); i++ {
+		if input[i] == '=' {
+			return input[i+1:], nil
+		}
+	}
+	return "", errors.New("missing =")
+}
+`
+
+func TestFuzzValidator_Passes(t *testing.T) {
+	root := writeSmokeProject(t, map[string]string{
+		"internal/parser/parser.go": fuzzParserSource,
+		"internal/parser/parser_fuzz_test.go": `package parser
+
+import "testing"
+
+func FuzzParse(f *testing.F) {
+	f.Add("a=b")
+	f.Fuzz(func(t *testing.T, input string) {
+		_, _ = Parse(input)
+	})
+}
+`,
+	})
+
+	result, err := validate.NewFuzzValidator(validate.WithFuzzTime(time.Second)).Validate(context.Background(), root)
+	require.NoError(t, err)
+	assert.True(t, result.Success, "%+v", result.Failures)
+	assert.Equal(t, 1, result.Targets)
+	assert.Equal(t, time.Second, result.FuzzTime)
+}
+
+func TestFuzzValidator_ReportsFailure(t *testing.T) {
+	root := writeSmokeProject(t, map[string]string{
+		"internal/parser/parser.go": fuzzParserSource,
+		"internal/parser/parser_fuzz_test.go": `package parser
+
+import "testing"
+
+func FuzzParse(f *testing.F) {
+	f.Add("crash")
+	f.Fuzz(func(t *testing.T, input string) {
+		if input == "crash" {
+			panic("malformed input")
+		}
+	})
+}
+`,
+	})
+
+	result, err := validate.NewFuzzValidator(validate.WithFuzzTime(time.Second)).Validate(context.Background(), root)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "./internal/parser", result.Failures[0].Package)
+	assert.Equal(t, "FuzzParse", result.Failures[0].Target)
+	assert.Contains(t, result.Failures[0].Output, "malformed input")
+}
+
+func TestFuzzValidator_NoTargets(t *testing.T) {
+	root := writeSmokeProject(t, map[string]string{
+		"internal/parser/parser.go":      fuzzParserSource,
+		"internal/parser/parser_test.go": "package parser\n\nimport \"testing\"\n\nfunc TestParse(t *testing.T) {}\n",
+	})
+
+	result, err := validate.NewFuzzValidator().Validate(context.Background(), root)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Zero(t, result.Targets)
+	assert.Equal(t, validate.DefaultFuzzTime, result.FuzzTime)
+}
+
+func TestValidationReport_FuzzFailureFailsOverall(t *testing.T) {
+	report := &models.ValidationReport{
+		BuildResult: models.BuildResult{Success: true},
+		LintResult:  models.LintResult{Success: true},
+		TestResult:  models.TestResult{Success: true},
+		FuzzResult:  &models.FuzzResult{Targets: 1},
+	}
+	assert.Equal(t, models.ValidationStatusFail, report.ComputeOverallStatus())
+
+	report.FuzzResult.Success = true
+	assert.Equal(t, models.ValidationStatusPass, report.ComputeOverallStatus())
+}
@@ -0,0 +1,122 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCrossCutting() models.CrossCutting {
+	return models.CrossCutting{Concerns: []models.Concern{
+		{Name: models.ConcernAuth},
+		{Name: models.ConcernLogging},
+	}}
+}
+
+const middlewareSource = `package middleware
+
+import "net/http"
+
+func Logging(next http.Handler) http.Handler { return next }
+
+func Auth(next http.Handler) http.Handler { return next }
+`
+
+func TestCheckMiddlewareUsage(t *testing.T) {
+	t.Run("routes wrapped", func(t *testing.T) {
+		usage := validate.CheckMiddlewareUsage(testCrossCutting(), map[string]string{
+			"internal/middleware/middleware.go": middlewareSource,
+			"internal/api/routes.go": `package api
+
+import (
+	"net/http"
+
+	mw "example.com/app/internal/middleware"
+)
+
+func Routes(h *Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users", h.ListUsers)
+	return mw.Logging(mw.Auth(mux))
+}
+`,
+		})
+
+		assert.True(t, usage.Success, usage.Issues)
+		assert.Equal(t, "internal/middleware", usage.Package)
+		assert.Equal(t, []string{"internal/api/routes.go"}, usage.Wrapped)
+	})
+
+	t.Run("route file skips middleware", func(t *testing.T) {
+		usage := validate.CheckMiddlewareUsage(testCrossCutting(), map[string]string{
+			"internal/middleware/middleware.go": middlewareSource,
+			"internal/api/routes.go": `package api
+
+import (
+	"net/http"
+
+	"example.com/app/internal/middleware"
+)
+
+func Routes(h *Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/users", middleware.Logging(http.HandlerFunc(h.ListUsers)))
+	return mux
+}
+`,
+		})
+
+		assert.False(t, usage.Success)
+		assert.Empty(t, usage.Wrapped)
+		require.Len(t, usage.Issues, 1)
+		assert.Equal(t, models.MiddlewareIssue{
+			Concern: models.ConcernAuth,
+			File:    "internal/api/routes.go",
+			Line:    11,
+			Message: "routes are registered without middleware.Auth",
+		}, usage.Issues[0])
+	})
+
+	t.Run("middleware not declared", func(t *testing.T) {
+		usage := validate.CheckMiddlewareUsage(testCrossCutting(), map[string]string{
+			"internal/middleware/middleware.go": "package middleware\n\nimport \"net/http\"\n\nfunc Logging(next http.Handler) http.Handler { return next }\n",
+		})
+
+		require.Len(t, usage.Issues, 1)
+		assert.Equal(t, "middleware middleware.Auth is not declared in internal/middleware", usage.Issues[0].Message)
+	})
+
+	t.Run("no concerns", func(t *testing.T) {
+		assert.True(t, validate.CheckMiddlewareUsage(models.CrossCutting{}, nil).Success)
+	})
+}
+
+func TestEngine_MiddlewareUsageFailsReport(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":  "module testproject\n\ngo 1.24\n",
+		"main.go": "package main\n\nimport \"net/http\"\n\nfunc main() {\n\thttp.HandleFunc(\"/health\", func(http.ResponseWriter, *http.Request) {})\n}\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(tmpDir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+
+	engine := validate.NewEngine(validate.WithMiddlewareValidator(validate.NewMiddlewareValidator(testCrossCutting())))
+	report, err := engine.Validate(context.Background(), tmpDir)
+	require.NoError(t, err)
+
+	require.NotNil(t, report.MiddlewareUsage)
+	assert.False(t, report.MiddlewareUsage.Success)
+	assert.Len(t, report.MiddlewareUsage.Issues, 4)
+	assert.True(t, report.BuildResult.Success)
+	assert.Equal(t, models.ValidationStatusFail, report.OverallStatus)
+}
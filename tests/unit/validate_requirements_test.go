@@ -0,0 +1,98 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRequirements() []models.FunctionalRequirement {
+	return []models.FunctionalRequirement{
+		{ID: "FR-001", Description: "Create users"},
+		{ID: "FR-002", Description: "Delete users"},
+		{ID: "FR-003", Description: "List users"},
+	}
+}
+
+func TestExtractRequirementRefs(t *testing.T) {
+	src := `package users
+
+// Requirement: FR-001
+func TestCreate(t *testing.T) {}
+
+//Requirement: FR-002, FR-003
+func TestDeleteAndList(t *testing.T) {}
+
+// Requirements: FR-001
+func TestCreateAgain(t *testing.T) {}
+`
+	assert.Equal(t, []string{"FR-001", "FR-002", "FR-003"}, validate.ExtractRequirementRefs(src))
+	assert.Empty(t, validate.ExtractRequirementRefs("package users\n\nfunc TestNothing(t *testing.T) {}\n"))
+}
+
+func TestCheckRequirementCoverage(t *testing.T) {
+	coverage := validate.CheckRequirementCoverage(testRequirements(), map[string]string{
+		"users/create_test.go": "// Requirement: FR-001\nfunc TestCreate(t *testing.T) {}\n",
+		"users/list_test.go":   "// Requirement: FR-003, FR-099\nfunc TestList(t *testing.T) {}\n",
+		"users/other_test.go":  "// Requirement: FR-001\nfunc TestOther(t *testing.T) {}\n",
+	})
+
+	assert.False(t, coverage.Success)
+	assert.Equal(t, 3, coverage.TotalRequirements)
+	assert.Equal(t, []string{"FR-002"}, coverage.Untested)
+	assert.Equal(t, []string{"FR-099"}, coverage.Unknown)
+	assert.Equal(t, []string{"users/create_test.go", "users/other_test.go"}, coverage.Covered["FR-001"])
+	assert.Equal(t, []string{"users/list_test.go"}, coverage.Covered["FR-003"])
+
+	full := validate.CheckRequirementCoverage(testRequirements(), map[string]string{
+		"users_test.go": "// Requirement: FR-001, FR-002, FR-003\n",
+	})
+	assert.True(t, full.Success)
+	assert.Empty(t, full.Untested)
+}
+
+func TestRequirementValidator_ScansTestFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"internal/users/service_test.go": "package users\n\n// Requirement: FR-001\nfunc TestCreate(t *testing.T) {}\n",
+		"internal/users/service.go":      "package users\n\n// Requirement: FR-002 is mentioned outside a test file\n",
+		"vendor/dep/dep_test.go":         "package dep\n\n// Requirement: FR-003\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(tmpDir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+
+	coverage, err := validate.NewRequirementValidator(testRequirements()).Validate(context.Background(), tmpDir)
+	require.NoError(t, err)
+
+	assert.False(t, coverage.Success)
+	assert.Equal(t, []string{"internal/users/service_test.go"}, coverage.Covered["FR-001"])
+	assert.Equal(t, []string{"FR-002", "FR-003"}, coverage.Untested)
+}
+
+func TestEngine_RequirementCoverageFailsReport(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testproject\n\ngo 1.24\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main_test.go"),
+		[]byte("package main\n\nimport \"testing\"\n\n// Requirement: FR-001\nfunc TestMain(t *testing.T) {}\n"), 0644))
+
+	engine := validate.NewEngine(validate.WithRequirementValidator(validate.NewRequirementValidator(testRequirements())))
+	report, err := engine.Validate(context.Background(), tmpDir)
+	require.NoError(t, err)
+
+	require.NotNil(t, report.RequirementCoverage)
+	assert.Equal(t, []string{"FR-002", "FR-003"}, report.RequirementCoverage.Untested)
+	assert.True(t, report.BuildResult.Success)
+	assert.Equal(t, models.ValidationStatusFail, report.OverallStatus)
+}
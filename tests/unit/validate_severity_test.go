@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeverityPolicy_Defaults(t *testing.T) {
+	policy := validate.DefaultSeverityPolicy()
+
+	assert.Equal(t, validate.SeverityError, policy.Severity(validate.CheckBuild))
+	assert.Equal(t, validate.SeverityError, policy.Severity(validate.CheckSmoke))
+	assert.Equal(t, validate.SeverityWarning, policy.Severity(validate.CheckVet))
+	assert.Equal(t, validate.SeverityWarning, policy.Severity(validate.CheckCoverage))
+	assert.Equal(t, validate.SeverityError, policy.LintSeverity("errcheck"))
+}
+
+func TestSeverityPolicy_Overrides(t *testing.T) {
+	policy, err := validate.NewSeverityPolicy(
+		map[string]string{"lint": "warning", "build_files": "ignore"},
+		map[string]string{"gosec": "error", "godot": "ignore"},
+		"",
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, validate.SeverityIgnore, policy.Severity(validate.CheckBuildFiles))
+	assert.Equal(t, validate.SeverityWarning, policy.LintSeverity("revive"), "linters take the lint check's severity")
+	assert.Equal(t, validate.SeverityError, policy.LintSeverity("GoSec"))
+
+	_, err = validate.NewSeverityPolicy(map[string]string{"lint": "fatal"}, nil, "")
+	assert.Error(t, err)
+	_, err = validate.NewSeverityPolicy(nil, nil, "ignore")
+	assert.Error(t, err)
+}
+
+func TestSeverityPolicy_Lint(t *testing.T) {
+	policy, err := validate.NewSeverityPolicy(
+		map[string]string{"lint": "info"},
+		map[string]string{"gosec": "warning", "godot": "ignore"},
+		"",
+	)
+	require.NoError(t, err)
+
+	result := &models.LintResult{Issues: []models.LintIssue{
+		{File: "a.go", Rule: "godot", Severity: "error"},
+		{File: "b.go", Rule: "revive", Severity: "error"},
+		{File: "c.go", Rule: "gosec", Severity: "error"},
+	}}
+	policy.ApplyToLint(result)
+	require.Len(t, result.Issues, 2, "ignored issues are dropped")
+	assert.Equal(t, "info", result.Issues[0].Severity)
+	assert.Equal(t, "warning", result.Issues[1].Severity)
+	assert.False(t, result.Success)
+
+	outcome := policy.LintOutcome(result)
+	assert.Equal(t, validate.CheckOutcome{Check: "lint", Passed: false, Severity: validate.SeverityWarning}, outcome)
+
+	onlyIgnored := &models.LintResult{Issues: []models.LintIssue{{Rule: "godot"}}}
+	policy.ApplyToLint(onlyIgnored)
+	assert.True(t, onlyIgnored.Success)
+	assert.Empty(t, onlyIgnored.Issues)
+}
+
+func TestSeverityPolicy_Fails(t *testing.T) {
+	policy := validate.DefaultSeverityPolicy()
+	warnings := []validate.CheckOutcome{
+		policy.Outcome(validate.CheckBuild, true),
+		policy.Outcome(validate.CheckCoverage, false),
+	}
+	assert.Equal(t, validate.SeverityWarning, validate.HighestSeverity(warnings))
+	assert.False(t, policy.Fails(warnings), "warnings do not fail by default")
+
+	errors := append(warnings, policy.Outcome(validate.CheckTest, false))
+	assert.Equal(t, validate.SeverityError, validate.HighestSeverity(errors))
+	assert.True(t, policy.Fails(errors))
+
+	strict, err := validate.NewSeverityPolicy(nil, nil, "warning")
+	require.NoError(t, err)
+	assert.True(t, strict.Fails(warnings))
+
+	ignored, err := validate.NewSeverityPolicy(map[string]string{"test": "ignore"}, nil, "info")
+	require.NoError(t, err)
+	outcomes := []validate.CheckOutcome{ignored.Outcome(validate.CheckTest, false)}
+	assert.Empty(t, validate.HighestSeverity(outcomes))
+	assert.False(t, ignored.Fails(outcomes))
+}
+
+func TestVetFindings(t *testing.T) {
+	result := &models.BuildResult{Warnings: []models.CompilationWarning{
+		{File: "a.go", Message: "declared and not used"},
+		{File: "b.go", Message: "vet: printf call has arguments but no formatting directives"},
+	}}
+	findings := validate.VetFindings(result)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "b.go", findings[0].File)
+}
@@ -0,0 +1,172 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSmokeProject creates a module with the given files
+func writeSmokeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	files["go.mod"] = "module smokeproject\n\ngo 1.21\n"
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o750))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o600))
+	}
+	return root
+}
+
+func TestSmokeValidator_HelpExitsCleanly(t *testing.T) {
+	root := writeSmokeProject(t, map[string]string{
+		"cmd/tool/main.go": `package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+func main() {
+	name := flag.String("name", "world", "who to greet")
+	flag.Parse()
+	fmt.Println("hello", *name)
+}
+`,
+		"tools/gen/main.go": "package main\n\nfunc main() { panic(\"wrong package\") }\n",
+	})
+
+	result, err := validate.NewSmokeValidator().Validate(context.Background(), root)
+	require.NoError(t, err)
+	assert.True(t, result.Success, result.Message)
+	assert.Equal(t, "smokeproject/cmd/tool", result.Package, "packages under cmd/ are preferred")
+	assert.Equal(t, "smoke --help", result.Command)
+	assert.Contains(t, result.Output, "who to greet")
+}
+
+func TestSmokeValidator_CatchesPanic(t *testing.T) {
+	root := writeSmokeProject(t, map[string]string{
+		"main.go": `package main
+
+var handlers map[string]func()
+
+func main() {
+	handlers["start"] = func() {}
+}
+`,
+	})
+
+	result, err := validate.NewSmokeValidator(validate.WithSmokeArgs()).Validate(context.Background(), root)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, result.ExitCode)
+	assert.Equal(t, "panic: assignment to entry in nil map", result.Message)
+}
+
+func TestSmokeValidator_Hang(t *testing.T) {
+	root := writeSmokeProject(t, map[string]string{
+		"main.go": "package main\n\nimport \"time\"\n\nfunc main() { time.Sleep(time.Hour) }\n",
+	})
+
+	result, err := validate.NewSmokeValidator(
+		validate.WithSmokeArgs(),
+		validate.WithStartupTimeout(time.Second),
+	).Validate(context.Background(), root)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Message, "did not exit within 1s")
+}
+
+func TestSmokeValidator_HealthCheck(t *testing.T) {
+	server := `package main
+
+import (
+	"net/http"
+	"os"
+)
+
+func main() {
+	if os.Getenv("SMOKE_FAIL") != "" {
+		panic("missing DATABASE_URL")
+	}
+	http.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	_ = http.ListenAndServe(os.Getenv("ADDR"), nil)
+}
+`
+	root := writeSmokeProject(t, map[string]string{"cmd/server/main.go": server})
+	addr := freeAddr(t)
+
+	t.Run("healthy", func(t *testing.T) {
+		result, err := validate.NewSmokeValidator(
+			validate.WithSmokeEnv("ADDR="+addr),
+			validate.WithHealthCheck(fmt.Sprintf("http://%s/healthz", addr)),
+			validate.WithStartupTimeout(20*time.Second),
+		).Validate(context.Background(), root)
+		require.NoError(t, err)
+		assert.True(t, result.Success, result.Message)
+		assert.Equal(t, "smoke", result.Command, "servers run without --help")
+	})
+
+	t.Run("exits before healthy", func(t *testing.T) {
+		result, err := validate.NewSmokeValidator(
+			validate.WithSmokeEnv("ADDR="+addr, "SMOKE_FAIL=1"),
+			validate.WithHealthCheck(fmt.Sprintf("http://%s/healthz", addr)),
+			validate.WithStartupTimeout(20*time.Second),
+		).Validate(context.Background(), root)
+		require.NoError(t, err)
+		assert.False(t, result.Success)
+		assert.Equal(t, "panic: missing DATABASE_URL", result.Message)
+	})
+}
+
+func TestSmokeValidator_NoMainPackage(t *testing.T) {
+	root := writeSmokeProject(t, map[string]string{
+		"lib.go": "package lib\n\nfunc Add(a, b int) int { return a + b }\n",
+	})
+
+	result, err := validate.NewSmokeValidator().Validate(context.Background(), root)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.True(t, result.Skipped)
+}
+
+// freeAddr returns a localhost address with a currently unused port
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return addr
+}
+
+func TestEngine_SmokeFailureFailsValidation(t *testing.T) {
+	root := writeSmokeProject(t, map[string]string{
+		"main.go": "package main\n\nfunc main() { panic(\"boom\") }\n",
+	})
+
+	engine := validate.NewEngine(
+		validate.WithLintValidator(validate.NewLintValidator(validate.WithSkipIfNotFound(true))),
+		validate.WithSmokeValidator(validate.NewSmokeValidator(validate.WithSmokeArgs())),
+	)
+
+	report, err := engine.Validate(context.Background(), root)
+	require.NoError(t, err)
+	require.NotNil(t, report.SmokeResult)
+	assert.True(t, report.BuildResult.Success)
+	assert.False(t, report.SmokeResult.Success)
+	assert.Equal(t, "panic: boom", report.SmokeResult.Message)
+	assert.Equal(t, models.ValidationStatusFail, report.OverallStatus)
+}
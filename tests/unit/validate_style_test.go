@@ -0,0 +1,162 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dshills/gocreator/internal/models"
+	"github.com/dshills/gocreator/internal/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// styleRules returns the rule of each issue, in order
+func styleRules(result *models.StyleResult) []string {
+	var rules []string
+	for _, issue := range result.Issues {
+		rules = append(rules, issue.Rule)
+	}
+	return rules
+}
+
+func TestCheckHouseStyle(t *testing.T) {
+	t.Run("error wrapping with fmt.Errorf", func(t *testing.T) {
+		result := validate.CheckHouseStyle(models.StyleGuide{ErrorWrapping: models.ErrorWrappingFmt}, map[string]string{
+			"internal/store/store.go": `package store
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+func Load(name string) error {
+	if err := open(name); err != nil {
+		return fmt.Errorf("open %s: %v", name, err)
+	}
+	if err := read(name); err != nil {
+		return fmt.Errorf("read %s: %w", name, err)
+	}
+	return errors.New("unreachable")
+}
+`,
+		})
+
+		assert.False(t, result.Success)
+		assert.Equal(t, 1, result.Files)
+		require.Len(t, result.Issues, 2)
+		assert.Equal(t, "internal/store/store.go", result.Issues[0].File)
+		assert.Equal(t, 6, result.Issues[0].Line)
+		assert.Contains(t, result.Issues[0].Message, "imports github.com/pkg/errors")
+		assert.Equal(t, 11, result.Issues[1].Line)
+		assert.Contains(t, result.Issues[1].Message, "formats err without %w")
+	})
+
+	t.Run("error wrapping with pkg/errors", func(t *testing.T) {
+		result := validate.CheckHouseStyle(models.StyleGuide{ErrorWrapping: models.ErrorWrappingPkgErrors}, map[string]string{
+			"store.go": "package store\n\nimport \"fmt\"\n\nfunc wrap(err error) error { return fmt.Errorf(\"load: %w\", err) }\n",
+		})
+
+		assert.Equal(t, []string{"error_wrapping"}, styleRules(result))
+	})
+
+	t.Run("logging library", func(t *testing.T) {
+		result := validate.CheckHouseStyle(models.StyleGuide{Logging: "slog"}, map[string]string{
+			"a.go": "package app\n\nimport \"log/slog\"\n\nvar _ = slog.Info\n",
+			"b.go": "package app\n\nimport \"github.com/rs/zerolog/log\"\n\nvar _ = log.Info\n",
+			"c.go": "package app\n\nimport \"log\"\n\nvar _ = log.Println\n",
+			"d.go": "package app\n\nimport \"log/syslog\"\n\nvar _ = syslog.LOG_INFO\n",
+		})
+
+		require.Len(t, result.Issues, 2)
+		assert.Equal(t, "b.go", result.Issues[0].File)
+		assert.Equal(t, "c.go", result.Issues[1].File)
+		assert.Equal(t, "imports log; log through slog", result.Issues[1].Message)
+	})
+
+	t.Run("context propagation", func(t *testing.T) {
+		style := models.StyleGuide{Context: models.ContextFirstParam}
+		result := validate.CheckHouseStyle(style, map[string]string{
+			"internal/svc/svc.go": `package svc
+
+import "context"
+
+func Good(ctx context.Context, id string) error { return nil }
+
+func Bad(id string, ctx context.Context) error { return nil }
+
+func Detached() error { return Good(context.Background(), "1") }
+`,
+			"main.go": "package main\n\nimport \"context\"\n\nfunc main() { _ = context.Background() }\n",
+		})
+
+		require.Len(t, result.Issues, 2)
+		assert.Contains(t, result.Issues[0].Message, "Bad takes context.Context as parameter 2")
+		assert.Contains(t, result.Issues[1].Message, "calls Background outside package main")
+	})
+
+	t.Run("initialisms", func(t *testing.T) {
+		result := validate.CheckHouseStyle(models.StyleGuide{Initialisms: true}, map[string]string{
+			"user.go": `package user
+
+type User struct {
+	UserId    string
+	AvatarURL string
+	Identity  string
+}
+
+func (u User) HttpApiUrl() string {
+	userIds := []string{u.UserId}
+	return userIds[0]
+}
+`,
+		})
+
+		var messages []string
+		for _, issue := range result.Issues {
+			messages = append(messages, issue.Message)
+		}
+		assert.Equal(t, []string{
+			"UserId should be UserID",
+			"HttpApiUrl should be HTTPAPIURL",
+			"userIds should be userIDs",
+		}, messages)
+	})
+
+	t.Run("test framework", func(t *testing.T) {
+		sources := map[string]string{
+			"app_test.go": "package app\n\nimport (\n\t\"testing\"\n\n\t\"github.com/stretchr/testify/assert\"\n)\n\nfunc TestApp(t *testing.T) { assert.True(t, true) }\n",
+			"app.go":      "package app\n\nimport \"github.com/stretchr/testify/assert\"\n\nvar _ = assert.True\n",
+		}
+
+		result := validate.CheckHouseStyle(models.StyleGuide{TestFramework: models.TestFrameworkStdlib}, sources)
+		require.Len(t, result.Issues, 1)
+		assert.Equal(t, "app_test.go", result.Issues[0].File)
+		assert.Equal(t, "test_framework", result.Issues[0].Rule)
+
+		assert.True(t, validate.CheckHouseStyle(models.StyleGuide{TestFramework: models.TestFrameworkTestify}, sources).Success)
+	})
+
+	t.Run("unparsable files are skipped", func(t *testing.T) {
+		result := validate.CheckHouseStyle(models.StyleGuide{Initialisms: true}, map[string]string{"broken.go": "not go"})
+		assert.True(t, result.Success)
+		assert.Zero(t, result.Files)
+	})
+}
+
+func TestStyleValidator_ReadsTests(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app.go"), []byte("package app\n\nvar userId string\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app_test.go"), []byte("package app\n\nimport \"github.com/onsi/gomega\"\n\nvar _ = gomega.Equal\n"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "vendor", "x"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "vendor", "x", "x.go"), []byte("package x\n\nvar apiUrl string\n"), 0o600))
+
+	validator := validate.NewStyleValidator(models.StyleGuide{Initialisms: true, TestFramework: models.TestFrameworkTestify})
+	result, err := validator.Validate(context.Background(), root)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Files)
+	assert.Equal(t, []string{"initialisms", "test_framework"}, styleRules(result))
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dshills/gocreator/internal/models"
 	"github.com/dshills/gocreator/internal/validate"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -378,3 +379,74 @@ func TestMain(t *testing.T) {}
 	require.NoError(t, err)
 	require.NotNil(t, result)
 }
+
+func TestTestValidator_PackageEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testproject\n\ngo 1.24\n"), 0644)
+	require.NoError(t, err)
+
+	for _, pkg := range []string{"good", "bad"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, pkg), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, pkg, pkg+".go"), []byte("package "+pkg+"\n\nfunc Value() int { return 1 }\n"), 0644))
+	}
+
+	goodTest := "package good\n\nimport \"testing\"\n\nfunc TestValue(t *testing.T) {\n\tif Value() != 1 {\n\t\tt.Fatal(\"unexpected\")\n\t}\n}\n"
+	badTest := "package bad\n\nimport \"testing\"\n\nfunc TestValue(t *testing.T) {\n\tif Value() != 2 {\n\t\tt.Errorf(\"Value() = %d; want 2\", Value())\n\t}\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "good", "good_test.go"), []byte(goodTest), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "bad", "bad_test.go"), []byte(badTest), 0644))
+
+	events := make(chan models.ProgressEvent, 10)
+	validator := validate.NewTestValidator(validate.WithTestEvents(events))
+	result, err := validator.Validate(context.Background(), tmpDir)
+	close(events)
+
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+
+	got := make(map[string]models.ProgressEvent)
+	for event := range events {
+		assert.Equal(t, validate.CheckTest, event.Data["check"])
+		got[event.Data["package"].(string)] = event
+	}
+
+	require.Contains(t, got, "testproject/good")
+	assert.True(t, got["testproject/good"].Data["success"].(bool))
+
+	require.Contains(t, got, "testproject/bad")
+	bad := got["testproject/bad"]
+	assert.False(t, bad.Data["success"].(bool))
+	assert.Contains(t, bad.Data["excerpt"].(string), "want 2")
+}
+
+func TestTestValidator_ParallelDeterministicOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module testproject\n\ngo 1.24\n"), 0644)
+	require.NoError(t, err)
+
+	pkgs := []string{"alpha", "bravo", "charlie", "delta"}
+	for _, pkg := range pkgs {
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, pkg), 0755))
+		src := "package " + pkg + "\n\nfunc Value() int { return 1 }\n"
+		test := "package " + pkg + "\n\nimport \"testing\"\n\nfunc TestFails(t *testing.T) {\n\tt.Errorf(\"" + pkg + " failed\")\n}\n"
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, pkg, pkg+".go"), []byte(src), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, pkg, pkg+"_test.go"), []byte(test), 0644))
+	}
+
+	for _, config := range []validate.ParallelValidationConfig{
+		{MaxParallel: 4, EnableParallel: true},
+		{MaxParallel: 1, EnableParallel: false},
+	} {
+		validator := validate.NewTestValidator(validate.WithTestParallelism(config))
+		result, err := validator.Validate(context.Background(), tmpDir)
+
+		require.NoError(t, err)
+		assert.False(t, result.Success)
+		require.Len(t, result.Failures, len(pkgs))
+		for i, pkg := range pkgs {
+			assert.Equal(t, "testproject/"+pkg, result.Failures[i].Package, "parallel=%v", config.EnableParallel)
+			assert.Contains(t, result.Failures[i].Message, pkg+" failed")
+		}
+	}
+}